@@ -8,32 +8,33 @@ import (
 	"github.com/jbctechsolutions/skillrunner/internal/application/ports"
 )
 
-// CompositeCache combines in-memory and SQLite caches in a two-tier architecture.
-// Hot data is served from memory, while cold data is persisted to SQLite.
+// CompositeCache combines an in-memory cache and a persistent cache, such as
+// SQLiteCache, in a two-tier architecture. Hot data is served from memory,
+// while cold data is persisted by the storage backend.
 type CompositeCache struct {
-	memory *MemoryCache
-	sqlite *SQLiteCache
+	memory     *MemoryCache
+	persistent ports.CachePort
 }
 
-// NewCompositeCache creates a new composite cache with memory and SQLite tiers.
-func NewCompositeCache(memory *MemoryCache, sqlite *SQLiteCache) *CompositeCache {
+// NewCompositeCache creates a new composite cache with memory and persistent tiers.
+func NewCompositeCache(memory *MemoryCache, persistent ports.CachePort) *CompositeCache {
 	return &CompositeCache{
-		memory: memory,
-		sqlite: sqlite,
+		memory:     memory,
+		persistent: persistent,
 	}
 }
 
-// Get retrieves an item from cache, checking memory first then SQLite.
+// Get retrieves an item from cache, checking memory first then the persistent tier.
 func (c *CompositeCache) Get(ctx context.Context, key string) (any, bool) {
 	// Check memory first
 	if value, found := c.memory.Get(ctx, key); found {
 		return value, true
 	}
 
-	// Check SQLite
-	if value, found := c.sqlite.Get(ctx, key); found {
+	// Check the persistent tier
+	if value, found := c.persistent.Get(ctx, key); found {
 		// Promote to memory cache
-		entry, _ := c.sqlite.GetEntry(ctx, key)
+		entry, _ := c.persistent.GetEntry(ctx, key)
 		if entry != nil {
 			remainingTTL := time.Until(entry.ExpiresAt)
 			if remainingTTL > 0 {
@@ -53,8 +54,8 @@ func (c *CompositeCache) GetEntry(ctx context.Context, key string) (*ports.Cache
 		return entry, true
 	}
 
-	// Check SQLite
-	if entry, found := c.sqlite.GetEntry(ctx, key); found {
+	// Check the persistent tier
+	if entry, found := c.persistent.GetEntry(ctx, key); found {
 		// Promote to memory cache
 		remainingTTL := time.Until(entry.ExpiresAt)
 		if remainingTTL > 0 {
@@ -66,15 +67,15 @@ func (c *CompositeCache) GetEntry(ctx context.Context, key string) (*ports.Cache
 	return nil, false
 }
 
-// Set stores an item in both memory and SQLite caches.
+// Set stores an item in both memory and persistent caches.
 func (c *CompositeCache) Set(ctx context.Context, key string, value any, ttl time.Duration) error {
 	// Set in memory first (fast)
 	if err := c.memory.Set(ctx, key, value, ttl); err != nil {
 		return err
 	}
 
-	// Persist to SQLite (durable)
-	return c.sqlite.Set(ctx, key, value, ttl)
+	// Persist (durable)
+	return c.persistent.Set(ctx, key, value, ttl)
 }
 
 // SetWithMetadata stores an item with additional metadata for tracking.
@@ -84,8 +85,8 @@ func (c *CompositeCache) SetWithMetadata(ctx context.Context, entry *ports.Cache
 		return err
 	}
 
-	// Persist to SQLite
-	return c.sqlite.SetWithMetadata(ctx, entry)
+	// Persist
+	return c.persistent.SetWithMetadata(ctx, entry)
 }
 
 // Delete removes an item from both caches.
@@ -95,8 +96,8 @@ func (c *CompositeCache) Delete(ctx context.Context, key string) error {
 		return err
 	}
 
-	// Delete from SQLite
-	return c.sqlite.Delete(ctx, key)
+	// Delete from the persistent tier
+	return c.persistent.Delete(ctx, key)
 }
 
 // Clear removes all items from both caches.
@@ -106,8 +107,8 @@ func (c *CompositeCache) Clear(ctx context.Context) error {
 		return err
 	}
 
-	// Clear SQLite
-	return c.sqlite.Clear(ctx)
+	// Clear the persistent tier
+	return c.persistent.Clear(ctx)
 }
 
 // Has checks if a key exists in either cache.
@@ -115,13 +116,13 @@ func (c *CompositeCache) Has(ctx context.Context, key string) bool {
 	if c.memory.Has(ctx, key) {
 		return true
 	}
-	return c.sqlite.Has(ctx, key)
+	return c.persistent.Has(ctx, key)
 }
 
 // Stats returns combined cache statistics.
 func (c *CompositeCache) Stats(ctx context.Context) (*ports.CacheStats, error) {
-	// Get SQLite stats as the source of truth for persistent stats
-	stats, err := c.sqlite.Stats(ctx)
+	// Get persistent stats as the source of truth for persistent stats
+	stats, err := c.persistent.Stats(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -148,9 +149,9 @@ func (c *CompositeCache) Stats(ctx context.Context) (*ports.CacheStats, error) {
 // Cleanup removes expired entries from both caches.
 func (c *CompositeCache) Cleanup(ctx context.Context) (int64, error) {
 	memRemoved, _ := c.memory.Cleanup(ctx)
-	sqlRemoved, err := c.sqlite.Cleanup(ctx)
+	diskRemoved, err := c.persistent.Cleanup(ctx)
 
-	return memRemoved + sqlRemoved, err
+	return memRemoved + diskRemoved, err
 }
 
 // Keys returns all unique keys matching a pattern from both caches.
@@ -161,7 +162,7 @@ func (c *CompositeCache) Keys(ctx context.Context, pattern string) ([]string, er
 		return nil, err
 	}
 
-	sqlKeys, err := c.sqlite.Keys(ctx, pattern)
+	diskKeys, err := c.persistent.Keys(ctx, pattern)
 	if err != nil {
 		return nil, err
 	}
@@ -171,7 +172,7 @@ func (c *CompositeCache) Keys(ctx context.Context, pattern string) ([]string, er
 	for _, key := range memKeys {
 		keySet[key] = struct{}{}
 	}
-	for _, key := range sqlKeys {
+	for _, key := range diskKeys {
 		keySet[key] = struct{}{}
 	}
 
@@ -190,12 +191,12 @@ func (c *CompositeCache) Size(ctx context.Context) (int64, error) {
 		return 0, err
 	}
 
-	sqlSize, err := c.sqlite.Size(ctx)
+	diskSize, err := c.persistent.Size(ctx)
 	if err != nil {
 		return 0, err
 	}
 
-	return memSize + sqlSize, nil
+	return memSize + diskSize, nil
 }
 
 // Close closes the composite cache and its underlying caches.
@@ -122,6 +122,10 @@ func Fingerprint(req ports.CompletionRequest) string {
 		parts = append(parts, "system:"+req.SystemPrompt)
 	}
 
+	if req.ResponseFormat != nil {
+		parts = append(parts, "response_format:"+string(req.ResponseFormat.Type)+":"+string(req.ResponseFormat.Schema))
+	}
+
 	// Sort all parts for determinism
 	sort.Strings(parts)
 
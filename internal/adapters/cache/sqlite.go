@@ -12,7 +12,8 @@ import (
 	"github.com/jbctechsolutions/skillrunner/internal/application/ports"
 )
 
-// SQLiteCache implements CachePort using SQLite for persistent caching.
+// SQLiteCache implements CachePort using SQLite for persistent caching. Its
+// queries are portable SQL, so the Postgres storage backend uses it as well.
 type SQLiteCache struct {
 	db      *sql.DB
 	maxSize int64 // Maximum cache size in bytes
@@ -46,7 +47,7 @@ func (s *SQLiteCache) GetEntry(ctx context.Context, key string) (*ports.CacheEnt
 			   finish_reason, model_used, duration_ns, size_bytes, hit_count,
 			   ttl_seconds, created_at, expires_at, last_accessed_at
 		FROM response_cache
-		WHERE key = ? AND expires_at > datetime('now')
+		WHERE key = ? AND expires_at > CURRENT_TIMESTAMP
 	`, key)
 
 	var entry ports.CacheEntry
@@ -75,7 +76,7 @@ func (s *SQLiteCache) GetEntry(ctx context.Context, key string) (*ports.CacheEnt
 	// Update hit count and last accessed time
 	_, _ = s.db.ExecContext(ctx, `
 		UPDATE response_cache
-		SET hit_count = hit_count + 1, last_accessed_at = datetime('now')
+		SET hit_count = hit_count + 1, last_accessed_at = CURRENT_TIMESTAMP
 		WHERE key = ?
 	`, key)
 
@@ -161,11 +162,19 @@ func (s *SQLiteCache) SetWithMetadata(ctx context.Context, entry *ports.CacheEnt
 	}
 
 	_, err = s.db.ExecContext(ctx, `
-		INSERT OR REPLACE INTO response_cache
+		INSERT INTO response_cache
 		(key, fingerprint, model_id, response_content, input_tokens, output_tokens,
 		 finish_reason, model_used, duration_ns, size_bytes, hit_count,
 		 ttl_seconds, created_at, expires_at, last_accessed_at)
 		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (key) DO UPDATE SET
+			fingerprint = excluded.fingerprint, model_id = excluded.model_id,
+			response_content = excluded.response_content, input_tokens = excluded.input_tokens,
+			output_tokens = excluded.output_tokens, finish_reason = excluded.finish_reason,
+			model_used = excluded.model_used, duration_ns = excluded.duration_ns,
+			size_bytes = excluded.size_bytes, hit_count = excluded.hit_count,
+			ttl_seconds = excluded.ttl_seconds, created_at = excluded.created_at,
+			expires_at = excluded.expires_at, last_accessed_at = excluded.last_accessed_at
 	`,
 		entry.Key, entry.PromptHash, entry.ModelID, responseContent,
 		inputTokens, outputTokens, finishReason, modelUsed,
@@ -215,7 +224,7 @@ func (s *SQLiteCache) Clear(ctx context.Context) error {
 	// Reset stats
 	_, err = s.db.ExecContext(ctx, `
 		UPDATE cache_stats
-		SET stat_value = 0, updated_at = datetime('now')
+		SET stat_value = 0, updated_at = CURRENT_TIMESTAMP
 	`)
 	return err
 }
@@ -225,7 +234,7 @@ func (s *SQLiteCache) Has(ctx context.Context, key string) bool {
 	var count int
 	err := s.db.QueryRowContext(ctx, `
 		SELECT COUNT(*) FROM response_cache
-		WHERE key = ? AND expires_at > datetime('now')
+		WHERE key = ? AND expires_at > CURRENT_TIMESTAMP
 	`, key).Scan(&count)
 
 	return err == nil && count > 0
@@ -239,7 +248,7 @@ func (s *SQLiteCache) Stats(ctx context.Context) (*ports.CacheStats, error) {
 	err := s.db.QueryRowContext(ctx, `
 		SELECT COUNT(*), COALESCE(SUM(size_bytes), 0)
 		FROM response_cache
-		WHERE expires_at > datetime('now')
+		WHERE expires_at > CURRENT_TIMESTAMP
 	`).Scan(&stats.TotalEntries, &stats.TotalSize)
 	if err != nil {
 		return nil, err
@@ -284,7 +293,7 @@ func (s *SQLiteCache) Stats(ctx context.Context) (*ports.CacheStats, error) {
 	_ = s.db.QueryRowContext(ctx, `
 		SELECT MIN(created_at), MAX(created_at), AVG(ttl_seconds)
 		FROM response_cache
-		WHERE expires_at > datetime('now')
+		WHERE expires_at > CURRENT_TIMESTAMP
 	`).Scan(&stats.OldestEntry, &stats.NewestEntry, &stats.AvgTTL)
 
 	return stats, nil
@@ -293,7 +302,7 @@ func (s *SQLiteCache) Stats(ctx context.Context) (*ports.CacheStats, error) {
 // Cleanup removes expired entries. Returns number of entries removed.
 func (s *SQLiteCache) Cleanup(ctx context.Context) (int64, error) {
 	result, err := s.db.ExecContext(ctx, `
-		DELETE FROM response_cache WHERE expires_at <= datetime('now')
+		DELETE FROM response_cache WHERE expires_at <= CURRENT_TIMESTAMP
 	`)
 	if err != nil {
 		return 0, err
@@ -310,7 +319,7 @@ func (s *SQLiteCache) Cleanup(ctx context.Context) (int64, error) {
 // Keys returns all keys matching a pattern (empty pattern = all keys).
 func (s *SQLiteCache) Keys(ctx context.Context, pattern string) ([]string, error) {
 	rows, err := s.db.QueryContext(ctx, `
-		SELECT key FROM response_cache WHERE expires_at > datetime('now')
+		SELECT key FROM response_cache WHERE expires_at > CURRENT_TIMESTAMP
 	`)
 	if err != nil {
 		return nil, err
@@ -345,7 +354,7 @@ func (s *SQLiteCache) Size(ctx context.Context) (int64, error) {
 	var size int64
 	err := s.db.QueryRowContext(ctx, `
 		SELECT COALESCE(SUM(size_bytes), 0) FROM response_cache
-		WHERE expires_at > datetime('now')
+		WHERE expires_at > CURRENT_TIMESTAMP
 	`).Scan(&size)
 	return size, err
 }
@@ -359,7 +368,7 @@ func (s *SQLiteCache) incrementStat(ctx context.Context, statType string) {
 func (s *SQLiteCache) incrementStatBy(ctx context.Context, statType string, amount int64) {
 	_, _ = s.db.ExecContext(ctx, `
 		UPDATE cache_stats
-		SET stat_value = stat_value + ?, updated_at = datetime('now')
+		SET stat_value = stat_value + ?, updated_at = CURRENT_TIMESTAMP
 		WHERE stat_type = ? AND model_id IS NULL
 	`, amount, statType)
 }
@@ -0,0 +1,144 @@
+package converter
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/xml"
+	"io"
+	"strings"
+
+	"github.com/jbctechsolutions/skillrunner/internal/application/ports"
+	"github.com/jbctechsolutions/skillrunner/internal/domain/errors"
+)
+
+// docxDocumentPath is the main document part of a DOCX package.
+const docxDocumentPath = "word/document.xml"
+
+// maxDOCXDocumentSize bounds the decompressed document part.
+const maxDOCXDocumentSize = 64 << 20
+
+// DOCXConverter converts Word documents to text, keeping paragraph breaks and
+// turning Heading and Title styles into markdown headings.
+type DOCXConverter struct{}
+
+// Ensure DOCXConverter implements DocumentConverterPort at compile time.
+var _ ports.DocumentConverterPort = (*DOCXConverter)(nil)
+
+// NewDOCXConverter creates a DOCX converter.
+func NewDOCXConverter() *DOCXConverter {
+	return &DOCXConverter{}
+}
+
+// Name returns the format name.
+func (c *DOCXConverter) Name() string {
+	return "docx"
+}
+
+// Extensions returns the handled file extensions.
+func (c *DOCXConverter) Extensions() []string {
+	return []string{".docx"}
+}
+
+// Convert extracts the text of a DOCX document.
+func (c *DOCXConverter) Convert(_ context.Context, data []byte) (string, error) {
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return "", errors.NewError(errors.CodeValidation, "not a DOCX file", err)
+	}
+
+	for _, file := range archive.File {
+		if file.Name != docxDocumentPath {
+			continue
+		}
+		rc, err := file.Open()
+		if err != nil {
+			return "", errors.NewError(errors.CodeValidation, "failed to open DOCX document", err)
+		}
+		defer rc.Close()
+		return docxText(io.LimitReader(rc, maxDOCXDocumentSize))
+	}
+
+	return "", errors.NewError(errors.CodeValidation, "DOCX file has no "+docxDocumentPath, nil)
+}
+
+// docxText extracts the paragraphs of a WordprocessingML document.
+func docxText(r io.Reader) (string, error) {
+	var (
+		w         = &textWriter{}
+		paragraph strings.Builder
+		heading   int
+		inText    bool
+	)
+
+	decoder := xml.NewDecoder(r)
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", errors.NewError(errors.CodeValidation, "failed to parse DOCX document", err)
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "p":
+				paragraph.Reset()
+				heading = 0
+			case "pStyle":
+				heading = docxHeadingLevel(attrValue(t, "val"))
+			case "t":
+				inText = true
+			case "tab":
+				paragraph.WriteByte('\t')
+			case "br", "cr":
+				paragraph.WriteByte('\n')
+			}
+		case xml.EndElement:
+			switch t.Name.Local {
+			case "t":
+				inText = false
+			case "p":
+				text := strings.TrimSpace(paragraph.String())
+				if text == "" {
+					continue
+				}
+				w.Paragraph()
+				if heading > 0 {
+					w.WriteRaw(strings.Repeat("#", heading) + " ")
+				}
+				w.WriteRaw(text)
+			}
+		case xml.CharData:
+			if inText {
+				paragraph.Write(t)
+			}
+		}
+	}
+
+	return w.String(), nil
+}
+
+// docxHeadingLevel returns the markdown heading level of a paragraph style
+// ("Heading2" is 2, "Title" is 1), or 0 for body text.
+func docxHeadingLevel(style string) int {
+	if style == "Title" {
+		return 1
+	}
+	if level, ok := strings.CutPrefix(style, "Heading"); ok && len(level) == 1 && level[0] >= '1' && level[0] <= '6' {
+		return int(level[0] - '0')
+	}
+	return 0
+}
+
+// attrValue returns the value of the attribute with the given local name.
+func attrValue(e xml.StartElement, local string) string {
+	for _, attr := range e.Attr {
+		if attr.Name.Local == local {
+			return attr.Value
+		}
+	}
+	return ""
+}
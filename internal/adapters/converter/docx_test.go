@@ -0,0 +1,53 @@
+package converter
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"testing"
+)
+
+// buildDOCX returns a DOCX package whose document part is body.
+func buildDOCX(t *testing.T, body string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	w, err := zw.Create(docxDocumentPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _ = w.Write([]byte(`<?xml version="1.0" encoding="UTF-8"?>
+<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"><w:body>` + body + `</w:body></w:document>`))
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestDOCXConverter_Convert(t *testing.T) {
+	data := buildDOCX(t, `
+<w:p><w:pPr><w:pStyle w:val="Heading2"/></w:pPr><w:r><w:t>Findings</w:t></w:r></w:p>
+<w:p><w:r><w:t xml:space="preserve">The cache </w:t></w:r><w:r><w:t>is cold.</w:t></w:r></w:p>
+<w:p></w:p>
+<w:p><w:r><w:t>Name</w:t><w:tab/><w:t>Value</w:t></w:r></w:p>`)
+
+	got, err := NewDOCXConverter().Convert(context.Background(), data)
+	if err != nil {
+		t.Fatalf("Convert() error = %v", err)
+	}
+	if want := "## Findings\n\nThe cache is cold.\n\nName\tValue"; got != want {
+		t.Errorf("Convert() = %q, want %q", got, want)
+	}
+}
+
+func TestDOCXConverter_Invalid(t *testing.T) {
+	if _, err := NewDOCXConverter().Convert(context.Background(), []byte("plain text")); err == nil {
+		t.Error("expected error for a non-zip file")
+	}
+
+	var buf bytes.Buffer
+	_ = zip.NewWriter(&buf).Close()
+	if _, err := NewDOCXConverter().Convert(context.Background(), buf.Bytes()); err == nil {
+		t.Error("expected error for a zip without a document part")
+	}
+}
@@ -0,0 +1,133 @@
+package converter
+
+import (
+	"bytes"
+	"context"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+
+	"github.com/jbctechsolutions/skillrunner/internal/application/ports"
+	"github.com/jbctechsolutions/skillrunner/internal/domain/errors"
+)
+
+// skippedElements hold no readable content.
+var skippedElements = map[atom.Atom]bool{
+	atom.Script:   true,
+	atom.Style:    true,
+	atom.Noscript: true,
+	atom.Template: true,
+	atom.Svg:      true,
+	atom.Iframe:   true,
+	atom.Head:     true,
+}
+
+// blockElements start a new paragraph.
+var blockElements = map[atom.Atom]bool{
+	atom.P: true, atom.Div: true, atom.Section: true, atom.Article: true,
+	atom.Main: true, atom.Header: true, atom.Footer: true, atom.Nav: true,
+	atom.Aside: true, atom.Blockquote: true, atom.Table: true, atom.Tr: true,
+	atom.Ul: true, atom.Ol: true, atom.Dl: true, atom.Figure: true,
+	atom.Form: true, atom.Hr: true,
+}
+
+// headingLevels maps heading elements to their markdown level.
+var headingLevels = map[atom.Atom]int{
+	atom.H1: 1, atom.H2: 2, atom.H3: 3, atom.H4: 4, atom.H5: 5, atom.H6: 6,
+}
+
+// HTMLConverter converts HTML to markdown-style text, keeping headings, list
+// items and preformatted blocks and dropping scripts and styles.
+type HTMLConverter struct{}
+
+// Ensure HTMLConverter implements DocumentConverterPort at compile time.
+var _ ports.DocumentConverterPort = (*HTMLConverter)(nil)
+
+// NewHTMLConverter creates an HTML converter.
+func NewHTMLConverter() *HTMLConverter {
+	return &HTMLConverter{}
+}
+
+// Name returns the format name.
+func (c *HTMLConverter) Name() string {
+	return "html"
+}
+
+// Extensions returns the handled file extensions.
+func (c *HTMLConverter) Extensions() []string {
+	return []string{".html", ".htm", ".xhtml"}
+}
+
+// Convert extracts the text of an HTML document.
+func (c *HTMLConverter) Convert(_ context.Context, data []byte) (string, error) {
+	doc, err := html.Parse(bytes.NewReader(data))
+	if err != nil {
+		return "", errors.NewError(errors.CodeValidation, "failed to parse HTML", err)
+	}
+	return HTMLNodeText(doc), nil
+}
+
+// HTMLNodeText renders the text of an HTML node tree as markdown-style text.
+func HTMLNodeText(n *html.Node) string {
+	w := &textWriter{}
+	writeHTMLNode(w, n, false)
+	return w.String()
+}
+
+// writeHTMLNode appends the text of n and its children to w.
+func writeHTMLNode(w *textWriter, n *html.Node, pre bool) {
+	switch n.Type {
+	case html.TextNode:
+		if pre {
+			w.WriteRaw(n.Data)
+		} else {
+			w.WriteText(n.Data)
+		}
+		return
+	case html.ElementNode:
+	case html.DocumentNode:
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			writeHTMLNode(w, child, pre)
+		}
+		return
+	default:
+		return
+	}
+
+	if skippedElements[n.DataAtom] {
+		return
+	}
+
+	switch {
+	case headingLevels[n.DataAtom] > 0:
+		w.Paragraph()
+		w.WriteRaw(strings.Repeat("#", headingLevels[n.DataAtom]) + " ")
+	case n.DataAtom == atom.Li:
+		w.Line()
+		w.WriteRaw("- ")
+	case n.DataAtom == atom.Pre:
+		w.Paragraph()
+		w.WriteRaw("```\n")
+		pre = true
+	case n.DataAtom == atom.Br:
+		w.Line()
+	case n.DataAtom == atom.Td || n.DataAtom == atom.Th:
+		w.WriteText(" ")
+	case blockElements[n.DataAtom]:
+		w.Paragraph()
+	}
+
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		writeHTMLNode(w, child, pre)
+	}
+
+	switch {
+	case n.DataAtom == atom.Pre:
+		w.Line()
+		w.WriteRaw("```")
+		w.Paragraph()
+	case headingLevels[n.DataAtom] > 0 || blockElements[n.DataAtom]:
+		w.Paragraph()
+	}
+}
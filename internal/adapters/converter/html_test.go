@@ -0,0 +1,30 @@
+package converter
+
+import (
+	"context"
+	"testing"
+)
+
+func TestHTMLConverter_Convert(t *testing.T) {
+	page := `<html><head><title>Ignored</title><style>p{}</style></head><body>
+<nav>Home</nav>
+<h1>Release  notes</h1>
+<p>Version <b>2.0</b> adds
+   streaming.</p>
+<script>track()</script>
+<ul><li>Faster</li><li>Smaller</li></ul>
+<pre>go test ./...
+go vet ./...</pre>
+<p>Line one<br>Line two</p>
+</body></html>`
+
+	got, err := NewHTMLConverter().Convert(context.Background(), []byte(page))
+	if err != nil {
+		t.Fatalf("Convert() error = %v", err)
+	}
+
+	want := "Home\n\n# Release notes\n\nVersion 2.0 adds streaming.\n\n- Faster\n- Smaller\n\n```\ngo test ./...\ngo vet ./...\n```\n\nLine one\nLine two"
+	if got != want {
+		t.Errorf("Convert() =\n%s\nwant\n%s", got, want)
+	}
+}
@@ -0,0 +1,404 @@
+package converter
+
+import (
+	"bytes"
+	"compress/zlib"
+	"context"
+	"io"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf16"
+
+	"github.com/jbctechsolutions/skillrunner/internal/application/ports"
+	"github.com/jbctechsolutions/skillrunner/internal/domain/errors"
+)
+
+// maxPDFStreamSize bounds a single decompressed content stream.
+const maxPDFStreamSize = 64 << 20
+
+// tjSpaceThreshold is the TJ displacement, in thousandths of an em, that is
+// wide enough to be a word break.
+const tjSpaceThreshold = -200
+
+// skippedStreamKeys mark streams that are not page content: images, fonts,
+// object and cross-reference streams.
+var skippedStreamKeys = []string{"/Image", "/XRef", "/ObjStm", "/Length1", "/Length2", "/Type1C", "/CIDFontType0C", "/OpenType", "/Metadata"}
+
+// PDFConverter extracts the text drawn by the content streams of a PDF. It
+// handles uncompressed and Flate-compressed streams with standard or UTF-16
+// string encodings; scanned PDFs have no text to extract and need OCR.
+type PDFConverter struct{}
+
+// Ensure PDFConverter implements DocumentConverterPort at compile time.
+var _ ports.DocumentConverterPort = (*PDFConverter)(nil)
+
+// NewPDFConverter creates a PDF converter.
+func NewPDFConverter() *PDFConverter {
+	return &PDFConverter{}
+}
+
+// Name returns the format name.
+func (c *PDFConverter) Name() string {
+	return "pdf"
+}
+
+// Extensions returns the handled file extensions.
+func (c *PDFConverter) Extensions() []string {
+	return []string{".pdf"}
+}
+
+// Convert extracts the text of a PDF document.
+func (c *PDFConverter) Convert(ctx context.Context, data []byte) (string, error) {
+	if !bytes.HasPrefix(bytes.TrimLeft(data, "\x00\t\n\r "), []byte("%PDF-")) {
+		return "", errors.NewError(errors.CodeValidation, "not a PDF file", nil)
+	}
+	if bytes.Contains(data, []byte("/Encrypt")) {
+		return "", errors.NewError(errors.CodeValidation, "encrypted PDFs are not supported", nil)
+	}
+
+	w := &textWriter{}
+	for dict, stream := range pdfStreams(data) {
+		if err := ctx.Err(); err != nil {
+			return "", err
+		}
+		content, ok := decodePDFStream(dict, stream)
+		if !ok || !bytes.Contains(content, []byte("BT")) {
+			continue
+		}
+		w.Paragraph()
+		extractPDFText(w, content)
+	}
+
+	text := w.String()
+	if text == "" {
+		return "", errors.NewError(errors.CodeValidation, "PDF has no extractable text (scanned documents need OCR)", nil)
+	}
+	return text, nil
+}
+
+// pdfStreams yields the dictionary and raw data of each stream object.
+func pdfStreams(data []byte) func(yield func(dict, stream []byte) bool) {
+	return func(yield func(dict, stream []byte) bool) {
+		pos := 0
+		for {
+			idx := bytes.Index(data[pos:], []byte("stream"))
+			if idx < 0 {
+				return
+			}
+			keyword := pos + idx
+			start := keyword + len("stream")
+			pos = start
+
+			// "endstream" also contains "stream"; real streams start with an EOL
+			if keyword >= 3 && string(data[keyword-3:keyword]) == "end" {
+				continue
+			}
+			switch {
+			case bytes.HasPrefix(data[start:], []byte("\r\n")):
+				start += 2
+			case bytes.HasPrefix(data[start:], []byte("\n")):
+				start++
+			default:
+				continue
+			}
+
+			end := bytes.Index(data[start:], []byte("endstream"))
+			if end < 0 {
+				return
+			}
+			dictStart := bytes.LastIndex(data[:keyword], []byte("obj"))
+			if dictStart < 0 {
+				dictStart = 0
+			}
+
+			if !yield(data[dictStart:keyword], data[start:start+end]) {
+				return
+			}
+			pos = start + end
+		}
+	}
+}
+
+// decodePDFStream decodes a stream with no filter or FlateDecode. Streams
+// that are not page content or use other filters are skipped.
+func decodePDFStream(dict, stream []byte) ([]byte, bool) {
+	for _, key := range skippedStreamKeys {
+		if bytes.Contains(dict, []byte(key)) {
+			return nil, false
+		}
+	}
+	if !bytes.Contains(dict, []byte("/Filter")) {
+		return stream, true
+	}
+	if !bytes.Contains(dict, []byte("/FlateDecode")) || bytes.Count(dict, []byte("Decode")) > 1 {
+		return nil, false
+	}
+
+	r, err := zlib.NewReader(bytes.NewReader(stream))
+	if err != nil {
+		return nil, false
+	}
+	defer r.Close()
+
+	// Truncated streams are common; keep whatever inflated
+	content, _ := io.ReadAll(io.LimitReader(r, maxPDFStreamSize))
+	return content, len(content) > 0
+}
+
+// pdfToken is a lexical token of a content stream.
+type pdfToken struct {
+	kind  byte // 's' string, 'n' number, '[' and ']' array bounds, 'o' operator, 'x' other
+	text  string
+	value float64
+}
+
+// extractPDFText writes the text shown by the operators of a content stream.
+func extractPDFText(w *textWriter, content []byte) {
+	var (
+		operands []pdfToken
+		lastY    float64
+		haveY    bool
+	)
+
+	lex := &pdfLexer{data: content}
+	for {
+		tok, ok := lex.next()
+		if !ok {
+			return
+		}
+		if tok.kind != 'o' {
+			operands = append(operands, tok)
+			continue
+		}
+
+		switch tok.text {
+		case "Tj":
+			if s, ok := lastString(operands); ok {
+				w.WriteRaw(s)
+			}
+		case "'", "\"":
+			w.Line()
+			if s, ok := lastString(operands); ok {
+				w.WriteRaw(s)
+			}
+		case "TJ":
+			for _, op := range operands {
+				switch {
+				case op.kind == 's':
+					w.WriteRaw(op.text)
+				case op.kind == 'n' && op.value < tjSpaceThreshold:
+					w.Space()
+				}
+			}
+		case "T*", "ET":
+			w.Line()
+		case "Td", "TD":
+			if len(operands) >= 2 && operands[len(operands)-1].value != 0 {
+				w.Line()
+			} else {
+				w.Space()
+			}
+		case "Tm":
+			if len(operands) >= 6 {
+				y := operands[len(operands)-1].value
+				if haveY && y != lastY {
+					w.Line()
+				}
+				lastY, haveY = y, true
+			}
+		}
+		operands = operands[:0]
+	}
+}
+
+// lastString returns the last operand if it is a string.
+func lastString(operands []pdfToken) (string, bool) {
+	if len(operands) == 0 || operands[len(operands)-1].kind != 's' {
+		return "", false
+	}
+	return operands[len(operands)-1].text, true
+}
+
+// pdfLexer splits a content stream into tokens.
+type pdfLexer struct {
+	data []byte
+	pos  int
+}
+
+// next returns the next token, or false at the end of the stream.
+func (l *pdfLexer) next() (pdfToken, bool) {
+	for l.pos < len(l.data) {
+		c := l.data[l.pos]
+		switch {
+		case isPDFSpace(c):
+			l.pos++
+		case c == '%':
+			for l.pos < len(l.data) && l.data[l.pos] != '\n' && l.data[l.pos] != '\r' {
+				l.pos++
+			}
+		case c == '(':
+			return pdfToken{kind: 's', text: decodePDFString(l.literalString())}, true
+		case c == '<' && l.pos+1 < len(l.data) && l.data[l.pos+1] == '<':
+			l.pos += 2
+			return pdfToken{kind: 'x', text: "<<"}, true
+		case c == '>' && l.pos+1 < len(l.data) && l.data[l.pos+1] == '>':
+			l.pos += 2
+			return pdfToken{kind: 'x', text: ">>"}, true
+		case c == '<':
+			return pdfToken{kind: 's', text: decodePDFString(l.hexString())}, true
+		case c == '[' || c == ']':
+			l.pos++
+			return pdfToken{kind: c}, true
+		case c == '/':
+			l.pos++
+			return pdfToken{kind: 'x', text: "/" + l.word()}, true
+		default:
+			word := l.word()
+			if word == "" {
+				l.pos++ // Stray delimiter
+				continue
+			}
+			if value, err := strconv.ParseFloat(word, 64); err == nil {
+				return pdfToken{kind: 'n', text: word, value: value}, true
+			}
+			return pdfToken{kind: 'o', text: word}, true
+		}
+	}
+	return pdfToken{}, false
+}
+
+// word reads a run of regular characters.
+func (l *pdfLexer) word() string {
+	start := l.pos
+	for l.pos < len(l.data) && !isPDFSpace(l.data[l.pos]) && !isPDFDelimiter(l.data[l.pos]) {
+		l.pos++
+	}
+	return string(l.data[start:l.pos])
+}
+
+// literalString reads a (string) with balanced parentheses and escapes.
+func (l *pdfLexer) literalString() []byte {
+	var out []byte
+	depth := 0
+	for l.pos < len(l.data) {
+		c := l.data[l.pos]
+		l.pos++
+		switch c {
+		case '(':
+			if depth > 0 {
+				out = append(out, c)
+			}
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return out
+			}
+			out = append(out, c)
+		case '\\':
+			out = l.escape(out)
+		default:
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// escape decodes the escape sequence after a backslash.
+func (l *pdfLexer) escape(out []byte) []byte {
+	if l.pos >= len(l.data) {
+		return out
+	}
+	c := l.data[l.pos]
+	l.pos++
+	switch c {
+	case 'n':
+		return append(out, '\n')
+	case 'r':
+		return append(out, '\r')
+	case 't':
+		return append(out, '\t')
+	case 'b':
+		return append(out, '\b')
+	case 'f':
+		return append(out, '\f')
+	case '\r':
+		if l.pos < len(l.data) && l.data[l.pos] == '\n' {
+			l.pos++
+		}
+		return out
+	case '\n':
+		return out
+	}
+	if c >= '0' && c <= '7' {
+		value := int(c - '0')
+		for i := 0; i < 2 && l.pos < len(l.data) && l.data[l.pos] >= '0' && l.data[l.pos] <= '7'; i++ {
+			value = value*8 + int(l.data[l.pos]-'0')
+			l.pos++
+		}
+		return append(out, byte(value))
+	}
+	return append(out, c)
+}
+
+// hexString reads a <hex string>.
+func (l *pdfLexer) hexString() []byte {
+	l.pos++ // '<'
+	var digits []byte
+	for l.pos < len(l.data) && l.data[l.pos] != '>' {
+		if c := l.data[l.pos]; !isPDFSpace(c) {
+			digits = append(digits, c)
+		}
+		l.pos++
+	}
+	l.pos++ // '>'
+	if len(digits)%2 == 1 {
+		digits = append(digits, '0')
+	}
+
+	out := make([]byte, 0, len(digits)/2)
+	for i := 0; i < len(digits); i += 2 {
+		value, err := strconv.ParseUint(string(digits[i:i+2]), 16, 8)
+		if err != nil {
+			return nil
+		}
+		out = append(out, byte(value))
+	}
+	return out
+}
+
+// decodePDFString decodes a UTF-16BE string (with byte order mark) or a
+// single-byte string, dropping control characters.
+func decodePDFString(raw []byte) string {
+	var runes []rune
+	if len(raw) >= 2 && raw[0] == 0xFE && raw[1] == 0xFF {
+		units := make([]uint16, 0, len(raw)/2)
+		for i := 2; i+1 < len(raw); i += 2 {
+			units = append(units, uint16(raw[i])<<8|uint16(raw[i+1]))
+		}
+		runes = utf16.Decode(units)
+	} else {
+		runes = make([]rune, len(raw))
+		for i, b := range raw {
+			runes[i] = rune(b) // PDFDocEncoding matches Latin-1 for printable text
+		}
+	}
+
+	return strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) && r != '\n' && r != '\t' {
+			return -1
+		}
+		return r
+	}, string(runes))
+}
+
+// isPDFSpace reports whether c is PDF whitespace.
+func isPDFSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f' || c == 0
+}
+
+// isPDFDelimiter reports whether c is a PDF delimiter character.
+func isPDFDelimiter(c byte) bool {
+	return strings.IndexByte("()<>[]{}/%", c) >= 0
+}
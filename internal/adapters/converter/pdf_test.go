@@ -0,0 +1,73 @@
+package converter
+
+import (
+	"bytes"
+	"compress/zlib"
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// buildPDF returns a minimal PDF with one content stream per entry of
+// contents, Flate-compressed if compress is set.
+func buildPDF(t *testing.T, compress bool, contents ...string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	buf.WriteString("%PDF-1.4\n")
+	for i, content := range contents {
+		data := []byte(content)
+		filter := ""
+		if compress {
+			var z bytes.Buffer
+			zw := zlib.NewWriter(&z)
+			_, _ = zw.Write(data)
+			_ = zw.Close()
+			data, filter = z.Bytes(), " /Filter /FlateDecode"
+		}
+		fmt.Fprintf(&buf, "%d 0 obj\n<< /Length %d%s >>\nstream\n", i+1, len(data), filter)
+		buf.Write(data)
+		buf.WriteString("\nendstream\nendobj\n")
+	}
+	buf.WriteString("trailer\n<< /Root 1 0 R >>\n%%EOF\n")
+	return buf.Bytes()
+}
+
+func TestPDFConverter_Convert(t *testing.T) {
+	page1 := `BT /F1 12 Tf 72 712 Td (Quarterly \(Q3\) report) Tj 0 -14 Td [(Reve)12(nue)-300(grew)] TJ ET`
+	page2 := `BT /F1 12 Tf 1 0 0 1 72 700 Tm <FEFF00430061006600E9> Tj T* (next\040line) Tj ET`
+
+	for _, compress := range []bool{false, true} {
+		t.Run(fmt.Sprintf("compress=%v", compress), func(t *testing.T) {
+			got, err := NewPDFConverter().Convert(context.Background(), buildPDF(t, compress, page1, page2))
+			if err != nil {
+				t.Fatalf("Convert() error = %v", err)
+			}
+			want := "Quarterly (Q3) report\nRevenue grew\n\nCafé\nnext line"
+			if got != want {
+				t.Errorf("Convert() = %q, want %q", got, want)
+			}
+		})
+	}
+}
+
+func TestPDFConverter_Invalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    []byte
+		wantErr string
+	}{
+		{"not a PDF", []byte("hello"), "not a PDF"},
+		{"no text", buildPDF(t, false, "0 0 m 100 100 l S"), "no extractable text"},
+		{"encrypted", append(buildPDF(t, false, "BT (x) Tj ET"), "/Encrypt 5 0 R"...), "encrypted"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewPDFConverter().Convert(context.Background(), tt.data)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Convert() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,91 @@
+// Package converter provides document converters that turn PDF, DOCX and
+// HTML files into text for workflow input, and a registry to look them up by
+// file extension.
+package converter
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/jbctechsolutions/skillrunner/internal/application/ports"
+	"github.com/jbctechsolutions/skillrunner/internal/domain/errors"
+)
+
+// Registry maps file extensions to document converters.
+type Registry struct {
+	mu         sync.RWMutex
+	converters map[string]ports.DocumentConverterPort // keyed by extension
+}
+
+// NewRegistry creates a new empty converter registry.
+func NewRegistry() *Registry {
+	return &Registry{
+		converters: make(map[string]ports.DocumentConverterPort),
+	}
+}
+
+// NewDefaultRegistry creates a registry with the built-in PDF, DOCX and HTML
+// converters.
+func NewDefaultRegistry() *Registry {
+	r := NewRegistry()
+	for _, c := range []ports.DocumentConverterPort{NewPDFConverter(), NewDOCXConverter(), NewHTMLConverter()} {
+		_ = r.Register(c)
+	}
+	return r
+}
+
+// Register adds a converter for each of its extensions, replacing any
+// converter previously registered for them.
+func (r *Registry) Register(converter ports.DocumentConverterPort) error {
+	if converter == nil {
+		return fmt.Errorf("converter cannot be nil")
+	}
+	if len(converter.Extensions()) == 0 {
+		return fmt.Errorf("converter %s handles no extensions", converter.Name())
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, ext := range converter.Extensions() {
+		r.converters[strings.ToLower(ext)] = converter
+	}
+	return nil
+}
+
+// ForPath returns the converter for the extension of path, or nil if there
+// is none.
+func (r *Registry) ForPath(path string) ports.DocumentConverterPort {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.converters[strings.ToLower(filepath.Ext(path))]
+}
+
+// ConvertFile reads a file and returns its text. Files without a converter
+// are returned as-is if they are UTF-8 text.
+func (r *Registry) ConvertFile(ctx context.Context, path string) (string, error) {
+	data, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return "", fmt.Errorf("failed to read input file: %w", err)
+	}
+
+	converter := r.ForPath(path)
+	if converter == nil {
+		if !utf8.Valid(data) {
+			return "", errors.NewError(errors.CodeValidation,
+				fmt.Sprintf("unsupported input file %s: not a text file and no converter handles %q", path, filepath.Ext(path)), nil)
+		}
+		return string(data), nil
+	}
+
+	text, err := converter.Convert(ctx, data)
+	if err != nil {
+		return "", fmt.Errorf("failed to convert %s: %w", path, err)
+	}
+	return text, nil
+}
@@ -0,0 +1,65 @@
+package converter
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// upperConverter is a test converter for ".up" files.
+type upperConverter struct{}
+
+func (upperConverter) Name() string         { return "upper" }
+func (upperConverter) Extensions() []string { return []string{".UP"} }
+func (upperConverter) Convert(_ context.Context, data []byte) (string, error) {
+	return strings.ToUpper(string(data)), nil
+}
+
+func TestRegistry_ConvertFile(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name string, data []byte) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, data, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	r := NewDefaultRegistry()
+	if err := r.Register(upperConverter{}); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+
+	if got, err := r.ConvertFile(context.Background(), write("notes.up", []byte("shout"))); err != nil || got != "SHOUT" {
+		t.Errorf("custom converter = %q, %v", got, err)
+	}
+	if got, err := r.ConvertFile(context.Background(), write("page.HTML", []byte("<p>Hi</p>"))); err != nil || got != "Hi" {
+		t.Errorf("html converter = %q, %v", got, err)
+	}
+	if got, err := r.ConvertFile(context.Background(), write("notes.md", []byte("# As is\n"))); err != nil || got != "# As is\n" {
+		t.Errorf("text passthrough = %q, %v", got, err)
+	}
+	if _, err := r.ConvertFile(context.Background(), write("blob.bin", []byte{0xff, 0xfe, 0x00})); err == nil {
+		t.Error("expected error for a binary file without a converter")
+	}
+	if _, err := r.ConvertFile(context.Background(), filepath.Join(dir, "missing.pdf")); err == nil {
+		t.Error("expected error for a missing file")
+	}
+}
+
+func TestRegistry_ForPath(t *testing.T) {
+	r := NewDefaultRegistry()
+	for path, want := range map[string]string{"a.pdf": "pdf", "b.DOCX": "docx", "c.htm": "html"} {
+		if c := r.ForPath(path); c == nil || c.Name() != want {
+			t.Errorf("ForPath(%q) = %v, want %s", path, c, want)
+		}
+	}
+	if r.ForPath("d.txt") != nil {
+		t.Error("ForPath(d.txt) should have no converter")
+	}
+	if err := r.Register(nil); err == nil {
+		t.Error("expected error registering nil")
+	}
+}
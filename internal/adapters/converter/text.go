@@ -0,0 +1,76 @@
+package converter
+
+import (
+	"strings"
+	"unicode"
+)
+
+// textWriter accumulates extracted text, collapsing whitespace and keeping at
+// most one blank line between paragraphs.
+type textWriter struct {
+	sb       strings.Builder
+	newlines int  // Trailing newlines written
+	space    bool // A space is pending before the next word
+}
+
+// WriteText appends text with its whitespace collapsed to single spaces.
+func (w *textWriter) WriteText(text string) {
+	if text == "" {
+		return
+	}
+	if unicode.IsSpace(rune(text[0])) {
+		w.space = true
+	}
+
+	for _, word := range strings.Fields(text) {
+		if w.space && w.newlines == 0 && w.sb.Len() > 0 && !strings.HasSuffix(w.sb.String(), " ") {
+			w.sb.WriteByte(' ')
+		}
+		w.sb.WriteString(word)
+		w.newlines = 0
+		w.space = true
+	}
+	w.space = unicode.IsSpace(rune(text[len(text)-1]))
+}
+
+// WriteRaw appends text verbatim.
+func (w *textWriter) WriteRaw(text string) {
+	if text == "" {
+		return
+	}
+	w.sb.WriteString(text)
+	w.newlines = len(text) - len(strings.TrimRight(text, "\n"))
+	w.space = false
+}
+
+// Space appends a single space unless the text is empty or already ends
+// with whitespace.
+func (w *textWriter) Space() {
+	if w.sb.Len() > 0 && w.newlines == 0 && !strings.HasSuffix(w.sb.String(), " ") {
+		w.sb.WriteByte(' ')
+	}
+	w.space = false
+}
+
+// Line ends the current line, if any.
+func (w *textWriter) Line() {
+	if w.sb.Len() > 0 && w.newlines == 0 {
+		w.sb.WriteByte('\n')
+		w.newlines = 1
+	}
+	w.space = false
+}
+
+// Paragraph ends the current paragraph with a blank line, if any.
+func (w *textWriter) Paragraph() {
+	w.Line()
+	if w.sb.Len() > 0 && w.newlines == 1 {
+		w.sb.WriteByte('\n')
+		w.newlines = 2
+	}
+}
+
+// String returns the text without surrounding whitespace.
+func (w *textWriter) String() string {
+	return strings.TrimSpace(w.sb.String())
+}
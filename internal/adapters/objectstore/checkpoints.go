@@ -0,0 +1,389 @@
+package objectstore
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jbctechsolutions/skillrunner/internal/application/ports"
+	domainErrors "github.com/jbctechsolutions/skillrunner/internal/domain/errors"
+	"github.com/jbctechsolutions/skillrunner/internal/domain/workflow"
+)
+
+// Compile-time check that CheckpointRepository implements WorkflowCheckpointPort.
+var _ ports.WorkflowCheckpointPort = (*CheckpointRepository)(nil)
+
+// CheckpointRepository implements WorkflowCheckpointPort with one JSON
+// object per checkpoint under <prefix>checkpoints/ in a bucket.
+//
+// Writes use optimistic locking: an update succeeds only if the object is
+// still at the version this repository last read or wrote, and otherwise
+// fails with ports.ErrCheckpointConflict. Two machines resuming the same
+// checkpoint therefore cannot both claim it.
+//
+// Queries other than Get list and read every checkpoint object, which
+// suits the few hundred checkpoints a team keeps between cleanups.
+type CheckpointRepository struct {
+	client *Client
+	prefix string
+
+	mu       sync.Mutex
+	versions map[string]string // object version by checkpoint ID, as last read or written
+}
+
+// NewCheckpointRepository creates a checkpoint repository storing its
+// objects through client below prefix, such as "skillrunner/".
+func NewCheckpointRepository(client *Client, prefix string) *CheckpointRepository {
+	prefix = strings.Trim(prefix, "/")
+	if prefix != "" {
+		prefix += "/"
+	}
+	return &CheckpointRepository{
+		client:   client,
+		prefix:   prefix + "checkpoints/",
+		versions: make(map[string]string),
+	}
+}
+
+// checkpointDocument is the stored form of a checkpoint.
+type checkpointDocument struct {
+	ID             string                               `json:"id"`
+	ExecutionID    string                               `json:"execution_id"`
+	SkillID        string                               `json:"skill_id"`
+	SkillName      string                               `json:"skill_name"`
+	Input          string                               `json:"input"`
+	InputHash      string                               `json:"input_hash"`
+	CompletedBatch int                                  `json:"completed_batch"`
+	TotalBatches   int                                  `json:"total_batches"`
+	PhaseResults   map[string]*workflow.PhaseResultData `json:"phase_results,omitempty"`
+	PhaseOutputs   map[string]string                    `json:"phase_outputs,omitempty"`
+	Status         string                               `json:"status"`
+	InputTokens    int                                  `json:"input_tokens"`
+	OutputTokens   int                                  `json:"output_tokens"`
+	MachineID      string                               `json:"machine_id,omitempty"`
+	Pinned         bool                                 `json:"pinned,omitempty"`
+	Tags           map[string]string                    `json:"tags,omitempty"`
+	CreatedAt      time.Time                            `json:"created_at"`
+	UpdatedAt      time.Time                            `json:"updated_at"`
+}
+
+// Create persists a new workflow checkpoint, failing if one with the same
+// ID already exists.
+func (r *CheckpointRepository) Create(ctx context.Context, checkpoint *workflow.WorkflowCheckpoint) error {
+	if err := checkpoint.Validate(); err != nil {
+		return err
+	}
+
+	err := r.put(ctx, checkpoint, "")
+	if errors.Is(err, ErrPreconditionFailed) {
+		return domainErrors.NewError(domainErrors.CodeValidation, "workflow checkpoint already exists", err)
+	}
+	return err
+}
+
+// Get retrieves a checkpoint by its unique identifier.
+func (r *CheckpointRepository) Get(ctx context.Context, id string) (*workflow.WorkflowCheckpoint, error) {
+	checkpoint, err := r.load(ctx, r.key(id))
+	if errors.Is(err, ErrNotFound) {
+		return nil, domainErrors.NewError(domainErrors.CodeNotFound, fmt.Sprintf("workflow checkpoint not found: %s", id), nil)
+	}
+	return checkpoint, err
+}
+
+// GetLatestInProgress retrieves the most recent in-progress checkpoint for a skill/input combination.
+func (r *CheckpointRepository) GetLatestInProgress(ctx context.Context, skillID string, inputHash string) (*workflow.WorkflowCheckpoint, error) {
+	checkpoints, err := r.List(ctx, &ports.WorkflowCheckpointFilter{
+		SkillID: skillID,
+		Status:  []workflow.CheckpointStatus{workflow.CheckpointStatusInProgress},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get latest in-progress checkpoint: %w", err)
+	}
+	for _, checkpoint := range checkpoints {
+		if checkpoint.InputHash() == inputHash {
+			return checkpoint, nil
+		}
+	}
+	return nil, nil
+}
+
+// GetByExecutionID retrieves all checkpoints for an execution.
+func (r *CheckpointRepository) GetByExecutionID(ctx context.Context, executionID string) ([]*workflow.WorkflowCheckpoint, error) {
+	return r.List(ctx, &ports.WorkflowCheckpointFilter{ExecutionID: executionID})
+}
+
+// Update persists changes to an existing checkpoint, provided nobody else
+// has changed it since this repository last read or wrote it.
+func (r *CheckpointRepository) Update(ctx context.Context, checkpoint *workflow.WorkflowCheckpoint) error {
+	if err := checkpoint.Validate(); err != nil {
+		return err
+	}
+
+	// A checkpoint this repository has not seen yet is updated from the
+	// version stored now
+	version, err := r.version(ctx, checkpoint.ID())
+	if err != nil {
+		return err
+	}
+
+	err = r.put(ctx, checkpoint, version)
+	if errors.Is(err, ErrPreconditionFailed) {
+		return fmt.Errorf("%w: %s", ports.ErrCheckpointConflict, checkpoint.ID())
+	}
+	return err
+}
+
+// List returns all checkpoints matching the optional filter criteria.
+func (r *CheckpointRepository) List(ctx context.Context, filter *ports.WorkflowCheckpointFilter) ([]*workflow.WorkflowCheckpoint, error) {
+	keys, err := r.client.List(ctx, r.prefix)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list workflow checkpoints: %w", err)
+	}
+
+	checkpoints := []*workflow.WorkflowCheckpoint{}
+	for _, key := range keys {
+		if !strings.HasSuffix(key, ".json") {
+			continue
+		}
+		checkpoint, err := r.load(ctx, key)
+		if errors.Is(err, ErrNotFound) {
+			continue // deleted since it was listed
+		}
+		if err != nil {
+			return nil, err
+		}
+		if matches(checkpoint, filter) {
+			checkpoints = append(checkpoints, checkpoint)
+		}
+	}
+
+	slices.SortStableFunc(checkpoints, func(a, b *workflow.WorkflowCheckpoint) int {
+		return b.UpdatedAt().Compare(a.UpdatedAt())
+	})
+	if filter != nil {
+		checkpoints = checkpoints[min(filter.Offset, len(checkpoints)):]
+		if filter.Limit > 0 && filter.Limit < len(checkpoints) {
+			checkpoints = checkpoints[:filter.Limit]
+		}
+	}
+	return checkpoints, nil
+}
+
+// Delete removes a checkpoint from storage.
+func (r *CheckpointRepository) Delete(ctx context.Context, id string) error {
+	if _, err := r.Get(ctx, id); err != nil {
+		return err
+	}
+	return r.delete(ctx, id)
+}
+
+// DeleteByExecutionID removes all checkpoints for an execution, except pinned ones.
+func (r *CheckpointRepository) DeleteByExecutionID(ctx context.Context, executionID string) (int, error) {
+	checkpoints, err := r.GetByExecutionID(ctx, executionID)
+	if err != nil {
+		return 0, err
+	}
+	return r.deleteUnpinned(ctx, checkpoints)
+}
+
+// MarkAbandoned marks all in-progress checkpoints for a machine as
+// abandoned. Checkpoints that another machine claims meanwhile are left to
+// it.
+func (r *CheckpointRepository) MarkAbandoned(ctx context.Context, machineID string) (int, error) {
+	checkpoints, err := r.List(ctx, &ports.WorkflowCheckpointFilter{
+		MachineID: machineID,
+		Status:    []workflow.CheckpointStatus{workflow.CheckpointStatusInProgress},
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to mark checkpoints as abandoned: %w", err)
+	}
+
+	marked := 0
+	for _, checkpoint := range checkpoints {
+		checkpoint.MarkAbandoned()
+		err := r.Update(ctx, checkpoint)
+		if errors.Is(err, ports.ErrCheckpointConflict) {
+			continue
+		}
+		if err != nil {
+			return marked, fmt.Errorf("failed to mark checkpoints as abandoned: %w", err)
+		}
+		marked++
+	}
+	return marked, nil
+}
+
+// SetPinned pins or unpins a checkpoint.
+func (r *CheckpointRepository) SetPinned(ctx context.Context, id string, pinned bool) error {
+	checkpoint, err := r.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+	checkpoint.SetPinned(pinned)
+	if err := r.Update(ctx, checkpoint); err != nil {
+		return fmt.Errorf("failed to pin workflow checkpoint: %w", err)
+	}
+	return nil
+}
+
+// Cleanup removes checkpoints older than the specified duration, except pinned ones.
+func (r *CheckpointRepository) Cleanup(ctx context.Context, olderThan time.Duration) (int, error) {
+	checkpoints, err := r.List(ctx, &ports.WorkflowCheckpointFilter{
+		CreatedBefore: time.Now().Add(-olderThan),
+		Status: []workflow.CheckpointStatus{
+			workflow.CheckpointStatusCompleted,
+			workflow.CheckpointStatusFailed,
+			workflow.CheckpointStatusAbandoned,
+		},
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to cleanup old checkpoints: %w", err)
+	}
+	return r.deleteUnpinned(ctx, checkpoints)
+}
+
+// deleteUnpinned deletes the checkpoints that are not pinned, returning how
+// many it deleted.
+func (r *CheckpointRepository) deleteUnpinned(ctx context.Context, checkpoints []*workflow.WorkflowCheckpoint) (int, error) {
+	deleted := 0
+	for _, checkpoint := range checkpoints {
+		if checkpoint.Pinned() {
+			continue
+		}
+		if err := r.delete(ctx, checkpoint.ID()); err != nil {
+			return deleted, err
+		}
+		deleted++
+	}
+	return deleted, nil
+}
+
+func (r *CheckpointRepository) delete(ctx context.Context, id string) error {
+	if err := r.client.Delete(ctx, r.key(id)); err != nil {
+		return fmt.Errorf("failed to delete workflow checkpoint: %w", err)
+	}
+	r.mu.Lock()
+	delete(r.versions, id)
+	r.mu.Unlock()
+	return nil
+}
+
+// key returns the object key of a checkpoint.
+func (r *CheckpointRepository) key(id string) string {
+	return r.prefix + id + ".json"
+}
+
+// version returns the object version the next write of a checkpoint is
+// conditioned on.
+func (r *CheckpointRepository) version(ctx context.Context, id string) (string, error) {
+	r.mu.Lock()
+	version, ok := r.versions[id]
+	r.mu.Unlock()
+	if ok {
+		return version, nil
+	}
+
+	obj, err := r.client.Get(ctx, r.key(id))
+	if errors.Is(err, ErrNotFound) {
+		return "", domainErrors.NewError(domainErrors.CodeNotFound, fmt.Sprintf("workflow checkpoint not found: %s", id), nil)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get workflow checkpoint: %w", err)
+	}
+	return obj.Version, nil
+}
+
+// put writes a checkpoint if its object is still at version, or does not
+// exist if version is empty, and remembers the new version.
+func (r *CheckpointRepository) put(ctx context.Context, checkpoint *workflow.WorkflowCheckpoint, version string) error {
+	body, err := json.Marshal(checkpointDocument{
+		ID:             checkpoint.ID(),
+		ExecutionID:    checkpoint.ExecutionID(),
+		SkillID:        checkpoint.SkillID(),
+		SkillName:      checkpoint.SkillName(),
+		Input:          checkpoint.Input(),
+		InputHash:      checkpoint.InputHash(),
+		CompletedBatch: checkpoint.CompletedBatch(),
+		TotalBatches:   checkpoint.TotalBatches(),
+		PhaseResults:   checkpoint.PhaseResults(),
+		PhaseOutputs:   checkpoint.PhaseOutputs(),
+		Status:         string(checkpoint.Status()),
+		InputTokens:    checkpoint.InputTokens(),
+		OutputTokens:   checkpoint.OutputTokens(),
+		MachineID:      checkpoint.MachineID(),
+		Pinned:         checkpoint.Pinned(),
+		Tags:           checkpoint.Tags(),
+		CreatedAt:      checkpoint.CreatedAt(),
+		UpdatedAt:      checkpoint.UpdatedAt(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal workflow checkpoint: %w", err)
+	}
+
+	newVersion, err := r.client.Put(ctx, r.key(checkpoint.ID()), body, version)
+	if err != nil {
+		if errors.Is(err, ErrPreconditionFailed) {
+			return err
+		}
+		return fmt.Errorf("failed to write workflow checkpoint: %w", err)
+	}
+
+	r.mu.Lock()
+	r.versions[checkpoint.ID()] = newVersion
+	r.mu.Unlock()
+	return nil
+}
+
+// load reads the checkpoint stored under key and remembers its version.
+func (r *CheckpointRepository) load(ctx context.Context, key string) (*workflow.WorkflowCheckpoint, error) {
+	obj, err := r.client.Get(ctx, key)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to get workflow checkpoint: %w", err)
+	}
+
+	var doc checkpointDocument
+	if err := json.Unmarshal(obj.Body, &doc); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal workflow checkpoint %s: %w", key, err)
+	}
+	if !workflow.IsValidStatus(doc.Status) {
+		return nil, fmt.Errorf("invalid checkpoint status: %s", doc.Status)
+	}
+
+	checkpoint := workflow.ReconstructCheckpoint(
+		doc.ID, doc.ExecutionID, doc.SkillID, doc.SkillName, doc.Input, doc.InputHash,
+		doc.CompletedBatch, doc.TotalBatches,
+		doc.PhaseResults, doc.PhaseOutputs,
+		workflow.CheckpointStatus(doc.Status),
+		doc.InputTokens, doc.OutputTokens,
+		doc.MachineID,
+		doc.CreatedAt, doc.UpdatedAt,
+	)
+	checkpoint.SetPinned(doc.Pinned)
+	checkpoint.SetTags(doc.Tags)
+
+	r.mu.Lock()
+	r.versions[doc.ID] = obj.Version
+	r.mu.Unlock()
+	return checkpoint, nil
+}
+
+// matches reports whether a checkpoint passes filter.
+func matches(checkpoint *workflow.WorkflowCheckpoint, filter *ports.WorkflowCheckpointFilter) bool {
+	if filter == nil {
+		return true
+	}
+	return (filter.SkillID == "" || checkpoint.SkillID() == filter.SkillID) &&
+		(filter.ExecutionID == "" || checkpoint.ExecutionID() == filter.ExecutionID) &&
+		(filter.MachineID == "" || checkpoint.MachineID() == filter.MachineID) &&
+		(len(filter.Status) == 0 || slices.Contains(filter.Status, checkpoint.Status())) &&
+		(filter.CreatedAfter.IsZero() || !checkpoint.CreatedAt().Before(filter.CreatedAfter)) &&
+		(filter.CreatedBefore.IsZero() || checkpoint.CreatedAt().Before(filter.CreatedBefore))
+}
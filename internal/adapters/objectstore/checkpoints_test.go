@@ -0,0 +1,180 @@
+package objectstore
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jbctechsolutions/skillrunner/internal/application/ports"
+	domainErrors "github.com/jbctechsolutions/skillrunner/internal/domain/errors"
+	"github.com/jbctechsolutions/skillrunner/internal/domain/workflow"
+)
+
+func newTestCheckpoint(t *testing.T, id, executionID, input string) *workflow.WorkflowCheckpoint {
+	t.Helper()
+	cp, err := workflow.NewWorkflowCheckpoint(id, executionID, "skill-1", "Skill One", input, 3)
+	if err != nil {
+		t.Fatalf("NewWorkflowCheckpoint() error = %v", err)
+	}
+	cp.SetMachineID("machine-a")
+	return cp
+}
+
+// errorCode returns the code of a domain error, or "" for other errors.
+func errorCode(err error) domainErrors.ErrorCode {
+	var e *domainErrors.SkillrunnerError
+	if errors.As(err, &e) {
+		return e.Code
+	}
+	return ""
+}
+
+func TestCheckpointRepository_RoundTrip(t *testing.T) {
+	client, store := newTestClient(t, false)
+	repo := NewCheckpointRepository(client, "/team/")
+	ctx := context.Background()
+
+	cp := newTestCheckpoint(t, "cp-1", "exec-1", "input")
+	cp.SetTags(map[string]string{"team": "search"})
+	cp.AddPhaseOutput("_input", "input")
+	if err := repo.Create(ctx, cp); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if _, ok := store.objects["team/checkpoints/cp-1.json"]; !ok {
+		t.Fatalf("objects = %v, want team/checkpoints/cp-1.json", store.objects)
+	}
+	if err := repo.Create(ctx, cp); errorCode(err) != domainErrors.CodeValidation {
+		t.Errorf("Create() of an existing checkpoint error = %v, want a validation error", err)
+	}
+
+	_ = cp.UpdateBatch(0)
+	cp.AddPhaseResult("p1", &workflow.PhaseResultData{PhaseID: "p1", Status: "completed", Output: "out"})
+	cp.AddPhaseOutput("p1", "out")
+	cp.UpdateTokens(10, 20)
+	if err := repo.Update(ctx, cp); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+
+	// A second process reads the checkpoint as it was written
+	got, err := NewCheckpointRepository(client, "team").Get(ctx, "cp-1")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if got.CompletedBatch() != 0 || got.PhaseOutputs()["p1"] != "out" || got.PhaseResults()["p1"].Output != "out" ||
+		got.TotalTokens() != 30 || got.MachineID() != "machine-a" || got.Tags()["team"] != "search" ||
+		!got.CreatedAt().Equal(cp.CreatedAt()) {
+		t.Errorf("Get() = %+v, want the updated checkpoint", got)
+	}
+
+	if _, err := repo.Get(ctx, "missing"); errorCode(err) != domainErrors.CodeNotFound {
+		t.Errorf("Get() of a missing checkpoint error = %v, want not found", err)
+	}
+}
+
+func TestCheckpointRepository_Queries(t *testing.T) {
+	client, _ := newTestClient(t, true)
+	repo := NewCheckpointRepository(client, "")
+	ctx := context.Background()
+
+	inProgress := newTestCheckpoint(t, "cp-1", "exec-1", "first")
+	completed := newTestCheckpoint(t, "cp-2", "exec-1", "second")
+	completed.MarkCompleted()
+	other := newTestCheckpoint(t, "cp-3", "exec-2", "first")
+	other.SetMachineID("machine-b")
+	for _, cp := range []*workflow.WorkflowCheckpoint{inProgress, completed, other} {
+		if err := repo.Create(ctx, cp); err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+	}
+
+	latest, err := repo.GetLatestInProgress(ctx, "skill-1", workflow.HashInput("first"))
+	if err != nil || latest == nil || latest.ID() != "cp-3" {
+		t.Errorf("GetLatestInProgress() = %v, %v; want cp-3", latest, err)
+	}
+	if latest, err := repo.GetLatestInProgress(ctx, "skill-1", workflow.HashInput("other")); err != nil || latest != nil {
+		t.Errorf("GetLatestInProgress() for another input = %v, %v; want nil", latest, err)
+	}
+
+	byExecution, err := repo.GetByExecutionID(ctx, "exec-1")
+	if err != nil || len(byExecution) != 2 {
+		t.Errorf("GetByExecutionID() = %d checkpoints, %v; want 2", len(byExecution), err)
+	}
+	limited, err := repo.List(ctx, &ports.WorkflowCheckpointFilter{Limit: 1, Offset: 1})
+	if err != nil || len(limited) != 1 || limited[0].ID() != "cp-2" {
+		t.Errorf("List() with limit and offset = %v, %v; want cp-2", limited, err)
+	}
+
+	abandoned, err := repo.MarkAbandoned(ctx, "machine-a")
+	if err != nil || abandoned != 1 {
+		t.Errorf("MarkAbandoned() = %d, %v; want 1", abandoned, err)
+	}
+
+	if err := repo.SetPinned(ctx, "cp-2", true); err != nil {
+		t.Fatalf("SetPinned() error = %v", err)
+	}
+	removed, err := repo.Cleanup(ctx, -time.Hour)
+	if err != nil || removed != 1 {
+		t.Errorf("Cleanup() = %d, %v; want the abandoned checkpoint removed", removed, err)
+	}
+	deleted, err := repo.DeleteByExecutionID(ctx, "exec-1")
+	if err != nil || deleted != 0 {
+		t.Errorf("DeleteByExecutionID() = %d, %v; want the pinned checkpoint kept", deleted, err)
+	}
+
+	if err := repo.Delete(ctx, "cp-3"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if err := repo.Delete(ctx, "cp-3"); errorCode(err) != domainErrors.CodeNotFound {
+		t.Errorf("Delete() of a missing checkpoint error = %v, want not found", err)
+	}
+	remaining, _ := repo.List(ctx, nil)
+	if len(remaining) != 1 || remaining[0].ID() != "cp-2" {
+		t.Errorf("List() = %v, want only the pinned cp-2", remaining)
+	}
+}
+
+func TestCheckpointRepository_OptimisticLocking(t *testing.T) {
+	for _, gcs := range []bool{false, true} {
+		t.Run(map[bool]string{false: "s3", true: "gcs"}[gcs], func(t *testing.T) {
+			client, _ := newTestClient(t, gcs)
+			ctx := context.Background()
+
+			// The checkpoint of a run interrupted on machine A
+			cp := newTestCheckpoint(t, "cp-1", "exec-1", "input")
+			if err := NewCheckpointRepository(client, "").Create(ctx, cp); err != nil {
+				t.Fatalf("Create() error = %v", err)
+			}
+
+			// Machines B and C both find it and try to claim it
+			b, c := NewCheckpointRepository(client, ""), NewCheckpointRepository(client, "")
+			cpB, err := b.GetLatestInProgress(ctx, "skill-1", workflow.HashInput("input"))
+			if err != nil || cpB == nil {
+				t.Fatalf("GetLatestInProgress() on B = %v, %v", cpB, err)
+			}
+			cpC, err := c.GetLatestInProgress(ctx, "skill-1", workflow.HashInput("input"))
+			if err != nil || cpC == nil {
+				t.Fatalf("GetLatestInProgress() on C = %v, %v", cpC, err)
+			}
+
+			cpB.SetMachineID("machine-b")
+			if err := b.Update(ctx, cpB); err != nil {
+				t.Fatalf("Update() claiming on B error = %v", err)
+			}
+			cpC.SetMachineID("machine-c")
+			if err := c.Update(ctx, cpC); !errors.Is(err, ports.ErrCheckpointConflict) {
+				t.Errorf("Update() claiming on C error = %v, want a checkpoint conflict", err)
+			}
+
+			// B keeps writing; C has to read the checkpoint again first
+			_ = cpB.UpdateBatch(0)
+			if err := b.Update(ctx, cpB); err != nil {
+				t.Errorf("Update() on B after its claim error = %v", err)
+			}
+			got, err := c.Get(ctx, "cp-1")
+			if err != nil || got.MachineID() != "machine-b" || got.CompletedBatch() != 0 {
+				t.Errorf("Get() on C = %+v, %v; want B's checkpoint", got, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,293 @@
+// Package objectstore keeps workflow checkpoints in an object storage bucket
+// (Amazon S3, Google Cloud Storage or another S3-compatible store), so that
+// a run started on one machine can be resumed on another.
+package objectstore
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Supported object stores.
+const (
+	ProviderS3  = "s3"
+	ProviderGCS = "gcs"
+)
+
+// Default endpoints and region.
+const (
+	DefaultGCSEndpoint = "https://storage.googleapis.com"
+	DefaultS3Region    = "us-east-1"
+	defaultTimeout     = 30 * time.Second
+)
+
+var (
+	// ErrNotFound is returned for an object that does not exist.
+	ErrNotFound = errors.New("object not found")
+
+	// ErrPreconditionFailed is returned by Put when the object has changed
+	// since the version it was conditioned on.
+	ErrPreconditionFailed = errors.New("object changed since it was read")
+)
+
+// Options configures a Client.
+type Options struct {
+	Provider string // s3 (default) or gcs
+	Bucket   string
+	Region   string // s3 region; defaults to us-east-1
+
+	// Endpoint overrides the store's URL, such as for MinIO or another
+	// S3-compatible store. Buckets are then addressed by path.
+	Endpoint string
+
+	// Credentials: an access key pair, or for gcs an HMAC key.
+	AccessKey    string
+	SecretKey    string
+	SessionToken string
+
+	HTTPClient *http.Client // defaults to a client with a 30s timeout
+}
+
+// Client reads and writes the objects of one bucket over the S3 API, which
+// Cloud Storage also serves for HMAC keys. Writes are conditional so that
+// concurrent writers cannot overwrite each other's changes unknowingly.
+type Client struct {
+	provider   string
+	bucket     string
+	baseURL    *url.URL // bucket URL; objects are below it
+	signer     *signer
+	httpClient *http.Client
+}
+
+// Object is the content and version of a stored object.
+type Object struct {
+	Body []byte
+	// Version identifies the object's content: its ETag on S3 and its
+	// generation on Cloud Storage.
+	Version string
+}
+
+// NewClient creates a client for the bucket described by opts.
+func NewClient(opts Options) (*Client, error) {
+	provider := opts.Provider
+	if provider == "" {
+		provider = ProviderS3
+	}
+	if provider != ProviderS3 && provider != ProviderGCS {
+		return nil, fmt.Errorf("unsupported object store %q: must be s3 or gcs", provider)
+	}
+	if opts.Bucket == "" {
+		return nil, errors.New("object store bucket is required")
+	}
+	if opts.AccessKey == "" || opts.SecretKey == "" {
+		return nil, errors.New("object store credentials are required")
+	}
+
+	region := opts.Region
+	switch {
+	case provider == ProviderGCS:
+		region = "auto"
+	case region == "":
+		region = DefaultS3Region
+	}
+
+	// Buckets are addressed by path on custom endpoints and Cloud Storage,
+	// and by virtual host on AWS
+	endpoint := opts.Endpoint
+	pathStyle := true
+	switch {
+	case endpoint != "":
+	case provider == ProviderGCS:
+		endpoint = DefaultGCSEndpoint
+	default:
+		endpoint = fmt.Sprintf("https://%s.s3.%s.amazonaws.com", opts.Bucket, region)
+		pathStyle = false
+	}
+	base, err := url.Parse(strings.TrimSuffix(endpoint, "/"))
+	if err != nil || base.Host == "" {
+		return nil, fmt.Errorf("invalid object store endpoint %q", endpoint)
+	}
+	if pathStyle {
+		base.Path += "/" + opts.Bucket
+	}
+
+	httpClient := opts.HTTPClient
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: defaultTimeout}
+	}
+
+	return &Client{
+		provider:   provider,
+		bucket:     opts.Bucket,
+		baseURL:    base,
+		signer:     &signer{accessKey: opts.AccessKey, secretKey: opts.SecretKey, sessionToken: opts.SessionToken, region: region, now: time.Now},
+		httpClient: httpClient,
+	}, nil
+}
+
+// Get returns the object stored under key, or ErrNotFound.
+func (c *Client) Get(ctx context.Context, key string) (*Object, error) {
+	resp, err := c.do(ctx, http.MethodGet, key, nil, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, key)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, responseError("get", key, resp)
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read object %s: %w", key, err)
+	}
+	return &Object{Body: body, Version: c.version(resp.Header)}, nil
+}
+
+// Put stores body under key if the object is still at ifVersion, or if
+// ifVersion is empty, does not exist yet. It returns the new version, or
+// ErrPreconditionFailed when another writer got there first.
+func (c *Client) Put(ctx context.Context, key string, body []byte, ifVersion string) (string, error) {
+	header := http.Header{"Content-Type": {"application/json"}}
+	switch {
+	case c.provider == ProviderGCS && ifVersion == "":
+		header.Set("X-Goog-If-Generation-Match", "0")
+	case c.provider == ProviderGCS:
+		header.Set("X-Goog-If-Generation-Match", ifVersion)
+	case ifVersion == "":
+		header.Set("If-None-Match", "*")
+	default:
+		header.Set("If-Match", ifVersion)
+	}
+
+	resp, err := c.do(ctx, http.MethodPut, key, nil, header, body)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return c.version(resp.Header), nil
+	case http.StatusPreconditionFailed, http.StatusConflict:
+		// S3 answers 409 when a concurrent conditional write is in flight
+		return "", fmt.Errorf("%w: %s", ErrPreconditionFailed, key)
+	default:
+		return "", responseError("put", key, resp)
+	}
+}
+
+// Delete removes the object stored under key. Deleting a missing object
+// succeeds.
+func (c *Client) Delete(ctx context.Context, key string) error {
+	resp, err := c.do(ctx, http.MethodDelete, key, nil, nil, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNotFound {
+		return responseError("delete", key, resp)
+	}
+	return nil
+}
+
+// List returns the keys of the objects whose keys start with prefix.
+func (c *Client) List(ctx context.Context, prefix string) ([]string, error) {
+	var keys []string
+	token := ""
+	for {
+		query := url.Values{"list-type": {"2"}, "prefix": {prefix}}
+		if token != "" {
+			query.Set("continuation-token", token)
+		}
+		resp, err := c.do(ctx, http.MethodGet, "", query, nil, nil)
+		if err != nil {
+			return nil, err
+		}
+		var page struct {
+			Contents []struct {
+				Key string `xml:"Key"`
+			} `xml:"Contents"`
+			IsTruncated           bool   `xml:"IsTruncated"`
+			NextContinuationToken string `xml:"NextContinuationToken"`
+		}
+		if resp.StatusCode != http.StatusOK {
+			err = responseError("list", prefix, resp)
+		} else if decodeErr := xml.NewDecoder(resp.Body).Decode(&page); decodeErr != nil {
+			err = fmt.Errorf("failed to decode object listing: %w", decodeErr)
+		}
+		resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+
+		for _, obj := range page.Contents {
+			keys = append(keys, obj.Key)
+		}
+		if !page.IsTruncated || page.NextContinuationToken == "" {
+			return keys, nil
+		}
+		token = page.NextContinuationToken
+	}
+}
+
+// do sends a signed request for the object at key, or for the bucket if key
+// is empty.
+func (c *Client) do(ctx context.Context, method, key string, query url.Values, header http.Header, body []byte) (*http.Response, error) {
+	u := *c.baseURL
+	if key != "" {
+		u.Path += "/" + key
+	} else {
+		u.Path += "/"
+	}
+	u.RawPath = escapePath(u.Path)
+	u.RawQuery = canonicalQuery(query)
+
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create object store request: %w", err)
+	}
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	c.signer.sign(req, body)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("object store request failed: %w", err)
+	}
+	return resp, nil
+}
+
+// version returns the version of the object a response describes.
+func (c *Client) version(header http.Header) string {
+	if c.provider == ProviderGCS {
+		return header.Get("X-Goog-Generation")
+	}
+	return header.Get("ETag")
+}
+
+// responseError describes an unexpected response, including the store's
+// error code when it sent one.
+func responseError(op, key string, resp *http.Response) error {
+	var body struct {
+		Code    string `xml:"Code"`
+		Message string `xml:"Message"`
+	}
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+	if xml.Unmarshal(data, &body) == nil && body.Code != "" {
+		return fmt.Errorf("object store %s %s failed: %s: %s: %s", op, key, resp.Status, body.Code, body.Message)
+	}
+	return fmt.Errorf("object store %s %s failed: %s", op, key, resp.Status)
+}
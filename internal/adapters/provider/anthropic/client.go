@@ -24,7 +24,8 @@ type Client struct {
 func NewClient(config Config) *Client {
 	return &Client{
 		httpClient: &http.Client{
-			Timeout: config.Timeout,
+			Timeout:   config.Timeout,
+			Transport: config.Transport,
 		},
 		config: config,
 	}
@@ -61,6 +62,32 @@ func (c *Client) SendMessage(ctx context.Context, req *MessagesRequest) (*Messag
 	return &result, nil
 }
 
+// CountTokens returns the number of input tokens of a messages request
+// without sending it to the model.
+func (c *Client) CountTokens(ctx context.Context, req *CountTokensRequest) (*CountTokensResponse, error) {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, errors.NewError(errors.CodeProvider, "failed to marshal request", err)
+	}
+
+	resp, err := c.doRequestWithRetry(ctx, http.MethodPost, "/messages/count_tokens", body, "")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, c.handleErrorResponse(resp)
+	}
+
+	var result CountTokensResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, errors.NewError(errors.CodeProvider, "failed to decode response", err)
+	}
+
+	return &result, nil
+}
+
 // hasDeferredLoadingTools checks if any tool has deferred loading enabled.
 func hasDeferredLoadingTools(tools []Tool) bool {
 	for _, tool := range tools {
@@ -241,6 +268,24 @@ func (c *Client) handleErrorResponse(resp *http.Response) error {
 		fmt.Sprintf("%s: %s", errResp.Error.Type, errResp.Error.Message), nil)
 }
 
+// Connect opens a connection to the API, including the TLS handshake, so
+// that the next request can reuse it. The response status is not checked.
+func (c *Client) Connect(ctx context.Context) error {
+	req, err := c.newRequest(ctx, http.MethodGet, "/models", nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return errors.NewError(errors.CodeProvider, "failed to connect", err)
+	}
+	defer resp.Body.Close()
+
+	_, err = io.Copy(io.Discard, resp.Body)
+	return err
+}
+
 // HealthCheck performs a lightweight check to verify API connectivity.
 func (c *Client) HealthCheck(ctx context.Context) error {
 	// Send a minimal request to verify connectivity
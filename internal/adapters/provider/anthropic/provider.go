@@ -2,6 +2,7 @@ package anthropic
 
 import (
 	"context"
+	"encoding/json"
 	"slices"
 	"strings"
 	"time"
@@ -15,8 +16,12 @@ type Provider struct {
 	config Config
 }
 
-// Ensure Provider implements ProviderPort at compile time.
-var _ ports.ProviderPort = (*Provider)(nil)
+// Ensure Provider implements ProviderPort, TokenCounterPort and Warmer at compile time.
+var (
+	_ ports.ProviderPort     = (*Provider)(nil)
+	_ ports.TokenCounterPort = (*Provider)(nil)
+	_ ports.Warmer           = (*Provider)(nil)
+)
 
 // NewProvider creates a new Anthropic provider with the given configuration.
 func NewProvider(config Config) *Provider {
@@ -91,6 +96,8 @@ func (p *Provider) Stream(ctx context.Context, req ports.CompletionRequest, cb p
 	var inputTokens, outputTokens int
 	var finishReason string
 	var modelUsed string
+	var toolCalls []ports.ToolCall
+	toolInputs := make(map[int]*toolInput) // Content block index -> tool_use being streamed
 
 	err := p.client.StreamMessage(ctx, anthropicReq, func(event *StreamEvent) error {
 		switch event.Type {
@@ -101,7 +108,24 @@ func (p *Provider) Stream(ctx context.Context, req ports.CompletionRequest, cb p
 					inputTokens = event.Message.Usage.InputTokens
 				}
 			}
+		case EventContentBlockStart:
+			if event.ContentBlock != nil && event.ContentBlock.Type == string(ContentTypeToolUse) && event.Index != nil {
+				toolInputs[*event.Index] = &toolInput{call: len(toolCalls)}
+				toolCalls = append(toolCalls, ports.ToolCall{ID: event.ContentBlock.ID, Name: event.ContentBlock.Name})
+			}
+		case EventContentBlockStop:
+			if event.Index != nil {
+				if input, ok := toolInputs[*event.Index]; ok {
+					toolCalls[input.call].Arguments = toolArguments(json.RawMessage(input.json.String()))
+					delete(toolInputs, *event.Index)
+				}
+			}
 		case EventContentBlockDelta:
+			if event.Delta != nil && event.Delta.PartialJSON != "" && event.Index != nil {
+				if input, ok := toolInputs[*event.Index]; ok {
+					input.json.WriteString(event.Delta.PartialJSON)
+				}
+			}
 			if event.Delta != nil && event.Delta.Text != "" {
 				fullContent.WriteString(event.Delta.Text)
 				if err := cb(event.Delta.Text); err != nil {
@@ -129,9 +153,16 @@ func (p *Provider) Stream(ctx context.Context, req ports.CompletionRequest, cb p
 		FinishReason: finishReason,
 		ModelUsed:    modelUsed,
 		Duration:     time.Since(startTime),
+		ToolCalls:    toolCalls,
 	}, nil
 }
 
+// Warm opens the connection to the API so the first request skips the
+// TLS handshake. It sends no message.
+func (p *Provider) Warm(ctx context.Context, _ string) error {
+	return p.client.Connect(ctx)
+}
+
 // HealthCheck verifies the provider is healthy and responsive.
 func (p *Provider) HealthCheck(ctx context.Context, modelID string) (*ports.HealthStatus, error) {
 	startTime := time.Now()
@@ -165,26 +196,67 @@ func (p *Provider) HealthCheck(ctx context.Context, modelID string) (*ports.Heal
 	}, nil
 }
 
+// CountTokens counts the input tokens of req with Anthropic's token
+// counting API.
+func (p *Provider) CountTokens(ctx context.Context, req ports.CompletionRequest) (int, error) {
+	built := p.buildRequest(req)
+	resp, err := p.client.CountTokens(ctx, &CountTokensRequest{
+		Model:      built.Model,
+		Messages:   built.Messages,
+		System:     built.System,
+		Tools:      built.Tools,
+		ToolChoice: built.ToolChoice,
+	})
+	if err != nil {
+		return 0, err
+	}
+	return resp.InputTokens, nil
+}
+
 // buildRequest converts a ports.CompletionRequest to an Anthropic MessagesRequest.
 func (p *Provider) buildRequest(req ports.CompletionRequest) *MessagesRequest {
 	messages := make([]Message, 0, len(req.Messages))
 	for _, msg := range req.Messages {
-		// Skip system messages as they go in the system field
-		if msg.Role == "system" {
+		switch msg.Role {
+		case "system":
+			// Skip system messages as they go in the system field
+			continue
+		case "tool":
+			// Tool results are sent as user content; consecutive results
+			// share one user message.
+			block := ContentBlock{Type: string(ContentTypeToolResult), ToolUseID: msg.ToolCallID, Content: msg.Content}
+			if n := len(messages); n > 0 && messages[n-1].Role == RoleUser && isToolResults(messages[n-1].Content) {
+				messages[n-1].Content = append(messages[n-1].Content, block)
+				continue
+			}
+			messages = append(messages, Message{Role: RoleUser, Content: MessageContent{block}})
 			continue
 		}
+
+		var content MessageContent
+		if msg.Content != "" || len(msg.ToolCalls) == 0 {
+			content = append(content, ContentBlock{Type: "text", Text: msg.Content})
+		}
+		for _, call := range msg.ToolCalls {
+			content = append(content, ContentBlock{
+				Type:  string(ContentTypeToolUse),
+				ID:    call.ID,
+				Name:  call.Name,
+				Input: toolArguments(call.Arguments),
+			})
+		}
 		messages = append(messages, Message{
-			Role: MessageRole(msg.Role),
-			Content: MessageContent{
-				{Type: "text", Text: msg.Content},
-			},
+			Role:    MessageRole(msg.Role),
+			Content: content,
 		})
 	}
 
 	anthropicReq := &MessagesRequest{
-		Model:     req.ModelID,
-		MaxTokens: req.MaxTokens,
-		Messages:  messages,
+		Model:      req.ModelID,
+		MaxTokens:  req.MaxTokens,
+		Messages:   messages,
+		Tools:      toTools(req.Tools),
+		ToolChoice: toToolChoice(req.ToolChoice),
 	}
 
 	// Add system prompt if provided
@@ -212,9 +284,17 @@ func (p *Provider) buildRequest(req ports.CompletionRequest) *MessagesRequest {
 // buildResponse converts an Anthropic MessagesResponse to a ports.CompletionResponse.
 func (p *Provider) buildResponse(resp *MessagesResponse, startTime time.Time) *ports.CompletionResponse {
 	var content strings.Builder
+	var toolCalls []ports.ToolCall
 	for _, block := range resp.Content {
-		if block.Type == "text" {
+		switch block.Type {
+		case string(ContentTypeText):
 			content.WriteString(block.Text)
+		case string(ContentTypeToolUse):
+			toolCalls = append(toolCalls, ports.ToolCall{
+				ID:        block.ID,
+				Name:      block.Name,
+				Arguments: toolArguments(block.Input),
+			})
 		}
 	}
 
@@ -225,5 +305,63 @@ func (p *Provider) buildResponse(resp *MessagesResponse, startTime time.Time) *p
 		FinishReason: string(resp.StopReason),
 		ModelUsed:    resp.Model,
 		Duration:     time.Since(startTime),
+		ToolCalls:    toolCalls,
+	}
+}
+
+// toolInput accumulates the streamed input of a tool_use block.
+type toolInput struct {
+	call int // Index into the response's tool calls
+	json strings.Builder
+}
+
+// toTools converts provider-agnostic tools to Anthropic tools.
+func toTools(tools []ports.Tool) []Tool {
+	if len(tools) == 0 {
+		return nil
+	}
+	result := make([]Tool, 0, len(tools))
+	for _, t := range tools {
+		result = append(result, Tool{
+			Name:         t.Name,
+			Description:  t.Description,
+			InputSchema:  toolArguments(t.InputSchema),
+			DeferLoading: t.DeferLoading,
+		})
+	}
+	return result
+}
+
+// toToolChoice converts a tool choice to the Anthropic tool_choice value.
+// Anthropic calls "required" "any".
+func toToolChoice(choice *ports.ToolChoice) *ToolChoice {
+	if choice == nil {
+		return nil
+	}
+	switch choice.Mode {
+	case ports.ToolChoiceRequired:
+		return &ToolChoice{Type: "any"}
+	case ports.ToolChoiceTool:
+		return &ToolChoice{Type: "tool", Name: choice.Name}
+	default:
+		return &ToolChoice{Type: string(choice.Mode)}
+	}
+}
+
+// toolArguments returns raw JSON, defaulting to an empty object.
+func toolArguments(raw json.RawMessage) json.RawMessage {
+	if len(raw) == 0 {
+		return json.RawMessage("{}")
+	}
+	return raw
+}
+
+// isToolResults reports whether content consists only of tool results.
+func isToolResults(content MessageContent) bool {
+	for _, block := range content {
+		if block.Type != string(ContentTypeToolResult) {
+			return false
+		}
 	}
+	return len(content) > 0
 }
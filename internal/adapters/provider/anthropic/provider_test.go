@@ -220,6 +220,37 @@ func TestProvider_Complete_WithSystemPrompt(t *testing.T) {
 	}
 }
 
+func TestProvider_CountTokens(t *testing.T) {
+	var received CountTokensRequest
+
+	server, provider := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/messages/count_tokens" {
+			t.Errorf("unexpected request to %s", r.URL.Path)
+		}
+		_ = json.NewDecoder(r.Body).Decode(&received)
+		_ = json.NewEncoder(w).Encode(CountTokensResponse{InputTokens: 42})
+	})
+	defer server.Close()
+
+	count, err := provider.CountTokens(context.Background(), ports.CompletionRequest{
+		ModelID:   ModelClaude35Sonnet,
+		MaxTokens: 100,
+		Messages: []ports.Message{
+			{Role: "system", Content: "Be terse."},
+			{Role: "user", Content: "Hello"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("CountTokens failed: %v", err)
+	}
+	if count != 42 {
+		t.Errorf("CountTokens() = %d, want 42", count)
+	}
+	if received.Model != ModelClaude35Sonnet || received.System != "Be terse." || len(received.Messages) != 1 {
+		t.Errorf("count request = %+v", received)
+	}
+}
+
 func TestProvider_Complete_ErrorResponse(t *testing.T) {
 	handler := func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "application/json")
@@ -584,3 +615,147 @@ func TestClient_HandleErrorResponse(t *testing.T) {
 		})
 	}
 }
+
+func TestBuildRequest_Tools(t *testing.T) {
+	provider := NewProviderWithAPIKey("test-key")
+
+	req := ports.CompletionRequest{
+		ModelID:   ModelClaude35Sonnet,
+		MaxTokens: 100,
+		Messages: []ports.Message{
+			{Role: "user", Content: "Weather in Paris and Rome?"},
+			{Role: "assistant", Content: "Checking.", ToolCalls: []ports.ToolCall{
+				{ID: "toolu_1", Name: "get_weather", Arguments: json.RawMessage(`{"city":"Paris"}`)},
+				{ID: "toolu_2", Name: "get_weather", Arguments: json.RawMessage(`{"city":"Rome"}`)},
+			}},
+			{Role: "tool", ToolCallID: "toolu_1", Content: "18C"},
+			{Role: "tool", ToolCallID: "toolu_2", Content: "24C"},
+		},
+		Tools: []ports.Tool{
+			{Name: "get_weather", InputSchema: json.RawMessage(`{"type":"object"}`)},
+		},
+		ToolChoice: &ports.ToolChoice{Mode: ports.ToolChoiceRequired},
+	}
+
+	anthropicReq := provider.buildRequest(req)
+
+	if len(anthropicReq.Tools) != 1 || anthropicReq.Tools[0].Name != "get_weather" {
+		t.Errorf("unexpected tools: %+v", anthropicReq.Tools)
+	}
+	if anthropicReq.ToolChoice == nil || anthropicReq.ToolChoice.Type != "any" {
+		t.Errorf("expected tool_choice 'any', got %+v", anthropicReq.ToolChoice)
+	}
+
+	if len(anthropicReq.Messages) != 3 {
+		t.Fatalf("expected 3 messages, got %d", len(anthropicReq.Messages))
+	}
+
+	assistant := anthropicReq.Messages[1].Content
+	if len(assistant) != 3 || assistant[0].Type != "text" || assistant[1].Type != "tool_use" || assistant[1].ID != "toolu_1" {
+		t.Errorf("unexpected assistant content: %+v", assistant)
+	}
+
+	results := anthropicReq.Messages[2]
+	if results.Role != RoleUser || len(results.Content) != 2 {
+		t.Fatalf("expected one user message with 2 tool results, got %+v", results)
+	}
+	if results.Content[1].Type != "tool_result" || results.Content[1].ToolUseID != "toolu_2" || results.Content[1].Content != "24C" {
+		t.Errorf("unexpected tool result: %+v", results.Content[1])
+	}
+}
+
+func TestProvider_Complete_ToolUse(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		resp := MessagesResponse{
+			ID:    "msg_123",
+			Model: ModelClaude35Sonnet,
+			Content: []ContentBlock{
+				{Type: "text", Text: "Let me check."},
+				{Type: "tool_use", ID: "toolu_1", Name: "get_weather", Input: json.RawMessage(`{"city":"Paris"}`)},
+			},
+			StopReason: StopReasonToolUse,
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(resp)
+	}
+
+	server, provider := newTestServer(t, handler)
+	defer server.Close()
+
+	resp, err := provider.Complete(context.Background(), ports.CompletionRequest{
+		ModelID:   ModelClaude35Sonnet,
+		MaxTokens: 100,
+		Messages:  []ports.Message{{Role: "user", Content: "Weather in Paris?"}},
+	})
+	if err != nil {
+		t.Fatalf("Complete failed: %v", err)
+	}
+
+	if resp.Content != "Let me check." {
+		t.Errorf("unexpected content: %q", resp.Content)
+	}
+	if resp.FinishReason != "tool_use" {
+		t.Errorf("expected finish reason 'tool_use', got %q", resp.FinishReason)
+	}
+	if len(resp.ToolCalls) != 1 {
+		t.Fatalf("expected 1 tool call, got %d", len(resp.ToolCalls))
+	}
+	call := resp.ToolCalls[0]
+	if call.ID != "toolu_1" || call.Name != "get_weather" || string(call.Arguments) != `{"city":"Paris"}` {
+		t.Errorf("unexpected tool call: %+v", call)
+	}
+}
+
+func TestProvider_Stream_ToolUse(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+
+		events := []string{
+			`event: message_start
+data: {"type":"message_start","message":{"id":"msg_123","type":"message","role":"assistant","content":[],"model":"claude-3-5-sonnet-20241022","usage":{"input_tokens":10,"output_tokens":0}}}`,
+			`event: content_block_start
+data: {"type":"content_block_start","index":0,"content_block":{"type":"tool_use","id":"toolu_1","name":"get_weather","input":{}}}`,
+			`event: content_block_delta
+data: {"type":"content_block_delta","index":0,"delta":{"type":"input_json_delta","partial_json":"{\"city\":"}}`,
+			`event: content_block_delta
+data: {"type":"content_block_delta","index":0,"delta":{"type":"input_json_delta","partial_json":"\"Paris\"}"}}`,
+			`event: content_block_stop
+data: {"type":"content_block_stop","index":0}`,
+			`event: message_delta
+data: {"type":"message_delta","delta":{"stop_reason":"tool_use"},"usage":{"output_tokens":12}}`,
+			`event: message_stop
+data: {"type":"message_stop"}`,
+		}
+		for _, event := range events {
+			fmt.Fprintln(w, event)
+			fmt.Fprintln(w)
+		}
+	}
+
+	server, provider := newTestServer(t, handler)
+	defer server.Close()
+
+	var chunks []string
+	resp, err := provider.Stream(context.Background(), ports.CompletionRequest{
+		ModelID:   ModelClaude35Sonnet,
+		MaxTokens: 100,
+		Messages:  []ports.Message{{Role: "user", Content: "Weather in Paris?"}},
+	}, func(chunk string) error {
+		chunks = append(chunks, chunk)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Stream failed: %v", err)
+	}
+
+	if len(chunks) != 0 {
+		t.Errorf("tool input should not be streamed as text, got %v", chunks)
+	}
+	if len(resp.ToolCalls) != 1 {
+		t.Fatalf("expected 1 tool call, got %d", len(resp.ToolCalls))
+	}
+	call := resp.ToolCalls[0]
+	if call.ID != "toolu_1" || call.Name != "get_weather" || string(call.Arguments) != `{"city":"Paris"}` {
+		t.Errorf("unexpected tool call: %+v", call)
+	}
+}
@@ -3,6 +3,7 @@ package anthropic
 
 import (
 	"encoding/json"
+	"net/http"
 	"time"
 )
 
@@ -18,7 +19,9 @@ const (
 type ContentType string
 
 const (
-	ContentTypeText ContentType = "text"
+	ContentTypeText       ContentType = "text"
+	ContentTypeToolUse    ContentType = "tool_use"
+	ContentTypeToolResult ContentType = "tool_result"
 )
 
 // ContentBlock represents a content block in a message.
@@ -50,18 +53,40 @@ type Tool struct {
 	DeferLoading bool            `json:"defer_loading,omitempty"` // For Tool Search Tool beta
 }
 
+// ToolChoice constrains how the model uses tools.
+type ToolChoice struct {
+	Type string `json:"type"`           // auto, any, tool, or none
+	Name string `json:"name,omitempty"` // Required when Type is "tool"
+}
+
 // MessagesRequest is the request body for the Anthropic Messages API.
 type MessagesRequest struct {
-	Model         string    `json:"model"`
-	Messages      []Message `json:"messages"`
-	MaxTokens     int       `json:"max_tokens"`
-	System        string    `json:"system,omitempty"`
-	Temperature   *float32  `json:"temperature,omitempty"`
-	TopP          *float32  `json:"top_p,omitempty"`
-	TopK          *int      `json:"top_k,omitempty"`
-	StopSequences []string  `json:"stop_sequences,omitempty"`
-	Stream        bool      `json:"stream,omitempty"`
-	Tools         []Tool    `json:"tools,omitempty"` // Optional tools for function calling
+	Model         string      `json:"model"`
+	Messages      []Message   `json:"messages"`
+	MaxTokens     int         `json:"max_tokens"`
+	System        string      `json:"system,omitempty"`
+	Temperature   *float32    `json:"temperature,omitempty"`
+	TopP          *float32    `json:"top_p,omitempty"`
+	TopK          *int        `json:"top_k,omitempty"`
+	StopSequences []string    `json:"stop_sequences,omitempty"`
+	Stream        bool        `json:"stream,omitempty"`
+	Tools         []Tool      `json:"tools,omitempty"` // Optional tools for function calling
+	ToolChoice    *ToolChoice `json:"tool_choice,omitempty"`
+}
+
+// CountTokensRequest is the request body for counting the input tokens of
+// a messages request.
+type CountTokensRequest struct {
+	Model      string      `json:"model"`
+	Messages   []Message   `json:"messages"`
+	System     string      `json:"system,omitempty"`
+	Tools      []Tool      `json:"tools,omitempty"`
+	ToolChoice *ToolChoice `json:"tool_choice,omitempty"`
+}
+
+// CountTokensResponse is the response of the token counting endpoint.
+type CountTokensResponse struct {
+	InputTokens int `json:"input_tokens"`
 }
 
 // Usage contains token usage information from the response.
@@ -151,6 +176,7 @@ type StreamMessage struct {
 type StreamDelta struct {
 	Type         string      `json:"type,omitempty"`
 	Text         string      `json:"text,omitempty"`
+	PartialJSON  string      `json:"partial_json,omitempty"` // For input_json_delta
 	StopReason   *StopReason `json:"stop_reason,omitempty"`
 	StopSequence *string     `json:"stop_sequence,omitempty"`
 }
@@ -167,6 +193,10 @@ type Config struct {
 	Version    string
 	Timeout    time.Duration
 	MaxRetries int
+
+	// Transport overrides the HTTP transport, e.g. to use a proxy or
+	// custom CAs (see the transport package); nil uses the default.
+	Transport http.RoundTripper
 }
 
 // DefaultConfig returns a Config with default values.
@@ -0,0 +1,146 @@
+package audit
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/jbctechsolutions/skillrunner/internal/application/ports"
+)
+
+// Ensure Provider implements CapabilityLayer at compile time.
+var _ ports.CapabilityLayer = (*Provider)(nil)
+
+// As offers the capabilities of the wrapped provider, recording the batch
+// items, image prompts and transcriptions they serve. Token counting
+// generates nothing and is passed on as it is; other capabilities are not
+// offered.
+func (p *Provider) As(target any) bool {
+	switch t := target.(type) {
+	case *ports.BatchProviderPort:
+		b, ok := ports.As[ports.BatchProviderPort](p.ProviderPort)
+		if ok {
+			*t = &batcher{BatchProviderPort: b, p: p, jobs: make(map[string]map[string]ports.CompletionRequest)}
+		}
+		return ok
+	case *ports.TokenCounterPort:
+		return ports.AsCapability(p.ProviderPort, target)
+	case *ports.ImageGenerationPort:
+		g, ok := ports.As[ports.ImageGenerationPort](p.ProviderPort)
+		if ok {
+			*t = &imageGenerator{ImageGenerationPort: g, p: p}
+		}
+		return ok
+	case *ports.TranscriptionPort:
+		tr, ok := ports.As[ports.TranscriptionPort](p.ProviderPort)
+		if ok {
+			*t = &transcriber{TranscriptionPort: tr, p: p}
+		}
+		return ok
+	}
+	return false
+}
+
+// batcher records the items of a batch job once their results are
+// collected, each attributed to the phase its custom ID names.
+type batcher struct {
+	ports.BatchProviderPort
+	p *Provider
+
+	mu   sync.Mutex
+	jobs map[string]map[string]ports.CompletionRequest // Requests by job ID, then custom ID
+}
+
+// SubmitBatch submits items and remembers their requests for the audit
+// records.
+func (b *batcher) SubmitBatch(ctx context.Context, items []ports.BatchItem) (*ports.BatchJob, error) {
+	job, err := b.BatchProviderPort.SubmitBatch(ctx, items)
+	if err != nil || job == nil {
+		return job, err
+	}
+	requests := make(map[string]ports.CompletionRequest, len(items))
+	for _, item := range items {
+		requests[item.CustomID] = item.Request
+	}
+	b.mu.Lock()
+	b.jobs[job.ID] = requests
+	b.mu.Unlock()
+	return job, nil
+}
+
+// GetBatchResults returns the results of a job and records each of them.
+// The requests of a job submitted by an earlier run are not known, so their
+// records hold the responses only.
+func (b *batcher) GetBatchResults(ctx context.Context, jobID string) ([]ports.BatchItemResult, error) {
+	results, err := b.BatchProviderPort.GetBatchResults(ctx, jobID)
+	if err != nil {
+		return results, err
+	}
+
+	b.mu.Lock()
+	requests := b.jobs[jobID]
+	delete(b.jobs, jobID)
+	b.mu.Unlock()
+
+	scope := ports.AuditScopeFrom(ctx)
+	for _, res := range results {
+		itemScope := scope
+		itemScope.PhaseID = res.CustomID
+		var itemErr error
+		if res.Error != "" {
+			itemErr = errors.New(res.Error)
+		}
+		b.p.record(ports.WithAuditScope(ctx, itemScope), requests[res.CustomID], res.Response, "", itemErr)
+	}
+	return results, nil
+}
+
+// imageGenerator records image prompts and the prompts the model used.
+type imageGenerator struct {
+	ports.ImageGenerationPort
+	p *Provider
+}
+
+// GenerateImages generates the images and records the request.
+func (g *imageGenerator) GenerateImages(ctx context.Context, req ports.ImageGenerationRequest) (*ports.ImageGenerationResponse, error) {
+	resp, err := g.ImageGenerationPort.GenerateImages(ctx, req)
+	var rec *ports.CompletionResponse
+	if resp != nil {
+		rec = &ports.CompletionResponse{ModelUsed: resp.ModelUsed}
+		for _, img := range resp.Images {
+			if img.RevisedPrompt != "" {
+				rec.Content = img.RevisedPrompt
+				break
+			}
+		}
+	}
+	g.p.record(ctx, promptRequest(req.Model, req.Prompt), rec, "", err)
+	return resp, err
+}
+
+// transcriber records transcription prompts and transcripts.
+type transcriber struct {
+	ports.TranscriptionPort
+	p *Provider
+}
+
+// Transcribe transcribes the audio and records the transcript.
+func (t *transcriber) Transcribe(ctx context.Context, req ports.TranscriptionRequest) (*ports.TranscriptionResponse, error) {
+	resp, err := t.TranscriptionPort.Transcribe(ctx, req)
+	var rec *ports.CompletionResponse
+	if resp != nil {
+		rec = &ports.CompletionResponse{Content: resp.Text, ModelUsed: resp.ModelUsed}
+	}
+	t.p.record(ctx, promptRequest(req.Model, req.Prompt), rec, "", err)
+	return resp, err
+}
+
+// promptRequest returns a completion request holding a prompt, to record
+// the requests of other operations like completions.
+func promptRequest(model, prompt string) ports.CompletionRequest {
+	req := ports.CompletionRequest{ModelID: model}
+	if prompt != "" {
+		req.Messages = []ports.Message{{Role: "user", Content: prompt}}
+	}
+	return req
+}
@@ -0,0 +1,120 @@
+// Package audit records the full request and response of each completion
+// and stream a provider serves in an audit log, with secrets redacted, for
+// compliance review of generated output.
+package audit
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/jbctechsolutions/skillrunner/internal/adapters/provider/redact"
+	"github.com/jbctechsolutions/skillrunner/internal/application/ports"
+	"github.com/jbctechsolutions/skillrunner/internal/domain/workflow"
+	"github.com/jbctechsolutions/skillrunner/internal/infrastructure/logging"
+)
+
+// Provider wraps a provider so that its completions and streams are
+// appended to an audit log, attributed to the run and phase in their
+// context's ports.AuditScope. Like the redact decorator it has no Unwrap
+// method, so that callers looking for optional capabilities, such as the
+// Batch API, cannot send requests around it; As offers them recorded.
+type Provider struct {
+	ports.ProviderPort
+	log   ports.AuditLogPort
+	rules []redact.Rule
+}
+
+// Ensure Provider implements ProviderPort, ThrottleReporter and Warmer at
+// compile time.
+var (
+	_ ports.ProviderPort     = (*Provider)(nil)
+	_ ports.ThrottleReporter = (*Provider)(nil)
+	_ ports.Warmer           = (*Provider)(nil)
+)
+
+// NewProvider wraps inner so its requests are recorded in log, with text
+// matching rules redacted.
+func NewProvider(inner ports.ProviderPort, log ports.AuditLogPort, rules []redact.Rule) *Provider {
+	return &Provider{ProviderPort: inner, log: log, rules: rules}
+}
+
+// Throttled reports whether the wrapped provider is being throttled.
+func (p *Provider) Throttled() bool {
+	t, ok := p.ProviderPort.(ports.ThrottleReporter)
+	return ok && t.Throttled()
+}
+
+// Warm prepares the model of the wrapped provider, if it supports warming.
+func (p *Provider) Warm(ctx context.Context, modelID string) error {
+	if w, ok := ports.UnwrapProvider(p.ProviderPort).(ports.Warmer); ok {
+		return w.Warm(ctx, modelID)
+	}
+	return nil
+}
+
+// Complete sends the completion request and records it.
+func (p *Provider) Complete(ctx context.Context, req ports.CompletionRequest) (*ports.CompletionResponse, error) {
+	resp, err := p.ProviderPort.Complete(ctx, req)
+	p.record(ctx, req, resp, "", err)
+	return resp, err
+}
+
+// Stream sends the streaming request and records it once the stream ends,
+// with the content streamed so far if it failed.
+func (p *Provider) Stream(ctx context.Context, req ports.CompletionRequest, cb ports.StreamCallback) (*ports.CompletionResponse, error) {
+	var streamed strings.Builder
+	resp, err := p.ProviderPort.Stream(ctx, req, func(chunk string) error {
+		streamed.WriteString(chunk)
+		return cb(chunk)
+	})
+	p.record(ctx, req, resp, streamed.String(), err)
+	return resp, err
+}
+
+// record appends the redacted request and response to the audit log. A
+// failure to record is logged and does not fail the request.
+func (p *Provider) record(ctx context.Context, req ports.CompletionRequest, resp *ports.CompletionResponse, streamed string, callErr error) {
+	scope := ports.AuditScopeFrom(ctx)
+	rec := &workflow.AuditRecord{
+		ExecutionID: scope.ExecutionID,
+		SkillID:     scope.SkillID,
+		PhaseID:     scope.PhaseID,
+		Provider:    p.Info().Name,
+		Model:       req.ModelID,
+		Messages:    make([]workflow.AuditMessage, len(req.Messages)),
+		MaxTokens:   req.MaxTokens,
+		Temperature: req.Temperature,
+		Response:    streamed,
+		RecordedAt:  time.Now(),
+	}
+	n := 0
+	rec.SystemPrompt, n = redact.Redact(req.SystemPrompt, p.rules)
+	rec.Redactions += n
+	for i, msg := range req.Messages {
+		rec.Messages[i] = workflow.AuditMessage{Role: msg.Role}
+		rec.Messages[i].Content, n = redact.Redact(msg.Content, p.rules)
+		rec.Redactions += n
+	}
+	if resp != nil {
+		if resp.Content != "" {
+			rec.Response = resp.Content
+		}
+		if resp.ModelUsed != "" {
+			rec.Model = resp.ModelUsed
+		}
+		rec.InputTokens = resp.InputTokens
+		rec.OutputTokens = resp.OutputTokens
+	}
+	rec.Response, n = redact.Redact(rec.Response, p.rules)
+	rec.Redactions += n
+	if callErr != nil {
+		rec.Error, n = redact.Redact(callErr.Error(), p.rules)
+		rec.Redactions += n
+	}
+
+	// Record a request canceled with its run too
+	if err := p.log.Append(context.WithoutCancel(ctx), rec); err != nil {
+		logging.FromContext(ctx).WarnContext(ctx, "failed to append to audit log", "error", err)
+	}
+}
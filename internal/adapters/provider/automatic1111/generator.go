@@ -0,0 +1,124 @@
+// Package automatic1111 provides an image generation adapter for a local
+// Stable Diffusion web UI (AUTOMATIC1111 or Forge) started with --api.
+package automatic1111
+
+import (
+	"context"
+	"encoding/base64"
+	"strings"
+	"time"
+
+	"github.com/jbctechsolutions/skillrunner/internal/adapters/provider/openaicompat"
+	"github.com/jbctechsolutions/skillrunner/internal/application/ports"
+	"github.com/jbctechsolutions/skillrunner/internal/domain/errors"
+)
+
+// ProviderName is the name reported by this adapter.
+const ProviderName = "a1111"
+
+// DefaultBaseURL is the address the web UI listens on by default.
+const DefaultBaseURL = "http://127.0.0.1:7860"
+
+// DefaultTimeout bounds a single generation; local diffusion is slow.
+const DefaultTimeout = 5 * time.Minute
+
+// Default image size of Stable Diffusion 1.x checkpoints.
+const (
+	defaultWidth  = 512
+	defaultHeight = 512
+)
+
+// endpointTxt2Img is the text-to-image API endpoint.
+const endpointTxt2Img = "/sdapi/v1/txt2img"
+
+// Txt2ImgRequest is the request body for text-to-image generation.
+type Txt2ImgRequest struct {
+	Prompt           string         `json:"prompt"`
+	NegativePrompt   string         `json:"negative_prompt,omitempty"`
+	Width            int            `json:"width"`
+	Height           int            `json:"height"`
+	BatchSize        int            `json:"batch_size"`
+	OverrideSettings map[string]any `json:"override_settings,omitempty"`
+}
+
+// Txt2ImgResponse is the response of text-to-image generation.
+type Txt2ImgResponse struct {
+	Images []string `json:"images"` // Base64-encoded PNGs
+}
+
+// Generator implements ports.ImageGenerationPort for the web UI's API.
+type Generator struct {
+	client  *openaicompat.Client
+	baseURL string
+}
+
+// Ensure Generator implements ImageGenerationPort at compile time.
+var _ ports.ImageGenerationPort = (*Generator)(nil)
+
+// NewGenerator creates a generator for the web UI at baseURL
+// (DefaultBaseURL if empty).
+func NewGenerator(baseURL string, opts ...openaicompat.ClientOption) *Generator {
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
+	baseURL = strings.TrimSuffix(baseURL, "/")
+
+	config := openaicompat.DefaultConfig("", baseURL)
+	config.Timeout = DefaultTimeout
+
+	return &Generator{
+		client:  openaicompat.NewClient(config, opts...),
+		baseURL: baseURL,
+	}
+}
+
+// Info returns provider metadata.
+func (g *Generator) Info() ports.ProviderInfo {
+	return ports.ProviderInfo{
+		Name:        ProviderName,
+		Description: "Stable Diffusion web UI - Local image generation",
+		BaseURL:     g.baseURL,
+		IsLocal:     true,
+	}
+}
+
+// GenerateImages generates images with the loaded checkpoint, or the
+// checkpoint named by req.Model.
+func (g *Generator) GenerateImages(ctx context.Context, req ports.ImageGenerationRequest) (*ports.ImageGenerationResponse, error) {
+	width, height, err := req.Dimensions(defaultWidth, defaultHeight)
+	if err != nil {
+		return nil, errors.NewError(errors.CodeValidation, err.Error(), nil)
+	}
+
+	body := Txt2ImgRequest{
+		Prompt:         req.Prompt,
+		NegativePrompt: req.NegativePrompt,
+		Width:          width,
+		Height:         height,
+		BatchSize:      max(req.Count, 1),
+	}
+	model := ProviderName
+	if req.Model != "" {
+		body.OverrideSettings = map[string]any{"sd_model_checkpoint": req.Model}
+		model = req.Model
+	}
+
+	var result Txt2ImgResponse
+	if _, err := g.client.PostJSON(ctx, endpointTxt2Img, body, &result); err != nil {
+		return nil, err
+	}
+	if len(result.Images) == 0 {
+		return nil, errors.NewError(errors.CodeProvider, "image generation returned no images", nil)
+	}
+
+	resp := &ports.ImageGenerationResponse{ModelUsed: model}
+	for _, encoded := range result.Images {
+		data, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, errors.NewError(errors.CodeProvider, "failed to decode generated image", err)
+		}
+		resp.Images = append(resp.Images, ports.GeneratedImage{Data: data, Format: "png"})
+	}
+
+	return resp, nil
+}
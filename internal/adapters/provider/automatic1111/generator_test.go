@@ -0,0 +1,64 @@
+package automatic1111
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jbctechsolutions/skillrunner/internal/application/ports"
+)
+
+func TestGenerator_Info(t *testing.T) {
+	info := NewGenerator("").Info()
+	if info.Name != ProviderName || info.BaseURL != DefaultBaseURL || !info.IsLocal {
+		t.Errorf("Info() = %+v", info)
+	}
+}
+
+func TestGenerator_GenerateImages(t *testing.T) {
+	var got Txt2ImgRequest
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/sdapi/v1/txt2img" {
+			t.Errorf("unexpected request to %s", r.URL.Path)
+		}
+		_ = json.NewDecoder(r.Body).Decode(&got)
+
+		image := base64.StdEncoding.EncodeToString([]byte("png-bytes"))
+		_ = json.NewEncoder(w).Encode(Txt2ImgResponse{Images: []string{image, image}})
+	}))
+	defer server.Close()
+
+	resp, err := NewGenerator(server.URL).GenerateImages(context.Background(), ports.ImageGenerationRequest{
+		Prompt:         "a lighthouse at dusk",
+		NegativePrompt: "blurry",
+		Model:          "sd_xl_base_1.0",
+		Count:          2,
+	})
+	if err != nil {
+		t.Fatalf("GenerateImages failed: %v", err)
+	}
+
+	if got.Width != defaultWidth || got.Height != defaultHeight || got.BatchSize != 2 || got.NegativePrompt != "blurry" {
+		t.Errorf("request = %+v", got)
+	}
+	if got.OverrideSettings["sd_model_checkpoint"] != "sd_xl_base_1.0" {
+		t.Errorf("override_settings = %v", got.OverrideSettings)
+	}
+	if len(resp.Images) != 2 || string(resp.Images[1].Data) != "png-bytes" || resp.ModelUsed != "sd_xl_base_1.0" {
+		t.Errorf("response = %+v", resp)
+	}
+}
+
+func TestGenerator_GenerateImages_InvalidSize(t *testing.T) {
+	_, err := NewGenerator("").GenerateImages(context.Background(), ports.ImageGenerationRequest{
+		Prompt: "x",
+		Size:   "512",
+	})
+	if err == nil {
+		t.Error("expected error for an invalid size")
+	}
+}
@@ -0,0 +1,75 @@
+// Package fireworks provides an adapter for the Fireworks AI API.
+// Fireworks exposes an OpenAI-compatible API, so this package only supplies
+// defaults and model metadata on top of the shared openaicompat provider.
+package fireworks
+
+import (
+	"slices"
+
+	"github.com/jbctechsolutions/skillrunner/internal/adapters/provider/openaicompat"
+	"github.com/jbctechsolutions/skillrunner/internal/application/ports"
+)
+
+// ProviderName is the name used to register this provider.
+const ProviderName = "fireworks"
+
+// DefaultBaseURL is the default Fireworks AI API endpoint.
+const DefaultBaseURL = "https://api.fireworks.ai/inference/v1"
+
+// Available Fireworks AI models.
+const (
+	ModelLlama33_70BInstruct  = "accounts/fireworks/models/llama-v3p3-70b-instruct"
+	ModelLlama31_8BInstruct   = "accounts/fireworks/models/llama-v3p1-8b-instruct"
+	ModelLlama31_405BInstruct = "accounts/fireworks/models/llama-v3p1-405b-instruct"
+	ModelQwen25_72BInstruct   = "accounts/fireworks/models/qwen2p5-72b-instruct"
+	ModelMixtral8x22BInstruct = "accounts/fireworks/models/mixtral-8x22b-instruct"
+	ModelDeepSeekV3           = "accounts/fireworks/models/deepseek-v3"
+)
+
+// SupportedModels returns the list of models supported by this adapter.
+func SupportedModels() []string {
+	return []string{
+		ModelLlama33_70BInstruct,
+		ModelLlama31_8BInstruct,
+		ModelLlama31_405BInstruct,
+		ModelQwen25_72BInstruct,
+		ModelMixtral8x22BInstruct,
+		ModelDeepSeekV3,
+	}
+}
+
+// IsSupportedModel checks if a model ID is in the list of supported models.
+func IsSupportedModel(modelID string) bool {
+	return slices.Contains(SupportedModels(), modelID)
+}
+
+// DefaultConfig returns a Config with default values for Fireworks AI.
+func DefaultConfig(apiKey string) openaicompat.Config {
+	return openaicompat.DefaultConfig(apiKey, DefaultBaseURL)
+}
+
+// Provider implements the ports.ProviderPort interface for Fireworks AI.
+type Provider struct {
+	*openaicompat.Provider
+}
+
+// Ensure Provider implements ProviderPort at compile time.
+var _ ports.ProviderPort = (*Provider)(nil)
+
+// NewProvider creates a new Fireworks AI provider with the given configuration.
+func NewProvider(config openaicompat.Config, opts ...openaicompat.ClientOption) *Provider {
+	spec := openaicompat.ProviderSpec{
+		Name:        ProviderName,
+		Description: "Fireworks AI provider for fast open-weight model inference",
+		Models:      SupportedModels(),
+	}
+
+	return &Provider{
+		Provider: openaicompat.NewProvider(spec, config, opts...),
+	}
+}
+
+// NewProviderWithAPIKey creates a new Fireworks AI provider with default configuration.
+func NewProviderWithAPIKey(apiKey string, opts ...openaicompat.ClientOption) *Provider {
+	return NewProvider(DefaultConfig(apiKey), opts...)
+}
@@ -2,10 +2,12 @@ package groq
 
 import (
 	"context"
+	"encoding/json"
 	"slices"
 	"strings"
 	"time"
 
+	"github.com/jbctechsolutions/skillrunner/internal/adapters/provider/openaicompat"
 	"github.com/jbctechsolutions/skillrunner/internal/application/ports"
 )
 
@@ -15,8 +17,11 @@ type Provider struct {
 	config Config
 }
 
-// Ensure Provider implements ProviderPort at compile time.
-var _ ports.ProviderPort = (*Provider)(nil)
+// Ensure Provider implements ProviderPort and Warmer at compile time.
+var (
+	_ ports.ProviderPort = (*Provider)(nil)
+	_ ports.Warmer       = (*Provider)(nil)
+)
 
 // NewProvider creates a new Groq provider with the given configuration.
 func NewProvider(config Config, opts ...ClientOption) *Provider {
@@ -31,6 +36,12 @@ func NewProviderWithAPIKey(apiKey string, opts ...ClientOption) *Provider {
 	return NewProvider(DefaultConfig(apiKey), opts...)
 }
 
+// OnRateLimit registers fn to receive the rate limit headers of the
+// provider's responses.
+func (p *Provider) OnRateLimit(fn func(*openaicompat.RateLimitInfo)) {
+	p.client.base.OnRateLimit(fn)
+}
+
 // Info returns metadata about this provider.
 func (p *Provider) Info() ports.ProviderInfo {
 	return ports.ProviderInfo{
@@ -92,6 +103,7 @@ func (p *Provider) Stream(ctx context.Context, req ports.CompletionRequest, cb p
 	var inputTokens, outputTokens int
 	var finishReason string
 	var modelUsed string
+	var toolCalls []ToolCall
 
 	err := p.client.ChatStream(ctx, groqReq, func(chunk *ChatCompletionChunk) error {
 		modelUsed = chunk.Model
@@ -103,6 +115,7 @@ func (p *Provider) Stream(ctx context.Context, req ports.CompletionRequest, cb p
 					return err
 				}
 			}
+			toolCalls = mergeToolCallDeltas(toolCalls, choice.Delta.ToolCalls)
 			if choice.FinishReason != "" {
 				finishReason = string(choice.FinishReason)
 			}
@@ -127,9 +140,17 @@ func (p *Provider) Stream(ctx context.Context, req ports.CompletionRequest, cb p
 		FinishReason: finishReason,
 		ModelUsed:    modelUsed,
 		Duration:     time.Since(startTime),
+		ToolCalls:    fromToolCalls(toolCalls),
 	}, nil
 }
 
+// Warm opens the connection to the API so the first request skips the
+// TLS handshake. It lists the models, which consumes no tokens.
+func (p *Provider) Warm(ctx context.Context, _ string) error {
+	_, err := p.client.ListModels(ctx)
+	return err
+}
+
 // HealthCheck verifies the provider is healthy and responsive.
 func (p *Provider) HealthCheck(ctx context.Context, modelID string) (*ports.HealthStatus, error) {
 	startTime := time.Now()
@@ -188,20 +209,26 @@ func (p *Provider) buildRequest(req ports.CompletionRequest) *ChatCompletionRequ
 			role = RoleSystem
 		case "assistant":
 			role = RoleAssistant
+		case "tool":
+			role = RoleTool
 		default:
 			role = RoleUser
 		}
 
 		messages = append(messages, Message{
-			Role:    role,
-			Content: msg.Content,
+			Role:       role,
+			Content:    msg.Content,
+			ToolCalls:  toToolCalls(msg.ToolCalls),
+			ToolCallID: msg.ToolCallID,
 		})
 	}
 
 	groqReq := &ChatCompletionRequest{
-		Model:     req.ModelID,
-		MaxTokens: req.MaxTokens,
-		Messages:  messages,
+		Model:      req.ModelID,
+		MaxTokens:  req.MaxTokens,
+		Messages:   messages,
+		Tools:      toTools(req.Tools),
+		ToolChoice: toToolChoice(req.ToolChoice),
 	}
 
 	// Add temperature if non-zero
@@ -217,10 +244,12 @@ func (p *Provider) buildRequest(req ports.CompletionRequest) *ChatCompletionRequ
 func (p *Provider) buildResponse(resp *ChatCompletionResponse, startTime time.Time) *ports.CompletionResponse {
 	var content string
 	var finishReason string
+	var toolCalls []ports.ToolCall
 
 	if len(resp.Choices) > 0 {
 		content = resp.Choices[0].Message.Content
 		finishReason = string(resp.Choices[0].FinishReason)
+		toolCalls = fromToolCalls(resp.Choices[0].Message.ToolCalls)
 	}
 
 	return &ports.CompletionResponse{
@@ -230,5 +259,92 @@ func (p *Provider) buildResponse(resp *ChatCompletionResponse, startTime time.Ti
 		FinishReason: finishReason,
 		ModelUsed:    resp.Model,
 		Duration:     time.Since(startTime),
+		ToolCalls:    toolCalls,
+	}
+}
+
+// toTools converts provider-agnostic tools to Groq function tools.
+func toTools(tools []ports.Tool) []Tool {
+	if len(tools) == 0 {
+		return nil
+	}
+	result := make([]Tool, 0, len(tools))
+	for _, t := range tools {
+		fn := Function{Name: t.Name, Description: t.Description}
+		if len(t.InputSchema) > 0 {
+			fn.Parameters = t.InputSchema
+		}
+		result = append(result, Tool{Type: "function", Function: fn})
+	}
+	return result
+}
+
+// toToolChoice converts a tool choice to the Groq tool_choice value.
+func toToolChoice(choice *ports.ToolChoice) any {
+	if choice == nil {
+		return nil
+	}
+	if choice.Mode == ports.ToolChoiceTool {
+		return ToolChoiceFunction{Type: "function", Function: FunctionName{Name: choice.Name}}
+	}
+	return string(choice.Mode)
+}
+
+// toToolCalls converts tool calls from a previous assistant turn.
+func toToolCalls(calls []ports.ToolCall) []ToolCall {
+	if len(calls) == 0 {
+		return nil
+	}
+	result := make([]ToolCall, 0, len(calls))
+	for _, c := range calls {
+		result = append(result, ToolCall{
+			ID:       c.ID,
+			Type:     "function",
+			Function: FunctionCall{Name: c.Name, Arguments: string(c.Arguments)},
+		})
+	}
+	return result
+}
+
+// fromToolCalls converts Groq tool calls to provider-agnostic tool calls.
+func fromToolCalls(calls []ToolCall) []ports.ToolCall {
+	if len(calls) == 0 {
+		return nil
+	}
+	result := make([]ports.ToolCall, 0, len(calls))
+	for _, c := range calls {
+		args := c.Function.Arguments
+		if args == "" {
+			args = "{}"
+		}
+		result = append(result, ports.ToolCall{
+			ID:        c.ID,
+			Name:      c.Function.Name,
+			Arguments: json.RawMessage(args),
+		})
+	}
+	return result
+}
+
+// mergeToolCallDeltas folds streamed tool call fragments into calls.
+// The first fragment of each call carries its ID and name; later fragments
+// with the same index append to its arguments.
+func mergeToolCallDeltas(calls []ToolCall, deltas []ToolCall) []ToolCall {
+	for _, d := range deltas {
+		i := len(calls)
+		if d.Index != nil {
+			i = *d.Index
+		}
+		for len(calls) <= i {
+			calls = append(calls, ToolCall{Type: "function"})
+		}
+		if d.ID != "" {
+			calls[i].ID = d.ID
+		}
+		if d.Function.Name != "" {
+			calls[i].Function.Name = d.Function.Name
+		}
+		calls[i].Function.Arguments += d.Function.Arguments
 	}
+	return calls
 }
@@ -863,3 +863,86 @@ func TestProvider_Complete_FinishReasonLength(t *testing.T) {
 		t.Errorf("expected finish reason 'length', got %q", resp.FinishReason)
 	}
 }
+
+func TestBuildRequest_Tools(t *testing.T) {
+	provider := NewProviderWithAPIKey("test-key")
+
+	req := ports.CompletionRequest{
+		ModelID: ModelLlama31_70BVersatile,
+		Messages: []ports.Message{
+			{Role: "user", Content: "Weather in Paris?"},
+			{Role: "assistant", ToolCalls: []ports.ToolCall{
+				{ID: "call_1", Name: "get_weather", Arguments: json.RawMessage(`{"city":"Paris"}`)},
+			}},
+			{Role: "tool", ToolCallID: "call_1", Content: "18C"},
+		},
+		Tools: []ports.Tool{
+			{Name: "get_weather", InputSchema: json.RawMessage(`{"type":"object"}`)},
+		},
+		ToolChoice: &ports.ToolChoice{Mode: ports.ToolChoiceAuto},
+	}
+
+	groqReq := provider.buildRequest(req)
+
+	if len(groqReq.Tools) != 1 || groqReq.Tools[0].Function.Name != "get_weather" {
+		t.Errorf("unexpected tools: %+v", groqReq.Tools)
+	}
+	if groqReq.ToolChoice != "auto" {
+		t.Errorf("expected tool_choice 'auto', got %#v", groqReq.ToolChoice)
+	}
+	if calls := groqReq.Messages[1].ToolCalls; len(calls) != 1 || calls[0].ID != "call_1" {
+		t.Errorf("unexpected assistant tool calls: %+v", calls)
+	}
+	if toolMsg := groqReq.Messages[2]; toolMsg.Role != RoleTool || toolMsg.ToolCallID != "call_1" {
+		t.Errorf("unexpected tool message: %+v", toolMsg)
+	}
+}
+
+func TestProvider_Complete_ToolCalls(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		var req ChatCompletionRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("failed to decode request: %v", err)
+		}
+		if len(req.Tools) != 1 {
+			t.Errorf("expected 1 tool in request, got %d", len(req.Tools))
+		}
+
+		resp := ChatCompletionResponse{
+			Model: req.Model,
+			Choices: []Choice{{
+				Message: Message{
+					Role: RoleAssistant,
+					ToolCalls: []ToolCall{{
+						ID:       "call_1",
+						Type:     "function",
+						Function: FunctionCall{Name: "get_weather", Arguments: `{"city":"Paris"}`},
+					}},
+				},
+				FinishReason: FinishReasonToolCalls,
+			}},
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(resp)
+	}
+
+	server, provider := newTestServer(t, handler)
+	defer server.Close()
+
+	resp, err := provider.Complete(context.Background(), ports.CompletionRequest{
+		ModelID:  ModelLlama31_70BVersatile,
+		Messages: []ports.Message{{Role: "user", Content: "Weather in Paris?"}},
+		Tools:    []ports.Tool{{Name: "get_weather", InputSchema: json.RawMessage(`{"type":"object"}`)}},
+	})
+	if err != nil {
+		t.Fatalf("Complete failed: %v", err)
+	}
+
+	if len(resp.ToolCalls) != 1 {
+		t.Fatalf("expected 1 tool call, got %d", len(resp.ToolCalls))
+	}
+	call := resp.ToolCalls[0]
+	if call.ID != "call_1" || call.Name != "get_weather" || string(call.Arguments) != `{"city":"Paris"}` {
+		t.Errorf("unexpected tool call: %+v", call)
+	}
+}
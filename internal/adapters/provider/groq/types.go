@@ -20,12 +20,53 @@ const (
 	RoleSystem    MessageRole = "system"
 	RoleUser      MessageRole = "user"
 	RoleAssistant MessageRole = "assistant"
+	RoleTool      MessageRole = "tool"
 )
 
 // Message represents a single message in the chat conversation.
 type Message struct {
-	Role    MessageRole `json:"role"`
-	Content string      `json:"content"`
+	Role       MessageRole `json:"role"`
+	Content    string      `json:"content"`
+	ToolCalls  []ToolCall  `json:"tool_calls,omitempty"`
+	ToolCallID string      `json:"tool_call_id,omitempty"`
+}
+
+// ToolCall represents a tool/function call requested by the model.
+type ToolCall struct {
+	Index    *int         `json:"index,omitempty"` // Set on streaming deltas only
+	ID       string       `json:"id,omitempty"`
+	Type     string       `json:"type,omitempty"`
+	Function FunctionCall `json:"function"`
+}
+
+// FunctionCall contains the function name and arguments.
+type FunctionCall struct {
+	Name      string `json:"name"`
+	Arguments string `json:"arguments"`
+}
+
+// Tool represents a tool available to the model.
+type Tool struct {
+	Type     string   `json:"type"`
+	Function Function `json:"function"`
+}
+
+// Function describes a function that can be called.
+type Function struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Parameters  any    `json:"parameters,omitempty"`
+}
+
+// ToolChoiceFunction forces the model to call a specific function.
+type ToolChoiceFunction struct {
+	Type     string       `json:"type"` // Always "function"
+	Function FunctionName `json:"function"`
+}
+
+// FunctionName identifies a function by name.
+type FunctionName struct {
+	Name string `json:"name"`
 }
 
 // ChatCompletionRequest is the request body for Groq chat completions.
@@ -41,6 +82,8 @@ type ChatCompletionRequest struct {
 	PresencePenalty  *float32  `json:"presence_penalty,omitempty"`
 	FrequencyPenalty *float32  `json:"frequency_penalty,omitempty"`
 	User             string    `json:"user,omitempty"`
+	Tools            []Tool    `json:"tools,omitempty"`
+	ToolChoice       any       `json:"tool_choice,omitempty"`
 }
 
 // Usage contains token usage information from the response.
@@ -54,8 +97,9 @@ type Usage struct {
 type FinishReason string
 
 const (
-	FinishReasonStop   FinishReason = "stop"
-	FinishReasonLength FinishReason = "length"
+	FinishReasonStop      FinishReason = "stop"
+	FinishReasonLength    FinishReason = "length"
+	FinishReasonToolCalls FinishReason = "tool_calls"
 )
 
 // Choice represents a single completion choice in the response.
@@ -0,0 +1,114 @@
+package moderation
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jbctechsolutions/skillrunner/internal/application/ports"
+)
+
+// Ensure Provider implements CapabilityLayer at compile time.
+var _ ports.CapabilityLayer = (*Provider)(nil)
+
+// As offers the capabilities of the wrapped provider that send prompts, with
+// the prompts checked first: batch jobs, token counting, image generation
+// and transcription. Other capabilities are not offered.
+func (p *Provider) As(target any) bool {
+	switch t := target.(type) {
+	case *ports.BatchProviderPort:
+		b, ok := ports.As[ports.BatchProviderPort](p.ProviderPort)
+		if ok {
+			*t = &batcher{BatchProviderPort: b, p: p}
+		}
+		return ok
+	case *ports.TokenCounterPort:
+		c, ok := ports.As[ports.TokenCounterPort](p.ProviderPort)
+		if ok {
+			*t = &tokenCounter{counter: c, p: p}
+		}
+		return ok
+	case *ports.ImageGenerationPort:
+		g, ok := ports.As[ports.ImageGenerationPort](p.ProviderPort)
+		if ok {
+			*t = &imageGenerator{ImageGenerationPort: g, p: p}
+		}
+		return ok
+	case *ports.TranscriptionPort:
+		tr, ok := ports.As[ports.TranscriptionPort](p.ProviderPort)
+		if ok {
+			*t = &transcriber{TranscriptionPort: tr, p: p}
+		}
+		return ok
+	}
+	return false
+}
+
+// batcher checks the request of each batch item before submitting it.
+type batcher struct {
+	ports.BatchProviderPort
+	p *Provider
+}
+
+// SubmitBatch submits the items whose requests pass the check. Blocked items
+// are left out of the job, so they have no result; callers run them
+// directly, where the check fails them as it does any request. If every
+// item is blocked, no job is submitted.
+func (b *batcher) SubmitBatch(ctx context.Context, items []ports.BatchItem) (*ports.BatchJob, error) {
+	allowed := make([]ports.BatchItem, 0, len(items))
+	var blocked error
+	for _, item := range items {
+		if err := b.p.check(ctx, item.Request); err != nil {
+			blocked = err
+			continue
+		}
+		allowed = append(allowed, item)
+	}
+	if len(allowed) == 0 && blocked != nil {
+		return nil, fmt.Errorf("every batch item was blocked: %w", blocked)
+	}
+	return b.BatchProviderPort.SubmitBatch(ctx, allowed)
+}
+
+// tokenCounter checks requests before counting their tokens remotely.
+type tokenCounter struct {
+	counter ports.TokenCounterPort
+	p       *Provider
+}
+
+// CountTokens checks the request, then counts its tokens.
+func (c *tokenCounter) CountTokens(ctx context.Context, req ports.CompletionRequest) (int, error) {
+	if err := c.p.check(ctx, req); err != nil {
+		return 0, err
+	}
+	return c.counter.CountTokens(ctx, req)
+}
+
+// imageGenerator checks image prompts before they are sent.
+type imageGenerator struct {
+	ports.ImageGenerationPort
+	p *Provider
+}
+
+// GenerateImages checks the prompt, then generates the images.
+func (g *imageGenerator) GenerateImages(ctx context.Context, req ports.ImageGenerationRequest) (*ports.ImageGenerationResponse, error) {
+	if err := g.p.checkText(ctx, req.Prompt); err != nil {
+		return nil, err
+	}
+	return g.ImageGenerationPort.GenerateImages(ctx, req)
+}
+
+// transcriber checks the prompt of transcription requests.
+type transcriber struct {
+	ports.TranscriptionPort
+	p *Provider
+}
+
+// Transcribe checks the prompt, if any, then transcribes the audio.
+func (t *transcriber) Transcribe(ctx context.Context, req ports.TranscriptionRequest) (*ports.TranscriptionResponse, error) {
+	if req.Prompt != "" {
+		if err := t.p.checkText(ctx, req.Prompt); err != nil {
+			return nil, err
+		}
+	}
+	return t.TranscriptionPort.Transcribe(ctx, req)
+}
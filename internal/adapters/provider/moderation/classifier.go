@@ -0,0 +1,88 @@
+package moderation
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/jbctechsolutions/skillrunner/internal/application/ports"
+)
+
+// ClassifierFunc adapts a function to a ModerationPort.
+type ClassifierFunc func(ctx context.Context, text string) (*ports.ModerationResult, error)
+
+// Moderate calls f.
+func (f ClassifierFunc) Moderate(ctx context.Context, text string) (*ports.ModerationResult, error) {
+	return f(ctx, text)
+}
+
+// llamaGuardCategories names the hazard categories of Llama Guard 3.
+var llamaGuardCategories = map[string]string{
+	"S1":  "violent_crimes",
+	"S2":  "non_violent_crimes",
+	"S3":  "sex_related_crimes",
+	"S4":  "child_sexual_exploitation",
+	"S5":  "defamation",
+	"S6":  "specialized_advice",
+	"S7":  "privacy",
+	"S8":  "intellectual_property",
+	"S9":  "indiscriminate_weapons",
+	"S10": "hate",
+	"S11": "suicide_self_harm",
+	"S12": "sexual_content",
+	"S13": "elections",
+	"S14": "code_interpreter_abuse",
+}
+
+// LocalClassifier classifies text with a safety model, such as Llama Guard,
+// served by a local provider, so the text never leaves the machine. The
+// model answers "safe", or "unsafe" followed by a line listing the hazard
+// categories.
+type LocalClassifier struct {
+	provider ports.ProviderPort
+	model    string
+}
+
+// Ensure LocalClassifier implements ModerationPort at compile time.
+var _ ports.ModerationPort = (*LocalClassifier)(nil)
+
+// NewLocalClassifier returns a classifier asking model on provider.
+func NewLocalClassifier(provider ports.ProviderPort, model string) *LocalClassifier {
+	return &LocalClassifier{provider: provider, model: model}
+}
+
+// Moderate asks the model whether text is safe.
+func (c *LocalClassifier) Moderate(ctx context.Context, text string) (*ports.ModerationResult, error) {
+	resp, err := c.provider.Complete(ctx, ports.CompletionRequest{
+		ModelID:  c.model,
+		Messages: []ports.Message{{Role: "user", Content: text}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return parseVerdict(resp.Content)
+}
+
+// parseVerdict parses the answer of a Llama Guard model.
+func parseVerdict(answer string) (*ports.ModerationResult, error) {
+	fields := strings.Fields(strings.ReplaceAll(strings.TrimSpace(answer), ",", " "))
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("unexpected classifier answer %q", answer)
+	}
+	switch strings.ToLower(fields[0]) {
+	case "safe":
+		return &ports.ModerationResult{}, nil
+	case "unsafe":
+		result := &ports.ModerationResult{Flagged: true}
+		for _, code := range fields[1:] {
+			name, ok := llamaGuardCategories[strings.ToUpper(code)]
+			if !ok {
+				name = code
+			}
+			result.Categories = append(result.Categories, name)
+		}
+		return result, nil
+	default:
+		return nil, fmt.Errorf("unexpected classifier answer %q", answer)
+	}
+}
@@ -0,0 +1,154 @@
+// Package moderation checks the prompts sent to a provider with a content
+// classifier, such as the OpenAI moderation endpoint or a local Llama Guard
+// model, and blocks or reports the requests it flags.
+package moderation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"strings"
+	"sync"
+
+	"github.com/jbctechsolutions/skillrunner/internal/application/ports"
+	"github.com/jbctechsolutions/skillrunner/internal/domain/skill"
+)
+
+// ErrBlocked is returned for a request the moderation check flagged, or could
+// not check, when its action is block.
+var ErrBlocked = errors.New("request blocked by content moderation")
+
+// Unchecked counts the requests sent with action warn that the classifier
+// failed to check.
+const Unchecked = "unchecked"
+
+// Provider wraps a provider so that the system prompt and messages of its
+// completions and streams are checked by a classifier before they are sent.
+// With action block a flagged request fails and is never sent; with warn it
+// is sent and counted. A skill's action, carried by the request's context,
+// overrides the provider's. Like the redact decorator it has no Unwrap
+// method, so that requests cannot be sent around it; As offers the wrapped
+// provider's capabilities checked instead.
+type Provider struct {
+	ports.ProviderPort
+	classifier ports.ModerationPort
+	action     string
+
+	mu     sync.Mutex
+	counts map[string]int // requests sent despite a flag, by category
+}
+
+// Ensure Provider implements ProviderPort, ThrottleReporter and Warmer at
+// compile time.
+var (
+	_ ports.ProviderPort     = (*Provider)(nil)
+	_ ports.ThrottleReporter = (*Provider)(nil)
+	_ ports.Warmer           = (*Provider)(nil)
+)
+
+// NewProvider wraps inner so its requests are checked by classifier; action
+// is block or warn.
+func NewProvider(inner ports.ProviderPort, classifier ports.ModerationPort, action string) *Provider {
+	return &Provider{ProviderPort: inner, classifier: classifier, action: action, counts: make(map[string]int)}
+}
+
+// Flags returns how many requests were sent despite being flagged, by
+// category, and how many could not be checked, as Unchecked.
+func (p *Provider) Flags() map[string]int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return maps.Clone(p.counts)
+}
+
+// Throttled reports whether the wrapped provider is being throttled.
+func (p *Provider) Throttled() bool {
+	t, ok := p.ProviderPort.(ports.ThrottleReporter)
+	return ok && t.Throttled()
+}
+
+// Warm prepares the model of the wrapped provider, if it supports warming.
+func (p *Provider) Warm(ctx context.Context, modelID string) error {
+	if w, ok := ports.UnwrapProvider(p.ProviderPort).(ports.Warmer); ok {
+		return w.Warm(ctx, modelID)
+	}
+	return nil
+}
+
+// Complete checks the request, then sends it.
+func (p *Provider) Complete(ctx context.Context, req ports.CompletionRequest) (*ports.CompletionResponse, error) {
+	if err := p.check(ctx, req); err != nil {
+		return nil, err
+	}
+	return p.ProviderPort.Complete(ctx, req)
+}
+
+// Stream checks the request, then sends it.
+func (p *Provider) Stream(ctx context.Context, req ports.CompletionRequest, cb ports.StreamCallback) (*ports.CompletionResponse, error) {
+	if err := p.check(ctx, req); err != nil {
+		return nil, err
+	}
+	return p.ProviderPort.Stream(ctx, req, cb)
+}
+
+// check classifies the prompts of req and returns an error if the request
+// must not be sent.
+func (p *Provider) check(ctx context.Context, req ports.CompletionRequest) error {
+	return p.checkText(ctx, requestText(req))
+}
+
+// checkText classifies text and returns an error if it must not be sent.
+func (p *Provider) checkText(ctx context.Context, text string) error {
+	action := p.action
+	if a := ports.ModerationFrom(ctx); a != "" {
+		action = a
+	}
+	if action == skill.ModerationOff {
+		return nil
+	}
+
+	result, err := p.classifier.Moderate(ctx, text)
+	if err != nil {
+		if action == skill.ModerationBlock {
+			return fmt.Errorf("%w: %s: moderation check failed: %w", ErrBlocked, p.Info().Name, err)
+		}
+		p.count(Unchecked)
+		return nil
+	}
+	if !result.Flagged {
+		return nil
+	}
+
+	categories := result.Categories
+	if len(categories) == 0 {
+		categories = []string{"flagged"}
+	}
+	if action == skill.ModerationBlock {
+		return fmt.Errorf("%w: %s: flagged for %s", ErrBlocked, p.Info().Name, strings.Join(categories, ", "))
+	}
+	p.count(categories...)
+	return nil
+}
+
+// count records a request sent despite being flagged for categories.
+func (p *Provider) count(categories ...string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, c := range categories {
+		p.counts[c]++
+	}
+}
+
+// requestText returns the prompts of req as one text.
+func requestText(req ports.CompletionRequest) string {
+	parts := make([]string, 0, len(req.Messages)+1)
+	if req.SystemPrompt != "" {
+		parts = append(parts, req.SystemPrompt)
+	}
+	for _, msg := range req.Messages {
+		if msg.Content != "" {
+			parts = append(parts, msg.Content)
+		}
+	}
+	return strings.Join(parts, "\n\n")
+}
@@ -0,0 +1,188 @@
+package moderation
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/jbctechsolutions/skillrunner/internal/application/ports"
+	"github.com/jbctechsolutions/skillrunner/internal/domain/skill"
+)
+
+// countingProvider counts the requests it received.
+type countingProvider struct {
+	ports.ProviderPort
+	calls int
+}
+
+func (c *countingProvider) Info() ports.ProviderInfo {
+	return ports.ProviderInfo{Name: "groq"}
+}
+
+func (c *countingProvider) Complete(context.Context, ports.CompletionRequest) (*ports.CompletionResponse, error) {
+	c.calls++
+	return &ports.CompletionResponse{Content: "ok"}, nil
+}
+
+// flagWord flags text containing "attack" for violence.
+var flagWord = ClassifierFunc(func(_ context.Context, text string) (*ports.ModerationResult, error) {
+	if strings.Contains(text, "attack") {
+		return &ports.ModerationResult{Flagged: true, Categories: []string{"violence"}}, nil
+	}
+	return &ports.ModerationResult{}, nil
+})
+
+func TestProvider_Block(t *testing.T) {
+	inner := &countingProvider{}
+	p := NewProvider(inner, flagWord, skill.ModerationBlock)
+
+	req := ports.CompletionRequest{SystemPrompt: "Be brief", Messages: []ports.Message{{Role: "user", Content: "Plan the attack"}}}
+	_, err := p.Complete(context.Background(), req)
+	if !errors.Is(err, ErrBlocked) || !strings.Contains(err.Error(), "groq: flagged for violence") {
+		t.Errorf("Complete() error = %v, want blocked for violence", err)
+	}
+	if inner.calls != 0 {
+		t.Error("a blocked request was sent")
+	}
+
+	req.Messages[0].Content = "Plan the picnic"
+	if _, err := p.Complete(context.Background(), req); err != nil || inner.calls != 1 {
+		t.Errorf("Complete() error = %v after %d calls, want a sent request", err, inner.calls)
+	}
+	if ports.UnwrapProvider(p) != ports.ProviderPort(p) {
+		t.Error("UnwrapProvider() should not reach past the moderation")
+	}
+}
+
+func TestProvider_WarnAndSkillOverride(t *testing.T) {
+	inner := &countingProvider{}
+	p := NewProvider(inner, flagWord, skill.ModerationWarn)
+	req := ports.CompletionRequest{Messages: []ports.Message{{Role: "user", Content: "Plan the attack"}}}
+
+	if _, err := p.Complete(context.Background(), req); err != nil || inner.calls != 1 {
+		t.Fatalf("Complete() error = %v after %d calls, want a sent request", err, inner.calls)
+	}
+	if got := p.Flags(); !reflect.DeepEqual(got, map[string]int{"violence": 1}) {
+		t.Errorf("Flags() = %v, want violence 1", got)
+	}
+
+	// A skill's action overrides the provider's
+	ctx := ports.WithModeration(context.Background(), skill.ModerationBlock)
+	if _, err := p.Complete(ctx, req); !errors.Is(err, ErrBlocked) {
+		t.Errorf("Complete() error = %v, want %v", err, ErrBlocked)
+	}
+	ctx = ports.WithModeration(context.Background(), skill.ModerationOff)
+	if _, err := p.Complete(ctx, req); err != nil {
+		t.Errorf("Complete() error = %v, want nil", err)
+	}
+	if got := p.Flags()["violence"]; got != 1 {
+		t.Errorf("Flags() counted %d flagged requests, want 1", got)
+	}
+}
+
+func TestProvider_ClassifierFails(t *testing.T) {
+	failing := ClassifierFunc(func(context.Context, string) (*ports.ModerationResult, error) {
+		return nil, errors.New("connection refused")
+	})
+	req := ports.CompletionRequest{Messages: []ports.Message{{Role: "user", Content: "hi"}}}
+
+	inner := &countingProvider{}
+	if _, err := NewProvider(inner, failing, skill.ModerationBlock).Complete(context.Background(), req); !errors.Is(err, ErrBlocked) {
+		t.Errorf("Complete() error = %v, want the request blocked", err)
+	}
+
+	warn := NewProvider(inner, failing, skill.ModerationWarn)
+	if _, err := warn.Complete(context.Background(), req); err != nil || inner.calls != 1 {
+		t.Errorf("Complete() error = %v after %d calls, want a sent request", err, inner.calls)
+	}
+	if got := warn.Flags()[Unchecked]; got != 1 {
+		t.Errorf("Flags()[%s] = %d, want 1", Unchecked, got)
+	}
+}
+
+func TestLocalClassifier(t *testing.T) {
+	tests := []struct {
+		answer string
+		want   *ports.ModerationResult
+	}{
+		{"safe", &ports.ModerationResult{}},
+		{"\nunsafe\nS1,S10", &ports.ModerationResult{Flagged: true, Categories: []string{"violent_crimes", "hate"}}},
+		{"unsafe\nS99", &ports.ModerationResult{Flagged: true, Categories: []string{"S99"}}},
+	}
+	for _, tt := range tests {
+		inner := &answeringProvider{answer: tt.answer}
+		got, err := NewLocalClassifier(inner, "llama-guard3").Moderate(context.Background(), "text")
+		if err != nil {
+			t.Fatalf("Moderate(%q) error = %v", tt.answer, err)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Moderate(%q) = %+v, want %+v", tt.answer, got, tt.want)
+		}
+		if inner.model != "llama-guard3" {
+			t.Errorf("model = %q, want llama-guard3", inner.model)
+		}
+	}
+
+	if _, err := NewLocalClassifier(&answeringProvider{answer: "I cannot help"}, "m").Moderate(context.Background(), "text"); err == nil {
+		t.Error("Moderate() of an unexpected answer succeeded")
+	}
+}
+
+// answeringProvider answers every request with answer.
+type answeringProvider struct {
+	ports.ProviderPort
+	answer string
+	model  string
+}
+
+func (a *answeringProvider) Complete(_ context.Context, req ports.CompletionRequest) (*ports.CompletionResponse, error) {
+	a.model = req.ModelID
+	return &ports.CompletionResponse{Content: a.answer}, nil
+}
+
+// batchProvider records the batch items submitted.
+type batchProvider struct {
+	countingProvider
+	items []ports.BatchItem
+}
+
+func (b *batchProvider) SubmitBatch(_ context.Context, items []ports.BatchItem) (*ports.BatchJob, error) {
+	b.items = append(b.items, items...)
+	return &ports.BatchJob{ID: "job-1"}, nil
+}
+
+func (b *batchProvider) GetBatch(context.Context, string) (*ports.BatchJob, error) {
+	return &ports.BatchJob{ID: "job-1", Status: ports.BatchJobCompleted}, nil
+}
+
+func (b *batchProvider) GetBatchResults(context.Context, string) ([]ports.BatchItemResult, error) {
+	return nil, nil
+}
+
+func TestProvider_BatchBlocksFlaggedItems(t *testing.T) {
+	inner := &batchProvider{}
+	p := NewProvider(inner, flagWord, skill.ModerationBlock)
+	batcher, ok := ports.As[ports.BatchProviderPort](p)
+	if !ok {
+		t.Fatal("As() did not find the batch API under the moderation")
+	}
+
+	item := func(id, prompt string) ports.BatchItem {
+		return ports.BatchItem{CustomID: id, Request: ports.CompletionRequest{Messages: []ports.Message{{Role: "user", Content: prompt}}}}
+	}
+	if _, err := batcher.SubmitBatch(context.Background(), []ports.BatchItem{item("a", "Plan the attack"), item("b", "Plan the picnic")}); err != nil {
+		t.Fatalf("SubmitBatch() error = %v", err)
+	}
+	if len(inner.items) != 1 || inner.items[0].CustomID != "b" {
+		t.Errorf("submitted %v, want only the unflagged item", inner.items)
+	}
+
+	if _, err := batcher.SubmitBatch(context.Background(), []ports.BatchItem{item("c", "Plan the attack")}); !errors.Is(err, ErrBlocked) {
+		t.Errorf("SubmitBatch() of flagged items error = %v, want %v", err, ErrBlocked)
+	}
+	if len(inner.items) != 1 {
+		t.Error("a flagged item reached SubmitBatch")
+	}
+}
@@ -34,6 +34,14 @@ func WithHTTPClient(client *http.Client) ClientOption {
 	}
 }
 
+// WithTransport sets the HTTP transport, such as one from the transport
+// package that routes requests through a proxy. Nil keeps the default.
+func WithTransport(rt http.RoundTripper) ClientOption {
+	return func(c *Client) {
+		c.httpClient.Transport = rt
+	}
+}
+
 // WithTimeout sets the HTTP client timeout
 func WithTimeout(timeout time.Duration) ClientOption {
 	return func(c *Client) {
@@ -46,7 +54,7 @@ func NewClient(opts ...ClientOption) *Client {
 	c := &Client{
 		baseURL: DefaultBaseURL,
 		httpClient: &http.Client{
-			Timeout: 30 * time.Second,
+			Timeout: DefaultTimeout,
 		},
 	}
 
@@ -115,6 +123,13 @@ func (c *Client) Chat(ctx context.Context, chatReq *ChatRequest) (*ChatResponse,
 	return &chatResp, nil
 }
 
+// Load loads a model into memory without generating, by sending a chat
+// request without messages.
+func (c *Client) Load(ctx context.Context, model string) error {
+	_, err := c.Chat(ctx, &ChatRequest{Model: model, Messages: []ChatMessage{}})
+	return err
+}
+
 // StreamCallback is called for each chunk in a streaming response
 type StreamCallback func(response *ChatResponse) error
 
@@ -2,6 +2,8 @@ package ollama
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
 	"strings"
 	"time"
 
@@ -103,6 +105,8 @@ func (p *Provider) Complete(ctx context.Context, req ports.CompletionRequest) (*
 			Temperature: req.Temperature,
 			NumPredict:  req.MaxTokens,
 		},
+		Format: convertFormat(req.ResponseFormat),
+		Tools:  convertTools(req.Tools, req.ToolChoice),
 	}
 
 	chatResp, err := p.client.Chat(ctx, chatReq)
@@ -117,6 +121,7 @@ func (p *Provider) Complete(ctx context.Context, req ports.CompletionRequest) (*
 		FinishReason: chatResp.DoneReason,
 		ModelUsed:    chatResp.Model,
 		Duration:     time.Since(startTime),
+		ToolCalls:    fromToolCalls(chatResp.Message.ToolCalls, 0),
 	}, nil
 }
 
@@ -133,12 +138,17 @@ func (p *Provider) Stream(ctx context.Context, req ports.CompletionRequest, cb p
 			Temperature: req.Temperature,
 			NumPredict:  req.MaxTokens,
 		},
+		Format: convertFormat(req.ResponseFormat),
+		Tools:  convertTools(req.Tools, req.ToolChoice),
 	}
 
 	var fullContent strings.Builder
+	var toolCalls []ports.ToolCall
 
 	finalResp, err := p.client.ChatStream(ctx, chatReq, func(chunk *ChatResponse) error {
 		fullContent.WriteString(chunk.Message.Content)
+		// Ollama streams each tool call whole rather than in fragments
+		toolCalls = append(toolCalls, fromToolCalls(chunk.Message.ToolCalls, len(toolCalls))...)
 		if cb != nil {
 			return cb(chunk.Message.Content)
 		}
@@ -153,6 +163,7 @@ func (p *Provider) Stream(ctx context.Context, req ports.CompletionRequest, cb p
 			Content:   fullContent.String(),
 			ModelUsed: req.ModelID,
 			Duration:  time.Since(startTime),
+			ToolCalls: toolCalls,
 		}, nil
 	}
 
@@ -163,9 +174,16 @@ func (p *Provider) Stream(ctx context.Context, req ports.CompletionRequest, cb p
 		FinishReason: finalResp.DoneReason,
 		ModelUsed:    finalResp.Model,
 		Duration:     time.Since(startTime),
+		ToolCalls:    toolCalls,
 	}, nil
 }
 
+// Warm loads the model into memory so its first request does not wait for
+// it to load.
+func (p *Provider) Warm(ctx context.Context, modelID string) error {
+	return p.client.Load(ctx, modelID)
+}
+
 // HealthCheck checks the health of the provider for a specific model
 func (p *Provider) HealthCheck(ctx context.Context, modelID string) (*ports.HealthStatus, error) {
 	startTime := time.Now()
@@ -224,14 +242,75 @@ func convertMessages(messages []ports.Message, systemPrompt string) []ChatMessag
 
 	for _, msg := range messages {
 		result = append(result, ChatMessage{
-			Role:    msg.Role,
-			Content: msg.Content,
+			Role:      msg.Role,
+			Content:   msg.Content,
+			ToolCalls: toToolCalls(msg.ToolCalls),
 		})
 	}
 
 	return result
 }
 
+// convertTools converts tools to Ollama function tools. Ollama has no
+// tool_choice, so ToolChoiceNone sends no tools and ToolChoiceTool sends
+// only the named tool; the model is never forced to call one.
+func convertTools(tools []ports.Tool, choice *ports.ToolChoice) []Tool {
+	if choice != nil && choice.Mode == ports.ToolChoiceNone {
+		return nil
+	}
+	result := make([]Tool, 0, len(tools))
+	for _, t := range tools {
+		if choice != nil && choice.Mode == ports.ToolChoiceTool && t.Name != choice.Name {
+			continue
+		}
+		result = append(result, Tool{
+			Type:     "function",
+			Function: Function{Name: t.Name, Description: t.Description, Parameters: t.InputSchema},
+		})
+	}
+	if len(result) == 0 {
+		return nil
+	}
+	return result
+}
+
+// toToolCalls converts tool calls from a previous assistant turn.
+func toToolCalls(calls []ports.ToolCall) []ToolCall {
+	if len(calls) == 0 {
+		return nil
+	}
+	result := make([]ToolCall, 0, len(calls))
+	for _, c := range calls {
+		args := c.Arguments
+		if len(args) == 0 {
+			args = json.RawMessage("{}")
+		}
+		result = append(result, ToolCall{Function: FunctionCall{Name: c.Name, Arguments: args}})
+	}
+	return result
+}
+
+// fromToolCalls converts Ollama tool calls to provider-agnostic tool calls.
+// Ollama does not assign call IDs, so they are numbered from offset.
+func fromToolCalls(calls []ToolCall, offset int) []ports.ToolCall {
+	if len(calls) == 0 {
+		return nil
+	}
+	result := make([]ports.ToolCall, 0, len(calls))
+	for i, c := range calls {
+		args := c.Function.Arguments
+		if len(args) == 0 {
+			args = json.RawMessage("{}")
+		}
+		result = append(result, ports.ToolCall{
+			ID:        fmt.Sprintf("call_%d", offset+i),
+			Name:      c.Function.Name,
+			Arguments: args,
+		})
+	}
+	return result
+}
+
 // normalizeModelID normalizes model IDs for comparison
 // Ollama models can have tags like "llama2:latest" or just "llama2"
 func normalizeModelID(modelID string) string {
@@ -242,5 +321,27 @@ func normalizeModelID(modelID string) string {
 	return id
 }
 
-// Ensure Provider implements ProviderPort
-var _ ports.ProviderPort = (*Provider)(nil)
+// Ensure Provider implements ProviderPort and Warmer
+var (
+	_ ports.ProviderPort = (*Provider)(nil)
+	_ ports.Warmer       = (*Provider)(nil)
+)
+
+// convertFormat converts a structured output request to Ollama's format field:
+// "json" for JSON mode, or the schema itself for structured outputs.
+func convertFormat(format *ports.ResponseFormat) json.RawMessage {
+	if format == nil {
+		return nil
+	}
+	switch format.Type {
+	case ports.ResponseFormatJSON:
+		return json.RawMessage(`"json"`)
+	case ports.ResponseFormatJSONSchema:
+		if len(format.Schema) > 0 {
+			return format.Schema
+		}
+		return json.RawMessage(`"json"`)
+	default:
+		return nil
+	}
+}
@@ -240,6 +240,33 @@ func TestProvider_Complete_Error(t *testing.T) {
 	}
 }
 
+func TestProvider_Warm(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != EndpointChat {
+			t.Errorf("expected path '%s', got '%s'", EndpointChat, r.URL.Path)
+		}
+
+		var req ChatRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Fatalf("failed to decode request: %v", err)
+		}
+		if req.Model != "llama2" {
+			t.Errorf("expected model 'llama2', got '%s'", req.Model)
+		}
+		if len(req.Messages) != 0 {
+			t.Errorf("expected no messages, got %d", len(req.Messages))
+		}
+
+		json.NewEncoder(w).Encode(ChatResponse{Model: "llama2", Done: true})
+	}))
+	defer server.Close()
+
+	p := NewProviderWithURL(server.URL)
+	if err := p.Warm(context.Background(), "llama2"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
 func TestProvider_Stream(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if r.URL.Path != EndpointChat {
@@ -628,3 +655,114 @@ func TestClient_ParseError(t *testing.T) {
 		t.Errorf("expected 404 in error, got: %v", err)
 	}
 }
+
+func TestProvider_Complete_ResponseFormat(t *testing.T) {
+	var gotFormat json.RawMessage
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req ChatRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Fatalf("failed to decode request: %v", err)
+		}
+		gotFormat = req.Format
+
+		json.NewEncoder(w).Encode(ChatResponse{
+			Model:   "llama2",
+			Message: ChatMessage{Role: "assistant", Content: `{"ok":true}`},
+			Done:    true,
+		})
+	}))
+	defer server.Close()
+
+	p := NewProviderWithURL(server.URL)
+	schema := `{"type":"object","properties":{"ok":{"type":"boolean"}}}`
+
+	tests := []struct {
+		name   string
+		format *ports.ResponseFormat
+		want   string
+	}{
+		{"text", nil, ""},
+		{"json mode", &ports.ResponseFormat{Type: ports.ResponseFormatJSON}, `"json"`},
+		{"schema", &ports.ResponseFormat{Type: ports.ResponseFormatJSONSchema, Schema: json.RawMessage(schema)}, schema},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := p.Complete(context.Background(), ports.CompletionRequest{
+				ModelID:        "llama2",
+				Messages:       []ports.Message{{Role: "user", Content: "Hello"}},
+				ResponseFormat: tt.format,
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if string(gotFormat) != tt.want {
+				t.Errorf("format = %s, want %s", gotFormat, tt.want)
+			}
+		})
+	}
+}
+
+func TestProvider_Complete_Tools(t *testing.T) {
+	var got ChatRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = ChatRequest{}
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Fatalf("failed to decode request: %v", err)
+		}
+		json.NewEncoder(w).Encode(ChatResponse{
+			Model: "llama3.1",
+			Message: ChatMessage{Role: "assistant", ToolCalls: []ToolCall{
+				{Function: FunctionCall{Name: "get_weather", Arguments: json.RawMessage(`{"city":"Rome"}`)}},
+			}},
+			Done: true,
+		})
+	}))
+	defer server.Close()
+
+	p := NewProviderWithURL(server.URL)
+	tools := []ports.Tool{
+		{Name: "get_weather", InputSchema: json.RawMessage(`{"type":"object"}`)},
+		{Name: "get_time"},
+	}
+
+	resp, err := p.Complete(context.Background(), ports.CompletionRequest{
+		ModelID: "llama3.1",
+		Messages: []ports.Message{
+			{Role: "user", Content: "Weather in Paris?"},
+			{Role: "assistant", ToolCalls: []ports.ToolCall{
+				{ID: "call_0", Name: "get_weather", Arguments: json.RawMessage(`{"city":"Paris"}`)},
+			}},
+			{Role: "tool", ToolCallID: "call_0", Content: "18C"},
+		},
+		Tools: tools,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(got.Tools) != 2 || got.Tools[0].Type != "function" || string(got.Tools[0].Function.Parameters) != `{"type":"object"}` {
+		t.Errorf("unexpected tools: %+v", got.Tools)
+	}
+	if calls := got.Messages[1].ToolCalls; len(calls) != 1 || string(calls[0].Function.Arguments) != `{"city":"Paris"}` {
+		t.Errorf("unexpected assistant tool calls: %+v", calls)
+	}
+	if len(resp.ToolCalls) != 1 || resp.ToolCalls[0].ID != "call_0" || string(resp.ToolCalls[0].Arguments) != `{"city":"Rome"}` {
+		t.Errorf("unexpected tool calls: %+v", resp.ToolCalls)
+	}
+
+	// Ollama has no tool_choice, so the choice narrows the tools sent
+	for _, tt := range []struct {
+		choice *ports.ToolChoice
+		want   int
+	}{
+		{&ports.ToolChoice{Mode: ports.ToolChoiceNone}, 0},
+		{&ports.ToolChoice{Mode: ports.ToolChoiceTool, Name: "get_time"}, 1},
+	} {
+		if _, err := p.Complete(context.Background(), ports.CompletionRequest{ModelID: "llama3.1", Tools: tools, ToolChoice: tt.choice}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(got.Tools) != tt.want {
+			t.Errorf("%s: sent %d tools, want %d", tt.choice.Mode, len(got.Tools), tt.want)
+		}
+	}
+}
@@ -1,10 +1,16 @@
 package ollama
 
-import "time"
+import (
+	"encoding/json"
+	"time"
+)
 
 // DefaultBaseURL is the default Ollama API endpoint
 const DefaultBaseURL = "http://localhost:11434"
 
+// DefaultTimeout is the default HTTP client timeout
+const DefaultTimeout = 30 * time.Second
+
 // API endpoints
 const (
 	EndpointTags     = "/api/tags"
@@ -39,16 +45,44 @@ type Details struct {
 
 // ChatRequest represents a chat completion request
 type ChatRequest struct {
-	Model    string        `json:"model"`
-	Messages []ChatMessage `json:"messages"`
-	Stream   bool          `json:"stream"`
-	Options  *Options      `json:"options,omitempty"`
+	Model    string          `json:"model"`
+	Messages []ChatMessage   `json:"messages"`
+	Stream   bool            `json:"stream"`
+	Options  *Options        `json:"options,omitempty"`
+	Format   json.RawMessage `json:"format,omitempty"` // "json" or a JSON Schema object
+	Tools    []Tool          `json:"tools,omitempty"`
 }
 
 // ChatMessage represents a message in a chat conversation
 type ChatMessage struct {
-	Role    string `json:"role"`
-	Content string `json:"content"`
+	Role      string     `json:"role"`
+	Content   string     `json:"content"`
+	ToolCalls []ToolCall `json:"tool_calls,omitempty"`
+}
+
+// Tool represents a function the model may call
+type Tool struct {
+	Type     string   `json:"type"` // Always "function"
+	Function Function `json:"function"`
+}
+
+// Function describes a callable function
+type Function struct {
+	Name        string          `json:"name"`
+	Description string          `json:"description,omitempty"`
+	Parameters  json.RawMessage `json:"parameters,omitempty"`
+}
+
+// ToolCall represents a function call requested by the model.
+// Ollama does not assign call IDs, and sends arguments as a JSON object.
+type ToolCall struct {
+	Function FunctionCall `json:"function"`
+}
+
+// FunctionCall contains the function name and its arguments
+type FunctionCall struct {
+	Name      string          `json:"name"`
+	Arguments json.RawMessage `json:"arguments"`
 }
 
 // Options for model configuration
@@ -0,0 +1,154 @@
+package openai
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/jbctechsolutions/skillrunner/internal/application/ports"
+	"github.com/jbctechsolutions/skillrunner/internal/domain/errors"
+)
+
+// Batch API settings.
+const (
+	batchEndpoint         = "/v1/chat/completions" // Endpoint every batch line targets
+	batchCompletionWindow = "24h"                  // The only window OpenAI offers
+	batchInputFileName    = "skillrunner-batch.jsonl"
+)
+
+// Ensure Provider implements BatchProviderPort at compile time.
+var _ ports.BatchProviderPort = (*Provider)(nil)
+
+// SubmitBatch uploads items as a JSONL input file and creates a batch job.
+func (p *Provider) SubmitBatch(ctx context.Context, items []ports.BatchItem) (*ports.BatchJob, error) {
+	if len(items) == 0 {
+		return nil, errors.NewError(errors.CodeValidation, "batch has no items", nil)
+	}
+
+	var input bytes.Buffer
+	encoder := json.NewEncoder(&input)
+	for _, item := range items {
+		line := BatchInputLine{
+			CustomID: item.CustomID,
+			Method:   http.MethodPost,
+			URL:      batchEndpoint,
+			Body:     p.buildRequest(item.Request),
+		}
+		if err := encoder.Encode(line); err != nil {
+			return nil, errors.NewError(errors.CodeProvider, "failed to encode batch item", err)
+		}
+	}
+
+	file, err := p.client.UploadBatchFile(ctx, batchInputFileName, input.Bytes())
+	if err != nil {
+		return nil, err
+	}
+
+	batch, err := p.client.CreateBatch(ctx, &CreateBatchRequest{
+		InputFileID:      file.ID,
+		Endpoint:         batchEndpoint,
+		CompletionWindow: batchCompletionWindow,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return toBatchJob(batch), nil
+}
+
+// GetBatch returns the current state of a batch job.
+func (p *Provider) GetBatch(ctx context.Context, jobID string) (*ports.BatchJob, error) {
+	batch, err := p.client.GetBatch(ctx, jobID)
+	if err != nil {
+		return nil, err
+	}
+	return toBatchJob(batch), nil
+}
+
+// GetBatchResults downloads and parses the output and error files of a
+// finished batch job.
+func (p *Provider) GetBatchResults(ctx context.Context, jobID string) ([]ports.BatchItemResult, error) {
+	batch, err := p.client.GetBatch(ctx, jobID)
+	if err != nil {
+		return nil, err
+	}
+	if !ports.BatchJobStatus(batch.Status).IsTerminal() {
+		return nil, errors.NewError(errors.CodeValidation,
+			fmt.Sprintf("batch %s is still %s", jobID, batch.Status), nil)
+	}
+
+	var results []ports.BatchItemResult
+	for _, fileID := range []string{batch.OutputFileID, batch.ErrorFileID} {
+		if fileID == "" {
+			continue
+		}
+
+		data, err := p.client.GetFileContent(ctx, fileID)
+		if err != nil {
+			return nil, err
+		}
+
+		parsed, err := p.parseBatchOutput(data)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, parsed...)
+	}
+
+	return results, nil
+}
+
+// parseBatchOutput converts the lines of a batch output or error file.
+func (p *Provider) parseBatchOutput(data []byte) ([]ports.BatchItemResult, error) {
+	var results []ports.BatchItemResult
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+
+	for scanner.Scan() {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+
+		var line BatchOutputLine
+		if err := json.Unmarshal(scanner.Bytes(), &line); err != nil {
+			return nil, errors.NewError(errors.CodeProvider, "failed to parse batch output", err)
+		}
+
+		result := ports.BatchItemResult{CustomID: line.CustomID}
+		switch {
+		case line.Error != nil:
+			result.Error = line.Error.Message
+		case line.Response == nil:
+			result.Error = "missing response"
+		case line.Response.StatusCode != http.StatusOK:
+			result.Error = fmt.Sprintf("HTTP %d", line.Response.StatusCode)
+		default:
+			// Batch requests have no meaningful per-request duration
+			result.Response = p.buildResponse(&line.Response.Body, time.Now())
+			result.Response.Duration = 0
+		}
+		results = append(results, result)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, errors.NewError(errors.CodeProvider, "failed to read batch output", err)
+	}
+
+	return results, nil
+}
+
+// toBatchJob converts an OpenAI batch to a provider-agnostic job.
+func toBatchJob(batch *Batch) *ports.BatchJob {
+	return &ports.BatchJob{
+		ID:        batch.ID,
+		Status:    ports.BatchJobStatus(batch.Status),
+		Total:     batch.RequestCounts.Total,
+		Completed: batch.RequestCounts.Completed,
+		Failed:    batch.RequestCounts.Failed,
+	}
+}
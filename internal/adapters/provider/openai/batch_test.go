@@ -0,0 +1,134 @@
+package openai
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/jbctechsolutions/skillrunner/internal/application/ports"
+)
+
+func TestProvider_SubmitBatch(t *testing.T) {
+	var uploaded []BatchInputLine
+	var created CreateBatchRequest
+
+	server, provider := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/files":
+			if err := r.ParseMultipartForm(1 << 20); err != nil {
+				t.Fatalf("failed to parse upload: %v", err)
+			}
+			if purpose := r.FormValue("purpose"); purpose != "batch" {
+				t.Errorf("purpose = %q, want batch", purpose)
+			}
+			file, _, err := r.FormFile("file")
+			if err != nil {
+				t.Fatalf("missing file: %v", err)
+			}
+			scanner := bufio.NewScanner(file)
+			for scanner.Scan() {
+				var line BatchInputLine
+				if err := json.Unmarshal(scanner.Bytes(), &line); err != nil {
+					t.Fatalf("invalid input line: %v", err)
+				}
+				uploaded = append(uploaded, line)
+			}
+			_ = json.NewEncoder(w).Encode(FileObject{ID: "file-in"})
+		case "/batches":
+			_ = json.NewDecoder(r.Body).Decode(&created)
+			_ = json.NewEncoder(w).Encode(Batch{ID: "batch_1", Status: "validating"})
+		default:
+			t.Errorf("unexpected request to %s", r.URL.Path)
+		}
+	})
+	defer server.Close()
+
+	job, err := provider.SubmitBatch(context.Background(), []ports.BatchItem{
+		{CustomID: "a", Request: ports.CompletionRequest{ModelID: "gpt-4o-mini", Messages: []ports.Message{{Role: "user", Content: "one"}}}},
+		{CustomID: "b", Request: ports.CompletionRequest{ModelID: "gpt-4o-mini", Messages: []ports.Message{{Role: "user", Content: "two"}}}},
+	})
+	if err != nil {
+		t.Fatalf("SubmitBatch failed: %v", err)
+	}
+
+	if job.ID != "batch_1" || job.Status != ports.BatchJobValidating {
+		t.Errorf("job = %+v", job)
+	}
+	if len(uploaded) != 2 || uploaded[0].CustomID != "a" || uploaded[1].URL != batchEndpoint {
+		t.Errorf("uploaded lines = %+v", uploaded)
+	}
+	if uploaded[1].Body.Messages[0].Content != "two" {
+		t.Errorf("second line body = %+v", uploaded[1].Body)
+	}
+	if created.InputFileID != "file-in" || created.CompletionWindow != batchCompletionWindow {
+		t.Errorf("create request = %+v", created)
+	}
+}
+
+func TestProvider_SubmitBatch_Empty(t *testing.T) {
+	provider := NewProvider(Config{APIKey: "test-api-key"})
+	if _, err := provider.SubmitBatch(context.Background(), nil); err == nil {
+		t.Error("expected error for an empty batch")
+	}
+}
+
+func TestProvider_GetBatchResults(t *testing.T) {
+	output := strings.Join([]string{
+		`{"id":"r1","custom_id":"a","response":{"status_code":200,"body":{"id":"c1","model":"gpt-4o-mini","choices":[{"index":0,"message":{"role":"assistant","content":"first"},"finish_reason":"stop"}],"usage":{"prompt_tokens":3,"completion_tokens":4,"total_tokens":7}}}}`,
+		`{"id":"r2","custom_id":"b","response":{"status_code":429,"body":{}}}`,
+	}, "\n")
+	errorsFile := `{"id":"r3","custom_id":"c","error":{"message":"invalid model","type":"invalid_request_error"}}`
+
+	server, provider := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/batches/batch_1":
+			_ = json.NewEncoder(w).Encode(Batch{
+				ID:           "batch_1",
+				Status:       "completed",
+				OutputFileID: "file-out",
+				ErrorFileID:  "file-err",
+			})
+		case "/files/file-out/content":
+			_, _ = io.WriteString(w, output)
+		case "/files/file-err/content":
+			_, _ = io.WriteString(w, errorsFile)
+		default:
+			t.Errorf("unexpected request to %s", r.URL.Path)
+		}
+	})
+	defer server.Close()
+
+	results, err := provider.GetBatchResults(context.Background(), "batch_1")
+	if err != nil {
+		t.Fatalf("GetBatchResults failed: %v", err)
+	}
+	if len(results) != 3 {
+		t.Fatalf("expected 3 results, got %d", len(results))
+	}
+
+	if r := results[0]; r.Response == nil || r.Response.Content != "first" || r.Response.InputTokens != 3 || r.Response.OutputTokens != 4 {
+		t.Errorf("result a = %+v", r)
+	}
+	if r := results[1]; r.Response != nil || r.Error != "HTTP 429" {
+		t.Errorf("result b = %+v", r)
+	}
+	if r := results[2]; r.CustomID != "c" || r.Error != "invalid model" {
+		t.Errorf("result c = %+v", r)
+	}
+}
+
+func TestProvider_GetBatchResults_NotFinished(t *testing.T) {
+	server, provider := newTestServer(t, func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = fmt.Fprint(w, `{"id":"batch_1","status":"in_progress"}`)
+	})
+	defer server.Close()
+
+	if _, err := provider.GetBatchResults(context.Background(), "batch_1"); err == nil {
+		t.Error("expected error for a running batch")
+	}
+}
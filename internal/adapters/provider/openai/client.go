@@ -1,24 +1,23 @@
 package openai
 
 import (
-	"bufio"
-	"bytes"
 	"context"
-	"encoding/json"
-	"fmt"
 	"io"
 	"net/http"
-	"strconv"
+	"net/url"
 	"strings"
 	"time"
 
-	"github.com/jbctechsolutions/skillrunner/internal/domain/errors"
+	"github.com/jbctechsolutions/skillrunner/internal/adapters/provider/openaicompat"
 )
 
 // Client handles HTTP communication with the OpenAI API.
+// Transport concerns (retries, error mapping, SSE parsing) are delegated to
+// the shared openaicompat client.
 type Client struct {
 	httpClient *http.Client
 	config     Config
+	base       *openaicompat.Client
 }
 
 // ClientOption is a functional option for configuring the Client.
@@ -64,7 +63,8 @@ func WithOrganization(org string) ClientOption {
 func NewClient(config Config, opts ...ClientOption) *Client {
 	c := &Client{
 		httpClient: &http.Client{
-			Timeout: config.Timeout,
+			Timeout:   config.Timeout,
+			Transport: config.Transport,
 		},
 		config: config,
 	}
@@ -73,6 +73,21 @@ func NewClient(config Config, opts ...ClientOption) *Client {
 		opt(c)
 	}
 
+	var headers map[string]string
+	if c.config.Organization != "" {
+		headers = map[string]string{"OpenAI-Organization": c.config.Organization}
+	}
+
+	c.base = openaicompat.NewClient(openaicompat.Config{
+		APIKey:         c.config.APIKey,
+		BaseURL:        c.config.BaseURL,
+		Headers:        headers,
+		Timeout:        c.config.Timeout,
+		MaxRetries:     c.config.MaxRetries,
+		RetryBaseDelay: c.config.RetryBaseDelay,
+		RetryMaxDelay:  c.config.RetryMaxDelay,
+	}, openaicompat.WithHTTPClient(c.httpClient))
+
 	return c
 }
 
@@ -80,26 +95,15 @@ func NewClient(config Config, opts ...ClientOption) *Client {
 func (c *Client) Chat(ctx context.Context, req *ChatCompletionRequest) (*ChatCompletionResponse, *RateLimitInfo, error) {
 	req.Stream = false
 
-	body, err := json.Marshal(req)
-	if err != nil {
-		return nil, nil, errors.NewError(errors.CodeProvider, "failed to marshal request", err)
-	}
-
-	resp, err := c.doRequestWithRetry(ctx, http.MethodPost, "/chat/completions", body)
-	if err != nil {
+	var result ChatCompletionResponse
+	headers, err := c.base.PostJSON(ctx, openaicompat.EndpointChatCompletions, req, &result)
+	if headers == nil {
 		return nil, nil, err
 	}
-	defer resp.Body.Close()
 
-	rateLimitInfo := c.parseRateLimitHeaders(resp.Header)
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, rateLimitInfo, c.handleErrorResponse(resp)
-	}
-
-	var result ChatCompletionResponse
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return nil, rateLimitInfo, errors.NewError(errors.CodeProvider, "failed to decode response", err)
+	rateLimitInfo := c.parseRateLimitHeaders(headers)
+	if err != nil {
+		return nil, rateLimitInfo, err
 	}
 
 	return &result, rateLimitInfo, nil
@@ -112,240 +116,98 @@ func (c *Client) ChatStream(ctx context.Context, req *ChatCompletionRequest, cal
 		req.StreamOptions = &StreamOptions{IncludeUsage: true}
 	}
 
-	body, err := json.Marshal(req)
-	if err != nil {
-		return nil, errors.NewError(errors.CodeProvider, "failed to marshal request", err)
-	}
-
-	// For streaming, we don't retry as it's a long-running operation
-	httpReq, err := c.newRequest(ctx, http.MethodPost, "/chat/completions", body)
-	if err != nil {
+	headers, err := c.base.Stream(ctx, openaicompat.EndpointChatCompletions, req, openaicompat.DecodeSSE(callback))
+	if headers == nil {
 		return nil, err
 	}
 
-	resp, err := c.httpClient.Do(httpReq)
-	if err != nil {
-		return nil, errors.NewError(errors.CodeProvider, "request failed", err)
-	}
-	defer resp.Body.Close()
-
-	rateLimitInfo := c.parseRateLimitHeaders(resp.Header)
-
-	if resp.StatusCode != http.StatusOK {
-		return rateLimitInfo, c.handleErrorResponse(resp)
-	}
-
-	return rateLimitInfo, c.parseSSEStream(resp.Body, callback)
+	return c.parseRateLimitHeaders(headers), err
 }
 
 // parseSSEStream parses the Server-Sent Events stream from OpenAI.
 func (c *Client) parseSSEStream(reader io.Reader, callback func(chunk *StreamChunk) error) error {
-	scanner := bufio.NewScanner(reader)
-	// Set a larger buffer for potentially large SSE messages
-	buf := make([]byte, 0, 64*1024)
-	scanner.Buffer(buf, 1024*1024)
-
-	for scanner.Scan() {
-		line := scanner.Text()
-
-		// Skip empty lines
-		if line == "" {
-			continue
-		}
-
-		// Check for data prefix
-		data, found := strings.CutPrefix(line, "data: ")
-		if !found {
-			continue
-		}
-
-		// Handle [DONE] sentinel
-		if data == "[DONE]" {
-			return nil
-		}
-
-		var chunk StreamChunk
-		if err := json.Unmarshal([]byte(data), &chunk); err != nil {
-			return errors.NewError(errors.CodeProvider, "failed to parse SSE chunk", err)
-		}
-
-		if err := callback(&chunk); err != nil {
-			return err
-		}
-	}
-
-	if err := scanner.Err(); err != nil {
-		return errors.NewError(errors.CodeProvider, "error reading SSE stream", err)
-	}
-
-	return nil
+	return openaicompat.ParseSSE(reader, openaicompat.DecodeSSE(callback))
 }
 
 // ListModels retrieves the list of available models from the OpenAI API.
 func (c *Client) ListModels(ctx context.Context) (*ModelsResponse, error) {
-	resp, err := c.doRequestWithRetry(ctx, http.MethodGet, "/models", nil)
-	if err != nil {
+	var result ModelsResponse
+	if _, err := c.base.GetJSON(ctx, openaicompat.EndpointModels, &result); err != nil {
 		return nil, err
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, c.handleErrorResponse(resp)
-	}
+	return &result, nil
+}
 
-	var result ModelsResponse
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return nil, errors.NewError(errors.CodeProvider, "failed to decode models response", err)
+// UploadBatchFile uploads a JSONL batch input file.
+func (c *Client) UploadBatchFile(ctx context.Context, name string, data []byte) (*FileObject, error) {
+	var result FileObject
+	fields := map[string]string{"purpose": "batch"}
+	if _, err := c.base.PostMultipart(ctx, openaicompat.EndpointFiles, fields, "file", name, data, &result); err != nil {
+		return nil, err
 	}
 
 	return &result, nil
 }
 
-// doRequestWithRetry performs an HTTP request with exponential backoff retry.
-func (c *Client) doRequestWithRetry(ctx context.Context, method, path string, body []byte) (*http.Response, error) {
-	var lastErr error
-	delay := c.config.RetryBaseDelay
-	if delay == 0 {
-		delay = 500 * time.Millisecond
-	}
-
-	for attempt := 0; attempt <= c.config.MaxRetries; attempt++ {
-		if attempt > 0 {
-			select {
-			case <-ctx.Done():
-				return nil, ctx.Err()
-			case <-time.After(delay):
-			}
-			// Exponential backoff with cap
-			delay *= 2
-			if c.config.RetryMaxDelay > 0 && delay > c.config.RetryMaxDelay {
-				delay = c.config.RetryMaxDelay
-			}
-		}
-
-		req, err := c.newRequest(ctx, method, path, body)
-		if err != nil {
-			return nil, err
-		}
-
-		resp, err := c.httpClient.Do(req)
-		if err != nil {
-			lastErr = errors.NewError(errors.CodeProvider, "request failed", err)
-			continue
-		}
-
-		// Check for retryable status codes (429 Too Many Requests, 5xx Server Errors)
-		if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500 {
-			// Check for Retry-After header
-			if retryAfter := resp.Header.Get("Retry-After"); retryAfter != "" {
-				if seconds, err := strconv.Atoi(retryAfter); err == nil {
-					delay = time.Duration(seconds) * time.Second
-				}
-			}
-			resp.Body.Close()
-			lastErr = fmt.Errorf("HTTP %d", resp.StatusCode)
-			continue
-		}
-
-		return resp, nil
+// CreateBatch creates a batch job from an uploaded input file.
+func (c *Client) CreateBatch(ctx context.Context, req *CreateBatchRequest) (*Batch, error) {
+	var result Batch
+	if _, err := c.base.PostJSON(ctx, openaicompat.EndpointBatches, req, &result); err != nil {
+		return nil, err
 	}
 
-	return nil, errors.NewError(errors.CodeProvider,
-		fmt.Sprintf("request failed after %d retries", c.config.MaxRetries+1), lastErr)
+	return &result, nil
 }
 
-// newRequest creates a new HTTP request with required headers.
-func (c *Client) newRequest(ctx context.Context, method, path string, body []byte) (*http.Request, error) {
-	url := c.config.BaseURL + path
-
-	var bodyReader io.Reader
-	if body != nil {
-		bodyReader = bytes.NewReader(body)
+// GetBatch retrieves a batch job.
+func (c *Client) GetBatch(ctx context.Context, batchID string) (*Batch, error) {
+	var result Batch
+	if _, err := c.base.GetJSON(ctx, openaicompat.EndpointBatches+"/"+url.PathEscape(batchID), &result); err != nil {
+		return nil, err
 	}
 
-	req, err := http.NewRequestWithContext(ctx, method, url, bodyReader)
-	if err != nil {
-		return nil, errors.NewError(errors.CodeProvider, "failed to create request", err)
-	}
+	return &result, nil
+}
 
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", "Bearer "+c.config.APIKey)
+// GetFileContent downloads the content of a file, such as a batch output file.
+func (c *Client) GetFileContent(ctx context.Context, fileID string) ([]byte, error) {
+	return c.base.GetRaw(ctx, openaicompat.EndpointFiles+"/"+url.PathEscape(fileID)+"/content")
+}
 
-	if c.config.Organization != "" {
-		req.Header.Set("OpenAI-Organization", c.config.Organization)
+// Transcribe uploads an audio file to the transcriptions endpoint.
+func (c *Client) Transcribe(ctx context.Context, fields map[string]string, name string, data []byte) (*TranscriptionResponse, error) {
+	var result TranscriptionResponse
+	if _, err := c.base.PostMultipart(ctx, openaicompat.EndpointAudioTranscriptions, fields, "file", name, data, &result); err != nil {
+		return nil, err
 	}
 
-	return req, nil
+	return &result, nil
 }
 
-// handleErrorResponse extracts error information from an error response.
-func (c *Client) handleErrorResponse(resp *http.Response) error {
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return errors.NewError(errors.CodeProvider,
-			fmt.Sprintf("HTTP %d: failed to read error response", resp.StatusCode), err)
-	}
-
-	var errResp ErrorResponse
-	if err := json.Unmarshal(body, &errResp); err != nil {
-		// If we can't parse the error, return the raw body
-		return errors.NewError(errors.CodeProvider,
-			fmt.Sprintf("HTTP %d: %s", resp.StatusCode, string(body)), nil)
+// GenerateImages calls the image generations endpoint.
+func (c *Client) GenerateImages(ctx context.Context, req *ImageGenerationRequest) (*ImageGenerationResponse, error) {
+	var result ImageGenerationResponse
+	if _, err := c.base.PostJSON(ctx, openaicompat.EndpointImageGenerations, req, &result); err != nil {
+		return nil, err
 	}
 
-	errCode := errors.CodeProvider
-	switch resp.StatusCode {
-	case http.StatusUnauthorized, http.StatusForbidden:
-		errCode = errors.CodeConfiguration
-	case http.StatusNotFound:
-		errCode = errors.CodeNotFound
-	case http.StatusBadRequest, http.StatusUnprocessableEntity:
-		errCode = errors.CodeValidation
-	}
+	return &result, nil
+}
 
-	errType := errResp.Error.Type
-	if errType == "" {
-		errType = "error"
+// Moderate calls the moderations endpoint.
+func (c *Client) Moderate(ctx context.Context, req *ModerationRequest) (*ModerationResponse, error) {
+	var result ModerationResponse
+	if _, err := c.base.PostJSON(ctx, openaicompat.EndpointModerations, req, &result); err != nil {
+		return nil, err
 	}
 
-	return errors.NewError(errCode,
-		fmt.Sprintf("%s: %s", errType, errResp.Error.Message), nil)
+	return &result, nil
 }
 
 // parseRateLimitHeaders extracts rate limit information from response headers.
 func (c *Client) parseRateLimitHeaders(headers http.Header) *RateLimitInfo {
-	info := &RateLimitInfo{}
-
-	if v := headers.Get("x-ratelimit-limit-requests"); v != "" {
-		info.LimitRequests, _ = strconv.Atoi(v)
-	}
-	if v := headers.Get("x-ratelimit-limit-tokens"); v != "" {
-		info.LimitTokens, _ = strconv.Atoi(v)
-	}
-	if v := headers.Get("x-ratelimit-remaining-requests"); v != "" {
-		info.RemainingRequests, _ = strconv.Atoi(v)
-	}
-	if v := headers.Get("x-ratelimit-remaining-tokens"); v != "" {
-		info.RemainingTokens, _ = strconv.Atoi(v)
-	}
-	if v := headers.Get("x-ratelimit-reset-requests"); v != "" {
-		info.ResetRequests = parseDuration(v)
-	}
-	if v := headers.Get("x-ratelimit-reset-tokens"); v != "" {
-		info.ResetTokens = parseDuration(v)
-	}
-
-	return info
-}
-
-// parseDuration parses OpenAI's duration format (e.g., "1s", "100ms", "6m0s")
-// and returns the time when the rate limit resets.
-func parseDuration(s string) time.Time {
-	d, err := time.ParseDuration(s)
-	if err != nil {
-		return time.Time{}
-	}
-	return time.Now().Add(d)
+	return openaicompat.ParseRateLimitHeaders(headers)
 }
 
 // HealthCheck performs a lightweight check to verify API connectivity.
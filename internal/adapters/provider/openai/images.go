@@ -0,0 +1,68 @@
+package openai
+
+import (
+	"context"
+	"encoding/base64"
+
+	"github.com/jbctechsolutions/skillrunner/internal/application/ports"
+	"github.com/jbctechsolutions/skillrunner/internal/domain/errors"
+)
+
+// Image models.
+const (
+	ModelDallE3 = "dall-e-3"
+	ModelDallE2 = "dall-e-2"
+)
+
+// Ensure Provider implements ImageGenerationPort at compile time.
+var _ ports.ImageGenerationPort = (*Provider)(nil)
+
+// GenerateImages generates images with the Images API. DALL·E 3 returns one
+// image per request, so larger counts are requested one at a time.
+func (p *Provider) GenerateImages(ctx context.Context, req ports.ImageGenerationRequest) (*ports.ImageGenerationResponse, error) {
+	if _, _, err := req.Dimensions(0, 0); err != nil {
+		return nil, errors.NewError(errors.CodeValidation, err.Error(), nil)
+	}
+
+	model := req.Model
+	if model == "" {
+		model = ModelDallE3
+	}
+	count := max(req.Count, 1)
+
+	perRequest := count
+	if model == ModelDallE3 {
+		perRequest = 1
+	}
+
+	resp := &ports.ImageGenerationResponse{ModelUsed: model}
+	for len(resp.Images) < count {
+		result, err := p.client.GenerateImages(ctx, &ImageGenerationRequest{
+			Model:          model,
+			Prompt:         req.Prompt,
+			N:              min(perRequest, count-len(resp.Images)),
+			Size:           req.Size,
+			ResponseFormat: "b64_json",
+		})
+		if err != nil {
+			return nil, err
+		}
+		if len(result.Data) == 0 {
+			return nil, errors.NewError(errors.CodeProvider, "image generation returned no images", nil)
+		}
+
+		for _, img := range result.Data {
+			data, err := base64.StdEncoding.DecodeString(img.B64JSON)
+			if err != nil {
+				return nil, errors.NewError(errors.CodeProvider, "failed to decode generated image", err)
+			}
+			resp.Images = append(resp.Images, ports.GeneratedImage{
+				Data:          data,
+				Format:        "png",
+				RevisedPrompt: img.RevisedPrompt,
+			})
+		}
+	}
+
+	return resp, nil
+}
@@ -0,0 +1,95 @@
+package openai
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/jbctechsolutions/skillrunner/internal/application/ports"
+)
+
+func TestProvider_GenerateImages(t *testing.T) {
+	var requests []ImageGenerationRequest
+
+	server, provider := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/images/generations" {
+			t.Errorf("unexpected request to %s", r.URL.Path)
+		}
+		var req ImageGenerationRequest
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		requests = append(requests, req)
+
+		resp := ImageGenerationResponse{}
+		for range req.N {
+			resp.Data = append(resp.Data, ImageData{
+				B64JSON:       base64.StdEncoding.EncodeToString([]byte("png-bytes")),
+				RevisedPrompt: "a lighthouse at dusk, oil painting",
+			})
+		}
+		_ = json.NewEncoder(w).Encode(resp)
+	})
+	defer server.Close()
+
+	resp, err := provider.GenerateImages(context.Background(), ports.ImageGenerationRequest{
+		Prompt: "a lighthouse at dusk",
+		Size:   "1024x1024",
+		Count:  2,
+	})
+	if err != nil {
+		t.Fatalf("GenerateImages failed: %v", err)
+	}
+
+	// DALL·E 3 only returns one image per request
+	if len(requests) != 2 || requests[0].N != 1 || requests[0].Model != ModelDallE3 {
+		t.Errorf("requests = %+v", requests)
+	}
+	if requests[0].ResponseFormat != "b64_json" || requests[0].Size != "1024x1024" {
+		t.Errorf("request = %+v", requests[0])
+	}
+	if len(resp.Images) != 2 || string(resp.Images[0].Data) != "png-bytes" || resp.Images[0].Format != "png" {
+		t.Errorf("images = %+v", resp.Images)
+	}
+	if resp.Images[1].RevisedPrompt == "" || resp.ModelUsed != ModelDallE3 {
+		t.Errorf("response = %+v", resp)
+	}
+}
+
+func TestProvider_GenerateImages_DallE2Batches(t *testing.T) {
+	calls := 0
+	server, provider := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		var req ImageGenerationRequest
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		resp := ImageGenerationResponse{}
+		for range req.N {
+			resp.Data = append(resp.Data, ImageData{B64JSON: base64.StdEncoding.EncodeToString([]byte("x"))})
+		}
+		_ = json.NewEncoder(w).Encode(resp)
+	})
+	defer server.Close()
+
+	resp, err := provider.GenerateImages(context.Background(), ports.ImageGenerationRequest{
+		Prompt: "icons",
+		Model:  ModelDallE2,
+		Count:  3,
+	})
+	if err != nil {
+		t.Fatalf("GenerateImages failed: %v", err)
+	}
+	if calls != 1 || len(resp.Images) != 3 {
+		t.Errorf("expected 3 images from 1 request, got %d images from %d requests", len(resp.Images), calls)
+	}
+}
+
+func TestProvider_GenerateImages_InvalidSize(t *testing.T) {
+	provider := NewProvider(Config{APIKey: "test-api-key"})
+	_, err := provider.GenerateImages(context.Background(), ports.ImageGenerationRequest{
+		Prompt: "x",
+		Size:   "large",
+	})
+	if err == nil {
+		t.Error("expected error for an invalid size")
+	}
+}
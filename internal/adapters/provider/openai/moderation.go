@@ -0,0 +1,39 @@
+package openai
+
+import (
+	"context"
+	"slices"
+
+	"github.com/jbctechsolutions/skillrunner/internal/application/ports"
+)
+
+// ModelOmniModeration is the default moderation model.
+const ModelOmniModeration = "omni-moderation-latest"
+
+// Ensure Provider implements ModerationPort at compile time.
+var _ ports.ModerationPort = (*Provider)(nil)
+
+// Moderate classifies text with the moderation endpoint's default model.
+func (p *Provider) Moderate(ctx context.Context, text string) (*ports.ModerationResult, error) {
+	return p.ModerateWith(ctx, ModelOmniModeration, text)
+}
+
+// ModerateWith classifies text with the given moderation model.
+func (p *Provider) ModerateWith(ctx context.Context, model, text string) (*ports.ModerationResult, error) {
+	resp, err := p.client.Moderate(ctx, &ModerationRequest{Model: model, Input: text})
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ports.ModerationResult{}
+	for _, r := range resp.Results {
+		result.Flagged = result.Flagged || r.Flagged
+		for category, flagged := range r.Categories {
+			if flagged && !slices.Contains(result.Categories, category) {
+				result.Categories = append(result.Categories, category)
+			}
+		}
+	}
+	slices.Sort(result.Categories)
+	return result, nil
+}
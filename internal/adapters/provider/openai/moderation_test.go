@@ -0,0 +1,37 @@
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestProvider_Moderate(t *testing.T) {
+	server, provider := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/moderations" {
+			t.Errorf("unexpected request to %s", r.URL.Path)
+		}
+		var req ModerationRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Fatalf("failed to decode request: %v", err)
+		}
+		if req.Model != ModelOmniModeration || req.Input != "Plan the attack" {
+			t.Errorf("request = %+v", req)
+		}
+		_ = json.NewEncoder(w).Encode(ModerationResponse{Results: []ModerationOutput{{
+			Flagged:    true,
+			Categories: map[string]bool{"violence": true, "hate": false, "harassment": true},
+		}}})
+	})
+	defer server.Close()
+
+	result, err := provider.Moderate(context.Background(), "Plan the attack")
+	if err != nil {
+		t.Fatalf("Moderate failed: %v", err)
+	}
+	if !result.Flagged || !reflect.DeepEqual(result.Categories, []string{"harassment", "violence"}) {
+		t.Errorf("result = %+v, want flagged for harassment and violence", result)
+	}
+}
@@ -2,6 +2,7 @@ package openai
 
 import (
 	"context"
+	"encoding/json"
 	"slices"
 	"strings"
 	"time"
@@ -15,8 +16,11 @@ type Provider struct {
 	config Config
 }
 
-// Ensure Provider implements ProviderPort at compile time.
-var _ ports.ProviderPort = (*Provider)(nil)
+// Ensure Provider implements ProviderPort and Warmer at compile time.
+var (
+	_ ports.ProviderPort = (*Provider)(nil)
+	_ ports.Warmer       = (*Provider)(nil)
+)
 
 // NewProvider creates a new OpenAI provider with the given configuration.
 func NewProvider(config Config) *Provider {
@@ -31,6 +35,12 @@ func NewProviderWithAPIKey(apiKey string) *Provider {
 	return NewProvider(DefaultConfig(apiKey))
 }
 
+// OnRateLimit registers fn to receive the rate limit headers of the
+// provider's responses.
+func (p *Provider) OnRateLimit(fn func(*RateLimitInfo)) {
+	p.client.base.OnRateLimit(fn)
+}
+
 // Info returns metadata about this provider.
 func (p *Provider) Info() ports.ProviderInfo {
 	return ports.ProviderInfo{
@@ -91,6 +101,7 @@ func (p *Provider) Stream(ctx context.Context, req ports.CompletionRequest, cb p
 	var inputTokens, outputTokens int
 	var finishReason string
 	var modelUsed string
+	var toolCalls []ToolCall
 
 	_, err := p.client.ChatStream(ctx, openaiReq, func(chunk *StreamChunk) error {
 		// Capture model from first chunk
@@ -108,6 +119,9 @@ func (p *Provider) Stream(ctx context.Context, req ports.CompletionRequest, cb p
 				}
 			}
 
+			// Assemble tool calls, whose arguments arrive in fragments
+			toolCalls = mergeToolCallDeltas(toolCalls, choice.Delta.ToolCalls)
+
 			// Capture finish reason
 			if choice.FinishReason != nil && *choice.FinishReason != "" {
 				finishReason = string(*choice.FinishReason)
@@ -133,9 +147,17 @@ func (p *Provider) Stream(ctx context.Context, req ports.CompletionRequest, cb p
 		FinishReason: finishReason,
 		ModelUsed:    modelUsed,
 		Duration:     time.Since(startTime),
+		ToolCalls:    fromToolCalls(toolCalls),
 	}, nil
 }
 
+// Warm opens the connection to the API so the first request skips the
+// TLS handshake. It lists the models, which consumes no tokens.
+func (p *Provider) Warm(ctx context.Context, _ string) error {
+	_, err := p.client.ListModels(ctx)
+	return err
+}
+
 // HealthCheck verifies the provider is healthy and responsive.
 func (p *Provider) HealthCheck(ctx context.Context, modelID string) (*ports.HealthStatus, error) {
 	startTime := time.Now()
@@ -196,19 +218,26 @@ func (p *Provider) buildRequest(req ports.CompletionRequest) *ChatCompletionRequ
 			role = RoleUser
 		case "assistant":
 			role = RoleAssistant
+		case "tool":
+			role = RoleTool
 		default:
 			role = MessageRole(msg.Role)
 		}
 
 		messages = append(messages, Message{
-			Role:    role,
-			Content: msg.Content,
+			Role:       role,
+			Content:    msg.Content,
+			ToolCalls:  toToolCalls(msg.ToolCalls),
+			ToolCallID: msg.ToolCallID,
 		})
 	}
 
 	openaiReq := &ChatCompletionRequest{
-		Model:    req.ModelID,
-		Messages: messages,
+		Model:          req.ModelID,
+		Messages:       messages,
+		Tools:          toTools(req.Tools),
+		ToolChoice:     toToolChoice(req.ToolChoice),
+		ResponseFormat: toResponseFormat(req.ResponseFormat),
 	}
 
 	// Add max tokens if specified
@@ -228,10 +257,12 @@ func (p *Provider) buildRequest(req ports.CompletionRequest) *ChatCompletionRequ
 func (p *Provider) buildResponse(resp *ChatCompletionResponse, startTime time.Time) *ports.CompletionResponse {
 	var content string
 	var finishReason string
+	var toolCalls []ports.ToolCall
 
 	if len(resp.Choices) > 0 {
 		content = resp.Choices[0].Message.Content
 		finishReason = string(resp.Choices[0].FinishReason)
+		toolCalls = fromToolCalls(resp.Choices[0].Message.ToolCalls)
 	}
 
 	return &ports.CompletionResponse{
@@ -241,5 +272,110 @@ func (p *Provider) buildResponse(resp *ChatCompletionResponse, startTime time.Ti
 		FinishReason: finishReason,
 		ModelUsed:    resp.Model,
 		Duration:     time.Since(startTime),
+		ToolCalls:    toolCalls,
+	}
+}
+
+// toTools converts provider-agnostic tools to OpenAI function tools.
+func toTools(tools []ports.Tool) []Tool {
+	if len(tools) == 0 {
+		return nil
+	}
+	result := make([]Tool, 0, len(tools))
+	for _, t := range tools {
+		fn := Function{Name: t.Name, Description: t.Description}
+		if len(t.InputSchema) > 0 {
+			fn.Parameters = t.InputSchema
+		}
+		result = append(result, Tool{Type: "function", Function: fn})
+	}
+	return result
+}
+
+// toToolChoice converts a tool choice to the OpenAI tool_choice value.
+func toToolChoice(choice *ports.ToolChoice) any {
+	if choice == nil {
+		return nil
+	}
+	if choice.Mode == ports.ToolChoiceTool {
+		return ToolChoiceFunction{Type: "function", Function: FunctionName{Name: choice.Name}}
+	}
+	return string(choice.Mode)
+}
+
+// toResponseFormat converts a structured output request to response_format.
+func toResponseFormat(format *ports.ResponseFormat) *ResponseFormat {
+	if format == nil || format.Type == "" {
+		return nil
+	}
+	if format.Type != ports.ResponseFormatJSONSchema {
+		return &ResponseFormat{Type: string(format.Type)}
+	}
+	name := format.Name
+	if name == "" {
+		name = "output"
+	}
+	return &ResponseFormat{
+		Type:       string(ports.ResponseFormatJSONSchema),
+		JSONSchema: &JSONSchemaFormat{Name: name, Schema: format.Schema},
+	}
+}
+
+// toToolCalls converts tool calls from a previous assistant turn.
+func toToolCalls(calls []ports.ToolCall) []ToolCall {
+	if len(calls) == 0 {
+		return nil
+	}
+	result := make([]ToolCall, 0, len(calls))
+	for _, c := range calls {
+		result = append(result, ToolCall{
+			ID:       c.ID,
+			Type:     "function",
+			Function: FunctionCall{Name: c.Name, Arguments: string(c.Arguments)},
+		})
+	}
+	return result
+}
+
+// fromToolCalls converts OpenAI tool calls to provider-agnostic tool calls.
+func fromToolCalls(calls []ToolCall) []ports.ToolCall {
+	if len(calls) == 0 {
+		return nil
+	}
+	result := make([]ports.ToolCall, 0, len(calls))
+	for _, c := range calls {
+		args := c.Function.Arguments
+		if args == "" {
+			args = "{}"
+		}
+		result = append(result, ports.ToolCall{
+			ID:        c.ID,
+			Name:      c.Function.Name,
+			Arguments: json.RawMessage(args),
+		})
+	}
+	return result
+}
+
+// mergeToolCallDeltas folds streamed tool call fragments into calls.
+// The first fragment of each call carries its ID and name; later fragments
+// with the same index append to its arguments.
+func mergeToolCallDeltas(calls []ToolCall, deltas []ToolCall) []ToolCall {
+	for _, d := range deltas {
+		i := len(calls)
+		if d.Index != nil {
+			i = *d.Index
+		}
+		for len(calls) <= i {
+			calls = append(calls, ToolCall{Type: "function"})
+		}
+		if d.ID != "" {
+			calls[i].ID = d.ID
+		}
+		if d.Function.Name != "" {
+			calls[i].Function.Name = d.Function.Name
+		}
+		calls[i].Function.Arguments += d.Function.Arguments
 	}
+	return calls
 }
@@ -983,3 +983,154 @@ func TestBuildRequest_ZeroTemperature(t *testing.T) {
 		t.Errorf("expected nil Temperature, got %f", *openaiReq.Temperature)
 	}
 }
+
+func TestBuildRequest_Tools(t *testing.T) {
+	provider := NewProviderWithAPIKey("test-key")
+
+	req := ports.CompletionRequest{
+		ModelID: ModelGPT4o,
+		Messages: []ports.Message{
+			{Role: "user", Content: "Weather in Paris?"},
+			{Role: "assistant", ToolCalls: []ports.ToolCall{
+				{ID: "call_1", Name: "get_weather", Arguments: json.RawMessage(`{"city":"Paris"}`)},
+			}},
+			{Role: "tool", ToolCallID: "call_1", Content: "18C"},
+		},
+		Tools: []ports.Tool{
+			{Name: "get_weather", Description: "Current weather", InputSchema: json.RawMessage(`{"type":"object"}`)},
+		},
+		ToolChoice: &ports.ToolChoice{Mode: ports.ToolChoiceTool, Name: "get_weather"},
+	}
+
+	openaiReq := provider.buildRequest(req)
+
+	if len(openaiReq.Tools) != 1 || openaiReq.Tools[0].Type != "function" || openaiReq.Tools[0].Function.Name != "get_weather" {
+		t.Errorf("unexpected tools: %+v", openaiReq.Tools)
+	}
+	choice, ok := openaiReq.ToolChoice.(ToolChoiceFunction)
+	if !ok || choice.Function.Name != "get_weather" {
+		t.Errorf("unexpected tool_choice: %#v", openaiReq.ToolChoice)
+	}
+
+	assistant := openaiReq.Messages[1]
+	if len(assistant.ToolCalls) != 1 || assistant.ToolCalls[0].Function.Arguments != `{"city":"Paris"}` {
+		t.Errorf("unexpected assistant tool calls: %+v", assistant.ToolCalls)
+	}
+	if toolMsg := openaiReq.Messages[2]; toolMsg.Role != RoleTool || toolMsg.ToolCallID != "call_1" {
+		t.Errorf("unexpected tool message: %+v", toolMsg)
+	}
+
+	req.ToolChoice = &ports.ToolChoice{Mode: ports.ToolChoiceRequired}
+	if got := provider.buildRequest(req).ToolChoice; got != "required" {
+		t.Errorf("expected tool_choice 'required', got %#v", got)
+	}
+}
+
+func TestProvider_Complete_ToolCalls(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		resp := ChatCompletionResponse{
+			Model: ModelGPT4o,
+			Choices: []Choice{{
+				Message: Message{
+					Role: RoleAssistant,
+					ToolCalls: []ToolCall{{
+						ID:       "call_1",
+						Type:     "function",
+						Function: FunctionCall{Name: "get_weather", Arguments: `{"city":"Paris"}`},
+					}},
+				},
+				FinishReason: FinishReasonToolCalls,
+			}},
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(resp)
+	}
+
+	server, provider := newTestServer(t, handler)
+	defer server.Close()
+
+	resp, err := provider.Complete(context.Background(), ports.CompletionRequest{
+		ModelID:  ModelGPT4o,
+		Messages: []ports.Message{{Role: "user", Content: "Weather in Paris?"}},
+	})
+	if err != nil {
+		t.Fatalf("Complete failed: %v", err)
+	}
+
+	if resp.FinishReason != "tool_calls" {
+		t.Errorf("expected finish reason 'tool_calls', got %q", resp.FinishReason)
+	}
+	if len(resp.ToolCalls) != 1 {
+		t.Fatalf("expected 1 tool call, got %d", len(resp.ToolCalls))
+	}
+	call := resp.ToolCalls[0]
+	if call.ID != "call_1" || call.Name != "get_weather" || string(call.Arguments) != `{"city":"Paris"}` {
+		t.Errorf("unexpected tool call: %+v", call)
+	}
+}
+
+func TestProvider_Stream_ToolCalls(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+
+		events := []string{
+			`data: {"id":"c","model":"gpt-4o","choices":[{"index":0,"delta":{"role":"assistant","tool_calls":[{"index":0,"id":"call_1","type":"function","function":{"name":"get_weather","arguments":""}}]}}]}`,
+			`data: {"id":"c","model":"gpt-4o","choices":[{"index":0,"delta":{"tool_calls":[{"index":0,"function":{"arguments":"{\"city\":"}}]}}]}`,
+			`data: {"id":"c","model":"gpt-4o","choices":[{"index":0,"delta":{"tool_calls":[{"index":0,"function":{"arguments":"\"Paris\"}"}}]}}]}`,
+			`data: {"id":"c","model":"gpt-4o","choices":[{"index":0,"delta":{},"finish_reason":"tool_calls"}]}`,
+			`data: [DONE]`,
+		}
+		for _, event := range events {
+			fmt.Fprintln(w, event)
+			fmt.Fprintln(w)
+		}
+	}
+
+	server, provider := newTestServer(t, handler)
+	defer server.Close()
+
+	resp, err := provider.Stream(context.Background(), ports.CompletionRequest{
+		ModelID:  ModelGPT4o,
+		Messages: []ports.Message{{Role: "user", Content: "Weather in Paris?"}},
+	}, func(string) error { return nil })
+	if err != nil {
+		t.Fatalf("Stream failed: %v", err)
+	}
+
+	if len(resp.ToolCalls) != 1 {
+		t.Fatalf("expected 1 tool call, got %d", len(resp.ToolCalls))
+	}
+	call := resp.ToolCalls[0]
+	if call.ID != "call_1" || call.Name != "get_weather" || string(call.Arguments) != `{"city":"Paris"}` {
+		t.Errorf("unexpected tool call: %+v", call)
+	}
+}
+
+func TestBuildRequest_ResponseFormat(t *testing.T) {
+	provider := NewProviderWithAPIKey("test-key")
+	schema := json.RawMessage(`{"type":"object"}`)
+
+	req := ports.CompletionRequest{
+		ModelID:        ModelGPT4o,
+		Messages:       []ports.Message{{Role: "user", Content: "Hello"}},
+		ResponseFormat: &ports.ResponseFormat{Type: ports.ResponseFormatJSONSchema, Schema: schema},
+	}
+
+	format := provider.buildRequest(req).ResponseFormat
+	if format == nil || format.Type != "json_schema" || format.JSONSchema == nil {
+		t.Fatalf("expected json_schema response format, got %+v", format)
+	}
+	if format.JSONSchema.Name != "output" || string(format.JSONSchema.Schema) != string(schema) {
+		t.Errorf("unexpected json_schema: %+v", format.JSONSchema)
+	}
+
+	req.ResponseFormat = &ports.ResponseFormat{Type: ports.ResponseFormatJSON}
+	if format := provider.buildRequest(req).ResponseFormat; format == nil || format.Type != "json_object" || format.JSONSchema != nil {
+		t.Errorf("expected json_object response format, got %+v", format)
+	}
+
+	req.ResponseFormat = nil
+	if format := provider.buildRequest(req).ResponseFormat; format != nil {
+		t.Errorf("expected no response format, got %+v", format)
+	}
+}
@@ -0,0 +1,54 @@
+package openai
+
+import (
+	"context"
+	"math"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/jbctechsolutions/skillrunner/internal/application/ports"
+	"github.com/jbctechsolutions/skillrunner/internal/domain/errors"
+)
+
+// ModelWhisper1 is the default speech-to-text model.
+const ModelWhisper1 = "whisper-1"
+
+// Ensure Provider implements TranscriptionPort at compile time.
+var _ ports.TranscriptionPort = (*Provider)(nil)
+
+// Transcribe transcribes an audio file with the Whisper API.
+func (p *Provider) Transcribe(ctx context.Context, req ports.TranscriptionRequest) (*ports.TranscriptionResponse, error) {
+	data, err := os.ReadFile(filepath.Clean(req.AudioPath))
+	if err != nil {
+		return nil, errors.NewError(errors.CodeValidation, "failed to read audio file", err)
+	}
+
+	model := req.Model
+	if model == "" {
+		model = ModelWhisper1
+	}
+
+	fields := map[string]string{
+		"model":           model,
+		"response_format": "verbose_json",
+	}
+	if req.Language != "" {
+		fields["language"] = req.Language
+	}
+	if req.Prompt != "" {
+		fields["prompt"] = req.Prompt
+	}
+
+	resp, err := p.client.Transcribe(ctx, fields, filepath.Base(req.AudioPath), data)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ports.TranscriptionResponse{
+		Text:      resp.Text,
+		Language:  resp.Language,
+		Duration:  time.Duration(math.Round(resp.Duration * float64(time.Second))),
+		ModelUsed: model,
+	}, nil
+}
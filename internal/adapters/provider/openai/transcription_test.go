@@ -0,0 +1,75 @@
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jbctechsolutions/skillrunner/internal/application/ports"
+)
+
+func TestProvider_Transcribe(t *testing.T) {
+	audio := filepath.Join(t.TempDir(), "standup.mp3")
+	if err := os.WriteFile(audio, []byte("fake audio"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	server, provider := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/audio/transcriptions" {
+			t.Errorf("unexpected request to %s", r.URL.Path)
+		}
+		if err := r.ParseMultipartForm(1 << 20); err != nil {
+			t.Fatalf("failed to parse upload: %v", err)
+		}
+		if got := r.FormValue("model"); got != ModelWhisper1 {
+			t.Errorf("model = %q, want %q", got, ModelWhisper1)
+		}
+		if got := r.FormValue("language"); got != "en" {
+			t.Errorf("language = %q, want en", got)
+		}
+		if got := r.FormValue("prompt"); got != "Skillrunner, Ollama" {
+			t.Errorf("prompt = %q", got)
+		}
+		file, header, err := r.FormFile("file")
+		if err != nil {
+			t.Fatalf("missing file: %v", err)
+		}
+		data, _ := io.ReadAll(file)
+		if header.Filename != "standup.mp3" || string(data) != "fake audio" {
+			t.Errorf("uploaded %q with content %q", header.Filename, data)
+		}
+		_ = json.NewEncoder(w).Encode(TranscriptionResponse{Text: "hello team", Language: "english", Duration: 12.5})
+	})
+	defer server.Close()
+
+	resp, err := provider.Transcribe(context.Background(), ports.TranscriptionRequest{
+		AudioPath: audio,
+		Language:  "en",
+		Prompt:    "Skillrunner, Ollama",
+	})
+	if err != nil {
+		t.Fatalf("Transcribe failed: %v", err)
+	}
+
+	if resp.Text != "hello team" || resp.Language != "english" || resp.ModelUsed != ModelWhisper1 {
+		t.Errorf("response = %+v", resp)
+	}
+	if resp.Duration != 12500*time.Millisecond {
+		t.Errorf("Duration = %v, want 12.5s", resp.Duration)
+	}
+}
+
+func TestProvider_Transcribe_MissingFile(t *testing.T) {
+	provider := NewProvider(Config{APIKey: "test-api-key"})
+	_, err := provider.Transcribe(context.Background(), ports.TranscriptionRequest{
+		AudioPath: filepath.Join(t.TempDir(), "missing.wav"),
+	})
+	if err == nil {
+		t.Error("expected error for a missing audio file")
+	}
+}
@@ -1,7 +1,13 @@
 // Package openai provides an adapter for the OpenAI Chat Completions API.
 package openai
 
-import "time"
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/jbctechsolutions/skillrunner/internal/adapters/provider/openaicompat"
+)
 
 // MessageRole represents the role of a message participant.
 type MessageRole string
@@ -34,8 +40,9 @@ type Message struct {
 
 // ToolCall represents a tool/function call requested by the model.
 type ToolCall struct {
-	ID       string       `json:"id"`
-	Type     string       `json:"type"`
+	Index    *int         `json:"index,omitempty"` // Set on streaming deltas only
+	ID       string       `json:"id,omitempty"`
+	Type     string       `json:"type,omitempty"`
 	Function FunctionCall `json:"function"`
 }
 
@@ -77,6 +84,17 @@ type Tool struct {
 	Function Function `json:"function"`
 }
 
+// ToolChoiceFunction forces the model to call a specific function.
+type ToolChoiceFunction struct {
+	Type     string       `json:"type"` // Always "function"
+	Function FunctionName `json:"function"`
+}
+
+// FunctionName identifies a function by name.
+type FunctionName struct {
+	Name string `json:"name"`
+}
+
 // Function describes a function that can be called.
 type Function struct {
 	Name        string `json:"name"`
@@ -86,7 +104,15 @@ type Function struct {
 
 // ResponseFormat specifies the format of the response.
 type ResponseFormat struct {
-	Type string `json:"type"` // "text" or "json_object"
+	Type       string            `json:"type"` // "text", "json_object", or "json_schema"
+	JSONSchema *JSONSchemaFormat `json:"json_schema,omitempty"`
+}
+
+// JSONSchemaFormat constrains the response to a JSON Schema.
+type JSONSchemaFormat struct {
+	Name   string          `json:"name"`
+	Schema json.RawMessage `json:"schema"`
+	Strict bool            `json:"strict,omitempty"`
 }
 
 // ChatCompletionResponse is the response body from the OpenAI Chat Completions API.
@@ -172,6 +198,112 @@ type ModelsResponse struct {
 	Data   []Model `json:"data"`
 }
 
+// FileObject is an uploaded file.
+type FileObject struct {
+	ID       string `json:"id"`
+	Object   string `json:"object"`
+	Bytes    int64  `json:"bytes"`
+	Filename string `json:"filename"`
+	Purpose  string `json:"purpose"`
+}
+
+// CreateBatchRequest is the request body for creating a batch job.
+type CreateBatchRequest struct {
+	InputFileID      string `json:"input_file_id"`
+	Endpoint         string `json:"endpoint"`
+	CompletionWindow string `json:"completion_window"`
+}
+
+// Batch is a batch job.
+type Batch struct {
+	ID            string             `json:"id"`
+	Object        string             `json:"object"`
+	Endpoint      string             `json:"endpoint"`
+	Status        string             `json:"status"`
+	InputFileID   string             `json:"input_file_id"`
+	OutputFileID  string             `json:"output_file_id,omitempty"`
+	ErrorFileID   string             `json:"error_file_id,omitempty"`
+	CreatedAt     int64              `json:"created_at"`
+	RequestCounts BatchRequestCounts `json:"request_counts"`
+}
+
+// BatchRequestCounts tracks the progress of a batch job.
+type BatchRequestCounts struct {
+	Total     int `json:"total"`
+	Completed int `json:"completed"`
+	Failed    int `json:"failed"`
+}
+
+// BatchInputLine is one line of a batch input file.
+type BatchInputLine struct {
+	CustomID string                 `json:"custom_id"`
+	Method   string                 `json:"method"`
+	URL      string                 `json:"url"`
+	Body     *ChatCompletionRequest `json:"body"`
+}
+
+// BatchOutputLine is one line of a batch output or error file.
+type BatchOutputLine struct {
+	ID       string               `json:"id"`
+	CustomID string               `json:"custom_id"`
+	Response *BatchOutputResponse `json:"response"`
+	Error    *ErrorInfo           `json:"error"`
+}
+
+// BatchOutputResponse is the HTTP response recorded for a batch request.
+type BatchOutputResponse struct {
+	StatusCode int                    `json:"status_code"`
+	Body       ChatCompletionResponse `json:"body"`
+}
+
+// TranscriptionResponse is the verbose_json response of the audio
+// transcriptions endpoint.
+type TranscriptionResponse struct {
+	Text     string  `json:"text"`
+	Language string  `json:"language,omitempty"`
+	Duration float64 `json:"duration,omitempty"` // Seconds
+}
+
+// ImageGenerationRequest is the request body for the image generations endpoint.
+type ImageGenerationRequest struct {
+	Model          string `json:"model"`
+	Prompt         string `json:"prompt"`
+	N              int    `json:"n,omitempty"`
+	Size           string `json:"size,omitempty"`
+	ResponseFormat string `json:"response_format,omitempty"`
+}
+
+// ImageGenerationResponse is the response of the image generations endpoint.
+type ImageGenerationResponse struct {
+	Created int64       `json:"created"`
+	Data    []ImageData `json:"data"`
+}
+
+// ImageData is one generated image.
+type ImageData struct {
+	B64JSON       string `json:"b64_json"`
+	RevisedPrompt string `json:"revised_prompt,omitempty"`
+}
+
+// ModerationRequest is the request body for the moderations endpoint.
+type ModerationRequest struct {
+	Model string `json:"model"`
+	Input string `json:"input"`
+}
+
+// ModerationResponse is the response of the moderations endpoint.
+type ModerationResponse struct {
+	ID      string             `json:"id"`
+	Model   string             `json:"model"`
+	Results []ModerationOutput `json:"results"`
+}
+
+// ModerationOutput is the verdict on one input.
+type ModerationOutput struct {
+	Flagged    bool            `json:"flagged"`
+	Categories map[string]bool `json:"categories"`
+}
+
 // Model represents an OpenAI model.
 type Model struct {
 	ID      string `json:"id"`
@@ -189,6 +321,10 @@ type Config struct {
 	MaxRetries     int
 	RetryBaseDelay time.Duration
 	RetryMaxDelay  time.Duration
+
+	// Transport overrides the HTTP transport, e.g. to use a proxy or
+	// custom CAs (see the transport package); nil uses the default.
+	Transport http.RoundTripper
 }
 
 // DefaultConfig returns a Config with default values.
@@ -254,11 +390,4 @@ func IsSupportedModel(modelID string) bool {
 }
 
 // RateLimitInfo contains rate limit information from response headers.
-type RateLimitInfo struct {
-	LimitRequests     int       // x-ratelimit-limit-requests
-	LimitTokens       int       // x-ratelimit-limit-tokens
-	RemainingRequests int       // x-ratelimit-remaining-requests
-	RemainingTokens   int       // x-ratelimit-remaining-tokens
-	ResetRequests     time.Time // x-ratelimit-reset-requests
-	ResetTokens       time.Time // x-ratelimit-reset-tokens
-}
+type RateLimitInfo = openaicompat.RateLimitInfo
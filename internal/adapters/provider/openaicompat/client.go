@@ -0,0 +1,470 @@
+package openaicompat
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/jbctechsolutions/skillrunner/internal/domain/errors"
+)
+
+// Client handles HTTP communication with an OpenAI-compatible API.
+// It owns request construction, authentication, retries, error mapping and
+// Server-Sent Events parsing so that individual provider adapters only need
+// to supply their own wire types and defaults.
+type Client struct {
+	httpClient  *http.Client
+	config      Config
+	onRateLimit atomic.Pointer[func(*RateLimitInfo)]
+}
+
+// ClientOption is a functional option for configuring the Client.
+type ClientOption func(*Client)
+
+// WithHTTPClient sets a custom HTTP client for the Client.
+func WithHTTPClient(httpClient *http.Client) ClientOption {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// WithTimeout sets the HTTP client timeout.
+func WithTimeout(timeout time.Duration) ClientOption {
+	return func(c *Client) {
+		c.config.Timeout = timeout
+		c.httpClient.Timeout = timeout
+	}
+}
+
+// WithTransport sets the HTTP transport, such as one from the transport
+// package that routes requests through a proxy. Nil keeps the default.
+func WithTransport(rt http.RoundTripper) ClientOption {
+	return func(c *Client) {
+		c.httpClient.Transport = rt
+	}
+}
+
+// WithMaxRetries sets the maximum number of retries.
+func WithMaxRetries(maxRetries int) ClientOption {
+	return func(c *Client) {
+		c.config.MaxRetries = maxRetries
+	}
+}
+
+// WithBaseURL sets the base URL for API requests.
+func WithBaseURL(baseURL string) ClientOption {
+	return func(c *Client) {
+		c.config.BaseURL = strings.TrimSuffix(baseURL, "/")
+	}
+}
+
+// WithHeader adds a static header that is sent with every request.
+func WithHeader(key, value string) ClientOption {
+	return func(c *Client) {
+		if c.config.Headers == nil {
+			c.config.Headers = make(map[string]string)
+		}
+		c.config.Headers[key] = value
+	}
+}
+
+// NewClient creates a new OpenAI-compatible API client with functional options.
+func NewClient(config Config, opts ...ClientOption) *Client {
+	c := &Client{
+		httpClient: &http.Client{
+			Timeout:   config.Timeout,
+			Transport: config.Transport,
+		},
+		config: config,
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return c
+}
+
+// OnRateLimit registers fn to receive the rate limit headers of every
+// response that carries them, including 429 responses that are retried.
+// It replaces any previously registered callback; nil removes it.
+func (c *Client) OnRateLimit(fn func(*RateLimitInfo)) {
+	if fn == nil {
+		c.onRateLimit.Store(nil)
+		return
+	}
+	c.onRateLimit.Store(&fn)
+}
+
+// reportRateLimits passes the rate limit headers of a response to the
+// OnRateLimit callback, if any.
+func (c *Client) reportRateLimits(headers http.Header) {
+	fn := c.onRateLimit.Load()
+	if fn == nil || !hasRateLimitHeaders(headers) {
+		return
+	}
+	(*fn)(ParseRateLimitHeaders(headers))
+}
+
+// Config returns a copy of the client configuration.
+func (c *Client) Config() Config {
+	return c.config
+}
+
+// PostJSON marshals req, POSTs it to path with retries and decodes a
+// successful response into out. The response headers are returned even when
+// the request fails with an HTTP error so callers can inspect rate limits.
+func (c *Client) PostJSON(ctx context.Context, path string, req, out any) (http.Header, error) {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, errors.NewError(errors.CodeProvider, "failed to marshal request", err)
+	}
+
+	return c.doJSON(ctx, http.MethodPost, path, contentTypeJSON, body, out)
+}
+
+// GetJSON performs a GET request against path with retries and decodes a
+// successful response into out.
+func (c *Client) GetJSON(ctx context.Context, path string, out any) (http.Header, error) {
+	return c.doJSON(ctx, http.MethodGet, path, "", nil, out)
+}
+
+// PostMultipart uploads data as a file part named fileField, together with
+// the given form fields, to path with retries and decodes the JSON response
+// into out.
+func (c *Client) PostMultipart(ctx context.Context, path string, fields map[string]string, fileField, fileName string, data []byte, out any) (http.Header, error) {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+
+	for key, value := range fields {
+		if err := writer.WriteField(key, value); err != nil {
+			return nil, errors.NewError(errors.CodeProvider, "failed to build multipart request", err)
+		}
+	}
+
+	part, err := writer.CreateFormFile(fileField, fileName)
+	if err != nil {
+		return nil, errors.NewError(errors.CodeProvider, "failed to build multipart request", err)
+	}
+	if _, err := part.Write(data); err != nil {
+		return nil, errors.NewError(errors.CodeProvider, "failed to build multipart request", err)
+	}
+	if err := writer.Close(); err != nil {
+		return nil, errors.NewError(errors.CodeProvider, "failed to build multipart request", err)
+	}
+
+	return c.doJSON(ctx, http.MethodPost, path, writer.FormDataContentType(), body.Bytes(), out)
+}
+
+// GetRaw performs a GET request against path with retries and returns the
+// response body unparsed, e.g. for file downloads.
+func (c *Client) GetRaw(ctx context.Context, path string) ([]byte, error) {
+	resp, err := c.doRequestWithRetry(ctx, http.MethodGet, path, "", nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, HandleErrorResponse(resp)
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, errors.NewError(errors.CodeProvider, "failed to read response", err)
+	}
+	return data, nil
+}
+
+// doJSON executes a request with retries and decodes the JSON response body.
+func (c *Client) doJSON(ctx context.Context, method, path, contentType string, body []byte, out any) (http.Header, error) {
+	resp, err := c.doRequestWithRetry(ctx, method, path, contentType, body)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return resp.Header, HandleErrorResponse(resp)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return resp.Header, errors.NewError(errors.CodeProvider, "failed to decode response", err)
+	}
+
+	return resp.Header, nil
+}
+
+// Stream marshals req, POSTs it to path and invokes onData with the payload
+// of every SSE data event until the [DONE] sentinel is received.
+// Streaming requests are not retried as they are long-running operations.
+func (c *Client) Stream(ctx context.Context, path string, req any, onData func(data []byte) error) (http.Header, error) {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, errors.NewError(errors.CodeProvider, "failed to marshal request", err)
+	}
+
+	httpReq, err := c.newRequest(ctx, http.MethodPost, path, contentTypeJSON, body)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		return nil, errors.NewError(errors.CodeProvider, "request failed", err)
+	}
+	defer resp.Body.Close()
+	c.reportRateLimits(resp.Header)
+
+	if resp.StatusCode != http.StatusOK {
+		return resp.Header, HandleErrorResponse(resp)
+	}
+
+	return resp.Header, ParseSSE(resp.Body, onData)
+}
+
+// ParseSSE reads an OpenAI-style Server-Sent Events stream and calls onData
+// with the payload of each "data: " line. It stops at the [DONE] sentinel.
+func ParseSSE(reader io.Reader, onData func(data []byte) error) error {
+	scanner := bufio.NewScanner(reader)
+	// Set a larger buffer for potentially large SSE messages
+	buf := make([]byte, 0, 64*1024)
+	scanner.Buffer(buf, 1024*1024)
+
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		// Skip empty lines
+		if line == "" {
+			continue
+		}
+
+		// Check for data prefix
+		data, found := strings.CutPrefix(line, "data: ")
+		if !found {
+			continue
+		}
+
+		// Handle [DONE] sentinel
+		if data == "[DONE]" {
+			return nil
+		}
+
+		if err := onData([]byte(data)); err != nil {
+			return err
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return errors.NewError(errors.CodeProvider, "error reading SSE stream", err)
+	}
+
+	return nil
+}
+
+// DecodeSSE returns an SSE data handler that unmarshals each payload into a
+// fresh T and passes it to callback.
+func DecodeSSE[T any](callback func(chunk *T) error) func(data []byte) error {
+	return func(data []byte) error {
+		var chunk T
+		if err := json.Unmarshal(data, &chunk); err != nil {
+			return errors.NewError(errors.CodeProvider, "failed to parse SSE chunk", err)
+		}
+		return callback(&chunk)
+	}
+}
+
+// doRequestWithRetry performs an HTTP request with exponential backoff retry.
+func (c *Client) doRequestWithRetry(ctx context.Context, method, path, contentType string, body []byte) (*http.Response, error) {
+	var lastErr error
+	delay := c.config.RetryBaseDelay
+	if delay == 0 {
+		delay = DefaultRetryBaseDelay
+	}
+
+	for attempt := 0; attempt <= c.config.MaxRetries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case <-time.After(delay):
+			}
+			// Exponential backoff with cap
+			delay *= 2
+			if c.config.RetryMaxDelay > 0 && delay > c.config.RetryMaxDelay {
+				delay = c.config.RetryMaxDelay
+			}
+		}
+
+		req, err := c.newRequest(ctx, method, path, contentType, body)
+		if err != nil {
+			return nil, err
+		}
+
+		resp, err := c.httpClient.Do(req)
+		if err != nil {
+			lastErr = errors.NewError(errors.CodeProvider, "request failed", err)
+			continue
+		}
+		c.reportRateLimits(resp.Header)
+
+		// Check for retryable status codes (429 Too Many Requests, 5xx Server Errors)
+		if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500 {
+			// Check for Retry-After header
+			if retryAfter := resp.Header.Get("Retry-After"); retryAfter != "" {
+				if seconds, err := strconv.Atoi(retryAfter); err == nil {
+					delay = time.Duration(seconds) * time.Second
+				}
+			}
+			resp.Body.Close()
+			lastErr = fmt.Errorf("HTTP %d", resp.StatusCode)
+			continue
+		}
+
+		return resp, nil
+	}
+
+	return nil, errors.NewError(errors.CodeProvider,
+		fmt.Sprintf("request failed after %d retries", c.config.MaxRetries+1), lastErr)
+}
+
+// newRequest creates a new HTTP request with required headers.
+// An empty contentType defaults to JSON.
+func (c *Client) newRequest(ctx context.Context, method, path, contentType string, body []byte) (*http.Request, error) {
+	url := c.config.BaseURL + path
+
+	var bodyReader io.Reader
+	if body != nil {
+		bodyReader = bytes.NewReader(body)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, url, bodyReader)
+	if err != nil {
+		return nil, errors.NewError(errors.CodeProvider, "failed to create request", err)
+	}
+
+	if contentType == "" {
+		contentType = contentTypeJSON
+	}
+	req.Header.Set("Content-Type", contentType)
+	if c.config.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+c.config.APIKey)
+	}
+
+	for key, value := range c.config.Headers {
+		req.Header.Set(key, value)
+	}
+
+	return req, nil
+}
+
+// HandleErrorResponse extracts error information from an error response and
+// maps the HTTP status onto a domain error code.
+func HandleErrorResponse(resp *http.Response) error {
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return errors.NewError(errors.CodeProvider,
+			fmt.Sprintf("HTTP %d: failed to read error response", resp.StatusCode), err)
+	}
+
+	var errResp ErrorResponse
+	if err := json.Unmarshal(body, &errResp); err != nil {
+		// If we can't parse the error, return the raw body
+		return errors.NewError(errors.CodeProvider,
+			fmt.Sprintf("HTTP %d: %s", resp.StatusCode, string(body)), nil)
+	}
+
+	errCode := errors.CodeProvider
+	switch resp.StatusCode {
+	case http.StatusUnauthorized, http.StatusForbidden:
+		errCode = errors.CodeConfiguration
+	case http.StatusNotFound:
+		errCode = errors.CodeNotFound
+	case http.StatusBadRequest, http.StatusUnprocessableEntity:
+		errCode = errors.CodeValidation
+	}
+
+	errType := errResp.Error.Type
+	if errType == "" {
+		errType = "error"
+	}
+
+	return errors.NewError(errCode,
+		fmt.Sprintf("%s: %s", errType, errResp.Error.Message), nil)
+}
+
+// ParseRateLimitHeaders extracts rate limit information from response headers.
+func ParseRateLimitHeaders(headers http.Header) *RateLimitInfo {
+	info := &RateLimitInfo{}
+
+	if v := headers.Get("x-ratelimit-limit-requests"); v != "" {
+		info.LimitRequests, _ = strconv.Atoi(v)
+	}
+	if v := headers.Get("x-ratelimit-limit-tokens"); v != "" {
+		info.LimitTokens, _ = strconv.Atoi(v)
+	}
+	if v := headers.Get("x-ratelimit-remaining-requests"); v != "" {
+		info.RemainingRequests, _ = strconv.Atoi(v)
+	}
+	if v := headers.Get("x-ratelimit-remaining-tokens"); v != "" {
+		info.RemainingTokens, _ = strconv.Atoi(v)
+	}
+	if v := headers.Get("x-ratelimit-reset-requests"); v != "" {
+		info.ResetRequests = parseResetDuration(v)
+	}
+	if v := headers.Get("x-ratelimit-reset-tokens"); v != "" {
+		info.ResetTokens = parseResetDuration(v)
+	}
+
+	return info
+}
+
+// hasRateLimitHeaders reports whether headers carry any x-ratelimit-* value.
+func hasRateLimitHeaders(headers http.Header) bool {
+	for _, key := range []string{
+		"x-ratelimit-limit-requests",
+		"x-ratelimit-limit-tokens",
+		"x-ratelimit-remaining-requests",
+		"x-ratelimit-remaining-tokens",
+	} {
+		if headers.Get(key) != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// parseResetDuration parses an OpenAI-style duration (e.g., "1s", "100ms",
+// "6m0s") and returns the time when the rate limit resets.
+func parseResetDuration(s string) time.Time {
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return time.Time{}
+	}
+	return time.Now().Add(d)
+}
+
+// ListModels retrieves the list of available models from the /models endpoint.
+func (c *Client) ListModels(ctx context.Context) (*ModelsResponse, error) {
+	var result ModelsResponse
+	if _, err := c.GetJSON(ctx, EndpointModels, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// HealthCheck performs a lightweight check to verify API connectivity.
+func (c *Client) HealthCheck(ctx context.Context) error {
+	// Use ListModels as a lightweight health check since it doesn't consume tokens
+	_, err := c.ListModels(ctx)
+	return err
+}
@@ -0,0 +1,247 @@
+package openaicompat
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jbctechsolutions/skillrunner/internal/domain/errors"
+)
+
+func TestNewClient_Options(t *testing.T) {
+	customHTTP := &http.Client{}
+	client := NewClient(DefaultConfig("key", "https://example.com/v1"),
+		WithTimeout(time.Minute),
+		WithMaxRetries(5),
+		WithBaseURL("https://custom.example.com/v1/"),
+		WithHeader("X-Test", "1"),
+		WithHTTPClient(customHTTP),
+	)
+
+	cfg := client.Config()
+	if cfg.Timeout != time.Minute {
+		t.Errorf("expected timeout 1m, got %v", cfg.Timeout)
+	}
+	if cfg.MaxRetries != 5 {
+		t.Errorf("expected max retries 5, got %d", cfg.MaxRetries)
+	}
+	if cfg.BaseURL != "https://custom.example.com/v1" {
+		t.Errorf("expected base URL without trailing slash, got %q", cfg.BaseURL)
+	}
+	if cfg.Headers["X-Test"] != "1" {
+		t.Errorf("expected custom header to be set, got %v", cfg.Headers)
+	}
+	if client.httpClient != customHTTP {
+		t.Error("expected custom HTTP client to be used")
+	}
+}
+
+func TestClient_PostJSON(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != EndpointChatCompletions {
+			t.Errorf("expected path %s, got %s", EndpointChatCompletions, r.URL.Path)
+		}
+		if got := r.Header.Get("Authorization"); got != "Bearer test-key" {
+			t.Errorf("unexpected Authorization header %q", got)
+		}
+		if got := r.Header.Get("X-Extra"); got != "yes" {
+			t.Errorf("expected extra header, got %q", got)
+		}
+		w.Header().Set("x-ratelimit-remaining-requests", "42")
+		json.NewEncoder(w).Encode(ChatCompletionResponse{Model: "m"})
+	}))
+	defer server.Close()
+
+	client := NewClient(DefaultConfig("test-key", server.URL), WithHeader("X-Extra", "yes"))
+
+	var resp ChatCompletionResponse
+	headers, err := client.PostJSON(context.Background(), EndpointChatCompletions, &ChatCompletionRequest{Model: "m"}, &resp)
+	if err != nil {
+		t.Fatalf("PostJSON failed: %v", err)
+	}
+	if resp.Model != "m" {
+		t.Errorf("expected model 'm', got %q", resp.Model)
+	}
+	if info := ParseRateLimitHeaders(headers); info.RemainingRequests != 42 {
+		t.Errorf("expected 42 remaining requests, got %d", info.RemainingRequests)
+	}
+}
+
+func TestClient_OnRateLimit(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			w.Header().Set("x-ratelimit-limit-requests", "100")
+			w.Header().Set("x-ratelimit-remaining-requests", "0")
+			w.Header().Set("x-ratelimit-reset-requests", "1s")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		json.NewEncoder(w).Encode(ChatCompletionResponse{Model: "m"})
+	}))
+	defer server.Close()
+
+	cfg := DefaultConfig("", server.URL)
+	cfg.RetryBaseDelay = time.Millisecond
+	client := NewClient(cfg)
+
+	var reported []*RateLimitInfo
+	client.OnRateLimit(func(info *RateLimitInfo) {
+		reported = append(reported, info)
+	})
+
+	var resp ChatCompletionResponse
+	if _, err := client.PostJSON(context.Background(), EndpointChatCompletions, &ChatCompletionRequest{Model: "m"}, &resp); err != nil {
+		t.Fatalf("PostJSON failed: %v", err)
+	}
+
+	// Only the retried 429 carried rate limit headers
+	if len(reported) != 1 {
+		t.Fatalf("expected 1 rate limit report, got %d", len(reported))
+	}
+	if reported[0].LimitRequests != 100 || reported[0].RemainingRequests != 0 || reported[0].ResetRequests.IsZero() {
+		t.Errorf("unexpected rate limit info: %+v", reported[0])
+	}
+}
+
+func TestClient_OmitsAuthorizationWithoutAPIKey(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Authorization"); got != "" {
+			t.Errorf("expected no Authorization header, got %q", got)
+		}
+		json.NewEncoder(w).Encode(ModelsResponse{})
+	}))
+	defer server.Close()
+
+	client := NewClient(DefaultConfig("", server.URL))
+	if _, err := client.ListModels(context.Background()); err != nil {
+		t.Fatalf("ListModels failed: %v", err)
+	}
+}
+
+func TestClient_Retry(t *testing.T) {
+	var attempts atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if attempts.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		json.NewEncoder(w).Encode(ModelsResponse{Data: []Model{{ID: "a"}}})
+	}))
+	defer server.Close()
+
+	cfg := DefaultConfig("key", server.URL)
+	cfg.RetryBaseDelay = time.Millisecond
+	client := NewClient(cfg)
+
+	resp, err := client.ListModels(context.Background())
+	if err != nil {
+		t.Fatalf("ListModels failed: %v", err)
+	}
+	if len(resp.Data) != 1 {
+		t.Errorf("expected 1 model, got %d", len(resp.Data))
+	}
+	if attempts.Load() != 3 {
+		t.Errorf("expected 3 attempts, got %d", attempts.Load())
+	}
+}
+
+func TestClient_RetryExhausted(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	cfg := DefaultConfig("key", server.URL)
+	cfg.MaxRetries = 1
+	cfg.RetryBaseDelay = time.Millisecond
+	client := NewClient(cfg)
+
+	_, err := client.ListModels(context.Background())
+	if err == nil {
+		t.Fatal("expected error after retries")
+	}
+	if !strings.Contains(err.Error(), "after 2 retries") {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestHandleErrorResponse(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   int
+		body     string
+		wantCode errors.ErrorCode
+		wantMsg  string
+	}{
+		{"unauthorized", http.StatusUnauthorized, `{"error":{"message":"bad key","type":"auth"}}`, errors.CodeConfiguration, "auth: bad key"},
+		{"not found", http.StatusNotFound, `{"error":{"message":"no model"}}`, errors.CodeNotFound, "error: no model"},
+		{"validation", http.StatusBadRequest, `{"error":{"message":"bad","code":400}}`, errors.CodeValidation, "bad"},
+		{"unparseable", http.StatusBadRequest, `oops`, errors.CodeProvider, "HTTP 400: oops"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			client := NewClient(DefaultConfig("key", server.URL), WithMaxRetries(0))
+			_, err := client.ListModels(context.Background())
+			if err == nil {
+				t.Fatal("expected error")
+			}
+			var srErr *errors.SkillrunnerError
+			if !errors.As(err, &srErr) {
+				t.Fatalf("expected SkillrunnerError, got %T", err)
+			}
+			if srErr.Code != tt.wantCode {
+				t.Errorf("expected code %v, got %v", tt.wantCode, srErr.Code)
+			}
+			if !strings.Contains(err.Error(), tt.wantMsg) {
+				t.Errorf("expected message to contain %q, got %q", tt.wantMsg, err.Error())
+			}
+		})
+	}
+}
+
+func TestParseSSE(t *testing.T) {
+	stream := strings.Join([]string{
+		`: keep-alive`,
+		`data: {"choices":[{"delta":{"content":"Hel"}}]}`,
+		``,
+		`data: {"choices":[{"delta":{"content":"lo"}}]}`,
+		`data: [DONE]`,
+		`data: {"choices":[{"delta":{"content":"ignored"}}]}`,
+	}, "\n")
+
+	var content strings.Builder
+	err := ParseSSE(strings.NewReader(stream), DecodeSSE(func(chunk *StreamChunk) error {
+		for _, c := range chunk.Choices {
+			content.WriteString(c.Delta.Content)
+		}
+		return nil
+	}))
+	if err != nil {
+		t.Fatalf("ParseSSE failed: %v", err)
+	}
+	if content.String() != "Hello" {
+		t.Errorf("expected 'Hello', got %q", content.String())
+	}
+}
+
+func TestParseSSE_InvalidChunk(t *testing.T) {
+	err := ParseSSE(strings.NewReader("data: {not json}\n"), DecodeSSE(func(chunk *StreamChunk) error {
+		return nil
+	}))
+	if err == nil {
+		t.Fatal("expected error for invalid chunk")
+	}
+}
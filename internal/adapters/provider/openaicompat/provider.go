@@ -0,0 +1,356 @@
+package openaicompat
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/jbctechsolutions/skillrunner/internal/application/ports"
+)
+
+// ProviderSpec describes the identity of an OpenAI-compatible provider.
+// Vendor packages (together, fireworks) supply a spec; everything else is
+// handled by the shared Provider implementation.
+type ProviderSpec struct {
+	Name        string   // Provider name used for registration and routing
+	Description string   // Human-readable description
+	Models      []string // Statically supported models; empty means query the API
+	IsLocal     bool     // Whether the endpoint runs on the local machine
+}
+
+// Provider implements the ports.ProviderPort interface for any API that
+// speaks the OpenAI Chat Completions format.
+type Provider struct {
+	client *Client
+	spec   ProviderSpec
+}
+
+// Ensure Provider implements ProviderPort and Warmer at compile time.
+var (
+	_ ports.ProviderPort = (*Provider)(nil)
+	_ ports.Warmer       = (*Provider)(nil)
+)
+
+// NewProvider creates a new OpenAI-compatible provider.
+func NewProvider(spec ProviderSpec, config Config, opts ...ClientOption) *Provider {
+	return &Provider{
+		client: NewClient(config, opts...),
+		spec:   spec,
+	}
+}
+
+// Client returns the underlying HTTP client.
+func (p *Provider) Client() *Client {
+	return p.client
+}
+
+// OnRateLimit registers fn to receive the rate limit headers of the
+// provider's responses.
+func (p *Provider) OnRateLimit(fn func(*RateLimitInfo)) {
+	p.client.OnRateLimit(fn)
+}
+
+// Info returns metadata about this provider.
+func (p *Provider) Info() ports.ProviderInfo {
+	return ports.ProviderInfo{
+		Name:        p.spec.Name,
+		Description: p.spec.Description,
+		BaseURL:     p.client.config.BaseURL,
+		IsLocal:     p.spec.IsLocal,
+	}
+}
+
+// ListModels returns the list of available models.
+// Statically configured models take precedence over the /models endpoint.
+func (p *Provider) ListModels(ctx context.Context) ([]string, error) {
+	if len(p.spec.Models) > 0 {
+		return slices.Clone(p.spec.Models), nil
+	}
+
+	resp, err := p.client.ListModels(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	models := make([]string, 0, len(resp.Data))
+	for _, m := range resp.Data {
+		models = append(models, m.ID)
+	}
+	return models, nil
+}
+
+// SupportsModel checks if this provider supports the given model.
+func (p *Provider) SupportsModel(ctx context.Context, modelID string) (bool, error) {
+	models, err := p.ListModels(ctx)
+	if err != nil {
+		return false, err
+	}
+	return slices.Contains(models, modelID), nil
+}
+
+// IsAvailable checks if a model is currently available.
+func (p *Provider) IsAvailable(ctx context.Context, modelID string) (bool, error) {
+	supported, err := p.SupportsModel(ctx, modelID)
+	if err != nil {
+		return false, err
+	}
+
+	// For cloud providers, if we support the model, it's available
+	return supported, nil
+}
+
+// Complete sends a completion request and returns the response.
+func (p *Provider) Complete(ctx context.Context, req ports.CompletionRequest) (*ports.CompletionResponse, error) {
+	startTime := time.Now()
+
+	chatReq := BuildRequest(req)
+
+	var resp ChatCompletionResponse
+	if _, err := p.client.PostJSON(ctx, EndpointChatCompletions, chatReq, &resp); err != nil {
+		return nil, err
+	}
+
+	var content, finishReason string
+	var toolCalls []ports.ToolCall
+	if len(resp.Choices) > 0 {
+		content = resp.Choices[0].Message.Content
+		finishReason = resp.Choices[0].FinishReason
+		toolCalls = fromToolCalls(resp.Choices[0].Message.ToolCalls)
+	}
+
+	return &ports.CompletionResponse{
+		Content:      content,
+		InputTokens:  resp.Usage.PromptTokens,
+		OutputTokens: resp.Usage.CompletionTokens,
+		FinishReason: finishReason,
+		ModelUsed:    resp.Model,
+		Duration:     time.Since(startTime),
+		ToolCalls:    toolCalls,
+	}, nil
+}
+
+// Stream sends a streaming completion request and calls the callback for each chunk.
+func (p *Provider) Stream(ctx context.Context, req ports.CompletionRequest, cb ports.StreamCallback) (*ports.CompletionResponse, error) {
+	startTime := time.Now()
+
+	chatReq := BuildRequest(req)
+	chatReq.Stream = true
+
+	var fullContent strings.Builder
+	var inputTokens, outputTokens int
+	var finishReason string
+	var modelUsed string
+	var toolCalls []ToolCall
+
+	_, err := p.client.Stream(ctx, EndpointChatCompletions, chatReq, DecodeSSE(func(chunk *StreamChunk) error {
+		if chunk.Model != "" {
+			modelUsed = chunk.Model
+		}
+
+		for _, choice := range chunk.Choices {
+			if choice.Delta.Content != "" {
+				fullContent.WriteString(choice.Delta.Content)
+				if err := cb(choice.Delta.Content); err != nil {
+					return err
+				}
+			}
+			// Assemble tool calls, whose arguments arrive in fragments
+			toolCalls = mergeToolCallDeltas(toolCalls, choice.Delta.ToolCalls)
+
+			if choice.FinishReason != nil {
+				finishReason = *choice.FinishReason
+			}
+		}
+
+		// Usage is included in the final chunk by most gateways
+		if chunk.Usage != nil {
+			inputTokens = chunk.Usage.PromptTokens
+			outputTokens = chunk.Usage.CompletionTokens
+		}
+
+		return nil
+	}))
+	if err != nil {
+		return nil, err
+	}
+
+	return &ports.CompletionResponse{
+		Content:      fullContent.String(),
+		InputTokens:  inputTokens,
+		OutputTokens: outputTokens,
+		FinishReason: finishReason,
+		ModelUsed:    modelUsed,
+		Duration:     time.Since(startTime),
+		ToolCalls:    fromToolCalls(toolCalls),
+	}, nil
+}
+
+// Warm opens the connection to the API so the first request skips the
+// TLS handshake. It lists the models, which consumes no tokens.
+func (p *Provider) Warm(ctx context.Context, _ string) error {
+	_, err := p.client.ListModels(ctx)
+	return err
+}
+
+// HealthCheck verifies the provider is healthy and responsive.
+// The /models endpoint is used so that health checks do not consume tokens.
+func (p *Provider) HealthCheck(ctx context.Context, _ string) (*ports.HealthStatus, error) {
+	startTime := time.Now()
+
+	err := p.client.HealthCheck(ctx)
+	latency := time.Since(startTime)
+
+	if err != nil {
+		return &ports.HealthStatus{
+			Healthy:     false,
+			Message:     err.Error(),
+			Latency:     latency,
+			LastChecked: time.Now(),
+		}, nil
+	}
+
+	return &ports.HealthStatus{
+		Healthy:     true,
+		Message:     "OK",
+		Latency:     latency,
+		LastChecked: time.Now(),
+	}, nil
+}
+
+// BuildRequest converts a ports.CompletionRequest to a ChatCompletionRequest.
+func BuildRequest(req ports.CompletionRequest) *ChatCompletionRequest {
+	messages := make([]Message, 0, len(req.Messages)+1)
+
+	// Add system prompt as the first message if provided
+	if req.SystemPrompt != "" {
+		messages = append(messages, Message{
+			Role:    RoleSystem,
+			Content: req.SystemPrompt,
+		})
+	}
+
+	for _, msg := range req.Messages {
+		// Skip system messages if we already added a system prompt
+		if msg.Role == RoleSystem && req.SystemPrompt != "" {
+			continue
+		}
+
+		role := msg.Role
+		switch role {
+		case RoleSystem, RoleAssistant, RoleTool:
+		default:
+			role = RoleUser
+		}
+
+		messages = append(messages, Message{
+			Role:       role,
+			Content:    msg.Content,
+			ToolCalls:  toToolCalls(msg.ToolCalls),
+			ToolCallID: msg.ToolCallID,
+		})
+	}
+
+	chatReq := &ChatCompletionRequest{
+		Model:      req.ModelID,
+		MaxTokens:  req.MaxTokens,
+		Messages:   messages,
+		Tools:      toTools(req.Tools),
+		ToolChoice: toToolChoice(req.ToolChoice),
+	}
+
+	// Add temperature if non-zero
+	if req.Temperature > 0 {
+		temp := req.Temperature
+		chatReq.Temperature = &temp
+	}
+
+	return chatReq
+}
+
+// toTools converts provider-agnostic tools to function tools.
+func toTools(tools []ports.Tool) []Tool {
+	if len(tools) == 0 {
+		return nil
+	}
+	result := make([]Tool, 0, len(tools))
+	for _, t := range tools {
+		fn := Function{Name: t.Name, Description: t.Description}
+		if len(t.InputSchema) > 0 {
+			fn.Parameters = t.InputSchema
+		}
+		result = append(result, Tool{Type: "function", Function: fn})
+	}
+	return result
+}
+
+// toToolChoice converts a tool choice to the tool_choice value.
+func toToolChoice(choice *ports.ToolChoice) any {
+	if choice == nil {
+		return nil
+	}
+	if choice.Mode == ports.ToolChoiceTool {
+		return ToolChoiceFunction{Type: "function", Function: FunctionName{Name: choice.Name}}
+	}
+	return string(choice.Mode)
+}
+
+// toToolCalls converts tool calls from a previous assistant turn.
+func toToolCalls(calls []ports.ToolCall) []ToolCall {
+	if len(calls) == 0 {
+		return nil
+	}
+	result := make([]ToolCall, 0, len(calls))
+	for _, c := range calls {
+		result = append(result, ToolCall{
+			ID:       c.ID,
+			Type:     "function",
+			Function: FunctionCall{Name: c.Name, Arguments: string(c.Arguments)},
+		})
+	}
+	return result
+}
+
+// fromToolCalls converts wire tool calls to provider-agnostic tool calls.
+func fromToolCalls(calls []ToolCall) []ports.ToolCall {
+	if len(calls) == 0 {
+		return nil
+	}
+	result := make([]ports.ToolCall, 0, len(calls))
+	for _, c := range calls {
+		args := c.Function.Arguments
+		if args == "" {
+			args = "{}"
+		}
+		result = append(result, ports.ToolCall{
+			ID:        c.ID,
+			Name:      c.Function.Name,
+			Arguments: json.RawMessage(args),
+		})
+	}
+	return result
+}
+
+// mergeToolCallDeltas folds streamed tool call fragments into calls.
+// The first fragment of each call carries its ID and name; later fragments
+// with the same index append to its arguments.
+func mergeToolCallDeltas(calls []ToolCall, deltas []ToolCall) []ToolCall {
+	for _, d := range deltas {
+		i := len(calls)
+		if d.Index != nil {
+			i = *d.Index
+		}
+		for len(calls) <= i {
+			calls = append(calls, ToolCall{Type: "function"})
+		}
+		if d.ID != "" {
+			calls[i].ID = d.ID
+		}
+		if d.Function.Name != "" {
+			calls[i].Function.Name = d.Function.Name
+		}
+		calls[i].Function.Arguments += d.Function.Arguments
+	}
+	return calls
+}
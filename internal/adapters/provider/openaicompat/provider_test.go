@@ -0,0 +1,236 @@
+package openaicompat
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	"github.com/jbctechsolutions/skillrunner/internal/application/ports"
+)
+
+func newTestProvider(t *testing.T, spec ProviderSpec, handler http.HandlerFunc) *Provider {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	return NewProvider(spec, DefaultConfig("test-key", server.URL), WithMaxRetries(0))
+}
+
+func TestProvider_Info(t *testing.T) {
+	p := NewProvider(ProviderSpec{Name: "gateway", Description: "My gateway", IsLocal: true},
+		DefaultConfig("", "http://localhost:8000/v1"))
+
+	info := p.Info()
+	if info.Name != "gateway" {
+		t.Errorf("expected name 'gateway', got %q", info.Name)
+	}
+	if info.BaseURL != "http://localhost:8000/v1" {
+		t.Errorf("unexpected base URL %q", info.BaseURL)
+	}
+	if !info.IsLocal {
+		t.Error("expected IsLocal to be true")
+	}
+}
+
+func TestProvider_ListModels_Static(t *testing.T) {
+	p := NewProvider(ProviderSpec{Name: "x", Models: []string{"a", "b"}}, DefaultConfig("", "http://unused"))
+
+	models, err := p.ListModels(context.Background())
+	if err != nil {
+		t.Fatalf("ListModels failed: %v", err)
+	}
+	if !slices.Equal(models, []string{"a", "b"}) {
+		t.Errorf("unexpected models %v", models)
+	}
+
+	supported, _ := p.SupportsModel(context.Background(), "c")
+	if supported {
+		t.Error("expected model 'c' to be unsupported")
+	}
+}
+
+func TestProvider_ListModels_FromAPI(t *testing.T) {
+	p := newTestProvider(t, ProviderSpec{Name: "x"}, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != EndpointModels {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		json.NewEncoder(w).Encode(ModelsResponse{Data: []Model{{ID: "remote-1"}, {ID: "remote-2"}}})
+	})
+
+	available, err := p.IsAvailable(context.Background(), "remote-2")
+	if err != nil {
+		t.Fatalf("IsAvailable failed: %v", err)
+	}
+	if !available {
+		t.Error("expected remote-2 to be available")
+	}
+}
+
+func TestProvider_Complete(t *testing.T) {
+	p := newTestProvider(t, ProviderSpec{Name: "x"}, func(w http.ResponseWriter, r *http.Request) {
+		var req ChatCompletionRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Fatalf("failed to decode request: %v", err)
+		}
+		if len(req.Messages) != 2 || req.Messages[0].Role != RoleSystem {
+			t.Errorf("expected system prompt followed by user message, got %+v", req.Messages)
+		}
+		if req.Messages[1].Role != RoleUser {
+			t.Errorf("expected unknown role to map to user, got %q", req.Messages[1].Role)
+		}
+		if req.Temperature == nil || *req.Temperature != 0.5 {
+			t.Errorf("expected temperature 0.5, got %v", req.Temperature)
+		}
+		json.NewEncoder(w).Encode(ChatCompletionResponse{
+			Model:   req.Model,
+			Choices: []Choice{{Message: Message{Role: RoleAssistant, Content: "hi"}, FinishReason: "stop"}},
+			Usage:   Usage{PromptTokens: 3, CompletionTokens: 1},
+		})
+	})
+
+	resp, err := p.Complete(context.Background(), ports.CompletionRequest{
+		ModelID:      "m",
+		SystemPrompt: "be brief",
+		Temperature:  0.5,
+		Messages:     []ports.Message{{Role: "human", Content: "hello"}},
+	})
+	if err != nil {
+		t.Fatalf("Complete failed: %v", err)
+	}
+	if resp.Content != "hi" || resp.FinishReason != "stop" || resp.ModelUsed != "m" {
+		t.Errorf("unexpected response %+v", resp)
+	}
+	if resp.InputTokens != 3 || resp.OutputTokens != 1 {
+		t.Errorf("unexpected token usage %d/%d", resp.InputTokens, resp.OutputTokens)
+	}
+}
+
+func TestProvider_Stream(t *testing.T) {
+	p := newTestProvider(t, ProviderSpec{Name: "x"}, func(w http.ResponseWriter, r *http.Request) {
+		var req ChatCompletionRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		if !req.Stream {
+			t.Error("expected stream to be true")
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprintln(w, `data: {"model":"m","choices":[{"delta":{"content":"foo"}}]}`)
+		fmt.Fprintln(w, `data: {"model":"m","choices":[{"delta":{"content":"bar"},"finish_reason":"stop"}]}`)
+		fmt.Fprintln(w, `data: {"choices":[],"usage":{"prompt_tokens":4,"completion_tokens":2}}`)
+		fmt.Fprintln(w, `data: [DONE]`)
+	})
+
+	var chunks []string
+	resp, err := p.Stream(context.Background(), ports.CompletionRequest{ModelID: "m"}, func(chunk string) error {
+		chunks = append(chunks, chunk)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Stream failed: %v", err)
+	}
+	if resp.Content != "foobar" || len(chunks) != 2 {
+		t.Errorf("unexpected content %q from chunks %v", resp.Content, chunks)
+	}
+	if resp.FinishReason != "stop" || resp.ModelUsed != "m" {
+		t.Errorf("unexpected response %+v", resp)
+	}
+	if resp.InputTokens != 4 || resp.OutputTokens != 2 {
+		t.Errorf("unexpected token usage %d/%d", resp.InputTokens, resp.OutputTokens)
+	}
+}
+
+func TestProvider_Complete_Tools(t *testing.T) {
+	p := newTestProvider(t, ProviderSpec{Name: "x"}, func(w http.ResponseWriter, r *http.Request) {
+		var req ChatCompletionRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Fatalf("failed to decode request: %v", err)
+		}
+		if len(req.Tools) != 1 || req.Tools[0].Type != "function" || req.Tools[0].Function.Name != "get_weather" {
+			t.Errorf("unexpected tools: %+v", req.Tools)
+		}
+		if req.ToolChoice != "required" {
+			t.Errorf("unexpected tool_choice: %#v", req.ToolChoice)
+		}
+		assistant, tool := req.Messages[1], req.Messages[2]
+		if len(assistant.ToolCalls) != 1 || assistant.ToolCalls[0].ID != "call_1" || assistant.ToolCalls[0].Function.Arguments != `{"city":"Paris"}` {
+			t.Errorf("unexpected assistant tool calls: %+v", assistant.ToolCalls)
+		}
+		if tool.Role != RoleTool || tool.ToolCallID != "call_1" {
+			t.Errorf("unexpected tool result message: %+v", tool)
+		}
+		json.NewEncoder(w).Encode(ChatCompletionResponse{
+			Model: req.Model,
+			Choices: []Choice{{
+				Message: Message{Role: RoleAssistant, ToolCalls: []ToolCall{
+					{ID: "call_2", Type: "function", Function: FunctionCall{Name: "get_weather", Arguments: `{"city":"Rome"}`}},
+				}},
+				FinishReason: "tool_calls",
+			}},
+		})
+	})
+
+	resp, err := p.Complete(context.Background(), ports.CompletionRequest{
+		ModelID: "m",
+		Messages: []ports.Message{
+			{Role: "user", Content: "Weather in Paris?"},
+			{Role: "assistant", ToolCalls: []ports.ToolCall{
+				{ID: "call_1", Name: "get_weather", Arguments: json.RawMessage(`{"city":"Paris"}`)},
+			}},
+			{Role: "tool", ToolCallID: "call_1", Content: "18C"},
+		},
+		Tools: []ports.Tool{
+			{Name: "get_weather", Description: "Current weather", InputSchema: json.RawMessage(`{"type":"object"}`)},
+		},
+		ToolChoice: &ports.ToolChoice{Mode: ports.ToolChoiceRequired},
+	})
+	if err != nil {
+		t.Fatalf("Complete failed: %v", err)
+	}
+	if len(resp.ToolCalls) != 1 || resp.ToolCalls[0].ID != "call_2" || string(resp.ToolCalls[0].Arguments) != `{"city":"Rome"}` {
+		t.Errorf("unexpected tool calls %+v", resp.ToolCalls)
+	}
+}
+
+func TestProvider_Stream_ToolCalls(t *testing.T) {
+	p := newTestProvider(t, ProviderSpec{Name: "x"}, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprintln(w, `data: {"model":"m","choices":[{"delta":{"tool_calls":[{"index":0,"id":"call_1","type":"function","function":{"name":"get_weather","arguments":"{\"ci"}}]}}]}`)
+		fmt.Fprintln(w, `data: {"model":"m","choices":[{"delta":{"tool_calls":[{"index":0,"function":{"arguments":"ty\":\"Paris\"}"}}]},"finish_reason":"tool_calls"}]}`)
+		fmt.Fprintln(w, `data: [DONE]`)
+	})
+
+	resp, err := p.Stream(context.Background(), ports.CompletionRequest{ModelID: "m"}, func(string) error { return nil })
+	if err != nil {
+		t.Fatalf("Stream failed: %v", err)
+	}
+	if len(resp.ToolCalls) != 1 || resp.ToolCalls[0].Name != "get_weather" || string(resp.ToolCalls[0].Arguments) != `{"city":"Paris"}` {
+		t.Errorf("unexpected tool calls %+v", resp.ToolCalls)
+	}
+}
+
+func TestProvider_HealthCheck(t *testing.T) {
+	healthy := newTestProvider(t, ProviderSpec{Name: "x"}, func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(ModelsResponse{})
+	})
+	status, err := healthy.HealthCheck(context.Background(), "")
+	if err != nil {
+		t.Fatalf("HealthCheck failed: %v", err)
+	}
+	if !status.Healthy {
+		t.Errorf("expected healthy status, got %q", status.Message)
+	}
+
+	unhealthy := newTestProvider(t, ProviderSpec{Name: "x"}, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte(`{"error":{"message":"invalid key"}}`))
+	})
+	status, err = unhealthy.HealthCheck(context.Background(), "")
+	if err != nil {
+		t.Fatalf("HealthCheck failed: %v", err)
+	}
+	if status.Healthy {
+		t.Error("expected unhealthy status")
+	}
+}
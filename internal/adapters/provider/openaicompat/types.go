@@ -0,0 +1,209 @@
+// Package openaicompat provides a reusable client and provider for APIs that
+// implement the OpenAI Chat Completions wire format (Together AI, Fireworks,
+// vLLM, LiteLLM and similar inference gateways).
+package openaicompat
+
+import (
+	"net/http"
+	"time"
+)
+
+// API endpoints
+const (
+	EndpointChatCompletions = "/chat/completions"
+	EndpointModels          = "/models"
+	EndpointFiles           = "/files"
+	EndpointBatches         = "/batches"
+
+	EndpointAudioTranscriptions = "/audio/transcriptions"
+	EndpointImageGenerations    = "/images/generations"
+	EndpointModerations         = "/moderations"
+)
+
+// contentTypeJSON is the default request content type.
+const contentTypeJSON = "application/json"
+
+// DefaultRetryBaseDelay is the initial backoff delay used when a Config does
+// not specify RetryBaseDelay.
+const DefaultRetryBaseDelay = 500 * time.Millisecond
+
+// Message roles
+const (
+	RoleSystem    = "system"
+	RoleUser      = "user"
+	RoleAssistant = "assistant"
+	RoleTool      = "tool"
+)
+
+// Message represents a single message in the chat conversation.
+type Message struct {
+	Role       string     `json:"role"`
+	Content    string     `json:"content"`
+	ToolCalls  []ToolCall `json:"tool_calls,omitempty"`
+	ToolCallID string     `json:"tool_call_id,omitempty"`
+}
+
+// ToolCall represents a function call requested by the model.
+type ToolCall struct {
+	Index    *int         `json:"index,omitempty"` // Set on streaming deltas only
+	ID       string       `json:"id,omitempty"`
+	Type     string       `json:"type,omitempty"`
+	Function FunctionCall `json:"function"`
+}
+
+// FunctionCall contains the function name and its JSON-encoded arguments.
+type FunctionCall struct {
+	Name      string `json:"name"`
+	Arguments string `json:"arguments"`
+}
+
+// Tool represents a function the model may call.
+type Tool struct {
+	Type     string   `json:"type"` // Always "function"
+	Function Function `json:"function"`
+}
+
+// Function describes a callable function.
+type Function struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Parameters  any    `json:"parameters,omitempty"`
+}
+
+// ToolChoiceFunction forces the model to call a specific function.
+type ToolChoiceFunction struct {
+	Type     string       `json:"type"` // Always "function"
+	Function FunctionName `json:"function"`
+}
+
+// FunctionName identifies a function by name.
+type FunctionName struct {
+	Name string `json:"name"`
+}
+
+// ChatCompletionRequest is the request body for chat completions.
+type ChatCompletionRequest struct {
+	Model         string         `json:"model"`
+	Messages      []Message      `json:"messages"`
+	MaxTokens     int            `json:"max_tokens,omitempty"`
+	Temperature   *float32       `json:"temperature,omitempty"`
+	TopP          *float32       `json:"top_p,omitempty"`
+	Stream        bool           `json:"stream,omitempty"`
+	StreamOptions *StreamOptions `json:"stream_options,omitempty"`
+	Stop          []string       `json:"stop,omitempty"`
+	User          string         `json:"user,omitempty"`
+	Tools         []Tool         `json:"tools,omitempty"`
+	ToolChoice    any            `json:"tool_choice,omitempty"`
+}
+
+// StreamOptions contains options for streaming responses.
+type StreamOptions struct {
+	IncludeUsage bool `json:"include_usage,omitempty"`
+}
+
+// Usage contains token usage information from the response.
+type Usage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+	TotalTokens      int `json:"total_tokens"`
+}
+
+// Choice represents a single completion choice.
+type Choice struct {
+	Index        int     `json:"index"`
+	Message      Message `json:"message"`
+	FinishReason string  `json:"finish_reason"`
+}
+
+// ChatCompletionResponse is the response body from chat completions.
+type ChatCompletionResponse struct {
+	ID      string   `json:"id"`
+	Object  string   `json:"object"`
+	Created int64    `json:"created"`
+	Model   string   `json:"model"`
+	Choices []Choice `json:"choices"`
+	Usage   Usage    `json:"usage"`
+}
+
+// StreamChoice represents a choice in a streaming response chunk.
+type StreamChoice struct {
+	Index        int     `json:"index"`
+	Delta        Message `json:"delta"`
+	FinishReason *string `json:"finish_reason,omitempty"`
+}
+
+// StreamChunk represents a streaming response chunk.
+type StreamChunk struct {
+	ID      string         `json:"id"`
+	Object  string         `json:"object"`
+	Created int64          `json:"created"`
+	Model   string         `json:"model"`
+	Choices []StreamChoice `json:"choices"`
+	Usage   *Usage         `json:"usage,omitempty"`
+}
+
+// ErrorResponse represents an error returned by an OpenAI-compatible API.
+type ErrorResponse struct {
+	Error ErrorInfo `json:"error"`
+}
+
+// ErrorInfo contains detailed error information.
+// Param and Code are left untyped because gateways disagree on whether they
+// are strings, numbers or null.
+type ErrorInfo struct {
+	Message string `json:"message"`
+	Type    string `json:"type"`
+	Param   any    `json:"param,omitempty"`
+	Code    any    `json:"code,omitempty"`
+}
+
+// Model represents model information from the /models endpoint.
+type Model struct {
+	ID      string `json:"id"`
+	Object  string `json:"object"`
+	Created int64  `json:"created"`
+	OwnedBy string `json:"owned_by"`
+}
+
+// ModelsResponse is the response from the /models endpoint.
+type ModelsResponse struct {
+	Object string  `json:"object"`
+	Data   []Model `json:"data"`
+}
+
+// RateLimitInfo contains rate limit information from response headers.
+type RateLimitInfo struct {
+	LimitRequests     int       // x-ratelimit-limit-requests
+	LimitTokens       int       // x-ratelimit-limit-tokens
+	RemainingRequests int       // x-ratelimit-remaining-requests
+	RemainingTokens   int       // x-ratelimit-remaining-tokens
+	ResetRequests     time.Time // x-ratelimit-reset-requests
+	ResetTokens       time.Time // x-ratelimit-reset-tokens
+}
+
+// Config contains configuration for an OpenAI-compatible client.
+type Config struct {
+	APIKey         string
+	BaseURL        string
+	Headers        map[string]string // Extra headers sent with every request
+	Timeout        time.Duration
+	MaxRetries     int
+	RetryBaseDelay time.Duration
+	RetryMaxDelay  time.Duration
+
+	// Transport overrides the HTTP transport, e.g. to use a proxy or
+	// custom CAs (see the transport package); nil uses the default.
+	Transport http.RoundTripper
+}
+
+// DefaultConfig returns a Config with default values for the given endpoint.
+func DefaultConfig(apiKey, baseURL string) Config {
+	return Config{
+		APIKey:         apiKey,
+		BaseURL:        baseURL,
+		Timeout:        60 * time.Second,
+		MaxRetries:     3,
+		RetryBaseDelay: DefaultRetryBaseDelay,
+		RetryMaxDelay:  30 * time.Second,
+	}
+}
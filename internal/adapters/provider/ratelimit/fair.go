@@ -0,0 +1,164 @@
+package ratelimit
+
+import (
+	"cmp"
+	"slices"
+	"time"
+
+	"github.com/jbctechsolutions/skillrunner/internal/application/ports"
+)
+
+// Requests waiting for a limiter are ordered by start-time fair queuing:
+// each request gets a virtual finish time, its client's previous finish (or
+// the current virtual time, whichever is later) plus its cost divided by the
+// client's weight, and the request with the earliest finish starts next.
+// A client that keeps many requests waiting pushes its own finish times
+// out, so the requests of other clients go ahead of most of them, and an
+// idle client does not bank capacity to use in a burst later.
+
+// waiter is a request waiting for capacity.
+type waiter struct {
+	client string
+	tokens int
+	seq    uint64 // arrival order, for ties
+
+	start, finish float64 // virtual times
+	queuedAt      time.Time
+
+	wake chan struct{} // signaled when the request may be next in line
+}
+
+// fairQueue holds the waiting requests of a limiter.
+type fairQueue struct {
+	waiters []*waiter
+	vtime   float64            // start of the request that started last
+	finish  map[string]float64 // finish of each client's last queued request
+}
+
+func newFairQueue() fairQueue {
+	return fairQueue{finish: make(map[string]float64)}
+}
+
+// head returns the request to start next, or nil.
+func (q *fairQueue) head() *waiter {
+	if len(q.waiters) == 0 {
+		return nil
+	}
+	return slices.MinFunc(q.waiters, func(a, b *waiter) int {
+		return cmp.Or(cmp.Compare(a.finish, b.finish), cmp.Compare(a.seq, b.seq))
+	})
+}
+
+// remove takes w out of the queue.
+func (q *fairQueue) remove(w *waiter) {
+	q.waiters = slices.DeleteFunc(q.waiters, func(o *waiter) bool { return o == w })
+}
+
+// enqueue queues a request of tokens for client.
+func (l *Limiter) enqueue(client ports.Client, tokens int) *waiter {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	// Requests cost what they use of the scarcest limit we know of
+	cost := 1.0
+	if l.tokens != nil {
+		cost = float64(max(tokens, 1))
+	}
+	weight := client.Weight
+	if weight <= 0 {
+		weight = 1
+	}
+
+	l.seqNum++
+	start := max(l.queue.vtime, l.queue.finish[client.Name])
+	w := &waiter{
+		client:   client.Name,
+		tokens:   tokens,
+		seq:      l.seqNum,
+		start:    start,
+		finish:   start + cost/weight,
+		queuedAt: l.now(),
+		wake:     make(chan struct{}, 1),
+	}
+	l.queue.finish[client.Name] = w.finish
+	l.queue.waiters = append(l.queue.waiters, w)
+	l.clientUsage(w.client).Waiting++
+	return w
+}
+
+// admit starts w if it is next in line and there is capacity for it,
+// returning true, or returns how long to wait before capacity refills (0
+// when w is to wait until woken).
+func (l *Limiter) admit(w *waiter) (time.Duration, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.queue.head() != w || (l.maxInFlight > 0 && l.inFlight >= l.maxInFlight) {
+		return 0, false
+	}
+	if wait := l.takeLocked(w.tokens); wait > 0 {
+		return wait, false
+	}
+
+	l.queue.remove(w)
+	l.queue.vtime = w.start
+	l.inFlight++
+	u := l.clientUsage(w.client)
+	u.Waiting--
+	u.InFlight++
+	u.Requests++
+	u.Tokens += int64(w.tokens)
+	u.WaitTime += l.now().Sub(w.queuedAt)
+	l.wakeNext()
+	return 0, true
+}
+
+// abandon takes w out of the queue when its caller stops waiting.
+func (l *Limiter) abandon(w *waiter) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.queue.remove(w)
+	if l.queue.finish[w.client] == w.finish {
+		// The client's next request need not wait behind this one
+		l.queue.finish[w.client] = w.start
+	}
+	l.clientUsage(w.client).Waiting--
+	l.wakeNext()
+}
+
+// wakeNext wakes the request next in line. The caller holds l.mu.
+func (l *Limiter) wakeNext() {
+	if w := l.queue.head(); w != nil {
+		select {
+		case w.wake <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// clientUsage returns the usage of client. The caller holds l.mu.
+func (l *Limiter) clientUsage(client string) *ports.ClientUsage {
+	u, ok := l.usage[client]
+	if !ok {
+		u = &ports.ClientUsage{Client: client}
+		l.usage[client] = u
+	}
+	return u
+}
+
+// Usage returns what each client has used of the limiter's capacity, sorted
+// by client. Requests made on behalf of no client are not included.
+func (l *Limiter) Usage() []ports.ClientUsage {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	usage := make([]ports.ClientUsage, 0, len(l.usage))
+	for client, u := range l.usage {
+		if client != "" {
+			usage = append(usage, *u)
+		}
+	}
+	slices.SortFunc(usage, func(a, b ports.ClientUsage) int { return cmp.Compare(a.Client, b.Client) })
+	return usage
+}
@@ -0,0 +1,292 @@
+// Package ratelimit enforces per-provider rate limits: requests and tokens
+// per minute, concurrent requests and bursts, and the limits providers
+// report in their rate limit headers. Requests over a limit wait for
+// capacity rather than failing.
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/jbctechsolutions/skillrunner/internal/adapters/provider/openaicompat"
+	"github.com/jbctechsolutions/skillrunner/internal/application/ports"
+)
+
+// Config defines the limits of a provider. Zero values mean unlimited.
+type Config struct {
+	RequestsPerMinute  int
+	TokensPerMinute    int
+	ConcurrentRequests int
+	BurstLimit         int // Requests allowed at once before the per-minute rate applies; defaults to RequestsPerMinute
+}
+
+// IsZero reports whether the configuration sets no limit.
+func (c Config) IsZero() bool {
+	return c.RequestsPerMinute <= 0 && c.TokensPerMinute <= 0 && c.ConcurrentRequests <= 0
+}
+
+// LowWaterFraction is the share of a provider-reported limit below which
+// the remaining capacity counts as low: requests are spread out until the
+// reset and the provider reports itself throttled so routing can prefer
+// another one.
+const LowWaterFraction = 0.1
+
+// Limiter throttles requests with token buckets for requests and tokens per
+// minute and a limit on concurrent requests. It also adapts to the rate
+// limit headers of the provider's responses: when the remaining requests or
+// tokens run low, requests are paced so the rest lasts until the reset, and
+// when they run out, requests wait for the reset.
+//
+// Waiting requests are queued by client (see ports.ClientFrom) and started
+// in fair-share order, so that a client sending many requests cannot starve
+// the others of the provider's capacity.
+type Limiter struct {
+	mu          sync.Mutex
+	requests    *bucket // nil when unlimited
+	tokens      *bucket // nil when unlimited
+	maxInFlight int     // 0 when unlimited
+	inFlight    int
+
+	// Limits reported by the provider, counted down locally between responses
+	reportedRequests reportedLimit
+	reportedTokens   reportedLimit
+	lastStart        time.Time
+
+	// Requests waiting for capacity, and what each client has used
+	queue  fairQueue
+	usage  map[string]*ports.ClientUsage
+	seqNum uint64
+
+	now func() time.Time
+}
+
+// reportedLimit is a limit from rate limit headers.
+type reportedLimit struct {
+	limit     int
+	remaining int
+	reset     time.Time
+}
+
+// active reports whether the limit is known and has not reset yet.
+func (r reportedLimit) active(now time.Time) bool {
+	return r.limit > 0 && now.Before(r.reset)
+}
+
+// low reports whether the remaining capacity is under LowWaterFraction of
+// the limit.
+func (r reportedLimit) low(now time.Time) bool {
+	return r.active(now) && float64(r.remaining) <= float64(r.limit)*LowWaterFraction
+}
+
+// NewLimiter creates a limiter enforcing cfg.
+func NewLimiter(cfg Config) *Limiter {
+	l := &Limiter{now: time.Now, queue: newFairQueue(), usage: make(map[string]*ports.ClientUsage)}
+	start := l.now()
+
+	if cfg.RequestsPerMinute > 0 {
+		burst := cfg.BurstLimit
+		if burst <= 0 {
+			burst = cfg.RequestsPerMinute
+		}
+		l.requests = newBucket(float64(burst), float64(cfg.RequestsPerMinute)/60, start)
+	}
+	if cfg.TokensPerMinute > 0 {
+		l.tokens = newBucket(float64(cfg.TokensPerMinute), float64(cfg.TokensPerMinute)/60, start)
+	}
+	l.maxInFlight = max(cfg.ConcurrentRequests, 0)
+	return l
+}
+
+// Reservation is the capacity taken by one request.
+type Reservation struct {
+	limiter *Limiter
+	client  string
+	tokens  int
+	once    sync.Once
+}
+
+// Done returns the request's concurrency slot and corrects the token bucket
+// and its client's usage with the tokens the request actually used; a
+// negative value keeps the estimate.
+func (r *Reservation) Done(usedTokens int) {
+	r.once.Do(func() {
+		l := r.limiter
+		l.mu.Lock()
+		defer l.mu.Unlock()
+
+		l.inFlight--
+		u := l.clientUsage(r.client)
+		u.InFlight--
+		if usedTokens >= 0 {
+			u.Tokens += int64(usedTokens - r.tokens)
+			if l.tokens != nil {
+				l.tokens.refill(l.now())
+				l.tokens.level += float64(r.tokens - usedTokens) // May go negative, delaying later requests
+				l.tokens.level = min(l.tokens.level, l.tokens.capacity)
+			}
+		}
+		l.wakeNext()
+	})
+}
+
+// Wait blocks until a request estimated to use tokens may start, or ctx is
+// done. Estimates larger than the per-minute token limit are capped to it so
+// that a large request still runs once the bucket is full. Requests of
+// different clients that have to wait start in fair-share order. The caller
+// must call Done on the returned reservation when the request finishes.
+func (l *Limiter) Wait(ctx context.Context, tokens int) (*Reservation, error) {
+	if l.tokens != nil {
+		tokens = min(max(tokens, 0), int(l.tokens.capacity))
+	}
+
+	w := l.enqueue(ports.ClientFrom(ctx), tokens)
+	for {
+		wait, ok := l.admit(w)
+		if ok {
+			return &Reservation{limiter: l, client: w.client, tokens: tokens}, nil
+		}
+
+		// Wait to be woken as the next in line, or for capacity to refill
+		var timer *time.Timer
+		var refilled <-chan time.Time
+		if wait > 0 {
+			timer = time.NewTimer(wait)
+			refilled = timer.C
+		}
+		select {
+		case <-w.wake:
+		case <-refilled:
+		case <-ctx.Done():
+		}
+		if timer != nil {
+			timer.Stop()
+		}
+		if err := ctx.Err(); err != nil {
+			l.abandon(w)
+			return nil, err
+		}
+	}
+}
+
+// take removes one request and tokens from the buckets if they all have
+// capacity, returning 0, or returns how long to wait before trying again.
+func (l *Limiter) take(tokens int) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.takeLocked(tokens)
+}
+
+// takeLocked is take for a caller holding l.mu.
+func (l *Limiter) takeLocked(tokens int) time.Duration {
+	now := l.now()
+	wait := l.reportedWait(tokens, now)
+	if l.requests != nil {
+		wait = max(wait, l.requests.wait(1, now))
+	}
+	if l.tokens != nil {
+		wait = max(wait, l.tokens.wait(float64(tokens), now))
+	}
+	if wait > 0 {
+		return wait
+	}
+
+	if l.requests != nil {
+		l.requests.level--
+	}
+	if l.tokens != nil {
+		l.tokens.level -= float64(tokens)
+	}
+	l.reportedRequests.remaining--
+	l.reportedTokens.remaining -= tokens
+	l.lastStart = now
+	return 0
+}
+
+// reportedWait returns how long a request of tokens waits under the limits
+// the provider reported. Exhausted limits wait for their reset. Low limits
+// space requests evenly over the time left until the reset. The caller
+// holds l.mu.
+func (l *Limiter) reportedWait(tokens int, now time.Time) time.Duration {
+	var wait time.Duration
+
+	if r := l.reportedRequests; r.active(now) {
+		untilReset := r.reset.Sub(now)
+		switch {
+		case r.remaining <= 0:
+			wait = untilReset
+		case r.low(now):
+			wait = l.lastStart.Add(untilReset / time.Duration(r.remaining)).Sub(now)
+		}
+	}
+
+	if r := l.reportedTokens; r.active(now) {
+		untilReset := r.reset.Sub(now)
+		switch {
+		case r.remaining <= 0 || tokens > r.remaining:
+			wait = max(wait, untilReset)
+		case r.low(now):
+			spacing := time.Duration(float64(untilReset) * float64(tokens) / float64(r.remaining))
+			wait = max(wait, l.lastStart.Add(spacing).Sub(now))
+		}
+	}
+
+	return wait
+}
+
+// Throttled reports whether the provider's reported remaining requests or
+// tokens are low, so requests are being paced until the reset.
+func (l *Limiter) Throttled() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	return l.reportedRequests.low(now) || l.reportedTokens.low(now)
+}
+
+// Observe records the rate limit headers of a provider response. Headers
+// without a limit or reset time are ignored.
+func (l *Limiter) Observe(info *openaicompat.RateLimitInfo) {
+	if info == nil {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if info.LimitRequests > 0 && !info.ResetRequests.IsZero() {
+		l.reportedRequests = reportedLimit{info.LimitRequests, info.RemainingRequests, info.ResetRequests}
+	}
+	if info.LimitTokens > 0 && !info.ResetTokens.IsZero() {
+		l.reportedTokens = reportedLimit{info.LimitTokens, info.RemainingTokens, info.ResetTokens}
+	}
+}
+
+// bucket is a token bucket refilled continuously at rate per second.
+type bucket struct {
+	capacity float64
+	level    float64
+	rate     float64
+	updated  time.Time
+}
+
+func newBucket(capacity, rate float64, now time.Time) *bucket {
+	return &bucket{capacity: capacity, level: capacity, rate: rate, updated: now}
+}
+
+// refill adds the capacity accrued since the last update.
+func (b *bucket) refill(now time.Time) {
+	if now.After(b.updated) {
+		b.level = min(b.capacity, b.level+now.Sub(b.updated).Seconds()*b.rate)
+		b.updated = now
+	}
+}
+
+// wait returns how long until the bucket holds n, or 0 if it does now.
+func (b *bucket) wait(n float64, now time.Time) time.Duration {
+	b.refill(now)
+	if b.level >= n {
+		return 0
+	}
+	return time.Duration((n - b.level) / b.rate * float64(time.Second))
+}
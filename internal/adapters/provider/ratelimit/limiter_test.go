@@ -0,0 +1,328 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/jbctechsolutions/skillrunner/internal/adapters/provider/openaicompat"
+	"github.com/jbctechsolutions/skillrunner/internal/application/ports"
+)
+
+// newTestLimiter returns a limiter on a fake clock and a function that
+// advances it.
+func newTestLimiter(cfg Config) (*Limiter, func(time.Duration)) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	l := NewLimiter(cfg)
+	l.now = func() time.Time { return now }
+	for _, b := range []*bucket{l.requests, l.tokens} {
+		if b != nil {
+			b.updated = now
+		}
+	}
+	return l, func(d time.Duration) { now = now.Add(d) }
+}
+
+func TestConfig_IsZero(t *testing.T) {
+	if !(Config{}).IsZero() {
+		t.Error("empty config should be zero")
+	}
+	if !(Config{BurstLimit: 5}).IsZero() {
+		t.Error("burst limit alone should not limit anything")
+	}
+	if (Config{TokensPerMinute: 1000}).IsZero() {
+		t.Error("tokens per minute should be a limit")
+	}
+}
+
+func TestLimiter_RequestsPerMinute(t *testing.T) {
+	l, advance := newTestLimiter(Config{RequestsPerMinute: 60, BurstLimit: 2})
+
+	for i := range 2 {
+		if wait := l.take(0); wait != 0 {
+			t.Fatalf("request %d within burst should not wait, got %v", i+1, wait)
+		}
+	}
+	if wait := l.take(0); wait != time.Second {
+		t.Errorf("expected third request to wait 1s at 60/min, got %v", wait)
+	}
+
+	advance(500 * time.Millisecond)
+	if wait := l.take(0); wait != 500*time.Millisecond {
+		t.Errorf("expected 500ms wait after half the interval, got %v", wait)
+	}
+
+	advance(500 * time.Millisecond)
+	if wait := l.take(0); wait != 0 {
+		t.Errorf("expected request to proceed after refill, got %v", wait)
+	}
+}
+
+func TestLimiter_BurstDefaultsToRequestsPerMinute(t *testing.T) {
+	l, _ := newTestLimiter(Config{RequestsPerMinute: 3})
+
+	for range 3 {
+		if wait := l.take(0); wait != 0 {
+			t.Fatalf("expected burst of 3, got wait %v", wait)
+		}
+	}
+	if wait := l.take(0); wait <= 0 {
+		t.Error("expected fourth request to wait")
+	}
+}
+
+func TestLimiter_TokensPerMinute(t *testing.T) {
+	l, advance := newTestLimiter(Config{TokensPerMinute: 600})
+
+	if wait := l.take(500); wait != 0 {
+		t.Fatalf("expected first request to proceed, got %v", wait)
+	}
+	// 100 tokens left; 300 more accrue in 30s at 10 tokens/s
+	if wait := l.take(400); wait != 30*time.Second {
+		t.Errorf("expected 30s wait for 400 tokens, got %v", wait)
+	}
+
+	advance(30 * time.Second)
+	if wait := l.take(400); wait != 0 {
+		t.Errorf("expected request to proceed after refill, got %v", wait)
+	}
+}
+
+func TestLimiter_Wait_CapsEstimateToLimit(t *testing.T) {
+	l := NewLimiter(Config{TokensPerMinute: 100})
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	r, err := l.Wait(ctx, 10_000)
+	if err != nil {
+		t.Fatalf("expected oversized request to run on a full bucket, got %v", err)
+	}
+	if r.tokens != 100 {
+		t.Errorf("expected estimate capped to 100, got %d", r.tokens)
+	}
+}
+
+func TestReservation_Done_CorrectsTokens(t *testing.T) {
+	l, _ := newTestLimiter(Config{TokensPerMinute: 600})
+
+	r, err := l.Wait(context.Background(), 500)
+	if err != nil {
+		t.Fatalf("Wait failed: %v", err)
+	}
+	r.Done(200)
+	if got := l.tokens.level; got != 400 {
+		t.Errorf("expected unused tokens refunded to 400, got %v", got)
+	}
+
+	r, _ = l.Wait(context.Background(), 100)
+	r.Done(700)
+	if got := l.tokens.level; got != -300 {
+		t.Errorf("expected overuse to leave the bucket at -300, got %v", got)
+	}
+
+	r.Done(0) // Done is idempotent
+	if got := l.tokens.level; got != -300 {
+		t.Errorf("expected second Done to be ignored, got %v", got)
+	}
+}
+
+func TestLimiter_ConcurrentRequests(t *testing.T) {
+	l := NewLimiter(Config{ConcurrentRequests: 1})
+
+	first, err := l.Wait(context.Background(), 0)
+	if err != nil {
+		t.Fatalf("Wait failed: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := l.Wait(ctx, 0); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected second request to block until the deadline, got %v", err)
+	}
+
+	acquired := make(chan *Reservation)
+	go func() {
+		r, _ := l.Wait(context.Background(), 0)
+		acquired <- r
+	}()
+
+	first.Done(-1)
+	select {
+	case r := <-acquired:
+		r.Done(-1)
+	case <-time.After(time.Second):
+		t.Fatal("expected queued request to start once the slot was released")
+	}
+}
+
+func TestLimiter_Observe(t *testing.T) {
+	l, advance := newTestLimiter(Config{ConcurrentRequests: 10})
+	reset := l.now().Add(2 * time.Second)
+
+	// Ample remaining capacity does not slow requests down
+	l.Observe(&openaicompat.RateLimitInfo{LimitRequests: 100, RemainingRequests: 50, ResetRequests: reset})
+	if wait := l.take(0); wait != 0 {
+		t.Fatalf("expected no wait with remaining requests, got %v", wait)
+	}
+	if l.Throttled() {
+		t.Error("expected limiter not throttled with half the requests left")
+	}
+
+	// Missing headers (zero limits) are ignored
+	l.Observe(&openaicompat.RateLimitInfo{ResetTokens: reset})
+	l.Observe(nil)
+	if wait := l.take(0); wait != 0 {
+		t.Fatalf("expected no wait without limit headers, got %v", wait)
+	}
+
+	l.Observe(&openaicompat.RateLimitInfo{LimitTokens: 1000, RemainingTokens: 0, ResetTokens: reset})
+	if wait := l.take(0); wait != 2*time.Second {
+		t.Errorf("expected 2s wait until the token reset, got %v", wait)
+	}
+
+	advance(2 * time.Second)
+	if wait := l.take(0); wait != 0 {
+		t.Errorf("expected requests to resume after the reset, got %v", wait)
+	}
+	if l.Throttled() {
+		t.Error("expected reported limits to expire at the reset")
+	}
+}
+
+func TestLimiter_Observe_PacesLowRemaining(t *testing.T) {
+	l, advance := newTestLimiter(Config{})
+	l.Observe(&openaicompat.RateLimitInfo{LimitRequests: 100, RemainingRequests: 5, ResetRequests: l.now().Add(10 * time.Second)})
+
+	if !l.Throttled() {
+		t.Error("expected limiter throttled with 5% of the requests left")
+	}
+	if wait := l.take(0); wait != 0 {
+		t.Fatalf("expected the first request to start, got %v", wait)
+	}
+
+	// 4 requests left for 10s: one every 2.5s
+	if wait := l.take(0); wait != 2500*time.Millisecond {
+		t.Errorf("expected 2.5s between requests, got %v", wait)
+	}
+	advance(2500 * time.Millisecond)
+	if wait := l.take(0); wait != 0 {
+		t.Errorf("expected the paced request to start, got %v", wait)
+	}
+}
+
+func TestLimiter_Observe_TokensOverRemaining(t *testing.T) {
+	l, _ := newTestLimiter(Config{})
+	l.Observe(&openaicompat.RateLimitInfo{LimitTokens: 1000, RemainingTokens: 500, ResetTokens: l.now().Add(5 * time.Second)})
+
+	if wait := l.take(800); wait != 5*time.Second {
+		t.Errorf("expected a request over the remaining tokens to wait for the reset, got %v", wait)
+	}
+	if wait := l.take(300); wait != 0 {
+		t.Errorf("expected a request within the remaining tokens to start, got %v", wait)
+	}
+}
+
+// queueRequests starts a request of each client in order, once the one
+// before it is waiting, and returns the order in which they start.
+func queueRequests(t *testing.T, l *Limiter, clients ...ports.Client) <-chan string {
+	t.Helper()
+	started := make(chan string, len(clients))
+	for i, c := range clients {
+		go func() {
+			r, err := l.Wait(ports.WithClient(context.Background(), c), 0)
+			if err != nil {
+				t.Errorf("Wait failed: %v", err)
+				return
+			}
+			started <- c.Name
+			r.Done(0)
+		}()
+		deadline := time.Now().Add(time.Second)
+		for waiting(l) < i+1 {
+			if time.Now().After(deadline) {
+				t.Fatalf("request %d of %s did not queue", i+1, c.Name)
+			}
+			time.Sleep(time.Millisecond)
+		}
+	}
+	return started
+}
+
+// waiting returns the number of requests waiting for l.
+func waiting(l *Limiter) int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return len(l.queue.waiters)
+}
+
+func TestLimiter_FairShare(t *testing.T) {
+	l := NewLimiter(Config{ConcurrentRequests: 1})
+	held, err := l.Wait(context.Background(), 0)
+	if err != nil {
+		t.Fatalf("Wait failed: %v", err)
+	}
+
+	noisy, quiet := ports.Client{Name: "noisy"}, ports.Client{Name: "quiet"}
+	started := queueRequests(t, l, noisy, noisy, noisy, noisy, quiet)
+	held.Done(0)
+
+	var order []string
+	for range 5 {
+		select {
+		case name := <-started:
+			order = append(order, name)
+		case <-time.After(time.Second):
+			t.Fatalf("requests stalled after %v", order)
+		}
+	}
+	if i := slices.Index(order, "quiet"); i > 1 {
+		t.Errorf("start order = %v, want the quiet client's request among the first two", order)
+	}
+
+	usage := l.Usage()
+	if len(usage) != 2 || usage[0].Client != "noisy" || usage[0].Requests != 4 || usage[1].Requests != 1 {
+		t.Errorf("Usage() = %+v, want 4 noisy and 1 quiet requests", usage)
+	}
+	if usage[0].InFlight != 0 || usage[0].Waiting != 0 {
+		t.Errorf("Usage() = %+v, want nothing in flight or waiting", usage)
+	}
+}
+
+func TestLimiter_FairShare_Weights(t *testing.T) {
+	l := NewLimiter(Config{ConcurrentRequests: 1})
+	held, _ := l.Wait(context.Background(), 0)
+
+	light, heavy := ports.Client{Name: "light"}, ports.Client{Name: "heavy", Weight: 2}
+	started := queueRequests(t, l, light, light, light, heavy, heavy, heavy)
+	held.Done(0)
+
+	var order []string
+	for range 6 {
+		order = append(order, <-started)
+	}
+	want := []string{"heavy", "light", "heavy", "heavy", "light", "light"}
+	if !slices.Equal(order, want) {
+		t.Errorf("start order = %v, want %v", order, want)
+	}
+}
+
+func TestLimiter_AbandonedRequest(t *testing.T) {
+	l := NewLimiter(Config{ConcurrentRequests: 1})
+	held, _ := l.Wait(context.Background(), 0)
+
+	ctx, cancel := context.WithTimeout(ports.WithClient(context.Background(), ports.Client{Name: "a"}), 10*time.Millisecond)
+	defer cancel()
+	if _, err := l.Wait(ctx, 0); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Wait() error = %v, want the deadline", err)
+	}
+	if n := waiting(l); n != 0 {
+		t.Errorf("%d requests still queued, want the abandoned one removed", n)
+	}
+	if usage := l.Usage(); len(usage) != 1 || usage[0].Waiting != 0 || usage[0].Requests != 0 {
+		t.Errorf("Usage() = %+v, want no request of the client", usage)
+	}
+	held.Done(0)
+}
@@ -0,0 +1,106 @@
+package ratelimit
+
+import (
+	"context"
+
+	"github.com/jbctechsolutions/skillrunner/internal/adapters/provider/openaicompat"
+	"github.com/jbctechsolutions/skillrunner/internal/application/ports"
+)
+
+// rateLimitReporter is implemented by providers that expose the rate limit
+// headers of their responses.
+type rateLimitReporter interface {
+	OnRateLimit(fn func(*openaicompat.RateLimitInfo))
+}
+
+// Provider wraps a provider so that completions and streams wait for its
+// limiter. Other calls (health checks, model listing) are not limited.
+type Provider struct {
+	ports.ProviderPort
+	limiter *Limiter
+}
+
+// Ensure Provider implements ProviderPort and ThrottleReporter at compile time.
+var (
+	_ ports.ProviderPort     = (*Provider)(nil)
+	_ ports.ThrottleReporter = (*Provider)(nil)
+)
+
+// ReportsRateLimits reports whether p exposes the rate limit headers of its
+// responses, so that wrapping it adapts to the provider's own limits even
+// without configured ones.
+func ReportsRateLimits(p ports.ProviderPort) bool {
+	_, ok := p.(rateLimitReporter)
+	return ok
+}
+
+// NewProvider wraps inner with a limiter enforcing cfg. If inner reports its
+// rate limit headers, the limiter also paces requests when the provider's
+// remaining limits run low and waits for the reset when they run out.
+func NewProvider(inner ports.ProviderPort, cfg Config) *Provider {
+	limiter := NewLimiter(cfg)
+	if reporter, ok := inner.(rateLimitReporter); ok {
+		reporter.OnRateLimit(limiter.Observe)
+	}
+	return &Provider{ProviderPort: inner, limiter: limiter}
+}
+
+// Unwrap returns the wrapped provider.
+func (p *Provider) Unwrap() ports.ProviderPort {
+	return p.ProviderPort
+}
+
+// Throttled reports whether the provider's remaining rate limit is low and
+// its requests are being paced.
+func (p *Provider) Throttled() bool {
+	return p.limiter.Throttled()
+}
+
+// Limiter returns the provider's limiter.
+func (p *Provider) Limiter() *Limiter {
+	return p.limiter
+}
+
+// Complete waits for the limiter, then sends the completion request.
+func (p *Provider) Complete(ctx context.Context, req ports.CompletionRequest) (*ports.CompletionResponse, error) {
+	r, err := p.limiter.Wait(ctx, EstimateTokens(req))
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := p.ProviderPort.Complete(ctx, req)
+	r.Done(usedTokens(resp))
+	return resp, err
+}
+
+// Stream waits for the limiter, then sends the streaming request.
+func (p *Provider) Stream(ctx context.Context, req ports.CompletionRequest, cb ports.StreamCallback) (*ports.CompletionResponse, error) {
+	r, err := p.limiter.Wait(ctx, EstimateTokens(req))
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := p.ProviderPort.Stream(ctx, req, cb)
+	r.Done(usedTokens(resp))
+	return resp, err
+}
+
+// EstimateTokens estimates the tokens a request counts against a
+// tokens-per-minute limit: its input at about four characters per token,
+// plus MaxTokens of output.
+func EstimateTokens(req ports.CompletionRequest) int {
+	chars := len(req.SystemPrompt)
+	for _, msg := range req.Messages {
+		chars += len(msg.Content)
+	}
+	return (chars+3)/4 + max(req.MaxTokens, 0)
+}
+
+// usedTokens returns the tokens reported in resp, or -1 to keep the
+// estimate when the response has no usage.
+func usedTokens(resp *ports.CompletionResponse) int {
+	if resp == nil || resp.InputTokens+resp.OutputTokens == 0 {
+		return -1
+	}
+	return resp.InputTokens + resp.OutputTokens
+}
@@ -0,0 +1,165 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jbctechsolutions/skillrunner/internal/adapters/provider/openaicompat"
+	"github.com/jbctechsolutions/skillrunner/internal/application/ports"
+)
+
+// mockProvider implements ports.ProviderPort and reports rate limits for
+// testing.
+type mockProvider struct {
+	resp        *ports.CompletionResponse
+	calls       int
+	onRateLimit func(*openaicompat.RateLimitInfo)
+}
+
+func (m *mockProvider) Info() ports.ProviderInfo {
+	return ports.ProviderInfo{Name: "mock"}
+}
+
+func (m *mockProvider) ListModels(_ context.Context) ([]string, error) {
+	return []string{"model"}, nil
+}
+
+func (m *mockProvider) SupportsModel(_ context.Context, _ string) (bool, error) {
+	return true, nil
+}
+
+func (m *mockProvider) IsAvailable(_ context.Context, _ string) (bool, error) {
+	return true, nil
+}
+
+func (m *mockProvider) Complete(_ context.Context, _ ports.CompletionRequest) (*ports.CompletionResponse, error) {
+	m.calls++
+	return m.resp, nil
+}
+
+func (m *mockProvider) Stream(_ context.Context, _ ports.CompletionRequest, cb ports.StreamCallback) (*ports.CompletionResponse, error) {
+	m.calls++
+	if err := cb(m.resp.Content); err != nil {
+		return nil, err
+	}
+	return m.resp, nil
+}
+
+func (m *mockProvider) HealthCheck(_ context.Context, _ string) (*ports.HealthStatus, error) {
+	return &ports.HealthStatus{Healthy: true}, nil
+}
+
+func (m *mockProvider) OnRateLimit(fn func(*openaicompat.RateLimitInfo)) {
+	m.onRateLimit = fn
+}
+
+func TestProvider_Complete(t *testing.T) {
+	inner := &mockProvider{resp: &ports.CompletionResponse{Content: "ok", InputTokens: 10, OutputTokens: 20}}
+	p := NewProvider(inner, Config{TokensPerMinute: 1000})
+
+	resp, err := p.Complete(context.Background(), ports.CompletionRequest{
+		Messages:  []ports.Message{{Role: "user", Content: "12345678"}},
+		MaxTokens: 100,
+	})
+	if err != nil {
+		t.Fatalf("Complete failed: %v", err)
+	}
+	if resp.Content != "ok" || inner.calls != 1 {
+		t.Errorf("expected the wrapped provider to answer, got %q after %d calls", resp.Content, inner.calls)
+	}
+	// The 102-token estimate is corrected to the 30 tokens reported
+	if got := int(p.Limiter().tokens.level); got != 970 {
+		t.Errorf("expected 970 tokens left, got %d", got)
+	}
+}
+
+func TestProvider_Stream(t *testing.T) {
+	inner := &mockProvider{resp: &ports.CompletionResponse{Content: "chunk"}}
+	p := NewProvider(inner, Config{ConcurrentRequests: 1})
+
+	var got string
+	if _, err := p.Stream(context.Background(), ports.CompletionRequest{}, func(chunk string) error {
+		got += chunk
+		return nil
+	}); err != nil {
+		t.Fatalf("Stream failed: %v", err)
+	}
+	if got != "chunk" {
+		t.Errorf("expected streamed chunk, got %q", got)
+	}
+
+	// The concurrency slot was released
+	if _, err := p.Stream(context.Background(), ports.CompletionRequest{}, func(string) error { return nil }); err != nil {
+		t.Fatalf("second Stream failed: %v", err)
+	}
+}
+
+func TestProvider_CanceledWhileWaiting(t *testing.T) {
+	inner := &mockProvider{resp: &ports.CompletionResponse{}}
+	p := NewProvider(inner, Config{RequestsPerMinute: 1})
+
+	if _, err := p.Complete(context.Background(), ports.CompletionRequest{}); err != nil {
+		t.Fatalf("first Complete failed: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := p.Complete(ctx, ports.CompletionRequest{}); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled while waiting, got %v", err)
+	}
+	if inner.calls != 1 {
+		t.Errorf("expected the canceled request not to reach the provider, got %d calls", inner.calls)
+	}
+}
+
+func TestNewProvider_ObservesRateLimitHeaders(t *testing.T) {
+	inner := &mockProvider{}
+	p := NewProvider(inner, Config{RequestsPerMinute: 60})
+
+	if inner.onRateLimit == nil {
+		t.Fatal("expected the limiter to be registered for rate limit headers")
+	}
+	reset := p.Limiter().now().Add(time.Minute)
+	inner.onRateLimit(&openaicompat.RateLimitInfo{LimitRequests: 60, RemainingRequests: 0, ResetRequests: reset})
+	if wait := p.Limiter().take(0); wait <= 0 {
+		t.Errorf("expected the limiter to wait for the reset, got %v", wait)
+	}
+	if !p.Throttled() {
+		t.Error("expected the provider to report itself throttled")
+	}
+}
+
+func TestReportsRateLimits(t *testing.T) {
+	if !ReportsRateLimits(&mockProvider{}) {
+		t.Error("expected a provider with OnRateLimit to report rate limits")
+	}
+	if ReportsRateLimits(struct{ ports.ProviderPort }{&mockProvider{}}) {
+		t.Error("expected a provider without OnRateLimit not to report rate limits")
+	}
+}
+
+func TestProvider_Unwrap(t *testing.T) {
+	inner := &mockProvider{}
+	p := NewProvider(inner, Config{RequestsPerMinute: 60})
+
+	if ports.UnwrapProvider(p) != inner {
+		t.Error("expected UnwrapProvider to return the wrapped provider")
+	}
+	if p.Info().Name != "mock" {
+		t.Errorf("expected wrapped provider info, got %q", p.Info().Name)
+	}
+}
+
+func TestEstimateTokens(t *testing.T) {
+	req := ports.CompletionRequest{
+		SystemPrompt: "abcd",
+		Messages:     []ports.Message{{Content: "abcdefgh"}, {Content: "a"}},
+		MaxTokens:    50,
+	}
+	// 13 characters round up to 4 tokens
+	if got := EstimateTokens(req); got != 54 {
+		t.Errorf("EstimateTokens() = %d, want 54", got)
+	}
+}
@@ -0,0 +1,98 @@
+package redact
+
+import (
+	"context"
+
+	"github.com/jbctechsolutions/skillrunner/internal/application/ports"
+)
+
+// Ensure Provider implements CapabilityLayer at compile time.
+var _ ports.CapabilityLayer = (*Provider)(nil)
+
+// As offers the capabilities of the wrapped provider that send prompts, with
+// the prompts redacted: batch jobs, token counting, image generation and
+// transcription. Transcription sends the audio itself as it is. Other
+// capabilities are not offered.
+func (p *Provider) As(target any) bool {
+	switch t := target.(type) {
+	case *ports.BatchProviderPort:
+		b, ok := ports.As[ports.BatchProviderPort](p.ProviderPort)
+		if ok {
+			*t = &batcher{BatchProviderPort: b, p: p}
+		}
+		return ok
+	case *ports.TokenCounterPort:
+		c, ok := ports.As[ports.TokenCounterPort](p.ProviderPort)
+		if ok {
+			*t = &tokenCounter{counter: c, p: p}
+		}
+		return ok
+	case *ports.ImageGenerationPort:
+		g, ok := ports.As[ports.ImageGenerationPort](p.ProviderPort)
+		if ok {
+			*t = &imageGenerator{ImageGenerationPort: g, p: p}
+		}
+		return ok
+	case *ports.TranscriptionPort:
+		tr, ok := ports.As[ports.TranscriptionPort](p.ProviderPort)
+		if ok {
+			*t = &transcriber{TranscriptionPort: tr, p: p}
+		}
+		return ok
+	}
+	return false
+}
+
+// batcher redacts the requests of batch items before submitting them.
+type batcher struct {
+	ports.BatchProviderPort
+	p *Provider
+}
+
+// SubmitBatch redacts the request of each item, then submits them.
+func (b *batcher) SubmitBatch(ctx context.Context, items []ports.BatchItem) (*ports.BatchJob, error) {
+	redacted := make([]ports.BatchItem, len(items))
+	for i, item := range items {
+		item.Request = b.p.redactRequest(item.Request)
+		redacted[i] = item
+	}
+	return b.BatchProviderPort.SubmitBatch(ctx, redacted)
+}
+
+// tokenCounter redacts requests before counting their tokens remotely.
+type tokenCounter struct {
+	counter ports.TokenCounterPort
+	p       *Provider
+}
+
+// CountTokens counts the tokens of the redacted request, which is what
+// completions send.
+func (c *tokenCounter) CountTokens(ctx context.Context, req ports.CompletionRequest) (int, error) {
+	return c.counter.CountTokens(ctx, c.p.redactRequest(req))
+}
+
+// imageGenerator redacts image prompts before they are sent.
+type imageGenerator struct {
+	ports.ImageGenerationPort
+	p *Provider
+}
+
+// GenerateImages redacts the prompt and negative prompt, then generates
+// the images.
+func (g *imageGenerator) GenerateImages(ctx context.Context, req ports.ImageGenerationRequest) (*ports.ImageGenerationResponse, error) {
+	req.Prompt = g.p.redactText(req.Prompt)
+	req.NegativePrompt = g.p.redactText(req.NegativePrompt)
+	return g.ImageGenerationPort.GenerateImages(ctx, req)
+}
+
+// transcriber redacts the prompt of transcription requests.
+type transcriber struct {
+	ports.TranscriptionPort
+	p *Provider
+}
+
+// Transcribe redacts the prompt, then transcribes the audio.
+func (t *transcriber) Transcribe(ctx context.Context, req ports.TranscriptionRequest) (*ports.TranscriptionResponse, error) {
+	req.Prompt = t.p.redactText(req.Prompt)
+	return t.TranscriptionPort.Transcribe(ctx, req)
+}
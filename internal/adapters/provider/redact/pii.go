@@ -0,0 +1,109 @@
+package redact
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Categories of rules selected by name, besides the names of single rules.
+const (
+	// CategorySecrets selects SecretRules.
+	CategorySecrets = "secrets"
+	// CategoryPII selects PIIRules.
+	CategoryPII = "pii"
+)
+
+// piiRules mask personal data by its common formats. Card numbers must
+// also pass the Luhn check, so order and invoice numbers are left alone.
+var piiRules = []Rule{
+	{Name: "email", Pattern: regexp.MustCompile(`\b[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}\b`), Replacement: "[REDACTED:email]"},
+	{Name: "ssn", Pattern: regexp.MustCompile(`\b\d{3}-\d{2}-\d{4}\b`), Replacement: "[REDACTED:ssn]"},
+	{Name: "credit_card", Pattern: regexp.MustCompile(`\b\d(?:[ -]?\d){12,18}\b`), Replacement: "[REDACTED:credit_card]", Valid: luhn},
+}
+
+// PIIRules returns rules that mask personal data: email addresses, US
+// social security numbers and credit card numbers.
+func PIIRules() []Rule {
+	return slices.Clone(piiRules)
+}
+
+// RulesFor returns the rules selected by names, each a category (secrets
+// or pii) or the name of a single rule, such as email.
+func RulesFor(names []string) ([]Rule, error) {
+	var rules []Rule
+	all := slices.Concat(secretRules, piiRules)
+	for _, name := range names {
+		switch name {
+		case CategorySecrets:
+			rules = append(rules, SecretRules()...)
+		case CategoryPII:
+			rules = append(rules, PIIRules()...)
+		default:
+			i := slices.IndexFunc(all, func(r Rule) bool { return r.Name == name })
+			if i < 0 {
+				return nil, fmt.Errorf("unknown redaction %q: must be secrets, pii or a rule such as email", name)
+			}
+			rules = append(rules, all[i])
+		}
+	}
+	return rules, nil
+}
+
+// Allowlist lists values that are never masked, such as a public support
+// address, as literal values or as regular expressions matching the whole
+// value.
+type Allowlist struct {
+	values   map[string]bool
+	patterns []*regexp.Regexp
+}
+
+// NewAllowlist compiles allowlist entries. An entry between slashes, such as
+// /.*@example\.com/, is a regular expression; any other is a literal value.
+func NewAllowlist(entries []string) (*Allowlist, error) {
+	a := &Allowlist{values: make(map[string]bool)}
+	for _, e := range entries {
+		if len(e) > 2 && strings.HasPrefix(e, "/") && strings.HasSuffix(e, "/") {
+			re, err := regexp.Compile(`^(?:` + e[1:len(e)-1] + `)$`)
+			if err != nil {
+				return nil, fmt.Errorf("allowlist entry %s: %w", e, err)
+			}
+			a.patterns = append(a.patterns, re)
+			continue
+		}
+		a.values[e] = true
+	}
+	return a, nil
+}
+
+// Allows reports whether value is never masked.
+func (a *Allowlist) Allows(value string) bool {
+	if a == nil {
+		return false
+	}
+	if a.values[value] {
+		return true
+	}
+	return slices.ContainsFunc(a.patterns, func(re *regexp.Regexp) bool { return re.MatchString(value) })
+}
+
+// luhn reports whether the digits of number pass the Luhn checksum.
+func luhn(number string) bool {
+	sum, double := 0, false
+	for i := len(number) - 1; i >= 0; i-- {
+		c := number[i]
+		if c < '0' || c > '9' {
+			continue
+		}
+		d := int(c - '0')
+		if double {
+			if d *= 2; d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+	}
+	return sum%10 == 0
+}
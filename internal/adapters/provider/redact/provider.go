@@ -0,0 +1,167 @@
+// Package redact masks text matching redaction rules, such as an
+// organization's customer IDs or internal hostnames, credentials or personal
+// data, in the prompts sent to a provider.
+package redact
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"regexp"
+	"sync"
+
+	"github.com/jbctechsolutions/skillrunner/internal/application/ports"
+)
+
+// Rule replaces every match of Pattern with Replacement. When Valid is set,
+// only the matches it accepts are replaced.
+type Rule struct {
+	Name        string
+	Pattern     *regexp.Regexp
+	Replacement string
+	Valid       func(match string) bool
+}
+
+// NewRule compiles a rule. An empty replacement defaults to
+// [REDACTED:<name>].
+func NewRule(name, pattern, replacement string) (Rule, error) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return Rule{}, fmt.Errorf("redaction rule %s: %w", name, err)
+	}
+	if replacement == "" {
+		replacement = "[REDACTED:" + name + "]"
+	}
+	return Rule{Name: name, Pattern: re, Replacement: replacement}, nil
+}
+
+// Redact applies rules to text in order and returns the result and the number
+// of matches replaced.
+func Redact(text string, rules []Rule) (string, int) {
+	count := 0
+	text = redact(text, rules, nil, func(string) { count++ })
+	return text, count
+}
+
+// redact applies rules to text in order, leaving the matches allow allows,
+// and calls replaced with the name of the rule of each match replaced.
+func redact(text string, rules []Rule, allow *Allowlist, replaced func(rule string)) string {
+	for _, rule := range rules {
+		text = rule.Pattern.ReplaceAllStringFunc(text, func(match string) string {
+			if (rule.Valid != nil && !rule.Valid(match)) || allow.Allows(match) {
+				return match
+			}
+			replaced(rule.Name)
+			return rule.Replacement
+		})
+	}
+	return text
+}
+
+// Provider wraps a provider so that the system prompt and messages of its
+// completions and streams are redacted before they are sent. Responses are
+// returned unchanged. Unlike other decorators it has no Unwrap method, so
+// that callers looking for optional capabilities, such as the Batch API,
+// cannot send requests around it; As offers them redacted instead.
+type Provider struct {
+	ports.ProviderPort
+	rules []Rule
+	allow *Allowlist
+
+	mu     sync.Mutex
+	counts map[string]int // matches replaced by rule name
+}
+
+// Option configures a Provider.
+type Option func(*Provider)
+
+// WithAllowlist leaves the values allow allows unmasked.
+func WithAllowlist(allow *Allowlist) Option {
+	return func(p *Provider) {
+		p.allow = allow
+	}
+}
+
+// Ensure Provider implements ProviderPort, ThrottleReporter and Warmer at
+// compile time.
+var (
+	_ ports.ProviderPort     = (*Provider)(nil)
+	_ ports.ThrottleReporter = (*Provider)(nil)
+	_ ports.Warmer           = (*Provider)(nil)
+)
+
+// NewProvider wraps inner so its requests are redacted with rules.
+func NewProvider(inner ports.ProviderPort, rules []Rule, opts ...Option) *Provider {
+	p := &Provider{ProviderPort: inner, rules: rules, counts: make(map[string]int)}
+	for _, opt := range opts {
+		opt(p)
+	}
+	return p
+}
+
+// Redactions returns how many matches of each rule have been masked in the
+// requests sent so far, by rule name.
+func (p *Provider) Redactions() map[string]int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return maps.Clone(p.counts)
+}
+
+// Throttled reports whether the wrapped provider is being throttled.
+func (p *Provider) Throttled() bool {
+	t, ok := p.ProviderPort.(ports.ThrottleReporter)
+	return ok && t.Throttled()
+}
+
+// Warm prepares the model of the wrapped provider, if it supports warming.
+func (p *Provider) Warm(ctx context.Context, modelID string) error {
+	if w, ok := ports.UnwrapProvider(p.ProviderPort).(ports.Warmer); ok {
+		return w.Warm(ctx, modelID)
+	}
+	return nil
+}
+
+// Complete redacts the request, then sends it.
+func (p *Provider) Complete(ctx context.Context, req ports.CompletionRequest) (*ports.CompletionResponse, error) {
+	return p.ProviderPort.Complete(ctx, p.redactRequest(req))
+}
+
+// Stream redacts the request, then sends it.
+func (p *Provider) Stream(ctx context.Context, req ports.CompletionRequest, cb ports.StreamCallback) (*ports.CompletionResponse, error) {
+	return p.ProviderPort.Stream(ctx, p.redactRequest(req), cb)
+}
+
+// redactRequest returns a copy of req with its prompts redacted; the
+// caller's messages are not modified.
+func (p *Provider) redactRequest(req ports.CompletionRequest) ports.CompletionRequest {
+	counts := make(map[string]int)
+	count := func(rule string) { counts[rule]++ }
+
+	req.SystemPrompt = redact(req.SystemPrompt, p.rules, p.allow, count)
+	messages := make([]ports.Message, len(req.Messages))
+	for i, msg := range req.Messages {
+		msg.Content = redact(msg.Content, p.rules, p.allow, count)
+		messages[i] = msg
+	}
+	req.Messages = messages
+
+	p.addCounts(counts)
+	return req
+}
+
+// redactText returns text redacted, counting the matches replaced.
+func (p *Provider) redactText(text string) string {
+	counts := make(map[string]int)
+	text = redact(text, p.rules, p.allow, func(rule string) { counts[rule]++ })
+	p.addCounts(counts)
+	return text
+}
+
+// addCounts adds matches replaced to the counts by rule name.
+func (p *Provider) addCounts(counts map[string]int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for rule, n := range counts {
+		p.counts[rule] += n
+	}
+}
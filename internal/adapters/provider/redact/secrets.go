@@ -0,0 +1,33 @@
+package redact
+
+import (
+	"regexp"
+	"slices"
+)
+
+// secretPatterns match common credentials by their well-known formats.
+var secretPatterns = []struct{ name, pattern string }{
+	{"private_key", `-----BEGIN [A-Z ]*PRIVATE KEY-----[\s\S]*?-----END [A-Z ]*PRIVATE KEY-----`},
+	{"aws_access_key", `\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`},
+	{"api_key", `\b(?:sk|gsk|fw)[-_][A-Za-z0-9_-]{20,}`},
+	{"github_token", `\b(?:gh[pousr]_[A-Za-z0-9]{36,}|github_pat_[A-Za-z0-9_]{22,})`},
+	{"slack_token", `\bxox[abprs]-[A-Za-z0-9-]{10,}`},
+	{"google_api_key", `\bAIza[0-9A-Za-z_-]{35}`},
+	{"bearer_token", `(?i)\bbearer\s+[A-Za-z0-9._~+/-]{20,}=*`},
+}
+
+// secretRules are the compiled secretPatterns.
+var secretRules = func() []Rule {
+	rules := make([]Rule, len(secretPatterns))
+	for i, p := range secretPatterns {
+		rules[i] = Rule{Name: p.name, Pattern: regexp.MustCompile(p.pattern), Replacement: "[REDACTED:" + p.name + "]"}
+	}
+	return rules
+}()
+
+// SecretRules returns rules that mask common credentials: private keys, AWS
+// access keys, provider API keys, GitHub and Slack tokens, Google API keys
+// and bearer tokens.
+func SecretRules() []Rule {
+	return slices.Clone(secretRules)
+}
@@ -0,0 +1,137 @@
+// Package stability provides an image generation adapter for the Stability AI
+// REST API (v1 text-to-image).
+package stability
+
+import (
+	"context"
+	"encoding/base64"
+	"net/url"
+
+	"github.com/jbctechsolutions/skillrunner/internal/adapters/provider/openaicompat"
+	"github.com/jbctechsolutions/skillrunner/internal/application/ports"
+	"github.com/jbctechsolutions/skillrunner/internal/domain/errors"
+)
+
+// ProviderName is the name reported by this adapter.
+const ProviderName = "stability"
+
+// DefaultBaseURL is the Stability AI API endpoint.
+const DefaultBaseURL = "https://api.stability.ai"
+
+// DefaultEngine is the engine used when a request does not name one.
+const DefaultEngine = "stable-diffusion-xl-1024-v1-0"
+
+// Default image size for DefaultEngine.
+const (
+	defaultWidth  = 1024
+	defaultHeight = 1024
+)
+
+// finishReasonFiltered marks an artifact blocked by the content filter.
+const finishReasonFiltered = "CONTENT_FILTERED"
+
+// TextPrompt is a weighted prompt; negative weights steer away from the text.
+type TextPrompt struct {
+	Text   string  `json:"text"`
+	Weight float64 `json:"weight"`
+}
+
+// TextToImageRequest is the request body for text-to-image generation.
+type TextToImageRequest struct {
+	TextPrompts []TextPrompt `json:"text_prompts"`
+	Width       int          `json:"width"`
+	Height      int          `json:"height"`
+	Samples     int          `json:"samples"`
+}
+
+// TextToImageResponse is the response of text-to-image generation.
+type TextToImageResponse struct {
+	Artifacts []Artifact `json:"artifacts"`
+}
+
+// Artifact is one generated image.
+type Artifact struct {
+	Base64       string `json:"base64"`
+	Seed         int64  `json:"seed"`
+	FinishReason string `json:"finishReason"`
+}
+
+// DefaultConfig returns a Config with default values for Stability AI.
+func DefaultConfig(apiKey string) openaicompat.Config {
+	return openaicompat.DefaultConfig(apiKey, DefaultBaseURL)
+}
+
+// Generator implements ports.ImageGenerationPort for Stability AI.
+type Generator struct {
+	client  *openaicompat.Client
+	baseURL string
+}
+
+// Ensure Generator implements ImageGenerationPort at compile time.
+var _ ports.ImageGenerationPort = (*Generator)(nil)
+
+// NewGenerator creates a Stability AI image generator.
+func NewGenerator(config openaicompat.Config, opts ...openaicompat.ClientOption) *Generator {
+	opts = append([]openaicompat.ClientOption{openaicompat.WithHeader("Accept", "application/json")}, opts...)
+	return &Generator{
+		client:  openaicompat.NewClient(config, opts...),
+		baseURL: config.BaseURL,
+	}
+}
+
+// Info returns provider metadata.
+func (g *Generator) Info() ports.ProviderInfo {
+	return ports.ProviderInfo{
+		Name:        ProviderName,
+		Description: "Stability AI image generation (Stable Diffusion)",
+		BaseURL:     g.baseURL,
+		IsLocal:     false,
+	}
+}
+
+// GenerateImages generates images with the engine named by req.Model
+// (DefaultEngine if empty). Images blocked by the content filter are dropped.
+func (g *Generator) GenerateImages(ctx context.Context, req ports.ImageGenerationRequest) (*ports.ImageGenerationResponse, error) {
+	width, height, err := req.Dimensions(defaultWidth, defaultHeight)
+	if err != nil {
+		return nil, errors.NewError(errors.CodeValidation, err.Error(), nil)
+	}
+
+	engine := req.Model
+	if engine == "" {
+		engine = DefaultEngine
+	}
+
+	body := TextToImageRequest{
+		TextPrompts: []TextPrompt{{Text: req.Prompt, Weight: 1}},
+		Width:       width,
+		Height:      height,
+		Samples:     max(req.Count, 1),
+	}
+	if req.NegativePrompt != "" {
+		body.TextPrompts = append(body.TextPrompts, TextPrompt{Text: req.NegativePrompt, Weight: -1})
+	}
+
+	var result TextToImageResponse
+	path := "/v1/generation/" + url.PathEscape(engine) + "/text-to-image"
+	if _, err := g.client.PostJSON(ctx, path, body, &result); err != nil {
+		return nil, err
+	}
+
+	resp := &ports.ImageGenerationResponse{ModelUsed: engine}
+	for _, artifact := range result.Artifacts {
+		if artifact.FinishReason == finishReasonFiltered {
+			continue
+		}
+		data, err := base64.StdEncoding.DecodeString(artifact.Base64)
+		if err != nil {
+			return nil, errors.NewError(errors.CodeProvider, "failed to decode generated image", err)
+		}
+		resp.Images = append(resp.Images, ports.GeneratedImage{Data: data, Format: "png"})
+	}
+	if len(resp.Images) == 0 {
+		return nil, errors.NewError(errors.CodeProvider, "image generation returned no images (content filtered?)", nil)
+	}
+
+	return resp, nil
+}
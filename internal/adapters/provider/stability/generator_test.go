@@ -0,0 +1,73 @@
+package stability
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jbctechsolutions/skillrunner/internal/application/ports"
+)
+
+func newTestGenerator(t *testing.T, handler http.HandlerFunc) (*httptest.Server, *Generator) {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	config := DefaultConfig("sk-test")
+	config.BaseURL = server.URL
+	config.MaxRetries = 0
+	return server, NewGenerator(config)
+}
+
+func TestGenerator_GenerateImages(t *testing.T) {
+	var got TextToImageRequest
+
+	server, generator := newTestGenerator(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/generation/"+DefaultEngine+"/text-to-image" {
+			t.Errorf("unexpected request to %s", r.URL.Path)
+		}
+		if r.Header.Get("Authorization") != "Bearer sk-test" || r.Header.Get("Accept") != "application/json" {
+			t.Errorf("headers = %v", r.Header)
+		}
+		_ = json.NewDecoder(r.Body).Decode(&got)
+
+		image := base64.StdEncoding.EncodeToString([]byte("png-bytes"))
+		_ = json.NewEncoder(w).Encode(TextToImageResponse{Artifacts: []Artifact{
+			{Base64: image, FinishReason: "SUCCESS"},
+			{Base64: image, FinishReason: finishReasonFiltered},
+		}})
+	})
+	defer server.Close()
+
+	resp, err := generator.GenerateImages(context.Background(), ports.ImageGenerationRequest{
+		Prompt:         "a lighthouse at dusk",
+		NegativePrompt: "blurry",
+		Size:           "1152x896",
+		Count:          2,
+	})
+	if err != nil {
+		t.Fatalf("GenerateImages failed: %v", err)
+	}
+
+	if got.Width != 1152 || got.Height != 896 || got.Samples != 2 {
+		t.Errorf("request = %+v", got)
+	}
+	if len(got.TextPrompts) != 2 || got.TextPrompts[1].Text != "blurry" || got.TextPrompts[1].Weight != -1 {
+		t.Errorf("text prompts = %+v", got.TextPrompts)
+	}
+	if len(resp.Images) != 1 || string(resp.Images[0].Data) != "png-bytes" || resp.ModelUsed != DefaultEngine {
+		t.Errorf("response = %+v, want the unfiltered image", resp)
+	}
+}
+
+func TestGenerator_GenerateImages_AllFiltered(t *testing.T) {
+	server, generator := newTestGenerator(t, func(w http.ResponseWriter, _ *http.Request) {
+		_ = json.NewEncoder(w).Encode(TextToImageResponse{Artifacts: []Artifact{{FinishReason: finishReasonFiltered}}})
+	})
+	defer server.Close()
+
+	if _, err := generator.GenerateImages(context.Background(), ports.ImageGenerationRequest{Prompt: "x"}); err == nil {
+		t.Error("expected error when every image is filtered")
+	}
+}
@@ -0,0 +1,144 @@
+// Package timeout bounds each call to a provider by a timeout for its kind
+// of operation, so that health checks fail fast while completions from slow
+// local models may take minutes, and aborts streams and completions that
+// stall.
+package timeout
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jbctechsolutions/skillrunner/internal/application/ports"
+)
+
+// Config holds the timeout of each kind of provider operation. A zero
+// timeout leaves the operation bounded only by the provider's HTTP client.
+type Config struct {
+	HealthCheck time.Duration // HealthCheck and IsAvailable
+	ListModels  time.Duration // ListModels and SupportsModel
+	Request     time.Duration // Complete and Stream, for the whole stream
+	StreamStall time.Duration // Longest wait for the next chunk of a stream, including the first
+}
+
+// IsZero reports whether no timeout is set.
+func (c Config) IsZero() bool {
+	return c.HealthCheck == 0 && c.ListModels == 0 && c.Request == 0 && c.StreamStall == 0
+}
+
+// Longest returns the longest timeout set for an operation. The stall
+// timeout is not one: it bounds the gaps within a stream.
+func (c Config) Longest() time.Duration {
+	return max(c.HealthCheck, c.ListModels, c.Request)
+}
+
+// Provider wraps a provider so that each call is canceled once the timeout
+// of its operation elapses.
+type Provider struct {
+	ports.ProviderPort
+	cfg Config
+}
+
+// Ensure Provider implements ProviderPort and ThrottleReporter at compile time.
+var (
+	_ ports.ProviderPort     = (*Provider)(nil)
+	_ ports.ThrottleReporter = (*Provider)(nil)
+)
+
+// NewProvider wraps inner so that its calls time out as set in cfg.
+func NewProvider(inner ports.ProviderPort, cfg Config) *Provider {
+	return &Provider{ProviderPort: inner, cfg: cfg}
+}
+
+// Unwrap returns the wrapped provider.
+func (p *Provider) Unwrap() ports.ProviderPort {
+	return p.ProviderPort
+}
+
+// Throttled reports whether the wrapped provider is being throttled.
+func (p *Provider) Throttled() bool {
+	t, ok := p.ProviderPort.(ports.ThrottleReporter)
+	return ok && t.Throttled()
+}
+
+// Config returns the provider's timeouts.
+func (p *Provider) Config() Config {
+	return p.cfg
+}
+
+// ListModels lists the provider's models within the list_models timeout.
+func (p *Provider) ListModels(ctx context.Context) ([]string, error) {
+	ctx, cancel := withTimeout(ctx, p.cfg.ListModels)
+	defer cancel()
+	return p.ProviderPort.ListModels(ctx)
+}
+
+// SupportsModel checks the model within the list_models timeout.
+func (p *Provider) SupportsModel(ctx context.Context, modelID string) (bool, error) {
+	ctx, cancel := withTimeout(ctx, p.cfg.ListModels)
+	defer cancel()
+	return p.ProviderPort.SupportsModel(ctx, modelID)
+}
+
+// IsAvailable checks the model within the health check timeout.
+func (p *Provider) IsAvailable(ctx context.Context, modelID string) (bool, error) {
+	ctx, cancel := withTimeout(ctx, p.cfg.HealthCheck)
+	defer cancel()
+	return p.ProviderPort.IsAvailable(ctx, modelID)
+}
+
+// HealthCheck checks the provider within the health check timeout.
+func (p *Provider) HealthCheck(ctx context.Context, modelID string) (*ports.HealthStatus, error) {
+	ctx, cancel := withTimeout(ctx, p.cfg.HealthCheck)
+	defer cancel()
+	return p.ProviderPort.HealthCheck(ctx, modelID)
+}
+
+// Complete sends the completion request within the request timeout. With a
+// stall timeout, the response is streamed and assembled instead, so that a
+// model that stops generating fails with ports.ErrStreamStalled as a stream
+// does. Requests with tools complete as they are, since tool calls stream
+// without content.
+func (p *Provider) Complete(ctx context.Context, req ports.CompletionRequest) (*ports.CompletionResponse, error) {
+	if p.cfg.StreamStall > 0 && len(req.Tools) == 0 {
+		return p.Stream(ctx, req, func(string) error { return nil })
+	}
+	ctx, cancel := withTimeout(ctx, p.cfg.Request)
+	defer cancel()
+	return p.ProviderPort.Complete(ctx, req)
+}
+
+// Stream sends the streaming request, which must finish within the request
+// timeout. With a stall timeout, the stream is aborted with
+// ports.ErrStreamStalled once no content arrives for that long, so a stalled
+// stream fails fast rather than at the request timeout.
+func (p *Provider) Stream(ctx context.Context, req ports.CompletionRequest, cb ports.StreamCallback) (*ports.CompletionResponse, error) {
+	ctx, cancel := withTimeout(ctx, p.cfg.Request)
+	defer cancel()
+	if p.cfg.StreamStall <= 0 {
+		return p.ProviderPort.Stream(ctx, req, cb)
+	}
+
+	stalled := fmt.Errorf("%w: no content from %s for %s", ports.ErrStreamStalled, p.Info().Name, p.cfg.StreamStall)
+	ctx, abort := context.WithCancelCause(ctx)
+	defer abort(nil)
+	watchdog := time.AfterFunc(p.cfg.StreamStall, func() { abort(stalled) })
+	defer watchdog.Stop()
+
+	resp, err := p.ProviderPort.Stream(ctx, req, func(chunk string) error {
+		watchdog.Reset(p.cfg.StreamStall)
+		return cb(chunk)
+	})
+	if err != nil && context.Cause(ctx) == stalled {
+		return nil, stalled
+	}
+	return resp, err
+}
+
+// withTimeout returns ctx bounded by d, or ctx itself if d is zero.
+func withTimeout(ctx context.Context, d time.Duration) (context.Context, context.CancelFunc) {
+	if d <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, d)
+}
@@ -0,0 +1,137 @@
+package timeout
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jbctechsolutions/skillrunner/internal/application/ports"
+)
+
+// slowProvider answers every call after delay, unless its context ends first.
+type slowProvider struct {
+	ports.ProviderPort
+	delay time.Duration
+}
+
+func (s *slowProvider) wait(ctx context.Context) error {
+	select {
+	case <-time.After(s.delay):
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (s *slowProvider) ListModels(ctx context.Context) ([]string, error) {
+	return []string{"m"}, s.wait(ctx)
+}
+
+func (s *slowProvider) HealthCheck(ctx context.Context, _ string) (*ports.HealthStatus, error) {
+	return &ports.HealthStatus{Healthy: true}, s.wait(ctx)
+}
+
+func (s *slowProvider) Complete(ctx context.Context, _ ports.CompletionRequest) (*ports.CompletionResponse, error) {
+	return &ports.CompletionResponse{Content: "ok"}, s.wait(ctx)
+}
+
+func TestProvider_TimeoutsByOperation(t *testing.T) {
+	p := NewProvider(&slowProvider{delay: 50 * time.Millisecond}, Config{
+		HealthCheck: 10 * time.Millisecond,
+		Request:     time.Second,
+	})
+	ctx := context.Background()
+
+	if _, err := p.HealthCheck(ctx, ""); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("HealthCheck() error = %v, want a deadline exceeded", err)
+	}
+	if _, err := p.Complete(ctx, ports.CompletionRequest{}); err != nil {
+		t.Errorf("Complete() error = %v, want none within the request timeout", err)
+	}
+	if _, err := p.ListModels(ctx); err != nil {
+		t.Errorf("ListModels() error = %v, want none without a timeout", err)
+	}
+}
+
+// stallingProvider streams chunks, then stops sending until its context ends.
+type stallingProvider struct {
+	ports.ProviderPort
+	chunks int
+	gap    time.Duration
+}
+
+func (s *stallingProvider) Info() ports.ProviderInfo {
+	return ports.ProviderInfo{Name: "local"}
+}
+
+func (s *stallingProvider) Stream(ctx context.Context, _ ports.CompletionRequest, cb ports.StreamCallback) (*ports.CompletionResponse, error) {
+	for range s.chunks {
+		time.Sleep(s.gap)
+		if err := cb("x"); err != nil {
+			return nil, err
+		}
+	}
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func TestProvider_StreamStall(t *testing.T) {
+	// Chunks arrive more often than the stall timeout, for longer than it
+	p := NewProvider(&stallingProvider{chunks: 5, gap: 20 * time.Millisecond}, Config{
+		Request:     10 * time.Second,
+		StreamStall: 50 * time.Millisecond,
+	})
+
+	received := 0
+	start := time.Now()
+	_, err := p.Stream(context.Background(), ports.CompletionRequest{}, func(string) error {
+		received++
+		return nil
+	})
+	if !errors.Is(err, ports.ErrStreamStalled) {
+		t.Fatalf("Stream() error = %v, want a stalled stream", err)
+	}
+	if received != 5 {
+		t.Errorf("received %d chunks, want 5 before the stall", received)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("stall detected after %s, want well before the request timeout", elapsed)
+	}
+}
+
+func TestProvider_CompleteStall(t *testing.T) {
+	p := NewProvider(&stallingProvider{chunks: 2, gap: 10 * time.Millisecond}, Config{
+		Request:     10 * time.Second,
+		StreamStall: 50 * time.Millisecond,
+	})
+
+	start := time.Now()
+	if _, err := p.Complete(context.Background(), ports.CompletionRequest{}); !errors.Is(err, ports.ErrStreamStalled) {
+		t.Fatalf("Complete() error = %v, want a stalled completion", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("stall detected after %s, want well before the request timeout", elapsed)
+	}
+
+	// Tool calls stream without content, so requests with tools are not
+	// streamed
+	slow := NewProvider(&slowProvider{delay: 100 * time.Millisecond}, Config{StreamStall: 50 * time.Millisecond})
+	resp, err := slow.Complete(context.Background(), ports.CompletionRequest{Tools: []ports.Tool{{Name: "search"}}})
+	if err != nil || resp.Content != "ok" {
+		t.Errorf("Complete() with tools = %v, %v; want the completion", resp, err)
+	}
+}
+
+func TestConfig(t *testing.T) {
+	if !(Config{}).IsZero() {
+		t.Error("IsZero() = false for an empty config")
+	}
+	if (Config{StreamStall: time.Second}).Longest() != 0 {
+		t.Error("Longest() should not count the stall timeout")
+	}
+	cfg := Config{HealthCheck: 5 * time.Second, Request: 10 * time.Minute}
+	if cfg.IsZero() || cfg.Longest() != 10*time.Minute {
+		t.Errorf("IsZero(), Longest() = %v, %v; want false, 10m", cfg.IsZero(), cfg.Longest())
+	}
+}
@@ -0,0 +1,77 @@
+// Package together provides an adapter for the Together AI API.
+// Together AI exposes an OpenAI-compatible API, so this package only supplies
+// defaults and model metadata on top of the shared openaicompat provider.
+package together
+
+import (
+	"slices"
+
+	"github.com/jbctechsolutions/skillrunner/internal/adapters/provider/openaicompat"
+	"github.com/jbctechsolutions/skillrunner/internal/application/ports"
+)
+
+// ProviderName is the name used to register this provider.
+const ProviderName = "together"
+
+// DefaultBaseURL is the default Together AI API endpoint.
+const DefaultBaseURL = "https://api.together.xyz/v1"
+
+// Available Together AI models.
+const (
+	ModelLlama33_70BInstructTurbo  = "meta-llama/Llama-3.3-70B-Instruct-Turbo"
+	ModelLlama31_8BInstructTurbo   = "meta-llama/Meta-Llama-3.1-8B-Instruct-Turbo"
+	ModelLlama31_405BInstructTurbo = "meta-llama/Meta-Llama-3.1-405B-Instruct-Turbo"
+	ModelQwen25_72BInstructTurbo   = "Qwen/Qwen2.5-72B-Instruct-Turbo"
+	ModelQwen25Coder32BInstruct    = "Qwen/Qwen2.5-Coder-32B-Instruct"
+	ModelMixtral8x7BInstruct       = "mistralai/Mixtral-8x7B-Instruct-v0.1"
+	ModelDeepSeekV3                = "deepseek-ai/DeepSeek-V3"
+)
+
+// SupportedModels returns the list of models supported by this adapter.
+func SupportedModels() []string {
+	return []string{
+		ModelLlama33_70BInstructTurbo,
+		ModelLlama31_8BInstructTurbo,
+		ModelLlama31_405BInstructTurbo,
+		ModelQwen25_72BInstructTurbo,
+		ModelQwen25Coder32BInstruct,
+		ModelMixtral8x7BInstruct,
+		ModelDeepSeekV3,
+	}
+}
+
+// IsSupportedModel checks if a model ID is in the list of supported models.
+func IsSupportedModel(modelID string) bool {
+	return slices.Contains(SupportedModels(), modelID)
+}
+
+// DefaultConfig returns a Config with default values for Together AI.
+func DefaultConfig(apiKey string) openaicompat.Config {
+	return openaicompat.DefaultConfig(apiKey, DefaultBaseURL)
+}
+
+// Provider implements the ports.ProviderPort interface for Together AI.
+type Provider struct {
+	*openaicompat.Provider
+}
+
+// Ensure Provider implements ProviderPort at compile time.
+var _ ports.ProviderPort = (*Provider)(nil)
+
+// NewProvider creates a new Together AI provider with the given configuration.
+func NewProvider(config openaicompat.Config, opts ...openaicompat.ClientOption) *Provider {
+	spec := openaicompat.ProviderSpec{
+		Name:        ProviderName,
+		Description: "Together AI provider for open-weight models (Llama, Qwen, Mixtral, DeepSeek)",
+		Models:      SupportedModels(),
+	}
+
+	return &Provider{
+		Provider: openaicompat.NewProvider(spec, config, opts...),
+	}
+}
+
+// NewProviderWithAPIKey creates a new Together AI provider with default configuration.
+func NewProviderWithAPIKey(apiKey string, opts ...openaicompat.ClientOption) *Provider {
+	return NewProvider(DefaultConfig(apiKey), opts...)
+}
@@ -0,0 +1,78 @@
+package together
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jbctechsolutions/skillrunner/internal/adapters/provider/openaicompat"
+	"github.com/jbctechsolutions/skillrunner/internal/application/ports"
+)
+
+func TestProvider_Info(t *testing.T) {
+	info := NewProviderWithAPIKey("test-key").Info()
+
+	if info.Name != ProviderName {
+		t.Errorf("expected name %q, got %q", ProviderName, info.Name)
+	}
+	if info.BaseURL != DefaultBaseURL {
+		t.Errorf("expected base URL %q, got %q", DefaultBaseURL, info.BaseURL)
+	}
+	if info.IsLocal {
+		t.Error("expected IsLocal to be false")
+	}
+}
+
+func TestProvider_SupportsModel(t *testing.T) {
+	provider := NewProviderWithAPIKey("test-key")
+
+	for _, model := range SupportedModels() {
+		supported, err := provider.SupportsModel(context.Background(), model)
+		if err != nil {
+			t.Fatalf("SupportsModel failed: %v", err)
+		}
+		if !supported {
+			t.Errorf("expected %q to be supported", model)
+		}
+	}
+
+	if IsSupportedModel("gpt-4") {
+		t.Error("expected gpt-4 to be unsupported")
+	}
+}
+
+func TestProvider_Complete(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != openaicompat.EndpointChatCompletions {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		if got := r.Header.Get("Authorization"); got != "Bearer test-key" {
+			t.Errorf("unexpected Authorization header %q", got)
+		}
+		json.NewEncoder(w).Encode(openaicompat.ChatCompletionResponse{
+			Model: ModelDeepSeekV3,
+			Choices: []openaicompat.Choice{
+				{Message: openaicompat.Message{Role: openaicompat.RoleAssistant, Content: "Hello from Together"}, FinishReason: "stop"},
+			},
+			Usage: openaicompat.Usage{PromptTokens: 5, CompletionTokens: 4},
+		})
+	}))
+	defer server.Close()
+
+	provider := NewProvider(DefaultConfig("test-key"), openaicompat.WithBaseURL(server.URL))
+	resp, err := provider.Complete(context.Background(), ports.CompletionRequest{
+		ModelID:  ModelDeepSeekV3,
+		Messages: []ports.Message{{Role: "user", Content: "Hi"}},
+	})
+	if err != nil {
+		t.Fatalf("Complete failed: %v", err)
+	}
+	if resp.Content != "Hello from Together" {
+		t.Errorf("unexpected content %q", resp.Content)
+	}
+	if resp.InputTokens != 5 || resp.OutputTokens != 4 {
+		t.Errorf("unexpected token usage %d/%d", resp.InputTokens, resp.OutputTokens)
+	}
+}
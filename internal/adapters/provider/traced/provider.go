@@ -0,0 +1,80 @@
+// Package traced records an OpenTelemetry span for each completion and
+// stream a provider serves, with its token usage and cost.
+package traced
+
+import (
+	"context"
+
+	"github.com/jbctechsolutions/skillrunner/internal/application/ports"
+	domainProvider "github.com/jbctechsolutions/skillrunner/internal/domain/provider"
+	"github.com/jbctechsolutions/skillrunner/internal/infrastructure/tracing"
+)
+
+// Provider wraps a provider so that its completions and streams are traced
+// as provider.request spans, nested under the span of the phase that sent
+// them. Other calls (health checks, model listing) are not traced.
+type Provider struct {
+	ports.ProviderPort
+	tracer *tracing.Tracer
+	costs  *domainProvider.CostCalculator
+}
+
+// Ensure Provider implements ProviderPort and ThrottleReporter at compile time.
+var (
+	_ ports.ProviderPort     = (*Provider)(nil)
+	_ ports.ThrottleReporter = (*Provider)(nil)
+)
+
+// NewProvider wraps inner so its requests are traced with tracer. The cost
+// of each request is priced with costs, if not nil.
+func NewProvider(inner ports.ProviderPort, tracer *tracing.Tracer, costs *domainProvider.CostCalculator) *Provider {
+	return &Provider{ProviderPort: inner, tracer: tracer, costs: costs}
+}
+
+// Unwrap returns the wrapped provider.
+func (p *Provider) Unwrap() ports.ProviderPort {
+	return p.ProviderPort
+}
+
+// Throttled reports whether the wrapped provider is being throttled.
+func (p *Provider) Throttled() bool {
+	t, ok := p.ProviderPort.(ports.ThrottleReporter)
+	return ok && t.Throttled()
+}
+
+// Complete sends the completion request in a provider.request span.
+func (p *Provider) Complete(ctx context.Context, req ports.CompletionRequest) (*ports.CompletionResponse, error) {
+	ctx, span := p.tracer.StartProviderSpan(ctx, p.Info().Name, req.ModelID)
+	resp, err := p.ProviderPort.Complete(ctx, req)
+	p.end(span, req, resp, err)
+	return resp, err
+}
+
+// Stream sends the streaming request in a provider.request span that ends
+// with the stream.
+func (p *Provider) Stream(ctx context.Context, req ports.CompletionRequest, cb ports.StreamCallback) (*ports.CompletionResponse, error) {
+	ctx, span := p.tracer.StartProviderSpan(ctx, p.Info().Name, req.ModelID)
+	resp, err := p.ProviderPort.Stream(ctx, req, cb)
+	p.end(span, req, resp, err)
+	return resp, err
+}
+
+// end records the usage and cost of resp on span and ends it.
+func (p *Provider) end(span *tracing.ProviderSpan, req ports.CompletionRequest, resp *ports.CompletionResponse, err error) {
+	if resp != nil {
+		span.SetRequestTokens(resp.InputTokens)
+		span.SetResponse(resp.OutputTokens, resp.FinishReason)
+		if p.costs != nil {
+			model := resp.ModelUsed
+			if model == "" {
+				model = req.ModelID
+			}
+			span.SetCost(p.costs.CalculateOrZero(model, resp.InputTokens, resp.OutputTokens).TotalCost)
+		}
+	}
+	if err != nil {
+		span.EndWithError(err)
+		return
+	}
+	span.End()
+}
@@ -0,0 +1,66 @@
+package traced
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/jbctechsolutions/skillrunner/internal/application/ports"
+	domainProvider "github.com/jbctechsolutions/skillrunner/internal/domain/provider"
+	"github.com/jbctechsolutions/skillrunner/internal/infrastructure/tracing"
+)
+
+// fakeProvider answers completions with fixed usage, or fails.
+type fakeProvider struct {
+	ports.ProviderPort
+	err error
+}
+
+func (f *fakeProvider) Info() ports.ProviderInfo {
+	return ports.ProviderInfo{Name: "fake"}
+}
+
+func (f *fakeProvider) Complete(context.Context, ports.CompletionRequest) (*ports.CompletionResponse, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &ports.CompletionResponse{Content: "ok", InputTokens: 1000, OutputTokens: 500, FinishReason: "stop", ModelUsed: "fake-large"}, nil
+}
+
+func TestProvider_TracesRequests(t *testing.T) {
+	ctx := context.Background()
+	buf := &bytes.Buffer{}
+	tracer, err := tracing.New(ctx, tracing.Config{
+		Enabled:      true,
+		ExporterType: tracing.ExporterStdout,
+		ServiceName:  "test",
+		SampleRate:   1.0,
+		Output:       buf,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	costs := domainProvider.NewCostCalculator()
+	costs.RegisterModel("fake-large", 3.0, 15.0)
+
+	p := NewProvider(&fakeProvider{}, tracer, costs)
+	if _, err := p.Complete(ctx, ports.CompletionRequest{ModelID: "fake-large"}); err != nil {
+		t.Fatalf("Complete() error = %v", err)
+	}
+	failing := NewProvider(&fakeProvider{err: errors.New("HTTP 503")}, tracer, costs)
+	if _, err := failing.Complete(ctx, ports.CompletionRequest{ModelID: "fake-large"}); err == nil {
+		t.Fatal("expected the provider's error")
+	}
+	_ = tracer.Shutdown(ctx)
+
+	for _, want := range []string{"provider.request", "fake-large", "provider.response.tokens", "provider.cost_usd", "HTTP 503"} {
+		if !bytes.Contains(buf.Bytes(), []byte(want)) {
+			t.Errorf("expected trace output to contain %q", want)
+		}
+	}
+	if ports.UnwrapProvider(p) == ports.ProviderPort(p) {
+		t.Error("UnwrapProvider() should reach the wrapped provider")
+	}
+}
@@ -0,0 +1,106 @@
+package transport
+
+import (
+	"encoding/pem"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestNew_ZeroOptions(t *testing.T) {
+	rt, err := New(Options{})
+	if err != nil || rt != nil {
+		t.Errorf("New(Options{}) = %v, %v; want nil, nil", rt, err)
+	}
+}
+
+func TestNew_Proxy(t *testing.T) {
+	var proxied string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied = r.URL.String()
+		_, _ = io.WriteString(w, "via proxy")
+	}))
+	defer proxy.Close()
+
+	rt, err := New(Options{ProxyURL: proxy.URL})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	client := &http.Client{Transport: rt}
+	resp, err := client.Get("http://api.example.invalid/v1/models")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	resp.Body.Close()
+
+	if proxied != "http://api.example.invalid/v1/models" {
+		t.Errorf("proxy received %q, want the upstream URL", proxied)
+	}
+}
+
+func TestNew_CACertPath(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	if _, err := (&http.Client{}).Get(server.URL); err == nil {
+		t.Fatal("the test server's certificate should not be trusted by default")
+	}
+
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	if err := os.WriteFile(caFile, certPEM, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	rt, err := New(Options{CACertPath: caFile})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	resp, err := (&http.Client{Transport: rt}).Get(server.URL)
+	if err != nil {
+		t.Fatalf("Get() with the CA error = %v", err)
+	}
+	resp.Body.Close()
+}
+
+func TestNew_InsecureSkipVerify(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	rt, err := New(Options{InsecureSkipVerify: true})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	resp, err := (&http.Client{Transport: rt}).Get(server.URL)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	resp.Body.Close()
+}
+
+func TestNew_InvalidOptions(t *testing.T) {
+	notPEM := filepath.Join(t.TempDir(), "ca.pem")
+	if err := os.WriteFile(notPEM, []byte("not a certificate"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		opts Options
+	}{
+		{"unsupported proxy scheme", Options{ProxyURL: "ftp://proxy.example.com"}},
+		{"proxy without host", Options{ProxyURL: "http://"}},
+		{"missing CA file", Options{CACertPath: filepath.Join(t.TempDir(), "missing.pem")}},
+		{"CA file without certificates", Options{CACertPath: notPEM}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := New(tt.opts); err == nil {
+				t.Error("New() should fail")
+			}
+		})
+	}
+}
@@ -0,0 +1,110 @@
+// Package whispercpp provides a speech-to-text adapter for a local
+// whisper.cpp server (the examples/server binary, started with e.g.
+// `whisper-server -m models/ggml-base.en.bin`).
+package whispercpp
+
+import (
+	"context"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/jbctechsolutions/skillrunner/internal/adapters/provider/openaicompat"
+	"github.com/jbctechsolutions/skillrunner/internal/application/ports"
+	"github.com/jbctechsolutions/skillrunner/internal/domain/errors"
+)
+
+// ProviderName is the name reported by this adapter.
+const ProviderName = "whispercpp"
+
+// DefaultBaseURL is the address whisper.cpp's server listens on by default.
+const DefaultBaseURL = "http://127.0.0.1:8080"
+
+// DefaultTimeout bounds a single transcription. Local inference on a CPU
+// runs at a few times real time, so the limit is generous.
+const DefaultTimeout = 10 * time.Minute
+
+// endpointInference is the server's transcription endpoint.
+const endpointInference = "/inference"
+
+// inferenceResponse is the verbose_json response of the inference endpoint.
+type inferenceResponse struct {
+	Text     string  `json:"text"`
+	Language string  `json:"language,omitempty"`
+	Duration float64 `json:"duration,omitempty"` // Seconds
+}
+
+// Transcriber implements ports.TranscriptionPort for a whisper.cpp server.
+type Transcriber struct {
+	client  *openaicompat.Client
+	baseURL string
+}
+
+// Ensure Transcriber implements TranscriptionPort at compile time.
+var _ ports.TranscriptionPort = (*Transcriber)(nil)
+
+// NewTranscriber creates a transcriber for the whisper.cpp server at baseURL
+// (DefaultBaseURL if empty).
+func NewTranscriber(baseURL string, opts ...openaicompat.ClientOption) *Transcriber {
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
+	baseURL = strings.TrimSuffix(baseURL, "/")
+
+	config := openaicompat.DefaultConfig("", baseURL)
+	config.Timeout = DefaultTimeout
+
+	return &Transcriber{
+		client:  openaicompat.NewClient(config, opts...),
+		baseURL: baseURL,
+	}
+}
+
+// Info returns provider metadata.
+func (t *Transcriber) Info() ports.ProviderInfo {
+	return ports.ProviderInfo{
+		Name:        ProviderName,
+		Description: "whisper.cpp - Local speech-to-text",
+		BaseURL:     t.baseURL,
+		IsLocal:     true,
+	}
+}
+
+// Transcribe transcribes an audio file with the model the server was started
+// with; req.Model is reported back but cannot select another model.
+func (t *Transcriber) Transcribe(ctx context.Context, req ports.TranscriptionRequest) (*ports.TranscriptionResponse, error) {
+	data, err := os.ReadFile(filepath.Clean(req.AudioPath))
+	if err != nil {
+		return nil, errors.NewError(errors.CodeValidation, "failed to read audio file", err)
+	}
+
+	fields := map[string]string{
+		"response_format": "verbose_json",
+		"temperature":     "0.0",
+	}
+	if req.Language != "" {
+		fields["language"] = req.Language
+	}
+	if req.Prompt != "" {
+		fields["prompt"] = req.Prompt
+	}
+
+	var resp inferenceResponse
+	if _, err := t.client.PostMultipart(ctx, endpointInference, fields, "file", filepath.Base(req.AudioPath), data, &resp); err != nil {
+		return nil, err
+	}
+
+	model := req.Model
+	if model == "" {
+		model = ProviderName
+	}
+
+	return &ports.TranscriptionResponse{
+		Text:      strings.TrimSpace(resp.Text),
+		Language:  resp.Language,
+		Duration:  time.Duration(math.Round(resp.Duration * float64(time.Second))),
+		ModelUsed: model,
+	}, nil
+}
@@ -0,0 +1,82 @@
+package whispercpp
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jbctechsolutions/skillrunner/internal/application/ports"
+)
+
+func writeAudio(t *testing.T) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "memo.wav")
+	if err := os.WriteFile(path, []byte("RIFF"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestTranscriber_Info(t *testing.T) {
+	info := NewTranscriber("").Info()
+	if info.Name != ProviderName || info.BaseURL != DefaultBaseURL || !info.IsLocal {
+		t.Errorf("Info() = %+v", info)
+	}
+}
+
+func TestTranscriber_Transcribe(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/inference" {
+			t.Errorf("unexpected request to %s", r.URL.Path)
+		}
+		if r.Header.Get("Authorization") != "" {
+			t.Error("whisper.cpp requests should not be authenticated")
+		}
+		if err := r.ParseMultipartForm(1 << 20); err != nil {
+			t.Fatalf("failed to parse upload: %v", err)
+		}
+		if got := r.FormValue("language"); got != "de" {
+			t.Errorf("language = %q, want de", got)
+		}
+		if _, header, err := r.FormFile("file"); err != nil || header.Filename != "memo.wav" {
+			t.Errorf("file part = %v, %v", header, err)
+		}
+		_, _ = fmt.Fprint(w, `{"text": " Guten Morgen.\n", "language": "de", "duration": 2.0}`)
+	}))
+	defer server.Close()
+
+	resp, err := NewTranscriber(server.URL+"/").Transcribe(context.Background(), ports.TranscriptionRequest{
+		AudioPath: writeAudio(t),
+		Language:  "de",
+	})
+	if err != nil {
+		t.Fatalf("Transcribe failed: %v", err)
+	}
+
+	if resp.Text != "Guten Morgen." || resp.Language != "de" || resp.Duration != 2*time.Second {
+		t.Errorf("response = %+v", resp)
+	}
+	if resp.ModelUsed != ProviderName {
+		t.Errorf("ModelUsed = %q, want %q", resp.ModelUsed, ProviderName)
+	}
+}
+
+func TestTranscriber_Transcribe_ServerError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = fmt.Fprint(w, `{"error": "failed to read audio"}`)
+	}))
+	defer server.Close()
+
+	_, err := NewTranscriber(server.URL).Transcribe(context.Background(), ports.TranscriptionRequest{
+		AudioPath: writeAudio(t),
+	})
+	if err == nil {
+		t.Error("expected error for a failed inference")
+	}
+}
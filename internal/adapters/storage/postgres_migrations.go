@@ -0,0 +1,341 @@
+package storage
+
+import "github.com/jbctechsolutions/skillrunner/internal/infrastructure/storage/migrate"
+
+// PostgresMigrations returns the Postgres schema migrations. They mirror the
+// SQLite migrations version for version, so both backends hold the same tables
+// and the shared repositories run against either.
+func PostgresMigrations() []migrate.Migration {
+	return []migrate.Migration{
+		{Version: 1, Name: "create_workspaces_table", SQL: pgCreateWorkspacesTable},
+		{Version: 2, Name: "create_sessions_table", SQL: pgCreateSessionsTable},
+		{Version: 3, Name: "create_checkpoints_table", SQL: pgCreateCheckpointsTable},
+		{Version: 4, Name: "create_context_items_table", SQL: pgCreateContextItemsTable},
+		{Version: 5, Name: "create_rules_table", SQL: pgCreateRulesTable},
+		{Version: 6, Name: "create_drift_log_table", SQL: pgCreateDriftLogTable},
+		{Version: 7, Name: "create_indices", SQL: pgCreateIndices},
+		{Version: 8, Name: "create_response_cache_table", SQL: pgCreateResponseCacheTable},
+		{Version: 9, Name: "create_cache_stats_table", SQL: pgCreateCacheStatsTable},
+		{Version: 10, Name: "create_cache_indices", SQL: pgCreateCacheIndices},
+		{Version: 11, Name: "create_execution_records_table", SQL: pgCreateExecutionRecordsTable},
+		{Version: 12, Name: "create_phase_execution_records_table", SQL: pgCreatePhaseExecutionRecordsTable},
+		{Version: 13, Name: "create_metrics_indices", SQL: pgCreateMetricsIndices},
+		{Version: 14, Name: "create_workflow_checkpoints_table", SQL: pgCreateWorkflowCheckpointsTable},
+		{Version: 15, Name: "create_workflow_checkpoint_indices", SQL: pgCreateWorkflowCheckpointIndices},
+		{Version: 16, Name: "create_run_notes_table", SQL: pgCreateRunNotesTable},
+		{Version: 17, Name: "add_workflow_checkpoint_pinned", SQL: pgAddWorkflowCheckpointPinned},
+		{Version: 18, Name: "create_cost_ledger_table", SQL: pgCreateCostLedgerTable},
+		{Version: 19, Name: "add_cost_attribution_tags", SQL: pgAddCostAttributionTags},
+		{Version: 20, Name: "create_audit_log_table", SQL: pgCreateAuditLogTable},
+		{Version: 21, Name: "add_audit_log_request_settings", SQL: pgAddAuditLogRequestSettings},
+		{Version: 22, Name: "add_workflow_checkpoint_archive_path", SQL: pgAddWorkflowCheckpointArchivePath},
+	}
+}
+
+const pgCreateWorkspacesTable = `
+CREATE TABLE workspaces (
+	id TEXT PRIMARY KEY,
+	name TEXT NOT NULL,
+	repo_path TEXT NOT NULL,
+	worktree_path TEXT,
+	branch TEXT,
+	focus TEXT,
+	status TEXT NOT NULL DEFAULT 'active',
+	default_backend TEXT,
+	last_active_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+	created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+`
+
+const pgCreateSessionsTable = `
+CREATE TABLE sessions (
+	id TEXT PRIMARY KEY,
+	workspace_id TEXT NOT NULL REFERENCES workspaces(id) ON DELETE CASCADE,
+	backend TEXT,
+	model TEXT,
+	profile TEXT,
+	status TEXT NOT NULL DEFAULT 'active',
+	tokens_used BIGINT DEFAULT 0,
+	tokens_limit BIGINT DEFAULT 0,
+	pid INTEGER,
+	machine_id TEXT,
+	started_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+	last_active_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+`
+
+const pgCreateCheckpointsTable = `
+CREATE TABLE checkpoints (
+	id TEXT PRIMARY KEY,
+	workspace_id TEXT NOT NULL REFERENCES workspaces(id) ON DELETE CASCADE,
+	session_id TEXT NOT NULL REFERENCES sessions(id) ON DELETE CASCADE,
+	summary TEXT NOT NULL,
+	details TEXT,
+	files_modified TEXT,
+	decisions TEXT,
+	machine_id TEXT,
+	created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+`
+
+const pgCreateContextItemsTable = `
+CREATE TABLE context_items (
+	id TEXT PRIMARY KEY,
+	name TEXT NOT NULL,
+	type TEXT NOT NULL,
+	content TEXT,
+	tags TEXT,
+	token_estimate BIGINT DEFAULT 0,
+	last_used_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+	created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+`
+
+const pgCreateRulesTable = `
+CREATE TABLE rules (
+	id TEXT PRIMARY KEY,
+	name TEXT NOT NULL,
+	content TEXT NOT NULL,
+	scope TEXT NOT NULL,
+	is_active BOOLEAN DEFAULT TRUE,
+	created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+`
+
+const pgCreateDriftLogTable = `
+CREATE TABLE drift_log (
+	id BIGSERIAL PRIMARY KEY,
+	workspace_id TEXT NOT NULL REFERENCES workspaces(id) ON DELETE CASCADE,
+	session_id TEXT REFERENCES sessions(id) ON DELETE SET NULL,
+	original_focus TEXT,
+	detected_topic TEXT,
+	prompt_snippet TEXT,
+	action_taken TEXT,
+	created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+`
+
+const pgCreateIndices = `
+CREATE INDEX IF NOT EXISTS idx_workspaces_status ON workspaces(status);
+CREATE INDEX IF NOT EXISTS idx_workspaces_last_active ON workspaces(last_active_at);
+CREATE INDEX IF NOT EXISTS idx_sessions_workspace ON sessions(workspace_id);
+CREATE INDEX IF NOT EXISTS idx_sessions_status ON sessions(status);
+CREATE INDEX IF NOT EXISTS idx_sessions_last_active ON sessions(last_active_at);
+CREATE INDEX IF NOT EXISTS idx_sessions_backend ON sessions(backend);
+CREATE INDEX IF NOT EXISTS idx_checkpoints_workspace ON checkpoints(workspace_id);
+CREATE INDEX IF NOT EXISTS idx_checkpoints_session ON checkpoints(session_id);
+CREATE INDEX IF NOT EXISTS idx_checkpoints_created ON checkpoints(created_at);
+CREATE INDEX IF NOT EXISTS idx_context_items_name ON context_items(name);
+CREATE INDEX IF NOT EXISTS idx_context_items_type ON context_items(type);
+CREATE INDEX IF NOT EXISTS idx_context_items_last_used ON context_items(last_used_at);
+CREATE INDEX IF NOT EXISTS idx_rules_scope ON rules(scope);
+CREATE INDEX IF NOT EXISTS idx_rules_active ON rules(is_active);
+CREATE INDEX IF NOT EXISTS idx_drift_log_workspace ON drift_log(workspace_id);
+CREATE INDEX IF NOT EXISTS idx_drift_log_session ON drift_log(session_id);
+CREATE INDEX IF NOT EXISTS idx_drift_log_created ON drift_log(created_at);
+`
+
+const pgCreateResponseCacheTable = `
+CREATE TABLE response_cache (
+	key TEXT PRIMARY KEY,
+	fingerprint TEXT NOT NULL,
+	model_id TEXT NOT NULL,
+	response_content TEXT NOT NULL,
+	input_tokens BIGINT DEFAULT 0,
+	output_tokens BIGINT DEFAULT 0,
+	finish_reason TEXT,
+	model_used TEXT,
+	duration_ns BIGINT DEFAULT 0,
+	size_bytes BIGINT DEFAULT 0,
+	hit_count BIGINT DEFAULT 0,
+	ttl_seconds BIGINT NOT NULL,
+	created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+	expires_at TIMESTAMPTZ NOT NULL,
+	last_accessed_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+`
+
+const pgCreateCacheStatsTable = `
+CREATE TABLE cache_stats (
+	id BIGSERIAL PRIMARY KEY,
+	stat_type TEXT NOT NULL,
+	stat_value BIGINT DEFAULT 0,
+	model_id TEXT,
+	updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+
+INSERT INTO cache_stats (stat_type, stat_value) VALUES
+	('hit_count', 0),
+	('miss_count', 0),
+	('eviction_count', 0),
+	('expired_count', 0),
+	('input_tokens_saved', 0),
+	('output_tokens_saved', 0);
+`
+
+const pgCreateCacheIndices = `
+CREATE INDEX IF NOT EXISTS idx_response_cache_fingerprint ON response_cache(fingerprint);
+CREATE INDEX IF NOT EXISTS idx_response_cache_model ON response_cache(model_id);
+CREATE INDEX IF NOT EXISTS idx_response_cache_expires ON response_cache(expires_at);
+CREATE INDEX IF NOT EXISTS idx_response_cache_created ON response_cache(created_at);
+CREATE INDEX IF NOT EXISTS idx_response_cache_last_accessed ON response_cache(last_accessed_at);
+CREATE INDEX IF NOT EXISTS idx_cache_stats_type ON cache_stats(stat_type);
+CREATE INDEX IF NOT EXISTS idx_cache_stats_model ON cache_stats(model_id);
+`
+
+const pgCreateExecutionRecordsTable = `
+CREATE TABLE execution_records (
+	id TEXT PRIMARY KEY,
+	skill_id TEXT NOT NULL,
+	skill_name TEXT NOT NULL,
+	status TEXT NOT NULL,
+	input_tokens BIGINT DEFAULT 0,
+	output_tokens BIGINT DEFAULT 0,
+	total_cost DOUBLE PRECISION DEFAULT 0,
+	duration_ns BIGINT DEFAULT 0,
+	phase_count INTEGER DEFAULT 0,
+	cache_hits INTEGER DEFAULT 0,
+	cache_misses INTEGER DEFAULT 0,
+	primary_model TEXT,
+	started_at TIMESTAMPTZ NOT NULL,
+	completed_at TIMESTAMPTZ NOT NULL,
+	correlation_id TEXT
+);
+`
+
+const pgCreatePhaseExecutionRecordsTable = `
+CREATE TABLE phase_execution_records (
+	id TEXT PRIMARY KEY,
+	execution_id TEXT NOT NULL REFERENCES execution_records(id) ON DELETE CASCADE,
+	phase_id TEXT NOT NULL,
+	phase_name TEXT NOT NULL,
+	status TEXT NOT NULL,
+	provider TEXT NOT NULL,
+	model TEXT NOT NULL,
+	input_tokens BIGINT DEFAULT 0,
+	output_tokens BIGINT DEFAULT 0,
+	cost DOUBLE PRECISION DEFAULT 0,
+	duration_ns BIGINT DEFAULT 0,
+	cache_hit BOOLEAN DEFAULT FALSE,
+	started_at TIMESTAMPTZ NOT NULL,
+	completed_at TIMESTAMPTZ NOT NULL,
+	error_message TEXT
+);
+`
+
+const pgCreateMetricsIndices = `
+CREATE INDEX IF NOT EXISTS idx_execution_records_skill ON execution_records(skill_id);
+CREATE INDEX IF NOT EXISTS idx_execution_records_status ON execution_records(status);
+CREATE INDEX IF NOT EXISTS idx_execution_records_started ON execution_records(started_at);
+CREATE INDEX IF NOT EXISTS idx_execution_records_completed ON execution_records(completed_at);
+CREATE INDEX IF NOT EXISTS idx_execution_records_correlation ON execution_records(correlation_id);
+CREATE INDEX IF NOT EXISTS idx_phase_records_execution ON phase_execution_records(execution_id);
+CREATE INDEX IF NOT EXISTS idx_phase_records_provider ON phase_execution_records(provider);
+CREATE INDEX IF NOT EXISTS idx_phase_records_model ON phase_execution_records(model);
+CREATE INDEX IF NOT EXISTS idx_phase_records_started ON phase_execution_records(started_at);
+CREATE INDEX IF NOT EXISTS idx_phase_records_cache_hit ON phase_execution_records(cache_hit);
+`
+
+const pgCreateWorkflowCheckpointsTable = `
+CREATE TABLE workflow_checkpoints (
+	id TEXT PRIMARY KEY,
+	execution_id TEXT NOT NULL,
+	skill_id TEXT NOT NULL,
+	skill_name TEXT NOT NULL,
+	input TEXT NOT NULL,
+	input_hash TEXT NOT NULL,
+	completed_batch INTEGER DEFAULT -1,
+	total_batches INTEGER NOT NULL,
+	phase_results TEXT,
+	phase_outputs TEXT,
+	status TEXT NOT NULL DEFAULT 'in_progress',
+	input_tokens BIGINT DEFAULT 0,
+	output_tokens BIGINT DEFAULT 0,
+	machine_id TEXT,
+	created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+	updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+`
+
+const pgCreateWorkflowCheckpointIndices = `
+CREATE INDEX IF NOT EXISTS idx_wf_checkpoint_skill_input ON workflow_checkpoints(skill_id, input_hash);
+CREATE INDEX IF NOT EXISTS idx_wf_checkpoint_execution ON workflow_checkpoints(execution_id);
+CREATE INDEX IF NOT EXISTS idx_wf_checkpoint_status ON workflow_checkpoints(status);
+CREATE INDEX IF NOT EXISTS idx_wf_checkpoint_machine ON workflow_checkpoints(machine_id);
+CREATE INDEX IF NOT EXISTS idx_wf_checkpoint_updated ON workflow_checkpoints(updated_at);
+CREATE INDEX IF NOT EXISTS idx_wf_checkpoint_created ON workflow_checkpoints(created_at);
+`
+
+const pgCreateRunNotesTable = `
+CREATE TABLE run_notes (
+	id BIGSERIAL PRIMARY KEY,
+	run_id TEXT NOT NULL,
+	text TEXT NOT NULL,
+	created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS idx_run_notes_run ON run_notes(run_id);
+`
+
+const pgAddWorkflowCheckpointPinned = `
+ALTER TABLE workflow_checkpoints ADD COLUMN pinned BOOLEAN NOT NULL DEFAULT FALSE;
+`
+
+const pgCreateCostLedgerTable = `
+CREATE TABLE cost_ledger (
+	id BIGSERIAL PRIMARY KEY,
+	execution_id TEXT NOT NULL,
+	phase_id TEXT NOT NULL,
+	skill_id TEXT NOT NULL,
+	provider TEXT NOT NULL,
+	model TEXT NOT NULL,
+	input_tokens BIGINT DEFAULT 0,
+	output_tokens BIGINT DEFAULT 0,
+	input_cost DOUBLE PRECISION DEFAULT 0,
+	output_cost DOUBLE PRECISION DEFAULT 0,
+	total_cost DOUBLE PRECISION DEFAULT 0,
+	recorded_at TIMESTAMPTZ NOT NULL,
+	UNIQUE (execution_id, phase_id)
+);
+CREATE INDEX IF NOT EXISTS idx_cost_ledger_recorded ON cost_ledger(recorded_at);
+`
+
+const pgAddCostAttributionTags = `
+ALTER TABLE workflow_checkpoints ADD COLUMN tags TEXT;
+CREATE TABLE cost_ledger_tags (
+	execution_id TEXT NOT NULL,
+	name TEXT NOT NULL,
+	value TEXT NOT NULL,
+	PRIMARY KEY (execution_id, name)
+);
+CREATE INDEX IF NOT EXISTS idx_cost_ledger_tags_name ON cost_ledger_tags(name, value);
+`
+
+const pgCreateAuditLogTable = `
+CREATE TABLE audit_log (
+	id BIGSERIAL PRIMARY KEY,
+	execution_id TEXT,
+	skill_id TEXT,
+	phase_id TEXT,
+	provider TEXT NOT NULL,
+	model TEXT NOT NULL,
+	system_prompt TEXT,
+	messages TEXT NOT NULL,
+	response TEXT,
+	error TEXT,
+	input_tokens BIGINT DEFAULT 0,
+	output_tokens BIGINT DEFAULT 0,
+	redactions BIGINT DEFAULT 0,
+	recorded_at TIMESTAMPTZ NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_audit_log_execution ON audit_log(execution_id);
+CREATE INDEX IF NOT EXISTS idx_audit_log_recorded ON audit_log(recorded_at);
+`
+
+const pgAddAuditLogRequestSettings = `
+ALTER TABLE audit_log ADD COLUMN max_tokens INTEGER DEFAULT 0;
+ALTER TABLE audit_log ADD COLUMN temperature DOUBLE PRECISION DEFAULT 0;
+`
+
+const pgAddWorkflowCheckpointArchivePath = `
+ALTER TABLE workflow_checkpoints ADD COLUMN archive_path TEXT;
+`
@@ -0,0 +1,140 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/jbctechsolutions/skillrunner/internal/domain/metrics"
+	"github.com/jbctechsolutions/skillrunner/internal/domain/workflow"
+	"github.com/jbctechsolutions/skillrunner/internal/infrastructure/storage/migrate"
+)
+
+func TestOpenPostgres_Errors(t *testing.T) {
+	ctx := context.Background()
+
+	if _, err := OpenPostgres(ctx, Options{}); err == nil {
+		t.Error("OpenPostgres() without a dsn should fail")
+	}
+	if _, err := OpenPostgres(ctx, Options{DSN: "postgres://localhost:notaport/skillrunner"}); err == nil {
+		t.Error("OpenPostgres() with an invalid dsn should fail")
+	}
+}
+
+// TestOpenPostgres_Integration opens the database SKILLRUNNER_TEST_POSTGRES_DSN
+// names, applies the migrations and round-trips a run. It is skipped when the
+// variable is not set.
+func TestOpenPostgres_Integration(t *testing.T) {
+	dsn := os.Getenv("SKILLRUNNER_TEST_POSTGRES_DSN")
+	if dsn == "" {
+		t.Skip("SKILLRUNNER_TEST_POSTGRES_DSN not set")
+	}
+	ctx := context.Background()
+
+	backend, err := OpenPostgres(ctx, Options{DSN: dsn})
+	if err != nil {
+		t.Fatalf("OpenPostgres() error = %v", err)
+	}
+	defer backend.Close()
+	if _, err := backend.Migrate(ctx); err != nil {
+		t.Fatalf("Migrate() error = %v", err)
+	}
+
+	now := time.Now()
+	id := fmt.Sprintf("it-%d", now.UnixNano())
+	exec := &metrics.ExecutionRecord{
+		ID:          id,
+		SkillID:     "integration-test",
+		SkillName:   "Integration Test",
+		Status:      "completed",
+		InputTokens: 100,
+		StartedAt:   now.Add(-time.Minute),
+		CompletedAt: now,
+	}
+	if err := backend.Metrics().SaveExecution(ctx, exec); err != nil {
+		t.Fatalf("SaveExecution() error = %v", err)
+	}
+	executions, err := backend.Metrics().GetExecutions(ctx, metrics.MetricsFilter{SkillID: "integration-test"})
+	if err != nil {
+		t.Fatalf("GetExecutions() error = %v", err)
+	}
+	if !slices.ContainsFunc(executions, func(e metrics.ExecutionRecord) bool { return e.ID == id && e.InputTokens == 100 }) {
+		t.Errorf("GetExecutions() = %+v, want %s", executions, id)
+	}
+
+	cp, err := workflow.NewWorkflowCheckpoint(id, id, "integration-test", "Integration Test", "input", 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkpoints := backend.WorkflowCheckpoints()
+	if err := checkpoints.Create(ctx, cp); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	defer func() { _, _ = checkpoints.DeleteByExecutionID(ctx, id) }()
+	got, err := checkpoints.Get(ctx, id)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if got.ExecutionID() != id || got.SkillID() != "integration-test" || got.TotalBatches() != 2 {
+		t.Errorf("Get() = %s/%s/%d, want the checkpoint created", got.ExecutionID(), got.SkillID(), got.TotalBatches())
+	}
+}
+
+// TestPostgresBackend_Rebound runs the Postgres backend on SQLite, which also
+// accepts $n parameters and the Postgres column types, to check that the
+// migrations apply and the repositories' queries are rebound.
+func TestPostgresBackend_Rebound(t *testing.T) {
+	ctx := context.Background()
+
+	db, err := openRebound("sqlite3", filepath.Join(t.TempDir(), "pg.db"), migrate.Postgres)
+	if err != nil {
+		t.Fatalf("openRebound() error = %v", err)
+	}
+	backend, err := newPostgresBackend(ctx, db, Options{MaxOpenConns: 1})
+	if err != nil {
+		t.Fatalf("newPostgresBackend() error = %v", err)
+	}
+	defer backend.Close()
+
+	applied, err := backend.Migrate(ctx)
+	if err != nil {
+		t.Fatalf("Migrate() error = %v", err)
+	}
+	if applied != len(PostgresMigrations()) {
+		t.Errorf("Migrate() applied %d migrations, want %d", applied, len(PostgresMigrations()))
+	}
+
+	now := time.Now()
+	exec := &metrics.ExecutionRecord{
+		ID:          "exec-1",
+		SkillID:     "code-review",
+		SkillName:   "Code Review",
+		Status:      "completed",
+		InputTokens: 100,
+		StartedAt:   now.Add(-time.Minute),
+		CompletedAt: now,
+	}
+	if err := backend.Metrics().SaveExecution(ctx, exec); err != nil {
+		t.Fatalf("SaveExecution() error = %v", err)
+	}
+
+	executions, err := backend.Metrics().GetExecutions(ctx, metrics.MetricsFilter{SkillID: "code-review"})
+	if err != nil {
+		t.Fatalf("GetExecutions() error = %v", err)
+	}
+	if len(executions) != 1 || executions[0].ID != "exec-1" {
+		t.Errorf("GetExecutions() = %+v, want exec-1", executions)
+	}
+
+	c := backend.Cache(1 << 20)
+	if err := c.Set(ctx, "key", "value", time.Hour); err != nil {
+		t.Fatalf("Cache().Set() error = %v", err)
+	}
+	if !c.Has(ctx, "key") {
+		t.Error("Cache().Has() = false after Set")
+	}
+}
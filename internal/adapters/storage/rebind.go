@@ -0,0 +1,116 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+
+	"github.com/jbctechsolutions/skillrunner/internal/infrastructure/storage/migrate"
+)
+
+// openRebound opens a database whose connections rewrite the ? placeholders of
+// every query into the dialect's bind parameters, so that the repositories,
+// which are written against SQLite, run unchanged on other SQL databases.
+func openRebound(driverName, dsn string, dialect migrate.Dialect) (*sql.DB, error) {
+	db, err := sql.Open(driverName, dsn)
+	if err != nil {
+		return nil, err
+	}
+	base := db.Driver()
+	_ = db.Close()
+
+	c := &reboundConnector{driver: base, dsn: dsn, dialect: dialect}
+	if dc, ok := base.(driver.DriverContext); ok {
+		if c.connector, err = dc.OpenConnector(dsn); err != nil {
+			return nil, err
+		}
+	}
+	return sql.OpenDB(c), nil
+}
+
+// reboundConnector opens connections of the underlying driver wrapped in
+// reboundConn.
+type reboundConnector struct {
+	driver    driver.Driver
+	connector driver.Connector // Nil if the driver has no connectors
+	dsn       string
+	dialect   migrate.Dialect
+}
+
+func (c *reboundConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	var conn driver.Conn
+	var err error
+	if c.connector != nil {
+		conn, err = c.connector.Connect(ctx)
+	} else {
+		conn, err = c.driver.Open(c.dsn)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &reboundConn{Conn: conn, dialect: c.dialect}, nil
+}
+
+func (c *reboundConnector) Driver() driver.Driver {
+	return c.driver
+}
+
+// reboundConn rebinds queries before handing them to the underlying
+// connection, and passes through the optional interfaces it implements.
+type reboundConn struct {
+	driver.Conn
+	dialect migrate.Dialect
+}
+
+func (c *reboundConn) Prepare(query string) (driver.Stmt, error) {
+	return c.Conn.Prepare(c.dialect.Rebind(query))
+}
+
+func (c *reboundConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	if p, ok := c.Conn.(driver.ConnPrepareContext); ok {
+		return p.PrepareContext(ctx, c.dialect.Rebind(query))
+	}
+	return c.Prepare(query)
+}
+
+func (c *reboundConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	if e, ok := c.Conn.(driver.ExecerContext); ok {
+		return e.ExecContext(ctx, c.dialect.Rebind(query), args)
+	}
+	return nil, driver.ErrSkip
+}
+
+func (c *reboundConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	if q, ok := c.Conn.(driver.QueryerContext); ok {
+		return q.QueryContext(ctx, c.dialect.Rebind(query), args)
+	}
+	return nil, driver.ErrSkip
+}
+
+func (c *reboundConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	if b, ok := c.Conn.(driver.ConnBeginTx); ok {
+		return b.BeginTx(ctx, opts)
+	}
+	return c.Conn.Begin() //nolint:staticcheck // Fallback for drivers without BeginTx
+}
+
+func (c *reboundConn) CheckNamedValue(nv *driver.NamedValue) error {
+	if checker, ok := c.Conn.(driver.NamedValueChecker); ok {
+		return checker.CheckNamedValue(nv)
+	}
+	return driver.ErrSkip
+}
+
+func (c *reboundConn) ResetSession(ctx context.Context) error {
+	if r, ok := c.Conn.(driver.SessionResetter); ok {
+		return r.ResetSession(ctx)
+	}
+	return nil
+}
+
+func (c *reboundConn) IsValid() bool {
+	if v, ok := c.Conn.(driver.Validator); ok {
+		return v.IsValid()
+	}
+	return true
+}
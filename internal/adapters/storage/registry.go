@@ -0,0 +1,108 @@
+// Package storage provides the storage backends that persist skillrunner's
+// state, and a registry to open them by name.
+package storage
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/jbctechsolutions/skillrunner/internal/application/ports"
+)
+
+// DefaultBackend is the backend used when none is configured.
+const DefaultBackend = "sqlite"
+
+// Options configures a storage backend.
+type Options struct {
+	// DSN is the backend's connection string. For sqlite it is the database
+	// file path; empty selects ~/.skillrunner/skillrunner.db.
+	DSN string
+
+	// Connection pool limits of server backends; zero uses the backend's
+	// defaults. The sqlite backend ignores them.
+	MaxOpenConns    int
+	MaxIdleConns    int
+	ConnMaxLifetime time.Duration
+}
+
+// Factory connects to a storage backend. Registry.Open migrates the
+// backend's schema afterwards.
+type Factory func(ctx context.Context, opts Options) (ports.StorageBackendPort, error)
+
+// Registry maps backend names to the factories that open them.
+type Registry struct {
+	mu        sync.RWMutex
+	factories map[string]Factory
+}
+
+// NewRegistry creates a new empty storage backend registry.
+func NewRegistry() *Registry {
+	return &Registry{
+		factories: make(map[string]Factory),
+	}
+}
+
+// NewDefaultRegistry creates a registry with the built-in backends.
+func NewDefaultRegistry() *Registry {
+	r := NewRegistry()
+	_ = r.Register(DefaultBackend, OpenSQLite)
+	_ = r.Register(PostgresBackendName, OpenPostgres)
+	return r
+}
+
+// Register makes a backend available under name, replacing any backend
+// previously registered under it.
+func (r *Registry) Register(name string, factory Factory) error {
+	if name == "" {
+		return fmt.Errorf("backend name cannot be empty")
+	}
+	if factory == nil {
+		return fmt.Errorf("backend factory cannot be nil")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.factories[name] = factory
+	return nil
+}
+
+// Names returns the names of the registered backends, sorted.
+func (r *Registry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	names := make([]string, 0, len(r.factories))
+	for name := range r.factories {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// Open connects to the named backend, or DefaultBackend if name is empty, and
+// brings its schema up to date.
+func (r *Registry) Open(ctx context.Context, name string, opts Options) (ports.StorageBackendPort, error) {
+	if name == "" {
+		name = DefaultBackend
+	}
+
+	r.mu.RLock()
+	factory, ok := r.factories[name]
+	r.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown storage backend %q: available backends are %v", name, r.Names())
+	}
+
+	backend, err := factory(ctx, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s storage: %w", name, err)
+	}
+	if _, err := backend.Migrate(ctx); err != nil {
+		_ = backend.Close()
+		return nil, fmt.Errorf("failed to migrate %s storage: %w", name, err)
+	}
+	return backend, nil
+}
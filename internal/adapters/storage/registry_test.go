@@ -0,0 +1,78 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/jbctechsolutions/skillrunner/internal/application/ports"
+)
+
+func TestRegistry_OpenSQLite(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "test.db")
+
+	backend, err := NewDefaultRegistry().Open(ctx, "", Options{DSN: path})
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	defer backend.Close()
+
+	if backend.Name() != DefaultBackend {
+		t.Errorf("Name() = %q, want %q", backend.Name(), DefaultBackend)
+	}
+	if backend.Sessions() == nil || backend.WorkflowCheckpoints() == nil || backend.Metrics() == nil || backend.Cache(1024) == nil {
+		t.Error("expected the sqlite backend to provide every repository")
+	}
+
+	statuses, err := backend.MigrationStatus(ctx)
+	if err != nil {
+		t.Fatalf("MigrationStatus() error = %v", err)
+	}
+	if len(statuses) == 0 {
+		t.Fatal("MigrationStatus() returned no migrations")
+	}
+	for _, s := range statuses {
+		if !s.Applied {
+			t.Errorf("migration %d (%s) not applied after Open()", s.Version, s.Name)
+		}
+	}
+
+	if applied, err := backend.Migrate(ctx); err != nil || applied != 0 {
+		t.Errorf("Migrate() = %d, %v; want nothing to apply", applied, err)
+	}
+}
+
+func TestRegistry_OpenUnknownBackend(t *testing.T) {
+	_, err := NewDefaultRegistry().Open(context.Background(), "boltdb", Options{})
+	if err == nil {
+		t.Fatal("Open() error = nil, want unknown backend error")
+	}
+}
+
+func TestRegistry_Register(t *testing.T) {
+	r := NewDefaultRegistry()
+	wantErr := errors.New("unreachable")
+	factory := func(context.Context, Options) (ports.StorageBackendPort, error) {
+		return nil, wantErr
+	}
+
+	if err := r.Register("", factory); err == nil {
+		t.Error("Register() with empty name should fail")
+	}
+	if err := r.Register("postgres", nil); err == nil {
+		t.Error("Register() with nil factory should fail")
+	}
+	if err := r.Register("postgres", factory); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+
+	if got := r.Names(); !slices.Equal(got, []string{"postgres", "sqlite"}) {
+		t.Errorf("Names() = %v, want [postgres sqlite]", got)
+	}
+	if _, err := r.Open(context.Background(), "postgres", Options{}); !errors.Is(err, wantErr) {
+		t.Errorf("Open() error = %v, want factory error", err)
+	}
+}
@@ -0,0 +1,160 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/jbctechsolutions/skillrunner/internal/adapters/cache"
+	"github.com/jbctechsolutions/skillrunner/internal/adapters/sync/sqlite"
+	"github.com/jbctechsolutions/skillrunner/internal/application/ports"
+	infraStorage "github.com/jbctechsolutions/skillrunner/internal/infrastructure/storage"
+	"github.com/jbctechsolutions/skillrunner/internal/infrastructure/storage/migrate"
+)
+
+// SQLiteBackend keeps all state in a single SQLite database file.
+type SQLiteBackend struct {
+	conn *sqlite.Connection
+	db   *sql.DB
+
+	workflowCheckpoints ports.WorkflowCheckpointPort
+	runNotes            ports.RunNotePort
+	costLedger          ports.CostLedgerPort
+	auditLog            ports.AuditLogPort
+	metrics             ports.MetricsStoragePort
+	sessions            ports.SessionStateStoragePort
+	workspaces          ports.WorkspaceStateStoragePort
+	checkpoints         ports.CheckpointStateStoragePort
+	contextItems        ports.ContextItemStoragePort
+	rules               ports.RuleStoragePort
+}
+
+// OpenSQLite opens the SQLite database at opts.DSN, or the default location
+// if it is empty. It is the Factory of the sqlite backend.
+func OpenSQLite(_ context.Context, opts Options) (ports.StorageBackendPort, error) {
+	return NewSQLiteBackend(opts.DSN)
+}
+
+// NewSQLiteBackend opens the SQLite database at path, or at
+// ~/.skillrunner/skillrunner.db if path is empty.
+func NewSQLiteBackend(path string) (*SQLiteBackend, error) {
+	conn, err := sqlite.NewConnection(path)
+	if err != nil {
+		return nil, err
+	}
+	if err := conn.Open(); err != nil {
+		return nil, err
+	}
+	db, err := conn.DB()
+	if err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+
+	return &SQLiteBackend{
+		conn:                conn,
+		db:                  db,
+		workflowCheckpoints: infraStorage.NewWorkflowCheckpointRepository(db),
+		runNotes:            infraStorage.NewRunNoteRepository(db),
+		costLedger:          infraStorage.NewCostLedgerRepository(db),
+		auditLog:            infraStorage.NewAuditLogRepository(db),
+		metrics:             infraStorage.NewMetricsRepository(db),
+		sessions:            infraStorage.NewSessionRepository(db),
+		workspaces:          infraStorage.NewWorkspaceRepository(db),
+		checkpoints:         infraStorage.NewCheckpointRepository(db),
+		contextItems:        infraStorage.NewContextItemRepository(db),
+		rules:               infraStorage.NewRuleRepository(db),
+	}, nil
+}
+
+// Name returns "sqlite".
+func (b *SQLiteBackend) Name() string {
+	return DefaultBackend
+}
+
+// Migrate applies pending SQLite migrations.
+func (b *SQLiteBackend) Migrate(ctx context.Context) (int, error) {
+	return migrate.Apply(ctx, b.db, migrate.SQLite, sqlite.Migrations())
+}
+
+// MigrationStatus reports the SQLite migrations and whether each is applied.
+func (b *SQLiteBackend) MigrationStatus(ctx context.Context) ([]ports.MigrationStatus, error) {
+	return migrate.Status(ctx, b.db, sqlite.Migrations())
+}
+
+// Ping checks that the database file can be reached.
+func (b *SQLiteBackend) Ping(ctx context.Context) error {
+	return b.db.PingContext(ctx)
+}
+
+// WorkflowCheckpoints returns the workflow checkpoint repository.
+func (b *SQLiteBackend) WorkflowCheckpoints() ports.WorkflowCheckpointPort {
+	return b.workflowCheckpoints
+}
+
+// RunNotes returns the run note repository.
+func (b *SQLiteBackend) RunNotes() ports.RunNotePort {
+	return b.runNotes
+}
+
+// CostLedger returns the cost ledger repository.
+func (b *SQLiteBackend) CostLedger() ports.CostLedgerPort {
+	return b.costLedger
+}
+
+// AuditLog returns the audit log repository.
+func (b *SQLiteBackend) AuditLog() ports.AuditLogPort {
+	return b.auditLog
+}
+
+// Metrics returns the metrics repository.
+func (b *SQLiteBackend) Metrics() ports.MetricsStoragePort {
+	return b.metrics
+}
+
+// Cache returns a response cache tier stored in the database.
+func (b *SQLiteBackend) Cache(maxSize int64) ports.CachePort {
+	return cache.NewSQLiteCache(b.db, maxSize)
+}
+
+// Sessions returns the session repository.
+func (b *SQLiteBackend) Sessions() ports.SessionStateStoragePort {
+	return b.sessions
+}
+
+// Workspaces returns the workspace repository.
+func (b *SQLiteBackend) Workspaces() ports.WorkspaceStateStoragePort {
+	return b.workspaces
+}
+
+// Checkpoints returns the session checkpoint repository.
+func (b *SQLiteBackend) Checkpoints() ports.CheckpointStateStoragePort {
+	return b.checkpoints
+}
+
+// ContextItems returns the context item repository.
+func (b *SQLiteBackend) ContextItems() ports.ContextItemStoragePort {
+	return b.contextItems
+}
+
+// Rules returns the rule repository.
+func (b *SQLiteBackend) Rules() ports.RuleStoragePort {
+	return b.rules
+}
+
+// DB returns the underlying database.
+func (b *SQLiteBackend) DB() *sql.DB {
+	return b.db
+}
+
+// Path returns the database file path.
+func (b *SQLiteBackend) Path() string {
+	return b.conn.Path()
+}
+
+// Close closes the database.
+func (b *SQLiteBackend) Close() error {
+	return b.conn.Close()
+}
+
+// Ensure SQLiteBackend implements StorageBackendPort
+var _ ports.StorageBackendPort = (*SQLiteBackend)(nil)
@@ -0,0 +1,56 @@
+package storage
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jbctechsolutions/skillrunner/internal/domain/workflow"
+)
+
+func TestSQLiteBackend_AuditLogIsAppendOnly(t *testing.T) {
+	ctx := context.Background()
+	backend, err := NewSQLiteBackend(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("NewSQLiteBackend() error = %v", err)
+	}
+	defer backend.Close()
+
+	record := &workflow.AuditRecord{
+		ExecutionID: "exec-1",
+		PhaseID:     "analyze",
+		Provider:    "anthropic",
+		Model:       "claude",
+		Messages:    []workflow.AuditMessage{{Role: "user", Content: "review this"}},
+		MaxTokens:   512,
+		Temperature: 0.5,
+		Response:    "looks good",
+		RecordedAt:  time.Now(),
+	}
+	if err := backend.AuditLog().Append(ctx, record); err != nil {
+		t.Fatalf("Append() error = %v", err)
+	}
+
+	var messages string
+	if err := backend.db.QueryRowContext(ctx, "SELECT messages FROM audit_log WHERE execution_id = ?", "exec-1").Scan(&messages); err != nil {
+		t.Fatalf("record not stored: %v", err)
+	}
+	if messages != `[{"role":"user","content":"review this"}]` {
+		t.Errorf("messages = %s", messages)
+	}
+	records, err := backend.AuditLog().Records(ctx, "exec-1")
+	if err != nil {
+		t.Fatalf("Records() error = %v", err)
+	}
+	if len(records) != 1 || records[0].Response != "looks good" || records[0].MaxTokens != 512 || records[0].Temperature != 0.5 || records[0].Messages[0].Content != "review this" {
+		t.Errorf("Records() = %+v, want the appended record", records)
+	}
+
+	if _, err := backend.db.ExecContext(ctx, "UPDATE audit_log SET response = 'edited'"); err == nil {
+		t.Error("updating the audit log should fail")
+	}
+	if _, err := backend.db.ExecContext(ctx, "DELETE FROM audit_log"); err == nil {
+		t.Error("deleting from the audit log should fail")
+	}
+}
@@ -1,96 +1,51 @@
 package sqlite
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
-)
-
-// applyMigrations applies all database migrations in order.
-func applyMigrations(db *sql.DB) error {
-	// Enable foreign keys
-	if _, err := db.Exec("PRAGMA foreign_keys = ON"); err != nil {
-		return fmt.Errorf("could not enable foreign keys: %w", err)
-	}
 
-	// Create migrations table
-	if err := createMigrationsTable(db); err != nil {
-		return err
-	}
+	"github.com/jbctechsolutions/skillrunner/internal/infrastructure/storage/migrate"
+)
 
-	// Apply each migration
-	migrations := []struct {
-		version int
-		name    string
-		sql     string
-	}{
-		{1, "create_workspaces_table", createWorkspacesTable},
-		{2, "create_sessions_table", createSessionsTable},
-		{3, "create_checkpoints_table", createCheckpointsTable},
-		{4, "create_context_items_table", createContextItemsTable},
-		{5, "create_rules_table", createRulesTable},
-		{6, "create_drift_log_table", createDriftLogTable},
-		{7, "create_indices", createIndices},
-		{8, "create_response_cache_table", createResponseCacheTable},
-		{9, "create_cache_stats_table", createCacheStatsTable},
-		{10, "create_cache_indices", createCacheIndices},
+// Migrations returns the SQLite schema migrations.
+func Migrations() []migrate.Migration {
+	return []migrate.Migration{
+		{Version: 1, Name: "create_workspaces_table", SQL: createWorkspacesTable},
+		{Version: 2, Name: "create_sessions_table", SQL: createSessionsTable},
+		{Version: 3, Name: "create_checkpoints_table", SQL: createCheckpointsTable},
+		{Version: 4, Name: "create_context_items_table", SQL: createContextItemsTable},
+		{Version: 5, Name: "create_rules_table", SQL: createRulesTable},
+		{Version: 6, Name: "create_drift_log_table", SQL: createDriftLogTable},
+		{Version: 7, Name: "create_indices", SQL: createIndices},
+		{Version: 8, Name: "create_response_cache_table", SQL: createResponseCacheTable},
+		{Version: 9, Name: "create_cache_stats_table", SQL: createCacheStatsTable},
+		{Version: 10, Name: "create_cache_indices", SQL: createCacheIndices},
 		// Wave 11: Observability
-		{11, "create_execution_records_table", createExecutionRecordsTable},
-		{12, "create_phase_execution_records_table", createPhaseExecutionRecordsTable},
-		{13, "create_metrics_indices", createMetricsIndices},
+		{Version: 11, Name: "create_execution_records_table", SQL: createExecutionRecordsTable},
+		{Version: 12, Name: "create_phase_execution_records_table", SQL: createPhaseExecutionRecordsTable},
+		{Version: 13, Name: "create_metrics_indices", SQL: createMetricsIndices},
 		// Crash Recovery: Workflow checkpoints
-		{14, "create_workflow_checkpoints_table", createWorkflowCheckpointsTable},
-		{15, "create_workflow_checkpoint_indices", createWorkflowCheckpointIndices},
-	}
-
-	for _, m := range migrations {
-		applied, err := isMigrationApplied(db, m.version)
-		if err != nil {
-			return fmt.Errorf("could not check migration %d: %w", m.version, err)
-		}
-
-		if applied {
-			continue
-		}
-
-		// Apply migration
-		if _, err := db.Exec(m.sql); err != nil {
-			return fmt.Errorf("could not apply migration %d (%s): %w", m.version, m.name, err)
-		}
-
-		// Record migration
-		if err := recordMigration(db, m.version, m.name); err != nil {
-			return fmt.Errorf("could not record migration %d: %w", m.version, err)
-		}
+		{Version: 14, Name: "create_workflow_checkpoints_table", SQL: createWorkflowCheckpointsTable},
+		{Version: 15, Name: "create_workflow_checkpoint_indices", SQL: createWorkflowCheckpointIndices},
+		{Version: 16, Name: "create_run_notes_table", SQL: createRunNotesTable},
+		{Version: 17, Name: "add_workflow_checkpoint_pinned", SQL: addWorkflowCheckpointPinned},
+		{Version: 18, Name: "create_cost_ledger_table", SQL: createCostLedgerTable},
+		{Version: 19, Name: "add_cost_attribution_tags", SQL: addCostAttributionTags},
+		{Version: 20, Name: "create_audit_log_table", SQL: createAuditLogTable},
+		{Version: 21, Name: "add_audit_log_request_settings", SQL: addAuditLogRequestSettings},
+		{Version: 22, Name: "add_workflow_checkpoint_archive_path", SQL: addWorkflowCheckpointArchivePath},
 	}
-
-	return nil
 }
 
-// createMigrationsTable creates the migrations tracking table.
-func createMigrationsTable(db *sql.DB) error {
-	_, err := db.Exec(`
-		CREATE TABLE IF NOT EXISTS migrations (
-			version INTEGER PRIMARY KEY,
-			name TEXT NOT NULL,
-			applied_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
-		)
-	`)
-	return err
-}
-
-// isMigrationApplied checks if a migration has been applied.
-func isMigrationApplied(db *sql.DB, version int) (bool, error) {
-	var count int
-	err := db.QueryRow("SELECT COUNT(*) FROM migrations WHERE version = ?", version).Scan(&count)
-	if err != nil {
-		return false, err
+// applyMigrations applies all database migrations in order.
+func applyMigrations(db *sql.DB) error {
+	// Enable foreign keys
+	if _, err := db.Exec("PRAGMA foreign_keys = ON"); err != nil {
+		return fmt.Errorf("could not enable foreign keys: %w", err)
 	}
-	return count > 0, nil
-}
 
-// recordMigration records that a migration has been applied.
-func recordMigration(db *sql.DB, version int, name string) error {
-	_, err := db.Exec("INSERT INTO migrations (version, name) VALUES (?, ?)", version, name)
+	_, err := migrate.Apply(context.Background(), db, migrate.SQLite, Migrations())
 	return err
 }
 
@@ -343,3 +298,92 @@ CREATE INDEX IF NOT EXISTS idx_wf_checkpoint_machine ON workflow_checkpoints(mac
 CREATE INDEX IF NOT EXISTS idx_wf_checkpoint_updated ON workflow_checkpoints(updated_at);
 CREATE INDEX IF NOT EXISTS idx_wf_checkpoint_created ON workflow_checkpoints(created_at);
 `
+
+// Notes on past runs, keyed by workflow checkpoint ID
+const createRunNotesTable = `
+CREATE TABLE run_notes (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	run_id TEXT NOT NULL,
+	text TEXT NOT NULL,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS idx_run_notes_run ON run_notes(run_id);
+`
+
+// Golden runs: pinned checkpoints are kept by retention cleanup
+const addWorkflowCheckpointPinned = `
+ALTER TABLE workflow_checkpoints ADD COLUMN pinned BOOLEAN NOT NULL DEFAULT 0;
+`
+
+// Cost ledger: the cost of each model invocation, one entry per run phase
+const createCostLedgerTable = `
+CREATE TABLE cost_ledger (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	execution_id TEXT NOT NULL,
+	phase_id TEXT NOT NULL,
+	skill_id TEXT NOT NULL,
+	provider TEXT NOT NULL,
+	model TEXT NOT NULL,
+	input_tokens INTEGER DEFAULT 0,
+	output_tokens INTEGER DEFAULT 0,
+	input_cost REAL DEFAULT 0,
+	output_cost REAL DEFAULT 0,
+	total_cost REAL DEFAULT 0,
+	recorded_at TIMESTAMP NOT NULL,
+	UNIQUE (execution_id, phase_id)
+);
+CREATE INDEX IF NOT EXISTS idx_cost_ledger_recorded ON cost_ledger(recorded_at);
+`
+
+// Cost attribution tags (e.g. team=search) of runs, on their checkpoints and
+// on their cost ledger entries
+const addCostAttributionTags = `
+ALTER TABLE workflow_checkpoints ADD COLUMN tags TEXT;
+CREATE TABLE cost_ledger_tags (
+	execution_id TEXT NOT NULL,
+	name TEXT NOT NULL,
+	value TEXT NOT NULL,
+	PRIMARY KEY (execution_id, name)
+);
+CREATE INDEX IF NOT EXISTS idx_cost_ledger_tags_name ON cost_ledger_tags(name, value);
+`
+
+// Audit log: the full request and response of each provider call, append-only
+const createAuditLogTable = `
+CREATE TABLE audit_log (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	execution_id TEXT,
+	skill_id TEXT,
+	phase_id TEXT,
+	provider TEXT NOT NULL,
+	model TEXT NOT NULL,
+	system_prompt TEXT,
+	messages TEXT NOT NULL,
+	response TEXT,
+	error TEXT,
+	input_tokens INTEGER DEFAULT 0,
+	output_tokens INTEGER DEFAULT 0,
+	redactions INTEGER DEFAULT 0,
+	recorded_at TIMESTAMP NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_audit_log_execution ON audit_log(execution_id);
+CREATE INDEX IF NOT EXISTS idx_audit_log_recorded ON audit_log(recorded_at);
+CREATE TRIGGER audit_log_no_update BEFORE UPDATE ON audit_log
+BEGIN
+	SELECT RAISE(ABORT, 'audit_log is append-only');
+END;
+CREATE TRIGGER audit_log_no_delete BEFORE DELETE ON audit_log
+BEGIN
+	SELECT RAISE(ABORT, 'audit_log is append-only');
+END;
+`
+
+const addAuditLogRequestSettings = `
+ALTER TABLE audit_log ADD COLUMN max_tokens INTEGER DEFAULT 0;
+ALTER TABLE audit_log ADD COLUMN temperature REAL DEFAULT 0;
+`
+
+// Cold storage: the file holding the phase outputs of an archived checkpoint
+const addWorkflowCheckpointArchivePath = `
+ALTER TABLE workflow_checkpoints ADD COLUMN archive_path TEXT;
+`
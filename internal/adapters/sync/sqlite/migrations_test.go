@@ -30,8 +30,8 @@ func TestApplyMigrations(t *testing.T) {
 	if err != nil {
 		t.Fatalf("QueryRow() error = %v", err)
 	}
-	if count != 15 {
-		t.Errorf("migrations count = %d, want 15", count)
+	if count != 22 {
+		t.Errorf("migrations count = %d, want 22", count)
 	}
 }
 
@@ -47,14 +47,14 @@ func TestApplyMigrations_Idempotent(t *testing.T) {
 		t.Fatalf("second applyMigrations() error = %v", err)
 	}
 
-	// Verify migrations count is still 15 (not duplicated)
+	// Verify migrations count is still 22 (not duplicated)
 	var count int
 	err := db.QueryRow("SELECT COUNT(*) FROM migrations").Scan(&count)
 	if err != nil {
 		t.Fatalf("QueryRow() error = %v", err)
 	}
-	if count != 15 {
-		t.Errorf("migrations count = %d after idempotent run, want 15", count)
+	if count != 22 {
+		t.Errorf("migrations count = %d after idempotent run, want 22", count)
 	}
 }
 
@@ -446,36 +446,3 @@ func TestDefaultValues(t *testing.T) {
 		t.Error("default is_active = false, want true")
 	}
 }
-
-func TestIsMigrationApplied(t *testing.T) {
-	db := openTestDB(t)
-	defer db.Close()
-
-	// Create migrations table
-	if err := createMigrationsTable(db); err != nil {
-		t.Fatalf("createMigrationsTable() error = %v", err)
-	}
-
-	// Check migration not applied
-	applied, err := isMigrationApplied(db, 1)
-	if err != nil {
-		t.Fatalf("isMigrationApplied() error = %v", err)
-	}
-	if applied {
-		t.Error("isMigrationApplied() = true for non-existent migration")
-	}
-
-	// Record migration
-	if err := recordMigration(db, 1, "test_migration"); err != nil {
-		t.Fatalf("recordMigration() error = %v", err)
-	}
-
-	// Check migration applied
-	applied, err = isMigrationApplied(db, 1)
-	if err != nil {
-		t.Fatalf("isMigrationApplied() error = %v", err)
-	}
-	if !applied {
-		t.Error("isMigrationApplied() = false for applied migration")
-	}
-}
@@ -0,0 +1,231 @@
+package web
+
+import (
+	"regexp"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+
+	"github.com/jbctechsolutions/skillrunner/internal/adapters/converter"
+)
+
+// boilerplateElements never hold the main content of a page.
+var boilerplateElements = map[atom.Atom]bool{
+	atom.Nav:    true,
+	atom.Header: true,
+	atom.Footer: true,
+	atom.Aside:  true,
+	atom.Form:   true,
+	atom.Button: true,
+	atom.Dialog: true,
+}
+
+// boilerplateRoles are ARIA landmark roles of page chrome.
+var boilerplateRoles = map[string]bool{
+	"navigation":    true,
+	"banner":        true,
+	"contentinfo":   true,
+	"complementary": true,
+	"search":        true,
+	"dialog":        true,
+}
+
+// boilerplatePattern matches class and id names of page chrome.
+var boilerplatePattern = regexp.MustCompile(`(?i)(^|[\s_-])(nav|navbar|menu|footer|sidebar|comments?|share|social|ads?|advert\w*|cookies?|banner|promo|related|subscribe|newsletter|breadcrumbs?|popup|modal)($|[\s_-])`)
+
+// minParagraphChars is the length below which a paragraph does not count
+// towards its container's score.
+const minParagraphChars = 25
+
+// extractContent returns the title of a page and the element holding its
+// main content: the page's single <article> or <main> element if it has
+// one, otherwise the container whose paragraphs score highest after
+// boilerplate is removed.
+func extractContent(doc *html.Node) (string, *html.Node) {
+	title := pageTitle(doc)
+	removeBoilerplate(doc)
+
+	body := findFirst(doc, atom.Body)
+	if body == nil {
+		body = doc
+	}
+
+	if articles := findAll(body, func(n *html.Node) bool { return n.DataAtom == atom.Article }); len(articles) == 1 {
+		return title, articles[0]
+	}
+	if main := findAll(body, func(n *html.Node) bool {
+		return n.DataAtom == atom.Main || attr(n, "role") == "main"
+	}); len(main) == 1 {
+		return title, main[0]
+	}
+
+	if best := bestCandidate(body); best != nil {
+		return title, best
+	}
+	return title, body
+}
+
+// bestCandidate scores the containers of each paragraph by its length and
+// commas, giving grandparents half the score, and returns the container
+// with the highest score after discounting link-heavy text.
+func bestCandidate(root *html.Node) *html.Node {
+	scores := make(map[*html.Node]float64)
+	for _, p := range findAll(root, func(n *html.Node) bool {
+		return n.DataAtom == atom.P || n.DataAtom == atom.Pre || n.DataAtom == atom.Blockquote
+	}) {
+		text := strings.TrimSpace(nodeText(p))
+		if len(text) < minParagraphChars {
+			continue
+		}
+		score := 1 + float64(strings.Count(text, ",")) + min(float64(len(text))/100, 3)
+		if parent := p.Parent; parent != nil {
+			scores[parent] += score
+			if grandparent := parent.Parent; grandparent != nil {
+				scores[grandparent] += score / 2
+			}
+		}
+	}
+
+	var (
+		best      *html.Node
+		bestScore float64
+	)
+	for node, score := range scores {
+		score *= 1 - linkDensity(node)
+		if score > bestScore {
+			best, bestScore = node, score
+		}
+	}
+	return best
+}
+
+// removeBoilerplate detaches navigation, headers, footers, sidebars and
+// similar page chrome, as well as scripts and styles.
+func removeBoilerplate(n *html.Node) {
+	for child := n.FirstChild; child != nil; {
+		next := child.NextSibling
+		if isBoilerplate(child) {
+			n.RemoveChild(child)
+		} else {
+			removeBoilerplate(child)
+		}
+		child = next
+	}
+}
+
+// isBoilerplate reports whether n is page chrome rather than content.
+func isBoilerplate(n *html.Node) bool {
+	if n.Type == html.CommentNode {
+		return true
+	}
+	if n.Type != html.ElementNode {
+		return false
+	}
+	switch n.DataAtom {
+	case atom.Script, atom.Style, atom.Noscript, atom.Template, atom.Iframe, atom.Svg:
+		return true
+	case atom.Body, atom.Main, atom.Article:
+		return false
+	}
+	if boilerplateElements[n.DataAtom] || boilerplateRoles[attr(n, "role")] || attr(n, "aria-hidden") == "true" {
+		return true
+	}
+	return boilerplatePattern.MatchString(attr(n, "class")) || boilerplatePattern.MatchString(attr(n, "id"))
+}
+
+// pageTitle returns the page's og:title, <title> or first <h1>.
+func pageTitle(doc *html.Node) string {
+	for _, meta := range findAll(doc, func(n *html.Node) bool { return n.DataAtom == atom.Meta }) {
+		if attr(meta, "property") == "og:title" {
+			if title := strings.TrimSpace(attr(meta, "content")); title != "" {
+				return title
+			}
+		}
+	}
+	for _, a := range []atom.Atom{atom.Title, atom.H1} {
+		if n := findFirst(doc, a); n != nil {
+			if title := strings.Join(strings.Fields(nodeText(n)), " "); title != "" {
+				return title
+			}
+		}
+	}
+	return ""
+}
+
+// linkDensity returns the fraction of the text of n inside links.
+func linkDensity(n *html.Node) float64 {
+	total := len(nodeText(n))
+	if total == 0 {
+		return 0
+	}
+	linked := 0
+	for _, a := range findAll(n, func(n *html.Node) bool { return n.DataAtom == atom.A }) {
+		linked += len(nodeText(a))
+	}
+	return float64(linked) / float64(total)
+}
+
+// nodeText returns the concatenated text of n and its descendants.
+func nodeText(n *html.Node) string {
+	var sb strings.Builder
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.TextNode {
+			sb.WriteString(n.Data)
+		}
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			walk(child)
+		}
+	}
+	walk(n)
+	return sb.String()
+}
+
+// findFirst returns the first element of type a under n in document order.
+func findFirst(n *html.Node, a atom.Atom) *html.Node {
+	if found := findAll(n, func(n *html.Node) bool { return n.DataAtom == a }); len(found) > 0 {
+		return found[0]
+	}
+	return nil
+}
+
+// findAll returns the elements under n, in document order, that match.
+func findAll(n *html.Node, match func(*html.Node) bool) []*html.Node {
+	var found []*html.Node
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.ElementNode && match(n) {
+			found = append(found, n)
+		}
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			walk(child)
+		}
+	}
+	walk(n)
+	return found
+}
+
+// attr returns the value of the named attribute of n.
+func attr(n *html.Node, name string) string {
+	for _, a := range n.Attr {
+		if a.Key == name {
+			return a.Val
+		}
+	}
+	return ""
+}
+
+// renderPage returns the markdown of a page: its title as a heading, its
+// source URL and the text of its main content.
+func renderPage(title, url string, content *html.Node) string {
+	text := converter.HTMLNodeText(content)
+
+	var sb strings.Builder
+	if title != "" && !strings.HasPrefix(text, "# "+title) {
+		sb.WriteString("# " + title + "\n\n")
+	}
+	sb.WriteString("Source: " + url + "\n\n")
+	sb.WriteString(text)
+	return strings.TrimSpace(sb.String())
+}
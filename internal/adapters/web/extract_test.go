@@ -0,0 +1,63 @@
+package web
+
+import (
+	"strings"
+	"testing"
+
+	"golang.org/x/net/html"
+)
+
+func parseHTML(t *testing.T, page string) *html.Node {
+	t.Helper()
+	doc, err := html.Parse(strings.NewReader(page))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return doc
+}
+
+func TestExtractContent_ScoresParagraphs(t *testing.T) {
+	page := `<html><head><title>Gardening | Example Blog</title></head><body>
+<div class="site-menu"><a href="/">Home</a> <a href="/about">About</a></div>
+<div id="links"><p><a href="/a">A very long list of links that goes on</a> <a href="/b">and on and on forever</a></p></div>
+<div class="post">
+  <h2>Growing tomatoes</h2>
+  <p>Tomatoes need full sun, rich soil, and consistent watering to thrive in most climates.</p>
+  <p>Stake or cage the plants early, before the stems become heavy with fruit, to avoid damage.</p>
+</div>
+<div class="cookie-banner"><p>We use cookies to improve your experience on this website, okay?</p></div>
+</body></html>`
+
+	title, content := extractContent(parseHTML(t, page))
+	if title != "Gardening | Example Blog" {
+		t.Errorf("title = %q", title)
+	}
+
+	got := renderPage(title, "https://example.com/tomatoes", content)
+	for _, want := range []string{"# Gardening | Example Blog", "Source: https://example.com/tomatoes", "## Growing tomatoes", "Stake or cage"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("content missing %q:\n%s", want, got)
+		}
+	}
+	for _, unwanted := range []string{"Home", "cookies", "list of links"} {
+		if strings.Contains(got, unwanted) {
+			t.Errorf("content contains boilerplate %q:\n%s", unwanted, got)
+		}
+	}
+}
+
+func TestExtractContent_PrefersArticle(t *testing.T) {
+	page := `<html><head><meta property="og:title" content="Release 2.0"></head><body>
+<header><h1>Site</h1></header>
+<article><h1>Release 2.0</h1><p>Short.</p></article>
+<footer><p>Copyright, all rights reserved, and a long footer text here.</p></footer>
+</body></html>`
+
+	title, content := extractContent(parseHTML(t, page))
+	got := renderPage(title, "https://example.com", content)
+
+	want := "Source: https://example.com\n\n# Release 2.0\n\nShort."
+	if got != want {
+		t.Errorf("renderPage() = %q, want %q", got, want)
+	}
+}
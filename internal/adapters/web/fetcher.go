@@ -0,0 +1,257 @@
+// Package web provides a web page fetcher that respects robots.txt,
+// extracts the readable content of pages and caches the results.
+package web
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/net/html"
+
+	"github.com/jbctechsolutions/skillrunner/internal/application/ports"
+	"github.com/jbctechsolutions/skillrunner/internal/domain/errors"
+)
+
+// Fetcher defaults.
+const (
+	// DefaultTimeout bounds a page download, including redirects.
+	DefaultTimeout = 30 * time.Second
+
+	// DefaultCacheTTL is how long fetched pages are reused.
+	DefaultCacheTTL = 24 * time.Hour
+
+	// DefaultUserAgent identifies the fetcher to servers and robots.txt.
+	DefaultUserAgent = "skillrunner/1.0 (+https://github.com/jbctechsolutions/skillrunner)"
+
+	// robotsProduct is the product token matched against robots.txt groups.
+	robotsProduct = "skillrunner"
+
+	// maxPageSize bounds a downloaded page.
+	maxPageSize = 10 << 20
+)
+
+// Fetcher implements ports.WebFetcherPort over HTTP.
+type Fetcher struct {
+	client    *http.Client
+	userAgent string
+	cacheDir  string // Empty disables caching
+	cacheTTL  time.Duration
+
+	mu     sync.Mutex
+	robots map[string]*robotsRules // keyed by scheme://host
+}
+
+// Ensure Fetcher implements WebFetcherPort at compile time.
+var _ ports.WebFetcherPort = (*Fetcher)(nil)
+
+// Option configures a Fetcher.
+type Option func(*Fetcher)
+
+// WithTimeout sets the download timeout.
+func WithTimeout(timeout time.Duration) Option {
+	return func(f *Fetcher) {
+		f.client.Timeout = timeout
+	}
+}
+
+// WithCache caches pages as files in dir for ttl.
+func WithCache(dir string, ttl time.Duration) Option {
+	return func(f *Fetcher) {
+		f.cacheDir = dir
+		f.cacheTTL = ttl
+	}
+}
+
+// WithUserAgent sets the User-Agent header.
+func WithUserAgent(userAgent string) Option {
+	return func(f *Fetcher) {
+		f.userAgent = userAgent
+	}
+}
+
+// NewFetcher creates a fetcher without a cache unless WithCache is given.
+func NewFetcher(opts ...Option) *Fetcher {
+	f := &Fetcher{
+		client:    &http.Client{Timeout: DefaultTimeout},
+		userAgent: DefaultUserAgent,
+		cacheTTL:  DefaultCacheTTL,
+		robots:    make(map[string]*robotsRules),
+	}
+	for _, opt := range opts {
+		opt(f)
+	}
+	return f
+}
+
+// Fetch downloads an http(s) page, unless robots.txt disallows it, and
+// returns its main content. Cached pages younger than the cache TTL are
+// returned without a request.
+func (f *Fetcher) Fetch(ctx context.Context, rawURL string) (*ports.WebPage, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, errors.NewError(errors.CodeValidation, fmt.Sprintf("invalid URL %q: must be an http or https URL", rawURL), err)
+	}
+
+	if page := f.cached(rawURL); page != nil {
+		return page, nil
+	}
+
+	if !f.robotsAllowed(ctx, u) {
+		return nil, errors.NewError(errors.CodeValidation, fmt.Sprintf("robots.txt of %s disallows fetching %s", u.Host, u.Path), nil)
+	}
+
+	page, err := f.download(ctx, u)
+	if err != nil {
+		return nil, err
+	}
+	f.store(rawURL, page)
+	return page, nil
+}
+
+// download fetches a page and extracts its content.
+func (f *Fetcher) download(ctx context.Context, u *url.URL) (*ports.WebPage, error) {
+	resp, err := f.get(ctx, u.String())
+	if err != nil {
+		return nil, errors.NewError(errors.CodeProvider, fmt.Sprintf("failed to fetch %s", u), err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.NewError(errors.CodeProvider, fmt.Sprintf("failed to fetch %s: HTTP %d", u, resp.StatusCode), nil)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxPageSize+1))
+	if err != nil {
+		return nil, errors.NewError(errors.CodeProvider, fmt.Sprintf("failed to read %s", u), err)
+	}
+	if len(body) > maxPageSize {
+		return nil, errors.NewError(errors.CodeValidation, fmt.Sprintf("%s is larger than %d MB", u, maxPageSize>>20), nil)
+	}
+
+	page := &ports.WebPage{URL: resp.Request.URL.String(), FetchedAt: time.Now()}
+
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	switch {
+	case mediaType == "" || mediaType == "text/html" || mediaType == "application/xhtml+xml":
+		doc, err := html.Parse(bytes.NewReader(body))
+		if err != nil {
+			return nil, errors.NewError(errors.CodeValidation, fmt.Sprintf("failed to parse %s", u), err)
+		}
+		var content *html.Node
+		page.Title, content = extractContent(doc)
+		page.Markdown = renderPage(page.Title, page.URL, content)
+	case strings.HasPrefix(mediaType, "text/"):
+		page.Markdown = "Source: " + page.URL + "\n\n" + strings.TrimSpace(string(body))
+	default:
+		return nil, errors.NewError(errors.CodeValidation, fmt.Sprintf("unsupported content type %q at %s", mediaType, u), nil)
+	}
+
+	return page, nil
+}
+
+// robotsAllowed reports whether the robots.txt of u's host allows fetching
+// u. Rules are fetched once per host.
+func (f *Fetcher) robotsAllowed(ctx context.Context, u *url.URL) bool {
+	origin := u.Scheme + "://" + u.Host
+
+	f.mu.Lock()
+	rules, ok := f.robots[origin]
+	f.mu.Unlock()
+
+	if !ok {
+		rules = f.fetchRobots(ctx, origin)
+		f.mu.Lock()
+		f.robots[origin] = rules
+		f.mu.Unlock()
+	}
+
+	path := u.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	if u.RawQuery != "" {
+		path += "?" + u.RawQuery
+	}
+	return rules.allowed(path)
+}
+
+// fetchRobots downloads and parses the robots.txt of origin. A missing file
+// allows everything; an unreachable one disallows everything.
+func (f *Fetcher) fetchRobots(ctx context.Context, origin string) *robotsRules {
+	resp, err := f.get(ctx, origin+"/robots.txt")
+	if err != nil {
+		return disallowAll
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode >= 500:
+		return disallowAll
+	case resp.StatusCode != http.StatusOK:
+		return allowAll
+	}
+	return parseRobots(io.LimitReader(resp.Body, maxPageSize), robotsProduct)
+}
+
+// get sends a GET request with the fetcher's User-Agent.
+func (f *Fetcher) get(ctx context.Context, rawURL string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", f.userAgent)
+	req.Header.Set("Accept", "text/html,application/xhtml+xml,text/plain;q=0.9,*/*;q=0.1")
+	return f.client.Do(req)
+}
+
+// cachePath returns the cache file of a URL.
+func (f *Fetcher) cachePath(rawURL string) string {
+	sum := sha256.Sum256([]byte(rawURL))
+	return filepath.Join(f.cacheDir, hex.EncodeToString(sum[:])+".json")
+}
+
+// cached returns the cached page of a URL, or nil if there is none or it
+// has expired.
+func (f *Fetcher) cached(rawURL string) *ports.WebPage {
+	if f.cacheDir == "" {
+		return nil
+	}
+	data, err := os.ReadFile(f.cachePath(rawURL))
+	if err != nil {
+		return nil
+	}
+	var page ports.WebPage
+	if err := json.Unmarshal(data, &page); err != nil || time.Since(page.FetchedAt) > f.cacheTTL {
+		return nil
+	}
+	page.Cached = true
+	return &page
+}
+
+// store caches a page. Caching is best-effort; failures are ignored.
+func (f *Fetcher) store(rawURL string, page *ports.WebPage) {
+	if f.cacheDir == "" {
+		return
+	}
+	data, err := json.Marshal(page)
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(f.cacheDir, 0o700); err != nil {
+		return
+	}
+	_ = os.WriteFile(f.cachePath(rawURL), data, 0o600)
+}
@@ -0,0 +1,107 @@
+package web
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func newTestSite(t *testing.T, pageHits *atomic.Int32) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/robots.txt", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("User-agent: *\nDisallow: /private\n"))
+	})
+	mux.HandleFunc("/post", func(w http.ResponseWriter, r *http.Request) {
+		pageHits.Add(1)
+		if !strings.HasPrefix(r.UserAgent(), "skillrunner/") {
+			t.Errorf("User-Agent = %q", r.UserAgent())
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_, _ = w.Write([]byte(`<html><head><title>Post</title></head><body><nav>Menu</nav><article><p>Body text.</p></article></body></html>`))
+	})
+	mux.HandleFunc("/notes.txt", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		_, _ = w.Write([]byte("plain notes\n"))
+	})
+	mux.HandleFunc("/image.png", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "image/png")
+		_, _ = w.Write([]byte{0x89, 'P', 'N', 'G'})
+	})
+	mux.HandleFunc("/private/page", func(w http.ResponseWriter, r *http.Request) {
+		t.Error("fetched a page disallowed by robots.txt")
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestFetcher_Fetch(t *testing.T) {
+	var hits atomic.Int32
+	server := newTestSite(t, &hits)
+	fetcher := NewFetcher(WithCache(t.TempDir(), time.Hour))
+	ctx := context.Background()
+
+	page, err := fetcher.Fetch(ctx, server.URL+"/post")
+	if err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+	if page.Title != "Post" || page.Cached || page.Markdown != "# Post\n\nSource: "+server.URL+"/post\n\nBody text." {
+		t.Errorf("page = %+v", page)
+	}
+
+	// The second fetch is served from the cache
+	page, err = fetcher.Fetch(ctx, server.URL+"/post")
+	if err != nil || !page.Cached || hits.Load() != 1 {
+		t.Errorf("cached fetch = %+v, %v (hits %d)", page, err, hits.Load())
+	}
+
+	if page, err := fetcher.Fetch(ctx, server.URL+"/notes.txt"); err != nil || !strings.HasSuffix(page.Markdown, "plain notes") {
+		t.Errorf("text page = %+v, %v", page, err)
+	}
+}
+
+func TestFetcher_Fetch_Errors(t *testing.T) {
+	var hits atomic.Int32
+	server := newTestSite(t, &hits)
+	fetcher := NewFetcher()
+	ctx := context.Background()
+
+	tests := []struct {
+		name    string
+		url     string
+		wantErr string
+	}{
+		{"robots disallowed", server.URL + "/private/page", "robots.txt"},
+		{"not found", server.URL + "/missing", "HTTP 404"},
+		{"unsupported type", server.URL + "/image.png", "unsupported content type"},
+		{"bad scheme", "file:///etc/passwd", "invalid URL"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := fetcher.Fetch(ctx, tt.url)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Fetch() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestFetcher_Timeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/robots.txt" {
+			time.Sleep(200 * time.Millisecond)
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	if _, err := NewFetcher(WithTimeout(50*time.Millisecond)).Fetch(context.Background(), server.URL+"/slow"); err == nil {
+		t.Error("expected a timeout error")
+	}
+}
@@ -0,0 +1,115 @@
+package web
+
+import (
+	"bufio"
+	"io"
+	"regexp"
+	"strings"
+)
+
+// robotsRules are the Allow and Disallow rules of the robots.txt group that
+// applies to the fetcher.
+type robotsRules struct {
+	allow    []string
+	disallow []string
+}
+
+// allowAll and disallowAll are the rules used when robots.txt is missing or
+// unreachable (RFC 9309 section 2.3.1).
+var (
+	allowAll    = &robotsRules{}
+	disallowAll = &robotsRules{disallow: []string{"/"}}
+)
+
+// parseRobots returns the rules of the group for product, or of the "*"
+// group if no group names it.
+func parseRobots(r io.Reader, product string) *robotsRules {
+	product = strings.ToLower(product)
+
+	var (
+		named, wildcard *robotsRules
+		current         []*robotsRules // Groups the current rules belong to
+		inAgents        bool           // Reading a run of user-agent lines
+	)
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = strings.TrimSpace(value)
+
+		switch key {
+		case "user-agent":
+			if !inAgents {
+				current = nil
+				inAgents = true
+			}
+			agent := strings.ToLower(value)
+			switch {
+			case agent == "*":
+				if wildcard == nil {
+					wildcard = &robotsRules{}
+				}
+				current = append(current, wildcard)
+			case strings.Contains(product, agent):
+				if named == nil {
+					named = &robotsRules{}
+				}
+				current = append(current, named)
+			}
+		case "allow", "disallow":
+			inAgents = false
+			if value == "" {
+				continue
+			}
+			for _, rules := range current {
+				if key == "allow" {
+					rules.allow = append(rules.allow, value)
+				} else {
+					rules.disallow = append(rules.disallow, value)
+				}
+			}
+		default:
+			inAgents = false
+		}
+	}
+
+	switch {
+	case named != nil:
+		return named
+	case wildcard != nil:
+		return wildcard
+	default:
+		return allowAll
+	}
+}
+
+// allowed reports whether path may be fetched. The longest matching rule
+// wins and Allow wins ties.
+func (r *robotsRules) allowed(path string) bool {
+	longest := func(patterns []string) int {
+		best := -1
+		for _, pattern := range patterns {
+			if len(pattern) > best && matchRobotsPattern(pattern, path) {
+				best = len(pattern)
+			}
+		}
+		return best
+	}
+	return longest(r.allow) >= longest(r.disallow)
+}
+
+// matchRobotsPattern matches a robots.txt path pattern, where "*" matches
+// any sequence and a trailing "$" anchors the end.
+func matchRobotsPattern(pattern, path string) bool {
+	expr := "^" + strings.ReplaceAll(regexp.QuoteMeta(strings.TrimSuffix(pattern, "$")), `\*`, ".*")
+	if strings.HasSuffix(pattern, "$") {
+		expr += "$"
+	}
+	matched, err := regexp.MatchString(expr, path)
+	return err == nil && matched
+}
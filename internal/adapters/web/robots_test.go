@@ -0,0 +1,55 @@
+package web
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseRobots(t *testing.T) {
+	robots := `
+# Everyone
+User-agent: *
+Disallow: /private/
+Allow: /private/press/
+
+User-agent: GPTBot
+User-agent: skillrunner
+Disallow: /drafts
+Disallow: /*.pdf$
+Allow: /drafts/public
+`
+
+	tests := []struct {
+		product string
+		path    string
+		want    bool
+	}{
+		{"skillrunner", "/drafts/2024", false},
+		{"skillrunner", "/drafts/public/a", true},
+		{"skillrunner", "/docs/guide.pdf", false},
+		{"skillrunner", "/docs/guide.pdf?x=1", true},
+		{"skillrunner", "/private/notes", true}, // Only the named group applies
+		{"otherbot", "/private/notes", false},
+		{"otherbot", "/private/press/release", true},
+		{"otherbot", "/drafts/2024", true},
+	}
+
+	for _, tt := range tests {
+		rules := parseRobots(strings.NewReader(robots), tt.product)
+		if got := rules.allowed(tt.path); got != tt.want {
+			t.Errorf("%s allowed(%q) = %v, want %v", tt.product, tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestParseRobots_NoRules(t *testing.T) {
+	if !parseRobots(strings.NewReader("Sitemap: /sitemap.xml\n"), "skillrunner").allowed("/anything") {
+		t.Error("robots.txt without groups should allow everything")
+	}
+	if !parseRobots(strings.NewReader("User-agent: *\nDisallow:\n"), "skillrunner").allowed("/anything") {
+		t.Error("an empty Disallow should allow everything")
+	}
+	if disallowAll.allowed("/") || !allowAll.allowed("/") {
+		t.Error("unexpected default rules")
+	}
+}
@@ -4,6 +4,7 @@ package chat
 import (
 	"context"
 	"fmt"
+	"time"
 
 	adapterProvider "github.com/jbctechsolutions/skillrunner/internal/adapters/provider"
 	"github.com/jbctechsolutions/skillrunner/internal/application/ports"
@@ -91,9 +92,15 @@ func (s *Service) Ask(ctx context.Context, req *AskRequest) (*AskResponse, error
 
 	// Build the completion request
 	completionReq := s.buildCompletionRequest(req, modelID)
+	selection := &appProvider.ModelSelection{ModelID: modelID, ProviderName: providerName}
+	if err := s.router.CheckContextWindow(ctx, selection, completionReq); err != nil {
+		return nil, err
+	}
 
 	// Execute the completion
+	start := time.Now()
 	response, err := provider.Complete(ctx, completionReq)
+	s.router.RecordLatency(selection, time.Since(start), err)
 	if err != nil {
 		return nil, fmt.Errorf("completion failed: %w", err)
 	}
@@ -182,11 +189,17 @@ func (s *Service) Stream(ctx context.Context, req *StreamRequest) (*AskResponse,
 
 	// Build the completion request
 	completionReq := s.buildCompletionRequest(&req.AskRequest, modelID)
+	selection := &appProvider.ModelSelection{ModelID: modelID, ProviderName: providerName}
+	if err := s.router.CheckContextWindow(ctx, selection, completionReq); err != nil {
+		return nil, err
+	}
 
 	// Execute streaming
+	start := time.Now()
 	response, err := provider.Stream(ctx, completionReq, func(chunk string) error {
 		return req.Callback(chunk)
 	})
+	s.router.RecordLatency(selection, time.Since(start), err)
 	if err != nil {
 		return nil, fmt.Errorf("streaming failed: %w", err)
 	}
@@ -211,16 +224,8 @@ func (s *Service) validateAskRequest(req *AskRequest) error {
 		req.Profile = skill.ProfileBalanced // Default to balanced
 	}
 
-	// Validate profile
-	validProfiles := []string{skill.ProfileCheap, skill.ProfileBalanced, skill.ProfilePremium}
-	isValid := false
-	for _, valid := range validProfiles {
-		if req.Profile == valid {
-			isValid = true
-			break
-		}
-	}
-	if !isValid {
+	// Validate profile; the router checks that custom profiles are defined
+	if !skill.IsValidProfileName(req.Profile) {
 		return fmt.Errorf("invalid profile: %s", req.Profile)
 	}
 
@@ -2,17 +2,25 @@
 package application
 
 import (
+	"cmp"
 	"context"
 	"database/sql"
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
+	"sync"
 
 	"github.com/jbctechsolutions/skillrunner/internal/adapters/backend"
 	"github.com/jbctechsolutions/skillrunner/internal/adapters/cache"
+	"github.com/jbctechsolutions/skillrunner/internal/adapters/converter"
 	adapterMCP "github.com/jbctechsolutions/skillrunner/internal/adapters/mcp"
+	"github.com/jbctechsolutions/skillrunner/internal/adapters/objectstore"
 	adapterProvider "github.com/jbctechsolutions/skillrunner/internal/adapters/provider"
-	"github.com/jbctechsolutions/skillrunner/internal/adapters/sync/sqlite"
+	"github.com/jbctechsolutions/skillrunner/internal/adapters/provider/openaicompat"
+	"github.com/jbctechsolutions/skillrunner/internal/adapters/provider/whispercpp"
+	"github.com/jbctechsolutions/skillrunner/internal/adapters/storage"
+	"github.com/jbctechsolutions/skillrunner/internal/adapters/web"
 	"github.com/jbctechsolutions/skillrunner/internal/application/observability"
 	"github.com/jbctechsolutions/skillrunner/internal/application/ports"
 	appProvider "github.com/jbctechsolutions/skillrunner/internal/application/provider"
@@ -23,8 +31,9 @@ import (
 	domainSession "github.com/jbctechsolutions/skillrunner/internal/domain/session"
 	"github.com/jbctechsolutions/skillrunner/internal/infrastructure/config"
 	"github.com/jbctechsolutions/skillrunner/internal/infrastructure/logging"
+	"github.com/jbctechsolutions/skillrunner/internal/infrastructure/secrets"
 	"github.com/jbctechsolutions/skillrunner/internal/infrastructure/skills"
-	"github.com/jbctechsolutions/skillrunner/internal/infrastructure/storage"
+	infraStorage "github.com/jbctechsolutions/skillrunner/internal/infrastructure/storage"
 	"github.com/jbctechsolutions/skillrunner/internal/infrastructure/tracing"
 )
 
@@ -33,18 +42,29 @@ import (
 // and ensures proper initialization order.
 type Container struct {
 	// Configuration
-	config  *config.Config
-	verbose bool // Override log level to info when true
-
-	// Database connection
-	dbConn *sqlite.Connection
-	db     *sql.DB
+	config        *config.Config
+	routingChain  config.RoutingChain          // Where routing configuration is looked up
+	routingConfig *config.RoutingConfiguration // The routing chain merged, if any layer is present
+	project       *config.ProjectConfig        // .skillrunner.yaml, if present
+	projectErr    error                        // Why .skillrunner.yaml could not be loaded
+	routingErr    error                        // Why the routing chain could not be loaded
+	routingMu     sync.RWMutex                 // Guards routingConfig and project during hot reload
+	policy        *config.Policy               // Organization policy, if one is configured
+	policyStatus  *config.PolicyStatus         // Where the policy came from
+	pricing       *config.PricingCatalog       // Synced model prices, if any; guarded by routingMu
+	verbose       bool                         // Override log level to info when true
+
+	// Storage backend (sqlite unless configured otherwise)
+	storage ports.StorageBackendPort
 
 	// Repositories
 	sessionRepo            ports.SessionStateStoragePort
 	workspaceRepo          ports.WorkspaceStateStoragePort
 	checkpointRepo         ports.CheckpointStateStoragePort
 	workflowCheckpointRepo ports.WorkflowCheckpointPort
+	runNoteRepo            ports.RunNotePort
+	costLedgerRepo         ports.CostLedgerPort
+	auditLog               ports.AuditLogPort
 	contextRepo            ports.ContextItemStoragePort
 	rulesRepo              ports.RuleStoragePort
 
@@ -55,18 +75,22 @@ type Container struct {
 	skillLoader       *skills.Loader
 	skillRegistry     *appSkills.Registry
 	skillWatchService *appSkills.WatchService
+	concurrencyGroups *workflow.ConcurrencyGroups
 
 	// Registries
 	providerRegistry    *adapterProvider.Registry
 	providerInitializer *appProvider.Initializer
 	backendRegistry     *backend.Registry
+	converterRegistry   *converter.Registry
+	webFetcher          *web.Fetcher
+	secretStore         ports.SecretStorePort
 
 	// MCP (Model Context Protocol)
 	mcpRegistry *adapterMCP.Registry
 
 	// Wave 10: Cache
 	memoryCache    *cache.MemoryCache
-	sqliteCache    *cache.SQLiteCache
+	diskCache      ports.CachePort
 	compositeCache *cache.CompositeCache
 	responseCache  *cache.ResponseCache
 
@@ -96,13 +120,16 @@ func NewContainer(cfg *config.Config, verbose bool) (*Container, error) {
 	// Generate or retrieve machine ID
 	c.machineID = getMachineID()
 
-	// Initialize database connection
-	if err := c.initDatabase(); err != nil {
-		return nil, fmt.Errorf("failed to initialize database: %w", err)
+	// Initialize storage backend
+	if err := c.initStorage(); err != nil {
+		return nil, fmt.Errorf("failed to initialize storage: %w", err)
 	}
 
 	// Initialize repositories
-	c.initRepositories()
+	if err := c.initRepositories(); err != nil {
+		_ = c.Close() // Clean up on error
+		return nil, fmt.Errorf("failed to initialize repositories: %w", err)
+	}
 
 	// Initialize registries (includes provider encryption setup)
 	if err := c.initRegistries(); err != nil {
@@ -131,43 +158,61 @@ func NewContainer(cfg *config.Config, verbose bool) (*Container, error) {
 	return c, nil
 }
 
-// initDatabase initializes the SQLite database connection.
-func (c *Container) initDatabase() error {
-	// Use default path: ~/.skillrunner/skillrunner.db
-	conn, err := sqlite.NewConnection("")
-	if err != nil {
-		return fmt.Errorf("failed to create database connection: %w", err)
-	}
-
-	if err := conn.Open(); err != nil {
-		return fmt.Errorf("failed to open database: %w", err)
-	}
-
-	db, err := conn.DB()
+// initStorage opens the configured storage backend and migrates its schema.
+// The sqlite backend defaults to ~/.skillrunner/skillrunner.db.
+func (c *Container) initStorage() error {
+	backend, err := storage.NewDefaultRegistry().Open(context.Background(), c.config.Storage.Backend, storage.Options{
+		DSN:             c.config.Storage.DSN,
+		MaxOpenConns:    c.config.Storage.MaxOpenConns,
+		MaxIdleConns:    c.config.Storage.MaxIdleConns,
+		ConnMaxLifetime: c.config.Storage.ConnMaxLifetime,
+	})
 	if err != nil {
-		_ = conn.Close()
-		return fmt.Errorf("failed to get database handle: %w", err)
+		return err
 	}
 
-	c.dbConn = conn
-	c.db = db
+	c.storage = backend
 	return nil
 }
 
-// initRepositories initializes all storage repositories.
-func (c *Container) initRepositories() {
-	c.sessionRepo = storage.NewSessionRepository(c.db)
-	c.workspaceRepo = storage.NewWorkspaceRepository(c.db)
-	c.checkpointRepo = storage.NewCheckpointRepository(c.db)
-	c.workflowCheckpointRepo = storage.NewWorkflowCheckpointRepository(c.db)
-	c.contextRepo = storage.NewContextItemRepository(c.db)
-	c.rulesRepo = storage.NewRuleRepository(c.db)
+// initRepositories initializes all storage repositories. Workflow
+// checkpoints are kept in an object store instead if one is configured.
+func (c *Container) initRepositories() error {
+	c.sessionRepo = c.storage.Sessions()
+	c.workspaceRepo = c.storage.Workspaces()
+	c.checkpointRepo = c.storage.Checkpoints()
+	c.workflowCheckpointRepo = c.storage.WorkflowCheckpoints()
+	c.runNoteRepo = c.storage.RunNotes()
+	c.costLedgerRepo = c.storage.CostLedger()
+	c.contextRepo = c.storage.ContextItems()
+	c.rulesRepo = c.storage.Rules()
+
+	if store := c.config.Storage.Checkpoints; store.Backend != "" {
+		accessKey, secretKey, sessionToken := store.Credentials()
+		client, err := objectstore.NewClient(objectstore.Options{
+			Provider:     store.Backend,
+			Bucket:       store.Bucket,
+			Region:       store.Region,
+			Endpoint:     store.Endpoint,
+			AccessKey:    accessKey,
+			SecretKey:    secretKey,
+			SessionToken: sessionToken,
+		})
+		if err != nil {
+			return fmt.Errorf("checkpoint store: %w", err)
+		}
+		c.workflowCheckpointRepo = objectstore.NewCheckpointRepository(client, store.Prefix)
+	}
+	return nil
 }
 
 // initRegistries initializes the provider and backend registries.
 func (c *Container) initRegistries() error {
 	c.providerRegistry = adapterProvider.NewRegistry()
 	c.backendRegistry = backend.NewRegistry()
+	c.converterRegistry = converter.NewDefaultRegistry()
+	c.webFetcher = newWebFetcher()
+	c.secretStore = secrets.NewKeychain(secrets.DefaultService)
 
 	// Initialize provider initializer with encryption support
 	var err error
@@ -175,6 +220,17 @@ func (c *Container) initRegistries() error {
 	if err != nil {
 		return fmt.Errorf("failed to create provider initializer: %w", err)
 	}
+	c.providerInitializer.SetSecretStore(c.secretStore)
+
+	// Load routing first: its proxy and TLS settings apply to every provider
+	c.project, c.projectErr = loadProjectFile()
+	c.routingChain = discoverRoutingChain(c.config)
+	c.routingConfig, c.routingErr = loadRoutingFiles(c.routingChain)
+	c.providerInitializer.SetRoutingConfig(c.routingConfig)
+
+	// A missing or unreadable pricing catalog leaves the configured costs as they are
+	c.pricing, _ = config.LoadPricingCatalog()
+	c.pricing.Apply(c.routingConfig)
 
 	// Register providers from config
 	if err := c.providerInitializer.InitFromConfig(c.config); err != nil {
@@ -183,9 +239,115 @@ func (c *Container) initRegistries() error {
 		_ = err
 	}
 
+	// Register generic providers declared in routing.yaml and .skillrunner.yaml
+	if err := c.providerInitializer.InitFromRoutingConfig(c.routingConfig); err != nil {
+		// Same policy as above: a misconfigured gateway must not block startup
+		_ = err
+	}
+	c.providerInitializer.ApplyRateLimits(c.routingConfig)
+	c.providerInitializer.ApplyTimeouts()
+
+	if err := c.providerInitializer.ApplyModeration(c.routingConfig); err != nil {
+		return fmt.Errorf("moderation: %w", err)
+	}
+	if err := c.initPolicy(); err != nil {
+		return err
+	}
+	if err := c.providerInitializer.ApplyGuardrails(c.routingConfig); err != nil {
+		return fmt.Errorf("guardrails: %w", err)
+	}
+	return c.initAudit()
+}
+
+// initAudit opens the configured audit log and, if audit.enabled is set,
+// wraps every provider so its requests and responses are appended to it.
+func (c *Container) initAudit() error {
+	cfg := c.config.Audit
+	switch cfg.StoreName() {
+	case config.AuditStoreDatabase:
+		c.auditLog = c.storage.AuditLog()
+	default:
+		path, err := expandHome(cfg.FilePath())
+		if err != nil {
+			return fmt.Errorf("audit log: %w", err)
+		}
+		c.auditLog = infraStorage.NewJSONLAuditLog(path)
+	}
+
+	if !cfg.Enabled {
+		return nil
+	}
+	if err := c.providerInitializer.ApplyAudit(c.auditLog, c.policy); err != nil {
+		return fmt.Errorf("audit log: %w", err)
+	}
+	return nil
+}
+
+// initPolicy loads the organization policy, if one is configured, and
+// enforces it on the registered providers. A configured policy that can be
+// neither fetched nor read from the cache fails startup.
+func (c *Container) initPolicy() error {
+	policyCfg, err := config.ResolvePolicyConfig(c.config)
+	if err != nil {
+		return fmt.Errorf("organization policy: %w", err)
+	}
+	c.policy, c.policyStatus, err = config.LoadPolicy(context.Background(), policyCfg)
+	if err != nil {
+		return fmt.Errorf("organization policy: %w", err)
+	}
+
+	denied, err := c.providerInitializer.ApplyPolicy(c.policy)
+	if err != nil {
+		return fmt.Errorf("organization policy: %w", err)
+	}
+	if c.policyStatus != nil {
+		c.policyStatus.DeniedProviders = denied
+	}
 	return nil
 }
 
+// loadProjectFile loads the .skillrunner.yaml nearest the working directory.
+// Returns nil without error if there is none.
+func loadProjectFile() (*config.ProjectConfig, error) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return nil, nil
+	}
+
+	path := config.FindProjectConfig(cwd)
+	if path == "" {
+		return nil, nil
+	}
+
+	project, err := config.LoadProjectConfig(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load %s: %w", path, err)
+	}
+	return project, nil
+}
+
+// discoverRoutingChain locates the routing layers for the working directory.
+func discoverRoutingChain(cfg *config.Config) config.RoutingChain {
+	cwd, err := os.Getwd()
+	if err != nil {
+		cwd = ""
+	}
+	return config.DiscoverRoutingChain(cwd, cfg)
+}
+
+// loadRoutingFiles loads and merges the layers of the routing discovery
+// chain: shared sources, the system, user and project routing files, SR_
+// environment variables and --routing files.
+// Returns nil if none is present, and the error if a layer cannot be
+// loaded or the result is invalid.
+func loadRoutingFiles(chain config.RoutingChain) (*config.RoutingConfiguration, error) {
+	layers, err := chain.Layers()
+	if err != nil || len(layers) == 0 {
+		return nil, err
+	}
+	return config.MergeRoutingLayers(layers...)
+}
+
 // initMCP initializes the MCP (Model Context Protocol) subsystem.
 func (c *Container) initMCP() error {
 	manager := adapterMCP.NewServerManager()
@@ -217,7 +379,9 @@ func (c *Container) initServices() error {
 
 	// Create workflow executors with a composite provider
 	// For now, we use a placeholder that will be replaced when providers are configured
+	c.concurrencyGroups = workflow.NewConcurrencyGroups(c.config.Skills.ConcurrencyGroups)
 	executorConfig := workflow.DefaultExecutorConfig()
+	executorConfig.ConcurrencyGroups = c.concurrencyGroups
 	c.workflowExecutor = workflow.NewExecutor(nil, executorConfig)
 	c.streamingExecutor = workflow.NewStreamingExecutor(nil, executorConfig)
 
@@ -227,7 +391,12 @@ func (c *Container) initServices() error {
 	// Create skill registry and load skills
 	c.skillRegistry = appSkills.NewRegistry(c.skillLoader)
 
-	// Load all skills (built-in and user)
+	c.skillRegistry.SetProjectDir(c.projectSkillsDir())
+	if c.policy != nil {
+		c.skillRegistry.SetPolicyDirs(c.policy.RequiredSkillSources)
+	}
+
+	// Load all skills (built-in, user and project)
 	if err := c.skillRegistry.LoadAll(); err != nil {
 		// Log warning but don't fail - skills are optional
 		// The registry will still work, just with fewer skills
@@ -257,16 +426,9 @@ func (c *Container) initSkillWatcher() error {
 		userDir = filepath.Join(homeDir, ".skillrunner", "skills")
 	}
 
-	// Get current working directory for project skills
-	cwd, err := os.Getwd()
-	if err != nil {
-		return fmt.Errorf("failed to get working directory: %w", err)
-	}
-	projectDir := filepath.Join(cwd, ".skillrunner", "skills")
-
 	cfg := appSkills.WatchServiceConfig{
 		UserDir:          userDir,
-		ProjectDir:       projectDir,
+		ProjectDir:       c.skillRegistry.ProjectDir(),
 		DebounceDuration: c.config.Skills.DebounceDuration,
 		OnReload: func(event appSkills.SkillReloadEvent) {
 			if event.Error != nil {
@@ -293,43 +455,72 @@ func (c *Container) initSkillWatcher() error {
 	return nil
 }
 
+// projectSkillsDir returns the project skills directory: the one configured in
+// .skillrunner.yaml, or .skillrunner/skills in the working directory.
+func (c *Container) projectSkillsDir() string {
+	if c.project != nil {
+		return c.project.SkillsDir()
+	}
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(cwd, ".skillrunner", "skills")
+}
+
 // initCache initializes the caching subsystem.
 func (c *Container) initCache() {
 	// Create memory cache (L1 - fast, limited size)
 	c.memoryCache = cache.NewMemoryCache(c.config.Cache.MaxMemorySize, c.config.Cache.CleanupPeriod)
 
-	// Create SQLite cache (L2 - persistent, larger capacity)
-	c.sqliteCache = cache.NewSQLiteCache(c.db, c.config.Cache.MaxDiskSize)
+	// Create disk cache in the storage backend (L2 - persistent, larger capacity)
+	c.diskCache = c.storage.Cache(c.config.Cache.MaxDiskSize)
 
 	// Create composite cache (combines L1 and L2)
-	c.compositeCache = cache.NewCompositeCache(c.memoryCache, c.sqliteCache)
+	c.compositeCache = cache.NewCompositeCache(c.memoryCache, c.diskCache)
 
 	// Create response cache (LLM-specific caching layer)
 	c.responseCache = cache.NewResponseCache(c.compositeCache, c.config.Cache.DefaultTTL)
 }
 
+// runLogDir returns the directory of per-run log files: logging.dir, with
+// a leading ~ expanded, or ~/.skillrunner/logs. It returns "" when neither
+// can be resolved, which disables run logs.
+func (c *Container) runLogDir() string {
+	dir := c.config.Logging.Dir
+	if dir == "" {
+		dir, _ = logging.DefaultRunLogDir()
+		return dir
+	}
+	dir, err := expandHome(dir)
+	if err != nil {
+		return ""
+	}
+	return dir
+}
+
+// expandHome expands a leading ~/ in path to the home directory.
+func expandHome(path string) (string, error) {
+	rest, ok := strings.CutPrefix(path, "~/")
+	if !ok {
+		return path, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, rest), nil
+}
+
 // initObservability initializes the observability subsystem (logging, tracing, metrics).
 func (c *Container) initObservability() error {
 	ctx := context.Background()
 
-	// Initialize logger
-	logLevel := logging.LevelInfo // default
-
-	// Check verbose flag first - overrides config
-	if c.verbose {
+	// Initialize logger; verbose output also shows the logs of runs
+	logLevel := logging.Level(cmp.Or(c.config.Logging.Level, config.DefaultLogLevel))
+	if c.verbose && (logLevel == logging.LevelWarn || logLevel == logging.LevelError) {
 		logLevel = logging.LevelInfo
-	} else {
-		// Use config level if not verbose
-		switch c.config.Logging.Level {
-		case "debug":
-			logLevel = logging.LevelDebug
-		case "info":
-			logLevel = logging.LevelInfo
-		case "warn":
-			logLevel = logging.LevelWarn
-		case "error":
-			logLevel = logging.LevelError
-		}
 	}
 
 	logFormat := logging.FormatText
@@ -338,10 +529,13 @@ func (c *Container) initObservability() error {
 	}
 
 	logCfg := logging.Config{
-		Level:  logLevel,
-		Format: logFormat,
+		Level:     logLevel,
+		Format:    logFormat,
+		RunLogDir: c.runLogDir(),
+		EchoRuns:  c.verbose,
 	}
 	c.logger = logging.New(logCfg)
+	logging.SetDefault(c.logger)
 
 	// Initialize tracer if enabled
 	if c.config.Observability.Tracing.Enabled {
@@ -349,6 +543,7 @@ func (c *Container) initObservability() error {
 			Enabled:      true,
 			ExporterType: tracing.ExporterType(c.config.Observability.Tracing.ExporterType),
 			OTLPEndpoint: c.config.Observability.Tracing.OTLPEndpoint,
+			OTLPHeaders:  c.config.Observability.Tracing.OTLPHeaders,
 			ServiceName:  c.config.Observability.Tracing.ServiceName,
 			Environment:  "production",
 			SampleRate:   c.config.Observability.Tracing.SampleRate,
@@ -365,12 +560,21 @@ func (c *Container) initObservability() error {
 
 	// Initialize metrics repository if enabled
 	if c.config.Observability.Metrics.Enabled {
-		c.metricsRepo = storage.NewMetricsRepository(c.db)
+		c.metricsRepo = c.storage.Metrics()
 	}
 
 	// Initialize cost calculator with default model pricing
 	c.costCalculator = provider.NewCostCalculator()
 	provider.PopulateCostCalculator(c.costCalculator)
+	c.pricing.Register(c.costCalculator)
+	if c.config.Pricing.AutoSync && config.PricingStale(c.pricing, c.config.Pricing.MaxAge) {
+		go c.refreshPricing()
+	}
+
+	// Trace every provider request once pricing is known
+	if c.config.Observability.Tracing.Enabled && c.providerInitializer != nil {
+		c.providerInitializer.ApplyTracing(c.tracer, c.costCalculator)
+	}
 
 	// Initialize observability service
 	c.observabilityService = observability.NewService(observability.ServiceConfig{
@@ -407,8 +611,8 @@ func (c *Container) Close() error {
 		_ = c.memoryCache.Close()
 	}
 
-	if c.dbConn != nil {
-		return c.dbConn.Close()
+	if c.storage != nil {
+		return c.storage.Close()
 	}
 	return nil
 }
@@ -441,9 +645,18 @@ func (c *Container) Config() *config.Config {
 	return c.config
 }
 
-// DB returns the database connection.
+// DB returns the database connection of SQL storage backends, or nil if the
+// backend does not expose one.
 func (c *Container) DB() *sql.DB {
-	return c.db
+	if sqlBackend, ok := c.storage.(interface{ DB() *sql.DB }); ok {
+		return sqlBackend.DB()
+	}
+	return nil
+}
+
+// Storage returns the storage backend.
+func (c *Container) Storage() ports.StorageBackendPort {
+	return c.storage
 }
 
 // SessionRepository returns the session repository.
@@ -466,6 +679,42 @@ func (c *Container) WorkflowCheckpointRepository() ports.WorkflowCheckpointPort
 	return c.workflowCheckpointRepo
 }
 
+// PhaseOutputArchiver returns the checkpoint store's archiver of old phase
+// outputs, or nil if the store cannot archive them.
+func (c *Container) PhaseOutputArchiver() ports.PhaseOutputArchiver {
+	archiver, _ := c.workflowCheckpointRepo.(ports.PhaseOutputArchiver)
+	return archiver
+}
+
+// ArchiveDir returns the directory of archived phase outputs:
+// storage.archive.dir, with a leading ~ expanded, or ~/.skillrunner/archive.
+func (c *Container) ArchiveDir() (string, error) {
+	if dir := c.config.Storage.Archive.Dir; dir != "" {
+		return expandHome(dir)
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve the archive directory: %w", err)
+	}
+	return filepath.Join(home, ".skillrunner", "archive"), nil
+}
+
+// RunNoteRepository returns the repository of notes on past runs.
+func (c *Container) RunNoteRepository() ports.RunNotePort {
+	return c.runNoteRepo
+}
+
+// CostLedgerRepository returns the ledger of model invocation costs.
+func (c *Container) CostLedgerRepository() ports.CostLedgerPort {
+	return c.costLedgerRepo
+}
+
+// AuditLog returns the configured audit log, which is only appended to when
+// auditing is enabled but can be read either way.
+func (c *Container) AuditLog() ports.AuditLogPort {
+	return c.auditLog
+}
+
 // ContextItemRepository returns the context item repository.
 func (c *Container) ContextItemRepository() ports.ContextItemStoragePort {
 	return c.contextRepo
@@ -517,9 +766,179 @@ func (c *Container) ProviderRegistry() *adapterProvider.Registry {
 }
 
 // RoutingConfiguration returns a RoutingConfiguration built from the user's config.
-// User-defined profiles are merged over defaults, ensuring user settings take precedence.
+// User-defined profiles are merged over defaults, ensuring user settings take precedence,
+// and profiles pinned in .skillrunner.yaml are merged over those.
+// Generic providers declared in routing.yaml are included so they can be routed to,
+// as are its custom profiles, experiments and power policy.
 func (c *Container) RoutingConfiguration() *config.RoutingConfiguration {
-	return config.NewRoutingConfigurationFromConfig(c.config)
+	c.routingMu.RLock()
+	defer c.routingMu.RUnlock()
+
+	rc := config.NewRoutingConfigurationFromConfig(c.config)
+	if c.project != nil && c.project.Routing != nil {
+		rc.Merge(&config.RoutingConfiguration{Profiles: c.project.Routing.Profiles})
+	}
+
+	if c.routingConfig != nil {
+		for name, p := range c.routingConfig.Providers {
+			if p.IsOpenAICompatible() {
+				rc.Providers[name] = p
+			}
+		}
+		for name, p := range c.routingConfig.Profiles {
+			if _, ok := rc.Profiles[name]; !ok {
+				rc.Profiles[name] = p // Custom profiles
+			}
+		}
+		rc.Experiments = c.routingConfig.Experiments
+		rc.PowerPolicy = c.routingConfig.PowerPolicy
+	}
+
+	return rc
+}
+
+// EffectiveRoutingConfiguration returns the routing configuration from config.yaml
+// with every layer of the routing chain merged over it, as reported by
+// the config commands.
+func (c *Container) EffectiveRoutingConfiguration() *config.RoutingConfiguration {
+	c.routingMu.RLock()
+	defer c.routingMu.RUnlock()
+	return config.MergeRoutingConfigs(config.NewRoutingConfigurationFromConfig(c.config), c.routingConfig)
+}
+
+// ProjectConfig returns the project configuration from the nearest
+// .skillrunner.yaml, or nil if there is none. The error reports a project file
+// that exists but could not be loaded; it is then ignored.
+func (c *Container) ProjectConfig() (*config.ProjectConfig, error) {
+	c.routingMu.RLock()
+	defer c.routingMu.RUnlock()
+	return c.project, c.projectErr
+}
+
+// RoutingError reports why the routing chain could not be loaded, such as a
+// project routing file that sets keys only trusted projects may set. The
+// routing files are then ignored.
+func (c *Container) RoutingError() error {
+	return c.routingErr
+}
+
+// Policy returns the organization policy in effect and where it came from,
+// or nil if none is configured.
+func (c *Container) Policy() (*config.Policy, *config.PolicyStatus) {
+	return c.policy, c.policyStatus
+}
+
+// RoutingChain returns where routing configuration is looked up for this run,
+// as reported by sr config show.
+func (c *Container) RoutingChain() config.RoutingChain {
+	return c.routingChain
+}
+
+// WatchRoutingConfig reloads the routing chain when one of its files
+// changes and passes the new RoutingConfiguration to onReload, for long-running
+// commands to apply with Router.UpdateConfig. An edit that fails to load or
+// validate is logged and the current configuration stays in effect. Providers
+// are not re-registered; adding a provider still requires a restart.
+// Watching stops when ctx is cancelled or the returned watcher is closed. It
+// returns a nil watcher if routing.hot_reload is disabled.
+func (c *Container) WatchRoutingConfig(ctx context.Context, onReload func(*config.RoutingConfiguration)) (*config.RoutingWatcher, error) {
+	if !c.config.Routing.HotReload {
+		return nil, nil
+	}
+
+	paths := c.routingChain.Paths()
+	watcher, err := config.NewRoutingChainWatcher(0, c.routingChain)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create routing watcher: %w", err)
+	}
+
+	err = watcher.Watch(ctx, func(rc *config.RoutingConfiguration, err error) {
+		if err != nil {
+			c.logger.Warn("routing config reload failed, keeping the current configuration", "error", err)
+			return
+		}
+
+		project, projectErr := loadProjectFile()
+		c.routingMu.Lock()
+		c.pricing.Apply(rc)
+		c.routingConfig = rc
+		if projectErr == nil && project != nil {
+			c.project = project
+		}
+		c.routingMu.Unlock()
+
+		c.logger.Info("routing config reloaded", "files", paths)
+		onReload(c.RoutingConfiguration())
+	})
+	if err != nil {
+		_ = watcher.Close()
+		return nil, fmt.Errorf("failed to watch routing config: %w", err)
+	}
+	return watcher, nil
+}
+
+// refreshPricing syncs the pricing catalog in the background when
+// pricing.auto_sync is enabled and the cached catalog is stale. A failed sync
+// is logged and the cached prices stay in effect.
+func (c *Container) refreshPricing() {
+	url := c.config.Pricing.SourceURL()
+	catalog, err := config.FetchPricingCatalog(context.Background(), url)
+	if err != nil {
+		c.logger.Warn("pricing sync failed, keeping the cached prices", "error", err)
+		return
+	}
+	if err := config.SavePricingCatalog(catalog); err != nil {
+		c.logger.Warn("failed to cache pricing catalog", "error", err)
+	}
+
+	c.routingMu.Lock()
+	c.pricing = catalog
+	catalog.Apply(c.routingConfig)
+	c.routingMu.Unlock()
+	catalog.Register(c.costCalculator)
+
+	c.logger.Info("pricing catalog synced", "source", url, "models", len(catalog.Models))
+}
+
+// Transcriber returns the speech-to-text backend for input_audio phases: the
+// local whisper.cpp server when enabled, otherwise the first registered
+// provider with a transcription API (OpenAI). Returns nil if there is none.
+func (c *Container) Transcriber() ports.TranscriptionPort {
+	if wc := c.config.Providers.WhisperCpp; wc.Enabled {
+		var opts []openaicompat.ClientOption
+		if wc.Timeout > 0 {
+			opts = append(opts, openaicompat.WithTimeout(wc.Timeout))
+		}
+		return whispercpp.NewTranscriber(wc.URL, opts...)
+	}
+
+	for _, p := range c.providerRegistry.ListProviders() {
+		// Found through the provider's decorators, so the prompt is redacted
+		// and the transcript audited
+		if t, ok := ports.As[ports.TranscriptionPort](p); ok {
+			return t
+		}
+	}
+	return nil
+}
+
+// ImageGenerator returns the image backend for phases with image output: a
+// configured Stable Diffusion web UI or Stability AI (local first), otherwise
+// the first registered provider with an images API (OpenAI). Returns nil if
+// there is none.
+func (c *Container) ImageGenerator() ports.ImageGenerationPort {
+	if generators := c.providerInitializer.ImageGenerators(); len(generators) > 0 {
+		return generators[0]
+	}
+
+	for _, p := range c.providerRegistry.ListProviders() {
+		// Found through the provider's decorators, so the prompt is redacted
+		// and audited
+		if g, ok := ports.As[ports.ImageGenerationPort](p); ok {
+			return g
+		}
+	}
+	return nil
 }
 
 // ProviderInitializer returns the provider initializer for health checks and status.
@@ -532,6 +951,32 @@ func (c *Container) BackendRegistry() *backend.Registry {
 	return c.backendRegistry
 }
 
+// DocumentConverters returns the registry of converters that turn input
+// documents (PDF, DOCX, HTML) into text.
+func (c *Container) DocumentConverters() *converter.Registry {
+	return c.converterRegistry
+}
+
+// WebFetcher returns the fetcher that turns web pages into input text.
+func (c *Container) WebFetcher() ports.WebFetcherPort {
+	return c.webFetcher
+}
+
+// SecretStore returns the OS keychain that sr auth login saves API keys in.
+func (c *Container) SecretStore() ports.SecretStorePort {
+	return c.secretStore
+}
+
+// newWebFetcher creates a web fetcher caching pages under
+// ~/.skillrunner/cache/web, or without a cache if there is no home directory.
+func newWebFetcher() *web.Fetcher {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return web.NewFetcher()
+	}
+	return web.NewFetcher(web.WithCache(filepath.Join(homeDir, ".skillrunner", "cache", "web"), web.DefaultCacheTTL))
+}
+
 // MCPRegistry returns the MCP server registry for tool access.
 // Returns nil if MCP is not initialized.
 func (c *Container) MCPRegistry() *adapterMCP.Registry {
@@ -582,6 +1027,12 @@ func (c *Container) CostCalculator() *provider.CostCalculator {
 	return c.costCalculator
 }
 
+// ConcurrencyGroups returns the limits of the phases' concurrency groups,
+// shared by the executors of all runs of the process.
+func (c *Container) ConcurrencyGroups() *workflow.ConcurrencyGroups {
+	return c.concurrencyGroups
+}
+
 // ObservabilityService returns the observability service for workflow execution.
 func (c *Container) ObservabilityService() *observability.Service {
 	return c.observabilityService
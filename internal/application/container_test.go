@@ -2,9 +2,11 @@
 package application
 
 import (
+	"context"
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/jbctechsolutions/skillrunner/internal/infrastructure/config"
 )
@@ -146,6 +148,67 @@ func TestContainer_Close(t *testing.T) {
 	}
 }
 
+func TestContainer_WatchRoutingConfig(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("HOME", tmpDir)
+
+	skillrunnerDir := filepath.Join(tmpDir, ".skillrunner")
+	if err := os.MkdirAll(skillrunnerDir, 0755); err != nil {
+		t.Fatalf("Failed to create .skillrunner directory: %v", err)
+	}
+
+	container, err := NewContainer(config.NewDefaultConfig(), false)
+	if err != nil {
+		t.Fatalf("NewContainer failed: %v", err)
+	}
+	defer container.Close()
+
+	reloaded := make(chan *config.RoutingConfiguration, 1)
+	watcher, err := container.WatchRoutingConfig(context.Background(), func(rc *config.RoutingConfiguration) {
+		reloaded <- rc
+	})
+	if err != nil {
+		t.Fatalf("WatchRoutingConfig failed: %v", err)
+	}
+	defer watcher.Close()
+
+	routing := "profiles:\n  reasoning:\n    generation_model: deepseek-r1\n"
+	if err := os.WriteFile(filepath.Join(skillrunnerDir, "routing.yaml"), []byte(routing), 0644); err != nil {
+		t.Fatalf("Failed to write routing.yaml: %v", err)
+	}
+
+	select {
+	case rc := <-reloaded:
+		if !rc.HasProfile("reasoning") {
+			t.Error("reloaded routing configuration should include the new custom profile")
+		}
+		if !container.RoutingConfiguration().HasProfile("reasoning") {
+			t.Error("container should serve the reloaded routing configuration")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for routing config reload")
+	}
+}
+
+func TestContainer_WatchRoutingConfig_Disabled(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("HOME", tmpDir)
+
+	cfg := config.NewDefaultConfig()
+	cfg.Routing.HotReload = false
+
+	container, err := NewContainer(cfg, false)
+	if err != nil {
+		t.Fatalf("NewContainer failed: %v", err)
+	}
+	defer container.Close()
+
+	watcher, err := container.WatchRoutingConfig(context.Background(), func(*config.RoutingConfiguration) {})
+	if err != nil || watcher != nil {
+		t.Errorf("WatchRoutingConfig() = %v, %v; want no watcher when hot reload is disabled", watcher, err)
+	}
+}
+
 func TestGetMachineID(t *testing.T) {
 	machineID := getMachineID()
 	if machineID == "" {
@@ -0,0 +1,213 @@
+package observability
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/rand/v2"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/jbctechsolutions/skillrunner/internal/application/ports"
+	"github.com/jbctechsolutions/skillrunner/internal/domain/metrics"
+)
+
+// BenchmarkFormatVersion identifies the layout of a BenchmarkReport.
+const BenchmarkFormatVersion = 1
+
+// Defaults of BenchmarkOptions.
+const (
+	DefaultBenchmarkEpsilon      = 1.0
+	DefaultBenchmarkMinGroupSize = 10
+)
+
+// latencyRange is a coarse range of phase latencies, by upper bound.
+type latencyRange struct {
+	upTo  time.Duration
+	label string
+}
+
+// latencyBuckets are the latency ranges benchmarks are reported in.
+var latencyBuckets = []latencyRange{
+	{time.Second, "<1s"},
+	{5 * time.Second, "1-5s"},
+	{15 * time.Second, "5-15s"},
+	{time.Minute, "15-60s"},
+	{math.MaxInt64, ">60s"},
+}
+
+// BenchmarkOptions controls the noise and suppression applied to benchmark
+// aggregates.
+type BenchmarkOptions struct {
+	// Epsilon is the privacy budget of each noisy count: the Laplace noise
+	// added has scale 1/Epsilon, so smaller values are noisier.
+	Epsilon float64
+
+	// MinGroupSize drops groups with fewer phase executions, after noise.
+	MinGroupSize int
+
+	// Rand is the noise source; a randomly seeded one when nil.
+	Rand *rand.Rand
+}
+
+// BenchmarkStat is the aggregate of the phase executions of one model that
+// fell into one latency bucket. Runs and SuccessRate include noise.
+type BenchmarkStat struct {
+	Provider      string  `json:"provider"`
+	Model         string  `json:"model"`
+	LatencyBucket string  `json:"latency_bucket"`
+	Runs          int     `json:"runs"`
+	SuccessRate   float64 `json:"success_rate"`
+}
+
+// BenchmarkReport is a set of coarse, noisy aggregates of phase executions
+// meant for sharing: it names no skill, phase, prompt or execution, its
+// period is whole days, and each count carries Laplace noise.
+type BenchmarkReport struct {
+	Version     int             `json:"version"`
+	PeriodStart string          `json:"period_start"` // YYYY-MM-DD
+	PeriodEnd   string          `json:"period_end"`   // YYYY-MM-DD
+	Epsilon     float64         `json:"epsilon"`
+	Stats       []BenchmarkStat `json:"stats"`
+}
+
+// BenchmarkAggregates aggregates the phases executed in the filter's period
+// by model and latency bucket, adds noise, and drops small groups. Phases
+// served from cache are left out.
+func BenchmarkAggregates(ctx context.Context, store ports.MetricsStoragePort, filter metrics.MetricsFilter, opts BenchmarkOptions) (*BenchmarkReport, error) {
+	if opts.Epsilon <= 0 {
+		opts.Epsilon = DefaultBenchmarkEpsilon
+	}
+	if opts.MinGroupSize <= 0 {
+		opts.MinGroupSize = DefaultBenchmarkMinGroupSize
+	}
+	if opts.Rand == nil {
+		opts.Rand = rand.New(rand.NewPCG(rand.Uint64(), rand.Uint64())) //nolint:gosec // Noise, not a secret
+	}
+
+	phases, err := store.GetPhaseExecutions(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	type key struct{ provider, model, bucket string }
+	type tally struct{ runs, succeeded int }
+	groups := make(map[key]*tally)
+	for _, p := range phases {
+		if p.CacheHit || p.Model == "" {
+			continue
+		}
+		k := key{p.Provider, p.Model, latencyBucket(p.Duration)}
+		t, ok := groups[k]
+		if !ok {
+			t = &tally{}
+			groups[k] = t
+		}
+		t.runs++
+		if p.Status == "completed" {
+			t.succeeded++
+		}
+	}
+
+	report := &BenchmarkReport{
+		Version: BenchmarkFormatVersion,
+		Epsilon: opts.Epsilon,
+		Stats:   []BenchmarkStat{},
+	}
+	if !filter.StartDate.IsZero() {
+		report.PeriodStart = filter.StartDate.UTC().Format(time.DateOnly)
+	}
+	if !filter.EndDate.IsZero() {
+		report.PeriodEnd = filter.EndDate.UTC().Format(time.DateOnly)
+	}
+
+	for k, t := range groups {
+		runs := noisyCount(t.runs, opts)
+		if runs < opts.MinGroupSize {
+			continue
+		}
+		succeeded := min(max(noisyCount(t.succeeded, opts), 0), runs)
+		report.Stats = append(report.Stats, BenchmarkStat{
+			Provider:      k.provider,
+			Model:         k.model,
+			LatencyBucket: k.bucket,
+			Runs:          runs,
+			SuccessRate:   math.Round(float64(succeeded)/float64(runs)*100) / 100,
+		})
+	}
+	slices.SortFunc(report.Stats, func(a, b BenchmarkStat) int {
+		if c := strings.Compare(a.Model, b.Model); c != 0 {
+			return c
+		}
+		return bucketIndex(a.LatencyBucket) - bucketIndex(b.LatencyBucket)
+	})
+	return report, nil
+}
+
+// latencyBucket returns the label of the latency bucket d falls into.
+func latencyBucket(d time.Duration) string {
+	for _, b := range latencyBuckets {
+		if d < b.upTo {
+			return b.label
+		}
+	}
+	return latencyBuckets[len(latencyBuckets)-1].label
+}
+
+// bucketIndex returns the position of a latency bucket, for sorting.
+func bucketIndex(label string) int {
+	return slices.IndexFunc(latencyBuckets, func(b latencyRange) bool { return b.label == label })
+}
+
+// noisyCount adds Laplace noise of scale 1/epsilon to n and rounds it.
+func noisyCount(n int, opts BenchmarkOptions) int {
+	u := opts.Rand.Float64() - 0.5
+	noise := -math.Copysign(1, u) * math.Log(1-2*math.Abs(u)) / opts.Epsilon
+	return int(math.Round(float64(n) + noise))
+}
+
+// ParseBenchmarkReport parses a benchmark file and rejects anything but a
+// BenchmarkReport, so that a reviewed file is uploaded exactly as reviewed.
+func ParseBenchmarkReport(data []byte) (*BenchmarkReport, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+
+	var report BenchmarkReport
+	if err := decoder.Decode(&report); err != nil {
+		return nil, fmt.Errorf("not a benchmark report: %w", err)
+	}
+	if report.Version != BenchmarkFormatVersion {
+		return nil, fmt.Errorf("unsupported benchmark report version %d", report.Version)
+	}
+	return &report, nil
+}
+
+// UploadBenchmarkReport posts a reviewed benchmark file to url, unchanged.
+func UploadBenchmarkReport(ctx context.Context, url string, data []byte) error {
+	if _, err := ParseBenchmarkReport(data); err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return errors.New(strings.TrimSpace(fmt.Sprintf("upload rejected: %s %s", resp.Status, body)))
+	}
+	return nil
+}
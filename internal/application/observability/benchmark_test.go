@@ -0,0 +1,146 @@
+package observability
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jbctechsolutions/skillrunner/internal/domain/metrics"
+)
+
+func benchmarkPhases(model string, n, failed int, duration time.Duration) []metrics.PhaseExecutionRecord {
+	phases := make([]metrics.PhaseExecutionRecord, n)
+	for i := range phases {
+		phases[i] = metrics.PhaseExecutionRecord{
+			ExecutionID: "exec", PhaseID: "secret-phase", Provider: "anthropic", Model: model,
+			Status: "completed", Duration: duration,
+		}
+		if i < failed {
+			phases[i].Status = "failed"
+		}
+	}
+	return phases
+}
+
+func TestBenchmarkAggregates(t *testing.T) {
+	storage := newMockMetricsStorage()
+	storage.phases = append(storage.phases, benchmarkPhases("claude-sonnet", 200, 20, 3*time.Second)...)
+	storage.phases = append(storage.phases, benchmarkPhases("claude-sonnet", 100, 0, 30*time.Second)...)
+	storage.phases = append(storage.phases, benchmarkPhases("rare-model", 2, 0, time.Second)...)
+	storage.phases = append(storage.phases, metrics.PhaseExecutionRecord{Model: "claude-sonnet", CacheHit: true, Status: "completed"})
+
+	start := time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC)
+	report, err := BenchmarkAggregates(context.Background(), storage,
+		metrics.MetricsFilter{StartDate: start, EndDate: start.AddDate(0, 1, 0)},
+		BenchmarkOptions{Rand: rand.New(rand.NewPCG(1, 2))})
+	if err != nil {
+		t.Fatalf("BenchmarkAggregates() error = %v", err)
+	}
+
+	if report.PeriodStart != "2026-09-01" || report.PeriodEnd != "2026-10-01" || report.Epsilon != DefaultBenchmarkEpsilon {
+		t.Errorf("report header = %+v", report)
+	}
+	if len(report.Stats) != 2 {
+		t.Fatalf("Stats = %+v, want the two claude-sonnet buckets (rare-model suppressed)", report.Stats)
+	}
+
+	fast, slow := report.Stats[0], report.Stats[1]
+	if fast.LatencyBucket != "1-5s" || slow.LatencyBucket != "15-60s" {
+		t.Errorf("buckets = %s, %s; want 1-5s, 15-60s", fast.LatencyBucket, slow.LatencyBucket)
+	}
+	// Noise of scale 1 moves counts by a few at most
+	if fast.Runs < 190 || fast.Runs > 210 || fast.SuccessRate < 0.85 || fast.SuccessRate > 0.95 {
+		t.Errorf("fast bucket = %+v, want about 200 runs at 90%%", fast)
+	}
+	if slow.SuccessRate > 1 {
+		t.Errorf("success rate = %v, want at most 1", slow.SuccessRate)
+	}
+
+	data, _ := json.Marshal(report)
+	if strings.Contains(string(data), "secret-phase") || strings.Contains(string(data), "exec") {
+		t.Errorf("report leaks phase or execution IDs: %s", data)
+	}
+}
+
+func TestBenchmarkAggregates_Noise(t *testing.T) {
+	storage := newMockMetricsStorage()
+	storage.phases = append(storage.phases, benchmarkPhases("llama3", 50, 0, 500*time.Millisecond)...)
+
+	seen := make(map[int]bool)
+	for seed := range uint64(20) {
+		report, err := BenchmarkAggregates(context.Background(), storage, metrics.MetricsFilter{},
+			BenchmarkOptions{Rand: rand.New(rand.NewPCG(seed, seed))})
+		if err != nil {
+			t.Fatal(err)
+		}
+		seen[report.Stats[0].Runs] = true
+	}
+	if len(seen) < 2 {
+		t.Error("counts should vary with the noise")
+	}
+}
+
+func TestLatencyBucket(t *testing.T) {
+	tests := map[time.Duration]string{
+		0:                      "<1s",
+		999 * time.Millisecond: "<1s",
+		time.Second:            "1-5s",
+		14 * time.Second:       "5-15s",
+		59 * time.Second:       "15-60s",
+		60 * time.Second:       ">60s",
+		time.Hour:              ">60s",
+	}
+	for d, want := range tests {
+		if got := latencyBucket(d); got != want {
+			t.Errorf("latencyBucket(%v) = %q, want %q", d, got, want)
+		}
+	}
+}
+
+func TestParseBenchmarkReport(t *testing.T) {
+	if _, err := ParseBenchmarkReport([]byte(`{"version": 1, "stats": []}`)); err != nil {
+		t.Errorf("ParseBenchmarkReport() error = %v", err)
+	}
+	for name, data := range map[string]string{
+		"extra field":   `{"version": 1, "stats": [], "prompts": ["secret"]}`,
+		"wrong version": `{"version": 2, "stats": []}`,
+		"not JSON":      `usage.csv`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			if _, err := ParseBenchmarkReport([]byte(data)); err == nil {
+				t.Error("ParseBenchmarkReport() should fail")
+			}
+		})
+	}
+}
+
+func TestUploadBenchmarkReport(t *testing.T) {
+	var received string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		received = string(body)
+		if strings.Contains(received, "reject") {
+			http.Error(w, "bad report", http.StatusBadRequest)
+		}
+	}))
+	defer server.Close()
+
+	file := `{"version": 1, "period_start": "2026-09-01", "stats": []}`
+	if err := UploadBenchmarkReport(context.Background(), server.URL, []byte(file)); err != nil {
+		t.Fatalf("UploadBenchmarkReport() error = %v", err)
+	}
+	if received != file {
+		t.Errorf("uploaded %q, want the file unchanged", received)
+	}
+
+	rejected := `{"version": 1, "period_start": "reject", "stats": []}`
+	if err := UploadBenchmarkReport(context.Background(), server.URL, []byte(rejected)); err == nil || !strings.Contains(err.Error(), "bad report") {
+		t.Errorf("UploadBenchmarkReport() error = %v, want the server's rejection", err)
+	}
+}
@@ -0,0 +1,207 @@
+package observability
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/jbctechsolutions/skillrunner/internal/application/ports"
+	"github.com/jbctechsolutions/skillrunner/internal/domain/metrics"
+)
+
+// FOCUS values shared by every usage record. See https://focus.finops.org.
+const (
+	focusCurrency        = "USD"
+	focusChargeCategory  = "Usage"
+	focusChargeFrequency = "Usage-Based"
+	focusServiceCategory = "AI and Machine Learning"
+	focusResourceType    = "Skill Phase"
+	focusUnit            = "Tokens"
+)
+
+// DefaultFOCUSAccount is the billing account usage is exported under when
+// none is given.
+const DefaultFOCUSAccount = "skillrunner"
+
+// FOCUSRecord is a usage record in the FinOps Open Cost and Usage
+// Specification (FOCUS 1.0) format, which FinOps tools and OpenCost ingest.
+// Each record is the provider usage of one phase execution; phases served from
+// cache used no provider and are not exported. Costs are skillrunner's
+// estimates from its pricing catalog, so billed, effective and list cost are
+// the same.
+type FOCUSRecord struct {
+	BillingAccountID   string            `json:"BillingAccountId"`
+	BillingAccountName string            `json:"BillingAccountName"`
+	BillingCurrency    string            `json:"BillingCurrency"`
+	BillingPeriodStart time.Time         `json:"BillingPeriodStart"`
+	BillingPeriodEnd   time.Time         `json:"BillingPeriodEnd"`
+	ChargeCategory     string            `json:"ChargeCategory"`
+	ChargeDescription  string            `json:"ChargeDescription"`
+	ChargeFrequency    string            `json:"ChargeFrequency"`
+	ChargePeriodStart  time.Time         `json:"ChargePeriodStart"`
+	ChargePeriodEnd    time.Time         `json:"ChargePeriodEnd"`
+	BilledCost         float64           `json:"BilledCost"`
+	EffectiveCost      float64           `json:"EffectiveCost"`
+	ListCost           float64           `json:"ListCost"`
+	ConsumedQuantity   int               `json:"ConsumedQuantity"`
+	ConsumedUnit       string            `json:"ConsumedUnit"`
+	PricingQuantity    int               `json:"PricingQuantity"`
+	PricingUnit        string            `json:"PricingUnit"`
+	ProviderName       string            `json:"ProviderName"`
+	PublisherName      string            `json:"PublisherName"`
+	InvoiceIssuerName  string            `json:"InvoiceIssuerName"`
+	ServiceCategory    string            `json:"ServiceCategory"`
+	ServiceName        string            `json:"ServiceName"`
+	SkuID              string            `json:"SkuId"`
+	ResourceID         string            `json:"ResourceId"`
+	ResourceName       string            `json:"ResourceName"`
+	ResourceType       string            `json:"ResourceType"`
+	SubAccountID       string            `json:"SubAccountId"`
+	SubAccountName     string            `json:"SubAccountName"`
+	Tags               map[string]string `json:"Tags"`
+}
+
+// FOCUSRecords returns the FOCUS usage records of the phases executed in the
+// filter's period, billed to account. Skills are the sub-accounts, and each
+// record is tagged with its skill, phase, execution and model.
+func FOCUSRecords(ctx context.Context, store ports.MetricsStoragePort, filter metrics.MetricsFilter, account string) ([]FOCUSRecord, error) {
+	if account == "" {
+		account = DefaultFOCUSAccount
+	}
+
+	phases, err := store.GetPhaseExecutions(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	// Parent executions name the skill of each phase
+	execFilter := filter
+	execFilter.Status, execFilter.Limit, execFilter.Offset = "", 0, 0
+	if !execFilter.StartDate.IsZero() {
+		// An execution starts before its phases; include runs straddling the start
+		execFilter.StartDate = execFilter.StartDate.Add(-24 * time.Hour)
+	}
+	executions, err := store.GetExecutions(ctx, execFilter)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[string]metrics.ExecutionRecord, len(executions))
+	for _, e := range executions {
+		byID[e.ID] = e
+	}
+
+	records := make([]FOCUSRecord, 0, len(phases))
+	for _, p := range phases {
+		if p.CacheHit {
+			continue
+		}
+		exec := byID[p.ExecutionID]
+		periodStart, periodEnd := billingPeriod(p.StartedAt)
+		tokens := p.InputTokens + p.OutputTokens
+
+		records = append(records, FOCUSRecord{
+			BillingAccountID:   account,
+			BillingAccountName: account,
+			BillingCurrency:    focusCurrency,
+			BillingPeriodStart: periodStart,
+			BillingPeriodEnd:   periodEnd,
+			ChargeCategory:     focusChargeCategory,
+			ChargeDescription:  fmt.Sprintf("%s tokens for phase %s of %s", p.Model, p.PhaseID, skillLabel(exec)),
+			ChargeFrequency:    focusChargeFrequency,
+			ChargePeriodStart:  p.StartedAt.UTC(),
+			ChargePeriodEnd:    p.CompletedAt.UTC(),
+			BilledCost:         p.Cost,
+			EffectiveCost:      p.Cost,
+			ListCost:           p.Cost,
+			ConsumedQuantity:   tokens,
+			ConsumedUnit:       focusUnit,
+			PricingQuantity:    tokens,
+			PricingUnit:        focusUnit,
+			ProviderName:       p.Provider,
+			PublisherName:      p.Provider,
+			InvoiceIssuerName:  p.Provider,
+			ServiceCategory:    focusServiceCategory,
+			ServiceName:        p.Provider,
+			SkuID:              p.Model,
+			ResourceID:         p.ExecutionID + "/" + p.PhaseID,
+			ResourceName:       p.PhaseName,
+			ResourceType:       focusResourceType,
+			SubAccountID:       exec.SkillID,
+			SubAccountName:     exec.SkillName,
+			Tags: map[string]string{
+				"skill":         exec.SkillID,
+				"phase":         p.PhaseID,
+				"execution_id":  p.ExecutionID,
+				"model":         p.Model,
+				"status":        p.Status,
+				"input_tokens":  strconv.Itoa(p.InputTokens),
+				"output_tokens": strconv.Itoa(p.OutputTokens),
+			},
+		})
+	}
+	return records, nil
+}
+
+// billingPeriod returns the calendar month, in UTC, that t falls in.
+func billingPeriod(t time.Time) (time.Time, time.Time) {
+	t = t.UTC()
+	start := time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+	return start, start.AddDate(0, 1, 0)
+}
+
+func skillLabel(exec metrics.ExecutionRecord) string {
+	if exec.SkillID == "" {
+		return "an unrecorded skill"
+	}
+	return "skill " + exec.SkillID
+}
+
+// focusColumns are the CSV columns of a FOCUS export, in order.
+var focusColumns = []string{
+	"BillingAccountId", "BillingAccountName", "BillingCurrency", "BillingPeriodStart", "BillingPeriodEnd",
+	"ChargeCategory", "ChargeDescription", "ChargeFrequency", "ChargePeriodStart", "ChargePeriodEnd",
+	"BilledCost", "EffectiveCost", "ListCost", "ConsumedQuantity", "ConsumedUnit", "PricingQuantity", "PricingUnit",
+	"ProviderName", "PublisherName", "InvoiceIssuerName", "ServiceCategory", "ServiceName", "SkuId",
+	"ResourceId", "ResourceName", "ResourceType", "SubAccountId", "SubAccountName", "Tags",
+}
+
+// WriteFOCUSCSV writes records as a FOCUS CSV file, with Tags as a JSON object.
+func WriteFOCUSCSV(w io.Writer, records []FOCUSRecord) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(focusColumns); err != nil {
+		return err
+	}
+
+	for _, r := range records {
+		tags, err := json.Marshal(r.Tags)
+		if err != nil {
+			return err
+		}
+		row := []string{
+			r.BillingAccountID, r.BillingAccountName, r.BillingCurrency, focusTime(r.BillingPeriodStart), focusTime(r.BillingPeriodEnd),
+			r.ChargeCategory, r.ChargeDescription, r.ChargeFrequency, focusTime(r.ChargePeriodStart), focusTime(r.ChargePeriodEnd),
+			focusCost(r.BilledCost), focusCost(r.EffectiveCost), focusCost(r.ListCost),
+			strconv.Itoa(r.ConsumedQuantity), r.ConsumedUnit, strconv.Itoa(r.PricingQuantity), r.PricingUnit,
+			r.ProviderName, r.PublisherName, r.InvoiceIssuerName, r.ServiceCategory, r.ServiceName, r.SkuID,
+			r.ResourceID, r.ResourceName, r.ResourceType, r.SubAccountID, r.SubAccountName, string(tags),
+		}
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+func focusTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
+
+func focusCost(c float64) string {
+	return strconv.FormatFloat(c, 'f', -1, 64)
+}
@@ -0,0 +1,70 @@
+package observability
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"testing"
+	"time"
+
+	"github.com/jbctechsolutions/skillrunner/internal/domain/metrics"
+)
+
+func TestFOCUSRecords(t *testing.T) {
+	storage := newMockMetricsStorage()
+	started := time.Date(2026, 3, 31, 23, 59, 0, 0, time.UTC)
+	storage.executions = append(storage.executions, metrics.ExecutionRecord{
+		ID: "exec-1", SkillID: "code-review", SkillName: "Code Review", StartedAt: started,
+	})
+	storage.phases = append(storage.phases,
+		metrics.PhaseExecutionRecord{
+			ExecutionID: "exec-1", PhaseID: "analyze", PhaseName: "Analyze", Status: "completed",
+			Provider: "anthropic", Model: "claude-3-5-sonnet", InputTokens: 500, OutputTokens: 200, Cost: 0.0045,
+			StartedAt: started, CompletedAt: started.Add(90 * time.Second),
+		},
+		metrics.PhaseExecutionRecord{
+			ExecutionID: "exec-1", PhaseID: "summary", Provider: "ollama", Model: "llama3.2:3b", CacheHit: true,
+			StartedAt: started, CompletedAt: started,
+		},
+	)
+
+	records, err := FOCUSRecords(context.Background(), storage, metrics.MetricsFilter{}, "")
+	if err != nil {
+		t.Fatalf("FOCUSRecords() error = %v", err)
+	}
+	if len(records) != 1 {
+		t.Fatalf("FOCUSRecords() = %d records, want 1 (cache hits skipped)", len(records))
+	}
+
+	r := records[0]
+	if r.BillingAccountID != DefaultFOCUSAccount || r.SubAccountID != "code-review" || r.ProviderName != "anthropic" {
+		t.Errorf("record accounts = %s/%s/%s", r.BillingAccountID, r.SubAccountID, r.ProviderName)
+	}
+	if r.ConsumedQuantity != 700 || r.BilledCost != 0.0045 || r.SkuID != "claude-3-5-sonnet" {
+		t.Errorf("record usage = %d tokens, $%v, sku %s", r.ConsumedQuantity, r.BilledCost, r.SkuID)
+	}
+	wantStart := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	if !r.BillingPeriodStart.Equal(wantStart) || !r.BillingPeriodEnd.Equal(wantStart.AddDate(0, 1, 0)) {
+		t.Errorf("billing period = %v to %v, want March 2026", r.BillingPeriodStart, r.BillingPeriodEnd)
+	}
+	if r.Tags["phase"] != "analyze" || r.Tags["execution_id"] != "exec-1" {
+		t.Errorf("Tags = %v", r.Tags)
+	}
+
+	var buf bytes.Buffer
+	if err := WriteFOCUSCSV(&buf, records); err != nil {
+		t.Fatalf("WriteFOCUSCSV() error = %v", err)
+	}
+	rows, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("exported CSV does not parse: %v", err)
+	}
+	if len(rows) != 2 || len(rows[1]) != len(focusColumns) {
+		t.Fatalf("CSV = %d rows, want a header and one record of %d columns", len(rows), len(focusColumns))
+	}
+	for i, col := range focusColumns {
+		if col == "BilledCost" && rows[1][i] != "0.0045" {
+			t.Errorf("BilledCost = %q, want 0.0045", rows[1][i])
+		}
+	}
+}
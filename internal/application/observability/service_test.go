@@ -39,6 +39,10 @@ func (m *mockMetricsStorage) GetExecutions(ctx context.Context, filter metrics.M
 	return m.executions, nil
 }
 
+func (m *mockMetricsStorage) GetPhaseExecutions(ctx context.Context, filter metrics.MetricsFilter) ([]metrics.PhaseExecutionRecord, error) {
+	return m.phases, nil
+}
+
 func (m *mockMetricsStorage) GetAggregatedMetrics(ctx context.Context, filter metrics.MetricsFilter) (*metrics.AggregatedMetrics, error) {
 	return nil, nil
 }
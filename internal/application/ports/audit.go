@@ -0,0 +1,43 @@
+package ports
+
+import (
+	"context"
+
+	"github.com/jbctechsolutions/skillrunner/internal/domain/workflow"
+)
+
+// -----------------------------------------------------------------------------
+// Audit Log Port
+// -----------------------------------------------------------------------------
+
+// AuditLogPort appends the requests and responses of provider calls to an
+// append-only audit log. Records are never updated or deleted.
+type AuditLogPort interface {
+	// Append persists a record at the end of the log.
+	Append(ctx context.Context, record *workflow.AuditRecord) error
+
+	// Records returns the records of an execution in the order they were
+	// appended, or an empty slice if it has none.
+	Records(ctx context.Context, executionID string) ([]*workflow.AuditRecord, error)
+}
+
+// AuditScope identifies the run and phase a provider call is made for.
+type AuditScope struct {
+	ExecutionID string
+	SkillID     string
+	PhaseID     string
+}
+
+// auditScopeKey is the context key of the AuditScope.
+type auditScopeKey struct{}
+
+// WithAuditScope returns a context carrying scope, for AuditScopeFrom.
+func WithAuditScope(ctx context.Context, scope AuditScope) context.Context {
+	return context.WithValue(ctx, auditScopeKey{}, scope)
+}
+
+// AuditScopeFrom returns the scope carried by ctx, or the zero scope.
+func AuditScopeFrom(ctx context.Context) AuditScope {
+	scope, _ := ctx.Value(auditScopeKey{}).(AuditScope)
+	return scope
+}
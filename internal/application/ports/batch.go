@@ -0,0 +1,67 @@
+package ports
+
+import "context"
+
+// BatchJobStatus is the lifecycle state of an asynchronous batch job.
+type BatchJobStatus string
+
+const (
+	BatchJobValidating BatchJobStatus = "validating"
+	BatchJobInProgress BatchJobStatus = "in_progress"
+	BatchJobFinalizing BatchJobStatus = "finalizing"
+	BatchJobCompleted  BatchJobStatus = "completed"
+	BatchJobFailed     BatchJobStatus = "failed"
+	BatchJobExpired    BatchJobStatus = "expired"
+	BatchJobCancelling BatchJobStatus = "cancelling"
+	BatchJobCancelled  BatchJobStatus = "cancelled"
+)
+
+// IsTerminal reports whether the job has stopped and its results, if any,
+// can be fetched.
+func (s BatchJobStatus) IsTerminal() bool {
+	switch s {
+	case BatchJobCompleted, BatchJobFailed, BatchJobExpired, BatchJobCancelled:
+		return true
+	default:
+		return false
+	}
+}
+
+// BatchItem is one completion request in a batch job.
+type BatchItem struct {
+	CustomID string // Caller-chosen ID used to match the item's result
+	Request  CompletionRequest
+}
+
+// BatchJob describes a submitted batch job.
+type BatchJob struct {
+	ID        string
+	Status    BatchJobStatus
+	Total     int
+	Completed int
+	Failed    int
+}
+
+// BatchItemResult is the outcome of one item in a finished batch job.
+// Exactly one of Response and Error is set.
+type BatchItemResult struct {
+	CustomID string
+	Response *CompletionResponse
+	Error    string
+}
+
+// BatchProviderPort is implemented by providers with an asynchronous batch
+// API, which trades latency (results within hours) for a lower price.
+// Providers expose it alongside ProviderPort; callers detect it with a type
+// assertion.
+type BatchProviderPort interface {
+	// SubmitBatch submits items as a single batch job.
+	SubmitBatch(ctx context.Context, items []BatchItem) (*BatchJob, error)
+
+	// GetBatch returns the current state of a batch job.
+	GetBatch(ctx context.Context, jobID string) (*BatchJob, error)
+
+	// GetBatchResults returns the results of a job in a terminal state.
+	// Items that never ran (e.g. because the job expired) are omitted.
+	GetBatchResults(ctx context.Context, jobID string) ([]BatchItemResult, error)
+}
@@ -86,11 +86,3 @@ type ResponseCachePort interface {
 	// GetTokenStats returns token-related cache statistics.
 	GetTokenStats(ctx context.Context) (inputTokensSaved, outputTokensSaved int64, err error)
 }
-
-// SecretStorePort for secure credential storage (v1.1 placeholder)
-type SecretStorePort interface {
-	Get(ctx context.Context, key string) (string, error)
-	Set(ctx context.Context, key, value string) error
-	Delete(ctx context.Context, key string) error
-	List(ctx context.Context) ([]string, error)
-}
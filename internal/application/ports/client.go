@@ -0,0 +1,42 @@
+package ports
+
+import (
+	"context"
+	"time"
+)
+
+// Client identifies the API client a provider call is made for, in sr
+// serve, so that rate limiters can share a provider's capacity fairly among
+// the clients using it.
+type Client struct {
+	Name string
+
+	// Weight is the client's share of a provider's capacity relative to the
+	// other clients waiting for it; zero counts as 1.
+	Weight float64
+}
+
+// clientKey is the context key of the Client.
+type clientKey struct{}
+
+// WithClient returns a context carrying client, for ClientFrom.
+func WithClient(ctx context.Context, client Client) context.Context {
+	return context.WithValue(ctx, clientKey{}, client)
+}
+
+// ClientFrom returns the client carried by ctx, or the zero client for
+// calls made on behalf of no client, such as those of sr run.
+func ClientFrom(ctx context.Context) Client {
+	client, _ := ctx.Value(clientKey{}).(Client)
+	return client
+}
+
+// ClientUsage is a client's use of the capacity of a rate-limited provider.
+type ClientUsage struct {
+	Client   string        `json:"client"`
+	Requests int64         `json:"requests"`
+	Tokens   int64         `json:"tokens"`
+	InFlight int           `json:"in_flight"`
+	Waiting  int           `json:"waiting"`
+	WaitTime time.Duration `json:"wait_time_ns"`
+}
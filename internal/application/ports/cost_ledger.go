@@ -0,0 +1,20 @@
+package ports
+
+import (
+	"context"
+	"time"
+
+	"github.com/jbctechsolutions/skillrunner/internal/domain/provider"
+)
+
+// CostLedgerPort persists the cost of model invocations so that spending can
+// be reported across runs.
+type CostLedgerPort interface {
+	// Record persists a ledger entry. Recording the same execution and phase
+	// again is a no-op, so a resumed run does not count its earlier phases twice.
+	Record(ctx context.Context, record *provider.CostRecord) error
+
+	// Summarize totals the entries recorded since the given time by provider,
+	// skill or model, most expensive first.
+	Summarize(ctx context.Context, since time.Time, by provider.CostGroupBy) ([]provider.CostGroup, error)
+}
@@ -0,0 +1,16 @@
+package ports
+
+import "context"
+
+// DocumentConverterPort converts a document format (PDF, DOCX, HTML) to plain
+// text or markdown so it can be used as workflow input.
+type DocumentConverterPort interface {
+	// Name identifies the format, e.g. "pdf".
+	Name() string
+
+	// Extensions lists the lowercase file extensions handled, with the dot.
+	Extensions() []string
+
+	// Convert extracts the text of a document.
+	Convert(ctx context.Context, data []byte) (string, error)
+}
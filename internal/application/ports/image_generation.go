@@ -0,0 +1,64 @@
+package ports
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ImageGenerationRequest asks for one or more images from a text prompt.
+type ImageGenerationRequest struct {
+	Prompt         string
+	NegativePrompt string // Optional; honored by Stability and Stable Diffusion
+	Model          string // Optional model; empty selects the adapter's default
+	Size           string // Optional "WIDTHxHEIGHT"; empty selects the adapter's default
+	Count          int    // Number of images (default 1)
+}
+
+// Dimensions parses Size, returning the given defaults when it is empty.
+func (r ImageGenerationRequest) Dimensions(defaultWidth, defaultHeight int) (width, height int, err error) {
+	if r.Size == "" {
+		return defaultWidth, defaultHeight, nil
+	}
+	return ParseImageSize(r.Size)
+}
+
+// ParseImageSize parses a "WIDTHxHEIGHT" size such as "1024x1024".
+func ParseImageSize(size string) (width, height int, err error) {
+	w, h, ok := strings.Cut(size, "x")
+	if ok {
+		width, err = strconv.Atoi(w)
+		if err == nil {
+			height, err = strconv.Atoi(h)
+		}
+	}
+	if !ok || err != nil || width <= 0 || height <= 0 {
+		return 0, 0, fmt.Errorf("invalid image size %q: want WIDTHxHEIGHT, e.g. 1024x1024", size)
+	}
+	return width, height, nil
+}
+
+// GeneratedImage is one generated image.
+type GeneratedImage struct {
+	Data          []byte
+	Format        string // File extension, e.g. "png"
+	RevisedPrompt string // The prompt the model actually used, if it rewrote it
+}
+
+// ImageGenerationResponse holds the generated images.
+type ImageGenerationResponse struct {
+	Images    []GeneratedImage
+	ModelUsed string
+}
+
+// ImageGenerationPort is implemented by text-to-image backends (OpenAI
+// Images, Stability AI, a local Stable Diffusion web UI). Cloud providers
+// expose it alongside ProviderPort; callers detect it with a type assertion.
+type ImageGenerationPort interface {
+	// Info returns metadata about the backend.
+	Info() ProviderInfo
+
+	// GenerateImages generates images for a prompt.
+	GenerateImages(ctx context.Context, req ImageGenerationRequest) (*ImageGenerationResponse, error)
+}
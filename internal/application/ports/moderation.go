@@ -0,0 +1,36 @@
+package ports
+
+import "context"
+
+// ModerationResult is the verdict of a moderation check.
+type ModerationResult struct {
+	Flagged    bool
+	Categories []string // Categories the text was flagged for, e.g. "violence"
+}
+
+// ModerationPort is implemented by content classifiers (the OpenAI
+// moderation endpoint, a local Llama Guard model) that check prompts before
+// they are sent to a provider.
+type ModerationPort interface {
+	// Moderate classifies text.
+	Moderate(ctx context.Context, text string) (*ModerationResult, error)
+}
+
+// moderationKey is the context key of a skill's moderation action.
+type moderationKey struct{}
+
+// WithModeration returns a context carrying the moderation action of the
+// skill being run (block, warn or off), for ModerationFrom. It overrides the
+// action of the providers the skill's requests are sent to.
+func WithModeration(ctx context.Context, action string) context.Context {
+	if action == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, moderationKey{}, action)
+}
+
+// ModerationFrom returns the moderation action carried by ctx, or "".
+func ModerationFrom(ctx context.Context) string {
+	action, _ := ctx.Value(moderationKey{}).(string)
+	return action
+}
@@ -3,6 +3,8 @@ package ports
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"reflect"
 	"time"
 )
 
@@ -16,8 +18,10 @@ type ProviderInfo struct {
 
 // Message represents a chat message
 type Message struct {
-	Role    string // system, user, assistant
-	Content string
+	Role       string // system, user, assistant, tool
+	Content    string
+	ToolCalls  []ToolCall // Tool calls made by the assistant in this turn
+	ToolCallID string     // For role "tool": the ID of the call this message answers
 }
 
 // Tool represents a tool that can be called by the LLM.
@@ -28,14 +32,64 @@ type Tool struct {
 	DeferLoading bool            `json:"defer_loading,omitempty"` // For Tool Search Tool support
 }
 
+// ToolChoiceMode controls whether and how the model may call tools.
+type ToolChoiceMode string
+
+const (
+	// ToolChoiceAuto lets the model decide whether to call a tool (the default).
+	ToolChoiceAuto ToolChoiceMode = "auto"
+	// ToolChoiceNone prevents the model from calling any tool.
+	ToolChoiceNone ToolChoiceMode = "none"
+	// ToolChoiceRequired forces the model to call at least one tool.
+	ToolChoiceRequired ToolChoiceMode = "required"
+	// ToolChoiceTool forces the model to call the tool named in ToolChoice.Name.
+	ToolChoiceTool ToolChoiceMode = "tool"
+)
+
+// ToolChoice constrains tool use for a completion request.
+type ToolChoice struct {
+	Mode ToolChoiceMode
+	Name string // Required when Mode is ToolChoiceTool
+}
+
+// ToolCall is a tool invocation requested by the model.
+type ToolCall struct {
+	ID        string          `json:"id"`
+	Name      string          `json:"name"`
+	Arguments json.RawMessage `json:"arguments"` // JSON object matching the tool's input schema
+}
+
+// ResponseFormatType selects the shape of a model's response.
+type ResponseFormatType string
+
+const (
+	// ResponseFormatText is free-form text (the default).
+	ResponseFormatText ResponseFormatType = "text"
+	// ResponseFormatJSON is any syntactically valid JSON object.
+	ResponseFormatJSON ResponseFormatType = "json_object"
+	// ResponseFormatJSONSchema is JSON conforming to ResponseFormat.Schema.
+	ResponseFormatJSONSchema ResponseFormatType = "json_schema"
+)
+
+// ResponseFormat requests structured output from providers that support a
+// native JSON mode. Providers without one ignore it; callers that need
+// guarantees must validate the response.
+type ResponseFormat struct {
+	Type   ResponseFormatType
+	Name   string          // Schema name, for providers that require one
+	Schema json.RawMessage // JSON Schema, required for ResponseFormatJSONSchema
+}
+
 // CompletionRequest is the input for LLM completion
 type CompletionRequest struct {
-	ModelID      string
-	Messages     []Message
-	MaxTokens    int
-	Temperature  float32
-	SystemPrompt string
-	Tools        []Tool // Optional tools for function calling
+	ModelID        string
+	Messages       []Message
+	MaxTokens      int
+	Temperature    float32
+	SystemPrompt   string
+	Tools          []Tool          // Optional tools for function calling
+	ToolChoice     *ToolChoice     // Optional tool use constraint; nil means auto
+	ResponseFormat *ResponseFormat // Optional structured output mode; nil means text
 }
 
 // CompletionResponse is the output from LLM completion
@@ -46,11 +100,16 @@ type CompletionResponse struct {
 	FinishReason string
 	ModelUsed    string
 	Duration     time.Duration
+	ToolCalls    []ToolCall // Tool calls requested by the model, if any
 }
 
 // StreamCallback for streaming responses
 type StreamCallback func(chunk string) error
 
+// ErrStreamStalled is returned by Stream when the provider stopped sending
+// content for longer than its stall timeout without ending the stream.
+var ErrStreamStalled = errors.New("stream stalled")
+
 // HealthStatus for provider health checks
 type HealthStatus struct {
 	Healthy     bool
@@ -69,3 +128,79 @@ type ProviderPort interface {
 	Stream(ctx context.Context, req CompletionRequest, cb StreamCallback) (*CompletionResponse, error)
 	HealthCheck(ctx context.Context, modelID string) (*HealthStatus, error)
 }
+
+// ThrottleReporter is implemented by providers that track their rate limits.
+// Throttled reports whether the provider is close to a limit and its
+// requests are being slowed down, so routing can prefer another provider.
+type ThrottleReporter interface {
+	Throttled() bool
+}
+
+// Warmer is implemented by providers that can prepare a model ahead of its
+// first request, such as loading a local model into memory or opening the
+// connection to a cloud API. Warm generates nothing and is best effort.
+type Warmer interface {
+	Warm(ctx context.Context, modelID string) error
+}
+
+// UnwrapProvider returns the provider underneath any decorators (such as a
+// rate limiter) that wrap p through an Unwrap method. Use As, not the
+// result, to find optional capabilities like BatchProviderPort.
+func UnwrapProvider(p ProviderPort) ProviderPort {
+	for {
+		u, ok := p.(interface{ Unwrap() ProviderPort })
+		if !ok {
+			return p
+		}
+		p = u.Unwrap()
+	}
+}
+
+// CapabilityLayer is implemented by provider decorators that must see every
+// request, such as redaction, moderation and audit. They have no Unwrap
+// method, so that requests cannot be sent around them; instead, As returns
+// the capabilities of the provider they wrap with their treatment applied.
+type CapabilityLayer interface {
+	// As sets target, a pointer to a capability interface such as
+	// *BatchProviderPort, like errors.As, and reports whether the layer
+	// offers the capability.
+	As(target any) bool
+}
+
+// As finds the optional capability C, such as BatchProviderPort or
+// TranscriptionPort, of p or of the provider under its decorators. It looks
+// through decorators with an Unwrap method and asks a CapabilityLayer for a
+// capability it does not implement itself, with its treatment applied; a
+// decorator with neither ends the search.
+func As[C any](p ProviderPort) (C, bool) {
+	var c C
+	ok := AsCapability(p, &c)
+	return c, ok
+}
+
+// AsCapability is As with the capability given as a pointer to an interface,
+// for CapabilityLayer implementations that pass on the capabilities they do
+// not change.
+func AsCapability(p ProviderPort, target any) bool {
+	val := reflect.ValueOf(target)
+	if val.Kind() != reflect.Pointer || val.IsNil() || val.Elem().Kind() != reflect.Interface {
+		return false
+	}
+	capability := val.Elem().Type()
+
+	for p != nil {
+		if reflect.TypeOf(p).Implements(capability) {
+			val.Elem().Set(reflect.ValueOf(p))
+			return true
+		}
+		if layer, ok := p.(CapabilityLayer); ok {
+			return layer.As(target)
+		}
+		u, ok := p.(interface{ Unwrap() ProviderPort })
+		if !ok {
+			return false
+		}
+		p = u.Unwrap()
+	}
+	return false
+}
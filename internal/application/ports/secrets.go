@@ -0,0 +1,31 @@
+package ports
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrSecretNotFound is returned by SecretStorePort.Get when no secret is
+// stored under the key.
+var ErrSecretNotFound = errors.New("secret not found")
+
+// SecretStorePort stores secrets such as provider API keys outside the
+// configuration files, for example in the OS keychain.
+type SecretStorePort interface {
+	// Name describes where secrets are kept, e.g. "macOS Keychain".
+	Name() string
+
+	// Get returns the secret stored under key, or an error wrapping
+	// ErrSecretNotFound.
+	Get(ctx context.Context, key string) (string, error)
+
+	// Set stores value under key, replacing any previous value.
+	Set(ctx context.Context, key, value string) error
+
+	// Delete removes the secret stored under key. Deleting a missing
+	// secret is not an error.
+	Delete(ctx context.Context, key string) error
+
+	// List returns the keys of the stored secrets, sorted.
+	List(ctx context.Context) ([]string, error)
+}
@@ -5,6 +5,7 @@ package ports
 
 import (
 	"context"
+	"time"
 
 	"github.com/jbctechsolutions/skillrunner/internal/domain/metrics"
 	"github.com/jbctechsolutions/skillrunner/internal/domain/skill"
@@ -34,6 +35,10 @@ type MetricsStoragePort interface {
 	// Results are ordered by execution time (most recent first).
 	GetExecutions(ctx context.Context, filter metrics.MetricsFilter) ([]metrics.ExecutionRecord, error)
 
+	// GetPhaseExecutions retrieves phase execution records matching the filter,
+	// ordered by start time (oldest first). SkillID matches the parent execution.
+	GetPhaseExecutions(ctx context.Context, filter metrics.MetricsFilter) ([]metrics.PhaseExecutionRecord, error)
+
 	// GetAggregatedMetrics retrieves aggregated metrics for the given filter.
 	// Returns complete metrics including provider and skill breakdowns.
 	GetAggregatedMetrics(ctx context.Context, filter metrics.MetricsFilter) (*metrics.AggregatedMetrics, error)
@@ -48,6 +53,66 @@ type MetricsStoragePort interface {
 	GetCostSummary(ctx context.Context, filter metrics.MetricsFilter) (*metrics.CostSummary, error)
 }
 
+// StorageBackendPort is a storage backend: the repositories skillrunner keeps
+// its persistent state in. SQLite is the default backend. Other backends,
+// such as Postgres or BoltDB, implement this interface and register a factory
+// under their name; docs/storage.md describes what each repository must do.
+type StorageBackendPort interface {
+	// Name returns the name the backend is configured by, e.g. "sqlite".
+	Name() string
+
+	// Migrate brings the backend's schema up to date. It must be safe to call
+	// on every start and returns how many migrations it applied.
+	Migrate(ctx context.Context) (int, error)
+
+	// MigrationStatus reports the backend's schema migrations in version
+	// order and whether each has been applied.
+	MigrationStatus(ctx context.Context) ([]MigrationStatus, error)
+
+	// Ping checks that the backend is reachable.
+	Ping(ctx context.Context) error
+
+	// WorkflowCheckpoints stores skill run checkpoints, which make up the run
+	// history and allow interrupted runs to resume.
+	WorkflowCheckpoints() WorkflowCheckpointPort
+
+	// RunNotes stores notes on past runs.
+	RunNotes() RunNotePort
+
+	// CostLedger stores the cost of each model invocation.
+	CostLedger() CostLedgerPort
+
+	// AuditLog stores the requests and responses of provider calls, for
+	// compliance review, and never updates or deletes them.
+	AuditLog() AuditLogPort
+
+	// Metrics stores execution metrics.
+	Metrics() MetricsStoragePort
+
+	// Cache returns the persistent tier of the response cache, holding up to
+	// maxSize bytes.
+	Cache(maxSize int64) CachePort
+
+	// Sessions, Workspaces, Checkpoints, ContextItems and Rules store the
+	// state of agent sessions.
+	Sessions() SessionStateStoragePort
+	Workspaces() WorkspaceStateStoragePort
+	Checkpoints() CheckpointStateStoragePort
+	ContextItems() ContextItemStoragePort
+	Rules() RuleStoragePort
+
+	// Close releases the backend's connections.
+	Close() error
+}
+
+// MigrationStatus describes one schema migration of a storage backend.
+type MigrationStatus struct {
+	Version   int
+	Name      string
+	Applied   bool
+	AppliedAt time.Time // Zero if not applied
+}
+
 // SkillLoaderPort defines the interface for loading and discovering skills.
 // Implementations might load from local YAML files, remote registries, or databases.
 type SkillLoaderPort interface {
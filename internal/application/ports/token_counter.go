@@ -0,0 +1,11 @@
+package ports
+
+import "context"
+
+// TokenCounterPort counts the input tokens of a completion request as the
+// target model sees them. Providers with a counting API (Anthropic) expose
+// it alongside ProviderPort; callers detect it with a type assertion.
+type TokenCounterPort interface {
+	// CountTokens returns the number of input tokens of req.
+	CountTokens(ctx context.Context, req CompletionRequest) (int, error)
+}
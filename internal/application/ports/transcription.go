@@ -0,0 +1,33 @@
+package ports
+
+import (
+	"context"
+	"time"
+)
+
+// TranscriptionRequest asks for the transcript of an audio file.
+type TranscriptionRequest struct {
+	AudioPath string // Path of the audio file to transcribe
+	Model     string // Optional model; empty selects the adapter's default
+	Language  string // Optional ISO-639-1 language hint, e.g. "en"
+	Prompt    string // Optional context or vocabulary to guide the model
+}
+
+// TranscriptionResponse is the transcript of an audio file.
+type TranscriptionResponse struct {
+	Text      string
+	Language  string        // Detected or requested language, if reported
+	Duration  time.Duration // Length of the audio, if reported
+	ModelUsed string
+}
+
+// TranscriptionPort is implemented by speech-to-text backends (OpenAI
+// Whisper, a local whisper.cpp server). Cloud providers expose it alongside
+// ProviderPort; callers detect it with a type assertion.
+type TranscriptionPort interface {
+	// Info returns metadata about the backend.
+	Info() ProviderInfo
+
+	// Transcribe converts the speech in an audio file to text.
+	Transcribe(ctx context.Context, req TranscriptionRequest) (*TranscriptionResponse, error)
+}
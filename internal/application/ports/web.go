@@ -0,0 +1,22 @@
+package ports
+
+import (
+	"context"
+	"time"
+)
+
+// WebPage is the readable content of a fetched web page.
+type WebPage struct {
+	URL       string    // Final URL after redirects
+	Title     string    // Page title, if any
+	Markdown  string    // Main content as markdown-style text
+	FetchedAt time.Time // When the page was downloaded
+	Cached    bool      // Whether the page was served from the cache
+}
+
+// WebFetcherPort fetches web pages and extracts their main content,
+// dropping navigation, ads and other boilerplate.
+type WebFetcherPort interface {
+	// Fetch downloads the page at url and returns its readable content.
+	Fetch(ctx context.Context, url string) (*WebPage, error)
+}
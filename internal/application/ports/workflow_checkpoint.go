@@ -3,6 +3,7 @@ package ports
 
 import (
 	"context"
+	"errors"
 	"time"
 
 	"github.com/jbctechsolutions/skillrunner/internal/domain/workflow"
@@ -12,6 +13,11 @@ import (
 // Workflow Checkpoint Storage Port
 // -----------------------------------------------------------------------------
 
+// ErrCheckpointConflict is returned by WorkflowCheckpointPort.Update when a
+// store with optimistic locking finds that the checkpoint was changed
+// elsewhere, such as resumed by another machine, since it was last read.
+var ErrCheckpointConflict = errors.New("workflow checkpoint was changed by another machine")
+
 // WorkflowCheckpointFilter defines criteria for querying workflow checkpoints.
 type WorkflowCheckpointFilter struct {
 	// SkillID filters by skill identifier.
@@ -53,6 +59,8 @@ type WorkflowCheckpointPort interface {
 
 	// Get retrieves a checkpoint by its unique identifier.
 	// Returns ErrNotFound if no checkpoint exists with the given ID.
+	// Archived phase outputs are read back; when they cannot be, the
+	// checkpoint has no outputs and its ArchiveError is set.
 	Get(ctx context.Context, id string) (*workflow.WorkflowCheckpoint, error)
 
 	// GetLatestInProgress retrieves the most recent in-progress checkpoint for a skill/input combination.
@@ -61,18 +69,23 @@ type WorkflowCheckpointPort interface {
 	GetLatestInProgress(ctx context.Context, skillID string, inputHash string) (*workflow.WorkflowCheckpoint, error)
 
 	// GetByExecutionID retrieves all checkpoints for an execution.
-	// Returns checkpoints ordered by updatedAt descending.
+	// Returns checkpoints ordered by updatedAt descending, without
+	// archived phase outputs.
 	// Returns an empty slice if no checkpoints exist for the execution.
 	GetByExecutionID(ctx context.Context, executionID string) ([]*workflow.WorkflowCheckpoint, error)
 
 	// Update persists changes to an existing checkpoint.
-	// Used to update batch progress, status, and token counts.
-	// Returns ErrNotFound if the checkpoint does not exist.
+	// Used to update batch progress, status, token counts and the machine
+	// that owns the checkpoint.
+	// Returns ErrNotFound if the checkpoint does not exist, and
+	// ErrCheckpointConflict if the store locks optimistically and the
+	// checkpoint changed since it was last read or written.
 	Update(ctx context.Context, checkpoint *workflow.WorkflowCheckpoint) error
 
 	// List returns all checkpoints matching the optional filter criteria.
 	// Pass nil filter to retrieve all checkpoints.
 	// Results are ordered by updatedAt descending (most recent first).
+	// Archived phase outputs are not read back; Get reads them.
 	List(ctx context.Context, filter *WorkflowCheckpointFilter) ([]*workflow.WorkflowCheckpoint, error)
 
 	// Delete removes a checkpoint from storage.
@@ -81,7 +94,7 @@ type WorkflowCheckpointPort interface {
 
 	// DeleteByExecutionID removes all checkpoints for an execution.
 	// Called after successful completion or explicit abandonment.
-	// Returns the number of checkpoints deleted.
+	// Pinned checkpoints are kept. Returns the number of checkpoints deleted.
 	DeleteByExecutionID(ctx context.Context, executionID string) (int, error)
 
 	// MarkAbandoned marks all in-progress checkpoints for a machine as abandoned.
@@ -89,8 +102,38 @@ type WorkflowCheckpointPort interface {
 	// Returns the number of checkpoints marked as abandoned.
 	MarkAbandoned(ctx context.Context, machineID string) (int, error)
 
+	// SetPinned pins or unpins a checkpoint as a golden run.
+	// Returns ErrNotFound if the checkpoint does not exist.
+	SetPinned(ctx context.Context, id string, pinned bool) error
+
 	// Cleanup removes checkpoints older than the specified duration.
-	// Only removes checkpoints with status completed, failed, or abandoned.
-	// Returns the number of checkpoints removed.
+	// Only removes checkpoints with status completed, failed, or abandoned,
+	// and never pinned ones. Returns the number of checkpoints removed.
 	Cleanup(ctx context.Context, olderThan time.Duration) (int, error)
 }
+
+// PhaseOutputArchiver is implemented by checkpoint stores that can move the
+// phase outputs of old runs into compressed files outside the store, keeping
+// the rest of each checkpoint in place. Get brings archived outputs back;
+// listings leave them out.
+type PhaseOutputArchiver interface {
+	// ArchiveOutputs moves the phase outputs of completed, failed and
+	// abandoned runs created more than olderThan ago into files in dir.
+	// Returns the number of checkpoints archived.
+	ArchiveOutputs(ctx context.Context, olderThan time.Duration, dir string) (int, error)
+}
+
+// -----------------------------------------------------------------------------
+// Run Note Storage Port
+// -----------------------------------------------------------------------------
+
+// RunNotePort stores free-form notes on past runs, kept apart from their
+// checkpoints so that annotating a run never rewrites its history.
+type RunNotePort interface {
+	// Add persists a note.
+	Add(ctx context.Context, note *workflow.RunNote) error
+
+	// List returns the notes on a run, oldest first.
+	// Returns an empty slice if the run has no notes.
+	List(ctx context.Context, runID string) ([]*workflow.RunNote, error)
+}
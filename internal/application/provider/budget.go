@@ -0,0 +1,308 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	adapterProvider "github.com/jbctechsolutions/skillrunner/internal/adapters/provider"
+	"github.com/jbctechsolutions/skillrunner/internal/application/ports"
+	domainProvider "github.com/jbctechsolutions/skillrunner/internal/domain/provider"
+	"github.com/jbctechsolutions/skillrunner/internal/domain/skill"
+	"github.com/jbctechsolutions/skillrunner/internal/infrastructure/config"
+)
+
+// ErrBudgetExceeded is returned, wrapped, when a hard budget cap is reached.
+var ErrBudgetExceeded = errors.New("budget exceeded")
+
+// BudgetWarningHandler is notified, once per cap, when spending reaches the
+// soft limit of a budget cap.
+type BudgetWarningHandler func(msg string)
+
+// budgetPeriod is a calendar period budgets are capped over.
+type budgetPeriod struct {
+	name  string // "daily" or "monthly"
+	cap   func(config.BudgetLimit) float64
+	start func(now time.Time) time.Time
+}
+
+var budgetPeriods = []budgetPeriod{
+	{
+		name: "daily",
+		cap:  func(l config.BudgetLimit) float64 { return l.Daily },
+		start: func(now time.Time) time.Time {
+			y, m, d := now.Date()
+			return time.Date(y, m, d, 0, 0, 0, 0, now.Location())
+		},
+	},
+	{
+		name: "monthly",
+		cap:  func(l config.BudgetLimit) float64 { return l.Monthly },
+		start: func(now time.Time) time.Time {
+			y, m, _ := now.Date()
+			return time.Date(y, m, 1, 0, 0, 0, 0, now.Location())
+		},
+	},
+}
+
+// BudgetGuard tracks cloud provider spending against the caps of a budget
+// configuration. It starts from the spending the cost ledger holds for the
+// current day and month, and adds the costs of the run's completions.
+type BudgetGuard struct {
+	config *config.BudgetConfiguration
+	calc   *domainProvider.CostCalculator
+	onWarn BudgetWarningHandler
+
+	mu       sync.Mutex
+	spent    map[string]map[string]float64 // By period, then provider
+	runCap   float64                       // Cap on the run's own spending; 0 = none
+	runSpent float64
+	warned   map[string]bool // Caps warned about, by description
+}
+
+// NewBudgetGuard creates a guard for cfg. calc prices the run's completions;
+// ledger may be nil, in which case spending starts at zero. onWarn may be nil.
+func NewBudgetGuard(ctx context.Context, cfg *config.BudgetConfiguration, ledger ports.CostLedgerPort, calc *domainProvider.CostCalculator, onWarn BudgetWarningHandler) (*BudgetGuard, error) {
+	g := &BudgetGuard{
+		config: cfg,
+		calc:   calc,
+		onWarn: onWarn,
+		spent:  make(map[string]map[string]float64, len(budgetPeriods)),
+		warned: make(map[string]bool),
+	}
+
+	now := time.Now()
+	for _, period := range budgetPeriods {
+		spent := make(map[string]float64)
+		g.spent[period.name] = spent
+		if ledger == nil {
+			continue
+		}
+		groups, err := ledger.Summarize(ctx, period.start(now), domainProvider.CostByProvider)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s spending: %w", period.name, err)
+		}
+		for _, group := range groups {
+			spent[group.Key] = group.TotalCost
+		}
+	}
+
+	return g, nil
+}
+
+// Add adds the cost of a completion served by a provider.
+func (g *BudgetGuard) Add(providerName string, cost float64) {
+	if cost <= 0 {
+		return
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	for _, spent := range g.spent {
+		spent[providerName] += cost
+	}
+	g.runSpent += cost
+}
+
+// CapRun caps what the guard's cloud providers may cost over the run,
+// counting from zero rather than from the ledger, such as the per-run
+// budget of a project or organization policy. A limit of 0 is no cap.
+func (g *BudgetGuard) CapRun(limit float64) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.runCap = limit
+}
+
+// Check returns an error wrapping ErrBudgetExceeded if the per-run cap, the
+// global caps or the caps of the named provider are reached, and warns about
+// caps past their soft limit.
+func (g *BudgetGuard) Check(providerName string) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	exceeded := g.checkCap("per-run budget", g.runCap, g.runSpent)
+	for _, period := range budgetPeriods {
+		spent := g.spent[period.name]
+
+		var total float64
+		for _, cost := range spent {
+			total += cost
+		}
+		if err := g.checkCap(period.name+" budget", period.cap(g.config.BudgetLimit), total); err != nil && exceeded == nil {
+			exceeded = err
+		}
+
+		if limit := g.config.Providers[providerName]; limit != nil {
+			desc := fmt.Sprintf("%s %s budget", providerName, period.name)
+			if err := g.checkCap(desc, period.cap(*limit), spent[providerName]); err != nil && exceeded == nil {
+				exceeded = err
+			}
+		}
+	}
+	return exceeded
+}
+
+// checkCap compares spending to a cap, warning once when it passes the soft
+// limit. A cap of 0 is no cap.
+func (g *BudgetGuard) checkCap(desc string, limit, spent float64) error {
+	if limit <= 0 {
+		return nil
+	}
+	if spent >= limit {
+		return fmt.Errorf("%w: %s of $%.2f reached ($%.2f spent)", ErrBudgetExceeded, desc, limit, spent)
+	}
+	if spent >= limit*g.config.SoftShare() && !g.warned[desc] {
+		g.warned[desc] = true
+		if g.onWarn != nil {
+			g.onWarn(fmt.Sprintf("%.0f%% of the %s of $%.2f spent ($%.2f)", spent/limit*100, desc, limit, spent))
+		}
+	}
+	return nil
+}
+
+// cost prices a completion.
+func (g *BudgetGuard) cost(req ports.CompletionRequest, resp *ports.CompletionResponse) float64 {
+	if g.calc == nil || resp == nil {
+		return 0
+	}
+	model := resp.ModelUsed
+	if model == "" {
+		model = req.ModelID
+	}
+	return g.calc.CalculateOrZero(model, resp.InputTokens, resp.OutputTokens).TotalCost
+}
+
+// BudgetSelector applies budget caps on top of another selector: phases
+// that would run on a cloud provider whose caps, or the global caps, are
+// reached run on the first healthy local provider instead, or fail when
+// the budget aborts. The cloud providers it returns add their costs to the
+// guard as completions finish, so caps are enforced mid-run.
+type BudgetSelector struct {
+	next     PhaseSelector
+	registry *adapterProvider.Registry
+	guard    *BudgetGuard
+
+	mu     sync.Mutex
+	health map[string]bool // Local provider health, by name
+}
+
+// NewBudgetSelector creates a selector applying guard's caps to the
+// providers next selects.
+func NewBudgetSelector(next PhaseSelector, registry *adapterProvider.Registry, guard *BudgetGuard) *BudgetSelector {
+	return &BudgetSelector{
+		next:     next,
+		registry: registry,
+		guard:    guard,
+		health:   make(map[string]bool),
+	}
+}
+
+// SelectProvider returns the provider next selects, metered when it is a
+// cloud provider, or a local provider once its budget is exhausted.
+func (s *BudgetSelector) SelectProvider(ctx context.Context, phase *skill.Phase, defaultProvider ports.ProviderPort) (ports.ProviderPort, error) {
+	p, err := s.next.SelectProvider(ctx, phase, defaultProvider)
+	if err != nil || p == nil || p.Info().IsLocal {
+		return p, err
+	}
+
+	if err := s.guard.Check(p.Info().Name); err != nil {
+		if s.guard.config.Aborts() {
+			return nil, err
+		}
+		if local := s.localProvider(ctx); local != nil {
+			return local, nil
+		}
+		return nil, fmt.Errorf("%w, and no local provider is available", err)
+	}
+
+	return &meteredProvider{ProviderPort: p, guard: s.guard}, nil
+}
+
+// localProvider returns the first registered local provider that passes a
+// health check, or nil if none does. Health is checked once per provider.
+func (s *BudgetSelector) localProvider(ctx context.Context) ports.ProviderPort {
+	if s.registry == nil {
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, p := range s.registry.GetLocalProviders() {
+		name := p.Info().Name
+		healthy, checked := s.health[name]
+		if !checked {
+			status, err := p.HealthCheck(ctx, "")
+			healthy = err == nil && status != nil && status.Healthy
+			s.health[name] = healthy
+		}
+		if healthy {
+			return p
+		}
+	}
+	return nil
+}
+
+// meteredProvider wraps a cloud provider so that the cost of each completion
+// and stream is added to a budget guard.
+type meteredProvider struct {
+	ports.ProviderPort
+	guard *BudgetGuard
+}
+
+// Unwrap returns the wrapped provider.
+func (p *meteredProvider) Unwrap() ports.ProviderPort {
+	return p.ProviderPort
+}
+
+// Throttled reports whether the wrapped provider is being throttled.
+func (p *meteredProvider) Throttled() bool {
+	t, ok := p.ProviderPort.(ports.ThrottleReporter)
+	return ok && t.Throttled()
+}
+
+// Complete sends the request and adds the cost of its response.
+func (p *meteredProvider) Complete(ctx context.Context, req ports.CompletionRequest) (*ports.CompletionResponse, error) {
+	resp, err := p.ProviderPort.Complete(ctx, req)
+	p.guard.Add(p.Info().Name, p.guard.cost(req, resp))
+	return resp, err
+}
+
+// Stream sends the request and adds the cost of its response.
+func (p *meteredProvider) Stream(ctx context.Context, req ports.CompletionRequest, cb ports.StreamCallback) (*ports.CompletionResponse, error) {
+	resp, err := p.ProviderPort.Stream(ctx, req, cb)
+	p.guard.Add(p.Info().Name, p.guard.cost(req, resp))
+	return resp, err
+}
+
+// As offers the batch API of the wrapped provider with the cost of each
+// item added to the guard as results are collected; other capabilities are
+// those of the wrapped provider.
+func (p *meteredProvider) As(target any) bool {
+	t, ok := target.(*ports.BatchProviderPort)
+	if !ok {
+		return ports.AsCapability(p.ProviderPort, target)
+	}
+	b, ok := ports.As[ports.BatchProviderPort](p.ProviderPort)
+	if ok {
+		*t = &meteredBatcher{BatchProviderPort: b, provider: p.Info().Name, guard: p.guard}
+	}
+	return ok
+}
+
+// meteredBatcher adds the costs of batch items to a budget guard.
+type meteredBatcher struct {
+	ports.BatchProviderPort
+	provider string
+	guard    *BudgetGuard
+}
+
+// GetBatchResults returns the results of a job and adds their costs.
+func (b *meteredBatcher) GetBatchResults(ctx context.Context, jobID string) ([]ports.BatchItemResult, error) {
+	results, err := b.BatchProviderPort.GetBatchResults(ctx, jobID)
+	for _, res := range results {
+		b.guard.Add(b.provider, b.guard.cost(ports.CompletionRequest{}, res.Response))
+	}
+	return results, err
+}
@@ -0,0 +1,187 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	adapterProvider "github.com/jbctechsolutions/skillrunner/internal/adapters/provider"
+	"github.com/jbctechsolutions/skillrunner/internal/application/ports"
+	domainProvider "github.com/jbctechsolutions/skillrunner/internal/domain/provider"
+	"github.com/jbctechsolutions/skillrunner/internal/infrastructure/config"
+)
+
+// fixedLedger is a cost ledger whose every summary holds the same groups.
+type fixedLedger struct {
+	groups []domainProvider.CostGroup
+}
+
+func (l *fixedLedger) Record(context.Context, *domainProvider.CostRecord) error { return nil }
+
+func (l *fixedLedger) Summarize(context.Context, time.Time, domainProvider.CostGroupBy) ([]domainProvider.CostGroup, error) {
+	return l.groups, nil
+}
+
+// budgetFixture is a budget selector over a cloud default provider and a
+// registered local provider. Each completion of "big" costs $0.50.
+type budgetFixture struct {
+	selector *BudgetSelector
+	cloud    *mockProvider
+	warnings []string
+}
+
+func newBudgetFixture(t *testing.T, cfg *config.BudgetConfiguration, spent float64) *budgetFixture {
+	t.Helper()
+	f := &budgetFixture{cloud: newMockProvider("anthropic")}
+	local := newMockProvider("ollama").withLocal(true)
+
+	registry := adapterProvider.NewRegistry()
+	for _, p := range []*mockProvider{local, f.cloud} {
+		if err := registry.Register(p); err != nil {
+			t.Fatalf("Register failed: %v", err)
+		}
+	}
+
+	calc := domainProvider.NewCostCalculator()
+	calc.RegisterModel("big", 0, 25)
+	ledger := &fixedLedger{groups: []domainProvider.CostGroup{{Key: "anthropic", TotalCost: spent}}}
+	guard, err := NewBudgetGuard(context.Background(), cfg, ledger, calc, func(msg string) {
+		f.warnings = append(f.warnings, msg)
+	})
+	if err != nil {
+		t.Fatalf("NewBudgetGuard failed: %v", err)
+	}
+	f.selector = NewBudgetSelector(NewPinSelector(registry, nil), registry, guard)
+	return f
+}
+
+// run selects a provider for a phase and completes a request with it.
+func (f *budgetFixture) run(t *testing.T) (ports.ProviderPort, error) {
+	t.Helper()
+	p, err := f.selector.SelectProvider(context.Background(), newPinnedPhase(t, "", false), f.cloud)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := p.Complete(context.Background(), ports.CompletionRequest{ModelID: "big"}); err != nil {
+		t.Fatalf("Complete failed: %v", err)
+	}
+	return p, nil
+}
+
+func TestBudgetSelector_MovesToLocalWhenCapReached(t *testing.T) {
+	cfg := &config.BudgetConfiguration{
+		Providers: map[string]*config.BudgetLimit{"anthropic": {Daily: 5}},
+	}
+	f := newBudgetFixture(t, cfg, 3.8)
+
+	// $3.80 spent: under the soft limit of $4
+	if p, err := f.run(t); err != nil || p.Info().Name != "anthropic" {
+		t.Fatalf("first phase = %v, %v; want anthropic", p, err)
+	}
+	if len(f.warnings) != 0 {
+		t.Errorf("warnings = %v, want none below the soft limit", f.warnings)
+	}
+
+	// $4.30 spent: warns once, still runs on the cloud
+	for range 2 {
+		if p, err := f.run(t); err != nil || p.Info().Name != "anthropic" {
+			t.Fatalf("phase = %v, %v; want anthropic", p, err)
+		}
+	}
+	if len(f.warnings) != 1 || !strings.Contains(f.warnings[0], "anthropic daily budget") {
+		t.Errorf("warnings = %v, want one soft limit warning", f.warnings)
+	}
+
+	// $5.30 spent: the cap is reached mid-run
+	if p, err := f.run(t); err != nil || p.Info().Name != "ollama" {
+		t.Errorf("phase past the cap = %v, %v; want ollama", p, err)
+	}
+}
+
+func TestBudgetSelector_Abort(t *testing.T) {
+	cfg := &config.BudgetConfiguration{
+		BudgetLimit: config.BudgetLimit{Monthly: 100},
+		OnExceed:    config.BudgetExceedAbort,
+	}
+	f := newBudgetFixture(t, cfg, 100)
+
+	if _, err := f.run(t); !errors.Is(err, ErrBudgetExceeded) {
+		t.Errorf("SelectProvider error = %v, want ErrBudgetExceeded", err)
+	}
+}
+
+func TestBudgetSelector_RunCap(t *testing.T) {
+	// $50 already spent today counts towards the daily cap, not the run's
+	f := newBudgetFixture(t, &config.BudgetConfiguration{}, 50)
+	f.selector.guard.CapRun(0.9)
+
+	// $0.50, then $1.00 spent by the run: the cap is reached mid-run
+	for range 2 {
+		if p, err := f.run(t); err != nil || p.Info().Name != "anthropic" {
+			t.Fatalf("phase = %v, %v; want anthropic", p, err)
+		}
+	}
+	if p, err := f.run(t); err != nil || p.Info().Name != "ollama" {
+		t.Errorf("phase past the run cap = %v, %v; want ollama", p, err)
+	}
+}
+
+func TestBudgetSelector_LocalProvidersAreNotCapped(t *testing.T) {
+	cfg := &config.BudgetConfiguration{
+		BudgetLimit: config.BudgetLimit{Daily: 1},
+		OnExceed:    config.BudgetExceedAbort,
+	}
+	f := newBudgetFixture(t, cfg, 10)
+
+	local := newMockProvider("ollama").withLocal(true)
+	p, err := f.selector.SelectProvider(context.Background(), newPinnedPhase(t, "", false), local)
+	if err != nil || p != local {
+		t.Errorf("SelectProvider = %v, %v; want the local provider unwrapped", p, err)
+	}
+}
+
+// batchMockProvider is a mock provider with a batch API whose every item
+// costs as much as a completion of "big".
+type batchMockProvider struct {
+	*mockProvider
+}
+
+func (b *batchMockProvider) SubmitBatch(context.Context, []ports.BatchItem) (*ports.BatchJob, error) {
+	return &ports.BatchJob{ID: "job-1"}, nil
+}
+
+func (b *batchMockProvider) GetBatch(context.Context, string) (*ports.BatchJob, error) {
+	return &ports.BatchJob{ID: "job-1", Status: ports.BatchJobCompleted}, nil
+}
+
+func (b *batchMockProvider) GetBatchResults(context.Context, string) ([]ports.BatchItemResult, error) {
+	resp := &ports.CompletionResponse{InputTokens: 10, OutputTokens: 20, ModelUsed: "big"}
+	return []ports.BatchItemResult{{CustomID: "a", Response: resp}, {CustomID: "b", Response: resp}}, nil
+}
+
+func TestBudgetSelector_MetersBatchJobs(t *testing.T) {
+	cfg := &config.BudgetConfiguration{
+		Providers: map[string]*config.BudgetLimit{"anthropic": {Daily: 5}},
+	}
+	f := newBudgetFixture(t, cfg, 4.2)
+	cloud := &batchMockProvider{mockProvider: f.cloud}
+
+	p, err := f.selector.SelectProvider(context.Background(), newPinnedPhase(t, "", false), cloud)
+	if err != nil || p.Info().Name != "anthropic" {
+		t.Fatalf("SelectProvider = %v, %v; want anthropic", p, err)
+	}
+	batcher, ok := ports.As[ports.BatchProviderPort](p)
+	if !ok {
+		t.Fatal("As() did not find the batch API of the metered provider")
+	}
+	if _, err := batcher.GetBatchResults(context.Background(), "job-1"); err != nil {
+		t.Fatal(err)
+	}
+
+	// $5.20 spent: the two batch items reached the cap
+	if p, err := f.selector.SelectProvider(context.Background(), newPinnedPhase(t, "", false), cloud); err != nil || p.Info().Name != "ollama" {
+		t.Errorf("phase past the cap = %v, %v; want ollama", p, err)
+	}
+}
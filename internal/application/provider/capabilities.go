@@ -0,0 +1,201 @@
+package provider
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/jbctechsolutions/skillrunner/internal/infrastructure/config"
+)
+
+// ErrNoCapableModel is returned when no available model has the
+// capabilities a request requires. It is wrapped by *CapabilityError.
+var ErrNoCapableModel = errors.New("no model has the required capabilities")
+
+// maxCapabilityCandidates is how many near-miss models a CapabilityError lists.
+const maxCapabilityCandidates = 5
+
+// CapabilityCandidate is a configured model that comes close to a
+// capability requirement: it can serve requests but lacks some
+// capabilities, or has them all but cannot serve requests.
+type CapabilityCandidate struct {
+	Provider         string
+	Model            string
+	Missing          []string // required capabilities the model does not declare
+	ProviderDisabled bool
+	ModelDisabled    bool
+	Unavailable      bool // enabled, but its provider is not registered or does not serve it
+}
+
+// Usable reports whether the model can serve requests as configured.
+func (c CapabilityCandidate) Usable() bool {
+	return !c.ProviderDisabled && !c.ModelDisabled && !c.Unavailable
+}
+
+// CapabilityError reports that no model satisfies a capability requirement.
+// It lists the configured models that came closest and how to fix the
+// configuration, and matches both ErrNoCapableModel and ErrNoFallbackModel.
+type CapabilityError struct {
+	Profile      string
+	Capabilities []string
+	Candidates   []CapabilityCandidate // closest first
+}
+
+func (e *CapabilityError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s: %s (profile %s)", ErrNoCapableModel, strings.Join(e.Capabilities, ", "), e.Profile)
+	if len(e.Candidates) > 0 {
+		b.WriteString("\nclosest configured models:")
+		for _, c := range e.Candidates {
+			fmt.Fprintf(&b, "\n  %s/%s: %s", c.Provider, c.Model, c.describe())
+		}
+	}
+	b.WriteString("\nto fix:")
+	for _, hint := range e.Hints() {
+		fmt.Fprintf(&b, "\n  - %s", hint)
+	}
+	return b.String()
+}
+
+func (e *CapabilityError) Unwrap() []error {
+	return []error{ErrNoCapableModel, ErrNoFallbackModel}
+}
+
+// Hints suggests configuration changes that would satisfy the requirement.
+func (e *CapabilityError) Hints() []string {
+	var hints []string
+	for _, c := range e.Candidates {
+		name := c.Provider + "/" + c.Model
+		switch {
+		case len(c.Missing) > 0:
+			hints = append(hints, fmt.Sprintf("if %s supports %s, add it to the model's capabilities in routing.yaml",
+				name, strings.Join(c.Missing, ", ")))
+		case c.ProviderDisabled:
+			hints = append(hints, fmt.Sprintf("enable provider %s in routing.yaml", c.Provider))
+		case c.ModelDisabled:
+			hints = append(hints, fmt.Sprintf("enable model %s in routing.yaml", name))
+		case c.Unavailable:
+			hints = append(hints, fmt.Sprintf("make %s reachable: check the provider's API key or that it is running and has the model (sr status)", name))
+		}
+	}
+	hints = append(hints, fmt.Sprintf("add a model with capabilities [%s] to a provider in routing.yaml",
+		strings.Join(e.Capabilities, ", ")))
+	return hints
+}
+
+func (c CapabilityCandidate) describe() string {
+	switch {
+	case len(c.Missing) > 0:
+		return "missing " + strings.Join(c.Missing, ", ")
+	case c.ProviderDisabled:
+		return "has every capability, but the provider is disabled"
+	case c.ModelDisabled:
+		return "has every capability, but the model is disabled"
+	default:
+		return "has every capability, but is not available"
+	}
+}
+
+// hasCapabilities reports whether the configuration of the selected model
+// declares every required capability.
+func (r *Router) hasCapabilities(selection *ModelSelection, required []string) bool {
+	if selection == nil {
+		return false
+	}
+	return hasAllCapabilities(r.GetModelConfig(selection.ProviderName, selection.ModelID), required)
+}
+
+// findCapableModel returns the first available model, in provider priority
+// order, whose configuration declares every required capability, or nil.
+func (r *Router) findCapableModel(ctx context.Context, required []string) *ModelSelection {
+	r.mu.RLock()
+	providerNames := r.config.GetEnabledProviders()
+	providers := r.config.Providers
+	r.mu.RUnlock()
+
+	for _, providerName := range providerNames {
+		providerConfig := providers[providerName]
+		if providerConfig == nil {
+			continue
+		}
+		for _, modelID := range slices.Sorted(maps.Keys(providerConfig.Models)) {
+			modelConfig := providerConfig.Models[modelID]
+			if !modelConfig.Enabled || !hasAllCapabilities(modelConfig, required) {
+				continue
+			}
+			if providerFound, available := r.findAvailableProvider(ctx, modelID); available {
+				return &ModelSelection{ModelID: modelID, ProviderName: providerFound}
+			}
+		}
+	}
+	return nil
+}
+
+// capabilityError builds a CapabilityError listing the configured models
+// closest to the requirement: models with every capability that are
+// disabled or unavailable, then usable models by fewest missing capabilities.
+func (r *Router) capabilityError(ctx context.Context, profile string, required []string) *CapabilityError {
+	r.mu.RLock()
+	providers := r.config.Providers
+	r.mu.RUnlock()
+
+	var candidates []CapabilityCandidate
+	for _, providerName := range slices.Sorted(maps.Keys(providers)) {
+		providerConfig := providers[providerName]
+		if providerConfig == nil {
+			continue
+		}
+		for _, modelID := range slices.Sorted(maps.Keys(providerConfig.Models)) {
+			modelConfig := providerConfig.Models[modelID]
+			if modelConfig == nil || isEmbeddingModel(modelID) {
+				continue
+			}
+			c := CapabilityCandidate{
+				Provider:         providerName,
+				Model:            modelID,
+				Missing:          missingCapabilities(modelConfig, required),
+				ProviderDisabled: !providerConfig.Enabled,
+				ModelDisabled:    !modelConfig.Enabled,
+			}
+			if c.Usable() {
+				_, available := r.findAvailableProvider(ctx, modelID)
+				c.Unavailable = !available
+			}
+			// A usable model with every capability would have been selected,
+			// and one that is off and lacks some is not close
+			if c.Usable() == (len(c.Missing) == 0) {
+				continue
+			}
+			candidates = append(candidates, c)
+		}
+	}
+
+	slices.SortStableFunc(candidates, func(a, b CapabilityCandidate) int {
+		return cmp.Compare(len(a.Missing), len(b.Missing))
+	})
+	if len(candidates) > maxCapabilityCandidates {
+		candidates = candidates[:maxCapabilityCandidates]
+	}
+
+	return &CapabilityError{
+		Profile:      profile,
+		Capabilities: slices.Clone(required),
+		Candidates:   candidates,
+	}
+}
+
+// missingCapabilities returns the required capabilities the model does not
+// declare.
+func missingCapabilities(model *config.ModelConfiguration, required []string) []string {
+	var missing []string
+	for _, c := range required {
+		if !model.HasCapability(c) {
+			missing = append(missing, c)
+		}
+	}
+	return missing
+}
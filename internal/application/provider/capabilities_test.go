@@ -0,0 +1,120 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	adapterProvider "github.com/jbctechsolutions/skillrunner/internal/adapters/provider"
+	"github.com/jbctechsolutions/skillrunner/internal/domain/skill"
+)
+
+func newCapabilityPhase(t *testing.T, capabilities ...string) *skill.Phase {
+	t.Helper()
+	phase, err := skill.NewPhase("describe", "Describe", "Describe {{.input}}")
+	if err != nil {
+		t.Fatalf("NewPhase() error = %v", err)
+	}
+	return phase.WithRoutingProfile(skill.ProfileBalanced).WithCapabilities(capabilities...)
+}
+
+func TestSelectModelForPhase_Capabilities(t *testing.T) {
+	t.Run("keeps a profile model with the capabilities", func(t *testing.T) {
+		registry := adapterProvider.NewRegistry()
+		_ = registry.Register(newMockProvider("ollama").withModels("llama3.2:8b"))
+		router, _ := NewRouter(newTestRoutingConfig(), registry)
+
+		selection, err := router.SelectModelForPhase(context.Background(), newCapabilityPhase(t, "code"))
+		if err != nil {
+			t.Fatalf("SelectModelForPhase() error = %v", err)
+		}
+		if selection.ModelID != "llama3.2:8b" || selection.IsFallback {
+			t.Errorf("SelectModelForPhase() = %+v, want llama3.2:8b", selection)
+		}
+	})
+
+	t.Run("switches to a capable model", func(t *testing.T) {
+		registry := adapterProvider.NewRegistry()
+		_ = registry.Register(newMockProvider("ollama").withModels("llama3.2:8b"))
+		_ = registry.Register(newMockProvider("openai").withModels("gpt-4o"))
+		router, _ := NewRouter(newTestRoutingConfig(), registry)
+
+		selection, err := router.SelectModelForPhase(context.Background(), newCapabilityPhase(t, "vision"))
+		if err != nil {
+			t.Fatalf("SelectModelForPhase() error = %v", err)
+		}
+		if selection.ModelID != "gpt-4o" || !selection.IsFallback {
+			t.Errorf("SelectModelForPhase() = %+v, want gpt-4o as a fallback", selection)
+		}
+	})
+
+	t.Run("lists the closest models when none qualifies", func(t *testing.T) {
+		cfg := newTestRoutingConfig()
+		cfg.Providers["openai"].Enabled = false
+		registry := adapterProvider.NewRegistry()
+		_ = registry.Register(newMockProvider("ollama").withModels("llama3.2:3b", "llama3.2:8b"))
+		_ = registry.Register(newMockProvider("anthropic").withModels("claude-3-5-sonnet-20241022"))
+		router, _ := NewRouter(cfg, registry)
+
+		_, err := router.SelectModelForPhase(context.Background(), newCapabilityPhase(t, "vision", "function_calling"))
+		if !errors.Is(err, ErrNoCapableModel) || !errors.Is(err, ErrNoFallbackModel) {
+			t.Fatalf("SelectModelForPhase() error = %v, want ErrNoCapableModel", err)
+		}
+
+		var capErr *CapabilityError
+		if !errors.As(err, &capErr) {
+			t.Fatalf("SelectModelForPhase() error = %T, want *CapabilityError", err)
+		}
+		var got []string
+		for _, c := range capErr.Candidates {
+			got = append(got, c.Provider+"/"+c.Model+": "+c.describe())
+		}
+		want := []string{
+			"openai/gpt-4o: has every capability, but the provider is disabled",
+			"anthropic/claude-3-5-sonnet-20241022: missing function_calling",
+			"ollama/llama3.2:3b: missing vision, function_calling",
+			"ollama/llama3.2:8b: missing vision, function_calling",
+		}
+		if !slices.Equal(got, want) {
+			t.Errorf("Candidates = %q, want %q", got, want)
+		}
+
+		msg := err.Error()
+		for _, s := range []string{
+			"enable provider openai in routing.yaml",
+			"if anthropic/claude-3-5-sonnet-20241022 supports function_calling, add it",
+			"add a model with capabilities [vision, function_calling]",
+		} {
+			if !strings.Contains(msg, s) {
+				t.Errorf("error should contain %q:\n%s", s, msg)
+			}
+		}
+	})
+
+	t.Run("ignores capabilities of pinned phases", func(t *testing.T) {
+		registry := adapterProvider.NewRegistry()
+		_ = registry.Register(newMockProvider("ollama").withModels("llama3.2:8b"))
+		router, _ := NewRouter(newTestRoutingConfig(), registry)
+
+		phase := newCapabilityPhase(t, "vision").WithModel("llama3.2:8b")
+		selection, err := router.SelectModelForPhase(context.Background(), phase)
+		if err != nil || selection.ModelID != "llama3.2:8b" {
+			t.Errorf("SelectModelForPhase() = %+v, %v, want the pinned model", selection, err)
+		}
+	})
+}
+
+func TestSelectModelWithCapabilities_NoModel(t *testing.T) {
+	router, _ := NewRouter(newTestRoutingConfig(), adapterProvider.NewRegistry())
+
+	_, err := router.SelectModelWithCapabilities(context.Background(), skill.ProfileBalanced, []string{"vision"})
+	var capErr *CapabilityError
+	if !errors.As(err, &capErr) {
+		t.Fatalf("SelectModelWithCapabilities() error = %v, want *CapabilityError", err)
+	}
+	if len(capErr.Candidates) != 2 || !capErr.Candidates[0].Unavailable {
+		t.Errorf("Candidates = %+v, want the unavailable anthropic and openai models", capErr.Candidates)
+	}
+}
@@ -0,0 +1,86 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/jbctechsolutions/skillrunner/internal/application/ports"
+)
+
+// CountTokens returns the input tokens of req with the tokenizer of the
+// selected provider: its counting API if it has one, tiktoken for OpenAI
+// and compatible hosts, and a heuristic for local models.
+func (r *Router) CountTokens(ctx context.Context, selection *ModelSelection, req ports.CompletionRequest) (int, error) {
+	if selection == nil {
+		return 0, fmt.Errorf("model selection is nil")
+	}
+	counter := r.counters.ForProvider(r.registry.Get(selection.ProviderName))
+	return counter.CountTokens(ctx, req)
+}
+
+// ContextWindow returns the context window configured for the selected
+// model, or 0 if the model is not configured.
+func (r *Router) ContextWindow(selection *ModelSelection) int {
+	if selection == nil {
+		return 0
+	}
+	if model := r.GetModelConfig(selection.ProviderName, selection.ModelID); model != nil {
+		return model.ContextWindow
+	}
+	return 0
+}
+
+// CheckContextWindow returns an error wrapping ErrContextWindowExceeded when
+// the input tokens of req plus its MaxTokens do not fit the selected model's
+// context window. Models without a configured window are not checked.
+func (r *Router) CheckContextWindow(ctx context.Context, selection *ModelSelection, req ports.CompletionRequest) error {
+	window := r.ContextWindow(selection)
+	if window <= 0 {
+		return nil
+	}
+
+	tokens, err := r.CountTokens(ctx, selection, req)
+	if err != nil {
+		return fmt.Errorf("failed to count tokens: %w", err)
+	}
+	if tokens+req.MaxTokens > window {
+		return fmt.Errorf("%w: %d input tokens + %d output tokens exceed the %d tokens of %s",
+			ErrContextWindowExceeded, tokens, req.MaxTokens, window, selection.ModelID)
+	}
+	return nil
+}
+
+// SplitText cuts text into parts of at most maxChars bytes, preferring
+// paragraph, then line, then word boundaries.
+func SplitText(text string, maxChars int) []string {
+	var parts []string
+	for len(text) > maxChars {
+		cut := cutPoint(text, maxChars)
+		parts = append(parts, strings.TrimSpace(text[:cut]))
+		text = strings.TrimLeft(text[cut:], " \n")
+	}
+	if text = strings.TrimSpace(text); text != "" {
+		parts = append(parts, text)
+	}
+	return parts
+}
+
+// cutPoint returns where to end a part of text of at most maxChars bytes:
+// after the last paragraph break, line break or space in its second half,
+// or else at the last rune boundary.
+func cutPoint(text string, maxChars int) int {
+	prefix := text[:maxChars]
+	for _, sep := range []string{"\n\n", "\n", " "} {
+		if i := strings.LastIndex(prefix, sep); i >= maxChars/2 {
+			return i + len(sep)
+		}
+	}
+
+	cut := maxChars
+	for cut > 1 && !utf8.RuneStart(text[cut]) {
+		cut--
+	}
+	return cut
+}
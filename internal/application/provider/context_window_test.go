@@ -0,0 +1,88 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	adapterProvider "github.com/jbctechsolutions/skillrunner/internal/adapters/provider"
+	"github.com/jbctechsolutions/skillrunner/internal/application/ports"
+)
+
+// countingProvider is a mockProvider with a token counting API.
+type countingProvider struct {
+	*mockProvider
+	tokens int
+}
+
+func (p *countingProvider) CountTokens(context.Context, ports.CompletionRequest) (int, error) {
+	return p.tokens, nil
+}
+
+func newContextWindowRouter(t *testing.T) *Router {
+	t.Helper()
+	registry := adapterProvider.NewRegistry()
+	_ = registry.Register(newMockProvider("ollama").withModels("llama3.2:3b"))
+	_ = registry.Register(&countingProvider{mockProvider: newMockProvider("anthropic"), tokens: 250000})
+
+	router, err := NewRouter(newTestRoutingConfig(), registry)
+	if err != nil {
+		t.Fatalf("NewRouter() error = %v", err)
+	}
+	return router
+}
+
+func TestCheckContextWindow(t *testing.T) {
+	router := newContextWindowRouter(t)
+	ctx := context.Background()
+	local := &ModelSelection{ModelID: "llama3.2:3b", ProviderName: "ollama"}
+
+	small := ports.CompletionRequest{MaxTokens: 1000, Messages: []ports.Message{{Role: "user", Content: "Hello"}}}
+	if err := router.CheckContextWindow(ctx, local, small); err != nil {
+		t.Errorf("small request: unexpected error %v", err)
+	}
+
+	// ~4 characters per token with the heuristic: 40k characters exceed 8192 tokens
+	large := ports.CompletionRequest{MaxTokens: 1000, Messages: []ports.Message{{Role: "user", Content: strings.Repeat("word ", 8000)}}}
+	if err := router.CheckContextWindow(ctx, local, large); !errors.Is(err, ErrContextWindowExceeded) {
+		t.Errorf("large request: error = %v, want ErrContextWindowExceeded", err)
+	}
+
+	// The provider's counting API is preferred over local estimates
+	remote := &ModelSelection{ModelID: "claude-3-5-sonnet-20241022", ProviderName: "anthropic"}
+	if err := router.CheckContextWindow(ctx, remote, small); !errors.Is(err, ErrContextWindowExceeded) {
+		t.Errorf("counted request: error = %v, want ErrContextWindowExceeded", err)
+	}
+
+	// Unconfigured models are not checked
+	unknown := &ModelSelection{ModelID: "mystery", ProviderName: "ollama"}
+	if err := router.CheckContextWindow(ctx, unknown, large); err != nil {
+		t.Errorf("unconfigured model: unexpected error %v", err)
+	}
+}
+
+func TestSplitText(t *testing.T) {
+	paragraph := strings.TrimSpace(strings.Repeat("lorem ipsum ", 100))
+	content := strings.TrimSpace(strings.Repeat(paragraph+"\n\n", 40))
+
+	parts := SplitText(content, 3000)
+	if len(parts) < 2 {
+		t.Fatalf("expected the text to be split, got %d part(s)", len(parts))
+	}
+	for i, part := range parts {
+		if len(part) > 3000 {
+			t.Errorf("part %d has %d bytes, want at most 3000", i, len(part))
+		}
+		if strings.HasSuffix(part, "lorem") {
+			t.Errorf("part %d was cut mid-paragraph", i)
+		}
+	}
+	if strings.Join(parts, "\n\n") != content {
+		t.Error("parts do not add up to the original text")
+	}
+
+	if parts := SplitText("Hi", 3000); len(parts) != 1 || parts[0] != "Hi" {
+		t.Errorf("short text = %q, want one part", parts)
+	}
+}
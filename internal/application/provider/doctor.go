@@ -0,0 +1,239 @@
+package provider
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/jbctechsolutions/skillrunner/internal/domain/provider"
+	"github.com/jbctechsolutions/skillrunner/internal/infrastructure/config"
+)
+
+// DoctorSeverity grades a configuration check.
+type DoctorSeverity string
+
+const (
+	DoctorOK      DoctorSeverity = "ok"
+	DoctorWarning DoctorSeverity = "warning"
+	DoctorError   DoctorSeverity = "error"
+)
+
+// maxDoctorModelHints is how many served models a check lists when a
+// configured model has no close match.
+const maxDoctorModelHints = 5
+
+// DoctorCheck is the outcome of one configuration check, with the change that
+// would fix it when it failed.
+type DoctorCheck struct {
+	Subject  string         `json:"subject"` // e.g. "provider ollama", "profile balanced"
+	Severity DoctorSeverity `json:"severity"`
+	Message  string         `json:"message"`
+	Fix      string         `json:"fix,omitempty"`
+}
+
+// DoctorReport is the result of diagnosing a routing configuration against
+// the providers it is served by.
+type DoctorReport struct {
+	Checks []DoctorCheck `json:"checks"`
+}
+
+// Count returns the number of checks with the given severity.
+func (r *DoctorReport) Count(severity DoctorSeverity) int {
+	n := 0
+	for _, c := range r.Checks {
+		if c.Severity == severity {
+			n++
+		}
+	}
+	return n
+}
+
+// Subjects returns the subjects starting with prefix, such as "profile ",
+// split into those with an error and those without, each in report order.
+func (r *DoctorReport) Subjects(prefix string) (passing, failing []string) {
+	failed := make(map[string]bool)
+	var subjects []string
+	for _, c := range r.Checks {
+		if !strings.HasPrefix(c.Subject, prefix) {
+			continue
+		}
+		if _, seen := failed[c.Subject]; !seen {
+			subjects = append(subjects, c.Subject)
+		}
+		failed[c.Subject] = failed[c.Subject] || c.Severity == DoctorError
+	}
+	for _, subject := range subjects {
+		if failed[subject] {
+			failing = append(failing, subject)
+		} else {
+			passing = append(passing, subject)
+		}
+	}
+	return passing, failing
+}
+
+func (r *DoctorReport) add(subject string, severity DoctorSeverity, message, fix string) {
+	r.Checks = append(r.Checks, DoctorCheck{Subject: subject, Severity: severity, Message: message, Fix: fix})
+}
+
+// Diagnose checks a routing configuration against the health of the
+// registered providers, as returned by Initializer.CheckHealth: that the
+// configuration is valid, that every enabled provider is registered and
+// responds, and that every model a profile references is served by a
+// healthy provider.
+func Diagnose(rc *config.RoutingConfiguration, health map[string]*ProviderHealth) *DoctorReport {
+	report := &DoctorReport{}
+	if rc == nil {
+		rc = config.NewRoutingConfiguration()
+	}
+
+	if err := rc.Validate(); err != nil {
+		report.add("configuration", DoctorError, err.Error(), "correct the routing configuration; 'sr config show --provenance' shows which file sets each value")
+	} else {
+		report.add("configuration", DoctorOK, "routing configuration is valid", "")
+	}
+
+	diagnoseProviders(report, rc, health)
+	diagnoseProfiles(report, rc, health)
+	return report
+}
+
+func diagnoseProviders(report *DoctorReport, rc *config.RoutingConfiguration, health map[string]*ProviderHealth) {
+	names := make(map[string]bool)
+	for _, name := range rc.GetEnabledProviders() {
+		names[name] = true
+	}
+	for name, h := range health {
+		if h != nil && h.Enabled {
+			names[name] = true
+		}
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(names)) {
+		subject := "provider " + name
+		h := health[name]
+		switch {
+		case h == nil || !h.Enabled:
+			report.add(subject, DoctorError, "enabled in routing configuration but not registered",
+				fmt.Sprintf("set its API key (api_key_env, or 'sr auth login %s') or disable it", name))
+		case !h.Healthy:
+			report.add(subject, DoctorError, "not responding: "+h.Error, unhealthyProviderFix(name, h))
+		default:
+			report.add(subject, DoctorOK, fmt.Sprintf("responding in %s, serving %d model(s)", h.Latency.Round(time.Millisecond), len(h.Models)), "")
+		}
+	}
+}
+
+func unhealthyProviderFix(name string, h *ProviderHealth) string {
+	switch {
+	case name == provider.ProviderOllama:
+		return fmt.Sprintf("start Ollama with 'ollama serve' or correct its URL (%s)", h.Endpoint)
+	case h.Type == "local":
+		return fmt.Sprintf("start the server at %s or correct its base_url", h.Endpoint)
+	default:
+		return fmt.Sprintf("check the API key and base_url of %s", name)
+	}
+}
+
+// profileModel is a model a profile references and the key referencing it.
+type profileModel struct {
+	key, model string
+}
+
+func diagnoseProfiles(report *DoctorReport, rc *config.RoutingConfiguration, health map[string]*ProviderHealth) {
+	// Models served by healthy providers, and the providers serving each
+	served := make(map[string][]string)
+	for name, h := range health {
+		if h != nil && h.Enabled && h.Healthy {
+			for _, m := range h.Models {
+				served[m] = append(served[m], name)
+			}
+		}
+	}
+	known := slices.Sorted(maps.Keys(served))
+
+	for _, name := range slices.Sorted(maps.Keys(rc.Profiles)) {
+		profile := rc.Profiles[name]
+		if profile == nil {
+			continue
+		}
+		subject := "profile " + name
+
+		var roles []profileModel
+		for _, m := range profile.GenerationModelIDs() {
+			roles = append(roles, profileModel{"generation_model", m})
+		}
+		roles = append(roles,
+			profileModel{"review_model", profile.ReviewModel},
+			profileModel{"fallback_model", profile.FallbackModel},
+		)
+
+		for _, role := range roles {
+			if role.model == "" {
+				continue
+			}
+			if providers, ok := served[role.model]; ok {
+				slices.Sort(providers)
+				report.add(subject, DoctorOK, fmt.Sprintf("%s %s is served by %s", role.key, role.model, strings.Join(providers, ", ")), "")
+				continue
+			}
+			// Runs still work without their fallback model, only less reliably
+			severity := DoctorError
+			if role.key == "fallback_model" {
+				severity = DoctorWarning
+			}
+			report.add(subject, severity, fmt.Sprintf("%s %s is not served by any responding provider", role.key, role.model),
+				missingModelFix(rc, health, name, role.key, role.model, known))
+		}
+	}
+}
+
+// missingModelFix suggests how to make a profile's model available: repair
+// or pull it on the provider that declares it, or switch to the closest
+// model that is served.
+func missingModelFix(rc *config.RoutingConfiguration, health map[string]*ProviderHealth, profile, key, model string, known []string) string {
+	for _, name := range slices.Sorted(maps.Keys(rc.Providers)) {
+		cfg := rc.Providers[name]
+		if cfg == nil || cfg.GetModel(model) == nil {
+			continue
+		}
+		h := health[name]
+		switch {
+		case !cfg.Enabled:
+			return fmt.Sprintf("enable provider %s, which declares %s", name, model)
+		case h == nil || !h.Healthy:
+			return fmt.Sprintf("fix provider %s above, which declares %s", name, model)
+		case name == provider.ProviderOllama:
+			return fmt.Sprintf("pull the model with 'ollama pull %s'", model)
+		default:
+			return fmt.Sprintf("provider %s declares %s but does not serve it; check the model ID", name, model)
+		}
+	}
+
+	if closest := closestModel(model, known); closest != "" {
+		return fmt.Sprintf("set profiles.%s.%s to %s", profile, key, closest)
+	}
+	if len(known) == 0 {
+		return "no provider is serving models; fix the providers above"
+	}
+	hints := known[:min(len(known), maxDoctorModelHints)]
+	return fmt.Sprintf("set profiles.%s.%s to a served model, e.g. %s", profile, key, strings.Join(hints, ", "))
+}
+
+// closestModel returns the served model most likely meant by model: one that
+// extends it or that it extends (a dated snapshot or size tag), or else one
+// within a few edits of it. Returns "" when none is close.
+func closestModel(model string, known []string) string {
+	best, bestDist := "", max(3, len(model)/4)+1
+	for _, k := range known {
+		if strings.HasPrefix(k, model) || strings.HasPrefix(model, k) {
+			return k
+		}
+		if d := config.EditDistance(model, k); d < bestDist {
+			best, bestDist = k, d
+		}
+	}
+	return best
+}
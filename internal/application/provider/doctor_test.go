@@ -0,0 +1,97 @@
+package provider
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDiagnose(t *testing.T) {
+	health := map[string]*ProviderHealth{
+		"ollama":    {Name: "ollama", Type: "local", Enabled: true, Healthy: true, Latency: 12 * time.Millisecond, Models: []string{"llama3.2:3b"}},
+		"anthropic": {Name: "anthropic", Type: "cloud", Enabled: true, Healthy: true, Models: []string{"claude-3-5-sonnet-20241022"}},
+		"openai":    {Name: "openai", Type: "cloud", Enabled: true, Error: "401 Unauthorized"},
+	}
+
+	report := Diagnose(newTestRoutingConfig(), health)
+
+	find := func(subject, message string) *DoctorCheck {
+		t.Helper()
+		for i, c := range report.Checks {
+			if c.Subject == subject && strings.Contains(c.Message, message) {
+				return &report.Checks[i]
+			}
+		}
+		t.Fatalf("no %s check mentioning %q in %+v", subject, message, report.Checks)
+		return nil
+	}
+
+	tests := []struct {
+		subject, message string
+		severity         DoctorSeverity
+		fix              string
+	}{
+		{"configuration", "valid", DoctorOK, ""},
+		{"provider ollama", "responding", DoctorOK, ""},
+		{"provider openai", "401", DoctorError, "API key"},
+		{"profile balanced", "generation_model llama3.2:8b", DoctorError, "ollama pull llama3.2:8b"},
+		{"profile premium", "generation_model claude-3-5-sonnet-20241022", DoctorOK, ""},
+		{"profile premium", "review_model gpt-4o", DoctorError, "fix provider openai"},
+		{"profile cheap", "fallback_model llama3.2:1b", DoctorWarning, "set profiles.cheap.fallback_model to llama3.2:3b"},
+	}
+	for _, tt := range tests {
+		c := find(tt.subject, tt.message)
+		if c.Severity != tt.severity {
+			t.Errorf("%s %q severity = %s, want %s", tt.subject, tt.message, c.Severity, tt.severity)
+		}
+		if !strings.Contains(c.Fix, tt.fix) {
+			t.Errorf("%s %q fix = %q, want it to mention %q", tt.subject, tt.message, c.Fix, tt.fix)
+		}
+	}
+}
+
+func TestDoctorReport_Subjects(t *testing.T) {
+	report := &DoctorReport{}
+	report.add("profile cheap", DoctorOK, "generation_model served", "")
+	report.add("profile cheap", DoctorWarning, "fallback_model missing", "")
+	report.add("profile premium", DoctorOK, "generation_model served", "")
+	report.add("profile premium", DoctorError, "review_model missing", "")
+	report.add("provider ollama", DoctorError, "not responding", "")
+
+	passing, failing := report.Subjects("profile ")
+	if strings.Join(passing, ",") != "profile cheap" || strings.Join(failing, ",") != "profile premium" {
+		t.Errorf("Subjects() = %v, %v; want cheap passing and premium failing", passing, failing)
+	}
+}
+
+func TestDiagnose_UnregisteredProvider(t *testing.T) {
+	report := Diagnose(newTestRoutingConfig(), map[string]*ProviderHealth{})
+
+	var anthropic *DoctorCheck
+	for i, c := range report.Checks {
+		if c.Subject == "provider anthropic" {
+			anthropic = &report.Checks[i]
+		}
+	}
+	if anthropic == nil || anthropic.Severity != DoctorError || !strings.Contains(anthropic.Fix, "sr auth login anthropic") {
+		t.Errorf("provider anthropic check = %+v, want an error suggesting sr auth login", anthropic)
+	}
+	if report.Count(DoctorOK) != 1 {
+		t.Errorf("Count(DoctorOK) = %d, want only the configuration check", report.Count(DoctorOK))
+	}
+}
+
+func TestClosestModel(t *testing.T) {
+	known := []string{"claude-3-5-sonnet-20241022", "gpt-4o", "llama3.2:3b"}
+	tests := map[string]string{
+		"claude-3-5-sonnet": "claude-3-5-sonnet-20241022",
+		"gpt-4":             "gpt-4o",
+		"llama3.1:3b":       "llama3.2:3b",
+		"mistral-large":     "",
+	}
+	for model, want := range tests {
+		if got := closestModel(model, known); got != want {
+			t.Errorf("closestModel(%q) = %q, want %q", model, got, want)
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package provider
+
+import (
+	"context"
+	"maps"
+	"math/rand/v2"
+	"slices"
+	"sync"
+
+	adapterProvider "github.com/jbctechsolutions/skillrunner/internal/adapters/provider"
+	"github.com/jbctechsolutions/skillrunner/internal/application/ports"
+	"github.com/jbctechsolutions/skillrunner/internal/domain/skill"
+	"github.com/jbctechsolutions/skillrunner/internal/infrastructure/config"
+)
+
+// Experiment variants.
+const (
+	VariantControl   = "control"   // The phase runs on its usual model
+	VariantCandidate = "candidate" // The phase runs on the experiment's candidate model
+)
+
+// ExperimentAssignment is the experiment variant a phase execution was
+// assigned to.
+type ExperimentAssignment struct {
+	Experiment string
+	Variant    string
+
+	// Provider and ModelID serve the candidate variant; they are empty for
+	// the control.
+	Provider ports.ProviderPort
+	ModelID  string
+}
+
+// ExperimentRouter assigns phase executions to the variants of the A/B
+// experiments in the routing configuration. Each phase is assigned once and
+// keeps its variant for the router's lifetime, so retries and batch
+// fallbacks stay in the same group; a router should be created per run.
+// Phases pinned to a provider or model never take part, and when the
+// candidate model is not available the phase runs as the control.
+type ExperimentRouter struct {
+	experiments map[string]*config.ExperimentConfiguration
+	registry    *adapterProvider.Registry
+	randIntN    func(n int) int
+
+	mu          sync.Mutex
+	assignments map[string]*ExperimentAssignment // By phase ID
+}
+
+// NewExperimentRouter creates a router for the experiments of cfg, backed by
+// the given registry. It returns nil when cfg has no active experiments.
+func NewExperimentRouter(cfg *config.RoutingConfiguration, registry *adapterProvider.Registry) *ExperimentRouter {
+	if cfg == nil || registry == nil {
+		return nil
+	}
+
+	experiments := make(map[string]*config.ExperimentConfiguration)
+	for name, experiment := range cfg.Experiments {
+		if experiment != nil && experiment.Percent > 0 {
+			experiments[name] = experiment
+		}
+	}
+	if len(experiments) == 0 {
+		return nil
+	}
+
+	return &ExperimentRouter{
+		experiments: experiments,
+		registry:    registry,
+		randIntN:    rand.IntN,
+		assignments: make(map[string]*ExperimentAssignment),
+	}
+}
+
+// AssignVariant returns the variant the phase runs as, or nil when it takes
+// part in no experiment. A phase matching several experiments joins the
+// first by name.
+func (r *ExperimentRouter) AssignVariant(ctx context.Context, phase *skill.Phase) *ExperimentAssignment {
+	if phase == nil || phase.IsPinned() || phase.IsModelPinned() {
+		return nil
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if assignment, ok := r.assignments[phase.ID]; ok {
+		return assignment
+	}
+
+	assignment := r.assign(ctx, phase)
+	r.assignments[phase.ID] = assignment
+	return assignment
+}
+
+// assign draws the variant of a phase not yet assigned. The caller holds
+// r.mu.
+func (r *ExperimentRouter) assign(ctx context.Context, phase *skill.Phase) *ExperimentAssignment {
+	for _, name := range slices.Sorted(maps.Keys(r.experiments)) {
+		experiment := r.experiments[name]
+		if !experiment.Includes(phase.ID, phase.RoutingProfile) {
+			continue
+		}
+
+		assignment := &ExperimentAssignment{Experiment: name, Variant: VariantControl}
+		if r.randIntN(100) < experiment.Percent {
+			if p := r.candidateProvider(ctx, experiment); p != nil {
+				assignment.Variant = VariantCandidate
+				assignment.Provider = p
+				assignment.ModelID = experiment.CandidateModel
+			}
+		}
+		return assignment
+	}
+
+	return nil
+}
+
+// candidateProvider returns the provider serving the experiment's candidate
+// model, or nil when it is not available.
+func (r *ExperimentRouter) candidateProvider(ctx context.Context, experiment *config.ExperimentConfiguration) ports.ProviderPort {
+	var p ports.ProviderPort
+	if experiment.CandidateProvider != "" {
+		p = r.registry.Get(experiment.CandidateProvider)
+	} else {
+		p, _ = r.registry.FindByModel(ctx, experiment.CandidateModel)
+	}
+	if p == nil {
+		return nil
+	}
+
+	if available, err := p.IsAvailable(ctx, experiment.CandidateModel); err != nil || !available {
+		return nil
+	}
+	return p
+}
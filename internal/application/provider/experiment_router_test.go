@@ -0,0 +1,145 @@
+package provider
+
+import (
+	"context"
+	"testing"
+
+	adapterProvider "github.com/jbctechsolutions/skillrunner/internal/adapters/provider"
+	"github.com/jbctechsolutions/skillrunner/internal/domain/skill"
+	"github.com/jbctechsolutions/skillrunner/internal/infrastructure/config"
+)
+
+func newExperimentRouter(t *testing.T, experiments map[string]*config.ExperimentConfiguration, draw int) *ExperimentRouter {
+	t.Helper()
+	registry := adapterProvider.NewRegistry()
+	if err := registry.Register(newMockProvider("openai").withModels("gpt-4o", "gpt-4o-mini")); err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+
+	router := NewExperimentRouter(&config.RoutingConfiguration{Experiments: experiments}, registry)
+	if router == nil {
+		t.Fatal("expected a router")
+	}
+	router.randIntN = func(int) int { return draw }
+	return router
+}
+
+func newExperimentPhase(t *testing.T, id, profile string) *skill.Phase {
+	t.Helper()
+	phase, err := skill.NewPhase(id, id, "prompt")
+	if err != nil {
+		t.Fatalf("NewPhase failed: %v", err)
+	}
+	return phase.WithRoutingProfile(profile)
+}
+
+func TestNewExperimentRouter_NoActiveExperiments(t *testing.T) {
+	registry := adapterProvider.NewRegistry()
+	cfg := &config.RoutingConfiguration{Experiments: map[string]*config.ExperimentConfiguration{
+		"paused": {CandidateModel: "gpt-4o-mini", Percent: 0},
+	}}
+
+	if router := NewExperimentRouter(cfg, registry); router != nil {
+		t.Error("expected nil router when all experiments are paused")
+	}
+	if router := NewExperimentRouter(&config.RoutingConfiguration{}, registry); router != nil {
+		t.Error("expected nil router without experiments")
+	}
+}
+
+func TestExperimentRouter_AssignVariant(t *testing.T) {
+	experiments := map[string]*config.ExperimentConfiguration{
+		"mini": {CandidateModel: "gpt-4o-mini", Percent: 20},
+	}
+
+	tests := []struct {
+		name        string
+		draw        int
+		wantVariant string
+		wantModel   string
+	}{
+		{"candidate", 19, VariantCandidate, "gpt-4o-mini"},
+		{"control", 20, VariantControl, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := newExperimentRouter(t, experiments, tt.draw)
+
+			got := router.AssignVariant(context.Background(), newExperimentPhase(t, "p1", skill.ProfileBalanced))
+			if got == nil {
+				t.Fatal("expected an assignment")
+			}
+			if got.Experiment != "mini" || got.Variant != tt.wantVariant || got.ModelID != tt.wantModel {
+				t.Errorf("got %+v, want variant %s with model %q", got, tt.wantVariant, tt.wantModel)
+			}
+			if tt.wantVariant == VariantCandidate && got.Provider.Info().Name != "openai" {
+				t.Errorf("expected candidate provider openai, got %v", got.Provider)
+			}
+		})
+	}
+}
+
+func TestExperimentRouter_AssignsOncePerPhase(t *testing.T) {
+	router := newExperimentRouter(t, map[string]*config.ExperimentConfiguration{
+		"mini": {CandidateModel: "gpt-4o-mini", Percent: 50},
+	}, 0)
+	phase := newExperimentPhase(t, "p1", skill.ProfileBalanced)
+
+	first := router.AssignVariant(context.Background(), phase)
+	router.randIntN = func(int) int { return 99 }
+	second := router.AssignVariant(context.Background(), phase)
+
+	if first != second || second.Variant != VariantCandidate {
+		t.Errorf("expected the phase to keep its candidate variant, got %+v then %+v", first, second)
+	}
+}
+
+func TestExperimentRouter_Filters(t *testing.T) {
+	router := newExperimentRouter(t, map[string]*config.ExperimentConfiguration{
+		"cheap-only": {CandidateModel: "gpt-4o-mini", Percent: 100, Profiles: []string{skill.ProfileCheap}},
+		"summarize":  {CandidateModel: "gpt-4o", Percent: 100, Phases: []string{"summarize"}},
+	}, 0)
+	ctx := context.Background()
+
+	if got := router.AssignVariant(ctx, newExperimentPhase(t, "draft", skill.ProfileBalanced)); got != nil {
+		t.Errorf("expected no experiment for a balanced draft phase, got %+v", got)
+	}
+	if got := router.AssignVariant(ctx, newExperimentPhase(t, "outline", skill.ProfileCheap)); got == nil || got.Experiment != "cheap-only" {
+		t.Errorf("expected cheap-only for a cheap phase, got %+v", got)
+	}
+	if got := router.AssignVariant(ctx, newExperimentPhase(t, "summarize", skill.ProfileBalanced)); got == nil || got.Experiment != "summarize" {
+		t.Errorf("expected summarize for the summarize phase, got %+v", got)
+	}
+}
+
+func TestExperimentRouter_SkipsPinnedPhases(t *testing.T) {
+	router := newExperimentRouter(t, map[string]*config.ExperimentConfiguration{
+		"mini": {CandidateModel: "gpt-4o-mini", Percent: 100},
+	}, 0)
+
+	phase := newExperimentPhase(t, "p1", skill.ProfileBalanced).WithProvider("ollama")
+	if got := router.AssignVariant(context.Background(), phase); got != nil {
+		t.Errorf("expected pinned phase to take part in no experiment, got %+v", got)
+	}
+
+	phase = newExperimentPhase(t, "p2", skill.ProfileBalanced).WithModel("gpt-4o")
+	if got := router.AssignVariant(context.Background(), phase); got != nil {
+		t.Errorf("expected model-pinned phase to take part in no experiment, got %+v", got)
+	}
+}
+
+func TestExperimentRouter_UnavailableCandidateRunsControl(t *testing.T) {
+	router := newExperimentRouter(t, map[string]*config.ExperimentConfiguration{
+		"missing":  {CandidateModel: "claude-3-haiku", Percent: 100, Phases: []string{"p1"}},
+		"provider": {CandidateModel: "gpt-4o-mini", CandidateProvider: "anthropic", Percent: 100, Phases: []string{"p2"}},
+	}, 0)
+	ctx := context.Background()
+
+	if got := router.AssignVariant(ctx, newExperimentPhase(t, "p1", skill.ProfileBalanced)); got == nil || got.Variant != VariantControl {
+		t.Errorf("expected control for an unsupported candidate model, got %+v", got)
+	}
+	if got := router.AssignVariant(ctx, newExperimentPhase(t, "p2", skill.ProfileBalanced)); got == nil || got.Experiment != "provider" || got.Variant != VariantControl {
+		t.Errorf("expected control for an unregistered candidate provider, got %+v", got)
+	}
+}
@@ -0,0 +1,107 @@
+package provider
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"time"
+)
+
+// unhealthyRecheckInterval is how long an unhealthy provider/model is
+// skipped before fastest selection health-checks it again.
+const unhealthyRecheckInterval = 30 * time.Second
+
+// RecordLatency records the outcome of a request served by selection, so
+// fastest selection can prefer the providers that have been responding
+// quickly. A failed request marks the provider/model unhealthy.
+func (r *Router) RecordLatency(selection *ModelSelection, latency time.Duration, err error) {
+	if selection == nil || selection.ProviderName == "" {
+		return
+	}
+	r.latency.Record(selection.ProviderName, selection.ModelID, latency, err)
+}
+
+// LatencyStats returns the rolling latency statistics of a provider/model,
+// and false if none have been recorded.
+func (r *Router) LatencyStats(providerName, modelID string) (LatencyStats, bool) {
+	return r.latency.Stats(providerName, modelID)
+}
+
+// latencyCandidate is a provider/model considered by fastest selection.
+type latencyCandidate struct {
+	selection ModelSelection
+	stats     LatencyStats
+	throttled bool
+	order     int
+}
+
+// selectFastest returns the healthy provider/model with the lowest p50
+// latency (then p95) among every provider serving one of the primaries or
+// fallbacks, or nil if none is available. Provider/models without latency samples are health
+// checked first; those still without samples rank after measured ones, in
+// configured order. Providers throttled by their rate limits rank after all
+// others. Selections of fallbacks are marked as fallbacks.
+func (r *Router) selectFastest(ctx context.Context, primaries []string, fallbacks ...string) *ModelSelection {
+	var candidates []latencyCandidate
+	seen := make(map[string]bool)
+
+	for i, modelID := range slices.Concat(primaries, fallbacks) {
+		if modelID == "" || seen[modelID] {
+			continue
+		}
+		seen[modelID] = true
+
+		for _, p := range r.registry.ListProviders() {
+			if supported, err := p.SupportsModel(ctx, modelID); err != nil || !supported {
+				continue
+			}
+			if available, err := p.IsAvailable(ctx, modelID); err != nil || !available {
+				continue
+			}
+
+			name := p.Info().Name
+			stats, ok := r.latency.Stats(name, modelID)
+			if !ok || stats.Samples == 0 || (!stats.Healthy && time.Since(stats.Updated) > unhealthyRecheckInterval) {
+				status, err := p.HealthCheck(ctx, modelID)
+				r.latency.RecordHealth(name, modelID, status, err)
+				stats, _ = r.latency.Stats(name, modelID)
+			}
+			if !stats.Healthy {
+				continue
+			}
+
+			candidates = append(candidates, latencyCandidate{
+				selection: ModelSelection{ModelID: modelID, ProviderName: name, IsFallback: i >= len(primaries)},
+				stats:     stats,
+				throttled: r.isThrottled(name),
+				order:     len(candidates),
+			})
+		}
+	}
+
+	if len(candidates) == 0 {
+		return nil
+	}
+
+	best := slices.MinFunc(candidates, func(a, b latencyCandidate) int {
+		if a.throttled != b.throttled {
+			if b.throttled {
+				return -1
+			}
+			return 1
+		}
+		measuredA, measuredB := a.stats.Samples > 0, b.stats.Samples > 0
+		if measuredA != measuredB {
+			if measuredA {
+				return -1
+			}
+			return 1
+		}
+		return cmp.Or(
+			cmp.Compare(a.stats.P50, b.stats.P50),
+			cmp.Compare(a.stats.P95, b.stats.P95),
+			cmp.Compare(a.order, b.order),
+		)
+	})
+	return &best.selection
+}
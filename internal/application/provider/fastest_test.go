@@ -0,0 +1,106 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	adapterProvider "github.com/jbctechsolutions/skillrunner/internal/adapters/provider"
+	"github.com/jbctechsolutions/skillrunner/internal/application/ports"
+	"github.com/jbctechsolutions/skillrunner/internal/domain/skill"
+	"github.com/jbctechsolutions/skillrunner/internal/infrastructure/config"
+)
+
+// newFastestRouter returns a router whose balanced profile uses fastest
+// selection over "shared-model" with "backup-model" as fallback.
+func newFastestRouter(t *testing.T, providers ...*mockProvider) *Router {
+	t.Helper()
+	registry := adapterProvider.NewRegistry()
+	for _, p := range providers {
+		if err := registry.Register(p); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cfg := config.NewRoutingConfiguration()
+	cfg.Profiles[skill.ProfileBalanced] = &config.ProfileConfiguration{
+		GenerationModel: "shared-model",
+		FallbackModel:   "backup-model",
+		Selection:       config.SelectionFastest,
+	}
+
+	router, err := NewRouter(cfg, registry)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return router
+}
+
+func healthy(latency time.Duration) *ports.HealthStatus {
+	return &ports.HealthStatus{Healthy: true, Latency: latency}
+}
+
+func TestRouter_SelectFastest_UsesHealthCheckLatency(t *testing.T) {
+	slow := newMockProvider("slow").withModels("shared-model")
+	slow.healthStatus = healthy(300 * time.Millisecond)
+	fast := newMockProvider("fast").withModels("shared-model")
+	fast.healthStatus = healthy(50 * time.Millisecond)
+
+	router := newFastestRouter(t, slow, fast)
+	selection, err := router.SelectModel(context.Background(), skill.ProfileBalanced)
+	if err != nil {
+		t.Fatalf("SelectModel() error = %v", err)
+	}
+	if selection.ProviderName != "fast" || selection.ModelID != "shared-model" || selection.IsFallback {
+		t.Errorf("selection = %+v, want fast/shared-model", selection)
+	}
+}
+
+func TestRouter_SelectFastest_UsesRecordedLatency(t *testing.T) {
+	a := newMockProvider("a").withModels("shared-model")
+	b := newMockProvider("b").withModels("backup-model")
+	router := newFastestRouter(t, a, b)
+
+	for range 5 {
+		router.RecordLatency(&ModelSelection{ProviderName: "a", ModelID: "shared-model"}, 900*time.Millisecond, nil)
+		router.RecordLatency(&ModelSelection{ProviderName: "b", ModelID: "backup-model"}, 100*time.Millisecond, nil)
+	}
+
+	selection, err := router.SelectModel(context.Background(), skill.ProfileBalanced)
+	if err != nil {
+		t.Fatalf("SelectModel() error = %v", err)
+	}
+	if selection.ProviderName != "b" || !selection.IsFallback {
+		t.Errorf("selection = %+v, want fallback b/backup-model", selection)
+	}
+
+	// A failure takes b out of rotation
+	router.RecordLatency(selection, 0, errors.New("503"))
+	selection, err = router.SelectModel(context.Background(), skill.ProfileBalanced)
+	if err != nil || selection.ProviderName != "a" {
+		t.Errorf("after failure: selection = %+v, err = %v", selection, err)
+	}
+	if stats, _ := router.LatencyStats("b", "backup-model"); stats.Healthy {
+		t.Error("expected b to be unhealthy")
+	}
+}
+
+func TestRouter_SelectFastest_SkipsUnhealthy(t *testing.T) {
+	down := newMockProvider("down").withModels("shared-model")
+	down.healthErr = errors.New("connection refused")
+	up := newMockProvider("up").withModels("shared-model")
+	up.healthStatus = healthy(2 * time.Second)
+
+	router := newFastestRouter(t, down, up)
+	phase, _ := skill.NewPhase("draft", "Draft", "{{._input}}")
+	phase.RoutingProfile = skill.ProfileBalanced
+
+	selection, err := router.SelectModelForPhase(context.Background(), phase)
+	if err != nil {
+		t.Fatalf("SelectModelForPhase() error = %v", err)
+	}
+	if selection.ProviderName != "up" {
+		t.Errorf("selection = %+v, want up", selection)
+	}
+}
@@ -2,19 +2,40 @@
 package provider
 
 import (
+	"cmp"
 	"context"
+	"errors"
 	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"slices"
 	"sync"
 	"time"
 
 	adapterProvider "github.com/jbctechsolutions/skillrunner/internal/adapters/provider"
 	"github.com/jbctechsolutions/skillrunner/internal/adapters/provider/anthropic"
+	"github.com/jbctechsolutions/skillrunner/internal/adapters/provider/audit"
+	"github.com/jbctechsolutions/skillrunner/internal/adapters/provider/automatic1111"
+	"github.com/jbctechsolutions/skillrunner/internal/adapters/provider/fireworks"
 	"github.com/jbctechsolutions/skillrunner/internal/adapters/provider/groq"
+	"github.com/jbctechsolutions/skillrunner/internal/adapters/provider/moderation"
 	"github.com/jbctechsolutions/skillrunner/internal/adapters/provider/ollama"
 	"github.com/jbctechsolutions/skillrunner/internal/adapters/provider/openai"
+	"github.com/jbctechsolutions/skillrunner/internal/adapters/provider/openaicompat"
+	"github.com/jbctechsolutions/skillrunner/internal/adapters/provider/ratelimit"
+	"github.com/jbctechsolutions/skillrunner/internal/adapters/provider/redact"
+	"github.com/jbctechsolutions/skillrunner/internal/adapters/provider/stability"
+	"github.com/jbctechsolutions/skillrunner/internal/adapters/provider/timeout"
+	"github.com/jbctechsolutions/skillrunner/internal/adapters/provider/together"
+	"github.com/jbctechsolutions/skillrunner/internal/adapters/provider/traced"
+	"github.com/jbctechsolutions/skillrunner/internal/adapters/provider/transport"
 	"github.com/jbctechsolutions/skillrunner/internal/application/ports"
+	domainProvider "github.com/jbctechsolutions/skillrunner/internal/domain/provider"
 	"github.com/jbctechsolutions/skillrunner/internal/infrastructure/config"
 	"github.com/jbctechsolutions/skillrunner/internal/infrastructure/crypto"
+	"github.com/jbctechsolutions/skillrunner/internal/infrastructure/tracing"
 )
 
 // ProviderHealth contains health status information for a provider.
@@ -35,8 +56,16 @@ type Initializer struct {
 	registry  *adapterProvider.Registry
 	config    *config.Config
 	encryptor *crypto.Encryptor
+	secrets   ports.SecretStorePort // API keys kept outside the config; may be nil
+	routing   *config.RoutingConfiguration
 	mu        sync.RWMutex
 	health    map[string]*ProviderHealth
+	timeouts  map[string]timeout.Config // Operation timeouts recorded when providers are created
+	endpoints map[string]string         // Base URLs configured in place of built-in ones, by provider
+
+	// imageGenerators are the configured image backends that are not LLM
+	// providers (Stability, Stable Diffusion web UI), local first.
+	imageGenerators []ports.ImageGenerationPort
 }
 
 // NewInitializer creates a new provider initializer.
@@ -51,9 +80,87 @@ func NewInitializer(registry *adapterProvider.Registry) (*Initializer, error) {
 		registry:  registry,
 		encryptor: encryptor,
 		health:    make(map[string]*ProviderHealth),
+		timeouts:  make(map[string]timeout.Config),
+		endpoints: make(map[string]string),
 	}, nil
 }
 
+// SetSecretStore sets the store of API keys saved with sr auth login, such
+// as the OS keychain. It must be called before the providers are initialized.
+func (i *Initializer) SetSecretStore(store ports.SecretStorePort) {
+	i.secrets = store
+}
+
+// SetRoutingConfig sets the routing configuration whose per-provider
+// network settings (proxy_url, ca_cert_path, insecure_skip_verify) apply to
+// the built-in providers. It must be called before InitFromConfig.
+func (i *Initializer) SetRoutingConfig(rc *config.RoutingConfiguration) {
+	i.routing = rc
+}
+
+// httpTransport returns the HTTP transport for a provider's network settings
+// in the routing configuration, or nil for the default transport.
+func (i *Initializer) httpTransport(name string) (http.RoundTripper, error) {
+	if i.routing == nil {
+		return nil, nil
+	}
+	return providerTransport(i.routing.Providers[name])
+}
+
+// clientTimeout returns the HTTP client timeout of a built-in provider whose
+// client times out after current, adjusted for the operation timeouts in the
+// routing configuration; see operationTimeouts.
+func (i *Initializer) clientTimeout(name string, current time.Duration) time.Duration {
+	if i.routing == nil {
+		return current
+	}
+	return i.operationTimeouts(name, i.routing.Providers[name], current)
+}
+
+// operationTimeouts records the timeout of each operation of a provider
+// whose HTTP client times out after current, for ApplyTimeouts, and returns
+// the client timeout: the longest of them, so that none is cut short by the
+// client. Operations without a timeout of their own keep current; streams
+// only stall out with a stream_stall timeout.
+func (i *Initializer) operationTimeouts(name string, cfg *config.ProviderConfiguration, current time.Duration) time.Duration {
+	if cfg == nil || cfg.Timeouts == nil {
+		return current
+	}
+	seconds := func(s int) time.Duration {
+		if s == 0 {
+			return current
+		}
+		return time.Duration(s) * time.Second
+	}
+	timeouts := timeout.Config{
+		HealthCheck: seconds(cfg.Timeouts.HealthCheck),
+		ListModels:  seconds(cfg.Timeouts.ListModels),
+		Request:     seconds(cfg.Timeouts.Request),
+		StreamStall: time.Duration(cfg.Timeouts.StreamStall) * time.Second,
+	}
+	if timeouts.IsZero() {
+		return current
+	}
+
+	i.mu.Lock()
+	i.timeouts[name] = timeouts
+	i.mu.Unlock()
+	return timeouts.Longest()
+}
+
+// providerTransport builds the HTTP transport for a provider's network
+// settings, or nil for the default transport.
+func providerTransport(cfg *config.ProviderConfiguration) (http.RoundTripper, error) {
+	if cfg == nil {
+		return nil, nil
+	}
+	return transport.New(transport.Options{
+		ProxyURL:           cfg.ProxyURL,
+		CACertPath:         cfg.CACertPath,
+		InsecureSkipVerify: cfg.InsecureSkipVerify,
+	})
+}
+
 // InitFromConfig initializes providers based on the configuration.
 // It registers enabled providers with the registry.
 func (i *Initializer) InitFromConfig(cfg *config.Config) error {
@@ -93,7 +200,7 @@ func (i *Initializer) InitFromConfig(cfg *config.Config) error {
 			Type:      "cloud",
 			Enabled:   false,
 			Healthy:   false,
-			APIKeySet: cfg.Providers.Anthropic.APIKeyEncrypted != "",
+			APIKeySet: cfg.Providers.Anthropic.HasAPIKey(),
 		})
 	}
 
@@ -108,7 +215,7 @@ func (i *Initializer) InitFromConfig(cfg *config.Config) error {
 			Type:      "cloud",
 			Enabled:   false,
 			Healthy:   false,
-			APIKeySet: cfg.Providers.OpenAI.APIKeyEncrypted != "",
+			APIKeySet: cfg.Providers.OpenAI.HasAPIKey(),
 		})
 	}
 
@@ -123,10 +230,59 @@ func (i *Initializer) InitFromConfig(cfg *config.Config) error {
 			Type:      "cloud",
 			Enabled:   false,
 			Healthy:   false,
-			APIKeySet: cfg.Providers.Groq.APIKeyEncrypted != "",
+			APIKeySet: cfg.Providers.Groq.HasAPIKey(),
 		})
 	}
 
+	// Initialize OpenAI-compatible cloud providers
+	compatProviders := []struct {
+		name          string
+		cfg           config.CloudConfig
+		defaultConfig func(apiKey string) openaicompat.Config
+		newProvider   func(cfg openaicompat.Config) ports.ProviderPort
+	}{
+		{
+			name:          together.ProviderName,
+			cfg:           cfg.Providers.Together,
+			defaultConfig: together.DefaultConfig,
+			newProvider:   func(c openaicompat.Config) ports.ProviderPort { return together.NewProvider(c) },
+		},
+		{
+			name:          fireworks.ProviderName,
+			cfg:           cfg.Providers.Fireworks,
+			defaultConfig: fireworks.DefaultConfig,
+			newProvider:   func(c openaicompat.Config) ports.ProviderPort { return fireworks.NewProvider(c) },
+		},
+	}
+
+	for _, cp := range compatProviders {
+		if cp.cfg.Enabled {
+			if err := i.initOpenAICompatible(cp.name, cp.cfg, cp.defaultConfig, cp.newProvider); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", cp.name, err))
+			}
+		} else {
+			i.setProviderHealth(cp.name, &ProviderHealth{
+				Name:      cp.name,
+				Type:      "cloud",
+				Enabled:   false,
+				Healthy:   false,
+				APIKeySet: cp.cfg.HasAPIKey(),
+			})
+		}
+	}
+
+	// Initialize image generation backends, local first
+	if cfg.Providers.A1111.Enabled {
+		if err := i.initA1111(cfg.Providers.A1111); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", automatic1111.ProviderName, err))
+		}
+	}
+	if cfg.Providers.Stability.Enabled {
+		if err := i.initStability(cfg.Providers.Stability); err != nil {
+			errs = append(errs, fmt.Errorf("stability: %w", err))
+		}
+	}
+
 	if len(errs) > 0 {
 		// Return combined error but don't fail completely
 		// Some providers may have initialized successfully
@@ -142,12 +298,19 @@ func (i *Initializer) initOllama(cfg config.OllamaConfig) error {
 	if url == "" {
 		url = config.DefaultOllamaURL
 	}
+	i.setEndpoint("ollama", url, config.DefaultOllamaURL)
 
-	clientOpts := []ollama.ClientOption{ollama.WithBaseURL(url)}
-	if cfg.Timeout > 0 {
-		clientOpts = append(clientOpts, ollama.WithTimeout(cfg.Timeout))
+	rt, err := i.httpTransport("ollama")
+	if err != nil {
+		return err
 	}
-	provider := ollama.NewProvider(ollama.WithClient(ollama.NewClient(clientOpts...)))
+
+	client := ollama.NewClient(
+		ollama.WithBaseURL(url),
+		ollama.WithTransport(rt),
+		ollama.WithTimeout(i.clientTimeout("ollama", cmp.Or(cfg.Timeout, ollama.DefaultTimeout))),
+	)
+	provider := ollama.NewProvider(ollama.WithClient(client))
 	if err := i.registry.Register(provider); err != nil {
 		return err
 	}
@@ -162,25 +325,51 @@ func (i *Initializer) initOllama(cfg config.OllamaConfig) error {
 	return nil
 }
 
-// initAnthropic initializes the Anthropic provider.
-func (i *Initializer) initAnthropic(cfg config.CloudConfig) error {
+// cloudAPIKey returns the API key of a cloud provider: from the secret store
+// if cfg.Keychain is set, and otherwise decrypted from the configuration.
+func (i *Initializer) cloudAPIKey(name string, cfg config.CloudConfig) (string, error) {
+	if cfg.Keychain {
+		if i.secrets == nil {
+			return "", fmt.Errorf("API key is stored in the keychain, but no keychain is available")
+		}
+		apiKey, err := i.secrets.Get(context.Background(), name)
+		if err != nil {
+			return "", fmt.Errorf("failed to read API key from %s: %w", i.secrets.Name(), err)
+		}
+		return apiKey, nil
+	}
+
 	if cfg.APIKeyEncrypted == "" {
-		return fmt.Errorf("API key not configured")
+		return "", fmt.Errorf("API key not configured")
 	}
 
 	// Decrypt the API key using AES-256-GCM
 	apiKey, err := i.encryptor.Decrypt(cfg.APIKeyEncrypted)
 	if err != nil {
-		return fmt.Errorf("failed to decrypt API key: %w", err)
+		return "", fmt.Errorf("failed to decrypt API key: %w", err)
+	}
+	return apiKey, nil
+}
+
+// initAnthropic initializes the Anthropic provider.
+func (i *Initializer) initAnthropic(cfg config.CloudConfig) error {
+	apiKey, err := i.cloudAPIKey("anthropic", cfg)
+	if err != nil {
+		return err
 	}
 
 	providerCfg := anthropic.DefaultConfig(apiKey)
 	if cfg.BaseURL != "" {
 		providerCfg.BaseURL = cfg.BaseURL
+		i.setEndpoint("anthropic", cfg.BaseURL, "")
 	}
 	if cfg.Timeout > 0 {
 		providerCfg.Timeout = cfg.Timeout
 	}
+	providerCfg.Timeout = i.clientTimeout("anthropic", providerCfg.Timeout)
+	if providerCfg.Transport, err = i.httpTransport("anthropic"); err != nil {
+		return err
+	}
 
 	provider := anthropic.NewProvider(providerCfg)
 	if err := i.registry.Register(provider); err != nil {
@@ -200,23 +389,23 @@ func (i *Initializer) initAnthropic(cfg config.CloudConfig) error {
 
 // initOpenAI initializes the OpenAI provider.
 func (i *Initializer) initOpenAI(cfg config.CloudConfig) error {
-	if cfg.APIKeyEncrypted == "" {
-		return fmt.Errorf("API key not configured")
-	}
-
-	// Decrypt the API key using AES-256-GCM
-	apiKey, err := i.encryptor.Decrypt(cfg.APIKeyEncrypted)
+	apiKey, err := i.cloudAPIKey("openai", cfg)
 	if err != nil {
-		return fmt.Errorf("failed to decrypt API key: %w", err)
+		return err
 	}
 
 	providerCfg := openai.DefaultConfig(apiKey)
 	if cfg.BaseURL != "" {
 		providerCfg.BaseURL = cfg.BaseURL
+		i.setEndpoint("openai", cfg.BaseURL, "")
 	}
 	if cfg.Timeout > 0 {
 		providerCfg.Timeout = cfg.Timeout
 	}
+	providerCfg.Timeout = i.clientTimeout("openai", providerCfg.Timeout)
+	if providerCfg.Transport, err = i.httpTransport("openai"); err != nil {
+		return err
+	}
 
 	provider := openai.NewProvider(providerCfg)
 	if err := i.registry.Register(provider); err != nil {
@@ -236,25 +425,27 @@ func (i *Initializer) initOpenAI(cfg config.CloudConfig) error {
 
 // initGroq initializes the Groq provider.
 func (i *Initializer) initGroq(cfg config.CloudConfig) error {
-	if cfg.APIKeyEncrypted == "" {
-		return fmt.Errorf("API key not configured")
-	}
-
-	// Decrypt the API key using AES-256-GCM
-	apiKey, err := i.encryptor.Decrypt(cfg.APIKeyEncrypted)
+	apiKey, err := i.cloudAPIKey("groq", cfg)
 	if err != nil {
-		return fmt.Errorf("failed to decrypt API key: %w", err)
+		return err
 	}
 
 	providerCfg := groq.DefaultConfig(apiKey)
 	if cfg.BaseURL != "" {
 		providerCfg.BaseURL = cfg.BaseURL
+		i.setEndpoint("groq", cfg.BaseURL, "")
 	}
 	if cfg.Timeout > 0 {
 		providerCfg.Timeout = cfg.Timeout
 	}
+	providerCfg.Timeout = i.clientTimeout("groq", providerCfg.Timeout)
+
+	rt, err := i.httpTransport("groq")
+	if err != nil {
+		return err
+	}
 
-	provider := groq.NewProvider(providerCfg)
+	provider := groq.NewProvider(providerCfg, groq.WithTransport(rt))
 	if err := i.registry.Register(provider); err != nil {
 		return err
 	}
@@ -270,6 +461,555 @@ func (i *Initializer) initGroq(cfg config.CloudConfig) error {
 	return nil
 }
 
+// initOpenAICompatible initializes a cloud provider that is built on the
+// shared OpenAI-compatible client (Together AI, Fireworks).
+func (i *Initializer) initOpenAICompatible(
+	name string,
+	cfg config.CloudConfig,
+	defaultConfig func(apiKey string) openaicompat.Config,
+	newProvider func(cfg openaicompat.Config) ports.ProviderPort,
+) error {
+	apiKey, err := i.cloudAPIKey(name, cfg)
+	if err != nil {
+		return err
+	}
+
+	providerCfg := defaultConfig(apiKey)
+	if cfg.BaseURL != "" {
+		providerCfg.BaseURL = cfg.BaseURL
+		i.setEndpoint(name, cfg.BaseURL, "")
+	}
+	if cfg.Timeout > 0 {
+		providerCfg.Timeout = cfg.Timeout
+	}
+	providerCfg.Timeout = i.clientTimeout(name, providerCfg.Timeout)
+	if providerCfg.Transport, err = i.httpTransport(name); err != nil {
+		return err
+	}
+
+	if err := i.registry.Register(newProvider(providerCfg)); err != nil {
+		return err
+	}
+
+	i.setProviderHealth(name, &ProviderHealth{
+		Name:      name,
+		Type:      "cloud",
+		Enabled:   true,
+		APIKeySet: true,
+		Endpoint:  providerCfg.BaseURL,
+	})
+
+	return nil
+}
+
+// initA1111 initializes the Stable Diffusion web UI image generator.
+func (i *Initializer) initA1111(cfg config.LocalServiceConfig) error {
+	rt, err := i.httpTransport(automatic1111.ProviderName)
+	if err != nil {
+		return err
+	}
+
+	opts := []openaicompat.ClientOption{openaicompat.WithTransport(rt)}
+	if cfg.Timeout > 0 {
+		opts = append(opts, openaicompat.WithTimeout(cfg.Timeout))
+	}
+
+	i.setEndpoint(automatic1111.ProviderName, cfg.URL, config.DefaultA1111URL)
+	i.mu.Lock()
+	i.imageGenerators = append(i.imageGenerators, automatic1111.NewGenerator(cfg.URL, opts...))
+	i.mu.Unlock()
+
+	return nil
+}
+
+// initStability initializes the Stability AI image generator.
+func (i *Initializer) initStability(cfg config.CloudConfig) error {
+	apiKey, err := i.cloudAPIKey(stability.ProviderName, cfg)
+	if err != nil {
+		return err
+	}
+
+	generatorCfg := stability.DefaultConfig(apiKey)
+	if cfg.BaseURL != "" {
+		generatorCfg.BaseURL = cfg.BaseURL
+		i.setEndpoint(stability.ProviderName, cfg.BaseURL, "")
+	}
+	if cfg.Timeout > 0 {
+		generatorCfg.Timeout = cfg.Timeout
+	}
+	if generatorCfg.Transport, err = i.httpTransport(stability.ProviderName); err != nil {
+		return err
+	}
+
+	i.mu.Lock()
+	i.imageGenerators = append(i.imageGenerators, stability.NewGenerator(generatorCfg))
+	i.mu.Unlock()
+
+	return nil
+}
+
+// ImageGenerators returns the configured image backends that are not LLM
+// providers, local first. Providers in the registry may also generate images
+// (see ports.ImageGenerationPort).
+func (i *Initializer) ImageGenerators() []ports.ImageGenerationPort {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+	return slices.Clone(i.imageGenerators)
+}
+
+// InitFromRoutingConfig registers the generic providers declared in a routing
+// configuration (type: openai_compatible). Built-in providers in the routing
+// configuration are ignored here; they are initialized by InitFromConfig.
+func (i *Initializer) InitFromRoutingConfig(rc *config.RoutingConfiguration) error {
+	if rc == nil {
+		return nil
+	}
+
+	var errs []error
+
+	names := make([]string, 0, len(rc.Providers))
+	for name := range rc.Providers {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	for _, name := range names {
+		cfg := rc.Providers[name]
+		if !cfg.IsOpenAICompatible() {
+			continue
+		}
+
+		if !cfg.Enabled {
+			i.setProviderHealth(name, &ProviderHealth{
+				Name:     name,
+				Type:     endpointType(cfg.BaseURL),
+				Enabled:  false,
+				Healthy:  false,
+				Endpoint: cfg.BaseURL,
+			})
+			continue
+		}
+
+		if err := i.initGenericOpenAICompatible(name, cfg); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("some providers failed to initialize: %v", errs)
+	}
+
+	return nil
+}
+
+// ApplyTimeouts wraps each registered provider that has timeouts in the
+// routing configuration so that each of its calls is canceled after the
+// timeout of its operation. Call it after the providers are registered and
+// rate limited, so request timeouts include any wait for the rate limiter.
+func (i *Initializer) ApplyTimeouts() {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+	for _, p := range i.registry.ListProviders() {
+		if _, ok := p.(*timeout.Provider); ok {
+			continue
+		}
+		if cfg, ok := i.timeouts[p.Info().Name]; ok {
+			// Register replaces the provider in place, keeping its position
+			_ = i.registry.Register(timeout.NewProvider(p, cfg))
+		}
+	}
+}
+
+// ApplyRateLimits wraps each registered provider that has rate_limits in the
+// routing configuration, or that reports rate limit headers, so its
+// completions wait for capacity instead of exceeding the limits. Call it
+// after the providers are registered.
+func (i *Initializer) ApplyRateLimits(rc *config.RoutingConfiguration) {
+	for _, p := range i.registry.ListProviders() {
+		if _, ok := p.(*ratelimit.Provider); ok {
+			continue
+		}
+
+		var limits ratelimit.Config
+		if rc != nil {
+			if cfg := rc.GetProvider(p.Info().Name); cfg != nil && cfg.RateLimits != nil {
+				limits = ratelimit.Config{
+					RequestsPerMinute:  cfg.RateLimits.RequestsPerMinute,
+					TokensPerMinute:    cfg.RateLimits.TokensPerMinute,
+					ConcurrentRequests: cfg.RateLimits.ConcurrentRequests,
+					BurstLimit:         cfg.RateLimits.BurstLimit,
+				}
+			}
+		}
+		if limits.IsZero() && !ratelimit.ReportsRateLimits(p) {
+			continue
+		}
+
+		// Register replaces the provider in place, keeping its position
+		_ = i.registry.Register(ratelimit.NewProvider(p, limits))
+	}
+}
+
+// ClientUsage returns what each client of sr serve has used of the rate
+// limits of the registered providers, by provider name. Providers without
+// a rate limit, or that no client has used, are left out.
+func (i *Initializer) ClientUsage() map[string][]ports.ClientUsage {
+	usage := make(map[string][]ports.ClientUsage)
+	for _, p := range i.registry.ListProviders() {
+		// The rate limiter may be under later decorators
+		for {
+			if rp, ok := p.(*ratelimit.Provider); ok {
+				if u := rp.Limiter().Usage(); len(u) > 0 {
+					usage[rp.Info().Name] = u
+				}
+				break
+			}
+			u, ok := p.(interface{ Unwrap() ports.ProviderPort })
+			if !ok {
+				break
+			}
+			p = u.Unwrap()
+		}
+	}
+	return usage
+}
+
+// setEndpoint records the base URL a provider is configured with, unless it
+// is the provider's built-in one.
+func (i *Initializer) setEndpoint(name, url, builtIn string) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	if url == "" || url == builtIn {
+		delete(i.endpoints, name)
+		return
+	}
+	i.endpoints[name] = url
+}
+
+// endpoint returns the base URL a provider is configured with in place of
+// its built-in one, or "" if none.
+func (i *Initializer) endpoint(name string) string {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+	return i.endpoints[name]
+}
+
+// ApplyPolicy enforces an organization policy on the registered providers:
+// providers and image backends the policy does not allow, or allows only at
+// other endpoints, are removed, and
+// the prompts sent to cloud providers are redacted with its rules. It returns
+// the names of the removed providers. Call it after the providers are
+// registered and rate limited; a nil policy changes nothing.
+func (i *Initializer) ApplyPolicy(policy *config.Policy) ([]string, error) {
+	if policy == nil {
+		return nil, nil
+	}
+
+	var removed []string
+	for _, name := range i.registry.List() {
+		if !policy.AllowsProvider(name, i.endpoint(name)) {
+			i.registry.Remove(name)
+			removed = append(removed, name)
+		}
+	}
+	i.imageGenerators = slices.DeleteFunc(i.imageGenerators, func(g ports.ImageGenerationPort) bool {
+		if policy.AllowsProvider(g.Info().Name, i.endpoint(g.Info().Name)) {
+			return false
+		}
+		removed = append(removed, g.Info().Name)
+		return true
+	})
+
+	if len(policy.Redaction) == 0 {
+		return removed, nil
+	}
+	rules, err := policyRules(policy)
+	if err != nil {
+		return removed, err
+	}
+	for _, p := range i.registry.ListProviders() {
+		if p.Info().IsLocal {
+			continue
+		}
+		// Register replaces the provider in place, keeping its position
+		_ = i.registry.Register(redact.NewProvider(p, rules))
+	}
+	return removed, nil
+}
+
+// ApplyModeration wraps providers so that their prompts are checked by a
+// content classifier before they are sent: every cloud provider with the
+// routing configuration's moderation, and any provider with moderation of
+// its own. Call it before ApplyPolicy, so that the classifier sees the
+// prompts as redacted by the policy and guardrails; a nil configuration
+// changes nothing.
+func (i *Initializer) ApplyModeration(rc *config.RoutingConfiguration) error {
+	// Classifiers run on the providers as they were before any was wrapped
+	providers := i.registry.ListProviders()
+	byName := make(map[string]ports.ProviderPort, len(providers))
+	for _, p := range providers {
+		byName[p.Info().Name] = p
+	}
+
+	for _, p := range providers {
+		info := p.Info()
+		m := rc.ProviderModeration(info.Name, info.IsLocal)
+		if m == nil {
+			continue
+		}
+		classifier, err := moderationClassifier(m, byName)
+		if err != nil {
+			return fmt.Errorf("provider %s: %w", info.Name, err)
+		}
+		// Register replaces the provider in place, keeping its position
+		_ = i.registry.Register(moderation.NewProvider(p, classifier, m.OnFlag()))
+	}
+	return nil
+}
+
+// moderationClassifier returns the classifier of a moderation
+// configuration, running on one of providers.
+func moderationClassifier(m *config.ModerationConfiguration, providers map[string]ports.ProviderPort) (ports.ModerationPort, error) {
+	if m.Kind() == config.ModerationClassifierLocal {
+		p, ok := providers[m.LocalProvider()]
+		if !ok {
+			return nil, fmt.Errorf("the local classifier requires the %s provider", m.LocalProvider())
+		}
+		if !p.Info().IsLocal {
+			return nil, fmt.Errorf("the local classifier's provider %s is not local", m.LocalProvider())
+		}
+		return moderation.NewLocalClassifier(p, m.Model), nil
+	}
+
+	p, ok := providers[config.ModerationClassifierOpenAI]
+	if !ok {
+		return nil, errors.New("the openai classifier requires the openai provider")
+	}
+	op, ok := ports.UnwrapProvider(p).(*openai.Provider)
+	if !ok {
+		return nil, errors.New("the openai provider has no moderation endpoint")
+	}
+	if m.Model == "" {
+		return op, nil
+	}
+	return moderation.ClassifierFunc(func(ctx context.Context, text string) (*ports.ModerationResult, error) {
+		return op.ModerateWith(ctx, m.Model, text)
+	}), nil
+}
+
+// Moderations returns how many requests to each provider were sent despite
+// the moderation check flagging them, by category, and how many it could
+// not check, by provider name. Providers without any are left out.
+func (i *Initializer) Moderations() map[string]map[string]int {
+	flags := make(map[string]map[string]int)
+	for _, p := range i.registry.ListProviders() {
+		name := p.Info().Name
+		// Moderation is under redaction and may be under later decorators
+		for p != nil {
+			switch dp := p.(type) {
+			case *moderation.Provider:
+				if f := dp.Flags(); len(f) > 0 {
+					flags[name] = f
+				}
+				p = nil
+			case *redact.Provider:
+				p = dp.ProviderPort
+			case interface{ Unwrap() ports.ProviderPort }:
+				p = dp.Unwrap()
+			default:
+				p = nil
+			}
+		}
+	}
+	return flags
+}
+
+// ApplyGuardrails wraps providers so that the credentials and personal data
+// in their prompts are masked: every cloud provider with the routing
+// configuration's guardrails, and any provider with guardrails of its own.
+// Call it after ApplyPolicy; a nil configuration changes nothing.
+func (i *Initializer) ApplyGuardrails(rc *config.RoutingConfiguration) error {
+	for _, p := range i.registry.ListProviders() {
+		info := p.Info()
+		g := rc.ProviderGuardrails(info.Name, info.IsLocal)
+		if g == nil {
+			continue
+		}
+		rules, err := redact.RulesFor(g.Redactions())
+		if err != nil {
+			return fmt.Errorf("provider %s: %w", info.Name, err)
+		}
+		allow, err := redact.NewAllowlist(g.Allow)
+		if err != nil {
+			return fmt.Errorf("provider %s: %w", info.Name, err)
+		}
+		// Register replaces the provider in place, keeping its position
+		_ = i.registry.Register(redact.NewProvider(p, rules, redact.WithAllowlist(allow)))
+	}
+	return nil
+}
+
+// Redactions returns how many values each rule has masked in the prompts
+// sent to each provider, by provider name, counting the guardrails and the
+// organization policy's redaction. Providers with nothing masked are left
+// out.
+func (i *Initializer) Redactions() map[string]map[string]int {
+	redactions := make(map[string]map[string]int)
+	for _, p := range i.registry.ListProviders() {
+		name := p.Info().Name
+		// Redaction may be under later decorators, and on top of more
+		// redaction
+		for p != nil {
+			if rp, ok := p.(*redact.Provider); ok {
+				for rule, n := range rp.Redactions() {
+					if redactions[name] == nil {
+						redactions[name] = make(map[string]int)
+					}
+					redactions[name][rule] += n
+				}
+				p = rp.ProviderPort
+				continue
+			}
+			u, ok := p.(interface{ Unwrap() ports.ProviderPort })
+			if !ok {
+				break
+			}
+			p = u.Unwrap()
+		}
+	}
+	return redactions
+}
+
+// policyRules compiles the redaction rules of an organization policy.
+func policyRules(policy *config.Policy) ([]redact.Rule, error) {
+	if policy == nil {
+		return nil, nil
+	}
+	rules := make([]redact.Rule, 0, len(policy.Redaction))
+	for _, r := range policy.Redaction {
+		rule, err := redact.NewRule(r.Name, r.Pattern, r.Replacement)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// ApplyAudit wraps each registered provider so that the requests and
+// responses of its completions and streams are appended to log, with
+// credentials and the policy's redaction patterns masked. Call it after
+// ApplyPolicy, so providers the policy denies are not wrapped; a nil policy
+// masks credentials only.
+func (i *Initializer) ApplyAudit(log ports.AuditLogPort, policy *config.Policy) error {
+	rules, err := policyRules(policy)
+	if err != nil {
+		return err
+	}
+	rules = append(redact.SecretRules(), rules...)
+	for _, p := range i.registry.ListProviders() {
+		if _, ok := p.(*audit.Provider); ok {
+			continue
+		}
+		// Register replaces the provider in place, keeping its position
+		_ = i.registry.Register(audit.NewProvider(p, log, rules))
+	}
+	return nil
+}
+
+// ApplyTracing wraps each registered provider so its completions and
+// streams are traced with tracer, priced with costs. Call it last, so the
+// spans cover rate limiting and redaction too.
+func (i *Initializer) ApplyTracing(tracer *tracing.Tracer, costs *domainProvider.CostCalculator) {
+	for _, p := range i.registry.ListProviders() {
+		if _, ok := p.(*traced.Provider); ok {
+			continue
+		}
+		// Register replaces the provider in place, keeping its position
+		_ = i.registry.Register(traced.NewProvider(p, tracer, costs))
+	}
+}
+
+// initGenericOpenAICompatible initializes a provider declared in routing
+// configuration using the generic OpenAI-compatible adapter.
+func (i *Initializer) initGenericOpenAICompatible(name string, cfg *config.ProviderConfiguration) error {
+	if cfg.BaseURL == "" {
+		return fmt.Errorf("base_url not configured")
+	}
+
+	var apiKey string
+	if cfg.APIKeyEnv != "" {
+		apiKey = os.Getenv(cfg.APIKeyEnv)
+	}
+	if apiKey == "" {
+		// Decrypted when the routing configuration was loaded
+		apiKey = cfg.APIKey
+	}
+	if apiKey == "" && i.secrets != nil {
+		// A key saved with sr auth login; a keychain that cannot be read
+		// is treated like a missing key
+		apiKey, _ = i.secrets.Get(context.Background(), name)
+	}
+	if apiKey == "" && cfg.APIKeyEnv != "" {
+		return fmt.Errorf("environment variable %s is not set", cfg.APIKeyEnv)
+	}
+
+	providerCfg := openaicompat.DefaultConfig(apiKey, cfg.BaseURL)
+	i.setEndpoint(name, cfg.BaseURL, "")
+	if cfg.Timeout > 0 {
+		providerCfg.Timeout = time.Duration(cfg.Timeout) * time.Second
+	}
+	providerCfg.Timeout = i.operationTimeouts(name, cfg, providerCfg.Timeout)
+	rt, err := providerTransport(cfg)
+	if err != nil {
+		return err
+	}
+	providerCfg.Transport = rt
+
+	models := cfg.GetEnabledModels()
+	slices.Sort(models)
+
+	typ := endpointType(cfg.BaseURL)
+	provider := openaicompat.NewProvider(openaicompat.ProviderSpec{
+		Name:        name,
+		Description: fmt.Sprintf("OpenAI-compatible provider at %s", cfg.BaseURL),
+		Models:      models,
+		IsLocal:     typ == "local",
+	}, providerCfg)
+	if err := i.registry.Register(provider); err != nil {
+		return err
+	}
+
+	i.setProviderHealth(name, &ProviderHealth{
+		Name:      name,
+		Type:      typ,
+		Enabled:   true,
+		APIKeySet: apiKey != "",
+		Endpoint:  cfg.BaseURL,
+	})
+
+	return nil
+}
+
+// endpointType classifies a base URL as "local" when it points at the
+// loopback interface and "cloud" otherwise.
+func endpointType(baseURL string) string {
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return "cloud"
+	}
+
+	host := u.Hostname()
+	if host == "localhost" {
+		return "local"
+	}
+	if ip := net.ParseIP(host); ip != nil && ip.IsLoopback() {
+		return "local"
+	}
+
+	return "cloud"
+}
+
 // CheckHealth performs health checks on all registered providers.
 // It updates the internal health state and returns the results.
 func (i *Initializer) CheckHealth(ctx context.Context) map[string]*ProviderHealth {
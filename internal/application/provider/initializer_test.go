@@ -2,12 +2,29 @@ package provider
 
 import (
 	"context"
+	"io"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
 	"testing"
 	"time"
 
 	adapterProvider "github.com/jbctechsolutions/skillrunner/internal/adapters/provider"
+	"github.com/jbctechsolutions/skillrunner/internal/adapters/provider/audit"
+	"github.com/jbctechsolutions/skillrunner/internal/adapters/provider/moderation"
+	"github.com/jbctechsolutions/skillrunner/internal/adapters/provider/openaicompat"
+	"github.com/jbctechsolutions/skillrunner/internal/adapters/provider/ratelimit"
+	"github.com/jbctechsolutions/skillrunner/internal/adapters/provider/redact"
+	"github.com/jbctechsolutions/skillrunner/internal/adapters/provider/timeout"
+	"github.com/jbctechsolutions/skillrunner/internal/adapters/provider/traced"
 	"github.com/jbctechsolutions/skillrunner/internal/application/ports"
+	"github.com/jbctechsolutions/skillrunner/internal/domain/workflow"
 	"github.com/jbctechsolutions/skillrunner/internal/infrastructure/config"
+	"github.com/jbctechsolutions/skillrunner/internal/infrastructure/tracing"
 )
 
 // testProvider implements ports.ProviderPort for testing the initializer
@@ -209,6 +226,612 @@ func TestInitFromConfig_CloudProviderWithKey(t *testing.T) {
 	}
 }
 
+// testSecretStore implements ports.SecretStorePort in memory.
+type testSecretStore map[string]string
+
+func (s testSecretStore) Name() string { return "test keychain" }
+
+func (s testSecretStore) Get(ctx context.Context, key string) (string, error) {
+	if value, ok := s[key]; ok {
+		return value, nil
+	}
+	return "", ports.ErrSecretNotFound
+}
+
+func (s testSecretStore) Set(ctx context.Context, key, value string) error {
+	s[key] = value
+	return nil
+}
+
+func (s testSecretStore) Delete(ctx context.Context, key string) error {
+	delete(s, key)
+	return nil
+}
+
+func (s testSecretStore) List(ctx context.Context) ([]string, error) {
+	return slices.Sorted(maps.Keys(s)), nil
+}
+
+func TestInitFromConfig_KeychainKey(t *testing.T) {
+	registry := adapterProvider.NewRegistry()
+	initializer, err := NewInitializer(registry)
+	if err != nil {
+		t.Fatalf("NewInitializer returned error: %v", err)
+	}
+	initializer.SetSecretStore(testSecretStore{"anthropic": "sk-ant-test"})
+
+	cfg := config.NewDefaultConfig()
+	cfg.Providers.Ollama.Enabled = false
+	cfg.Providers.Anthropic = config.CloudConfig{Enabled: true, Keychain: true}
+	cfg.Providers.OpenAI = config.CloudConfig{Enabled: true, Keychain: true}
+
+	err = initializer.InitFromConfig(cfg)
+	if err == nil || !strings.Contains(err.Error(), "test keychain") {
+		t.Errorf("InitFromConfig() error = %v, want the missing OpenAI key reported", err)
+	}
+
+	if registry.Get("anthropic") == nil {
+		t.Error("expected Anthropic provider to be registered from the keychain")
+	}
+	if registry.Get("openai") != nil {
+		t.Error("OpenAI has no key in the keychain and should not be registered")
+	}
+}
+
+func TestInitFromRoutingConfig_KeychainKey(t *testing.T) {
+	registry := adapterProvider.NewRegistry()
+	initializer, err := NewInitializer(registry)
+	if err != nil {
+		t.Fatalf("NewInitializer returned error: %v", err)
+	}
+	initializer.SetSecretStore(testSecretStore{"gateway": "gw-key"})
+
+	rc := config.NewRoutingConfiguration()
+	rc.Providers["gateway"] = &config.ProviderConfiguration{
+		Type:      config.ProviderTypeOpenAICompatible,
+		Enabled:   true,
+		BaseURL:   "https://gateway.example.com/v1",
+		APIKeyEnv: "SKILLRUNNER_TEST_UNSET_KEY",
+	}
+
+	if err := initializer.InitFromRoutingConfig(rc); err != nil {
+		t.Fatalf("InitFromRoutingConfig() error = %v", err)
+	}
+	if health := initializer.GetHealth("gateway"); health == nil || !health.APIKeySet {
+		t.Errorf("gateway health = %+v, want the keychain key set", health)
+	}
+}
+
+func TestInitFromConfig_ImageGenerators(t *testing.T) {
+	registry := adapterProvider.NewRegistry()
+	initializer, err := NewInitializer(registry)
+	if err != nil {
+		t.Fatalf("NewInitializer returned error: %v", err)
+	}
+
+	cfg := config.NewDefaultConfig()
+	cfg.Providers.Ollama.Enabled = false
+	cfg.Providers.Stability.Enabled = true
+	cfg.Providers.A1111.Enabled = true
+
+	encryptedKey, err := initializer.encryptor.Encrypt("sk-test")
+	if err != nil {
+		t.Fatalf("failed to encrypt test API key: %v", err)
+	}
+	cfg.Providers.Stability.APIKeyEncrypted = encryptedKey
+
+	if err := initializer.InitFromConfig(cfg); err != nil {
+		t.Fatalf("InitFromConfig returned error: %v", err)
+	}
+
+	generators := initializer.ImageGenerators()
+	if len(generators) != 2 {
+		t.Fatalf("expected 2 image generators, got %d", len(generators))
+	}
+	if generators[0].Info().Name != "a1111" || generators[1].Info().Name != "stability" {
+		t.Errorf("expected the local generator first, got %s, %s", generators[0].Info().Name, generators[1].Info().Name)
+	}
+	if registry.Count() != 0 {
+		t.Error("image generators should not be registered as LLM providers")
+	}
+}
+
+func TestInitFromRoutingConfig_OpenAICompatible(t *testing.T) {
+	registry := adapterProvider.NewRegistry()
+	initializer, err := NewInitializer(registry)
+	if err != nil {
+		t.Fatalf("NewInitializer returned error: %v", err)
+	}
+
+	t.Setenv("TEST_GATEWAY_KEY", "secret")
+
+	rc := config.NewRoutingConfiguration()
+	rc.Providers["vllm"] = &config.ProviderConfiguration{
+		Type:    config.ProviderTypeOpenAICompatible,
+		Enabled: true,
+		BaseURL: "http://localhost:8000/v1",
+		Models: map[string]*config.ModelConfiguration{
+			"llama-3.1-8b": {Enabled: true},
+		},
+	}
+	rc.Providers["gateway"] = &config.ProviderConfiguration{
+		Type:      config.ProviderTypeOpenAICompatible,
+		Enabled:   true,
+		BaseURL:   "https://gateway.example.com/v1",
+		APIKeyEnv: "TEST_GATEWAY_KEY",
+	}
+	rc.Providers["disabled"] = &config.ProviderConfiguration{
+		Type:    config.ProviderTypeOpenAICompatible,
+		BaseURL: "https://disabled.example.com/v1",
+	}
+	rc.Providers["groq"] = &config.ProviderConfiguration{Enabled: true}
+
+	if err := initializer.InitFromRoutingConfig(rc); err != nil {
+		t.Fatalf("InitFromRoutingConfig returned error: %v", err)
+	}
+
+	vllm := registry.Get("vllm")
+	if vllm == nil {
+		t.Fatal("expected vllm provider to be registered")
+	}
+	if !vllm.Info().IsLocal {
+		t.Error("expected loopback provider to be local")
+	}
+	models, err := vllm.ListModels(context.Background())
+	if err != nil || len(models) != 1 || models[0] != "llama-3.1-8b" {
+		t.Errorf("ListModels() = %v, %v; want [llama-3.1-8b]", models, err)
+	}
+
+	if registry.Get("gateway") == nil {
+		t.Error("expected gateway provider to be registered")
+	}
+	if h := initializer.GetHealth("gateway"); h == nil || !h.APIKeySet || h.Type != "cloud" {
+		t.Errorf("unexpected gateway health: %+v", h)
+	}
+
+	if registry.Get("disabled") != nil {
+		t.Error("disabled provider should not be registered")
+	}
+	if registry.Get("groq") != nil {
+		t.Error("built-in providers should not be registered from routing config")
+	}
+}
+
+func TestInitFromRoutingConfig_MissingAPIKey(t *testing.T) {
+	registry := adapterProvider.NewRegistry()
+	initializer, err := NewInitializer(registry)
+	if err != nil {
+		t.Fatalf("NewInitializer returned error: %v", err)
+	}
+
+	rc := config.NewRoutingConfiguration()
+	rc.Providers["gateway"] = &config.ProviderConfiguration{
+		Type:      config.ProviderTypeOpenAICompatible,
+		Enabled:   true,
+		BaseURL:   "https://gateway.example.com/v1",
+		APIKeyEnv: "SKILLRUNNER_TEST_UNSET_KEY",
+	}
+
+	if err := initializer.InitFromRoutingConfig(rc); err == nil {
+		t.Error("expected error when api_key_env is not set")
+	}
+	if registry.Get("gateway") != nil {
+		t.Error("provider without API key should not be registered")
+	}
+}
+
+func TestInitFromRoutingConfig_Proxy(t *testing.T) {
+	var proxied string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied = r.URL.String()
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = io.WriteString(w, `{"choices":[{"message":{"role":"assistant","content":"ok"},"finish_reason":"stop"}]}`)
+	}))
+	defer proxy.Close()
+
+	registry := adapterProvider.NewRegistry()
+	initializer, err := NewInitializer(registry)
+	if err != nil {
+		t.Fatalf("NewInitializer returned error: %v", err)
+	}
+
+	rc := config.NewRoutingConfiguration()
+	rc.Providers["gateway"] = &config.ProviderConfiguration{
+		Type:     config.ProviderTypeOpenAICompatible,
+		Enabled:  true,
+		BaseURL:  "http://gateway.example.invalid/v1",
+		ProxyURL: proxy.URL,
+	}
+	if err := initializer.InitFromRoutingConfig(rc); err != nil {
+		t.Fatalf("InitFromRoutingConfig returned error: %v", err)
+	}
+
+	_, err = registry.Get("gateway").Complete(context.Background(), ports.CompletionRequest{
+		ModelID:  "m",
+		Messages: []ports.Message{{Role: "user", Content: "hi"}},
+	})
+	if err != nil {
+		t.Fatalf("Complete() error = %v", err)
+	}
+	if proxied != "http://gateway.example.invalid/v1/chat/completions" {
+		t.Errorf("proxy received %q, want the gateway URL", proxied)
+	}
+}
+
+func TestInitFromConfig_InvalidCACert(t *testing.T) {
+	registry := adapterProvider.NewRegistry()
+	initializer, err := NewInitializer(registry)
+	if err != nil {
+		t.Fatalf("NewInitializer returned error: %v", err)
+	}
+
+	rc := config.NewRoutingConfiguration()
+	rc.Providers["ollama"] = &config.ProviderConfiguration{
+		Enabled:    true,
+		CACertPath: filepath.Join(t.TempDir(), "missing.pem"),
+	}
+	initializer.SetRoutingConfig(rc)
+
+	cfg := config.NewDefaultConfig()
+	cfg.Providers.Ollama.Enabled = true
+	if err := initializer.InitFromConfig(cfg); err == nil {
+		t.Error("expected error for an unreadable ca_cert_path")
+	}
+	if registry.Get("ollama") != nil {
+		t.Error("provider with invalid TLS settings should not be registered")
+	}
+}
+
+func TestApplyRateLimits(t *testing.T) {
+	registry := adapterProvider.NewRegistry()
+	initializer, err := NewInitializer(registry)
+	if err != nil {
+		t.Fatalf("NewInitializer returned error: %v", err)
+	}
+
+	_ = registry.Register(&testProvider{name: "limited"})
+	_ = registry.Register(&testProvider{name: "unlimited"})
+
+	rc := config.NewRoutingConfiguration()
+	rc.Providers["limited"] = &config.ProviderConfiguration{
+		Enabled:    true,
+		RateLimits: &config.RateLimitConfiguration{RequestsPerMinute: 60, ConcurrentRequests: 2},
+	}
+	rc.Providers["unlimited"] = &config.ProviderConfiguration{Enabled: true}
+	rc.Providers["missing"] = &config.ProviderConfiguration{
+		Enabled:    true,
+		RateLimits: &config.RateLimitConfiguration{RequestsPerMinute: 60},
+	}
+
+	initializer.ApplyRateLimits(rc)
+	initializer.ApplyRateLimits(rc) // Applying twice must not wrap twice
+
+	limited, ok := registry.Get("limited").(*ratelimit.Provider)
+	if !ok {
+		t.Fatalf("expected limited provider to be wrapped, got %T", registry.Get("limited"))
+	}
+	if _, ok := limited.Unwrap().(*testProvider); !ok {
+		t.Errorf("expected wrapper around the original provider, got %T", limited.Unwrap())
+	}
+	if _, ok := registry.Get("unlimited").(*testProvider); !ok {
+		t.Errorf("provider without rate_limits should not be wrapped, got %T", registry.Get("unlimited"))
+	}
+	if registry.Get("missing") != nil {
+		t.Error("rate limits should not register providers")
+	}
+	if got := registry.List(); len(got) != 2 || got[0] != "limited" {
+		t.Errorf("expected registration order to be kept, got %v", got)
+	}
+}
+
+func TestClientUsage(t *testing.T) {
+	registry := adapterProvider.NewRegistry()
+	initializer, err := NewInitializer(registry)
+	if err != nil {
+		t.Fatalf("NewInitializer returned error: %v", err)
+	}
+	_ = registry.Register(&testProvider{name: "limited"})
+	_ = registry.Register(&testProvider{name: "unlimited"})
+
+	rc := config.NewRoutingConfiguration()
+	rc.Providers["limited"] = &config.ProviderConfiguration{
+		Enabled:    true,
+		RateLimits: &config.RateLimitConfiguration{ConcurrentRequests: 2},
+	}
+	initializer.ApplyRateLimits(rc)
+	initializer.timeouts["limited"] = timeout.Config{Request: time.Minute}
+	initializer.ApplyTimeouts()
+
+	if usage := initializer.ClientUsage(); len(usage) != 0 {
+		t.Errorf("ClientUsage() = %v before any request, want none", usage)
+	}
+	ctx := ports.WithClient(context.Background(), ports.Client{Name: "ci"})
+	for _, name := range []string{"limited", "unlimited"} {
+		if _, err := registry.Get(name).Complete(ctx, ports.CompletionRequest{}); err != nil {
+			t.Fatalf("Complete() on %s error = %v", name, err)
+		}
+	}
+
+	usage := initializer.ClientUsage()
+	if len(usage) != 1 || len(usage["limited"]) != 1 || usage["limited"][0].Client != "ci" || usage["limited"][0].Requests != 1 {
+		t.Errorf("ClientUsage() = %v, want one request of ci on the limited provider", usage)
+	}
+}
+
+// reportingProvider is a test provider that reports rate limit headers.
+type reportingProvider struct {
+	*testProvider
+	onRateLimit func(*openaicompat.RateLimitInfo)
+}
+
+func (p *reportingProvider) OnRateLimit(fn func(*openaicompat.RateLimitInfo)) {
+	p.onRateLimit = fn
+}
+
+func TestApplyRateLimits_ReportedLimits(t *testing.T) {
+	registry := adapterProvider.NewRegistry()
+	initializer, err := NewInitializer(registry)
+	if err != nil {
+		t.Fatalf("NewInitializer returned error: %v", err)
+	}
+
+	_ = registry.Register(&reportingProvider{testProvider: &testProvider{name: "reporting"}})
+
+	initializer.ApplyRateLimits(config.NewRoutingConfiguration())
+
+	p, ok := registry.Get("reporting").(*ratelimit.Provider)
+	if !ok {
+		t.Fatalf("expected a provider reporting rate limits to be wrapped, got %T", registry.Get("reporting"))
+	}
+	if p.Unwrap().(*reportingProvider).onRateLimit == nil {
+		t.Error("expected the limiter to observe the provider's rate limit headers")
+	}
+}
+
+func TestApplyTimeouts(t *testing.T) {
+	registry := adapterProvider.NewRegistry()
+	initializer, err := NewInitializer(registry)
+	if err != nil {
+		t.Fatalf("NewInitializer returned error: %v", err)
+	}
+
+	rc := config.NewRoutingConfiguration()
+	rc.Providers["ollama"] = &config.ProviderConfiguration{
+		Enabled:  true,
+		Timeouts: &config.TimeoutConfiguration{HealthCheck: 5, Request: 600, StreamStall: 20},
+	}
+	initializer.SetRoutingConfig(rc)
+
+	cfg := config.NewDefaultConfig()
+	cfg.Providers.Ollama.Timeout = 0
+	if err := initializer.InitFromConfig(cfg); err != nil {
+		t.Fatalf("InitFromConfig returned error: %v", err)
+	}
+	_ = registry.Register(&testProvider{name: "other"})
+
+	initializer.ApplyTimeouts()
+	initializer.ApplyTimeouts() // Applying twice must not wrap twice
+
+	p, ok := registry.Get("ollama").(*timeout.Provider)
+	if !ok {
+		t.Fatalf("expected ollama to be wrapped, got %T", registry.Get("ollama"))
+	}
+	want := timeout.Config{HealthCheck: 5 * time.Second, ListModels: 30 * time.Second, Request: 10 * time.Minute, StreamStall: 20 * time.Second}
+	if p.Config() != want {
+		t.Errorf("Config() = %+v, want %+v", p.Config(), want)
+	}
+	if _, ok := p.Unwrap().(*timeout.Provider); ok {
+		t.Error("expected a single timeout wrapper")
+	}
+	if _, ok := registry.Get("other").(*testProvider); !ok {
+		t.Errorf("provider without timeouts should not be wrapped, got %T", registry.Get("other"))
+	}
+}
+
+func TestApplyTracing(t *testing.T) {
+	registry := adapterProvider.NewRegistry()
+	initializer, err := NewInitializer(registry)
+	if err != nil {
+		t.Fatalf("NewInitializer returned error: %v", err)
+	}
+
+	_ = registry.Register(&testProvider{name: "ollama", isLocal: true})
+	_ = registry.Register(&testProvider{name: "anthropic"})
+
+	initializer.ApplyTracing(tracing.Default(), nil)
+	initializer.ApplyTracing(tracing.Default(), nil) // Applying twice must not wrap twice
+
+	for _, name := range []string{"ollama", "anthropic"} {
+		p, ok := registry.Get(name).(*traced.Provider)
+		if !ok {
+			t.Fatalf("expected %s to be wrapped, got %T", name, registry.Get(name))
+		}
+		if _, ok := p.Unwrap().(*testProvider); !ok {
+			t.Errorf("expected wrapper around the original provider, got %T", p.Unwrap())
+		}
+	}
+	if got := registry.List(); len(got) != 2 || got[0] != "ollama" {
+		t.Errorf("expected registration order to be kept, got %v", got)
+	}
+}
+
+func TestApplyPolicy(t *testing.T) {
+	registry := adapterProvider.NewRegistry()
+	initializer, err := NewInitializer(registry)
+	if err != nil {
+		t.Fatalf("NewInitializer returned error: %v", err)
+	}
+
+	_ = registry.Register(&testProvider{name: "ollama", isLocal: true})
+	_ = registry.Register(&testProvider{name: "anthropic"})
+	_ = registry.Register(&testProvider{name: "openai"})
+	// An allowed name redefined, as by a routing.yaml openai_compatible provider
+	_ = registry.Register(&testProvider{name: "groq"})
+	initializer.setEndpoint("groq", "https://groq.attacker.example/v1", "")
+
+	denied, err := initializer.ApplyPolicy(&config.Policy{
+		AllowedProviders: []string{"ollama", "anthropic", "groq"},
+		Redaction:        []config.RedactionRule{{Name: "ticket", Pattern: `TICKET-\d+`}},
+	})
+	if err != nil {
+		t.Fatalf("ApplyPolicy returned error: %v", err)
+	}
+
+	if !slices.Equal(denied, []string{"openai", "groq"}) || registry.Get("openai") != nil || registry.Get("groq") != nil {
+		t.Errorf("denied = %v; openai and the redefined groq should be removed from the registry", denied)
+	}
+	if _, ok := registry.Get("anthropic").(*redact.Provider); !ok {
+		t.Errorf("cloud provider should be redacted, got %T", registry.Get("anthropic"))
+	}
+	if _, ok := registry.Get("ollama").(*testProvider); !ok {
+		t.Errorf("local provider should not be redacted, got %T", registry.Get("ollama"))
+	}
+}
+
+func TestApplyGuardrails(t *testing.T) {
+	registry := adapterProvider.NewRegistry()
+	initializer, err := NewInitializer(registry)
+	if err != nil {
+		t.Fatalf("NewInitializer returned error: %v", err)
+	}
+
+	_ = registry.Register(&testProvider{name: "ollama", isLocal: true})
+	_ = registry.Register(&testProvider{name: "anthropic"})
+	_ = registry.Register(&testProvider{name: "openai"})
+
+	if _, err := initializer.ApplyPolicy(&config.Policy{
+		Redaction: []config.RedactionRule{{Name: "ticket", Pattern: `TICKET-\d+`}},
+	}); err != nil {
+		t.Fatalf("ApplyPolicy returned error: %v", err)
+	}
+	err = initializer.ApplyGuardrails(&config.RoutingConfiguration{
+		Guardrails: &config.GuardrailsConfiguration{Allow: []string{"support@acme.io"}},
+		Providers: map[string]*config.ProviderConfiguration{
+			"openai": {Guardrails: &config.GuardrailsConfiguration{Disabled: true}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("ApplyGuardrails returned error: %v", err)
+	}
+
+	if _, ok := registry.Get("ollama").(*testProvider); !ok {
+		t.Errorf("local provider should not be guarded, got %T", registry.Get("ollama"))
+	}
+	guarded, ok := registry.Get("anthropic").(*redact.Provider)
+	if !ok {
+		t.Fatalf("cloud provider should be guarded, got %T", registry.Get("anthropic"))
+	}
+	if _, ok := guarded.ProviderPort.(*redact.Provider); !ok {
+		t.Errorf("guardrails should wrap the policy's redaction, got %T", guarded.ProviderPort)
+	}
+
+	_, _ = registry.Get("anthropic").Complete(context.Background(), ports.CompletionRequest{Messages: []ports.Message{{
+		Role:    "user",
+		Content: "Ask jane@acme.io or support@acme.io about TICKET-42",
+	}}})
+	want := map[string]map[string]int{"anthropic": {"email": 1, "ticket": 1}}
+	if got := initializer.Redactions(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Redactions() = %v, want %v", got, want)
+	}
+
+	err = initializer.ApplyGuardrails(&config.RoutingConfiguration{
+		Guardrails: &config.GuardrailsConfiguration{Redact: []string{"passport"}},
+	})
+	if err == nil {
+		t.Error("ApplyGuardrails should reject an unknown redaction")
+	}
+}
+
+func TestApplyModeration(t *testing.T) {
+	registry := adapterProvider.NewRegistry()
+	initializer, err := NewInitializer(registry)
+	if err != nil {
+		t.Fatalf("NewInitializer returned error: %v", err)
+	}
+
+	_ = registry.Register(&testProvider{name: "ollama", isLocal: true})
+	_ = registry.Register(&testProvider{name: "anthropic"})
+	_ = registry.Register(&testProvider{name: "groq"})
+
+	err = initializer.ApplyModeration(&config.RoutingConfiguration{
+		Moderation: &config.ModerationConfiguration{Classifier: config.ModerationClassifierLocal, Model: "llama-guard3"},
+		Providers: map[string]*config.ProviderConfiguration{
+			"groq": {Moderation: &config.ModerationConfiguration{Disabled: true}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("ApplyModeration returned error: %v", err)
+	}
+	if _, ok := registry.Get("ollama").(*testProvider); !ok {
+		t.Errorf("local provider should not be moderated, got %T", registry.Get("ollama"))
+	}
+	if _, ok := registry.Get("groq").(*testProvider); !ok {
+		t.Errorf("provider with moderation disabled should not be moderated, got %T", registry.Get("groq"))
+	}
+	moderated, ok := registry.Get("anthropic").(*moderation.Provider)
+	if !ok {
+		t.Fatalf("cloud provider should be moderated, got %T", registry.Get("anthropic"))
+	}
+	if moderated.ProviderPort.Info().Name != "anthropic" {
+		t.Errorf("moderation wraps %s, want anthropic", moderated.ProviderPort.Info().Name)
+	}
+	if got := initializer.Moderations(); len(got) != 0 {
+		t.Errorf("Moderations() = %v, want none", got)
+	}
+
+	for _, m := range []*config.ModerationConfiguration{
+		{},
+		{Classifier: config.ModerationClassifierLocal, Model: "llama-guard3", Provider: "lmstudio"},
+		{Classifier: config.ModerationClassifierLocal, Model: "llama-guard3", Provider: "groq"},
+	} {
+		if err := initializer.ApplyModeration(&config.RoutingConfiguration{Moderation: m}); err == nil {
+			t.Errorf("ApplyModeration(%+v) should fail without its classifier", m)
+		}
+	}
+}
+
+// nopAuditLog discards audit records.
+type nopAuditLog struct{}
+
+func (nopAuditLog) Append(context.Context, *workflow.AuditRecord) error { return nil }
+
+func (nopAuditLog) Records(context.Context, string) ([]*workflow.AuditRecord, error) {
+	return nil, nil
+}
+
+func TestApplyAudit(t *testing.T) {
+	registry := adapterProvider.NewRegistry()
+	initializer, err := NewInitializer(registry)
+	if err != nil {
+		t.Fatalf("NewInitializer returned error: %v", err)
+	}
+
+	_ = registry.Register(&testProvider{name: "ollama", isLocal: true})
+	_ = registry.Register(&testProvider{name: "anthropic"})
+
+	var log nopAuditLog
+	policy := &config.Policy{Redaction: []config.RedactionRule{{Name: "ticket", Pattern: `TICKET-\d+`}}}
+	if err := initializer.ApplyAudit(log, policy); err != nil {
+		t.Fatalf("ApplyAudit returned error: %v", err)
+	}
+	_ = initializer.ApplyAudit(log, policy) // Applying twice must not wrap twice
+
+	for _, name := range []string{"ollama", "anthropic"} {
+		p, ok := registry.Get(name).(*audit.Provider)
+		if !ok {
+			t.Fatalf("expected %s to be audited, got %T", name, registry.Get(name))
+		}
+		if _, ok := p.ProviderPort.(*testProvider); !ok {
+			t.Errorf("expected a single audit wrapper around %s, got %T", name, p.ProviderPort)
+		}
+	}
+
+	bad := &config.Policy{Redaction: []config.RedactionRule{{Name: "bad", Pattern: `(`}}}
+	if err := initializer.ApplyAudit(log, bad); err == nil {
+		t.Error("ApplyAudit should reject an invalid redaction pattern")
+	}
+}
+
 func TestCheckHealth(t *testing.T) {
 	registry := adapterProvider.NewRegistry()
 	initializer, err := NewInitializer(registry)
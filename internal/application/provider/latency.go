@@ -0,0 +1,135 @@
+package provider
+
+import (
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/jbctechsolutions/skillrunner/internal/application/ports"
+)
+
+// DefaultLatencyWindow is the number of recent samples kept per
+// provider/model.
+const DefaultLatencyWindow = 50
+
+// LatencyStats summarizes the recent latency and health of a provider/model.
+type LatencyStats struct {
+	P50     time.Duration
+	P95     time.Duration
+	Samples int
+	Healthy bool // False after a failed health check or completion, until the next success
+	Updated time.Time
+}
+
+// LatencyTracker keeps a rolling window of latency samples per
+// provider/model, fed by health checks and completed requests.
+type LatencyTracker struct {
+	mu     sync.Mutex
+	window int
+	series map[latencyKey]*latencySeries
+}
+
+type latencyKey struct {
+	provider string
+	model    string
+}
+
+// latencySeries is a ring buffer of samples.
+type latencySeries struct {
+	samples []time.Duration
+	next    int
+	healthy bool
+	updated time.Time
+}
+
+// NewLatencyTracker creates a tracker keeping window samples per
+// provider/model, or DefaultLatencyWindow if window is not positive.
+func NewLatencyTracker(window int) *LatencyTracker {
+	if window <= 0 {
+		window = DefaultLatencyWindow
+	}
+	return &LatencyTracker{
+		window: window,
+		series: make(map[latencyKey]*latencySeries),
+	}
+}
+
+// Record adds the outcome of a request: its latency on success, or an
+// unhealthy mark on failure.
+func (t *LatencyTracker) Record(providerName, modelID string, latency time.Duration, err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	s := t.seriesFor(providerName, modelID)
+	s.updated = time.Now()
+	s.healthy = err == nil
+	if err != nil {
+		return
+	}
+
+	if len(s.samples) < t.window {
+		s.samples = append(s.samples, latency)
+	} else {
+		s.samples[s.next] = latency
+	}
+	s.next = (s.next + 1) % t.window
+}
+
+// RecordHealth adds the result of a provider health check.
+func (t *LatencyTracker) RecordHealth(providerName, modelID string, status *ports.HealthStatus, err error) {
+	switch {
+	case err != nil:
+		t.Record(providerName, modelID, 0, err)
+	case status == nil || !status.Healthy || status.Latency <= 0:
+		// Unhealthy, or healthy without a latency measurement
+		t.mu.Lock()
+		s := t.seriesFor(providerName, modelID)
+		s.healthy = status != nil && status.Healthy
+		s.updated = time.Now()
+		t.mu.Unlock()
+	default:
+		t.Record(providerName, modelID, status.Latency, nil)
+	}
+}
+
+// Stats returns the latency statistics of a provider/model, and false if
+// nothing has been recorded for it.
+func (t *LatencyTracker) Stats(providerName, modelID string) (LatencyStats, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	s, ok := t.series[latencyKey{providerName, modelID}]
+	if !ok {
+		return LatencyStats{}, false
+	}
+
+	stats := LatencyStats{
+		Samples: len(s.samples),
+		Healthy: s.healthy,
+		Updated: s.updated,
+	}
+	if len(s.samples) > 0 {
+		sorted := slices.Sorted(slices.Values(s.samples))
+		stats.P50 = percentile(sorted, 50)
+		stats.P95 = percentile(sorted, 95)
+	}
+	return stats, true
+}
+
+// seriesFor returns the series of a provider/model, creating it. The caller
+// holds t.mu.
+func (t *LatencyTracker) seriesFor(providerName, modelID string) *latencySeries {
+	key := latencyKey{providerName, modelID}
+	s, ok := t.series[key]
+	if !ok {
+		s = &latencySeries{healthy: true}
+		t.series[key] = s
+	}
+	return s
+}
+
+// percentile returns the nearest-rank percentile of sorted samples.
+func percentile(sorted []time.Duration, p int) time.Duration {
+	rank := (p*len(sorted) + 99) / 100 // ceil(p/100 * n)
+	return sorted[max(rank, 1)-1]
+}
@@ -0,0 +1,56 @@
+package provider
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jbctechsolutions/skillrunner/internal/application/ports"
+)
+
+func TestLatencyTracker_Percentiles(t *testing.T) {
+	tracker := NewLatencyTracker(10)
+	for i := 1; i <= 20; i++ {
+		tracker.Record("openai", "gpt-4o", time.Duration(i)*time.Millisecond, nil)
+	}
+
+	stats, ok := tracker.Stats("openai", "gpt-4o")
+	if !ok {
+		t.Fatal("expected stats")
+	}
+	// Only the last 10 samples (11..20 ms) are kept
+	if stats.Samples != 10 || stats.P50 != 15*time.Millisecond || stats.P95 != 20*time.Millisecond {
+		t.Errorf("stats = %+v", stats)
+	}
+	if !stats.Healthy {
+		t.Error("expected healthy")
+	}
+
+	if _, ok := tracker.Stats("openai", "gpt-4o-mini"); ok {
+		t.Error("expected no stats for an unseen model")
+	}
+}
+
+func TestLatencyTracker_Health(t *testing.T) {
+	tracker := NewLatencyTracker(0)
+
+	tracker.Record("groq", "llama", 0, errors.New("timeout"))
+	if stats, _ := tracker.Stats("groq", "llama"); stats.Healthy || stats.Samples != 0 {
+		t.Errorf("failed request: stats = %+v", stats)
+	}
+
+	tracker.RecordHealth("groq", "llama", &ports.HealthStatus{Healthy: true, Latency: 40 * time.Millisecond}, nil)
+	if stats, _ := tracker.Stats("groq", "llama"); !stats.Healthy || stats.P50 != 40*time.Millisecond {
+		t.Errorf("healthy check: stats = %+v", stats)
+	}
+
+	tracker.RecordHealth("groq", "llama", &ports.HealthStatus{Healthy: false}, nil)
+	if stats, _ := tracker.Stats("groq", "llama"); stats.Healthy || stats.Samples != 1 {
+		t.Errorf("unhealthy check: stats = %+v", stats)
+	}
+
+	tracker.RecordHealth("ollama", "llama", &ports.HealthStatus{Healthy: true}, nil)
+	if stats, _ := tracker.Stats("ollama", "llama"); !stats.Healthy || stats.Samples != 0 {
+		t.Errorf("check without latency: stats = %+v", stats)
+	}
+}
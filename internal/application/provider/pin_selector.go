@@ -0,0 +1,150 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	adapterProvider "github.com/jbctechsolutions/skillrunner/internal/adapters/provider"
+	"github.com/jbctechsolutions/skillrunner/internal/application/ports"
+	"github.com/jbctechsolutions/skillrunner/internal/domain/skill"
+)
+
+// ErrPinnedProviderUnavailable is returned when a phase is hard-pinned to a
+// provider that is not registered or fails its health check.
+var ErrPinnedProviderUnavailable = errors.New("pinned provider unavailable")
+
+// ErrPinnedModelUnavailable is reported when no registered provider serves a
+// phase's pinned model.
+var ErrPinnedModelUnavailable = errors.New("pinned model unavailable")
+
+// PinFallbackHandler is notified when a soft-pinned phase falls back to the
+// default provider because its pinned provider is unavailable, or a
+// model-pinned phase falls back to profile routing because no provider
+// serves its model.
+type PinFallbackHandler func(phase *skill.Phase, fallback ports.ProviderPort, reason error)
+
+// PinSelector honors per-phase provider pins (skill.Phase.Provider).
+// Hard pins fail the phase when the pinned provider is unhealthy, which gives
+// deterministic behavior for compliance-sensitive phases; soft pins
+// (pin_soft: true) fall back to the default provider with a warning.
+// Phases pinned only to a model (skill.Phase.Model) run on a provider that
+// serves it, falling back to the default provider with a warning.
+// Health is checked once per provider and cached for the selector's lifetime,
+// so a selector should be created per run.
+type PinSelector struct {
+	registry   *adapterProvider.Registry
+	onFallback PinFallbackHandler
+
+	mu     sync.Mutex
+	health map[string]error // nil value means the provider is healthy
+}
+
+// NewPinSelector creates a new PinSelector backed by the given registry.
+// onFallback may be nil.
+func NewPinSelector(registry *adapterProvider.Registry, onFallback PinFallbackHandler) *PinSelector {
+	return &PinSelector{
+		registry:   registry,
+		onFallback: onFallback,
+		health:     make(map[string]error),
+	}
+}
+
+// SelectProvider returns the provider that should execute the phase.
+// Unpinned phases always use defaultProvider.
+func (s *PinSelector) SelectProvider(ctx context.Context, phase *skill.Phase, defaultProvider ports.ProviderPort) (ports.ProviderPort, error) {
+	if phase == nil {
+		return defaultProvider, nil
+	}
+	if !phase.IsPinned() {
+		return s.modelProvider(ctx, phase, defaultProvider), nil
+	}
+
+	pinned, err := s.pinnedProvider(ctx, phase.Provider)
+	if err == nil {
+		return pinned, nil
+	}
+
+	if !phase.PinSoft || defaultProvider == nil {
+		return nil, fmt.Errorf("phase %q: %w", phase.ID, err)
+	}
+
+	if s.onFallback != nil {
+		s.onFallback(phase, defaultProvider, err)
+	}
+
+	return defaultProvider, nil
+}
+
+// modelProvider returns the provider for a phase that is not pinned to a
+// provider: defaultProvider when it serves the phase's pinned model, if any,
+// otherwise the first registered provider that has the model available.
+func (s *PinSelector) modelProvider(ctx context.Context, phase *skill.Phase, defaultProvider ports.ProviderPort) ports.ProviderPort {
+	if !phase.IsModelPinned() {
+		return defaultProvider
+	}
+	if defaultProvider != nil {
+		if supported, err := defaultProvider.SupportsModel(ctx, phase.Model); err == nil && supported {
+			return defaultProvider
+		}
+	}
+
+	if s.registry != nil {
+		if p, err := s.registry.FindByModel(ctx, phase.Model); err == nil {
+			if available, err := p.IsAvailable(ctx, phase.Model); err == nil && available {
+				return p
+			}
+		}
+	}
+
+	if s.onFallback != nil && defaultProvider != nil {
+		s.onFallback(phase, defaultProvider, fmt.Errorf("%w: %s", ErrPinnedModelUnavailable, phase.Model))
+	}
+	return defaultProvider
+}
+
+// pinnedProvider looks up the pinned provider and verifies it is healthy.
+func (s *PinSelector) pinnedProvider(ctx context.Context, name string) (ports.ProviderPort, error) {
+	if s.registry == nil {
+		return nil, ErrRegistryNil
+	}
+
+	p := s.registry.Get(name)
+	if p == nil {
+		return nil, fmt.Errorf("%w: %s is not configured", ErrPinnedProviderUnavailable, name)
+	}
+
+	if err := s.checkHealth(ctx, name, p); err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
+
+// checkHealth returns the cached health result for a provider, running a
+// health check on first use.
+func (s *PinSelector) checkHealth(ctx context.Context, name string, p ports.ProviderPort) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err, checked := s.health[name]; checked {
+		return err
+	}
+
+	var healthErr error
+	status, err := p.HealthCheck(ctx, "")
+	switch {
+	case err != nil:
+		healthErr = fmt.Errorf("%w: %s: %v", ErrPinnedProviderUnavailable, name, err)
+	case status == nil || !status.Healthy:
+		msg := "unhealthy"
+		if status != nil && status.Message != "" {
+			msg = status.Message
+		}
+		healthErr = fmt.Errorf("%w: %s: %s", ErrPinnedProviderUnavailable, name, msg)
+	}
+
+	s.health[name] = healthErr
+	return healthErr
+}
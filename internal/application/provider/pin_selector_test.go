@@ -0,0 +1,169 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	adapterProvider "github.com/jbctechsolutions/skillrunner/internal/adapters/provider"
+	"github.com/jbctechsolutions/skillrunner/internal/application/ports"
+	"github.com/jbctechsolutions/skillrunner/internal/domain/skill"
+)
+
+// countingHealthProvider wraps mockProvider to count health checks.
+type countingHealthProvider struct {
+	*mockProvider
+	checks int
+}
+
+func (c *countingHealthProvider) HealthCheck(ctx context.Context, modelID string) (*ports.HealthStatus, error) {
+	c.checks++
+	return c.mockProvider.HealthCheck(ctx, modelID)
+}
+
+func newPinnedPhase(t *testing.T, provider string, soft bool) *skill.Phase {
+	t.Helper()
+	phase, err := skill.NewPhase("p1", "Phase 1", "prompt")
+	if err != nil {
+		t.Fatalf("NewPhase failed: %v", err)
+	}
+	if provider != "" {
+		phase.WithProvider(provider).WithPinSoft(soft)
+	}
+	return phase
+}
+
+func TestPinSelector_UnpinnedUsesDefault(t *testing.T) {
+	registry := adapterProvider.NewRegistry()
+	defaultProvider := newMockProvider("groq")
+	selector := NewPinSelector(registry, nil)
+
+	got, err := selector.SelectProvider(context.Background(), newPinnedPhase(t, "", false), defaultProvider)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != defaultProvider {
+		t.Errorf("expected default provider, got %v", got.Info().Name)
+	}
+}
+
+func TestPinSelector_HealthyPin(t *testing.T) {
+	registry := adapterProvider.NewRegistry()
+	pinned := &countingHealthProvider{mockProvider: newMockProvider("ollama")}
+	if err := registry.Register(pinned); err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+	selector := NewPinSelector(registry, nil)
+
+	for range 3 {
+		got, err := selector.SelectProvider(context.Background(), newPinnedPhase(t, "ollama", false), newMockProvider("groq"))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got.Info().Name != "ollama" {
+			t.Errorf("expected pinned provider, got %q", got.Info().Name)
+		}
+	}
+
+	if pinned.checks != 1 {
+		t.Errorf("expected health to be checked once, got %d", pinned.checks)
+	}
+}
+
+func TestPinSelector_HardPinFailsFast(t *testing.T) {
+	tests := []struct {
+		name     string
+		register bool
+		healthy  bool
+	}{
+		{"not registered", false, false},
+		{"unhealthy", true, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			registry := adapterProvider.NewRegistry()
+			if tt.register {
+				p := newMockProvider("ollama")
+				p.healthStatus = &ports.HealthStatus{Healthy: tt.healthy, Message: "connection refused"}
+				if err := registry.Register(p); err != nil {
+					t.Fatalf("Register failed: %v", err)
+				}
+			}
+			selector := NewPinSelector(registry, nil)
+
+			_, err := selector.SelectProvider(context.Background(), newPinnedPhase(t, "ollama", false), newMockProvider("groq"))
+			if !errors.Is(err, ErrPinnedProviderUnavailable) {
+				t.Errorf("expected ErrPinnedProviderUnavailable, got %v", err)
+			}
+		})
+	}
+}
+
+func TestPinSelector_SoftPinFallsBack(t *testing.T) {
+	registry := adapterProvider.NewRegistry()
+	pinned := newMockProvider("ollama")
+	pinned.healthErr = errors.New("dial tcp: connection refused")
+	if err := registry.Register(pinned); err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+
+	var warned *skill.Phase
+	var reason error
+	selector := NewPinSelector(registry, func(phase *skill.Phase, fallback ports.ProviderPort, err error) {
+		warned = phase
+		reason = err
+	})
+
+	defaultProvider := newMockProvider("groq")
+	got, err := selector.SelectProvider(context.Background(), newPinnedPhase(t, "ollama", true), defaultProvider)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != defaultProvider {
+		t.Errorf("expected fallback to default provider, got %q", got.Info().Name)
+	}
+	if warned == nil || warned.ID != "p1" {
+		t.Error("expected fallback handler to be called for phase p1")
+	}
+	if !errors.Is(reason, ErrPinnedProviderUnavailable) {
+		t.Errorf("expected fallback reason to wrap ErrPinnedProviderUnavailable, got %v", reason)
+	}
+}
+
+func TestPinSelector_ModelPin(t *testing.T) {
+	registry := adapterProvider.NewRegistry()
+	anthropic := newMockProvider("anthropic").withModels("claude-3-haiku")
+	if err := registry.Register(anthropic); err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+	defaultProvider := newMockProvider("ollama").withModels("llama3.2:3b")
+
+	var fallbacks []error
+	selector := NewPinSelector(registry, func(_ *skill.Phase, _ ports.ProviderPort, reason error) {
+		fallbacks = append(fallbacks, reason)
+	})
+	ctx := context.Background()
+
+	tests := []struct {
+		model string
+		want  string
+	}{
+		{"llama3.2:3b", "ollama"},
+		{"claude-3-haiku", "anthropic"},
+		{"gpt-4o", "ollama"},
+	}
+	for _, tt := range tests {
+		got, err := selector.SelectProvider(ctx, newPinnedPhase(t, "", false).WithModel(tt.model), defaultProvider)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got.Info().Name != tt.want {
+			t.Errorf("model %s: expected provider %q, got %q", tt.model, tt.want, got.Info().Name)
+		}
+	}
+
+	if len(fallbacks) != 1 || !errors.Is(fallbacks[0], ErrPinnedModelUnavailable) {
+		t.Errorf("expected one fallback for the unserved model, got %v", fallbacks)
+	}
+}
@@ -0,0 +1,139 @@
+package provider
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	adapterProvider "github.com/jbctechsolutions/skillrunner/internal/adapters/provider"
+	"github.com/jbctechsolutions/skillrunner/internal/application/ports"
+	"github.com/jbctechsolutions/skillrunner/internal/domain/skill"
+	"github.com/jbctechsolutions/skillrunner/internal/infrastructure/config"
+	"github.com/jbctechsolutions/skillrunner/internal/infrastructure/resources"
+)
+
+// PowerStateTTL is how long a power state reading is reused before the
+// machine is checked again.
+const PowerStateTTL = 30 * time.Second
+
+// PhaseSelector chooses the provider that executes a phase. It has the
+// method set of workflow.PhaseProviderSelector, so selectors can be chained.
+type PhaseSelector interface {
+	SelectProvider(ctx context.Context, phase *skill.Phase, defaultProvider ports.ProviderPort) (ports.ProviderPort, error)
+}
+
+// PowerStateFunc reads the machine's power state.
+type PowerStateFunc func(ctx context.Context) (resources.PowerState, error)
+
+// PowerChangeHandler is notified when the power state starts or stops
+// constraining local inference.
+type PowerChangeHandler func(state resources.PowerState)
+
+// PowerAwareSelector applies a power policy on top of another selector:
+// while the machine runs on battery or under thermal pressure, unpinned
+// phases of the policy's profiles that would run on a local provider run on
+// the first healthy cloud provider instead. The power state is read at most
+// every PowerStateTTL, so phases return to local providers once the machine
+// is plugged in and cool again.
+type PowerAwareSelector struct {
+	next     PhaseSelector
+	registry *adapterProvider.Registry
+	policy   *config.PowerPolicyConfiguration
+	read     PowerStateFunc
+	onChange PowerChangeHandler
+	now      func() time.Time
+
+	mu          sync.Mutex
+	readAt      time.Time
+	constrained bool
+	health      map[string]bool // Cloud provider health, by name
+}
+
+// NewPowerAwareSelector creates a selector applying policy to the providers
+// next selects. onChange may be nil.
+func NewPowerAwareSelector(next PhaseSelector, registry *adapterProvider.Registry, policy *config.PowerPolicyConfiguration, read PowerStateFunc, onChange PowerChangeHandler) *PowerAwareSelector {
+	return &PowerAwareSelector{
+		next:     next,
+		registry: registry,
+		policy:   policy,
+		read:     read,
+		onChange: onChange,
+		now:      time.Now,
+		health:   make(map[string]bool),
+	}
+}
+
+// SelectProvider returns the provider next selects, moved to a cloud
+// provider when the power state is constrained.
+func (s *PowerAwareSelector) SelectProvider(ctx context.Context, phase *skill.Phase, defaultProvider ports.ProviderPort) (ports.ProviderPort, error) {
+	p, err := s.next.SelectProvider(ctx, phase, defaultProvider)
+	if err != nil || p == nil || phase == nil || phase.IsPinned() || phase.IsModelPinned() {
+		return p, err
+	}
+	if !p.Info().IsLocal || !s.policy.AppliesTo(phase.RoutingProfile) || !s.Constrained(ctx) {
+		return p, nil
+	}
+
+	if cloud := s.cloudProvider(ctx); cloud != nil {
+		return cloud, nil
+	}
+	return p, nil
+}
+
+// Constrained reports whether the machine currently runs on battery or
+// under thermal pressure. A power state that cannot be read counts as
+// unconstrained. The change handler is called when the answer changes,
+// starting from unconstrained.
+func (s *PowerAwareSelector) Constrained(ctx context.Context) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	if !s.readAt.IsZero() && now.Sub(s.readAt) < PowerStateTTL {
+		return s.constrained
+	}
+
+	state, err := s.read(ctx)
+	if err != nil {
+		state = resources.PowerState{}
+	}
+	changed := state.Constrained() != s.constrained
+	s.readAt, s.constrained = now, state.Constrained()
+
+	if changed && s.onChange != nil {
+		s.onChange(state)
+	}
+	return s.constrained
+}
+
+// cloudProvider returns the first registered cloud provider that passes a
+// health check, or nil if none does. Health is checked once per provider.
+func (s *PowerAwareSelector) cloudProvider(ctx context.Context) ports.ProviderPort {
+	if s.registry == nil {
+		return nil
+	}
+
+	for _, p := range s.registry.GetCloudProviders() {
+		if s.healthy(ctx, p) {
+			return p
+		}
+	}
+	return nil
+}
+
+// healthy returns the cached health of a cloud provider, checking it on
+// first use.
+func (s *PowerAwareSelector) healthy(ctx context.Context, p ports.ProviderPort) bool {
+	name := p.Info().Name
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if healthy, checked := s.health[name]; checked {
+		return healthy
+	}
+	status, err := p.HealthCheck(ctx, "")
+	healthy := err == nil && status != nil && status.Healthy
+	s.health[name] = healthy
+	return healthy
+}
@@ -0,0 +1,118 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	adapterProvider "github.com/jbctechsolutions/skillrunner/internal/adapters/provider"
+	"github.com/jbctechsolutions/skillrunner/internal/domain/skill"
+	"github.com/jbctechsolutions/skillrunner/internal/infrastructure/config"
+	"github.com/jbctechsolutions/skillrunner/internal/infrastructure/resources"
+)
+
+// powerFixture is a power-aware selector over a local default provider and a
+// registered cloud provider, with a power state the test controls.
+type powerFixture struct {
+	selector *PowerAwareSelector
+	local    *mockProvider
+	state    resources.PowerState
+	reads    int
+	changes  []resources.PowerState
+	now      time.Time
+}
+
+func newPowerFixture(t *testing.T, policy *config.PowerPolicyConfiguration) *powerFixture {
+	t.Helper()
+	f := &powerFixture{local: newMockProvider("ollama"), now: time.Now()}
+	f.local.isLocal = true
+
+	registry := adapterProvider.NewRegistry()
+	for _, p := range []*mockProvider{f.local, newMockProvider("anthropic")} {
+		if err := registry.Register(p); err != nil {
+			t.Fatalf("Register failed: %v", err)
+		}
+	}
+
+	read := func(context.Context) (resources.PowerState, error) {
+		f.reads++
+		return f.state, nil
+	}
+	f.selector = NewPowerAwareSelector(NewPinSelector(registry, nil), registry, policy, read, func(state resources.PowerState) {
+		f.changes = append(f.changes, state)
+	})
+	f.selector.now = func() time.Time { return f.now }
+	return f
+}
+
+func (f *powerFixture) selectFor(t *testing.T, profile string) string {
+	t.Helper()
+	phase := newPinnedPhase(t, "", false).WithRoutingProfile(profile)
+	got, err := f.selector.SelectProvider(context.Background(), phase, f.local)
+	if err != nil {
+		t.Fatalf("SelectProvider failed: %v", err)
+	}
+	return got.Info().Name
+}
+
+func TestPowerAwareSelector_MovesLocalPhasesToCloud(t *testing.T) {
+	f := newPowerFixture(t, &config.PowerPolicyConfiguration{Enabled: true})
+
+	if got := f.selectFor(t, skill.ProfileBalanced); got != "ollama" {
+		t.Errorf("expected local provider on AC power, got %q", got)
+	}
+
+	f.state = resources.PowerState{OnBattery: true}
+	f.now = f.now.Add(PowerStateTTL)
+	if got := f.selectFor(t, skill.ProfileBalanced); got != "anthropic" {
+		t.Errorf("expected cloud provider for balanced phase on battery, got %q", got)
+	}
+	if got := f.selectFor(t, skill.ProfileCheap); got != "ollama" {
+		t.Errorf("expected cheap phase to stay local, got %q", got)
+	}
+
+	f.state = resources.PowerState{}
+	f.now = f.now.Add(PowerStateTTL)
+	if got := f.selectFor(t, skill.ProfilePremium); got != "ollama" {
+		t.Errorf("expected local provider once plugged in, got %q", got)
+	}
+
+	if len(f.changes) != 2 || !f.changes[0].OnBattery || f.changes[1].Constrained() {
+		t.Errorf("expected to be notified on battery and when plugged in, got %+v", f.changes)
+	}
+}
+
+func TestPowerAwareSelector_CachesPowerState(t *testing.T) {
+	f := newPowerFixture(t, &config.PowerPolicyConfiguration{Enabled: true})
+	f.state = resources.PowerState{ThermalPressure: true}
+
+	for range 3 {
+		if got := f.selectFor(t, skill.ProfileBalanced); got != "anthropic" {
+			t.Errorf("expected cloud provider under thermal pressure, got %q", got)
+		}
+	}
+	if f.reads != 1 {
+		t.Errorf("expected the power state to be read once within its TTL, got %d reads", f.reads)
+	}
+}
+
+func TestPowerAwareSelector_KeepsPinsAndLocalWithoutCloud(t *testing.T) {
+	f := newPowerFixture(t, &config.PowerPolicyConfiguration{Enabled: true, Profiles: []string{skill.ProfileCheap}})
+	f.state = resources.PowerState{OnBattery: true}
+
+	pinned := newPinnedPhase(t, "ollama", false).WithRoutingProfile(skill.ProfileCheap)
+	got, err := f.selector.SelectProvider(context.Background(), pinned, f.local)
+	if err != nil {
+		t.Fatalf("SelectProvider failed: %v", err)
+	}
+	if got.Info().Name != "ollama" {
+		t.Errorf("expected pinned phase to keep its provider, got %q", got.Info().Name)
+	}
+
+	cloud := f.selector.registry.Get("anthropic").(*mockProvider)
+	cloud.healthErr = errors.New("connection refused")
+	if got := f.selectFor(t, skill.ProfileCheap); got != "ollama" {
+		t.Errorf("expected local provider when no cloud provider is healthy, got %q", got)
+	}
+}
@@ -12,6 +12,7 @@ import (
 	domainProvider "github.com/jbctechsolutions/skillrunner/internal/domain/provider"
 	"github.com/jbctechsolutions/skillrunner/internal/domain/skill"
 	"github.com/jbctechsolutions/skillrunner/internal/infrastructure/config"
+	"github.com/jbctechsolutions/skillrunner/internal/infrastructure/tracing"
 )
 
 // Resolver errors
@@ -66,7 +67,10 @@ func NewResolver(router *Router, registry *adapterProvider.Registry, cfg *config
 
 // Resolve selects a model based on the given routing profile and returns
 // a complete resolution including model configuration and cost estimate.
-func (r *Resolver) Resolve(ctx context.Context, profile string) (*Resolution, error) {
+func (r *Resolver) Resolve(ctx context.Context, profile string) (res *Resolution, err error) {
+	ctx, span := tracing.Default().StartRoutingSpan(ctx, "resolver.resolve", profile)
+	defer func() { endResolveSpan(span, res, err) }()
+
 	selection, err := r.router.SelectModel(ctx, profile)
 	if err != nil {
 		return nil, fmt.Errorf("%w: %v", ErrModelNotResolved, err)
@@ -77,14 +81,17 @@ func (r *Resolver) Resolve(ctx context.Context, profile string) (*Resolution, er
 
 // ResolveForPhase selects a model based on the phase's routing requirements.
 // It considers the phase type (generation vs review) and routing profile.
-func (r *Resolver) ResolveForPhase(ctx context.Context, phase *skill.Phase) (*Resolution, error) {
+func (r *Resolver) ResolveForPhase(ctx context.Context, phase *skill.Phase) (res *Resolution, err error) {
 	if phase == nil {
 		return nil, errors.New("phase is nil")
 	}
 
+	ctx, span := tracing.Default().StartRoutingSpan(ctx, "resolver.resolve", phase.RoutingProfile)
+	defer func() { endResolveSpan(span, res, err) }()
+
 	selection, err := r.router.SelectModelForPhase(ctx, phase)
 	if err != nil {
-		return nil, fmt.Errorf("%w: %v", ErrModelNotResolved, err)
+		return nil, fmt.Errorf("%w: %w", ErrModelNotResolved, err)
 	}
 
 	return r.buildResolution(selection)
@@ -92,10 +99,13 @@ func (r *Resolver) ResolveForPhase(ctx context.Context, phase *skill.Phase) (*Re
 
 // ResolveWithCapabilities selects a model that has all the required capabilities.
 // Falls back to regular resolution if no model with all capabilities is found.
-func (r *Resolver) ResolveWithCapabilities(ctx context.Context, profile string, capabilities []string) (*Resolution, error) {
+func (r *Resolver) ResolveWithCapabilities(ctx context.Context, profile string, capabilities []string) (res *Resolution, err error) {
+	ctx, span := tracing.Default().StartRoutingSpan(ctx, "resolver.resolve", profile)
+	defer func() { endResolveSpan(span, res, err) }()
+
 	selection, err := r.router.SelectModelWithCapabilities(ctx, profile, capabilities)
 	if err != nil {
-		return nil, fmt.Errorf("%w: %v", ErrModelNotResolved, err)
+		return nil, fmt.Errorf("%w: %w", ErrModelNotResolved, err)
 	}
 
 	resolution, err := r.buildResolution(selection)
@@ -114,6 +124,16 @@ func (r *Resolver) ResolveWithCapabilities(ctx context.Context, profile string,
 	return resolution, nil
 }
 
+// endResolveSpan records the resolution, or the error, on span and ends it.
+func endResolveSpan(span *tracing.RoutingSpan, res *Resolution, err error) {
+	if err != nil {
+		span.EndWithError(err)
+		return
+	}
+	span.SetSelection(res.ProviderName, res.ModelID, res.IsFallback)
+	span.End()
+}
+
 // buildResolution converts a ModelSelection to a complete Resolution.
 func (r *Resolver) buildResolution(selection *ModelSelection) (*Resolution, error) {
 	if selection == nil {
@@ -5,11 +5,15 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"math/rand/v2"
 	"sync"
 
 	adapterProvider "github.com/jbctechsolutions/skillrunner/internal/adapters/provider"
+	"github.com/jbctechsolutions/skillrunner/internal/application/ports"
 	"github.com/jbctechsolutions/skillrunner/internal/domain/skill"
 	"github.com/jbctechsolutions/skillrunner/internal/infrastructure/config"
+	"github.com/jbctechsolutions/skillrunner/internal/infrastructure/tokenizer"
+	"github.com/jbctechsolutions/skillrunner/internal/infrastructure/tracing"
 )
 
 // Router errors
@@ -22,6 +26,8 @@ var (
 	ErrModelNotSupported = errors.New("model not supported by any provider")
 	ErrConfigurationNil  = errors.New("routing configuration is nil")
 	ErrRegistryNil       = errors.New("provider registry is nil")
+
+	ErrContextWindowExceeded = errors.New("prompt exceeds the model's context window")
 )
 
 // ModelSelection represents the result of model selection.
@@ -38,6 +44,9 @@ type Router struct {
 	mu       sync.RWMutex
 	config   *config.RoutingConfiguration
 	registry *adapterProvider.Registry
+	counters *tokenizer.Counters
+	latency  *LatencyTracker
+	randIntN func(n int) int // Picks weighted generation models
 }
 
 // NewRouter creates a new Router with the given configuration and registry.
@@ -53,6 +62,9 @@ func NewRouter(cfg *config.RoutingConfiguration, registry *adapterProvider.Regis
 	return &Router{
 		config:   cfg,
 		registry: registry,
+		counters: tokenizer.NewCounters(),
+		latency:  NewLatencyTracker(DefaultLatencyWindow),
+		randIntN: rand.IntN,
 	}, nil
 }
 
@@ -60,7 +72,15 @@ func NewRouter(cfg *config.RoutingConfiguration, registry *adapterProvider.Regis
 // It returns the model ID and provider name for the selected model.
 // If the primary model is unavailable, it attempts to use the fallback model.
 func (r *Router) SelectModel(ctx context.Context, profile string) (*ModelSelection, error) {
-	if !isValidProfile(profile) {
+	ctx, span := tracing.Default().StartRoutingSpan(ctx, "router.select_model", profile)
+	selection, err := r.selectModel(ctx, profile)
+	endRoutingSpan(span, selection, err)
+	return selection, err
+}
+
+// selectModel implements SelectModel.
+func (r *Router) selectModel(ctx context.Context, profile string) (*ModelSelection, error) {
+	if !r.isValidProfile(profile) {
 		return nil, fmt.Errorf("%w: %s", ErrInvalidProfile, profile)
 	}
 
@@ -73,15 +93,20 @@ func (r *Router) SelectModel(ctx context.Context, profile string) (*ModelSelecti
 	}
 
 	// Try the generation model first (default for general selection)
-	modelID := profileConfig.GenerationModel
+	if profileConfig.Selection == config.SelectionFastest {
+		if selection := r.selectFastest(ctx, profileConfig.GenerationModelIDs(), profileConfig.FallbackModel); selection != nil {
+			return selection, nil
+		}
+	}
+	modelID := r.generationModel(ctx, profileConfig)
 	if modelID != "" {
 		providerName, available := r.findAvailableProvider(ctx, modelID)
 		if available {
-			return &ModelSelection{
+			return r.preferUnthrottled(ctx, profile, &ModelSelection{
 				ModelID:      modelID,
 				ProviderName: providerName,
 				IsFallback:   false,
-			}, nil
+			}), nil
 		}
 	}
 
@@ -91,13 +116,25 @@ func (r *Router) SelectModel(ctx context.Context, profile string) (*ModelSelecti
 
 // SelectModelForPhase selects a model based on the phase's routing profile.
 // It chooses between generation and review models based on the phase configuration.
+// Unless the phase is pinned, a model lacking a capability the phase
+// requires is replaced by one that has them all, and a *CapabilityError
+// is returned if there is none.
 func (r *Router) SelectModelForPhase(ctx context.Context, phase *skill.Phase) (*ModelSelection, error) {
 	if phase == nil {
 		return nil, errors.New("phase is nil")
 	}
 
+	ctx, span := tracing.Default().StartRoutingSpan(ctx, "router.select_model_for_phase", phase.RoutingProfile)
+	selection, err := r.selectModelForPhase(ctx, phase)
+	endRoutingSpan(span, selection, err)
+	return selection, err
+}
+
+// selectModelForPhase implements SelectModelForPhase.
+func (r *Router) selectModelForPhase(ctx context.Context, phase *skill.Phase) (*ModelSelection, error) {
+
 	profile := phase.RoutingProfile
-	if !isValidProfile(profile) {
+	if !r.isValidProfile(profile) {
 		profile = skill.ProfileBalanced // Default to balanced
 	}
 
@@ -109,17 +146,102 @@ func (r *Router) SelectModelForPhase(ctx context.Context, phase *skill.Phase) (*
 		return nil, fmt.Errorf("%w: %s", ErrNoProfileConfig, profile)
 	}
 
+	if phase.IsPinned() || phase.IsModelPinned() {
+		selection, err := r.pinnedSelection(ctx, phase, profileConfig)
+		if selection != nil || err != nil {
+			return selection, err
+		}
+		selection, err = r.selectProfileModel(ctx, phase, profile, profileConfig)
+		if selection != nil {
+			selection.IsFallback = true
+		}
+		return selection, err
+	}
+
+	selection, err := r.selectProfileModel(ctx, phase, profile, profileConfig)
+	if len(phase.Capabilities) > 0 && (err != nil || !r.hasCapabilities(selection, phase.Capabilities)) {
+		if capable := r.findCapableModel(ctx, phase.Capabilities); capable != nil {
+			capable.IsFallback = true
+			return capable, nil
+		}
+		return nil, r.capabilityError(ctx, profile, phase.Capabilities)
+	}
+	return selection, err
+}
+
+// endRoutingSpan records the selection, or the error, on span and ends it.
+func endRoutingSpan(span *tracing.RoutingSpan, selection *ModelSelection, err error) {
+	switch {
+	case err != nil:
+		span.EndWithError(err)
+	case selection != nil:
+		span.SetSelection(selection.ProviderName, selection.ModelID, selection.IsFallback)
+		span.End()
+	default:
+		span.End()
+	}
+}
+
+// pinnedSelection honors a phase's provider and model pins. Provider-only
+// pins use the profile's model when the provider serves it, otherwise the
+// provider's first model. It returns nil when the pins cannot be satisfied
+// and the phase may fall back to profile routing, and an error wrapping
+// ErrPinnedProviderUnavailable when a hard-pinned provider cannot serve it.
+func (r *Router) pinnedSelection(ctx context.Context, phase *skill.Phase, profileConfig *config.ProfileConfiguration) (*ModelSelection, error) {
+	if !phase.IsPinned() {
+		if providerName, available := r.findAvailableProvider(ctx, phase.Model); available {
+			return &ModelSelection{ModelID: phase.Model, ProviderName: providerName}, nil
+		}
+		return nil, nil
+	}
+
+	if p := r.registry.Get(phase.Provider); p != nil {
+		modelID := phase.Model
+		if modelID == "" {
+			modelID = r.selectModelForPhaseType(ctx, phase, profileConfig)
+			if supported, err := p.SupportsModel(ctx, modelID); err != nil || !supported {
+				modelID = ""
+				if models, err := p.ListModels(ctx); err == nil && len(models) > 0 {
+					modelID = models[0]
+				}
+			}
+		}
+		if modelID != "" {
+			if available, err := p.IsAvailable(ctx, modelID); err == nil && available {
+				return &ModelSelection{ModelID: modelID, ProviderName: phase.Provider}, nil
+			}
+		}
+	}
+
+	if phase.PinSoft {
+		return nil, nil
+	}
+	return nil, fmt.Errorf("%w: %s", ErrPinnedProviderUnavailable, phase.Provider)
+}
+
+// selectProfileModel selects a model for the phase from its routing profile.
+func (r *Router) selectProfileModel(ctx context.Context, phase *skill.Phase, profile string, profileConfig *config.ProfileConfiguration) (*ModelSelection, error) {
 	// Determine which model to use based on phase characteristics
-	modelID := r.selectModelForPhaseType(phase, profileConfig)
+	if profileConfig.Selection == config.SelectionFastest {
+		models := profileConfig.GenerationModelIDs()
+		if isReviewPhase(phase) && profileConfig.ReviewModel != "" {
+			models = []string{profileConfig.ReviewModel}
+		}
+		if selection := r.selectFastest(ctx, models, profileConfig.FallbackModel); selection != nil {
+			return selection, nil
+		}
+	}
+
+	modelID := r.selectModelForPhaseType(ctx, phase, profileConfig)
 
 	if modelID != "" {
 		providerName, available := r.findAvailableProvider(ctx, modelID)
 		if available {
-			return &ModelSelection{
+			return r.preferUnthrottled(ctx, profile, &ModelSelection{
 				ModelID:      modelID,
 				ProviderName: providerName,
 				IsFallback:   false,
-			}, nil
+			}), nil
 		}
 	}
 
@@ -129,7 +251,7 @@ func (r *Router) SelectModelForPhase(ctx context.Context, phase *skill.Phase) (*
 
 // selectModelForPhaseType determines the appropriate model based on phase type.
 // Review phases use the review model, all others use the generation model.
-func (r *Router) selectModelForPhaseType(phase *skill.Phase, profileConfig *config.ProfileConfiguration) string {
+func (r *Router) selectModelForPhaseType(ctx context.Context, phase *skill.Phase, profileConfig *config.ProfileConfiguration) string {
 	// Check if this is a review phase by looking at the phase ID or name
 	if isReviewPhase(phase) {
 		if profileConfig.ReviewModel != "" {
@@ -138,6 +260,47 @@ func (r *Router) selectModelForPhaseType(phase *skill.Phase, profileConfig *conf
 	}
 
 	// Default to generation model
+	return r.generationModel(ctx, profileConfig)
+}
+
+// generationModel returns the profile's generation model. With weighted
+// generation_models, it picks one of the available models at random in
+// proportion to their weights, preferring providers that are not throttled,
+// and falls back to generation_model when none is available.
+func (r *Router) generationModel(ctx context.Context, profileConfig *config.ProfileConfiguration) string {
+	var available, unthrottled []config.WeightedModel
+	for _, m := range profileConfig.GenerationModels {
+		if m.Weight <= 0 {
+			continue
+		}
+		providerName, ok := r.findAvailableProvider(ctx, m.Model)
+		if !ok {
+			continue
+		}
+		available = append(available, m)
+		if !r.isThrottled(providerName) {
+			unthrottled = append(unthrottled, m)
+		}
+	}
+	if len(unthrottled) > 0 {
+		available = unthrottled
+	}
+
+	var total int
+	for _, m := range available {
+		total += m.Weight
+	}
+	if total == 0 {
+		return profileConfig.GenerationModel
+	}
+
+	n := r.randIntN(total)
+	for _, m := range available {
+		if n < m.Weight {
+			return m.Model
+		}
+		n -= m.Weight
+	}
 	return profileConfig.GenerationModel
 }
 
@@ -194,7 +357,64 @@ func toLower(s string) string {
 // GetFallbackModel returns the fallback model for the given profile.
 // It tries the profile's fallback model first, then walks the fallback chain.
 func (r *Router) GetFallbackModel(ctx context.Context, profile string) (*ModelSelection, error) {
-	if !isValidProfile(profile) {
+	return r.fallbackModel(ctx, profile, false)
+}
+
+// ProfileProvider returns the provider and model the given profile selects,
+// as SelectModel selects them.
+func (r *Router) ProfileProvider(ctx context.Context, profile string) (ports.ProviderPort, string, error) {
+	selection, err := r.SelectModel(ctx, profile)
+	if err != nil {
+		return nil, "", err
+	}
+	return r.selectionProvider(selection, ErrProviderNotFound)
+}
+
+// FallbackProvider returns the provider and model the given profile falls
+// back to, as GetFallbackModel selects them.
+func (r *Router) FallbackProvider(ctx context.Context, profile string) (ports.ProviderPort, string, error) {
+	selection, err := r.GetFallbackModel(ctx, profile)
+	if err != nil {
+		return nil, "", err
+	}
+	return r.selectionProvider(selection, ErrNoFallbackModel)
+}
+
+// selectionProvider returns the registered provider and model of selection,
+// or notFound if its provider is not registered.
+func (r *Router) selectionProvider(selection *ModelSelection, notFound error) (ports.ProviderPort, string, error) {
+	p := r.registry.Get(selection.ProviderName)
+	if p == nil {
+		return nil, "", fmt.Errorf("%w: %s is not registered", notFound, selection.ProviderName)
+	}
+	return p, selection.ModelID, nil
+}
+
+// preferUnthrottled returns selection unless its provider reports that its
+// rate limit is nearly exhausted. Then it returns the profile's fallback on
+// a provider that is not throttled, if there is one, so requests move along
+// the fallback chain before the provider starts rejecting them.
+func (r *Router) preferUnthrottled(ctx context.Context, profile string, selection *ModelSelection) *ModelSelection {
+	if !r.isThrottled(selection.ProviderName) {
+		return selection
+	}
+	if fallback, err := r.fallbackModel(ctx, profile, true); err == nil {
+		return fallback
+	}
+	return selection
+}
+
+// isThrottled reports whether a provider is pacing requests because its
+// reported rate limit is low.
+func (r *Router) isThrottled(providerName string) bool {
+	t, ok := r.registry.Get(providerName).(ports.ThrottleReporter)
+	return ok && t.Throttled()
+}
+
+// fallbackModel implements GetFallbackModel, skipping throttled providers if
+// unthrottledOnly is set.
+func (r *Router) fallbackModel(ctx context.Context, profile string, unthrottledOnly bool) (*ModelSelection, error) {
+	if !r.isValidProfile(profile) {
 		return nil, fmt.Errorf("%w: %s", ErrInvalidProfile, profile)
 	}
 
@@ -206,7 +426,7 @@ func (r *Router) GetFallbackModel(ctx context.Context, profile string) (*ModelSe
 	// Try the profile's configured fallback model
 	if profileConfig != nil && profileConfig.FallbackModel != "" {
 		providerName, available := r.findAvailableProvider(ctx, profileConfig.FallbackModel)
-		if available {
+		if available && !(unthrottledOnly && r.isThrottled(providerName)) {
 			return &ModelSelection{
 				ModelID:      profileConfig.FallbackModel,
 				ProviderName: providerName,
@@ -218,7 +438,7 @@ func (r *Router) GetFallbackModel(ctx context.Context, profile string) (*ModelSe
 	// Try the fallback chain (providers in order of preference)
 	for _, providerName := range fallbackChain {
 		provider := r.registry.Get(providerName)
-		if provider == nil {
+		if provider == nil || (unthrottledOnly && r.isThrottled(providerName)) {
 			continue
 		}
 
@@ -332,57 +552,33 @@ func isEmbeddingModel(modelID string) bool {
 	return false
 }
 
-// isValidProfile checks if the profile is a valid routing profile.
-func isValidProfile(profile string) bool {
-	switch profile {
-	case skill.ProfileCheap, skill.ProfileBalanced, skill.ProfilePremium:
-		return true
-	default:
-		return false
-	}
+// isValidProfile checks if the profile is a built-in routing profile or a
+// custom profile defined in the loaded configuration.
+func (r *Router) isValidProfile(profile string) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.config.HasProfile(profile)
 }
 
 // SelectModelWithCapabilities selects a model that has the required capabilities.
-// Returns an error if no model with the required capabilities is available.
+// Without one it falls back to regular selection, and returns a
+// *CapabilityError listing the closest models if that fails too.
 func (r *Router) SelectModelWithCapabilities(ctx context.Context, profile string, capabilities []string) (*ModelSelection, error) {
-	if !isValidProfile(profile) {
+	if !r.isValidProfile(profile) {
 		return nil, fmt.Errorf("%w: %s", ErrInvalidProfile, profile)
 	}
 
-	r.mu.RLock()
-	providers := r.config.Providers
-	r.mu.RUnlock()
-
-	// Search through all providers for a model with matching capabilities
-	for providerName, providerConfig := range providers {
-		if !providerConfig.Enabled {
-			continue
-		}
-
-		for modelID, modelConfig := range providerConfig.Models {
-			if !modelConfig.Enabled {
-				continue
-			}
-
-			// Check if model has all required capabilities
-			if hasAllCapabilities(modelConfig, capabilities) {
-				// Verify model is actually available
-				providerFound, available := r.findAvailableProvider(ctx, modelID)
-				if available {
-					return &ModelSelection{
-						ModelID:      modelID,
-						ProviderName: providerFound,
-						IsFallback:   false,
-					}, nil
-				}
-			}
-			// Avoid unused variable warning
-			_ = providerName
-		}
+	// Search the enabled providers in priority order for a capable model
+	if selection := r.findCapableModel(ctx, capabilities); selection != nil {
+		return selection, nil
 	}
 
 	// Fall back to regular selection
-	return r.SelectModel(ctx, profile)
+	selection, err := r.SelectModel(ctx, profile)
+	if errors.Is(err, ErrNoFallbackModel) && len(capabilities) > 0 {
+		return nil, r.capabilityError(ctx, profile, capabilities)
+	}
+	return selection, err
 }
 
 // hasAllCapabilities checks if the model has all the required capabilities.
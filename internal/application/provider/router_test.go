@@ -56,9 +56,7 @@ func (m *mockProvider) withAvailableModel(modelID string, available bool) *mockP
 	return m
 }
 
-// withLocal sets whether the mock provider is local (kept for future use).
-var _ = (*mockProvider).withLocal
-
+// withLocal sets whether the mock provider is local.
 func (m *mockProvider) withLocal(isLocal bool) *mockProvider {
 	m.isLocal = isLocal
 	return m
@@ -547,6 +545,237 @@ func TestSelectModelForPhase(t *testing.T) {
 	})
 }
 
+// throttledProvider is a mock provider that reports whether its rate limit
+// is low.
+type throttledProvider struct {
+	*mockProvider
+	throttled bool
+}
+
+func (p *throttledProvider) Throttled() bool {
+	return p.throttled
+}
+
+func TestSelectModelForPhase_CustomProfile(t *testing.T) {
+	cfg := newTestRoutingConfig()
+	cfg.Profiles["reasoning"] = &config.ProfileConfiguration{GenerationModel: "llama3.2:3b", MaxContextTokens: 8192}
+	registry := adapterProvider.NewRegistry()
+	if err := registry.Register(newMockProvider("ollama").withModels("llama3.2:3b", "llama3.2:8b")); err != nil {
+		t.Fatalf("failed to register provider: %v", err)
+	}
+	router, err := NewRouter(cfg, registry)
+	if err != nil {
+		t.Fatalf("NewRouter() error = %v", err)
+	}
+
+	selection, err := router.SelectModelForPhase(context.Background(), &skill.Phase{ID: "think", RoutingProfile: "reasoning"})
+	if err != nil {
+		t.Fatalf("SelectModelForPhase() error = %v", err)
+	}
+	if selection.ModelID != "llama3.2:3b" {
+		t.Errorf("SelectModelForPhase() ModelID = %q, want the custom profile's model", selection.ModelID)
+	}
+
+	if _, err := router.SelectModel(context.Background(), "offline"); !errors.Is(err, ErrInvalidProfile) {
+		t.Errorf("SelectModel() error = %v, want %v for an undefined custom profile", err, ErrInvalidProfile)
+	}
+}
+
+func TestSelectModelForPhase_Pins(t *testing.T) {
+	newPinRouter := func(t *testing.T) *Router {
+		t.Helper()
+		registry := adapterProvider.NewRegistry()
+		for _, p := range []*mockProvider{
+			newMockProvider("ollama").withModels("llama3.2:8b"),
+			newMockProvider("anthropic").withModels("claude-3-5-sonnet-20241022", "claude-3-haiku"),
+		} {
+			if err := registry.Register(p); err != nil {
+				t.Fatalf("failed to register provider: %v", err)
+			}
+		}
+		router, err := NewRouter(newTestRoutingConfig(), registry)
+		if err != nil {
+			t.Fatalf("NewRouter() error = %v", err)
+		}
+		return router
+	}
+	newPhase := func(provider, model string, soft bool) *skill.Phase {
+		return &skill.Phase{ID: "draft", Name: "Draft", RoutingProfile: skill.ProfileBalanced, Provider: provider, Model: model, PinSoft: soft}
+	}
+
+	tests := []struct {
+		name         string
+		phase        *skill.Phase
+		wantModel    string
+		wantProvider string
+		wantFallback bool
+		wantErr      error
+	}{
+		{"provider and model", newPhase("anthropic", "claude-3-haiku", false), "claude-3-haiku", "anthropic", false, nil},
+		{"provider only", newPhase("anthropic", "", false), "claude-3-5-sonnet-20241022", "anthropic", false, nil},
+		{"model only", newPhase("", "claude-3-haiku", false), "claude-3-haiku", "anthropic", false, nil},
+		{"unknown model", newPhase("", "gpt-4o", false), "llama3.2:8b", "ollama", true, nil},
+		{"soft pin unavailable", newPhase("groq", "", true), "llama3.2:8b", "ollama", true, nil},
+		{"hard pin unavailable", newPhase("groq", "", false), "", "", false, ErrPinnedProviderUnavailable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			selection, err := newPinRouter(t).SelectModelForPhase(context.Background(), tt.phase)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("SelectModelForPhase() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("SelectModelForPhase() error = %v", err)
+			}
+			if selection.ModelID != tt.wantModel || selection.ProviderName != tt.wantProvider || selection.IsFallback != tt.wantFallback {
+				t.Errorf("SelectModelForPhase() = %+v, want %s on %s (fallback %v)", selection, tt.wantModel, tt.wantProvider, tt.wantFallback)
+			}
+		})
+	}
+}
+
+func TestSelectModel_Throttled(t *testing.T) {
+	t.Run("reroutes along the fallback chain", func(t *testing.T) {
+		cfg := newTestRoutingConfig()
+		registry := adapterProvider.NewRegistry()
+		_ = registry.Register(&throttledProvider{mockProvider: newMockProvider("ollama").withModels("llama3.2:8b"), throttled: true})
+		_ = registry.Register(newMockProvider("anthropic").withModels("claude-3-5-sonnet-20241022"))
+
+		router, err := NewRouter(cfg, registry)
+		if err != nil {
+			t.Fatalf("NewRouter() error = %v", err)
+		}
+
+		selection, err := router.SelectModel(context.Background(), skill.ProfileBalanced)
+		if err != nil {
+			t.Fatalf("SelectModel() error = %v", err)
+		}
+		if selection.ProviderName != "anthropic" || !selection.IsFallback {
+			t.Errorf("SelectModel() = %s/%s (fallback %v), want the next provider in the chain",
+				selection.ProviderName, selection.ModelID, selection.IsFallback)
+		}
+	})
+
+	t.Run("keeps the selection when every provider is throttled", func(t *testing.T) {
+		cfg := newTestRoutingConfig()
+		registry := adapterProvider.NewRegistry()
+		_ = registry.Register(&throttledProvider{mockProvider: newMockProvider("ollama").withModels("llama3.2:8b"), throttled: true})
+		_ = registry.Register(&throttledProvider{mockProvider: newMockProvider("anthropic").withModels("claude-3-5-sonnet-20241022"), throttled: true})
+
+		router, err := NewRouter(cfg, registry)
+		if err != nil {
+			t.Fatalf("NewRouter() error = %v", err)
+		}
+
+		selection, err := router.SelectModel(context.Background(), skill.ProfileBalanced)
+		if err != nil {
+			t.Fatalf("SelectModel() error = %v", err)
+		}
+		if selection.ProviderName != "ollama" || selection.IsFallback {
+			t.Errorf("SelectModel() = %s/%s, want the primary selection", selection.ProviderName, selection.ModelID)
+		}
+	})
+
+	t.Run("GetFallbackModel ignores throttling", func(t *testing.T) {
+		cfg := newTestRoutingConfig()
+		registry := adapterProvider.NewRegistry()
+		_ = registry.Register(&throttledProvider{mockProvider: newMockProvider("ollama").withModels("llama3.2:3b"), throttled: true})
+
+		router, err := NewRouter(cfg, registry)
+		if err != nil {
+			t.Fatalf("NewRouter() error = %v", err)
+		}
+
+		selection, err := router.GetFallbackModel(context.Background(), skill.ProfileBalanced)
+		if err != nil {
+			t.Fatalf("GetFallbackModel() error = %v", err)
+		}
+		if selection.ProviderName != "ollama" {
+			t.Errorf("GetFallbackModel() ProviderName = %q, want %q", selection.ProviderName, "ollama")
+		}
+	})
+}
+
+func TestSelectModel_Weighted(t *testing.T) {
+	newWeightedRouter := func(t *testing.T, providers ...ports.ProviderPort) *Router {
+		t.Helper()
+		cfg := newTestRoutingConfig()
+		cfg.Profiles[skill.ProfileBalanced].GenerationModels = []config.WeightedModel{
+			{Model: "gpt-4o", Weight: 70},
+			{Model: "claude-3-5-sonnet-20241022", Weight: 30},
+			{Model: "llama3.2:8b", Weight: 0},
+		}
+		registry := adapterProvider.NewRegistry()
+		for _, p := range providers {
+			_ = registry.Register(p)
+		}
+		router, err := NewRouter(cfg, registry)
+		if err != nil {
+			t.Fatalf("NewRouter() error = %v", err)
+		}
+		// Step through every value so the split is exact
+		next := 0
+		router.randIntN = func(n int) int {
+			next++
+			return (next - 1) % n
+		}
+		return router
+	}
+
+	t.Run("distributes by weight", func(t *testing.T) {
+		router := newWeightedRouter(t,
+			newMockProvider("ollama").withModels("llama3.2:8b"),
+			newMockProvider("openai").withModels("gpt-4o"),
+			newMockProvider("anthropic").withModels("claude-3-5-sonnet-20241022"))
+
+		counts := make(map[string]int)
+		for range 100 {
+			selection, err := router.SelectModel(context.Background(), skill.ProfileBalanced)
+			if err != nil {
+				t.Fatalf("SelectModel() error = %v", err)
+			}
+			counts[selection.ModelID]++
+		}
+		if counts["gpt-4o"] != 70 || counts["claude-3-5-sonnet-20241022"] != 30 {
+			t.Errorf("SelectModel() split = %v, want 70/30 and none for the drained model", counts)
+		}
+	})
+
+	t.Run("skips unavailable and throttled models", func(t *testing.T) {
+		router := newWeightedRouter(t,
+			&throttledProvider{mockProvider: newMockProvider("openai").withModels("gpt-4o"), throttled: true},
+			newMockProvider("anthropic").withModels("claude-3-5-sonnet-20241022"))
+
+		for range 10 {
+			selection, err := router.SelectModel(context.Background(), skill.ProfileBalanced)
+			if err != nil {
+				t.Fatalf("SelectModel() error = %v", err)
+			}
+			if selection.ModelID != "claude-3-5-sonnet-20241022" || selection.IsFallback {
+				t.Fatalf("SelectModel() = %s, want the unthrottled weighted model", selection.ModelID)
+			}
+		}
+	})
+
+	t.Run("review phases keep the review model", func(t *testing.T) {
+		router := newWeightedRouter(t, newMockProvider("openai").withModels("gpt-4o", "llama3.2:8b"))
+		router.config.Profiles[skill.ProfileBalanced].ReviewModel = "llama3.2:8b"
+
+		phase := &skill.Phase{ID: "review", Name: "Review", RoutingProfile: skill.ProfileBalanced}
+		selection, err := router.SelectModelForPhase(context.Background(), phase)
+		if err != nil {
+			t.Fatalf("SelectModelForPhase() error = %v", err)
+		}
+		if selection.ModelID != "llama3.2:8b" {
+			t.Errorf("SelectModelForPhase() = %s, want the review model", selection.ModelID)
+		}
+	})
+}
+
 func TestGetFallbackModel(t *testing.T) {
 	t.Run("uses profile fallback model", func(t *testing.T) {
 		cfg := newTestRoutingConfig()
@@ -674,6 +903,56 @@ func TestGetFallbackModel(t *testing.T) {
 	})
 }
 
+func TestRouter_FallbackProvider(t *testing.T) {
+	registry := adapterProvider.NewRegistry()
+	ollama := newMockProvider("ollama").withModels("llama3.2:3b")
+	if err := registry.Register(ollama); err != nil {
+		t.Fatalf("failed to register provider: %v", err)
+	}
+	router, err := NewRouter(newTestRoutingConfig(), registry)
+	if err != nil {
+		t.Fatalf("NewRouter() error = %v", err)
+	}
+
+	p, model, err := router.FallbackProvider(context.Background(), skill.ProfileBalanced)
+	if err != nil {
+		t.Fatalf("FallbackProvider() error = %v", err)
+	}
+	if p != ollama || model != "llama3.2:3b" {
+		t.Errorf("FallbackProvider() = %s, %q; want ollama, llama3.2:3b", p.Info().Name, model)
+	}
+
+	if _, _, err := router.FallbackProvider(context.Background(), "missing"); !errors.Is(err, ErrInvalidProfile) {
+		t.Errorf("FallbackProvider() of an unknown profile error = %v, want ErrInvalidProfile", err)
+	}
+}
+
+func TestRouter_ProfileProvider(t *testing.T) {
+	cfg := newTestRoutingConfig()
+	cfg.Profiles["reasoning"] = &config.ProfileConfiguration{GenerationModel: "llama3.2:3b", MaxContextTokens: 8192}
+	registry := adapterProvider.NewRegistry()
+	ollama := newMockProvider("ollama").withModels("llama3.2:3b")
+	if err := registry.Register(ollama); err != nil {
+		t.Fatalf("failed to register provider: %v", err)
+	}
+	router, err := NewRouter(cfg, registry)
+	if err != nil {
+		t.Fatalf("NewRouter() error = %v", err)
+	}
+
+	p, model, err := router.ProfileProvider(context.Background(), "reasoning")
+	if err != nil {
+		t.Fatalf("ProfileProvider() error = %v", err)
+	}
+	if p != ollama || model != "llama3.2:3b" {
+		t.Errorf("ProfileProvider() = %s, %q; want ollama, llama3.2:3b", p.Info().Name, model)
+	}
+
+	if _, _, err := router.ProfileProvider(context.Background(), "offline"); !errors.Is(err, ErrInvalidProfile) {
+		t.Errorf("ProfileProvider() of an undefined profile error = %v, want ErrInvalidProfile", err)
+	}
+}
+
 func TestSelectModelWithCapabilities(t *testing.T) {
 	t.Run("selects model with required capabilities", func(t *testing.T) {
 		cfg := newTestRoutingConfig()
@@ -1048,6 +1327,13 @@ func TestGetDefaultProvider(t *testing.T) {
 }
 
 func TestIsValidProfile(t *testing.T) {
+	cfg := newTestRoutingConfig()
+	cfg.Profiles["reasoning"] = &config.ProfileConfiguration{GenerationModel: "llama3.2:8b"}
+	router, err := NewRouter(cfg, adapterProvider.NewRegistry())
+	if err != nil {
+		t.Fatalf("NewRouter() error = %v", err)
+	}
+
 	tests := []struct {
 		profile string
 		want    bool
@@ -1055,6 +1341,8 @@ func TestIsValidProfile(t *testing.T) {
 		{skill.ProfileCheap, true},
 		{skill.ProfileBalanced, true},
 		{skill.ProfilePremium, true},
+		{"reasoning", true}, // Custom profile defined in the config
+		{"offline", false},  // Custom profile not defined
 		{"invalid", false},
 		{"", false},
 		{"CHEAP", false},    // Case sensitive
@@ -1063,7 +1351,7 @@ func TestIsValidProfile(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.profile, func(t *testing.T) {
-			got := isValidProfile(tt.profile)
+			got := router.isValidProfile(tt.profile)
 			if got != tt.want {
 				t.Errorf("isValidProfile(%q) = %v, want %v", tt.profile, got, tt.want)
 			}
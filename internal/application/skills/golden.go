@@ -0,0 +1,123 @@
+package skills
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/jbctechsolutions/skillrunner/internal/domain/skill"
+	"github.com/jbctechsolutions/skillrunner/internal/domain/workflow"
+)
+
+// DefaultMinSimilarity is the similarity to a golden run's output below which
+// a phase output counts as a regression.
+const DefaultMinSimilarity = 0.8
+
+// Golden run errors.
+var (
+	ErrGoldenNotPinned     = errors.New("run is not pinned as a golden run")
+	ErrGoldenSkillMismatch = errors.New("golden run is of a different skill")
+)
+
+// PhaseComparison compares a phase's output with the golden run's.
+type PhaseComparison struct {
+	PhaseID    string  `json:"phase"`
+	Similarity float64 `json:"similarity"`        // 0 (nothing in common) to 1 (identical)
+	Missing    string  `json:"missing,omitempty"` // "golden" or "current" when a side has no output
+	Passed     bool    `json:"passed"`
+}
+
+// GoldenComparison is the result of comparing a run with a golden run.
+type GoldenComparison struct {
+	GoldenRunID   string            `json:"golden_run_id"`
+	MinSimilarity float64           `json:"min_similarity"`
+	Phases        []PhaseComparison `json:"phases"`
+}
+
+// Regressions returns the phases whose output drifted from the golden run.
+func (c *GoldenComparison) Regressions() []PhaseComparison {
+	var failed []PhaseComparison
+	for _, p := range c.Phases {
+		if !p.Passed {
+			failed = append(failed, p)
+		}
+	}
+	return failed
+}
+
+// CheckGoldenRun returns an error unless golden is a pinned run of sk, and
+// so a usable baseline for it.
+func CheckGoldenRun(golden *workflow.WorkflowCheckpoint, sk *skill.Skill) error {
+	if !golden.Pinned() {
+		return fmt.Errorf("%w: %s; pin it with sr history pin", ErrGoldenNotPinned, golden.ID())
+	}
+	if golden.SkillID() != sk.ID() {
+		return fmt.Errorf("%w: %s ran %s, not %s", ErrGoldenSkillMismatch, golden.ID(), golden.SkillID(), sk.ID())
+	}
+	return nil
+}
+
+// CompareWithGolden compares the phase outputs of a run with those of the
+// golden run, phase by phase. A phase passes when its output is at least
+// minSimilarity similar to the golden output; a phase missing on either side
+// fails.
+func CompareWithGolden(golden *workflow.WorkflowCheckpoint, outputs map[string]string, minSimilarity float64) *GoldenComparison {
+	baseline := golden.PhaseOutputs()
+	result := &GoldenComparison{GoldenRunID: golden.ID(), MinSimilarity: minSimilarity}
+
+	ids := make([]string, 0, len(baseline)+len(outputs))
+	for id := range baseline {
+		ids = append(ids, id)
+	}
+	for id := range outputs {
+		if _, ok := baseline[id]; !ok {
+			ids = append(ids, id)
+		}
+	}
+	slices.Sort(ids)
+
+	for _, id := range ids {
+		want, inGolden := baseline[id]
+		got, inCurrent := outputs[id]
+		cmp := PhaseComparison{PhaseID: id}
+		switch {
+		case !inGolden:
+			cmp.Missing = "golden"
+		case !inCurrent:
+			cmp.Missing = "current"
+		default:
+			cmp.Similarity = OutputSimilarity(want, got)
+			cmp.Passed = cmp.Similarity >= minSimilarity
+		}
+		result.Phases = append(result.Phases, cmp)
+	}
+	return result
+}
+
+// OutputSimilarity returns how similar two outputs are, from 0 to 1: twice
+// the number of words in their longest common subsequence over the total
+// number of words. Whitespace differences are ignored.
+func OutputSimilarity(a, b string) float64 {
+	wa, wb := strings.Fields(a), strings.Fields(b)
+	if len(wa)+len(wb) == 0 {
+		return 1
+	}
+
+	// Longest common subsequence, keeping one row of the table
+	row := make([]int, len(wb)+1)
+	for i := range wa {
+		diag := 0
+		for j := range wb {
+			up := row[j+1]
+			if wa[i] == wb[j] {
+				row[j+1] = diag + 1
+			} else {
+				row[j+1] = max(row[j+1], row[j])
+			}
+			diag = up
+		}
+	}
+
+	return 2 * float64(row[len(wb)]) / float64(len(wa)+len(wb))
+}
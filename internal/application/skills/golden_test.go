@@ -0,0 +1,105 @@
+package skills
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/jbctechsolutions/skillrunner/internal/domain/skill"
+	"github.com/jbctechsolutions/skillrunner/internal/domain/workflow"
+)
+
+func newGoldenRun(t *testing.T, outputs map[string]string) *workflow.WorkflowCheckpoint {
+	t.Helper()
+	cp, err := workflow.NewWorkflowCheckpoint("cp-golden", "exec-golden", "opt-skill", "Opt", "input", 1)
+	if err != nil {
+		t.Fatalf("NewWorkflowCheckpoint failed: %v", err)
+	}
+	cp.SetPhaseOutputs(outputs)
+	cp.SetPinned(true)
+	return cp
+}
+
+func TestOutputSimilarity(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want float64
+	}{
+		{"", "", 1},
+		{"same words here", "same  words\nhere", 1},
+		{"a b c d", "", 0},
+		{"a b c d", "a b x d", 0.75},
+		{"one two", "three four", 0},
+	}
+	for _, tt := range tests {
+		if got := OutputSimilarity(tt.a, tt.b); math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("OutputSimilarity(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestCompareWithGolden(t *testing.T) {
+	golden := newGoldenRun(t, map[string]string{
+		"analyze": "the function leaks a file handle",
+		"review":  "approve with one change",
+		"summary": "looks good",
+	})
+
+	result := CompareWithGolden(golden, map[string]string{
+		"analyze": "the function leaks a file handle",
+		"review":  "reject the change entirely",
+		"extra":   "new phase",
+	}, DefaultMinSimilarity)
+
+	want := map[string]struct {
+		passed  bool
+		missing string
+	}{
+		"analyze": {true, ""},
+		"extra":   {false, "golden"},
+		"review":  {false, ""},
+		"summary": {false, "current"},
+	}
+	if len(result.Phases) != len(want) {
+		t.Fatalf("Phases = %+v, want %d", result.Phases, len(want))
+	}
+	for _, p := range result.Phases {
+		w := want[p.PhaseID]
+		if p.Passed != w.passed || p.Missing != w.missing {
+			t.Errorf("phase %s = %+v, want passed=%v missing=%q", p.PhaseID, p, w.passed, w.missing)
+		}
+	}
+	if n := len(result.Regressions()); n != 3 {
+		t.Errorf("Regressions() = %d, want 3", n)
+	}
+}
+
+func TestCheckGoldenRun(t *testing.T) {
+	phase, err := skill.NewPhase("main", "Main", "{{._input}}")
+	if err != nil {
+		t.Fatalf("NewPhase failed: %v", err)
+	}
+	sk, err := skill.NewSkill("opt-skill", "Opt", "1.0.0", []skill.Phase{*phase})
+	if err != nil {
+		t.Fatalf("NewSkill failed: %v", err)
+	}
+
+	golden := newGoldenRun(t, nil)
+	if err := CheckGoldenRun(golden, sk); err != nil {
+		t.Errorf("CheckGoldenRun() error = %v", err)
+	}
+
+	golden.SetPinned(false)
+	if err := CheckGoldenRun(golden, sk); !errors.Is(err, ErrGoldenNotPinned) {
+		t.Errorf("CheckGoldenRun() of an unpinned run = %v, want ErrGoldenNotPinned", err)
+	}
+
+	other, err := skill.NewSkill("other", "Other", "1.0.0", []skill.Phase{*phase})
+	if err != nil {
+		t.Fatalf("NewSkill failed: %v", err)
+	}
+	golden.SetPinned(true)
+	if err := CheckGoldenRun(golden, other); !errors.Is(err, ErrGoldenSkillMismatch) {
+		t.Errorf("CheckGoldenRun() for another skill = %v, want ErrGoldenSkillMismatch", err)
+	}
+}
@@ -0,0 +1,255 @@
+package skills
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/jbctechsolutions/skillrunner/internal/application/ports"
+	"github.com/jbctechsolutions/skillrunner/internal/domain/skill"
+)
+
+// Optimizer defaults.
+const (
+	// DefaultOptimizerMaxRuns is the number of recent runs inspected.
+	DefaultOptimizerMaxRuns = 50
+
+	// DefaultOptimizerMinRuns is the number of runs required before a
+	// downgrade is suggested, so a single lucky run does not drive advice.
+	DefaultOptimizerMinRuns = 3
+
+	// lightOutputTokens is the output size below which a phase is considered
+	// not to need premium generation capability.
+	lightOutputTokens = 512
+
+	// truncationRatio is the share of max_tokens at which output is
+	// considered truncated.
+	truncationRatio = 0.95
+
+	// failureRateThreshold is the failure rate above which a phase is flagged.
+	failureRateThreshold = 0.25
+)
+
+// ErrSkillRequired is returned when the optimizer is given a nil skill.
+var ErrSkillRequired = errors.New("skill is required")
+
+// profileOrder ranks routing profiles from cheapest to most capable.
+var profileOrder = []string{skill.RoutingProfileCheap, skill.RoutingProfileBalanced, skill.RoutingProfilePremium}
+
+// SuggestionKind classifies a skill optimization suggestion.
+type SuggestionKind string
+
+const (
+	SuggestDowngradeProfile SuggestionKind = "downgrade_profile"
+	SuggestUpgradeProfile   SuggestionKind = "upgrade_profile"
+	SuggestRaiseMaxTokens   SuggestionKind = "raise_max_tokens"
+	SuggestReviewPin        SuggestionKind = "review_pin"
+	SuggestReviewFailures   SuggestionKind = "review_failures"
+)
+
+// Suggestion is a concrete change proposed for a skill phase.
+type Suggestion struct {
+	Kind    SuggestionKind `json:"kind"`
+	PhaseID string         `json:"phase"`
+	Message string         `json:"message"`
+}
+
+// PhaseUsage aggregates a phase's recorded executions.
+type PhaseUsage struct {
+	PhaseID         string `json:"phase"`
+	RoutingProfile  string `json:"routing_profile"`
+	Runs            int    `json:"runs"`
+	Failures        int    `json:"failures"`
+	Fallbacks       int    `json:"fallbacks"` // Runs served by a provider other than the pinned one
+	Truncated       int    `json:"truncated"` // Runs whose output reached max_tokens
+	AvgInputTokens  int    `json:"avg_input_tokens"`
+	AvgOutputTokens int    `json:"avg_output_tokens"`
+	MaxInputTokens  int    `json:"max_input_tokens"`
+	MaxOutputTokens int    `json:"max_output_tokens"`
+	MaxTotalTokens  int    `json:"max_total_tokens"`
+}
+
+// OptimizationReport is the result of analyzing a skill's run history.
+type OptimizationReport struct {
+	SkillID      string       `json:"skill"`
+	RunsAnalyzed int          `json:"runs_analyzed"`
+	Phases       []PhaseUsage `json:"phases"`
+	Suggestions  []Suggestion `json:"suggestions"`
+}
+
+// OptimizerConfig configures an Optimizer.
+type OptimizerConfig struct {
+	MaxRuns int // Recent runs to inspect (default DefaultOptimizerMaxRuns)
+	MinRuns int // Runs required before suggesting a downgrade (default DefaultOptimizerMinRuns)
+
+	// ContextLimits maps routing profiles to their maximum context tokens.
+	// Profiles without a limit are never considered overflowing.
+	ContextLimits map[string]int
+}
+
+// Optimizer inspects a skill's run history and suggests configuration changes.
+type Optimizer struct {
+	checkpoints ports.WorkflowCheckpointPort
+	config      OptimizerConfig
+}
+
+// NewOptimizer creates a new Optimizer reading run history from checkpoints.
+func NewOptimizer(checkpoints ports.WorkflowCheckpointPort, config OptimizerConfig) *Optimizer {
+	if config.MaxRuns <= 0 {
+		config.MaxRuns = DefaultOptimizerMaxRuns
+	}
+	if config.MinRuns <= 0 {
+		config.MinRuns = DefaultOptimizerMinRuns
+	}
+	return &Optimizer{checkpoints: checkpoints, config: config}
+}
+
+// Analyze aggregates per-phase token usage, failures and pin fallbacks for
+// the skill's recent runs and derives suggestions from them.
+func (o *Optimizer) Analyze(ctx context.Context, sk *skill.Skill) (*OptimizationReport, error) {
+	if sk == nil {
+		return nil, ErrSkillRequired
+	}
+
+	runs, err := o.checkpoints.List(ctx, &ports.WorkflowCheckpointFilter{
+		SkillID: sk.ID(),
+		Limit:   o.config.MaxRuns,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load run history: %w", err)
+	}
+
+	report := &OptimizationReport{
+		SkillID:      sk.ID(),
+		RunsAnalyzed: len(runs),
+	}
+
+	for _, phase := range sk.Phases() {
+		usage := PhaseUsage{PhaseID: phase.ID, RoutingProfile: phase.RoutingProfile}
+		var totalIn, totalOut int
+
+		for _, run := range runs {
+			data, ok := run.PhaseResults()[phase.ID]
+			if !ok {
+				continue
+			}
+
+			usage.Runs++
+			if data.Status == "failed" {
+				usage.Failures++
+				continue
+			}
+
+			totalIn += data.InputTokens
+			totalOut += data.OutputTokens
+			usage.MaxInputTokens = max(usage.MaxInputTokens, data.InputTokens)
+			usage.MaxOutputTokens = max(usage.MaxOutputTokens, data.OutputTokens)
+			usage.MaxTotalTokens = max(usage.MaxTotalTokens, data.InputTokens+data.OutputTokens)
+
+			if phase.IsPinned() && data.ProviderUsed != "" && data.ProviderUsed != phase.Provider {
+				usage.Fallbacks++
+			}
+			if phase.MaxTokens > 0 && float64(data.OutputTokens) >= truncationRatio*float64(phase.MaxTokens) {
+				usage.Truncated++
+			}
+		}
+
+		if succeeded := usage.Runs - usage.Failures; succeeded > 0 {
+			usage.AvgInputTokens = totalIn / succeeded
+			usage.AvgOutputTokens = totalOut / succeeded
+		}
+
+		report.Phases = append(report.Phases, usage)
+		report.Suggestions = append(report.Suggestions, o.suggest(&phase, usage)...)
+	}
+
+	return report, nil
+}
+
+// suggest derives suggestions for a single phase.
+func (o *Optimizer) suggest(phase *skill.Phase, usage PhaseUsage) []Suggestion {
+	var suggestions []Suggestion
+	succeeded := usage.Runs - usage.Failures
+
+	if usage.Runs > 0 && float64(usage.Failures)/float64(usage.Runs) >= failureRateThreshold {
+		suggestions = append(suggestions, Suggestion{
+			Kind:    SuggestReviewFailures,
+			PhaseID: phase.ID,
+			Message: fmt.Sprintf("phase %q failed in %d of %d runs; review its prompt or provider", phase.ID, usage.Failures, usage.Runs),
+		})
+	}
+
+	if limit, ok := o.config.ContextLimits[usage.RoutingProfile]; ok && limit > 0 && usage.MaxTotalTokens > limit {
+		suggestions = append(suggestions, Suggestion{
+			Kind:    SuggestUpgradeProfile,
+			PhaseID: phase.ID,
+			Message: fmt.Sprintf("phase %q used up to %d tokens, exceeding the %s tier context of %d; use a larger profile or trim its inputs",
+				phase.ID, usage.MaxTotalTokens, usage.RoutingProfile, limit),
+		})
+	}
+
+	if succeeded > 0 && usage.Truncated*2 >= succeeded {
+		suggestions = append(suggestions, Suggestion{
+			Kind:    SuggestRaiseMaxTokens,
+			PhaseID: phase.ID,
+			Message: fmt.Sprintf("phase %q hit max_tokens (%d) in %d of %d runs; output is likely truncated, raise max_tokens",
+				phase.ID, phase.MaxTokens, usage.Truncated, succeeded),
+		})
+	}
+
+	if usage.Fallbacks > 0 {
+		suggestions = append(suggestions, Suggestion{
+			Kind:    SuggestReviewPin,
+			PhaseID: phase.ID,
+			Message: fmt.Sprintf("phase %q fell back from pinned provider %q in %d of %d runs; check that provider's health or unpin the phase",
+				phase.ID, phase.Provider, usage.Fallbacks, succeeded),
+		})
+	}
+
+	if succeeded >= o.config.MinRuns && usage.MaxOutputTokens < lightOutputTokens {
+		if cheaper := o.cheapestFittingProfile(usage); cheaper != "" &&
+			slices.Index(profileOrder, cheaper) < slices.Index(profileOrder, usage.RoutingProfile) {
+			suggestions = append(suggestions, Suggestion{
+				Kind:    SuggestDowngradeProfile,
+				PhaseID: phase.ID,
+				Message: fmt.Sprintf("phase %q never produced more than %d output tokens across %d runs and fits the %s context; downgrade routing_profile from %s to %s",
+					phase.ID, usage.MaxOutputTokens, succeeded, cheaper, usage.RoutingProfile, cheaper),
+			})
+		}
+	}
+
+	slices.SortStableFunc(suggestions, func(a, b Suggestion) int {
+		return cmp.Compare(suggestionPriority(a.Kind), suggestionPriority(b.Kind))
+	})
+
+	return suggestions
+}
+
+// cheapestFittingProfile returns the cheapest profile whose context limit
+// holds the phase's largest recorded request, or "" if none is configured.
+func (o *Optimizer) cheapestFittingProfile(usage PhaseUsage) string {
+	for _, profile := range profileOrder {
+		if limit, ok := o.config.ContextLimits[profile]; ok && limit >= usage.MaxTotalTokens {
+			return profile
+		}
+	}
+	return ""
+}
+
+// suggestionPriority orders suggestions: correctness problems before savings.
+func suggestionPriority(kind SuggestionKind) int {
+	switch kind {
+	case SuggestReviewFailures:
+		return 0
+	case SuggestUpgradeProfile:
+		return 1
+	case SuggestRaiseMaxTokens:
+		return 2
+	case SuggestReviewPin:
+		return 3
+	default:
+		return 4
+	}
+}
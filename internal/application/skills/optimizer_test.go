@@ -0,0 +1,154 @@
+package skills
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"testing"
+
+	"github.com/jbctechsolutions/skillrunner/internal/application/ports"
+	"github.com/jbctechsolutions/skillrunner/internal/domain/skill"
+	"github.com/jbctechsolutions/skillrunner/internal/domain/workflow"
+)
+
+// historyStub serves a fixed run history. Only List is used by the optimizer;
+// the embedded port panics if anything else is called.
+type historyStub struct {
+	ports.WorkflowCheckpointPort
+	runs []*workflow.WorkflowCheckpoint
+}
+
+func (h *historyStub) List(_ context.Context, filter *ports.WorkflowCheckpointFilter) ([]*workflow.WorkflowCheckpoint, error) {
+	var result []*workflow.WorkflowCheckpoint
+	for _, run := range h.runs {
+		if filter == nil || filter.SkillID == "" || run.SkillID() == filter.SkillID {
+			result = append(result, run)
+		}
+	}
+	return result, nil
+}
+
+func newHistoryRun(t *testing.T, n int, results map[string]*workflow.PhaseResultData) *workflow.WorkflowCheckpoint {
+	t.Helper()
+	cp, err := workflow.NewWorkflowCheckpoint(fmt.Sprintf("cp-%d", n), fmt.Sprintf("exec-%d", n), "opt-skill", "Opt", "input", 1)
+	if err != nil {
+		t.Fatalf("NewWorkflowCheckpoint failed: %v", err)
+	}
+	for id, data := range results {
+		cp.AddPhaseResult(id, data)
+	}
+	return cp
+}
+
+func newOptimizerSkill(t *testing.T) *skill.Skill {
+	t.Helper()
+
+	extract, err := skill.NewPhase("extract", "Extract", "{{.input}}")
+	if err != nil {
+		t.Fatalf("NewPhase failed: %v", err)
+	}
+	extract.WithRoutingProfile(skill.RoutingProfilePremium)
+
+	summarize, err := skill.NewPhase("summarize", "Summarize", "{{.input}}")
+	if err != nil {
+		t.Fatalf("NewPhase failed: %v", err)
+	}
+	summarize.WithRoutingProfile(skill.RoutingProfileBalanced).WithMaxTokens(1000).WithProvider("ollama").WithPinSoft(true)
+
+	sk, err := skill.NewSkill("opt-skill", "Opt", "1.0.0", []skill.Phase{*extract, *summarize})
+	if err != nil {
+		t.Fatalf("NewSkill failed: %v", err)
+	}
+	return sk
+}
+
+func TestOptimizer_Analyze(t *testing.T) {
+	history := &historyStub{}
+	for i := range 4 {
+		history.runs = append(history.runs, newHistoryRun(t, i, map[string]*workflow.PhaseResultData{
+			"extract":   {Status: "completed", InputTokens: 1500, OutputTokens: 200, ProviderUsed: "anthropic"},
+			"summarize": {Status: "completed", InputTokens: 9000, OutputTokens: 990, ProviderUsed: "groq"},
+		}))
+	}
+
+	optimizer := NewOptimizer(history, OptimizerConfig{
+		ContextLimits: map[string]int{
+			skill.RoutingProfileCheap:    4096,
+			skill.RoutingProfileBalanced: 8192,
+			skill.RoutingProfilePremium:  128000,
+		},
+	})
+
+	report, err := optimizer.Analyze(context.Background(), newOptimizerSkill(t))
+	if err != nil {
+		t.Fatalf("Analyze failed: %v", err)
+	}
+
+	if report.RunsAnalyzed != 4 {
+		t.Errorf("RunsAnalyzed = %d, want 4", report.RunsAnalyzed)
+	}
+	if report.Phases[0].AvgOutputTokens != 200 {
+		t.Errorf("extract AvgOutputTokens = %d, want 200", report.Phases[0].AvgOutputTokens)
+	}
+
+	want := []struct {
+		kind  SuggestionKind
+		phase string
+	}{
+		{SuggestDowngradeProfile, "extract"},
+		{SuggestUpgradeProfile, "summarize"},
+		{SuggestRaiseMaxTokens, "summarize"},
+		{SuggestReviewPin, "summarize"},
+	}
+	for _, w := range want {
+		found := slices.ContainsFunc(report.Suggestions, func(s Suggestion) bool {
+			return s.Kind == w.kind && s.PhaseID == w.phase
+		})
+		if !found {
+			t.Errorf("missing %s suggestion for %s; got %+v", w.kind, w.phase, report.Suggestions)
+		}
+	}
+}
+
+func TestOptimizer_NotEnoughRunsForDowngrade(t *testing.T) {
+	history := &historyStub{runs: []*workflow.WorkflowCheckpoint{
+		newHistoryRun(t, 0, map[string]*workflow.PhaseResultData{
+			"extract": {Status: "completed", InputTokens: 100, OutputTokens: 50},
+		}),
+	}}
+
+	optimizer := NewOptimizer(history, OptimizerConfig{
+		ContextLimits: map[string]int{skill.RoutingProfileCheap: 4096},
+	})
+
+	report, err := optimizer.Analyze(context.Background(), newOptimizerSkill(t))
+	if err != nil {
+		t.Fatalf("Analyze failed: %v", err)
+	}
+	for _, s := range report.Suggestions {
+		if s.Kind == SuggestDowngradeProfile {
+			t.Errorf("unexpected downgrade suggestion from a single run: %s", s.Message)
+		}
+	}
+}
+
+func TestOptimizer_FailureRate(t *testing.T) {
+	history := &historyStub{runs: []*workflow.WorkflowCheckpoint{
+		newHistoryRun(t, 0, map[string]*workflow.PhaseResultData{"extract": {Status: "failed"}}),
+		newHistoryRun(t, 1, map[string]*workflow.PhaseResultData{"extract": {Status: "completed", OutputTokens: 10}}),
+	}}
+
+	report, err := NewOptimizer(history, OptimizerConfig{}).Analyze(context.Background(), newOptimizerSkill(t))
+	if err != nil {
+		t.Fatalf("Analyze failed: %v", err)
+	}
+	if len(report.Suggestions) == 0 || report.Suggestions[0].Kind != SuggestReviewFailures {
+		t.Errorf("expected failure review suggestion first, got %+v", report.Suggestions)
+	}
+}
+
+func TestOptimizer_NilSkill(t *testing.T) {
+	if _, err := NewOptimizer(&historyStub{}, OptimizerConfig{}).Analyze(context.Background(), nil); err != ErrSkillRequired {
+		t.Errorf("expected ErrSkillRequired, got %v", err)
+	}
+}
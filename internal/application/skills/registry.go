@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"sync"
 	"time"
 
@@ -28,6 +29,7 @@ type Registry struct {
 	builtInDir string
 	userDir    string
 	projectDir string
+	policyDirs []string
 }
 
 // NewRegistry creates a new SkillRegistry with the given loader.
@@ -61,6 +63,14 @@ func (r *Registry) SetProjectDir(dir string) {
 	r.projectDir = dir
 }
 
+// SetPolicyDirs sets the skill directories required by the organization
+// policy.
+func (r *Registry) SetPolicyDirs(dirs []string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.policyDirs = dirs
+}
+
 // ProjectDir returns the project skills directory path.
 func (r *Registry) ProjectDir() string {
 	r.mu.RLock()
@@ -161,8 +171,69 @@ func (r *Registry) LoadUserSkills(dir string) error {
 	return nil
 }
 
-// LoadAll loads skills from both built-in and user directories.
-// User skills take precedence over built-in skills with the same ID.
+// LoadProjectSkills loads skills from the project skills directory, if one is
+// set. Project skills override built-in and user skills with the same ID.
+func (r *Registry) LoadProjectSkills() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.projectDir == "" {
+		return nil
+	}
+
+	// Check if directory exists
+	if _, err := os.Stat(r.projectDir); os.IsNotExist(err) {
+		// Not an error - project skills are optional
+		return nil
+	}
+
+	skills, err := r.loader.LoadSkillsDir(r.projectDir)
+	if err != nil {
+		return fmt.Errorf("failed to load project skills: %w", err)
+	}
+
+	for id, s := range skills {
+		r.skills[id] = s
+	}
+
+	return nil
+}
+
+// LoadPolicySkills loads skills from the directories required by the
+// organization policy. They override every other skill with the same ID,
+// including ones hot reloaded later, so local skills cannot replace them.
+func (r *Registry) LoadPolicySkills() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var missing []string
+	for _, dir := range r.policyDirs {
+		if _, err := os.Stat(dir); err != nil {
+			missing = append(missing, dir)
+			continue
+		}
+
+		skills, err := r.loader.LoadSkillsDir(dir)
+		if err != nil {
+			return fmt.Errorf("failed to load policy skills: %w", err)
+		}
+		for id, s := range skills {
+			r.skills[id] = s
+			if source, err := skill.NewSkillSource(id, dir, skill.SourcePolicy, time.Now()); err == nil {
+				r.sourceMap[id] = source
+			}
+		}
+	}
+
+	if len(missing) > 0 {
+		return fmt.Errorf("required skill sources not found: %s", strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// LoadAll loads skills from the built-in, user, project and policy
+// directories. User skills take precedence over built-in skills with the
+// same ID, project skills over both, and policy skills over all of them.
 func (r *Registry) LoadAll() error {
 	// Clear existing cache
 	r.mu.Lock()
@@ -182,11 +253,19 @@ func (r *Registry) LoadAll() error {
 		return err
 	}
 
+	// Load project skills (they can override both)
+	if err := r.LoadProjectSkills(); err != nil {
+		return err
+	}
+
+	// Load policy skills (nothing overrides them)
+	policyErr := r.LoadPolicySkills()
+
 	r.mu.Lock()
 	r.loaded = true
 	r.mu.Unlock()
 
-	return nil
+	return policyErr
 }
 
 // GetSkill retrieves a skill by its ID.
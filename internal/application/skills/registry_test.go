@@ -1,6 +1,7 @@
 package skills
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
 	"testing"
@@ -406,6 +407,41 @@ phases:
 	}
 }
 
+func TestProjectSkillsOverrideUser(t *testing.T) {
+	userDir := t.TempDir()
+	projectDir := t.TempDir()
+
+	skillYAML := `
+id: same-skill
+name: %s Version
+version: "1.0.0"
+phases:
+  - id: main
+    name: Main
+    prompt_template: "{{.input}}"
+`
+	if err := os.WriteFile(filepath.Join(userDir, "skill.yaml"), []byte(fmt.Sprintf(skillYAML, "User")), 0644); err != nil {
+		t.Fatalf("failed to write user skill: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(projectDir, "skill.yaml"), []byte(fmt.Sprintf(skillYAML, "Project")), 0644); err != nil {
+		t.Fatalf("failed to write project skill: %v", err)
+	}
+
+	registry := NewRegistry(infraSkills.NewLoader())
+	registry.SetBuiltInDir(filepath.Join(t.TempDir(), "none"))
+	registry.SetUserDir(userDir)
+	registry.SetProjectDir(projectDir)
+
+	if err := registry.LoadAll(); err != nil {
+		t.Fatalf("failed to load all skills: %v", err)
+	}
+
+	s := registry.GetSkill("same-skill")
+	if s == nil || s.Name() != "Project Version" {
+		t.Errorf("expected the project version, got %v", s)
+	}
+}
+
 func TestRegisterWithSource(t *testing.T) {
 	loader := infraSkills.NewLoader()
 	registry := NewRegistry(loader)
@@ -0,0 +1,150 @@
+package skills
+
+import (
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"github.com/jbctechsolutions/skillrunner/internal/domain/skill"
+)
+
+// lookPath finds programs on PATH; tests replace it.
+var lookPath = exec.LookPath
+
+// RequirementStatus reports whether a program a skill requires is on PATH.
+type RequirementStatus struct {
+	Name string `json:"name"`
+	Path string `json:"path,omitempty"` // Where the program was found; empty if missing
+	Hint string `json:"hint,omitempty"` // How to install a missing program
+}
+
+// Found reports whether the program is on PATH.
+func (r RequirementStatus) Found() bool {
+	return r.Path != ""
+}
+
+// MissingRequirementsError lists the programs a skill requires that are not
+// on PATH, with a hint on how to install each.
+type MissingRequirementsError struct {
+	SkillID string
+	Missing []RequirementStatus
+}
+
+func (e *MissingRequirementsError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "skill %s requires programs that are not on PATH:", e.SkillID)
+	for _, r := range e.Missing {
+		fmt.Fprintf(&b, "\n  %s: %s", r.Name, r.Hint)
+	}
+	return b.String()
+}
+
+// CheckRequirements looks up each program the skill requires on PATH.
+// Missing programs get the skill's install hint, or a built-in one for
+// common tools.
+func CheckRequirements(sk *skill.Skill) []RequirementStatus {
+	requires := sk.Requires()
+	statuses := make([]RequirementStatus, 0, len(requires))
+	for _, r := range requires {
+		status := RequirementStatus{Name: r.Name}
+		if path, err := lookPath(r.Name); err == nil {
+			status.Path = path
+		} else {
+			status.Hint = r.Hint
+			if status.Hint == "" {
+				status.Hint = InstallHint(r.Name, runtime.GOOS)
+			}
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses
+}
+
+// VerifyRequirements returns a *MissingRequirementsError if a program the
+// skill requires is not on PATH. It runs before a skill so that a missing
+// tool is reported up front rather than by a failing phase.
+func VerifyRequirements(sk *skill.Skill) error {
+	var missing []RequirementStatus
+	for _, status := range CheckRequirements(sk) {
+		if !status.Found() {
+			missing = append(missing, status)
+		}
+	}
+	if len(missing) > 0 {
+		return &MissingRequirementsError{SkillID: sk.ID(), Missing: missing}
+	}
+	return nil
+}
+
+// installHints are install commands of common tools per platform. The ""
+// entry applies to every platform.
+var installHints = map[string]map[string]string{
+	"git": {
+		"darwin":  "brew install git (or xcode-select --install)",
+		"linux":   "sudo apt install git (or your distribution's package manager)",
+		"windows": "winget install Git.Git",
+	},
+	"go":        {"": "install Go from https://go.dev/dl/"},
+	"gofmt":     {"": "gofmt ships with Go; install it from https://go.dev/dl/"},
+	"goimports": {"": "go install golang.org/x/tools/cmd/goimports@latest"},
+	"golangci-lint": {
+		"darwin": "brew install golangci-lint",
+		"":       "see https://golangci-lint.run/welcome/install/",
+	},
+	"node": {
+		"darwin":  "brew install node",
+		"linux":   "sudo apt install nodejs npm (or use nvm)",
+		"windows": "winget install OpenJS.NodeJS",
+	},
+	"npm": {"": "npm ships with Node.js; install it from https://nodejs.org/"},
+	"npx": {"": "npx ships with Node.js; install it from https://nodejs.org/"},
+	"python3": {
+		"darwin":  "brew install python",
+		"linux":   "sudo apt install python3",
+		"windows": "winget install Python.Python.3.12",
+	},
+	"jq": {
+		"darwin":  "brew install jq",
+		"linux":   "sudo apt install jq",
+		"windows": "winget install jqlang.jq",
+	},
+	"rg": {
+		"darwin":  "brew install ripgrep",
+		"linux":   "sudo apt install ripgrep",
+		"windows": "winget install BurntSushi.ripgrep.MSVC",
+	},
+	"gh": {
+		"darwin":  "brew install gh",
+		"windows": "winget install GitHub.cli",
+		"":        "see https://github.com/cli/cli#installation",
+	},
+	"docker": {"": "install Docker from https://docs.docker.com/get-docker/"},
+	"make": {
+		"darwin": "xcode-select --install",
+		"linux":  "sudo apt install make",
+	},
+	"pandoc": {
+		"darwin":  "brew install pandoc",
+		"linux":   "sudo apt install pandoc",
+		"windows": "winget install JohnMacFarlane.Pandoc",
+	},
+	"ffmpeg": {
+		"darwin":  "brew install ffmpeg",
+		"linux":   "sudo apt install ffmpeg",
+		"windows": "winget install Gyan.FFmpeg",
+	},
+}
+
+// InstallHint suggests how to install a program on goos.
+func InstallHint(name, goos string) string {
+	if hints, ok := installHints[name]; ok {
+		if hint, ok := hints[goos]; ok {
+			return hint
+		}
+		if hint, ok := hints[""]; ok {
+			return hint
+		}
+	}
+	return fmt.Sprintf("install %s and make sure it is on PATH", name)
+}
@@ -0,0 +1,102 @@
+package skills
+
+import (
+	"errors"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/jbctechsolutions/skillrunner/internal/domain/skill"
+)
+
+// stubLookPath makes only the given programs appear on PATH.
+func stubLookPath(t *testing.T, installed ...string) {
+	t.Helper()
+	orig := lookPath
+	t.Cleanup(func() { lookPath = orig })
+	lookPath = func(name string) (string, error) {
+		for _, p := range installed {
+			if p == name {
+				return "/usr/bin/" + name, nil
+			}
+		}
+		return "", exec.ErrNotFound
+	}
+}
+
+func newRequiringSkill(t *testing.T, requires ...skill.Requirement) *skill.Skill {
+	t.Helper()
+	phase, err := skill.NewPhase("main", "Main", "prompt")
+	if err != nil {
+		t.Fatalf("NewPhase failed: %v", err)
+	}
+	sk, err := skill.NewSkill("go-refactor", "Go Refactor", "1.0.0", []skill.Phase{*phase})
+	if err != nil {
+		t.Fatalf("NewSkill failed: %v", err)
+	}
+	sk.SetRequires(requires)
+	return sk
+}
+
+func TestCheckRequirements(t *testing.T) {
+	stubLookPath(t, "git")
+	sk := newRequiringSkill(t,
+		skill.Requirement{Name: "git"},
+		skill.Requirement{Name: "golangci-lint", Hint: "go install golangci-lint"},
+		skill.Requirement{Name: "frobnicate"},
+	)
+
+	statuses := CheckRequirements(sk)
+	if len(statuses) != 3 {
+		t.Fatalf("CheckRequirements() returned %d statuses, want 3", len(statuses))
+	}
+	if !statuses[0].Found() || statuses[0].Path != "/usr/bin/git" || statuses[0].Hint != "" {
+		t.Errorf("git status = %+v, want found without a hint", statuses[0])
+	}
+	if statuses[1].Found() || statuses[1].Hint != "go install golangci-lint" {
+		t.Errorf("golangci-lint status = %+v, want the skill's hint", statuses[1])
+	}
+	if statuses[2].Hint != "install frobnicate and make sure it is on PATH" {
+		t.Errorf("frobnicate hint = %q, want the generic hint", statuses[2].Hint)
+	}
+
+	err := VerifyRequirements(sk)
+	var missing *MissingRequirementsError
+	if !errors.As(err, &missing) {
+		t.Fatalf("VerifyRequirements() error = %v, want *MissingRequirementsError", err)
+	}
+	if len(missing.Missing) != 2 {
+		t.Errorf("Missing = %+v, want golangci-lint and frobnicate", missing.Missing)
+	}
+	if !strings.Contains(err.Error(), "golangci-lint: go install golangci-lint") {
+		t.Errorf("error should include install hints:\n%s", err)
+	}
+}
+
+func TestVerifyRequirements_AllFound(t *testing.T) {
+	stubLookPath(t, "git", "gofmt")
+
+	if err := VerifyRequirements(newRequiringSkill(t, skill.Requirement{Name: "git"}, skill.Requirement{Name: "gofmt"})); err != nil {
+		t.Errorf("VerifyRequirements() error = %v", err)
+	}
+	if err := VerifyRequirements(newRequiringSkill(t)); err != nil {
+		t.Errorf("VerifyRequirements() without requires error = %v", err)
+	}
+}
+
+func TestInstallHint(t *testing.T) {
+	tests := []struct {
+		name, goos, want string
+	}{
+		{"git", "darwin", "brew install git (or xcode-select --install)"},
+		{"git", "windows", "winget install Git.Git"},
+		{"gofmt", "linux", "gofmt ships with Go; install it from https://go.dev/dl/"},
+		{"golangci-lint", "linux", "see https://golangci-lint.run/welcome/install/"},
+		{"unknown-tool", "darwin", "install unknown-tool and make sure it is on PATH"},
+	}
+	for _, tt := range tests {
+		if got := InstallHint(tt.name, tt.goos); got != tt.want {
+			t.Errorf("InstallHint(%q, %q) = %q, want %q", tt.name, tt.goos, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,25 @@
+package workflow
+
+import (
+	"context"
+
+	"github.com/jbctechsolutions/skillrunner/internal/application/ports"
+	"github.com/jbctechsolutions/skillrunner/internal/domain/skill"
+)
+
+// auditRun attributes the provider calls made under ctx to a skill run, in
+// the audit log.
+func auditRun(ctx context.Context, s *skill.Skill, executionID string) context.Context {
+	if s == nil {
+		return ctx
+	}
+	return ports.WithAuditScope(ctx, ports.AuditScope{ExecutionID: executionID, SkillID: s.ID()})
+}
+
+// auditPhase attributes the provider calls made under ctx to a phase of the
+// run, in the audit log.
+func auditPhase(ctx context.Context, phaseID string) context.Context {
+	scope := ports.AuditScopeFrom(ctx)
+	scope.PhaseID = phaseID
+	return ports.WithAuditScope(ctx, scope)
+}
@@ -0,0 +1,41 @@
+package workflow
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/jbctechsolutions/skillrunner/internal/application/ports"
+	"github.com/jbctechsolutions/skillrunner/internal/domain/skill"
+)
+
+func TestExecutor_AuditScope(t *testing.T) {
+	var mu sync.Mutex
+	scopes := make(map[string]ports.AuditScope)
+	provider := newMockProvider()
+	provider.completeFunc = func(ctx context.Context, _ ports.CompletionRequest) (*ports.CompletionResponse, error) {
+		scope := ports.AuditScopeFrom(ctx)
+		mu.Lock()
+		scopes[scope.PhaseID] = scope
+		mu.Unlock()
+		return &ports.CompletionResponse{Content: "ok"}, nil
+	}
+
+	phase1 := createTestPhase(t, "phase1", "Phase 1", "Process: {{._input}}", nil)
+	phase2 := createTestPhase(t, "phase2", "Phase 2", "Continue: {{.phase1}}", []string{"phase1"})
+	s := createTestSkill(t, []skill.Phase{phase1, phase2})
+
+	result, err := NewExecutor(provider, DefaultExecutorConfig()).Execute(context.Background(), s, "input")
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	for _, id := range []string{"phase1", "phase2"} {
+		scope, ok := scopes[id]
+		if !ok {
+			t.Fatalf("no provider call attributed to %s, got %v", id, scopes)
+		}
+		if scope.ExecutionID != result.ExecutionID || scope.SkillID != s.ID() {
+			t.Errorf("scope of %s = %+v, want execution %s of skill %s", id, scope, result.ExecutionID, s.ID())
+		}
+	}
+}
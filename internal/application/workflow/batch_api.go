@@ -0,0 +1,322 @@
+package workflow
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/jbctechsolutions/skillrunner/internal/application/ports"
+	appProvider "github.com/jbctechsolutions/skillrunner/internal/application/provider"
+	"github.com/jbctechsolutions/skillrunner/internal/domain/skill"
+	"github.com/jbctechsolutions/skillrunner/internal/domain/workflow"
+	"github.com/jbctechsolutions/skillrunner/internal/infrastructure/logging"
+)
+
+// Batch API defaults.
+const (
+	// DefaultBatchPollInterval is how often a batch job's status is checked.
+	DefaultBatchPollInterval = 30 * time.Second
+
+	// BatchJobTimeout is an executor timeout long enough for batch jobs,
+	// which complete within a 24 hour window.
+	BatchJobTimeout = 25 * time.Hour
+
+	// MaxBatchPollErrors is how many status checks of a batch job in a row
+	// may fail before its phases stop waiting and run directly.
+	MaxBatchPollErrors = 5
+)
+
+// batchJobRunner executes the phases of a DAG batch as one job on a
+// provider's asynchronous batch API. Phases pinned to another provider run
+// directly, as do phases whose batch item failed or returned invalid JSON.
+type batchJobRunner struct {
+	provider      ports.ProviderPort
+	batcher       ports.BatchProviderPort
+	phaseExecutor *phaseExecutor
+	pollInterval  time.Duration
+
+	// onSubmit is called once a job has been created so its ID can be
+	// checkpointed; a resumed run then collects the job instead of resubmitting.
+	onSubmit func(jobID string, phaseIDs []string)
+}
+
+// newBatchJobRunner returns a runner for provider, or nil if the provider has
+// no batch API. The batch API is found through the provider's decorators, so
+// that batch items are redacted, moderated, audited and metered like
+// completions.
+func newBatchJobRunner(provider ports.ProviderPort, phaseExecutor *phaseExecutor, pollInterval time.Duration) *batchJobRunner {
+	batcher, ok := ports.As[ports.BatchProviderPort](provider)
+	if !ok {
+		return nil
+	}
+	if pollInterval <= 0 {
+		pollInterval = DefaultBatchPollInterval
+	}
+	return &batchJobRunner{
+		provider:      provider,
+		batcher:       batcher,
+		phaseExecutor: phaseExecutor,
+		pollInterval:  pollInterval,
+	}
+}
+
+// dependencyGatherer collects the outputs a phase depends on.
+type dependencyGatherer func(dag *workflow.DAG, phaseID string, phaseOutputs map[string]string) map[string]string
+
+// runBatchJob executes the phases of a DAG batch through runner and records
+// their results and outputs. If jobID is set, that previously submitted job is
+// collected. It returns the first phase error.
+func runBatchJob(
+	ctx context.Context,
+	runner *batchJobRunner,
+	dag *workflow.DAG,
+	batchIndex int,
+	batch []string,
+	result *ExecutionResult,
+	phaseOutputs map[string]string,
+	jobID string,
+	gather dependencyGatherer,
+) error {
+	queuedAt := time.Now()
+	phases := make([]*skill.Phase, 0, len(batch))
+	inputs := make(map[string]map[string]string, len(batch))
+
+	for _, phaseID := range batch {
+		phase := dag.GetPhase(phaseID)
+		if phase == nil {
+			continue
+		}
+		phases = append(phases, phase)
+		inputs[phaseID] = gather(dag, phaseID, phaseOutputs)
+		result.PhaseResults[phaseID].Status = PhaseStatusRunning
+		result.PhaseResults[phaseID].StartTime = queuedAt
+	}
+
+	results := runner.run(ctx, phases, inputs, jobID)
+
+	var firstErr error
+	for _, phase := range phases {
+		phaseResult, ok := results[phase.ID]
+		if !ok {
+			continue
+		}
+		phaseResult.Batch = batchIndex
+		phaseResult.QueuedAt = queuedAt
+
+		result.PhaseResults[phase.ID] = phaseResult
+		if phaseResult.passesOutput() {
+			phaseOutputs[phase.ID] = phaseResult.Output
+		} else if phaseResult.Error != nil && firstErr == nil {
+			firstErr = phaseResult.Error
+		}
+	}
+
+	return firstErr
+}
+
+// batchPhase is a phase submitted as a batch item.
+type batchPhase struct {
+	phase      *skill.Phase
+	provider   ports.ProviderPort // As selected for the phase, e.g. metered by a budget
+	req        ports.CompletionRequest
+	assignment *appProvider.ExperimentAssignment
+	sources    map[string]string // Dependency outputs, checked by the similarity guard
+}
+
+// run executes phases with their dependency outputs and returns a result for
+// each. If jobID is set, that previously submitted job is collected instead of
+// submitting a new one.
+func (r *batchJobRunner) run(ctx context.Context, phases []*skill.Phase, inputs map[string]map[string]string, jobID string) map[string]*PhaseResult {
+	results := make(map[string]*PhaseResult, len(phases))
+	var mu sync.Mutex
+	var direct sync.WaitGroup
+
+	// executeDirect runs a phase outside the batch job
+	executeDirect := func(p *skill.Phase) {
+		direct.Add(1)
+		go func() {
+			defer direct.Done()
+			res := r.phaseExecutor.Execute(ctx, p, inputs[p.ID])
+			mu.Lock()
+			results[p.ID] = res
+			mu.Unlock()
+		}()
+	}
+
+	var items []batchPhase
+	for _, p := range phases {
+		if !p.IsCompletion() || p.LongContext != nil || p.IsConditional() || p.ForEach != nil || p.Timeout > 0 || p.Validation != nil {
+			executeDirect(p) // Transcription, image generation, sliding window, condition, loop, timeout or validators
+			continue
+		}
+		provider, req, assignment, err := r.phaseExecutor.prepareRequest(ctx, p, inputs[p.ID])
+		// Selectors may decorate the provider, e.g. to meter its costs
+		if err != nil || ports.UnwrapProvider(provider) != ports.UnwrapProvider(r.provider) {
+			executeDirect(p) // Reports the error, or runs on the pinned or candidate provider
+			continue
+		}
+		items = append(items, batchPhase{phase: p, provider: provider, req: req, assignment: assignment, sources: inputs[p.ID]})
+	}
+
+	if len(items) > 0 {
+		startTime := time.Now()
+		responses, submittedID, err := r.runJob(ctx, items, jobID)
+
+		for _, item := range items {
+			if err != nil && ctx.Err() != nil {
+				mu.Lock()
+				results[item.phase.ID] = failedPhaseResult(item.phase, startTime, err)
+				mu.Unlock()
+				continue
+			}
+
+			res := r.toPhaseResult(item, responses[item.phase.ID], submittedID, startTime)
+			if res == nil {
+				executeDirect(item.phase)
+				continue
+			}
+			mu.Lock()
+			results[item.phase.ID] = res
+			mu.Unlock()
+		}
+	}
+
+	direct.Wait()
+	return results
+}
+
+// runJob submits items (unless jobID names an existing job), waits for the
+// job to finish and returns its results keyed by phase ID, along with the job ID.
+// The job runs on the batch API of the provider selected for the items, so
+// that the decorators the selector added, such as a budget meter, see it.
+func (r *batchJobRunner) runJob(ctx context.Context, items []batchPhase, jobID string) (map[string]ports.BatchItemResult, string, error) {
+	batcher := r.batcher
+	if b, ok := ports.As[ports.BatchProviderPort](items[0].provider); ok {
+		batcher = b
+	}
+
+	if jobID == "" {
+		batchItems := make([]ports.BatchItem, len(items))
+		phaseIDs := make([]string, len(items))
+		for i, item := range items {
+			batchItems[i] = ports.BatchItem{CustomID: item.phase.ID, Request: item.req}
+			phaseIDs[i] = item.phase.ID
+		}
+
+		job, err := batcher.SubmitBatch(ctx, batchItems)
+		if err != nil {
+			return nil, "", err
+		}
+		jobID = job.ID
+
+		if r.onSubmit != nil {
+			r.onSubmit(jobID, phaseIDs)
+		}
+	}
+
+	if err := waitForJob(ctx, batcher, jobID, r.pollInterval); err != nil {
+		return nil, jobID, err
+	}
+
+	itemResults, err := batcher.GetBatchResults(ctx, jobID)
+	if err != nil {
+		return nil, jobID, err
+	}
+
+	byID := make(map[string]ports.BatchItemResult, len(itemResults))
+	for _, res := range itemResults {
+		byID[res.CustomID] = res
+	}
+	return byID, jobID, nil
+}
+
+// waitForJob polls a job on batcher every interval until it reaches a
+// terminal state. Polling errors are treated as transient until
+// MaxBatchPollErrors of them in a row; the context bounds how long the job
+// may take.
+func waitForJob(ctx context.Context, batcher ports.BatchProviderPort, jobID string, interval time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	failures := 0
+	for {
+		job, err := batcher.GetBatch(ctx, jobID)
+		switch {
+		case err == nil && job.Status.IsTerminal():
+			return nil
+		case err == nil:
+			failures = 0
+		case ctx.Err() == nil:
+			if failures++; failures >= MaxBatchPollErrors {
+				logging.FromContext(ctx).WarnContext(ctx, "giving up on batch job; running its phases directly", "job_id", jobID, "error", err)
+				return fmt.Errorf("checking batch job %s failed %d times in a row: %w", jobID, failures, err)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// toPhaseResult converts a batch item result, or returns nil if the phase
+// should be retried directly: when the item failed, so the phase's retry
+// policy applies, when its JSON output is invalid, and when its output
+// echoes its sources and the phase's similarity guard retries. Outputs are
+// checked against the phase's glossary like direct ones.
+func (r *batchJobRunner) toPhaseResult(item batchPhase, res ports.BatchItemResult, jobID string, startTime time.Time) *PhaseResult {
+	if res.Response == nil {
+		return nil
+	}
+
+	content := res.Response.Content
+	if item.phase.WantsJSON() {
+		content = extractJSON(content)
+		if validateJSONOutput([]byte(content), item.phase.OutputSchema) != nil {
+			return nil // The direct path retries with corrective prompts
+		}
+	}
+	var problem string
+	if guard := item.phase.SimilarityGuard; guard != nil {
+		if problem = echoProblem(content, item.sources, guard.Threshold()); problem != "" && guard.Retries() {
+			return nil // The direct path asks again, showing the model the problem
+		}
+	}
+
+	endTime := time.Now()
+	result := &PhaseResult{
+		PhaseID:      item.phase.ID,
+		PhaseName:    item.phase.Name,
+		Status:       PhaseStatusCompleted,
+		Prompt:       requestPrompt(item.req),
+		StartTime:    startTime,
+		EndTime:      endTime,
+		Duration:     endTime.Sub(startTime),
+		InputTokens:  res.Response.InputTokens,
+		OutputTokens: res.Response.OutputTokens,
+		ModelUsed:    res.Response.ModelUsed,
+		ProviderUsed: r.provider.Info().Name,
+		BatchJobID:   jobID,
+	}
+	result.Warnings = warningList(problem)
+	result.setOutput(item.phase, content)
+	result.tagExperiment(item.assignment)
+	return result
+}
+
+// failedPhaseResult returns a failed result for a phase that started at startTime.
+func failedPhaseResult(phase *skill.Phase, startTime time.Time, err error) *PhaseResult {
+	endTime := time.Now()
+	return &PhaseResult{
+		PhaseID:   phase.ID,
+		PhaseName: phase.Name,
+		Status:    PhaseStatusFailed,
+		Error:     err,
+		StartTime: startTime,
+		EndTime:   endTime,
+		Duration:  endTime.Sub(startTime),
+	}
+}
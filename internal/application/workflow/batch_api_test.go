@@ -0,0 +1,392 @@
+package workflow
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jbctechsolutions/skillrunner/internal/adapters/provider/moderation"
+	"github.com/jbctechsolutions/skillrunner/internal/application/ports"
+	"github.com/jbctechsolutions/skillrunner/internal/domain/skill"
+	"github.com/jbctechsolutions/skillrunner/internal/domain/workflow"
+)
+
+// mockBatchProvider adds a batch API to mockProvider. Jobs complete after
+// pollsUntilDone status checks, or never if pollErr is set; each item's
+// response echoes its prompt.
+type mockBatchProvider struct {
+	*mockProvider
+
+	mu             sync.Mutex
+	submitted      [][]ports.BatchItem
+	jobs           map[string][]ports.BatchItem
+	pollsUntilDone int
+	polls          int
+	pollErr        error
+	itemResult     func(item ports.BatchItem) ports.BatchItemResult
+}
+
+func newMockBatchProvider() *mockBatchProvider {
+	return &mockBatchProvider{
+		mockProvider:   newMockProvider(),
+		jobs:           make(map[string][]ports.BatchItem),
+		pollsUntilDone: 2,
+	}
+}
+
+func (m *mockBatchProvider) SubmitBatch(_ context.Context, items []ports.BatchItem) (*ports.BatchJob, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.submitted = append(m.submitted, items)
+	id := fmt.Sprintf("batch-%d", len(m.submitted))
+	m.jobs[id] = items
+	return &ports.BatchJob{ID: id, Status: ports.BatchJobValidating, Total: len(items)}, nil
+}
+
+func (m *mockBatchProvider) GetBatch(_ context.Context, jobID string) (*ports.BatchJob, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.polls++
+	if m.pollErr != nil {
+		return nil, m.pollErr
+	}
+	status := ports.BatchJobInProgress
+	if m.polls >= m.pollsUntilDone {
+		status = ports.BatchJobCompleted
+	}
+	return &ports.BatchJob{ID: jobID, Status: status}, nil
+}
+
+func (m *mockBatchProvider) GetBatchResults(_ context.Context, jobID string) ([]ports.BatchItemResult, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	items, ok := m.jobs[jobID]
+	if !ok {
+		return nil, fmt.Errorf("unknown batch %s", jobID)
+	}
+
+	results := make([]ports.BatchItemResult, 0, len(items))
+	for _, item := range items {
+		if m.itemResult != nil {
+			results = append(results, m.itemResult(item))
+			continue
+		}
+		results = append(results, ports.BatchItemResult{
+			CustomID: item.CustomID,
+			Response: &ports.CompletionResponse{
+				Content:      "Batch response for: " + item.Request.Messages[len(item.Request.Messages)-1].Content,
+				InputTokens:  5,
+				OutputTokens: 7,
+				ModelUsed:    item.Request.ModelID,
+			},
+		})
+	}
+	return results, nil
+}
+
+func batchExecutorConfig() ExecutorConfig {
+	config := DefaultExecutorConfig()
+	config.BatchAPI = true
+	config.BatchPollInterval = time.Millisecond
+	return config
+}
+
+func TestExecutor_BatchAPI(t *testing.T) {
+	provider := newMockBatchProvider()
+	exec := NewExecutor(provider, batchExecutorConfig())
+
+	// Two independent phases share a job; the third runs in a second job
+	phase1 := createTestPhase(t, "phase1", "Phase 1", "A: {{._input}}", nil)
+	phase2 := createTestPhase(t, "phase2", "Phase 2", "B: {{._input}}", nil)
+	phase3 := createTestPhase(t, "phase3", "Phase 3", "C: {{.phase1}}", []string{"phase1", "phase2"})
+	s := createTestSkill(t, []skill.Phase{phase1, phase2, phase3})
+
+	result, err := exec.Execute(context.Background(), s, "input")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Status != PhaseStatusCompleted {
+		t.Fatalf("expected status Completed, got %s", result.Status)
+	}
+
+	if len(provider.submitted) != 2 {
+		t.Fatalf("expected 2 batch jobs, got %d", len(provider.submitted))
+	}
+	if len(provider.submitted[0]) != 2 || len(provider.submitted[1]) != 1 {
+		t.Errorf("unexpected job sizes: %d, %d", len(provider.submitted[0]), len(provider.submitted[1]))
+	}
+	if calls := provider.callCount.Load(); calls != 0 {
+		t.Errorf("expected no direct completions, got %d", calls)
+	}
+
+	pr := result.PhaseResults["phase3"]
+	if pr.BatchJobID != "batch-2" {
+		t.Errorf("phase3 BatchJobID = %q, want batch-2", pr.BatchJobID)
+	}
+	if want := "Batch response for: C: Batch response for: A: input"; pr.Output != want {
+		t.Errorf("phase3 output = %q, want %q", pr.Output, want)
+	}
+	if result.TotalTokens != 36 {
+		t.Errorf("TotalTokens = %d, want 36", result.TotalTokens)
+	}
+}
+
+func TestExecutor_BatchAPI_FallsBackToDirect(t *testing.T) {
+	provider := newMockBatchProvider()
+	provider.itemResult = func(item ports.BatchItem) ports.BatchItemResult {
+		switch item.CustomID {
+		case "failed":
+			return ports.BatchItemResult{CustomID: item.CustomID, Error: "HTTP 500"}
+		case "json":
+			return ports.BatchItemResult{CustomID: item.CustomID, Response: &ports.CompletionResponse{Content: "not json"}}
+		default:
+			return ports.BatchItemResult{CustomID: item.CustomID, Response: &ports.CompletionResponse{Content: "ok"}}
+		}
+	}
+	provider.completeFunc = func(_ context.Context, _ ports.CompletionRequest) (*ports.CompletionResponse, error) {
+		return &ports.CompletionResponse{Content: `{"ok": true}`}, nil
+	}
+	exec := NewExecutor(provider, batchExecutorConfig())
+
+	batched := createTestPhase(t, "batched", "Batched", "A: {{._input}}", nil)
+	failed := createTestPhase(t, "failed", "Failed", "B: {{._input}}", nil)
+	jsonPhase := createTestPhase(t, "json", "JSON", "C: {{._input}}", nil)
+	jsonPhase.OutputFormat = skill.OutputFormatJSON
+	s := createTestSkill(t, []skill.Phase{batched, failed, jsonPhase})
+
+	result, err := exec.Execute(context.Background(), s, "input")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if calls := provider.callCount.Load(); calls != 2 {
+		t.Errorf("expected 2 direct completions, got %d", calls)
+	}
+	if pr := result.PhaseResults["batched"]; pr.Output != "ok" || pr.BatchJobID == "" {
+		t.Errorf("batched phase = %q (job %q), want batch output", pr.Output, pr.BatchJobID)
+	}
+	for _, id := range []string{"failed", "json"} {
+		if pr := result.PhaseResults[id]; pr.Status != PhaseStatusCompleted || pr.BatchJobID != "" {
+			t.Errorf("%s: status %s, job %q; want direct completion", id, pr.Status, pr.BatchJobID)
+		}
+	}
+}
+
+func TestExecutor_BatchAPI_StopsPollingAfterErrors(t *testing.T) {
+	provider := newMockBatchProvider()
+	provider.pollErr = errors.New("HTTP 502")
+	exec := NewExecutor(provider, batchExecutorConfig())
+
+	phase1 := createTestPhase(t, "phase1", "Phase 1", "A: {{._input}}", nil)
+	phase2 := createTestPhase(t, "phase2", "Phase 2", "B: {{._input}}", nil)
+	s := createTestSkill(t, []skill.Phase{phase1, phase2})
+
+	result, err := exec.Execute(context.Background(), s, "input")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if provider.polls != MaxBatchPollErrors {
+		t.Errorf("polls = %d, want %d", provider.polls, MaxBatchPollErrors)
+	}
+	if calls := provider.callCount.Load(); calls != 2 {
+		t.Errorf("expected 2 direct completions, got %d", calls)
+	}
+	for _, id := range []string{"phase1", "phase2"} {
+		if pr := result.PhaseResults[id]; pr.Status != PhaseStatusCompleted || pr.BatchJobID != "" {
+			t.Errorf("%s: status %s, job %q; want direct completion", id, pr.Status, pr.BatchJobID)
+		}
+	}
+}
+
+func TestExecutor_BatchAPI_GuardsOutputs(t *testing.T) {
+	input := "the quarterly report covers revenue growth across all regions"
+	provider := newMockBatchProvider()
+	provider.itemResult = func(item ports.BatchItem) ports.BatchItemResult {
+		switch item.CustomID {
+		case "echo", "flag":
+			return ports.BatchItemResult{CustomID: item.CustomID, Response: &ports.CompletionResponse{Content: input}}
+		case "retry":
+			return ports.BatchItemResult{CustomID: item.CustomID, Error: "HTTP 500"}
+		default:
+			return ports.BatchItemResult{CustomID: item.CustomID, Response: &ports.CompletionResponse{Content: "Built with Github Actions"}}
+		}
+	}
+	var retryCalls atomic.Int32
+	provider.completeFunc = func(_ context.Context, req ports.CompletionRequest) (*ports.CompletionResponse, error) {
+		if strings.HasPrefix(requestPrompt(req), "Retry") && retryCalls.Add(1) == 1 {
+			return nil, errors.New("HTTP 503")
+		}
+		return &ports.CompletionResponse{Content: "a fresh summary in other words"}, nil
+	}
+	exec := NewExecutor(provider, batchExecutorConfig())
+
+	echo := createTestPhase(t, "echo", "Echo", "Summarize {{._input}}", nil)
+	echo.WithSimilarityGuard(&skill.SimilarityGuardConfig{})
+	flag := createTestPhase(t, "flag", "Flag", "Condense {{._input}}", nil)
+	flag.WithSimilarityGuard(&skill.SimilarityGuardConfig{Action: skill.SimilarityActionFlag})
+	glossary := createTestPhase(t, "glossary", "Glossary", "Describe the build of {{._input}}", nil)
+	glossary.WithGlossary(&skill.Glossary{Terms: []skill.GlossaryTerm{{Term: "GitHub"}}, Action: skill.GlossaryActionCorrect})
+	retry := createTestPhase(t, "retry", "Retry", "Retry {{._input}}", nil)
+	retry.WithRetry(&skill.RetryPolicy{Retries: 1, Backoff: time.Millisecond})
+	s := createTestSkill(t, []skill.Phase{echo, flag, glossary, retry})
+
+	result, err := exec.Execute(context.Background(), s, input)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// An echo is asked for again directly when the guard retries, and kept
+	// with a warning when it flags
+	if pr := result.PhaseResults["echo"]; pr.BatchJobID != "" || pr.Output != "a fresh summary in other words" {
+		t.Errorf("echo phase = %q (job %q), want a direct completion", pr.Output, pr.BatchJobID)
+	}
+	if pr := result.PhaseResults["flag"]; pr.BatchJobID == "" || len(pr.Warnings) != 1 {
+		t.Errorf("flag phase: job %q, warnings %q; want the batch output flagged", pr.BatchJobID, pr.Warnings)
+	}
+	if pr := result.PhaseResults["glossary"]; pr.BatchJobID == "" || pr.Output != "Built with GitHub Actions" {
+		t.Errorf("glossary phase = %q (job %q), want the batch output corrected", pr.Output, pr.BatchJobID)
+	}
+
+	// A failed item runs directly under the phase's retry policy
+	pr := result.PhaseResults["retry"]
+	if pr.Status != PhaseStatusCompleted || retryCalls.Load() != 2 {
+		t.Errorf("retry phase: %s after %d direct calls, want completed after a retry", pr.Status, retryCalls.Load())
+	}
+	if len(pr.Warnings) == 0 || !strings.HasPrefix(pr.Warnings[0], "retry:") {
+		t.Errorf("retry phase warnings = %q, want the recovered failure", pr.Warnings)
+	}
+}
+
+func TestExecutor_BatchAPI_UnsupportedProvider(t *testing.T) {
+	provider := newMockProvider()
+	exec := NewExecutor(provider, batchExecutorConfig())
+
+	phase := createTestPhase(t, "phase1", "Phase 1", "A: {{._input}}", nil)
+	s := createTestSkill(t, []skill.Phase{phase})
+
+	result, err := exec.Execute(context.Background(), s, "input")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Status != PhaseStatusCompleted || provider.callCount.Load() != 1 {
+		t.Errorf("expected a direct completion, got status %s and %d calls", result.Status, provider.callCount.Load())
+	}
+}
+
+func TestExecutor_BatchAPI_ModerationBlocksFlaggedPhase(t *testing.T) {
+	provider := newMockBatchProvider()
+	flagAttack := moderation.ClassifierFunc(func(_ context.Context, text string) (*ports.ModerationResult, error) {
+		return &ports.ModerationResult{Flagged: strings.Contains(text, "attack"), Categories: []string{"violence"}}, nil
+	})
+	exec := NewExecutor(moderation.NewProvider(provider, flagAttack, skill.ModerationBlock), batchExecutorConfig())
+
+	safe := createTestPhase(t, "safe", "Safe", "Plan the picnic: {{._input}}", nil)
+	flagged := createTestPhase(t, "flagged", "Flagged", "Plan the attack: {{._input}}", nil)
+	s := createTestSkill(t, []skill.Phase{safe, flagged})
+
+	result, _ := exec.Execute(context.Background(), s, "input")
+	if result == nil {
+		t.Fatal("expected a result")
+	}
+
+	for _, job := range provider.submitted {
+		for _, item := range job {
+			if item.CustomID == "flagged" {
+				t.Fatal("the flagged phase reached SubmitBatch")
+			}
+		}
+	}
+	if calls := provider.callCount.Load(); calls != 0 {
+		t.Errorf("expected no direct completions, got %d", calls)
+	}
+	if pr := result.PhaseResults["safe"]; pr.Status != PhaseStatusCompleted || pr.BatchJobID == "" {
+		t.Errorf("safe phase: status %s, job %q; want a batch completion", pr.Status, pr.BatchJobID)
+	}
+	if pr := result.PhaseResults["flagged"]; pr.Status != PhaseStatusFailed || !errors.Is(pr.Error, moderation.ErrBlocked) {
+		t.Errorf("flagged phase: status %s, error %v; want blocked", pr.Status, pr.Error)
+	}
+}
+
+func TestCheckpointingExecutor_BatchAPI_RecordsJob(t *testing.T) {
+	provider := newMockBatchProvider()
+	cpPort := newMockCheckpointPort()
+
+	exec := NewCheckpointingExecutor(provider, batchExecutorConfig(), CheckpointConfig{
+		Enabled:   true,
+		Port:      cpPort,
+		MachineID: "test-machine",
+	})
+
+	phase := createTestPhase(t, "phase1", "Phase 1", "A: {{._input}}", nil)
+	s := createTestSkill(t, []skill.Phase{phase})
+
+	result, err := exec.Execute(context.Background(), s, "input")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.PhaseResults["phase1"].BatchJobID != "batch-1" {
+		t.Errorf("BatchJobID = %q, want batch-1", result.PhaseResults["phase1"].BatchJobID)
+	}
+
+	cpPort.mu.Lock()
+	defer cpPort.mu.Unlock()
+	for _, cp := range cpPort.checkpoints {
+		data := cp.PhaseResults()["phase1"]
+		if data == nil || data.BatchJobID != "batch-1" {
+			t.Errorf("checkpoint phase result = %+v, want batch job batch-1", data)
+		}
+	}
+}
+
+func TestCheckpointingExecutor_BatchAPI_ResumesSubmittedJob(t *testing.T) {
+	provider := newMockBatchProvider()
+	cpPort := newMockCheckpointPort()
+
+	phase := createTestPhase(t, "phase1", "Phase 1", "A: {{._input}}", nil)
+	s := createTestSkill(t, []skill.Phase{phase})
+
+	// A previous run submitted the job and was interrupted while it ran
+	provider.jobs["batch-prev"] = []ports.BatchItem{{
+		CustomID: "phase1",
+		Request: ports.CompletionRequest{
+			Messages: []ports.Message{{Role: "user", Content: "A: input"}},
+		},
+	}}
+	existingCP, _ := workflow.NewWorkflowCheckpoint("existing-cp", "exec-123", "test-skill", "Test Skill", "input", 1)
+	existingCP.AddPhaseOutput("_input", "input")
+	existingCP.AddPhaseResult("phase1", &workflow.PhaseResultData{
+		PhaseID:    "phase1",
+		PhaseName:  "Phase 1",
+		Status:     string(PhaseStatusRunning),
+		BatchJobID: "batch-prev",
+	})
+	cpPort.checkpoints["existing-cp"] = existingCP
+
+	exec := NewCheckpointingExecutor(provider, batchExecutorConfig(), CheckpointConfig{
+		Enabled:   true,
+		Port:      cpPort,
+		Resume:    true,
+		MachineID: "test-machine",
+	})
+
+	result, err := exec.Execute(context.Background(), s, "input")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(provider.submitted) != 0 {
+		t.Errorf("expected the existing job to be collected, got %d submissions", len(provider.submitted))
+	}
+	pr := result.PhaseResults["phase1"]
+	if pr.Status != PhaseStatusCompleted || pr.BatchJobID != "batch-prev" {
+		t.Errorf("phase1: status %s, job %q; want completed from batch-prev", pr.Status, pr.BatchJobID)
+	}
+	if pr.Output != "Batch response for: A: input" {
+		t.Errorf("phase1 output = %q", pr.Output)
+	}
+}
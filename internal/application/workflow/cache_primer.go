@@ -0,0 +1,120 @@
+package workflow
+
+import (
+	"context"
+	"maps"
+	"time"
+
+	"github.com/jbctechsolutions/skillrunner/internal/application/ports"
+	"github.com/jbctechsolutions/skillrunner/internal/domain/skill"
+	"github.com/jbctechsolutions/skillrunner/internal/domain/workflow"
+)
+
+// PrimeResult counts the phases of a past run that CachePrimer.Prime looked at.
+type PrimeResult struct {
+	Primed  int // Phases stored in the cache
+	Cached  int // Phases whose response was already cached
+	Skipped int // Phases that did not complete or no longer match the skill
+}
+
+// CachePrimer seeds the response cache with the phase outputs of past runs,
+// under the keys the caching executors look them up with, without calling a
+// provider. This keeps cache hit analysis and replay-based tests working
+// after entries have been evicted.
+type CachePrimer struct {
+	cache      ports.ResponseCachePort
+	delegate   *phaseExecutor
+	defaultTTL time.Duration
+	// Fingerprinter is optional; if nil, uses default fingerprinting. It must
+	// match the one of the caching executors that read the cache.
+	Fingerprinter func(ports.CompletionRequest) string
+}
+
+// NewCachePrimer creates a cache primer. memoryContent must be the memory
+// the primed runs injected into their prompts, since it is part of the key.
+func NewCachePrimer(cache ports.ResponseCachePort, cfg CachingConfig, memoryContent string) *CachePrimer {
+	return &CachePrimer{
+		cache:      cache,
+		delegate:   newPhaseExecutor(nil, memoryContent),
+		defaultTTL: cfg.DefaultTTL,
+	}
+}
+
+// Prime stores the completed completion phases of a past run of s in the
+// cache. Each phase's prompt is rendered again from the run's input and
+// phase outputs, so a phase whose template has changed since the run gets
+// the key the current skill would use. Phases already in the cache are
+// left as they are.
+func (p *CachePrimer) Prime(ctx context.Context, s *skill.Skill, cp *workflow.WorkflowCheckpoint) (PrimeResult, error) {
+	var result PrimeResult
+
+	dag, err := workflow.NewDAG(s.Phases())
+	if err != nil {
+		return result, err
+	}
+	results := cp.PhaseResults()
+	outputs := cp.PhaseOutputs()
+	maps.Copy(outputs, runVariables(cp.Input(), outputs))
+
+	ttl := p.defaultTTL
+	if ttl == 0 {
+		ttl = 24 * time.Hour // Same default as the caching executors
+	}
+
+	for _, phase := range s.Phases() {
+		data, ok := results[phase.ID]
+		if !ok || data.Status != string(PhaseStatusCompleted) || !phase.IsCompletion() || hasCustomProfile(&phase) {
+			if ok {
+				result.Skipped++
+			}
+			continue
+		}
+
+		dependencyOutputs := make(map[string]string, len(phase.DependsOn)+len(skill.FileVariables)+1)
+		copyRunVariables(dependencyOutputs, outputs)
+		for _, depID := range dag.GetDependencies(phase.ID) {
+			if output, ok := outputs[depID]; ok {
+				dependencyOutputs[depID] = output
+			}
+		}
+
+		_, messages, err := p.delegate.buildRequest(&phase, dependencyOutputs)
+		if err != nil {
+			result.Skipped++
+			continue
+		}
+		key := p.fingerprint(ports.CompletionRequest{
+			ModelID:        p.delegate.selectModel(phase.RoutingProfile),
+			Messages:       messages,
+			MaxTokens:      phase.MaxTokens,
+			Temperature:    phase.Temperature,
+			ResponseFormat: phaseResponseFormat(&phase),
+		})
+
+		if p.cache.Has(ctx, key) {
+			result.Cached++
+			continue
+		}
+		err = p.cache.SetResponse(ctx, key, &ports.CompletionResponse{
+			Content:      data.Output,
+			InputTokens:  data.InputTokens,
+			OutputTokens: data.OutputTokens,
+			ModelUsed:    data.ModelUsed,
+			Duration:     time.Duration(data.DurationNs),
+		}, ttl)
+		if err != nil {
+			return result, err
+		}
+		result.Primed++
+	}
+
+	return result, nil
+}
+
+// fingerprint generates a cache key for the request.
+func (p *CachePrimer) fingerprint(req ports.CompletionRequest) string {
+	if p.Fingerprinter != nil {
+		return p.Fingerprinter(req)
+	}
+	return defaultFingerprint(req)
+}
@@ -0,0 +1,112 @@
+package workflow
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jbctechsolutions/skillrunner/internal/application/ports"
+	"github.com/jbctechsolutions/skillrunner/internal/domain/skill"
+	"github.com/jbctechsolutions/skillrunner/internal/domain/workflow"
+)
+
+// mapResponseCache is a ResponseCachePort backed by a map; only the methods
+// the caching executors and CachePrimer use are implemented.
+type mapResponseCache struct {
+	ports.ResponseCachePort
+	mu        sync.Mutex
+	responses map[string]*ports.CompletionResponse
+}
+
+func newMapResponseCache() *mapResponseCache {
+	return &mapResponseCache{responses: make(map[string]*ports.CompletionResponse)}
+}
+
+func (c *mapResponseCache) Has(_ context.Context, key string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	_, ok := c.responses[key]
+	return ok
+}
+
+func (c *mapResponseCache) GetResponse(_ context.Context, key string) (*ports.CompletionResponse, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	resp, ok := c.responses[key]
+	return resp, ok
+}
+
+func (c *mapResponseCache) SetResponse(_ context.Context, key string, resp *ports.CompletionResponse, _ time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.responses[key] = resp
+	return nil
+}
+
+func TestCachePrimer_Prime(t *testing.T) {
+	phase1 := createTestPhase(t, "phase1", "Phase 1", "Summarize: {{._input}}", nil)
+	phase2 := createTestPhase(t, "phase2", "Phase 2", "Polish: {{.phase1}}", []string{"phase1"})
+	phase3 := createTestPhase(t, "phase3", "Phase 3", "Review: {{.phase2}}", []string{"phase2"})
+	s := createTestSkill(t, []skill.Phase{phase1, phase2, phase3})
+
+	cp, err := workflow.NewWorkflowCheckpoint("cp-1", "exec-1", "test-skill", "Test Skill", "the report", 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cp.AddPhaseOutput("_input", "the report")
+	cp.AddPhaseOutput("phase1", "a summary")
+	cp.AddPhaseOutput("phase2", "a polished summary")
+	cp.AddPhaseResult("phase1", &workflow.PhaseResultData{PhaseID: "phase1", Status: "completed", Output: "a summary", InputTokens: 12, OutputTokens: 3, ModelUsed: "llama3:8b"})
+	cp.AddPhaseResult("phase2", &workflow.PhaseResultData{PhaseID: "phase2", Status: "completed", Output: "a polished summary", ModelUsed: "llama3:8b"})
+	cp.AddPhaseResult("phase3", &workflow.PhaseResultData{PhaseID: "phase3", Status: "failed", ErrorMessage: "timeout"})
+
+	cache := newMapResponseCache()
+	primer := NewCachePrimer(cache, CachingConfig{Enabled: true}, "")
+
+	got, err := primer.Prime(context.Background(), s, cp)
+	if err != nil {
+		t.Fatalf("Prime() error = %v", err)
+	}
+	if got != (PrimeResult{Primed: 2, Skipped: 1}) {
+		t.Errorf("Prime() = %+v, want 2 primed and the failed phase skipped", got)
+	}
+
+	// The caching executor serves the primed phases without calling the provider
+	provider := newMockProvider()
+	exec := NewCachingPhaseExecutor(provider, cache, CachingConfig{Enabled: true}, "")
+	result := exec.Execute(context.Background(), &phase2, map[string]string{"_input": "the report", "phase1": "a summary"})
+	if !result.CacheHit || result.Output != "a polished summary" {
+		t.Errorf("phase2 result = %+v, want a cache hit with the run's output", result)
+	}
+	if provider.callCount.Load() != 0 {
+		t.Errorf("provider called %d times, want 0", provider.callCount.Load())
+	}
+
+	// Priming the same run again leaves the entries as they are
+	got, err = primer.Prime(context.Background(), s, cp)
+	if err != nil {
+		t.Fatalf("Prime() again error = %v", err)
+	}
+	if got.Cached != 2 || got.Primed != 0 {
+		t.Errorf("Prime() again = %+v, want both phases already cached", got)
+	}
+}
+
+func TestCachePrimer_MemoryIsPartOfTheKey(t *testing.T) {
+	phase := createTestPhase(t, "phase1", "Phase 1", "Summarize: {{._input}}", nil)
+	s := createTestSkill(t, []skill.Phase{phase})
+
+	cp, _ := workflow.NewWorkflowCheckpoint("cp-1", "exec-1", "test-skill", "Test Skill", "the report", 1)
+	cp.AddPhaseResult("phase1", &workflow.PhaseResultData{PhaseID: "phase1", Status: "completed", Output: "a summary"})
+
+	cache := newMapResponseCache()
+	if _, err := NewCachePrimer(cache, CachingConfig{}, "project memory").Prime(context.Background(), s, cp); err != nil {
+		t.Fatal(err)
+	}
+
+	exec := NewCachingPhaseExecutor(newMockProvider(), cache, CachingConfig{Enabled: true}, "")
+	if result := exec.Execute(context.Background(), &phase, map[string]string{"_input": "the report"}); result.CacheHit {
+		t.Error("a run without memory should not hit an entry primed with memory")
+	}
+}
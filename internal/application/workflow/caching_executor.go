@@ -37,7 +37,7 @@ func NewCachingPhaseExecutor(provider ports.ProviderPort, cache ports.ResponseCa
 
 // Execute runs a single phase with caching support.
 func (e *CachingPhaseExecutor) Execute(ctx context.Context, phase *skill.Phase, dependencyOutputs map[string]string) *PhaseResult {
-	if !e.enabled || e.cache == nil {
+	if !e.enabled || e.cache == nil || !phase.IsCompletion() || phase.IsConditional() || phase.ForEach != nil || hasCustomProfile(phase) {
 		return e.delegate.Execute(ctx, phase, dependencyOutputs)
 	}
 
@@ -49,7 +49,7 @@ func (e *CachingPhaseExecutor) Execute(ctx context.Context, phase *skill.Phase,
 	}
 
 	// Build the prompt and request to generate cache key
-	prompt, err := e.delegate.buildPrompt(phase.PromptTemplate, dependencyOutputs)
+	prompt, messages, err := e.delegate.buildRequest(phase, dependencyOutputs)
 	if err != nil {
 		result.Status = PhaseStatusFailed
 		result.Error = err
@@ -57,13 +57,15 @@ func (e *CachingPhaseExecutor) Execute(ctx context.Context, phase *skill.Phase,
 		result.Duration = result.EndTime.Sub(result.StartTime)
 		return result
 	}
+	result.Prompt = prompt
 
 	// Build the completion request
 	req := ports.CompletionRequest{
-		ModelID:     e.delegate.selectModel(phase.RoutingProfile),
-		Messages:    e.delegate.buildMessages(prompt, dependencyOutputs),
-		MaxTokens:   phase.MaxTokens,
-		Temperature: phase.Temperature,
+		ModelID:        e.delegate.selectModel(phase.RoutingProfile),
+		Messages:       messages,
+		MaxTokens:      phase.MaxTokens,
+		Temperature:    phase.Temperature,
+		ResponseFormat: phaseResponseFormat(phase),
 	}
 
 	// Generate cache key
@@ -72,7 +74,7 @@ func (e *CachingPhaseExecutor) Execute(ctx context.Context, phase *skill.Phase,
 	// Try to get from cache
 	if cachedResp, found := e.cache.GetResponse(ctx, cacheKey); found {
 		result.Status = PhaseStatusCompleted
-		result.Output = cachedResp.Content
+		result.setOutput(phase, cachedResp.Content)
 		result.InputTokens = cachedResp.InputTokens
 		result.OutputTokens = cachedResp.OutputTokens
 		result.ModelUsed = cachedResp.ModelUsed
@@ -83,7 +85,13 @@ func (e *CachingPhaseExecutor) Execute(ctx context.Context, phase *skill.Phase,
 	}
 
 	// Cache miss - call provider
-	resp, err := e.delegate.provider.Complete(ctx, req)
+	models := profileModels(e.delegate.selectModel, e.delegate.provider, e.delegate.provider)
+	complete := windowed(phase, models, e.delegate.provider.Complete, e.delegate.provider.Complete)
+	resp, warnings, err := retryPhase(ctx, phase.Retry, req, escalationModel(phase, models), timedAttempt(phase.Timeout, func(ctx context.Context, req ports.CompletionRequest) (*ports.CompletionResponse, string, error) {
+		resp, err := completePhase(ctx, phase, req, complete)
+		return resp, "", err
+	}), nil)
+	result.Warnings = append(result.Warnings, warnings...)
 	if err != nil {
 		result.Status = PhaseStatusFailed
 		result.Error = err
@@ -101,7 +109,7 @@ func (e *CachingPhaseExecutor) Execute(ctx context.Context, phase *skill.Phase,
 
 	// Populate the result
 	result.Status = PhaseStatusCompleted
-	result.Output = resp.Content
+	result.setOutput(phase, resp.Content)
 	result.InputTokens = resp.InputTokens
 	result.OutputTokens = resp.OutputTokens
 	result.ModelUsed = resp.ModelUsed
@@ -159,7 +167,7 @@ func (e *CachingStreamingPhaseExecutor) ExecuteWithStreaming(
 	dependencyOutputs map[string]string,
 	callback PhaseStreamCallback,
 ) *PhaseResult {
-	if !e.enabled || e.cache == nil {
+	if !e.enabled || e.cache == nil || !phase.IsCompletion() {
 		return e.delegate.ExecuteWithStreaming(ctx, phase, dependencyOutputs, callback)
 	}
 
@@ -171,7 +179,7 @@ func (e *CachingStreamingPhaseExecutor) ExecuteWithStreaming(
 	}
 
 	// Build the prompt and request to generate cache key
-	prompt, err := e.delegate.buildPrompt(phase.PromptTemplate, dependencyOutputs)
+	prompt, messages, err := e.delegate.buildRequest(phase, dependencyOutputs)
 	if err != nil {
 		result.Status = PhaseStatusFailed
 		result.Error = err
@@ -179,13 +187,15 @@ func (e *CachingStreamingPhaseExecutor) ExecuteWithStreaming(
 		result.Duration = result.EndTime.Sub(result.StartTime)
 		return result
 	}
+	result.Prompt = prompt
 
 	// Build the completion request
 	req := ports.CompletionRequest{
-		ModelID:     e.delegate.selectModel(phase.RoutingProfile),
-		Messages:    e.delegate.buildMessages(prompt, dependencyOutputs),
-		MaxTokens:   phase.MaxTokens,
-		Temperature: phase.Temperature,
+		ModelID:        e.delegate.selectModel(phase.RoutingProfile),
+		Messages:       messages,
+		MaxTokens:      phase.MaxTokens,
+		Temperature:    phase.Temperature,
+		ResponseFormat: phaseResponseFormat(phase),
 	}
 
 	// Generate cache key
@@ -199,7 +209,7 @@ func (e *CachingStreamingPhaseExecutor) ExecuteWithStreaming(
 		}
 
 		result.Status = PhaseStatusCompleted
-		result.Output = cachedResp.Content
+		result.setOutput(phase, cachedResp.Content)
 		result.InputTokens = cachedResp.InputTokens
 		result.OutputTokens = cachedResp.OutputTokens
 		result.ModelUsed = cachedResp.ModelUsed
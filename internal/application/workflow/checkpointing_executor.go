@@ -2,7 +2,10 @@
 package workflow
 
 import (
+	"cmp"
 	"context"
+	stderrors "errors"
+	"fmt"
 	"log/slog"
 	"maps"
 	"sync"
@@ -16,6 +19,19 @@ import (
 	"github.com/jbctechsolutions/skillrunner/internal/domain/workflow"
 )
 
+// ErrInterrupted is the cause with which a run's context is canceled to
+// interrupt it for resuming later, such as when sr serve shuts down. An
+// interrupted run keeps its checkpoint in progress instead of failing it.
+var ErrInterrupted = stderrors.New("execution interrupted")
+
+// ErrCheckpointInUse is returned when resuming a checkpoint that another
+// machine updated within ActiveCheckpointWindow and may still be running.
+var ErrCheckpointInUse = stderrors.New("checkpoint in use by another machine")
+
+// ActiveCheckpointWindow is how long after its last update a checkpoint
+// claimed by another machine is treated as still running there.
+const ActiveCheckpointWindow = 15 * time.Minute
+
 // CheckpointConfig contains configuration for checkpoint-aware execution.
 type CheckpointConfig struct {
 	// Enabled enables checkpoint persistence.
@@ -27,6 +43,10 @@ type CheckpointConfig struct {
 	// Resume attempts to resume from an existing checkpoint if available.
 	Resume bool
 
+	// ResumeID is the checkpoint to resume when Resume is set. If empty, the
+	// latest in-progress checkpoint of the skill and input is resumed.
+	ResumeID string
+
 	// ExecutionID is the correlation ID for this execution.
 	// If empty and Resume is true, will try to find existing checkpoint.
 	// If empty and Resume is false, a new UUID will be generated.
@@ -35,6 +55,18 @@ type CheckpointConfig struct {
 	// MachineID identifies the machine running this execution.
 	MachineID string
 
+	// Takeover resumes a checkpoint even when another machine updated it
+	// within ActiveCheckpointWindow and may still be running it.
+	Takeover bool
+
+	// Tags are cost attribution tags recorded on new checkpoints.
+	Tags map[string]string
+
+	// Reuse reuses the completed phases of the latest earlier run of the
+	// skill on the same input whose prompts are unchanged, instead of
+	// running them again. Ignored when resuming a checkpoint.
+	Reuse bool
+
 	// Logger for checkpoint operations (optional).
 	Logger *slog.Logger
 }
@@ -45,6 +77,9 @@ type CheckpointingExecutor struct {
 	provider ports.ProviderPort
 	config   ExecutorConfig
 	cpConfig CheckpointConfig
+
+	// mu serializes the checkpoint updates of phases running at once
+	mu sync.Mutex
 }
 
 // NewCheckpointingExecutor creates a new executor with checkpoint support.
@@ -68,7 +103,10 @@ func (e *CheckpointingExecutor) Execute(ctx context.Context, s *domainSkill.Skil
 		return e.executor.Execute(ctx, s, input)
 	}
 
-	return e.executeWithCheckpoint(ctx, s, input)
+	ctx, end := traceWorkflow(ctx, s)
+	result, err := e.executeWithCheckpoint(ctx, s, input)
+	end(result, err)
+	return result, err
 }
 
 // executeWithCheckpoint handles checkpoint creation, updates, and resume.
@@ -115,8 +153,7 @@ func (e *CheckpointingExecutor) executeWithCheckpoint(ctx context.Context, s *do
 	// Try to resume from checkpoint if requested
 	var checkpoint *workflow.WorkflowCheckpoint
 	var startBatchIndex int
-	phaseOutputs := make(map[string]string)
-	phaseOutputs["_input"] = input
+	phaseOutputs := runVariables(input, e.config.InputFiles)
 
 	result := &ExecutionResult{
 		SkillID:      s.ID(),
@@ -137,6 +174,14 @@ func (e *CheckpointingExecutor) executeWithCheckpoint(ctx context.Context, s *do
 
 	if e.cpConfig.Resume {
 		checkpoint, err = e.tryResume(ctx, s, input, result, phaseOutputs)
+		if stderrors.Is(err, ports.ErrCheckpointConflict) || stderrors.Is(err, ErrCheckpointInUse) {
+			// Another machine resumed the checkpoint first, or may still be running it
+			result.Status = PhaseStatusFailed
+			result.Error = err
+			result.EndTime = time.Now()
+			e.markRemainingAsSkipped(result)
+			return result, err
+		}
 		if err != nil {
 			e.log("warn", "failed to resume from checkpoint", "error", err)
 		}
@@ -149,6 +194,17 @@ func (e *CheckpointingExecutor) executeWithCheckpoint(ctx context.Context, s *do
 		}
 	}
 
+	// Reuse unchanged phases of an earlier run, before this run's checkpoint
+	// becomes the latest
+	if e.cpConfig.Reuse && checkpoint == nil {
+		from, reused, err := e.reusePhases(ctx, s, dag, input, result, phaseOutputs)
+		if err != nil {
+			e.log("warn", "failed to look up phases to reuse", "error", err)
+		} else if reused > 0 {
+			e.log("info", "reusing phases of an earlier run", "execution_id", from, "phases", reused)
+		}
+	}
+
 	// Create new checkpoint if not resuming or no checkpoint found
 	if checkpoint == nil {
 		checkpoint, err = e.createCheckpoint(ctx, s, input, len(batches))
@@ -158,12 +214,24 @@ func (e *CheckpointingExecutor) executeWithCheckpoint(ctx context.Context, s *do
 			checkpoint = nil
 		}
 	}
-
-	// Execute batches
+	if checkpoint != nil {
+		result.ExecutionID = checkpoint.ExecutionID()
+		ctx = withChunkProgress(ctx, &checkpointChunks{executor: e, checkpoint: checkpoint, saved: checkpoint.PhaseOutputs()})
+	} else {
+		result.ExecutionID = cmp.Or(e.cpConfig.ExecutionID, uuid.New().String())
+	}
+	ctx, endLog := logWorkflow(ctx, s, result.ExecutionID)
+	ctx = auditRun(ctx, s, result.ExecutionID)
+	ctx = moderateRun(ctx, s)
+	defer func() { endLog(result, nil) }()
+
+	// Execute batches, warming the models of the next batch meanwhile
+	warmer := newModelWarmer(e.provider, e.config)
 	for batchIndex := startBatchIndex; batchIndex < len(batches); batchIndex++ {
 		batch := batches[batchIndex]
+		warmer.warmAhead(ctx, dag, batches, batchIndex)
 
-		if err := e.executeBatch(ctx, dag, batch, result, phaseOutputs); err != nil {
+		if err := e.executeBatch(ctx, dag, batchIndex, batch, result, phaseOutputs, checkpoint); err != nil {
 			result.Status = PhaseStatusFailed
 			result.Error = err
 			result.EndTime = time.Now()
@@ -172,8 +240,14 @@ func (e *CheckpointingExecutor) executeWithCheckpoint(ctx context.Context, s *do
 			// Mark remaining phases as skipped
 			e.markRemainingAsSkipped(result)
 
-			// Update checkpoint to failed if we have one
-			if checkpoint != nil {
+			// Update checkpoint to failed if we have one; an interrupted run
+			// keeps it in progress so that it can be resumed
+			if checkpoint != nil && stderrors.Is(context.Cause(ctx), ErrInterrupted) {
+				e.log("info", "execution interrupted, checkpoint kept for resume",
+					"checkpoint_id", checkpoint.ID(),
+					"completed_batch", checkpoint.CompletedBatch())
+			} else if checkpoint != nil {
+				e.recordFailure(checkpoint, dag, result)
 				checkpoint.MarkFailed()
 				if updateErr := e.cpConfig.Port.Update(ctx, checkpoint); updateErr != nil {
 					e.log("warn", "failed to update checkpoint status to failed", "error", updateErr)
@@ -186,6 +260,20 @@ func (e *CheckpointingExecutor) executeWithCheckpoint(ctx context.Context, s *do
 			return result, nil
 		}
 
+		// Update checkpoint after successful batch, even if the run is being
+		// canceled, so that a resume does not repeat the batch
+		if checkpoint != nil {
+			if err := e.updateCheckpoint(context.WithoutCancel(ctx), checkpoint, dag, batchIndex, result, phaseOutputs); err != nil {
+				// Another machine has taken the run over
+				result.Status = PhaseStatusFailed
+				result.Error = err
+				result.EndTime = time.Now()
+				result.Duration = result.EndTime.Sub(result.StartTime)
+				e.markRemainingAsSkipped(result)
+				return result, err
+			}
+		}
+
 		// Check for context cancellation
 		if ctx.Err() != nil {
 			result.Status = PhaseStatusFailed
@@ -195,11 +283,6 @@ func (e *CheckpointingExecutor) executeWithCheckpoint(ctx context.Context, s *do
 			e.markRemainingAsSkipped(result)
 			return result, ctx.Err()
 		}
-
-		// Update checkpoint after successful batch
-		if checkpoint != nil {
-			e.updateCheckpoint(ctx, checkpoint, batchIndex, result, phaseOutputs)
-		}
 	}
 
 	// Determine final output
@@ -210,6 +293,15 @@ func (e *CheckpointingExecutor) executeWithCheckpoint(ctx context.Context, s *do
 		result.TotalTokens += phaseResult.InputTokens + phaseResult.OutputTokens
 	}
 
+	// Save Markdown tables of phases with extract_tables
+	if err := extractPhaseTables(phases, result, e.config.ArtifactDir); err != nil {
+		result.Status = PhaseStatusFailed
+		result.Error = err
+		result.EndTime = time.Now()
+		result.Duration = result.EndTime.Sub(result.StartTime)
+		return result, nil
+	}
+
 	result.Status = PhaseStatusCompleted
 	result.EndTime = time.Now()
 	result.Duration = result.EndTime.Sub(result.StartTime)
@@ -233,14 +325,23 @@ func (e *CheckpointingExecutor) tryResume(
 	result *ExecutionResult,
 	phaseOutputs map[string]string,
 ) (*workflow.WorkflowCheckpoint, error) {
-	inputHash := workflow.HashInput(input)
-
-	checkpoint, err := e.cpConfig.Port.GetLatestInProgress(ctx, s.ID(), inputHash)
-	if err != nil {
+	checkpoint, err := e.resumableCheckpoint(ctx, s, input)
+	if err != nil || checkpoint == nil {
 		return nil, err
 	}
-	if checkpoint == nil {
-		return nil, nil
+
+	owner := checkpoint.MachineID()
+	if !e.cpConfig.Takeover && owner != "" && e.cpConfig.MachineID != "" && owner != e.cpConfig.MachineID {
+		if since := time.Since(checkpoint.UpdatedAt()); since < ActiveCheckpointWindow {
+			return nil, fmt.Errorf("%w: %s was updated by %s %s ago", ErrCheckpointInUse, checkpoint.ID(), owner, since.Round(time.Second))
+		}
+	}
+
+	// Claim the checkpoint for this machine. With a store that locks
+	// optimistically, only one of several machines resuming it succeeds.
+	checkpoint.SetMachineID(cmp.Or(e.cpConfig.MachineID, checkpoint.MachineID()))
+	if err := e.cpConfig.Port.Update(ctx, checkpoint); err != nil {
+		return nil, fmt.Errorf("failed to claim checkpoint %s: %w", checkpoint.ID(), err)
 	}
 
 	// Restore phase outputs
@@ -260,13 +361,46 @@ func (e *CheckpointingExecutor) tryResume(
 			pr.InputTokens = data.InputTokens
 			pr.OutputTokens = data.OutputTokens
 			pr.ModelUsed = data.ModelUsed
+			pr.ProviderUsed = data.ProviderUsed
 			pr.CacheHit = data.CacheHit
+			pr.Batch = data.Batch
+			pr.BatchJobID = data.BatchJobID
+			pr.Experiment = data.Experiment
+			pr.Variant = data.Variant
+			pr.ReusedFrom = data.ReusedFrom
+			pr.SkipReason = data.SkipReason
+			pr.Sample = data.Sample
+			if data.QueuedAt != 0 {
+				pr.QueuedAt = time.Unix(0, data.QueuedAt)
+			}
 		}
 	}
 
 	return checkpoint, nil
 }
 
+// resumableCheckpoint returns the checkpoint to resume: the one configured,
+// or the latest in-progress checkpoint of the skill and input.
+func (e *CheckpointingExecutor) resumableCheckpoint(ctx context.Context, s *domainSkill.Skill, input string) (*workflow.WorkflowCheckpoint, error) {
+	if e.cpConfig.ResumeID == "" {
+		return e.cpConfig.Port.GetLatestInProgress(ctx, s.ID(), workflow.HashInput(input))
+	}
+
+	checkpoint, err := e.cpConfig.Port.Get(ctx, e.cpConfig.ResumeID)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case checkpoint.SkillID() != s.ID():
+		return nil, fmt.Errorf("checkpoint %s belongs to skill %s, not %s", checkpoint.ID(), checkpoint.SkillID(), s.ID())
+	case checkpoint.InputHash() != workflow.HashInput(input):
+		return nil, fmt.Errorf("checkpoint %s was created for a different input", checkpoint.ID())
+	case !checkpoint.IsResumable():
+		return nil, fmt.Errorf("checkpoint %s is %s and cannot be resumed", checkpoint.ID(), checkpoint.Status())
+	}
+	return checkpoint, nil
+}
+
 // createCheckpoint creates a new checkpoint for this execution.
 func (e *CheckpointingExecutor) createCheckpoint(
 	ctx context.Context,
@@ -292,7 +426,13 @@ func (e *CheckpointingExecutor) createCheckpoint(
 	}
 
 	checkpoint.SetMachineID(e.cpConfig.MachineID)
+	checkpoint.SetTags(e.cpConfig.Tags)
 	checkpoint.AddPhaseOutput("_input", input)
+	for _, name := range domainSkill.FileVariables {
+		if value := e.config.InputFiles[name]; value != "" {
+			checkpoint.AddPhaseOutput(name, value)
+		}
+	}
 
 	if err := e.cpConfig.Port.Create(ctx, checkpoint); err != nil {
 		return nil, err
@@ -301,38 +441,29 @@ func (e *CheckpointingExecutor) createCheckpoint(
 	return checkpoint, nil
 }
 
-// updateCheckpoint updates the checkpoint after a batch completes.
+// updateCheckpoint updates the checkpoint after a batch completes. It fails
+// only if another machine has claimed the checkpoint, and logs other errors.
 func (e *CheckpointingExecutor) updateCheckpoint(
 	ctx context.Context,
 	checkpoint *workflow.WorkflowCheckpoint,
+	dag *workflow.DAG,
 	batchIndex int,
 	result *ExecutionResult,
 	phaseOutputs map[string]string,
-) {
+) error {
 	// Update completed batch
 	if err := checkpoint.UpdateBatch(batchIndex); err != nil {
 		e.log("warn", "failed to update batch in checkpoint", "error", err)
-		return
+		return nil
 	}
 
-	// Update phase results
+	// Update phase results, with the prompt hashes of completed phases for
+	// later runs to reuse them
 	for phaseID, pr := range result.PhaseResults {
-		if pr.Status == PhaseStatusCompleted || pr.Status == PhaseStatusFailed {
-			data := &workflow.PhaseResultData{
-				PhaseID:      pr.PhaseID,
-				PhaseName:    pr.PhaseName,
-				Status:       string(pr.Status),
-				Output:       pr.Output,
-				StartTime:    pr.StartTime.UnixNano(),
-				EndTime:      pr.EndTime.UnixNano(),
-				DurationNs:   pr.Duration.Nanoseconds(),
-				InputTokens:  pr.InputTokens,
-				OutputTokens: pr.OutputTokens,
-				ModelUsed:    pr.ModelUsed,
-				CacheHit:     pr.CacheHit,
-			}
-			if pr.Error != nil {
-				data.ErrorMessage = pr.Error.Error()
+		if pr.Status == PhaseStatusCompleted || pr.Status == PhaseStatusFailed || pr.SkipReason != "" {
+			data := phaseResultData(dag, pr)
+			if phase := dag.GetPhase(phaseID); phase != nil && pr.Status == PhaseStatusCompleted {
+				data.PromptHash = PhasePromptHash(phase, e.config.MemoryContent)
 			}
 			checkpoint.AddPhaseResult(phaseID, data)
 		}
@@ -352,18 +483,72 @@ func (e *CheckpointingExecutor) updateCheckpoint(
 	checkpoint.UpdateTokens(inputTokens, outputTokens)
 
 	// Save checkpoint
-	if err := e.cpConfig.Port.Update(ctx, checkpoint); err != nil {
+	err := e.cpConfig.Port.Update(ctx, checkpoint)
+	if stderrors.Is(err, ports.ErrCheckpointConflict) {
+		return fmt.Errorf("stopping run: %w", err)
+	}
+	if err != nil {
 		e.log("warn", "failed to update checkpoint", "error", err)
 	}
+	return nil
+}
+
+// recordFailure adds the results of the phases that failed, with their
+// failure class, to the checkpoint of a failed run.
+func (e *CheckpointingExecutor) recordFailure(checkpoint *workflow.WorkflowCheckpoint, dag *workflow.DAG, result *ExecutionResult) {
+	for phaseID, pr := range result.PhaseResults {
+		if pr.Status == PhaseStatusFailed && pr.Error != nil {
+			checkpoint.AddPhaseResult(phaseID, phaseResultData(dag, pr))
+		}
+	}
+}
+
+// phaseResultData converts a phase result for checkpoint storage. Errors are
+// stored as their message and classified.
+func phaseResultData(dag *workflow.DAG, pr *PhaseResult) *workflow.PhaseResultData {
+	data := &workflow.PhaseResultData{
+		PhaseID:      pr.PhaseID,
+		PhaseName:    pr.PhaseName,
+		Status:       string(pr.Status),
+		Output:       pr.Output,
+		StartTime:    pr.StartTime.UnixNano(),
+		EndTime:      pr.EndTime.UnixNano(),
+		DurationNs:   pr.Duration.Nanoseconds(),
+		InputTokens:  pr.InputTokens,
+		OutputTokens: pr.OutputTokens,
+		ModelUsed:    pr.ModelUsed,
+		ProviderUsed: pr.ProviderUsed,
+		CacheHit:     pr.CacheHit,
+		Batch:        pr.Batch,
+		BatchJobID:   pr.BatchJobID,
+		Experiment:   pr.Experiment,
+		Variant:      pr.Variant,
+		ReusedFrom:   pr.ReusedFrom,
+		SkipReason:   pr.SkipReason,
+		Sample:       pr.Sample,
+	}
+	if !pr.QueuedAt.IsZero() {
+		data.QueuedAt = pr.QueuedAt.UnixNano()
+	}
+	if phase := dag.GetPhase(pr.PhaseID); phase != nil {
+		data.RoutingProfile = phase.RoutingProfile
+	}
+	if pr.Error != nil {
+		data.ErrorMessage = pr.Error.Error()
+		data.FailureClass = string(workflow.ClassifyFailure(data.ErrorMessage))
+	}
+	return data
 }
 
 // executeBatch executes a batch of phases in parallel.
 func (e *CheckpointingExecutor) executeBatch(
 	ctx context.Context,
 	dag *workflow.DAG,
+	batchIndex int,
 	batch []string,
 	result *ExecutionResult,
 	phaseOutputs map[string]string,
+	checkpoint *workflow.WorkflowCheckpoint,
 ) error {
 	if len(batch) == 0 {
 		return nil
@@ -371,6 +556,38 @@ func (e *CheckpointingExecutor) executeBatch(
 
 	// Create phase executor
 	phaseExecutor := newPhaseExecutor(e.provider, e.config.MemoryContent)
+	phaseExecutor.selector = e.config.ProviderSelector
+	phaseExecutor.experiments = e.config.Experiments
+	phaseExecutor.media = newMediaBackends(e.provider, e.config)
+	phaseExecutor.groups = e.config.ConcurrencyGroups
+	phaseExecutor.sampling = newItemSampling(e.config)
+	phaseExecutor.windows = e.config.ContextWindows
+	phaseExecutor.fallbacks = e.config.Fallbacks
+	phaseExecutor.profiles = e.config.Profiles
+	phaseExecutor.latency = e.config.Latency
+
+	if e.config.BatchAPI {
+		if runner := newBatchJobRunner(e.provider, phaseExecutor, e.config.BatchPollInterval); runner != nil {
+			// Collect a job submitted before a restart rather than paying for it twice
+			var pending []string
+			var jobID string
+			for _, phaseID := range batch {
+				pr := result.PhaseResults[phaseID]
+				if pr != nil && pr.passesOutput() {
+					continue
+				}
+				if pr != nil && pr.BatchJobID != "" {
+					jobID = pr.BatchJobID
+				}
+				pending = append(pending, phaseID)
+			}
+
+			runner.onSubmit = func(jobID string, phaseIDs []string) {
+				e.recordBatchJob(ctx, checkpoint, dag, batchIndex, jobID, phaseIDs)
+			}
+			return runBatchJob(ctx, runner, dag, batchIndex, pending, result, phaseOutputs, jobID, e.gatherDependencyOutputs)
+		}
+	}
 
 	// Create a semaphore for limiting parallelism
 	sem := make(chan struct{}, e.config.MaxParallel)
@@ -386,7 +603,7 @@ func (e *CheckpointingExecutor) executeBatch(
 		}
 
 		// Skip already completed phases (from checkpoint restore)
-		if pr, ok := result.PhaseResults[phaseID]; ok && pr.Status == PhaseStatusCompleted {
+		if pr, ok := result.PhaseResults[phaseID]; ok && pr.passesOutput() {
 			e.log("debug", "skipping already completed phase", "phase_id", phaseID)
 			continue
 		}
@@ -394,6 +611,7 @@ func (e *CheckpointingExecutor) executeBatch(
 		wg.Add(1)
 		go func(p *domainSkill.Phase) {
 			defer wg.Done()
+			queuedAt := time.Now()
 
 			// Acquire semaphore
 			select {
@@ -420,12 +638,17 @@ func (e *CheckpointingExecutor) executeBatch(
 			mu.Unlock()
 
 			// Execute the phase
-			phaseResult := phaseExecutor.Execute(ctx, p, dependencyOutputs)
+			phaseCtx, endPhase := tracePhase(auditPhase(ctx, p.ID), p)
+			phaseResult := phaseExecutor.Execute(phaseCtx, p, dependencyOutputs)
+			endPhase(phaseResult)
+			logPhase(phaseCtx, phaseResult)
+			phaseResult.Batch = batchIndex
+			phaseResult.QueuedAt = queuedAt
 
 			// Store result
 			mu.Lock()
 			result.PhaseResults[p.ID] = phaseResult
-			if phaseResult.Status == PhaseStatusCompleted {
+			if phaseResult.passesOutput() {
 				phaseOutputs[p.ID] = phaseResult.Output
 			} else if phaseResult.Error != nil && firstErr == nil {
 				firstErr = phaseResult.Error
@@ -439,14 +662,76 @@ func (e *CheckpointingExecutor) executeBatch(
 	return firstErr
 }
 
+// recordBatchJob checkpoints a submitted batch job so that a resumed run
+// collects its results instead of submitting the phases again.
+func (e *CheckpointingExecutor) recordBatchJob(
+	ctx context.Context,
+	checkpoint *workflow.WorkflowCheckpoint,
+	dag *workflow.DAG,
+	batchIndex int,
+	jobID string,
+	phaseIDs []string,
+) {
+	if checkpoint == nil {
+		return
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for _, phaseID := range phaseIDs {
+		data := &workflow.PhaseResultData{
+			PhaseID:    phaseID,
+			Status:     string(PhaseStatusRunning),
+			Batch:      batchIndex,
+			BatchJobID: jobID,
+		}
+		if phase := dag.GetPhase(phaseID); phase != nil {
+			data.PhaseName = phase.Name
+		}
+		checkpoint.AddPhaseResult(phaseID, data)
+	}
+
+	if err := e.cpConfig.Port.Update(ctx, checkpoint); err != nil {
+		e.log("warn", "failed to record batch job in checkpoint", "batch_job_id", jobID, "error", err)
+	}
+}
+
+// chunkOutputKey is the phase output key under which a checkpoint keeps
+// the response to chunk i of n of a chunked phase.
+func chunkOutputKey(phaseID string, i, n int) string {
+	return fmt.Sprintf("_chunks/%s/%d-of-%d", phaseID, i+1, n)
+}
+
+// checkpointChunks keeps the chunk responses of chunked phases in a run's
+// checkpoint, updating it after each chunk so that a resumed run only
+// sends the chunks the interrupted run had not finished.
+type checkpointChunks struct {
+	executor   *CheckpointingExecutor
+	checkpoint *workflow.WorkflowCheckpoint
+	saved      map[string]string // chunk responses of the resumed run
+}
+
+func (c *checkpointChunks) chunkOutput(phaseID string, i, n int) (string, bool) {
+	output, ok := c.saved[chunkOutputKey(phaseID, i, n)]
+	return output, ok
+}
+
+func (c *checkpointChunks) saveChunkOutput(ctx context.Context, phaseID string, i, n int, output string) {
+	e := c.executor
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	c.checkpoint.AddPhaseOutput(chunkOutputKey(phaseID, i, n), output)
+	if err := e.cpConfig.Port.Update(context.WithoutCancel(ctx), c.checkpoint); err != nil {
+		e.log("warn", "failed to record chunk in checkpoint", "phase_id", phaseID, "chunk", i+1, "error", err)
+	}
+}
+
 // gatherDependencyOutputs collects outputs from all phases this phase depends on.
 func (e *CheckpointingExecutor) gatherDependencyOutputs(dag *workflow.DAG, phaseID string, phaseOutputs map[string]string) map[string]string {
 	deps := dag.GetDependencies(phaseID)
-	outputs := make(map[string]string, len(deps)+1)
+	outputs := make(map[string]string, len(deps)+len(domainSkill.FileVariables)+1)
 
-	if input, ok := phaseOutputs["_input"]; ok {
-		outputs["_input"] = input
-	}
+	copyRunVariables(outputs, phaseOutputs)
 
 	for _, depID := range deps {
 		if output, ok := phaseOutputs[depID]; ok {
@@ -2,6 +2,10 @@ package workflow
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
 	"sync"
 	"testing"
 	"time"
@@ -129,6 +133,15 @@ func (m *mockCheckpointPort) MarkAbandoned(ctx context.Context, machineID string
 	return count, nil
 }
 
+func (m *mockCheckpointPort) SetPinned(ctx context.Context, id string, pinned bool) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if cp, ok := m.checkpoints[id]; ok {
+		cp.SetPinned(pinned)
+	}
+	return nil
+}
+
 func (m *mockCheckpointPort) Cleanup(ctx context.Context, olderThan time.Duration) (int, error) {
 	return 0, nil
 }
@@ -234,6 +247,116 @@ func TestCheckpointingExecutor_Execute_CreatesCheckpoint(t *testing.T) {
 	}
 }
 
+func TestCheckpointingExecutor_Execute_RecordsFailureClass(t *testing.T) {
+	provider := newMockProvider()
+	provider.completeFunc = func(context.Context, ports.CompletionRequest) (*ports.CompletionResponse, error) {
+		return nil, errors.New("[PROVIDER] overloaded_error: Overloaded")
+	}
+	cpPort := newMockCheckpointPort()
+	exec := NewCheckpointingExecutor(provider, DefaultExecutorConfig(), CheckpointConfig{Enabled: true, Port: cpPort})
+
+	phase := createTestPhase(t, "phase1", "Phase 1", "Process: {{._input}}", nil)
+	s := createTestSkill(t, []skill.Phase{phase})
+	_, _ = exec.Execute(context.Background(), s, "test input")
+
+	cpPort.mu.Lock()
+	defer cpPort.mu.Unlock()
+	if len(cpPort.checkpoints) != 1 {
+		t.Fatalf("created %d checkpoints, want 1", len(cpPort.checkpoints))
+	}
+	for _, cp := range cpPort.checkpoints {
+		if cp.Status() != workflow.CheckpointStatusFailed {
+			t.Errorf("checkpoint status = %s, want failed", cp.Status())
+		}
+		failed := cp.FailedPhase()
+		if failed == nil || failed.FailureClass != string(workflow.FailureProviderOutage) {
+			t.Errorf("FailedPhase() = %+v, want a provider_outage failure", failed)
+		}
+	}
+}
+
+func TestCheckpointingExecutor_Execute_InterruptedKeepsCheckpoint(t *testing.T) {
+	ctx, interrupt := context.WithCancelCause(context.Background())
+	provider := newMockProvider()
+	provider.completeFunc = func(ctx context.Context, _ ports.CompletionRequest) (*ports.CompletionResponse, error) {
+		interrupt(ErrInterrupted)
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	cpPort := newMockCheckpointPort()
+	exec := NewCheckpointingExecutor(provider, DefaultExecutorConfig(), CheckpointConfig{Enabled: true, Port: cpPort})
+
+	phase := createTestPhase(t, "phase1", "Phase 1", "Process: {{._input}}", nil)
+	s := createTestSkill(t, []skill.Phase{phase})
+	_, _ = exec.Execute(ctx, s, "test input")
+
+	cpPort.mu.Lock()
+	defer cpPort.mu.Unlock()
+	for _, cp := range cpPort.checkpoints {
+		if cp.Status() != workflow.CheckpointStatusInProgress {
+			t.Errorf("checkpoint status = %s, want in_progress to resume the interrupted run", cp.Status())
+		}
+	}
+}
+
+func TestCheckpointingExecutor_Execute_RecordsChunks(t *testing.T) {
+	provider := newMockProvider()
+	provider.completeFunc = func(_ context.Context, req ports.CompletionRequest) (*ports.CompletionResponse, error) {
+		if strings.Contains(req.Messages[len(req.Messages)-1].Content, "processed in parts") {
+			return &ports.CompletionResponse{Content: "findings"}, nil
+		}
+		return nil, errors.New("reduce failed")
+	}
+	cpPort := newMockCheckpointPort()
+	exec := NewCheckpointingExecutor(provider, DefaultExecutorConfig(), CheckpointConfig{Enabled: true, Port: cpPort})
+
+	phase := createTestPhase(t, "audit", "Audit", "Audit the input", nil)
+	phase.WithMaxTokens(200).WithLongContext(skill.NewChunked(1000, 100))
+	s := createTestSkill(t, []skill.Phase{phase})
+	_, _ = exec.Execute(context.Background(), s, longInput(120))
+
+	cpPort.mu.Lock()
+	defer cpPort.mu.Unlock()
+	for _, cp := range cpPort.checkpoints {
+		var chunks int
+		for key, output := range cp.PhaseOutputs() {
+			if strings.HasPrefix(key, "_chunks/audit/") && output == "findings" {
+				chunks++
+			}
+		}
+		if chunks < 2 {
+			t.Errorf("checkpoint holds %d chunk responses, want those of every chunk", chunks)
+		}
+	}
+}
+
+func TestCheckpointingExecutor_Execute_RecordsTags(t *testing.T) {
+	cpPort := newMockCheckpointPort()
+	exec := NewCheckpointingExecutor(
+		newMockProvider(),
+		DefaultExecutorConfig(),
+		CheckpointConfig{
+			Enabled: true,
+			Port:    cpPort,
+			Tags:    map[string]string{"team": "search"},
+		},
+	)
+
+	phase := createTestPhase(t, "phase1", "Phase 1", "Process: {{._input}}", nil)
+	s := createTestSkill(t, []skill.Phase{phase})
+	if _, err := exec.Execute(context.Background(), s, "test input"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	cpPort.mu.Lock()
+	defer cpPort.mu.Unlock()
+	for _, cp := range cpPort.checkpoints {
+		if cp.Tags()["team"] != "search" {
+			t.Errorf("checkpoint tags = %v, want team=search", cp.Tags())
+		}
+	}
+}
+
 func TestCheckpointingExecutor_Execute_MultiPhase(t *testing.T) {
 	provider := newMockProvider()
 	cpPort := newMockCheckpointPort()
@@ -337,6 +460,232 @@ func TestCheckpointingExecutor_Execute_Resume(t *testing.T) {
 	}
 }
 
+func TestCheckpointingExecutor_Execute_ResumeClaimsCheckpoint(t *testing.T) {
+	// newPort stores a checkpoint of another machine, last updated at updated
+	newPort := func(updated time.Time) *mockCheckpointPort {
+		cpPort := newMockCheckpointPort()
+		cpPort.checkpoints["existing-cp"] = workflow.ReconstructCheckpoint(
+			"existing-cp", "exec-123", "test-skill", "Test Skill", "test input", workflow.HashInput("test input"),
+			0, 2,
+			map[string]*workflow.PhaseResultData{"phase1": {PhaseID: "phase1", PhaseName: "Phase 1", Status: "completed", Output: "Phase 1 output"}},
+			map[string]string{"phase1": "Phase 1 output"},
+			workflow.CheckpointStatusInProgress, 0, 0, "other-machine", updated.Add(-time.Minute), updated)
+		return cpPort
+	}
+	stale := time.Now().Add(-2 * ActiveCheckpointWindow)
+	phase1 := createTestPhase(t, "phase1", "Phase 1", "Process: {{._input}}", nil)
+	phase2 := createTestPhase(t, "phase2", "Phase 2", "Continue: {{.phase1}}", []string{"phase1"})
+	s := createTestSkill(t, []skill.Phase{phase1, phase2})
+	cpConfig := CheckpointConfig{Enabled: true, Resume: true, MachineID: "test-machine"}
+
+	t.Run("claimed", func(t *testing.T) {
+		cpConfig.Port = newPort(stale)
+		result, err := NewCheckpointingExecutor(newMockProvider(), DefaultExecutorConfig(), cpConfig).Execute(context.Background(), s, "test input")
+		if err != nil || result.Status != PhaseStatusCompleted {
+			t.Fatalf("Execute() = %v, %v", result, err)
+		}
+		if got := cpConfig.Port.(*mockCheckpointPort).checkpoints["existing-cp"].MachineID(); got != "test-machine" {
+			t.Errorf("checkpoint machine = %q, want test-machine", got)
+		}
+	})
+
+	t.Run("in use by another machine", func(t *testing.T) {
+		cpConfig.Port = newPort(time.Now())
+		provider := newMockProvider()
+		result, err := NewCheckpointingExecutor(provider, DefaultExecutorConfig(), cpConfig).Execute(context.Background(), s, "test input")
+		if !errors.Is(err, ErrCheckpointInUse) {
+			t.Fatalf("Execute() error = %v, want the checkpoint in use", err)
+		}
+		if result.Status != PhaseStatusFailed || provider.callCount.Load() != 0 {
+			t.Errorf("status = %s after %d provider calls, want failed without calls", result.Status, provider.callCount.Load())
+		}
+		if got := cpConfig.Port.(*mockCheckpointPort).checkpoints["existing-cp"].MachineID(); got != "other-machine" {
+			t.Errorf("checkpoint machine = %q, want it left to other-machine", got)
+		}
+	})
+
+	t.Run("taken over", func(t *testing.T) {
+		takeover := cpConfig
+		takeover.Port, takeover.Takeover = newPort(time.Now()), true
+		result, err := NewCheckpointingExecutor(newMockProvider(), DefaultExecutorConfig(), takeover).Execute(context.Background(), s, "test input")
+		if err != nil || result.Status != PhaseStatusCompleted {
+			t.Fatalf("Execute() = %v, %v", result, err)
+		}
+		if got := takeover.Port.(*mockCheckpointPort).checkpoints["existing-cp"].MachineID(); got != "test-machine" {
+			t.Errorf("checkpoint machine = %q, want test-machine", got)
+		}
+	})
+
+	t.Run("claimed by another machine first", func(t *testing.T) {
+		cpPort := newPort(stale)
+		cpPort.updateError = fmt.Errorf("%w: existing-cp", ports.ErrCheckpointConflict)
+		cpConfig.Port = cpPort
+		provider := newMockProvider()
+		result, err := NewCheckpointingExecutor(provider, DefaultExecutorConfig(), cpConfig).Execute(context.Background(), s, "test input")
+		if !errors.Is(err, ports.ErrCheckpointConflict) {
+			t.Fatalf("Execute() error = %v, want a checkpoint conflict", err)
+		}
+		if result.Status != PhaseStatusFailed || provider.callCount.Load() != 0 {
+			t.Errorf("status = %s after %d provider calls, want failed without calls", result.Status, provider.callCount.Load())
+		}
+	})
+}
+
+func TestCheckpointingExecutor_Execute_ResumeID(t *testing.T) {
+	cpPort := newMockCheckpointPort()
+	older, _ := workflow.NewWorkflowCheckpoint("older-cp", "exec-1", "test-skill", "Test Skill", "test input", 2)
+	older.AddPhaseOutput("phase1", "Phase 1 output")
+	older.AddPhaseResult("phase1", &workflow.PhaseResultData{PhaseID: "phase1", PhaseName: "Phase 1", Status: "completed", Output: "Phase 1 output"})
+	_ = older.UpdateBatch(0)
+	newer, _ := workflow.NewWorkflowCheckpoint("newer-cp", "exec-2", "test-skill", "Test Skill", "test input", 2)
+	cpPort.checkpoints["older-cp"], cpPort.checkpoints["newer-cp"] = older, newer
+
+	phase1 := createTestPhase(t, "phase1", "Phase 1", "Process: {{._input}}", nil)
+	phase2 := createTestPhase(t, "phase2", "Phase 2", "Continue: {{.phase1}}", []string{"phase1"})
+	s := createTestSkill(t, []skill.Phase{phase1, phase2})
+
+	provider := newMockProvider()
+	cpConfig := CheckpointConfig{Enabled: true, Port: cpPort, Resume: true, ResumeID: "older-cp", MachineID: "test-machine"}
+	result, err := NewCheckpointingExecutor(provider, DefaultExecutorConfig(), cpConfig).Execute(context.Background(), s, "test input")
+	if err != nil || result.Status != PhaseStatusCompleted {
+		t.Fatalf("Execute() = %v, %v", result, err)
+	}
+	if result.ExecutionID != "exec-1" || provider.callCount.Load() != 1 {
+		t.Errorf("execution %s with %d provider calls, want exec-1 resumed with 1 call", result.ExecutionID, provider.callCount.Load())
+	}
+	if older.Status() != workflow.CheckpointStatusCompleted || newer.Status() != workflow.CheckpointStatusInProgress {
+		t.Errorf("statuses = %s, %s; want only the resumed checkpoint completed", older.Status(), newer.Status())
+	}
+
+	// A checkpoint that is no longer in progress is not resumed
+	exec := NewCheckpointingExecutor(newMockProvider(), DefaultExecutorConfig(), cpConfig)
+	if _, err := exec.resumableCheckpoint(context.Background(), s, "test input"); err == nil {
+		t.Error("resumableCheckpoint() of a completed checkpoint should fail")
+	}
+	exec.cpConfig.ResumeID = "newer-cp"
+	if _, err := exec.resumableCheckpoint(context.Background(), s, "other input"); err == nil {
+		t.Error("resumableCheckpoint() for a different input should fail")
+	}
+}
+
+func TestCheckpointingExecutor_Execute_UpdateConflictStopsRun(t *testing.T) {
+	provider := newMockProvider()
+	cpPort := newMockCheckpointPort()
+	cpPort.updateError = fmt.Errorf("%w: cp", ports.ErrCheckpointConflict)
+
+	exec := NewCheckpointingExecutor(provider, DefaultExecutorConfig(), CheckpointConfig{
+		Enabled:   true,
+		Port:      cpPort,
+		MachineID: "test-machine",
+	})
+	phase1 := createTestPhase(t, "phase1", "Phase 1", "Process: {{._input}}", nil)
+	phase2 := createTestPhase(t, "phase2", "Phase 2", "Continue: {{.phase1}}", []string{"phase1"})
+	s := createTestSkill(t, []skill.Phase{phase1, phase2})
+
+	// Another machine took the run over after the first batch
+	result, err := exec.Execute(context.Background(), s, "test input")
+	if !errors.Is(err, ports.ErrCheckpointConflict) {
+		t.Fatalf("Execute() error = %v, want a checkpoint conflict", err)
+	}
+	if result.Status != PhaseStatusFailed || result.PhaseResults["phase2"].Status != PhaseStatusSkipped {
+		t.Errorf("status = %s, phase2 = %s; want failed and skipped", result.Status, result.PhaseResults["phase2"].Status)
+	}
+	if got := provider.callCount.Load(); got != 1 {
+		t.Errorf("provider called %d times, want 1", got)
+	}
+}
+
+func TestCheckpointingExecutor_Execute_Reuse(t *testing.T) {
+	provider := newMockProvider()
+	cpPort := newMockCheckpointPort()
+	cpConfig := CheckpointConfig{Enabled: true, Port: cpPort, MachineID: "test-machine", Reuse: true}
+
+	phase1 := createTestPhase(t, "phase1", "Phase 1", "Process: {{._input}}", nil)
+	phase2 := createTestPhase(t, "phase2", "Phase 2", "Continue: {{.phase1}}", []string{"phase1"})
+	phase3 := createTestPhase(t, "phase3", "Phase 3", "Finalize: {{.phase2}}", []string{"phase2"})
+
+	run := func(phases ...skill.Phase) (*ExecutionResult, int32) {
+		t.Helper()
+		before := provider.callCount.Load()
+		result, err := NewCheckpointingExecutor(provider, DefaultExecutorConfig(), cpConfig).
+			Execute(context.Background(), createTestSkill(t, phases), "test input")
+		if err != nil || result.Status != PhaseStatusCompleted {
+			t.Fatalf("Execute() = %v, %v; want a completed run", result, err)
+		}
+		return result, provider.callCount.Load() - before
+	}
+
+	first, calls := run(phase1, phase2, phase3)
+	if calls != 3 {
+		t.Fatalf("first run made %d provider calls, want 3 with nothing to reuse", calls)
+	}
+
+	// Changing the last phase's prompt reruns only that phase
+	phase3.PromptTemplate = "Finalize briefly: {{.phase2}}"
+	second, calls := run(phase1, phase2, phase3)
+	if calls != 1 {
+		t.Errorf("second run made %d provider calls, want 1 for the changed phase", calls)
+	}
+	for _, id := range []string{"phase1", "phase2"} {
+		pr := second.PhaseResults[id]
+		if pr.ReusedFrom != first.ExecutionID || !pr.CacheHit || pr.Output != first.PhaseResults[id].Output {
+			t.Errorf("phase %s = %+v, want the result reused from %s", id, pr, first.ExecutionID)
+		}
+	}
+	if second.PhaseResults["phase3"].ReusedFrom != "" {
+		t.Error("the changed phase should not be reused")
+	}
+
+	// Changing the first phase's prompt invalidates every phase after it
+	phase1.PromptTemplate = "Process carefully: {{._input}}"
+	if _, calls := run(phase1, phase2, phase3); calls != 3 {
+		t.Errorf("third run made %d provider calls, want 3 once the first phase changed", calls)
+	}
+}
+
+func TestPhasePromptHash(t *testing.T) {
+	phase := createTestPhase(t, "p", "P", "Summarize: {{._input}}", nil)
+	hash := PhasePromptHash(&phase, "")
+	if PhasePromptHash(&phase, "") != hash {
+		t.Error("PhasePromptHash() should be deterministic")
+	}
+	if PhasePromptHash(&phase, "memory") == hash {
+		t.Error("PhasePromptHash() should change with the memory content")
+	}
+	phase.Model = "gpt-4o"
+	if PhasePromptHash(&phase, "") == hash {
+		t.Error("PhasePromptHash() should change with the model")
+	}
+}
+
+func TestPhaseRequest(t *testing.T) {
+	provider := newMockProvider()
+	cpPort := newMockCheckpointPort()
+	config := DefaultExecutorConfig()
+	config.MemoryContent = "Use British spelling."
+
+	phase1 := createTestPhase(t, "phase1", "Phase 1", "Process: {{._input}}", nil)
+	phase2 := createTestPhase(t, "phase2", "Phase 2", "Continue: {{.phase1}}", []string{"phase1"})
+	phase2.MaxTokens = 200
+	cpConfig := CheckpointConfig{Enabled: true, Port: cpPort, MachineID: "test-machine"}
+	if _, err := NewCheckpointingExecutor(provider, config, cpConfig).Execute(context.Background(), createTestSkill(t, []skill.Phase{phase1, phase2}), "test input"); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if len(cpPort.checkpoints) != 1 || len(provider.completeCalls) != 2 {
+		t.Fatalf("got %d checkpoints and %d calls, want 1 and 2", len(cpPort.checkpoints), len(provider.completeCalls))
+	}
+	sent := provider.completeCalls[1]
+	for _, cp := range cpPort.checkpoints {
+		req, err := PhaseRequest(&phase2, config.MemoryContent, cp.PhaseOutputs())
+		if err != nil {
+			t.Fatalf("PhaseRequest() error = %v", err)
+		}
+		if !reflect.DeepEqual(req.Messages, sent.Messages) || req.MaxTokens != sent.MaxTokens {
+			t.Errorf("PhaseRequest() = %+v, want the request sent: %+v", req, sent)
+		}
+	}
+}
+
 func TestCheckpointingExecutor_Execute_NilSkill(t *testing.T) {
 	provider := newMockProvider()
 	cpPort := newMockCheckpointPort()
@@ -0,0 +1,246 @@
+package workflow
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"time"
+)
+
+// Chrome trace event phases (see the Trace Event Format specification).
+const (
+	traceEventComplete = "X"
+	traceEventMetadata = "M"
+)
+
+// traceSchedulerTID is the thread row used for scheduler (batch) events.
+// Phase rows start at 1.
+const traceSchedulerTID = 0
+
+// ChromeTraceEvent is a single event in the Chrome trace event format.
+type ChromeTraceEvent struct {
+	Name      string         `json:"name"`
+	Category  string         `json:"cat,omitempty"`
+	Phase     string         `json:"ph"`
+	Timestamp int64          `json:"ts"`            // Microseconds since the run started
+	Duration  int64          `json:"dur,omitempty"` // Microseconds
+	PID       int            `json:"pid"`
+	TID       int            `json:"tid"`
+	Args      map[string]any `json:"args,omitempty"`
+}
+
+// ChromeTrace is a trace document loadable by chrome://tracing and Perfetto.
+type ChromeTrace struct {
+	TraceEvents     []ChromeTraceEvent `json:"traceEvents"`
+	DisplayTimeUnit string             `json:"displayTimeUnit"`
+}
+
+// BuildChromeTrace converts an execution result into a Chrome trace.
+// Each phase becomes a slice on its own row; overlapping phases are placed on
+// separate rows so parallelism is visible. Time spent waiting for a
+// concurrency slot is shown as a separate "queued" slice, and each DAG batch
+// is shown on the scheduler row.
+func BuildChromeTrace(result *ExecutionResult) *ChromeTrace {
+	trace := &ChromeTrace{
+		TraceEvents:     []ChromeTraceEvent{},
+		DisplayTimeUnit: "ms",
+	}
+	if result == nil {
+		return trace
+	}
+
+	origin := result.StartTime
+	ts := func(t time.Time) int64 {
+		return t.Sub(origin).Microseconds()
+	}
+
+	trace.TraceEvents = append(trace.TraceEvents,
+		metadataEvent("process_name", 0, result.SkillName),
+		metadataEvent("thread_name", traceSchedulerTID, "scheduler"),
+	)
+
+	phases := tracedPhases(result)
+	lanes := assignTraceLanes(phases)
+
+	laneCount := 0
+	for _, pr := range phases {
+		tid := lanes[pr.PhaseID]
+		laneCount = max(laneCount, tid)
+
+		if !pr.QueuedAt.IsZero() && pr.StartTime.After(pr.QueuedAt) {
+			trace.TraceEvents = append(trace.TraceEvents, ChromeTraceEvent{
+				Name:      "queued: " + pr.PhaseID,
+				Category:  "scheduler",
+				Phase:     traceEventComplete,
+				Timestamp: ts(pr.QueuedAt),
+				Duration:  pr.StartTime.Sub(pr.QueuedAt).Microseconds(),
+				TID:       tid,
+			})
+		}
+
+		args := map[string]any{
+			"status":        string(pr.Status),
+			"batch":         pr.Batch,
+			"input_tokens":  pr.InputTokens,
+			"output_tokens": pr.OutputTokens,
+		}
+		if pr.ModelUsed != "" {
+			args["model"] = pr.ModelUsed
+		}
+		if pr.ProviderUsed != "" {
+			args["provider"] = pr.ProviderUsed
+		}
+		if pr.Cost > 0 {
+			args["cost"] = pr.Cost
+		}
+		if pr.CacheHit {
+			args["cache_hit"] = true
+		}
+		if pr.Error != nil {
+			args["error"] = pr.Error.Error()
+		}
+
+		trace.TraceEvents = append(trace.TraceEvents, ChromeTraceEvent{
+			Name:      pr.PhaseName,
+			Category:  "phase",
+			Phase:     traceEventComplete,
+			Timestamp: ts(pr.StartTime),
+			Duration:  pr.EndTime.Sub(pr.StartTime).Microseconds(),
+			TID:       tid,
+			Args:      args,
+		})
+	}
+
+	for tid := 1; tid <= laneCount; tid++ {
+		trace.TraceEvents = append(trace.TraceEvents, metadataEvent("thread_name", tid, fmt.Sprintf("slot %d", tid)))
+	}
+
+	trace.TraceEvents = append(trace.TraceEvents, batchEvents(phases, ts)...)
+
+	return trace
+}
+
+// WriteChromeTrace writes the execution result to w as Chrome trace JSON.
+func WriteChromeTrace(w io.Writer, result *ExecutionResult) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(BuildChromeTrace(result)); err != nil {
+		return fmt.Errorf("failed to encode chrome trace: %w", err)
+	}
+	return nil
+}
+
+// tracedPhases returns the phases that actually ran, ordered by start time.
+func tracedPhases(result *ExecutionResult) []*PhaseResult {
+	phases := make([]*PhaseResult, 0, len(result.PhaseResults))
+	for _, pr := range result.PhaseResults {
+		if pr == nil || pr.StartTime.IsZero() || pr.EndTime.IsZero() {
+			continue
+		}
+		phases = append(phases, pr)
+	}
+
+	slices.SortFunc(phases, func(a, b *PhaseResult) int {
+		if c := a.StartTime.Compare(b.StartTime); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.PhaseID, b.PhaseID)
+	})
+
+	return phases
+}
+
+// assignTraceLanes places phases on rows so that no two overlapping phases
+// share a row. Phases must be sorted by start time.
+func assignTraceLanes(phases []*PhaseResult) map[string]int {
+	lanes := make(map[string]int, len(phases))
+	var laneEnds []time.Time
+
+	for _, pr := range phases {
+		start := pr.StartTime
+		if !pr.QueuedAt.IsZero() && pr.QueuedAt.Before(start) {
+			start = pr.QueuedAt
+		}
+
+		lane := -1
+		for i, end := range laneEnds {
+			if !end.After(start) {
+				lane = i
+				break
+			}
+		}
+		if lane == -1 {
+			laneEnds = append(laneEnds, time.Time{})
+			lane = len(laneEnds) - 1
+		}
+
+		laneEnds[lane] = pr.EndTime
+		lanes[pr.PhaseID] = lane + 1
+	}
+
+	return lanes
+}
+
+// batchEvents returns one scheduler slice per DAG batch spanning the
+// phases scheduled in it.
+func batchEvents(phases []*PhaseResult, ts func(time.Time) int64) []ChromeTraceEvent {
+	type span struct {
+		start, end time.Time
+		phases     []string
+	}
+
+	spans := make(map[int]*span)
+	for _, pr := range phases {
+		start := pr.StartTime
+		if !pr.QueuedAt.IsZero() && pr.QueuedAt.Before(start) {
+			start = pr.QueuedAt
+		}
+
+		sp, ok := spans[pr.Batch]
+		if !ok {
+			spans[pr.Batch] = &span{start: start, end: pr.EndTime, phases: []string{pr.PhaseID}}
+			continue
+		}
+		if start.Before(sp.start) {
+			sp.start = start
+		}
+		if pr.EndTime.After(sp.end) {
+			sp.end = pr.EndTime
+		}
+		sp.phases = append(sp.phases, pr.PhaseID)
+	}
+
+	indices := make([]int, 0, len(spans))
+	for i := range spans {
+		indices = append(indices, i)
+	}
+	slices.Sort(indices)
+
+	events := make([]ChromeTraceEvent, 0, len(indices))
+	for _, i := range indices {
+		sp := spans[i]
+		events = append(events, ChromeTraceEvent{
+			Name:      fmt.Sprintf("batch %d", i),
+			Category:  "scheduler",
+			Phase:     traceEventComplete,
+			Timestamp: ts(sp.start),
+			Duration:  sp.end.Sub(sp.start).Microseconds(),
+			TID:       traceSchedulerTID,
+			Args:      map[string]any{"phases": sp.phases},
+		})
+	}
+
+	return events
+}
+
+// metadataEvent builds a metadata event naming a process or thread.
+func metadataEvent(name string, tid int, value string) ChromeTraceEvent {
+	return ChromeTraceEvent{
+		Name:  name,
+		Phase: traceEventMetadata,
+		TID:   tid,
+		Args:  map[string]any{"name": value},
+	}
+}
@@ -0,0 +1,100 @@
+package workflow
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/jbctechsolutions/skillrunner/internal/domain/skill"
+)
+
+func TestBuildChromeTrace_ParallelPhasesUseSeparateLanes(t *testing.T) {
+	origin := time.Now()
+	at := func(ms int) time.Time { return origin.Add(time.Duration(ms) * time.Millisecond) }
+
+	result := &ExecutionResult{
+		SkillName: "review",
+		StartTime: origin,
+		PhaseResults: map[string]*PhaseResult{
+			"a": {PhaseID: "a", PhaseName: "A", Status: PhaseStatusCompleted, Batch: 0, QueuedAt: at(0), StartTime: at(0), EndTime: at(100)},
+			"b": {PhaseID: "b", PhaseName: "B", Status: PhaseStatusCompleted, Batch: 0, QueuedAt: at(0), StartTime: at(20), EndTime: at(80)},
+			"c": {PhaseID: "c", PhaseName: "C", Status: PhaseStatusCompleted, Batch: 1, QueuedAt: at(100), StartTime: at(100), EndTime: at(150)},
+			"d": {PhaseID: "d", PhaseName: "D", Status: PhaseStatusSkipped},
+		},
+	}
+
+	trace := BuildChromeTrace(result)
+
+	phases := make(map[string]ChromeTraceEvent)
+	var queued, batches int
+	for _, ev := range trace.TraceEvents {
+		switch ev.Category {
+		case "phase":
+			phases[ev.Name] = ev
+		case "scheduler":
+			if ev.TID == traceSchedulerTID {
+				batches++
+			} else {
+				queued++
+			}
+		}
+	}
+
+	if len(phases) != 3 {
+		t.Fatalf("expected 3 phase events (skipped phase omitted), got %d", len(phases))
+	}
+	if phases["A"].TID == phases["B"].TID {
+		t.Error("overlapping phases should be on different lanes")
+	}
+	if phases["C"].TID != phases["A"].TID {
+		t.Error("sequential phase should reuse the first free lane")
+	}
+	if phases["B"].Timestamp != 20000 || phases["B"].Duration != 60000 {
+		t.Errorf("phase B timing = (%d, %d), want (20000, 60000)", phases["B"].Timestamp, phases["B"].Duration)
+	}
+	if queued != 1 {
+		t.Errorf("expected 1 queued event, got %d", queued)
+	}
+	if batches != 2 {
+		t.Errorf("expected 2 batch events, got %d", batches)
+	}
+}
+
+func TestWriteChromeTrace(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteChromeTrace(&buf, &ExecutionResult{SkillName: "empty", StartTime: time.Now()}); err != nil {
+		t.Fatalf("WriteChromeTrace failed: %v", err)
+	}
+
+	var decoded map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatalf("trace is not valid JSON: %v", err)
+	}
+	if _, ok := decoded["traceEvents"]; !ok {
+		t.Error("trace should contain traceEvents")
+	}
+}
+
+func TestExecutor_RecordsSchedulingData(t *testing.T) {
+	provider := newMockProvider()
+	exec := NewExecutor(provider, DefaultExecutorConfig())
+
+	sk := createTestSkill(t, []skill.Phase{
+		createTestPhase(t, "first", "First", "{{._input}}", nil),
+		createTestPhase(t, "second", "Second", "{{.first}}", []string{"first"}),
+	})
+
+	result, err := exec.Execute(t.Context(), sk, "input")
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+
+	first, second := result.PhaseResults["first"], result.PhaseResults["second"]
+	if first.Batch != 0 || second.Batch != 1 {
+		t.Errorf("batches = (%d, %d), want (0, 1)", first.Batch, second.Batch)
+	}
+	if first.QueuedAt.IsZero() || second.QueuedAt.Before(first.QueuedAt) {
+		t.Error("expected QueuedAt to be recorded in scheduling order")
+	}
+}
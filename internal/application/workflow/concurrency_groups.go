@@ -0,0 +1,47 @@
+package workflow
+
+import (
+	"context"
+	"fmt"
+)
+
+// ConcurrencyGroups limits how many phases of each named concurrency group
+// run at once. One ConcurrencyGroups is shared by the executors of all runs
+// in a process, so phases that load the same hardware (e.g., a local GPU)
+// do not oversubscribe it when runs overlap, as they do in sr serve.
+type ConcurrencyGroups struct {
+	slots map[string]chan struct{} // by group; not modified after creation
+}
+
+// NewConcurrencyGroups creates the concurrency groups with the given maximum
+// number of phases running at once by group name. Groups that are not
+// listed, or whose limit is not positive, are unlimited.
+func NewConcurrencyGroups(limits map[string]int) *ConcurrencyGroups {
+	g := &ConcurrencyGroups{slots: make(map[string]chan struct{}, len(limits))}
+	for name, limit := range limits {
+		if name != "" && limit > 0 {
+			g.slots[name] = make(chan struct{}, limit)
+		}
+	}
+	return g
+}
+
+// Acquire waits until a phase of group may run, returning the function that
+// releases its slot. It returns at once for a nil receiver, an empty group
+// and unlimited groups, and fails if ctx ends first.
+func (g *ConcurrencyGroups) Acquire(ctx context.Context, group string) (func(), error) {
+	if g == nil || group == "" {
+		return func() {}, nil
+	}
+	slots, ok := g.slots[group]
+	if !ok {
+		return func() {}, nil
+	}
+
+	select {
+	case slots <- struct{}{}:
+		return func() { <-slots }, nil
+	case <-ctx.Done():
+		return nil, fmt.Errorf("waiting for concurrency group %q: %w", group, ctx.Err())
+	}
+}
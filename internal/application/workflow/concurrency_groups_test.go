@@ -0,0 +1,101 @@
+package workflow
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jbctechsolutions/skillrunner/internal/application/ports"
+	"github.com/jbctechsolutions/skillrunner/internal/domain/skill"
+	"github.com/jbctechsolutions/skillrunner/internal/infrastructure/logging"
+)
+
+func TestConcurrencyGroups_Acquire(t *testing.T) {
+	groups := NewConcurrencyGroups(map[string]int{"gpu": 1, "off": 0})
+	ctx := context.Background()
+
+	release, err := groups.Acquire(ctx, "gpu")
+	if err != nil {
+		t.Fatalf("Acquire() error = %v", err)
+	}
+
+	// The group is full until the slot is released
+	waitCtx, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer cancel()
+	if _, err := groups.Acquire(waitCtx, "gpu"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Acquire() on a full group error = %v, want deadline exceeded", err)
+	}
+	release()
+	release, err = groups.Acquire(ctx, "gpu")
+	if err != nil {
+		t.Fatalf("Acquire() after release error = %v", err)
+	}
+	defer release()
+
+	// Unlisted groups, groups without a positive limit and phases without a
+	// group are unlimited
+	for _, group := range []string{"scraper", "off", ""} {
+		for range 3 {
+			if _, err := groups.Acquire(waitCtx, group); err != nil {
+				t.Errorf("Acquire(%q) error = %v", group, err)
+			}
+		}
+	}
+
+	var none *ConcurrencyGroups
+	if _, err := none.Acquire(ctx, "gpu"); err != nil {
+		t.Errorf("Acquire() on nil groups error = %v", err)
+	}
+}
+
+func TestExecutor_ConcurrencyGroupsAcrossRuns(t *testing.T) {
+	var running, maxRunning atomic.Int32
+	provider := newMockProvider()
+	provider.completeFunc = func(_ context.Context, req ports.CompletionRequest) (*ports.CompletionResponse, error) {
+		n := running.Add(1)
+		defer running.Add(-1)
+		for {
+			m := maxRunning.Load()
+			if n <= m || maxRunning.CompareAndSwap(m, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		return &ports.CompletionResponse{Content: "ok", ModelUsed: req.ModelID}, nil
+	}
+
+	config := DefaultExecutorConfig()
+	config.ConcurrencyGroups = NewConcurrencyGroups(map[string]int{"gpu": 1})
+
+	phases := make([]skill.Phase, 3)
+	for i, id := range []string{"a", "b", "c"} {
+		phases[i] = createTestPhase(t, id, "Phase "+id, "Prompt {{._input}}", nil)
+		phases[i].WithConcurrencyGroup("gpu")
+	}
+	s := createTestSkill(t, phases)
+
+	// Two runs whose phases could all run in parallel
+	logging.Default()
+	var wg sync.WaitGroup
+	for range 2 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			result, err := NewExecutor(provider, config).Execute(context.Background(), s, "input")
+			if err != nil || result.Status != PhaseStatusCompleted {
+				t.Errorf("Execute() = %v, %v", result, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got := maxRunning.Load(); got != 1 {
+		t.Errorf("at most %d gpu phases ran at once, want 1", got)
+	}
+	if got := provider.callCount.Load(); got != 6 {
+		t.Errorf("provider called %d times, want 6", got)
+	}
+}
@@ -0,0 +1,122 @@
+package workflow
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/jbctechsolutions/skillrunner/internal/domain/skill"
+)
+
+// falsyValues are the rendered values for which a condition without an
+// operator does not hold, compared regardless of case.
+var falsyValues = []string{"", "false", "no", "0"}
+
+// renderFunc renders a template with a phase's dependency outputs.
+type renderFunc func(templateStr string) (string, error)
+
+// conditionResult evaluates a phase's when and unless conditions. It
+// returns the skipped result of a phase whose conditions are not met, the
+// failed result of a phase whose conditions cannot be evaluated, or nil
+// when the phase runs.
+func conditionResult(phase *skill.Phase, render renderFunc) *PhaseResult {
+	if !phase.IsConditional() {
+		return nil
+	}
+	now := time.Now()
+
+	reason, err := skipReason(phase, render)
+	if err != nil {
+		return failedPhaseResult(phase, now, err)
+	}
+	if reason == "" {
+		return nil
+	}
+	return &PhaseResult{
+		PhaseID:    phase.ID,
+		PhaseName:  phase.Name,
+		Status:     PhaseStatusSkipped,
+		SkipReason: reason,
+		StartTime:  now,
+		EndTime:    now,
+	}
+}
+
+// skipReason returns why a phase's conditions are not met, or "" when they
+// are.
+func skipReason(phase *skill.Phase, render renderFunc) (string, error) {
+	if phase.When != "" {
+		holds, err := evalCondition(phase.When, render)
+		if err != nil {
+			return "", fmt.Errorf("failed to evaluate when: %w", err)
+		}
+		if !holds {
+			return fmt.Sprintf("when %s is false", phase.When), nil
+		}
+	}
+	if phase.Unless != "" {
+		holds, err := evalCondition(phase.Unless, render)
+		if err != nil {
+			return "", fmt.Errorf("failed to evaluate unless: %w", err)
+		}
+		if holds {
+			return fmt.Sprintf("unless %s is true", phase.Unless), nil
+		}
+	}
+	return "", nil
+}
+
+// evalCondition reports whether a condition expression holds.
+func evalCondition(expr string, render renderFunc) (bool, error) {
+	cond, err := skill.ParseCondition(expr)
+	if err != nil {
+		return false, err
+	}
+	left, err := conditionSide(cond.Left, render)
+	if err != nil {
+		return false, err
+	}
+
+	switch cond.Operator {
+	case "":
+		return !slices.Contains(falsyValues, strings.ToLower(left)), nil
+	case skill.ConditionMatches:
+		pattern, err := conditionSide(cond.Right, render)
+		if err != nil {
+			return false, err
+		}
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return false, fmt.Errorf("%w: %v", skill.ErrInvalidConditionRegex, err)
+		}
+		return re.MatchString(left), nil
+	default:
+		right, err := conditionSide(cond.Right, render)
+		if err != nil {
+			return false, err
+		}
+		return strings.EqualFold(left, right) == (cond.Operator == skill.ConditionEquals), nil
+	}
+}
+
+// conditionSide returns the value of a condition side: a quoted literal, or
+// a rendered template with surrounding whitespace trimmed.
+func conditionSide(side string, render renderFunc) (string, error) {
+	if literal, ok := skill.ConditionLiteral(side); ok {
+		return literal, nil
+	}
+	value, err := render(side)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(value), nil
+}
+
+// passesOutput reports whether a phase's output is passed on to the phases
+// that depend on it: the output of a completed phase, or the empty output of
+// a phase skipped by its conditions.
+func (r *PhaseResult) passesOutput() bool {
+	return r.Status == PhaseStatusCompleted || r.SkipReason != ""
+}
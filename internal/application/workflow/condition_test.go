@@ -0,0 +1,90 @@
+package workflow
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/jbctechsolutions/skillrunner/internal/application/ports"
+	"github.com/jbctechsolutions/skillrunner/internal/domain/skill"
+)
+
+func TestEvalCondition(t *testing.T) {
+	outputs := map[string]string{"classify": "  Bug\n", "review": "Status: APPROVED", "tests": "no"}
+	render := func(t string) (string, error) {
+		return renderPrompt(t, outputs, nil)
+	}
+
+	tests := []struct {
+		expr string
+		want bool
+	}{
+		{`{{.classify}} == "bug"`, true},
+		{`{{.classify}} == 'feature'`, false},
+		{`{{.classify}} != "feature"`, true},
+		{`{{.review}} =~ "APPROVED$"`, true},
+		{`{{.review}} =~ "^approved"`, false},
+		{`{{.classify}}`, true},
+		{`{{.tests}}`, false},
+		{`{{if eq .tests "no"}}true{{end}}`, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			got, err := evalCondition(tt.expr, render)
+			if err != nil {
+				t.Fatalf("evalCondition() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("evalCondition() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := evalCondition(`{{.classify | nosuchfunc}}`, render); err == nil {
+		t.Error("evalCondition() with an invalid template succeeded")
+	}
+	if _, err := evalCondition(`{{.missing}} == "bug"`, render); err == nil {
+		t.Error("evalCondition() with a missing key succeeded")
+	}
+}
+
+func TestExecutor_Conditions(t *testing.T) {
+	provider := newMockProvider()
+	provider.completeFunc = func(_ context.Context, req ports.CompletionRequest) (*ports.CompletionResponse, error) {
+		prompt := req.Messages[len(req.Messages)-1].Content
+		if strings.HasPrefix(prompt, "Classify") {
+			return &ports.CompletionResponse{Content: "bug"}, nil
+		}
+		return &ports.CompletionResponse{Content: "done: " + prompt}, nil
+	}
+
+	classify := createTestPhase(t, "classify", "Classify", "Classify {{._input}}", nil)
+	fix := createTestPhase(t, "fix", "Fix", "Fix {{._input}}", []string{"classify"})
+	fix.WithWhen(`{{.classify}} == "bug"`)
+	spec := createTestPhase(t, "spec", "Spec", "Spec {{._input}}", []string{"classify"})
+	spec.WithUnless(`{{.classify}} == "bug"`)
+	summary := createTestPhase(t, "summary", "Summary", "Summary [{{.fix}}] [{{.spec}}]", []string{"fix", "spec"})
+	s := createTestSkill(t, []skill.Phase{classify, fix, spec, summary})
+
+	result, err := NewExecutor(provider, DefaultExecutorConfig()).Execute(context.Background(), s, "crash on save")
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if result.Status != PhaseStatusCompleted {
+		t.Fatalf("status = %s, want completed", result.Status)
+	}
+	if pr := result.PhaseResults["fix"]; pr.Status != PhaseStatusCompleted {
+		t.Errorf("fix status = %s, want completed", pr.Status)
+	}
+	pr := result.PhaseResults["spec"]
+	if pr.Status != PhaseStatusSkipped || pr.SkipReason != `unless {{.classify}} == "bug" is true` {
+		t.Errorf("spec status, reason = %s, %q; want skipped by its condition", pr.Status, pr.SkipReason)
+	}
+	// The skipped phase passes on an empty output
+	if !strings.HasSuffix(result.FinalOutput, "] []") {
+		t.Errorf("final output = %q, want an empty spec", result.FinalOutput)
+	}
+	if got := provider.callCount.Load(); got != 3 {
+		t.Errorf("provider called %d times, want 3", got)
+	}
+}
@@ -0,0 +1,125 @@
+package workflow
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/jbctechsolutions/skillrunner/internal/application/ports"
+	"github.com/jbctechsolutions/skillrunner/internal/application/provider"
+	"github.com/jbctechsolutions/skillrunner/internal/domain/skill"
+)
+
+// ErrContextBudgetTooSmall is returned when a context budget leaves no room
+// for the prompt once the phase's output tokens are reserved.
+var ErrContextBudgetTooSmall = errors.New("context budget too small")
+
+// contextTrimMarker replaces the middle of a component trimmed to fit its
+// allocation.
+const contextTrimMarker = "\n\n[... trimmed to fit the context budget ...]\n\n"
+
+// ContextWindowChecker checks that a request fits the context window of the
+// model that serves it, such as *provider.Router.
+type ContextWindowChecker interface {
+	CheckContextWindow(ctx context.Context, selection *provider.ModelSelection, req ports.CompletionRequest) error
+}
+
+// checkContextWindow fails a phase request that does not fit the context
+// window of its model before it is sent. Phases with a long_context
+// strategy split oversized requests themselves and are not checked, and
+// requests whose tokens cannot be counted are sent as they are.
+func checkContextWindow(ctx context.Context, checker ContextWindowChecker, phase *skill.Phase, p ports.ProviderPort, req ports.CompletionRequest) error {
+	if checker == nil || phase.LongContext != nil {
+		return nil
+	}
+	selection := &provider.ModelSelection{ModelID: req.ModelID, ProviderName: p.Info().Name}
+	if err := checker.CheckContextWindow(ctx, selection, req); errors.Is(err, provider.ErrContextWindowExceeded) {
+		return fmt.Errorf("phase %s: %w; set a context_budget or long_context strategy to fit it", phase.ID, err)
+	}
+	return nil
+}
+
+// fitContextBudget renders the phase prompt with render and, when the phase
+// has a context budget that the memory, input, dependency outputs and prompt
+// together exceed, trims each to its allocation. It returns the memory,
+// dependency outputs and prompt to build the request from.
+func fitContextBudget(phase *skill.Phase, memory string, dependencyOutputs map[string]string, render func(map[string]string) (string, error)) (string, map[string]string, string, error) {
+	prompt, err := render(dependencyOutputs)
+	if err != nil {
+		return "", nil, "", err
+	}
+	budget := phase.ContextBudget
+	if budget == nil {
+		return memory, dependencyOutputs, prompt, nil
+	}
+
+	available := budget.MaxTokens - phase.MaxTokens - windowFramingTokens
+	if available <= 0 {
+		return "", nil, "", fmt.Errorf("%w: phase %s needs max_tokens above %d", ErrContextBudgetTooSmall, phase.ID, phase.MaxTokens+windowFramingTokens)
+	}
+
+	depSizes := make(map[string]int, len(dependencyOutputs))
+	depTokens := 0
+	for id, output := range dependencyOutputs {
+		if id != "_input" {
+			depSizes[id] = estimateTokens(output)
+			depTokens += depSizes[id]
+		}
+	}
+	sizes := map[string]int{
+		skill.ContextMemory:       estimateTokens(memory),
+		skill.ContextInput:        estimateTokens(dependencyOutputs["_input"]),
+		skill.ContextDependencies: depTokens,
+		skill.ContextInstructions: estimateTokens(prompt),
+	}
+	total := 0
+	for _, tokens := range sizes {
+		total += tokens
+	}
+	if total <= available {
+		return memory, dependencyOutputs, prompt, nil
+	}
+
+	alloc := budget.Allocate(sizes, available)
+	trimmed := make(map[string]string, len(dependencyOutputs))
+	for id, tokens := range skill.FillBudget(depSizes, nil, alloc[skill.ContextDependencies]) {
+		trimmed[id] = trimToTokens(dependencyOutputs[id], tokens)
+	}
+	if input, ok := dependencyOutputs["_input"]; ok {
+		trimmed["_input"] = trimToTokens(input, alloc[skill.ContextInput])
+	}
+
+	// The prompt template may embed the input and dependency outputs, so it
+	// is rendered again from the trimmed ones before it is trimmed itself
+	prompt, err = render(trimmed)
+	if err != nil {
+		return "", nil, "", err
+	}
+	return trimToTokens(memory, alloc[skill.ContextMemory]), trimmed, trimToTokens(prompt, alloc[skill.ContextInstructions]), nil
+}
+
+// trimToTokens shortens text to about tokens by keeping its beginning and
+// end, cut at paragraph, line or word boundaries, around contextTrimMarker.
+func trimToTokens(text string, tokens int) string {
+	limit := tokens * windowCharsPerToken
+	if len(text) <= limit {
+		return text
+	}
+	keep := limit - len(contextTrimMarker)
+	if keep <= 0 {
+		return ""
+	}
+
+	head := ""
+	if parts := provider.SplitText(text, keep/2); len(parts) > 0 {
+		head = parts[0]
+	}
+	tail := text[len(text)-(keep-len(head)):]
+	if i := strings.Index(tail, "\n"); i >= 0 && i < len(tail)/2 {
+		tail = tail[i+1:]
+	} else if i := strings.IndexByte(tail, ' '); i >= 0 {
+		tail = tail[i+1:]
+	}
+	return head + contextTrimMarker + strings.TrimSpace(tail)
+}
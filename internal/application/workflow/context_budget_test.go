@@ -0,0 +1,147 @@
+package workflow
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/jbctechsolutions/skillrunner/internal/application/ports"
+	"github.com/jbctechsolutions/skillrunner/internal/application/provider"
+	"github.com/jbctechsolutions/skillrunner/internal/domain/skill"
+)
+
+func TestPhaseExecutor_ContextBudget(t *testing.T) {
+	provider := newMockProvider()
+	phase := createTestPhase(t, "review", "Review", "Review the draft of {{.draft | printf \"%.30s\"}}", nil)
+	phase.WithMaxTokens(200).WithContextBudget(&skill.ContextBudgetConfig{
+		MaxTokens: 2000,
+		Shares:    map[string]int{skill.ContextInput: 50, skill.ContextDependencies: 30},
+	})
+
+	memory := longInput(20) // About 500 tokens each
+	deps := map[string]string{"_input": longInput(80), "draft": longInput(40)}
+	result := newPhaseExecutor(provider, memory).Execute(context.Background(), &phase, deps)
+
+	if result.Status != PhaseStatusCompleted {
+		t.Fatalf("expected completed phase, got %s: %v", result.Status, result.Error)
+	}
+	req := provider.completeCalls[0]
+	if tokens := estimateRequestTokens(req) + req.MaxTokens; tokens > 2000 {
+		t.Errorf("request uses %d tokens, over the 2000-token budget", tokens)
+	}
+	if len(req.Messages) != 3 {
+		t.Fatalf("expected memory, context and prompt messages, got %d", len(req.Messages))
+	}
+	if !strings.HasPrefix(req.Messages[0].Content, "Project Memory:") {
+		t.Errorf("expected the memory first, got %.40q", req.Messages[0].Content)
+	}
+	for _, want := range []string{"Paragraph 001", "Paragraph 080", contextTrimMarker} {
+		if !strings.Contains(req.Messages[1].Content, want) {
+			t.Errorf("expected the trimmed context to contain %q", want)
+		}
+	}
+	if result.Prompt != "Review the draft of Paragraph 001: lorem ipsum lor" {
+		t.Errorf("expected the prompt to fit unchanged, got %q", result.Prompt)
+	}
+}
+
+func TestPhaseExecutor_ContextBudgetFits(t *testing.T) {
+	provider := newMockProvider()
+	phase := createTestPhase(t, "review", "Review", "Review the input", nil)
+	phase.WithMaxTokens(200).WithContextBudget(&skill.ContextBudgetConfig{
+		MaxTokens:  2000,
+		Priorities: map[string]int{skill.ContextInstructions: 1},
+	})
+
+	input := longInput(10)
+	result := newPhaseExecutor(provider, "").Execute(context.Background(), &phase, map[string]string{"_input": input})
+
+	if result.Status != PhaseStatusCompleted {
+		t.Fatalf("expected completed phase, got %s: %v", result.Status, result.Error)
+	}
+	if got := provider.completeCalls[0].Messages[0].Content; !strings.HasSuffix(got, input) {
+		t.Errorf("expected a request that fits to be sent unchanged")
+	}
+}
+
+func TestPhaseExecutor_ContextBudgetTooSmall(t *testing.T) {
+	provider := newMockProvider()
+	phase := createTestPhase(t, "review", "Review", "Review the input", nil)
+	phase.WithMaxTokens(400).WithContextBudget(&skill.ContextBudgetConfig{
+		MaxTokens: 500,
+		Shares:    map[string]int{skill.ContextInput: 50},
+	})
+
+	result := newPhaseExecutor(provider, "").Execute(context.Background(), &phase, map[string]string{"_input": "short"})
+
+	if result.Status != PhaseStatusFailed || !errors.Is(result.Error, ErrContextBudgetTooSmall) {
+		t.Errorf("expected ErrContextBudgetTooSmall, got %s: %v", result.Status, result.Error)
+	}
+	if len(provider.completeCalls) != 0 {
+		t.Errorf("expected no provider calls, got %d", len(provider.completeCalls))
+	}
+}
+
+// windowChecker fails requests of more than window estimated tokens.
+type windowChecker struct {
+	window     int
+	selections []*provider.ModelSelection
+}
+
+func (c *windowChecker) CheckContextWindow(_ context.Context, selection *provider.ModelSelection, req ports.CompletionRequest) error {
+	c.selections = append(c.selections, selection)
+	if estimateRequestTokens(req)+req.MaxTokens > c.window {
+		return provider.ErrContextWindowExceeded
+	}
+	return nil
+}
+
+func TestPhaseExecutor_ContextWindow(t *testing.T) {
+	mock := newMockProvider()
+	checker := &windowChecker{window: 1000}
+	executor := newPhaseExecutor(mock, "")
+	executor.windows = checker
+
+	phase := createTestPhase(t, "review", "Review", "Review {{._input}}", nil)
+	phase.WithMaxTokens(200)
+	result := executor.Execute(context.Background(), &phase, map[string]string{"_input": longInput(80)})
+	if result.Status != PhaseStatusFailed || !errors.Is(result.Error, provider.ErrContextWindowExceeded) {
+		t.Errorf("expected ErrContextWindowExceeded, got %s: %v", result.Status, result.Error)
+	}
+	if len(mock.completeCalls) != 0 {
+		t.Errorf("expected no provider calls, got %d", len(mock.completeCalls))
+	}
+	if got := checker.selections[0]; got.ProviderName != "mock" || got.ModelID == "" {
+		t.Errorf("checked selection = %+v, want the phase's provider and model", got)
+	}
+
+	// Requests that fit are sent
+	if result := executor.Execute(context.Background(), &phase, map[string]string{"_input": "short"}); result.Status != PhaseStatusCompleted {
+		t.Errorf("small request: %s: %v", result.Status, result.Error)
+	}
+
+	// Long-context strategies split oversized requests themselves
+	phase.WithLongContext(&skill.LongContextConfig{Strategy: skill.LongContextSlidingWindow, WindowTokens: 4000, CarryoverTokens: 100})
+	if result := executor.Execute(context.Background(), &phase, map[string]string{"_input": longInput(80)}); result.Status != PhaseStatusCompleted {
+		t.Errorf("long-context phase: %s: %v", result.Status, result.Error)
+	}
+}
+
+func TestTrimToTokens(t *testing.T) {
+	if got := trimToTokens("short", 10); got != "short" {
+		t.Errorf("trimToTokens() = %q, want unchanged", got)
+	}
+
+	text := strings.TrimSpace(longInput(40))
+	got := trimToTokens(text, 200)
+	if estimateTokens(got) > 200 {
+		t.Errorf("trimToTokens() left %d tokens, want at most 200", estimateTokens(got))
+	}
+	if !strings.HasPrefix(got, "Paragraph 001") || !strings.HasSuffix(got, text[len(text)-20:]) || strings.Contains(got, contextTrimMarker+"lorem") {
+		t.Errorf("trimToTokens() should keep the beginning and end, got %q", got)
+	}
+	if got := trimToTokens(text, 5); got != "" {
+		t.Errorf("trimToTokens() = %q, want nothing when no room is left", got)
+	}
+}
@@ -3,6 +3,7 @@ package workflow
 
 import (
 	"context"
+	"github.com/google/uuid"
 	"sync"
 	"time"
 
@@ -29,6 +30,7 @@ type PhaseResult struct {
 	PhaseName    string
 	Status       PhaseStatus
 	Output       string
+	Prompt       string // Rendered prompt the phase sent, if it ran
 	Error        error
 	StartTime    time.Time
 	EndTime      time.Time
@@ -36,13 +38,25 @@ type PhaseResult struct {
 	InputTokens  int
 	OutputTokens int
 	ModelUsed    string
-	CacheHit     bool    // Wave 10: Whether the result was served from cache
-	Cost         float64 // Cost in USD for this phase execution
+	ProviderUsed string           // Name of the provider that served the phase
+	Batch        int              // Index of the DAG batch the phase was scheduled in
+	QueuedAt     time.Time        // When the phase was scheduled, before waiting for a concurrency slot
+	CacheHit     bool             // Wave 10: Whether the result was served from cache
+	Cost         float64          // Cost in USD for this phase execution
+	BatchJobID   string           // Provider batch job that served the phase, if any
+	Artifacts    []string         // Files the phase saved, e.g. generated images
+	Experiment   string           // A/B experiment the phase took part in, if any
+	Variant      string           // Experiment variant the phase ran as: control or candidate
+	ReusedFrom   string           // Execution ID of the earlier run whose result the phase reused, if any
+	Warnings     []string         // Quality problems found in the output, such as echoing the input
+	SkipReason   string           // Why the phase was skipped by its when or unless condition, if it was
+	Sample       *workflow.Sample // Foreach items the phase ran on, if it ran on a sample
 }
 
 // ExecutionResult contains the aggregated results of executing a skill.
 type ExecutionResult struct {
 	SkillID      string
+	ExecutionID  string // Correlation ID of the run; names its checkpoint and log file
 	SkillName    string
 	Status       PhaseStatus
 	PhaseResults map[string]*PhaseResult
@@ -63,6 +77,81 @@ type ExecutorConfig struct {
 	MaxParallel   int           // Maximum number of phases to execute in parallel
 	Timeout       time.Duration // Overall timeout for skill execution
 	MemoryContent string        // Memory content to inject into prompts (from MEMORY.md/CLAUDE.md)
+
+	// ProviderSelector optionally chooses a provider per phase (provider pinning).
+	// When nil, every phase runs on the executor's provider.
+	ProviderSelector PhaseProviderSelector
+
+	// Experiments optionally routes a share of phase executions to the
+	// candidate models of A/B experiments and tags their results with the
+	// variant. When nil, no phase takes part in an experiment.
+	Experiments PhaseExperimentRouter
+
+	// Transcriber transcribes the audio of input_audio phases. When nil,
+	// such phases fail.
+	Transcriber ports.TranscriptionPort
+
+	// ImageGenerator generates the images of phases with image output. When
+	// nil, such phases fail.
+	ImageGenerator ports.ImageGenerationPort
+
+	// ArtifactDir is where generated files are saved (default: a
+	// skillrunner-artifacts directory under os.TempDir()).
+	ArtifactDir string
+
+	// BatchAPI submits the phases of each DAG batch as one asynchronous job
+	// when the provider implements ports.BatchProviderPort, trading latency for
+	// a lower price. Use BatchJobTimeout as the Timeout for such runs.
+	BatchAPI bool
+
+	// BatchPollInterval is how often batch jobs are polled (default 30s).
+	BatchPollInterval time.Duration
+
+	// Warmup warms the providers and models of the next batch while a batch
+	// runs, such as loading a local model or connecting to a cloud API, when
+	// the providers implement ports.Warmer.
+	Warmup bool
+
+	// ConcurrencyGroups limits how many phases of each concurrency group run
+	// at once. Share one among the executors of runs that may overlap. When
+	// nil, concurrency groups are unlimited.
+	ConcurrencyGroups *ConcurrencyGroups
+
+	// Skills looks up the skills that skill phases run. When nil, such
+	// phases fail.
+	Skills SkillResolver
+
+	// InputFiles holds the template variables of the run's input files,
+	// keyed by the names in skill.FileVariables, such as those of
+	// ingest.Variables. When nil, the variables are empty.
+	InputFiles map[string]string
+
+	// SampleRate runs foreach phases on a seeded, reproducible sample of
+	// their items: the fraction in (0, 1] of workflow.ParseSampleRate,
+	// selected with SampleSeed. Their results record the items sampled. When
+	// 0, every item runs.
+	SampleRate float64
+	SampleSeed uint64
+
+	// ContextWindows checks each phase request against the context window of
+	// its model before it is sent, failing the phases whose requests do not
+	// fit. When nil, requests are not checked.
+	ContextWindows ContextWindowChecker
+
+	// Fallbacks moves phases whose streams or completions stall to their
+	// routing profile's fallback provider and model. When nil, stalled
+	// phases are sent to the same provider again.
+	Fallbacks PhaseFallbackRouter
+
+	// Profiles resolves the custom routing profiles of phases to the
+	// provider and model they select. When nil, phases with a custom
+	// profile fail.
+	Profiles PhaseProfileRouter
+
+	// Latency records the latency and outcome of each request phases send,
+	// so that fastest routing selection learns from runs. When nil, nothing
+	// is recorded.
+	Latency LatencyRecorder
 }
 
 // DefaultExecutorConfig returns the default executor configuration.
@@ -71,6 +160,7 @@ func DefaultExecutorConfig() ExecutorConfig {
 		MaxParallel:   4,
 		Timeout:       5 * time.Minute,
 		MemoryContent: "",
+		Warmup:        true,
 	}
 }
 
@@ -95,15 +185,42 @@ func NewExecutor(provider ports.ProviderPort, config ExecutorConfig) Executor {
 		config.Timeout = DefaultExecutorConfig().Timeout
 	}
 
+	phaseExecutor := newPhaseExecutor(provider, config.MemoryContent)
+	phaseExecutor.selector = config.ProviderSelector
+	phaseExecutor.experiments = config.Experiments
+	phaseExecutor.media = newMediaBackends(provider, config)
+	phaseExecutor.groups = config.ConcurrencyGroups
+	phaseExecutor.sampling = newItemSampling(config)
+	phaseExecutor.windows = config.ContextWindows
+	phaseExecutor.fallbacks = config.Fallbacks
+	phaseExecutor.profiles = config.Profiles
+	phaseExecutor.latency = config.Latency
+
 	return &executor{
 		provider:      provider,
 		config:        config,
-		phaseExecutor: newPhaseExecutor(provider, config.MemoryContent),
+		phaseExecutor: phaseExecutor,
 	}
 }
 
 // Execute runs all phases of a skill in DAG order, executing parallel batches concurrently.
 func (e *executor) Execute(ctx context.Context, s *skill.Skill, input string) (*ExecutionResult, error) {
+	executionID := uuid.New().String()
+	ctx, endLog := logWorkflow(ctx, s, executionID)
+	ctx = auditRun(ctx, s, executionID)
+	ctx = moderateRun(ctx, s)
+	ctx, end := traceWorkflow(ctx, s)
+	result, err := e.execute(ctx, s, input)
+	if result != nil && result.ExecutionID == "" {
+		result.ExecutionID = executionID
+	}
+	end(result, err)
+	endLog(result, err)
+	return result, err
+}
+
+// execute implements Execute.
+func (e *executor) execute(ctx context.Context, s *skill.Skill, input string) (*ExecutionResult, error) {
 	if s == nil {
 		return nil, errors.NewError(errors.CodeValidation, "skill is required", nil)
 	}
@@ -156,12 +273,14 @@ func (e *executor) Execute(ctx context.Context, s *skill.Skill, input string) (*
 	}
 
 	// Track outputs from previous phases for context
-	phaseOutputs := make(map[string]string)
-	phaseOutputs["_input"] = input
-
-	// Execute batches sequentially, phases within each batch in parallel
-	for _, batch := range batches {
-		if err := e.executeBatch(ctx, dag, batch, result, phaseOutputs); err != nil {
+	phaseOutputs := runVariables(input, e.config.InputFiles)
+
+	// Execute batches sequentially, phases within each batch in parallel,
+	// warming the models of the next batch meanwhile
+	warmer := newModelWarmer(e.provider, e.config)
+	for batchIndex, batch := range batches {
+		warmer.warmAhead(ctx, dag, batches, batchIndex)
+		if err := e.executeBatch(ctx, dag, batchIndex, batch, result, phaseOutputs); err != nil {
 			result.Status = PhaseStatusFailed
 			result.Error = err
 			result.EndTime = time.Now()
@@ -199,6 +318,15 @@ func (e *executor) Execute(ctx context.Context, s *skill.Skill, input string) (*
 		result.TotalCost += phaseResult.Cost
 	}
 
+	// Save Markdown tables of phases with extract_tables
+	if err := extractPhaseTables(phases, result, e.config.ArtifactDir); err != nil {
+		result.Status = PhaseStatusFailed
+		result.Error = err
+		result.EndTime = time.Now()
+		result.Duration = result.EndTime.Sub(result.StartTime)
+		return result, nil
+	}
+
 	result.Status = PhaseStatusCompleted
 	result.EndTime = time.Now()
 	result.Duration = result.EndTime.Sub(result.StartTime)
@@ -210,6 +338,7 @@ func (e *executor) Execute(ctx context.Context, s *skill.Skill, input string) (*
 func (e *executor) executeBatch(
 	ctx context.Context,
 	dag *workflow.DAG,
+	batchIndex int,
 	batch []string,
 	result *ExecutionResult,
 	phaseOutputs map[string]string,
@@ -218,6 +347,12 @@ func (e *executor) executeBatch(
 		return nil
 	}
 
+	if e.config.BatchAPI {
+		if runner := newBatchJobRunner(e.provider, e.phaseExecutor, e.config.BatchPollInterval); runner != nil {
+			return runBatchJob(ctx, runner, dag, batchIndex, batch, result, phaseOutputs, "", e.gatherDependencyOutputs)
+		}
+	}
+
 	// Create a semaphore for limiting parallelism
 	sem := make(chan struct{}, e.config.MaxParallel)
 
@@ -234,6 +369,7 @@ func (e *executor) executeBatch(
 		wg.Add(1)
 		go func(p *skill.Phase) {
 			defer wg.Done()
+			queuedAt := time.Now()
 
 			// Acquire semaphore
 			select {
@@ -260,12 +396,17 @@ func (e *executor) executeBatch(
 			mu.Unlock()
 
 			// Execute the phase
-			phaseResult := e.phaseExecutor.Execute(ctx, p, dependencyOutputs)
+			phaseCtx, endPhase := tracePhase(auditPhase(ctx, p.ID), p)
+			phaseResult := e.phaseExecutor.Execute(phaseCtx, p, dependencyOutputs)
+			endPhase(phaseResult)
+			logPhase(phaseCtx, phaseResult)
+			phaseResult.Batch = batchIndex
+			phaseResult.QueuedAt = queuedAt
 
 			// Store result
 			mu.Lock()
 			result.PhaseResults[p.ID] = phaseResult
-			if phaseResult.Status == PhaseStatusCompleted {
+			if phaseResult.passesOutput() {
 				phaseOutputs[p.ID] = phaseResult.Output
 			} else if phaseResult.Error != nil && firstErr == nil {
 				firstErr = phaseResult.Error
@@ -282,12 +423,10 @@ func (e *executor) executeBatch(
 // gatherDependencyOutputs collects outputs from all phases this phase depends on.
 func (e *executor) gatherDependencyOutputs(dag *workflow.DAG, phaseID string, phaseOutputs map[string]string) map[string]string {
 	deps := dag.GetDependencies(phaseID)
-	outputs := make(map[string]string, len(deps)+1)
+	outputs := make(map[string]string, len(deps)+len(skill.FileVariables)+1)
 
 	// Always include the original input
-	if input, ok := phaseOutputs["_input"]; ok {
-		outputs["_input"] = input
-	}
+	copyRunVariables(outputs, phaseOutputs)
 
 	// Add outputs from dependencies
 	for _, depID := range deps {
@@ -527,13 +527,28 @@ func TestExecutor_Execute_MaxParallelLimit(t *testing.T) {
 	}
 }
 
-func TestPhaseExecutor_BuildPrompt(t *testing.T) {
+func TestPhaseExecutor_RecordsPrompt(t *testing.T) {
 	pe := newPhaseExecutor(newMockProvider(), "")
+	phase, err := skill.NewPhase("summarize", "Summarize", "Summarize: {{._input}}")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result := pe.Execute(context.Background(), phase, map[string]string{"_input": "the report"})
+	if result.Status != PhaseStatusCompleted {
+		t.Fatalf("status = %s, error = %v", result.Status, result.Error)
+	}
+	if result.Prompt != "Summarize: the report" {
+		t.Errorf("Prompt = %q", result.Prompt)
+	}
+}
 
+func TestRenderPrompt(t *testing.T) {
 	tests := []struct {
 		name     string
 		template string
 		data     map[string]string
+		partials map[string]string
 		expected string
 		wantErr  bool
 	}{
@@ -565,7 +580,13 @@ func TestPhaseExecutor_BuildPrompt(t *testing.T) {
 			name:     "special keys excluded from phases",
 			template: "Input: {{._input}}, Phases.input: {{.phases._input}}",
 			data:     map[string]string{"_input": "user input", "extract": "data"},
-			expected: "Input: user input, Phases.input: <no value>",
+			wantErr:  true,
+		},
+		{
+			name:     "input alias",
+			template: "Input: {{.input}}",
+			data:     map[string]string{"_input": "user input"},
+			expected: "Input: user input",
 		},
 		{
 			name:     "only special keys - no phases map",
@@ -577,19 +598,44 @@ func TestPhaseExecutor_BuildPrompt(t *testing.T) {
 			name:     "accessing nonexistent phase",
 			template: "{{.phases.nonexistent}}",
 			data:     map[string]string{"extract": "data"},
-			expected: "<no value>",
+			wantErr:  true,
 		},
 		{
 			name:     "empty data",
 			template: "Phases: {{.phases}}",
 			data:     map[string]string{},
-			expected: "Phases: <no value>",
+			wantErr:  true,
 		},
 		{
 			name:     "missing variable",
 			template: "Hello {{.missing}}",
 			data:     map[string]string{},
-			expected: "Hello <no value>",
+			wantErr:  true,
+		},
+		{
+			name:     "missing variable with get",
+			template: "Hello {{get \"missing\"}}!",
+			data:     map[string]string{},
+			expected: "Hello !",
+		},
+		{
+			name:     "sprig functions",
+			template: "{{._input | trim | upper}} {{list \"a\" \"b\" | join \",\"}}",
+			data:     map[string]string{"_input": "  go  "},
+			expected: "GO a,b",
+		},
+		{
+			name:     "partials",
+			template: "{{template \"rules\" .}}\n{{include \"rules\" . | indent 2}}",
+			data:     map[string]string{"_input": "Go"},
+			partials: map[string]string{"rules": "Follow {{._input}} style."},
+			expected: "Follow Go style.\n  Follow Go style.",
+		},
+		{
+			name:     "environment functions removed",
+			template: "{{env \"HOME\"}}",
+			data:     map[string]string{},
+			wantErr:  true,
 		},
 		{
 			name:     "invalid template",
@@ -601,7 +647,7 @@ func TestPhaseExecutor_BuildPrompt(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result, err := pe.buildPrompt(tt.template, tt.data)
+			result, err := renderPrompt(tt.template, tt.data, tt.partials)
 
 			if tt.wantErr {
 				if err == nil {
@@ -631,7 +677,7 @@ func TestPhaseExecutor_SelectModel(t *testing.T) {
 		{skill.RoutingProfileCheap, "llama3.2:3b"},
 		{skill.RoutingProfileBalanced, "llama3:8b"},
 		{skill.RoutingProfilePremium, "qwen2.5:14b"},
-		{"unknown", "llama3:8b"},
+		{"reasoning", ""}, // Custom profiles are resolved through the routing configuration
 		{"", "llama3:8b"},
 	}
 
@@ -770,7 +816,7 @@ func TestPhaseExecutor_BuildMessages_WithMemory(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			pe := newPhaseExecutor(newMockProvider(), tt.memoryContent)
-			messages := pe.buildMessages(tt.prompt, tt.dependencyOutput)
+			messages := pe.buildMessages(pe.memoryContent, tt.prompt, tt.dependencyOutput)
 
 			// Check if memory message is present
 			hasMemoryMsg := false
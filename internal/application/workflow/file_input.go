@@ -0,0 +1,28 @@
+package workflow
+
+import "github.com/jbctechsolutions/skillrunner/internal/domain/skill"
+
+// runVariables returns the template variables every phase of a run sees:
+// the input as _input, and the variables of the run's input files, empty
+// unless set in files.
+func runVariables(input string, files map[string]string) map[string]string {
+	vars := make(map[string]string, len(skill.FileVariables)+1)
+	for _, name := range skill.FileVariables {
+		vars[name] = files[name]
+	}
+	vars["_input"] = input
+	return vars
+}
+
+// copyRunVariables copies the run variables of phaseOutputs, from
+// runVariables, into a phase's dependency outputs.
+func copyRunVariables(outputs, phaseOutputs map[string]string) {
+	if input, ok := phaseOutputs["_input"]; ok {
+		outputs["_input"] = input
+	}
+	for _, name := range skill.FileVariables {
+		if value, ok := phaseOutputs[name]; ok {
+			outputs[name] = value
+		}
+	}
+}
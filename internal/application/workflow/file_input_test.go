@@ -0,0 +1,65 @@
+package workflow
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/jbctechsolutions/skillrunner/internal/application/ports"
+	"github.com/jbctechsolutions/skillrunner/internal/domain/skill"
+)
+
+func TestExecutor_InputFiles(t *testing.T) {
+	var mu sync.Mutex
+	var requests []ports.CompletionRequest
+	provider := newMockProvider()
+	provider.completeFunc = func(_ context.Context, req ports.CompletionRequest) (*ports.CompletionResponse, error) {
+		mu.Lock()
+		requests = append(requests, req)
+		mu.Unlock()
+		return &ports.CompletionResponse{Content: "reviewed " + req.Messages[len(req.Messages)-1].Content}, nil
+	}
+
+	review := createTestPhase(t, "review", "Review", "Review {{._item}}", nil)
+	review.WithForEach(&skill.ForEachConfig{Items: "{{._file_chunks}}"})
+	summary := createTestPhase(t, "summary", "Summary", "Summarize {{._file_paths | replace \"\\n\" \", \"}}: {{.review}}", []string{"review"})
+	s := createTestSkill(t, []skill.Phase{review, summary})
+
+	config := DefaultExecutorConfig()
+	config.InputFiles = map[string]string{
+		skill.FilesVariable:      "a.go\n```go\npackage a\n```\n\nb.go\n```go\npackage b\n```",
+		skill.FilePathsVariable:  "a.go\nb.go",
+		skill.FileChunksVariable: `["a.go chunk", "b.go chunk"]`,
+	}
+	result, err := NewExecutor(provider, config).Execute(context.Background(), s, "check errors")
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if result.Status != PhaseStatusCompleted {
+		t.Fatalf("status = %s, want completed", result.Status)
+	}
+	if want := "reviewed Review a.go chunk\n\nreviewed Review b.go chunk"; result.PhaseResults["review"].Output != want {
+		t.Errorf("review output = %q, want %q", result.PhaseResults["review"].Output, want)
+	}
+	if !strings.HasPrefix(result.PhaseResults["summary"].Prompt, "Summarize a.go, b.go: ") {
+		t.Errorf("summary prompt = %q, want the file paths", result.PhaseResults["summary"].Prompt)
+	}
+	for _, req := range requests {
+		for _, m := range req.Messages {
+			if strings.Contains(m.Content, "Previous Phase (_file") {
+				t.Errorf("context message passes on the file variables: %q", m.Content)
+			}
+		}
+	}
+
+	// Without input files, the variables are empty
+	empty := createTestPhase(t, "paths", "Paths", "Files: [{{._files}}]", nil)
+	result, err = NewExecutor(provider, DefaultExecutorConfig()).Execute(context.Background(), createTestSkill(t, []skill.Phase{empty}), "x")
+	if err != nil || result.Status != PhaseStatusCompleted {
+		t.Fatalf("Execute() without files = %v, %v; want completed", result.Status, err)
+	}
+	if got := result.PhaseResults["paths"].Prompt; got != "Files: []" {
+		t.Errorf("prompt without files = %q, want empty files", got)
+	}
+}
@@ -0,0 +1,220 @@
+package workflow
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jbctechsolutions/skillrunner/internal/domain/skill"
+	"github.com/jbctechsolutions/skillrunner/internal/domain/workflow"
+)
+
+// phaseRunner runs a phase with its dependency outputs.
+type phaseRunner func(ctx context.Context, phase *skill.Phase, dependencyOutputs map[string]string) *PhaseResult
+
+// itemSampling selects a seeded sample of the items of foreach phases; the
+// zero value selects every item.
+type itemSampling struct {
+	rate float64
+	seed uint64
+}
+
+// newItemSampling returns the sampling of an executor configuration.
+func newItemSampling(config ExecutorConfig) itemSampling {
+	return itemSampling{rate: config.SampleRate, seed: config.SampleSeed}
+}
+
+// sample returns the items of total to run, or nil to run them all.
+func (s itemSampling) sample(total int) (*workflow.Sample, error) {
+	if s.rate == 0 {
+		return nil, nil
+	}
+	return workflow.NewSample(total, s.rate, s.seed)
+}
+
+// matches reports whether a phase's earlier result, which ran on sample,
+// ran on the items this sampling selects; phases without foreach always do.
+func (s itemSampling) matches(phase *skill.Phase, sample *workflow.Sample) bool {
+	if phase.ForEach == nil {
+		return true
+	}
+	if sample == nil {
+		return s.rate == 0
+	}
+	return s.rate == sample.Rate && s.seed == sample.Seed
+}
+
+// runForEach runs a foreach phase: it renders and splits the phase's items,
+// runs the phase once per item with run, up to the configured number at
+// once, and aggregates the item results into the phase's result. Each run
+// sees the item as _item and its index as _index. The first failing item
+// cancels the items still running and fails the phase. With sampling, only
+// the sampled items run, with their indices among all the items, and the
+// result records the sample.
+func runForEach(ctx context.Context, phase *skill.Phase, dependencyOutputs map[string]string, render renderFunc, sampling itemSampling, run phaseRunner) *PhaseResult {
+	start := time.Now()
+	cfg := phase.ForEach
+
+	list, err := render(cfg.Items)
+	if err != nil {
+		return failedPhaseResult(phase, start, fmt.Errorf("failed to render foreach items: %w", err))
+	}
+	items, err := splitItems(list, cfg.Split)
+	if err != nil {
+		return failedPhaseResult(phase, start, err)
+	}
+	sample, err := sampling.sample(len(items))
+	if err != nil {
+		return failedPhaseResult(phase, start, err)
+	}
+
+	// The items run as the phase without its loop and conditions, which
+	// apply to the phase as a whole
+	itemPhase := *phase
+	itemPhase.ForEach = nil
+	itemPhase.When, itemPhase.Unless = "", ""
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	results := make([]*PhaseResult, len(items))
+	sem := make(chan struct{}, cfg.Workers())
+	var wg sync.WaitGroup
+	for i, item := range items {
+		if sample != nil && !sample.Contains(i) {
+			continue
+		}
+
+		// Items start in order, and none after one failed
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			results[i] = failedPhaseResult(&itemPhase, time.Now(), ctx.Err())
+			continue
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			outputs := make(map[string]string, len(dependencyOutputs)+2)
+			for k, v := range dependencyOutputs {
+				outputs[k] = v
+			}
+			outputs["_item"] = item
+			outputs["_index"] = strconv.Itoa(i)
+
+			results[i] = run(ctx, &itemPhase, outputs)
+			if results[i].Status != PhaseStatusCompleted {
+				cancel()
+			}
+		}()
+	}
+	wg.Wait()
+
+	result := aggregateItems(phase, start, results)
+	result.Sample = sample
+	return result
+}
+
+// splitItems splits the rendered items of a foreach phase: the elements of
+// a JSON array, strings as their value and others as JSON, or the
+// non-empty lines.
+func splitItems(list, split string) ([]string, error) {
+	content := extractJSON(list)
+	var elements []json.RawMessage
+	err := json.Unmarshal([]byte(content), &elements)
+
+	switch {
+	case split == skill.ForEachSplitJSON && err != nil:
+		return nil, fmt.Errorf("foreach items are not a JSON array: %w", err)
+	case split == skill.ForEachSplitLines || err != nil:
+		var items []string
+		for _, line := range strings.Split(list, "\n") {
+			if line = strings.TrimSpace(line); line != "" {
+				items = append(items, line)
+			}
+		}
+		return items, nil
+	}
+
+	items := make([]string, len(elements))
+	for i, e := range elements {
+		var s string
+		if json.Unmarshal(e, &s) == nil {
+			items[i] = s
+		} else {
+			items[i] = string(e)
+		}
+	}
+	return items, nil
+}
+
+// aggregateItems combines the results of a foreach phase's items into the
+// phase's result, summing their usage and aggregating their outputs. Items
+// left out of a sample have no result.
+func aggregateItems(phase *skill.Phase, start time.Time, results []*PhaseResult) *PhaseResult {
+	result := &PhaseResult{
+		PhaseID:   phase.ID,
+		PhaseName: phase.Name,
+		Status:    PhaseStatusCompleted,
+		StartTime: start,
+	}
+
+	outputs := make([]string, 0, len(results))
+	for i, r := range results {
+		if r == nil {
+			continue
+		}
+		result.InputTokens += r.InputTokens
+		result.OutputTokens += r.OutputTokens
+		result.Cost += r.Cost
+		result.Artifacts = append(result.Artifacts, r.Artifacts...)
+		if result.ModelUsed == "" {
+			result.ModelUsed, result.ProviderUsed, result.Prompt = r.ModelUsed, r.ProviderUsed, r.Prompt
+		}
+		for _, w := range r.Warnings {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("item %d: %s", i, w))
+		}
+		// Report the item that failed rather than those it canceled
+		if r.Status != PhaseStatusCompleted && (result.Error == nil || errors.Is(result.Error, context.Canceled) && !errors.Is(r.Error, context.Canceled)) {
+			result.Status = PhaseStatusFailed
+			result.Error = fmt.Errorf("foreach item %d: %w", i, r.Error)
+		}
+		outputs = append(outputs, r.Output)
+	}
+
+	if result.Status == PhaseStatusCompleted {
+		result.Output = joinItemOutputs(phase.ForEach, outputs)
+	}
+	result.EndTime = time.Now()
+	result.Duration = result.EndTime.Sub(result.StartTime)
+	return result
+}
+
+// joinItemOutputs aggregates the outputs of a foreach phase's items:
+// concatenated, or as a JSON array whose elements are the outputs that are
+// JSON documents and the others as strings.
+func joinItemOutputs(cfg *skill.ForEachConfig, outputs []string) string {
+	if cfg.Aggregate != skill.ForEachAggregateJSON {
+		return strings.Join(outputs, cfg.Joiner())
+	}
+	elements := make([]json.RawMessage, len(outputs))
+	for i, o := range outputs {
+		if trimmed := strings.TrimSpace(o); trimmed != "" && json.Valid([]byte(trimmed)) {
+			elements[i] = json.RawMessage(trimmed)
+		} else {
+			elements[i], _ = json.Marshal(o)
+		}
+	}
+	data, _ := json.Marshal(elements)
+	return string(data)
+}
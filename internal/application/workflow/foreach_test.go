@@ -0,0 +1,160 @@
+package workflow
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jbctechsolutions/skillrunner/internal/application/ports"
+	"github.com/jbctechsolutions/skillrunner/internal/domain/skill"
+	"github.com/jbctechsolutions/skillrunner/internal/domain/workflow"
+)
+
+func TestSplitItems(t *testing.T) {
+	tests := []struct {
+		name, list, split string
+		want              []string
+	}{
+		{"json strings", `["a.go", "b.go"]`, "", []string{"a.go", "b.go"}},
+		{"fenced json", "```json\n[\"a.go\"]\n```", skill.ForEachSplitJSON, []string{"a.go"}},
+		{"json objects", `[{"path":"a.go"}, 3]`, "", []string{`{"path":"a.go"}`, "3"}},
+		{"lines", "a.go\n\n  b.go  \n", "", []string{"a.go", "b.go"}},
+		{"json as lines", "[\"a.go\",\n\"b.go\"]", skill.ForEachSplitLines, []string{`["a.go",`, `"b.go"]`}},
+		{"empty", "  \n", "", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := splitItems(tt.list, tt.split)
+			if err != nil {
+				t.Fatalf("splitItems() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("splitItems() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := splitItems("a.go\nb.go", skill.ForEachSplitJSON); err == nil {
+		t.Error("splitItems() of lines as json succeeded")
+	}
+}
+
+func TestJoinItemOutputs(t *testing.T) {
+	outputs := []string{`{"ok":true}`, "plain text"}
+	if got := joinItemOutputs(&skill.ForEachConfig{}, outputs); got != "{\"ok\":true}\n\nplain text" {
+		t.Errorf("concat = %q", got)
+	}
+	if got := joinItemOutputs(&skill.ForEachConfig{Aggregate: skill.ForEachAggregateJSON}, outputs); got != `[{"ok":true},"plain text"]` {
+		t.Errorf("json = %q", got)
+	}
+}
+
+func TestExecutor_ForEach(t *testing.T) {
+	var running, peak atomic.Int32
+	provider := newMockProvider()
+	provider.completeFunc = func(_ context.Context, req ports.CompletionRequest) (*ports.CompletionResponse, error) {
+		prompt := req.Messages[len(req.Messages)-1].Content
+		if strings.HasPrefix(prompt, "List") {
+			return &ports.CompletionResponse{Content: `["a.go", "b.go", "c.go"]`, InputTokens: 1}, nil
+		}
+		n := running.Add(1)
+		defer running.Add(-1)
+		for p := peak.Load(); n > p && !peak.CompareAndSwap(p, n); p = peak.Load() {
+		}
+		time.Sleep(10 * time.Millisecond)
+		return &ports.CompletionResponse{Content: "reviewed " + prompt, InputTokens: 1, OutputTokens: 2, ModelUsed: req.ModelID}, nil
+	}
+
+	list := createTestPhase(t, "list", "List", "List files of {{._input}}", nil)
+	review := createTestPhase(t, "review", "Review", "{{._index}}:{{._item}}", []string{"list"})
+	review.WithForEach(&skill.ForEachConfig{Items: "{{.list}}", Parallel: 2})
+	summary := createTestPhase(t, "summary", "Summary", "Summarize {{.review}}", []string{"review"})
+	s := createTestSkill(t, []skill.Phase{list, review, summary})
+
+	result, err := NewExecutor(provider, DefaultExecutorConfig()).Execute(context.Background(), s, "repo")
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	pr := result.PhaseResults["review"]
+	if pr.Status != PhaseStatusCompleted {
+		t.Fatalf("review status = %s (%v), want completed", pr.Status, pr.Error)
+	}
+	if want := "reviewed 0:a.go\n\nreviewed 1:b.go\n\nreviewed 2:c.go"; pr.Output != want {
+		t.Errorf("review output = %q, want %q", pr.Output, want)
+	}
+	if pr.InputTokens != 3 || pr.OutputTokens != 6 {
+		t.Errorf("review tokens = %d/%d, want 3/6", pr.InputTokens, pr.OutputTokens)
+	}
+	if got := peak.Load(); got > 2 {
+		t.Errorf("%d items ran at once, want at most 2", got)
+	}
+	if !strings.Contains(result.FinalOutput, "reviewed 2:c.go") {
+		t.Errorf("final output = %q, want the aggregated reviews", result.FinalOutput)
+	}
+}
+
+func TestExecutor_ForEach_ItemFails(t *testing.T) {
+	provider := newMockProvider()
+	provider.completeFunc = func(_ context.Context, req ports.CompletionRequest) (*ports.CompletionResponse, error) {
+		if strings.Contains(req.Messages[len(req.Messages)-1].Content, "bad") {
+			return nil, errors.New("HTTP 500")
+		}
+		return &ports.CompletionResponse{Content: "ok"}, nil
+	}
+
+	review := createTestPhase(t, "review", "Review", "Review {{._item}}", nil)
+	review.WithForEach(&skill.ForEachConfig{Items: "{{._input}}"})
+	s := createTestSkill(t, []skill.Phase{review})
+
+	result, _ := NewExecutor(provider, DefaultExecutorConfig()).Execute(context.Background(), s, "good\nbad\nlater")
+	pr := result.PhaseResults["review"]
+	if pr.Status != PhaseStatusFailed || pr.Error == nil || !strings.Contains(pr.Error.Error(), "foreach item 1") {
+		t.Errorf("review status, error = %s, %v; want item 1 failed", pr.Status, pr.Error)
+	}
+	if got := provider.callCount.Load(); got != 2 {
+		t.Errorf("provider called %d times, want the items after the failure canceled", got)
+	}
+}
+
+func TestExecutor_ForEach_Sample(t *testing.T) {
+	provider := newMockProvider()
+	provider.completeFunc = func(_ context.Context, req ports.CompletionRequest) (*ports.CompletionResponse, error) {
+		return &ports.CompletionResponse{Content: req.Messages[len(req.Messages)-1].Content, InputTokens: 1}, nil
+	}
+
+	review := createTestPhase(t, "review", "Review", "{{._index}}", nil)
+	review.WithForEach(&skill.ForEachConfig{Items: "{{._input}}"})
+	s := createTestSkill(t, []skill.Phase{review})
+
+	config := DefaultExecutorConfig()
+	config.SampleRate = 0.3
+	config.SampleSeed = 7
+	result, err := NewExecutor(provider, config).Execute(context.Background(), s, "a\nb\nc\nd\ne\nf\ng\nh\ni\nj")
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	want, _ := workflow.NewSample(10, 0.3, 7)
+	pr := result.PhaseResults["review"]
+	if !reflect.DeepEqual(pr.Sample, want) {
+		t.Fatalf("review sample = %+v, want %+v", pr.Sample, want)
+	}
+	if got := provider.callCount.Load(); got != 3 {
+		t.Errorf("provider called %d times, want the 3 sampled items", got)
+	}
+	var outputs []string
+	for _, i := range want.Selected {
+		outputs = append(outputs, strconv.Itoa(i))
+	}
+	if got := strings.Join(outputs, "\n\n"); pr.Output != got {
+		t.Errorf("review output = %q, want the sampled items' indices %q", pr.Output, got)
+	}
+	if pr.InputTokens != 3 {
+		t.Errorf("review input tokens = %d, want 3", pr.InputTokens)
+	}
+}
@@ -0,0 +1,167 @@
+package workflow
+
+import (
+	"cmp"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/jbctechsolutions/skillrunner/internal/domain/skill"
+)
+
+// codeSpans matches fenced code blocks and inline code, whose identifiers
+// and commands a glossary leaves alone.
+var codeSpans = regexp.MustCompile("(?s)```.*?(?:```|\\z)|`[^`\n]+`")
+
+// glossaryViolation is one spelling found in an output in place of a
+// glossary term.
+type glossaryViolation struct {
+	found, term string
+	count       int
+}
+
+// setOutput records the output of a completion phase, enforcing the phase's
+// glossary.
+func (r *PhaseResult) setOutput(phase *skill.Phase, content string) {
+	output, warnings := enforceGlossary(phase.Glossary, content)
+	r.Output = output
+	r.Warnings = append(r.Warnings, warnings...)
+}
+
+// enforceGlossary checks output against a phase's glossary. It returns the
+// output, with the violations replaced by their terms if the glossary
+// corrects, and a warning for each violation found.
+func enforceGlossary(g *skill.Glossary, output string) (string, []string) {
+	if g == nil {
+		return output, nil
+	}
+
+	var violations []*glossaryViolation
+	for _, t := range g.Terms {
+		pattern := termPattern(t)
+		if pattern == nil {
+			continue
+		}
+		code := codeSpans.FindAllStringIndex(output, -1)
+
+		var b strings.Builder
+		last := 0
+		for _, m := range pattern.FindAllStringIndex(output, -1) {
+			found := output[m[0]:m[1]]
+			if !isWholeWord(output, m[0], m[1]) || inSpans(code, m[0]) || acceptsSpelling(t.Term, found) {
+				continue
+			}
+
+			i := slices.IndexFunc(violations, func(v *glossaryViolation) bool { return v.found == found && v.term == t.Term })
+			if i < 0 {
+				violations = append(violations, &glossaryViolation{found: found, term: t.Term})
+				i = len(violations) - 1
+			}
+			violations[i].count++
+
+			b.WriteString(output[last:m[0]])
+			b.WriteString(matchCapital(t.Term, found))
+			last = m[1]
+		}
+		if g.Corrects() && last > 0 {
+			b.WriteString(output[last:])
+			output = b.String()
+		}
+	}
+
+	warnings := make([]string, len(violations))
+	for i, v := range violations {
+		times := ""
+		if v.count > 1 {
+			times = fmt.Sprintf(" (%d times)", v.count)
+		}
+		if g.Corrects() {
+			warnings[i] = fmt.Sprintf("terminology: corrected %q to %q%s", v.found, v.term, times)
+		} else {
+			warnings[i] = fmt.Sprintf("terminology: %q should be %q%s", v.found, v.term, times)
+		}
+	}
+	return output, warnings
+}
+
+// termPattern matches the term and its variants regardless of case and
+// spacing, longest first. Terms without capitals and variants only match
+// their variants.
+func termPattern(t skill.GlossaryTerm) *regexp.Regexp {
+	spellings := slices.Clone(t.Avoid)
+	if strings.ToLower(t.Term) != t.Term {
+		spellings = append(spellings, t.Term)
+	}
+	if len(spellings) == 0 {
+		return nil
+	}
+	slices.SortFunc(spellings, func(a, b string) int { return cmp.Compare(len(b), len(a)) })
+
+	alternatives := make([]string, len(spellings))
+	for i, s := range spellings {
+		words := strings.Fields(s)
+		for j, w := range words {
+			words[j] = regexp.QuoteMeta(w)
+		}
+		alternatives[i] = strings.Join(words, `\s+`)
+	}
+	return regexp.MustCompile(`(?i)(?:` + strings.Join(alternatives, "|") + `)`)
+}
+
+// acceptsSpelling reports whether found is the term itself: letter for
+// letter, or for terms without capitals, in any case.
+func acceptsSpelling(term, found string) bool {
+	found = strings.Join(strings.Fields(found), " ")
+	if strings.ToLower(term) == term {
+		return strings.EqualFold(found, term)
+	}
+	return found == term
+}
+
+// matchCapital returns term capitalized like found, so that a variant
+// starting a sentence is replaced by a capitalized term.
+func matchCapital(term, found string) string {
+	f, _ := utf8.DecodeRuneInString(found)
+	t, size := utf8.DecodeRuneInString(term)
+	if unicode.IsUpper(f) && unicode.IsLower(t) {
+		return string(unicode.ToUpper(t)) + term[size:]
+	}
+	return term
+}
+
+// isWholeWord reports whether text[start:end] stands as a word of its own,
+// rather than as part of a longer word, path, address or domain name.
+func isWholeWord(text string, start, end int) bool {
+	if before, _ := utf8.DecodeLastRuneInString(text[:start]); start > 0 && (isWordRune(before) || strings.ContainsRune("./@", before)) {
+		return false
+	}
+	if end == len(text) {
+		return true
+	}
+	after, size := utf8.DecodeRuneInString(text[end:])
+	if isWordRune(after) || strings.ContainsRune("/@", after) {
+		return false
+	}
+	if after == '.' {
+		next, _ := utf8.DecodeRuneInString(text[end+size:])
+		return !isWordRune(next)
+	}
+	return true
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' || r == '-'
+}
+
+// inSpans reports whether offset falls in one of spans.
+func inSpans(spans [][]int, offset int) bool {
+	for _, s := range spans {
+		if offset >= s[0] && offset < s[1] {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,102 @@
+package workflow
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/jbctechsolutions/skillrunner/internal/application/ports"
+	"github.com/jbctechsolutions/skillrunner/internal/domain/skill"
+)
+
+func TestEnforceGlossary(t *testing.T) {
+	terms := []skill.GlossaryTerm{
+		{Term: "GitHub", Avoid: []string{"Git Hub"}},
+		{Term: "sign in", Avoid: []string{"log in", "login"}},
+	}
+
+	tests := []struct {
+		name     string
+		output   string
+		want     string
+		warnings []string
+	}{
+		{
+			name:   "product name",
+			output: "Push to Github, then open github in a browser.",
+			want:   "Push to GitHub, then open GitHub in a browser.",
+			warnings: []string{
+				`terminology: corrected "Github" to "GitHub"`,
+				`terminology: corrected "github" to "GitHub"`,
+			},
+		},
+		{
+			name:     "phrasing keeps sentence case",
+			output:   "Log in to the console. After you login,\nlog  in again.",
+			want:     "Sign in to the console. After you sign in,\nsign in again.",
+			warnings: []string{`terminology: corrected "Log in" to "sign in"`, `terminology: corrected "login" to "sign in"`, `terminology: corrected "log  in" to "sign in"`},
+		},
+		{
+			name:     "repeated violation",
+			output:   "Git Hub and Git Hub",
+			want:     "GitHub and GitHub",
+			warnings: []string{`terminology: corrected "Git Hub" to "GitHub" (2 times)`},
+		},
+		{
+			name:   "code, paths and domains are left alone",
+			output: "Run `github login` or see:\n```\ngit clone github.com/org/repo\n```\nat https://github.com/org and in .github/workflows, as login-shell does.",
+			want:   "Run `github login` or see:\n```\ngit clone github.com/org/repo\n```\nat https://github.com/org and in .github/workflows, as login-shell does.",
+		},
+		{
+			name:   "term itself",
+			output: "Sign in with GitHub.",
+			want:   "Sign in with GitHub.",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, warnings := enforceGlossary(&skill.Glossary{Terms: terms, Action: skill.GlossaryActionCorrect}, tt.output)
+			if got != tt.want {
+				t.Errorf("output = %q, want %q", got, tt.want)
+			}
+			if !slices.Equal(warnings, tt.warnings) {
+				t.Errorf("warnings = %q, want %q", warnings, tt.warnings)
+			}
+		})
+	}
+
+	t.Run("report", func(t *testing.T) {
+		output := "Log in with Github."
+		got, warnings := enforceGlossary(&skill.Glossary{Terms: terms}, output)
+		want := []string{`terminology: "Github" should be "GitHub"`, `terminology: "Log in" should be "sign in"`}
+		if got != output || !slices.Equal(warnings, want) {
+			t.Errorf("enforceGlossary() = %q, %q; want the output unchanged and %q", got, warnings, want)
+		}
+	})
+}
+
+func TestExecutor_Glossary(t *testing.T) {
+	provider := newMockProvider()
+	provider.completeFunc = func(_ context.Context, req ports.CompletionRequest) (*ports.CompletionResponse, error) {
+		return &ports.CompletionResponse{Content: "Log in to Github to continue.", ModelUsed: req.ModelID}, nil
+	}
+
+	p := createTestPhase(t, "docs", "Docs", "Document {{._input}}", nil)
+	p.WithGlossary(&skill.Glossary{
+		Terms:  []skill.GlossaryTerm{{Term: "GitHub"}, {Term: "sign in", Avoid: []string{"log in"}}},
+		Action: skill.GlossaryActionCorrect,
+	})
+	s := createTestSkill(t, []skill.Phase{p})
+
+	result, err := NewExecutor(provider, DefaultExecutorConfig()).Execute(context.Background(), s, "the login flow")
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	pr := result.PhaseResults["docs"]
+	if pr.Output != "Sign in to GitHub to continue." || result.FinalOutput != pr.Output {
+		t.Errorf("output = %q, final output = %q", pr.Output, result.FinalOutput)
+	}
+	if len(pr.Warnings) != 2 {
+		t.Errorf("warnings = %q, want one per corrected term", pr.Warnings)
+	}
+}
@@ -0,0 +1,109 @@
+package workflow
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/jbctechsolutions/skillrunner/internal/application/ports"
+	"github.com/jbctechsolutions/skillrunner/internal/domain/errors"
+	"github.com/jbctechsolutions/skillrunner/internal/domain/skill"
+)
+
+// defaultArtifactDirName is the directory under os.TempDir() that artifacts
+// are saved in when ExecutorConfig.ArtifactDir is not set.
+const defaultArtifactDirName = "skillrunner-artifacts"
+
+// generateImagePhase runs a phase with image output: it renders the prompt,
+// generates the images, saves them to artifactDir and returns their paths,
+// one per line, as the phase output for downstream phases.
+func generateImagePhase(ctx context.Context, generator ports.ImageGenerationPort, artifactDir string, phase *skill.Phase, render func(string) (string, error)) *PhaseResult {
+	startTime := time.Now()
+
+	if generator == nil {
+		return failedPhaseResult(phase, startTime, errors.NewError(errors.CodeConfiguration,
+			fmt.Sprintf("phase %s generates images but no image generation provider is configured", phase.ID), nil))
+	}
+
+	prompt, err := render(phase.PromptTemplate)
+	if err != nil {
+		return failedPhaseResult(phase, startTime, err)
+	}
+
+	resp, err := generator.GenerateImages(ctx, ports.ImageGenerationRequest{
+		Prompt:         strings.TrimSpace(prompt),
+		NegativePrompt: phase.Image.NegativePrompt,
+		Size:           phase.Image.Size,
+		Count:          phase.Image.Count,
+	})
+	if err != nil {
+		return failedPhaseResult(phase, startTime, fmt.Errorf("failed to generate images: %w", err))
+	}
+
+	paths, err := saveArtifacts(artifactDir, phase.ID, resp.Images)
+	if err != nil {
+		return failedPhaseResult(phase, startTime, err)
+	}
+
+	endTime := time.Now()
+	return &PhaseResult{
+		PhaseID:      phase.ID,
+		PhaseName:    phase.Name,
+		Status:       PhaseStatusCompleted,
+		Output:       strings.Join(paths, "\n"),
+		Prompt:       strings.TrimSpace(prompt),
+		Artifacts:    paths,
+		ModelUsed:    resp.ModelUsed,
+		ProviderUsed: generator.Info().Name,
+		StartTime:    startTime,
+		EndTime:      endTime,
+		Duration:     endTime.Sub(startTime),
+	}
+}
+
+// resolveArtifactDir returns the absolute path of the artifact directory,
+// defaulting to a directory under os.TempDir(), and creates it.
+func resolveArtifactDir(dir string) (string, error) {
+	if dir == "" {
+		dir = filepath.Join(os.TempDir(), defaultArtifactDirName)
+	}
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve artifact directory: %w", err)
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", fmt.Errorf("failed to create artifact directory: %w", err)
+	}
+	return dir, nil
+}
+
+// saveArtifacts writes images to dir as <phaseID>.<ext>, or <phaseID>-<n>.<ext>
+// when there are several, and returns their absolute paths.
+func saveArtifacts(dir, phaseID string, images []ports.GeneratedImage) ([]string, error) {
+	dir, err := resolveArtifactDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	paths := make([]string, 0, len(images))
+	for i, img := range images {
+		ext := img.Format
+		if ext == "" {
+			ext = "png"
+		}
+		name := phaseID + "." + ext
+		if len(images) > 1 {
+			name = fmt.Sprintf("%s-%d.%s", phaseID, i+1, ext)
+		}
+
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, img.Data, 0o644); err != nil {
+			return nil, fmt.Errorf("failed to save artifact: %w", err)
+		}
+		paths = append(paths, path)
+	}
+	return paths, nil
+}
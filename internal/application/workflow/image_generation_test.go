@@ -0,0 +1,110 @@
+package workflow
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jbctechsolutions/skillrunner/internal/application/ports"
+	"github.com/jbctechsolutions/skillrunner/internal/domain/skill"
+)
+
+// mockImageGenerator implements ports.ImageGenerationPort for testing.
+type mockImageGenerator struct {
+	requests []ports.ImageGenerationRequest
+}
+
+func (m *mockImageGenerator) Info() ports.ProviderInfo {
+	return ports.ProviderInfo{Name: "mock-images"}
+}
+
+func (m *mockImageGenerator) GenerateImages(_ context.Context, req ports.ImageGenerationRequest) (*ports.ImageGenerationResponse, error) {
+	m.requests = append(m.requests, req)
+	resp := &ports.ImageGenerationResponse{ModelUsed: "mock-diffusion"}
+	for range max(req.Count, 1) {
+		resp.Images = append(resp.Images, ports.GeneratedImage{Data: []byte(req.Prompt), Format: "png"})
+	}
+	return resp, nil
+}
+
+// createImageSkill returns a skill that describes an image, renders it and
+// writes alt text referencing the rendered files.
+func createImageSkill(t *testing.T, count int) *skill.Skill {
+	t.Helper()
+	describe := createTestPhase(t, "describe", "Describe", "Describe: {{._input}}", nil)
+	render := createTestPhase(t, "render", "Render", "{{.describe}}", []string{"describe"})
+	render.WithOutputFormat(skill.OutputFormatImage).WithImageOptions(skill.ImageOptions{Size: "512x512", Count: count, NegativePrompt: "text"})
+	alt := createTestPhase(t, "alt", "Alt Text", "Alt text for {{.render}}", []string{"render"})
+	return createTestSkill(t, []skill.Phase{describe, render, alt})
+}
+
+func TestExecutor_ImagePhase(t *testing.T) {
+	provider := newMockProvider()
+	generator := &mockImageGenerator{}
+	config := DefaultExecutorConfig()
+	config.ImageGenerator = generator
+	config.ArtifactDir = t.TempDir()
+	exec := NewExecutor(provider, config)
+
+	result, err := exec.Execute(context.Background(), createImageSkill(t, 0), "a lighthouse")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Status != PhaseStatusCompleted {
+		t.Fatalf("expected status Completed, got %s", result.Status)
+	}
+
+	if len(generator.requests) != 1 {
+		t.Fatalf("expected 1 generation, got %d", len(generator.requests))
+	}
+	req := generator.requests[0]
+	if req.Prompt != "Mock response for: Describe: a lighthouse" || req.Size != "512x512" || req.NegativePrompt != "text" {
+		t.Errorf("generation request = %+v", req)
+	}
+
+	pr := result.PhaseResults["render"]
+	want := filepath.Join(config.ArtifactDir, "render.png")
+	if pr.Output != want || len(pr.Artifacts) != 1 || pr.ProviderUsed != "mock-images" {
+		t.Errorf("render result = %+v, want output %q", pr, want)
+	}
+	if data, err := os.ReadFile(want); err != nil || string(data) != req.Prompt {
+		t.Errorf("saved image = %q, %v", data, err)
+	}
+	if result.FinalOutput != "Mock response for: Alt text for "+want {
+		t.Errorf("FinalOutput = %q", result.FinalOutput)
+	}
+}
+
+func TestExecutor_ImagePhase_MultipleImages(t *testing.T) {
+	config := DefaultExecutorConfig()
+	config.ImageGenerator = &mockImageGenerator{}
+	config.ArtifactDir = t.TempDir()
+	exec := NewExecutor(newMockProvider(), config)
+
+	result, err := exec.Execute(context.Background(), createImageSkill(t, 3), "a lighthouse")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	paths := strings.Split(result.PhaseResults["render"].Output, "\n")
+	if len(paths) != 3 || filepath.Base(paths[2]) != "render-3.png" {
+		t.Errorf("output paths = %v", paths)
+	}
+	for _, path := range paths {
+		if _, err := os.Stat(path); err != nil {
+			t.Errorf("artifact %s not saved: %v", path, err)
+		}
+	}
+}
+
+func TestExecutor_ImagePhase_NoGenerator(t *testing.T) {
+	exec := NewExecutor(newMockProvider(), DefaultExecutorConfig())
+
+	result, _ := exec.Execute(context.Background(), createImageSkill(t, 0), "a lighthouse")
+	pr := result.PhaseResults["render"]
+	if pr == nil || pr.Status != PhaseStatusFailed || !strings.Contains(pr.Error.Error(), "no image generation provider") {
+		t.Errorf("expected render to fail without a generator, got %+v", pr)
+	}
+}
@@ -0,0 +1,209 @@
+package workflow
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+
+	"github.com/jbctechsolutions/skillrunner/internal/application/ports"
+	"github.com/jbctechsolutions/skillrunner/internal/domain/skill"
+)
+
+// DefaultJSONRetries is the number of corrective retries for a json phase
+// whose output does not parse or does not satisfy its schema.
+const DefaultJSONRetries = 2
+
+// ErrInvalidJSONOutput is returned when a json phase exhausts its retries.
+var ErrInvalidJSONOutput = errors.New("phase output is not valid JSON")
+
+// completeFunc performs a single completion attempt.
+type completeFunc func(ctx context.Context, req ports.CompletionRequest) (*ports.CompletionResponse, error)
+
+// phaseResponseFormat returns the provider-native response format for a phase,
+// or nil for text phases.
+func phaseResponseFormat(phase *skill.Phase) *ports.ResponseFormat {
+	if !phase.WantsJSON() {
+		return nil
+	}
+	if len(phase.OutputSchema) > 0 {
+		return &ports.ResponseFormat{
+			Type:   ports.ResponseFormatJSONSchema,
+			Name:   phase.ID,
+			Schema: phase.OutputSchema,
+		}
+	}
+	return &ports.ResponseFormat{Type: ports.ResponseFormatJSON}
+}
+
+// completeJSON runs a completion for a phase. For json phases the output is
+// validated and, when invalid, the model is asked to correct it up to
+// DefaultJSONRetries times. Token usage is summed across attempts and the
+// returned content is the bare JSON document.
+func completeJSON(ctx context.Context, phase *skill.Phase, req ports.CompletionRequest, complete completeFunc) (*ports.CompletionResponse, error) {
+	if !phase.WantsJSON() {
+		return complete(ctx, req)
+	}
+
+	var inputTokens, outputTokens int
+	var lastErr error
+
+	for attempt := 0; attempt <= DefaultJSONRetries; attempt++ {
+		resp, err := complete(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+		inputTokens += resp.InputTokens
+		outputTokens += resp.OutputTokens
+
+		content := extractJSON(resp.Content)
+		if lastErr = validateJSONOutput([]byte(content), phase.OutputSchema); lastErr == nil {
+			resp.Content = content
+			resp.InputTokens = inputTokens
+			resp.OutputTokens = outputTokens
+			return resp, nil
+		}
+
+		// Show the model its own output and what was wrong with it
+		req.Messages = append(slices.Clip(req.Messages),
+			ports.Message{Role: "assistant", Content: resp.Content},
+			ports.Message{Role: "user", Content: fmt.Sprintf(
+				"Your previous response was rejected: %v. Respond with only the corrected JSON document, without prose or code fences.", lastErr)},
+		)
+	}
+
+	return nil, fmt.Errorf("%w after %d attempts: %v", ErrInvalidJSONOutput, DefaultJSONRetries+1, lastErr)
+}
+
+// extractJSON strips surrounding whitespace and a markdown code fence, which
+// models often add even in JSON mode.
+func extractJSON(content string) string {
+	content = strings.TrimSpace(content)
+	if !strings.HasPrefix(content, "```") {
+		return content
+	}
+	content = strings.TrimPrefix(content, "```")
+	if nl := strings.IndexByte(content, '\n'); nl >= 0 {
+		content = content[nl+1:] // Drop the language tag line
+	}
+	return strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(content), "```"))
+}
+
+// validateJSONOutput checks that data is a JSON document and, if a schema is
+// given, that it satisfies the schema.
+func validateJSONOutput(data []byte, schema json.RawMessage) error {
+	var value any
+	if err := json.Unmarshal(data, &value); err != nil {
+		return fmt.Errorf("invalid JSON: %w", err)
+	}
+	if len(schema) == 0 {
+		return nil
+	}
+
+	var s map[string]any
+	if err := json.Unmarshal(schema, &s); err != nil {
+		return fmt.Errorf("invalid output schema: %w", err)
+	}
+	return validateSchema(value, s, "$")
+}
+
+// validateSchema checks value against the commonly used subset of JSON Schema:
+// type, enum, required, properties, additionalProperties (false), and items.
+func validateSchema(value any, schema map[string]any, path string) error {
+	if t, ok := schema["type"]; ok && !matchesType(value, t) {
+		return fmt.Errorf("%s: expected %v, got %s", path, t, jsonTypeOf(value))
+	}
+
+	if enum, ok := schema["enum"].([]any); ok {
+		if !slices.ContainsFunc(enum, func(e any) bool { return fmt.Sprint(e) == fmt.Sprint(value) }) {
+			return fmt.Errorf("%s: value %v is not one of %v", path, value, enum)
+		}
+	}
+
+	switch v := value.(type) {
+	case map[string]any:
+		if required, ok := schema["required"].([]any); ok {
+			for _, r := range required {
+				if name, _ := r.(string); name != "" {
+					if _, present := v[name]; !present {
+						return fmt.Errorf("%s: missing required property %q", path, name)
+					}
+				}
+			}
+		}
+
+		props, _ := schema["properties"].(map[string]any)
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		slices.Sort(keys)
+
+		for _, k := range keys {
+			propSchema, known := props[k].(map[string]any)
+			if !known {
+				if extra, ok := schema["additionalProperties"].(bool); ok && !extra {
+					return fmt.Errorf("%s: unexpected property %q", path, k)
+				}
+				continue
+			}
+			if err := validateSchema(v[k], propSchema, path+"."+k); err != nil {
+				return err
+			}
+		}
+
+	case []any:
+		if items, ok := schema["items"].(map[string]any); ok {
+			for i, item := range v {
+				if err := validateSchema(item, items, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// matchesType reports whether value has the schema type t, which may be a
+// single type name or a list of names.
+func matchesType(value any, t any) bool {
+	switch t := t.(type) {
+	case string:
+		actual := jsonTypeOf(value)
+		if t == "number" && actual == "integer" {
+			return true
+		}
+		return t == actual
+	case []any:
+		return slices.ContainsFunc(t, func(name any) bool { return matchesType(value, name) })
+	default:
+		return true // Unknown type keyword shape; don't reject output over it
+	}
+}
+
+// jsonTypeOf returns the JSON Schema type name of a decoded JSON value.
+func jsonTypeOf(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		if v == math.Trunc(v) {
+			return "integer"
+		}
+		return "number"
+	case string:
+		return "string"
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	default:
+		return fmt.Sprintf("%T", value)
+	}
+}
@@ -0,0 +1,132 @@
+package workflow
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/jbctechsolutions/skillrunner/internal/application/ports"
+	"github.com/jbctechsolutions/skillrunner/internal/domain/skill"
+)
+
+const testOutputSchema = `{
+	"type": "object",
+	"required": ["title", "score"],
+	"additionalProperties": false,
+	"properties": {
+		"title": {"type": "string"},
+		"score": {"type": "integer"},
+		"tags": {"type": "array", "items": {"type": "string"}},
+		"level": {"enum": ["low", "high"]}
+	}
+}`
+
+func TestValidateJSONOutput(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		wantErr string
+	}{
+		{"valid", `{"title":"a","score":3,"tags":["x"],"level":"low"}`, ""},
+		{"not json", `title: a`, "invalid JSON"},
+		{"missing required", `{"title":"a"}`, `missing required property "score"`},
+		{"wrong type", `{"title":"a","score":1.5}`, "$.score: expected integer"},
+		{"bad item", `{"title":"a","score":1,"tags":[1]}`, "$.tags[0]: expected string"},
+		{"not in enum", `{"title":"a","score":1,"level":"mid"}`, "is not one of"},
+		{"extra property", `{"title":"a","score":1,"extra":true}`, `unexpected property "extra"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateJSONOutput([]byte(tt.data), json.RawMessage(testOutputSchema))
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestExtractJSON(t *testing.T) {
+	tests := map[string]string{
+		`{"a":1}`:                       `{"a":1}`,
+		"  {\"a\":1}\n":                 `{"a":1}`,
+		"```json\n{\"a\":1}\n```":       `{"a":1}`,
+		"```\n[1, 2]\n```\n":            `[1, 2]`,
+		"Here you go: ```json {}```":    "Here you go: ```json {}```",
+		"```json\n{\"b\":\"```\"}\n```": "{\"b\":\"```\"}",
+	}
+
+	for in, want := range tests {
+		if got := extractJSON(in); got != want {
+			t.Errorf("extractJSON(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestPhaseExecutor_JSONOutputRetry(t *testing.T) {
+	provider := newMockProvider()
+	responses := []string{
+		"Sure! Here is the result.",
+		"```json\n{\"title\":\"Report\",\"score\":7}\n```",
+	}
+	attempt := 0
+	provider.completeFunc = func(_ context.Context, _ ports.CompletionRequest) (*ports.CompletionResponse, error) {
+		content := responses[min(attempt, len(responses)-1)]
+		attempt++
+		return &ports.CompletionResponse{Content: content, InputTokens: 10, OutputTokens: 5}, nil
+	}
+
+	phase := createTestPhase(t, "extract", "Extract", "Extract {{._input}}", nil)
+	phase.WithOutputSchema(json.RawMessage(testOutputSchema))
+
+	result := newPhaseExecutor(provider, "").Execute(context.Background(), &phase, map[string]string{"_input": "doc"})
+
+	if result.Status != PhaseStatusCompleted {
+		t.Fatalf("expected completed phase, got %s: %v", result.Status, result.Error)
+	}
+	if result.Output != `{"title":"Report","score":7}` {
+		t.Errorf("unexpected output: %q", result.Output)
+	}
+	if result.InputTokens != 20 || result.OutputTokens != 10 {
+		t.Errorf("expected tokens summed across attempts, got %d/%d", result.InputTokens, result.OutputTokens)
+	}
+
+	if len(provider.completeCalls) != 2 {
+		t.Fatalf("expected 2 provider calls, got %d", len(provider.completeCalls))
+	}
+	first := provider.completeCalls[0]
+	if first.ResponseFormat == nil || first.ResponseFormat.Type != ports.ResponseFormatJSONSchema {
+		t.Errorf("expected json_schema response format, got %+v", first.ResponseFormat)
+	}
+	retry := provider.completeCalls[1].Messages
+	if last := retry[len(retry)-1]; last.Role != "user" || !strings.Contains(last.Content, "invalid JSON") {
+		t.Errorf("expected corrective user message, got %+v", last)
+	}
+}
+
+func TestPhaseExecutor_JSONOutputExhaustsRetries(t *testing.T) {
+	provider := newMockProvider()
+	provider.completeFunc = func(_ context.Context, _ ports.CompletionRequest) (*ports.CompletionResponse, error) {
+		return &ports.CompletionResponse{Content: "not json"}, nil
+	}
+
+	phase := createTestPhase(t, "extract", "Extract", "Extract {{._input}}", nil)
+	phase.WithOutputFormat(skill.OutputFormatJSON)
+
+	result := newPhaseExecutor(provider, "").Execute(context.Background(), &phase, map[string]string{"_input": "doc"})
+
+	if result.Status != PhaseStatusFailed || !errors.Is(result.Error, ErrInvalidJSONOutput) {
+		t.Errorf("expected ErrInvalidJSONOutput failure, got %s: %v", result.Status, result.Error)
+	}
+	if got := len(provider.completeCalls); got != DefaultJSONRetries+1 {
+		t.Errorf("expected %d attempts, got %d", DefaultJSONRetries+1, got)
+	}
+}
@@ -0,0 +1,37 @@
+package workflow
+
+import (
+	"context"
+	"time"
+
+	"github.com/jbctechsolutions/skillrunner/internal/application/ports"
+	"github.com/jbctechsolutions/skillrunner/internal/application/provider"
+)
+
+// LatencyRecorder records the latency and outcome of the requests phases
+// send to their provider and model, such as provider.Router, whose fastest
+// selection prefers the providers that have been responding quickly.
+type LatencyRecorder interface {
+	RecordLatency(selection *provider.ModelSelection, latency time.Duration, err error)
+}
+
+// timed returns the completions of complete, recording the latency of each
+// request they send with recorder. Requests cut short by their context say
+// nothing about the provider and are not recorded. When recorder is nil,
+// complete is returned as it is.
+func timed(recorder LatencyRecorder, complete func(ports.ProviderPort) completeFunc) func(ports.ProviderPort) completeFunc {
+	if recorder == nil {
+		return complete
+	}
+	return func(p ports.ProviderPort) completeFunc {
+		send := complete(p)
+		return func(ctx context.Context, req ports.CompletionRequest) (*ports.CompletionResponse, error) {
+			start := time.Now()
+			resp, err := send(ctx, req)
+			if ctx.Err() == nil {
+				recorder.RecordLatency(&provider.ModelSelection{ProviderName: p.Info().Name, ModelID: req.ModelID}, time.Since(start), err)
+			}
+			return resp, err
+		}
+	}
+}
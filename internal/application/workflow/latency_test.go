@@ -0,0 +1,88 @@
+package workflow
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jbctechsolutions/skillrunner/internal/application/ports"
+	"github.com/jbctechsolutions/skillrunner/internal/application/provider"
+	"github.com/jbctechsolutions/skillrunner/internal/domain/skill"
+)
+
+// latencyRecord is a request recorded by stubLatency.
+type latencyRecord struct {
+	provider, model string
+	err             error
+}
+
+// stubLatency records the requests whose latency is recorded.
+type stubLatency struct {
+	mu      sync.Mutex
+	records []latencyRecord
+}
+
+func (s *stubLatency) RecordLatency(selection *provider.ModelSelection, _ time.Duration, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.records = append(s.records, latencyRecord{selection.ProviderName, selection.ModelID, err})
+}
+
+func TestExecutor_RecordsLatency(t *testing.T) {
+	failing := errors.New("HTTP 500")
+	prov := newMockProvider()
+	prov.completeFunc = func(_ context.Context, req ports.CompletionRequest) (*ports.CompletionResponse, error) {
+		if strings.HasPrefix(req.Messages[len(req.Messages)-1].Content, "Review") {
+			return nil, failing
+		}
+		return &ports.CompletionResponse{Content: "draft", ModelUsed: req.ModelID}, nil
+	}
+
+	recorder := &stubLatency{}
+	config := DefaultExecutorConfig()
+	config.Latency = recorder
+	draft := createTestPhase(t, "draft", "Draft", "Draft {{._input}}", nil)
+	review := createTestPhase(t, "review", "Review", "Review {{.draft}}", []string{"draft"})
+	s := createTestSkill(t, []skill.Phase{draft, review})
+
+	_, _ = NewExecutor(prov, config).Execute(context.Background(), s, "notes")
+	if len(recorder.records) != 2 {
+		t.Fatalf("recorded %d requests, want 2", len(recorder.records))
+	}
+	for i, want := range []error{nil, failing} {
+		got := recorder.records[i]
+		if got.provider != "mock" || got.model == "" || !errors.Is(got.err, want) {
+			t.Errorf("record %d = %+v, want mock with error %v", i, got, want)
+		}
+	}
+
+	streamed := &stubLatency{}
+	config.Latency = streamed
+	if _, err := NewStreamingExecutor(newMockStreamingProvider([]string{"ok"}), config).ExecuteWithStreaming(context.Background(), s, "notes", nil); err != nil {
+		t.Fatalf("ExecuteWithStreaming() error = %v", err)
+	}
+	if len(streamed.records) != 2 || streamed.records[0].err != nil {
+		t.Errorf("recorded %+v, want both streamed requests", streamed.records)
+	}
+}
+
+func TestTimed_SkipsCanceledRequests(t *testing.T) {
+	recorder := &stubLatency{}
+	complete := timed(recorder, func(ports.ProviderPort) completeFunc {
+		return func(ctx context.Context, _ ports.CompletionRequest) (*ports.CompletionResponse, error) {
+			return nil, ctx.Err()
+		}
+	})(newMockProvider())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := complete(ctx, ports.CompletionRequest{ModelID: "m"}); !errors.Is(err, context.Canceled) {
+		t.Fatalf("complete() error = %v, want context.Canceled", err)
+	}
+	if len(recorder.records) != 0 {
+		t.Errorf("recorded %+v, want canceled requests skipped", recorder.records)
+	}
+}
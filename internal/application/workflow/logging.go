@@ -0,0 +1,66 @@
+package workflow
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jbctechsolutions/skillrunner/internal/domain/skill"
+	"github.com/jbctechsolutions/skillrunner/internal/infrastructure/logging"
+)
+
+// logWorkflow opens the log of a skill run, in its own file when run logs
+// are enabled, and logs its start. The phases of the run log to it through
+// the returned context. The returned function logs how the run ended and
+// closes the log. A log file that cannot be opened leaves the run logging
+// to the global logger.
+func logWorkflow(ctx context.Context, s *skill.Skill, executionID string) (context.Context, func(*ExecutionResult, error)) {
+	if s == nil {
+		return ctx, func(*ExecutionResult, error) {}
+	}
+	logger, closer, err := logging.Default().ForRun(executionID)
+	if err != nil {
+		logger = logging.Default().With("execution_id", executionID)
+		logger.Warn("failed to open run log", "error", err)
+	}
+	ctx = logging.WithLogger(ctx, logger)
+	logging.LogWorkflowStart(ctx, logger, s.ID(), s.Name())
+
+	return ctx, func(result *ExecutionResult, err error) {
+		defer func() {
+			if closer != nil {
+				_ = closer.Close()
+			}
+		}()
+		if result == nil {
+			if err != nil {
+				logging.LogWorkflowFailed(ctx, logger, s.ID(), err, 0)
+			}
+			return
+		}
+		if err == nil {
+			err = result.Error
+		}
+		if err == nil && result.Status == PhaseStatusFailed {
+			err = errors.New("workflow failed")
+		}
+		if err != nil {
+			logging.LogWorkflowFailed(ctx, logger, s.ID(), err, result.Duration)
+			return
+		}
+		logging.LogWorkflowComplete(ctx, logger, s.ID(), result.Duration, result.TotalTokens)
+	}
+}
+
+// logPhase logs how a phase ended to the log of its run.
+func logPhase(ctx context.Context, result *PhaseResult) {
+	logger := logging.FromContext(ctx)
+	switch result.Status {
+	case PhaseStatusCompleted:
+		logging.LogPhaseComplete(ctx, logger, result.PhaseID, result.InputTokens, result.OutputTokens, result.Duration, result.CacheHit)
+		logger.DebugContext(ctx, "phase provider", "phase_id", result.PhaseID, "provider", result.ProviderUsed, "model", result.ModelUsed)
+	case PhaseStatusFailed:
+		if result.Error != nil {
+			logging.LogPhaseFailed(ctx, logger, result.PhaseID, result.Error, result.Duration)
+		}
+	}
+}
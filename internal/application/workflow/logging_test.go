@@ -0,0 +1,42 @@
+package workflow
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/jbctechsolutions/skillrunner/internal/domain/skill"
+	"github.com/jbctechsolutions/skillrunner/internal/infrastructure/logging"
+)
+
+func TestExecutor_Execute_WritesRunLog(t *testing.T) {
+	dir := t.TempDir()
+	previous := logging.Default()
+	logging.SetDefault(logging.New(logging.Config{Level: logging.LevelInfo, Format: logging.FormatText, RunLogDir: dir}))
+	t.Cleanup(func() { logging.SetDefault(previous) })
+
+	phases := []skill.Phase{
+		createTestPhase(t, "draft", "Draft", "Draft {{._input}}", nil),
+		createTestPhase(t, "review", "Review", "Review {{.draft}}", []string{"draft"}),
+	}
+	exec := NewExecutor(newMockProvider(), DefaultExecutorConfig())
+	result, err := exec.Execute(context.Background(), createTestSkill(t, phases), "input")
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if result.ExecutionID == "" {
+		t.Fatal("expected the run to have an execution ID")
+	}
+
+	data, err := os.ReadFile(logging.RunLogPath(dir, result.ExecutionID))
+	if err != nil {
+		t.Fatalf("expected a log file named after the execution ID: %v", err)
+	}
+	log := string(data)
+	for _, want := range []string{"workflow execution started", "phase_id=draft", "phase_id=review", "workflow execution completed", "execution_id=" + result.ExecutionID} {
+		if !strings.Contains(log, want) {
+			t.Errorf("run log should contain %q:\n%s", want, log)
+		}
+	}
+}
@@ -0,0 +1,556 @@
+package workflow
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/jbctechsolutions/skillrunner/internal/application/ports"
+	"github.com/jbctechsolutions/skillrunner/internal/application/provider"
+	"github.com/jbctechsolutions/skillrunner/internal/domain/skill"
+)
+
+// ErrWindowTooSmall is returned when a long_context window leaves no room
+// for input once the rest of the request, the carryover and the output are
+// accounted for.
+var ErrWindowTooSmall = errors.New("long_context window too small")
+
+const (
+	// windowCharsPerToken is the token estimate used to size windows.
+	windowCharsPerToken = 4
+
+	// windowFramingTokens is reserved in each window for the part headers
+	// and the summary instruction.
+	windowFramingTokens = 128
+
+	// minWindowInputTokens is the smallest slice of input a window may hold.
+	minWindowInputTokens = 64
+
+	// mapReduceParallelism is the number of map_reduce summaries requested
+	// at once.
+	mapReduceParallelism = 4
+)
+
+// windowSummaryPrompt asks for the rolling summary of the windows so far.
+const windowSummaryPrompt = "The input above is part %d of %d of a longer input that is read in parts. " +
+	"Do not respond to the request yet. Write a summary of at most %d words that keeps everything " +
+	"from the summary and the part above that will be needed to respond once the whole input has been read. " +
+	"Respond with only the summary."
+
+// chunkSummaryPrompt asks for the summary of one map_reduce chunk.
+const chunkSummaryPrompt = "The input above is part %d of %d of a longer input that is summarized in parts. " +
+	"Do not respond to the request yet. Write a summary of at most %d words that keeps everything " +
+	"from the part above that will be needed to respond once the summaries of all parts are combined. " +
+	"Respond with only the summary."
+
+// mergeSummaryPrompt asks for the merge of consecutive map_reduce summaries.
+const mergeSummaryPrompt = "The input above holds summaries of consecutive parts of a longer input. " +
+	"Do not respond to the request yet. Merge them into one summary of at most %d words that keeps everything " +
+	"that will be needed to respond once the whole input has been summarized. " +
+	"Respond with only the summary."
+
+// chunkPrompt asks for the response to one chunk of a chunked input.
+const chunkPrompt = "The input above is part %d of %d of a longer input that is processed in parts. " +
+	"Respond to the request for this part only; the responses to all parts will be merged."
+
+// defaultReducePrompt merges the chunk responses of a chunked phase that
+// sets no reduce_prompt.
+const defaultReducePrompt = "The input above holds the responses to consecutive parts of a longer input. " +
+	"Merge them into one response to the request for the whole input, without repeating anything. " +
+	"Respond with only the merged response."
+
+// reduceLimitPrompt bounds the merges of a chunked phase's intermediate
+// reduce levels.
+const reduceLimitPrompt = " Use at most %d words."
+
+// profileModelFunc returns the model a routing profile maps to, or "" to
+// keep the model of the request.
+type profileModelFunc func(profile string) string
+
+// profileModels maps routing profiles to models with selectModel while a
+// phase runs on the executor's own provider. Phases pinned to another
+// provider keep their model at every level, since the profiles do not map
+// to that provider's models.
+func profileModels(selectModel func(string) string, provider, defaultProvider ports.ProviderPort) profileModelFunc {
+	return func(profile string) string {
+		if profile == "" || provider != defaultProvider {
+			return ""
+		}
+		return selectModel(profile)
+	}
+}
+
+// windowed returns answer for phases without a long_context strategy.
+// Otherwise it returns a completion that splits oversized requests: as a
+// sliding window, where summarize produces the rolling summary of each
+// window but the last, or as a map-reduce, where summarize produces the
+// chunk summaries and their merges on the models models maps the level
+// profiles to. In both, answer responds to the request from the last
+// window or the merged summaries. A chunked phase instead runs its request
+// on each chunk with summarize and merges the responses with answer.
+func windowed(phase *skill.Phase, models profileModelFunc, summarize, answer completeFunc) completeFunc {
+	cfg := phase.LongContext
+	if cfg == nil {
+		return answer
+	}
+	return func(ctx context.Context, req ports.CompletionRequest) (*ports.CompletionResponse, error) {
+		switch cfg.Strategy {
+		case skill.LongContextMapReduce:
+			return mapReduce(ctx, cfg, req, models, summarize, answer)
+		case skill.LongContextChunked:
+			return chunked(ctx, phase.ID, cfg, req, models, summarize, answer)
+		}
+		return slidingWindow(ctx, cfg, req, summarize, answer)
+	}
+}
+
+// slidingWindow sends req as is when it fits cfg.WindowTokens. Otherwise it
+// splits the largest message of req into parts that fit a window alongside
+// the rest of the request, the carryover and the output. Each part but the
+// last is summarized together with the summary of the parts before it; the
+// last part is sent with that summary and the original request. Summaries
+// are capped at cfg.CarryoverTokens so every window stays in budget. Token
+// usage is summed across windows.
+func slidingWindow(ctx context.Context, cfg *skill.LongContextConfig, req ports.CompletionRequest, summarize, answer completeFunc) (*ports.CompletionResponse, error) {
+	if len(req.Messages) == 0 || estimateRequestTokens(req)+req.MaxTokens <= cfg.WindowTokens {
+		return answer(ctx, req)
+	}
+
+	doc := largestMessage(req.Messages)
+	content := req.Messages[doc].Content
+	overhead := estimateRequestTokens(withMessageContent(req, doc, "")) + windowFramingTokens
+	output := max(cfg.CarryoverTokens, req.MaxTokens)
+
+	budget := cfg.WindowTokens - overhead - cfg.CarryoverTokens - output
+	if budget < minWindowInputTokens {
+		return nil, fmt.Errorf("%w: %d tokens leave no room for input after %d for the rest of the request, %d for the carryover and %d for the output",
+			ErrWindowTooSmall, cfg.WindowTokens, overhead, cfg.CarryoverTokens, output)
+	}
+
+	parts := provider.SplitText(content, budget*windowCharsPerToken)
+	n := len(parts)
+
+	var (
+		summary                   string
+		inputTokens, outputTokens int
+	)
+	for i, part := range parts[:n-1] {
+		r := withMessageContent(req, doc, windowContent(summary, part, i+1, n))
+		r.Messages = append(r.Messages, ports.Message{
+			Role:    "user",
+			Content: fmt.Sprintf(windowSummaryPrompt, i+1, n, cfg.CarryoverTokens*3/4),
+		})
+		r.MaxTokens = cfg.CarryoverTokens
+		r.ResponseFormat = nil
+
+		resp, err := summarize(ctx, r)
+		if err != nil {
+			return nil, fmt.Errorf("window %d of %d: %w", i+1, n, err)
+		}
+		inputTokens += resp.InputTokens
+		outputTokens += resp.OutputTokens
+		summary = capCarryover(resp.Content, cfg.CarryoverTokens)
+	}
+
+	resp, err := answer(ctx, withMessageContent(req, doc, windowContent(summary, parts[n-1], n, n)))
+	if err != nil {
+		return nil, err
+	}
+	resp.InputTokens += inputTokens
+	resp.OutputTokens += outputTokens
+	return resp, nil
+}
+
+// partSummary is the summary of the consecutive parts first to last of a
+// map_reduce input.
+type partSummary struct {
+	first, last int
+	text        string
+}
+
+// mapReduce sends req as is when it fits cfg.WindowTokens. Otherwise it
+// splits the largest message of req into chunks that fit a window alongside
+// the rest of the request and a summary, and summarizes each chunk on the
+// map profile's model. While the summaries do not fit one request with the
+// output, runs of consecutive summaries are merged on the reduce profile's
+// model, forming a merge tree. The merged summaries are then sent in place
+// of the input with the original request. Summaries are capped at
+// cfg.CarryoverTokens, and token usage is summed across all requests.
+func mapReduce(ctx context.Context, cfg *skill.LongContextConfig, req ports.CompletionRequest, models profileModelFunc, summarize, answer completeFunc) (*ports.CompletionResponse, error) {
+	if len(req.Messages) == 0 || estimateRequestTokens(req)+req.MaxTokens <= cfg.WindowTokens {
+		return answer(ctx, req)
+	}
+
+	doc := largestMessage(req.Messages)
+	content := req.Messages[doc].Content
+	overhead := estimateRequestTokens(withMessageContent(req, doc, "")) + windowFramingTokens
+
+	budget := cfg.WindowTokens - overhead - cfg.CarryoverTokens
+	if answerBudget := cfg.WindowTokens - overhead - req.MaxTokens; min(budget, answerBudget) < minWindowInputTokens {
+		return nil, fmt.Errorf("%w: %d tokens leave no room for input after %d for the rest of the request, %d for a summary and %d for the output",
+			ErrWindowTooSmall, cfg.WindowTokens, overhead, cfg.CarryoverTokens, req.MaxTokens)
+	}
+
+	var usage ports.CompletionResponse
+
+	// Map: summarize each chunk
+	chunks := provider.SplitText(content, budget*windowCharsPerToken)
+	n := len(chunks)
+	mapModel := cmp.Or(models(cfg.MapProfile), req.ModelID)
+	reqs := make([]ports.CompletionRequest, n)
+	summaries := make([]partSummary, n)
+	for i, chunk := range chunks {
+		reqs[i] = summaryRequest(req, doc, fmt.Sprintf("[Part %d of %d]\n\n%s", i+1, n, chunk),
+			fmt.Sprintf(chunkSummaryPrompt, i+1, n, cfg.CarryoverTokens*3/4), cfg.CarryoverTokens, mapModel)
+		summaries[i] = partSummary{first: i + 1, last: i + 1}
+	}
+	texts, err := summarizeAll(ctx, reqs, summarize, cfg.CarryoverTokens, &usage, nil)
+	if err != nil {
+		return nil, fmt.Errorf("map: %w", err)
+	}
+	for i := range summaries {
+		summaries[i].text = texts[i]
+	}
+
+	// Reduce: merge runs of summaries until they fit the final request
+	reduceModel := cmp.Or(models(cfg.ReduceProfile), req.ModelID)
+	for level := 1; estimateTokens(summariesContent(summaries, n)) > cfg.WindowTokens-overhead-req.MaxTokens; level++ {
+		groups := groupSummaries(summaries, n, budget, summariesContent)
+		if len(groups) == len(summaries) {
+			return nil, fmt.Errorf("%w: %d tokens cannot hold two %d-token summaries to merge",
+				ErrWindowTooSmall, cfg.WindowTokens, cfg.CarryoverTokens)
+		}
+
+		var merges []ports.CompletionRequest
+		for _, group := range groups {
+			if len(group) > 1 {
+				merges = append(merges, summaryRequest(req, doc, summariesContent(group, n),
+					fmt.Sprintf(mergeSummaryPrompt, cfg.CarryoverTokens*3/4), cfg.CarryoverTokens, reduceModel))
+			}
+		}
+		texts, err := summarizeAll(ctx, merges, summarize, cfg.CarryoverTokens, &usage, nil)
+		if err != nil {
+			return nil, fmt.Errorf("reduce level %d: %w", level, err)
+		}
+
+		merged := make([]partSummary, 0, len(groups))
+		for _, group := range groups {
+			if len(group) == 1 {
+				merged = append(merged, group[0])
+				continue
+			}
+			merged = append(merged, partSummary{first: group[0].first, last: group[len(group)-1].last, text: texts[0]})
+			texts = texts[1:]
+		}
+		summaries = merged
+	}
+
+	resp, err := answer(ctx, withMessageContent(req, doc, summariesContent(summaries, n)))
+	if err != nil {
+		return nil, err
+	}
+	resp.InputTokens += usage.InputTokens
+	resp.OutputTokens += usage.OutputTokens
+	return resp, nil
+}
+
+// chunked sends req as is when it fits cfg.WindowTokens. Otherwise it
+// splits the largest message of req into chunks that fit a window alongside
+// the rest of the request and the output, and runs req on each chunk on the
+// map profile's model. The chunk responses are kept with the run's chunk
+// progress, if any, and chunks already responded to are not sent again.
+// While the responses do not fit one request, runs of consecutive responses
+// are merged, capped at cfg.CarryoverTokens. The responses are then merged
+// into the phase's output with the reduce prompt on the reduce profile's
+// model. Token usage is summed across all requests.
+func chunked(ctx context.Context, phaseID string, cfg *skill.LongContextConfig, req ports.CompletionRequest, models profileModelFunc, run, answer completeFunc) (*ports.CompletionResponse, error) {
+	if len(req.Messages) == 0 || estimateRequestTokens(req)+req.MaxTokens <= cfg.WindowTokens {
+		return answer(ctx, req)
+	}
+
+	doc := largestMessage(req.Messages)
+	content := req.Messages[doc].Content
+	overhead := estimateRequestTokens(withMessageContent(req, doc, "")) + windowFramingTokens
+	output := max(cfg.CarryoverTokens, req.MaxTokens)
+
+	budget := cfg.WindowTokens - overhead - output
+	if budget < minWindowInputTokens {
+		return nil, fmt.Errorf("%w: %d tokens leave no room for input after %d for the rest of the request and %d for the output",
+			ErrWindowTooSmall, cfg.WindowTokens, overhead, output)
+	}
+
+	var usage ports.CompletionResponse
+
+	// Map: run the request on each chunk not responded to yet
+	chunks := provider.SplitText(content, budget*windowCharsPerToken)
+	n := len(chunks)
+	progress := chunkProgressFrom(ctx)
+	mapModel := cmp.Or(models(cfg.MapProfile), req.ModelID)
+	results := make([]partSummary, n)
+	var (
+		pending []int
+		reqs    []ports.CompletionRequest
+	)
+	for i, chunk := range chunks {
+		results[i] = partSummary{first: i + 1, last: i + 1}
+		if progress != nil {
+			if text, ok := progress.chunkOutput(phaseID, i, n); ok {
+				results[i].text = text
+				continue
+			}
+		}
+		r := summaryRequest(req, doc, fmt.Sprintf("[Part %d of %d]\n\n%s", i+1, n, chunk),
+			fmt.Sprintf(chunkPrompt, i+1, n), output, mapModel)
+		pending = append(pending, i)
+		reqs = append(reqs, r)
+	}
+	var saved func(int, string)
+	if progress != nil {
+		saved = func(i int, text string) {
+			progress.saveChunkOutput(ctx, phaseID, pending[i], n, text)
+		}
+	}
+	texts, err := summarizeAll(ctx, reqs, run, output, &usage, saved)
+	if err != nil {
+		return nil, fmt.Errorf("map: %w", err)
+	}
+	for i, text := range texts {
+		results[pending[i]].text = text
+	}
+
+	// Reduce: merge runs of responses until they fit the final request
+	reducePrompt := cmp.Or(cfg.ReducePrompt, defaultReducePrompt)
+	reduceOverhead := overhead + estimateTokens(reducePrompt)
+	reduceModel := cmp.Or(models(cfg.ReduceProfile), req.ModelID)
+	for level := 1; estimateTokens(responsesContent(results, n)) > cfg.WindowTokens-reduceOverhead-req.MaxTokens; level++ {
+		groups := groupSummaries(results, n, cfg.WindowTokens-reduceOverhead-cfg.CarryoverTokens, responsesContent)
+		if len(groups) == len(results) {
+			return nil, fmt.Errorf("%w: %d tokens cannot hold two %d-token responses to merge",
+				ErrWindowTooSmall, cfg.WindowTokens, output)
+		}
+
+		var merges []ports.CompletionRequest
+		for _, group := range groups {
+			if len(group) > 1 {
+				merges = append(merges, summaryRequest(req, doc, responsesContent(group, n),
+					reducePrompt+fmt.Sprintf(reduceLimitPrompt, cfg.CarryoverTokens*3/4), cfg.CarryoverTokens, reduceModel))
+			}
+		}
+		texts, err := summarizeAll(ctx, merges, run, cfg.CarryoverTokens, &usage, nil)
+		if err != nil {
+			return nil, fmt.Errorf("reduce level %d: %w", level, err)
+		}
+
+		merged := make([]partSummary, 0, len(groups))
+		for _, group := range groups {
+			if len(group) == 1 {
+				merged = append(merged, group[0])
+				continue
+			}
+			merged = append(merged, partSummary{first: group[0].first, last: group[len(group)-1].last, text: texts[0]})
+			texts = texts[1:]
+		}
+		results = merged
+	}
+
+	r := withMessageContent(req, doc, responsesContent(results, n))
+	r.Messages = append(r.Messages, ports.Message{Role: "user", Content: reducePrompt})
+	r.ModelID = reduceModel
+	resp, err := answer(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+	resp.InputTokens += usage.InputTokens
+	resp.OutputTokens += usage.OutputTokens
+	return resp, nil
+}
+
+// summaryRequest returns a copy of req that asks, with prompt, for a
+// summary of content on modelID.
+func summaryRequest(req ports.CompletionRequest, doc int, content, prompt string, maxTokens int, modelID string) ports.CompletionRequest {
+	r := withMessageContent(req, doc, content)
+	r.Messages = append(r.Messages, ports.Message{Role: "user", Content: prompt})
+	r.ModelID = modelID
+	r.MaxTokens = maxTokens
+	r.ResponseFormat = nil
+	return r
+}
+
+// summarizeAll sends reqs with at most mapReduceParallelism in flight and
+// returns their responses in order, capped at maxTokens. Token usage is
+// added to usage, and done, if set, is called with each response as it
+// arrives. The first error cancels the remaining requests.
+func summarizeAll(ctx context.Context, reqs []ports.CompletionRequest, summarize completeFunc, maxTokens int, usage *ports.CompletionResponse, done func(i int, text string)) ([]string, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		firstErr error
+	)
+	texts := make([]string, len(reqs))
+	semaphore := make(chan struct{}, mapReduceParallelism)
+	for i, req := range reqs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			semaphore <- struct{}{}
+			defer func() { <-semaphore }()
+
+			resp, err := summarize(ctx, req)
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				if firstErr == nil {
+					firstErr = fmt.Errorf("summary %d of %d: %w", i+1, len(reqs), err)
+					cancel()
+				}
+				return
+			}
+			usage.InputTokens += resp.InputTokens
+			usage.OutputTokens += resp.OutputTokens
+			texts[i] = capCarryover(resp.Content, maxTokens)
+			if done != nil {
+				done(i, texts[i])
+			}
+		}()
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	return texts, nil
+}
+
+// groupSummaries splits summaries into runs of consecutive summaries whose
+// content, framed by frame, fits budget tokens. A summary that fits with no
+// other forms a run of its own.
+func groupSummaries(summaries []partSummary, n, budget int, frame func([]partSummary, int) string) [][]partSummary {
+	var groups [][]partSummary
+	start := 0
+	for i := 1; i <= len(summaries); i++ {
+		if i == len(summaries) || estimateTokens(frame(summaries[start:i+1], n)) > budget {
+			groups = append(groups, summaries[start:i])
+			start = i
+		}
+	}
+	return groups
+}
+
+// summariesContent frames summaries with the parts of the n-part input
+// they cover.
+func summariesContent(summaries []partSummary, n int) string {
+	var b strings.Builder
+	for i, s := range summaries {
+		if i > 0 {
+			b.WriteString("\n\n")
+		}
+		if s.first == s.last {
+			fmt.Fprintf(&b, "[Summary of part %d of %d]\n\n%s", s.first, n, s.text)
+		} else {
+			fmt.Fprintf(&b, "[Summary of parts %d-%d of %d]\n\n%s", s.first, s.last, n, s.text)
+		}
+	}
+	return b.String()
+}
+
+// responsesContent frames the chunk responses of a chunked phase with the
+// parts of the n-part input they respond to.
+func responsesContent(responses []partSummary, n int) string {
+	var b strings.Builder
+	for i, r := range responses {
+		if i > 0 {
+			b.WriteString("\n\n")
+		}
+		if r.first == r.last {
+			fmt.Fprintf(&b, "[Response to part %d of %d]\n\n%s", r.first, n, r.text)
+		} else {
+			fmt.Fprintf(&b, "[Responses to parts %d-%d of %d]\n\n%s", r.first, r.last, n, r.text)
+		}
+	}
+	return b.String()
+}
+
+// chunkProgress keeps the responses to the chunks of chunked phases, so a
+// resumed run does not send them again.
+type chunkProgress interface {
+	// chunkOutput returns the saved response to chunk i of n of a phase.
+	chunkOutput(phaseID string, i, n int) (string, bool)
+	// saveChunkOutput saves the response to chunk i of n of a phase.
+	saveChunkOutput(ctx context.Context, phaseID string, i, n int, output string)
+}
+
+type chunkProgressKey struct{}
+
+// withChunkProgress returns a context whose chunked phases keep their
+// chunk responses with progress.
+func withChunkProgress(ctx context.Context, progress chunkProgress) context.Context {
+	return context.WithValue(ctx, chunkProgressKey{}, progress)
+}
+
+// chunkProgressFrom returns the chunk progress of ctx, or nil.
+func chunkProgressFrom(ctx context.Context) chunkProgress {
+	progress, _ := ctx.Value(chunkProgressKey{}).(chunkProgress)
+	return progress
+}
+
+// windowContent frames a part of the input with the summary of the parts
+// before it.
+func windowContent(summary, part string, i, n int) string {
+	if summary == "" {
+		return fmt.Sprintf("[Part %d of %d]\n\n%s", i, n, part)
+	}
+	return fmt.Sprintf("[Summary of parts 1-%d]\n\n%s\n\n[Part %d of %d]\n\n%s", i-1, summary, i, n, part)
+}
+
+// capCarryover truncates summary to about maxTokens at a paragraph, line or
+// word boundary, in case the model ignored its output limit.
+func capCarryover(summary string, maxTokens int) string {
+	if estimateTokens(summary) <= maxTokens {
+		return summary
+	}
+	if parts := provider.SplitText(summary, maxTokens*windowCharsPerToken); len(parts) > 0 {
+		return parts[0]
+	}
+	return ""
+}
+
+// largestMessage returns the index of the longest message.
+func largestMessage(messages []ports.Message) int {
+	largest := 0
+	for i, msg := range messages {
+		if len(msg.Content) > len(messages[largest].Content) {
+			largest = i
+		}
+	}
+	return largest
+}
+
+// withMessageContent returns a copy of req with the content of message i
+// replaced.
+func withMessageContent(req ports.CompletionRequest, i int, content string) ports.CompletionRequest {
+	req.Messages = slices.Clone(req.Messages)
+	req.Messages[i].Content = content
+	return req
+}
+
+// estimateRequestTokens estimates the input tokens of req.
+func estimateRequestTokens(req ports.CompletionRequest) int {
+	tokens := estimateTokens(req.SystemPrompt)
+	for _, msg := range req.Messages {
+		tokens += estimateTokens(msg.Content)
+	}
+	return tokens
+}
+
+// estimateTokens estimates the tokens of text at windowCharsPerToken.
+func estimateTokens(text string) int {
+	return (len(text) + windowCharsPerToken - 1) / windowCharsPerToken
+}
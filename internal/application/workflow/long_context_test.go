@@ -0,0 +1,328 @@
+package workflow
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/jbctechsolutions/skillrunner/internal/application/ports"
+	"github.com/jbctechsolutions/skillrunner/internal/domain/skill"
+)
+
+// longInput returns n numbered paragraphs of about 100 characters each.
+func longInput(n int) string {
+	paragraphs := make([]string, n)
+	for i := range paragraphs {
+		paragraphs[i] = fmt.Sprintf("Paragraph %03d: %s", i+1, strings.Repeat("lorem ipsum ", 7))
+	}
+	return strings.Join(paragraphs, "\n\n")
+}
+
+func TestPhaseExecutor_SlidingWindow(t *testing.T) {
+	provider := newMockProvider()
+	calls := 0
+	provider.completeFunc = func(_ context.Context, req ports.CompletionRequest) (*ports.CompletionResponse, error) {
+		calls++
+		content := "final answer"
+		if strings.Contains(req.Messages[len(req.Messages)-1].Content, "Do not respond to the request yet") {
+			// An overlong summary must be capped to the carryover budget
+			content = fmt.Sprintf("summary %d ", calls) + strings.Repeat("detail ", 200)
+		}
+		return &ports.CompletionResponse{Content: content, InputTokens: 100, OutputTokens: 10}, nil
+	}
+
+	phase := createTestPhase(t, "digest", "Digest", "Summarize the input", nil)
+	phase.WithMaxTokens(200).WithLongContext(skill.NewSlidingWindow(1000, 100))
+
+	input := longInput(80) // About 2000 tokens
+	result := newPhaseExecutor(provider, "").Execute(context.Background(), &phase, map[string]string{"_input": input})
+
+	if result.Status != PhaseStatusCompleted {
+		t.Fatalf("expected completed phase, got %s: %v", result.Status, result.Error)
+	}
+	if result.Output != "final answer" {
+		t.Errorf("expected the last window's answer, got %q", result.Output)
+	}
+
+	n := len(provider.completeCalls)
+	if n < 3 {
+		t.Fatalf("expected the input to span several windows, got %d calls", n)
+	}
+	if result.InputTokens != 100*n || result.OutputTokens != 10*n {
+		t.Errorf("expected tokens summed across windows, got %d/%d", result.InputTokens, result.OutputTokens)
+	}
+
+	var seen strings.Builder
+	for i, req := range provider.completeCalls {
+		if tokens := estimateRequestTokens(req) + req.MaxTokens; tokens > 1000 {
+			t.Errorf("window %d uses %d tokens, over the 1000-token window", i+1, tokens)
+		}
+		content := req.Messages[0].Content
+		if !strings.Contains(content, fmt.Sprintf("[Part %d of %d]", i+1, n)) {
+			t.Errorf("window %d is missing its part header", i+1)
+		}
+		if i > 0 {
+			if !strings.Contains(content, fmt.Sprintf("summary %d", i)) {
+				t.Errorf("window %d does not carry the previous summary", i+1)
+			}
+			if summary := content[:strings.Index(content, "[Part")]; estimateTokens(summary) > 100+16 {
+				t.Errorf("window %d carries a %d-token summary, over the 100-token carryover", i+1, estimateTokens(summary))
+			}
+		}
+
+		last := req.Messages[len(req.Messages)-1]
+		if i < n-1 && req.MaxTokens != 100 {
+			t.Errorf("summary window %d: MaxTokens = %d, want the carryover", i+1, req.MaxTokens)
+		}
+		if i == n-1 && (last.Content != "Summarize the input" || req.MaxTokens != 200) {
+			t.Errorf("last window should send the original prompt and max tokens, got %q / %d", last.Content, req.MaxTokens)
+		}
+		seen.WriteString(content)
+	}
+	for _, marker := range []string{"Paragraph 001", "Paragraph 040", "Paragraph 080"} {
+		if !strings.Contains(seen.String(), marker) {
+			t.Errorf("%s was not sent in any window", marker)
+		}
+	}
+}
+
+func TestPhaseExecutor_SlidingWindowFits(t *testing.T) {
+	provider := newMockProvider()
+	phase := createTestPhase(t, "digest", "Digest", "Summarize the input", nil)
+	phase.WithMaxTokens(200).WithLongContext(skill.NewSlidingWindow(1000, 100))
+
+	result := newPhaseExecutor(provider, "").Execute(context.Background(), &phase, map[string]string{"_input": "short"})
+
+	if result.Status != PhaseStatusCompleted {
+		t.Fatalf("expected completed phase, got %s: %v", result.Status, result.Error)
+	}
+	if len(provider.completeCalls) != 1 || strings.Contains(provider.completeCalls[0].Messages[0].Content, "[Part") {
+		t.Errorf("expected a request that fits to be sent unchanged, got %d calls", len(provider.completeCalls))
+	}
+}
+
+func TestPhaseExecutor_SlidingWindowTooSmall(t *testing.T) {
+	provider := newMockProvider()
+	phase := createTestPhase(t, "digest", "Digest", "Summarize the input", nil)
+	phase.WithMaxTokens(400).WithLongContext(skill.NewSlidingWindow(600, 100))
+
+	result := newPhaseExecutor(provider, "").Execute(context.Background(), &phase, map[string]string{"_input": longInput(40)})
+
+	if result.Status != PhaseStatusFailed || !errors.Is(result.Error, ErrWindowTooSmall) {
+		t.Errorf("expected ErrWindowTooSmall, got %s: %v", result.Status, result.Error)
+	}
+	if len(provider.completeCalls) != 0 {
+		t.Errorf("expected no provider calls, got %d", len(provider.completeCalls))
+	}
+}
+
+func TestCapCarryover(t *testing.T) {
+	if got := capCarryover("short summary", 10); got != "short summary" {
+		t.Errorf("capCarryover() = %q, want unchanged", got)
+	}
+
+	long := strings.Repeat("word ", 100)
+	got := capCarryover(long, 10)
+	if estimateTokens(got) > 10 {
+		t.Errorf("capCarryover() left %d tokens, want at most 10", estimateTokens(got))
+	}
+	if strings.HasSuffix(got, "wor") {
+		t.Errorf("capCarryover() cut inside a word: %q", got)
+	}
+}
+
+func TestPhaseExecutor_MapReduce(t *testing.T) {
+	provider := newMockProvider()
+	provider.completeFunc = func(_ context.Context, req ports.CompletionRequest) (*ports.CompletionResponse, error) {
+		content := "final answer"
+		switch last := req.Messages[len(req.Messages)-1].Content; {
+		case strings.Contains(last, "summarized in parts"):
+			content = "chunk summary " + strings.Repeat("detail ", 50)
+		case strings.Contains(last, "Merge them"):
+			content = "merged summary " + strings.Repeat("detail ", 50)
+		}
+		return &ports.CompletionResponse{Content: content, InputTokens: 100, OutputTokens: 10, ModelUsed: req.ModelID}, nil
+	}
+
+	lc := skill.NewMapReduce(1000, 100)
+	lc.ReduceProfile = skill.RoutingProfilePremium
+	phase := createTestPhase(t, "digest", "Digest", "Summarize the input", nil)
+	phase.WithMaxTokens(200).WithLongContext(lc)
+
+	executor := newPhaseExecutor(provider, "")
+	result := executor.Execute(context.Background(), &phase, map[string]string{"_input": longInput(200)})
+
+	if result.Status != PhaseStatusCompleted {
+		t.Fatalf("expected completed phase, got %s: %v", result.Status, result.Error)
+	}
+	if result.Output != "final answer" {
+		t.Errorf("expected the answer from the merged summaries, got %q", result.Output)
+	}
+
+	var chunks, merges int
+	for i, req := range provider.completeCalls {
+		if tokens := estimateRequestTokens(req) + req.MaxTokens; tokens > 1000 {
+			t.Errorf("request %d uses %d tokens, over the 1000-token window", i+1, tokens)
+		}
+		switch last := req.Messages[len(req.Messages)-1].Content; {
+		case strings.Contains(last, "summarized in parts"):
+			chunks++
+			if req.ModelID != executor.selectModel(skill.RoutingProfileCheap) || req.MaxTokens != 100 {
+				t.Errorf("chunk summary on %q with %d max tokens, want the cheap model and the carryover", req.ModelID, req.MaxTokens)
+			}
+		case strings.Contains(last, "Merge them"):
+			merges++
+			if req.ModelID != executor.selectModel(skill.RoutingProfilePremium) {
+				t.Errorf("merge on %q, want the premium model", req.ModelID)
+			}
+		default:
+			if req.ModelID != executor.selectModel(phase.RoutingProfile) || req.MaxTokens != 200 {
+				t.Errorf("answer on %q with %d max tokens, want the phase's model and max tokens", req.ModelID, req.MaxTokens)
+			}
+			if !strings.Contains(req.Messages[0].Content, "[Summary of parts 1-") {
+				t.Error("expected the answer to receive the merged summaries")
+			}
+		}
+	}
+
+	n := len(provider.completeCalls)
+	if chunks < 4 || merges == 0 || chunks+merges+1 != n {
+		t.Fatalf("expected chunk summaries, merges and one answer, got %d/%d of %d calls", chunks, merges, n)
+	}
+	if result.InputTokens != 100*n || result.OutputTokens != 10*n {
+		t.Errorf("expected tokens summed across requests, got %d/%d", result.InputTokens, result.OutputTokens)
+	}
+}
+
+func TestPhaseExecutor_MapReduceError(t *testing.T) {
+	provider := newMockProvider()
+	failure := errors.New("provider down")
+	provider.completeFunc = func(_ context.Context, _ ports.CompletionRequest) (*ports.CompletionResponse, error) {
+		return nil, failure
+	}
+
+	phase := createTestPhase(t, "digest", "Digest", "Summarize the input", nil)
+	phase.WithMaxTokens(200).WithLongContext(skill.NewMapReduce(1000, 100))
+
+	result := newPhaseExecutor(provider, "").Execute(context.Background(), &phase, map[string]string{"_input": longInput(80)})
+
+	if result.Status != PhaseStatusFailed || !errors.Is(result.Error, failure) {
+		t.Errorf("expected the chunk summary error, got %s: %v", result.Status, result.Error)
+	}
+}
+
+// memoryChunks is a chunkProgress that keeps chunk responses in a map.
+type memoryChunks struct {
+	mu      sync.Mutex
+	outputs map[string]string
+}
+
+func (m *memoryChunks) chunkOutput(phaseID string, i, n int) (string, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	output, ok := m.outputs[chunkOutputKey(phaseID, i, n)]
+	return output, ok
+}
+
+func (m *memoryChunks) saveChunkOutput(_ context.Context, phaseID string, i, n int, output string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.outputs[chunkOutputKey(phaseID, i, n)] = output
+}
+
+func TestPhaseExecutor_Chunked(t *testing.T) {
+	provider := newMockProvider()
+	provider.completeFunc = func(_ context.Context, req ports.CompletionRequest) (*ports.CompletionResponse, error) {
+		content := "final report"
+		switch last := req.Messages[len(req.Messages)-1].Content; {
+		case strings.Contains(last, "processed in parts"):
+			content = "findings " + strings.Repeat("detail ", 100)
+		case strings.Contains(last, "Use at most"):
+			content = "merged findings"
+		}
+		return &ports.CompletionResponse{Content: content, InputTokens: 100, OutputTokens: 10}, nil
+	}
+
+	lc := skill.NewChunked(1000, 100)
+	lc.ReducePrompt = "Combine the findings into one report."
+	phase := createTestPhase(t, "audit", "Audit", "List the findings", nil)
+	phase.WithMaxTokens(200).WithLongContext(lc)
+
+	// A resumed run keeps the response to the first chunk
+	progress := &memoryChunks{outputs: map[string]string{}}
+	input := longInput(120)
+	progress.saveChunkOutput(context.Background(), "audit", 0, 5, "saved findings")
+
+	ctx := withChunkProgress(context.Background(), progress)
+	result := newPhaseExecutor(provider, "").Execute(ctx, &phase, map[string]string{"_input": input})
+
+	if result.Status != PhaseStatusCompleted {
+		t.Fatalf("expected completed phase, got %s: %v", result.Status, result.Error)
+	}
+	if result.Output != "final report" {
+		t.Errorf("expected the reduced report, got %q", result.Output)
+	}
+
+	var chunks, merges int
+	for i, req := range provider.completeCalls {
+		if tokens := estimateRequestTokens(req) + req.MaxTokens; tokens > 1000 {
+			t.Errorf("request %d uses %d tokens, over the 1000-token window", i+1, tokens)
+		}
+		switch last := req.Messages[len(req.Messages)-1].Content; {
+		case strings.Contains(last, "processed in parts"):
+			chunks++
+			if strings.Contains(req.Messages[0].Content, "[Part 1 of 5]") {
+				t.Error("expected the saved chunk not to be sent again")
+			}
+		case strings.Contains(last, "Use at most"):
+			merges++
+		default:
+			if last != lc.ReducePrompt || req.MaxTokens != 200 {
+				t.Errorf("reduce sent %q with %d max tokens, want the reduce prompt and the phase's max tokens", last, req.MaxTokens)
+			}
+		}
+	}
+	if chunks != 4 || merges == 0 {
+		t.Fatalf("expected 4 chunks and some merges, got %d/%d", chunks, merges)
+	}
+	if len(progress.outputs) != 5 {
+		t.Errorf("expected every chunk response saved, got %d", len(progress.outputs))
+	}
+	n := len(provider.completeCalls)
+	if result.InputTokens != 100*n || result.OutputTokens != 10*n {
+		t.Errorf("expected tokens summed across requests, got %d/%d", result.InputTokens, result.OutputTokens)
+	}
+}
+
+func TestProfileModels(t *testing.T) {
+	def, pinned := newMockProvider(), newMockProvider()
+	selectModel := func(profile string) string { return "model-" + profile }
+
+	if got := profileModels(selectModel, def, def)(skill.RoutingProfileCheap); got != "model-cheap" {
+		t.Errorf("profile model on the default provider = %q, want %q", got, "model-cheap")
+	}
+	if got := profileModels(selectModel, def, def)(""); got != "" {
+		t.Errorf("empty profile = %q, want the request's model", got)
+	}
+	if got := profileModels(selectModel, pinned, def)(skill.RoutingProfileCheap); got != "" {
+		t.Errorf("profile model on a pinned provider = %q, want the request's model", got)
+	}
+}
+
+func TestGroupSummaries(t *testing.T) {
+	summaries := make([]partSummary, 5)
+	for i := range summaries {
+		summaries[i] = partSummary{first: i + 1, last: i + 1, text: strings.Repeat("x", 400)}
+	}
+
+	groups := groupSummaries(summaries, 5, 250, summariesContent)
+	if len(groups) != 3 || len(groups[0]) != 2 || len(groups[2]) != 1 {
+		t.Errorf("expected runs of 2, 2 and 1, got %d groups", len(groups))
+	}
+	if got := groupSummaries(summaries, 5, 50, summariesContent); len(got) != 5 {
+		t.Errorf("expected summaries too large to merge to stay apart, got %d groups", len(got))
+	}
+}
@@ -0,0 +1,43 @@
+package workflow
+
+import (
+	"context"
+
+	"github.com/jbctechsolutions/skillrunner/internal/application/ports"
+	"github.com/jbctechsolutions/skillrunner/internal/domain/skill"
+)
+
+// mediaBackends are the non-LLM backends that transcription and image
+// generation phases run on, and the runner of skill phases.
+type mediaBackends struct {
+	transcriber    ports.TranscriptionPort
+	imageGenerator ports.ImageGenerationPort
+	artifactDir    string
+	subSkills      subSkillRunner
+}
+
+// newMediaBackends returns the media backends of an executor configuration
+// whose skill phases run on provider.
+func newMediaBackends(provider ports.ProviderPort, config ExecutorConfig) mediaBackends {
+	return mediaBackends{
+		transcriber:    config.Transcriber,
+		imageGenerator: config.ImageGenerator,
+		artifactDir:    config.ArtifactDir,
+		subSkills:      subSkillRunner{provider: provider, config: config},
+	}
+}
+
+// execute runs a phase that is not an LLM completion, rendering templates
+// with render. It returns nil for completion phases.
+func (m mediaBackends) execute(ctx context.Context, phase *skill.Phase, render func(string) (string, error)) *PhaseResult {
+	switch {
+	case phase.IsTranscription():
+		return transcribePhase(ctx, m.transcriber, phase, render)
+	case phase.IsImageGeneration():
+		return generateImagePhase(ctx, m.imageGenerator, m.artifactDir, phase, render)
+	case phase.IsSubSkill():
+		return m.subSkills.execute(ctx, phase, render)
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,17 @@
+package workflow
+
+import (
+	"context"
+
+	"github.com/jbctechsolutions/skillrunner/internal/application/ports"
+	"github.com/jbctechsolutions/skillrunner/internal/domain/skill"
+)
+
+// moderateRun applies the skill's moderation action to the provider calls
+// made under ctx, overriding the providers' action.
+func moderateRun(ctx context.Context, s *skill.Skill) context.Context {
+	if s == nil {
+		return ctx
+	}
+	return ports.WithModeration(ctx, s.Moderation())
+}
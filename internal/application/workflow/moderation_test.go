@@ -0,0 +1,34 @@
+package workflow
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jbctechsolutions/skillrunner/internal/application/ports"
+	"github.com/jbctechsolutions/skillrunner/internal/domain/skill"
+)
+
+func TestExecutor_SkillModeration(t *testing.T) {
+	var got string
+	provider := newMockProvider()
+	provider.completeFunc = func(ctx context.Context, _ ports.CompletionRequest) (*ports.CompletionResponse, error) {
+		got = ports.ModerationFrom(ctx)
+		return &ports.CompletionResponse{Content: "ok"}, nil
+	}
+
+	s := createTestSkill(t, []skill.Phase{createTestPhase(t, "a", "A", "{{._input}}", nil)})
+	if _, err := NewExecutor(provider, DefaultExecutorConfig()).Execute(context.Background(), s, "hi"); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if got != "" {
+		t.Errorf("moderation = %q, want the providers' action", got)
+	}
+
+	s.SetModeration(skill.ModerationWarn)
+	if _, err := NewExecutor(provider, DefaultExecutorConfig()).Execute(context.Background(), s, "hi"); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if got != skill.ModerationWarn {
+		t.Errorf("moderation = %q, want %q", got, skill.ModerationWarn)
+	}
+}
@@ -4,10 +4,10 @@ package workflow
 import (
 	"context"
 	"strings"
-	"text/template"
 	"time"
 
 	"github.com/jbctechsolutions/skillrunner/internal/application/ports"
+	appProvider "github.com/jbctechsolutions/skillrunner/internal/application/provider"
 	"github.com/jbctechsolutions/skillrunner/internal/domain/skill"
 )
 
@@ -15,6 +15,15 @@ import (
 type phaseExecutor struct {
 	provider      ports.ProviderPort
 	memoryContent string
+	selector      PhaseProviderSelector // optional per-phase provider selection
+	experiments   PhaseExperimentRouter // optional A/B experiment assignment
+	media         mediaBackends         // backends for transcription and image phases
+	groups        *ConcurrencyGroups    // optional limits of the phases' concurrency groups
+	sampling      itemSampling          // optional sample of the items of foreach phases
+	windows       ContextWindowChecker  // optional check of requests against model context windows
+	fallbacks     PhaseFallbackRouter   // optional fallbacks of phases whose completions stall
+	profiles      PhaseProfileRouter    // optional resolution of custom routing profiles
+	latency       LatencyRecorder       // optional record of the latency of requests
 }
 
 // newPhaseExecutor creates a new phase executor with the given provider and memory content.
@@ -28,6 +37,28 @@ func newPhaseExecutor(provider ports.ProviderPort, memoryContent string) *phaseE
 // Execute runs a single phase with the given dependency outputs.
 // It returns a PhaseResult containing the execution outcome.
 func (e *phaseExecutor) Execute(ctx context.Context, phase *skill.Phase, dependencyOutputs map[string]string) *PhaseResult {
+	render := func(t string) (string, error) {
+		return renderPrompt(t, dependencyOutputs, phase.Partials)
+	}
+	if result := conditionResult(phase, render); result != nil {
+		return result
+	}
+	if phase.ForEach != nil {
+		return runForEach(ctx, phase, dependencyOutputs, render, e.sampling, e.Execute)
+	}
+
+	release, err := e.groups.Acquire(ctx, phase.ConcurrencyGroup)
+	if err != nil {
+		return failedPhaseResult(phase, time.Now(), err)
+	}
+	defer release()
+
+	if !phase.IsCompletion() {
+		return timedPhase(ctx, phase.Timeout, func(ctx context.Context) *PhaseResult {
+			return e.media.execute(ctx, phase, render)
+		})
+	}
+
 	result := &PhaseResult{
 		PhaseID:   phase.ID,
 		PhaseName: phase.Name,
@@ -35,8 +66,9 @@ func (e *phaseExecutor) Execute(ctx context.Context, phase *skill.Phase, depende
 		StartTime: time.Now(),
 	}
 
-	// Build the prompt from the template
-	prompt, err := e.buildPrompt(phase.PromptTemplate, dependencyOutputs)
+	// Build the request and resolve the provider (honoring phase pins and
+	// experiments)
+	provider, req, assignment, err := e.prepareRequest(ctx, phase, dependencyOutputs)
 	if err != nil {
 		result.Status = PhaseStatusFailed
 		result.Error = err
@@ -44,17 +76,25 @@ func (e *phaseExecutor) Execute(ctx context.Context, phase *skill.Phase, depende
 		result.Duration = result.EndTime.Sub(result.StartTime)
 		return result
 	}
-
-	// Build the completion request
-	req := ports.CompletionRequest{
-		ModelID:     e.selectModel(phase.RoutingProfile),
-		Messages:    e.buildMessages(prompt, dependencyOutputs),
-		MaxTokens:   phase.MaxTokens,
-		Temperature: phase.Temperature,
-	}
-
-	// Call the provider
-	resp, err := e.provider.Complete(ctx, req)
+	result.ProviderUsed = provider.Info().Name
+	result.Prompt = requestPrompt(req)
+	result.tagExperiment(assignment)
+
+	// Call the provider (validating and retrying json output and outputs
+	// that echo the input, and moving stalled completions to the phase's
+	// fallback)
+	fallback := phaseStallFallback(e.fallbacks, e.selector, phase, result.stalledTo)
+	completion := retryStalled(provider, timed(e.latency, providerComplete), fallback, func() {})
+	models := profileModels(e.selectModel, provider, e.provider)
+	complete := windowed(phase, models, completion, completion)
+	resp, warnings, err := retryPhase(ctx, phase.Retry, req, escalationModel(phase, models), timedAttempt(phase.Timeout, func(ctx context.Context, req ports.CompletionRequest) (*ports.CompletionResponse, string, error) {
+		resp, err := completePhase(ctx, phase, req, complete)
+		if err != nil {
+			return nil, "", err
+		}
+		return guardSimilarity(ctx, phase, req, dependencyOutputs, resp, complete)
+	}), nil)
+	result.Warnings = append(result.Warnings, warnings...)
 	if err != nil {
 		result.Status = PhaseStatusFailed
 		result.Error = err
@@ -65,7 +105,7 @@ func (e *phaseExecutor) Execute(ctx context.Context, phase *skill.Phase, depende
 
 	// Populate the result
 	result.Status = PhaseStatusCompleted
-	result.Output = resp.Content
+	result.setOutput(phase, resp.Content)
 	result.InputTokens = resp.InputTokens
 	result.OutputTokens = resp.OutputTokens
 	result.ModelUsed = resp.ModelUsed
@@ -75,60 +115,64 @@ func (e *phaseExecutor) Execute(ctx context.Context, phase *skill.Phase, depende
 	return result
 }
 
-// buildPrompt renders the phase's prompt template with the dependency outputs.
-// The template can access values using {{.key}} syntax or {{index . "key-name"}} for keys with special chars.
-// Phase outputs are also available via {{.phases.phaseid}} for better organization.
-func (e *phaseExecutor) buildPrompt(templateStr string, data map[string]string) (string, error) {
-	// Convert to a generic map for template rendering with nested structure
-	templateData := make(map[string]any, len(data)+1)
-	phases := make(map[string]string)
-
-	for k, v := range data {
-		templateData[k] = v
-		// Add non-special keys to the phases map for nested access
-		if !strings.HasPrefix(k, "_") {
-			phases[k] = v
-		}
+// prepareRequest renders the phase prompt and resolves the provider and model
+// that will serve it, and the experiment variant the phase runs as, if any.
+// A request that does not fit the model's context window fails.
+func (e *phaseExecutor) prepareRequest(ctx context.Context, phase *skill.Phase, dependencyOutputs map[string]string) (ports.ProviderPort, ports.CompletionRequest, *appProvider.ExperimentAssignment, error) {
+	_, messages, err := e.buildRequest(phase, dependencyOutputs)
+	if err != nil {
+		return nil, ports.CompletionRequest{}, nil, err
 	}
 
-	// Add phases map for nested template access: {{.phases.phaseid}}
-	if len(phases) > 0 {
-		templateData["phases"] = phases
+	provider, modelID, err := resolveProfileProvider(ctx, e.profiles, e.selector, e.provider, phase, e.selectModel)
+	if err != nil {
+		return nil, ports.CompletionRequest{}, nil, err
 	}
+	provider, modelID, assignment := applyExperiment(ctx, e.experiments, phase, provider, modelID)
 
-	// Create template with custom function to access map values by key
-	funcMap := template.FuncMap{
-		"get": func(key string) string {
-			if v, ok := data[key]; ok {
-				return v
-			}
-			return ""
-		},
+	req := ports.CompletionRequest{
+		ModelID:        modelID,
+		Messages:       messages,
+		MaxTokens:      phase.MaxTokens,
+		Temperature:    phase.Temperature,
+		ResponseFormat: phaseResponseFormat(phase),
 	}
-
-	// Parse and execute the template
-	tmpl, err := template.New("prompt").Funcs(funcMap).Parse(templateStr)
-	if err != nil {
-		return "", err
+	if err := checkContextWindow(ctx, e.windows, phase, provider, req); err != nil {
+		return nil, ports.CompletionRequest{}, nil, err
 	}
+	return provider, req, assignment, nil
+}
 
-	var buf strings.Builder
-	if err := tmpl.Execute(&buf, templateData); err != nil {
-		return "", err
+// requestPrompt returns the rendered phase prompt of a request: the content
+// of its final user message.
+func requestPrompt(req ports.CompletionRequest) string {
+	if len(req.Messages) == 0 {
+		return ""
 	}
+	return req.Messages[len(req.Messages)-1].Content
+}
 
-	return buf.String(), nil
+// buildRequest renders the phase prompt and builds the request messages,
+// trimmed to the phase's context budget, if any.
+func (e *phaseExecutor) buildRequest(phase *skill.Phase, dependencyOutputs map[string]string) (string, []ports.Message, error) {
+	memory, dependencyOutputs, prompt, err := fitContextBudget(phase, e.memoryContent, dependencyOutputs, func(data map[string]string) (string, error) {
+		return renderPrompt(phase.PromptTemplate, data, phase.Partials)
+	})
+	if err != nil {
+		return "", nil, err
+	}
+	return prompt, e.buildMessages(memory, prompt, dependencyOutputs), nil
 }
 
 // buildMessages constructs the message array for the LLM request.
-func (e *phaseExecutor) buildMessages(prompt string, dependencyOutputs map[string]string) []ports.Message {
+func (e *phaseExecutor) buildMessages(memory, prompt string, dependencyOutputs map[string]string) []ports.Message {
 	messages := make([]ports.Message, 0, 3)
 
 	// Add memory context first (if available) - highest priority
-	if e.memoryContent != "" {
+	if memory != "" {
 		messages = append(messages, ports.Message{
 			Role:    "system",
-			Content: "Project Memory:\n\n" + e.memoryContent,
+			Content: "Project Memory:\n\n" + memory,
 		})
 	}
 
@@ -143,7 +187,7 @@ func (e *phaseExecutor) buildMessages(prompt string, dependencyOutputs map[strin
 
 		// Add outputs from dependencies
 		for id, output := range dependencyOutputs {
-			if id != "_input" && output != "" {
+			if id != "_input" && !skill.IsFileVariable(id) && output != "" {
 				contextParts = append(contextParts, "Previous Phase ("+id+"):\n"+output)
 			}
 		}
@@ -167,16 +211,18 @@ func (e *phaseExecutor) buildMessages(prompt string, dependencyOutputs map[strin
 }
 
 // selectModel returns a model ID based on the routing profile.
-// Maps routing profiles to actual Ollama model names.
+// Maps the built-in routing profiles to actual Ollama model names. Custom
+// profiles map to no model; resolveProfileProvider resolves them through
+// the routing configuration.
 func (e *phaseExecutor) selectModel(routingProfile string) string {
 	switch routingProfile {
 	case skill.RoutingProfileCheap:
 		return "llama3.2:3b"
 	case skill.RoutingProfilePremium:
 		return "qwen2.5:14b"
-	case skill.RoutingProfileBalanced:
-		fallthrough
-	default:
+	case skill.RoutingProfileBalanced, "":
 		return "llama3:8b"
+	default:
+		return ""
 	}
 }
@@ -4,13 +4,13 @@ package workflow
 import (
 	"context"
 	"strings"
-	"text/template"
 
 	"github.com/jbctechsolutions/skillrunner/internal/application/provider"
 	"github.com/jbctechsolutions/skillrunner/internal/domain/errors"
 	domainProvider "github.com/jbctechsolutions/skillrunner/internal/domain/provider"
 	"github.com/jbctechsolutions/skillrunner/internal/domain/skill"
 	"github.com/jbctechsolutions/skillrunner/internal/domain/workflow"
+	"github.com/jbctechsolutions/skillrunner/internal/infrastructure/templates"
 )
 
 // PlannerConfig contains configuration options for the planner.
@@ -107,10 +107,20 @@ func (p *Planner) GeneratePlan(
 		}
 	}
 
+	// Phases receive the outputs of the phases they depend on
+	outputTokens := make(map[string]int, len(phases))
+	for i := range phases {
+		outputTokens[phases[i].ID] = p.estimateOutputTokens(&phases[i])
+	}
+
 	// Generate plan for each phase
 	for i := range phases {
 		phase := &phases[i]
-		phasePlan, err := p.planPhase(ctx, phase, input, memoryContent, batchIndexMap)
+		dependencyTokens := 0
+		for _, dep := range phase.DependsOn {
+			dependencyTokens += outputTokens[dep]
+		}
+		phasePlan, err := p.planPhase(ctx, phase, input, memoryContent, dependencyTokens, batchIndexMap)
 		if err != nil {
 			// Log the error but continue with placeholder values
 			phasePlan = p.createPlaceholderPhasePlan(phase, batchIndexMap)
@@ -121,12 +131,14 @@ func (p *Planner) GeneratePlan(
 	return plan, nil
 }
 
-// planPhase generates a plan for a single phase.
+// planPhase generates a plan for a single phase. dependencyTokens is the
+// estimated output of the phases it depends on, which its prompt receives.
 func (p *Planner) planPhase(
 	ctx context.Context,
 	phase *skill.Phase,
 	input string,
 	memoryContent string,
+	dependencyTokens int,
 	batchIndexMap map[string]int,
 ) (*workflow.PhasePlan, error) {
 	// Resolve model using router
@@ -138,7 +150,7 @@ func (p *Planner) planPhase(
 	// Count input tokens
 	inputTokens := 0
 	if p.tokenEstimator != nil {
-		inputTokens = p.tokenEstimator.CountTokens(estimatedPrompt)
+		inputTokens = p.tokenEstimator.CountTokens(estimatedPrompt) + dependencyTokens
 	}
 
 	// Estimate output tokens
@@ -217,33 +229,26 @@ func (p *Planner) buildEstimatedPrompt(phase *skill.Phase, input string, memoryC
 	}
 
 	// Render the prompt template with the input
-	renderedPrompt := p.renderTemplate(phase.PromptTemplate, input)
+	renderedPrompt := p.renderTemplate(phase.PromptTemplate, phase.Partials, input)
 	builder.WriteString(renderedPrompt)
 
 	return builder.String()
 }
 
-// renderTemplate renders the prompt template with the given input.
-// Returns the original template if rendering fails.
-func (p *Planner) renderTemplate(templateStr string, input string) string {
+// renderTemplate renders the prompt template with the given input and
+// partials. Returns the original template if rendering fails.
+func (p *Planner) renderTemplate(templateStr string, partials map[string]string, input string) string {
 	// Create template data with input
 	data := map[string]any{
 		"_input": input,
 		"input":  input,
 	}
 
-	// Parse and execute the template
-	tmpl, err := template.New("prompt").Parse(templateStr)
+	rendered, err := templates.Render(templateStr, partials, data, nil)
 	if err != nil {
 		return templateStr
 	}
-
-	var buf strings.Builder
-	if err := tmpl.Execute(&buf, data); err != nil {
-		return templateStr
-	}
-
-	return buf.String()
+	return rendered
 }
 
 // estimateOutputTokens estimates the number of output tokens for a phase.
@@ -314,6 +314,27 @@ func TestPlanner_GeneratePlan_WithMemoryContent(t *testing.T) {
 	}
 }
 
+func TestPlanner_GeneratePlan_CountsDependencyOutputs(t *testing.T) {
+	estimator := &mockTokenEstimator{tokensPerChar: 0.25}
+	planner := NewPlanner(nil, nil, estimator, DefaultPlannerConfig())
+
+	draft, _ := skill.NewPhase("draft", "Draft", "Draft: {{.input}}")
+	draft.WithMaxTokens(1000)
+	polish, _ := skill.NewPhase("polish", "Polish", "Draft: {{.input}}")
+	polish.WithMaxTokens(1000).WithDependencies([]string{"draft"})
+
+	sk, _ := skill.NewSkill("test-skill", "Test Skill", "1.0.0", []skill.Phase{*draft, *polish})
+	plan, err := planner.GeneratePlan(context.Background(), sk, "test input", "")
+	if err != nil {
+		t.Fatalf("GeneratePlan() error: %v", err)
+	}
+
+	// Same prompt, plus the 500 estimated output tokens of draft
+	if got, want := plan.GetPhase("polish").EstimatedInputTokens, plan.GetPhase("draft").EstimatedInputTokens+500; got != want {
+		t.Errorf("polish EstimatedInputTokens = %d, want %d", got, want)
+	}
+}
+
 func TestPlanner_GeneratePlan_WithCostCalculator(t *testing.T) {
 	estimator := &mockTokenEstimator{tokensPerChar: 0.25}
 	calculator := domainProvider.NewCostCalculator()
@@ -412,7 +433,7 @@ func TestPlanner_RenderTemplate(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := planner.renderTemplate(tt.template, tt.input)
+			result := planner.renderTemplate(tt.template, nil, tt.input)
 			if !containsString(result, tt.contains) {
 				t.Errorf("expected result to contain %q, got %q", tt.contains, result)
 			}
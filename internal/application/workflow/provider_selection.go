@@ -0,0 +1,143 @@
+// Package workflow provides the workflow executor for skill execution.
+package workflow
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jbctechsolutions/skillrunner/internal/application/ports"
+	"github.com/jbctechsolutions/skillrunner/internal/application/provider"
+	"github.com/jbctechsolutions/skillrunner/internal/domain/skill"
+)
+
+// PhaseProviderSelector chooses the provider that executes a phase.
+// It lets individual phases pin a provider (skill.Phase.Provider) while
+// unpinned phases keep using the executor's default provider.
+// Implementations return an error to fail the phase, e.g. when a hard-pinned
+// provider is unavailable.
+type PhaseProviderSelector interface {
+	SelectProvider(ctx context.Context, phase *skill.Phase, defaultProvider ports.ProviderPort) (ports.ProviderPort, error)
+}
+
+// PhaseExperimentRouter assigns phase executions to the variants of A/B
+// experiments. It returns nil for phases that take part in no experiment.
+type PhaseExperimentRouter interface {
+	AssignVariant(ctx context.Context, phase *skill.Phase) *provider.ExperimentAssignment
+}
+
+// applyExperiment assigns a phase to an experiment variant and, for the
+// candidate variant, replaces the resolved provider and model with the
+// candidate's. The assignment is nil when the phase is in no experiment.
+func applyExperiment(
+	ctx context.Context,
+	router PhaseExperimentRouter,
+	phase *skill.Phase,
+	p ports.ProviderPort,
+	modelID string,
+) (ports.ProviderPort, string, *provider.ExperimentAssignment) {
+	if router == nil {
+		return p, modelID, nil
+	}
+
+	assignment := router.AssignVariant(ctx, phase)
+	if assignment != nil && assignment.Variant == provider.VariantCandidate {
+		return assignment.Provider, assignment.ModelID, assignment
+	}
+	return p, modelID, assignment
+}
+
+// PhaseProfileRouter returns the provider and model a routing profile
+// selects, such as *provider.Router. Phases with a custom routing profile
+// run on them, since selectModel only maps the built-in profiles.
+type PhaseProfileRouter interface {
+	ProfileProvider(ctx context.Context, profile string) (ports.ProviderPort, string, error)
+}
+
+// tagExperiment records the experiment variant a phase ran as.
+func (r *PhaseResult) tagExperiment(assignment *provider.ExperimentAssignment) {
+	if assignment != nil {
+		r.Experiment = assignment.Experiment
+		r.Variant = assignment.Variant
+	}
+}
+
+// resolvePhaseProvider determines the provider and model for a phase.
+// Without a selector the default provider is used. A model pin
+// (skill.Phase.Model) replaces the profile model when the provider serves
+// it; otherwise the phase falls back to the profile model. When the selector
+// picks a different provider that does not serve the profile model, the
+// first model advertised by that provider is used instead.
+func resolvePhaseProvider(
+	ctx context.Context,
+	selector PhaseProviderSelector,
+	defaultProvider ports.ProviderPort,
+	phase *skill.Phase,
+	profileModel string,
+) (ports.ProviderPort, string, error) {
+	provider := defaultProvider
+	if selector != nil {
+		selected, err := selector.SelectProvider(ctx, phase, defaultProvider)
+		if err != nil {
+			return nil, "", err
+		}
+		if selected != nil {
+			provider = selected
+		}
+	}
+
+	if phase.IsModelPinned() && supportsModel(ctx, provider, phase.Model) {
+		return provider, phase.Model, nil
+	}
+	// Selectors may decorate the default provider, e.g. to meter its costs
+	sameProvider := ports.UnwrapProvider(provider) == ports.UnwrapProvider(defaultProvider)
+	if sameProvider || supportsModel(ctx, provider, profileModel) {
+		return provider, profileModel, nil
+	}
+
+	models, err := provider.ListModels(ctx)
+	if err != nil || len(models) == 0 {
+		return provider, profileModel, nil
+	}
+
+	return provider, models[0], nil
+}
+
+// resolveProfileProvider resolves the provider and model of a phase like
+// resolvePhaseProvider. Built-in routing profiles map to models of
+// defaultProvider with selectModel. A custom profile selects its provider
+// and model with profiles instead, and fails the phase without a router or
+// when the routing configuration does not define it.
+func resolveProfileProvider(
+	ctx context.Context,
+	profiles PhaseProfileRouter,
+	selector PhaseProviderSelector,
+	defaultProvider ports.ProviderPort,
+	phase *skill.Phase,
+	selectModel func(routingProfile string) string,
+) (ports.ProviderPort, string, error) {
+	profile := phase.RoutingProfile
+	if !hasCustomProfile(phase) {
+		return resolvePhaseProvider(ctx, selector, defaultProvider, phase, selectModel(profile))
+	}
+
+	if profiles == nil {
+		return nil, "", fmt.Errorf("phase %s: %w: %s is not defined", phase.ID, provider.ErrInvalidProfile, profile)
+	}
+	p, model, err := profiles.ProfileProvider(ctx, profile)
+	if err != nil {
+		return nil, "", fmt.Errorf("phase %s: %w", phase.ID, err)
+	}
+	return resolvePhaseProvider(ctx, selector, p, phase, model)
+}
+
+// hasCustomProfile reports whether phase routes through a custom profile
+// of the routing configuration rather than a built-in one.
+func hasCustomProfile(phase *skill.Phase) bool {
+	return phase.RoutingProfile != "" && !skill.IsBuiltinProfile(phase.RoutingProfile)
+}
+
+// supportsModel reports whether provider serves modelID.
+func supportsModel(ctx context.Context, provider ports.ProviderPort, modelID string) bool {
+	supported, err := provider.SupportsModel(ctx, modelID)
+	return err == nil && supported
+}
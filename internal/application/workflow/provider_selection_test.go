@@ -0,0 +1,229 @@
+package workflow
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/jbctechsolutions/skillrunner/internal/application/ports"
+	"github.com/jbctechsolutions/skillrunner/internal/application/provider"
+	"github.com/jbctechsolutions/skillrunner/internal/domain/skill"
+)
+
+// stubSelector routes pinned phases to a fixed provider or returns an error.
+type stubSelector struct {
+	pinned ports.ProviderPort
+	err    error
+}
+
+func (s *stubSelector) SelectProvider(_ context.Context, phase *skill.Phase, defaultProvider ports.ProviderPort) (ports.ProviderPort, error) {
+	if !phase.IsPinned() {
+		return defaultProvider, nil
+	}
+	if s.err != nil {
+		return nil, s.err
+	}
+	return s.pinned, nil
+}
+
+// stubExperiments assigns phases to fixed experiment variants by phase ID.
+type stubExperiments map[string]*provider.ExperimentAssignment
+
+func (s stubExperiments) AssignVariant(_ context.Context, phase *skill.Phase) *provider.ExperimentAssignment {
+	return s[phase.ID]
+}
+
+// namedMockProvider is a mockProvider that reports a custom name and model list.
+type namedMockProvider struct {
+	*mockProvider
+	name   string
+	models []string
+}
+
+func (m *namedMockProvider) Info() ports.ProviderInfo {
+	return ports.ProviderInfo{Name: m.name}
+}
+
+func (m *namedMockProvider) SupportsModel(_ context.Context, modelID string) (bool, error) {
+	for _, model := range m.models {
+		if model == modelID {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (m *namedMockProvider) ListModels(_ context.Context) ([]string, error) {
+	return m.models, nil
+}
+
+func TestExecutor_Execute_PinnedPhaseUsesSelectedProvider(t *testing.T) {
+	defaultProvider := newMockProvider()
+	pinned := &namedMockProvider{mockProvider: newMockProvider(), name: "anthropic", models: []string{"claude-x"}}
+
+	unpinned := createTestPhase(t, "draft", "Draft", "Draft it", nil)
+	compliance := createTestPhase(t, "review", "Review", "Review it", []string{"draft"})
+	compliance.WithProvider("anthropic")
+	s := createTestSkill(t, []skill.Phase{unpinned, compliance})
+
+	config := DefaultExecutorConfig()
+	config.ProviderSelector = &stubSelector{pinned: pinned}
+	result, err := NewExecutor(defaultProvider, config).Execute(context.Background(), s, "input")
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+
+	if got := result.PhaseResults["draft"].ProviderUsed; got != "mock" {
+		t.Errorf("expected unpinned phase on default provider, got %q", got)
+	}
+	if got := result.PhaseResults["review"].ProviderUsed; got != "anthropic" {
+		t.Errorf("expected pinned phase on anthropic, got %q", got)
+	}
+	if got := result.PhaseResults["review"].ModelUsed; got != "claude-x" {
+		t.Errorf("expected pinned provider's model, got %q", got)
+	}
+	if defaultProvider.callCount.Load() != 1 || pinned.callCount.Load() != 1 {
+		t.Errorf("expected one call per provider, got default=%d pinned=%d",
+			defaultProvider.callCount.Load(), pinned.callCount.Load())
+	}
+}
+
+func TestExecutor_Execute_ModelPinnedPhase(t *testing.T) {
+	pinned := &namedMockProvider{mockProvider: newMockProvider(), name: "anthropic", models: []string{"claude-x", "claude-y"}}
+
+	draft := createTestPhase(t, "draft", "Draft", "Draft it", nil)
+	draft.WithProvider("anthropic").WithModel("claude-y")
+	review := createTestPhase(t, "review", "Review", "Review it", []string{"draft"})
+	review.WithProvider("anthropic").WithModel("gpt-4o")
+	s := createTestSkill(t, []skill.Phase{draft, review})
+
+	config := DefaultExecutorConfig()
+	config.ProviderSelector = &stubSelector{pinned: pinned}
+	result, err := NewExecutor(newMockProvider(), config).Execute(context.Background(), s, "input")
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+
+	if got := result.PhaseResults["draft"].ModelUsed; got != "claude-y" {
+		t.Errorf("expected pinned model, got %q", got)
+	}
+	if got := result.PhaseResults["review"].ModelUsed; got != "claude-x" {
+		t.Errorf("expected the provider's model for an unsupported pin, got %q", got)
+	}
+}
+
+func TestExecutor_Execute_PinnedPhaseSelectorError(t *testing.T) {
+	defaultProvider := newMockProvider()
+	pinErr := errors.New("pinned provider unavailable")
+
+	phase := createTestPhase(t, "review", "Review", "Review it", nil)
+	phase.WithProvider("ollama")
+	s := createTestSkill(t, []skill.Phase{phase})
+
+	config := DefaultExecutorConfig()
+	config.ProviderSelector = &stubSelector{err: pinErr}
+	result, err := NewExecutor(defaultProvider, config).Execute(context.Background(), s, "input")
+	if err != nil {
+		t.Fatalf("Execute returned unexpected error: %v", err)
+	}
+
+	if result.Status != PhaseStatusFailed {
+		t.Errorf("expected failed status, got %s", result.Status)
+	}
+	if !errors.Is(result.PhaseResults["review"].Error, pinErr) {
+		t.Errorf("expected phase error to be the selector error, got %v", result.PhaseResults["review"].Error)
+	}
+	if defaultProvider.callCount.Load() != 0 {
+		t.Error("expected no provider calls when the pin fails")
+	}
+}
+
+func TestExecutor_Execute_ExperimentVariants(t *testing.T) {
+	defaultProvider := newMockProvider()
+	candidate := &namedMockProvider{mockProvider: newMockProvider(), name: "openai", models: []string{"gpt-4o-mini"}}
+
+	draft := createTestPhase(t, "draft", "Draft", "Draft it", nil)
+	review := createTestPhase(t, "review", "Review", "Review it", []string{"draft"})
+	polish := createTestPhase(t, "polish", "Polish", "Polish it", []string{"review"})
+	s := createTestSkill(t, []skill.Phase{draft, review, polish})
+
+	config := DefaultExecutorConfig()
+	config.Experiments = stubExperiments{
+		"draft":  {Experiment: "mini", Variant: provider.VariantCandidate, Provider: candidate, ModelID: "gpt-4o-mini"},
+		"review": {Experiment: "mini", Variant: provider.VariantControl},
+	}
+	result, err := NewExecutor(defaultProvider, config).Execute(context.Background(), s, "input")
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+
+	tests := []struct {
+		phase      string
+		provider   string
+		experiment string
+		variant    string
+	}{
+		{"draft", "openai", "mini", provider.VariantCandidate},
+		{"review", "mock", "mini", provider.VariantControl},
+		{"polish", "mock", "", ""},
+	}
+	for _, tt := range tests {
+		pr := result.PhaseResults[tt.phase]
+		if pr.ProviderUsed != tt.provider || pr.Experiment != tt.experiment || pr.Variant != tt.variant {
+			t.Errorf("phase %s: got provider %q, experiment %q, variant %q; want %q, %q, %q",
+				tt.phase, pr.ProviderUsed, pr.Experiment, pr.Variant, tt.provider, tt.experiment, tt.variant)
+		}
+	}
+	if got := result.PhaseResults["draft"].ModelUsed; got != "gpt-4o-mini" {
+		t.Errorf("expected candidate model, got %q", got)
+	}
+}
+
+// stubProfiles resolves the custom routing profiles it holds to a provider
+// and model.
+type stubProfiles map[string]*namedMockProvider
+
+func (s stubProfiles) ProfileProvider(_ context.Context, profile string) (ports.ProviderPort, string, error) {
+	p, ok := s[profile]
+	if !ok {
+		return nil, "", provider.ErrInvalidProfile
+	}
+	return p, p.models[0], nil
+}
+
+func TestExecutor_Execute_CustomProfile(t *testing.T) {
+	defaultProvider := newMockProvider()
+	reasoning := &namedMockProvider{mockProvider: newMockProvider(), name: "anthropic", models: []string{"claude-x"}}
+
+	draft := createTestPhase(t, "draft", "Draft", "Draft it", nil)
+	draft.WithRoutingProfile("reasoning")
+	review := createTestPhase(t, "review", "Review", "Review it", nil)
+	review.WithRoutingProfile("offline")
+	s := createTestSkill(t, []skill.Phase{draft, review})
+
+	config := DefaultExecutorConfig()
+	config.Profiles = stubProfiles{"reasoning": reasoning}
+	result, err := NewExecutor(defaultProvider, config).Execute(context.Background(), s, "input")
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+
+	if pr := result.PhaseResults["draft"]; pr.ProviderUsed != "anthropic" || pr.ModelUsed != "claude-x" {
+		t.Errorf("draft ran on %s/%s, want the custom profile's anthropic/claude-x", pr.ProviderUsed, pr.ModelUsed)
+	}
+	if err := result.PhaseResults["review"].Error; !errors.Is(err, provider.ErrInvalidProfile) {
+		t.Errorf("review error = %v, want ErrInvalidProfile for an undefined profile", err)
+	}
+	if defaultProvider.callCount.Load() != 0 {
+		t.Errorf("default provider called %d times, want none", defaultProvider.callCount.Load())
+	}
+
+	// Without a router, custom profiles cannot be resolved
+	result, err = NewStreamingExecutor(defaultProvider, DefaultExecutorConfig()).ExecuteWithStreaming(context.Background(), s, "input", nil)
+	if err != nil {
+		t.Fatalf("ExecuteWithStreaming failed: %v", err)
+	}
+	if err := result.PhaseResults["draft"].Error; !errors.Is(err, provider.ErrInvalidProfile) {
+		t.Errorf("draft error = %v, want ErrInvalidProfile without a router", err)
+	}
+}
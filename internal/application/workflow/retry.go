@@ -0,0 +1,106 @@
+package workflow
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jbctechsolutions/skillrunner/internal/application/ports"
+	"github.com/jbctechsolutions/skillrunner/internal/domain/skill"
+	"github.com/jbctechsolutions/skillrunner/internal/infrastructure/logging"
+)
+
+// retryNotice is streamed before a failed phase is sent again, so the
+// output streamed so far is not mistaken for the start of the answer.
+const retryNotice = "\n\n[phase failed; retrying]\n\n"
+
+// phaseAttempt runs a phase's completion once, returning the response and
+// any warning about it.
+type phaseAttempt func(ctx context.Context, req ports.CompletionRequest) (*ports.CompletionResponse, string, error)
+
+// escalationModel returns the model a phase escalates to under its retry
+// policy: the policy's model, or the premium profile's model on the
+// executor's default provider. It returns "" when the phase does not
+// escalate or no model is known.
+func escalationModel(phase *skill.Phase, models profileModelFunc) string {
+	if phase.Retry == nil || !phase.Retry.Escalates() {
+		return ""
+	}
+	return cmp.Or(phase.Retry.EscalateModel, models(skill.RoutingProfilePremium))
+}
+
+// retryPhase runs attempt under policy. A failed attempt is run again up to
+// policy.Retries times, waiting the policy's backoff before each retry.
+// When the retries are exhausted, the request is sent once more to escalate
+// if the policy escalates and escalate differs from the request's model.
+// restart, if not nil, is called before each further attempt to discard
+// the content streamed so far. Warnings name the failures a phase recovered
+// from. Token usage of failed attempts is unknown and not counted.
+func retryPhase(
+	ctx context.Context,
+	policy *skill.RetryPolicy,
+	req ports.CompletionRequest,
+	escalate string,
+	attempt phaseAttempt,
+	restart func(),
+) (*ports.CompletionResponse, []string, error) {
+	resp, warning, err := attempt(ctx, req)
+	if err == nil || policy == nil {
+		return resp, warningList(warning), err
+	}
+
+	log := logging.FromContext(ctx)
+	for retry := 1; retry <= policy.Retries && err != nil && ctx.Err() == nil; retry++ {
+		delay := policy.Delay(retry)
+		log.WarnContext(ctx, "retrying failed phase", "model", req.ModelID, "retry", retry, "backoff", delay, "error", err)
+		if waitErr := sleepContext(ctx, delay); waitErr != nil {
+			return nil, nil, err
+		}
+		if restart != nil {
+			restart()
+		}
+		lastErr := err
+		if resp, warning, err = attempt(ctx, req); err == nil {
+			return resp, warningList(fmt.Sprintf("retry: succeeded on attempt %d after: %v", retry+1, lastErr), warning), nil
+		}
+	}
+
+	if escalate == "" || escalate == req.ModelID || ctx.Err() != nil {
+		return nil, nil, err
+	}
+	log.WarnContext(ctx, "escalating failed phase", "model", req.ModelID, "escalate_model", escalate, "error", err)
+	if restart != nil {
+		restart()
+	}
+	lastErr, from := err, req.ModelID
+	req.ModelID = escalate
+	if resp, warning, err = attempt(ctx, req); err != nil {
+		return nil, nil, fmt.Errorf("escalated to %s after %v: %w", escalate, lastErr, err)
+	}
+	return resp, warningList(fmt.Sprintf("retry: escalated from %s to %s after %d failed attempts: %v", from, escalate, policy.Retries+1, lastErr), warning), nil
+}
+
+// warningList returns the non-empty warnings.
+func warningList(warnings ...string) []string {
+	var list []string
+	for _, w := range warnings {
+		if w != "" {
+			list = append(list, w)
+		}
+	}
+	return list
+}
+
+// sleepContext waits for d or until ctx is done, returning the context's
+// error in the latter case.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,113 @@
+package workflow
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jbctechsolutions/skillrunner/internal/application/ports"
+	"github.com/jbctechsolutions/skillrunner/internal/domain/skill"
+)
+
+// failingAttempt fails the first failures attempts on any model other than
+// the escalation model, recording the models it was sent.
+func failingAttempt(failures int, models *[]string) phaseAttempt {
+	return func(_ context.Context, req ports.CompletionRequest) (*ports.CompletionResponse, string, error) {
+		*models = append(*models, req.ModelID)
+		if req.ModelID != "big" && len(*models) <= failures {
+			return nil, "", errors.New("HTTP 500")
+		}
+		return &ports.CompletionResponse{Content: "done", ModelUsed: req.ModelID}, "", nil
+	}
+}
+
+func TestRetryPhase(t *testing.T) {
+	req := ports.CompletionRequest{ModelID: "small"}
+	policy := &skill.RetryPolicy{Retries: 2, Backoff: time.Millisecond, OnFailure: skill.RetryOnFailureEscalateModel}
+
+	t.Run("recovers on retry", func(t *testing.T) {
+		var models []string
+		restarts := 0
+		resp, warnings, err := retryPhase(context.Background(), policy, req, "big", failingAttempt(1, &models), func() { restarts++ })
+		if err != nil || resp.ModelUsed != "small" {
+			t.Fatalf("retryPhase() = %v, %v; want the retried response", resp, err)
+		}
+		if len(models) != 2 || restarts != 1 {
+			t.Errorf("models, restarts = %v, %d; want 2 attempts and 1 restart", models, restarts)
+		}
+		if len(warnings) != 1 || !strings.Contains(warnings[0], "succeeded on attempt 2") {
+			t.Errorf("warnings = %q", warnings)
+		}
+	})
+
+	t.Run("escalates", func(t *testing.T) {
+		var models []string
+		resp, warnings, err := retryPhase(context.Background(), policy, req, "big", failingAttempt(10, &models), nil)
+		if err != nil || resp.ModelUsed != "big" {
+			t.Fatalf("retryPhase() = %v, %v; want the escalated response", resp, err)
+		}
+		if strings.Join(models, ",") != "small,small,small,big" {
+			t.Errorf("models = %v, want 3 attempts and the escalation", models)
+		}
+		if len(warnings) != 1 || !strings.Contains(warnings[0], "escalated from small to big after 3 failed attempts") {
+			t.Errorf("warnings = %q", warnings)
+		}
+	})
+
+	t.Run("fails without escalation model", func(t *testing.T) {
+		var models []string
+		if _, _, err := retryPhase(context.Background(), policy, req, "", failingAttempt(10, &models), nil); err == nil || len(models) != 3 {
+			t.Errorf("error, models = %v, %v; want the failure after 3 attempts", err, models)
+		}
+	})
+
+	t.Run("no policy", func(t *testing.T) {
+		var models []string
+		if _, _, err := retryPhase(context.Background(), nil, req, "big", failingAttempt(1, &models), nil); err == nil || len(models) != 1 {
+			t.Errorf("error, models = %v, %v; want the first failure", err, models)
+		}
+	})
+
+	t.Run("canceled during backoff", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		var models []string
+		slow := &skill.RetryPolicy{Retries: 1, Backoff: time.Hour, OnFailure: skill.RetryOnFailureEscalateModel}
+		if _, _, err := retryPhase(ctx, slow, req, "big", failingAttempt(10, &models), nil); err == nil || len(models) != 1 {
+			t.Errorf("error, models = %v, %v; want the failure without further attempts", err, models)
+		}
+	})
+}
+
+func TestExecutor_RetryPolicy(t *testing.T) {
+	provider := newMockProvider()
+	provider.completeFunc = func(_ context.Context, req ports.CompletionRequest) (*ports.CompletionResponse, error) {
+		// A cheap local model that keeps failing
+		if req.ModelID == "llama3.2:3b" {
+			return nil, errors.New("model crashed")
+		}
+		return &ports.CompletionResponse{Content: "summary", ModelUsed: req.ModelID}, nil
+	}
+
+	p := createTestPhase(t, "summarize", "Summarize", "{{._input}}", nil)
+	p.WithRoutingProfile(skill.RoutingProfileCheap).
+		WithRetry(&skill.RetryPolicy{Retries: 1, Backoff: time.Millisecond, OnFailure: skill.RetryOnFailureEscalateModel})
+	s := createTestSkill(t, []skill.Phase{p})
+
+	result, err := NewExecutor(provider, DefaultExecutorConfig()).Execute(context.Background(), s, "a long document")
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	pr := result.PhaseResults["summarize"]
+	if pr.Status != PhaseStatusCompleted || pr.ModelUsed != "qwen2.5:14b" {
+		t.Fatalf("status, model = %s, %s; want completed on the premium model", pr.Status, pr.ModelUsed)
+	}
+	if len(pr.Warnings) != 1 {
+		t.Errorf("warnings = %q, want the escalation", pr.Warnings)
+	}
+	if got := provider.callCount.Load(); got != 3 {
+		t.Errorf("provider called %d times, want 3", got)
+	}
+}
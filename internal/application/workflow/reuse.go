@@ -0,0 +1,157 @@
+package workflow
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+
+	"github.com/jbctechsolutions/skillrunner/internal/application/ports"
+	domainSkill "github.com/jbctechsolutions/skillrunner/internal/domain/skill"
+	"github.com/jbctechsolutions/skillrunner/internal/domain/workflow"
+)
+
+// reuseSearchLimit is how many of a skill's latest checkpoints are searched
+// for one with the same input to reuse phases from.
+const reuseSearchLimit = 50
+
+// PhasePromptHash returns a hash of what a phase sends to its model apart
+// from its input and dependencies: its prompt template, routing, generation
+// settings, validators and the memory content injected into its prompt. A completed
+// phase whose hash is unchanged, run on the same input and dependency
+// outputs, can be reused instead of run again.
+func PhasePromptHash(p *domainSkill.Phase, memoryContent string) string {
+	data, _ := json.Marshal(struct {
+		Template, Profile, Provider, Model string
+		MaxTokens                          int
+		Temperature                        float32
+		OutputFormat                       string
+		OutputSchema                       json.RawMessage
+		Memory                             string
+		Validation                         *domainSkill.ValidationConfig `json:",omitempty"`
+		Partials                           map[string]string             `json:",omitempty"`
+	}{
+		p.PromptTemplate, p.RoutingProfile, p.Provider, p.Model,
+		p.MaxTokens, p.Temperature, p.OutputFormat, p.OutputSchema, memoryContent,
+		p.Validation, p.Partials,
+	})
+	h := sha256.Sum256(data)
+	return hex.EncodeToString(h[:16])
+}
+
+// PhaseRequest rebuilds the request phase sends to its model in a run
+// whose phase outputs, with run variables such as _input, are phaseOutputs,
+// with memoryContent injected into its prompt. Its model is left empty.
+// When the phase's PhasePromptHash is unchanged since the run, it is the
+// request the run sent.
+func PhaseRequest(phase *domainSkill.Phase, memoryContent string, phaseOutputs map[string]string) (ports.CompletionRequest, error) {
+	outputs := make(map[string]string, len(phase.DependsOn)+len(domainSkill.FileVariables)+1)
+	copyRunVariables(outputs, phaseOutputs)
+	for _, dep := range phase.DependsOn {
+		if output, ok := phaseOutputs[dep]; ok {
+			outputs[dep] = output
+		}
+	}
+
+	_, messages, err := newPhaseExecutor(nil, memoryContent).buildRequest(phase, outputs)
+	if err != nil {
+		return ports.CompletionRequest{}, err
+	}
+	return ports.CompletionRequest{
+		Messages:       messages,
+		MaxTokens:      phase.MaxTokens,
+		Temperature:    phase.Temperature,
+		ResponseFormat: phaseResponseFormat(phase),
+	}, nil
+}
+
+// reusePhases fills result and phaseOutputs with the completed phases of the
+// latest earlier run of s on input that are unchanged: their prompt hash
+// matches and every phase they depend on is reused too. Reused phases are
+// marked as cache hits with no tokens, since they call no model. Returns the
+// execution ID of the run reused from and how many phases were reused.
+// Transcriptions and skill phases depend on more than their prompt, so
+// they always run again, as do foreach phases sampled differently.
+func (e *CheckpointingExecutor) reusePhases(
+	ctx context.Context,
+	s *domainSkill.Skill,
+	dag *workflow.DAG,
+	input string,
+	result *ExecutionResult,
+	phaseOutputs map[string]string,
+) (string, int, error) {
+	source, err := e.reuseSource(ctx, s.ID(), input)
+	if err != nil || source == nil {
+		return "", 0, err
+	}
+
+	order, err := dag.TopologicalSort()
+	if err != nil {
+		return "", 0, err
+	}
+	results := source.PhaseResults()
+	outputs := source.PhaseOutputs()
+	reused := make(map[string]bool)
+	for _, phaseID := range order {
+		phase := dag.GetPhase(phaseID)
+		data := results[phaseID]
+		if phase == nil || data == nil || phase.InputAudio != "" || phase.IsSubSkill() ||
+			data.Status != string(PhaseStatusCompleted) ||
+			data.PromptHash == "" || data.PromptHash != PhasePromptHash(phase, e.config.MemoryContent) ||
+			!newItemSampling(e.config).matches(phase, data.Sample) {
+			continue
+		}
+		depsReused := true
+		for _, dep := range dag.GetDependencies(phaseID) {
+			depsReused = depsReused && reused[dep]
+		}
+		output, ok := outputs[phaseID]
+		if !depsReused || !ok {
+			continue
+		}
+
+		now := time.Now()
+		reused[phaseID] = true
+		phaseOutputs[phaseID] = output
+		pr := result.PhaseResults[phaseID]
+		pr.Status = PhaseStatusCompleted
+		pr.Output = output
+		pr.StartTime, pr.EndTime = now, now
+		pr.ModelUsed = data.ModelUsed
+		pr.ProviderUsed = data.ProviderUsed
+		pr.Batch = data.Batch
+		pr.CacheHit = true
+		pr.ReusedFrom = source.ExecutionID()
+	}
+	return source.ExecutionID(), len(reused), nil
+}
+
+// reuseSource returns the latest checkpoint of an earlier run of the skill
+// on input that recorded phase prompt hashes, or nil.
+func (e *CheckpointingExecutor) reuseSource(ctx context.Context, skillID, input string) (*workflow.WorkflowCheckpoint, error) {
+	checkpoints, err := e.cpConfig.Port.List(ctx, &ports.WorkflowCheckpointFilter{
+		SkillID: skillID,
+		Limit:   reuseSearchLimit,
+	})
+	if err != nil {
+		return nil, err
+	}
+	inputHash := workflow.HashInput(input)
+	for _, cp := range checkpoints {
+		if cp.InputHash() != inputHash || (e.cpConfig.ExecutionID != "" && cp.ExecutionID() == e.cpConfig.ExecutionID) {
+			continue
+		}
+		for _, data := range cp.PhaseResults() {
+			if data.PromptHash != "" && data.Status == string(PhaseStatusCompleted) {
+				// Listings leave archived outputs out
+				full, err := e.cpConfig.Port.Get(ctx, cp.ID())
+				if err != nil || full.ArchiveError() != nil {
+					return nil, err
+				}
+				return full, nil
+			}
+		}
+	}
+	return nil, nil
+}
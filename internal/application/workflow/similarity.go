@@ -0,0 +1,122 @@
+package workflow
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/jbctechsolutions/skillrunner/internal/application/ports"
+	"github.com/jbctechsolutions/skillrunner/internal/domain/skill"
+)
+
+// DefaultSimilarityRetries is the number of times a phase whose output
+// echoes its input is asked again before the output is flagged.
+const DefaultSimilarityRetries = 1
+
+// guardSimilarity checks resp, the output of a phase with a similarity
+// guard, against sources, the input and dependency outputs the phase was
+// given. An output that is empty or repeats a source beyond the guard's
+// threshold is asked for again when the guard retries, and otherwise kept
+// with a warning describing the problem. Token usage is summed across
+// attempts.
+func guardSimilarity(
+	ctx context.Context,
+	phase *skill.Phase,
+	req ports.CompletionRequest,
+	sources map[string]string,
+	resp *ports.CompletionResponse,
+	complete completeFunc,
+) (*ports.CompletionResponse, string, error) {
+	guard := phase.SimilarityGuard
+	if guard == nil {
+		return resp, "", nil
+	}
+
+	inputTokens, outputTokens := resp.InputTokens, resp.OutputTokens
+	problem := echoProblem(resp.Content, sources, guard.Threshold())
+	for attempt := 0; problem != "" && guard.Retries() && attempt < DefaultSimilarityRetries; attempt++ {
+		// Show the model its own output and what was wrong with it
+		req.Messages = append(slices.Clip(req.Messages),
+			ports.Message{Role: "assistant", Content: resp.Content},
+			ports.Message{Role: "user", Content: fmt.Sprintf(
+				"Your previous response was rejected: %s. Respond with the result the instructions ask for instead of repeating what you were given.", problem)},
+		)
+		next, err := completePhase(ctx, phase, req, complete)
+		if err != nil {
+			return nil, "", err
+		}
+		inputTokens += next.InputTokens
+		outputTokens += next.OutputTokens
+		resp = next
+		problem = echoProblem(resp.Content, sources, guard.Threshold())
+	}
+
+	resp.InputTokens = inputTokens
+	resp.OutputTokens = outputTokens
+	return resp, problem, nil
+}
+
+// addWarning records a quality problem found in the output, if any.
+func (r *PhaseResult) addWarning(warning string) {
+	if warning != "" {
+		r.Warnings = append(r.Warnings, warning)
+	}
+}
+
+// echoProblem describes how output fails a similarity guard with the given
+// threshold, or returns "" when it passes.
+func echoProblem(output string, sources map[string]string, threshold float64) string {
+	words := similarityWords(output)
+	if len(words) == 0 {
+		return "the output is empty"
+	}
+	for _, name := range slices.Sorted(maps.Keys(sources)) {
+		if name != "_input" && strings.HasPrefix(name, "_") {
+			continue
+		}
+		if similarity := containment(words, similarityWords(sources[name])); similarity >= threshold {
+			source := "the input"
+			if name != "_input" {
+				source = "the output of phase " + name
+			}
+			return fmt.Sprintf("the output is %.0f%% similar to %s", similarity*100, source)
+		}
+	}
+	return ""
+}
+
+// containment returns the share of the word pairs of output that also
+// appear in source: 1 when output copies source or a part of it, near 0
+// when it is written anew. Single-word outputs are compared by word.
+func containment(output, source []string) float64 {
+	n := min(2, len(output))
+	if n == 0 || len(source) < n {
+		return 0
+	}
+	seen := make(map[string]bool, len(source))
+	for i := 0; i+n <= len(source); i++ {
+		seen[strings.Join(source[i:i+n], " ")] = true
+	}
+	grams := make(map[string]bool, len(output))
+	for i := 0; i+n <= len(output); i++ {
+		grams[strings.Join(output[i:i+n], " ")] = true
+	}
+	shared := 0
+	for g := range grams {
+		if seen[g] {
+			shared++
+		}
+	}
+	return float64(shared) / float64(len(grams))
+}
+
+// similarityWords splits text into lowercase words, ignoring punctuation
+// and formatting.
+func similarityWords(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+}
@@ -0,0 +1,152 @@
+package workflow
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/jbctechsolutions/skillrunner/internal/application/ports"
+	"github.com/jbctechsolutions/skillrunner/internal/domain/skill"
+)
+
+func TestEchoProblem(t *testing.T) {
+	input := "The quick brown fox jumps over the lazy dog near the river bank."
+	sources := map[string]string{
+		"_input":  input,
+		"_memory": "Translate everything into French.",
+		"draft":   "Un renard brun rapide saute par-dessus le chien paresseux.",
+	}
+
+	tests := []struct {
+		name   string
+		output string
+		want   string
+	}{
+		{"verbatim echo", input, "the output is 100% similar to the input"},
+		{"echo with formatting", "**The quick brown fox** jumps over the lazy dog near the river bank", "the output is 100% similar to the input"},
+		{"echo of a dependency", "Un renard brun rapide saute par-dessus le chien paresseux.", "the output is 100% similar to the output of phase draft"},
+		{"empty", "  \n ", "the output is empty"},
+		{"punctuation only", "...", "the output is empty"},
+		{"translation", "Le rapide renard brun saute au-dessus du chien fainéant près de la rive.", ""},
+		{"summary", "A fox jumps over a dog by a river.", ""},
+		{"ignored special source", "Translate everything into French.", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := echoProblem(tt.output, sources, skill.DefaultMaxSimilarity); got != tt.want {
+				t.Errorf("echoProblem() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGuardSimilarity(t *testing.T) {
+	input := "Please rewrite this paragraph so that it reads more formally and clearly."
+	sources := map[string]string{"_input": input}
+	req := ports.CompletionRequest{Messages: []ports.Message{{Role: "user", Content: "Rewrite: " + input}}}
+
+	// complete answers with outputs in turn, recording the requests
+	replay := func(outputs ...string) (completeFunc, *[]ports.CompletionRequest) {
+		var calls []ports.CompletionRequest
+		return func(_ context.Context, req ports.CompletionRequest) (*ports.CompletionResponse, error) {
+			calls = append(calls, req)
+			out := outputs[min(len(calls), len(outputs))-1]
+			return &ports.CompletionResponse{Content: out, InputTokens: 10, OutputTokens: 5}, nil
+		}, &calls
+	}
+	phase := func(cfg *skill.SimilarityGuardConfig) *skill.Phase {
+		p, _ := skill.NewPhase("rewrite", "Rewrite", "Rewrite: {{._input}}")
+		return p.WithSimilarityGuard(cfg)
+	}
+	rewritten := "Kindly revise the following passage to improve its formality and clarity."
+
+	t.Run("retry fixes echo", func(t *testing.T) {
+		complete, calls := replay(rewritten)
+		first := &ports.CompletionResponse{Content: input, InputTokens: 10, OutputTokens: 5}
+		resp, warning, err := guardSimilarity(context.Background(), phase(&skill.SimilarityGuardConfig{}), req, sources, first, complete)
+		if err != nil || warning != "" {
+			t.Fatalf("guardSimilarity() = %q, %v; want no warning", warning, err)
+		}
+		if resp.Content != rewritten || resp.InputTokens != 20 || resp.OutputTokens != 10 {
+			t.Errorf("resp = %+v, want the retried output with summed tokens", resp)
+		}
+		if len(*calls) != 1 {
+			t.Fatalf("provider called %d times, want 1", len(*calls))
+		}
+		msgs := (*calls)[0].Messages
+		if len(msgs) != 3 || msgs[1].Content != input || !strings.Contains(msgs[2].Content, "100% similar to the input") {
+			t.Errorf("retry messages = %+v", msgs)
+		}
+	})
+
+	t.Run("persistent echo is flagged", func(t *testing.T) {
+		complete, calls := replay(input)
+		first := &ports.CompletionResponse{Content: input}
+		resp, warning, err := guardSimilarity(context.Background(), phase(&skill.SimilarityGuardConfig{}), req, sources, first, complete)
+		if err != nil || warning != "the output is 100% similar to the input" {
+			t.Errorf("guardSimilarity() = %q, %v", warning, err)
+		}
+		if resp.Content != input || len(*calls) != DefaultSimilarityRetries {
+			t.Errorf("content = %q after %d retries", resp.Content, len(*calls))
+		}
+	})
+
+	t.Run("flag action does not retry", func(t *testing.T) {
+		complete, calls := replay(rewritten)
+		first := &ports.CompletionResponse{Content: ""}
+		_, warning, err := guardSimilarity(context.Background(), phase(&skill.SimilarityGuardConfig{Action: skill.SimilarityActionFlag}), req, sources, first, complete)
+		if err != nil || warning != "the output is empty" || len(*calls) != 0 {
+			t.Errorf("guardSimilarity() = %q, %v after %d calls", warning, err, len(*calls))
+		}
+	})
+
+	t.Run("threshold", func(t *testing.T) {
+		complete, _ := replay(rewritten)
+		// Half of the output's word pairs come from the input
+		first := &ports.CompletionResponse{Content: "please rewrite this paragraph so that kindly revise passage improve formality"}
+		_, warning, err := guardSimilarity(context.Background(), phase(&skill.SimilarityGuardConfig{Action: skill.SimilarityActionFlag}), req, sources, first, complete)
+		if err != nil || warning != "" {
+			t.Errorf("guardSimilarity() with the default threshold = %q, %v", warning, err)
+		}
+		_, warning, err = guardSimilarity(context.Background(), phase(&skill.SimilarityGuardConfig{MaxSimilarity: 0.4, Action: skill.SimilarityActionFlag}), req, sources, first, complete)
+		if err != nil || warning != "the output is 50% similar to the input" {
+			t.Errorf("guardSimilarity() with threshold 0.4 = %q, %v", warning, err)
+		}
+	})
+
+	t.Run("no guard", func(t *testing.T) {
+		complete, calls := replay(rewritten)
+		first := &ports.CompletionResponse{Content: input}
+		resp, warning, err := guardSimilarity(context.Background(), phase(nil), req, sources, first, complete)
+		if err != nil || warning != "" || resp != first || len(*calls) != 0 {
+			t.Errorf("guardSimilarity() = %q, %v after %d calls", warning, err, len(*calls))
+		}
+	})
+}
+
+func TestExecutor_SimilarityGuard(t *testing.T) {
+	provider := newMockProvider()
+	provider.completeFunc = func(_ context.Context, req ports.CompletionRequest) (*ports.CompletionResponse, error) {
+		// A model that hands back what it was given
+		return &ports.CompletionResponse{Content: "Translate this sentence into German please", ModelUsed: req.ModelID}, nil
+	}
+
+	p := createTestPhase(t, "translate", "Translate", "{{._input}}", nil)
+	p.WithSimilarityGuard(&skill.SimilarityGuardConfig{})
+	s := createTestSkill(t, []skill.Phase{p})
+
+	result, err := NewExecutor(provider, DefaultExecutorConfig()).Execute(context.Background(), s, "Translate this sentence into German please")
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	pr := result.PhaseResults["translate"]
+	if pr.Status != PhaseStatusCompleted {
+		t.Fatalf("status = %s, want completed", pr.Status)
+	}
+	if len(pr.Warnings) != 1 || pr.Warnings[0] != "the output is 100% similar to the input" {
+		t.Errorf("warnings = %q", pr.Warnings)
+	}
+	if got := provider.callCount.Load(); got != 1+DefaultSimilarityRetries {
+		t.Errorf("provider called %d times, want %d", got, 1+DefaultSimilarityRetries)
+	}
+}
@@ -0,0 +1,129 @@
+package workflow
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/jbctechsolutions/skillrunner/internal/application/ports"
+	"github.com/jbctechsolutions/skillrunner/internal/domain/skill"
+	"github.com/jbctechsolutions/skillrunner/internal/infrastructure/logging"
+)
+
+// DefaultStallRetries is the number of times a phase whose stream stalls is
+// sent again before the phase fails.
+const DefaultStallRetries = 1
+
+// stallNotice is streamed before a stalled phase is sent again, so the
+// output streamed so far is not mistaken for the start of the answer.
+const stallNotice = "\n\n[stream stalled; retrying]\n\n"
+
+// PhaseFallbackRouter returns the provider and model a routing profile falls
+// back to, such as *provider.Router. A phase whose stream stalls moves to
+// its profile's fallback.
+type PhaseFallbackRouter interface {
+	FallbackProvider(ctx context.Context, profile string) (ports.ProviderPort, string, error)
+}
+
+// stallFallbackFunc returns the provider and model to send a request to
+// once its stream stalled on model of stalled, or false to send it to the
+// same one again.
+type stallFallbackFunc func(ctx context.Context, stalled ports.ProviderPort, model string) (ports.ProviderPort, string, bool)
+
+// phaseStallFallback returns the stall fallback of phase: its routing
+// profile's fallback, resolved like a phase pinned to that provider and
+// model, so that selectors such as budgets still apply to it. moved is
+// called when the phase moves, one call at a time. Without a router,
+// stalled phases are sent to the same provider again.
+func phaseStallFallback(
+	router PhaseFallbackRouter,
+	selector PhaseProviderSelector,
+	phase *skill.Phase,
+	moved func(p ports.ProviderPort, model string),
+) stallFallbackFunc {
+	if router == nil {
+		return nil
+	}
+	return func(ctx context.Context, stalled ports.ProviderPort, model string) (ports.ProviderPort, string, bool) {
+		p, fallbackModel, err := router.FallbackProvider(ctx, cmp.Or(phase.RoutingProfile, skill.DefaultRoutingProfile))
+		if err != nil || (p.Info().Name == stalled.Info().Name && fallbackModel == model) {
+			return nil, "", false
+		}
+
+		pinned := *phase
+		pinned.Provider, pinned.PinSoft, pinned.Model = p.Info().Name, false, fallbackModel
+		p, fallbackModel, err = resolvePhaseProvider(ctx, selector, p, &pinned, fallbackModel)
+		if err != nil {
+			return nil, "", false
+		}
+		if moved != nil {
+			moved(p, fallbackModel)
+		}
+		return p, fallbackModel, true
+	}
+}
+
+// retryStalled returns the completion of a phase on provider, made with
+// complete, that sends the request again up to DefaultStallRetries times
+// when its stream stalls (ports.ErrStreamStalled): to the provider and model
+// fallback returns, if any, and otherwise to the same provider. Once the
+// phase moved, its later requests for the stalled model go to the fallback
+// too. restart is called before each retry to discard the content streamed
+// so far. Token usage of stalled attempts is unknown and not counted.
+func retryStalled(provider ports.ProviderPort, complete func(ports.ProviderPort) completeFunc, fallback stallFallbackFunc, restart func()) completeFunc {
+	var (
+		mu                          sync.Mutex // Long-context phases send requests at once
+		stalledModel, fallbackModel string
+	)
+	current := func(req ports.CompletionRequest) (ports.ProviderPort, ports.CompletionRequest) {
+		mu.Lock()
+		defer mu.Unlock()
+		if stalledModel != "" && req.ModelID == stalledModel {
+			req.ModelID = fallbackModel
+		}
+		return provider, req
+	}
+
+	return func(ctx context.Context, req ports.CompletionRequest) (*ports.CompletionResponse, error) {
+		p, req := current(req)
+		resp, err := complete(p)(ctx, req)
+		for attempt := 0; attempt < DefaultStallRetries && errors.Is(err, ports.ErrStreamStalled) && ctx.Err() == nil; attempt++ {
+			log := logging.FromContext(ctx)
+			mu.Lock()
+			if next, model, ok := fallback.resolve(ctx, p, req.ModelID); ok {
+				log.WarnContext(ctx, "falling back from stalled stream", "model", req.ModelID, "fallback_provider", next.Info().Name, "fallback_model", model, "error", err)
+				stalledModel, fallbackModel = req.ModelID, model
+				provider, p, req.ModelID = next, next, model
+			} else {
+				log.WarnContext(ctx, "retrying stalled stream", "model", req.ModelID, "error", err)
+			}
+			mu.Unlock()
+			restart()
+			resp, err = complete(p)(ctx, req)
+		}
+		return resp, err
+	}
+}
+
+// resolve returns the fallback of a stalled request, or false if there is
+// none.
+func (f stallFallbackFunc) resolve(ctx context.Context, stalled ports.ProviderPort, model string) (ports.ProviderPort, string, bool) {
+	if f == nil {
+		return nil, "", false
+	}
+	return f(ctx, stalled, model)
+}
+
+// providerComplete returns the completion of p.
+func providerComplete(p ports.ProviderPort) completeFunc {
+	return p.Complete
+}
+
+// stalledTo records a phase's move to p and model after its stream
+// stalled, with a warning.
+func (r *PhaseResult) stalledTo(p ports.ProviderPort, model string) {
+	r.ProviderUsed = p.Info().Name
+	r.Warnings = append(r.Warnings, fmt.Sprintf("stream stalled; fell back to %s on %s", model, r.ProviderUsed))
+}
@@ -0,0 +1,127 @@
+package workflow
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/jbctechsolutions/skillrunner/internal/application/ports"
+	"github.com/jbctechsolutions/skillrunner/internal/domain/skill"
+)
+
+func TestRetryStalled(t *testing.T) {
+	calls, restarts := 0, 0
+	stream := retryStalled(newMockProvider(), func(ports.ProviderPort) completeFunc {
+		return func(context.Context, ports.CompletionRequest) (*ports.CompletionResponse, error) {
+			calls++
+			if calls == 1 {
+				return nil, ports.ErrStreamStalled
+			}
+			return &ports.CompletionResponse{Content: "done"}, nil
+		}
+	}, nil, func() { restarts++ })
+
+	resp, err := stream(context.Background(), ports.CompletionRequest{})
+	if err != nil || resp.Content != "done" {
+		t.Fatalf("stream() = %v, %v; want the retried response", resp, err)
+	}
+	if calls != 2 || restarts != 1 {
+		t.Errorf("calls, restarts = %d, %d; want 2, 1", calls, restarts)
+	}
+}
+
+func TestRetryStalled_GivesUp(t *testing.T) {
+	calls := 0
+	stalled := retryStalled(newMockProvider(), func(ports.ProviderPort) completeFunc {
+		return func(context.Context, ports.CompletionRequest) (*ports.CompletionResponse, error) {
+			calls++
+			return nil, ports.ErrStreamStalled
+		}
+	}, nil, func() {})
+	if _, err := stalled(context.Background(), ports.CompletionRequest{}); !errors.Is(err, ports.ErrStreamStalled) {
+		t.Errorf("error = %v, want the stall after the retries", err)
+	}
+	if calls != DefaultStallRetries+1 {
+		t.Errorf("calls = %d, want %d", calls, DefaultStallRetries+1)
+	}
+
+	calls = 0
+	failing := retryStalled(newMockProvider(), func(ports.ProviderPort) completeFunc {
+		return func(context.Context, ports.CompletionRequest) (*ports.CompletionResponse, error) {
+			calls++
+			return nil, errors.New("HTTP 500")
+		}
+	}, nil, func() {})
+	if _, err := failing(context.Background(), ports.CompletionRequest{}); err == nil || calls != 1 {
+		t.Errorf("error, calls = %v, %d; want other errors returned without a retry", err, calls)
+	}
+}
+
+func TestRetryStalled_FallsBack(t *testing.T) {
+	local := &namedMockProvider{mockProvider: newMockProvider(), name: "ollama"}
+	cloud := &namedMockProvider{mockProvider: newMockProvider(), name: "groq"}
+	fallback := func(_ context.Context, stalled ports.ProviderPort, model string) (ports.ProviderPort, string, bool) {
+		return cloud, "llama-3.1-8b", stalled == local && model == "llama3:8b"
+	}
+
+	var sent []string
+	complete := retryStalled(local, func(p ports.ProviderPort) completeFunc {
+		return func(_ context.Context, req ports.CompletionRequest) (*ports.CompletionResponse, error) {
+			sent = append(sent, p.Info().Name+"/"+req.ModelID)
+			if p == local {
+				return nil, ports.ErrStreamStalled
+			}
+			return &ports.CompletionResponse{Content: "done", ModelUsed: req.ModelID}, nil
+		}
+	}, fallback, func() {})
+
+	for range 2 {
+		if resp, err := complete(context.Background(), ports.CompletionRequest{ModelID: "llama3:8b"}); err != nil || resp.ModelUsed != "llama-3.1-8b" {
+			t.Fatalf("complete() = %v, %v; want the fallback's response", resp, err)
+		}
+	}
+	// Once moved, requests go to the fallback directly
+	if got := strings.Join(sent, ", "); got != "ollama/llama3:8b, groq/llama-3.1-8b, groq/llama-3.1-8b" {
+		t.Errorf("requests sent to %s", got)
+	}
+}
+
+// stubFallbacks falls every profile back to one provider and model.
+type stubFallbacks struct {
+	provider ports.ProviderPort
+	model    string
+}
+
+func (s stubFallbacks) FallbackProvider(context.Context, string) (ports.ProviderPort, string, error) {
+	return s.provider, s.model, nil
+}
+
+func TestExecutor_StalledPhaseFallsBack(t *testing.T) {
+	local := &namedMockProvider{mockProvider: newMockProvider(), name: "ollama"}
+	local.completeFunc = func(context.Context, ports.CompletionRequest) (*ports.CompletionResponse, error) {
+		return nil, ports.ErrStreamStalled
+	}
+	cloud := &namedMockProvider{mockProvider: newMockProvider(), name: "groq", models: []string{"llama-3.1-8b"}}
+
+	config := DefaultExecutorConfig()
+	config.Fallbacks = stubFallbacks{provider: cloud, model: "llama-3.1-8b"}
+	config.ProviderSelector = &stubSelector{pinned: cloud}
+	phase := createTestPhase(t, "draft", "Draft", "Draft {{._input}}", nil)
+	s := createTestSkill(t, []skill.Phase{phase})
+
+	result, err := NewExecutor(local, config).Execute(context.Background(), s, "notes")
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	pr := result.PhaseResults["draft"]
+	if pr.Status != PhaseStatusCompleted || pr.ProviderUsed != "groq" || pr.ModelUsed != "llama-3.1-8b" {
+		t.Errorf("draft: %s on %s/%s (%v), want completed on the fallback", pr.Status, pr.ProviderUsed, pr.ModelUsed, pr.Error)
+	}
+	if local.callCount.Load() != 1 || cloud.callCount.Load() != 1 {
+		t.Errorf("calls = %d local, %d fallback; want 1 each", local.callCount.Load(), cloud.callCount.Load())
+	}
+	if len(pr.Warnings) != 1 || !strings.Contains(pr.Warnings[0], "fell back to llama-3.1-8b on groq") {
+		t.Errorf("warnings = %q, want the fallback", pr.Warnings)
+	}
+}
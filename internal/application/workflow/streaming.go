@@ -3,6 +3,7 @@ package workflow
 
 import (
 	"context"
+	"github.com/google/uuid"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -44,8 +45,9 @@ type StreamEvent struct {
 	OutputTokens int
 	TotalTokens  int
 	Timestamp    time.Time
-	PhaseIndex   int // Current phase index (1-based)
-	TotalPhases  int // Total number of phases
+	PhaseIndex   int    // Current phase index (1-based)
+	TotalPhases  int    // Total number of phases
+	ModelUsed    string // For completed events, the model that ran the phase
 }
 
 // StreamCallback is called for each streaming event during execution.
@@ -73,15 +75,42 @@ func NewStreamingExecutor(provider ports.ProviderPort, config ExecutorConfig) St
 		config.Timeout = DefaultExecutorConfig().Timeout
 	}
 
+	phaseExecutor := newStreamingPhaseExecutor(provider, config.MemoryContent)
+	phaseExecutor.selector = config.ProviderSelector
+	phaseExecutor.experiments = config.Experiments
+	phaseExecutor.media = newMediaBackends(provider, config)
+	phaseExecutor.groups = config.ConcurrencyGroups
+	phaseExecutor.sampling = newItemSampling(config)
+	phaseExecutor.windows = config.ContextWindows
+	phaseExecutor.fallbacks = config.Fallbacks
+	phaseExecutor.profiles = config.Profiles
+	phaseExecutor.latency = config.Latency
+
 	return &streamingExecutor{
 		provider:               provider,
 		config:                 config,
-		streamingPhaseExecutor: newStreamingPhaseExecutor(provider, config.MemoryContent),
+		streamingPhaseExecutor: phaseExecutor,
 	}
 }
 
 // ExecuteWithStreaming runs all phases of a skill with streaming callbacks.
 func (e *streamingExecutor) ExecuteWithStreaming(ctx context.Context, s *skill.Skill, input string, callback StreamCallback) (*ExecutionResult, error) {
+	executionID := uuid.New().String()
+	ctx, endLog := logWorkflow(ctx, s, executionID)
+	ctx = auditRun(ctx, s, executionID)
+	ctx = moderateRun(ctx, s)
+	ctx, end := traceWorkflow(ctx, s)
+	result, err := e.executeWithStreaming(ctx, s, input, callback)
+	if result != nil && result.ExecutionID == "" {
+		result.ExecutionID = executionID
+	}
+	end(result, err)
+	endLog(result, err)
+	return result, err
+}
+
+// executeWithStreaming implements ExecuteWithStreaming.
+func (e *streamingExecutor) executeWithStreaming(ctx context.Context, s *skill.Skill, input string, callback StreamCallback) (*ExecutionResult, error) {
 	if s == nil {
 		return nil, errors.NewError(errors.CodeValidation, "skill is required", nil)
 	}
@@ -142,14 +171,16 @@ func (e *streamingExecutor) ExecuteWithStreaming(ctx context.Context, s *skill.S
 	}
 
 	// Track outputs from previous phases and token counts
-	phaseOutputs := make(map[string]string)
-	phaseOutputs["_input"] = input
+	phaseOutputs := runVariables(input, e.config.InputFiles)
 	var totalInputTokens, totalOutputTokens int64
 	phaseCounter := 0
 
-	// Execute batches sequentially, phases within each batch in parallel
-	for _, batch := range batches {
-		if err := e.executeBatchWithStreaming(ctx, dag, batch, result, phaseOutputs, callback, &totalInputTokens, &totalOutputTokens, &phaseCounter, len(phases)); err != nil {
+	// Execute batches sequentially, phases within each batch in parallel,
+	// warming the models of the next batch meanwhile
+	warmer := newModelWarmer(e.provider, e.config)
+	for batchIndex, batch := range batches {
+		warmer.warmAhead(ctx, dag, batches, batchIndex)
+		if err := e.executeBatchWithStreaming(ctx, dag, batchIndex, batch, result, phaseOutputs, callback, &totalInputTokens, &totalOutputTokens, &phaseCounter, len(phases)); err != nil {
 			result.Status = PhaseStatusFailed
 			result.Error = err
 			result.EndTime = time.Now()
@@ -180,6 +211,15 @@ func (e *streamingExecutor) ExecuteWithStreaming(ctx context.Context, s *skill.S
 	// Aggregate token counts
 	result.TotalTokens = int(atomic.LoadInt64(&totalInputTokens) + atomic.LoadInt64(&totalOutputTokens))
 
+	// Save Markdown tables of phases with extract_tables
+	if err := extractPhaseTables(phases, result, e.config.ArtifactDir); err != nil {
+		result.Status = PhaseStatusFailed
+		result.Error = err
+		result.EndTime = time.Now()
+		result.Duration = result.EndTime.Sub(result.StartTime)
+		return result, nil
+	}
+
 	result.Status = PhaseStatusCompleted
 	result.EndTime = time.Now()
 	result.Duration = result.EndTime.Sub(result.StartTime)
@@ -203,6 +243,7 @@ func (e *streamingExecutor) ExecuteWithStreaming(ctx context.Context, s *skill.S
 func (e *streamingExecutor) executeBatchWithStreaming(
 	ctx context.Context,
 	dag *workflow.DAG,
+	batchIndex int,
 	batch []string,
 	result *ExecutionResult,
 	phaseOutputs map[string]string,
@@ -230,6 +271,7 @@ func (e *streamingExecutor) executeBatchWithStreaming(
 		wg.Add(1)
 		go func(p *skill.Phase) {
 			defer wg.Done()
+			queuedAt := time.Now()
 
 			select {
 			case sem <- struct{}{}:
@@ -288,13 +330,18 @@ func (e *streamingExecutor) executeBatchWithStreaming(
 			}
 
 			// Execute the phase with streaming
-			phaseResult := e.streamingPhaseExecutor.ExecuteWithStreaming(ctx, p, dependencyOutputs, phaseCallback)
+			phaseCtx, endPhase := tracePhase(auditPhase(ctx, p.ID), p)
+			phaseResult := e.streamingPhaseExecutor.ExecuteWithStreaming(phaseCtx, p, dependencyOutputs, phaseCallback)
+			endPhase(phaseResult)
+			logPhase(phaseCtx, phaseResult)
+			phaseResult.Batch = batchIndex
+			phaseResult.QueuedAt = queuedAt
 
 			// Store result
 			mu.Lock()
 			result.PhaseResults[p.ID] = phaseResult
 
-			if phaseResult.Status == PhaseStatusCompleted {
+			if phaseResult.passesOutput() {
 				phaseOutputs[p.ID] = phaseResult.Output
 				atomic.AddInt64(totalInputTokens, int64(phaseResult.InputTokens))
 				atomic.AddInt64(totalOutputTokens, int64(phaseResult.OutputTokens))
@@ -310,6 +357,7 @@ func (e *streamingExecutor) executeBatchWithStreaming(
 						TotalTokens:  int(atomic.LoadInt64(totalInputTokens) + atomic.LoadInt64(totalOutputTokens)),
 						PhaseIndex:   currentPhaseIndex,
 						TotalPhases:  totalPhases,
+						ModelUsed:    phaseResult.ModelUsed,
 						Timestamp:    time.Now(),
 					})
 
@@ -352,11 +400,9 @@ func (e *streamingExecutor) executeBatchWithStreaming(
 // gatherDependencyOutputs collects outputs from dependent phases.
 func (e *streamingExecutor) gatherDependencyOutputs(dag *workflow.DAG, phaseID string, phaseOutputs map[string]string) map[string]string {
 	deps := dag.GetDependencies(phaseID)
-	outputs := make(map[string]string, len(deps)+1)
+	outputs := make(map[string]string, len(deps)+len(skill.FileVariables)+1)
 
-	if input, ok := phaseOutputs["_input"]; ok {
-		outputs["_input"] = input
-	}
+	copyRunVariables(outputs, phaseOutputs)
 
 	for _, depID := range deps {
 		if output, ok := phaseOutputs[depID]; ok {
@@ -4,7 +4,6 @@ package workflow
 import (
 	"context"
 	"strings"
-	"text/template"
 	"time"
 
 	"github.com/jbctechsolutions/skillrunner/internal/application/ports"
@@ -18,6 +17,15 @@ type PhaseStreamCallback func(chunk string, inputTokens, outputTokens int) error
 type streamingPhaseExecutor struct {
 	provider      ports.ProviderPort
 	memoryContent string
+	selector      PhaseProviderSelector // optional per-phase provider selection
+	experiments   PhaseExperimentRouter // optional A/B experiment assignment
+	media         mediaBackends         // backends for transcription and image phases
+	groups        *ConcurrencyGroups    // optional limits of the phases' concurrency groups
+	sampling      itemSampling          // optional sample of the items of foreach phases
+	windows       ContextWindowChecker  // optional check of requests against model context windows
+	fallbacks     PhaseFallbackRouter   // optional fallbacks of phases whose streams stall
+	profiles      PhaseProfileRouter    // optional resolution of custom routing profiles
+	latency       LatencyRecorder       // optional record of the latency of requests
 }
 
 // newStreamingPhaseExecutor creates a new streaming phase executor.
@@ -35,6 +43,41 @@ func (e *streamingPhaseExecutor) ExecuteWithStreaming(
 	dependencyOutputs map[string]string,
 	callback PhaseStreamCallback,
 ) *PhaseResult {
+	render := func(t string) (string, error) {
+		return renderPrompt(t, dependencyOutputs, phase.Partials)
+	}
+	if result := conditionResult(phase, render); result != nil {
+		return result
+	}
+	if phase.ForEach != nil {
+		// Items may run at once, so their aggregated output arrives in one
+		// piece
+		result := runForEach(ctx, phase, dependencyOutputs, render, e.sampling, e.Execute)
+		if callback != nil && result.Status == PhaseStatusCompleted {
+			_ = callback(result.Output, 0, 0)
+			_ = callback("", result.InputTokens, result.OutputTokens)
+		}
+		return result
+	}
+
+	release, err := e.groups.Acquire(ctx, phase.ConcurrencyGroup)
+	if err != nil {
+		return failedPhaseResult(phase, time.Now(), err)
+	}
+	defer release()
+
+	if !phase.IsCompletion() {
+		// Transcripts and image paths arrive in one piece
+		result := timedPhase(ctx, phase.Timeout, func(ctx context.Context) *PhaseResult {
+			return e.media.execute(ctx, phase, render)
+		})
+		if callback != nil && result.Status == PhaseStatusCompleted {
+			_ = callback(result.Output, 0, 0)
+			_ = callback("", 0, 0)
+		}
+		return result
+	}
+
 	result := &PhaseResult{
 		PhaseID:   phase.ID,
 		PhaseName: phase.Name,
@@ -42,8 +85,18 @@ func (e *streamingPhaseExecutor) ExecuteWithStreaming(
 		StartTime: time.Now(),
 	}
 
-	// Build the prompt from the template
-	prompt, err := e.buildPrompt(phase.PromptTemplate, dependencyOutputs)
+	// Build the prompt and messages
+	prompt, messages, err := e.buildRequest(phase, dependencyOutputs)
+	if err != nil {
+		result.Status = PhaseStatusFailed
+		result.Error = err
+		result.EndTime = time.Now()
+		result.Duration = result.EndTime.Sub(result.StartTime)
+		return result
+	}
+
+	// Resolve the provider (honoring phase pins and experiments) and model
+	provider, modelID, err := resolveProfileProvider(ctx, e.profiles, e.selector, e.provider, phase, e.selectModel)
 	if err != nil {
 		result.Status = PhaseStatusFailed
 		result.Error = err
@@ -51,13 +104,25 @@ func (e *streamingPhaseExecutor) ExecuteWithStreaming(
 		result.Duration = result.EndTime.Sub(result.StartTime)
 		return result
 	}
+	provider, modelID, assignment := applyExperiment(ctx, e.experiments, phase, provider, modelID)
+	result.ProviderUsed = provider.Info().Name
+	result.Prompt = prompt
+	result.tagExperiment(assignment)
 
 	// Build the completion request
 	req := ports.CompletionRequest{
-		ModelID:     e.selectModel(phase.RoutingProfile),
-		Messages:    e.buildMessages(prompt, dependencyOutputs),
-		MaxTokens:   phase.MaxTokens,
-		Temperature: phase.Temperature,
+		ModelID:        modelID,
+		Messages:       messages,
+		MaxTokens:      phase.MaxTokens,
+		Temperature:    phase.Temperature,
+		ResponseFormat: phaseResponseFormat(phase),
+	}
+	if err := checkContextWindow(ctx, e.windows, phase, provider, req); err != nil {
+		result.Status = PhaseStatusFailed
+		result.Error = err
+		result.EndTime = time.Now()
+		result.Duration = result.EndTime.Sub(result.StartTime)
+		return result
 	}
 
 	// Accumulate the full content for the result
@@ -75,8 +140,36 @@ func (e *streamingPhaseExecutor) ExecuteWithStreaming(
 		return nil
 	}
 
-	// Call the provider with streaming
-	resp, err := e.provider.Stream(ctx, req, streamCallback)
+	// A stalled stream or failed phase is sent again, starting the
+	// accumulated content over
+	restartWith := func(notice string) func() {
+		return func() {
+			fullContent.Reset()
+			if callback != nil {
+				_ = callback(notice, lastInputTokens, 0)
+			}
+		}
+	}
+	fallback := phaseStallFallback(e.fallbacks, e.selector, phase, result.stalledTo)
+	stream := retryStalled(provider, timed(e.latency, func(p ports.ProviderPort) completeFunc {
+		return func(ctx context.Context, req ports.CompletionRequest) (*ports.CompletionResponse, error) {
+			return p.Stream(ctx, req, streamCallback)
+		}
+	}), fallback, restartWith(stallNotice))
+
+	// Call the provider with streaming (validating and retrying json output
+	// and outputs that echo the input, and moving stalled streams to the
+	// phase's fallback). Long-context summaries are not streamed.
+	models := profileModels(e.selectModel, provider, e.provider)
+	complete := windowed(phase, models, retryStalled(provider, timed(e.latency, providerComplete), fallback, func() {}), stream)
+	resp, warnings, err := retryPhase(ctx, phase.Retry, req, escalationModel(phase, models), timedAttempt(phase.Timeout, func(ctx context.Context, req ports.CompletionRequest) (*ports.CompletionResponse, string, error) {
+		resp, err := completePhase(ctx, phase, req, complete)
+		if err != nil {
+			return nil, "", err
+		}
+		return guardSimilarity(ctx, phase, req, dependencyOutputs, resp, complete)
+	}), restartWith(retryNotice))
+	result.Warnings = append(result.Warnings, warnings...)
 	if err != nil {
 		result.Status = PhaseStatusFailed
 		result.Error = err
@@ -85,9 +178,10 @@ func (e *streamingPhaseExecutor) ExecuteWithStreaming(
 		return result
 	}
 
-	// Use the response content (which should match accumulated content)
+	// Use the response content (which should match accumulated content,
+	// before glossary corrections)
 	result.Status = PhaseStatusCompleted
-	result.Output = resp.Content
+	result.setOutput(phase, resp.Content)
 	result.InputTokens = resp.InputTokens
 	result.OutputTokens = resp.OutputTokens
 	result.ModelUsed = resp.ModelUsed
@@ -107,56 +201,27 @@ func (e *streamingPhaseExecutor) Execute(ctx context.Context, phase *skill.Phase
 	return e.ExecuteWithStreaming(ctx, phase, dependencyOutputs, nil)
 }
 
-// buildPrompt renders the phase's prompt template with the dependency outputs.
-// Phase outputs are also available via {{.phases.phaseid}} for better organization.
-func (e *streamingPhaseExecutor) buildPrompt(templateStr string, data map[string]string) (string, error) {
-	templateData := make(map[string]any, len(data)+1)
-	phases := make(map[string]string)
-
-	for k, v := range data {
-		templateData[k] = v
-		// Add non-special keys to the phases map for nested access
-		if !strings.HasPrefix(k, "_") {
-			phases[k] = v
-		}
-	}
-
-	// Add phases map for nested template access: {{.phases.phaseid}}
-	if len(phases) > 0 {
-		templateData["phases"] = phases
-	}
-
-	funcMap := template.FuncMap{
-		"get": func(key string) string {
-			if v, ok := data[key]; ok {
-				return v
-			}
-			return ""
-		},
-	}
-
-	tmpl, err := template.New("prompt").Funcs(funcMap).Parse(templateStr)
+// buildRequest renders the phase prompt and builds the request messages,
+// trimmed to the phase's context budget, if any.
+func (e *streamingPhaseExecutor) buildRequest(phase *skill.Phase, dependencyOutputs map[string]string) (string, []ports.Message, error) {
+	memory, dependencyOutputs, prompt, err := fitContextBudget(phase, e.memoryContent, dependencyOutputs, func(data map[string]string) (string, error) {
+		return renderPrompt(phase.PromptTemplate, data, phase.Partials)
+	})
 	if err != nil {
-		return "", err
-	}
-
-	var buf strings.Builder
-	if err := tmpl.Execute(&buf, templateData); err != nil {
-		return "", err
+		return "", nil, err
 	}
-
-	return buf.String(), nil
+	return prompt, e.buildMessages(memory, prompt, dependencyOutputs), nil
 }
 
 // buildMessages constructs the message array for the LLM request.
-func (e *streamingPhaseExecutor) buildMessages(prompt string, dependencyOutputs map[string]string) []ports.Message {
+func (e *streamingPhaseExecutor) buildMessages(memory, prompt string, dependencyOutputs map[string]string) []ports.Message {
 	messages := make([]ports.Message, 0, 3)
 
 	// Add memory context first (if available) - highest priority
-	if e.memoryContent != "" {
+	if memory != "" {
 		messages = append(messages, ports.Message{
 			Role:    "system",
-			Content: "Project Memory:\n\n" + e.memoryContent,
+			Content: "Project Memory:\n\n" + memory,
 		})
 	}
 
@@ -168,7 +233,7 @@ func (e *streamingPhaseExecutor) buildMessages(prompt string, dependencyOutputs
 		}
 
 		for id, output := range dependencyOutputs {
-			if id != "_input" && output != "" {
+			if id != "_input" && !skill.IsFileVariable(id) && output != "" {
 				contextParts = append(contextParts, "Previous Phase ("+id+"):\n"+output)
 			}
 		}
@@ -191,16 +256,18 @@ func (e *streamingPhaseExecutor) buildMessages(prompt string, dependencyOutputs
 }
 
 // selectModel returns a model ID based on the routing profile.
-// Maps routing profiles to actual Ollama model names.
+// Maps the built-in routing profiles to actual Ollama model names. Custom
+// profiles map to no model; resolveProfileProvider resolves them through
+// the routing configuration.
 func (e *streamingPhaseExecutor) selectModel(routingProfile string) string {
 	switch routingProfile {
 	case skill.RoutingProfileCheap:
 		return "llama3.2:3b"
 	case skill.RoutingProfilePremium:
 		return "qwen2.5:14b"
-	case skill.RoutingProfileBalanced:
-		fallthrough
-	default:
+	case skill.RoutingProfileBalanced, "":
 		return "llama3:8b"
+	default:
+		return ""
 	}
 }
@@ -0,0 +1,131 @@
+package workflow
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/jbctechsolutions/skillrunner/internal/application/ports"
+	"github.com/jbctechsolutions/skillrunner/internal/domain/skill"
+)
+
+// Sub-skill execution errors.
+var (
+	ErrNoSkillResolver  = errors.New("skill phases need an executor configured with Skills")
+	ErrSubSkillNotFound = errors.New("skill not found")
+	ErrSubSkillOutput   = errors.New("skill has no such phase")
+)
+
+// SkillResolver looks up the skills that skill phases run, such as the
+// skill registry.
+type SkillResolver interface {
+	GetSkill(id string) *skill.Skill
+}
+
+// subSkillRunner runs the skills of skill phases with the configuration of
+// the executor running the phases.
+type subSkillRunner struct {
+	provider ports.ProviderPort
+	config   ExecutorConfig
+}
+
+// execute runs a skill phase: it renders the phase's prompt template as the
+// input of the skill it names, runs that skill, and returns its output with
+// the token usage, cost, artifacts and warnings of all its phases.
+func (r subSkillRunner) execute(ctx context.Context, phase *skill.Phase, render func(string) (string, error)) *PhaseResult {
+	start := time.Now()
+	cfg := phase.SubSkill
+	if r.config.Skills == nil {
+		return failedPhaseResult(phase, start, ErrNoSkillResolver)
+	}
+	child := r.config.Skills.GetSkill(cfg.Skill)
+	if child == nil {
+		return failedPhaseResult(phase, start, fmt.Errorf("%w: %s", ErrSubSkillNotFound, cfg.Skill))
+	}
+	if cycle := SubSkillCycle(child, r.config.Skills); cycle != nil {
+		return failedPhaseResult(phase, start, fmt.Errorf("%w: %s", skill.ErrSubSkillCycle, strings.Join(cycle, " -> ")))
+	}
+	input, err := render(phase.PromptTemplate)
+	if err != nil {
+		return failedPhaseResult(phase, start, fmt.Errorf("failed to render skill input: %w", err))
+	}
+
+	run, err := NewExecutor(r.provider, r.config).Execute(ctx, child, input)
+	result := &PhaseResult{
+		PhaseID:   phase.ID,
+		PhaseName: phase.Name,
+		Status:    PhaseStatusCompleted,
+		Prompt:    input,
+		StartTime: start,
+	}
+	if run != nil {
+		for _, id := range slices.Sorted(maps.Keys(run.PhaseResults)) {
+			pr := run.PhaseResults[id]
+			result.InputTokens += pr.InputTokens
+			result.OutputTokens += pr.OutputTokens
+			result.Cost += pr.Cost
+			result.Artifacts = append(result.Artifacts, pr.Artifacts...)
+			result.ModelUsed = cmp.Or(result.ModelUsed, pr.ModelUsed)
+			result.ProviderUsed = cmp.Or(result.ProviderUsed, pr.ProviderUsed)
+			for _, w := range pr.Warnings {
+				result.Warnings = append(result.Warnings, fmt.Sprintf("%s/%s: %s", cfg.Skill, id, w))
+			}
+		}
+		if err == nil && run.Status != PhaseStatusCompleted {
+			err = cmp.Or(run.Error, fmt.Errorf("skill ended %s", run.Status))
+		}
+	}
+
+	switch {
+	case err != nil:
+		result.Status = PhaseStatusFailed
+		result.Error = fmt.Errorf("skill %s: %w", cfg.Skill, err)
+	case cfg.Output == "":
+		result.setOutput(phase, run.FinalOutput)
+	case run.PhaseResults[cfg.Output] == nil:
+		result.Status = PhaseStatusFailed
+		result.Error = fmt.Errorf("%w: %s has no phase %s", ErrSubSkillOutput, cfg.Skill, cfg.Output)
+	default:
+		result.setOutput(phase, run.PhaseResults[cfg.Output].Output)
+	}
+	result.EndTime = time.Now()
+	result.Duration = result.EndTime.Sub(result.StartTime)
+	return result
+}
+
+// SubSkillCycle returns the skill IDs through which s, or a skill it runs,
+// runs itself through skill phases, starting and ending with the repeated
+// skill, or nil if no skill does. Skills resolve cannot find are ignored.
+func SubSkillCycle(s *skill.Skill, resolve SkillResolver) []string {
+	var (
+		path []string
+		done = make(map[string]bool)
+	)
+	var visit func(s *skill.Skill) []string
+	visit = func(s *skill.Skill) []string {
+		id := s.ID()
+		if i := slices.Index(path, id); i >= 0 {
+			return append(slices.Clone(path[i:]), id)
+		}
+		if done[id] {
+			return nil
+		}
+		path = append(path, id)
+		for _, sub := range s.SubSkills() {
+			if next := resolve.GetSkill(sub); next != nil {
+				if cycle := visit(next); cycle != nil {
+					return cycle
+				}
+			}
+		}
+		path = path[:len(path)-1]
+		done[id] = true
+		return nil
+	}
+	return visit(s)
+}
@@ -0,0 +1,132 @@
+package workflow
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/jbctechsolutions/skillrunner/internal/application/ports"
+	"github.com/jbctechsolutions/skillrunner/internal/domain/skill"
+)
+
+// skillMap is a SkillResolver over a fixed set of skills.
+type skillMap map[string]*skill.Skill
+
+func (m skillMap) GetSkill(id string) *skill.Skill { return m[id] }
+
+// newTestSkill returns a skill with the given ID and phases.
+func newTestSkill(t *testing.T, id string, phases ...skill.Phase) *skill.Skill {
+	t.Helper()
+	s, err := skill.NewSkill(id, id, "1.0.0", phases)
+	if err != nil {
+		t.Fatalf("failed to create skill %s: %v", id, err)
+	}
+	return s
+}
+
+// callPhase returns a phase that runs the skill id on the input.
+func callPhase(t *testing.T, phaseID, id string) skill.Phase {
+	p := createTestPhase(t, phaseID, phaseID, "{{._input}}", nil)
+	p.WithSubSkill(&skill.SubSkillConfig{Skill: id})
+	return p
+}
+
+func TestExecutor_SubSkill(t *testing.T) {
+	provider := newMockProvider()
+	provider.completeFunc = func(_ context.Context, req ports.CompletionRequest) (*ports.CompletionResponse, error) {
+		prompt := req.Messages[len(req.Messages)-1].Content
+		return &ports.CompletionResponse{Content: "<" + prompt + ">", InputTokens: 10, OutputTokens: 5, ModelUsed: "m"}, nil
+	}
+
+	lint := createTestPhase(t, "lint", "Lint", "Lint {{._input}}", nil)
+	report := createTestPhase(t, "report", "Report", "Report {{.lint}}", []string{"lint"})
+	review := newTestSkill(t, "review", lint, report)
+
+	diff := createTestPhase(t, "diff", "Diff", "Diff {{._input}}", nil)
+	call := createTestPhase(t, "review", "Review", "{{.diff}}", []string{"diff"})
+	call.WithSubSkill(&skill.SubSkillConfig{Skill: "review"})
+	lintOnly := createTestPhase(t, "lint_only", "Lint only", "{{._input}}", nil)
+	lintOnly.WithSubSkill(&skill.SubSkillConfig{Skill: "review", Output: "lint"})
+	summary := createTestPhase(t, "summary", "Summary", "Summary {{.review}} {{.lint_only}}", []string{"review", "lint_only"})
+	s := createTestSkill(t, []skill.Phase{diff, call, lintOnly, summary})
+
+	config := DefaultExecutorConfig()
+	config.Skills = skillMap{"review": review}
+	result, err := NewExecutor(provider, config).Execute(context.Background(), s, "main")
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	pr := result.PhaseResults["review"]
+	if pr.Status != PhaseStatusCompleted || pr.Output != "<Report <Lint <Diff main>>>" {
+		t.Errorf("review = %s %q, want the sub-skill's final output on the diff", pr.Status, pr.Output)
+	}
+	if pr.InputTokens != 20 || pr.OutputTokens != 10 || pr.ModelUsed != "m" {
+		t.Errorf("review tokens = %d/%d on %q, want the sub-skill's 20/10", pr.InputTokens, pr.OutputTokens, pr.ModelUsed)
+	}
+	if got := result.PhaseResults["lint_only"].Output; got != "<Lint main>" {
+		t.Errorf("lint_only = %q, want the output of the sub-skill's lint phase", got)
+	}
+	// The diff and summary phases, and two sub-skill runs of two phases
+	if want := 6 * 15; result.TotalTokens != want {
+		t.Errorf("TotalTokens = %d, want %d with the sub-skills' tokens", result.TotalTokens, want)
+	}
+}
+
+func TestExecutor_SubSkillErrors(t *testing.T) {
+	provider := newMockProvider()
+	other := newTestSkill(t, "other", createTestPhase(t, "a", "A", "{{._input}}", nil))
+
+	tests := []struct {
+		name   string
+		skills SkillResolver
+		phase  skill.Phase
+		want   error
+	}{
+		{"no resolver", nil, callPhase(t, "call", "other"), ErrNoSkillResolver},
+		{"unknown skill", skillMap{}, callPhase(t, "call", "other"), ErrSubSkillNotFound},
+		{"unknown output", skillMap{"other": other}, func() skill.Phase {
+			p := callPhase(t, "call", "other")
+			p.SubSkill.Output = "missing"
+			return p
+		}(), ErrSubSkillOutput},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := DefaultExecutorConfig()
+			config.Skills = tt.skills
+			result, _ := NewExecutor(provider, config).Execute(context.Background(), createTestSkill(t, []skill.Phase{tt.phase}), "x")
+			if pr := result.PhaseResults["call"]; pr.Status != PhaseStatusFailed || !errors.Is(pr.Error, tt.want) {
+				t.Errorf("call = %s %v, want %v", pr.Status, pr.Error, tt.want)
+			}
+		})
+	}
+}
+
+func TestSubSkillCycle(t *testing.T) {
+	a := newTestSkill(t, "a", callPhase(t, "p", "b"))
+	b := newTestSkill(t, "b", callPhase(t, "p", "c"), callPhase(t, "q", "missing"))
+	c := newTestSkill(t, "c", callPhase(t, "p", "b"))
+	leaf := newTestSkill(t, "leaf", createTestPhase(t, "p", "P", "{{._input}}", nil))
+	skills := skillMap{"a": a, "b": b, "c": c, "leaf": leaf}
+
+	if got := SubSkillCycle(a, skills); !slices.Equal(got, []string{"b", "c", "b"}) {
+		t.Errorf("SubSkillCycle(a) = %v, want b -> c -> b", got)
+	}
+	if got := SubSkillCycle(leaf, skills); got != nil {
+		t.Errorf("SubSkillCycle(leaf) = %v, want nil", got)
+	}
+
+	provider := newMockProvider()
+	config := DefaultExecutorConfig()
+	config.Skills = skills
+	result, _ := NewExecutor(provider, config).Execute(context.Background(), createTestSkill(t, []skill.Phase{callPhase(t, "call", "a")}), "x")
+	if pr := result.PhaseResults["call"]; !errors.Is(pr.Error, skill.ErrSubSkillCycle) || !strings.Contains(pr.Error.Error(), "b -> c -> b") {
+		t.Errorf("call error = %v, want the cycle", pr.Error)
+	}
+	if got := provider.callCount.Load(); got != 0 {
+		t.Errorf("provider called %d times, want the cycle found before running", got)
+	}
+}
@@ -0,0 +1,347 @@
+package workflow
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jbctechsolutions/skillrunner/internal/application/ports"
+	"github.com/jbctechsolutions/skillrunner/internal/domain/skill"
+)
+
+// Parameters a sweep can vary. Each applies to every phase of the skill.
+const (
+	SweepTemperature = "temperature"
+	SweepModel       = "model"
+	SweepMaxTokens   = "max_tokens"
+	SweepProfile     = "profile"
+)
+
+// DefaultSweepParallel is how many combinations a sweep runs at once by default.
+const DefaultSweepParallel = 2
+
+// Statuses of a sweep result besides the phase statuses.
+const (
+	SweepStatusOverBudget = "over_budget" // Not run: the sweep's cost cap was reached
+)
+
+// SweepParam is a parameter and the values a sweep tries for it.
+type SweepParam struct {
+	Name   string
+	Values []string
+}
+
+// ParseSweepParam parses a parameter of the form name=value1,value2.
+func ParseSweepParam(s string) (SweepParam, error) {
+	name, list, ok := strings.Cut(s, "=")
+	name = strings.TrimSpace(name)
+	if !ok || name == "" {
+		return SweepParam{}, fmt.Errorf("invalid parameter %q: expected name=value1,value2", s)
+	}
+
+	var values []string
+	for v := range strings.SplitSeq(list, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
+	}
+	if len(values) == 0 {
+		return SweepParam{}, fmt.Errorf("parameter %s has no values", name)
+	}
+
+	param := SweepParam{Name: name, Values: values}
+	for _, v := range values {
+		if err := validateSweepValue(name, v); err != nil {
+			return SweepParam{}, err
+		}
+	}
+	return param, nil
+}
+
+// validateSweepValue checks a value of a sweep parameter.
+func validateSweepValue(name, value string) error {
+	switch name {
+	case SweepTemperature:
+		t, err := strconv.ParseFloat(value, 32)
+		if err != nil || t < 0 || t > 2 {
+			return fmt.Errorf("invalid temperature %q: must be a number between 0 and 2", value)
+		}
+	case SweepMaxTokens:
+		n, err := strconv.Atoi(value)
+		if err != nil || n <= 0 {
+			return fmt.Errorf("invalid max_tokens %q: must be a positive integer", value)
+		}
+	case SweepProfile:
+		if !skill.IsValidProfileName(value) {
+			return fmt.Errorf("invalid profile %q", value)
+		}
+	case SweepModel:
+	default:
+		return fmt.Errorf("unknown parameter %q: must be temperature, model, max_tokens or profile", name)
+	}
+	return nil
+}
+
+// SweepSetting is the value of one parameter in a combination.
+type SweepSetting struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// SweepCombination is one point of a sweep: a value for each parameter.
+type SweepCombination []SweepSetting
+
+// Get returns the value of the named parameter, or "" if it is not set.
+func (c SweepCombination) Get(name string) string {
+	for _, s := range c {
+		if s.Name == name {
+			return s.Value
+		}
+	}
+	return ""
+}
+
+// String formats the combination as name=value pairs.
+func (c SweepCombination) String() string {
+	parts := make([]string, len(c))
+	for i, s := range c {
+		parts[i] = s.Name + "=" + s.Value
+	}
+	return strings.Join(parts, " ")
+}
+
+// SweepCombinations returns the cross-product of the parameters' values, the
+// last parameter varying fastest.
+func SweepCombinations(params []SweepParam) []SweepCombination {
+	combos := []SweepCombination{{}}
+	for _, p := range params {
+		next := make([]SweepCombination, 0, len(combos)*len(p.Values))
+		for _, combo := range combos {
+			for _, v := range p.Values {
+				next = append(next, append(slices.Clone(combo), SweepSetting{Name: p.Name, Value: v}))
+			}
+		}
+		combos = next
+	}
+	return combos
+}
+
+// ApplySweepCombination returns a copy of the skill with the combination's
+// settings applied to every phase. A model is pinned on each phase.
+func ApplySweepCombination(sk *skill.Skill, combo SweepCombination) (*skill.Skill, error) {
+	phases := sk.Phases()
+	for i := range phases {
+		for _, s := range combo {
+			switch s.Name {
+			case SweepTemperature:
+				t, _ := strconv.ParseFloat(s.Value, 32)
+				phases[i].WithTemperature(float32(t))
+			case SweepMaxTokens:
+				n, _ := strconv.Atoi(s.Value)
+				phases[i].WithMaxTokens(n)
+			case SweepProfile:
+				phases[i].WithRoutingProfile(s.Value)
+			case SweepModel:
+				phases[i].WithModel(s.Value)
+			}
+		}
+	}
+	return sk.WithPhases(phases)
+}
+
+// SweepRunFunc runs the skill with a combination applied. The result's
+// TotalCost must be filled in for the sweep's cost cap to apply.
+type SweepRunFunc func(ctx context.Context, sk *skill.Skill, combo SweepCombination) (*ExecutionResult, error)
+
+// SweepJudgeFunc scores the final output of a run for the given input, from
+// 1 (poor) to 10 (excellent).
+type SweepJudgeFunc func(ctx context.Context, input, output string) (float64, error)
+
+// SweepConfig configures RunSweep.
+type SweepConfig struct {
+	// Parallel is how many combinations run at once (default DefaultSweepParallel).
+	Parallel int
+
+	// MaxCost stops starting combinations once the completed ones cost this
+	// much in USD. 0 means no cap.
+	MaxCost float64
+
+	// Run executes one combination. Required.
+	Run SweepRunFunc
+
+	// Judge optionally scores the output of each completed combination.
+	Judge SweepJudgeFunc
+}
+
+// SweepResult is the outcome of one combination of a sweep.
+type SweepResult struct {
+	Combination SweepCombination `json:"params"`
+	Status      string           `json:"status"`
+	Output      string           `json:"output,omitempty"`
+	Tokens      int              `json:"tokens"`
+	Cost        float64          `json:"cost"`
+	Duration    time.Duration    `json:"duration_ns"`
+	Score       *float64         `json:"score,omitempty"`
+	Error       string           `json:"error,omitempty"`
+}
+
+// SweepReport is the comparison matrix of a sweep: one result per
+// combination, in the order of SweepCombinations.
+type SweepReport struct {
+	SkillID   string        `json:"skill_id"`
+	Params    []string      `json:"param_names"`
+	Results   []SweepResult `json:"results"`
+	TotalCost float64       `json:"total_cost"`
+}
+
+// Best returns the completed result with the highest judge score, or nil
+// if no result was scored.
+func (r *SweepReport) Best() *SweepResult {
+	var best *SweepResult
+	for i := range r.Results {
+		res := &r.Results[i]
+		if res.Score != nil && (best == nil || *res.Score > *best.Score) {
+			best = res
+		}
+	}
+	return best
+}
+
+// RunSweep runs the skill on input once for every combination of params,
+// at most cfg.Parallel at a time, and collects outputs, costs and judge
+// scores into a report.
+func RunSweep(ctx context.Context, sk *skill.Skill, input string, params []SweepParam, cfg SweepConfig) (*SweepReport, error) {
+	if cfg.Run == nil {
+		return nil, errors.New("sweep: no run function")
+	}
+	if cfg.Parallel <= 0 {
+		cfg.Parallel = DefaultSweepParallel
+	}
+
+	combos := SweepCombinations(params)
+	report := &SweepReport{SkillID: sk.ID(), Results: make([]SweepResult, len(combos))}
+	for _, p := range params {
+		report.Params = append(report.Params, p.Name)
+	}
+
+	var (
+		mu    sync.Mutex
+		spent float64
+		wg    sync.WaitGroup
+	)
+	slots := make(chan struct{}, cfg.Parallel)
+
+	for i, combo := range combos {
+		slots <- struct{}{}
+
+		mu.Lock()
+		overBudget := cfg.MaxCost > 0 && spent >= cfg.MaxCost
+		mu.Unlock()
+		switch {
+		case ctx.Err() != nil:
+			<-slots
+			report.Results[i] = SweepResult{Combination: combo, Status: string(PhaseStatusSkipped), Error: ctx.Err().Error()}
+			continue
+		case overBudget:
+			<-slots
+			report.Results[i] = SweepResult{Combination: combo, Status: SweepStatusOverBudget}
+			continue
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-slots }()
+
+			res := runSweepCombination(ctx, sk, input, combo, cfg)
+			mu.Lock()
+			spent += res.Cost
+			mu.Unlock()
+			report.Results[i] = res
+		}()
+	}
+	wg.Wait()
+
+	report.TotalCost = spent
+	return report, nil
+}
+
+// runSweepCombination runs and scores one combination.
+func runSweepCombination(ctx context.Context, sk *skill.Skill, input string, combo SweepCombination, cfg SweepConfig) SweepResult {
+	res := SweepResult{Combination: combo, Status: string(PhaseStatusFailed)}
+
+	variant, err := ApplySweepCombination(sk, combo)
+	if err != nil {
+		res.Error = err.Error()
+		return res
+	}
+
+	result, err := cfg.Run(ctx, variant, combo)
+	if result != nil {
+		res.Status = string(result.Status)
+		res.Output = result.FinalOutput
+		res.Tokens = result.TotalTokens
+		res.Cost = result.TotalCost
+		res.Duration = result.Duration
+		if result.Error != nil {
+			res.Error = result.Error.Error()
+		}
+	}
+	if err != nil {
+		res.Status = string(PhaseStatusFailed)
+		res.Error = err.Error()
+		return res
+	}
+
+	if cfg.Judge != nil && res.Status == string(PhaseStatusCompleted) {
+		score, err := cfg.Judge(ctx, input, res.Output)
+		if err != nil {
+			res.Error = "judge: " + err.Error()
+		} else {
+			res.Score = &score
+		}
+	}
+	return res
+}
+
+// judgeScorePattern finds the score in a judge's reply.
+var judgeScorePattern = regexp.MustCompile(`\d+(\.\d+)?`)
+
+// NewLLMJudge returns a judge that asks model on provider to rate outputs.
+func NewLLMJudge(provider ports.ProviderPort, model string) SweepJudgeFunc {
+	return func(ctx context.Context, input, output string) (float64, error) {
+		score, _, err := judgeOutput(ctx, provider, model, input, output)
+		return score, err
+	}
+}
+
+// judgeOutput asks model on provider to rate output as a response to input,
+// from 1 to 10. It returns the judge's response as well, for pricing.
+func judgeOutput(ctx context.Context, provider ports.ProviderPort, model, input, output string) (float64, *ports.CompletionResponse, error) {
+	resp, err := provider.Complete(ctx, ports.CompletionRequest{
+		ModelID: model,
+		SystemPrompt: "You grade responses. Rate how well the response fulfils the request " +
+			"on a scale from 1 (poor) to 10 (excellent). Reply with the number only.",
+		Messages: []ports.Message{{
+			Role:    "user",
+			Content: "Request:\n" + input + "\n\nResponse:\n" + output,
+		}},
+		MaxTokens: 8,
+	})
+	if err != nil {
+		return 0, nil, err
+	}
+
+	match := judgeScorePattern.FindString(resp.Content)
+	score, err := strconv.ParseFloat(match, 64)
+	if err != nil || score < 1 || score > 10 {
+		return 0, resp, fmt.Errorf("unexpected judge reply %q", strings.TrimSpace(resp.Content))
+	}
+	return score, resp, nil
+}
@@ -0,0 +1,158 @@
+package workflow
+
+import (
+	"context"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/jbctechsolutions/skillrunner/internal/application/ports"
+	"github.com/jbctechsolutions/skillrunner/internal/domain/skill"
+)
+
+func TestParseSweepParam(t *testing.T) {
+	p, err := ParseSweepParam("temperature=0, 0.3,0.7")
+	if err != nil {
+		t.Fatalf("ParseSweepParam() error = %v", err)
+	}
+	if p.Name != SweepTemperature || strings.Join(p.Values, "|") != "0|0.3|0.7" {
+		t.Errorf("ParseSweepParam() = %+v", p)
+	}
+
+	for _, bad := range []string{"temperature", "=1", "temperature=", "temperature=hot", "max_tokens=0", "top_p=0.9", "profile=Fast"} {
+		if _, err := ParseSweepParam(bad); err == nil {
+			t.Errorf("ParseSweepParam(%q) should fail", bad)
+		}
+	}
+}
+
+func TestSweepCombinations(t *testing.T) {
+	combos := SweepCombinations([]SweepParam{
+		{Name: SweepTemperature, Values: []string{"0", "0.7"}},
+		{Name: SweepModel, Values: []string{"a", "b", "c"}},
+	})
+
+	var got []string
+	for _, c := range combos {
+		got = append(got, c.String())
+	}
+	want := "temperature=0 model=a,temperature=0 model=b,temperature=0 model=c," +
+		"temperature=0.7 model=a,temperature=0.7 model=b,temperature=0.7 model=c"
+	if strings.Join(got, ",") != want {
+		t.Errorf("SweepCombinations() = %v", got)
+	}
+	if combos[4].Get(SweepModel) != "b" || combos[4].Get(SweepMaxTokens) != "" {
+		t.Errorf("Get() on %v is wrong", combos[4])
+	}
+}
+
+func TestApplySweepCombination(t *testing.T) {
+	s := createTestSkill(t, []skill.Phase{
+		createTestPhase(t, "draft", "Draft", "Draft: {{._input}}", nil),
+		createTestPhase(t, "polish", "Polish", "Polish: {{.draft}}", []string{"draft"}),
+	})
+
+	variant, err := ApplySweepCombination(s, SweepCombination{
+		{Name: SweepTemperature, Value: "0.3"},
+		{Name: SweepModel, Value: "gpt-4o-mini"},
+		{Name: SweepMaxTokens, Value: "256"},
+	})
+	if err != nil {
+		t.Fatalf("ApplySweepCombination() error = %v", err)
+	}
+	for _, p := range variant.Phases() {
+		if p.Temperature != 0.3 || p.Model != "gpt-4o-mini" || p.MaxTokens != 256 {
+			t.Errorf("phase %s = temperature %v, model %q, max_tokens %d", p.ID, p.Temperature, p.Model, p.MaxTokens)
+		}
+	}
+	if s.Phases()[0].Model != "" {
+		t.Error("the original skill should not change")
+	}
+}
+
+func TestRunSweep(t *testing.T) {
+	s := createTestSkill(t, []skill.Phase{createTestPhase(t, "draft", "Draft", "Draft: {{._input}}", nil)})
+	params := []SweepParam{{Name: SweepTemperature, Values: []string{"0", "0.5", "1"}}}
+
+	var running, peak atomic.Int32
+	report, err := RunSweep(context.Background(), s, "a report", params, SweepConfig{
+		Parallel: 2,
+		Run: func(_ context.Context, sk *skill.Skill, combo SweepCombination) (*ExecutionResult, error) {
+			n := running.Add(1)
+			defer running.Add(-1)
+			for {
+				p := peak.Load()
+				if n <= p || peak.CompareAndSwap(p, n) {
+					break
+				}
+			}
+			return &ExecutionResult{
+				Status:      PhaseStatusCompleted,
+				FinalOutput: "output at " + combo.Get(SweepTemperature),
+				TotalTokens: 10,
+				TotalCost:   0.01,
+			}, nil
+		},
+		Judge: func(_ context.Context, input, output string) (float64, error) {
+			if strings.HasSuffix(output, "0.5") {
+				return 9, nil
+			}
+			return 5, nil
+		},
+	})
+	if err != nil {
+		t.Fatalf("RunSweep() error = %v", err)
+	}
+
+	if len(report.Results) != 3 || report.Results[1].Output != "output at 0.5" {
+		t.Fatalf("Results = %+v, want one per combination in order", report.Results)
+	}
+	if best := report.Best(); best == nil || best.Combination.Get(SweepTemperature) != "0.5" {
+		t.Errorf("Best() = %+v, want temperature 0.5", best)
+	}
+	if peak.Load() > 2 {
+		t.Errorf("ran %d combinations at once, want at most 2", peak.Load())
+	}
+	if report.TotalCost < 0.029 || report.TotalCost > 0.031 {
+		t.Errorf("TotalCost = %v, want 0.03", report.TotalCost)
+	}
+}
+
+func TestRunSweep_MaxCost(t *testing.T) {
+	s := createTestSkill(t, []skill.Phase{createTestPhase(t, "draft", "Draft", "Draft: {{._input}}", nil)})
+	params := []SweepParam{{Name: SweepMaxTokens, Values: []string{"100", "200", "300"}}}
+
+	report, err := RunSweep(context.Background(), s, "input", params, SweepConfig{
+		Parallel: 1,
+		MaxCost:  0.5,
+		Run: func(context.Context, *skill.Skill, SweepCombination) (*ExecutionResult, error) {
+			return &ExecutionResult{Status: PhaseStatusCompleted, TotalCost: 0.5}, nil
+		},
+	})
+	if err != nil {
+		t.Fatalf("RunSweep() error = %v", err)
+	}
+	if report.Results[0].Status != string(PhaseStatusCompleted) || report.Results[2].Status != SweepStatusOverBudget {
+		t.Errorf("Results = %+v, want the combinations after the cap skipped", report.Results)
+	}
+}
+
+// judgeProvider replies to every completion with a fixed text.
+type judgeProvider struct {
+	ports.ProviderPort
+	reply string
+}
+
+func (p *judgeProvider) Complete(context.Context, ports.CompletionRequest) (*ports.CompletionResponse, error) {
+	return &ports.CompletionResponse{Content: p.reply}, nil
+}
+
+func TestNewLLMJudge(t *testing.T) {
+	score, err := NewLLMJudge(&judgeProvider{reply: "8"}, "judge")(context.Background(), "in", "out")
+	if err != nil || score != 8 {
+		t.Errorf("judge = %v, %v; want 8", score, err)
+	}
+	if _, err := NewLLMJudge(&judgeProvider{reply: "excellent"}, "judge")(context.Background(), "in", "out"); err == nil {
+		t.Error("a reply without a score should fail")
+	}
+}
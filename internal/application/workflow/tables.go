@@ -0,0 +1,222 @@
+package workflow
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/jbctechsolutions/skillrunner/internal/domain/skill"
+)
+
+// MarkdownTable is a table found in Markdown text.
+type MarkdownTable struct {
+	Header []string
+	Rows   [][]string // Each row has one cell per header column
+}
+
+// tableDelimiterPattern matches the delimiter row under a table header,
+// e.g. "|---|:---:|" or "--- | ---".
+var tableDelimiterPattern = regexp.MustCompile(`^\s*\|?\s*:?-+:?\s*(\|\s*:?-+:?\s*)*\|?\s*$`)
+
+// ParseMarkdownTables returns the GitHub-flavored Markdown tables in text,
+// skipping fenced code blocks. Rows are padded or truncated to the number
+// of header columns.
+func ParseMarkdownTables(text string) []MarkdownTable {
+	lines := strings.Split(text, "\n")
+
+	var (
+		tables  []MarkdownTable
+		current *MarkdownTable
+		fence   string
+	)
+	finish := func() {
+		if current != nil {
+			tables = append(tables, *current)
+			current = nil
+		}
+	}
+
+	for i := 0; i < len(lines); i++ {
+		line := strings.TrimSpace(lines[i])
+
+		if fence != "" {
+			if strings.HasPrefix(line, fence) {
+				fence = ""
+			}
+			continue
+		}
+		if strings.HasPrefix(line, "```") || strings.HasPrefix(line, "~~~") {
+			finish()
+			fence = line[:3]
+			continue
+		}
+
+		if current != nil {
+			if !strings.Contains(line, "|") {
+				finish()
+				continue
+			}
+			current.Rows = append(current.Rows, fitRow(splitTableRow(line), len(current.Header)))
+			continue
+		}
+
+		// A table starts with a header row followed by a matching delimiter row
+		if !strings.Contains(line, "|") || i+1 >= len(lines) || !tableDelimiterPattern.MatchString(lines[i+1]) {
+			continue
+		}
+		header := splitTableRow(line)
+		if len(splitTableRow(lines[i+1])) != len(header) {
+			continue
+		}
+		current = &MarkdownTable{Header: header}
+		i++ // Skip the delimiter row
+	}
+	finish()
+
+	return tables
+}
+
+// splitTableRow splits a table row into trimmed cells, honoring escaped
+// pipes and pipes inside inline code.
+func splitTableRow(line string) []string {
+	line = strings.TrimSpace(line)
+	line = strings.TrimPrefix(line, "|")
+	if strings.HasSuffix(line, "|") && !strings.HasSuffix(line, `\|`) {
+		line = line[:len(line)-1]
+	}
+
+	var (
+		cells  []string
+		cell   strings.Builder
+		inCode bool
+	)
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case c == '\\' && i+1 < len(line) && line[i+1] == '|':
+			cell.WriteByte('|')
+			i++
+		case c == '`':
+			inCode = !inCode
+			cell.WriteByte(c)
+		case c == '|' && !inCode:
+			cells = append(cells, strings.TrimSpace(cell.String()))
+			cell.Reset()
+		default:
+			cell.WriteByte(c)
+		}
+	}
+	return append(cells, strings.TrimSpace(cell.String()))
+}
+
+// fitRow pads or truncates row to n cells.
+func fitRow(row []string, n int) []string {
+	if len(row) >= n {
+		return row[:n]
+	}
+	return append(row, make([]string, n-len(row))...)
+}
+
+// Records returns the rows as objects keyed by column name. Empty column
+// names become column_<n> and repeated names get a _<n> suffix.
+func (t MarkdownTable) Records() []map[string]string {
+	keys := make([]string, len(t.Header))
+	seen := make(map[string]int, len(t.Header))
+	for i, name := range t.Header {
+		if name == "" {
+			name = fmt.Sprintf("column_%d", i+1)
+		}
+		seen[name]++
+		if seen[name] > 1 {
+			name = fmt.Sprintf("%s_%d", name, seen[name])
+		}
+		keys[i] = name
+	}
+
+	records := make([]map[string]string, 0, len(t.Rows))
+	for _, row := range t.Rows {
+		record := make(map[string]string, len(keys))
+		for i, key := range keys {
+			record[key] = row[i]
+		}
+		records = append(records, record)
+	}
+	return records
+}
+
+// encodeTable renders a table in format: CSV or TSV with a header line, or
+// a JSON array of row objects.
+func encodeTable(table MarkdownTable, format string) ([]byte, error) {
+	var buf bytes.Buffer
+	switch format {
+	case skill.TableFormatJSON:
+		enc := json.NewEncoder(&buf)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(table.Records()); err != nil {
+			return nil, err
+		}
+	case skill.TableFormatCSV, skill.TableFormatTSV:
+		w := csv.NewWriter(&buf)
+		if format == skill.TableFormatTSV {
+			w.Comma = '\t'
+		}
+		if err := w.Write(table.Header); err != nil {
+			return nil, err
+		}
+		if err := w.WriteAll(table.Rows); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("%w: got %q", skill.ErrInvalidTableFormat, format)
+	}
+	return buf.Bytes(), nil
+}
+
+// extractPhaseTables saves the Markdown tables in the output of each
+// completed phase with extract_tables set to dir, as <phaseID>-table.<ext>
+// or <phaseID>-table-<n>.<ext> when there are several, and adds the files
+// to the phase's artifacts.
+func extractPhaseTables(phases []skill.Phase, result *ExecutionResult, dir string) error {
+	for i := range phases {
+		phase := &phases[i]
+		if phase.ExtractTables == "" {
+			continue
+		}
+		pr, ok := result.PhaseResults[phase.ID]
+		if !ok || pr.Status != PhaseStatusCompleted {
+			continue
+		}
+
+		tables := ParseMarkdownTables(pr.Output)
+		if len(tables) == 0 {
+			continue
+		}
+
+		resolved, err := resolveArtifactDir(dir)
+		if err != nil {
+			return err
+		}
+		for n, table := range tables {
+			data, err := encodeTable(table, phase.ExtractTables)
+			if err != nil {
+				return fmt.Errorf("phase %s: failed to encode table: %w", phase.ID, err)
+			}
+
+			name := phase.ID + "-table." + phase.ExtractTables
+			if len(tables) > 1 {
+				name = fmt.Sprintf("%s-table-%d.%s", phase.ID, n+1, phase.ExtractTables)
+			}
+			path := filepath.Join(resolved, name)
+			if err := os.WriteFile(path, data, 0o644); err != nil {
+				return fmt.Errorf("phase %s: failed to save table: %w", phase.ID, err)
+			}
+			pr.Artifacts = append(pr.Artifacts, path)
+		}
+	}
+	return nil
+}
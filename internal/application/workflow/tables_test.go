@@ -0,0 +1,115 @@
+package workflow
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/jbctechsolutions/skillrunner/internal/application/ports"
+	"github.com/jbctechsolutions/skillrunner/internal/domain/skill"
+)
+
+const tableOutput = "Here are the results:\n\n" +
+	"| Name | Price | Notes |\n" +
+	"|:-----|------:|-------|\n" +
+	"| Widget | 9.99 | `a|b` and \\| pipe |\n" +
+	"| Gadget | 19.50 |\n" +
+	"\n" +
+	"```\n| not | a table |\n|---|---|\n```\n\n" +
+	"Name | Qty\n--- | ---\nBolt | 4\n"
+
+func TestParseMarkdownTables(t *testing.T) {
+	tables := ParseMarkdownTables(tableOutput)
+	if len(tables) != 2 {
+		t.Fatalf("found %d tables, want 2: %+v", len(tables), tables)
+	}
+
+	want := MarkdownTable{
+		Header: []string{"Name", "Price", "Notes"},
+		Rows: [][]string{
+			{"Widget", "9.99", "`a|b` and | pipe"},
+			{"Gadget", "19.50", ""},
+		},
+	}
+	if !reflect.DeepEqual(tables[0], want) {
+		t.Errorf("table 1 = %+v, want %+v", tables[0], want)
+	}
+
+	if !reflect.DeepEqual(tables[1].Rows, [][]string{{"Bolt", "4"}}) {
+		t.Errorf("table 2 rows = %v", tables[1].Rows)
+	}
+
+	if got := ParseMarkdownTables("a | b\nno delimiter\n"); len(got) != 0 {
+		t.Errorf("expected no tables, got %+v", got)
+	}
+}
+
+func TestEncodeTable(t *testing.T) {
+	table := MarkdownTable{
+		Header: []string{"Name", "", "Name"},
+		Rows:   [][]string{{"a, b", "1", "x"}},
+	}
+
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{skill.TableFormatCSV, "Name,,Name\n\"a, b\",1,x\n"},
+		{skill.TableFormatTSV, "Name\t\tName\na, b\t1\tx\n"},
+		{skill.TableFormatJSON, "[\n  {\n    \"Name\": \"a, b\",\n    \"Name_2\": \"x\",\n    \"column_2\": \"1\"\n  }\n]\n"},
+	}
+	for _, tt := range tests {
+		got, err := encodeTable(table, tt.format)
+		if err != nil {
+			t.Fatalf("encodeTable(%s) error = %v", tt.format, err)
+		}
+		if string(got) != tt.want {
+			t.Errorf("encodeTable(%s) = %q, want %q", tt.format, got, tt.want)
+		}
+	}
+
+	if _, err := encodeTable(table, "xlsx"); err == nil {
+		t.Error("expected error for unknown format")
+	}
+}
+
+func TestExecutor_ExtractTables(t *testing.T) {
+	provider := newMockProvider()
+	provider.completeFunc = func(_ context.Context, req ports.CompletionRequest) (*ports.CompletionResponse, error) {
+		return &ports.CompletionResponse{Content: tableOutput, ModelUsed: req.ModelID}, nil
+	}
+
+	phase := createTestPhase(t, "extract", "Extract", "List prices", nil)
+	phase.WithExtractTables(skill.TableFormatCSV)
+	plain := createTestPhase(t, "plain", "Plain", "No tables saved", nil)
+	sk := createTestSkill(t, []skill.Phase{phase, plain})
+
+	dir := t.TempDir()
+	config := DefaultExecutorConfig()
+	config.ArtifactDir = dir
+	config.Timeout = 5 * time.Second
+
+	result, err := NewExecutor(provider, config).Execute(context.Background(), sk, "input")
+	if err != nil || result.Status != PhaseStatusCompleted {
+		t.Fatalf("Execute() = %v, %v (%v)", result.Status, err, result.Error)
+	}
+
+	wantPaths := []string{filepath.Join(dir, "extract-table-1.csv"), filepath.Join(dir, "extract-table-2.csv")}
+	if got := result.PhaseResults["extract"].Artifacts; !reflect.DeepEqual(got, wantPaths) {
+		t.Errorf("artifacts = %v, want %v", got, wantPaths)
+	}
+	if got := result.PhaseResults["plain"].Artifacts; len(got) != 0 {
+		t.Errorf("plain phase artifacts = %v", got)
+	}
+
+	data, err := os.ReadFile(wantPaths[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "Name,Qty\nBolt,4\n" {
+		t.Errorf("table file = %q", data)
+	}
+}
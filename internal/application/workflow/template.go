@@ -0,0 +1,39 @@
+package workflow
+
+import (
+	"strings"
+
+	"github.com/jbctechsolutions/skillrunner/internal/infrastructure/templates"
+)
+
+// renderPrompt renders a phase's prompt template with its dependency
+// outputs and the skill's partials. The input is {{._input}}, or
+// {{.input}}, and the outputs are also available as {{.phases.phaseid}}.
+// A missing key is an error; {{get "key"}} returns "" for one instead.
+func renderPrompt(templateStr string, data map[string]string, partials map[string]string) (string, error) {
+	templateData := make(map[string]any, len(data)+2)
+	phases := make(map[string]string)
+
+	for k, v := range data {
+		templateData[k] = v
+		// Add non-special keys to the phases map for nested access
+		if !strings.HasPrefix(k, "_") {
+			phases[k] = v
+		}
+	}
+	if input, ok := data["_input"]; ok {
+		if _, ok := templateData["input"]; !ok {
+			templateData["input"] = input
+		}
+	}
+
+	// Add phases map for nested template access: {{.phases.phaseid}}
+	if len(phases) > 0 {
+		templateData["phases"] = phases
+	}
+
+	get := func(key string) string {
+		return data[key]
+	}
+	return templates.Render(templateStr, partials, templateData, get)
+}
@@ -0,0 +1,243 @@
+package workflow
+
+import (
+	"cmp"
+	"slices"
+	"time"
+
+	"github.com/jbctechsolutions/skillrunner/internal/domain/skill"
+	"github.com/jbctechsolutions/skillrunner/internal/domain/workflow"
+)
+
+// LatencyHistory estimates phase durations from past runs. A phase that has
+// run with a routing profile takes its median recorded duration; otherwise
+// its median output length is scaled by the time per output token recorded
+// for the profile across all phases.
+type LatencyHistory struct {
+	durations map[phaseProfileKey][]time.Duration
+	tokens    map[phaseKey][]int
+	rates     map[string]*profileRate // By routing profile
+}
+
+type phaseKey struct {
+	skillID string
+	phaseID string
+}
+
+type phaseProfileKey struct {
+	phaseKey
+	profile string
+}
+
+// profileRate accumulates the duration and output tokens of a profile's
+// phase executions.
+type profileRate struct {
+	duration time.Duration
+	tokens   int
+}
+
+// NewLatencyHistory creates an empty latency history.
+func NewLatencyHistory() *LatencyHistory {
+	return &LatencyHistory{
+		durations: make(map[phaseProfileKey][]time.Duration),
+		tokens:    make(map[phaseKey][]int),
+		rates:     make(map[string]*profileRate),
+	}
+}
+
+// Record adds a completed phase execution. Executions served from a cache
+// should not be recorded, since they say nothing about the model's speed.
+func (h *LatencyHistory) Record(skillID, phaseID, profile string, duration time.Duration, outputTokens int) {
+	if duration <= 0 || profile == "" {
+		return
+	}
+
+	key := phaseKey{skillID, phaseID}
+	profileKey := phaseProfileKey{key, profile}
+	h.durations[profileKey] = append(h.durations[profileKey], duration)
+
+	if outputTokens > 0 {
+		h.tokens[key] = append(h.tokens[key], outputTokens)
+		rate, ok := h.rates[profile]
+		if !ok {
+			rate = &profileRate{}
+			h.rates[profile] = rate
+		}
+		rate.duration += duration
+		rate.tokens += outputTokens
+	}
+}
+
+// Estimate returns how long a phase of the skill is expected to take with
+// the routing profile, and false when nothing recorded tells.
+func (h *LatencyHistory) Estimate(skillID string, phase *skill.Phase, profile string) (time.Duration, bool) {
+	key := phaseKey{skillID, phase.ID}
+	if durations := h.durations[phaseProfileKey{key, profile}]; len(durations) > 0 {
+		return median(durations), true
+	}
+
+	rate, tokens := h.rates[profile], h.tokens[key]
+	if rate == nil || rate.tokens == 0 || len(tokens) == 0 {
+		return 0, false
+	}
+	return time.Duration(int64(median(tokens)) * int64(rate.duration) / int64(rate.tokens)), true
+}
+
+// median returns the middle value of values, which must not be empty.
+func median[T cmp.Ordered](values []T) T {
+	sorted := slices.Sorted(slices.Values(values))
+	return sorted[len(sorted)/2]
+}
+
+// PhaseEstimateFunc estimates how long a phase takes with a routing profile,
+// and returns false when it cannot tell.
+type PhaseEstimateFunc func(phase *skill.Phase, profile string) (time.Duration, bool)
+
+// TimeBudgetPlan adapts a skill to an overall time budget.
+type TimeBudgetPlan struct {
+	Skill       *skill.Skill      // The skill to run: downgraded profiles, without dropped phases
+	MaxParallel int               // Phases to run at once
+	Estimate    time.Duration     // Estimated wall time of Skill
+	Downgraded  map[string]string // New routing profile, by phase ID
+	Dropped     []string          // Optional phases left out
+	Unestimated []string          // Phases without latency data, counted as instant
+}
+
+// Fits reports whether the plan is expected to finish within budget.
+func (p *TimeBudgetPlan) Fits(budget time.Duration) bool {
+	return p.Estimate <= budget
+}
+
+// PlanTimeBudget plans a run of the skill expected to finish within budget.
+// The wall time is estimated batch by batch over the skill's DAG. While it
+// exceeds the budget, the plan first runs every phase of a batch at once,
+// then moves the phases to cheaper, faster routing profiles where the
+// estimates show a gain, and finally leaves out optional phases that no
+// other phase depends on. A plan that still does not fit runs best effort.
+func PlanTimeBudget(sk *skill.Skill, budget time.Duration, maxParallel int, estimate PhaseEstimateFunc) (*TimeBudgetPlan, error) {
+	phases := sk.Phases()
+	plan := &TimeBudgetPlan{
+		MaxParallel: max(maxParallel, 1),
+		Downgraded:  make(map[string]string),
+	}
+
+	wallTime, width, err := estimateWallTime(phases, plan.MaxParallel, estimate)
+	if err != nil {
+		return nil, err
+	}
+
+	if wallTime > budget && width > plan.MaxParallel {
+		plan.MaxParallel = width
+		if wallTime, _, err = estimateWallTime(phases, plan.MaxParallel, estimate); err != nil {
+			return nil, err
+		}
+	}
+
+	for wallTime > budget {
+		best, bestTime := -1, wallTime
+		for i := range phases {
+			cheaper := cheaperProfile(phases[i].RoutingProfile)
+			if cheaper == "" || phases[i].IsModelPinned() {
+				continue
+			}
+			if _, ok := estimate(&phases[i], cheaper); !ok {
+				continue
+			}
+
+			candidate := slices.Clone(phases)
+			candidate[i].RoutingProfile = cheaper
+			if t, _, err := estimateWallTime(candidate, plan.MaxParallel, estimate); err == nil && t < bestTime {
+				best, bestTime = i, t
+			}
+		}
+		if best < 0 {
+			break
+		}
+
+		phases[best].RoutingProfile = cheaperProfile(phases[best].RoutingProfile)
+		plan.Downgraded[phases[best].ID] = phases[best].RoutingProfile
+		wallTime = bestTime
+	}
+
+	for wallTime > budget && len(phases) > 1 {
+		best, bestTime := -1, wallTime
+		for i := range phases {
+			if !phases[i].Optional || hasDependents(phases, phases[i].ID) {
+				continue
+			}
+
+			candidate := slices.Delete(slices.Clone(phases), i, i+1)
+			if t, _, err := estimateWallTime(candidate, plan.MaxParallel, estimate); err == nil && t < bestTime {
+				best, bestTime = i, t
+			}
+		}
+		if best < 0 {
+			break
+		}
+
+		delete(plan.Downgraded, phases[best].ID)
+		plan.Dropped = append(plan.Dropped, phases[best].ID)
+		phases = slices.Delete(phases, best, best+1)
+		wallTime = bestTime
+	}
+
+	if plan.Skill, err = sk.WithPhases(phases); err != nil {
+		return nil, err
+	}
+	plan.Estimate = wallTime
+	for i := range phases {
+		if _, ok := estimate(&phases[i], phases[i].RoutingProfile); !ok {
+			plan.Unestimated = append(plan.Unestimated, phases[i].ID)
+		}
+	}
+	return plan, nil
+}
+
+// estimateWallTime estimates how long the phases take when batches run one
+// after another with up to maxParallel phases at once, and returns the
+// width of the widest batch. Phases without an estimate count as instant.
+func estimateWallTime(phases []skill.Phase, maxParallel int, estimate PhaseEstimateFunc) (time.Duration, int, error) {
+	dag, err := workflow.NewDAG(phases)
+	if err != nil {
+		return 0, 0, err
+	}
+	batches, err := dag.GetParallelBatches()
+	if err != nil {
+		return 0, 0, err
+	}
+
+	var total time.Duration
+	width := 0
+	for _, batch := range batches {
+		var longest, sum time.Duration
+		for _, id := range batch {
+			phase := dag.GetPhase(id)
+			d, _ := estimate(phase, phase.RoutingProfile)
+			longest = max(longest, d)
+			sum += d
+		}
+		total += max(longest, sum/time.Duration(maxParallel))
+		width = max(width, len(batch))
+	}
+	return total, width, nil
+}
+
+// cheaperProfile returns the next cheaper routing profile, or "" if there
+// is none.
+func cheaperProfile(profile string) string {
+	switch profile {
+	case skill.ProfilePremium:
+		return skill.ProfileBalanced
+	case skill.ProfileBalanced:
+		return skill.ProfileCheap
+	default:
+		return ""
+	}
+}
+
+// hasDependents reports whether any phase depends on the phase with id.
+func hasDependents(phases []skill.Phase, id string) bool {
+	return slices.ContainsFunc(phases, func(p skill.Phase) bool {
+		return slices.Contains(p.DependsOn, id)
+	})
+}
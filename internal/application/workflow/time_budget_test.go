@@ -0,0 +1,126 @@
+package workflow
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/jbctechsolutions/skillrunner/internal/domain/skill"
+)
+
+// profileDurations estimates phases by phase ID and routing profile.
+type profileDurations map[string]map[string]time.Duration
+
+func (d profileDurations) estimate(phase *skill.Phase, profile string) (time.Duration, bool) {
+	duration, ok := d[phase.ID][profile]
+	return duration, ok
+}
+
+func TestLatencyHistory_Estimate(t *testing.T) {
+	history := NewLatencyHistory()
+	history.Record("review", "draft", skill.ProfilePremium, 30*time.Second, 600)
+	history.Record("review", "draft", skill.ProfilePremium, 10*time.Second, 200)
+	history.Record("review", "draft", skill.ProfilePremium, 20*time.Second, 400)
+	history.Record("summarize", "outline", skill.ProfileCheap, 2*time.Second, 400)
+
+	draft := &skill.Phase{ID: "draft"}
+	if got, ok := history.Estimate("review", draft, skill.ProfilePremium); !ok || got != 20*time.Second {
+		t.Errorf("Estimate(premium) = %v, %v; want the median 20s", got, ok)
+	}
+	// 400 median output tokens at 5ms per token recorded for cheap phases
+	if got, ok := history.Estimate("review", draft, skill.ProfileCheap); !ok || got != 2*time.Second {
+		t.Errorf("Estimate(cheap) = %v, %v; want 2s scaled from the profile's token rate", got, ok)
+	}
+	if _, ok := history.Estimate("review", draft, skill.ProfileBalanced); ok {
+		t.Error("expected no estimate for a profile without recorded runs")
+	}
+	if _, ok := history.Estimate("review", &skill.Phase{ID: "polish"}, skill.ProfilePremium); ok {
+		t.Error("expected no estimate for a phase without recorded runs")
+	}
+}
+
+func TestPlanTimeBudget(t *testing.T) {
+	outline := createTestPhase(t, "outline", "Outline", "Outline it", nil)
+	draft := createTestPhase(t, "draft", "Draft", "Draft it", []string{"outline"})
+	draft.RoutingProfile = skill.ProfilePremium
+	examples := createTestPhase(t, "examples", "Examples", "Add examples", []string{"outline"})
+	examples.Optional = true
+	s := createTestSkill(t, []skill.Phase{outline, draft, examples})
+
+	durations := profileDurations{
+		"outline":  {skill.ProfileBalanced: 5 * time.Second},
+		"draft":    {skill.ProfilePremium: 40 * time.Second, skill.ProfileBalanced: 20 * time.Second},
+		"examples": {skill.ProfileBalanced: 30 * time.Second},
+	}
+
+	tests := []struct {
+		name           string
+		budget         time.Duration
+		maxParallel    int
+		wantParallel   int
+		wantDowngraded map[string]string
+		wantDropped    []string
+		wantFits       bool
+	}{
+		{"fits as is", 60 * time.Second, 2, 2, map[string]string{}, nil, true},
+		{"runs the batch at once", 50 * time.Second, 1, 2, map[string]string{}, nil, true},
+		{"downgrades", 40 * time.Second, 2, 2, map[string]string{"draft": skill.ProfileBalanced}, nil, true},
+		{"drops optional phases", 25 * time.Second, 2, 2, map[string]string{"draft": skill.ProfileBalanced}, []string{"examples"}, true},
+		{"best effort", 10 * time.Second, 2, 2, map[string]string{"draft": skill.ProfileBalanced}, []string{"examples"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plan, err := PlanTimeBudget(s, tt.budget, tt.maxParallel, durations.estimate)
+			if err != nil {
+				t.Fatalf("PlanTimeBudget failed: %v", err)
+			}
+
+			if plan.MaxParallel != tt.wantParallel {
+				t.Errorf("MaxParallel = %d, want %d", plan.MaxParallel, tt.wantParallel)
+			}
+			if len(plan.Downgraded) != len(tt.wantDowngraded) {
+				t.Errorf("Downgraded = %v, want %v", plan.Downgraded, tt.wantDowngraded)
+			}
+			for id, profile := range tt.wantDowngraded {
+				phase, err := plan.Skill.GetPhase(id)
+				if err != nil || phase.RoutingProfile != profile || plan.Downgraded[id] != profile {
+					t.Errorf("expected phase %s to run with %s, got %v", id, profile, plan.Downgraded)
+				}
+			}
+			if !slices.Equal(plan.Dropped, tt.wantDropped) {
+				t.Errorf("Dropped = %v, want %v", plan.Dropped, tt.wantDropped)
+			}
+			if len(plan.Skill.Phases()) != 3-len(tt.wantDropped) {
+				t.Errorf("expected %d phases to run, got %d", 3-len(tt.wantDropped), len(plan.Skill.Phases()))
+			}
+			if plan.Fits(tt.budget) != tt.wantFits {
+				t.Errorf("Fits() = %v with estimate %v, want %v", plan.Fits(tt.budget), plan.Estimate, tt.wantFits)
+			}
+		})
+	}
+
+	if draft, _ := s.GetPhase("draft"); draft.RoutingProfile != skill.ProfilePremium {
+		t.Error("expected planning to leave the original skill unchanged")
+	}
+}
+
+func TestPlanTimeBudget_KeepsDependedOnPhases(t *testing.T) {
+	outline := createTestPhase(t, "outline", "Outline", "Outline it", nil)
+	outline.Optional = true
+	draft := createTestPhase(t, "draft", "Draft", "Draft it", []string{"outline"})
+	s := createTestSkill(t, []skill.Phase{outline, draft})
+
+	durations := profileDurations{
+		"outline": {skill.ProfileBalanced: 30 * time.Second},
+		"draft":   {skill.ProfileBalanced: 30 * time.Second},
+	}
+	plan, err := PlanTimeBudget(s, 10*time.Second, 1, durations.estimate)
+	if err != nil {
+		t.Fatalf("PlanTimeBudget failed: %v", err)
+	}
+
+	if len(plan.Dropped) != 0 || plan.Fits(10*time.Second) {
+		t.Errorf("expected an optional phase with dependents to be kept, got %+v", plan)
+	}
+}
@@ -0,0 +1,54 @@
+package workflow
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jbctechsolutions/skillrunner/internal/application/ports"
+)
+
+// ErrPhaseTimeout is returned when an attempt of a phase runs longer than
+// the phase's timeout.
+var ErrPhaseTimeout = errors.New("phase timed out")
+
+// timedAttempt bounds each call of attempt by timeout, canceling only that
+// call's request when it expires. The phase's retries and escalation then
+// run as for any other failure, and the run goes on. A timeout of 0
+// returns attempt as is.
+func timedAttempt(timeout time.Duration, attempt phaseAttempt) phaseAttempt {
+	if timeout <= 0 {
+		return attempt
+	}
+	return func(ctx context.Context, req ports.CompletionRequest) (*ports.CompletionResponse, string, error) {
+		ctx, cancel := context.WithTimeoutCause(ctx, timeout, ErrPhaseTimeout)
+		defer cancel()
+		resp, warning, err := attempt(ctx, req)
+		return resp, warning, timeoutError(ctx, timeout, err)
+	}
+}
+
+// timedPhase runs a phase that is not a completion, such as a transcription
+// or sub-skill, bounded by timeout. A timeout of 0 runs it as is.
+func timedPhase(ctx context.Context, timeout time.Duration, run func(ctx context.Context) *PhaseResult) *PhaseResult {
+	if timeout <= 0 {
+		return run(ctx)
+	}
+	ctx, cancel := context.WithTimeoutCause(ctx, timeout, ErrPhaseTimeout)
+	defer cancel()
+	result := run(ctx)
+	if result.Status == PhaseStatusFailed {
+		result.Error = timeoutError(ctx, timeout, result.Error)
+	}
+	return result
+}
+
+// timeoutError returns ErrPhaseTimeout in place of err when err followed the
+// expiry of ctx's phase timeout, rather than the end of the run.
+func timeoutError(ctx context.Context, timeout time.Duration, err error) error {
+	if err == nil || !errors.Is(context.Cause(ctx), ErrPhaseTimeout) {
+		return err
+	}
+	return fmt.Errorf("%w after %s: %w", ErrPhaseTimeout, timeout, err)
+}
@@ -0,0 +1,78 @@
+package workflow
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jbctechsolutions/skillrunner/internal/application/ports"
+	"github.com/jbctechsolutions/skillrunner/internal/domain/skill"
+)
+
+// slowCheapProvider returns a provider whose cheap model hangs until its
+// request is canceled.
+func slowCheapProvider() *mockProvider {
+	provider := newMockProvider()
+	provider.completeFunc = func(ctx context.Context, req ports.CompletionRequest) (*ports.CompletionResponse, error) {
+		if req.ModelID == "llama3.2:3b" {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		}
+		return &ports.CompletionResponse{Content: "summary", ModelUsed: req.ModelID}, nil
+	}
+	return provider
+}
+
+func TestExecutor_PhaseTimeout(t *testing.T) {
+	p := createTestPhase(t, "summarize", "Summarize", "{{._input}}", nil)
+	p.WithRoutingProfile(skill.RoutingProfileCheap).WithTimeout(20 * time.Millisecond)
+	s := createTestSkill(t, []skill.Phase{p})
+
+	start := time.Now()
+	result, _ := NewExecutor(slowCheapProvider(), DefaultExecutorConfig()).Execute(context.Background(), s, "a long document")
+	pr := result.PhaseResults["summarize"]
+	if pr.Status != PhaseStatusFailed || !errors.Is(pr.Error, ErrPhaseTimeout) {
+		t.Fatalf("status, error = %s, %v; want a phase timeout", pr.Status, pr.Error)
+	}
+	if !strings.Contains(pr.Error.Error(), "after 20ms") {
+		t.Errorf("error = %v, want the timeout", pr.Error)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("run took %s, want the phase canceled after its timeout", elapsed)
+	}
+}
+
+func TestExecutor_PhaseTimeoutEscalates(t *testing.T) {
+	provider := slowCheapProvider()
+	p := createTestPhase(t, "summarize", "Summarize", "{{._input}}", nil)
+	p.WithRoutingProfile(skill.RoutingProfileCheap).
+		WithTimeout(20 * time.Millisecond).
+		WithRetry(&skill.RetryPolicy{Retries: 1, Backoff: time.Millisecond, OnFailure: skill.RetryOnFailureEscalateModel})
+	s := createTestSkill(t, []skill.Phase{p})
+
+	result, err := NewExecutor(provider, DefaultExecutorConfig()).Execute(context.Background(), s, "a long document")
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	pr := result.PhaseResults["summarize"]
+	if pr.Status != PhaseStatusCompleted || pr.ModelUsed != "qwen2.5:14b" {
+		t.Fatalf("status, model = %s, %s; want completed on the premium model", pr.Status, pr.ModelUsed)
+	}
+	if len(pr.Warnings) != 1 || !strings.Contains(pr.Warnings[0], "phase timed out") {
+		t.Errorf("warnings = %q, want the escalation after the timeouts", pr.Warnings)
+	}
+	if got := provider.callCount.Load(); got != 3 {
+		t.Errorf("provider called %d times, want 3", got)
+	}
+}
+
+func TestTimeoutError(t *testing.T) {
+	// The end of the run is not the phase's timeout
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := timeoutError(ctx, time.Second, context.Canceled); errors.Is(err, ErrPhaseTimeout) {
+		t.Errorf("timeoutError() = %v, want the run's cancellation", err)
+	}
+}
@@ -0,0 +1,57 @@
+package workflow
+
+import (
+	"context"
+
+	"github.com/jbctechsolutions/skillrunner/internal/domain/skill"
+	"github.com/jbctechsolutions/skillrunner/internal/infrastructure/tracing"
+)
+
+// traceWorkflow starts the workflow.execute span of a skill run, under which
+// the phase spans nest. The returned function ends it with the run's totals.
+func traceWorkflow(ctx context.Context, s *skill.Skill) (context.Context, func(*ExecutionResult, error)) {
+	if s == nil {
+		return ctx, func(*ExecutionResult, error) {}
+	}
+	ctx, span := tracing.Default().StartWorkflowSpan(ctx, s.ID(), s.Name())
+	span.SetPhaseCount(len(s.Phases()))
+
+	return ctx, func(result *ExecutionResult, err error) {
+		if result != nil {
+			var input, output int
+			for _, pr := range result.PhaseResults {
+				input += pr.InputTokens
+				output += pr.OutputTokens
+			}
+			span.SetTotalTokens(input, output)
+			span.SetCost(result.TotalCost)
+			span.SetCacheStats(result.CacheHits, result.CacheMisses)
+			if err == nil {
+				err = result.Error
+			}
+		}
+		if err != nil {
+			span.EndWithError(err)
+			return
+		}
+		span.End()
+	}
+}
+
+// tracePhase starts the phase.execute span of a phase, under which its
+// provider requests nest. The returned function ends it with the result.
+func tracePhase(ctx context.Context, phase *skill.Phase) (context.Context, func(*PhaseResult)) {
+	ctx, span := tracing.Default().StartPhaseSpan(ctx, phase.ID, phase.Name)
+
+	return ctx, func(result *PhaseResult) {
+		span.SetProvider(result.ProviderUsed, result.ModelUsed)
+		span.SetTokens(result.InputTokens, result.OutputTokens)
+		span.SetCost(result.Cost)
+		span.SetCacheHit(result.CacheHit)
+		if result.Status == PhaseStatusFailed && result.Error != nil {
+			span.EndWithError(result.Error)
+			return
+		}
+		span.End()
+	}
+}
@@ -0,0 +1,52 @@
+package workflow
+
+import (
+	"context"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"github.com/jbctechsolutions/skillrunner/internal/domain/skill"
+)
+
+func TestExecutor_Execute_Traces(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	phases := []skill.Phase{
+		createTestPhase(t, "draft", "Draft", "Draft {{._input}}", nil),
+		createTestPhase(t, "review", "Review", "Review {{.draft}}", []string{"draft"}),
+	}
+	exec := NewExecutor(newMockProvider(), DefaultExecutorConfig())
+	if _, err := exec.Execute(context.Background(), createTestSkill(t, phases), "input"); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	spans := recorder.Ended()
+	var workflowSpan sdktrace.ReadOnlySpan
+	phaseSpans := 0
+	for _, span := range spans {
+		if span.Name() == "workflow.execute" {
+			workflowSpan = span
+		}
+	}
+	if workflowSpan == nil {
+		t.Fatalf("expected a workflow.execute span, got %d spans", len(spans))
+	}
+	for _, span := range spans {
+		if span.Name() != "phase.execute" {
+			continue
+		}
+		phaseSpans++
+		if span.Parent().SpanID() != workflowSpan.SpanContext().SpanID() {
+			t.Errorf("phase span should nest under the workflow span")
+		}
+	}
+	if phaseSpans != 2 {
+		t.Errorf("expected 2 phase spans, got %d", phaseSpans)
+	}
+}
@@ -0,0 +1,60 @@
+package workflow
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jbctechsolutions/skillrunner/internal/application/ports"
+	"github.com/jbctechsolutions/skillrunner/internal/domain/errors"
+	"github.com/jbctechsolutions/skillrunner/internal/domain/skill"
+)
+
+// transcribePhase runs a phase with input_audio: it renders the audio path and
+// the prompt (which guides the transcription), transcribes the file and
+// returns the transcript as the phase output for downstream phases.
+func transcribePhase(ctx context.Context, transcriber ports.TranscriptionPort, phase *skill.Phase, render func(string) (string, error)) *PhaseResult {
+	startTime := time.Now()
+
+	if transcriber == nil {
+		return failedPhaseResult(phase, startTime, errors.NewError(errors.CodeConfiguration,
+			fmt.Sprintf("phase %s transcribes audio but no transcription provider is configured", phase.ID), nil))
+	}
+
+	audioPath, err := render(phase.InputAudio)
+	if err != nil {
+		return failedPhaseResult(phase, startTime, err)
+	}
+	audioPath = strings.TrimSpace(audioPath)
+	if audioPath == "" {
+		return failedPhaseResult(phase, startTime, errors.NewError(errors.CodeValidation,
+			fmt.Sprintf("phase %s: input_audio rendered to an empty path", phase.ID), nil))
+	}
+
+	prompt, err := render(phase.PromptTemplate)
+	if err != nil {
+		return failedPhaseResult(phase, startTime, err)
+	}
+
+	resp, err := transcriber.Transcribe(ctx, ports.TranscriptionRequest{
+		AudioPath: audioPath,
+		Prompt:    prompt,
+	})
+	if err != nil {
+		return failedPhaseResult(phase, startTime, fmt.Errorf("failed to transcribe %s: %w", audioPath, err))
+	}
+
+	endTime := time.Now()
+	return &PhaseResult{
+		PhaseID:      phase.ID,
+		PhaseName:    phase.Name,
+		Status:       PhaseStatusCompleted,
+		Output:       resp.Text,
+		ModelUsed:    resp.ModelUsed,
+		ProviderUsed: transcriber.Info().Name,
+		StartTime:    startTime,
+		EndTime:      endTime,
+		Duration:     endTime.Sub(startTime),
+	}
+}
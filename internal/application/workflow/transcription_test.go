@@ -0,0 +1,153 @@
+package workflow
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/jbctechsolutions/skillrunner/internal/application/ports"
+	"github.com/jbctechsolutions/skillrunner/internal/domain/skill"
+)
+
+// mockTranscriber implements ports.TranscriptionPort for testing.
+type mockTranscriber struct {
+	mu       sync.Mutex
+	requests []ports.TranscriptionRequest
+	err      error
+}
+
+func (m *mockTranscriber) Info() ports.ProviderInfo {
+	return ports.ProviderInfo{Name: "mock-whisper", IsLocal: true}
+}
+
+func (m *mockTranscriber) Transcribe(_ context.Context, req ports.TranscriptionRequest) (*ports.TranscriptionResponse, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.requests = append(m.requests, req)
+	if m.err != nil {
+		return nil, m.err
+	}
+	return &ports.TranscriptionResponse{Text: "transcript of " + req.AudioPath, ModelUsed: "whisper-test"}, nil
+}
+
+// createAudioSkill returns a skill that transcribes the input path and
+// summarizes the transcript.
+func createAudioSkill(t *testing.T) *skill.Skill {
+	t.Helper()
+	transcribe := createTestPhase(t, "transcribe", "Transcribe", "Names: Priya, Tomasz", nil)
+	transcribe.WithInputAudio("{{._input}}")
+	summarize := createTestPhase(t, "summarize", "Summarize", "Summarize: {{.transcribe}}", []string{"transcribe"})
+	return createTestSkill(t, []skill.Phase{transcribe, summarize})
+}
+
+func TestExecutor_TranscriptionPhase(t *testing.T) {
+	provider := newMockProvider()
+	transcriber := &mockTranscriber{}
+	config := DefaultExecutorConfig()
+	config.Transcriber = transcriber
+	exec := NewExecutor(provider, config)
+
+	result, err := exec.Execute(context.Background(), createAudioSkill(t), "standup.m4a")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Status != PhaseStatusCompleted {
+		t.Fatalf("expected status Completed, got %s", result.Status)
+	}
+
+	if len(transcriber.requests) != 1 {
+		t.Fatalf("expected 1 transcription, got %d", len(transcriber.requests))
+	}
+	if req := transcriber.requests[0]; req.AudioPath != "standup.m4a" || req.Prompt != "Names: Priya, Tomasz" {
+		t.Errorf("transcription request = %+v", req)
+	}
+
+	pr := result.PhaseResults["transcribe"]
+	if pr.Output != "transcript of standup.m4a" || pr.ProviderUsed != "mock-whisper" || pr.ModelUsed != "whisper-test" {
+		t.Errorf("transcribe result = %+v", pr)
+	}
+	if calls := provider.callCount.Load(); calls != 1 {
+		t.Errorf("expected only the summary to call the LLM, got %d calls", calls)
+	}
+	if want := "Mock response for: Summarize: transcript of standup.m4a"; result.FinalOutput != want {
+		t.Errorf("FinalOutput = %q, want %q", result.FinalOutput, want)
+	}
+}
+
+func TestExecutor_TranscriptionPhase_Errors(t *testing.T) {
+	tests := []struct {
+		name        string
+		transcriber ports.TranscriptionPort
+		wantErr     string
+	}{
+		{"no transcriber", nil, "no transcription provider is configured"},
+		{"transcription fails", &mockTranscriber{err: errors.New("unsupported format")}, "unsupported format"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := DefaultExecutorConfig()
+			config.Transcriber = tt.transcriber
+			exec := NewExecutor(newMockProvider(), config)
+
+			result, _ := exec.Execute(context.Background(), createAudioSkill(t), "standup.m4a")
+			pr := result.PhaseResults["transcribe"]
+			if pr == nil || pr.Status != PhaseStatusFailed {
+				t.Fatalf("expected transcribe to fail, got %+v", pr)
+			}
+			if !strings.Contains(pr.Error.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want %q", pr.Error, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestExecutor_BatchAPI_TranscriptionRunsDirect(t *testing.T) {
+	provider := newMockBatchProvider()
+	transcriber := &mockTranscriber{}
+	config := batchExecutorConfig()
+	config.Transcriber = transcriber
+	exec := NewExecutor(provider, config)
+
+	result, err := exec.Execute(context.Background(), createAudioSkill(t), "call.wav")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(transcriber.requests) != 1 || len(provider.submitted) != 1 || len(provider.submitted[0]) != 1 {
+		t.Errorf("expected the transcription to run directly and the summary in a batch job, got %d transcriptions and %v", len(transcriber.requests), provider.submitted)
+	}
+	if want := "Batch response for: Summarize: transcript of call.wav"; result.FinalOutput != want {
+		t.Errorf("FinalOutput = %q, want %q", result.FinalOutput, want)
+	}
+}
+
+func TestStreamingExecutor_TranscriptionPhase(t *testing.T) {
+	config := DefaultExecutorConfig()
+	config.Transcriber = &mockTranscriber{}
+	exec := NewStreamingExecutor(newMockStreamingProvider([]string{"ok"}), config)
+
+	var progress []string
+	var mu sync.Mutex
+	callback := func(event StreamEvent) error {
+		mu.Lock()
+		defer mu.Unlock()
+		if event.Type == EventPhaseProgress && event.PhaseID == "transcribe" {
+			progress = append(progress, event.Content)
+		}
+		return nil
+	}
+
+	result, err := exec.ExecuteWithStreaming(context.Background(), createAudioSkill(t), "memo.mp3", callback)
+	if err != nil {
+		t.Fatalf("ExecuteWithStreaming failed: %v", err)
+	}
+	if result.PhaseResults["transcribe"].Output != "transcript of memo.mp3" {
+		t.Errorf("transcribe output = %q", result.PhaseResults["transcribe"].Output)
+	}
+	if strings.Join(progress, "") != "transcript of memo.mp3" {
+		t.Errorf("streamed transcript = %q", progress)
+	}
+}
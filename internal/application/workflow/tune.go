@@ -0,0 +1,388 @@
+package workflow
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/jbctechsolutions/skillrunner/internal/application/ports"
+	"github.com/jbctechsolutions/skillrunner/internal/application/skills"
+	domainProvider "github.com/jbctechsolutions/skillrunner/internal/domain/provider"
+	"github.com/jbctechsolutions/skillrunner/internal/domain/skill"
+	"github.com/jbctechsolutions/skillrunner/internal/infrastructure/templates"
+)
+
+// Tuning defaults.
+const (
+	DefaultTuneRounds   = 3
+	DefaultTuneVariants = 2
+)
+
+// Statuses of a tuning candidate.
+const (
+	TuneStatusEvaluated  = "evaluated"
+	TuneStatusInvalid    = "invalid"     // The proposal was empty, unchanged or not a valid template
+	TuneStatusOverBudget = "over_budget" // Evaluation stopped at the cost cap
+)
+
+// tuneOutputExcerpt is how much of each test output the proposer is shown.
+const tuneOutputExcerpt = 500
+
+// TuneCase is a test input a prompt is tuned against.
+type TuneCase struct {
+	Name     string            // Golden run ID, or a label for the input
+	Input    string            // Input of the skill
+	Expected map[string]string // Phase outputs of a golden run; nil when there is none
+}
+
+// TuneCaseResult is the outcome of one test case with a prompt.
+type TuneCaseResult struct {
+	Case   string  `json:"case"`
+	Score  float64 `json:"score"`
+	Output string  `json:"output,omitempty"`
+	Error  string  `json:"error,omitempty"`
+}
+
+// TuneCandidate is a prompt tried by a tuning loop and how it scored.
+type TuneCandidate struct {
+	Round  int              `json:"round"` // 0 for the original prompt
+	Prompt string           `json:"prompt"`
+	Status string           `json:"status"`
+	Score  float64          `json:"score"` // Mean case score, 0 to 1
+	Cost   float64          `json:"cost"`
+	Cases  []TuneCaseResult `json:"cases,omitempty"`
+	Error  string           `json:"error,omitempty"`
+}
+
+// TuneReport is the outcome of a tuning loop.
+type TuneReport struct {
+	SkillID    string          `json:"skill_id"`
+	PhaseID    string          `json:"phase_id"`
+	Baseline   TuneCandidate   `json:"baseline"`
+	Candidates []TuneCandidate `json:"candidates"`
+	Best       *TuneCandidate  `json:"best,omitempty"` // nil when no candidate beat the baseline
+	TotalCost  float64         `json:"total_cost"`
+	Exhausted  bool            `json:"budget_exhausted"`
+}
+
+// TuneRunFunc runs a skill on an input. The result's TotalCost must be
+// filled in for the tuning budget to apply.
+type TuneRunFunc func(ctx context.Context, sk *skill.Skill, input string) (*ExecutionResult, error)
+
+// TuneProposeFunc proposes a variant of a prompt template, given how the
+// prompt did on the test cases, and returns what the proposal cost.
+type TuneProposeFunc func(ctx context.Context, prompt string, results []TuneCaseResult) (proposal string, cost float64, err error)
+
+// TuneScoreFunc scores the result of a test case from 0 (poor) to 1 (best),
+// and returns what scoring cost.
+type TuneScoreFunc func(ctx context.Context, c TuneCase, result *ExecutionResult) (score, cost float64, err error)
+
+// TuneConfig configures RunTune.
+type TuneConfig struct {
+	// PhaseID is the phase whose prompt template is tuned. Required.
+	PhaseID string
+
+	// Rounds of proposals (default DefaultTuneRounds). Each round proposes
+	// variants of the best prompt so far.
+	Rounds int
+
+	// Variants proposed per round (default DefaultTuneVariants).
+	Variants int
+
+	// MaxCost stops the loop once runs, proposals and scoring cost this much
+	// in USD. Required.
+	MaxCost float64
+
+	// Run, Propose and Score are required.
+	Run     TuneRunFunc
+	Propose TuneProposeFunc
+	Score   TuneScoreFunc
+}
+
+// RunTune tunes the prompt template of a phase: it scores the current
+// prompt on the test cases, then for each round has variants of the best
+// prompt so far proposed and scored, keeping the best. It stops early when
+// the cost cap is reached.
+func RunTune(ctx context.Context, sk *skill.Skill, cases []TuneCase, cfg TuneConfig) (*TuneReport, error) {
+	switch {
+	case cfg.Run == nil || cfg.Propose == nil || cfg.Score == nil:
+		return nil, errors.New("tune: run, propose and score functions are required")
+	case cfg.MaxCost <= 0:
+		return nil, errors.New("tune: a cost cap is required")
+	case len(cases) == 0:
+		return nil, errors.New("tune: no test cases")
+	}
+	phase, err := sk.GetPhase(cfg.PhaseID)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Rounds <= 0 {
+		cfg.Rounds = DefaultTuneRounds
+	}
+	if cfg.Variants <= 0 {
+		cfg.Variants = DefaultTuneVariants
+	}
+
+	t := &tuner{skill: sk, cases: cases, config: cfg}
+	report := &TuneReport{SkillID: sk.ID(), PhaseID: cfg.PhaseID, Candidates: []TuneCandidate{}}
+	defer func() { report.TotalCost = t.spent }()
+
+	report.Baseline = t.evaluate(ctx, 0, phase.PromptTemplate)
+	if err := ctx.Err(); err != nil {
+		return report, err
+	}
+	if report.Baseline.Status != TuneStatusEvaluated {
+		report.Exhausted = true
+		return report, nil
+	}
+
+	best := report.Baseline
+	for round := 1; round <= cfg.Rounds && !report.Exhausted; round++ {
+		next := best
+		for range cfg.Variants {
+			if t.overBudget() {
+				report.Exhausted = true
+				break
+			}
+
+			cand := t.propose(ctx, round, best)
+			if err := ctx.Err(); err != nil {
+				return report, err
+			}
+			report.Candidates = append(report.Candidates, cand)
+			if cand.Status == TuneStatusOverBudget {
+				report.Exhausted = true
+				break
+			}
+			if cand.Status == TuneStatusEvaluated && cand.Score > next.Score {
+				next = cand
+			}
+		}
+		best = next
+	}
+
+	if best.Round > 0 {
+		report.Best = &best
+	}
+	return report, nil
+}
+
+// tuner holds the state of a tuning loop.
+type tuner struct {
+	skill  *skill.Skill
+	cases  []TuneCase
+	config TuneConfig
+	spent  float64
+}
+
+func (t *tuner) overBudget() bool {
+	return t.spent >= t.config.MaxCost
+}
+
+// propose has a variant of best proposed and scores it.
+func (t *tuner) propose(ctx context.Context, round int, best TuneCandidate) TuneCandidate {
+	start := t.spent
+	proposal, cost, err := t.config.Propose(ctx, best.Prompt, best.Cases)
+	t.spent += cost
+
+	cand := TuneCandidate{Round: round, Prompt: proposal, Status: TuneStatusInvalid}
+	switch {
+	case err != nil:
+		cand.Error = err.Error()
+	case strings.TrimSpace(proposal) == "" || proposal == best.Prompt:
+		cand.Error = "no new prompt proposed"
+	default:
+		if err := validatePromptTemplate(proposal); err != nil {
+			cand.Error = err.Error()
+			break
+		}
+		cand = t.evaluate(ctx, round, proposal)
+	}
+	cand.Cost = t.spent - start
+	return cand
+}
+
+// evaluate scores a prompt on every test case.
+func (t *tuner) evaluate(ctx context.Context, round int, prompt string) (cand TuneCandidate) {
+	start := t.spent
+	cand = TuneCandidate{Round: round, Prompt: prompt, Status: TuneStatusEvaluated}
+	defer func() { cand.Cost = t.spent - start }()
+
+	variant, err := withPhasePrompt(t.skill, t.config.PhaseID, prompt)
+	if err != nil {
+		cand.Status = TuneStatusInvalid
+		cand.Error = err.Error()
+		return cand
+	}
+
+	var total float64
+	for _, c := range t.cases {
+		if t.overBudget() || ctx.Err() != nil {
+			cand.Status = TuneStatusOverBudget
+			return cand
+		}
+		res := t.runCase(ctx, variant, c)
+		total += res.Score
+		cand.Cases = append(cand.Cases, res)
+	}
+	cand.Score = total / float64(len(t.cases))
+	return cand
+}
+
+// runCase runs and scores one test case. Failed runs score 0.
+func (t *tuner) runCase(ctx context.Context, variant *skill.Skill, c TuneCase) TuneCaseResult {
+	res := TuneCaseResult{Case: c.Name}
+
+	result, err := t.config.Run(ctx, variant, c.Input)
+	if result != nil {
+		t.spent += result.TotalCost
+		res.Output = result.FinalOutput
+	}
+	switch {
+	case err != nil:
+		res.Error = err.Error()
+		return res
+	case result.Status != PhaseStatusCompleted:
+		res.Error = fmt.Sprintf("run %s", result.Status)
+		if result.Error != nil {
+			res.Error = result.Error.Error()
+		}
+		return res
+	}
+
+	score, cost, err := t.config.Score(ctx, c, result)
+	t.spent += cost
+	if err != nil {
+		res.Error = "score: " + err.Error()
+		return res
+	}
+	res.Score = score
+	return res
+}
+
+// withPhasePrompt returns a copy of the skill with the prompt template of a
+// phase replaced.
+func withPhasePrompt(sk *skill.Skill, phaseID, prompt string) (*skill.Skill, error) {
+	phases := sk.Phases()
+	for i := range phases {
+		if phases[i].ID == phaseID {
+			phases[i].PromptTemplate = prompt
+		}
+	}
+	return sk.WithPhases(phases)
+}
+
+// validatePromptTemplate checks that a proposed prompt parses as a phase
+// prompt template.
+func validatePromptTemplate(prompt string) error {
+	if _, err := templates.Parse(prompt, nil); err != nil {
+		return fmt.Errorf("invalid prompt template: %w", err)
+	}
+	return nil
+}
+
+// NewLLMProposer returns a proposer that asks model on provider to rewrite
+// prompt templates. calc prices its completions and may be nil.
+func NewLLMProposer(provider ports.ProviderPort, model string, calc *domainProvider.CostCalculator) TuneProposeFunc {
+	return func(ctx context.Context, prompt string, results []TuneCaseResult) (string, float64, error) {
+		var feedback strings.Builder
+		for _, r := range results {
+			fmt.Fprintf(&feedback, "\n### %s (score %.2f)\n", r.Case, r.Score)
+			if r.Error != "" {
+				fmt.Fprintf(&feedback, "Error: %s\n", r.Error)
+				continue
+			}
+			out := r.Output
+			if len(out) > tuneOutputExcerpt {
+				out = out[:tuneOutputExcerpt] + "..."
+			}
+			feedback.WriteString(out + "\n")
+		}
+
+		resp, err := provider.Complete(ctx, ports.CompletionRequest{
+			ModelID: model,
+			SystemPrompt: "You improve prompt templates for language models. Rewrite the template " +
+				"so that it scores higher on the test cases. Keep every {{...}} template " +
+				"reference of the original. Reply with the new template only, without " +
+				"explanation or code fences.",
+			Messages: []ports.Message{{
+				Role: "user",
+				Content: "Prompt template:\n" + prompt +
+					"\n\nOutputs on the test cases, scored from 0 to 1:\n" + feedback.String(),
+			}},
+			MaxTokens:   skill.DefaultMaxTokens,
+			Temperature: 1,
+		})
+		cost := priceCompletion(calc, model, resp)
+		if err != nil {
+			return "", cost, err
+		}
+		return stripCodeFence(resp.Content), cost, nil
+	}
+}
+
+// NewTuneScorer returns a scorer that compares each phase output with the
+// golden run's, when the case has one, and has model on judge rate the final
+// output, when judge is not nil. The score is the mean of the two. calc
+// prices the judge's completions and may be nil.
+func NewTuneScorer(judge ports.ProviderPort, model string, calc *domainProvider.CostCalculator) TuneScoreFunc {
+	return func(ctx context.Context, c TuneCase, result *ExecutionResult) (float64, float64, error) {
+		var scores []float64
+		if len(c.Expected) > 0 {
+			var total float64
+			for id, want := range c.Expected {
+				var got string
+				if pr := result.PhaseResults[id]; pr != nil && pr.Status == PhaseStatusCompleted {
+					got = pr.Output
+				}
+				total += skills.OutputSimilarity(want, got)
+			}
+			scores = append(scores, total/float64(len(c.Expected)))
+		}
+
+		var cost float64
+		if judge != nil {
+			rating, resp, err := judgeOutput(ctx, judge, model, c.Input, result.FinalOutput)
+			cost = priceCompletion(calc, model, resp)
+			if err != nil {
+				return 0, cost, err
+			}
+			scores = append(scores, (rating-1)/9)
+		}
+
+		if len(scores) == 0 {
+			return 0, cost, errors.New("no golden outputs or judge to score against")
+		}
+		var sum float64
+		for _, s := range scores {
+			sum += s
+		}
+		return sum / float64(len(scores)), cost, nil
+	}
+}
+
+// priceCompletion returns the cost of a completion, or 0 if it cannot be
+// priced.
+func priceCompletion(calc *domainProvider.CostCalculator, model string, resp *ports.CompletionResponse) float64 {
+	if calc == nil || resp == nil {
+		return 0
+	}
+	if resp.ModelUsed != "" {
+		model = resp.ModelUsed
+	}
+	return calc.CalculateOrZero(model, resp.InputTokens, resp.OutputTokens).TotalCost
+}
+
+// stripCodeFence removes a Markdown code fence around a reply.
+func stripCodeFence(s string) string {
+	s = strings.TrimSpace(s)
+	if !strings.HasPrefix(s, "```") || !strings.HasSuffix(s, "```") || len(s) < 6 {
+		return s
+	}
+	s = strings.TrimSuffix(s, "```")
+	if _, body, ok := strings.Cut(s, "\n"); ok {
+		return strings.TrimSpace(body)
+	}
+	return ""
+}
@@ -0,0 +1,119 @@
+package workflow
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/jbctechsolutions/skillrunner/internal/domain/skill"
+)
+
+// tuneFixture runs a one-phase skill whose output is its prompt template,
+// scoring outputs by how many "!" they contain. Every run costs $0.10.
+func tuneFixture(t *testing.T, maxCost float64, proposals ...string) (*skill.Skill, TuneConfig) {
+	t.Helper()
+	s := createTestSkill(t, []skill.Phase{createTestPhase(t, "draft", "Draft", "Draft: {{._input}}", nil)})
+
+	next := 0
+	return s, TuneConfig{
+		PhaseID:  "draft",
+		Rounds:   2,
+		Variants: 2,
+		MaxCost:  maxCost,
+		Run: func(_ context.Context, sk *skill.Skill, input string) (*ExecutionResult, error) {
+			return &ExecutionResult{
+				Status:      PhaseStatusCompleted,
+				FinalOutput: sk.Phases()[0].PromptTemplate,
+				TotalCost:   0.1,
+			}, nil
+		},
+		Propose: func(context.Context, string, []TuneCaseResult) (string, float64, error) {
+			if next == len(proposals) {
+				return "", 0, nil
+			}
+			next++
+			return proposals[next-1], 0, nil
+		},
+		Score: func(_ context.Context, _ TuneCase, result *ExecutionResult) (float64, float64, error) {
+			return float64(strings.Count(result.FinalOutput, "!")) / 10, 0, nil
+		},
+	}
+}
+
+func TestRunTune_KeepsBestPrompt(t *testing.T) {
+	s, cfg := tuneFixture(t, 10, "Draft! {{._input}}", "{{", "Draft!!! {{._input}}", "Draft!! {{._input}}")
+	cases := []TuneCase{{Name: "a", Input: "x"}, {Name: "b", Input: "y"}}
+
+	report, err := RunTune(context.Background(), s, cases, cfg)
+	if err != nil {
+		t.Fatalf("RunTune() error = %v", err)
+	}
+
+	if report.Baseline.Score != 0 || len(report.Baseline.Cases) != 2 {
+		t.Errorf("Baseline = %+v", report.Baseline)
+	}
+	if len(report.Candidates) != 4 || report.Candidates[1].Status != TuneStatusInvalid {
+		t.Errorf("Candidates = %+v, want 4 with the unparsable one invalid", report.Candidates)
+	}
+	if report.Best == nil || report.Best.Prompt != "Draft!!! {{._input}}" || report.Best.Round != 2 {
+		t.Errorf("Best = %+v, want the round 2 prompt with three marks", report.Best)
+	}
+	if report.Exhausted || report.TotalCost < 0.79 || report.TotalCost > 0.81 {
+		t.Errorf("TotalCost = %v, Exhausted = %v; want $0.80 for 8 runs", report.TotalCost, report.Exhausted)
+	}
+}
+
+func TestRunTune_StopsAtCostCap(t *testing.T) {
+	s, cfg := tuneFixture(t, 0.3, "Draft! {{._input}}", "Draft!! {{._input}}")
+	cases := []TuneCase{{Name: "a", Input: "x"}, {Name: "b", Input: "y"}}
+
+	report, err := RunTune(context.Background(), s, cases, cfg)
+	if err != nil {
+		t.Fatalf("RunTune() error = %v", err)
+	}
+	if !report.Exhausted || len(report.Candidates) != 1 || report.Candidates[0].Status != TuneStatusOverBudget {
+		t.Errorf("report = %+v, want the first candidate cut short by the cap", report)
+	}
+	if report.Best != nil {
+		t.Errorf("Best = %+v, want nil for a partly scored candidate", report.Best)
+	}
+}
+
+func TestRunTune_NoImprovement(t *testing.T) {
+	s, cfg := tuneFixture(t, 10, "Plain {{._input}}")
+
+	report, err := RunTune(context.Background(), s, []TuneCase{{Name: "a", Input: "x"}}, cfg)
+	if err != nil {
+		t.Fatalf("RunTune() error = %v", err)
+	}
+	if report.Best != nil {
+		t.Errorf("Best = %+v, want nil when nothing beats the baseline", report.Best)
+	}
+}
+
+func TestNewTuneScorer_GoldenSimilarity(t *testing.T) {
+	score := NewTuneScorer(nil, "", nil)
+	result := &ExecutionResult{PhaseResults: map[string]*PhaseResult{
+		"draft": {Status: PhaseStatusCompleted, Output: "a b c d"},
+	}}
+
+	got, _, err := score(context.Background(), TuneCase{Expected: map[string]string{"draft": "a b c d", "polish": "e f"}}, result)
+	if err != nil || got != 0.5 {
+		t.Errorf("score = %v, %v; want 0.5 for one of two phases matching", got, err)
+	}
+	if _, _, err := score(context.Background(), TuneCase{Input: "x"}, result); err == nil {
+		t.Error("scoring without golden outputs or a judge should fail")
+	}
+}
+
+func TestStripCodeFence(t *testing.T) {
+	for in, want := range map[string]string{
+		"```\nSummarize {{._input}}\n```":     "Summarize {{._input}}",
+		"```text\nSummarize {{._input}}\n```": "Summarize {{._input}}",
+		"  Summarize {{._input}}\n":           "Summarize {{._input}}",
+	} {
+		if got := stripCodeFence(in); got != want {
+			t.Errorf("stripCodeFence(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package workflow
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/jbctechsolutions/skillrunner/internal/application/ports"
+	"github.com/jbctechsolutions/skillrunner/internal/domain/skill"
+)
+
+// ValidatorScriptTimeout bounds each run of a validator script.
+const ValidatorScriptTimeout = 30 * time.Second
+
+// maxRejectionLength truncates what a validator script printed when it is
+// shown to the model.
+const maxRejectionLength = 2000
+
+// ErrOutputValidation is returned when a phase's output still fails its
+// validators once its validation attempts are exhausted.
+var ErrOutputValidation = errors.New("phase output failed validation")
+
+// completePhase runs a completion for a phase (see completeJSON). For
+// phases with validators, an output a validator rejects is sent back to the
+// model with the validator's error, up to the phase's validation attempts.
+// Token usage is summed across attempts.
+func completePhase(ctx context.Context, phase *skill.Phase, req ports.CompletionRequest, complete completeFunc) (*ports.CompletionResponse, error) {
+	cfg := phase.Validation
+	if cfg == nil {
+		return completeJSON(ctx, phase, req, complete)
+	}
+
+	var inputTokens, outputTokens int
+	var lastErr error
+
+	for attempt := 0; attempt < cfg.Attempts(); attempt++ {
+		resp, err := completeJSON(ctx, phase, req, complete)
+		if err != nil {
+			return nil, err
+		}
+		inputTokens += resp.InputTokens
+		outputTokens += resp.OutputTokens
+
+		if lastErr = validateOutput(ctx, phase, resp.Content); lastErr == nil {
+			resp.InputTokens = inputTokens
+			resp.OutputTokens = outputTokens
+			return resp, nil
+		}
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+
+		// Show the model its own output and what was wrong with it
+		req.Messages = append(slices.Clip(req.Messages),
+			ports.Message{Role: "assistant", Content: resp.Content},
+			ports.Message{Role: "user", Content: fmt.Sprintf(
+				"Your previous response failed validation: %v. Respond again with a corrected response.", lastErr)},
+		)
+	}
+
+	return nil, fmt.Errorf("%w after %d attempts: %v", ErrOutputValidation, cfg.Attempts(), lastErr)
+}
+
+// validateOutput runs a phase's validators on its output in order,
+// returning the first one's error.
+func validateOutput(ctx context.Context, phase *skill.Phase, output string) error {
+	for _, v := range phase.Validation.Validators {
+		var err error
+		switch v.Kind() {
+		case skill.ValidatorRegex:
+			err = validateRegex(v.Regex, output)
+		case skill.ValidatorJSONSchema:
+			err = validateJSONOutput([]byte(extractJSON(output)), v.JSONSchema)
+		case skill.ValidatorScript:
+			err = runValidatorScript(ctx, phase.ID, v, output)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateRegex checks that output matches pattern.
+func validateRegex(pattern, output string) error {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return fmt.Errorf("%w: %v", skill.ErrInvalidValidatorRegex, err)
+	}
+	if !re.MatchString(output) {
+		return fmt.Errorf("the output does not match the pattern %s", pattern)
+	}
+	return nil
+}
+
+// runValidatorScript runs a validator's script with sh in its directory,
+// giving it the output on stdin and the phase ID as SR_PHASE_ID. A non-zero
+// exit rejects the output with what the script printed.
+func runValidatorScript(ctx context.Context, phaseID string, v skill.OutputValidator, output string) error {
+	ctx, cancel := context.WithTimeout(ctx, ValidatorScriptTimeout)
+	defer cancel()
+
+	var printed bytes.Buffer
+	cmd := exec.CommandContext(ctx, "sh", "-c", v.Script)
+	cmd.Dir = v.Dir
+	cmd.Stdin = strings.NewReader(output)
+	cmd.Stdout = &printed
+	cmd.Stderr = &printed
+	cmd.Env = append(os.Environ(), "SR_PHASE_ID="+phaseID)
+	if err := cmd.Run(); err != nil {
+		reason := strings.TrimSpace(printed.String())
+		if r := []rune(reason); len(r) > maxRejectionLength {
+			reason = string(r[:maxRejectionLength]) + "…"
+		}
+		if reason == "" {
+			reason = err.Error()
+		}
+		return fmt.Errorf("validator script %q rejected the output: %s", v.Script, reason)
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+package workflow
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/jbctechsolutions/skillrunner/internal/application/ports"
+	"github.com/jbctechsolutions/skillrunner/internal/domain/skill"
+)
+
+func TestCompletePhase_Validation(t *testing.T) {
+	phase := createTestPhase(t, "review", "Review", "Review {{._input}}", nil)
+	phase.WithValidation(&skill.ValidationConfig{Validators: []skill.OutputValidator{
+		{Regex: `(?m)^VERDICT: (PASS|FAIL)$`},
+		{Script: `grep -q "reviewed by $SR_PHASE_ID" || { echo "missing signature" >&2; exit 1; }`},
+	}})
+
+	responses := []string{"Looks fine", "VERDICT: PASS", "VERDICT: PASS\nreviewed by review"}
+	var requests []ports.CompletionRequest
+	complete := func(_ context.Context, req ports.CompletionRequest) (*ports.CompletionResponse, error) {
+		requests = append(requests, req)
+		return &ports.CompletionResponse{Content: responses[len(requests)-1], InputTokens: 10, OutputTokens: 2}, nil
+	}
+
+	resp, err := completePhase(context.Background(), &phase, ports.CompletionRequest{Messages: []ports.Message{{Role: "user", Content: "Review it"}}}, complete)
+	if err != nil {
+		t.Fatalf("completePhase() error = %v", err)
+	}
+	if resp.Content != responses[2] || resp.InputTokens != 30 || resp.OutputTokens != 6 {
+		t.Errorf("completePhase() = %q, %d/%d tokens; want the third response and 30/6", resp.Content, resp.InputTokens, resp.OutputTokens)
+	}
+	if got := requests[1].Messages[2].Content; !strings.Contains(got, "does not match the pattern") {
+		t.Errorf("first correction = %q, want the regex error", got)
+	}
+	if got := requests[2].Messages[4].Content; !strings.Contains(got, "missing signature") {
+		t.Errorf("second correction = %q, want what the script printed", got)
+	}
+}
+
+func TestCompletePhase_ValidationExhausted(t *testing.T) {
+	phase := createTestPhase(t, "extract", "Extract", "Extract {{._input}}", nil)
+	phase.WithValidation(&skill.ValidationConfig{
+		Validators:  []skill.OutputValidator{{JSONSchema: json.RawMessage(`{"type":"object","required":["name"]}`)}},
+		MaxAttempts: 2,
+	})
+
+	calls := 0
+	complete := func(context.Context, ports.CompletionRequest) (*ports.CompletionResponse, error) {
+		calls++
+		return &ports.CompletionResponse{Content: `{"title":"x"}`}, nil
+	}
+
+	_, err := completePhase(context.Background(), &phase, ports.CompletionRequest{}, complete)
+	if !errors.Is(err, ErrOutputValidation) || !strings.Contains(err.Error(), `missing required property "name"`) {
+		t.Errorf("completePhase() error = %v, want %v with the schema error", err, ErrOutputValidation)
+	}
+	if calls != 2 {
+		t.Errorf("provider called %d times, want 2", calls)
+	}
+}
+
+func TestExecutor_Validation(t *testing.T) {
+	provider := newMockProvider()
+	provider.completeFunc = func(_ context.Context, req ports.CompletionRequest) (*ports.CompletionResponse, error) {
+		if strings.Contains(req.Messages[len(req.Messages)-1].Content, "failed validation") {
+			return &ports.CompletionResponse{Content: "42"}, nil
+		}
+		return &ports.CompletionResponse{Content: "forty-two"}, nil
+	}
+
+	phase := createTestPhase(t, "count", "Count", "Count {{._input}}", nil)
+	phase.WithValidation(&skill.ValidationConfig{Validators: []skill.OutputValidator{{Regex: `^\d+$`}}})
+	s := createTestSkill(t, []skill.Phase{phase})
+
+	result, err := NewExecutor(provider, DefaultExecutorConfig()).Execute(context.Background(), s, "words")
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if result.FinalOutput != "42" {
+		t.Errorf("final output = %q, want the corrected output", result.FinalOutput)
+	}
+}
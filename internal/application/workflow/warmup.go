@@ -0,0 +1,88 @@
+package workflow
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/jbctechsolutions/skillrunner/internal/application/ports"
+	"github.com/jbctechsolutions/skillrunner/internal/domain/workflow"
+)
+
+// warmupTimeout bounds how long warming one model may take; loading a large
+// local model can take a while.
+const warmupTimeout = 2 * time.Minute
+
+// modelWarmer warms the providers and models of upcoming phases in the
+// background while earlier batches run, so a phase does not wait for a
+// local model to load or a connection to be set up when its batch starts.
+// Warming is best effort: failures are ignored and the phase proceeds as
+// usual.
+type modelWarmer struct {
+	provider    ports.ProviderPort
+	selector    PhaseProviderSelector
+	experiments PhaseExperimentRouter
+	profiles    PhaseProfileRouter
+	selectModel func(routingProfile string) string
+
+	mu     sync.Mutex
+	warmed map[string]bool // By provider and model
+}
+
+// newModelWarmer creates a warmer for one execution, or nil when warm-up is
+// disabled or phases run through a batch API.
+func newModelWarmer(provider ports.ProviderPort, config ExecutorConfig) *modelWarmer {
+	if !config.Warmup || config.BatchAPI {
+		return nil
+	}
+	return &modelWarmer{
+		provider:    provider,
+		selector:    config.ProviderSelector,
+		experiments: config.Experiments,
+		profiles:    config.Profiles,
+		selectModel: newPhaseExecutor(provider, "").selectModel,
+		warmed:      make(map[string]bool),
+	}
+}
+
+// warmAhead starts warming the models of the batch after batchIndex.
+func (w *modelWarmer) warmAhead(ctx context.Context, dag *workflow.DAG, batches [][]string, batchIndex int) {
+	if w == nil || batchIndex+1 >= len(batches) {
+		return
+	}
+
+	for _, phaseID := range batches[batchIndex+1] {
+		if phase := dag.GetPhase(phaseID); phase != nil && phase.IsCompletion() {
+			go w.warmPhase(ctx, phase.ID, dag)
+		}
+	}
+}
+
+// warmPhase resolves the provider and model of a phase the way its
+// execution will, and warms them once per execution.
+func (w *modelWarmer) warmPhase(ctx context.Context, phaseID string, dag *workflow.DAG) {
+	phase := dag.GetPhase(phaseID)
+	p, modelID, err := resolveProfileProvider(ctx, w.profiles, w.selector, w.provider, phase, w.selectModel)
+	if err != nil {
+		return
+	}
+	p, modelID, _ = applyExperiment(ctx, w.experiments, phase, p, modelID)
+
+	warmer, ok := ports.UnwrapProvider(p).(ports.Warmer)
+	if !ok {
+		return
+	}
+
+	key := p.Info().Name + "/" + modelID
+	w.mu.Lock()
+	if w.warmed[key] {
+		w.mu.Unlock()
+		return
+	}
+	w.warmed[key] = true
+	w.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(ctx, warmupTimeout)
+	defer cancel()
+	_ = warmer.Warm(ctx, modelID)
+}
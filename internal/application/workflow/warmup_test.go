@@ -0,0 +1,92 @@
+package workflow
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jbctechsolutions/skillrunner/internal/domain/skill"
+	"github.com/jbctechsolutions/skillrunner/internal/domain/workflow"
+)
+
+// warmingProvider is a namedMockProvider that reports the models it warms.
+type warmingProvider struct {
+	*namedMockProvider
+	warmed chan string
+}
+
+func (m *warmingProvider) Warm(_ context.Context, modelID string) error {
+	m.warmed <- modelID
+	return nil
+}
+
+func newWarmingProvider() *warmingProvider {
+	return &warmingProvider{
+		namedMockProvider: &namedMockProvider{mockProvider: newMockProvider(), name: "anthropic", models: []string{"claude-x"}},
+		warmed:            make(chan string, 10),
+	}
+}
+
+func TestExecutor_Execute_WarmsNextBatch(t *testing.T) {
+	draft := createTestPhase(t, "draft", "Draft", "Draft it", nil)
+	review := createTestPhase(t, "review", "Review", "Review it", []string{"draft"})
+	review.WithProvider("anthropic")
+	s := createTestSkill(t, []skill.Phase{draft, review})
+
+	t.Run("enabled", func(t *testing.T) {
+		pinned := newWarmingProvider()
+		config := DefaultExecutorConfig()
+		config.ProviderSelector = &stubSelector{pinned: pinned}
+		if _, err := NewExecutor(newMockProvider(), config).Execute(context.Background(), s, "input"); err != nil {
+			t.Fatalf("Execute failed: %v", err)
+		}
+
+		select {
+		case model := <-pinned.warmed:
+			if model != "claude-x" {
+				t.Errorf("expected claude-x to be warmed, got %q", model)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("expected the pinned phase's model to be warmed")
+		}
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		pinned := newWarmingProvider()
+		config := DefaultExecutorConfig()
+		config.ProviderSelector = &stubSelector{pinned: pinned}
+		config.Warmup = false
+		if _, err := NewExecutor(newMockProvider(), config).Execute(context.Background(), s, "input"); err != nil {
+			t.Fatalf("Execute failed: %v", err)
+		}
+
+		if len(pinned.warmed) != 0 {
+			t.Errorf("expected no warm-up, got %d", len(pinned.warmed))
+		}
+	})
+}
+
+func TestModelWarmer_WarmsOncePerModel(t *testing.T) {
+	review := createTestPhase(t, "review", "Review", "Review it", nil)
+	review.WithProvider("anthropic")
+	polish := createTestPhase(t, "polish", "Polish", "Polish it", nil)
+	polish.WithProvider("anthropic")
+	dag, err := workflow.NewDAG([]skill.Phase{review, polish})
+	if err != nil {
+		t.Fatalf("NewDAG failed: %v", err)
+	}
+
+	pinned := newWarmingProvider()
+	config := DefaultExecutorConfig()
+	config.ProviderSelector = &stubSelector{pinned: pinned}
+	warmer := newModelWarmer(newMockProvider(), config)
+	warmer.warmPhase(context.Background(), "review", dag)
+	warmer.warmPhase(context.Background(), "polish", dag)
+
+	if len(pinned.warmed) != 1 {
+		t.Errorf("expected the shared model to be warmed once, got %d", len(pinned.warmed))
+	}
+	if newModelWarmer(newMockProvider(), ExecutorConfig{Warmup: true, BatchAPI: true}) != nil {
+		t.Error("expected no warmer for batch API runs")
+	}
+}
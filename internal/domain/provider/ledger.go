@@ -0,0 +1,108 @@
+package provider
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// tagNamePattern matches valid cost attribution tag names.
+var tagNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.-]{0,63}$`)
+
+// costByTagPrefix prefixes the summary dimensions of cost attribution tags.
+const costByTagPrefix = "tag:"
+
+// CostRecord is an entry of the cost ledger: the cost of one model
+// invocation, attributed to the skill run it was made for.
+type CostRecord struct {
+	CostBreakdown
+	SkillID     string    // skill the invocation ran for
+	ExecutionID string    // execution (run) the invocation was part of
+	PhaseID     string    // phase the invocation served
+	RecordedAt  time.Time // when the invocation completed
+
+	// Tags are the cost attribution tags of the run, such as team=search
+	Tags map[string]string
+}
+
+// ParseCostTag parses a cost attribution tag given as name=value.
+func ParseCostTag(s string) (name, value string, err error) {
+	name, value, ok := strings.Cut(s, "=")
+	name, value = strings.TrimSpace(name), strings.TrimSpace(value)
+	if !ok || value == "" {
+		return "", "", fmt.Errorf("invalid tag %q: must be name=value", s)
+	}
+	if !tagNamePattern.MatchString(name) {
+		return "", "", fmt.Errorf("invalid tag name %q: use letters, digits, '.', '_' and '-'", name)
+	}
+	return name, value, nil
+}
+
+// ParseCostTags parses cost attribution tags given as name=value. A name
+// given twice is an error.
+func ParseCostTags(specs []string) (map[string]string, error) {
+	if len(specs) == 0 {
+		return nil, nil
+	}
+	tags := make(map[string]string, len(specs))
+	for _, spec := range specs {
+		name, value, err := ParseCostTag(spec)
+		if err != nil {
+			return nil, err
+		}
+		if _, dup := tags[name]; dup {
+			return nil, fmt.Errorf("tag %s given more than once", name)
+		}
+		tags[name] = value
+	}
+	return tags, nil
+}
+
+// CostGroupBy is a dimension the cost ledger can be summarized by.
+type CostGroupBy string
+
+// Dimensions of a cost ledger summary.
+const (
+	CostByProvider CostGroupBy = "provider"
+	CostBySkill    CostGroupBy = "skill"
+	CostByModel    CostGroupBy = "model"
+)
+
+// CostByTag returns the summary dimension of a cost attribution tag.
+func CostByTag(name string) CostGroupBy {
+	return CostGroupBy(costByTagPrefix + name)
+}
+
+// Tag returns the tag name of a tag dimension, and whether it is one.
+func (b CostGroupBy) Tag() (string, bool) {
+	return strings.CutPrefix(string(b), costByTagPrefix)
+}
+
+// ParseCostGroupBy parses a summary dimension: provider, skill, model or
+// tag:<name>.
+func ParseCostGroupBy(s string) (CostGroupBy, error) {
+	by := CostGroupBy(s)
+	if name, ok := by.Tag(); ok {
+		if !tagNamePattern.MatchString(name) {
+			return "", fmt.Errorf("invalid tag name %q", name)
+		}
+		return by, nil
+	}
+	switch by {
+	case CostByProvider, CostBySkill, CostByModel:
+		return by, nil
+	default:
+		return "", fmt.Errorf("invalid grouping %q: must be provider, skill, model or tag:<name>", s)
+	}
+}
+
+// CostGroup is the total of the ledger entries sharing a provider, skill,
+// model or tag value. Entries of runs without the tag have an empty key.
+type CostGroup struct {
+	Key          string  `json:"key"`
+	Invocations  int     `json:"invocations"`
+	InputTokens  int     `json:"input_tokens"`
+	OutputTokens int     `json:"output_tokens"`
+	TotalCost    float64 `json:"total_cost"`
+}
@@ -0,0 +1,36 @@
+package provider
+
+import "testing"
+
+func TestParseCostGroupBy(t *testing.T) {
+	for _, s := range []string{"provider", "skill", "model", "tag:team"} {
+		if by, err := ParseCostGroupBy(s); err != nil || string(by) != s {
+			t.Errorf("ParseCostGroupBy(%q) = %q, %v", s, by, err)
+		}
+	}
+	if _, err := ParseCostGroupBy("phase"); err == nil {
+		t.Error("ParseCostGroupBy(\"phase\") should fail")
+	}
+	if _, err := ParseCostGroupBy("tag:"); err == nil {
+		t.Error("ParseCostGroupBy(\"tag:\") should fail")
+	}
+	if name, ok := CostByTag("team").Tag(); !ok || name != "team" {
+		t.Errorf("CostByTag(team).Tag() = %q, %v", name, ok)
+	}
+}
+
+func TestParseCostTags(t *testing.T) {
+	tags, err := ParseCostTags([]string{"team=search", " ticket = JIRA-123 "})
+	if err != nil {
+		t.Fatalf("ParseCostTags() error = %v", err)
+	}
+	if len(tags) != 2 || tags["team"] != "search" || tags["ticket"] != "JIRA-123" {
+		t.Errorf("ParseCostTags() = %v", tags)
+	}
+
+	for _, bad := range [][]string{{"team"}, {"team="}, {"=search"}, {"te am=x"}, {"team=a", "team=b"}} {
+		if _, err := ParseCostTags(bad); err == nil {
+			t.Errorf("ParseCostTags(%q) should fail", bad)
+		}
+	}
+}
@@ -9,6 +9,8 @@ const (
 	ProviderAnthropic = "anthropic"
 	ProviderOpenAI    = "openai"
 	ProviderGroq      = "groq"
+	ProviderTogether  = "together"
+	ProviderFireworks = "fireworks"
 )
 
 // Common capability identifiers
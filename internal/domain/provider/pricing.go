@@ -13,6 +13,8 @@ package provider
 //   - Anthropic: https://docs.anthropic.com/en/docs/about-claude/models
 //   - OpenAI: https://openai.com/api/pricing/
 //   - Groq: https://groq.com/pricing/
+//   - Together AI: https://www.together.ai/pricing
+//   - Fireworks AI: https://fireworks.ai/pricing
 func DefaultModelPricing() []ModelCostRate {
 	return []ModelCostRate{
 		// ============================================
@@ -114,6 +116,36 @@ func DefaultModelPricing() []ModelCostRate {
 		// DeepSeek R1: $0.75/MTok input, $0.99/MTok output
 		{ModelID: "deepseek-r1-distill-llama-70b", Provider: ProviderGroq, InputRate: 0.00075, OutputRate: 0.00099, IsLocal: false},
 
+		// ============================================
+		// Together AI models
+		// https://www.together.ai/pricing
+		// ============================================
+
+		// Llama 3.3 70B Turbo: $0.88/MTok input, $0.88/MTok output
+		{ModelID: "meta-llama/Llama-3.3-70B-Instruct-Turbo", Provider: ProviderTogether, InputRate: 0.00088, OutputRate: 0.00088, IsLocal: false},
+		// Llama 3.1 8B Turbo: $0.18/MTok input, $0.18/MTok output
+		{ModelID: "meta-llama/Meta-Llama-3.1-8B-Instruct-Turbo", Provider: ProviderTogether, InputRate: 0.00018, OutputRate: 0.00018, IsLocal: false},
+		// Llama 3.1 405B Turbo: $3.50/MTok input, $3.50/MTok output
+		{ModelID: "meta-llama/Meta-Llama-3.1-405B-Instruct-Turbo", Provider: ProviderTogether, InputRate: 0.0035, OutputRate: 0.0035, IsLocal: false},
+		{ModelID: "Qwen/Qwen2.5-72B-Instruct-Turbo", Provider: ProviderTogether, InputRate: 0.0012, OutputRate: 0.0012, IsLocal: false},
+		{ModelID: "Qwen/Qwen2.5-Coder-32B-Instruct", Provider: ProviderTogether, InputRate: 0.0008, OutputRate: 0.0008, IsLocal: false},
+		{ModelID: "mistralai/Mixtral-8x7B-Instruct-v0.1", Provider: ProviderTogether, InputRate: 0.0006, OutputRate: 0.0006, IsLocal: false},
+		{ModelID: "deepseek-ai/DeepSeek-V3", Provider: ProviderTogether, InputRate: 0.00125, OutputRate: 0.00125, IsLocal: false},
+
+		// ============================================
+		// Fireworks AI models
+		// https://fireworks.ai/pricing
+		// ============================================
+
+		// Models above 16B parameters: $0.90/MTok input, $0.90/MTok output
+		{ModelID: "accounts/fireworks/models/llama-v3p3-70b-instruct", Provider: ProviderFireworks, InputRate: 0.0009, OutputRate: 0.0009, IsLocal: false},
+		// Models 4B-16B parameters: $0.20/MTok input, $0.20/MTok output
+		{ModelID: "accounts/fireworks/models/llama-v3p1-8b-instruct", Provider: ProviderFireworks, InputRate: 0.0002, OutputRate: 0.0002, IsLocal: false},
+		{ModelID: "accounts/fireworks/models/llama-v3p1-405b-instruct", Provider: ProviderFireworks, InputRate: 0.003, OutputRate: 0.003, IsLocal: false},
+		{ModelID: "accounts/fireworks/models/qwen2p5-72b-instruct", Provider: ProviderFireworks, InputRate: 0.0009, OutputRate: 0.0009, IsLocal: false},
+		{ModelID: "accounts/fireworks/models/mixtral-8x22b-instruct", Provider: ProviderFireworks, InputRate: 0.0012, OutputRate: 0.0012, IsLocal: false},
+		{ModelID: "accounts/fireworks/models/deepseek-v3", Provider: ProviderFireworks, InputRate: 0.0009, OutputRate: 0.0009, IsLocal: false},
+
 		// ============================================
 		// Ollama models (local, zero cost)
 		// All local models are free to run
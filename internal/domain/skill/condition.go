@@ -0,0 +1,110 @@
+package skill
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Operators of a phase condition.
+const (
+	// ConditionEquals compares the sides regardless of case and surrounding
+	// whitespace.
+	ConditionEquals = "=="
+	// ConditionNotEquals is the negation of ConditionEquals.
+	ConditionNotEquals = "!="
+	// ConditionMatches matches the left side against the regular expression
+	// on the right.
+	ConditionMatches = "=~"
+)
+
+// Condition validation errors.
+var (
+	ErrEmptyConditionSide    = errors.New("condition operand cannot be empty")
+	ErrUnclosedConditionTerm = errors.New("condition has an unclosed {{ or quote")
+	ErrInvalidConditionRegex = errors.New("condition =~ needs a valid regular expression")
+)
+
+// Condition is a parsed when or unless expression of a phase, such as
+// `{{.classify}} == "bug"`. Each side is a prompt template rendered with
+// the phase's dependency outputs, or a quoted literal. Without an operator,
+// the condition holds when the rendered Left is not empty, "false", "no"
+// or "0".
+type Condition struct {
+	Left     string
+	Operator string // ==, != or =~; empty tests Left alone
+	Right    string
+}
+
+// ParseCondition parses a when or unless expression, splitting it at the
+// first operator outside template actions and quotes.
+func ParseCondition(expr string) (*Condition, error) {
+	depth := 0
+	var quote byte
+	for i := 0; i < len(expr); i++ {
+		c := expr[i]
+		switch {
+		case strings.HasPrefix(expr[i:], "{{"):
+			depth++
+			i++
+		case strings.HasPrefix(expr[i:], "}}") && depth > 0:
+			depth--
+			i++
+		case depth > 0:
+		case quote != 0:
+			if c == '\\' && quote == '"' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case i+1 < len(expr) && (c == '=' || c == '!') && (expr[i+1] == '=' || (c == '=' && expr[i+1] == '~')):
+			cond := &Condition{
+				Left:     strings.TrimSpace(expr[:i]),
+				Operator: expr[i : i+2],
+				Right:    strings.TrimSpace(expr[i+2:]),
+			}
+			return cond, cond.validate()
+		}
+	}
+	if depth > 0 || quote != 0 {
+		return nil, fmt.Errorf("%w: %s", ErrUnclosedConditionTerm, expr)
+	}
+	cond := &Condition{Left: strings.TrimSpace(expr)}
+	return cond, cond.validate()
+}
+
+// validate checks that both sides are present and a literal regular
+// expression compiles.
+func (c *Condition) validate() error {
+	if c.Left == "" || (c.Operator != "" && c.Right == "") {
+		return ErrEmptyConditionSide
+	}
+	if c.Operator == ConditionMatches {
+		if pattern, ok := ConditionLiteral(c.Right); ok {
+			if _, err := regexp.Compile(pattern); err != nil {
+				return fmt.Errorf("%w: %v", ErrInvalidConditionRegex, err)
+			}
+		}
+	}
+	return nil
+}
+
+// ConditionLiteral returns the value of a quoted condition side, and false
+// for sides that are templates.
+func ConditionLiteral(side string) (string, bool) {
+	if len(side) < 2 {
+		return "", false
+	}
+	switch {
+	case side[0] == '"' && side[len(side)-1] == '"':
+		value, err := strconv.Unquote(side)
+		return value, err == nil
+	case side[0] == '\'' && side[len(side)-1] == '\'':
+		return side[1 : len(side)-1], true
+	}
+	return "", false
+}
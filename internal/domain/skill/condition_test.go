@@ -0,0 +1,74 @@
+package skill
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseCondition(t *testing.T) {
+	tests := []struct {
+		expr string
+		want Condition
+	}{
+		{`{{.classify}} == "bug"`, Condition{Left: "{{.classify}}", Operator: ConditionEquals, Right: `"bug"`}},
+		{`{{.classify}} != 'feature'`, Condition{Left: "{{.classify}}", Operator: ConditionNotEquals, Right: "'feature'"}},
+		{`{{.review}} =~ "(?i)approved"`, Condition{Left: "{{.review}}", Operator: ConditionMatches, Right: `"(?i)approved"`}},
+		{`{{if eq .lang "go"}}true{{end}}`, Condition{Left: `{{if eq .lang "go"}}true{{end}}`}},
+		{`"a == b" == {{.x}}`, Condition{Left: `"a == b"`, Operator: ConditionEquals, Right: "{{.x}}"}},
+		{`  {{.needs_tests}}  `, Condition{Left: "{{.needs_tests}}"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			got, err := ParseCondition(tt.expr)
+			if err != nil {
+				t.Fatalf("ParseCondition() error = %v", err)
+			}
+			if *got != tt.want {
+				t.Errorf("ParseCondition() = %+v, want %+v", *got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseCondition_Invalid(t *testing.T) {
+	tests := []struct {
+		expr string
+		want error
+	}{
+		{`{{.classify}} ==`, ErrEmptyConditionSide},
+		{`== "bug"`, ErrEmptyConditionSide},
+		{`{{.classify`, ErrUnclosedConditionTerm},
+		{`"bug`, ErrUnclosedConditionTerm},
+		{`{{.review}} =~ "(unclosed"`, ErrInvalidConditionRegex},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			if _, err := ParseCondition(tt.expr); !errors.Is(err, tt.want) {
+				t.Errorf("ParseCondition() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestConditionLiteral(t *testing.T) {
+	for side, want := range map[string]string{`"bug"`: "bug", `'bug'`: "bug", `"say \"hi\""`: `say "hi"`} {
+		if got, ok := ConditionLiteral(side); !ok || got != want {
+			t.Errorf("ConditionLiteral(%s) = %q, %v; want %q", side, got, ok, want)
+		}
+	}
+	for _, side := range []string{"{{.x}}", `"`, "bug"} {
+		if _, ok := ConditionLiteral(side); ok {
+			t.Errorf("ConditionLiteral(%s) is a literal, want a template", side)
+		}
+	}
+}
+
+func TestPhase_Validate_Condition(t *testing.T) {
+	p, _ := NewPhase("fix", "Fix", "Fix {{.classify}}")
+	if err := p.WithWhen(`{{.classify}} == "bug"`).WithUnless("{{.skip}}").Validate(); err != nil || !p.IsConditional() {
+		t.Errorf("Validate() = %v, IsConditional() = %v; want nil, true", err, p.IsConditional())
+	}
+	if err := p.WithUnless(`{{.skip}} ==`).Validate(); !errors.Is(err, ErrEmptyConditionSide) {
+		t.Errorf("Validate() = %v, want %v", err, ErrEmptyConditionSide)
+	}
+}
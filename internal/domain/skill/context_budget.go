@@ -0,0 +1,192 @@
+package skill
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+)
+
+// Prompt components a context budget allocates tokens to.
+const (
+	// ContextMemory is the project memory (MEMORY.md, CLAUDE.md).
+	ContextMemory = "memory"
+	// ContextInput is the original input of the run.
+	ContextInput = "input"
+	// ContextDependencies is the outputs of the phases the phase depends on.
+	ContextDependencies = "dependencies"
+	// ContextInstructions is the phase's rendered prompt.
+	ContextInstructions = "instructions"
+)
+
+// ContextComponents lists the prompt components in the order they appear
+// in a request.
+var ContextComponents = []string{ContextMemory, ContextInput, ContextDependencies, ContextInstructions}
+
+// Context budget validation errors.
+var (
+	ErrInvalidContextBudget   = errors.New("invalid context_budget: set either shares or priorities")
+	ErrInvalidContextTokens   = errors.New("context_budget max_tokens must be positive")
+	ErrUnknownContextPart     = errors.New("unknown context_budget component: must be memory, input, dependencies or instructions")
+	ErrInvalidContextShare    = errors.New("context_budget shares must be percentages adding up to at most 100")
+	ErrInvalidContextPriority = errors.New("context_budget priorities must be non-negative")
+	ErrContextBudgetConflict  = errors.New("context_budget cannot be combined with long_context")
+)
+
+// ContextBudgetConfig allocates the tokens of a request among its prompt
+// components when together they exceed the budget, so that each is trimmed
+// instead of the request failing. Components are allocated either a share
+// of the budget, with room a component does not need going to the others in
+// proportion to their shares, or by priority, higher priorities first, with
+// components of equal priority sharing what is left evenly.
+type ContextBudgetConfig struct {
+	MaxTokens  int            // tokens per request, including the output
+	Shares     map[string]int // percentage of the budget per component; unlisted ones split the rest evenly
+	Priorities map[string]int // priority per component; unlisted ones have priority 0
+}
+
+// Validate checks that exactly one allocation is set, over known
+// components, with valid values.
+func (c *ContextBudgetConfig) Validate() error {
+	if c.MaxTokens <= 0 {
+		return ErrInvalidContextTokens
+	}
+	if (len(c.Shares) == 0) == (len(c.Priorities) == 0) {
+		return ErrInvalidContextBudget
+	}
+
+	total := 0
+	for name, share := range c.Shares {
+		if !slices.Contains(ContextComponents, name) {
+			return fmt.Errorf("%w: got %q", ErrUnknownContextPart, name)
+		}
+		if share < 0 {
+			return fmt.Errorf("%w: %s is %d", ErrInvalidContextShare, name, share)
+		}
+		total += share
+	}
+	if total > 100 {
+		return fmt.Errorf("%w: got %d", ErrInvalidContextShare, total)
+	}
+	for name, priority := range c.Priorities {
+		if !slices.Contains(ContextComponents, name) {
+			return fmt.Errorf("%w: got %q", ErrUnknownContextPart, name)
+		}
+		if priority < 0 {
+			return fmt.Errorf("%w: %s is %d", ErrInvalidContextPriority, name, priority)
+		}
+	}
+	return nil
+}
+
+// Allocate returns the tokens each component may use out of available,
+// given the tokens each needs. Components that fit their allocation get
+// what they need.
+func (c *ContextBudgetConfig) Allocate(sizes map[string]int, available int) map[string]int {
+	if len(c.Priorities) == 0 {
+		return FillBudget(sizes, c.shareWeights(), available)
+	}
+
+	levels := make(map[int][]string)
+	for name := range sizes {
+		levels[c.Priorities[name]] = append(levels[c.Priorities[name]], name)
+	}
+	order := slices.Sorted(maps.Keys(levels))
+	slices.Reverse(order)
+
+	alloc := make(map[string]int, len(sizes))
+	for _, priority := range order {
+		group := make(map[string]int, len(levels[priority]))
+		for _, name := range levels[priority] {
+			group[name] = sizes[name]
+		}
+		for name, tokens := range FillBudget(group, nil, available) {
+			alloc[name] = tokens
+			available -= tokens
+		}
+	}
+	return alloc
+}
+
+// shareWeights returns the share of every component, splitting what the
+// listed shares leave among the unlisted components.
+func (c *ContextBudgetConfig) shareWeights() map[string]int {
+	weights := maps.Clone(c.Shares)
+	rest := 100
+	var unlisted []string
+	for _, name := range ContextComponents {
+		if share, ok := c.Shares[name]; ok {
+			rest -= share
+		} else {
+			unlisted = append(unlisted, name)
+		}
+	}
+	for _, name := range unlisted {
+		weights[name] = max(rest, 0) / len(unlisted)
+	}
+	return weights
+}
+
+// FillBudget divides available tokens among parts of the given sizes in
+// proportion to their weights, giving room a part does not need to the
+// others. Nil weights weigh every part equally; if no part that still
+// needs room has a weight, the room is divided evenly among them.
+func FillBudget(sizes map[string]int, weights map[string]int, available int) map[string]int {
+	alloc := make(map[string]int, len(sizes))
+	var open []string
+	for name, size := range sizes {
+		if size > 0 {
+			open = append(open, name)
+		} else {
+			alloc[name] = 0
+		}
+	}
+	slices.Sort(open) // Deterministic rounding
+
+	weight := func(name string) int {
+		if weights == nil {
+			return 1
+		}
+		return weights[name]
+	}
+
+	for len(open) > 0 && available > 0 {
+		total := 0
+		for _, name := range open {
+			total += weight(name)
+		}
+		quota := func(name string) int {
+			if total == 0 {
+				return available / len(open)
+			}
+			return available * weight(name) / total
+		}
+
+		// Parts that fit their quota take what they need; the rest is
+		// divided again among the others
+		var still []string
+		for _, name := range open {
+			if sizes[name] <= quota(name) {
+				alloc[name] = sizes[name]
+			} else {
+				still = append(still, name)
+			}
+		}
+		if len(still) == len(open) {
+			for _, name := range open {
+				alloc[name] = quota(name)
+			}
+			return alloc
+		}
+		for _, name := range open {
+			if !slices.Contains(still, name) {
+				available -= sizes[name]
+			}
+		}
+		open = still
+	}
+	for _, name := range open {
+		alloc[name] = 0 // No room left
+	}
+	return alloc
+}
@@ -0,0 +1,70 @@
+package skill
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestContextBudgetConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  ContextBudgetConfig
+		want error
+	}{
+		{"shares", ContextBudgetConfig{MaxTokens: 8000, Shares: map[string]int{ContextInput: 60, ContextMemory: 10}}, nil},
+		{"priorities", ContextBudgetConfig{MaxTokens: 8000, Priorities: map[string]int{ContextInstructions: 3}}, nil},
+		{"no tokens", ContextBudgetConfig{Shares: map[string]int{ContextInput: 60}}, ErrInvalidContextTokens},
+		{"neither", ContextBudgetConfig{MaxTokens: 8000}, ErrInvalidContextBudget},
+		{"both", ContextBudgetConfig{MaxTokens: 8000, Shares: map[string]int{ContextInput: 60}, Priorities: map[string]int{ContextInput: 1}}, ErrInvalidContextBudget},
+		{"unknown", ContextBudgetConfig{MaxTokens: 8000, Shares: map[string]int{"workspace": 60}}, ErrUnknownContextPart},
+		{"over 100", ContextBudgetConfig{MaxTokens: 8000, Shares: map[string]int{ContextInput: 60, ContextMemory: 50}}, ErrInvalidContextShare},
+		{"negative priority", ContextBudgetConfig{MaxTokens: 8000, Priorities: map[string]int{ContextMemory: -1}}, ErrInvalidContextPriority},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.cfg.Validate(); !errors.Is(err, tt.want) {
+				t.Errorf("Validate() = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestContextBudgetConfig_AllocateShares(t *testing.T) {
+	cfg := &ContextBudgetConfig{MaxTokens: 2000, Shares: map[string]int{ContextInput: 50, ContextDependencies: 30}}
+	sizes := map[string]int{ContextMemory: 50, ContextInput: 3000, ContextDependencies: 3000, ContextInstructions: 100}
+
+	alloc := cfg.Allocate(sizes, 1000)
+	// Memory and instructions fit their 10% each; input and dependencies
+	// split the remaining 850 tokens 5:3
+	want := map[string]int{ContextMemory: 50, ContextInput: 531, ContextDependencies: 318, ContextInstructions: 100}
+	for name, tokens := range want {
+		if alloc[name] != tokens {
+			t.Errorf("alloc[%s] = %d, want %d", name, alloc[name], tokens)
+		}
+	}
+}
+
+func TestContextBudgetConfig_AllocatePriorities(t *testing.T) {
+	cfg := &ContextBudgetConfig{MaxTokens: 2000, Priorities: map[string]int{ContextInstructions: 2, ContextInput: 1, ContextDependencies: 1}}
+	sizes := map[string]int{ContextMemory: 200, ContextInput: 900, ContextDependencies: 300, ContextInstructions: 400}
+
+	alloc := cfg.Allocate(sizes, 1000)
+	want := map[string]int{ContextInstructions: 400, ContextDependencies: 300, ContextInput: 300, ContextMemory: 0}
+	for name, tokens := range want {
+		if alloc[name] != tokens {
+			t.Errorf("alloc[%s] = %d, want %d", name, alloc[name], tokens)
+		}
+	}
+}
+
+func TestFillBudget(t *testing.T) {
+	alloc := FillBudget(map[string]int{"a": 10, "b": 500, "c": 500, "d": 0}, nil, 300)
+	if alloc["a"] != 10 || alloc["b"] != 145 || alloc["c"] != 145 || alloc["d"] != 0 {
+		t.Errorf("FillBudget() = %v, want a whole, b and c sharing the rest", alloc)
+	}
+
+	alloc = FillBudget(map[string]int{"a": 100, "b": 100}, nil, 500)
+	if alloc["a"] != 100 || alloc["b"] != 100 {
+		t.Errorf("FillBudget() = %v, want everything when it fits", alloc)
+	}
+}
@@ -0,0 +1,25 @@
+package skill
+
+import "slices"
+
+// Template variables holding the files a run takes as input, such as those
+// given with sr run --input. Every phase sees them; they are empty when the
+// run has no input files.
+const (
+	// FilesVariable holds the contents of the files, each under its path.
+	FilesVariable = "_files"
+	// FilePathsVariable holds the paths of the files, one per line.
+	FilePathsVariable = "_file_paths"
+	// FileChunksVariable holds a JSON array of the files in chunks of about
+	// the chunk size, for the items of a foreach phase.
+	FileChunksVariable = "_file_chunks"
+)
+
+// FileVariables lists the template variables of a run's input files.
+var FileVariables = []string{FilesVariable, FilePathsVariable, FileChunksVariable}
+
+// IsFileVariable reports whether name is a template variable of a run's
+// input files.
+func IsFileVariable(name string) bool {
+	return slices.Contains(FileVariables, name)
+}
@@ -0,0 +1,88 @@
+package skill
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// How a foreach phase splits its items template's output into items.
+const (
+	// ForEachSplitAuto splits a JSON array into its elements and anything
+	// else into lines.
+	ForEachSplitAuto = "auto"
+	// ForEachSplitJSON splits a JSON array into its elements.
+	ForEachSplitJSON = "json"
+	// ForEachSplitLines splits the output into its non-empty lines.
+	ForEachSplitLines = "lines"
+)
+
+// How a foreach phase aggregates the outputs of its items.
+const (
+	// ForEachAggregateConcat joins the outputs with the separator.
+	ForEachAggregateConcat = "concat"
+	// ForEachAggregateJSON collects the outputs into a JSON array.
+	ForEachAggregateJSON = "json"
+)
+
+// DefaultForEachSeparator joins the outputs of a foreach phase's items
+// unless it sets another separator.
+const DefaultForEachSeparator = "\n\n"
+
+// MaxForEachParallel is the largest number of items a foreach phase may
+// run at once.
+const MaxForEachParallel = 32
+
+// Foreach validation errors.
+var (
+	ErrEmptyForEachItems       = errors.New("foreach items cannot be empty")
+	ErrInvalidForEachSplit     = errors.New("invalid foreach split: must be auto, json or lines")
+	ErrInvalidForEachAggregate = errors.New("invalid foreach aggregate: must be concat or json")
+	ErrInvalidForEachParallel  = errors.New("foreach parallel must be between 0 and 32")
+)
+
+// ForEachConfig runs a phase once per item of a list, such as the files an
+// earlier phase listed, and aggregates the outputs of the items into the
+// phase's output. Each run sees the item as {{._item}} and its position,
+// counting from 0, as {{._index}}.
+type ForEachConfig struct {
+	Items     string // template rendering the list, e.g. "{{.list_files}}"
+	Split     string // auto (default), json or lines
+	Parallel  int    // items run at once; 0 means 1
+	Aggregate string // concat (default) or json
+	Separator string // joins concatenated outputs; empty means DefaultForEachSeparator
+}
+
+// Workers returns the number of items run at once.
+func (c *ForEachConfig) Workers() int {
+	return max(c.Parallel, 1)
+}
+
+// Joiner returns the separator of concatenated outputs.
+func (c *ForEachConfig) Joiner() string {
+	if c.Separator == "" {
+		return DefaultForEachSeparator
+	}
+	return c.Separator
+}
+
+// Validate checks the items template, split, parallelism and aggregation.
+func (c *ForEachConfig) Validate() error {
+	if strings.TrimSpace(c.Items) == "" {
+		return ErrEmptyForEachItems
+	}
+	switch c.Split {
+	case "", ForEachSplitAuto, ForEachSplitJSON, ForEachSplitLines:
+	default:
+		return fmt.Errorf("%w: got %q", ErrInvalidForEachSplit, c.Split)
+	}
+	if c.Parallel < 0 || c.Parallel > MaxForEachParallel {
+		return fmt.Errorf("%w: got %d", ErrInvalidForEachParallel, c.Parallel)
+	}
+	switch c.Aggregate {
+	case "", ForEachAggregateConcat, ForEachAggregateJSON:
+		return nil
+	default:
+		return fmt.Errorf("%w: got %q", ErrInvalidForEachAggregate, c.Aggregate)
+	}
+}
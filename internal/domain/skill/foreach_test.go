@@ -0,0 +1,50 @@
+package skill
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestForEachConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  ForEachConfig
+		want error
+	}{
+		{"defaults", ForEachConfig{Items: "{{.list}}"}, nil},
+		{"all set", ForEachConfig{Items: "{{.list}}", Split: ForEachSplitJSON, Parallel: 4, Aggregate: ForEachAggregateJSON}, nil},
+		{"no items", ForEachConfig{Items: "  "}, ErrEmptyForEachItems},
+		{"unknown split", ForEachConfig{Items: "{{.list}}", Split: "csv"}, ErrInvalidForEachSplit},
+		{"negative parallel", ForEachConfig{Items: "{{.list}}", Parallel: -1}, ErrInvalidForEachParallel},
+		{"too parallel", ForEachConfig{Items: "{{.list}}", Parallel: MaxForEachParallel + 1}, ErrInvalidForEachParallel},
+		{"unknown aggregate", ForEachConfig{Items: "{{.list}}", Aggregate: "sum"}, ErrInvalidForEachAggregate},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.cfg.Validate(); !errors.Is(err, tt.want) {
+				t.Errorf("Validate() = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestForEachConfig_Defaults(t *testing.T) {
+	cfg := &ForEachConfig{Items: "{{.list}}"}
+	if cfg.Workers() != 1 || cfg.Joiner() != DefaultForEachSeparator {
+		t.Errorf("Workers() = %d, Joiner() = %q; want 1, %q", cfg.Workers(), cfg.Joiner(), DefaultForEachSeparator)
+	}
+	cfg = &ForEachConfig{Items: "{{.list}}", Parallel: 8, Separator: "\n---\n"}
+	if cfg.Workers() != 8 || cfg.Joiner() != "\n---\n" {
+		t.Errorf("Workers() = %d, Joiner() = %q; want 8, the separator", cfg.Workers(), cfg.Joiner())
+	}
+}
+
+func TestPhase_Validate_ForEach(t *testing.T) {
+	p, _ := NewPhase("review", "Review", "Review {{._item}}")
+	if err := p.WithForEach(&ForEachConfig{Items: "{{.list_files}}"}).Validate(); err != nil {
+		t.Errorf("Validate() = %v, want nil", err)
+	}
+	if err := p.WithForEach(&ForEachConfig{}).Validate(); !errors.Is(err, ErrEmptyForEachItems) {
+		t.Errorf("Validate() = %v, want %v", err, ErrEmptyForEachItems)
+	}
+}
@@ -0,0 +1,63 @@
+package skill
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Actions a glossary takes on terminology violations in an output.
+const (
+	// GlossaryActionReport keeps the output and reports the violations.
+	GlossaryActionReport = "report"
+	// GlossaryActionCorrect replaces the violations with the glossary terms
+	// and reports the corrections.
+	GlossaryActionCorrect = "correct"
+)
+
+// Glossary validation errors.
+var (
+	ErrEmptyGlossaryTerm     = errors.New("glossary term cannot be empty")
+	ErrInvalidGlossaryAction = errors.New("invalid glossary action: must be report or correct")
+	ErrGlossaryPhase         = errors.New("glossary applies to completion phases with text output only")
+)
+
+// GlossaryTerm is a required spelling, such as a product name, and the
+// phrasings to use it instead of.
+type GlossaryTerm struct {
+	Term  string   // required spelling; enforced letter for letter if it has capitals
+	Avoid []string // variants to replace with Term, matched regardless of case
+}
+
+// Glossary is the terminology a phase's output must follow, as laid down by
+// a team's style guide.
+type Glossary struct {
+	Terms  []GlossaryTerm
+	Action string // report (default) or correct
+}
+
+// Corrects reports whether violations are replaced in the output rather
+// than only reported.
+func (g *Glossary) Corrects() bool {
+	return g.Action == GlossaryActionCorrect
+}
+
+// Validate checks the terms and action.
+func (g *Glossary) Validate() error {
+	for _, t := range g.Terms {
+		if strings.TrimSpace(t.Term) == "" {
+			return ErrEmptyGlossaryTerm
+		}
+		for _, v := range t.Avoid {
+			if strings.TrimSpace(v) == "" {
+				return fmt.Errorf("%w: avoid list of %q", ErrEmptyGlossaryTerm, t.Term)
+			}
+		}
+	}
+	switch g.Action {
+	case "", GlossaryActionReport, GlossaryActionCorrect:
+		return nil
+	default:
+		return fmt.Errorf("%w: got %q", ErrInvalidGlossaryAction, g.Action)
+	}
+}
@@ -0,0 +1,38 @@
+package skill
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestGlossary_Validate(t *testing.T) {
+	terms := []GlossaryTerm{{Term: "GitHub"}, {Term: "sign in", Avoid: []string{"log in", "login"}}}
+	tests := []struct {
+		name     string
+		glossary Glossary
+		want     error
+	}{
+		{"report", Glossary{Terms: terms}, nil},
+		{"correct", Glossary{Terms: terms, Action: GlossaryActionCorrect}, nil},
+		{"empty term", Glossary{Terms: []GlossaryTerm{{Term: " "}}}, ErrEmptyGlossaryTerm},
+		{"empty variant", Glossary{Terms: []GlossaryTerm{{Term: "sign in", Avoid: []string{""}}}}, ErrEmptyGlossaryTerm},
+		{"unknown action", Glossary{Terms: terms, Action: "fix"}, ErrInvalidGlossaryAction},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.glossary.Validate(); !errors.Is(err, tt.want) {
+				t.Errorf("Validate() = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestPhase_Validate_Glossary(t *testing.T) {
+	p, _ := NewPhase("p1", "Phase 1", "Document {{._input}}")
+	if err := p.WithGlossary(&Glossary{Terms: []GlossaryTerm{{Term: "GitHub"}}}).Validate(); err != nil {
+		t.Errorf("Validate() = %v, want nil", err)
+	}
+	if err := p.WithOutputFormat(OutputFormatJSON).Validate(); !errors.Is(err, ErrGlossaryPhase) {
+		t.Errorf("Validate() with json output = %v, want %v", err, ErrGlossaryPhase)
+	}
+}
@@ -0,0 +1,108 @@
+package skill
+
+import (
+	"errors"
+	"fmt"
+)
+
+// LongContextSlidingWindow processes an input larger than the context window
+// in consecutive windows, passing a rolling summary of the earlier windows to
+// each one and answering the prompt in the last.
+const LongContextSlidingWindow = "sliding_window"
+
+// LongContextMapReduce processes an input larger than the context window by
+// summarizing each chunk independently, merging the partial summaries in a
+// tree until they fit one request, and answering the prompt from the merged
+// summaries. Each level can run on a different routing profile.
+const LongContextMapReduce = "map_reduce"
+
+// LongContextChunked processes an input larger than the context window by
+// running the phase's own prompt on each chunk, and merging the chunk
+// outputs into the phase's output with a reduce request.
+const LongContextChunked = "chunked"
+
+// DefaultCarryoverTokens is the summary budget used when a long context
+// configuration does not set one.
+const DefaultCarryoverTokens = 512
+
+// Long-context validation errors.
+var (
+	ErrInvalidLongContextStrategy = errors.New("invalid long_context strategy: must be sliding_window, map_reduce or chunked")
+	ErrInvalidWindowTokens        = errors.New("long_context window_tokens must be positive")
+	ErrInvalidCarryoverTokens     = errors.New("long_context carryover_tokens must be positive and less than half of window_tokens")
+	ErrInvalidLevelProfile        = errors.New("long_context map_profile and reduce_profile must be valid profile names and require map_reduce or chunked")
+	ErrReducePromptStrategy       = errors.New("long_context reduce_prompt requires strategy chunked")
+)
+
+// LongContextConfig configures how a phase handles input that does not fit
+// in one request.
+type LongContextConfig struct {
+	Strategy        string // sliding_window, map_reduce or chunked
+	WindowTokens    int    // tokens per request, including the carryover and the output
+	CarryoverTokens int    // maximum length of each summary: the rolling summary, or a partial summary for map_reduce
+	MapProfile      string // map_reduce: routing profile that summarizes the chunks; chunked: that runs them, empty uses the phase's
+	ReduceProfile   string // map_reduce and chunked: routing profile that merges partial results; empty uses the phase's
+	ReducePrompt    string // chunked: instruction that merges the chunk outputs; empty uses a generic merge
+}
+
+// NewSlidingWindow returns a sliding-window configuration. A carryover of 0
+// selects DefaultCarryoverTokens, reduced to a quarter of the window for
+// small windows.
+func NewSlidingWindow(windowTokens, carryoverTokens int) *LongContextConfig {
+	if carryoverTokens == 0 {
+		carryoverTokens = min(DefaultCarryoverTokens, windowTokens/4)
+	}
+	return &LongContextConfig{
+		Strategy:        LongContextSlidingWindow,
+		WindowTokens:    windowTokens,
+		CarryoverTokens: carryoverTokens,
+	}
+}
+
+// NewMapReduce returns a map-reduce configuration whose chunks are
+// summarized on the cheap profile. A carryover of 0 is defaulted as for
+// NewSlidingWindow.
+func NewMapReduce(windowTokens, carryoverTokens int) *LongContextConfig {
+	c := NewSlidingWindow(windowTokens, carryoverTokens)
+	c.Strategy = LongContextMapReduce
+	c.MapProfile = RoutingProfileCheap
+	return c
+}
+
+// NewChunked returns a chunked configuration. A carryover of 0 is defaulted
+// as for NewSlidingWindow; it bounds the merged results of intermediate
+// reduce levels.
+func NewChunked(windowTokens, carryoverTokens int) *LongContextConfig {
+	c := NewSlidingWindow(windowTokens, carryoverTokens)
+	c.Strategy = LongContextChunked
+	return c
+}
+
+// Validate checks the strategy and that the carryover leaves at least half
+// of each window for new input and output.
+func (c *LongContextConfig) Validate() error {
+	switch c.Strategy {
+	case LongContextSlidingWindow:
+		if c.MapProfile != "" || c.ReduceProfile != "" {
+			return ErrInvalidLevelProfile
+		}
+	case LongContextMapReduce, LongContextChunked:
+		for _, profile := range []string{c.MapProfile, c.ReduceProfile} {
+			if profile != "" && !IsValidProfileName(profile) {
+				return fmt.Errorf("%w: got %q", ErrInvalidLevelProfile, profile)
+			}
+		}
+	default:
+		return fmt.Errorf("%w: got %q", ErrInvalidLongContextStrategy, c.Strategy)
+	}
+	if c.ReducePrompt != "" && c.Strategy != LongContextChunked {
+		return ErrReducePromptStrategy
+	}
+	if c.WindowTokens <= 0 {
+		return ErrInvalidWindowTokens
+	}
+	if c.CarryoverTokens <= 0 || c.CarryoverTokens*2 >= c.WindowTokens {
+		return fmt.Errorf("%w: got %d of %d", ErrInvalidCarryoverTokens, c.CarryoverTokens, c.WindowTokens)
+	}
+	return nil
+}
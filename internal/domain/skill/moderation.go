@@ -0,0 +1,43 @@
+package skill
+
+import (
+	"errors"
+	"fmt"
+)
+
+// What happens to a request the moderation check flags before it is sent to
+// a provider.
+const (
+	// ModerationBlock fails the request; its prompt is not sent.
+	ModerationBlock = "block"
+	// ModerationWarn sends the request and reports what was flagged.
+	ModerationWarn = "warn"
+	// ModerationOff sends the request without checking it.
+	ModerationOff = "off"
+)
+
+// ErrInvalidModeration is returned for a moderation action other than block,
+// warn or off.
+var ErrInvalidModeration = errors.New("invalid moderation: must be block, warn or off")
+
+// ValidateModeration checks a moderation action; empty means the provider's.
+func ValidateModeration(action string) error {
+	switch action {
+	case "", ModerationBlock, ModerationWarn, ModerationOff:
+		return nil
+	default:
+		return fmt.Errorf("%w: got %q", ErrInvalidModeration, action)
+	}
+}
+
+// Moderation returns what happens to the skill's requests the moderation
+// check flags, overriding the providers' action, or "" for theirs.
+func (s *Skill) Moderation() string {
+	return s.moderation
+}
+
+// SetModeration sets what happens to the skill's requests the moderation
+// check flags.
+func (s *Skill) SetModeration(action string) {
+	s.moderation = action
+}
@@ -0,0 +1,26 @@
+package skill
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestSkill_Validate_Moderation(t *testing.T) {
+	p, _ := NewPhase("a", "A", "{{._input}}")
+	s, err := NewSkill("review", "Review", "1.0.0", []Phase{*p})
+	if err != nil {
+		t.Fatalf("NewSkill() error = %v", err)
+	}
+
+	for _, action := range []string{"", ModerationBlock, ModerationWarn, ModerationOff} {
+		s.SetModeration(action)
+		if err := s.Validate(); err != nil {
+			t.Errorf("Validate() with moderation %q = %v, want nil", action, err)
+		}
+	}
+
+	s.SetModeration("ask")
+	if err := s.Validate(); !errors.Is(err, ErrInvalidModeration) {
+		t.Errorf("Validate() = %v, want %v", err, ErrInvalidModeration)
+	}
+}
@@ -0,0 +1,85 @@
+package skill
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// Where an output sink delivers an output.
+const (
+	// OutputSinkFile writes the output to a file.
+	OutputSinkFile = "file"
+	// OutputSinkStdout writes the output to standard output as is.
+	OutputSinkStdout = "stdout"
+	// OutputSinkClipboard copies the output to the system clipboard.
+	OutputSinkClipboard = "clipboard"
+)
+
+// Output sink validation errors.
+var (
+	ErrInvalidOutputSinkType  = errors.New("invalid output sink type: must be file, stdout or clipboard")
+	ErrOutputSinkPathRequired = errors.New("file output sink requires a path")
+	ErrOutputSinkPath         = errors.New("output sink path applies to file sinks only")
+	ErrOutputSinkPhase        = errors.New("output sink phase not found")
+	ErrOutputSinkPathNotLocal = errors.New("file output sink path must be relative and stay within the working directory")
+)
+
+// OutputSink is where a completed run of a skill delivers an output: its
+// final output, or the output of one of its phases.
+type OutputSink struct {
+	Type  string // file, stdout or clipboard
+	Path  string // file name template of file sinks, e.g. "reviews/{{.skill}}-{{.timestamp}}.md"
+	Phase string // phase whose output is delivered; empty means the final output
+}
+
+// Validate checks the type and path of the sink.
+func (o OutputSink) Validate() error {
+	switch o.Type {
+	case OutputSinkFile:
+		if strings.TrimSpace(o.Path) == "" {
+			return ErrOutputSinkPathRequired
+		}
+		if !filepath.IsLocal(o.Path) {
+			return fmt.Errorf("%w: %q", ErrOutputSinkPathNotLocal, o.Path)
+		}
+	case OutputSinkStdout, OutputSinkClipboard:
+		if o.Path != "" {
+			return fmt.Errorf("%w: %s sink has path %q", ErrOutputSinkPath, o.Type, o.Path)
+		}
+	default:
+		return fmt.Errorf("%w: got %q", ErrInvalidOutputSinkType, o.Type)
+	}
+	return nil
+}
+
+// OutputSinks returns a copy of where runs of the skill deliver outputs.
+func (s *Skill) OutputSinks() []OutputSink {
+	sinks := make([]OutputSink, len(s.outputs))
+	copy(sinks, s.outputs)
+	return sinks
+}
+
+// SetOutputSinks sets where runs of the skill deliver outputs.
+func (s *Skill) SetOutputSinks(sinks []OutputSink) {
+	s.outputs = make([]OutputSink, len(sinks))
+	copy(s.outputs, sinks)
+}
+
+// validateOutputSinks checks the skill's output sinks and that the phases
+// they deliver exist.
+func (s *Skill) validateOutputSinks() error {
+	for _, o := range s.outputs {
+		if err := o.Validate(); err != nil {
+			return err
+		}
+		if o.Phase == "" {
+			continue
+		}
+		if _, err := s.GetPhase(o.Phase); err != nil {
+			return fmt.Errorf("%w: %s", ErrOutputSinkPhase, o.Phase)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,44 @@
+package skill
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestSkill_Validate_OutputSinks(t *testing.T) {
+	p, _ := NewPhase("review", "Review", "{{._input}}")
+	s, err := NewSkill("review", "Review", "1.0.0", []Phase{*p})
+	if err != nil {
+		t.Fatalf("NewSkill() error = %v", err)
+	}
+
+	s.SetOutputSinks([]OutputSink{
+		{Type: OutputSinkFile, Path: "reviews/{{.execution_id}}.md"},
+		{Type: OutputSinkStdout, Phase: "review"},
+		{Type: OutputSinkClipboard},
+	})
+	if err := s.Validate(); err != nil {
+		t.Errorf("Validate() = %v, want nil", err)
+	}
+
+	tests := []struct {
+		name string
+		sink OutputSink
+		want error
+	}{
+		{"unknown type", OutputSink{Type: "email"}, ErrInvalidOutputSinkType},
+		{"file without path", OutputSink{Type: OutputSinkFile}, ErrOutputSinkPathRequired},
+		{"absolute path", OutputSink{Type: OutputSinkFile, Path: "/etc/cron.d/job"}, ErrOutputSinkPathNotLocal},
+		{"escaping path", OutputSink{Type: OutputSinkFile, Path: "../../.bashrc"}, ErrOutputSinkPathNotLocal},
+		{"clipboard with path", OutputSink{Type: OutputSinkClipboard, Path: "out.md"}, ErrOutputSinkPath},
+		{"missing phase", OutputSink{Type: OutputSinkStdout, Phase: "summary"}, ErrOutputSinkPhase},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s.SetOutputSinks([]OutputSink{tt.sink})
+			if err := s.Validate(); !errors.Is(err, tt.want) {
+				t.Errorf("Validate() = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
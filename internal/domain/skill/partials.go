@@ -0,0 +1,21 @@
+package skill
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrInvalidPartialName is returned for a partial whose name is empty or
+// contains whitespace or quotes.
+var ErrInvalidPartialName = errors.New("invalid partial name: must be non-empty without whitespace or quotes")
+
+// ValidatePartials checks the names of partial templates.
+func ValidatePartials(partials map[string]string) error {
+	for name := range partials {
+		if name == "" || strings.ContainsAny(name, " \t\n\"'`") {
+			return fmt.Errorf("%w: %q", ErrInvalidPartialName, name)
+		}
+	}
+	return nil
+}
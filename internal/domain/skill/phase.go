@@ -2,9 +2,13 @@
 package skill
 
 import (
+	"bytes"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"slices"
 	"strings"
+	"time"
 )
 
 // Routing profiles for phase execution.
@@ -14,6 +18,23 @@ const (
 	RoutingProfilePremium  = "premium"
 )
 
+// Output formats for phase responses.
+const (
+	OutputFormatText  = "text"
+	OutputFormatJSON  = "json"
+	OutputFormatImage = "image"
+)
+
+// Table formats that Markdown tables in phase outputs can be extracted to.
+const (
+	TableFormatCSV  = "csv"
+	TableFormatTSV  = "tsv"
+	TableFormatJSON = "json"
+)
+
+// MaxImageCount limits how many images one phase may generate.
+const MaxImageCount = 10
+
 // Default values for Phase configuration.
 const (
 	DefaultRoutingProfile = RoutingProfileBalanced
@@ -26,9 +47,17 @@ var (
 	ErrPhaseIDRequired             = errors.New("phase id is required")
 	ErrPhaseNameRequired           = errors.New("phase name is required")
 	ErrPhasePromptTemplateRequired = errors.New("phase prompt template is required")
-	ErrInvalidRoutingProfile       = errors.New("invalid routing profile: must be cheap, balanced, or premium")
+	ErrInvalidRoutingProfile       = errors.New("invalid routing profile: must be cheap, balanced, premium or a custom profile name")
 	ErrInvalidMaxTokens            = errors.New("max tokens must be positive")
 	ErrInvalidTemperature          = errors.New("temperature must be between 0.0 and 2.0")
+	ErrPinSoftWithoutProvider      = errors.New("pin_soft requires a pinned provider")
+	ErrInvalidOutputFormat         = errors.New("invalid output format: must be text, json or image")
+	ErrInvalidOutputSchema         = errors.New("output schema must be a JSON object")
+	ErrTranscriptionOutputFormat   = errors.New("input_audio phases produce text output")
+	ErrInvalidImageOptions         = errors.New("invalid image options: size must be WIDTHxHEIGHT and count between 0 and 10")
+	ErrInvalidTableFormat          = errors.New("invalid extract_tables format: must be csv, tsv or json")
+	ErrImageTableExtraction        = errors.New("image phases have no tables to extract")
+	ErrInvalidPhaseTimeout         = errors.New("phase timeout cannot be negative")
 )
 
 // Phase represents a discrete step in a skill execution workflow.
@@ -40,8 +69,71 @@ type Phase struct {
 	PromptTemplate string
 	RoutingProfile string   // cheap, balanced, premium
 	DependsOn      []string // phase IDs this depends on
+	Optional       bool     // may be left out to fit a time budget
 	MaxTokens      int
 	Temperature    float32
+	Provider       string               // optional provider pin (e.g., "ollama"); empty means profile routing
+	PinSoft        bool                 // fall back to profile routing when the pinned provider is unhealthy
+	Model          string               // optional model pin (e.g., "claude-3-5-sonnet-20241022"); empty means profile routing
+	OutputFormat   string               // text (default), json or image
+	OutputSchema   json.RawMessage      // optional JSON Schema the json output must satisfy
+	InputAudio     string               // optional audio file path template; the phase outputs its transcript
+	Image          ImageOptions         // options for image output
+	ExtractTables  string               // optional format (csv, tsv, json) to save Markdown tables in the output as
+	LongContext    *LongContextConfig   // optional strategy for input larger than one request; nil sends it as is
+	ContextBudget  *ContextBudgetConfig // optional allocation of the request's tokens among prompt components
+	Capabilities   []string             // model capabilities the phase needs (e.g., vision, function_calling)
+
+	// SimilarityGuard optionally checks that the output does not echo the
+	// phase's input; nil leaves the output unchecked.
+	SimilarityGuard *SimilarityGuardConfig
+
+	// ConcurrencyGroup optionally names a group of phases (e.g., "gpu") that
+	// share a maximum concurrency configured for the process, across runs.
+	ConcurrencyGroup string
+
+	// Glossary optionally checks the output's terminology, reporting or
+	// correcting violations; nil leaves the output unchecked.
+	Glossary *Glossary
+
+	// Retry optionally sends the phase again when it fails, and escalates
+	// it to a stronger model; nil fails the phase on its first error.
+	Retry *RetryPolicy
+
+	// When and Unless optionally run the phase only if a condition on its
+	// dependency outputs holds, or does not hold (see ParseCondition). A
+	// phase whose conditions are not met is skipped.
+	When   string
+	Unless string
+
+	// ForEach optionally runs the phase once per item of a list and
+	// aggregates the outputs; nil runs it once.
+	ForEach *ForEachConfig
+
+	// SubSkill optionally runs another skill instead of a completion, with
+	// the rendered prompt template as its input; nil sends the prompt.
+	SubSkill *SubSkillConfig
+
+	// Timeout optionally bounds each attempt of the phase, canceling its
+	// request without ending the run; 0 leaves only the run's timeout.
+	Timeout time.Duration
+
+	// Validation optionally checks the output with validators, sending the
+	// phase again with their errors; nil accepts any output.
+	Validation *ValidationConfig
+
+	// Partials are named templates the phase's templates can render with
+	// {{template "name" .}} or {{include "name" .}}, shared by the phases
+	// of a skill.
+	Partials map[string]string
+}
+
+// ImageOptions configures phases with image output. Zero values select the
+// image backend's defaults.
+type ImageOptions struct {
+	Size           string // WIDTHxHEIGHT, e.g. "1024x1024"
+	Count          int    // number of images (default 1)
+	NegativePrompt string // what the image should not contain
 }
 
 // NewPhase creates a new Phase with the required fields and default values for optional fields.
@@ -72,8 +164,8 @@ func NewPhase(id, name, promptTemplate string) (*Phase, error) {
 	}, nil
 }
 
-// WithRoutingProfile sets the routing profile for the phase.
-// Valid values are: cheap, balanced, premium.
+// WithRoutingProfile sets the routing profile for the phase: cheap,
+// balanced, premium or a custom profile of the routing configuration.
 func (p *Phase) WithRoutingProfile(profile string) *Phase {
 	p.RoutingProfile = strings.TrimSpace(profile)
 	return p
@@ -104,6 +196,209 @@ func (p *Phase) WithTemperature(temp float32) *Phase {
 	return p
 }
 
+// WithProvider pins the phase to a specific provider.
+// By default the pin is hard: the phase fails if the provider is unhealthy.
+func (p *Phase) WithProvider(provider string) *Phase {
+	p.Provider = strings.TrimSpace(provider)
+	return p
+}
+
+// WithModel pins the phase to a specific model, overriding the model its
+// routing profile maps to.
+func (p *Phase) WithModel(model string) *Phase {
+	p.Model = strings.TrimSpace(model)
+	return p
+}
+
+// WithOptional marks the phase as one a time-boxed run may leave out.
+// Phases that other phases depend on are never left out.
+func (p *Phase) WithOptional(optional bool) *Phase {
+	p.Optional = optional
+	return p
+}
+
+// WithPinSoft controls whether an unhealthy pinned provider falls back to
+// profile routing (soft) instead of failing the phase (hard).
+func (p *Phase) WithPinSoft(soft bool) *Phase {
+	p.PinSoft = soft
+	return p
+}
+
+// WithOutputFormat sets the expected response format (text or json).
+func (p *Phase) WithOutputFormat(format string) *Phase {
+	p.OutputFormat = strings.TrimSpace(format)
+	return p
+}
+
+// WithOutputSchema sets a JSON Schema for the phase output.
+// A schema implies json output.
+func (p *Phase) WithOutputSchema(schema json.RawMessage) *Phase {
+	p.OutputSchema = schema
+	if len(schema) > 0 {
+		p.OutputFormat = OutputFormatJSON
+	}
+	return p
+}
+
+// WithInputAudio makes the phase transcribe the audio file that the template
+// renders to. The prompt template then guides the transcription (names,
+// vocabulary) instead of being sent to an LLM.
+func (p *Phase) WithInputAudio(pathTemplate string) *Phase {
+	p.InputAudio = strings.TrimSpace(pathTemplate)
+	return p
+}
+
+// WithImageOptions sets the size, count and negative prompt of generated images.
+func (p *Phase) WithImageOptions(opts ImageOptions) *Phase {
+	opts.Size = strings.TrimSpace(opts.Size)
+	p.Image = opts
+	return p
+}
+
+// WithExtractTables saves the Markdown tables of the phase output as files
+// in format (csv, tsv or json).
+func (p *Phase) WithExtractTables(format string) *Phase {
+	p.ExtractTables = strings.TrimSpace(format)
+	return p
+}
+
+// WithCapabilities sets the model capabilities the phase needs. Routing
+// only selects models whose configuration declares all of them.
+func (p *Phase) WithCapabilities(capabilities ...string) *Phase {
+	p.Capabilities = nil
+	for _, c := range capabilities {
+		if c = strings.TrimSpace(c); c != "" && !slices.Contains(p.Capabilities, c) {
+			p.Capabilities = append(p.Capabilities, c)
+		}
+	}
+	return p
+}
+
+// WithSimilarityGuard checks the phase output against its input.
+func (p *Phase) WithSimilarityGuard(cfg *SimilarityGuardConfig) *Phase {
+	p.SimilarityGuard = cfg
+	return p
+}
+
+// WithConcurrencyGroup puts the phase in the named concurrency group.
+func (p *Phase) WithConcurrencyGroup(group string) *Phase {
+	p.ConcurrencyGroup = strings.TrimSpace(group)
+	return p
+}
+
+// WithGlossary checks the phase output's terminology against a glossary.
+func (p *Phase) WithGlossary(g *Glossary) *Phase {
+	p.Glossary = g
+	return p
+}
+
+// WithRetry sets how the phase is retried when it fails.
+func (p *Phase) WithRetry(policy *RetryPolicy) *Phase {
+	p.Retry = policy
+	return p
+}
+
+// WithWhen runs the phase only when the condition holds.
+func (p *Phase) WithWhen(expr string) *Phase {
+	p.When = strings.TrimSpace(expr)
+	return p
+}
+
+// WithUnless skips the phase when the condition holds.
+func (p *Phase) WithUnless(expr string) *Phase {
+	p.Unless = strings.TrimSpace(expr)
+	return p
+}
+
+// IsConditional reports whether the phase runs only under a condition.
+func (p *Phase) IsConditional() bool {
+	return p.When != "" || p.Unless != ""
+}
+
+// WithForEach runs the phase once per item of a list.
+func (p *Phase) WithForEach(cfg *ForEachConfig) *Phase {
+	p.ForEach = cfg
+	return p
+}
+
+// WithSubSkill runs another skill as the phase.
+func (p *Phase) WithSubSkill(cfg *SubSkillConfig) *Phase {
+	if cfg != nil {
+		cfg.Skill = strings.TrimSpace(cfg.Skill)
+		cfg.Output = strings.TrimSpace(cfg.Output)
+	}
+	p.SubSkill = cfg
+	return p
+}
+
+// IsSubSkill returns true if the phase runs another skill.
+func (p *Phase) IsSubSkill() bool {
+	return p.SubSkill != nil
+}
+
+// WithValidation checks the phase output with validators.
+func (p *Phase) WithValidation(cfg *ValidationConfig) *Phase {
+	p.Validation = cfg
+	return p
+}
+
+// WithPartials sets the named templates the phase's templates can render.
+func (p *Phase) WithPartials(partials map[string]string) *Phase {
+	p.Partials = partials
+	return p
+}
+
+// WithTimeout bounds each attempt of the phase.
+func (p *Phase) WithTimeout(d time.Duration) *Phase {
+	p.Timeout = d
+	return p
+}
+
+// WithLongContext sets how the phase handles input larger than one request.
+func (p *Phase) WithLongContext(cfg *LongContextConfig) *Phase {
+	p.LongContext = cfg
+	return p
+}
+
+// WithContextBudget sets how the phase trims its prompt components when
+// they exceed the request budget.
+func (p *Phase) WithContextBudget(cfg *ContextBudgetConfig) *Phase {
+	p.ContextBudget = cfg
+	return p
+}
+
+// WantsJSON returns true if the phase output must be valid JSON.
+func (p *Phase) WantsJSON() bool {
+	return p.OutputFormat == OutputFormatJSON
+}
+
+// IsImageGeneration returns true if the phase generates images. Its output
+// is the paths of the saved image files, one per line.
+func (p *Phase) IsImageGeneration() bool {
+	return p.OutputFormat == OutputFormatImage
+}
+
+// IsCompletion returns true if the phase is an LLM completion, as opposed to
+// a transcription, image generation or sub-skill.
+func (p *Phase) IsCompletion() bool {
+	return !p.IsTranscription() && !p.IsImageGeneration() && !p.IsSubSkill()
+}
+
+// IsPinned returns true if the phase is pinned to a specific provider.
+func (p *Phase) IsPinned() bool {
+	return p.Provider != ""
+}
+
+// IsModelPinned returns true if the phase is pinned to a specific model.
+func (p *Phase) IsModelPinned() bool {
+	return p.Model != ""
+}
+
+// IsTranscription returns true if the phase transcribes an audio file.
+func (p *Phase) IsTranscription() bool {
+	return p.InputAudio != ""
+}
+
 // Validate checks if the Phase is in a valid state.
 // Returns an error describing any validation failures.
 func (p *Phase) Validate() error {
@@ -116,7 +411,7 @@ func (p *Phase) Validate() error {
 	if strings.TrimSpace(p.PromptTemplate) == "" {
 		return ErrPhasePromptTemplateRequired
 	}
-	if !isValidRoutingProfile(p.RoutingProfile) {
+	if !IsValidProfileName(p.RoutingProfile) {
 		return fmt.Errorf("%w: got %q", ErrInvalidRoutingProfile, p.RoutingProfile)
 	}
 	if p.MaxTokens <= 0 {
@@ -125,17 +420,120 @@ func (p *Phase) Validate() error {
 	if p.Temperature < 0.0 || p.Temperature > 2.0 {
 		return ErrInvalidTemperature
 	}
+	if p.PinSoft && !p.IsPinned() {
+		return ErrPinSoftWithoutProvider
+	}
+	if p.Timeout < 0 {
+		return fmt.Errorf("%w: got %s", ErrInvalidPhaseTimeout, p.Timeout)
+	}
+	switch p.OutputFormat {
+	case "", OutputFormatText, OutputFormatJSON, OutputFormatImage:
+	default:
+		return fmt.Errorf("%w: got %q", ErrInvalidOutputFormat, p.OutputFormat)
+	}
+	if len(p.OutputSchema) > 0 {
+		if !json.Valid(p.OutputSchema) || !bytes.HasPrefix(bytes.TrimSpace(p.OutputSchema), []byte("{")) {
+			return ErrInvalidOutputSchema
+		}
+	}
+	if p.IsTranscription() && p.OutputFormat != "" && p.OutputFormat != OutputFormatText {
+		return ErrTranscriptionOutputFormat
+	}
+	if !isValidImageOptions(p.Image) {
+		return fmt.Errorf("%w: got %+v", ErrInvalidImageOptions, p.Image)
+	}
+	switch p.ExtractTables {
+	case "", TableFormatCSV, TableFormatTSV, TableFormatJSON:
+	default:
+		return fmt.Errorf("%w: got %q", ErrInvalidTableFormat, p.ExtractTables)
+	}
+	if p.ExtractTables != "" && p.IsImageGeneration() {
+		return ErrImageTableExtraction
+	}
+	if p.LongContext != nil {
+		if err := p.LongContext.Validate(); err != nil {
+			return err
+		}
+	}
+	if p.ContextBudget != nil {
+		if p.LongContext != nil {
+			return ErrContextBudgetConflict
+		}
+		if err := p.ContextBudget.Validate(); err != nil {
+			return err
+		}
+	}
+	if p.SimilarityGuard != nil {
+		if !p.IsCompletion() {
+			return ErrSimilarityGuardPhase
+		}
+		if err := p.SimilarityGuard.Validate(); err != nil {
+			return err
+		}
+	}
+	if p.Validation != nil {
+		if !p.IsCompletion() {
+			return ErrValidationPhase
+		}
+		if err := p.Validation.Validate(); err != nil {
+			return err
+		}
+	}
+	if err := ValidatePartials(p.Partials); err != nil {
+		return err
+	}
+	if p.Glossary != nil {
+		if !p.IsCompletion() || p.WantsJSON() {
+			return ErrGlossaryPhase
+		}
+		if err := p.Glossary.Validate(); err != nil {
+			return err
+		}
+	}
+	if p.Retry != nil {
+		if !p.IsCompletion() {
+			return ErrRetryPolicyPhase
+		}
+		if err := p.Retry.Validate(); err != nil {
+			return err
+		}
+	}
+	if p.ForEach != nil {
+		if err := p.ForEach.Validate(); err != nil {
+			return err
+		}
+	}
+	if p.SubSkill != nil {
+		if p.IsTranscription() || p.IsImageGeneration() {
+			return ErrSubSkillPhase
+		}
+		if err := p.SubSkill.Validate(); err != nil {
+			return err
+		}
+	}
+	for _, expr := range []string{p.When, p.Unless} {
+		if expr == "" {
+			continue
+		}
+		if _, err := ParseCondition(expr); err != nil {
+			return fmt.Errorf("invalid condition %q: %w", expr, err)
+		}
+	}
 	return nil
 }
 
-// isValidRoutingProfile checks if the given profile is a valid routing profile.
-func isValidRoutingProfile(profile string) bool {
-	switch profile {
-	case RoutingProfileCheap, RoutingProfileBalanced, RoutingProfilePremium:
-		return true
-	default:
+// isValidImageOptions checks the image size format and count range.
+func isValidImageOptions(opts ImageOptions) bool {
+	if opts.Count < 0 || opts.Count > MaxImageCount {
 		return false
 	}
+	if opts.Size == "" {
+		return true
+	}
+	var width, height int
+	var rest string
+	n, _ := fmt.Sscanf(opts.Size, "%dx%d%s", &width, &height, &rest)
+	return n == 2 && width > 0 && height > 0
 }
 
 // HasDependencies returns true if this phase has dependencies on other phases.
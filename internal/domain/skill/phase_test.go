@@ -3,6 +3,7 @@ package skill
 import (
 	"errors"
 	"testing"
+	"time"
 )
 
 func TestNewPhase(t *testing.T) {
@@ -306,7 +307,7 @@ func TestPhase_Validate(t *testing.T) {
 				ID:             "phase-1",
 				Name:           "Test Phase",
 				PromptTemplate: "Template",
-				RoutingProfile: "invalid",
+				RoutingProfile: "not a profile",
 				MaxTokens:      4096,
 				Temperature:    0.7,
 			},
@@ -489,3 +490,223 @@ func TestDefaultConstants(t *testing.T) {
 		t.Errorf("DefaultTemperature = %f, want %f", DefaultTemperature, 0.7)
 	}
 }
+
+func TestPhase_WithProvider(t *testing.T) {
+	p, _ := NewPhase("p1", "Phase 1", "prompt")
+	if p.IsPinned() {
+		t.Error("new phase should not be pinned")
+	}
+
+	p.WithProvider("  ollama ").WithPinSoft(true)
+	if p.Provider != "ollama" {
+		t.Errorf("Provider = %q, want %q", p.Provider, "ollama")
+	}
+	if !p.IsPinned() || !p.PinSoft {
+		t.Error("expected phase to be soft-pinned")
+	}
+	if err := p.Validate(); err != nil {
+		t.Errorf("Validate() unexpected error: %v", err)
+	}
+}
+
+func TestPhase_WithModel(t *testing.T) {
+	p, _ := NewPhase("p1", "Phase 1", "prompt")
+	if p.IsModelPinned() {
+		t.Error("new phase should not be model-pinned")
+	}
+
+	p.WithModel(" claude-3-5-sonnet-20241022 ")
+	if p.Model != "claude-3-5-sonnet-20241022" {
+		t.Errorf("Model = %q, want %q", p.Model, "claude-3-5-sonnet-20241022")
+	}
+	if !p.IsModelPinned() || p.IsPinned() {
+		t.Error("expected phase to be pinned to a model only")
+	}
+}
+
+func TestPhase_Validate_PinSoftWithoutProvider(t *testing.T) {
+	p, _ := NewPhase("p1", "Phase 1", "prompt")
+	p.WithPinSoft(true)
+
+	if err := p.Validate(); !errors.Is(err, ErrPinSoftWithoutProvider) {
+		t.Errorf("Validate() error = %v, want %v", err, ErrPinSoftWithoutProvider)
+	}
+}
+
+func TestPhase_Validate_Timeout(t *testing.T) {
+	p, _ := NewPhase("p1", "Phase 1", "prompt")
+	if err := p.WithTimeout(30 * time.Second).Validate(); err != nil {
+		t.Errorf("Validate() error = %v, want nil", err)
+	}
+	if err := p.WithTimeout(-time.Second).Validate(); !errors.Is(err, ErrInvalidPhaseTimeout) {
+		t.Errorf("Validate() error = %v, want %v", err, ErrInvalidPhaseTimeout)
+	}
+}
+
+func TestPhase_OutputFormat(t *testing.T) {
+	p, _ := NewPhase("p1", "Phase 1", "prompt")
+	if p.WantsJSON() {
+		t.Error("new phase should default to text output")
+	}
+
+	p.WithOutputSchema([]byte(`{"type":"object","required":["title"]}`))
+	if !p.WantsJSON() {
+		t.Error("a schema should imply json output")
+	}
+	if err := p.Validate(); err != nil {
+		t.Errorf("Validate() unexpected error: %v", err)
+	}
+
+	p.WithOutputSchema([]byte(`["not","an","object"]`))
+	if err := p.Validate(); !errors.Is(err, ErrInvalidOutputSchema) {
+		t.Errorf("Validate() error = %v, want %v", err, ErrInvalidOutputSchema)
+	}
+
+	p.WithOutputSchema(nil).WithOutputFormat("yaml")
+	if err := p.Validate(); !errors.Is(err, ErrInvalidOutputFormat) {
+		t.Errorf("Validate() error = %v, want %v", err, ErrInvalidOutputFormat)
+	}
+}
+
+func TestPhase_InputAudio(t *testing.T) {
+	p, _ := NewPhase("p1", "Phase 1", "Acme, Kubernetes")
+	if p.IsTranscription() {
+		t.Error("new phase should not be a transcription")
+	}
+
+	p.WithInputAudio("  {{._input}}  ")
+	if !p.IsTranscription() || p.InputAudio != "{{._input}}" {
+		t.Errorf("InputAudio = %q, want trimmed template", p.InputAudio)
+	}
+	if err := p.Validate(); err != nil {
+		t.Errorf("Validate() unexpected error: %v", err)
+	}
+
+	p.WithOutputFormat(OutputFormatJSON)
+	if err := p.Validate(); !errors.Is(err, ErrTranscriptionOutputFormat) {
+		t.Errorf("Validate() error = %v, want %v", err, ErrTranscriptionOutputFormat)
+	}
+}
+
+func TestPhase_ImageOutput(t *testing.T) {
+	p, _ := NewPhase("p1", "Phase 1", "A lighthouse at dusk")
+	if p.IsImageGeneration() || !p.IsCompletion() {
+		t.Error("new phase should be an LLM completion")
+	}
+
+	p.WithOutputFormat(OutputFormatImage).WithImageOptions(ImageOptions{Size: " 1024x768 ", Count: 2})
+	if !p.IsImageGeneration() || p.IsCompletion() || p.Image.Size != "1024x768" {
+		t.Errorf("expected an image phase, got %+v", p)
+	}
+	if err := p.Validate(); err != nil {
+		t.Errorf("Validate() unexpected error: %v", err)
+	}
+
+	for _, opts := range []ImageOptions{{Size: "large"}, {Size: "1024x"}, {Size: "0x512"}, {Size: "512x512px"}, {Count: MaxImageCount + 1}} {
+		p.WithImageOptions(opts)
+		if err := p.Validate(); !errors.Is(err, ErrInvalidImageOptions) {
+			t.Errorf("Validate(%+v) error = %v, want %v", opts, err, ErrInvalidImageOptions)
+		}
+	}
+
+	p.WithImageOptions(ImageOptions{}).WithInputAudio("memo.wav")
+	if err := p.Validate(); !errors.Is(err, ErrTranscriptionOutputFormat) {
+		t.Errorf("Validate() error = %v, want %v", err, ErrTranscriptionOutputFormat)
+	}
+}
+
+func TestPhase_ExtractTables(t *testing.T) {
+	p, _ := NewPhase("p1", "Phase 1", "Tabulate the results")
+	for _, format := range []string{TableFormatCSV, TableFormatTSV, TableFormatJSON} {
+		if err := p.WithExtractTables(format).Validate(); err != nil {
+			t.Errorf("Validate(%s) unexpected error: %v", format, err)
+		}
+	}
+
+	if err := p.WithExtractTables("xlsx").Validate(); !errors.Is(err, ErrInvalidTableFormat) {
+		t.Errorf("Validate() error = %v, want %v", err, ErrInvalidTableFormat)
+	}
+
+	p.WithExtractTables(TableFormatCSV).WithOutputFormat(OutputFormatImage)
+	if err := p.Validate(); !errors.Is(err, ErrImageTableExtraction) {
+		t.Errorf("Validate() error = %v, want %v", err, ErrImageTableExtraction)
+	}
+}
+
+func TestPhase_LongContext(t *testing.T) {
+	p, _ := NewPhase("p1", "Phase 1", "Digest the input")
+	if err := p.WithLongContext(NewSlidingWindow(8192, 0)).Validate(); err != nil {
+		t.Errorf("Validate() unexpected error: %v", err)
+	}
+	if got := p.LongContext.CarryoverTokens; got != DefaultCarryoverTokens {
+		t.Errorf("CarryoverTokens = %d, want %d", got, DefaultCarryoverTokens)
+	}
+	if got := NewSlidingWindow(1000, 0).CarryoverTokens; got != 250 {
+		t.Errorf("CarryoverTokens for a small window = %d, want 250", got)
+	}
+	if err := p.WithLongContext(NewMapReduce(8192, 0)).Validate(); err != nil {
+		t.Errorf("Validate() unexpected error for map_reduce: %v", err)
+	}
+	if got := p.LongContext.MapProfile; got != RoutingProfileCheap {
+		t.Errorf("MapProfile = %q, want %q", got, RoutingProfileCheap)
+	}
+
+	chunked := NewChunked(8192, 0)
+	chunked.ReduceProfile = RoutingProfilePremium
+	chunked.ReducePrompt = "Merge the action items"
+	if err := p.WithLongContext(chunked).Validate(); err != nil {
+		t.Errorf("Validate() unexpected error for chunked: %v", err)
+	}
+	reducePromptOnMapReduce := NewMapReduce(4096, 0)
+	reducePromptOnMapReduce.ReducePrompt = "Merge"
+
+	reduceOnSlidingWindow := NewSlidingWindow(4096, 0)
+	reduceOnSlidingWindow.ReduceProfile = RoutingProfilePremium
+	unknownMapProfile := NewMapReduce(4096, 0)
+	unknownMapProfile.MapProfile = "Fastest!"
+
+	tests := []struct {
+		name string
+		cfg  *LongContextConfig
+		want error
+	}{
+		{"unknown strategy", &LongContextConfig{Strategy: "truncate", WindowTokens: 4096, CarryoverTokens: 512}, ErrInvalidLongContextStrategy},
+		{"no window", NewSlidingWindow(0, 100), ErrInvalidWindowTokens},
+		{"carryover half the window", NewSlidingWindow(1000, 500), ErrInvalidCarryoverTokens},
+		{"negative carryover", NewSlidingWindow(1000, -1), ErrInvalidCarryoverTokens},
+		{"map_reduce carryover half the window", NewMapReduce(1000, 500), ErrInvalidCarryoverTokens},
+		{"level profile without map_reduce", reduceOnSlidingWindow, ErrInvalidLevelProfile},
+		{"unknown map profile", unknownMapProfile, ErrInvalidLevelProfile},
+		{"reduce prompt without chunked", reducePromptOnMapReduce, ErrReducePromptStrategy},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := p.WithLongContext(tt.cfg).Validate(); !errors.Is(err, tt.want) {
+				t.Errorf("Validate() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestPhase_WithCapabilities(t *testing.T) {
+	p, _ := NewPhase("p1", "Phase 1", "Describe the screenshot")
+	p.WithCapabilities(" vision", "", "function_calling", "vision")
+	if len(p.Capabilities) != 2 || p.Capabilities[0] != "vision" || p.Capabilities[1] != "function_calling" {
+		t.Errorf("Capabilities = %q, want [vision function_calling]", p.Capabilities)
+	}
+	if p.WithCapabilities().Capabilities != nil {
+		t.Errorf("Capabilities = %q, want none", p.Capabilities)
+	}
+}
+
+func TestPhase_Validate_Partials(t *testing.T) {
+	p, _ := NewPhase("a", "A", `{{template "rules" .}}`)
+	if err := p.WithPartials(map[string]string{"rules": "Be brief."}).Validate(); err != nil {
+		t.Errorf("Validate() = %v, want nil", err)
+	}
+	for _, name := range []string{"", "style rules", `"rules"`} {
+		if err := p.WithPartials(map[string]string{name: "x"}).Validate(); !errors.Is(err, ErrInvalidPartialName) {
+			t.Errorf("Validate() with partial %q = %v, want %v", name, err, ErrInvalidPartialName)
+		}
+	}
+}
@@ -0,0 +1,39 @@
+package skill
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrInvalidRequirement is returned for a requires entry without a program
+// name or with whitespace in it.
+var ErrInvalidRequirement = errors.New("invalid requires entry: must name a program")
+
+// Requirement is an external program a skill needs on PATH, such as git or
+// gofmt.
+type Requirement struct {
+	Name string // Program name, looked up on PATH
+	Hint string // How to install the program; optional
+}
+
+// Validate checks that the requirement names a program.
+func (r Requirement) Validate() error {
+	if r.Name == "" || strings.ContainsFunc(r.Name, func(c rune) bool { return c == ' ' || c == '\t' || c == '\n' }) {
+		return fmt.Errorf("%w: %q", ErrInvalidRequirement, r.Name)
+	}
+	return nil
+}
+
+// Requires returns a copy of the programs the skill needs.
+func (s *Skill) Requires() []Requirement {
+	requires := make([]Requirement, len(s.requires))
+	copy(requires, s.requires)
+	return requires
+}
+
+// SetRequires sets the programs the skill needs.
+func (s *Skill) SetRequires(requires []Requirement) {
+	s.requires = make([]Requirement, len(requires))
+	copy(s.requires, requires)
+}
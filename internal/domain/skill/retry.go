@@ -0,0 +1,80 @@
+package skill
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// What a retry policy does once a phase's retries are exhausted.
+const (
+	// RetryOnFailureFail marks the phase failed.
+	RetryOnFailureFail = "fail"
+	// RetryOnFailureEscalateModel sends the phase once more to the
+	// escalation model before marking it failed.
+	RetryOnFailureEscalateModel = "escalate_model"
+)
+
+// DefaultRetryBackoff is the wait before a phase's first retry when its
+// policy sets none. Each further retry waits twice as long as the last.
+const DefaultRetryBackoff = time.Second
+
+// MaxPhaseRetries is the largest number of retries a policy may set.
+const MaxPhaseRetries = 10
+
+// Retry policy validation errors.
+var (
+	ErrInvalidRetries      = errors.New("retries must be between 0 and 10")
+	ErrInvalidRetryBackoff = errors.New("backoff cannot be negative")
+	ErrInvalidRetryFailure = errors.New("invalid on_failure: must be fail or escalate_model")
+	ErrRetryPolicyPhase    = errors.New("retries and on_failure apply to completion phases only")
+	ErrEmptyRetryPolicy    = errors.New("retry policy needs retries or on_failure: escalate_model")
+)
+
+// RetryPolicy sends a failing phase again, waiting longer before each
+// attempt, and optionally escalates it to a stronger model, so that a
+// cheap local model's occasional failure does not fail the run.
+type RetryPolicy struct {
+	Retries   int           // attempts after the first on the phase's own model
+	Backoff   time.Duration // wait before the first retry; 0 means DefaultRetryBackoff
+	OnFailure string        // fail (default) or escalate_model
+
+	// EscalateModel is the model escalated to; empty means the premium
+	// routing profile's model.
+	EscalateModel string
+}
+
+// Escalates reports whether the phase is sent to the escalation model once
+// its retries are exhausted.
+func (p *RetryPolicy) Escalates() bool {
+	return p.OnFailure == RetryOnFailureEscalateModel
+}
+
+// Delay returns the wait before the given retry, counting from 1: the
+// backoff doubled for each retry before it.
+func (p *RetryPolicy) Delay(retry int) time.Duration {
+	backoff := p.Backoff
+	if backoff == 0 {
+		backoff = DefaultRetryBackoff
+	}
+	return backoff << (retry - 1)
+}
+
+// Validate checks the retries, backoff and failure action.
+func (p *RetryPolicy) Validate() error {
+	if p.Retries < 0 || p.Retries > MaxPhaseRetries {
+		return fmt.Errorf("%w: got %d", ErrInvalidRetries, p.Retries)
+	}
+	if p.Backoff < 0 {
+		return fmt.Errorf("%w: got %s", ErrInvalidRetryBackoff, p.Backoff)
+	}
+	switch p.OnFailure {
+	case "", RetryOnFailureFail, RetryOnFailureEscalateModel:
+	default:
+		return fmt.Errorf("%w: got %q", ErrInvalidRetryFailure, p.OnFailure)
+	}
+	if p.Retries == 0 && !p.Escalates() {
+		return ErrEmptyRetryPolicy
+	}
+	return nil
+}
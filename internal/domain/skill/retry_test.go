@@ -0,0 +1,59 @@
+package skill
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRetryPolicy_Validate(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy RetryPolicy
+		want   error
+	}{
+		{"retries", RetryPolicy{Retries: 2}, nil},
+		{"escalate only", RetryPolicy{OnFailure: RetryOnFailureEscalateModel}, nil},
+		{"retries and fail", RetryPolicy{Retries: 3, Backoff: time.Second, OnFailure: RetryOnFailureFail}, nil},
+		{"empty", RetryPolicy{}, ErrEmptyRetryPolicy},
+		{"fail only", RetryPolicy{OnFailure: RetryOnFailureFail}, ErrEmptyRetryPolicy},
+		{"negative retries", RetryPolicy{Retries: -1}, ErrInvalidRetries},
+		{"too many retries", RetryPolicy{Retries: MaxPhaseRetries + 1}, ErrInvalidRetries},
+		{"negative backoff", RetryPolicy{Retries: 1, Backoff: -time.Second}, ErrInvalidRetryBackoff},
+		{"unknown action", RetryPolicy{Retries: 1, OnFailure: "skip"}, ErrInvalidRetryFailure},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.policy.Validate(); !errors.Is(err, tt.want) {
+				t.Errorf("Validate() = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestRetryPolicy_Delay(t *testing.T) {
+	policy := &RetryPolicy{Retries: 3}
+	if got := policy.Delay(1); got != DefaultRetryBackoff {
+		t.Errorf("Delay(1) = %s, want %s", got, DefaultRetryBackoff)
+	}
+	policy.Backoff = 500 * time.Millisecond
+	for retry, want := range map[int]time.Duration{1: 500 * time.Millisecond, 2: time.Second, 3: 2 * time.Second} {
+		if got := policy.Delay(retry); got != want {
+			t.Errorf("Delay(%d) = %s, want %s", retry, got, want)
+		}
+	}
+}
+
+func TestPhase_Validate_Retry(t *testing.T) {
+	p, _ := NewPhase("p1", "Phase 1", "Summarize {{._input}}")
+	if err := p.WithRetry(&RetryPolicy{Retries: 2, OnFailure: RetryOnFailureEscalateModel}).Validate(); err != nil {
+		t.Errorf("Validate() = %v, want nil", err)
+	}
+	if err := p.WithRetry(&RetryPolicy{}).Validate(); !errors.Is(err, ErrEmptyRetryPolicy) {
+		t.Errorf("Validate() = %v, want %v", err, ErrEmptyRetryPolicy)
+	}
+	p.WithRetry(&RetryPolicy{Retries: 1}).WithInputAudio("{{._input}}")
+	if err := p.Validate(); !errors.Is(err, ErrRetryPolicyPhase) {
+		t.Errorf("Validate() = %v, want %v", err, ErrRetryPolicyPhase)
+	}
+}
@@ -6,13 +6,40 @@ import (
 	"fmt"
 )
 
-// Valid routing profiles
+// Built-in routing profiles
 const (
 	ProfileCheap    = "cheap"
 	ProfileBalanced = "balanced"
 	ProfilePremium  = "premium"
 )
 
+// IsBuiltinProfile reports whether profile is cheap, balanced or premium.
+func IsBuiltinProfile(profile string) bool {
+	switch profile {
+	case ProfileCheap, ProfileBalanced, ProfilePremium:
+		return true
+	default:
+		return false
+	}
+}
+
+// IsValidProfileName reports whether name can name a routing profile: a
+// built-in profile, or a custom one such as "reasoning" or "long-context"
+// made of lowercase letters, digits and hyphens and starting with a letter.
+// Custom profiles are defined in the routing configuration; whether a custom
+// profile exists is checked when phases are routed.
+func IsValidProfileName(name string) bool {
+	if name == "" || name[0] < 'a' || name[0] > 'z' {
+		return false
+	}
+	for _, c := range name {
+		if (c < 'a' || c > 'z') && (c < '0' || c > '9') && c != '-' {
+			return false
+		}
+	}
+	return true
+}
+
 // RoutingConfig defines model routing configuration for skill execution.
 // It specifies which models to use for different phases of execution
 // and provides fallback options when primary models are unavailable.
@@ -72,18 +99,12 @@ func (r *RoutingConfig) Validate() error {
 	}
 
 	// Validate default profile
-	validProfiles := map[string]bool{
-		ProfileCheap:    true,
-		ProfileBalanced: true,
-		ProfilePremium:  true,
-	}
-
 	if r.DefaultProfile == "" {
 		return errors.New("default profile is required")
 	}
 
-	if !validProfiles[r.DefaultProfile] {
-		return fmt.Errorf("invalid default profile %q: must be one of cheap, balanced, premium", r.DefaultProfile)
+	if !IsValidProfileName(r.DefaultProfile) {
+		return fmt.Errorf("invalid default profile %q: must be cheap, balanced, premium or a custom profile name", r.DefaultProfile)
 	}
 
 	// Validate max context tokens
@@ -131,12 +131,20 @@ func TestRoutingConfig_Validate(t *testing.T) {
 		{
 			name: "invalid default profile",
 			config: &RoutingConfig{
-				DefaultProfile:   "invalid",
+				DefaultProfile:   "Fast Lane",
 				MaxContextTokens: 4096,
 			},
 			wantErr: true,
 			errMsg:  "invalid default profile",
 		},
+		{
+			name: "custom default profile",
+			config: &RoutingConfig{
+				DefaultProfile:   "long-context",
+				MaxContextTokens: 4096,
+			},
+			wantErr: false,
+		},
 		{
 			name: "zero max context tokens",
 			config: &RoutingConfig{
@@ -217,6 +225,34 @@ func TestRoutingConfig_ProfileConstants(t *testing.T) {
 	}
 }
 
+func TestIsValidProfileName(t *testing.T) {
+	tests := []struct {
+		name string
+		want bool
+	}{
+		{ProfileCheap, true},
+		{ProfilePremium, true},
+		{"reasoning", true},
+		{"long-context", true},
+		{"gpt4-offline", true},
+		{"", false},
+		{"Reasoning", false},
+		{"long_context", false},
+		{"4k", false},
+		{"-offline", false},
+		{"fast lane", false},
+	}
+
+	for _, tt := range tests {
+		if got := IsValidProfileName(tt.name); got != tt.want {
+			t.Errorf("IsValidProfileName(%q) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+	if IsBuiltinProfile("reasoning") || !IsBuiltinProfile(ProfileBalanced) {
+		t.Error("expected only cheap, balanced and premium to be built in")
+	}
+}
+
 // containsString checks if s contains substr
 func containsString(s, substr string) bool {
 	return len(s) >= len(substr) && (s == substr || len(s) > 0 && containsSubstring(s, substr))
@@ -0,0 +1,62 @@
+package skill
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Actions a similarity guard takes on an output that echoes its input.
+const (
+	// SimilarityActionRetry asks the model again, then flags the output if
+	// it still echoes its input.
+	SimilarityActionRetry = "retry"
+	// SimilarityActionFlag keeps the output and flags it in the report.
+	SimilarityActionFlag = "flag"
+)
+
+// DefaultMaxSimilarity is the share of an output that may repeat the
+// phase's input before a similarity guard rejects it.
+const DefaultMaxSimilarity = 0.9
+
+// Similarity guard validation errors.
+var (
+	ErrInvalidMaxSimilarity    = errors.New("similarity_guard max_similarity must be greater than 0 and at most 1")
+	ErrInvalidSimilarityAction = errors.New("invalid similarity_guard action: must be retry or flag")
+	ErrSimilarityGuardPhase    = errors.New("similarity_guard applies to completion phases only")
+)
+
+// SimilarityGuardConfig checks the output of a transform-type phase (a
+// rewrite, translation or summary) against the input and dependency outputs
+// it was given, catching models that echo them verbatim or return an empty
+// response instead of doing the work.
+type SimilarityGuardConfig struct {
+	MaxSimilarity float64 // share of the output that may repeat a source; 0 means DefaultMaxSimilarity
+	Action        string  // retry (default) or flag
+}
+
+// Threshold returns the share of the output that may repeat a source.
+func (c *SimilarityGuardConfig) Threshold() float64 {
+	if c.MaxSimilarity == 0 {
+		return DefaultMaxSimilarity
+	}
+	return c.MaxSimilarity
+}
+
+// Retries reports whether an echoing output is asked for again before it
+// is flagged.
+func (c *SimilarityGuardConfig) Retries() bool {
+	return c.Action != SimilarityActionFlag
+}
+
+// Validate checks the threshold and action.
+func (c *SimilarityGuardConfig) Validate() error {
+	if c.MaxSimilarity < 0 || c.MaxSimilarity > 1 {
+		return fmt.Errorf("%w: got %g", ErrInvalidMaxSimilarity, c.MaxSimilarity)
+	}
+	switch c.Action {
+	case "", SimilarityActionRetry, SimilarityActionFlag:
+		return nil
+	default:
+		return fmt.Errorf("%w: got %q", ErrInvalidSimilarityAction, c.Action)
+	}
+}
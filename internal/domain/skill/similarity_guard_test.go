@@ -0,0 +1,53 @@
+package skill
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestSimilarityGuardConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  SimilarityGuardConfig
+		want error
+	}{
+		{"defaults", SimilarityGuardConfig{}, nil},
+		{"flag", SimilarityGuardConfig{MaxSimilarity: 0.8, Action: SimilarityActionFlag}, nil},
+		{"retry", SimilarityGuardConfig{MaxSimilarity: 1, Action: SimilarityActionRetry}, nil},
+		{"negative", SimilarityGuardConfig{MaxSimilarity: -0.1}, ErrInvalidMaxSimilarity},
+		{"over 1", SimilarityGuardConfig{MaxSimilarity: 90}, ErrInvalidMaxSimilarity},
+		{"unknown action", SimilarityGuardConfig{Action: "fail"}, ErrInvalidSimilarityAction},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.cfg.Validate(); !errors.Is(err, tt.want) {
+				t.Errorf("Validate() = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestSimilarityGuardConfig_Defaults(t *testing.T) {
+	cfg := &SimilarityGuardConfig{}
+	if cfg.Threshold() != DefaultMaxSimilarity || !cfg.Retries() {
+		t.Errorf("Threshold() = %g, Retries() = %v; want %g, true", cfg.Threshold(), cfg.Retries(), DefaultMaxSimilarity)
+	}
+	cfg = &SimilarityGuardConfig{MaxSimilarity: 0.5, Action: SimilarityActionFlag}
+	if cfg.Threshold() != 0.5 || cfg.Retries() {
+		t.Errorf("Threshold() = %g, Retries() = %v; want 0.5, false", cfg.Threshold(), cfg.Retries())
+	}
+}
+
+func TestPhase_Validate_SimilarityGuard(t *testing.T) {
+	p, _ := NewPhase("p1", "Phase 1", "Rewrite {{._input}}")
+	if err := p.WithSimilarityGuard(&SimilarityGuardConfig{}).Validate(); err != nil {
+		t.Errorf("Validate() = %v, want nil", err)
+	}
+	if err := p.WithSimilarityGuard(&SimilarityGuardConfig{Action: "fail"}).Validate(); !errors.Is(err, ErrInvalidSimilarityAction) {
+		t.Errorf("Validate() = %v, want %v", err, ErrInvalidSimilarityAction)
+	}
+	p.WithSimilarityGuard(&SimilarityGuardConfig{}).WithInputAudio("{{._input}}")
+	if err := p.Validate(); !errors.Is(err, ErrSimilarityGuardPhase) {
+		t.Errorf("Validate() = %v, want %v", err, ErrSimilarityGuardPhase)
+	}
+}
@@ -2,6 +2,8 @@
 package skill
 
 import (
+	"maps"
+	"slices"
 	"strings"
 
 	"github.com/jbctechsolutions/skillrunner/internal/domain/errors"
@@ -17,6 +19,9 @@ type Skill struct {
 	description string
 	phases      []Phase
 	routing     RoutingConfig
+	requires    []Requirement
+	moderation  string
+	outputs     []OutputSink
 	metadata    map[string]any
 }
 
@@ -110,6 +115,21 @@ func (s *Skill) SetMetadata(key string, value any) {
 	s.metadata[key] = value
 }
 
+// WithPhases returns a copy of the skill with its phases replaced, keeping
+// every other field, such as its requirements, moderation and output sinks.
+func (s *Skill) WithPhases(phases []Phase) (*Skill, error) {
+	if len(phases) == 0 {
+		return nil, errors.ErrNoPhasesDefied
+	}
+
+	out := *s
+	out.phases = slices.Clone(phases)
+	out.requires = slices.Clone(s.requires)
+	out.outputs = slices.Clone(s.outputs)
+	out.metadata = maps.Clone(s.metadata)
+	return &out, nil
+}
+
 // GetPhase returns the phase with the given ID, or an error if not found.
 func (s *Skill) GetPhase(id string) (*Phase, error) {
 	for i := range s.phases {
@@ -127,6 +147,9 @@ func (s *Skill) GetPhase(id string) (*Phase, error) {
 //   - Routing configuration is valid
 //   - All phase dependencies exist
 //   - No cycles in phase dependencies
+//   - Every requires entry names a program
+//   - The moderation action is block, warn or off
+//   - Output sinks are valid and deliver existing phases
 func (s *Skill) Validate() error {
 	if strings.TrimSpace(s.id) == "" {
 		return errors.ErrSkillIDRequired
@@ -162,13 +185,26 @@ func (s *Skill) Validate() error {
 	if hasCycle(s.phases) {
 		return errors.ErrCycleDetected
 	}
+	if slices.Contains(s.SubSkills(), s.id) {
+		return ErrSubSkillCycle
+	}
 
 	// Validate routing configuration
 	if err := s.routing.Validate(); err != nil {
 		return err
 	}
 
-	return nil
+	for _, r := range s.requires {
+		if err := r.Validate(); err != nil {
+			return err
+		}
+	}
+
+	if err := s.validateOutputSinks(); err != nil {
+		return err
+	}
+
+	return ValidateModeration(s.moderation)
 }
 
 // hasCycle detects if there's a cycle in phase dependencies using DFS.
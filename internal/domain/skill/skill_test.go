@@ -1,6 +1,7 @@
 package skill
 
 import (
+	stderrors "errors"
 	"testing"
 
 	"github.com/jbctechsolutions/skillrunner/internal/domain/errors"
@@ -221,6 +222,39 @@ func TestSkillSetters(t *testing.T) {
 	})
 }
 
+func TestSkillWithPhases(t *testing.T) {
+	s, _ := NewSkill("skill-1", "Test Skill", "1.0.0", []Phase{validPhase("phase-1", "Phase 1")})
+	s.SetDescription("A test skill")
+	s.SetRouting(*NewRoutingConfig().WithDefaultProfile(ProfilePremium))
+	s.SetRequires([]Requirement{{Name: "git"}})
+	s.SetModeration(ModerationWarn)
+	s.SetOutputSinks([]OutputSink{{Type: OutputSinkClipboard}})
+	s.SetMetadata("author", "test")
+
+	out, err := s.WithPhases([]Phase{validPhase("phase-2", "Phase 2")})
+	if err != nil {
+		t.Fatalf("WithPhases() error = %v", err)
+	}
+	if phases := out.Phases(); len(phases) != 1 || phases[0].ID != "phase-2" {
+		t.Errorf("phases = %v, want phase-2", phases)
+	}
+	if out.Description() != "A test skill" || out.Routing().DefaultProfile != ProfilePremium ||
+		len(out.Requires()) != 1 || out.Moderation() != ModerationWarn ||
+		len(out.OutputSinks()) != 1 || out.Metadata()["author"] != "test" {
+		t.Errorf("WithPhases() dropped fields: %+v", out)
+	}
+
+	// The copy is independent of the original
+	out.SetMetadata("author", "other")
+	if s.Metadata()["author"] != "test" || len(s.Phases()) != 1 || s.Phases()[0].ID != "phase-1" {
+		t.Error("WithPhases() shares state with the original")
+	}
+
+	if _, err := s.WithPhases(nil); !errors.Is(err, errors.ErrNoPhasesDefied) {
+		t.Errorf("WithPhases(nil) error = %v, want ErrNoPhasesDefied", err)
+	}
+}
+
 func TestSkillGetPhase(t *testing.T) {
 	t.Run("returns phase when found", func(t *testing.T) {
 		phases := []Phase{
@@ -271,7 +305,7 @@ func TestSkillValidate(t *testing.T) {
 
 	t.Run("validates phases", func(t *testing.T) {
 		phase, _ := NewPhase("phase-1", "Phase 1", "prompt")
-		phase.RoutingProfile = "invalid"
+		phase.RoutingProfile = "Invalid"
 		phases := []Phase{*phase}
 		skill, _ := NewSkill("skill-1", "Test Skill", "1.0.0", phases)
 
@@ -285,7 +319,7 @@ func TestSkillValidate(t *testing.T) {
 	t.Run("validates routing configuration", func(t *testing.T) {
 		phases := []Phase{validPhase("phase-1", "Phase 1")}
 		skill, _ := NewSkill("skill-1", "Test Skill", "1.0.0", phases)
-		skill.routing.DefaultProfile = "invalid"
+		skill.routing.DefaultProfile = "Invalid"
 
 		err := skill.Validate()
 
@@ -382,6 +416,21 @@ func TestSkillValidate(t *testing.T) {
 		}
 	})
 
+	t.Run("validates requires entries", func(t *testing.T) {
+		phases := []Phase{validPhase("phase-1", "Phase 1")}
+		skill, _ := NewSkill("skill-1", "Test Skill", "1.0.0", phases)
+
+		skill.SetRequires([]Requirement{{Name: "git"}, {Name: "gofmt", Hint: "install Go"}})
+		if err := skill.Validate(); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		skill.SetRequires([]Requirement{{Name: "git"}, {Name: "go fmt"}})
+		if err := skill.Validate(); !stderrors.Is(err, ErrInvalidRequirement) {
+			t.Errorf("expected ErrInvalidRequirement, got %v", err)
+		}
+	})
+
 	t.Run("diamond dependency passes validation", func(t *testing.T) {
 		// A -> B, A -> C, B -> D, C -> D (diamond, no cycle)
 		phaseA, _ := NewPhase("a", "Phase A", "prompt")
@@ -17,12 +17,14 @@ const (
 	SourceUser SourceType = "user"
 	// SourceProject indicates a skill from the project's .skillrunner/skills/ directory.
 	SourceProject SourceType = "project"
+	// SourcePolicy indicates a skill from a directory required by the organization policy.
+	SourcePolicy SourceType = "policy"
 )
 
 // IsValid returns true if the source type is a recognized value.
 func (s SourceType) IsValid() bool {
 	switch s {
-	case SourceBuiltIn, SourceUser, SourceProject:
+	case SourceBuiltIn, SourceUser, SourceProject, SourcePolicy:
 		return true
 	default:
 		return false
@@ -31,9 +33,11 @@ func (s SourceType) IsValid() bool {
 
 // Priority returns the priority level for this source type.
 // Higher values indicate higher priority.
-// Project > User > BuiltIn (3 > 2 > 1).
+// Policy > Project > User > BuiltIn (4 > 3 > 2 > 1).
 func (s SourceType) Priority() int {
 	switch s {
+	case SourcePolicy:
+		return 4
 	case SourceProject:
 		return 3
 	case SourceUser:
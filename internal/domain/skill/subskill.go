@@ -0,0 +1,43 @@
+package skill
+
+import (
+	"errors"
+	"strings"
+)
+
+// Sub-skill validation errors.
+var (
+	ErrEmptySubSkill = errors.New("skill phase must name the skill to run")
+	ErrSubSkillPhase = errors.New("skill phases cannot transcribe audio or generate images")
+	ErrSubSkillCycle = errors.New("skill calls itself through its skill phases")
+)
+
+// SubSkillConfig runs another skill in place of a phase's completion. The
+// phase's prompt template renders the input of that skill, and its final
+// output, or the output of one of its phases, becomes the phase's output.
+type SubSkillConfig struct {
+	Skill  string // ID of the skill to run
+	Output string // optional phase of that skill whose output is the phase's; empty uses its final output
+}
+
+// Validate checks that the config names a skill.
+func (c *SubSkillConfig) Validate() error {
+	if strings.TrimSpace(c.Skill) == "" {
+		return ErrEmptySubSkill
+	}
+	return nil
+}
+
+// SubSkills returns the IDs of the skills the skill's phases run, in phase
+// order and without duplicates.
+func (s *Skill) SubSkills() []string {
+	var ids []string
+	seen := make(map[string]bool)
+	for i := range s.phases {
+		if c := s.phases[i].SubSkill; c != nil && !seen[c.Skill] {
+			seen[c.Skill] = true
+			ids = append(ids, c.Skill)
+		}
+	}
+	return ids
+}
@@ -0,0 +1,50 @@
+package skill
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestPhase_Validate_SubSkill(t *testing.T) {
+	p, _ := NewPhase("review", "Review", "{{.diff}}")
+	p.WithSubSkill(&SubSkillConfig{Skill: " code-review ", Output: "summary"})
+	if err := p.Validate(); err != nil {
+		t.Errorf("Validate() = %v, want nil", err)
+	}
+	if p.SubSkill.Skill != "code-review" || p.IsCompletion() {
+		t.Errorf("SubSkill = %+v, IsCompletion() = %v; want a trimmed skill and no completion", p.SubSkill, p.IsCompletion())
+	}
+
+	if err := p.WithSubSkill(&SubSkillConfig{}).Validate(); !errors.Is(err, ErrEmptySubSkill) {
+		t.Errorf("Validate() = %v, want %v", err, ErrEmptySubSkill)
+	}
+	p.WithSubSkill(&SubSkillConfig{Skill: "code-review"}).WithInputAudio("{{._input}}")
+	if err := p.Validate(); !errors.Is(err, ErrSubSkillPhase) {
+		t.Errorf("Validate() = %v, want %v", err, ErrSubSkillPhase)
+	}
+}
+
+func TestSkill_SubSkills(t *testing.T) {
+	a, _ := NewPhase("a", "A", "{{._input}}")
+	a.WithSubSkill(&SubSkillConfig{Skill: "lint"})
+	b, _ := NewPhase("b", "B", "{{._input}}")
+	b.WithSubSkill(&SubSkillConfig{Skill: "test"})
+	c, _ := NewPhase("c", "C", "{{._input}}")
+	c.WithSubSkill(&SubSkillConfig{Skill: "lint"})
+	d, _ := NewPhase("d", "D", "{{._input}}")
+
+	s, err := NewSkill("review", "Review", "1.0.0", []Phase{*a, *b, *c, *d})
+	if err != nil {
+		t.Fatalf("NewSkill() error = %v", err)
+	}
+	if got := s.SubSkills(); !slices.Equal(got, []string{"lint", "test"}) {
+		t.Errorf("SubSkills() = %q, want lint, test", got)
+	}
+
+	a.WithSubSkill(&SubSkillConfig{Skill: "review"})
+	s, _ = NewSkill("review", "Review", "1.0.0", []Phase{*a})
+	if err := s.Validate(); !errors.Is(err, ErrSubSkillCycle) {
+		t.Errorf("Validate() of a skill calling itself = %v, want %v", err, ErrSubSkillCycle)
+	}
+}
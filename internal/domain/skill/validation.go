@@ -0,0 +1,115 @@
+package skill
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Kinds of output validators.
+const (
+	// ValidatorRegex requires the output to match a regular expression.
+	ValidatorRegex = "regex"
+	// ValidatorJSONSchema requires the output to be a JSON document
+	// satisfying a schema.
+	ValidatorJSONSchema = "json_schema"
+	// ValidatorScript requires a shell command given the output on stdin to
+	// exit with status 0.
+	ValidatorScript = "script"
+)
+
+// DefaultValidationAttempts is the number of times a phase with validators
+// is sent, counting the first, unless it sets another number.
+const DefaultValidationAttempts = 3
+
+// MaxValidationAttempts is the largest number of times a phase with
+// validators may be sent.
+const MaxValidationAttempts = 10
+
+// Validation errors.
+var (
+	ErrEmptyValidators           = errors.New("validators cannot be empty")
+	ErrInvalidValidator          = errors.New("a validator needs exactly one of regex, json_schema or script")
+	ErrInvalidValidatorRegex     = errors.New("invalid validator regex")
+	ErrInvalidValidatorSchema    = errors.New("validator json_schema must be a JSON object")
+	ErrInvalidValidationAttempts = errors.New("max_validation_attempts must be between 0 and 10")
+	ErrValidationPhase           = errors.New("validators apply to completion phases only")
+)
+
+// ValidationConfig checks a phase's output with validators. An output that
+// fails one is sent back to the model with the validator's error, until it
+// passes or the attempts run out and the phase fails.
+type ValidationConfig struct {
+	Validators  []OutputValidator
+	MaxAttempts int // attempts counting the first; 0 means DefaultValidationAttempts
+}
+
+// Attempts returns the number of times the phase is sent at most.
+func (c *ValidationConfig) Attempts() int {
+	if c.MaxAttempts == 0 {
+		return DefaultValidationAttempts
+	}
+	return c.MaxAttempts
+}
+
+// Validate checks the validators and the number of attempts.
+func (c *ValidationConfig) Validate() error {
+	if len(c.Validators) == 0 {
+		return ErrEmptyValidators
+	}
+	for i, v := range c.Validators {
+		if err := v.Validate(); err != nil {
+			return fmt.Errorf("validator %d: %w", i, err)
+		}
+	}
+	if c.MaxAttempts < 0 || c.MaxAttempts > MaxValidationAttempts {
+		return fmt.Errorf("%w: got %d", ErrInvalidValidationAttempts, c.MaxAttempts)
+	}
+	return nil
+}
+
+// OutputValidator is one check of a phase's output. Exactly one of its
+// fields is set.
+type OutputValidator struct {
+	Regex      string          // pattern the output must match
+	JSONSchema json.RawMessage // JSON Schema the output must satisfy
+	Script     string          // shell command given the output on stdin; a non-zero exit rejects it
+	Dir        string          // working directory of the script; empty means the current directory
+}
+
+// Kind returns the kind of validator: regex, json_schema or script.
+func (v OutputValidator) Kind() string {
+	switch {
+	case v.Regex != "":
+		return ValidatorRegex
+	case len(v.JSONSchema) > 0:
+		return ValidatorJSONSchema
+	default:
+		return ValidatorScript
+	}
+}
+
+// Validate checks that exactly one check is set and that it is well formed.
+func (v OutputValidator) Validate() error {
+	set := 0
+	for _, ok := range []bool{v.Regex != "", len(v.JSONSchema) > 0, strings.TrimSpace(v.Script) != ""} {
+		if ok {
+			set++
+		}
+	}
+	if set != 1 {
+		return ErrInvalidValidator
+	}
+	if v.Regex != "" {
+		if _, err := regexp.Compile(v.Regex); err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidValidatorRegex, err)
+		}
+	}
+	if len(v.JSONSchema) > 0 && (!json.Valid(v.JSONSchema) || !bytes.HasPrefix(bytes.TrimSpace(v.JSONSchema), []byte("{"))) {
+		return ErrInvalidValidatorSchema
+	}
+	return nil
+}
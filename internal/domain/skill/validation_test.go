@@ -0,0 +1,66 @@
+package skill
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestValidationConfig_Validate(t *testing.T) {
+	regex := OutputValidator{Regex: `^VERDICT: (PASS|FAIL)`}
+	tests := []struct {
+		name string
+		cfg  ValidationConfig
+		want error
+	}{
+		{"regex", ValidationConfig{Validators: []OutputValidator{regex}}, nil},
+		{"all kinds", ValidationConfig{Validators: []OutputValidator{
+			regex,
+			{JSONSchema: json.RawMessage(`{"type":"object"}`)},
+			{Script: "./check.sh"},
+		}, MaxAttempts: 5}, nil},
+		{"no validators", ValidationConfig{}, ErrEmptyValidators},
+		{"empty validator", ValidationConfig{Validators: []OutputValidator{{}}}, ErrInvalidValidator},
+		{"two checks", ValidationConfig{Validators: []OutputValidator{{Regex: "a", Script: "true"}}}, ErrInvalidValidator},
+		{"bad regex", ValidationConfig{Validators: []OutputValidator{{Regex: "(unclosed"}}}, ErrInvalidValidatorRegex},
+		{"bad schema", ValidationConfig{Validators: []OutputValidator{{JSONSchema: json.RawMessage(`[]`)}}}, ErrInvalidValidatorSchema},
+		{"too many attempts", ValidationConfig{Validators: []OutputValidator{regex}, MaxAttempts: 11}, ErrInvalidValidationAttempts},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.cfg.Validate(); !errors.Is(err, tt.want) {
+				t.Errorf("Validate() = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestOutputValidator_Kind(t *testing.T) {
+	if got := (OutputValidator{Regex: "x"}).Kind(); got != ValidatorRegex {
+		t.Errorf("Kind() = %q, want %q", got, ValidatorRegex)
+	}
+	if got := (OutputValidator{JSONSchema: json.RawMessage(`{}`)}).Kind(); got != ValidatorJSONSchema {
+		t.Errorf("Kind() = %q, want %q", got, ValidatorJSONSchema)
+	}
+	if got := (OutputValidator{Script: "true"}).Kind(); got != ValidatorScript {
+		t.Errorf("Kind() = %q, want %q", got, ValidatorScript)
+	}
+	if got := (&ValidationConfig{}).Attempts(); got != DefaultValidationAttempts {
+		t.Errorf("Attempts() = %d, want %d", got, DefaultValidationAttempts)
+	}
+}
+
+func TestPhase_Validate_Validation(t *testing.T) {
+	p, _ := NewPhase("p1", "Phase 1", "Review {{._input}}")
+	cfg := &ValidationConfig{Validators: []OutputValidator{{Regex: "PASS|FAIL"}}}
+	if err := p.WithValidation(cfg).Validate(); err != nil {
+		t.Errorf("Validate() = %v, want nil", err)
+	}
+	if err := p.WithValidation(&ValidationConfig{}).Validate(); !errors.Is(err, ErrEmptyValidators) {
+		t.Errorf("Validate() = %v, want %v", err, ErrEmptyValidators)
+	}
+	p.WithValidation(cfg).WithInputAudio("{{._input}}")
+	if err := p.Validate(); !errors.Is(err, ErrValidationPhase) {
+		t.Errorf("Validate() = %v, want %v", err, ErrValidationPhase)
+	}
+}
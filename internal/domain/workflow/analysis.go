@@ -0,0 +1,308 @@
+package workflow
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/jbctechsolutions/skillrunner/internal/domain/errors"
+	"github.com/jbctechsolutions/skillrunner/internal/domain/skill"
+)
+
+// Analysis thresholds. Suggestions below these are considered noise.
+const (
+	// minSuggestedWait is the smallest wait worth suggesting a scheduling change for.
+	minSuggestedWait = time.Second
+
+	// cacheCriticalShare is the share of the critical path a phase must take
+	// before caching it is suggested.
+	cacheCriticalShare = 0.2
+
+	// downgradeCostShare is the share of the run cost a phase must take
+	// before a cheaper routing profile is suggested.
+	downgradeCostShare = 0.25
+)
+
+// SuggestionKind classifies an optimization suggestion.
+type SuggestionKind string
+
+const (
+	// SuggestParallelize indicates a phase spent time waiting to be scheduled.
+	SuggestParallelize SuggestionKind = "parallelize"
+	// SuggestCache indicates a slow critical-path phase that could be cached.
+	SuggestCache SuggestionKind = "cache"
+	// SuggestDowngrade indicates an expensive phase that could use a cheaper profile.
+	SuggestDowngrade SuggestionKind = "downgrade"
+)
+
+// PhaseTiming is the recorded execution of one phase in a past run.
+type PhaseTiming struct {
+	PhaseID        string
+	PhaseName      string
+	DependsOn      []string
+	RoutingProfile string
+	Model          string
+	Batch          int
+	QueuedAt       time.Time // Zero if unknown
+	StartTime      time.Time
+	EndTime        time.Time
+	InputTokens    int
+	OutputTokens   int
+	Cost           float64
+	CacheHit       bool
+}
+
+// PhaseAnalysis breaks down where a phase spent its time.
+type PhaseAnalysis struct {
+	PhaseID   string
+	PhaseName string
+	Batch     int
+	ReadyAt   time.Time     // When all dependencies had finished
+	Wait      time.Duration // Time between ready and start
+	SlotWait  time.Duration // Portion of Wait spent waiting for a concurrency slot
+	Execute   time.Duration // Time between start and end
+	Critical  bool          // Whether the phase is on the critical path
+	Cost      float64
+}
+
+// BarrierWait returns the portion of Wait not explained by the concurrency
+// limit, i.e. time spent waiting for unrelated phases in an earlier batch.
+func (p PhaseAnalysis) BarrierWait() time.Duration {
+	return max(p.Wait-p.SlotWait, 0)
+}
+
+// Suggestion is a concrete optimization proposal for a phase.
+type Suggestion struct {
+	Kind         SuggestionKind
+	PhaseID      string
+	Message      string
+	TimeSavings  time.Duration // Estimated wall-time reduction
+	CostFraction float64       // Share of run cost affected (0-1)
+}
+
+// RunAnalysis is the result of analyzing a completed run.
+type RunAnalysis struct {
+	WallTime             time.Duration
+	CriticalPath         []string      // Phase IDs, in execution order
+	CriticalPathDuration time.Duration // Sum of execute time along the critical path
+	TotalWait            time.Duration
+	TotalExecute         time.Duration
+	TotalCost            float64
+	Phases               []PhaseAnalysis // Ordered by start time
+	Suggestions          []Suggestion    // Ordered by estimated impact
+}
+
+// AnalyzeRun computes the critical path, per-phase wait and execute time, and
+// optimization suggestions for a run that started at start.
+// The critical path is the dependency chain with the largest total execute
+// time; it is the lower bound on wall time with unlimited parallelism.
+func AnalyzeRun(start time.Time, timings []PhaseTiming) (*RunAnalysis, error) {
+	if len(timings) == 0 {
+		return nil, errors.New("run_analysis", "run has no executed phases")
+	}
+
+	byID := make(map[string]*PhaseTiming, len(timings))
+	for i := range timings {
+		t := &timings[i]
+		if t.PhaseID == "" {
+			return nil, errors.New("run_analysis", "phase ID is required")
+		}
+		byID[t.PhaseID] = t
+	}
+
+	path, pathDuration, err := criticalPath(byID)
+	if err != nil {
+		return nil, err
+	}
+	critical := make(map[string]bool, len(path))
+	for _, id := range path {
+		critical[id] = true
+	}
+
+	analysis := &RunAnalysis{
+		CriticalPath:         path,
+		CriticalPathDuration: pathDuration,
+		Phases:               make([]PhaseAnalysis, 0, len(timings)),
+	}
+
+	var end time.Time
+	for _, t := range timings {
+		readyAt := start
+		for _, dep := range t.DependsOn {
+			if d, ok := byID[dep]; ok && d.EndTime.After(readyAt) {
+				readyAt = d.EndTime
+			}
+		}
+
+		pa := PhaseAnalysis{
+			PhaseID:   t.PhaseID,
+			PhaseName: t.PhaseName,
+			Batch:     t.Batch,
+			ReadyAt:   readyAt,
+			Wait:      max(t.StartTime.Sub(readyAt), 0),
+			Execute:   max(t.EndTime.Sub(t.StartTime), 0),
+			Critical:  critical[t.PhaseID],
+			Cost:      t.Cost,
+		}
+		if !t.QueuedAt.IsZero() {
+			pa.SlotWait = min(max(t.StartTime.Sub(t.QueuedAt), 0), pa.Wait)
+		}
+
+		analysis.Phases = append(analysis.Phases, pa)
+		analysis.TotalWait += pa.Wait
+		analysis.TotalExecute += pa.Execute
+		analysis.TotalCost += t.Cost
+		if t.EndTime.After(end) {
+			end = t.EndTime
+		}
+	}
+
+	slices.SortFunc(analysis.Phases, func(a, b PhaseAnalysis) int {
+		if c := a.ReadyAt.Add(a.Wait).Compare(b.ReadyAt.Add(b.Wait)); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.PhaseID, b.PhaseID)
+	})
+
+	analysis.WallTime = max(end.Sub(start), 0)
+	analysis.Suggestions = suggest(analysis, byID)
+
+	return analysis, nil
+}
+
+// criticalPath returns the dependency chain with the largest total execute time.
+func criticalPath(byID map[string]*PhaseTiming) ([]string, time.Duration, error) {
+	const (
+		_ = iota // unvisited
+		visiting
+		done
+	)
+
+	state := make(map[string]int, len(byID))
+	dist := make(map[string]time.Duration, len(byID))
+	prev := make(map[string]string, len(byID))
+
+	var visit func(id string) error
+	visit = func(id string) error {
+		switch state[id] {
+		case done:
+			return nil
+		case visiting:
+			return errors.New("run_analysis", fmt.Sprintf("dependency cycle at phase %q", id))
+		}
+		state[id] = visiting
+
+		t := byID[id]
+		var best time.Duration
+		bestDep := ""
+		for _, dep := range t.DependsOn {
+			if _, ok := byID[dep]; !ok {
+				continue
+			}
+			if err := visit(dep); err != nil {
+				return err
+			}
+			if bestDep == "" || dist[dep] > best {
+				best, bestDep = dist[dep], dep
+			}
+		}
+
+		dist[id] = best + max(t.EndTime.Sub(t.StartTime), 0)
+		prev[id] = bestDep
+		state[id] = done
+		return nil
+	}
+
+	ids := make([]string, 0, len(byID))
+	for id := range byID {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+
+	tail := ""
+	for _, id := range ids {
+		if err := visit(id); err != nil {
+			return nil, 0, err
+		}
+		if tail == "" || dist[id] > dist[tail] {
+			tail = id
+		}
+	}
+
+	var path []string
+	for id := tail; id != ""; id = prev[id] {
+		path = append(path, id)
+	}
+	slices.Reverse(path)
+
+	return path, dist[tail], nil
+}
+
+// suggest derives optimization suggestions from a run analysis.
+func suggest(analysis *RunAnalysis, byID map[string]*PhaseTiming) []Suggestion {
+	var suggestions []Suggestion
+
+	for _, pa := range analysis.Phases {
+		t := byID[pa.PhaseID]
+
+		if pa.SlotWait >= minSuggestedWait {
+			suggestions = append(suggestions, Suggestion{
+				Kind:        SuggestParallelize,
+				PhaseID:     pa.PhaseID,
+				TimeSavings: pa.SlotWait,
+				Message: fmt.Sprintf("phase %q waited %s for a free execution slot; raise the parallelism limit",
+					pa.PhaseID, pa.SlotWait.Round(time.Millisecond)),
+			})
+		}
+
+		if barrier := pa.BarrierWait(); barrier >= minSuggestedWait {
+			suggestions = append(suggestions, Suggestion{
+				Kind:        SuggestParallelize,
+				PhaseID:     pa.PhaseID,
+				TimeSavings: barrier,
+				Message: fmt.Sprintf("phase %q was ready %s before it started but waited for unrelated phases before batch %d; move it earlier or split the slow phase it was held behind",
+					pa.PhaseID, barrier.Round(time.Millisecond), pa.Batch),
+			})
+		}
+
+		if pa.Critical && !t.CacheHit && analysis.CriticalPathDuration > 0 {
+			share := float64(pa.Execute) / float64(analysis.CriticalPathDuration)
+			if share >= cacheCriticalShare && pa.Execute >= minSuggestedWait {
+				suggestions = append(suggestions, Suggestion{
+					Kind:        SuggestCache,
+					PhaseID:     pa.PhaseID,
+					TimeSavings: pa.Execute,
+					Message: fmt.Sprintf("phase %q takes %.0f%% of the critical path (%s); caching its output would speed up repeat runs",
+						pa.PhaseID, share*100, pa.Execute.Round(time.Millisecond)),
+				})
+			}
+		}
+
+		if analysis.TotalCost > 0 && t.RoutingProfile != "" && t.RoutingProfile != skill.RoutingProfileCheap {
+			share := t.Cost / analysis.TotalCost
+			if share >= downgradeCostShare {
+				note := "it is on the critical path, so compare latency too"
+				if !pa.Critical {
+					note = "it is off the critical path, so a slower model would not lengthen the run"
+				}
+				suggestions = append(suggestions, Suggestion{
+					Kind:         SuggestDowngrade,
+					PhaseID:      pa.PhaseID,
+					CostFraction: share,
+					Message: fmt.Sprintf("phase %q accounts for %.0f%% of run cost on the %s profile; try a cheaper profile (%s)",
+						pa.PhaseID, share*100, t.RoutingProfile, note),
+				})
+			}
+		}
+	}
+
+	slices.SortStableFunc(suggestions, func(a, b Suggestion) int {
+		if c := cmp.Compare(b.TimeSavings, a.TimeSavings); c != 0 {
+			return c
+		}
+		return cmp.Compare(b.CostFraction, a.CostFraction)
+	})
+
+	return suggestions
+}
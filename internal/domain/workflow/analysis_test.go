@@ -0,0 +1,103 @@
+package workflow
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestAnalyzeRun_CriticalPathAndWaits(t *testing.T) {
+	start := time.Now()
+	at := func(s float64) time.Time { return start.Add(time.Duration(s * float64(time.Second))) }
+
+	// a (10s) and b (2s) run in batch 0; c depends on b only but the batch
+	// barrier holds it until a finishes. d depends on a and c.
+	timings := []PhaseTiming{
+		{PhaseID: "a", Batch: 0, QueuedAt: at(0), StartTime: at(0), EndTime: at(10)},
+		{PhaseID: "b", Batch: 0, QueuedAt: at(0), StartTime: at(0), EndTime: at(2)},
+		{PhaseID: "c", DependsOn: []string{"b"}, Batch: 1, QueuedAt: at(10), StartTime: at(10), EndTime: at(13)},
+		{PhaseID: "d", DependsOn: []string{"a", "c"}, Batch: 2, QueuedAt: at(13), StartTime: at(13), EndTime: at(15)},
+	}
+
+	analysis, err := AnalyzeRun(start, timings)
+	if err != nil {
+		t.Fatalf("AnalyzeRun failed: %v", err)
+	}
+
+	if !slices.Equal(analysis.CriticalPath, []string{"a", "d"}) {
+		t.Errorf("CriticalPath = %v, want [a d]", analysis.CriticalPath)
+	}
+	if analysis.CriticalPathDuration != 12*time.Second {
+		t.Errorf("CriticalPathDuration = %v, want 12s", analysis.CriticalPathDuration)
+	}
+	if analysis.WallTime != 15*time.Second {
+		t.Errorf("WallTime = %v, want 15s", analysis.WallTime)
+	}
+
+	var c PhaseAnalysis
+	for _, pa := range analysis.Phases {
+		if pa.PhaseID == "c" {
+			c = pa
+		}
+	}
+	if c.Wait != 8*time.Second || c.BarrierWait() != 8*time.Second || c.SlotWait != 0 {
+		t.Errorf("phase c wait = %v (barrier %v, slot %v), want 8s barrier wait", c.Wait, c.BarrierWait(), c.SlotWait)
+	}
+
+	if len(analysis.Suggestions) == 0 {
+		t.Fatal("expected suggestions")
+	}
+	top := analysis.Suggestions[0]
+	if top.Kind != SuggestCache || top.PhaseID != "a" {
+		t.Errorf("top suggestion = %s/%s, want cache/a", top.Kind, top.PhaseID)
+	}
+	if !hasSuggestion(analysis.Suggestions, SuggestParallelize, "c") {
+		t.Error("expected parallelize suggestion for phase c")
+	}
+}
+
+func TestAnalyzeRun_SlotWaitAndDowngrade(t *testing.T) {
+	start := time.Now()
+	at := func(s float64) time.Time { return start.Add(time.Duration(s * float64(time.Second))) }
+
+	timings := []PhaseTiming{
+		{PhaseID: "x", RoutingProfile: "premium", Cost: 0.9, QueuedAt: at(0), StartTime: at(0), EndTime: at(1)},
+		{PhaseID: "y", RoutingProfile: "cheap", Cost: 0.1, QueuedAt: at(0), StartTime: at(3), EndTime: at(4)},
+	}
+
+	analysis, err := AnalyzeRun(start, timings)
+	if err != nil {
+		t.Fatalf("AnalyzeRun failed: %v", err)
+	}
+
+	if !hasSuggestion(analysis.Suggestions, SuggestParallelize, "y") {
+		t.Error("expected parallelize suggestion for slot wait on y")
+	}
+	if !hasSuggestion(analysis.Suggestions, SuggestDowngrade, "x") {
+		t.Error("expected downgrade suggestion for expensive premium phase x")
+	}
+	if hasSuggestion(analysis.Suggestions, SuggestDowngrade, "y") {
+		t.Error("cheap phase should not be downgraded")
+	}
+}
+
+func TestAnalyzeRun_Errors(t *testing.T) {
+	if _, err := AnalyzeRun(time.Now(), nil); err == nil {
+		t.Error("expected error for empty run")
+	}
+
+	now := time.Now()
+	cycle := []PhaseTiming{
+		{PhaseID: "a", DependsOn: []string{"b"}, StartTime: now, EndTime: now},
+		{PhaseID: "b", DependsOn: []string{"a"}, StartTime: now, EndTime: now},
+	}
+	if _, err := AnalyzeRun(now, cycle); err == nil {
+		t.Error("expected error for dependency cycle")
+	}
+}
+
+func hasSuggestion(suggestions []Suggestion, kind SuggestionKind, phaseID string) bool {
+	return slices.ContainsFunc(suggestions, func(s Suggestion) bool {
+		return s.Kind == kind && s.PhaseID == phaseID
+	})
+}
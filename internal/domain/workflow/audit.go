@@ -0,0 +1,30 @@
+package workflow
+
+import "time"
+
+// AuditRecord is the full request and response of one provider call of a
+// run, kept for compliance review of generated output. Secrets are redacted
+// from its text before it is stored.
+type AuditRecord struct {
+	ExecutionID  string         `json:"execution_id,omitempty"`
+	SkillID      string         `json:"skill_id,omitempty"`
+	PhaseID      string         `json:"phase_id,omitempty"`
+	Provider     string         `json:"provider"`
+	Model        string         `json:"model"`
+	SystemPrompt string         `json:"system_prompt,omitempty"`
+	Messages     []AuditMessage `json:"messages"`
+	MaxTokens    int            `json:"max_tokens,omitempty"`
+	Temperature  float32        `json:"temperature,omitempty"`
+	Response     string         `json:"response,omitempty"`
+	Error        string         `json:"error,omitempty"`
+	InputTokens  int            `json:"input_tokens,omitempty"`
+	OutputTokens int            `json:"output_tokens,omitempty"`
+	Redactions   int            `json:"redactions,omitempty"` // Matches redacted from the text above
+	RecordedAt   time.Time      `json:"recorded_at"`
+}
+
+// AuditMessage is a message of an audited request.
+type AuditMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
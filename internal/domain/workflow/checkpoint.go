@@ -50,18 +50,30 @@ func IsValidStatus(status string) bool {
 // PhaseResultData is a JSON-serializable version of PhaseResult for checkpoint storage.
 // Unlike PhaseResult, it stores error as a string since error types cannot be serialized.
 type PhaseResultData struct {
-	PhaseID      string `json:"phase_id"`
-	PhaseName    string `json:"phase_name"`
-	Status       string `json:"status"`
-	Output       string `json:"output"`
-	ErrorMessage string `json:"error_message,omitempty"`
-	StartTime    int64  `json:"start_time_unix"`
-	EndTime      int64  `json:"end_time_unix"`
-	DurationNs   int64  `json:"duration_ns"`
-	InputTokens  int    `json:"input_tokens"`
-	OutputTokens int    `json:"output_tokens"`
-	ModelUsed    string `json:"model_used"`
-	CacheHit     bool   `json:"cache_hit"`
+	PhaseID        string  `json:"phase_id"`
+	PhaseName      string  `json:"phase_name"`
+	Status         string  `json:"status"`
+	Output         string  `json:"output"`
+	ErrorMessage   string  `json:"error_message,omitempty"`
+	FailureClass   string  `json:"failure_class,omitempty"` // Class of ErrorMessage, see ClassifyFailure
+	StartTime      int64   `json:"start_time_unix"`
+	EndTime        int64   `json:"end_time_unix"`
+	DurationNs     int64   `json:"duration_ns"`
+	InputTokens    int     `json:"input_tokens"`
+	OutputTokens   int     `json:"output_tokens"`
+	ModelUsed      string  `json:"model_used"`
+	ProviderUsed   string  `json:"provider_used,omitempty"`
+	RoutingProfile string  `json:"routing_profile,omitempty"` // Profile the phase ran with
+	CacheHit       bool    `json:"cache_hit"`
+	Batch          int     `json:"batch"`
+	QueuedAt       int64   `json:"queued_at_unix,omitempty"` // When the phase was scheduled (0 if unknown)
+	BatchJobID     string  `json:"batch_job_id,omitempty"`   // Provider batch job serving the phase, if any
+	Experiment     string  `json:"experiment,omitempty"`     // A/B experiment the phase took part in, if any
+	Variant        string  `json:"variant,omitempty"`        // Experiment variant: control or candidate
+	PromptHash     string  `json:"prompt_hash,omitempty"`    // Hash of the phase's prompt and settings, for reuse
+	ReusedFrom     string  `json:"reused_from,omitempty"`    // Execution ID of the run the result was reused from
+	SkipReason     string  `json:"skip_reason,omitempty"`    // Why the phase's condition skipped it, if it did
+	Sample         *Sample `json:"sample,omitempty"`         // Foreach items the phase ran on, if it ran on a sample
 }
 
 // WorkflowCheckpoint captures the state of a workflow execution for crash recovery.
@@ -81,6 +93,9 @@ type WorkflowCheckpoint struct {
 	inputTokens    int                         // Total input tokens consumed
 	outputTokens   int                         // Total output tokens consumed
 	machineID      string                      // Machine where execution started
+	pinned         bool                        // Golden run, kept by retention cleanup
+	tags           map[string]string           // Cost attribution tags, e.g. team=search
+	archiveErr     error                       // Why archived phase outputs could not be read back
 	createdAt      time.Time
 	updatedAt      time.Time
 }
@@ -229,6 +244,57 @@ func (c *WorkflowCheckpoint) UpdatedAt() time.Time {
 	return c.updatedAt
 }
 
+// Pinned reports whether the run is pinned as a golden run, which retention
+// cleanup never deletes and which regression tests compare against.
+func (c *WorkflowCheckpoint) Pinned() bool {
+	return c.pinned
+}
+
+// SetPinned pins or unpins the run.
+func (c *WorkflowCheckpoint) SetPinned(pinned bool) {
+	c.pinned = pinned
+}
+
+// FailedPhase returns the result of the phase that failed first, or nil if
+// no phase failed.
+func (c *WorkflowCheckpoint) FailedPhase() *PhaseResultData {
+	var first *PhaseResultData
+	for _, pr := range c.phaseResults {
+		if pr.ErrorMessage == "" {
+			continue
+		}
+		if first == nil || pr.EndTime < first.EndTime || (pr.EndTime == first.EndTime && pr.PhaseID < first.PhaseID) {
+			first = pr
+		}
+	}
+	return first
+}
+
+// Tags returns a copy of the run's cost attribution tags.
+func (c *WorkflowCheckpoint) Tags() map[string]string {
+	if len(c.tags) == 0 {
+		return nil
+	}
+	return maps.Clone(c.tags)
+}
+
+// SetTags sets the run's cost attribution tags.
+func (c *WorkflowCheckpoint) SetTags(tags map[string]string) {
+	c.tags = maps.Clone(tags)
+}
+
+// ArchiveError returns why the run's phase outputs, moved to an archive
+// file, could not be read back, or nil. Such a checkpoint has no outputs.
+func (c *WorkflowCheckpoint) ArchiveError() error {
+	return c.archiveErr
+}
+
+// SetArchiveError records why the run's archived phase outputs could not be
+// read back.
+func (c *WorkflowCheckpoint) SetArchiveError(err error) {
+	c.archiveErr = err
+}
+
 // SetMachineID sets the machine ID for the checkpoint.
 func (c *WorkflowCheckpoint) SetMachineID(machineID string) {
 	c.machineID = strings.TrimSpace(machineID)
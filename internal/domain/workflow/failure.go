@@ -0,0 +1,184 @@
+package workflow
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+	"time"
+)
+
+// FailureClass categorizes why a phase failed.
+type FailureClass string
+
+const (
+	// FailureProviderOutage indicates the provider was unreachable, overloaded,
+	// rate limited or returned a server error.
+	FailureProviderOutage FailureClass = "provider_outage"
+	// FailureContextOverflow indicates the prompt did not fit the model's
+	// context window.
+	FailureContextOverflow FailureClass = "context_overflow"
+	// FailureValidation indicates a request or output was rejected as invalid,
+	// such as JSON output that does not satisfy its schema.
+	FailureValidation FailureClass = "validation_failed"
+	// FailureJSONParse indicates output that should be JSON did not parse.
+	FailureJSONParse FailureClass = "json_parse_error"
+	// FailureTool indicates a tool or program the skill relies on failed.
+	FailureTool FailureClass = "tool_error"
+	// FailureOther is any failure not matching a known class.
+	FailureOther FailureClass = "other"
+)
+
+// failurePatterns are lower-case error message fragments of each failure
+// class, in the order they are checked: a context overflow reported as a
+// provider validation error is a context overflow.
+var failurePatterns = []struct {
+	class    FailureClass
+	patterns []string
+}{
+	{FailureContextOverflow, []string{
+		"context_length_exceeded", "context length", "context window", "maximum context",
+		"context exceeds max tokens", "prompt is too long", "too many tokens",
+		"long_context window too small",
+	}},
+	{FailureJSONParse, []string{
+		"invalid character", "unexpected end of json input", "cannot unmarshal",
+		"looking for beginning of value",
+	}},
+	{FailureValidation, []string{
+		"not valid json", "[validation]", "validation failed", "invalid output schema",
+		"invalid_request_error",
+	}},
+	{FailureTool, []string{
+		"tool", "mcp", "not on path", "exit status", "executable file not found",
+	}},
+	{FailureProviderOutage, []string{
+		"[provider]", "provider unreachable", "provider unavailable", "model unavailable",
+		"connection refused", "connection reset", "no such host", "deadline exceeded",
+		"timeout", "overloaded", "rate limit", "http 429", "http 500", "http 502",
+		"http 503", "http 504", "service unavailable", "eof", "stream stalled",
+	}},
+}
+
+// ClassifyFailure returns the failure class of an error message.
+func ClassifyFailure(message string) FailureClass {
+	message = strings.ToLower(message)
+	for _, fp := range failurePatterns {
+		for _, p := range fp.patterns {
+			if strings.Contains(message, p) {
+				return fp.class
+			}
+		}
+	}
+	return FailureOther
+}
+
+// Class returns the failure class of a failed phase: the one recorded when
+// the run was persisted or, for runs recorded before classification, the
+// class of its error message. It returns "" for phases without an error.
+func (p *PhaseResultData) Class() FailureClass {
+	switch {
+	case p.FailureClass != "":
+		return FailureClass(p.FailureClass)
+	case p.ErrorMessage != "":
+		return ClassifyFailure(p.ErrorMessage)
+	default:
+		return ""
+	}
+}
+
+// FailureCount is the number of failed runs of a failure class.
+type FailureCount struct {
+	Class    FailureClass `json:"class"`
+	Runs     int          `json:"runs"`
+	Skills   []string     `json:"skills"`    // Skills that failed this way, most affected first
+	LastSeen time.Time    `json:"last_seen"` // When the latest such run failed
+	Example  string       `json:"example"`   // Error message of the latest such run
+}
+
+// FailurePeriod counts the failed runs of each class in a period.
+type FailurePeriod struct {
+	Start   time.Time            `json:"start"`
+	Runs    int                  `json:"runs"` // All runs started in the period
+	Failed  int                  `json:"failed"`
+	ByClass map[FailureClass]int `json:"by_class"`
+}
+
+// FailureStats summarizes why runs failed.
+type FailureStats struct {
+	Runs    int             `json:"runs"`
+	Failed  int             `json:"failed"`
+	Classes []FailureCount  `json:"classes"` // Most frequent first
+	Periods []FailurePeriod `json:"periods"` // Oldest first
+}
+
+// SummarizeFailures classifies the failed runs among checkpoints by the
+// first phase that failed, and counts them per class overall and per period
+// of the given length. A failed run without a failed phase, such as a
+// canceled one, counts as FailureOther.
+func SummarizeFailures(checkpoints []*WorkflowCheckpoint, period time.Duration) *FailureStats {
+	stats := &FailureStats{Classes: []FailureCount{}, Periods: []FailurePeriod{}}
+	classes := make(map[FailureClass]*FailureCount)
+	skillCounts := make(map[FailureClass]map[string]int)
+	periods := make(map[time.Time]*FailurePeriod)
+
+	for _, cp := range checkpoints {
+		var bucket *FailurePeriod
+		if period > 0 {
+			start := cp.CreatedAt().Truncate(period)
+			if bucket = periods[start]; bucket == nil {
+				bucket = &FailurePeriod{Start: start, ByClass: make(map[FailureClass]int)}
+				periods[start] = bucket
+			}
+			bucket.Runs++
+		}
+		stats.Runs++
+		if cp.Status() != CheckpointStatusFailed {
+			continue
+		}
+
+		class, message := FailureOther, ""
+		if pr := cp.FailedPhase(); pr != nil {
+			class, message = pr.Class(), pr.ErrorMessage
+		}
+		stats.Failed++
+		if bucket != nil {
+			bucket.Failed++
+			bucket.ByClass[class]++
+		}
+
+		count := classes[class]
+		if count == nil {
+			count = &FailureCount{Class: class}
+			classes[class] = count
+			skillCounts[class] = make(map[string]int)
+		}
+		count.Runs++
+		skillCounts[class][cp.SkillID()]++
+		if at := cp.UpdatedAt(); !at.Before(count.LastSeen) {
+			count.LastSeen = at
+			count.Example = message
+		}
+	}
+
+	for class, count := range classes {
+		for skillID := range skillCounts[class] {
+			count.Skills = append(count.Skills, skillID)
+		}
+		slices.SortFunc(count.Skills, func(a, b string) int {
+			return cmp.Or(cmp.Compare(skillCounts[class][b], skillCounts[class][a]), cmp.Compare(a, b))
+		})
+		stats.Classes = append(stats.Classes, *count)
+	}
+	slices.SortFunc(stats.Classes, func(a, b FailureCount) int {
+		return cmp.Or(cmp.Compare(b.Runs, a.Runs), cmp.Compare(a.Class, b.Class))
+	})
+
+	for _, p := range periods {
+		stats.Periods = append(stats.Periods, *p)
+	}
+	slices.SortFunc(stats.Periods, func(a, b FailurePeriod) int {
+		return a.Start.Compare(b.Start)
+	})
+
+	return stats
+}
@@ -0,0 +1,75 @@
+package workflow
+
+import (
+	"testing"
+	"time"
+)
+
+func TestClassifyFailure(t *testing.T) {
+	tests := []struct {
+		message string
+		want    FailureClass
+	}{
+		{"[VALIDATION] invalid_request_error: prompt is too long: 210000 tokens > 200000 maximum", FailureContextOverflow},
+		{"This model's maximum context length is 128000 tokens (context_length_exceeded)", FailureContextOverflow},
+		{"phase output is not valid JSON after 3 attempts: invalid character 'H' looking for beginning of value", FailureJSONParse},
+		{"phase output is not valid JSON after 3 attempts: $.score: expected number", FailureValidation},
+		{"skill code-review requires programs that are not on PATH: gh", FailureTool},
+		{"[PROVIDER] overloaded_error: Overloaded", FailureProviderOutage},
+		{"Post \"http://localhost:11434/api/chat\": dial tcp: connection refused", FailureProviderOutage},
+		{"stream stalled: no content from ollama for 30s", FailureProviderOutage},
+		{"context canceled", FailureOther},
+	}
+	for _, tt := range tests {
+		if got := ClassifyFailure(tt.message); got != tt.want {
+			t.Errorf("ClassifyFailure(%q) = %s, want %s", tt.message, got, tt.want)
+		}
+	}
+}
+
+func TestPhaseResultData_Class(t *testing.T) {
+	if got := (&PhaseResultData{ErrorMessage: "connection refused", FailureClass: string(FailureTool)}).Class(); got != FailureTool {
+		t.Errorf("Class() = %s, want the recorded class", got)
+	}
+	if got := (&PhaseResultData{ErrorMessage: "connection refused"}).Class(); got != FailureProviderOutage {
+		t.Errorf("Class() = %s, want the class of the message", got)
+	}
+	if got := (&PhaseResultData{}).Class(); got != "" {
+		t.Errorf("Class() = %s, want none without an error", got)
+	}
+}
+
+func TestSummarizeFailures(t *testing.T) {
+	week := 7 * 24 * time.Hour
+	base := time.Date(2026, 3, 2, 12, 0, 0, 0, time.UTC) // A Monday
+	run := func(id, skillID string, at time.Time, failure string) *WorkflowCheckpoint {
+		cp := ReconstructCheckpoint(id, "exec-"+id, skillID, skillID, "input", "hash", 0, 1,
+			nil, nil, CheckpointStatusCompleted, 0, 0, "", at, at)
+		if failure != "" {
+			cp.AddPhaseResult("draft", &PhaseResultData{PhaseID: "draft", ErrorMessage: failure})
+			cp.MarkFailed()
+		}
+		return cp
+	}
+
+	stats := SummarizeFailures([]*WorkflowCheckpoint{
+		run("1", "review", base, ""),
+		run("2", "review", base.Add(time.Hour), "[PROVIDER] HTTP 503"),
+		run("3", "summarize", base.Add(week), "connection refused"),
+		run("4", "review", base.Add(week), "prompt is too long"),
+		run("5", "review", base.Add(week+time.Hour), "[PROVIDER] overloaded"),
+	}, week)
+
+	if stats.Runs != 5 || stats.Failed != 4 {
+		t.Fatalf("Runs, Failed = %d, %d; want 5, 4", stats.Runs, stats.Failed)
+	}
+	if len(stats.Classes) != 2 || stats.Classes[0].Class != FailureProviderOutage || stats.Classes[0].Runs != 3 {
+		t.Fatalf("Classes = %+v, want provider_outage first with 3 runs", stats.Classes)
+	}
+	if skills := stats.Classes[0].Skills; len(skills) != 2 || skills[0] != "review" {
+		t.Errorf("Skills = %v, want review first", skills)
+	}
+	if len(stats.Periods) != 2 || stats.Periods[0].Runs != 2 || stats.Periods[1].ByClass[FailureContextOverflow] != 1 {
+		t.Errorf("Periods = %+v, want two weeks", stats.Periods)
+	}
+}
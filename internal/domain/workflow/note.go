@@ -0,0 +1,30 @@
+package workflow
+
+import (
+	"strings"
+	"time"
+
+	"github.com/jbctechsolutions/skillrunner/internal/domain/errors"
+)
+
+// RunNote is a free-form note attached to a past run, such as why a prompt
+// version regressed, so that prompt iterations can be compared later.
+type RunNote struct {
+	RunID     string    `json:"run_id"`
+	Text      string    `json:"text"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// NewRunNote creates a note on the run with the given checkpoint ID.
+func NewRunNote(runID, text string) (*RunNote, error) {
+	runID = strings.TrimSpace(runID)
+	if runID == "" {
+		return nil, errors.New("run_note", "run ID is required")
+	}
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return nil, errors.New("run_note", "note text is required")
+	}
+
+	return &RunNote{RunID: runID, Text: text, CreatedAt: time.Now()}, nil
+}
@@ -0,0 +1,23 @@
+package workflow
+
+import "testing"
+
+func TestNewRunNote(t *testing.T) {
+	note, err := NewRunNote(" cp-1 ", "  this prompt version regressed\n")
+	if err != nil {
+		t.Fatalf("NewRunNote() error = %v", err)
+	}
+	if note.RunID != "cp-1" || note.Text != "this prompt version regressed" {
+		t.Errorf("note = %+v, want trimmed run ID and text", note)
+	}
+	if note.CreatedAt.IsZero() {
+		t.Error("CreatedAt should be set")
+	}
+
+	if _, err := NewRunNote("", "text"); err == nil {
+		t.Error("expected an error without a run ID")
+	}
+	if _, err := NewRunNote("cp-1", "   "); err == nil {
+		t.Error("expected an error without text")
+	}
+}
@@ -0,0 +1,107 @@
+package workflow
+
+import (
+	"math"
+	"math/rand/v2"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/jbctechsolutions/skillrunner/internal/domain/errors"
+)
+
+// Sample records which fan-out items were selected by a seeded, reproducible
+// sample. Persisting a Sample lets a later full run skip the items that were
+// already processed, or compare results against them.
+type Sample struct {
+	Rate     float64 `json:"rate"`     // Fraction of items selected, in (0, 1]
+	Seed     uint64  `json:"seed"`     // Seed used for selection
+	Total    int     `json:"total"`    // Number of items sampled from
+	Selected []int   `json:"selected"` // Selected item indices, ascending
+}
+
+// ParseSampleRate parses a sample rate given as a percentage ("10%") or a
+// fraction ("0.1"). The rate must be greater than 0 and at most 1.
+func ParseSampleRate(s string) (float64, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, errors.New("sample", "sample rate is required")
+	}
+
+	divisor := 1.0
+	if pct, ok := strings.CutSuffix(s, "%"); ok {
+		s = strings.TrimSpace(pct)
+		divisor = 100
+	}
+
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, errors.New("sample", "invalid sample rate: "+s)
+	}
+
+	rate := v / divisor
+	if !validSampleRate(rate) {
+		return 0, errors.New("sample", "sample rate must be greater than 0% and at most 100%")
+	}
+
+	return rate, nil
+}
+
+// NewSample selects a reproducible subset of total items.
+// The same rate, seed and total always select the same indices. At least one
+// item is selected whenever total is positive.
+func NewSample(total int, rate float64, seed uint64) (*Sample, error) {
+	if total < 0 {
+		return nil, errors.New("sample", "total cannot be negative")
+	}
+	if !validSampleRate(rate) {
+		return nil, errors.New("sample", "sample rate must be greater than 0 and at most 1")
+	}
+
+	count := min(max(int(float64(total)*rate), 0), total)
+	if count == 0 && total > 0 {
+		count = 1
+	}
+
+	rng := rand.New(rand.NewPCG(seed, seed))
+	selected := rng.Perm(total)[:count]
+	slices.Sort(selected)
+
+	return &Sample{
+		Rate:     rate,
+		Seed:     seed,
+		Total:    total,
+		Selected: selected,
+	}, nil
+}
+
+// validSampleRate reports whether rate is a number greater than 0 and at
+// most 1; NaN and infinities are not.
+func validSampleRate(rate float64) bool {
+	return !math.IsNaN(rate) && !math.IsInf(rate, 0) && rate > 0 && rate <= 1
+}
+
+// Contains reports whether the item at index was selected.
+func (s *Sample) Contains(index int) bool {
+	if s == nil {
+		return false
+	}
+	_, found := slices.BinarySearch(s.Selected, index)
+	return found
+}
+
+// Remaining returns the indices that were not selected, in ascending order.
+// A full run can use this to process only the items skipped by the sample.
+func (s *Sample) Remaining() []int {
+	if s == nil {
+		return nil
+	}
+
+	remaining := make([]int, 0, s.Total-len(s.Selected))
+	for i := range s.Total {
+		if !s.Contains(i) {
+			remaining = append(remaining, i)
+		}
+	}
+	return remaining
+}
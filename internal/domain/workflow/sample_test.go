@@ -0,0 +1,122 @@
+package workflow
+
+import (
+	"math"
+	"slices"
+	"testing"
+)
+
+func TestParseSampleRate(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    float64
+		wantErr bool
+	}{
+		{"10%", 0.10, false},
+		{" 25 % ", 0.25, false},
+		{"100%", 1, false},
+		{"0.5", 0.5, false},
+		{"1", 1, false},
+		{"", 0, true},
+		{"0%", 0, true},
+		{"150%", 0, true},
+		{"-5%", 0, true},
+		{"abc", 0, true},
+		{"NaN", 0, true},
+		{"nan%", 0, true},
+		{"Inf", 0, true},
+		{"-Inf", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseSampleRate(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseSampleRate(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("ParseSampleRate(%q) = %v, want %v", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewSample_Reproducible(t *testing.T) {
+	a, err := NewSample(1000, 0.1, 42)
+	if err != nil {
+		t.Fatalf("NewSample failed: %v", err)
+	}
+	b, _ := NewSample(1000, 0.1, 42)
+	c, _ := NewSample(1000, 0.1, 7)
+
+	if len(a.Selected) != 100 {
+		t.Errorf("expected 100 selected items, got %d", len(a.Selected))
+	}
+	if !slices.Equal(a.Selected, b.Selected) {
+		t.Error("same seed should select the same items")
+	}
+	if slices.Equal(a.Selected, c.Selected) {
+		t.Error("different seeds should select different items")
+	}
+	if !slices.IsSorted(a.Selected) {
+		t.Error("selected indices should be sorted")
+	}
+}
+
+func TestNewSample_MinimumOne(t *testing.T) {
+	s, err := NewSample(5, 0.01, 1)
+	if err != nil {
+		t.Fatalf("NewSample failed: %v", err)
+	}
+	if len(s.Selected) != 1 {
+		t.Errorf("expected at least one selected item, got %d", len(s.Selected))
+	}
+
+	empty, err := NewSample(0, 0.5, 1)
+	if err != nil {
+		t.Fatalf("NewSample failed: %v", err)
+	}
+	if len(empty.Selected) != 0 {
+		t.Errorf("expected no selected items, got %d", len(empty.Selected))
+	}
+}
+
+func TestNewSample_InvalidArgs(t *testing.T) {
+	if _, err := NewSample(-1, 0.5, 1); err == nil {
+		t.Error("expected error for negative total")
+	}
+	if _, err := NewSample(10, 0, 1); err == nil {
+		t.Error("expected error for zero rate")
+	}
+	if _, err := NewSample(10, 1.5, 1); err == nil {
+		t.Error("expected error for rate above 1")
+	}
+	if _, err := NewSample(10, math.NaN(), 1); err == nil {
+		t.Error("expected error for a NaN rate")
+	}
+	if _, err := NewSample(10, math.Inf(1), 1); err == nil {
+		t.Error("expected error for an infinite rate")
+	}
+}
+
+func TestSample_Remaining(t *testing.T) {
+	s, err := NewSample(20, 0.25, 3)
+	if err != nil {
+		t.Fatalf("NewSample failed: %v", err)
+	}
+
+	remaining := s.Remaining()
+	if len(remaining)+len(s.Selected) != 20 {
+		t.Fatalf("selected and remaining should partition all items")
+	}
+	for _, i := range remaining {
+		if s.Contains(i) {
+			t.Errorf("index %d is both selected and remaining", i)
+		}
+	}
+	for _, i := range s.Selected {
+		if !s.Contains(i) {
+			t.Errorf("Contains(%d) = false for selected index", i)
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package config
+
+import "fmt"
+
+// DefaultAuditPath is the file of the jsonl audit log store.
+const DefaultAuditPath = "~/.skillrunner/audit/audit.jsonl"
+
+// Audit log stores.
+const (
+	AuditStoreJSONL    = "jsonl"    // A JSON Lines file
+	AuditStoreDatabase = "database" // The audit_log table of the storage backend
+)
+
+// AuditConfig enables the audit log, which records the full request and
+// response of every provider call, with secrets redacted, for compliance
+// review of generated output.
+type AuditConfig struct {
+	Enabled bool `yaml:"enabled,omitempty"`
+
+	// Store is where records are appended: jsonl (default) or database.
+	Store string `yaml:"store,omitempty"`
+
+	// Path is the file of the jsonl store (default: DefaultAuditPath).
+	Path string `yaml:"path,omitempty"`
+}
+
+// StoreName returns the store records are appended to.
+func (a AuditConfig) StoreName() string {
+	if a.Store == "" {
+		return AuditStoreJSONL
+	}
+	return a.Store
+}
+
+// FilePath returns the file of the jsonl store, which may start with ~/.
+func (a AuditConfig) FilePath() string {
+	if a.Path == "" {
+		return DefaultAuditPath
+	}
+	return a.Path
+}
+
+// Validate checks the audit settings.
+func (a *AuditConfig) Validate() error {
+	switch a.StoreName() {
+	case AuditStoreJSONL, AuditStoreDatabase:
+		return nil
+	default:
+		return fmt.Errorf("store %q must be %s or %s", a.Store, AuditStoreJSONL, AuditStoreDatabase)
+	}
+}
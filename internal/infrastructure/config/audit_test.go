@@ -0,0 +1,16 @@
+package config
+
+import "testing"
+
+func TestAuditConfig(t *testing.T) {
+	var defaults AuditConfig
+	if defaults.StoreName() != AuditStoreJSONL || defaults.FilePath() != DefaultAuditPath {
+		t.Errorf("StoreName(), FilePath() = %q, %q; want the defaults", defaults.StoreName(), defaults.FilePath())
+	}
+	if err := (&AuditConfig{Enabled: true, Store: "s3"}).Validate(); err == nil {
+		t.Error("an unknown store should be rejected")
+	}
+	if err := (&AuditConfig{Enabled: true, Store: AuditStoreDatabase}).Validate(); err != nil {
+		t.Errorf("Validate() error = %v", err)
+	}
+}
@@ -2,9 +2,14 @@
 package config
 
 import (
+	"cmp"
 	"errors"
 	"fmt"
+	"maps"
 	"net/url"
+	"os"
+	"slices"
+	"strings"
 	"time"
 )
 
@@ -17,6 +22,11 @@ type Config struct {
 	Cache         CacheConfig         `yaml:"cache"`
 	Observability ObservabilityConfig `yaml:"observability"`
 	Memory        MemoryConfig        `yaml:"memory"`
+	Storage       StorageConfig       `yaml:"storage"`
+	Policy        PolicyConfig        `yaml:"policy,omitempty"`
+	Pricing       PricingConfig       `yaml:"pricing,omitempty"`
+	Serve         ServeConfig         `yaml:"serve,omitempty"`
+	Audit         AuditConfig         `yaml:"audit,omitempty"`
 }
 
 // ProviderConfigs holds configuration for all supported LLM providers.
@@ -25,6 +35,42 @@ type ProviderConfigs struct {
 	Anthropic CloudConfig  `yaml:"anthropic"`
 	OpenAI    CloudConfig  `yaml:"openai"`
 	Groq      CloudConfig  `yaml:"groq"`
+	Together  CloudConfig  `yaml:"together"`
+	Fireworks CloudConfig  `yaml:"fireworks"`
+
+	// WhisperCpp is a local whisper.cpp server used to transcribe the audio
+	// of input_audio phases.
+	WhisperCpp LocalServiceConfig `yaml:"whispercpp"`
+
+	// Stability and A1111 (a local Stable Diffusion web UI) generate the
+	// images of phases with image output.
+	Stability CloudConfig        `yaml:"stability"`
+	A1111     LocalServiceConfig `yaml:"a1111"`
+}
+
+// CloudProviderNames lists the built-in providers configured with a
+// CloudConfig, in the order they appear in the configuration.
+var CloudProviderNames = []string{"anthropic", "openai", "groq", "together", "fireworks", "stability"}
+
+// Cloud returns the configuration of the built-in cloud provider name, or
+// nil if there is no such provider.
+func (p *ProviderConfigs) Cloud(name string) *CloudConfig {
+	switch name {
+	case "anthropic":
+		return &p.Anthropic
+	case "openai":
+		return &p.OpenAI
+	case "groq":
+		return &p.Groq
+	case "together":
+		return &p.Together
+	case "fireworks":
+		return &p.Fireworks
+	case "stability":
+		return &p.Stability
+	default:
+		return nil
+	}
 }
 
 // OllamaConfig holds configuration for the Ollama local LLM provider.
@@ -34,9 +80,18 @@ type OllamaConfig struct {
 	Timeout time.Duration `yaml:"timeout"`
 }
 
+// LocalServiceConfig holds configuration for a local model server that is
+// not an LLM provider (whisper.cpp, Stable Diffusion web UI).
+type LocalServiceConfig struct {
+	URL     string        `yaml:"url"`
+	Enabled bool          `yaml:"enabled"`
+	Timeout time.Duration `yaml:"timeout"`
+}
+
 // CloudConfig holds configuration for cloud-based LLM providers.
 type CloudConfig struct {
 	APIKeyEncrypted string        `yaml:"api_key_encrypted"`
+	Keychain        bool          `yaml:"keychain,omitempty"` // API key is in the OS keychain (sr auth login)
 	BaseURL         string        `yaml:"base_url,omitempty"` // Optional custom endpoint (e.g., for proxies)
 	Enabled         bool          `yaml:"enabled"`
 	Timeout         time.Duration `yaml:"timeout"`
@@ -46,12 +101,33 @@ type CloudConfig struct {
 type RoutingConfig struct {
 	DefaultProfile string                           `yaml:"default_profile"`
 	Profiles       map[string]*ProfileConfiguration `yaml:"profiles,omitempty"`
+
+	// HotReload applies edits to routing.yaml and .skillrunner.yaml to
+	// long-running commands such as sr chat without a restart.
+	HotReload bool `yaml:"hot_reload"`
+
+	// Sources are shared routing files merged under routing.yaml, so a team
+	// can publish a central model policy: http(s) URLs or git repositories
+	// (git::<repository>//<path>?ref=<ref>). They are cached locally and
+	// fetched again by 'sr config refresh'.
+	Sources []string `yaml:"sources,omitempty"`
+
+	// TrustedProjects are project directories whose .skillrunner.yaml and
+	// .skillrunner/routing.yaml may define providers, budgets, guardrails and
+	// moderation. Other projects may only set routing keys that send nothing
+	// to a new destination, such as profiles and the fallback chain.
+	TrustedProjects []string `yaml:"trusted_projects,omitempty"`
+
+	// Files are routing files passed with --routing, merged over every other
+	// routing layer. They are set from the command line only.
+	Files []string `yaml:"-"`
 }
 
 // LoggingConfig holds configuration for application logging.
 type LoggingConfig struct {
-	Level  string `yaml:"level"`  // debug, info, warn, error
-	Format string `yaml:"format"` // json, text
+	Level  string `yaml:"level"`         // debug, info, warn, error
+	Format string `yaml:"format"`        // json, text
+	Dir    string `yaml:"dir,omitempty"` // Per-run log files; default ~/.skillrunner/logs
 }
 
 // SkillsConfig holds configuration for skill management.
@@ -59,6 +135,11 @@ type SkillsConfig struct {
 	Directory        string        `yaml:"directory"`
 	HotReload        bool          `yaml:"hot_reload"`
 	DebounceDuration time.Duration `yaml:"debounce_duration"`
+
+	// ConcurrencyGroups is the maximum number of phases of each concurrency
+	// group (a phase's concurrency_group) that run at once in a process, by
+	// group name. Groups not listed are unlimited.
+	ConcurrencyGroups map[string]int `yaml:"concurrency_groups,omitempty"`
 }
 
 // CacheConfig holds configuration for response caching.
@@ -89,15 +170,26 @@ type MetricsConfig struct {
 	Enabled          bool          `yaml:"enabled"`           // Whether metrics collection is enabled
 	RetentionPeriod  time.Duration `yaml:"retention_period"`  // How long to retain metrics
 	AggregationLevel string        `yaml:"aggregation_level"` // none, skill, provider, phase
+	Share            ShareConfig   `yaml:"share,omitempty"`   // Publishing anonymized benchmark aggregates
+}
+
+// ShareConfig controls publishing coarse, noisy benchmark aggregates with
+// sr metrics share. Nothing is uploaded unless it is enabled.
+type ShareConfig struct {
+	Enabled      bool    `yaml:"enabled,omitempty"`        // Opt in to uploading benchmark files
+	URL          string  `yaml:"url,omitempty"`            // Endpoint benchmark files are posted to
+	Epsilon      float64 `yaml:"epsilon,omitempty"`        // Privacy budget of the noise; smaller is noisier (default: 1.0)
+	MinGroupSize int     `yaml:"min_group_size,omitempty"` // Groups with fewer phase runs are dropped (default: 10)
 }
 
 // TracingConfig holds configuration for distributed tracing.
 type TracingConfig struct {
-	Enabled      bool    `yaml:"enabled"`       // Whether tracing is enabled
-	ExporterType string  `yaml:"exporter_type"` // none, stdout, otlp
-	OTLPEndpoint string  `yaml:"otlp_endpoint"` // OTLP collector endpoint
-	SampleRate   float64 `yaml:"sample_rate"`   // Sampling rate (0.0 to 1.0)
-	ServiceName  string  `yaml:"service_name"`  // Service name for traces
+	Enabled      bool              `yaml:"enabled"`                // Whether tracing is enabled
+	ExporterType string            `yaml:"exporter_type"`          // none, stdout, otlp
+	OTLPEndpoint string            `yaml:"otlp_endpoint"`          // OTLP/HTTP collector host:port or URL
+	OTLPHeaders  map[string]string `yaml:"otlp_headers,omitempty"` // Headers sent with each export, e.g. an API key
+	SampleRate   float64           `yaml:"sample_rate"`            // Sampling rate (0.0 to 1.0)
+	ServiceName  string            `yaml:"service_name"`           // Service name for traces
 }
 
 // MemoryConfig holds configuration for the memory system (MEMORY.md/CLAUDE.md).
@@ -106,16 +198,77 @@ type MemoryConfig struct {
 	MaxTokens int  `yaml:"max_tokens"` // Maximum tokens for memory content (default: 2000)
 }
 
+// StorageConfig selects the backend that persists sessions, checkpoints,
+// metrics and the disk cache. See docs/storage.md.
+type StorageConfig struct {
+	Backend string `yaml:"backend"`       // Registered backend name (default: sqlite)
+	DSN     string `yaml:"dsn,omitempty"` // Backend connection string; for sqlite, the database path
+
+	// Connection pool limits of server backends such as postgres; zero uses
+	// the backend's defaults.
+	MaxOpenConns    int           `yaml:"max_open_conns,omitempty"`
+	MaxIdleConns    int           `yaml:"max_idle_conns,omitempty"`
+	ConnMaxLifetime time.Duration `yaml:"conn_max_lifetime,omitempty"`
+
+	// Checkpoints keeps workflow checkpoints in an object store instead of
+	// the backend, so that a run can be resumed on another machine.
+	Checkpoints CheckpointStoreConfig `yaml:"checkpoints,omitempty"`
+
+	// Archive moves the phase outputs of old runs out of the backend into
+	// compressed files, keeping the rest of their history in place.
+	Archive ArchiveConfig `yaml:"archive,omitempty"`
+}
+
+// ArchiveConfig is the cold storage of the phase outputs of old runs,
+// written by sr storage archive. See docs/storage.md.
+type ArchiveConfig struct {
+	AfterDays int    `yaml:"after_days,omitempty"` // Archive runs started more than this many days ago (default 30)
+	Dir       string `yaml:"dir,omitempty"`        // Archive files; default ~/.skillrunner/archive
+}
+
+// CheckpointStoreConfig keeps workflow checkpoints in an S3 or Cloud Storage
+// bucket. See docs/storage.md.
+type CheckpointStoreConfig struct {
+	Backend  string `yaml:"backend,omitempty"`  // s3 or gcs; empty keeps checkpoints in the storage backend
+	Bucket   string `yaml:"bucket,omitempty"`   // Bucket name (required with a backend)
+	Prefix   string `yaml:"prefix,omitempty"`   // Key prefix of the checkpoint objects
+	Region   string `yaml:"region,omitempty"`   // s3 region (default: us-east-1)
+	Endpoint string `yaml:"endpoint,omitempty"` // URL of an S3-compatible store, such as MinIO
+
+	// Environment variables holding the access key pair, or for gcs an
+	// HMAC key. They default to AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY
+	// for s3, and GCS_HMAC_ACCESS_ID and GCS_HMAC_SECRET for gcs.
+	AccessKeyEnv string `yaml:"access_key_env,omitempty"`
+	SecretKeyEnv string `yaml:"secret_key_env,omitempty"`
+}
+
+// Credentials returns the access key, secret key and, for s3, the session
+// token from AWS_SESSION_TOKEN, read from the environment.
+func (c CheckpointStoreConfig) Credentials() (accessKey, secretKey, sessionToken string) {
+	accessEnv, secretEnv := "AWS_ACCESS_KEY_ID", "AWS_SECRET_ACCESS_KEY"
+	if c.Backend == "gcs" {
+		accessEnv, secretEnv = "GCS_HMAC_ACCESS_ID", "GCS_HMAC_SECRET"
+	} else {
+		sessionToken = os.Getenv("AWS_SESSION_TOKEN")
+	}
+	return os.Getenv(cmp.Or(c.AccessKeyEnv, accessEnv)), os.Getenv(cmp.Or(c.SecretKeyEnv, secretEnv)), sessionToken
+}
+
 // Default configuration values.
 const (
 	DefaultOllamaURL              = "http://localhost:11434"
 	DefaultTimeout                = 30 * time.Second
+	DefaultWhisperCppURL          = "http://127.0.0.1:8080"
+	DefaultWhisperCppTimeout      = 10 * time.Minute
+	DefaultA1111URL               = "http://127.0.0.1:7860"
+	DefaultA1111Timeout           = 5 * time.Minute
 	DefaultLogLevel               = "info"
 	DefaultLogFormat              = "text"
 	DefaultSkillsDirectory        = "~/.skillrunner/skills"
 	DefaultSkillsHotReload        = true
 	DefaultSkillsDebounceDuration = 100 * time.Millisecond
 	DefaultRoutingProfile         = "default"
+	DefaultRoutingHotReload       = true
 
 	// Cache defaults
 	DefaultCacheEnabled       = true
@@ -141,6 +294,9 @@ const (
 	// Memory defaults
 	DefaultMemoryEnabled   = true
 	DefaultMemoryMaxTokens = 2000
+
+	// Storage defaults
+	DefaultStorageBackend = "sqlite"
 )
 
 // Valid log levels.
@@ -193,9 +349,32 @@ func NewDefaultConfig() *Config {
 				Enabled: false,
 				Timeout: DefaultTimeout,
 			},
+			Together: CloudConfig{
+				Enabled: false,
+				Timeout: DefaultTimeout,
+			},
+			Fireworks: CloudConfig{
+				Enabled: false,
+				Timeout: DefaultTimeout,
+			},
+			WhisperCpp: LocalServiceConfig{
+				URL:     DefaultWhisperCppURL,
+				Enabled: false,
+				Timeout: DefaultWhisperCppTimeout,
+			},
+			Stability: CloudConfig{
+				Enabled: false,
+				Timeout: DefaultTimeout,
+			},
+			A1111: LocalServiceConfig{
+				URL:     DefaultA1111URL,
+				Enabled: false,
+				Timeout: DefaultA1111Timeout,
+			},
 		},
 		Routing: RoutingConfig{
 			DefaultProfile: DefaultRoutingProfile,
+			HotReload:      DefaultRoutingHotReload,
 		},
 		Logging: LoggingConfig{
 			Level:  DefaultLogLevel,
@@ -230,6 +409,9 @@ func NewDefaultConfig() *Config {
 			Enabled:   DefaultMemoryEnabled,
 			MaxTokens: DefaultMemoryMaxTokens,
 		},
+		Storage: StorageConfig{
+			Backend: DefaultStorageBackend,
+		},
 	}
 }
 
@@ -272,6 +454,31 @@ func (c *Config) Validate() error {
 		errs = append(errs, fmt.Errorf("memory: %w", err))
 	}
 
+	// Validate storage config
+	if err := c.Storage.Validate(); err != nil {
+		errs = append(errs, fmt.Errorf("storage: %w", err))
+	}
+
+	// Validate organization policy config
+	if err := c.Policy.Validate(); err != nil {
+		errs = append(errs, fmt.Errorf("policy: %w", err))
+	}
+
+	// Validate pricing catalog config
+	if err := c.Pricing.Validate(); err != nil {
+		errs = append(errs, fmt.Errorf("pricing: %w", err))
+	}
+
+	// Validate sr serve config
+	if err := c.Serve.Validate(); err != nil {
+		errs = append(errs, fmt.Errorf("serve: %w", err))
+	}
+
+	// Validate audit log config
+	if err := c.Audit.Validate(); err != nil {
+		errs = append(errs, fmt.Errorf("audit: %w", err))
+	}
+
 	if len(errs) > 0 {
 		return errors.Join(errs...)
 	}
@@ -318,6 +525,26 @@ func (p *ProviderConfigs) Validate() error {
 		errs = append(errs, err)
 	}
 
+	if err := p.Together.Validate("together"); err != nil {
+		errs = append(errs, err)
+	}
+
+	if err := p.Fireworks.Validate("fireworks"); err != nil {
+		errs = append(errs, err)
+	}
+
+	if err := p.WhisperCpp.Validate(); err != nil {
+		errs = append(errs, fmt.Errorf("whispercpp: %w", err))
+	}
+
+	if err := p.Stability.Validate("stability"); err != nil {
+		errs = append(errs, err)
+	}
+
+	if err := p.A1111.Validate(); err != nil {
+		errs = append(errs, fmt.Errorf("a1111: %w", err))
+	}
+
 	if len(errs) > 0 {
 		return errors.Join(errs...)
 	}
@@ -344,12 +571,33 @@ func (o *OllamaConfig) Validate() error {
 	return nil
 }
 
+// Validate checks if the LocalServiceConfig is valid.
+func (l *LocalServiceConfig) Validate() error {
+	var errs []error
+
+	if l.Enabled && l.URL == "" {
+		errs = append(errs, errors.New("url is required when enabled"))
+	}
+
+	if l.Timeout < 0 {
+		errs = append(errs, errors.New("timeout must be non-negative"))
+	}
+
+	return errors.Join(errs...)
+}
+
+// HasAPIKey reports whether an API key is configured, either encrypted in the
+// configuration or in the OS keychain.
+func (c *CloudConfig) HasAPIKey() bool {
+	return c.APIKeyEncrypted != "" || c.Keychain
+}
+
 // Validate checks if the CloudConfig is valid.
 func (c *CloudConfig) Validate(providerName string) error {
 	var errs []error
 
-	if c.Enabled && c.APIKeyEncrypted == "" {
-		errs = append(errs, fmt.Errorf("%s: api_key_encrypted is required when enabled", providerName))
+	if c.Enabled && !c.HasAPIKey() {
+		errs = append(errs, fmt.Errorf("%s: api_key_encrypted or keychain is required when enabled", providerName))
 	}
 
 	if c.Timeout < 0 {
@@ -378,7 +626,14 @@ func (r *RoutingConfig) Validate() error {
 	if r.DefaultProfile == "" {
 		return errors.New("default_profile is required")
 	}
-	return nil
+
+	var errs []error
+	for _, source := range r.Sources {
+		if err := ValidateRemoteRoutingSource(source); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
 }
 
 // Validate checks if the SkillsConfig is valid.
@@ -393,6 +648,14 @@ func (s *SkillsConfig) Validate() error {
 		errs = append(errs, errors.New("debounce_duration must be positive when hot_reload is enabled"))
 	}
 
+	for _, name := range slices.Sorted(maps.Keys(s.ConcurrencyGroups)) {
+		if strings.TrimSpace(name) == "" {
+			errs = append(errs, errors.New("concurrency_groups: group name is required"))
+		} else if s.ConcurrencyGroups[name] < 1 {
+			errs = append(errs, fmt.Errorf("concurrency_groups: %s must allow at least 1 phase", name))
+		}
+	}
+
 	if len(errs) > 0 {
 		return errors.Join(errs...)
 	}
@@ -478,6 +741,36 @@ func (m *MetricsConfig) Validate() error {
 		}
 	}
 
+	if err := m.Share.Validate(); err != nil {
+		errs = append(errs, fmt.Errorf("share: %w", err))
+	}
+
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+
+	return nil
+}
+
+// Validate checks if the ShareConfig is valid.
+func (s *ShareConfig) Validate() error {
+	var errs []error
+
+	if s.Enabled && s.URL == "" {
+		errs = append(errs, errors.New("url is required when sharing is enabled"))
+	}
+	if s.URL != "" {
+		if u, err := url.Parse(s.URL); err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+			errs = append(errs, fmt.Errorf("url %q must be an http(s) URL", s.URL))
+		}
+	}
+	if s.Epsilon < 0 {
+		errs = append(errs, errors.New("epsilon must be non-negative"))
+	}
+	if s.MinGroupSize < 0 {
+		errs = append(errs, errors.New("min_group_size must be non-negative"))
+	}
+
 	if len(errs) > 0 {
 		return errors.Join(errs...)
 	}
@@ -518,3 +811,27 @@ func (m *MemoryConfig) Validate() error {
 	}
 	return nil
 }
+
+// Validate checks if the storage configuration is valid. Whether the backend
+// is registered is checked when it is opened.
+func (s *StorageConfig) Validate() error {
+	if s.Backend == "" {
+		return errors.New("backend is required")
+	}
+	if s.MaxOpenConns < 0 || s.MaxIdleConns < 0 || s.ConnMaxLifetime < 0 {
+		return errors.New("connection pool limits cannot be negative")
+	}
+	if s.Archive.AfterDays < 0 {
+		return errors.New("archive: after_days cannot be negative")
+	}
+	switch s.Checkpoints.Backend {
+	case "":
+	case "s3", "gcs":
+		if s.Checkpoints.Bucket == "" {
+			return errors.New("checkpoints: bucket is required")
+		}
+	default:
+		return fmt.Errorf("checkpoints: unsupported backend %q: must be s3 or gcs", s.Checkpoints.Backend)
+	}
+	return nil
+}
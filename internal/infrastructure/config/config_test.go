@@ -156,6 +156,44 @@ func TestOllamaConfig_Validate(t *testing.T) {
 	}
 }
 
+func TestLocalServiceConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  LocalServiceConfig
+		wantErr bool
+	}{
+		{
+			name:    "valid enabled config",
+			config:  LocalServiceConfig{URL: DefaultWhisperCppURL, Enabled: true, Timeout: DefaultWhisperCppTimeout},
+			wantErr: false,
+		},
+		{
+			name:    "disabled without URL is valid",
+			config:  LocalServiceConfig{},
+			wantErr: false,
+		},
+		{
+			name:    "enabled without URL is invalid",
+			config:  LocalServiceConfig{Enabled: true},
+			wantErr: true,
+		},
+		{
+			name:    "negative timeout is invalid",
+			config:  LocalServiceConfig{URL: DefaultWhisperCppURL, Enabled: true, Timeout: -1 * time.Second},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.config.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
 func TestCloudConfig_Validate(t *testing.T) {
 	tests := []struct {
 		name         string
@@ -274,6 +312,21 @@ func TestSkillsConfig_Validate(t *testing.T) {
 			config:  SkillsConfig{Directory: ""},
 			wantErr: true,
 		},
+		{
+			name:    "concurrency groups",
+			config:  SkillsConfig{Directory: "/path/to/skills", ConcurrencyGroups: map[string]int{"gpu": 1, "scraper": 4}},
+			wantErr: false,
+		},
+		{
+			name:    "concurrency group without phases",
+			config:  SkillsConfig{Directory: "/path/to/skills", ConcurrencyGroups: map[string]int{"gpu": 0}},
+			wantErr: true,
+		},
+		{
+			name:    "concurrency group without name",
+			config:  SkillsConfig{Directory: "/path/to/skills", ConcurrencyGroups: map[string]int{" ": 1}},
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -286,6 +339,76 @@ func TestSkillsConfig_Validate(t *testing.T) {
 	}
 }
 
+func TestStorageConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  StorageConfig
+		wantErr bool
+	}{
+		{
+			name:    "default backend",
+			config:  StorageConfig{Backend: "sqlite"},
+			wantErr: false,
+		},
+		{
+			name:    "checkpoints in s3",
+			config:  StorageConfig{Backend: "sqlite", Checkpoints: CheckpointStoreConfig{Backend: "s3", Bucket: "runs"}},
+			wantErr: false,
+		},
+		{
+			name:    "checkpoints without bucket",
+			config:  StorageConfig{Backend: "sqlite", Checkpoints: CheckpointStoreConfig{Backend: "gcs"}},
+			wantErr: true,
+		},
+		{
+			name:    "unsupported checkpoint backend",
+			config:  StorageConfig{Backend: "sqlite", Checkpoints: CheckpointStoreConfig{Backend: "azure", Bucket: "runs"}},
+			wantErr: true,
+		},
+		{
+			name:    "negative archive age",
+			config:  StorageConfig{Backend: "sqlite", Archive: ArchiveConfig{AfterDays: -1}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.config.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCheckpointStoreConfig_Credentials(t *testing.T) {
+	t.Setenv("AWS_ACCESS_KEY_ID", "aws-id")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "aws-secret")
+	t.Setenv("AWS_SESSION_TOKEN", "aws-token")
+	t.Setenv("GCS_HMAC_ACCESS_ID", "gcs-id")
+	t.Setenv("GCS_HMAC_SECRET", "gcs-secret")
+	t.Setenv("RUNS_KEY", "runs-id")
+
+	tests := []struct {
+		name                  string
+		config                CheckpointStoreConfig
+		access, secret, token string
+	}{
+		{"s3", CheckpointStoreConfig{Backend: "s3"}, "aws-id", "aws-secret", "aws-token"},
+		{"gcs", CheckpointStoreConfig{Backend: "gcs"}, "gcs-id", "gcs-secret", ""},
+		{"custom variable", CheckpointStoreConfig{Backend: "s3", AccessKeyEnv: "RUNS_KEY"}, "runs-id", "aws-secret", "aws-token"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			access, secret, token := tt.config.Credentials()
+			if access != tt.access || secret != tt.secret || token != tt.token {
+				t.Errorf("Credentials() = %q, %q, %q", access, secret, token)
+			}
+		})
+	}
+}
+
 func TestConfig_Validate_MultipleErrors(t *testing.T) {
 	cfg := &Config{
 		Providers: ProviderConfigs{
@@ -0,0 +1,147 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ExpandEnv replaces ${VAR} and ${VAR:-default} references in s with the
+// values of environment variables. ${VAR} expands to the empty string if VAR
+// is unset; ${VAR:-default} expands to default if VAR is unset or empty.
+// $${ is a literal ${, and a $ not followed by { is left alone.
+func ExpandEnv(s string) (string, error) {
+	if !strings.Contains(s, "${") {
+		return s, nil
+	}
+
+	var b strings.Builder
+	for {
+		i := strings.Index(s, "${")
+		if i < 0 {
+			b.WriteString(s)
+			return b.String(), nil
+		}
+
+		if i > 0 && s[i-1] == '$' {
+			b.WriteString(s[:i-1])
+			b.WriteString("${")
+			s = s[i+2:]
+			continue
+		}
+		b.WriteString(s[:i])
+
+		end := strings.IndexByte(s[i:], '}')
+		if end < 0 {
+			return "", fmt.Errorf("unterminated variable reference in %q", s[i:])
+		}
+		ref := s[i+2 : i+end]
+		s = s[i+end+1:]
+
+		name, def, hasDefault := strings.Cut(ref, ":-")
+		if !isEnvName(name) {
+			return "", fmt.Errorf("invalid variable reference ${%s}", ref)
+		}
+		value := os.Getenv(name)
+		if value == "" && hasDefault {
+			value = def
+		}
+		b.WriteString(value)
+	}
+}
+
+// isEnvName reports whether name is a valid environment variable name.
+func isEnvName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for i, r := range name {
+		switch {
+		case r == '_', r >= 'A' && r <= 'Z', r >= 'a' && r <= 'z':
+		case r >= '0' && r <= '9' && i > 0:
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// UnmarshalYAMLWithEnv parses YAML like yaml.Unmarshal, expanding environment
+// variable references in its keys and values first. Expansion happens after
+// parsing, so a value containing YAML syntax cannot change the document's
+// structure, and an unquoted value is typed by what it expands to.
+func UnmarshalYAMLWithEnv(data []byte, v any) error {
+	doc, err := parseYAMLWithEnv(data)
+	if err != nil || doc == nil {
+		return err
+	}
+	return doc.Decode(v)
+}
+
+// parseYAMLWithEnv parses YAML into a node tree with environment variables
+// expanded in its scalars. It returns nil for an empty document.
+func parseYAMLWithEnv(data []byte) (*yaml.Node, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	if doc.Kind == 0 {
+		return nil, nil
+	}
+	if err := expandNodeEnv(&doc); err != nil {
+		return nil, err
+	}
+	return &doc, nil
+}
+
+func expandNodeEnv(n *yaml.Node) error {
+	if n.Kind == yaml.ScalarNode {
+		value, err := ExpandEnv(n.Value)
+		if err != nil {
+			return fmt.Errorf("line %d: %w", n.Line, err)
+		}
+		if value != n.Value {
+			n.Value = value
+			if n.Style == 0 {
+				// Resolve the tag again, so ${TIMEOUT:-30} decodes as an int
+				n.Tag = ""
+			}
+		}
+		return nil
+	}
+	for _, child := range n.Content {
+		if err := expandNodeEnv(child); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// expandKeysEnv expands environment variables in the values of the mapping
+// keys of n named in keys.
+func expandKeysEnv(n *yaml.Node, keys []string) error {
+	if n.Kind == yaml.MappingNode {
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			key, value := n.Content[i], n.Content[i+1]
+			var err error
+			if slices.Contains(keys, key.Value) {
+				err = expandNodeEnv(value)
+			} else {
+				err = expandKeysEnv(value, keys)
+			}
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	for _, child := range n.Content {
+		if err := expandKeysEnv(child, keys); err != nil {
+			return err
+		}
+	}
+	return nil
+}
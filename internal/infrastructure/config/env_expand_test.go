@@ -0,0 +1,88 @@
+package config
+
+import "testing"
+
+func TestExpandEnv(t *testing.T) {
+	t.Setenv("SR_TEST_HOST", "gateway.internal")
+	t.Setenv("SR_TEST_EMPTY", "")
+
+	tests := []struct {
+		name    string
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{name: "no references", input: "http://localhost:8000", want: "http://localhost:8000"},
+		{name: "set variable", input: "https://${SR_TEST_HOST}/v1", want: "https://gateway.internal/v1"},
+		{name: "unset variable", input: "a${SR_TEST_UNSET}b", want: "ab"},
+		{name: "default unused", input: "${SR_TEST_HOST:-localhost}", want: "gateway.internal"},
+		{name: "default for unset", input: "${SR_TEST_UNSET:-localhost}", want: "localhost"},
+		{name: "default for empty", input: "${SR_TEST_EMPTY:-localhost}", want: "localhost"},
+		{name: "empty default", input: "${SR_TEST_UNSET:-}", want: ""},
+		{name: "escaped", input: "$${SR_TEST_HOST}", want: "${SR_TEST_HOST}"},
+		{name: "bare dollar", input: "costs $5 or $SR_TEST_HOST", want: "costs $5 or $SR_TEST_HOST"},
+		{name: "unterminated", input: "${SR_TEST_HOST", wantErr: true},
+		{name: "invalid name", input: "${1ABC}", wantErr: true},
+		{name: "empty name", input: "${:-x}", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ExpandEnv(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ExpandEnv(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ExpandEnv(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadRoutingConfigFromBytes_EnvExpansion(t *testing.T) {
+	t.Setenv("SR_TEST_BASE_URL", "https://llm.example.com/v1")
+	t.Setenv("SR_TEST_MODEL", "llama-3.1-70b")
+	t.Setenv("SR_TEST_INJECT", "x\ninjected: true")
+
+	data := []byte(`
+default_provider: vllm
+providers:
+  vllm:
+    type: openai_compatible
+    base_url: ${SR_TEST_BASE_URL}
+    api_key_env: ${SR_TEST_KEY_ENV:-VLLM_API_KEY}
+    timeout: ${SR_TEST_TIMEOUT:-45}
+    enabled: true
+    models:
+      ${SR_TEST_MODEL}:
+        tier: cheap
+        enabled: true
+  ollama:
+    base_url: "${SR_TEST_INJECT}"
+    enabled: true
+`)
+
+	cfg, err := LoadRoutingConfigFromBytes(data)
+	if err != nil {
+		t.Fatalf("LoadRoutingConfigFromBytes() error = %v", err)
+	}
+
+	vllm := cfg.GetProvider("vllm")
+	if vllm.BaseURL != "https://llm.example.com/v1" {
+		t.Errorf("BaseURL = %q, want https://llm.example.com/v1", vllm.BaseURL)
+	}
+	if vllm.APIKeyEnv != "VLLM_API_KEY" {
+		t.Errorf("APIKeyEnv = %q, want VLLM_API_KEY", vllm.APIKeyEnv)
+	}
+	if vllm.Timeout != 45 {
+		t.Errorf("Timeout = %d, want 45", vllm.Timeout)
+	}
+	if vllm.GetModel("llama-3.1-70b") == nil {
+		t.Error("expected model named by SR_TEST_MODEL to be configured")
+	}
+
+	// A value is substituted as a whole and cannot add keys
+	if got := cfg.GetProvider("ollama").BaseURL; got != "x\ninjected: true" {
+		t.Errorf("ollama BaseURL = %q, want the literal value", got)
+	}
+}
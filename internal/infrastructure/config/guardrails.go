@@ -0,0 +1,85 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Default redactions of guardrails that list none: credentials and
+// personal data.
+var defaultGuardrailRedactions = []string{"secrets", "pii"}
+
+// GuardrailsConfiguration masks credentials and personal data in the prompts
+// sent to a provider, and reports what was masked.
+type GuardrailsConfiguration struct {
+	// Redact lists what is masked: secrets (API keys, tokens and private
+	// keys), pii (email addresses, social security and credit card
+	// numbers), or single rules such as email. Empty means secrets and pii.
+	Redact []string `yaml:"redact,omitempty"`
+
+	// Allow lists values that are never masked, such as a public support
+	// address. An entry between slashes, such as /.*@example\.com/, is a
+	// regular expression matching the whole value.
+	Allow []string `yaml:"allow,omitempty"`
+
+	// Disabled turns the guardrails off, such as for one provider when
+	// routing.yaml sets them for every cloud provider.
+	Disabled bool `yaml:"disabled,omitempty"`
+}
+
+// Redactions returns what is masked.
+func (g *GuardrailsConfiguration) Redactions() []string {
+	if len(g.Redact) == 0 {
+		return slices.Clone(defaultGuardrailRedactions)
+	}
+	return g.Redact
+}
+
+// Validate checks the redactions and the allowlist's regular expressions.
+func (g *GuardrailsConfiguration) Validate() error {
+	if g == nil {
+		return nil
+	}
+
+	var errs []error
+	for _, name := range g.Redact {
+		if strings.TrimSpace(name) == "" {
+			errs = append(errs, errors.New("redact contains an empty name"))
+		}
+	}
+	for _, entry := range g.Allow {
+		if len(entry) > 2 && strings.HasPrefix(entry, "/") && strings.HasSuffix(entry, "/") {
+			if _, err := regexp.Compile(entry[1 : len(entry)-1]); err != nil {
+				errs = append(errs, fmt.Errorf("allow entry %s: %w", entry, err))
+			}
+		}
+	}
+
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+	return nil
+}
+
+// ProviderGuardrails returns the guardrails of the named provider: its own,
+// or for a cloud provider the routing configuration's. Returns nil when the
+// provider's prompts are sent as they are.
+func (r *RoutingConfiguration) ProviderGuardrails(name string, local bool) *GuardrailsConfiguration {
+	if r == nil {
+		return nil
+	}
+	g := r.Guardrails
+	if local {
+		g = nil
+	}
+	if cfg := r.GetProvider(name); cfg != nil && cfg.Guardrails != nil {
+		g = cfg.Guardrails
+	}
+	if g == nil || g.Disabled {
+		return nil
+	}
+	return g
+}
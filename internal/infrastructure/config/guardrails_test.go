@@ -0,0 +1,56 @@
+package config
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestRoutingConfiguration_ProviderGuardrails(t *testing.T) {
+	shared := &GuardrailsConfiguration{}
+	own := &GuardrailsConfiguration{Redact: []string{"email"}}
+	rc := &RoutingConfiguration{
+		Guardrails: shared,
+		Providers: map[string]*ProviderConfiguration{
+			"anthropic": {Guardrails: own},
+			"openai":    {Guardrails: &GuardrailsConfiguration{Disabled: true}},
+			"groq":      {},
+			"ollama":    {Guardrails: own},
+		},
+	}
+
+	tests := []struct {
+		name  string
+		local bool
+		want  *GuardrailsConfiguration
+	}{
+		{"anthropic", false, own},
+		{"openai", false, nil},
+		{"groq", false, shared},
+		{"lmstudio", true, nil},
+		{"ollama", true, own},
+	}
+	for _, tt := range tests {
+		if got := rc.ProviderGuardrails(tt.name, tt.local); got != tt.want {
+			t.Errorf("ProviderGuardrails(%s) = %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+
+	if got := shared.Redactions(); !slices.Equal(got, []string{"secrets", "pii"}) {
+		t.Errorf("Redactions() = %v, want secrets and pii", got)
+	}
+}
+
+func TestGuardrailsConfiguration_Validate(t *testing.T) {
+	valid := &GuardrailsConfiguration{Redact: []string{"secrets"}, Allow: []string{"support@acme.io", `/.*@example\.com/`}}
+	if err := valid.Validate(); err != nil {
+		t.Errorf("Validate() = %v, want nil", err)
+	}
+	for _, g := range []*GuardrailsConfiguration{
+		{Redact: []string{" "}},
+		{Allow: []string{"/(/"}},
+	} {
+		if err := g.Validate(); err == nil {
+			t.Errorf("Validate(%+v) succeeded", g)
+		}
+	}
+}
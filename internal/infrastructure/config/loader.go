@@ -121,3 +121,8 @@ func (l *Loader) ConfigDir() string {
 func (l *Loader) DefaultConfigPath() string {
 	return filepath.Join(l.configDir, "config.yaml")
 }
+
+// DefaultRoutingConfigPath returns the default routing configuration file path.
+func (l *Loader) DefaultRoutingConfigPath() string {
+	return filepath.Join(l.configDir, "routing.yaml")
+}
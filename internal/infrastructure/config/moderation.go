@@ -0,0 +1,120 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/jbctechsolutions/skillrunner/internal/domain/skill"
+)
+
+// Classifiers of the moderation check.
+const (
+	// ModerationClassifierOpenAI checks prompts with the OpenAI moderation
+	// endpoint; the prompts are sent to OpenAI for the check.
+	ModerationClassifierOpenAI = "openai"
+	// ModerationClassifierLocal checks prompts with a safety model, such as
+	// Llama Guard, served by a local provider.
+	ModerationClassifierLocal = "local"
+)
+
+// DefaultModerationProvider serves the model of a local classifier unless
+// the moderation configuration names another provider.
+const DefaultModerationProvider = "ollama"
+
+// ModerationConfiguration checks the prompts sent to a provider with a
+// content classifier before they leave the machine, and blocks or reports
+// the requests it flags.
+type ModerationConfiguration struct {
+	// Classifier is openai (default) or local.
+	Classifier string `yaml:"classifier,omitempty"`
+
+	// Model is the classifier's model: for openai optional, defaulting to
+	// omni-moderation-latest; for local required, such as llama-guard3.
+	Model string `yaml:"model,omitempty"`
+
+	// Provider is the local provider serving the model of a local
+	// classifier. Empty means ollama.
+	Provider string `yaml:"provider,omitempty"`
+
+	// Action is what happens to a flagged request: block (default) fails it
+	// without sending it, warn sends it and reports it.
+	Action string `yaml:"action,omitempty"`
+
+	// Disabled turns the moderation off, such as for one provider when
+	// routing.yaml sets it for every cloud provider.
+	Disabled bool `yaml:"disabled,omitempty"`
+}
+
+// Kind returns the classifier: openai or local.
+func (m *ModerationConfiguration) Kind() string {
+	if m.Classifier == "" {
+		return ModerationClassifierOpenAI
+	}
+	return m.Classifier
+}
+
+// LocalProvider returns the provider serving the model of a local
+// classifier.
+func (m *ModerationConfiguration) LocalProvider() string {
+	if m.Provider == "" {
+		return DefaultModerationProvider
+	}
+	return m.Provider
+}
+
+// OnFlag returns what happens to a flagged request: block or warn.
+func (m *ModerationConfiguration) OnFlag() string {
+	if m.Action == "" {
+		return skill.ModerationBlock
+	}
+	return m.Action
+}
+
+// Validate checks the classifier, its model and the action.
+func (m *ModerationConfiguration) Validate() error {
+	if m == nil || m.Disabled {
+		return nil
+	}
+
+	var errs []error
+	switch m.Classifier {
+	case "", ModerationClassifierOpenAI:
+	case ModerationClassifierLocal:
+		if strings.TrimSpace(m.Model) == "" {
+			errs = append(errs, errors.New("the local classifier requires a model"))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("invalid classifier %q: must be openai or local", m.Classifier))
+	}
+	switch m.Action {
+	case "", skill.ModerationBlock, skill.ModerationWarn:
+	default:
+		errs = append(errs, fmt.Errorf("invalid action %q: must be block or warn", m.Action))
+	}
+
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+	return nil
+}
+
+// ProviderModeration returns the moderation of the named provider: its own,
+// or for a cloud provider the routing configuration's. Returns nil when the
+// provider's prompts are sent unchecked.
+func (r *RoutingConfiguration) ProviderModeration(name string, local bool) *ModerationConfiguration {
+	if r == nil {
+		return nil
+	}
+	m := r.Moderation
+	if local {
+		m = nil
+	}
+	if cfg := r.GetProvider(name); cfg != nil && cfg.Moderation != nil {
+		m = cfg.Moderation
+	}
+	if m == nil || m.Disabled {
+		return nil
+	}
+	return m
+}
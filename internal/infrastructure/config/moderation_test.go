@@ -0,0 +1,58 @@
+package config
+
+import "testing"
+
+func TestRoutingConfiguration_ProviderModeration(t *testing.T) {
+	shared := &ModerationConfiguration{}
+	own := &ModerationConfiguration{Classifier: ModerationClassifierLocal, Model: "llama-guard3", Action: "warn"}
+	rc := &RoutingConfiguration{
+		Moderation: shared,
+		Providers: map[string]*ProviderConfiguration{
+			"anthropic": {Moderation: own},
+			"openai":    {Moderation: &ModerationConfiguration{Disabled: true}},
+			"groq":      {},
+		},
+	}
+
+	tests := []struct {
+		name  string
+		local bool
+		want  *ModerationConfiguration
+	}{
+		{"anthropic", false, own},
+		{"openai", false, nil},
+		{"groq", false, shared},
+		{"ollama", true, nil},
+	}
+	for _, tt := range tests {
+		if got := rc.ProviderModeration(tt.name, tt.local); got != tt.want {
+			t.Errorf("ProviderModeration(%s) = %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+
+	if shared.Kind() != ModerationClassifierOpenAI || shared.OnFlag() != "block" || own.LocalProvider() != "ollama" {
+		t.Errorf("defaults = %s, %s, %s; want openai, block, ollama", shared.Kind(), shared.OnFlag(), own.LocalProvider())
+	}
+}
+
+func TestModerationConfiguration_Validate(t *testing.T) {
+	for _, m := range []*ModerationConfiguration{
+		nil,
+		{},
+		{Classifier: ModerationClassifierLocal, Model: "llama-guard3", Provider: "lmstudio", Action: "warn"},
+		{Classifier: "nosuch", Disabled: true},
+	} {
+		if err := m.Validate(); err != nil {
+			t.Errorf("Validate(%+v) = %v, want nil", m, err)
+		}
+	}
+	for _, m := range []*ModerationConfiguration{
+		{Classifier: ModerationClassifierLocal},
+		{Classifier: "perspective"},
+		{Action: "off"},
+	} {
+		if err := m.Validate(); err == nil {
+			t.Errorf("Validate(%+v) succeeded", m)
+		}
+	}
+}
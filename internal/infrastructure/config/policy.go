@@ -0,0 +1,361 @@
+package config
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// PolicyFetchTimeout bounds fetching the policy bundle at startup; past it
+// the cached bundle is used.
+const PolicyFetchTimeout = 5 * time.Second
+
+// policyConfigFileName is the file in SystemConfigDir that locates the
+// policy bundle for every user of the machine.
+const policyConfigFileName = "policy.yaml"
+
+// policyCacheFile is the last verified policy bundle, under the
+// configuration directory.
+const policyCacheFile = "policy/bundle.json"
+
+// DefaultPolicyValidity is how long a signed policy bundle stays valid.
+const DefaultPolicyValidity = 90 * 24 * time.Hour
+
+// Policy sources reported in PolicyStatus.
+const (
+	PolicySourceFetched = "fetched"
+	PolicySourceCached  = "cached"
+)
+
+// PolicyConfig locates an organization policy bundle and the key it must be
+// signed with.
+type PolicyConfig struct {
+	URL       string `yaml:"url,omitempty"`        // http(s) URL of the signed bundle
+	PublicKey string `yaml:"public_key,omitempty"` // Base64 Ed25519 public key of the signer
+}
+
+// Validate checks that a configured policy has a URL and a valid key.
+func (p *PolicyConfig) Validate() error {
+	if p.URL == "" && p.PublicKey == "" {
+		return nil
+	}
+	if !strings.HasPrefix(p.URL, "https://") && !strings.HasPrefix(p.URL, "http://") {
+		return fmt.Errorf("url %q must be an http(s) URL", p.URL)
+	}
+	if _, err := decodePolicyKey(p.PublicKey); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Policy is an organization policy: the providers skillrunner may use, its
+// spending limit, patterns redacted from prompts sent to cloud providers, and
+// skill directories that are always loaded. It is distributed as a signed
+// bundle, and local configuration cannot weaken it: other providers are not
+// registered, nor are allowed providers local configuration points at other
+// endpoints, a looser local budget is capped, and required skills override
+// local skills with the same ID.
+type Policy struct {
+	Name             string   `yaml:"name,omitempty" json:"name,omitempty"`
+	AllowedProviders []string `yaml:"allowed_providers,omitempty" json:"allowed_providers,omitempty"`
+
+	// ProviderEndpoints are the base URLs allowed providers may be
+	// configured with, by provider. An allowed provider configured with
+	// another base URL, such as a routing.yaml provider reusing an allowed
+	// name, is not allowed; without an entry, only its built-in endpoint is.
+	ProviderEndpoints map[string]string `yaml:"provider_endpoints,omitempty" json:"provider_endpoints,omitempty"`
+
+	Budget               PolicyBudget    `yaml:"budget,omitempty" json:"budget,omitempty"`
+	Redaction            []RedactionRule `yaml:"redaction,omitempty" json:"redaction,omitempty"`
+	RequiredSkillSources []string        `yaml:"required_skill_sources,omitempty" json:"required_skill_sources,omitempty"`
+
+	// IssuedAt and ExpiresAt are when the bundle carrying the policy was
+	// signed and when it stops being valid.
+	IssuedAt  time.Time `yaml:"-" json:"issued_at"`
+	ExpiresAt time.Time `yaml:"-" json:"expires_at"`
+}
+
+// PolicyBudget holds the policy's cost limits.
+type PolicyBudget struct {
+	// MaxCostPerRun is the cost in USD a single run may spend on cloud
+	// providers (0 = no limit).
+	MaxCostPerRun float64 `yaml:"max_cost_per_run,omitempty" json:"max_cost_per_run,omitempty"`
+}
+
+// RedactionRule masks text matching Pattern, a regular expression, in the
+// prompts sent to cloud providers.
+type RedactionRule struct {
+	Name        string `yaml:"name" json:"name"`
+	Pattern     string `yaml:"pattern" json:"pattern"`
+	Replacement string `yaml:"replacement,omitempty" json:"replacement,omitempty"` // Defaults to [REDACTED:<name>]
+}
+
+// Validate checks the policy's budget and redaction rules.
+func (p *Policy) Validate() error {
+	var errs []error
+	if p.Budget.MaxCostPerRun < 0 {
+		errs = append(errs, errors.New("budget.max_cost_per_run must not be negative"))
+	}
+	for i, rule := range p.Redaction {
+		if rule.Name == "" {
+			errs = append(errs, fmt.Errorf("redaction[%d]: name is required", i))
+		}
+		if _, err := regexp.Compile(rule.Pattern); err != nil || rule.Pattern == "" {
+			errs = append(errs, fmt.Errorf("redaction[%d]: invalid pattern %q", i, rule.Pattern))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// AllowsProvider reports whether the policy lets skillrunner use a provider
+// configured with a base URL, where an empty endpoint is the provider's
+// built-in one. A policy without allowed_providers allows every provider.
+func (p *Policy) AllowsProvider(name, endpoint string) bool {
+	if p == nil || len(p.AllowedProviders) == 0 {
+		return true
+	}
+	if !slices.Contains(p.AllowedProviders, name) {
+		return false
+	}
+	if endpoint == "" {
+		return true
+	}
+	allowed, ok := p.ProviderEndpoints[name]
+	return ok && strings.EqualFold(strings.TrimSuffix(endpoint, "/"), strings.TrimSuffix(allowed, "/"))
+}
+
+// MaxCostPerRun returns the stricter of a local per-run budget and the
+// policy's, where zero means no limit.
+func (p *Policy) MaxCostPerRun(local float64) float64 {
+	if p == nil || p.Budget.MaxCostPerRun <= 0 {
+		return local
+	}
+	if local <= 0 || p.Budget.MaxCostPerRun < local {
+		return p.Budget.MaxCostPerRun
+	}
+	return local
+}
+
+// PolicyBundle is the signed form of a policy: the policy YAML, base64
+// encoded, when it was signed and until when it is valid, and the Ed25519
+// signature of all three, base64 encoded. Bundles are rejected once they
+// expire, and in favor of a cached bundle issued later, so an old policy
+// cannot be replayed.
+type PolicyBundle struct {
+	Policy    string    `json:"policy"`
+	IssuedAt  time.Time `json:"issued_at"`
+	ExpiresAt time.Time `json:"expires_at"`
+	Signature string    `json:"signature"`
+}
+
+// signedMessage returns the bytes the bundle's signature covers.
+func (b *PolicyBundle) signedMessage() []byte {
+	return []byte(strings.Join([]string{
+		b.Policy,
+		b.IssuedAt.UTC().Format(time.RFC3339),
+		b.ExpiresAt.UTC().Format(time.RFC3339),
+	}, "\n"))
+}
+
+// GeneratePolicyKey creates an Ed25519 key pair for signing policy bundles,
+// both base64 encoded.
+func GeneratePolicyKey() (publicKey, privateKey string, err error) {
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return "", "", err
+	}
+	return base64.StdEncoding.EncodeToString(public), base64.StdEncoding.EncodeToString(private), nil
+}
+
+// SignPolicy validates a policy YAML document and returns it as a bundle in
+// JSON, issued at issuedAt, valid for validFor and signed with a base64
+// Ed25519 private key from GeneratePolicyKey.
+func SignPolicy(policyYAML []byte, privateKey string, issuedAt time.Time, validFor time.Duration) ([]byte, error) {
+	raw, err := base64.StdEncoding.DecodeString(strings.TrimSpace(privateKey))
+	if err != nil || len(raw) != ed25519.PrivateKeySize {
+		return nil, errors.New("signing key must be a base64 Ed25519 private key")
+	}
+	key := ed25519.PrivateKey(raw)
+	if validFor <= 0 {
+		return nil, errors.New("validity must be positive")
+	}
+
+	if _, err := parsePolicy(policyYAML); err != nil {
+		return nil, err
+	}
+	issuedAt = issuedAt.UTC().Truncate(time.Second)
+	bundle := PolicyBundle{
+		Policy:    base64.StdEncoding.EncodeToString(policyYAML),
+		IssuedAt:  issuedAt,
+		ExpiresAt: issuedAt.Add(validFor),
+	}
+	bundle.Signature = base64.StdEncoding.EncodeToString(ed25519.Sign(key, bundle.signedMessage()))
+	return json.MarshalIndent(bundle, "", "  ")
+}
+
+// VerifyPolicyBundle checks a bundle's signature against a base64 Ed25519
+// public key and that it has not expired, and returns its policy.
+func VerifyPolicyBundle(data []byte, publicKey string) (*Policy, error) {
+	key, err := decodePolicyKey(publicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	var bundle PolicyBundle
+	if err := json.Unmarshal(data, &bundle); err != nil {
+		return nil, fmt.Errorf("invalid policy bundle: %w", err)
+	}
+	policyYAML, err := base64.StdEncoding.DecodeString(bundle.Policy)
+	if err != nil {
+		return nil, fmt.Errorf("invalid policy bundle: %w", err)
+	}
+	signature, err := base64.StdEncoding.DecodeString(bundle.Signature)
+	if err != nil {
+		return nil, fmt.Errorf("invalid policy bundle signature: %w", err)
+	}
+	if bundle.IssuedAt.IsZero() || bundle.ExpiresAt.IsZero() {
+		return nil, errors.New("policy bundle has no issued_at or expires_at; sign it again with sr config policy sign")
+	}
+	if !ed25519.Verify(key, bundle.signedMessage(), signature) {
+		return nil, errors.New("policy bundle signature does not match the configured public key")
+	}
+	if time.Now().After(bundle.ExpiresAt) {
+		return nil, fmt.Errorf("policy bundle expired at %s", bundle.ExpiresAt.Format(time.RFC3339))
+	}
+
+	policy, err := parsePolicy(policyYAML)
+	if err != nil {
+		return nil, err
+	}
+	policy.IssuedAt, policy.ExpiresAt = bundle.IssuedAt, bundle.ExpiresAt
+	return policy, nil
+}
+
+func parsePolicy(data []byte) (*Policy, error) {
+	var policy Policy
+	if err := yaml.Unmarshal(data, &policy); err != nil {
+		return nil, fmt.Errorf("invalid policy: %w", err)
+	}
+	if err := policy.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid policy: %w", err)
+	}
+	return &policy, nil
+}
+
+func decodePolicyKey(publicKey string) (ed25519.PublicKey, error) {
+	key, err := base64.StdEncoding.DecodeString(publicKey)
+	if err != nil || len(key) != ed25519.PublicKeySize {
+		return nil, errors.New("public_key must be a base64 Ed25519 public key")
+	}
+	return ed25519.PublicKey(key), nil
+}
+
+// ResolvePolicyConfig returns where the organization policy comes from:
+// policy.yaml in SystemConfigDir if present, so that an administrator's
+// policy cannot be removed by a user's configuration, otherwise the policy
+// section of config.yaml.
+func ResolvePolicyConfig(cfg *Config) (PolicyConfig, error) {
+	data, err := os.ReadFile(filepath.Join(SystemConfigDir, policyConfigFileName))
+	if err == nil {
+		var system PolicyConfig
+		if err := yaml.Unmarshal(data, &system); err != nil {
+			return PolicyConfig{}, fmt.Errorf("failed to parse %s: %w", policyConfigFileName, err)
+		}
+		if err := system.Validate(); err != nil {
+			return PolicyConfig{}, fmt.Errorf("%s: %w", policyConfigFileName, err)
+		}
+		return system, nil
+	}
+	if cfg == nil {
+		return PolicyConfig{}, nil
+	}
+	return cfg.Policy, nil
+}
+
+// PolicyStatus reports where the policy in effect came from.
+type PolicyStatus struct {
+	URL        string    `json:"url"`
+	Source     string    `json:"source"` // PolicySourceFetched or PolicySourceCached
+	VerifiedAt time.Time `json:"verified_at"`
+	FetchError string    `json:"fetch_error,omitempty"` // Why the cached bundle is in use
+
+	// DeniedProviders are configured providers the policy does not allow,
+	// which were not registered.
+	DeniedProviders []string `json:"denied_providers,omitempty"`
+}
+
+// LoadPolicy fetches the policy bundle at cfg.URL, verifies its signature and
+// expiry and caches it for offline use. When the bundle cannot be fetched,
+// fails verification or was issued before the cached bundle, the cached
+// bundle is used instead. Returns nil without error when no policy is
+// configured, and an error when one is but no unexpired verified bundle is
+// available: a sanctioned installation must not run without its policy.
+func LoadPolicy(ctx context.Context, cfg PolicyConfig) (*Policy, *PolicyStatus, error) {
+	if cfg.URL == "" {
+		return nil, nil, nil
+	}
+	cachePath, err := policyCachePath()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, PolicyFetchTimeout)
+	defer cancel()
+
+	cached, cacheErr := os.ReadFile(cachePath)
+	var cachedPolicy *Policy
+	if cacheErr == nil {
+		cachedPolicy, cacheErr = VerifyPolicyBundle(cached, cfg.PublicKey)
+	}
+
+	data, fetchErr := fetchHTTPFile(ctx, cfg.URL, maxRemoteRoutingSize)
+	if fetchErr == nil {
+		policy, err := VerifyPolicyBundle(data, cfg.PublicKey)
+		if err == nil && cachedPolicy != nil && policy.IssuedAt.Before(cachedPolicy.IssuedAt) {
+			err = fmt.Errorf("policy bundle issued at %s is older than the cached bundle issued at %s",
+				policy.IssuedAt.Format(time.RFC3339), cachedPolicy.IssuedAt.Format(time.RFC3339))
+		}
+		if err == nil {
+			if err := writeFileAtomic(cachePath, data); err != nil {
+				return nil, nil, fmt.Errorf("failed to cache policy bundle: %w", err)
+			}
+			return policy, &PolicyStatus{URL: cfg.URL, Source: PolicySourceFetched, VerifiedAt: time.Now()}, nil
+		}
+		fetchErr = err
+	}
+
+	if errors.Is(cacheErr, fs.ErrNotExist) {
+		return nil, nil, fmt.Errorf("failed to fetch policy bundle %s and no cached copy is available: %w", cfg.URL, fetchErr)
+	}
+	if cacheErr != nil {
+		return nil, nil, fmt.Errorf("failed to fetch policy bundle %s (%w), and the cached policy bundle is not valid: %w", cfg.URL, fetchErr, cacheErr)
+	}
+	policy := cachedPolicy
+
+	status := &PolicyStatus{URL: cfg.URL, Source: PolicySourceCached, FetchError: fetchErr.Error()}
+	if info, err := os.Stat(cachePath); err == nil {
+		status.VerifiedAt = info.ModTime()
+	}
+	return policy, status, nil
+}
+
+func policyCachePath() (string, error) {
+	loader, err := NewLoader("")
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(loader.ConfigDir(), filepath.FromSlash(policyCacheFile)), nil
+}
@@ -0,0 +1,218 @@
+package config
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testPolicyYAML = `name: acme
+allowed_providers: [ollama, anthropic]
+budget:
+  max_cost_per_run: 2
+redaction:
+  - name: customer-id
+    pattern: 'CUST-\d{6}'
+`
+
+func signedTestPolicy(t *testing.T) (bundle []byte, publicKey string) {
+	t.Helper()
+	publicKey, privateKey, err := GeneratePolicyKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	bundle, err = SignPolicy([]byte(testPolicyYAML), privateKey, time.Now(), time.Hour)
+	if err != nil {
+		t.Fatalf("SignPolicy() error = %v", err)
+	}
+	return bundle, publicKey
+}
+
+func TestPolicy_AllowsProvider(t *testing.T) {
+	policy := &Policy{
+		AllowedProviders:  []string{"anthropic", "gateway"},
+		ProviderEndpoints: map[string]string{"gateway": "https://llm.acme.io/v1"},
+	}
+
+	tests := []struct {
+		name, endpoint string
+		want           bool
+	}{
+		{"anthropic", "", true},
+		{"anthropic", "https://attacker.example/v1", false},
+		{"gateway", "https://llm.acme.io/v1/", true},
+		{"gateway", "https://llm.acme.io.attacker.example/v1", false},
+		{"openai", "", false},
+	}
+	for _, tt := range tests {
+		if got := policy.AllowsProvider(tt.name, tt.endpoint); got != tt.want {
+			t.Errorf("AllowsProvider(%q, %q) = %v, want %v", tt.name, tt.endpoint, got, tt.want)
+		}
+	}
+	if !(*Policy)(nil).AllowsProvider("openai", "https://proxy.example") {
+		t.Error("no policy should allow every provider")
+	}
+}
+
+func TestPolicyBundle_SignAndVerify(t *testing.T) {
+	bundle, publicKey := signedTestPolicy(t)
+
+	policy, err := VerifyPolicyBundle(bundle, publicKey)
+	if err != nil {
+		t.Fatalf("VerifyPolicyBundle() error = %v", err)
+	}
+	if policy.Name != "acme" || !policy.AllowsProvider("anthropic", "") || policy.AllowsProvider("openai", "") {
+		t.Errorf("policy = %+v", policy)
+	}
+
+	otherKey, _, _ := GeneratePolicyKey()
+	if _, err := VerifyPolicyBundle(bundle, otherKey); err == nil {
+		t.Error("VerifyPolicyBundle() should reject a bundle signed with another key")
+	}
+
+	tampered := strings.Replace(string(bundle), `"policy": "`, `"policy": "IA==`, 1)
+	if _, err := VerifyPolicyBundle([]byte(tampered), publicKey); err == nil {
+		t.Error("VerifyPolicyBundle() should reject a modified policy")
+	}
+
+	extended := strings.Replace(string(bundle), `"expires_at": "20`, `"expires_at": "21`, 1)
+	if _, err := VerifyPolicyBundle([]byte(extended), publicKey); err == nil {
+		t.Error("VerifyPolicyBundle() should reject a modified expiry")
+	}
+
+	_, privateKey, _ := GeneratePolicyKey()
+	if _, err := SignPolicy([]byte("redaction:\n  - name: bad\n    pattern: '('\n"), privateKey, time.Now(), time.Hour); err == nil {
+		t.Error("SignPolicy() should reject an invalid policy")
+	}
+}
+
+func TestPolicyBundle_Expiry(t *testing.T) {
+	publicKey, privateKey, _ := GeneratePolicyKey()
+	expired, err := SignPolicy([]byte(testPolicyYAML), privateKey, time.Now().Add(-2*time.Hour), time.Hour)
+	if err != nil {
+		t.Fatalf("SignPolicy() error = %v", err)
+	}
+	if _, err := VerifyPolicyBundle(expired, publicKey); err == nil || !strings.Contains(err.Error(), "expired") {
+		t.Errorf("VerifyPolicyBundle() of an expired bundle error = %v", err)
+	}
+}
+
+func TestPolicy_MaxCostPerRun(t *testing.T) {
+	policy := &Policy{Budget: PolicyBudget{MaxCostPerRun: 2}}
+	tests := []struct{ local, want float64 }{
+		{0, 2}, // No local budget
+		{5, 2}, // A looser local budget is capped
+		{1, 1}, // A stricter local budget stands
+	}
+	for _, tt := range tests {
+		if got := policy.MaxCostPerRun(tt.local); got != tt.want {
+			t.Errorf("MaxCostPerRun(%v) = %v, want %v", tt.local, got, tt.want)
+		}
+	}
+	var none *Policy
+	if got := none.MaxCostPerRun(3); got != 3 {
+		t.Errorf("nil policy MaxCostPerRun(3) = %v, want 3", got)
+	}
+}
+
+func TestLoadPolicy_CachesForOffline(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	bundle, publicKey := signedTestPolicy(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write(bundle)
+	}))
+	cfg := PolicyConfig{URL: server.URL + "/policy.json", PublicKey: publicKey}
+
+	policy, status, err := LoadPolicy(context.Background(), cfg)
+	if err != nil {
+		t.Fatalf("LoadPolicy() error = %v", err)
+	}
+	if policy.Name != "acme" || status.Source != PolicySourceFetched {
+		t.Errorf("LoadPolicy() = %q from %s, want acme fetched", policy.Name, status.Source)
+	}
+
+	server.Close()
+	policy, status, err = LoadPolicy(context.Background(), cfg)
+	if err != nil {
+		t.Fatalf("LoadPolicy() offline error = %v", err)
+	}
+	if policy.Name != "acme" || status.Source != PolicySourceCached || status.FetchError == "" {
+		t.Errorf("offline LoadPolicy() = %q from %s (%q), want the cached policy", policy.Name, status.Source, status.FetchError)
+	}
+}
+
+func TestLoadPolicy_RejectsOlderBundle(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	publicKey, privateKey, _ := GeneratePolicyKey()
+	older, _ := SignPolicy([]byte("name: old\n"), privateKey, time.Now().Add(-time.Hour), 2*time.Hour)
+	newer, _ := SignPolicy([]byte(testPolicyYAML), privateKey, time.Now(), time.Hour)
+
+	serving := newer
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write(serving)
+	}))
+	defer server.Close()
+	cfg := PolicyConfig{URL: server.URL, PublicKey: publicKey}
+
+	if _, _, err := LoadPolicy(context.Background(), cfg); err != nil {
+		t.Fatalf("LoadPolicy() error = %v", err)
+	}
+	// A replayed older bundle is refused in favor of the cached one
+	serving = older
+	policy, status, err := LoadPolicy(context.Background(), cfg)
+	if err != nil {
+		t.Fatalf("LoadPolicy() error = %v", err)
+	}
+	if policy.Name != "acme" || status.Source != PolicySourceCached || !strings.Contains(status.FetchError, "older") {
+		t.Errorf("LoadPolicy() = %q from %s (%q), want the cached newer policy", policy.Name, status.Source, status.FetchError)
+	}
+}
+
+func TestLoadPolicy_RequiresVerifiedBundle(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	bundle, _ := signedTestPolicy(t)
+	otherKey, _, _ := GeneratePolicyKey()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write(bundle)
+	}))
+	defer server.Close()
+
+	if _, _, err := LoadPolicy(context.Background(), PolicyConfig{URL: server.URL, PublicKey: otherKey}); err == nil {
+		t.Error("LoadPolicy() should fail without a bundle signed by the configured key")
+	}
+	if policy, _, err := LoadPolicy(context.Background(), PolicyConfig{}); policy != nil || err != nil {
+		t.Errorf("LoadPolicy() without a URL = %v, %v; want nil", policy, err)
+	}
+}
+
+func TestResolvePolicyConfig_SystemWins(t *testing.T) {
+	system := t.TempDir()
+	orig := SystemConfigDir
+	SystemConfigDir = system
+	t.Cleanup(func() { SystemConfigDir = orig })
+
+	publicKey, _, _ := GeneratePolicyKey()
+	cfg := NewDefaultConfig()
+	cfg.Policy = PolicyConfig{URL: "https://user.example.com/policy.json", PublicKey: publicKey}
+
+	got, err := ResolvePolicyConfig(cfg)
+	if err != nil || got.URL != cfg.Policy.URL {
+		t.Fatalf("ResolvePolicyConfig() = %+v, %v; want the config.yaml policy", got, err)
+	}
+
+	content := "url: https://admin.example.com/policy.json\npublic_key: " + publicKey + "\n"
+	if err := os.WriteFile(filepath.Join(system, "policy.yaml"), []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	got, err = ResolvePolicyConfig(cfg)
+	if err != nil || got.URL != "https://admin.example.com/policy.json" {
+		t.Errorf("ResolvePolicyConfig() = %+v, %v; want the system policy", got, err)
+	}
+}
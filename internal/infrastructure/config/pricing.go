@@ -0,0 +1,314 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/jbctechsolutions/skillrunner/internal/domain/provider"
+)
+
+// DefaultPricingURL is the pricing catalog synced by default: the model price
+// list maintained by the LiteLLM project, which covers every cloud provider
+// skillrunner supports.
+const DefaultPricingURL = "https://raw.githubusercontent.com/BerriAI/litellm/main/model_prices_and_context_window.json"
+
+// DefaultPricingMaxAge is how old the cached pricing catalog may get before
+// a background refresh syncs it again.
+const DefaultPricingMaxAge = 7 * 24 * time.Hour
+
+// PricingSyncTimeout bounds fetching the pricing catalog.
+const PricingSyncTimeout = 30 * time.Second
+
+// maxPricingCatalogSize is the largest pricing catalog fetched over HTTP.
+const maxPricingCatalogSize = 16 << 20
+
+// pricingCacheFile is the last synced pricing catalog, under the
+// configuration directory.
+const pricingCacheFile = "pricing/catalog.json"
+
+// catalogProviders maps the provider names used by the LiteLLM catalog to
+// skillrunner's.
+var catalogProviders = map[string]string{
+	"anthropic":    provider.ProviderAnthropic,
+	"openai":       provider.ProviderOpenAI,
+	"groq":         provider.ProviderGroq,
+	"together_ai":  provider.ProviderTogether,
+	"fireworks_ai": provider.ProviderFireworks,
+}
+
+// PricingConfig controls syncing per-token model prices from a pricing
+// catalog, so that cost estimates follow provider price changes.
+type PricingConfig struct {
+	// URL is the catalog to sync; DefaultPricingURL when empty. Either the
+	// LiteLLM format or a catalog written by sr models sync-pricing.
+	URL string `yaml:"url,omitempty"`
+
+	// AutoSync refreshes the catalog in the background at startup when it
+	// is older than MaxAge.
+	AutoSync bool `yaml:"auto_sync,omitempty"`
+
+	// MaxAge is how old the catalog may get before AutoSync refreshes it
+	// (default: 7 days).
+	MaxAge time.Duration `yaml:"max_age,omitempty"`
+}
+
+// SourceURL returns the catalog URL to sync.
+func (p PricingConfig) SourceURL() string {
+	if p.URL == "" {
+		return DefaultPricingURL
+	}
+	return p.URL
+}
+
+// Validate checks the pricing settings.
+func (p *PricingConfig) Validate() error {
+	if p.URL != "" && !strings.HasPrefix(p.URL, "https://") && !strings.HasPrefix(p.URL, "http://") {
+		return fmt.Errorf("url %q must be an http(s) URL", p.URL)
+	}
+	if p.MaxAge < 0 {
+		return errors.New("max_age must be non-negative")
+	}
+	return nil
+}
+
+// ModelPrice is the per-token price of one model.
+type ModelPrice struct {
+	Provider           string  `json:"provider"`
+	Model              string  `json:"model"`
+	CostPerInputToken  float64 `json:"cost_per_input_token"`
+	CostPerOutputToken float64 `json:"cost_per_output_token"`
+}
+
+// PricingCatalog is a synced set of model prices.
+type PricingCatalog struct {
+	Source    string       `json:"source"`
+	UpdatedAt time.Time    `json:"updated_at"`
+	Models    []ModelPrice `json:"models"`
+}
+
+// Lookup returns the price of a provider's model, if the catalog has it.
+func (c *PricingCatalog) Lookup(providerName, modelID string) (ModelPrice, bool) {
+	if c == nil {
+		return ModelPrice{}, false
+	}
+	for _, m := range c.Models {
+		if m.Provider == providerName && m.Model == modelID {
+			return m, true
+		}
+	}
+	return ModelPrice{}, false
+}
+
+// Apply sets the costs of the models in rc that the catalog prices and
+// that have no cost configured, and returns how many it set. Costs written
+// in routing files take precedence, e.g. for negotiated rates.
+func (c *PricingCatalog) Apply(rc *RoutingConfiguration) int {
+	if c == nil || rc == nil {
+		return 0
+	}
+	applied := 0
+	for name, p := range rc.Providers {
+		if p == nil {
+			continue
+		}
+		for id, m := range p.Models {
+			if m == nil || m.CostPerInputToken != 0 || m.CostPerOutputToken != 0 {
+				continue
+			}
+			if price, ok := c.Lookup(name, id); ok {
+				m.CostPerInputToken = price.CostPerInputToken
+				m.CostPerOutputToken = price.CostPerOutputToken
+				applied++
+			}
+		}
+	}
+	return applied
+}
+
+// Register adds the catalog's prices to a cost calculator, replacing the
+// built-in defaults of the models it prices.
+func (c *PricingCatalog) Register(calc *provider.CostCalculator) {
+	if c == nil || calc == nil {
+		return
+	}
+	for _, m := range c.Models {
+		calc.RegisterModelWithProvider(m.Model, m.Provider, m.CostPerInputToken*1000, m.CostPerOutputToken*1000)
+	}
+}
+
+// PriceChange is a model whose price differs between two catalogs.
+type PriceChange struct {
+	Provider string      `json:"provider"`
+	Model    string      `json:"model"`
+	Old      *ModelPrice `json:"old,omitempty"` // nil for a new model
+	New      *ModelPrice `json:"new,omitempty"` // nil for a removed model
+}
+
+// Changes lists the models priced differently in c than in old, sorted by
+// provider and model.
+func (c *PricingCatalog) Changes(old *PricingCatalog) []PriceChange {
+	type key struct{ provider, model string }
+	index := func(catalog *PricingCatalog) map[key]ModelPrice {
+		prices := make(map[key]ModelPrice)
+		if catalog != nil {
+			for _, m := range catalog.Models {
+				prices[key{m.Provider, m.Model}] = m
+			}
+		}
+		return prices
+	}
+	before, after := index(old), index(c)
+
+	var changes []PriceChange
+	for k, n := range after {
+		o, ok := before[k]
+		switch {
+		case !ok:
+			changes = append(changes, PriceChange{Provider: k.provider, Model: k.model, New: &n})
+		case o != n:
+			changes = append(changes, PriceChange{Provider: k.provider, Model: k.model, Old: &o, New: &n})
+		}
+	}
+	for k, o := range before {
+		if _, ok := after[k]; !ok {
+			changes = append(changes, PriceChange{Provider: k.provider, Model: k.model, Old: &o})
+		}
+	}
+	slices.SortFunc(changes, func(a, b PriceChange) int {
+		if c := strings.Compare(a.Provider, b.Provider); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Model, b.Model)
+	})
+	return changes
+}
+
+// litellmEntry is a model in the LiteLLM pricing catalog.
+type litellmEntry struct {
+	Provider           string  `json:"litellm_provider"`
+	Mode               string  `json:"mode"`
+	CostPerInputToken  float64 `json:"input_cost_per_token"`
+	CostPerOutputToken float64 `json:"output_cost_per_token"`
+}
+
+// ParsePricingCatalog parses a pricing catalog in the LiteLLM format or in
+// the format written by sr models sync-pricing. Only the chat models of the
+// providers skillrunner supports are kept.
+func ParsePricingCatalog(data []byte) (*PricingCatalog, error) {
+	var own PricingCatalog
+	if err := json.Unmarshal(data, &own); err == nil && len(own.Models) > 0 {
+		return &own, nil
+	}
+
+	var entries map[string]json.RawMessage
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("invalid pricing catalog: %w", err)
+	}
+
+	catalog := &PricingCatalog{}
+	for id, raw := range entries {
+		var e litellmEntry
+		if json.Unmarshal(raw, &e) != nil {
+			continue // e.g. the catalog's sample_spec
+		}
+		name, ok := catalogProviders[e.Provider]
+		if !ok || (e.Mode != "" && e.Mode != "chat" && e.Mode != "completion") {
+			continue
+		}
+		if e.CostPerInputToken <= 0 && e.CostPerOutputToken <= 0 {
+			continue
+		}
+		catalog.Models = append(catalog.Models, ModelPrice{
+			Provider:           name,
+			Model:              strings.TrimPrefix(id, e.Provider+"/"),
+			CostPerInputToken:  e.CostPerInputToken,
+			CostPerOutputToken: e.CostPerOutputToken,
+		})
+	}
+	if len(catalog.Models) == 0 {
+		return nil, errors.New("pricing catalog has no prices for supported providers")
+	}
+	slices.SortFunc(catalog.Models, func(a, b ModelPrice) int {
+		if c := strings.Compare(a.Provider, b.Provider); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Model, b.Model)
+	})
+	return catalog, nil
+}
+
+// FetchPricingCatalog downloads and parses the catalog at url.
+func FetchPricingCatalog(ctx context.Context, url string) (*PricingCatalog, error) {
+	ctx, cancel := context.WithTimeout(ctx, PricingSyncTimeout)
+	defer cancel()
+
+	data, err := fetchHTTPFile(ctx, url, maxPricingCatalogSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch pricing catalog %s: %w", url, err)
+	}
+	catalog, err := ParsePricingCatalog(data)
+	if err != nil {
+		return nil, err
+	}
+	catalog.Source = url
+	catalog.UpdatedAt = time.Now().UTC()
+	return catalog, nil
+}
+
+// SavePricingCatalog caches a catalog for LoadPricingCatalog.
+func SavePricingCatalog(catalog *PricingCatalog) error {
+	path, err := pricingCachePath()
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(catalog, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(path, data)
+}
+
+// LoadPricingCatalog returns the last synced catalog, or nil without error
+// if none has been synced.
+func LoadPricingCatalog() (*PricingCatalog, error) {
+	path, err := pricingCachePath()
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var catalog PricingCatalog
+	if err := json.Unmarshal(data, &catalog); err != nil {
+		return nil, fmt.Errorf("invalid cached pricing catalog: %w", err)
+	}
+	return &catalog, nil
+}
+
+// PricingStale reports whether a catalog should be synced again: it is
+// missing or older than maxAge (DefaultPricingMaxAge when zero).
+func PricingStale(catalog *PricingCatalog, maxAge time.Duration) bool {
+	if maxAge <= 0 {
+		maxAge = DefaultPricingMaxAge
+	}
+	return catalog == nil || time.Since(catalog.UpdatedAt) > maxAge
+}
+
+func pricingCachePath() (string, error) {
+	loader, err := NewLoader("")
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(loader.ConfigDir(), filepath.FromSlash(pricingCacheFile)), nil
+}
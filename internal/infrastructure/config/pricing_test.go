@@ -0,0 +1,196 @@
+package config
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/jbctechsolutions/skillrunner/internal/domain/provider"
+)
+
+const litellmCatalog = `{
+  "sample_spec": {"litellm_provider": "one of the providers", "input_cost_per_token": 0},
+  "claude-sonnet-4-5": {"litellm_provider": "anthropic", "mode": "chat", "input_cost_per_token": 3e-06, "output_cost_per_token": 1.5e-05},
+  "groq/llama-3.3-70b-versatile": {"litellm_provider": "groq", "mode": "chat", "input_cost_per_token": 5.9e-07, "output_cost_per_token": 7.9e-07},
+  "text-embedding-3-small": {"litellm_provider": "openai", "mode": "embedding", "input_cost_per_token": 2e-08},
+  "bedrock/claude": {"litellm_provider": "bedrock", "mode": "chat", "input_cost_per_token": 3e-06},
+  "gpt-free": {"litellm_provider": "openai", "mode": "chat"}
+}`
+
+func TestParsePricingCatalog_LiteLLM(t *testing.T) {
+	catalog, err := ParsePricingCatalog([]byte(litellmCatalog))
+	if err != nil {
+		t.Fatalf("ParsePricingCatalog() error = %v", err)
+	}
+
+	want := []ModelPrice{
+		{Provider: "anthropic", Model: "claude-sonnet-4-5", CostPerInputToken: 3e-06, CostPerOutputToken: 1.5e-05},
+		{Provider: "groq", Model: "llama-3.3-70b-versatile", CostPerInputToken: 5.9e-07, CostPerOutputToken: 7.9e-07},
+	}
+	if len(catalog.Models) != len(want) {
+		t.Fatalf("Models = %+v, want %+v", catalog.Models, want)
+	}
+	for i := range want {
+		if catalog.Models[i] != want[i] {
+			t.Errorf("Models[%d] = %+v, want %+v", i, catalog.Models[i], want[i])
+		}
+	}
+}
+
+func TestParsePricingCatalog_Invalid(t *testing.T) {
+	for name, data := range map[string]string{
+		"not JSON":              "<html>",
+		"no supported provider": `{"bedrock/claude": {"litellm_provider": "bedrock", "input_cost_per_token": 3e-06}}`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			if _, err := ParsePricingCatalog([]byte(data)); err == nil {
+				t.Error("ParsePricingCatalog() should fail")
+			}
+		})
+	}
+}
+
+func TestPricingCatalog_Apply(t *testing.T) {
+	catalog := &PricingCatalog{Models: []ModelPrice{
+		{Provider: "anthropic", Model: "claude-sonnet-4-5", CostPerInputToken: 3e-06, CostPerOutputToken: 1.5e-05},
+		{Provider: "anthropic", Model: "claude-negotiated", CostPerInputToken: 3e-06, CostPerOutputToken: 1.5e-05},
+	}}
+	rc := &RoutingConfiguration{Providers: map[string]*ProviderConfiguration{
+		"anthropic": {Models: map[string]*ModelConfiguration{
+			"claude-sonnet-4-5": {},
+			"claude-negotiated": {CostPerInputToken: 1e-06, CostPerOutputToken: 5e-06},
+			"claude-unlisted":   {},
+		}},
+	}}
+
+	if got := catalog.Apply(rc); got != 1 {
+		t.Errorf("Apply() = %d, want 1", got)
+	}
+	models := rc.Providers["anthropic"].Models
+	if m := models["claude-sonnet-4-5"]; m.CostPerInputToken != 3e-06 || m.CostPerOutputToken != 1.5e-05 {
+		t.Errorf("unpriced model = %+v, want the catalog price", m)
+	}
+	if m := models["claude-negotiated"]; m.CostPerInputToken != 1e-06 {
+		t.Errorf("configured cost = %v, should take precedence over the catalog", m.CostPerInputToken)
+	}
+	if m := models["claude-unlisted"]; m.CostPerInputToken != 0 {
+		t.Errorf("model missing from the catalog = %v, want unpriced", m.CostPerInputToken)
+	}
+}
+
+func TestPricingCatalog_Register(t *testing.T) {
+	calc := provider.NewCostCalculator()
+	provider.PopulateCostCalculator(calc)
+
+	catalog := &PricingCatalog{Models: []ModelPrice{
+		{Provider: "openai", Model: "gpt-4o", CostPerInputToken: 2e-06, CostPerOutputToken: 8e-06},
+	}}
+	catalog.Register(calc)
+
+	rate := calc.GetModelCost("gpt-4o")
+	if rate == nil || rate.InputRate != 0.002 || rate.OutputRate != 0.008 {
+		t.Errorf("gpt-4o rate = %+v, want the synced price per 1K tokens", rate)
+	}
+}
+
+func TestPricingCatalog_Changes(t *testing.T) {
+	old := &PricingCatalog{Models: []ModelPrice{
+		{Provider: "openai", Model: "gpt-4o", CostPerInputToken: 2.5e-06, CostPerOutputToken: 1e-05},
+		{Provider: "openai", Model: "gpt-4", CostPerInputToken: 3e-05, CostPerOutputToken: 6e-05},
+		{Provider: "groq", Model: "llama", CostPerInputToken: 1e-07, CostPerOutputToken: 1e-07},
+	}}
+	current := &PricingCatalog{Models: []ModelPrice{
+		{Provider: "openai", Model: "gpt-4o", CostPerInputToken: 2e-06, CostPerOutputToken: 8e-06},
+		{Provider: "openai", Model: "gpt-5", CostPerInputToken: 1.25e-06, CostPerOutputToken: 1e-05},
+		{Provider: "groq", Model: "llama", CostPerInputToken: 1e-07, CostPerOutputToken: 1e-07},
+	}}
+
+	changes := current.Changes(old)
+	if len(changes) != 3 {
+		t.Fatalf("Changes() = %+v, want 3 changes", changes)
+	}
+	if c := changes[0]; c.Model != "gpt-4" || c.Old == nil || c.New != nil {
+		t.Errorf("changes[0] = %+v, want gpt-4 removed", c)
+	}
+	if c := changes[1]; c.Model != "gpt-4o" || c.Old == nil || c.New == nil {
+		t.Errorf("changes[1] = %+v, want gpt-4o repriced", c)
+	}
+	if c := changes[2]; c.Model != "gpt-5" || c.Old != nil || c.New == nil {
+		t.Errorf("changes[2] = %+v, want gpt-5 added", c)
+	}
+
+	if got := current.Changes(nil); len(got) != 3 {
+		t.Errorf("Changes(nil) = %d changes, want every model added", len(got))
+	}
+}
+
+func TestFetchPricingCatalog_SaveAndLoad(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, litellmCatalog)
+	}))
+	defer server.Close()
+
+	if cached, err := LoadPricingCatalog(); err != nil || cached != nil {
+		t.Fatalf("LoadPricingCatalog() before a sync = %v, %v; want nil, nil", cached, err)
+	}
+
+	catalog, err := FetchPricingCatalog(context.Background(), server.URL)
+	if err != nil {
+		t.Fatalf("FetchPricingCatalog() error = %v", err)
+	}
+	if catalog.Source != server.URL || catalog.UpdatedAt.IsZero() {
+		t.Errorf("catalog source = %q, updated at %v", catalog.Source, catalog.UpdatedAt)
+	}
+	if err := SavePricingCatalog(catalog); err != nil {
+		t.Fatalf("SavePricingCatalog() error = %v", err)
+	}
+
+	cached, err := LoadPricingCatalog()
+	if err != nil {
+		t.Fatalf("LoadPricingCatalog() error = %v", err)
+	}
+	if len(cached.Models) != len(catalog.Models) || cached.Source != server.URL {
+		t.Errorf("cached catalog = %+v, want the synced one", cached)
+	}
+
+	// A catalog in our own format, e.g. an internal mirror of the cache
+	data, err := ParsePricingCatalog([]byte(`{"models": [{"provider": "openai", "model": "gpt-4o", "cost_per_input_token": 2e-06}]}`))
+	if err != nil || len(data.Models) != 1 {
+		t.Errorf("ParsePricingCatalog() of a cached catalog = %+v, %v", data, err)
+	}
+}
+
+func TestPricingStale(t *testing.T) {
+	fresh := &PricingCatalog{UpdatedAt: time.Now()}
+	old := &PricingCatalog{UpdatedAt: time.Now().Add(-8 * 24 * time.Hour)}
+
+	if !PricingStale(nil, 0) {
+		t.Error("a missing catalog should be stale")
+	}
+	if PricingStale(fresh, 0) {
+		t.Error("a fresh catalog should not be stale")
+	}
+	if !PricingStale(old, 0) {
+		t.Error("a catalog older than the default max age should be stale")
+	}
+	if PricingStale(old, 30*24*time.Hour) {
+		t.Error("a catalog within max_age should not be stale")
+	}
+}
+
+func TestPricingConfig_Validate(t *testing.T) {
+	if err := (&PricingConfig{URL: "file:///etc/prices.json"}).Validate(); err == nil {
+		t.Error("a non-http URL should be rejected")
+	}
+	if err := (&PricingConfig{MaxAge: -time.Hour}).Validate(); err == nil {
+		t.Error("a negative max_age should be rejected")
+	}
+	if err := (&PricingConfig{URL: "https://example.com/prices.json", AutoSync: true}).Validate(); err != nil {
+		t.Errorf("Validate() error = %v", err)
+	}
+}
@@ -0,0 +1,288 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/jbctechsolutions/skillrunner/internal/infrastructure/crypto"
+
+	"github.com/jbctechsolutions/skillrunner/internal/domain/skill"
+)
+
+// ProjectConfigFileName is the per-repository configuration file, discovered by
+// walking up from the working directory.
+const ProjectConfigFileName = ".skillrunner.yaml"
+
+// ProjectConfig holds the project settings of a .skillrunner.yaml file.
+// The file may also contain routing keys (default_provider, profiles,
+// fallback_chain) in the routing.yaml format; those are merged over the
+// user's routing configuration by the routing chain. Providers, budgets,
+// guardrails and moderation are accepted from trusted projects only (see
+// RoutingConfig.TrustedProjects).
+type ProjectConfig struct {
+	// Path is the file the configuration was loaded from.
+	Path string `yaml:"-"`
+
+	// Routing holds the file's routing keys as an overlay (see LoadRoutingOverlay).
+	Routing *RoutingConfiguration `yaml:"-"`
+
+	// DefaultProfile is the routing profile used when --profile is not given.
+	DefaultProfile string `yaml:"default_profile,omitempty"`
+
+	// DefaultSkill is the skill sr run uses when only a request is given.
+	DefaultSkill string `yaml:"default_skill,omitempty"`
+
+	// Aliases maps shortcut command names to the sr command line they run,
+	// e.g. review: run code-review "Review the staged changes".
+	Aliases map[string]string `yaml:"aliases,omitempty"`
+
+	// Skills configures the project's skills.
+	Skills ProjectSkillsConfig `yaml:"skills,omitempty"`
+
+	// Memory configures memory files injected into prompts.
+	Memory ProjectMemoryConfig `yaml:"memory,omitempty"`
+
+	// Budget limits what a run may cost.
+	Budget ProjectBudgetConfig `yaml:"budget,omitempty"`
+}
+
+// ProjectSkillsConfig holds project skill settings.
+type ProjectSkillsConfig struct {
+	// Directory holds project skills, relative to the project root
+	// (default: .skillrunner/skills).
+	Directory string `yaml:"directory,omitempty"`
+}
+
+// ProjectMemoryConfig holds project memory settings.
+type ProjectMemoryConfig struct {
+	// Files are additional memory files, relative to the project root, injected
+	// after MEMORY.md/CLAUDE.md.
+	Files []string `yaml:"files,omitempty"`
+}
+
+// ProjectBudgetConfig holds project cost limits.
+type ProjectBudgetConfig struct {
+	// MaxCostPerRun is the cost in USD a single run may spend on cloud
+	// providers (0 = no limit).
+	MaxCostPerRun float64 `yaml:"max_cost_per_run,omitempty"`
+}
+
+// FindProjectConfig returns the path of the nearest .skillrunner.yaml in dir
+// or one of its parents, or "" if there is none.
+func FindProjectConfig(dir string) string {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return ""
+	}
+
+	for {
+		path := filepath.Join(dir, ProjectConfigFileName)
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			return path
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// LoadProjectConfig loads a .skillrunner.yaml file. Its routing keys are
+// validated too, so a broken file is reported once here.
+func LoadProjectConfig(path string) (*ProjectConfig, error) {
+	if path == "" {
+		return nil, errors.New("config path is empty")
+	}
+
+	data, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file %q: %w", path, err)
+	}
+
+	// Check the whole file, routing keys included, before decoding the
+	// project settings; a sops document is checked once decrypted
+	if !crypto.IsSopsDocument(data) {
+		doc, err := parseYAMLWithEnv(data)
+		if err == nil && doc != nil {
+			err = ValidateNode(doc, ProjectSchema())
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse YAML: %w", err)
+		}
+	}
+
+	cfg := &ProjectConfig{}
+	if err := yaml.Unmarshal(data, cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse YAML: %w", err)
+	}
+	cfg.Path = path
+
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("config validation failed: %w", err)
+	}
+
+	if _, err := LoadAndMergeRoutingConfigs(path); err != nil {
+		return nil, err
+	}
+	if cfg.Routing, err = LoadRoutingOverlay(path); err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
+// Validate checks if the ProjectConfig is valid.
+func (p *ProjectConfig) Validate() error {
+	var errs []error
+
+	if p.DefaultProfile != "" && !skill.IsValidProfileName(p.DefaultProfile) {
+		errs = append(errs, fmt.Errorf("invalid default_profile %q: must be cheap, balanced, premium or a custom profile name", p.DefaultProfile))
+	}
+
+	if p.Budget.MaxCostPerRun < 0 {
+		errs = append(errs, errors.New("budget.max_cost_per_run cannot be negative"))
+	}
+
+	for _, file := range p.Memory.Files {
+		if file == "" {
+			errs = append(errs, errors.New("memory.files contains an empty path"))
+		}
+	}
+
+	for name := range p.Aliases {
+		if !isValidAliasName(name) {
+			errs = append(errs, fmt.Errorf("invalid alias name %q: use letters, digits, '-' and '_'", name))
+			continue
+		}
+		if _, err := p.AliasArgs(name); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// AliasArgs returns the arguments the named alias expands to.
+func (p *ProjectConfig) AliasArgs(name string) ([]string, error) {
+	line, ok := p.Aliases[name]
+	if !ok {
+		return nil, fmt.Errorf("alias %q is not defined", name)
+	}
+
+	args, err := splitCommandLine(line)
+	if err != nil {
+		return nil, fmt.Errorf("alias %q: %w", name, err)
+	}
+	if len(args) == 0 {
+		return nil, fmt.Errorf("alias %q: command is empty", name)
+	}
+	return args, nil
+}
+
+// Root returns the directory containing the project configuration file.
+func (p *ProjectConfig) Root() string {
+	return filepath.Dir(p.Path)
+}
+
+// SkillsDir returns the absolute project skills directory.
+func (p *ProjectConfig) SkillsDir() string {
+	if p.Skills.Directory == "" {
+		return filepath.Join(p.Root(), ".skillrunner", "skills")
+	}
+	return p.resolve(p.Skills.Directory)
+}
+
+// MemoryFiles returns the absolute paths of the project memory files.
+func (p *ProjectConfig) MemoryFiles() []string {
+	files := make([]string, len(p.Memory.Files))
+	for i, file := range p.Memory.Files {
+		files[i] = p.resolve(file)
+	}
+	return files
+}
+
+// resolve makes path absolute relative to the project root.
+func (p *ProjectConfig) resolve(path string) string {
+	if filepath.IsAbs(path) {
+		return filepath.Clean(path)
+	}
+	return filepath.Join(p.Root(), path)
+}
+
+// isValidAliasName reports whether name can be used as a command name.
+func isValidAliasName(name string) bool {
+	if name == "" || name[0] == '-' {
+		return false
+	}
+	for _, r := range name {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '-' && r != '_' {
+			return false
+		}
+	}
+	return true
+}
+
+// splitCommandLine splits line into arguments at unquoted whitespace. Single
+// quotes preserve their content literally; inside double quotes and outside
+// quotes a backslash escapes the next character.
+func splitCommandLine(line string) ([]string, error) {
+	var (
+		args    []string
+		current strings.Builder
+		inArg   bool
+		quote   rune
+		escaped bool
+	)
+
+	for _, r := range line {
+		switch {
+		case escaped:
+			current.WriteRune(r)
+			escaped = false
+		case quote == '\'':
+			if r == quote {
+				quote = 0
+			} else {
+				current.WriteRune(r)
+			}
+		case r == '\\':
+			escaped, inArg = true, true
+		case quote == '"':
+			if r == quote {
+				quote = 0
+			} else {
+				current.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote, inArg = r, true
+		case unicode.IsSpace(r):
+			if inArg {
+				args = append(args, current.String())
+				current.Reset()
+				inArg = false
+			}
+		default:
+			current.WriteRune(r)
+			inArg = true
+		}
+	}
+
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated %c quote", quote)
+	}
+	if escaped {
+		return nil, errors.New("trailing backslash")
+	}
+	if inArg {
+		args = append(args, current.String())
+	}
+	return args, nil
+}
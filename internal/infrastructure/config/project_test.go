@@ -0,0 +1,155 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func writeProjectFile(t *testing.T, dir, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, ProjectConfigFileName)
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("Failed to write project config: %v", err)
+	}
+	return path
+}
+
+func TestFindProjectConfig(t *testing.T) {
+	root := t.TempDir()
+	nested := filepath.Join(root, "a", "b")
+	if err := os.MkdirAll(nested, 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := FindProjectConfig(nested); got != "" {
+		t.Errorf("FindProjectConfig() = %q before the file exists, want empty", got)
+	}
+
+	path := writeProjectFile(t, root, "default_profile: cheap\n")
+	if got := FindProjectConfig(nested); got != path {
+		t.Errorf("FindProjectConfig() = %q, want %q", got, path)
+	}
+
+	// The nearest file wins
+	inner := writeProjectFile(t, filepath.Join(root, "a"), "")
+	if got := FindProjectConfig(nested); got != inner {
+		t.Errorf("FindProjectConfig() = %q, want nearest %q", got, inner)
+	}
+}
+
+func TestLoadProjectConfig(t *testing.T) {
+	root := t.TempDir()
+	path := writeProjectFile(t, root, `
+default_profile: premium
+skills:
+  directory: tools/skills
+memory:
+  files: [docs/CONVENTIONS.md, /etc/shared.md]
+budget:
+  max_cost_per_run: 0.25
+profiles:
+  premium:
+    generation_model: gpt-4o
+`)
+
+	cfg, err := LoadProjectConfig(path)
+	if err != nil {
+		t.Fatalf("LoadProjectConfig() error = %v", err)
+	}
+
+	if cfg.DefaultProfile != "premium" || cfg.Budget.MaxCostPerRun != 0.25 {
+		t.Errorf("unexpected project settings: %+v", cfg)
+	}
+	if cfg.Root() != root {
+		t.Errorf("Root() = %q, want %q", cfg.Root(), root)
+	}
+	if want := filepath.Join(root, "tools", "skills"); cfg.SkillsDir() != want {
+		t.Errorf("SkillsDir() = %q, want %q", cfg.SkillsDir(), want)
+	}
+
+	files := cfg.MemoryFiles()
+	if len(files) != 2 || files[0] != filepath.Join(root, "docs", "CONVENTIONS.md") || files[1] != "/etc/shared.md" {
+		t.Errorf("MemoryFiles() = %v", files)
+	}
+
+	if cfg.Routing == nil || cfg.Routing.Profiles["premium"].GenerationModel != "gpt-4o" {
+		t.Errorf("Routing overlay = %+v, want premium profile", cfg.Routing)
+	}
+	if cfg.Routing.DefaultProvider != "" || cfg.Routing.FallbackChain != nil {
+		t.Error("Routing overlay should not have defaults applied")
+	}
+}
+
+func TestLoadProjectConfig_DefaultSkillsDir(t *testing.T) {
+	root := t.TempDir()
+	cfg, err := LoadProjectConfig(writeProjectFile(t, root, ""))
+	if err != nil {
+		t.Fatalf("LoadProjectConfig() error = %v", err)
+	}
+	if want := filepath.Join(root, ".skillrunner", "skills"); cfg.SkillsDir() != want {
+		t.Errorf("SkillsDir() = %q, want %q", cfg.SkillsDir(), want)
+	}
+}
+
+func TestLoadProjectConfig_Aliases(t *testing.T) {
+	path := writeProjectFile(t, t.TempDir(), `
+default_skill: code-review
+aliases:
+  review: run code-review "Review the staged changes" --profile cheap
+  plan-it: plan 'refactor' it\'s
+`)
+
+	cfg, err := LoadProjectConfig(path)
+	if err != nil {
+		t.Fatalf("LoadProjectConfig() error = %v", err)
+	}
+	if cfg.DefaultSkill != "code-review" {
+		t.Errorf("DefaultSkill = %q", cfg.DefaultSkill)
+	}
+
+	args, err := cfg.AliasArgs("review")
+	if err != nil {
+		t.Fatalf("AliasArgs() error = %v", err)
+	}
+	if want := []string{"run", "code-review", "Review the staged changes", "--profile", "cheap"}; !slices.Equal(args, want) {
+		t.Errorf("AliasArgs(review) = %q, want %q", args, want)
+	}
+
+	args, _ = cfg.AliasArgs("plan-it")
+	if want := []string{"plan", "refactor", "it's"}; !slices.Equal(args, want) {
+		t.Errorf("AliasArgs(plan-it) = %q, want %q", args, want)
+	}
+
+	if _, err := cfg.AliasArgs("missing"); err == nil {
+		t.Error("expected error for an undefined alias")
+	}
+}
+
+func TestLoadProjectConfig_Invalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{"invalid profile", "default_profile: Fastest\n"},
+		{"negative budget", "budget:\n  max_cost_per_run: -1\n"},
+		{"empty memory file", "memory:\n  files: [\"\"]\n"},
+		{"invalid routing", "profiles:\n  Turbo:\n    generation_model: x\n"},
+		{"invalid YAML", "default_profile: [cheap\n"},
+		{"invalid alias name", "aliases:\n  \"-x\": version\n"},
+		{"empty alias", "aliases:\n  review: \"\"\n"},
+		{"unterminated alias quote", "aliases:\n  review: run code-review 'staged\n"},
+		{"unknown key", "default_skil: code-review\n"},
+		{"wrong routing type", "fallback_chain: ollama\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeProjectFile(t, t.TempDir(), tt.content)
+			if _, err := LoadProjectConfig(path); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
@@ -0,0 +1,176 @@
+package config
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Configuration sources, from lowest to highest precedence.
+const (
+	SourceDefaults = "defaults"
+	SourceRemote   = "remote"
+	SourceSystem   = "system"
+	SourceGlobal   = "global"
+	SourceProject  = "project"
+	SourceEnv      = "env"
+	SourceFlags    = "flags"
+)
+
+// redactedValue replaces secret values in settings output.
+const redactedValue = "<redacted>"
+
+// Layer is one configuration source and the values it supplies.
+type Layer struct {
+	Source string // One of the Source constants
+	File   string // File the values were read from, if any
+	Value  any    // Configuration as of this layer; see TraceSettings and TraceRoutingSettings
+}
+
+// Setting is a single effective configuration value and the layer that supplied it.
+type Setting struct {
+	Path   string `json:"path"`
+	Value  string `json:"value"`
+	Source string `json:"source"`
+	File   string `json:"file,omitempty"`
+}
+
+// TraceSettings flattens the last layer into settings and attributes each one
+// to the last layer that changed it. Each layer's Value must be the cumulative
+// configuration after applying that layer over the ones before it. Settings
+// are sorted by path.
+func TraceSettings(layers ...Layer) ([]Setting, error) {
+	origins := make(map[string]Layer)
+	var previous map[string]string
+
+	for _, layer := range layers {
+		values, err := flattenSettings(layer.Value)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s configuration: %w", layer.Source, err)
+		}
+		for path, value := range values {
+			if old, ok := previous[path]; !ok || old != value {
+				origins[path] = layer
+			}
+		}
+		previous = values
+	}
+
+	settings := make([]Setting, 0, len(previous))
+	for path, value := range previous {
+		origin := origins[path]
+		settings = append(settings, Setting{Path: path, Value: value, Source: origin.Source, File: origin.File})
+	}
+	slices.SortFunc(settings, func(a, b Setting) int { return strings.Compare(a.Path, b.Path) })
+	return settings, nil
+}
+
+// TraceRoutingSettings merges routing layers in order, as MergeRoutingConfigs
+// does, and traces which layer supplied each effective setting. Each layer's
+// Value is the *RoutingConfiguration read from that source alone.
+func TraceRoutingSettings(layers ...Layer) (*RoutingConfiguration, []Setting, error) {
+	var merged *RoutingConfiguration
+	cumulative := make([]Layer, 0, len(layers))
+
+	for _, layer := range layers {
+		rc, ok := layer.Value.(*RoutingConfiguration)
+		if !ok {
+			return nil, nil, fmt.Errorf("%s layer is %T, not a routing configuration", layer.Source, layer.Value)
+		}
+		merged = MergeRoutingConfigs(merged, rc)
+		cumulative = append(cumulative, Layer{Source: layer.Source, File: layer.File, Value: merged})
+	}
+
+	settings, err := TraceSettings(cumulative...)
+	if err != nil {
+		return nil, nil, err
+	}
+	return merged, settings, nil
+}
+
+// flattenSettings renders a configuration as dotted YAML paths and scalar
+// values. Unset values are omitted and lists of scalars are joined.
+func flattenSettings(v any) (map[string]string, error) {
+	data, err := yaml.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var tree any
+	if err := yaml.Unmarshal(data, &tree); err != nil {
+		return nil, err
+	}
+
+	values := make(map[string]string)
+	flattenNode("", tree, values)
+	return values, nil
+}
+
+func flattenNode(path string, node any, values map[string]string) {
+	switch n := node.(type) {
+	case nil:
+	case map[string]any:
+		for key, child := range n {
+			childPath := key
+			if path != "" {
+				childPath = path + "." + key
+			}
+			flattenNode(childPath, child, values)
+		}
+	case []any:
+		scalars := make([]string, 0, len(n))
+		for i, item := range n {
+			switch item.(type) {
+			case map[string]any, []any:
+				flattenNode(fmt.Sprintf("%s[%d]", path, i), item, values)
+			default:
+				scalars = append(scalars, fmt.Sprint(item))
+			}
+		}
+		if len(scalars) > 0 {
+			values[path] = strings.Join(scalars, ", ")
+		}
+	default:
+		value := fmt.Sprint(n)
+		if value == "" {
+			return
+		}
+		if isSecretSetting(path) {
+			value = redactedValue
+		}
+		values[path] = value
+	}
+}
+
+// secretKeys are the keys whose values are secrets, wherever they appear in
+// a configuration. Keys naming the environment variable that holds a
+// secret, such as api_key_env, are not secrets.
+var secretKeys = map[string]bool{
+	"api_key":           true,
+	"api_key_encrypted": true,
+	"token":             true,
+	"password":          true,
+	"secret":            true,
+}
+
+// secretMaps are the maps whose values are all secrets, such as the
+// headers that carry a telemetry backend's API key. Their keys are shown.
+var secretMaps = map[string]bool{
+	"otlp_headers": true,
+}
+
+// isSecretSetting reports whether the setting at a dotted path holds a
+// secret that settings output must redact.
+func isSecretSetting(path string) bool {
+	keys := strings.Split(path, ".")
+	for i, key := range keys {
+		if j := strings.IndexByte(key, '['); j >= 0 {
+			key = key[:j]
+		}
+		if i == len(keys)-1 && secretKeys[key] || i < len(keys)-1 && secretMaps[key] {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,113 @@
+package config
+
+import (
+	"testing"
+)
+
+func findSetting(settings []Setting, path string) (Setting, bool) {
+	for _, s := range settings {
+		if s.Path == path {
+			return s, true
+		}
+	}
+	return Setting{}, false
+}
+
+func TestTraceSettings(t *testing.T) {
+	defaults := NewDefaultConfig()
+	user := NewDefaultConfig()
+	user.Logging.Level = "debug"
+	user.Providers.Anthropic.APIKeyEncrypted = "secret"
+	user.Serve.Clients = []ServeClient{{Name: "ci", Token: "s3cret", TokenEnv: "SR_CI_TOKEN"}}
+	user.Observability.Tracing.OTLPHeaders = map[string]string{"x-honeycomb-team": "hc-key"}
+
+	settings, err := TraceSettings(
+		Layer{Source: SourceDefaults, Value: defaults},
+		Layer{Source: SourceGlobal, File: "config.yaml", Value: user},
+	)
+	if err != nil {
+		t.Fatalf("TraceSettings failed: %v", err)
+	}
+
+	tests := []struct {
+		path, value, source string
+	}{
+		{"logging.level", "debug", SourceGlobal},
+		{"logging.format", DefaultLogFormat, SourceDefaults},
+		{"providers.ollama.timeout", "30s", SourceDefaults},
+		{"providers.anthropic.api_key_encrypted", redactedValue, SourceGlobal},
+		{"serve.clients[0].token", redactedValue, SourceGlobal},
+		{"serve.clients[0].token_env", "SR_CI_TOKEN", SourceGlobal},
+		{"observability.tracing.otlp_headers.x-honeycomb-team", redactedValue, SourceGlobal},
+	}
+	for _, tt := range tests {
+		s, ok := findSetting(settings, tt.path)
+		if !ok {
+			t.Errorf("missing setting %s", tt.path)
+			continue
+		}
+		if s.Value != tt.value || s.Source != tt.source {
+			t.Errorf("%s = %q from %s, want %q from %s", tt.path, s.Value, s.Source, tt.value, tt.source)
+		}
+	}
+
+	if s, _ := findSetting(settings, "logging.level"); s.File != "config.yaml" {
+		t.Errorf("logging.level file = %q, want config.yaml", s.File)
+	}
+	for i := 1; i < len(settings); i++ {
+		if settings[i-1].Path >= settings[i].Path {
+			t.Fatalf("settings not sorted: %s before %s", settings[i-1].Path, settings[i].Path)
+		}
+	}
+}
+
+func TestTraceRoutingSettings(t *testing.T) {
+	project := &RoutingConfiguration{
+		DefaultProvider: "openai",
+		Providers: map[string]*ProviderConfiguration{
+			"openai": {Enabled: true, Models: map[string]*ModelConfiguration{
+				"gpt-4o": {Tier: "premium", Enabled: true},
+			}},
+		},
+		Profiles: map[string]*ProfileConfiguration{
+			"cheap": {GenerationModel: "gpt-4o-mini"},
+		},
+	}
+
+	merged, settings, err := TraceRoutingSettings(
+		Layer{Source: SourceDefaults, Value: NewRoutingConfiguration()},
+		Layer{Source: SourceProject, File: ".skillrunner.yaml", Value: project},
+	)
+	if err != nil {
+		t.Fatalf("TraceRoutingSettings failed: %v", err)
+	}
+	if merged.DefaultProvider != "openai" {
+		t.Errorf("merged DefaultProvider = %q, want openai", merged.DefaultProvider)
+	}
+
+	tests := []struct {
+		path, value, source string
+	}{
+		{"default_provider", "openai", SourceProject},
+		{"providers.openai.models.gpt-4o.tier", "premium", SourceProject},
+		{"profiles.cheap.generation_model", "gpt-4o-mini", SourceProject},
+		{"profiles.premium.generation_model", merged.Profiles["premium"].GenerationModel, SourceDefaults},
+		{"fallback_chain", "ollama, groq, openai, anthropic", SourceDefaults},
+	}
+	for _, tt := range tests {
+		s, ok := findSetting(settings, tt.path)
+		if !ok {
+			t.Errorf("missing setting %s", tt.path)
+			continue
+		}
+		if s.Value != tt.value || s.Source != tt.source {
+			t.Errorf("%s = %q from %s, want %q from %s", tt.path, s.Value, s.Source, tt.value, tt.source)
+		}
+	}
+}
+
+func TestTraceRoutingSettings_RejectsOtherValues(t *testing.T) {
+	if _, _, err := TraceRoutingSettings(Layer{Source: SourceDefaults, Value: NewDefaultConfig()}); err == nil {
+		t.Error("expected error for a non-routing layer")
+	}
+}
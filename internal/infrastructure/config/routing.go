@@ -4,6 +4,8 @@ package config
 import (
 	"errors"
 	"fmt"
+	"net/url"
+	"slices"
 
 	"github.com/jbctechsolutions/skillrunner/internal/domain/provider"
 	"github.com/jbctechsolutions/skillrunner/internal/domain/skill"
@@ -18,15 +20,55 @@ type RoutingConfiguration struct {
 	// DefaultProvider is the provider to use when no specific routing is defined.
 	DefaultProvider string `yaml:"default_provider"`
 
-	// Profiles maps routing profiles (cheap/balanced/premium) to model selections.
+	// Profiles maps routing profiles to model selections: the built-in cheap,
+	// balanced and premium profiles, and custom profiles such as "reasoning"
+	// or "offline" that skill phases can reference by name.
 	Profiles map[string]*ProfileConfiguration `yaml:"profiles"`
 
 	// FallbackChain defines the order of fallback providers when the primary is unavailable.
 	FallbackChain []string `yaml:"fallback_chain"`
+
+	// Experiments maps experiment names to A/B experiments that route a share
+	// of phase executions to a candidate model.
+	Experiments map[string]*ExperimentConfiguration `yaml:"experiments,omitempty"`
+
+	// PowerPolicy moves phases from local to cloud providers while a laptop
+	// runs on battery or under thermal pressure.
+	PowerPolicy *PowerPolicyConfiguration `yaml:"power_policy,omitempty"`
+
+	// Budgets caps what cloud providers may cost per day and per month.
+	Budgets *BudgetConfiguration `yaml:"budgets,omitempty"`
+
+	// Guardrails masks credentials and personal data in the prompts sent to
+	// every cloud provider without guardrails of its own.
+	Guardrails *GuardrailsConfiguration `yaml:"guardrails,omitempty"`
+
+	// Moderation checks the prompts sent to every cloud provider without
+	// moderation of its own with a content classifier.
+	Moderation *ModerationConfiguration `yaml:"moderation,omitempty"`
 }
 
+// ProviderTypeOpenAICompatible declares a provider that speaks the OpenAI Chat
+// Completions wire format. Such providers are instantiated from routing
+// configuration alone, without a dedicated adapter.
+const ProviderTypeOpenAICompatible = "openai_compatible"
+
 // ProviderConfiguration defines configuration for a single LLM provider.
 type ProviderConfiguration struct {
+	// Type selects a generic adapter for providers without built-in support.
+	// Empty for built-in providers; "openai_compatible" for any gateway that
+	// implements the OpenAI Chat Completions API.
+	Type string `yaml:"type,omitempty"`
+
+	// APIKeyEnv names the environment variable holding the provider's API key.
+	APIKeyEnv string `yaml:"api_key_env,omitempty"`
+
+	// APIKey is the provider's API key, encrypted with age (ASCII armor, as
+	// written by sr config encrypt) or in a file encrypted by sops. It is
+	// decrypted when the configuration is loaded. APIKeyEnv takes precedence
+	// when its variable is set.
+	APIKey string `yaml:"api_key,omitempty"`
+
 	// Enabled determines if this provider is active.
 	Enabled bool `yaml:"enabled"`
 
@@ -44,6 +86,30 @@ type ProviderConfiguration struct {
 
 	// Timeout is the request timeout in seconds.
 	Timeout int `yaml:"timeout"`
+
+	// Timeouts overrides Timeout for each kind of operation, such as short
+	// health checks for a local model whose completions take minutes.
+	Timeouts *TimeoutConfiguration `yaml:"timeouts,omitempty"`
+
+	// ProxyURL routes the provider's requests through an egress proxy
+	// (http, https or socks5) instead of the one in HTTPS_PROXY.
+	ProxyURL string `yaml:"proxy_url,omitempty"`
+
+	// CACertPath is a PEM file of certificate authorities to trust in
+	// addition to the system's, such as a TLS-inspecting proxy's.
+	CACertPath string `yaml:"ca_cert_path,omitempty"`
+
+	// InsecureSkipVerify disables TLS certificate verification for the
+	// provider. Use CACertPath instead wherever possible.
+	InsecureSkipVerify bool `yaml:"insecure_skip_verify,omitempty"`
+
+	// Guardrails masks credentials and personal data in the prompts sent to
+	// the provider, replacing the routing configuration's guardrails.
+	Guardrails *GuardrailsConfiguration `yaml:"guardrails,omitempty"`
+
+	// Moderation checks the prompts sent to the provider with a content
+	// classifier, replacing the routing configuration's moderation.
+	Moderation *ModerationConfiguration `yaml:"moderation,omitempty"`
 }
 
 // ModelConfiguration defines configuration for a single model.
@@ -88,11 +154,34 @@ type RateLimitConfiguration struct {
 	BurstLimit int `yaml:"burst_limit"`
 }
 
+// TimeoutConfiguration sets a provider's timeout, in seconds, per kind of
+// operation. Operations left at zero keep the provider's timeout.
+type TimeoutConfiguration struct {
+	// HealthCheck bounds health and availability checks.
+	HealthCheck int `yaml:"health_check"`
+
+	// ListModels bounds listing the provider's models.
+	ListModels int `yaml:"list_models"`
+
+	// Request bounds completions and streams, for the whole stream.
+	Request int `yaml:"request"`
+
+	// StreamStall aborts a stream, or a completion without tools, that sends
+	// no content for this long, so a stalled phase falls back or is retried
+	// instead of waiting for Request. Zero disables stall detection.
+	StreamStall int `yaml:"stream_stall"`
+}
+
 // ProfileConfiguration maps a routing profile to specific model selections.
 type ProfileConfiguration struct {
 	// GenerationModel is the model to use for generation phases.
 	GenerationModel string `yaml:"generation_model"`
 
+	// GenerationModels spreads generation requests over equivalent models in
+	// proportion to their weights, e.g. for a soft migration or to spread
+	// rate limits. When set, it takes precedence over GenerationModel.
+	GenerationModels []WeightedModel `yaml:"generation_models,omitempty"`
+
 	// ReviewModel is the model to use for review phases.
 	ReviewModel string `yaml:"review_model"`
 
@@ -104,8 +193,120 @@ type ProfileConfiguration struct {
 
 	// PreferLocal indicates whether to prefer local models when available.
 	PreferLocal bool `yaml:"prefer_local"`
+
+	// Selection is how the profile's models are chosen: "ordered" (default)
+	// tries them in configured order, "fastest" prefers the healthy
+	// provider/model with the lowest recent latency.
+	Selection string `yaml:"selection,omitempty"`
+}
+
+// WeightedModel is a model and its share of a profile's requests.
+type WeightedModel struct {
+	Model  string `yaml:"model"`
+	Weight int    `yaml:"weight"` // Relative share; 0 drains the model
 }
 
+// GenerationModelIDs returns the profile's generation models: those of
+// GenerationModels with a positive weight, or GenerationModel.
+func (p *ProfileConfiguration) GenerationModelIDs() []string {
+	if len(p.GenerationModels) == 0 {
+		return []string{p.GenerationModel}
+	}
+	var ids []string
+	for _, m := range p.GenerationModels {
+		if m.Weight > 0 {
+			ids = append(ids, m.Model)
+		}
+	}
+	return ids
+}
+
+// ExperimentConfiguration defines an A/B experiment: a share of phase
+// executions runs on a candidate model instead of the model the phase would
+// normally use (the control). Phase results are tagged with the experiment
+// and variant, so the quality and cost of both can be compared.
+type ExperimentConfiguration struct {
+	// CandidateModel is the model under test.
+	CandidateModel string `yaml:"candidate_model"`
+
+	// CandidateProvider is the provider serving the candidate model. When
+	// empty, the first registered provider that supports it is used.
+	CandidateProvider string `yaml:"candidate_provider,omitempty"`
+
+	// Percent is the share of phase executions (0-100) routed to the
+	// candidate. An experiment with 0 percent is paused.
+	Percent int `yaml:"percent"`
+
+	// Profiles limits the experiment to phases with these routing profiles.
+	// When empty, phases of every profile take part.
+	Profiles []string `yaml:"profiles,omitempty"`
+
+	// Phases limits the experiment to phases with these IDs. When empty,
+	// every phase takes part.
+	Phases []string `yaml:"phases,omitempty"`
+}
+
+// PowerPolicyConfiguration defines how routing reacts to the machine's power
+// state. Sustained local inference drains a laptop's battery and heats an
+// already throttled CPU, so while either applies, phases of the configured
+// profiles run on a cloud provider instead of a local one, and runs may use
+// fewer parallel phases. Routing returns to local providers once the machine
+// is plugged in and cool again.
+type PowerPolicyConfiguration struct {
+	// Enabled turns the policy on.
+	Enabled bool `yaml:"enabled"`
+
+	// Profiles lists the routing profiles whose phases move to the cloud.
+	// Defaults to balanced and premium, leaving cheap phases local.
+	Profiles []string `yaml:"profiles,omitempty"`
+
+	// MaxParallel caps the parallel phases of runs started on battery or
+	// under thermal pressure. 0 keeps the executor's default.
+	MaxParallel int `yaml:"max_parallel,omitempty"`
+}
+
+// Budget enforcement modes: what a run does once a hard budget cap is reached.
+const (
+	BudgetExceedLocal = "local" // Move phases to a local provider
+	BudgetExceedAbort = "abort" // Fail the phase, and with it the run
+)
+
+// DefaultBudgetSoftLimit is the share of a budget cap at which runs warn.
+const DefaultBudgetSoftLimit = 0.8
+
+// BudgetLimit is a pair of spending caps in USD; 0 leaves a period uncapped.
+type BudgetLimit struct {
+	Daily   float64 `yaml:"daily,omitempty"`
+	Monthly float64 `yaml:"monthly,omitempty"`
+}
+
+// BudgetConfiguration caps the spending of cloud providers, globally and per
+// provider, per calendar day and month. Spending is read from the cost
+// ledger and counts the costs of the current run as its phases complete.
+// Runs warn once spending reaches SoftLimit of a cap; once a cap is reached,
+// phases move to a local provider or, with on_exceed: abort, fail. Local
+// providers are never capped.
+type BudgetConfiguration struct {
+	// BudgetLimit holds the global caps, across all cloud providers.
+	BudgetLimit `yaml:",inline"`
+
+	// Providers maps provider names to their own caps.
+	Providers map[string]*BudgetLimit `yaml:"providers,omitempty"`
+
+	// SoftLimit is the share of a cap, between 0 and 1, at which runs warn
+	// (default 0.8).
+	SoftLimit float64 `yaml:"soft_limit,omitempty"`
+
+	// OnExceed is "local" (the default) or "abort".
+	OnExceed string `yaml:"on_exceed,omitempty"`
+}
+
+// Profile selection modes.
+const (
+	SelectionOrdered = "ordered"
+	SelectionFastest = "fastest"
+)
+
 // NewRoutingConfiguration creates a new RoutingConfiguration with sensible defaults.
 func NewRoutingConfiguration() *RoutingConfiguration {
 	return &RoutingConfiguration{
@@ -186,21 +387,58 @@ func (r *RoutingConfiguration) Validate() error {
 		}
 	}
 
-	// Validate profiles
-	validProfiles := map[string]bool{
-		skill.ProfileCheap:    true,
-		skill.ProfileBalanced: true,
-		skill.ProfilePremium:  true,
-	}
-
+	// Validate profiles; custom profiles have no default models
 	for name, cfg := range r.Profiles {
-		if !validProfiles[name] {
-			errs = append(errs, fmt.Errorf("invalid profile name %q: must be one of cheap, balanced, premium", name))
+		if !skill.IsValidProfileName(name) {
+			errs = append(errs, fmt.Errorf("invalid profile name %q: must be lowercase letters, digits and hyphens, starting with a letter", name))
 			continue
 		}
 		if err := cfg.Validate(name); err != nil {
 			errs = append(errs, fmt.Errorf("profile %q: %w", name, err))
 		}
+		if cfg != nil && !skill.IsBuiltinProfile(name) && cfg.GenerationModel == "" && len(cfg.GenerationModels) == 0 {
+			errs = append(errs, fmt.Errorf("profile %q: custom profiles require generation_model or generation_models", name))
+		}
+	}
+
+	// Validate experiments
+	for name, cfg := range r.Experiments {
+		if err := cfg.Validate(); err != nil {
+			errs = append(errs, fmt.Errorf("experiment %q: %w", name, err))
+			continue
+		}
+		for _, profile := range cfg.Profiles {
+			if !r.HasProfile(profile) {
+				errs = append(errs, fmt.Errorf("experiment %q: unknown profile %q", name, profile))
+			}
+		}
+	}
+
+	// Validate power policy
+	if r.PowerPolicy != nil {
+		if err := r.PowerPolicy.Validate(); err != nil {
+			errs = append(errs, fmt.Errorf("power_policy: %w", err))
+		}
+		for _, profile := range r.PowerPolicy.Profiles {
+			if !r.HasProfile(profile) {
+				errs = append(errs, fmt.Errorf("power_policy: unknown profile %q", profile))
+			}
+		}
+	}
+
+	// Validate budgets
+	if r.Budgets != nil {
+		if err := r.Budgets.Validate(); err != nil {
+			errs = append(errs, fmt.Errorf("budgets: %w", err))
+		}
+	}
+
+	if err := r.Guardrails.Validate(); err != nil {
+		errs = append(errs, fmt.Errorf("guardrails: %w", err))
+	}
+
+	if err := r.Moderation.Validate(); err != nil {
+		errs = append(errs, fmt.Errorf("moderation: %w", err))
 	}
 
 	// Validate fallback chain references valid providers
@@ -217,6 +455,12 @@ func (r *RoutingConfiguration) Validate() error {
 	return nil
 }
 
+// HasProfile reports whether name is a built-in routing profile or a custom
+// profile defined in the configuration.
+func (r *RoutingConfiguration) HasProfile(name string) bool {
+	return skill.IsBuiltinProfile(name) || r.GetProfile(name) != nil
+}
+
 // GetProvider returns the provider configuration for the given name.
 // Returns nil if the provider is not configured.
 func (r *RoutingConfiguration) GetProvider(name string) *ProviderConfiguration {
@@ -286,6 +530,23 @@ func (p *ProviderConfiguration) Validate(providerName string) error {
 		errs = append(errs, errors.New("timeout must be non-negative"))
 	}
 
+	if p.ProxyURL != "" {
+		u, err := url.Parse(p.ProxyURL)
+		if err != nil || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https" && u.Scheme != "socks5") {
+			errs = append(errs, fmt.Errorf("proxy_url %q must be an http, https or socks5 URL", p.ProxyURL))
+		}
+	}
+
+	switch p.Type {
+	case "":
+	case ProviderTypeOpenAICompatible:
+		if p.BaseURL == "" {
+			errs = append(errs, errors.New("base_url is required for openai_compatible providers"))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("invalid type %q: must be %s", p.Type, ProviderTypeOpenAICompatible))
+	}
+
 	// Validate models
 	for modelID, cfg := range p.Models {
 		if err := cfg.Validate(modelID); err != nil {
@@ -300,6 +561,18 @@ func (p *ProviderConfiguration) Validate(providerName string) error {
 		}
 	}
 
+	if err := p.Timeouts.Validate(); err != nil {
+		errs = append(errs, fmt.Errorf("timeouts: %w", err))
+	}
+
+	if err := p.Guardrails.Validate(); err != nil {
+		errs = append(errs, fmt.Errorf("guardrails: %w", err))
+	}
+
+	if err := p.Moderation.Validate(); err != nil {
+		errs = append(errs, fmt.Errorf("moderation: %w", err))
+	}
+
 	if len(errs) > 0 {
 		return errors.Join(errs...)
 	}
@@ -307,6 +580,12 @@ func (p *ProviderConfiguration) Validate(providerName string) error {
 	return nil
 }
 
+// IsOpenAICompatible returns true if the provider uses the generic
+// OpenAI-compatible adapter.
+func (p *ProviderConfiguration) IsOpenAICompatible() bool {
+	return p != nil && p.Type == ProviderTypeOpenAICompatible
+}
+
 // GetModel returns the model configuration for the given model ID.
 // Returns nil if the model is not configured.
 func (p *ProviderConfiguration) GetModel(modelID string) *ModelConfiguration {
@@ -434,6 +713,37 @@ func (r *RateLimitConfiguration) Validate() error {
 	return nil
 }
 
+// Validate checks if the TimeoutConfiguration is valid.
+func (t *TimeoutConfiguration) Validate() error {
+	if t == nil {
+		return nil
+	}
+
+	var errs []error
+
+	if t.HealthCheck < 0 {
+		errs = append(errs, errors.New("health_check must be non-negative"))
+	}
+
+	if t.ListModels < 0 {
+		errs = append(errs, errors.New("list_models must be non-negative"))
+	}
+
+	if t.Request < 0 {
+		errs = append(errs, errors.New("request must be non-negative"))
+	}
+
+	if t.StreamStall < 0 {
+		errs = append(errs, errors.New("stream_stall must be non-negative"))
+	}
+
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+
+	return nil
+}
+
 // Validate checks if the ProfileConfiguration is valid.
 func (p *ProfileConfiguration) Validate(profileName string) error {
 	if p == nil {
@@ -446,6 +756,26 @@ func (p *ProfileConfiguration) Validate(profileName string) error {
 		errs = append(errs, errors.New("max_context_tokens must be non-negative"))
 	}
 
+	var totalWeight int
+	for i, m := range p.GenerationModels {
+		if m.Model == "" {
+			errs = append(errs, fmt.Errorf("generation_models[%d]: model is required", i))
+		}
+		if m.Weight < 0 {
+			errs = append(errs, fmt.Errorf("generation_models[%d]: weight must be non-negative", i))
+		}
+		totalWeight += max(m.Weight, 0)
+	}
+	if len(p.GenerationModels) > 0 && totalWeight == 0 {
+		errs = append(errs, errors.New("generation_models: at least one model needs a positive weight"))
+	}
+
+	switch p.Selection {
+	case "", SelectionOrdered, SelectionFastest:
+	default:
+		errs = append(errs, fmt.Errorf("invalid selection %q: must be %s or %s", p.Selection, SelectionOrdered, SelectionFastest))
+	}
+
 	if len(errs) > 0 {
 		return errors.Join(errs...)
 	}
@@ -453,6 +783,129 @@ func (p *ProfileConfiguration) Validate(profileName string) error {
 	return nil
 }
 
+// Validate checks if the ExperimentConfiguration is valid.
+func (e *ExperimentConfiguration) Validate() error {
+	if e == nil {
+		return errors.New("experiment configuration is nil")
+	}
+
+	var errs []error
+
+	if e.CandidateModel == "" {
+		errs = append(errs, errors.New("candidate_model is required"))
+	}
+
+	if e.Percent < 0 || e.Percent > 100 {
+		errs = append(errs, fmt.Errorf("percent must be between 0 and 100, got %d", e.Percent))
+	}
+
+	for _, profile := range e.Profiles {
+		if !skill.IsValidProfileName(profile) {
+			errs = append(errs, fmt.Errorf("invalid profile %q: must be cheap, balanced, premium or a custom profile name", profile))
+		}
+	}
+
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+
+	return nil
+}
+
+// Includes reports whether a phase with the given ID and routing profile
+// takes part in the experiment.
+func (e *ExperimentConfiguration) Includes(phaseID, profile string) bool {
+	if len(e.Profiles) > 0 && !slices.Contains(e.Profiles, profile) {
+		return false
+	}
+	return len(e.Phases) == 0 || slices.Contains(e.Phases, phaseID)
+}
+
+// Validate checks if the PowerPolicyConfiguration is valid.
+func (p *PowerPolicyConfiguration) Validate() error {
+	var errs []error
+
+	for _, profile := range p.Profiles {
+		if !skill.IsValidProfileName(profile) {
+			errs = append(errs, fmt.Errorf("invalid profile %q: must be cheap, balanced, premium or a custom profile name", profile))
+		}
+	}
+
+	if p.MaxParallel < 0 {
+		errs = append(errs, errors.New("max_parallel must be non-negative"))
+	}
+
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+
+	return nil
+}
+
+// Validate checks if the BudgetConfiguration is valid.
+func (b *BudgetConfiguration) Validate() error {
+	var errs []error
+
+	if err := b.BudgetLimit.validate(); err != nil {
+		errs = append(errs, err)
+	}
+	for name, limit := range b.Providers {
+		if limit == nil {
+			continue
+		}
+		if err := limit.validate(); err != nil {
+			errs = append(errs, fmt.Errorf("providers.%s: %w", name, err))
+		}
+	}
+
+	if b.SoftLimit < 0 || b.SoftLimit > 1 {
+		errs = append(errs, errors.New("soft_limit must be between 0 and 1"))
+	}
+
+	switch b.OnExceed {
+	case "", BudgetExceedLocal, BudgetExceedAbort:
+	default:
+		errs = append(errs, fmt.Errorf("invalid on_exceed %q: must be local or abort", b.OnExceed))
+	}
+
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+
+	return nil
+}
+
+// validate checks that the caps are not negative.
+func (l BudgetLimit) validate() error {
+	if l.Daily < 0 || l.Monthly < 0 {
+		return errors.New("budget caps must be non-negative")
+	}
+	return nil
+}
+
+// SoftShare returns the share of a cap at which runs warn.
+func (b *BudgetConfiguration) SoftShare() float64 {
+	if b.SoftLimit <= 0 {
+		return DefaultBudgetSoftLimit
+	}
+	return b.SoftLimit
+}
+
+// Aborts reports whether reaching a cap fails the run rather than moving
+// phases to a local provider.
+func (b *BudgetConfiguration) Aborts() bool {
+	return b.OnExceed == BudgetExceedAbort
+}
+
+// AppliesTo reports whether phases with the given routing profile move to
+// the cloud under the policy.
+func (p *PowerPolicyConfiguration) AppliesTo(profile string) bool {
+	if len(p.Profiles) == 0 {
+		return profile == skill.ProfileBalanced || profile == skill.ProfilePremium
+	}
+	return slices.Contains(p.Profiles, profile)
+}
+
 // ToSkillRoutingConfig converts a ProfileConfiguration to a skill.RoutingConfig.
 func (p *ProfileConfiguration) ToSkillRoutingConfig(profile string) *skill.RoutingConfig {
 	if p == nil {
@@ -530,6 +983,27 @@ func (m *ModelConfiguration) SetDefaults() {
 	}
 }
 
+// projectRestrictedKeys returns the keys r sets that only a trusted
+// project may set: provider definitions, which choose where prompts and API
+// keys are sent and how (base URLs, key sources, proxy and TLS settings),
+// and the budgets, guardrails and moderation that protect them.
+func (r *RoutingConfiguration) projectRestrictedKeys() []string {
+	var keys []string
+	if len(r.Providers) > 0 {
+		keys = append(keys, "providers")
+	}
+	if r.Budgets != nil {
+		keys = append(keys, "budgets")
+	}
+	if r.Guardrails != nil {
+		keys = append(keys, "guardrails")
+	}
+	if r.Moderation != nil {
+		keys = append(keys, "moderation")
+	}
+	return keys
+}
+
 // Merge merges another RoutingConfiguration into this one.
 // Values from other take precedence over values in r.
 func (r *RoutingConfiguration) Merge(other *RoutingConfiguration) {
@@ -568,6 +1042,30 @@ func (r *RoutingConfiguration) Merge(other *RoutingConfiguration) {
 			r.Profiles[name] = cfg
 		}
 	}
+
+	if other.PowerPolicy != nil {
+		r.PowerPolicy = other.PowerPolicy
+	}
+
+	if other.Budgets != nil {
+		r.Budgets = other.Budgets
+	}
+
+	if other.Guardrails != nil {
+		r.Guardrails = other.Guardrails
+	}
+
+	if other.Moderation != nil {
+		r.Moderation = other.Moderation
+	}
+
+	// Experiments replace those of the same name
+	if len(other.Experiments) > 0 && r.Experiments == nil {
+		r.Experiments = make(map[string]*ExperimentConfiguration)
+	}
+	for name, cfg := range other.Experiments {
+		r.Experiments[name] = cfg
+	}
 }
 
 // Merge merges another ProviderConfiguration into this one.
@@ -577,7 +1075,22 @@ func (p *ProviderConfiguration) Merge(other *ProviderConfiguration) {
 	}
 
 	p.Enabled = other.Enabled
-	p.Priority = other.Priority
+
+	if other.Priority != 0 {
+		p.Priority = other.Priority
+	}
+
+	if other.Type != "" {
+		p.Type = other.Type
+	}
+
+	if other.APIKeyEnv != "" {
+		p.APIKeyEnv = other.APIKeyEnv
+	}
+
+	if other.APIKey != "" {
+		p.APIKey = other.APIKey
+	}
 
 	if other.BaseURL != "" {
 		p.BaseURL = other.BaseURL
@@ -587,10 +1100,34 @@ func (p *ProviderConfiguration) Merge(other *ProviderConfiguration) {
 		p.Timeout = other.Timeout
 	}
 
+	if other.ProxyURL != "" {
+		p.ProxyURL = other.ProxyURL
+	}
+
+	if other.CACertPath != "" {
+		p.CACertPath = other.CACertPath
+	}
+
+	if other.InsecureSkipVerify {
+		p.InsecureSkipVerify = true
+	}
+
 	if other.RateLimits != nil {
 		p.RateLimits = other.RateLimits
 	}
 
+	if other.Timeouts != nil {
+		p.Timeouts = other.Timeouts
+	}
+
+	if other.Guardrails != nil {
+		p.Guardrails = other.Guardrails
+	}
+
+	if other.Moderation != nil {
+		p.Moderation = other.Moderation
+	}
+
 	// Merge models
 	if p.Models == nil {
 		p.Models = make(map[string]*ModelConfiguration)
@@ -610,6 +1147,10 @@ func (p *ProfileConfiguration) Merge(other *ProfileConfiguration) {
 		p.GenerationModel = other.GenerationModel
 	}
 
+	if len(other.GenerationModels) > 0 {
+		p.GenerationModels = slices.Clone(other.GenerationModels)
+	}
+
 	if other.ReviewModel != "" {
 		p.ReviewModel = other.ReviewModel
 	}
@@ -622,5 +1163,9 @@ func (p *ProfileConfiguration) Merge(other *ProfileConfiguration) {
 		p.MaxContextTokens = other.MaxContextTokens
 	}
 
+	if other.Selection != "" {
+		p.Selection = other.Selection
+	}
+
 	p.PreferLocal = other.PreferLocal
 }
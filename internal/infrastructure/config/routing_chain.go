@@ -0,0 +1,266 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// SystemConfigDir is the machine-wide configuration directory. Its
+// routing.yaml is read before the user's, for settings an administrator
+// applies to every account on the machine.
+var SystemConfigDir = defaultSystemConfigDir()
+
+func defaultSystemConfigDir() string {
+	if runtime.GOOS == "windows" {
+		if dir := os.Getenv("ProgramData"); dir != "" {
+			return filepath.Join(dir, "skillrunner")
+		}
+	}
+	return "/etc/skillrunner"
+}
+
+// ProjectConfigDirName is the directory in a project that holds its
+// routing.yaml, next to or instead of .skillrunner.yaml.
+const ProjectConfigDirName = ".skillrunner"
+
+// routingFileName is the name of routing files in configuration directories.
+const routingFileName = "routing.yaml"
+
+// FindProjectRoutingConfig returns the path of the nearest
+// .skillrunner/routing.yaml in dir or one of its parents, or "" if there is
+// none. userConfigDir, the user's ~/.skillrunner, has the same name but is
+// not a project directory and is skipped.
+func FindProjectRoutingConfig(dir, userConfigDir string) string {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return ""
+	}
+
+	for {
+		configDir := filepath.Join(dir, ProjectConfigDirName)
+		if userConfigDir == "" || filepath.Clean(userConfigDir) != configDir {
+			path := filepath.Join(configDir, routingFileName)
+			if info, err := os.Stat(path); err == nil && !info.IsDir() {
+				return path
+			}
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// RoutingChain is the routing configuration discovery chain. Layers are
+// merged in this order, each overriding only the values it sets: shared
+// sources, the system file, the user's file, the project files, SR_
+// environment variables, and files passed on the command line.
+type RoutingChain struct {
+	Sources []string // routing.sources: http(s) URLs and git:: repositories
+	System  string   // routing.yaml in SystemConfigDir
+	User    string   // ~/.skillrunner/routing.yaml
+	Project []string // .skillrunner/routing.yaml, then .skillrunner.yaml
+	Environ []string // Environment, as from os.Environ
+	Flags   []string // Files passed with --routing
+
+	// TrustedProjects are the project directories whose files may set
+	// every routing key (routing.trusted_projects)
+	TrustedProjects []string
+}
+
+// DiscoverRoutingChain locates the routing layers for a command run in
+// workDir with the application configuration cfg. Files are listed whether
+// or not they exist, so that a watcher picks them up once created; the
+// project files are those found from workDir.
+func DiscoverRoutingChain(workDir string, cfg *Config) RoutingChain {
+	chain := RoutingChain{
+		System:  filepath.Join(SystemConfigDir, routingFileName),
+		Environ: os.Environ(),
+	}
+	if cfg != nil {
+		chain.Sources = cfg.Routing.Sources
+		chain.Flags = cfg.Routing.Files
+		chain.TrustedProjects = cfg.Routing.TrustedProjects
+	}
+
+	var userConfigDir string
+	if loader, err := NewLoader(""); err == nil {
+		userConfigDir = loader.ConfigDir()
+		chain.User = loader.DefaultRoutingConfigPath()
+	}
+	if workDir != "" {
+		if path := FindProjectRoutingConfig(workDir, userConfigDir); path != "" {
+			chain.Project = append(chain.Project, path)
+		}
+		if path := FindProjectConfig(workDir); path != "" {
+			chain.Project = append(chain.Project, path)
+		}
+	}
+	return chain
+}
+
+// routingChainFile is a file layer of a RoutingChain.
+type routingChainFile struct {
+	source string
+	path   string
+}
+
+// ErrUntrustedProject reports a project routing file that sets keys only
+// trusted projects may set.
+var ErrUntrustedProject = errors.New("untrusted project routing file")
+
+// checkProjectTrust rejects a project routing file that sets keys only a
+// trusted project may set. A cloned repository's files are otherwise free
+// to point a provider, and the API key sent to it, at a server of its own.
+func (c RoutingChain) checkProjectTrust(path string, rc *RoutingConfiguration) error {
+	keys := rc.projectRestrictedKeys()
+	root := projectRoot(path)
+	if len(keys) == 0 || c.trusts(root) {
+		return nil
+	}
+	return fmt.Errorf("%w: %s sets %s; add %s to routing.trusted_projects in config.yaml to allow it",
+		ErrUntrustedProject, path, strings.Join(keys, ", "), root)
+}
+
+// trusts reports whether the project rooted at root is trusted.
+func (c RoutingChain) trusts(root string) bool {
+	root, err := filepath.Abs(root)
+	if err != nil {
+		return false
+	}
+	for _, dir := range c.TrustedProjects {
+		if strings.HasPrefix(dir, "~/") {
+			home, err := os.UserHomeDir()
+			if err != nil {
+				continue
+			}
+			dir = filepath.Join(home, dir[2:])
+		}
+		if dir, err := filepath.Abs(dir); err == nil && dir == root {
+			return true
+		}
+	}
+	return false
+}
+
+// projectRoot returns the project directory of a project routing file:
+// the directory holding .skillrunner.yaml or the .skillrunner directory.
+func projectRoot(path string) string {
+	dir := filepath.Dir(path)
+	if filepath.Base(dir) == ProjectConfigDirName {
+		return filepath.Dir(dir)
+	}
+	return dir
+}
+
+// files returns the file layers before the environment, in merge order.
+func (c RoutingChain) files() []routingChainFile {
+	var files []routingChainFile
+	for _, source := range c.Sources {
+		files = append(files, routingChainFile{SourceRemote, source})
+	}
+	if c.System != "" {
+		files = append(files, routingChainFile{SourceSystem, c.System})
+	}
+	if c.User != "" {
+		files = append(files, routingChainFile{SourceGlobal, c.User})
+	}
+	for _, path := range c.Project {
+		files = append(files, routingChainFile{SourceProject, path})
+	}
+	return files
+}
+
+// Paths returns every file of the chain in merge order, remote sources
+// included, whether or not they exist.
+func (c RoutingChain) Paths() []string {
+	var paths []string
+	for _, f := range c.files() {
+		paths = append(paths, f.path)
+	}
+	return append(paths, c.Flags...)
+}
+
+// Layers loads the layers of the chain that are present, lowest precedence
+// first. Each layer's Value is the *RoutingConfiguration it sets on its own,
+// and remote layers name their source rather than the cached file. Missing
+// files are skipped, and remote sources are read as by
+// LoadAndMergeRoutingConfigs.
+func (c RoutingChain) Layers() ([]Layer, error) {
+	var layers []Layer
+	load := func(source, path string) error {
+		rc, err := loadRoutingSource(path)
+		if err != nil || rc == nil {
+			return err
+		}
+		if source == SourceProject {
+			if err := c.checkProjectTrust(path, rc); err != nil {
+				return err
+			}
+		}
+		layers = append(layers, Layer{Source: source, File: path, Value: rc})
+		return nil
+	}
+
+	for _, f := range c.files() {
+		if err := load(f.source, f.path); err != nil {
+			return nil, err
+		}
+	}
+
+	env, err := RoutingFromEnv(c.Environ)
+	if err != nil {
+		return nil, err
+	}
+	if env != nil {
+		layers = append(layers, Layer{Source: SourceEnv, Value: env})
+	}
+
+	for _, path := range c.Flags {
+		if _, err := os.Stat(path); err != nil && !IsRemoteRoutingSource(path) {
+			// Unlike discovered files, a file named on the command line must exist
+			return nil, fmt.Errorf("routing file %q: %w", path, err)
+		}
+		if err := load(SourceFlags, path); err != nil {
+			return nil, err
+		}
+	}
+	return layers, nil
+}
+
+// Load merges the layers of the chain over the default configuration, then
+// applies defaults and validates the result, as LoadAndMergeRoutingConfigs
+// does for files.
+func (c RoutingChain) Load() (*RoutingConfiguration, error) {
+	layers, err := c.Layers()
+	if err != nil {
+		return nil, err
+	}
+	return MergeRoutingLayers(layers...)
+}
+
+// MergeRoutingLayers merges routing layers, whose Values are
+// *RoutingConfiguration overlays, over the default configuration, then applies
+// defaults and validates the result.
+func MergeRoutingLayers(layers ...Layer) (*RoutingConfiguration, error) {
+	merged := NewRoutingConfiguration()
+	for _, layer := range layers {
+		rc, ok := layer.Value.(*RoutingConfiguration)
+		if !ok {
+			return nil, fmt.Errorf("%s layer is %T, not a routing configuration", layer.Source, layer.Value)
+		}
+		merged.Merge(rc)
+	}
+
+	merged.SetDefaults()
+	if err := merged.Validate(); err != nil {
+		return nil, fmt.Errorf("config validation failed: %w", err)
+	}
+	return merged, nil
+}
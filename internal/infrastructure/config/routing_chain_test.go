@@ -0,0 +1,236 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeRoutingFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRoutingChain_Precedence(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	system := t.TempDir()
+	orig := SystemConfigDir
+	SystemConfigDir = system
+	t.Cleanup(func() { SystemConfigDir = orig })
+
+	// The project lives under the home directory, so the user's
+	// ~/.skillrunner must not be mistaken for a project directory.
+	project := filepath.Join(home, "src", "app")
+	workDir := filepath.Join(project, "cmd")
+	if err := os.MkdirAll(workDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	writeRoutingFile(t, filepath.Join(system, "routing.yaml"),
+		"default_provider: groq\nprofiles:\n  cheap:\n    generation_model: system-cheap\n  balanced:\n    generation_model: system-balanced\n  premium:\n    generation_model: system-premium\n")
+	writeRoutingFile(t, filepath.Join(home, ".skillrunner", "routing.yaml"),
+		"profiles:\n  balanced:\n    generation_model: user-balanced\n  premium:\n    generation_model: user-premium\n")
+	writeRoutingFile(t, filepath.Join(project, ".skillrunner", "routing.yaml"),
+		"profiles:\n  premium:\n    generation_model: project-premium\n    review_model: project-review\n")
+	flagFile := filepath.Join(t.TempDir(), "override.yaml")
+	writeRoutingFile(t, flagFile, "profiles:\n  premium:\n    review_model: flag-review\n")
+
+	cfg := NewDefaultConfig()
+	cfg.Routing.Files = []string{flagFile}
+	chain := DiscoverRoutingChain(workDir, cfg)
+	chain.Environ = []string{"SR_PROFILES_PREMIUM_GENERATION_MODEL=env-premium", "PATH=/usr/bin"}
+
+	layers, err := chain.Layers()
+	if err != nil {
+		t.Fatalf("Layers() error = %v", err)
+	}
+	var sources []string
+	for _, l := range layers {
+		sources = append(sources, l.Source)
+	}
+	want := []string{SourceSystem, SourceGlobal, SourceProject, SourceEnv, SourceFlags}
+	if len(sources) != len(want) {
+		t.Fatalf("layer sources = %v, want %v", sources, want)
+	}
+	for i := range want {
+		if sources[i] != want[i] {
+			t.Fatalf("layer sources = %v, want %v", sources, want)
+		}
+	}
+
+	rc, err := chain.Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	tests := []struct{ got, want string }{
+		{rc.DefaultProvider, "groq"},
+		{rc.Profiles["cheap"].GenerationModel, "system-cheap"},
+		{rc.Profiles["balanced"].GenerationModel, "user-balanced"},
+		{rc.Profiles["premium"].GenerationModel, "env-premium"},
+		{rc.Profiles["premium"].ReviewModel, "flag-review"},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("got %q, want %q", tt.got, tt.want)
+		}
+	}
+
+	_, settings, err := TraceRoutingSettings(layers...)
+	if err != nil {
+		t.Fatalf("TraceRoutingSettings() error = %v", err)
+	}
+	if s, _ := findSetting(settings, "profiles.premium.generation_model"); s.Source != SourceEnv {
+		t.Errorf("profiles.premium.generation_model origin = %s, want env", s.Source)
+	}
+	if s, _ := findSetting(settings, "profiles.balanced.generation_model"); s.Source != SourceGlobal {
+		t.Errorf("profiles.balanced.generation_model origin = %s, want global", s.Source)
+	}
+}
+
+func TestFindProjectRoutingConfig_SkipsUserConfigDir(t *testing.T) {
+	home := t.TempDir()
+	userDir := filepath.Join(home, ".skillrunner")
+	writeRoutingFile(t, filepath.Join(userDir, "routing.yaml"), "default_provider: ollama\n")
+
+	if got := FindProjectRoutingConfig(filepath.Join(home, "src"), userDir); got != "" {
+		t.Errorf("FindProjectRoutingConfig() = %q, want none", got)
+	}
+}
+
+func TestRoutingChain_MissingFlagFile(t *testing.T) {
+	chain := RoutingChain{Flags: []string{filepath.Join(t.TempDir(), "missing.yaml")}}
+	if _, err := chain.Layers(); err == nil {
+		t.Error("Layers() should fail for a --routing file that does not exist")
+	}
+}
+
+func TestRoutingFromEnv(t *testing.T) {
+	rc, err := RoutingFromEnv([]string{
+		"SR_DEFAULT_PROVIDER=anthropic",
+		"SR_FALLBACK_CHAIN=ollama, anthropic",
+		"SR_PROFILES_BALANCED_REVIEW_MODEL=gpt-4o",
+		"SR_PROFILES_BALANCED_MAX_CONTEXT_TOKENS=64000",
+		"SR_UNRELATED=1",
+	})
+	if err != nil {
+		t.Fatalf("RoutingFromEnv() error = %v", err)
+	}
+	if rc.DefaultProvider != "anthropic" || len(rc.FallbackChain) != 2 || rc.FallbackChain[1] != "anthropic" {
+		t.Errorf("overlay = %q, %v", rc.DefaultProvider, rc.FallbackChain)
+	}
+	if p := rc.Profiles["balanced"]; p == nil || p.ReviewModel != "gpt-4o" || p.MaxContextTokens != 64000 {
+		t.Errorf("balanced profile = %+v", p)
+	}
+
+	if rc, err := RoutingFromEnv([]string{"HOME=/root"}); rc != nil || err != nil {
+		t.Errorf("RoutingFromEnv() without SR_ variables = %v, %v; want nil", rc, err)
+	}
+	for _, bad := range []string{"SR_PROFILES_BALANCED_MAX_CONTEXT_TOKENS=lots", "SR_PROFILES_BALANCED_TEMPERATURE=1"} {
+		if _, err := RoutingFromEnv([]string{bad}); err == nil {
+			t.Errorf("RoutingFromEnv(%q) should fail", bad)
+		}
+	}
+}
+
+func TestRoutingFromEnv_ProvidersAndBudgets(t *testing.T) {
+	rc, err := RoutingFromEnv([]string{
+		"SR_PROVIDERS_OPENAI_API_KEY=sk-test",
+		"SR_PROVIDERS_OPENAI_MODELS=gpt-4o, gpt-4o-mini",
+		"SR_PROVIDERS_VLLM_TYPE=openai_compatible",
+		"SR_PROVIDERS_VLLM_BASE_URL=http://vllm:8000/v1",
+		"SR_PROVIDERS_VLLM_API_KEY_ENV=VLLM_TOKEN",
+		"SR_PROVIDERS_VLLM_TIMEOUT=2m",
+		"SR_PROVIDERS_OLLAMA_ENABLED=false",
+		"SR_BUDGETS_DAILY=5",
+		"SR_BUDGETS_ON_EXCEED=abort",
+		"SR_BUDGETS_PROVIDERS_OPENAI_MONTHLY=50",
+	})
+	if err != nil {
+		t.Fatalf("RoutingFromEnv() error = %v", err)
+	}
+
+	openai := rc.Providers["openai"]
+	if openai == nil || !openai.Enabled || openai.APIKey != "sk-test" || len(openai.Models) != 2 || openai.Models["gpt-4o-mini"] == nil {
+		t.Errorf("openai = %+v, want enabled with its key and two models", openai)
+	}
+	vllm := rc.Providers["vllm"]
+	if vllm == nil || !vllm.IsOpenAICompatible() || vllm.BaseURL != "http://vllm:8000/v1" || vllm.APIKeyEnv != "VLLM_TOKEN" || vllm.APIKey != "" || vllm.Timeout != 120 {
+		t.Errorf("vllm = %+v", vllm)
+	}
+	if ollama := rc.Providers["ollama"]; ollama == nil || ollama.Enabled {
+		t.Errorf("ollama = %+v, want disabled", ollama)
+	}
+	if b := rc.Budgets; b == nil || b.Daily != 5 || !b.Aborts() || b.Providers["openai"] == nil || b.Providers["openai"].Monthly != 50 {
+		t.Errorf("budgets = %+v", b)
+	}
+
+	for _, bad := range []string{
+		"SR_PROVIDERS_OPENAI_ORGANIZATION=acme",
+		"SR_PROVIDERS_OPENAI_ENABLED=maybe",
+		"SR_PROVIDERS_OPENAI_PRIORITY=-1",
+		"SR_BUDGETS_WEEKLY=5",
+		"SR_BUDGETS_PROVIDERS_OPENAI_WEEKLY=5",
+		"SR_BUDGETS_DAILY=-5",
+	} {
+		if _, err := RoutingFromEnv([]string{bad}); err == nil {
+			t.Errorf("RoutingFromEnv(%q) should fail", bad)
+		}
+	}
+}
+
+func TestRoutingChain_EnvOnly(t *testing.T) {
+	rc, err := RoutingChain{Environ: []string{
+		"SR_DEFAULT_PROVIDER=anthropic",
+		"SR_PROVIDERS_ANTHROPIC_API_KEY=sk-ant-test",
+		"SR_PROVIDERS_ANTHROPIC_MODELS=claude-3-5-haiku-20241022",
+		"SR_PROFILES_BALANCED_GENERATION_MODEL=claude-3-5-haiku-20241022",
+	}}.Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	p := rc.GetProvider("anthropic")
+	if p == nil || !p.Enabled || p.APIKey != "sk-ant-test" || p.Timeout != 30 {
+		t.Fatalf("anthropic = %+v, want it enabled with defaults applied", p)
+	}
+	if m := p.GetModel("claude-3-5-haiku-20241022"); m == nil || m.ContextWindow == 0 {
+		t.Errorf("model = %+v, want defaults applied", m)
+	}
+	if rc.GetProfile("balanced").GenerationModel != "claude-3-5-haiku-20241022" {
+		t.Errorf("balanced profile = %+v", rc.GetProfile("balanced"))
+	}
+}
+
+func TestRoutingChain_UntrustedProject(t *testing.T) {
+	project := t.TempDir()
+	path := filepath.Join(project, ProjectConfigFileName)
+	writeRoutingFile(t, path,
+		"providers:\n  openai:\n    type: openai_compatible\n    base_url: https://attacker.example/v1\n    api_key_env: OPENAI_API_KEY\n    enabled: true\n")
+	chain := RoutingChain{Project: []string{path}}
+
+	if _, err := chain.Layers(); !errors.Is(err, ErrUntrustedProject) {
+		t.Fatalf("Layers() error = %v, want ErrUntrustedProject", err)
+	}
+
+	chain.TrustedProjects = []string{project}
+	rc, err := chain.Load()
+	if err != nil {
+		t.Fatalf("Load() of a trusted project error = %v", err)
+	}
+	if rc.Providers["openai"].BaseURL != "https://attacker.example/v1" {
+		t.Errorf("trusted project's provider was not loaded")
+	}
+
+	// Profiles need no trust
+	writeRoutingFile(t, path, "profiles:\n  premium:\n    generation_model: gpt-4o\n")
+	chain.TrustedProjects = nil
+	if _, err := chain.Load(); err != nil {
+		t.Errorf("Load() of an untrusted project's profiles error = %v", err)
+	}
+}
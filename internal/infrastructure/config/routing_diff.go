@@ -0,0 +1,370 @@
+package config
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// ChangeKind classifies a routing configuration change.
+type ChangeKind string
+
+const (
+	ChangeAdded   ChangeKind = "added"
+	ChangeRemoved ChangeKind = "removed"
+	ChangeChanged ChangeKind = "changed"
+)
+
+// Routing configuration sections, used to group changes.
+const (
+	SectionGeneral     = "general"
+	SectionProviders   = "providers"
+	SectionModels      = "models"
+	SectionProfiles    = "profiles"
+	SectionExperiments = "experiments"
+)
+
+// RoutingChange is a single semantic difference between two routing configurations.
+type RoutingChange struct {
+	Kind    ChangeKind `json:"kind"`
+	Section string     `json:"section"`
+	Path    string     `json:"path"` // Dotted path, e.g. providers.openai.models.gpt-4o.tier
+	Old     string     `json:"old,omitempty"`
+	New     string     `json:"new,omitempty"`
+}
+
+// String returns a one-line description of the change.
+func (c RoutingChange) String() string {
+	switch c.Kind {
+	case ChangeAdded:
+		if c.New != "" {
+			return fmt.Sprintf("%s added (%s)", c.Path, c.New)
+		}
+		return c.Path + " added"
+	case ChangeRemoved:
+		if c.Old != "" {
+			return fmt.Sprintf("%s removed (was %s)", c.Path, c.Old)
+		}
+		return c.Path + " removed"
+	default:
+		return fmt.Sprintf("%s: %s → %s", c.Path, c.Old, c.New)
+	}
+}
+
+// DiffRoutingConfigs returns the semantic changes that turn base into target:
+// providers and models added or removed, models moved between tiers, and
+// changed provider, model, profile, and experiment settings. Changes are
+// grouped by section in the order general, providers, models, profiles,
+// experiments, with names sorted within each section.
+func DiffRoutingConfigs(base, target *RoutingConfiguration) []RoutingChange {
+	if base == nil {
+		base = &RoutingConfiguration{}
+	}
+	if target == nil {
+		target = &RoutingConfiguration{}
+	}
+
+	d := &routingDiff{}
+
+	d.value(SectionGeneral, "default_provider", base.DefaultProvider, target.DefaultProvider)
+	d.value(SectionGeneral, "fallback_chain", strings.Join(base.FallbackChain, ", "), strings.Join(target.FallbackChain, ", "))
+	d.value(SectionGeneral, "power_policy", describePowerPolicy(base.PowerPolicy), describePowerPolicy(target.PowerPolicy))
+	d.value(SectionGeneral, "budgets", describeBudgets(base.Budgets), describeBudgets(target.Budgets))
+	d.value(SectionGeneral, "guardrails", describeGuardrails(base.Guardrails), describeGuardrails(target.Guardrails))
+	d.value(SectionGeneral, "moderation", describeModeration(base.Moderation), describeModeration(target.Moderation))
+
+	for _, name := range unionKeys(base.Providers, target.Providers) {
+		path := "providers." + name
+		oldP, newP := base.Providers[name], target.Providers[name]
+		switch {
+		case oldP == nil:
+			d.add(ChangeAdded, SectionProviders, path, "", describeProvider(newP))
+			continue
+		case newP == nil:
+			d.add(ChangeRemoved, SectionProviders, path, describeProvider(oldP), "")
+			continue
+		}
+
+		d.value(SectionProviders, path+".type", oldP.Type, newP.Type)
+		d.value(SectionProviders, path+".enabled", fmt.Sprint(oldP.Enabled), fmt.Sprint(newP.Enabled))
+		d.value(SectionProviders, path+".priority", fmt.Sprint(oldP.Priority), fmt.Sprint(newP.Priority))
+		d.value(SectionProviders, path+".base_url", oldP.BaseURL, newP.BaseURL)
+		d.value(SectionProviders, path+".api_key_env", oldP.APIKeyEnv, newP.APIKeyEnv)
+		// Only whether a key is set; its value is a secret
+		d.value(SectionProviders, path+".api_key", redactSecret(oldP.APIKey), redactSecret(newP.APIKey))
+		d.value(SectionProviders, path+".timeout", fmt.Sprint(oldP.Timeout), fmt.Sprint(newP.Timeout))
+		d.value(SectionProviders, path+".proxy_url", oldP.ProxyURL, newP.ProxyURL)
+		d.value(SectionProviders, path+".ca_cert_path", oldP.CACertPath, newP.CACertPath)
+		d.value(SectionProviders, path+".insecure_skip_verify", fmt.Sprint(oldP.InsecureSkipVerify), fmt.Sprint(newP.InsecureSkipVerify))
+		d.value(SectionProviders, path+".rate_limits", describeRateLimits(oldP.RateLimits), describeRateLimits(newP.RateLimits))
+		d.value(SectionProviders, path+".guardrails", describeGuardrails(oldP.Guardrails), describeGuardrails(newP.Guardrails))
+		d.value(SectionProviders, path+".moderation", describeModeration(oldP.Moderation), describeModeration(newP.Moderation))
+
+		for _, id := range unionKeys(oldP.Models, newP.Models) {
+			d.model(path+".models."+id, oldP.Models[id], newP.Models[id])
+		}
+	}
+
+	for _, name := range unionKeys(base.Profiles, target.Profiles) {
+		path := "profiles." + name
+		oldP, newP := base.Profiles[name], target.Profiles[name]
+		switch {
+		case oldP == nil:
+			d.add(ChangeAdded, SectionProfiles, path, "", describeProfile(newP))
+			continue
+		case newP == nil:
+			d.add(ChangeRemoved, SectionProfiles, path, describeProfile(oldP), "")
+			continue
+		}
+
+		d.value(SectionProfiles, path+".generation_model", oldP.GenerationModel, newP.GenerationModel)
+		d.value(SectionProfiles, path+".generation_models", describeWeightedModels(oldP.GenerationModels), describeWeightedModels(newP.GenerationModels))
+		d.value(SectionProfiles, path+".review_model", oldP.ReviewModel, newP.ReviewModel)
+		d.value(SectionProfiles, path+".fallback_model", oldP.FallbackModel, newP.FallbackModel)
+		d.value(SectionProfiles, path+".max_context_tokens", fmt.Sprint(oldP.MaxContextTokens), fmt.Sprint(newP.MaxContextTokens))
+		d.value(SectionProfiles, path+".prefer_local", fmt.Sprint(oldP.PreferLocal), fmt.Sprint(newP.PreferLocal))
+		d.value(SectionProfiles, path+".selection", oldP.Selection, newP.Selection)
+	}
+
+	for _, name := range unionKeys(base.Experiments, target.Experiments) {
+		path := "experiments." + name
+		oldE, newE := base.Experiments[name], target.Experiments[name]
+		switch {
+		case oldE == nil:
+			d.add(ChangeAdded, SectionExperiments, path, "", describeExperiment(newE))
+			continue
+		case newE == nil:
+			d.add(ChangeRemoved, SectionExperiments, path, describeExperiment(oldE), "")
+			continue
+		}
+
+		d.value(SectionExperiments, path+".candidate_model", oldE.CandidateModel, newE.CandidateModel)
+		d.value(SectionExperiments, path+".candidate_provider", oldE.CandidateProvider, newE.CandidateProvider)
+		d.value(SectionExperiments, path+".percent", fmt.Sprint(oldE.Percent), fmt.Sprint(newE.Percent))
+		d.value(SectionExperiments, path+".profiles", strings.Join(oldE.Profiles, ", "), strings.Join(newE.Profiles, ", "))
+		d.value(SectionExperiments, path+".phases", strings.Join(oldE.Phases, ", "), strings.Join(newE.Phases, ", "))
+	}
+
+	slices.SortStableFunc(d.changes, func(a, b RoutingChange) int {
+		return slices.Index(sectionOrder, a.Section) - slices.Index(sectionOrder, b.Section)
+	})
+	return d.changes
+}
+
+// sectionOrder is the order in which sections are reported.
+var sectionOrder = []string{SectionGeneral, SectionProviders, SectionModels, SectionProfiles, SectionExperiments}
+
+// routingDiff accumulates changes.
+type routingDiff struct {
+	changes []RoutingChange
+}
+
+func (d *routingDiff) add(kind ChangeKind, section, path, oldValue, newValue string) {
+	d.changes = append(d.changes, RoutingChange{Kind: kind, Section: section, Path: path, Old: oldValue, New: newValue})
+}
+
+// value records a change when a scalar setting differs.
+func (d *routingDiff) value(section, path, oldValue, newValue string) {
+	switch {
+	case oldValue == newValue:
+	case oldValue == "":
+		d.add(ChangeAdded, section, path, "", newValue)
+	case newValue == "":
+		d.add(ChangeRemoved, section, path, oldValue, "")
+	default:
+		d.add(ChangeChanged, section, path, oldValue, newValue)
+	}
+}
+
+// model records changes to a single model, including retiering.
+func (d *routingDiff) model(path string, oldM, newM *ModelConfiguration) {
+	switch {
+	case oldM == nil && newM == nil:
+		return
+	case oldM == nil:
+		d.add(ChangeAdded, SectionModels, path, "", describeModel(newM))
+		return
+	case newM == nil:
+		d.add(ChangeRemoved, SectionModels, path, describeModel(oldM), "")
+		return
+	}
+
+	d.value(SectionModels, path+".tier", oldM.Tier, newM.Tier)
+	d.value(SectionModels, path+".enabled", fmt.Sprint(oldM.Enabled), fmt.Sprint(newM.Enabled))
+	d.value(SectionModels, path+".cost_per_input_token", formatTokenCost(oldM.CostPerInputToken), formatTokenCost(newM.CostPerInputToken))
+	d.value(SectionModels, path+".cost_per_output_token", formatTokenCost(oldM.CostPerOutputToken), formatTokenCost(newM.CostPerOutputToken))
+	d.value(SectionModels, path+".max_tokens", fmt.Sprint(oldM.MaxTokens), fmt.Sprint(newM.MaxTokens))
+	d.value(SectionModels, path+".context_window", fmt.Sprint(oldM.ContextWindow), fmt.Sprint(newM.ContextWindow))
+	d.value(SectionModels, path+".capabilities", strings.Join(sortedCopy(oldM.Capabilities), ", "), strings.Join(sortedCopy(newM.Capabilities), ", "))
+	d.value(SectionModels, path+".aliases", strings.Join(sortedCopy(oldM.Aliases), ", "), strings.Join(sortedCopy(newM.Aliases), ", "))
+}
+
+// unionKeys returns the sorted union of two maps' keys.
+func unionKeys[V any](a, b map[string]V) []string {
+	keys := slices.Collect(maps.Keys(a))
+	for k := range b {
+		if _, ok := a[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+func sortedCopy(values []string) []string {
+	return slices.Sorted(slices.Values(values))
+}
+
+func describeProvider(p *ProviderConfiguration) string {
+	if p == nil {
+		return ""
+	}
+	parts := []string{fmt.Sprintf("%d models", len(p.Models))}
+	if len(p.Models) == 1 {
+		parts[0] = "1 model"
+	}
+	if p.Type != "" {
+		parts = append(parts, p.Type)
+	}
+	if !p.Enabled {
+		parts = append(parts, "disabled")
+	}
+	return strings.Join(parts, ", ")
+}
+
+func describeModel(m *ModelConfiguration) string {
+	if m == nil {
+		return ""
+	}
+	desc := "tier " + m.Tier
+	if m.Tier == "" {
+		desc = "no tier"
+	}
+	if !m.Enabled {
+		desc += ", disabled"
+	}
+	return desc
+}
+
+func describeProfile(p *ProfileConfiguration) string {
+	if p == nil {
+		return ""
+	}
+	return "generation " + p.GenerationModel
+}
+
+func describePowerPolicy(p *PowerPolicyConfiguration) string {
+	if p == nil || !p.Enabled {
+		return ""
+	}
+	desc := "enabled"
+	if len(p.Profiles) > 0 {
+		desc += ", profiles " + strings.Join(p.Profiles, ", ")
+	}
+	if p.MaxParallel > 0 {
+		desc += fmt.Sprintf(", max_parallel %d", p.MaxParallel)
+	}
+	return desc
+}
+
+func describeBudgets(b *BudgetConfiguration) string {
+	if b == nil {
+		return ""
+	}
+	var parts []string
+	if limit := describeBudgetLimit(b.BudgetLimit); limit != "" {
+		parts = append(parts, limit)
+	}
+	for _, name := range slices.Sorted(maps.Keys(b.Providers)) {
+		if limit := b.Providers[name]; limit != nil {
+			if desc := describeBudgetLimit(*limit); desc != "" {
+				parts = append(parts, name+" "+desc)
+			}
+		}
+	}
+	if len(parts) > 0 && b.Aborts() {
+		parts = append(parts, "abort when exceeded")
+	}
+	return strings.Join(parts, ", ")
+}
+
+func describeGuardrails(g *GuardrailsConfiguration) string {
+	switch {
+	case g == nil:
+		return ""
+	case g.Disabled:
+		return "disabled"
+	}
+	desc := "redact " + strings.Join(g.Redactions(), ", ")
+	if len(g.Allow) > 0 {
+		desc += fmt.Sprintf(", %d allowed", len(g.Allow))
+	}
+	return desc
+}
+
+func describeModeration(m *ModerationConfiguration) string {
+	switch {
+	case m == nil:
+		return ""
+	case m.Disabled:
+		return "disabled"
+	}
+	desc := m.Kind()
+	if m.Model != "" {
+		desc += " " + m.Model
+	}
+	if m.Kind() == ModerationClassifierLocal {
+		desc += " on " + m.LocalProvider()
+	}
+	return desc + ", " + m.OnFlag()
+}
+
+func describeBudgetLimit(l BudgetLimit) string {
+	var parts []string
+	if l.Daily > 0 {
+		parts = append(parts, fmt.Sprintf("$%.2f/day", l.Daily))
+	}
+	if l.Monthly > 0 {
+		parts = append(parts, fmt.Sprintf("$%.2f/month", l.Monthly))
+	}
+	return strings.Join(parts, " ")
+}
+
+func describeExperiment(e *ExperimentConfiguration) string {
+	if e == nil {
+		return ""
+	}
+	return fmt.Sprintf("%d%% to %s", e.Percent, e.CandidateModel)
+}
+
+func describeWeightedModels(models []WeightedModel) string {
+	parts := make([]string, len(models))
+	for i, m := range models {
+		parts[i] = fmt.Sprintf("%s:%d", m.Model, m.Weight)
+	}
+	return strings.Join(parts, ", ")
+}
+
+func describeRateLimits(r *RateLimitConfiguration) string {
+	if r == nil {
+		return ""
+	}
+	return fmt.Sprintf("%d rpm, %d tpm, %d concurrent, %d burst",
+		r.RequestsPerMinute, r.TokensPerMinute, r.ConcurrentRequests, r.BurstLimit)
+}
+
+func formatTokenCost(cost float64) string {
+	if cost == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%g", cost)
+}
+
+// redactSecret hides a secret value, keeping whether it is set.
+func redactSecret(s string) string {
+	if s == "" {
+		return ""
+	}
+	return redactedValue
+}
@@ -0,0 +1,115 @@
+package config
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestDiffRoutingConfigs_Identical(t *testing.T) {
+	if changes := DiffRoutingConfigs(NewRoutingConfiguration(), NewRoutingConfiguration()); len(changes) != 0 {
+		t.Errorf("expected no changes, got %+v", changes)
+	}
+}
+
+func TestDiffRoutingConfigs(t *testing.T) {
+	base := &RoutingConfiguration{
+		DefaultProvider: "ollama",
+		FallbackChain:   []string{"ollama", "openai"},
+		Providers: map[string]*ProviderConfiguration{
+			"ollama": {Enabled: true, Models: map[string]*ModelConfiguration{
+				"llama3": {Tier: "cheap", Enabled: true},
+			}},
+			"openai": {Enabled: true, Models: map[string]*ModelConfiguration{
+				"gpt-4o":      {Tier: "premium", Enabled: true, CostPerInputToken: 0.005},
+				"gpt-4o-mini": {Tier: "cheap", Enabled: true},
+			}},
+		},
+		Profiles: map[string]*ProfileConfiguration{
+			"balanced": {GenerationModel: "llama3", MaxContextTokens: 8192},
+		},
+	}
+	target := &RoutingConfiguration{
+		DefaultProvider: "openai",
+		FallbackChain:   []string{"ollama", "openai"},
+		Providers: map[string]*ProviderConfiguration{
+			"openai": {Enabled: true, Models: map[string]*ModelConfiguration{
+				"gpt-4o":      {Tier: "balanced", Enabled: true, CostPerInputToken: 0.0025},
+				"gpt-4o-mini": {Tier: "cheap", Enabled: true},
+			}},
+			"gateway": {Type: "openai_compatible", Enabled: true, BaseURL: "http://localhost:4000"},
+		},
+		Profiles: map[string]*ProfileConfiguration{
+			"balanced": {GenerationModel: "gpt-4o", MaxContextTokens: 8192},
+			"research": {GenerationModel: "gpt-4o"},
+		},
+	}
+
+	changes := DiffRoutingConfigs(base, target)
+
+	want := []RoutingChange{
+		{Kind: ChangeChanged, Section: SectionGeneral, Path: "default_provider", Old: "ollama", New: "openai"},
+		{Kind: ChangeAdded, Section: SectionProviders, Path: "providers.gateway", New: "0 models, openai_compatible"},
+		{Kind: ChangeRemoved, Section: SectionProviders, Path: "providers.ollama", Old: "1 model"},
+		{Kind: ChangeChanged, Section: SectionModels, Path: "providers.openai.models.gpt-4o.tier", Old: "premium", New: "balanced"},
+		{Kind: ChangeChanged, Section: SectionModels, Path: "providers.openai.models.gpt-4o.cost_per_input_token", Old: "0.005", New: "0.0025"},
+		{Kind: ChangeChanged, Section: SectionProfiles, Path: "profiles.balanced.generation_model", Old: "llama3", New: "gpt-4o"},
+		{Kind: ChangeAdded, Section: SectionProfiles, Path: "profiles.research", New: "generation gpt-4o"},
+	}
+	if !slices.Equal(changes, want) {
+		t.Errorf("DiffRoutingConfigs() =\n%+v\nwant\n%+v", changes, want)
+	}
+}
+
+func TestDiffRoutingConfigs_ModelsAddedAndRemoved(t *testing.T) {
+	base := &RoutingConfiguration{Providers: map[string]*ProviderConfiguration{
+		"groq": {Enabled: true, Models: map[string]*ModelConfiguration{"mixtral": {Tier: "cheap", Enabled: true}}},
+	}}
+	target := &RoutingConfiguration{Providers: map[string]*ProviderConfiguration{
+		"groq": {Enabled: false, Models: map[string]*ModelConfiguration{"llama-3.3": {Tier: "balanced"}}},
+	}}
+
+	changes := DiffRoutingConfigs(base, target)
+
+	want := []string{
+		"providers.groq.enabled: true → false",
+		"providers.groq.models.llama-3.3 added (tier balanced, disabled)",
+		"providers.groq.models.mixtral removed (was tier cheap)",
+	}
+	got := make([]string, len(changes))
+	for i, c := range changes {
+		got[i] = c.String()
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("changes = %q, want %q", got, want)
+	}
+}
+
+func TestDiffRoutingConfigs_Nil(t *testing.T) {
+	changes := DiffRoutingConfigs(nil, &RoutingConfiguration{DefaultProvider: "ollama"})
+	if len(changes) != 1 || changes[0].Kind != ChangeAdded {
+		t.Errorf("expected a single added change, got %+v", changes)
+	}
+}
+
+func TestDiffRoutingConfigs_Experiments(t *testing.T) {
+	base := &RoutingConfiguration{Experiments: map[string]*ExperimentConfiguration{
+		"haiku": {CandidateModel: "claude-3-5-haiku-20241022", Percent: 5},
+		"mini":  {CandidateModel: "gpt-4o-mini", Percent: 10},
+	}}
+	target := &RoutingConfiguration{Experiments: map[string]*ExperimentConfiguration{
+		"mini":  {CandidateModel: "gpt-4o-mini", Percent: 25, Profiles: []string{"cheap"}},
+		"llama": {CandidateModel: "llama-3.3-70b", CandidateProvider: "groq", Percent: 10},
+	}}
+
+	changes := DiffRoutingConfigs(base, target)
+
+	want := []RoutingChange{
+		{Kind: ChangeRemoved, Section: SectionExperiments, Path: "experiments.haiku", Old: "5% to claude-3-5-haiku-20241022"},
+		{Kind: ChangeAdded, Section: SectionExperiments, Path: "experiments.llama", New: "10% to llama-3.3-70b"},
+		{Kind: ChangeChanged, Section: SectionExperiments, Path: "experiments.mini.percent", Old: "10", New: "25"},
+		{Kind: ChangeAdded, Section: SectionExperiments, Path: "experiments.mini.profiles", New: "cheap"},
+	}
+	if !slices.Equal(changes, want) {
+		t.Errorf("DiffRoutingConfigs() =\n%+v\nwant\n%+v", changes, want)
+	}
+}
@@ -0,0 +1,271 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// EnvPrefix namespaces the environment variables skillrunner reads its
+// configuration from.
+const EnvPrefix = "SR_"
+
+// Profile settings that can be set through SR_PROFILES_<PROFILE>_<SETTING>.
+const (
+	envGenerationModel  = "_GENERATION_MODEL"
+	envReviewModel      = "_REVIEW_MODEL"
+	envFallbackModel    = "_FALLBACK_MODEL"
+	envMaxContextTokens = "_MAX_CONTEXT_TOKENS"
+)
+
+// Provider settings that can be set through SR_PROVIDERS_<PROVIDER>_<SETTING>.
+const (
+	envAPIKeyEnv  = "_API_KEY_ENV"
+	envAPIKey     = "_API_KEY"
+	envBaseURL    = "_BASE_URL"
+	envType       = "_TYPE"
+	envEnabled    = "_ENABLED"
+	envPriority   = "_PRIORITY"
+	envTimeout    = "_TIMEOUT"
+	envProxyURL   = "_PROXY_URL"
+	envCACertPath = "_CA_CERT_PATH"
+	envModels     = "_MODELS"
+)
+
+// providerEnvSettings lists the provider settings, longest first where one
+// is a suffix of another.
+var providerEnvSettings = []string{
+	envAPIKeyEnv, envAPIKey, envBaseURL, envType, envEnabled, envPriority,
+	envTimeout, envProxyURL, envCACertPath, envModels,
+}
+
+// RoutingFromEnv builds a routing overlay from the SR_ variables in environ,
+// given as by os.Environ:
+//
+//	SR_DEFAULT_PROVIDER=anthropic
+//	SR_FALLBACK_CHAIN=ollama,anthropic
+//	SR_PROFILES_BALANCED_GENERATION_MODEL=claude-3-5-haiku-20241022
+//	SR_PROVIDERS_OPENAI_API_KEY=sk-...
+//	SR_BUDGETS_DAILY=5
+//
+// Profiles take REVIEW_MODEL, FALLBACK_MODEL and MAX_CONTEXT_TOKENS the same
+// way. Providers take API_KEY, API_KEY_ENV, BASE_URL, TYPE, ENABLED,
+// PRIORITY, TIMEOUT, PROXY_URL, CA_CERT_PATH and MODELS, a comma-separated
+// list of model IDs; a provider set this way is enabled unless ENABLED is
+// false. Budgets take DAILY, MONTHLY, SOFT_LIMIT, ON_EXCEED and
+// PROVIDERS_<PROVIDER>_DAILY or _MONTHLY. Profile and provider names are
+// lowercased. Other variables are ignored. Returns nil if none of the
+// variables is set.
+func RoutingFromEnv(environ []string) (*RoutingConfiguration, error) {
+	var rc *RoutingConfiguration
+	overlay := func() *RoutingConfiguration {
+		if rc == nil {
+			rc = &RoutingConfiguration{}
+		}
+		return rc
+	}
+
+	for _, entry := range environ {
+		name, value, ok := strings.Cut(entry, "=")
+		if !ok || value == "" || !strings.HasPrefix(name, EnvPrefix) {
+			continue
+		}
+		key := strings.TrimPrefix(name, EnvPrefix)
+
+		switch {
+		case key == "DEFAULT_PROVIDER":
+			overlay().DefaultProvider = value
+		case key == "FALLBACK_CHAIN":
+			overlay().FallbackChain = splitEnvList(value)
+		case strings.HasPrefix(key, "PROFILES_"):
+			if err := setProfileFromEnv(overlay(), name, strings.TrimPrefix(key, "PROFILES_"), value); err != nil {
+				return nil, err
+			}
+		case strings.HasPrefix(key, "PROVIDERS_"):
+			if err := setProviderFromEnv(overlay(), name, strings.TrimPrefix(key, "PROVIDERS_"), value); err != nil {
+				return nil, err
+			}
+		case strings.HasPrefix(key, "BUDGETS_"):
+			if err := setBudgetFromEnv(overlay(), name, strings.TrimPrefix(key, "BUDGETS_"), value); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return rc, nil
+}
+
+// setProfileFromEnv applies one SR_PROFILES_<PROFILE>_<SETTING> variable.
+func setProfileFromEnv(rc *RoutingConfiguration, name, key, value string) error {
+	setting := envSetting(key, []string{envGenerationModel, envReviewModel, envFallbackModel, envMaxContextTokens})
+	if setting == "" {
+		return fmt.Errorf("%s: unknown profile setting; use GENERATION_MODEL, REVIEW_MODEL, FALLBACK_MODEL or MAX_CONTEXT_TOKENS", name)
+	}
+
+	profileName := strings.ToLower(strings.TrimSuffix(key, setting))
+	if rc.Profiles == nil {
+		rc.Profiles = make(map[string]*ProfileConfiguration)
+	}
+	profile := rc.Profiles[profileName]
+	if profile == nil {
+		profile = &ProfileConfiguration{}
+		rc.Profiles[profileName] = profile
+	}
+
+	switch setting {
+	case envGenerationModel:
+		profile.GenerationModel = value
+	case envReviewModel:
+		profile.ReviewModel = value
+	case envFallbackModel:
+		profile.FallbackModel = value
+	case envMaxContextTokens:
+		tokens, err := strconv.Atoi(value)
+		if err != nil || tokens <= 0 {
+			return fmt.Errorf("%s: expected a positive number of tokens, got %q", name, value)
+		}
+		profile.MaxContextTokens = tokens
+	}
+	return nil
+}
+
+// setProviderFromEnv applies one SR_PROVIDERS_<PROVIDER>_<SETTING> variable.
+func setProviderFromEnv(rc *RoutingConfiguration, name, key, value string) error {
+	setting := envSetting(key, providerEnvSettings)
+	if setting == "" {
+		return fmt.Errorf("%s: unknown provider setting; use API_KEY, API_KEY_ENV, BASE_URL, TYPE, ENABLED, PRIORITY, TIMEOUT, PROXY_URL, CA_CERT_PATH or MODELS", name)
+	}
+
+	providerName := strings.ToLower(strings.TrimSuffix(key, setting))
+	if rc.Providers == nil {
+		rc.Providers = make(map[string]*ProviderConfiguration)
+	}
+	p := rc.Providers[providerName]
+	if p == nil {
+		p = &ProviderConfiguration{Enabled: true}
+		rc.Providers[providerName] = p
+	}
+
+	switch setting {
+	case envAPIKey:
+		p.APIKey = value
+	case envAPIKeyEnv:
+		p.APIKeyEnv = value
+	case envBaseURL:
+		p.BaseURL = value
+	case envType:
+		p.Type = value
+	case envEnabled:
+		enabled, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("%s: expected true or false, got %q", name, value)
+		}
+		p.Enabled = enabled
+	case envPriority:
+		priority, err := strconv.Atoi(value)
+		if err != nil || priority < 0 {
+			return fmt.Errorf("%s: expected a non-negative priority, got %q", name, value)
+		}
+		p.Priority = priority
+	case envTimeout:
+		seconds, err := parseEnvSeconds(value)
+		if err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		p.Timeout = seconds
+	case envProxyURL:
+		p.ProxyURL = value
+	case envCACertPath:
+		p.CACertPath = value
+	case envModels:
+		p.Models = make(map[string]*ModelConfiguration)
+		for _, model := range splitEnvList(value) {
+			p.Models[model] = &ModelConfiguration{Enabled: true}
+		}
+	}
+	return nil
+}
+
+// setBudgetFromEnv applies one SR_BUDGETS_<SETTING> variable.
+func setBudgetFromEnv(rc *RoutingConfiguration, name, key, value string) error {
+	if rc.Budgets == nil {
+		rc.Budgets = &BudgetConfiguration{}
+	}
+	b := rc.Budgets
+
+	switch {
+	case key == "DAILY":
+		return parseEnvAmount(name, value, &b.Daily)
+	case key == "MONTHLY":
+		return parseEnvAmount(name, value, &b.Monthly)
+	case key == "SOFT_LIMIT":
+		return parseEnvAmount(name, value, &b.SoftLimit)
+	case key == "ON_EXCEED":
+		b.OnExceed = strings.ToLower(value)
+		return nil
+	case strings.HasPrefix(key, "PROVIDERS_"):
+		key = strings.TrimPrefix(key, "PROVIDERS_")
+		setting := envSetting(key, []string{"_DAILY", "_MONTHLY"})
+		if setting == "" {
+			return fmt.Errorf("%s: unknown provider budget setting; use DAILY or MONTHLY", name)
+		}
+		providerName := strings.ToLower(strings.TrimSuffix(key, setting))
+		if b.Providers == nil {
+			b.Providers = make(map[string]*BudgetLimit)
+		}
+		limit := b.Providers[providerName]
+		if limit == nil {
+			limit = &BudgetLimit{}
+			b.Providers[providerName] = limit
+		}
+		if setting == "_DAILY" {
+			return parseEnvAmount(name, value, &limit.Daily)
+		}
+		return parseEnvAmount(name, value, &limit.Monthly)
+	}
+	return fmt.Errorf("%s: unknown budget setting; use DAILY, MONTHLY, SOFT_LIMIT, ON_EXCEED or PROVIDERS_<PROVIDER>_DAILY/_MONTHLY", name)
+}
+
+// envSetting returns the setting of settings that key ends with, after a
+// non-empty name, or "" if there is none.
+func envSetting(key string, settings []string) string {
+	for _, suffix := range settings {
+		if strings.HasSuffix(key, suffix) && len(key) > len(suffix) {
+			return suffix
+		}
+	}
+	return ""
+}
+
+// splitEnvList splits a comma-separated variable, dropping empty entries.
+func splitEnvList(value string) []string {
+	var list []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
+// parseEnvAmount parses a non-negative number into dst.
+func parseEnvAmount(name, value string, dst *float64) error {
+	amount, err := strconv.ParseFloat(value, 64)
+	if err != nil || amount < 0 {
+		return fmt.Errorf("%s: expected a non-negative number, got %q", name, value)
+	}
+	*dst = amount
+	return nil
+}
+
+// parseEnvSeconds parses a timeout given in seconds or as a duration.
+func parseEnvSeconds(value string) (int, error) {
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return seconds, nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("expected a timeout in seconds or as a duration, got %q", value)
+	}
+	return int(d.Round(time.Second) / time.Second), nil
+}
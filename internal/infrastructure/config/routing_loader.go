@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 
 	"gopkg.in/yaml.v3"
 )
@@ -29,7 +30,9 @@ func LoadRoutingConfig(path string) (*RoutingConfiguration, error) {
 	return LoadRoutingConfigFromBytes(data)
 }
 
-// LoadRoutingConfigFromBytes parses YAML bytes into a RoutingConfiguration.
+// LoadRoutingConfigFromBytes parses YAML bytes into a RoutingConfiguration,
+// expanding ${VAR} and ${VAR:-default} environment variable references and
+// decrypting a sops-encrypted document or age-encrypted api_key values.
 // It applies default values and validates the resulting configuration.
 // Returns an error if the YAML is invalid or the configuration fails validation.
 func LoadRoutingConfigFromBytes(data []byte) (*RoutingConfiguration, error) {
@@ -39,8 +42,8 @@ func LoadRoutingConfigFromBytes(data []byte) (*RoutingConfiguration, error) {
 
 	cfg := &RoutingConfiguration{}
 
-	if err := yaml.Unmarshal(data, cfg); err != nil {
-		return nil, fmt.Errorf("failed to parse YAML: %w", err)
+	if err := unmarshalRoutingYAML(data, cfg, RoutingSchema()); err != nil {
+		return nil, err
 	}
 
 	// Apply defaults to fill in missing values
@@ -157,6 +160,38 @@ func deepCopyRoutingConfig(src *RoutingConfiguration) *RoutingConfiguration {
 		}
 	}
 
+	if src.PowerPolicy != nil {
+		policy := *src.PowerPolicy
+		policy.Profiles = slices.Clone(src.PowerPolicy.Profiles)
+		dst.PowerPolicy = &policy
+	}
+
+	if src.Budgets != nil {
+		budgets := *src.Budgets
+		if src.Budgets.Providers != nil {
+			budgets.Providers = make(map[string]*BudgetLimit, len(src.Budgets.Providers))
+			for name, limit := range src.Budgets.Providers {
+				if limit != nil {
+					copied := *limit
+					limit = &copied
+				}
+				budgets.Providers[name] = limit
+			}
+		}
+		dst.Budgets = &budgets
+	}
+
+	dst.Guardrails = deepCopyGuardrailsConfig(src.Guardrails)
+	dst.Moderation = deepCopyModerationConfig(src.Moderation)
+
+	// Deep copy experiments
+	if src.Experiments != nil {
+		dst.Experiments = make(map[string]*ExperimentConfiguration, len(src.Experiments))
+		for name, experiment := range src.Experiments {
+			dst.Experiments[name] = deepCopyExperimentConfig(experiment)
+		}
+	}
+
 	return dst
 }
 
@@ -167,10 +202,17 @@ func deepCopyProviderConfig(src *ProviderConfiguration) *ProviderConfiguration {
 	}
 
 	dst := &ProviderConfiguration{
-		Enabled:  src.Enabled,
-		Priority: src.Priority,
-		BaseURL:  src.BaseURL,
-		Timeout:  src.Timeout,
+		Type:      src.Type,
+		APIKeyEnv: src.APIKeyEnv,
+		APIKey:    src.APIKey,
+		Enabled:   src.Enabled,
+		Priority:  src.Priority,
+		BaseURL:   src.BaseURL,
+		Timeout:   src.Timeout,
+
+		ProxyURL:           src.ProxyURL,
+		CACertPath:         src.CACertPath,
+		InsecureSkipVerify: src.InsecureSkipVerify,
 	}
 
 	// Deep copy rate limits
@@ -183,6 +225,9 @@ func deepCopyProviderConfig(src *ProviderConfiguration) *ProviderConfiguration {
 		}
 	}
 
+	dst.Guardrails = deepCopyGuardrailsConfig(src.Guardrails)
+	dst.Moderation = deepCopyModerationConfig(src.Moderation)
+
 	// Deep copy models
 	if src.Models != nil {
 		dst.Models = make(map[string]*ModelConfiguration, len(src.Models))
@@ -194,6 +239,27 @@ func deepCopyProviderConfig(src *ProviderConfiguration) *ProviderConfiguration {
 	return dst
 }
 
+// deepCopyGuardrailsConfig creates a deep copy of a GuardrailsConfiguration.
+func deepCopyGuardrailsConfig(src *GuardrailsConfiguration) *GuardrailsConfiguration {
+	if src == nil {
+		return nil
+	}
+	return &GuardrailsConfiguration{
+		Redact:   slices.Clone(src.Redact),
+		Allow:    slices.Clone(src.Allow),
+		Disabled: src.Disabled,
+	}
+}
+
+// deepCopyModerationConfig creates a deep copy of a ModerationConfiguration.
+func deepCopyModerationConfig(src *ModerationConfiguration) *ModerationConfiguration {
+	if src == nil {
+		return nil
+	}
+	dst := *src
+	return &dst
+}
+
 // deepCopyModelConfig creates a deep copy of a ModelConfiguration.
 func deepCopyModelConfig(src *ModelConfiguration) *ModelConfiguration {
 	if src == nil {
@@ -232,10 +298,27 @@ func deepCopyProfileConfig(src *ProfileConfiguration) *ProfileConfiguration {
 
 	return &ProfileConfiguration{
 		GenerationModel:  src.GenerationModel,
+		GenerationModels: slices.Clone(src.GenerationModels),
 		ReviewModel:      src.ReviewModel,
 		FallbackModel:    src.FallbackModel,
 		MaxContextTokens: src.MaxContextTokens,
 		PreferLocal:      src.PreferLocal,
+		Selection:        src.Selection,
+	}
+}
+
+// deepCopyExperimentConfig creates a deep copy of an ExperimentConfiguration.
+func deepCopyExperimentConfig(src *ExperimentConfiguration) *ExperimentConfiguration {
+	if src == nil {
+		return nil
+	}
+
+	return &ExperimentConfiguration{
+		CandidateModel:    src.CandidateModel,
+		CandidateProvider: src.CandidateProvider,
+		Percent:           src.Percent,
+		Profiles:          slices.Clone(src.Profiles),
+		Phases:            slices.Clone(src.Phases),
 	}
 }
 
@@ -255,35 +338,78 @@ func LoadRoutingConfigWithDefaults(path string) (*RoutingConfiguration, error) {
 	return LoadRoutingConfig(path)
 }
 
-// LoadAndMergeRoutingConfigs loads multiple configuration files and merges them.
-// Files are loaded in order, with later files taking precedence.
-// Missing files are skipped without error.
-// Returns an error only if a file exists but cannot be parsed.
-func LoadAndMergeRoutingConfigs(paths ...string) (*RoutingConfiguration, error) {
-	var configs []*RoutingConfiguration
+// LoadRoutingOverlay loads a routing file that is merged over another
+// configuration. Unlike LoadRoutingConfig, no defaults are applied and the
+// result is not validated on its own, so the overlay only overrides the values
+// it sets. Environment variable references are expanded and secrets decrypted
+// as in LoadRoutingConfigFromBytes. An empty file is an empty overlay.
+func LoadRoutingOverlay(path string) (*RoutingConfiguration, error) {
+	if path == "" {
+		return nil, errors.New("config path is empty")
+	}
 
-	for _, path := range paths {
-		if path == "" {
-			continue
-		}
+	data, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file %q: %w", path, err)
+	}
 
-		cleanPath := filepath.Clean(path)
-		if _, err := os.Stat(cleanPath); os.IsNotExist(err) {
-			// Skip missing files
-			continue
-		}
+	// A project file holds project settings next to its routing keys
+	schema := RoutingSchema()
+	if filepath.Base(path) == ProjectConfigFileName {
+		schema = ProjectSchema()
+	}
 
-		cfg, err := LoadRoutingConfig(path)
+	cfg := &RoutingConfiguration{}
+	if err := unmarshalRoutingYAML(data, cfg, schema); err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
+// LoadAndMergeRoutingConfigs loads multiple configuration files and merges them
+// over the default configuration. Files are loaded in order, with later files
+// taking precedence; each file only overrides the values it sets.
+// A path may also be a remote source (an http(s) URL or git::<repository>//<path>),
+// which is loaded from its local cache and fetched only when not cached yet.
+// Missing files are skipped without error.
+// Returns an error if a file exists but cannot be parsed, a remote source
+// cannot be fetched, or the merged configuration is invalid.
+func LoadAndMergeRoutingConfigs(sources ...string) (*RoutingConfiguration, error) {
+	var layers []Layer
+	for _, source := range sources {
+		overlay, err := loadRoutingSource(source)
 		if err != nil {
-			return nil, fmt.Errorf("failed to load config from %q: %w", path, err)
+			return nil, err
 		}
+		if overlay != nil {
+			layers = append(layers, Layer{File: source, Value: overlay})
+		}
+	}
+	return MergeRoutingLayers(layers...)
+}
 
-		configs = append(configs, cfg)
+// loadRoutingSource loads a routing file or remote source as an overlay.
+// Returns nil without error if source is empty or the file does not exist.
+func loadRoutingSource(source string) (*RoutingConfiguration, error) {
+	if source == "" {
+		return nil, nil
 	}
 
-	if len(configs) == 0 {
-		return NewRoutingConfiguration(), nil
+	path, err := resolveRoutingSource(source)
+	if err != nil {
+		return nil, err
 	}
 
-	return MergeRoutingConfigs(configs...), nil
+	cleanPath := filepath.Clean(path)
+	if _, err := os.Stat(cleanPath); os.IsNotExist(err) {
+		// Skip missing files
+		return nil, nil
+	}
+
+	overlay, err := LoadRoutingOverlay(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config from %q: %w", source, err)
+	}
+	return overlay, nil
 }
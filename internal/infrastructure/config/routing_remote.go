@@ -0,0 +1,238 @@
+package config
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// gitSourcePrefix marks a routing source in a git repository:
+// git::<repository>//<path>?ref=<branch or tag>.
+const gitSourcePrefix = "git::"
+
+// remoteRoutingCacheDir is the directory under the configuration directory
+// holding the last fetched copy of each remote routing source.
+const remoteRoutingCacheDir = "remote"
+
+// remoteRoutingFetchTimeout bounds fetching one remote routing source.
+const remoteRoutingFetchTimeout = 30 * time.Second
+
+// maxRemoteRoutingSize is the largest routing file fetched over HTTP.
+const maxRemoteRoutingSize = 1 << 20
+
+// IsRemoteRoutingSource reports whether a routing source is fetched rather
+// than read from a local file: an http(s) URL, or a git:: repository source.
+func IsRemoteRoutingSource(source string) bool {
+	return strings.HasPrefix(source, "https://") || strings.HasPrefix(source, "http://") ||
+		strings.HasPrefix(source, gitSourcePrefix)
+}
+
+// ValidateRemoteRoutingSource checks that a remote routing source is well formed.
+func ValidateRemoteRoutingSource(source string) error {
+	if !IsRemoteRoutingSource(source) {
+		return fmt.Errorf("routing source %q must be an http(s) URL or git::<repository>//<path>", source)
+	}
+	if strings.HasPrefix(source, gitSourcePrefix) {
+		_, path, _, err := parseGitSource(source)
+		if err != nil {
+			return err
+		}
+		if path == "" {
+			return fmt.Errorf("routing source %q: missing //<path> of the routing file in the repository", source)
+		}
+		return nil
+	}
+	if _, err := url.ParseRequestURI(source); err != nil {
+		return fmt.Errorf("routing source %q: %w", source, err)
+	}
+	return nil
+}
+
+// RemoteRoutingCachePath returns where the last fetched copy of a remote
+// routing source is kept, whether or not it has been fetched.
+func RemoteRoutingCachePath(source string) (string, error) {
+	loader, err := NewLoader("")
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256([]byte(source))
+	return filepath.Join(loader.ConfigDir(), remoteRoutingCacheDir, hex.EncodeToString(sum[:8])+".yaml"), nil
+}
+
+// resolveRoutingSource returns the local file to load a routing source from.
+// Remote sources are read from their cached copy, which is fetched first if
+// there is none yet; 'sr config refresh' fetches them again.
+func resolveRoutingSource(source string) (string, error) {
+	if !IsRemoteRoutingSource(source) {
+		return source, nil
+	}
+
+	path, err := RemoteRoutingCachePath(source)
+	if err != nil {
+		return "", err
+	}
+	if _, err := os.Stat(path); err == nil {
+		return path, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), remoteRoutingFetchTimeout)
+	defer cancel()
+	return RefreshRemoteRouting(ctx, source)
+}
+
+// RefreshRemoteRouting fetches a remote routing source and replaces its
+// cached copy, returning the cache path. The fetched file must parse as
+// routing configuration; otherwise the cached copy is kept and an error is
+// returned.
+func RefreshRemoteRouting(ctx context.Context, source string) (string, error) {
+	if err := ValidateRemoteRoutingSource(source); err != nil {
+		return "", err
+	}
+
+	var data []byte
+	var err error
+	if strings.HasPrefix(source, gitSourcePrefix) {
+		data, err = fetchGitRouting(ctx, source)
+	} else {
+		data, err = fetchHTTPFile(ctx, source, maxRemoteRoutingSize)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch routing source %s: %w", source, err)
+	}
+
+	if err := unmarshalRoutingYAML(data, &RoutingConfiguration{}, RoutingSchema()); err != nil {
+		return "", fmt.Errorf("routing source %s: %w", source, err)
+	}
+
+	path, err := RemoteRoutingCachePath(source)
+	if err != nil {
+		return "", err
+	}
+	if err := writeFileAtomic(path, data); err != nil {
+		return "", fmt.Errorf("failed to cache routing source: %w", err)
+	}
+	return path, nil
+}
+
+// writeFileAtomic replaces a cached file through a rename, creating its
+// directory, so that a watcher never reads half a file.
+func writeFileAtomic(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// fetchHTTPFile downloads source, refusing files larger than maxSize bytes.
+func fetchHTTPFile(ctx context.Context, source string, maxSize int64) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, source, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > maxSize {
+		return nil, fmt.Errorf("file is larger than %d bytes", maxSize)
+	}
+	return data, nil
+}
+
+// fetchGitRouting shallow-clones the repository of a git:: source into a
+// temporary directory and reads the routing file from it.
+func fetchGitRouting(ctx context.Context, source string) ([]byte, error) {
+	repo, path, ref, err := parseGitSource(source)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := exec.LookPath("git"); err != nil {
+		return nil, errors.New("git is not installed")
+	}
+
+	dir, err := os.MkdirTemp("", "sr-routing-*")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	args := []string{"clone", "--quiet", "--depth", "1"}
+	if ref != "" {
+		args = append(args, "--branch", ref)
+	}
+	args = append(args, "--", repo, dir)
+
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return nil, fmt.Errorf("git clone failed: %w: %s", err, strings.TrimSpace(string(out)))
+	}
+
+	file := filepath.Join(dir, filepath.FromSlash(path))
+	if rel, err := filepath.Rel(dir, file); err != nil || strings.HasPrefix(rel, "..") {
+		return nil, fmt.Errorf("path %q is outside the repository", path)
+	}
+	return os.ReadFile(file)
+}
+
+// parseGitSource splits git::<repository>//<path>?ref=<ref> into its parts.
+// The // separating the path is the first one after the repository's scheme.
+func parseGitSource(source string) (repo, path, ref string, err error) {
+	rest := strings.TrimPrefix(source, gitSourcePrefix)
+	if i := strings.LastIndex(rest, "?"); i >= 0 {
+		query, qerr := url.ParseQuery(rest[i+1:])
+		if qerr != nil {
+			return "", "", "", fmt.Errorf("routing source %q: %w", source, qerr)
+		}
+		ref = query.Get("ref")
+		rest = rest[:i]
+	}
+
+	searchFrom := 0
+	if i := strings.Index(rest, "://"); i >= 0 {
+		searchFrom = i + len("://")
+	}
+	if i := strings.Index(rest[searchFrom:], "//"); i >= 0 {
+		repo, path = rest[:searchFrom+i], rest[searchFrom+i+2:]
+	} else {
+		repo = rest
+	}
+
+	if repo == "" {
+		return "", "", "", fmt.Errorf("routing source %q: missing repository", source)
+	}
+	return repo, path, ref, nil
+}
@@ -0,0 +1,127 @@
+package config
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseGitSource(t *testing.T) {
+	tests := []struct {
+		source, repo, path, ref string
+	}{
+		{"git::https://github.com/acme/policy.git//routing.yaml?ref=v2", "https://github.com/acme/policy.git", "routing.yaml", "v2"},
+		{"git::git@github.com:acme/policy.git//team/routing.yaml", "git@github.com:acme/policy.git", "team/routing.yaml", ""},
+		{"git::file:///srv/policy//routing.yaml", "file:///srv/policy", "routing.yaml", ""},
+	}
+	for _, tt := range tests {
+		repo, path, ref, err := parseGitSource(tt.source)
+		if err != nil {
+			t.Fatalf("parseGitSource(%q) error = %v", tt.source, err)
+		}
+		if repo != tt.repo || path != tt.path || ref != tt.ref {
+			t.Errorf("parseGitSource(%q) = %q, %q, %q; want %q, %q, %q", tt.source, repo, path, ref, tt.repo, tt.path, tt.ref)
+		}
+	}
+
+	for _, bad := range []string{"/etc/routing.yaml", "git::https://github.com/acme/policy.git", "ftp://example.com/routing.yaml"} {
+		if err := ValidateRemoteRoutingSource(bad); err == nil {
+			t.Errorf("ValidateRemoteRoutingSource(%q) = nil, want an error", bad)
+		}
+	}
+}
+
+func TestRemoteRoutingSource_HTTP(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	body := "profiles:\n  balanced:\n    generation_model: team-model\n"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(body))
+	}))
+	source := server.URL + "/routing.yaml"
+
+	// The first load fetches and caches the source
+	rc, err := LoadAndMergeRoutingConfigs(source)
+	if err != nil {
+		t.Fatalf("LoadAndMergeRoutingConfigs() error = %v", err)
+	}
+	if got := rc.Profiles["balanced"].GenerationModel; got != "team-model" {
+		t.Errorf("balanced generation_model = %q, want team-model", got)
+	}
+
+	// Later loads read the cache, even with the server gone
+	server.Close()
+	rc, err = LoadAndMergeRoutingConfigs(source)
+	if err != nil {
+		t.Fatalf("LoadAndMergeRoutingConfigs() from cache error = %v", err)
+	}
+	if got := rc.Profiles["balanced"].GenerationModel; got != "team-model" {
+		t.Errorf("cached balanced generation_model = %q, want team-model", got)
+	}
+	if _, err := RefreshRemoteRouting(context.Background(), source); err == nil {
+		t.Error("RefreshRemoteRouting() of an unreachable source should fail")
+	}
+}
+
+func TestRefreshRemoteRouting_KeepsCacheOnInvalidFile(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	body := "default_provider: ollama\n"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(body))
+	}))
+	defer server.Close()
+
+	path, err := RefreshRemoteRouting(context.Background(), server.URL)
+	if err != nil {
+		t.Fatalf("RefreshRemoteRouting() error = %v", err)
+	}
+
+	body = "providers: [not, a, mapping]\n"
+	if _, err := RefreshRemoteRouting(context.Background(), server.URL); err == nil {
+		t.Fatal("RefreshRemoteRouting() of an invalid file should fail")
+	}
+	data, err := os.ReadFile(path)
+	if err != nil || !strings.Contains(string(data), "default_provider") {
+		t.Errorf("cached copy = %q, %v; want the previous file", data, err)
+	}
+}
+
+func TestRemoteRoutingSource_Git(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	t.Setenv("HOME", t.TempDir())
+
+	repo := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(repo, "team"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(repo, "team", "routing.yaml"), []byte("default_provider: groq\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	for _, args := range [][]string{
+		{"init", "--quiet"},
+		{"add", "."},
+		{"-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "--quiet", "-m", "policy"},
+	} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = repo
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %v: %s", args, err, out)
+		}
+	}
+
+	rc, err := LoadAndMergeRoutingConfigs("git::file://" + filepath.ToSlash(repo) + "//team/routing.yaml")
+	if err != nil {
+		t.Fatalf("LoadAndMergeRoutingConfigs() error = %v", err)
+	}
+	if rc.DefaultProvider != "groq" {
+		t.Errorf("DefaultProvider = %q, want groq", rc.DefaultProvider)
+	}
+}
@@ -0,0 +1,118 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/jbctechsolutions/skillrunner/internal/infrastructure/crypto"
+)
+
+// unmarshalRoutingYAML parses routing YAML with environment variable
+// expansion, checking it against schema. A document encrypted by sops is
+// decrypted first; api_key values must then be age-encrypted, unless the
+// document was encrypted by sops.
+func unmarshalRoutingYAML(data []byte, cfg *RoutingConfiguration, schema *Schema) error {
+	ctx := context.Background()
+
+	fromSops := crypto.IsSopsDocument(data)
+	if fromSops {
+		decrypted, err := crypto.SopsDecrypt(ctx, data)
+		if err != nil {
+			return err
+		}
+		data = decrypted
+	}
+
+	if err := UnmarshalYAMLWithSchema(data, cfg, schema); err != nil {
+		return fmt.Errorf("failed to parse YAML: %w", err)
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(cfg.Providers)) {
+		p := cfg.Providers[name]
+		switch {
+		case p == nil || p.APIKey == "":
+		case crypto.IsAgeEncrypted(p.APIKey):
+			apiKey, err := crypto.AgeDecrypt(ctx, p.APIKey)
+			if err != nil {
+				return fmt.Errorf("providers.%s.api_key: %w", name, err)
+			}
+			p.APIKey = apiKey
+		case !fromSops:
+			return fmt.Errorf("providers.%s.api_key must be encrypted with sr config encrypt or sops; use api_key_env for a key in the environment", name)
+		}
+	}
+	return nil
+}
+
+// SetProviderAPIKey sets providers.<name>.api_key in the routing file at
+// path to apiKey, which should be encrypted. The rest of the file, comments
+// included, is kept; environment variable references are not expanded.
+// The provider must already be declared in the file.
+func SetProviderAPIKey(path, name, apiKey string) error {
+	path = filepath.Clean(path)
+	info, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("failed to read config file %q: %w", path, err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read config file %q: %w", path, err)
+	}
+	if crypto.IsSopsDocument(data) {
+		return fmt.Errorf("%s is encrypted with sops: edit it with sops instead", path)
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return fmt.Errorf("failed to parse YAML: %w", err)
+	}
+	if len(doc.Content) == 0 {
+		return fmt.Errorf("provider %s is not declared in %s", name, path)
+	}
+
+	provider := mappingValue(mappingValue(doc.Content[0], "providers"), name)
+	if provider == nil || provider.Kind != yaml.MappingNode {
+		return fmt.Errorf("provider %s is not declared in %s", name, path)
+	}
+
+	value := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: apiKey, Style: yaml.LiteralStyle}
+	if existing := mappingValue(provider, "api_key"); existing != nil {
+		*existing = *value
+	} else {
+		key := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: "api_key"}
+		provider.Content = append(provider.Content, key, value)
+	}
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := errors.Join(enc.Encode(&doc), enc.Close()); err != nil {
+		return fmt.Errorf("failed to marshal config: %w", err)
+	}
+
+	if err := os.WriteFile(path, buf.Bytes(), info.Mode().Perm()); err != nil {
+		return fmt.Errorf("failed to write config file %q: %w", path, err)
+	}
+	return nil
+}
+
+// mappingValue returns the value of key in a YAML mapping node, or nil.
+func mappingValue(n *yaml.Node, key string) *yaml.Node {
+	if n == nil || n.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		if n.Content[i].Value == key {
+			return n.Content[i+1]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadRoutingConfigFromBytes_PlainAPIKey(t *testing.T) {
+	data := []byte(`providers:
+  gateway:
+    type: openai_compatible
+    base_url: http://localhost:8000/v1
+    api_key: sk-plain
+    enabled: true
+`)
+	_, err := LoadRoutingConfigFromBytes(data)
+	if err == nil || !strings.Contains(err.Error(), "providers.gateway.api_key must be encrypted") {
+		t.Errorf("LoadRoutingConfigFromBytes() error = %v, want a plain api_key to be rejected", err)
+	}
+}
+
+func TestSetProviderAPIKey(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "routing.yaml")
+	original := `# Team gateway
+providers:
+  gateway:
+    type: openai_compatible
+    base_url: ${GATEWAY_URL}
+    enabled: true
+`
+	if err := os.WriteFile(path, []byte(original), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	ciphertext := "-----BEGIN AGE ENCRYPTED FILE-----\nYWJj\n-----END AGE ENCRYPTED FILE-----\n"
+	if err := SetProviderAPIKey(path, "gateway", ciphertext); err != nil {
+		t.Fatalf("SetProviderAPIKey() error = %v", err)
+	}
+	if err := SetProviderAPIKey(path, "gateway", ciphertext); err != nil {
+		t.Fatalf("SetProviderAPIKey() replacing the key error = %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := string(data)
+	for _, want := range []string{"# Team gateway", "base_url: ${GATEWAY_URL}", "api_key: |\n      -----BEGIN AGE ENCRYPTED FILE-----\n"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("routing file should contain %q:\n%s", want, got)
+		}
+	}
+	if strings.Count(got, "api_key:") != 1 {
+		t.Errorf("routing file should have one api_key:\n%s", got)
+	}
+	if info, _ := os.Stat(path); info.Mode().Perm() != 0o600 {
+		t.Errorf("file mode = %v, want 0600", info.Mode().Perm())
+	}
+
+	if err := SetProviderAPIKey(path, "missing", ciphertext); err == nil {
+		t.Error("SetProviderAPIKey() for an undeclared provider should fail")
+	}
+}
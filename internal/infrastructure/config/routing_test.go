@@ -4,8 +4,12 @@ package config
 import (
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
 	"testing"
 
+	"gopkg.in/yaml.v3"
+
 	"github.com/jbctechsolutions/skillrunner/internal/domain/provider"
 	"github.com/jbctechsolutions/skillrunner/internal/domain/skill"
 )
@@ -98,6 +102,53 @@ func TestRoutingConfiguration_Validate(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "custom profile",
+			config: &RoutingConfiguration{
+				DefaultProvider: "ollama",
+				Providers:       make(map[string]*ProviderConfiguration),
+				Profiles: map[string]*ProfileConfiguration{
+					"long-context": {GenerationModel: "gemini-1.5-pro", MaxContextTokens: 1000000},
+				},
+				Experiments: map[string]*ExperimentConfiguration{
+					"flash": {CandidateModel: "gemini-1.5-flash", Percent: 10, Profiles: []string{"long-context"}},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "custom profile without models",
+			config: &RoutingConfiguration{
+				DefaultProvider: "ollama",
+				Providers:       make(map[string]*ProviderConfiguration),
+				Profiles: map[string]*ProfileConfiguration{
+					"reasoning": {MaxContextTokens: 4096},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "experiment on undefined profile",
+			config: &RoutingConfiguration{
+				DefaultProvider: "ollama",
+				Providers:       make(map[string]*ProviderConfiguration),
+				Profiles:        make(map[string]*ProfileConfiguration),
+				Experiments: map[string]*ExperimentConfiguration{
+					"mini": {CandidateModel: "gpt-4o-mini", Percent: 10, Profiles: []string{"reasoning"}},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "power policy on undefined profile",
+			config: &RoutingConfiguration{
+				DefaultProvider: "ollama",
+				Providers:       make(map[string]*ProviderConfiguration),
+				Profiles:        make(map[string]*ProfileConfiguration),
+				PowerPolicy:     &PowerPolicyConfiguration{Enabled: true, Profiles: []string{"offline"}},
+			},
+			wantErr: true,
+		},
 		{
 			name: "invalid provider config",
 			config: &RoutingConfiguration{
@@ -404,6 +455,22 @@ func TestRoutingConfiguration_Merge(t *testing.T) {
 				return nil
 			},
 		},
+		{
+			name: "experiments replace those of the same name",
+			base: &RoutingConfiguration{Experiments: map[string]*ExperimentConfiguration{
+				"mini":  {CandidateModel: "gpt-4o-mini", Percent: 10},
+				"haiku": {CandidateModel: "claude-3-5-haiku-20241022", Percent: 5},
+			}},
+			other: &RoutingConfiguration{Experiments: map[string]*ExperimentConfiguration{
+				"mini": {CandidateModel: "gpt-4o-mini", Percent: 50},
+			}},
+			check: func(cfg *RoutingConfiguration) error {
+				if len(cfg.Experiments) != 2 || cfg.Experiments["mini"].Percent != 50 {
+					return errorf("Experiments = %v, want mini at 50%% and haiku kept", cfg.Experiments)
+				}
+				return nil
+			},
+		},
 		{
 			name: "override default provider",
 			base: &RoutingConfiguration{DefaultProvider: "ollama"},
@@ -564,6 +631,26 @@ func TestProviderConfiguration_Validate(t *testing.T) {
 			config:  &ProviderConfiguration{Timeout: -1},
 			wantErr: true,
 		},
+		{
+			name:    "valid operation timeouts",
+			config:  &ProviderConfiguration{Timeouts: &TimeoutConfiguration{HealthCheck: 5, Request: 600}},
+			wantErr: false,
+		},
+		{
+			name:    "negative operation timeout",
+			config:  &ProviderConfiguration{Timeouts: &TimeoutConfiguration{ListModels: -1}},
+			wantErr: true,
+		},
+		{
+			name:    "valid proxy",
+			config:  &ProviderConfiguration{ProxyURL: "http://proxy.corp.example.com:3128"},
+			wantErr: false,
+		},
+		{
+			name:    "unsupported proxy scheme",
+			config:  &ProviderConfiguration{ProxyURL: "ftp://proxy.corp.example.com"},
+			wantErr: true,
+		},
 		{
 			name: "invalid model config",
 			config: &ProviderConfiguration{
@@ -580,6 +667,21 @@ func TestProviderConfiguration_Validate(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name:    "openai_compatible with base_url",
+			config:  &ProviderConfiguration{Type: ProviderTypeOpenAICompatible, BaseURL: "http://localhost:8000/v1"},
+			wantErr: false,
+		},
+		{
+			name:    "openai_compatible without base_url",
+			config:  &ProviderConfiguration{Type: ProviderTypeOpenAICompatible},
+			wantErr: true,
+		},
+		{
+			name:    "unknown type",
+			config:  &ProviderConfiguration{Type: "grpc", BaseURL: "http://localhost:8000"},
+			wantErr: true,
+		},
 		{
 			name: "valid full config",
 			config: &ProviderConfiguration{
@@ -1158,6 +1260,38 @@ func TestProfileConfiguration_Validate(t *testing.T) {
 			config:  &ProfileConfiguration{MaxContextTokens: -1},
 			wantErr: true,
 		},
+		{
+			name:    "fastest selection",
+			config:  &ProfileConfiguration{Selection: SelectionFastest},
+			wantErr: false,
+		},
+		{
+			name:    "unknown selection",
+			config:  &ProfileConfiguration{Selection: "cheapest"},
+			wantErr: true,
+		},
+		{
+			name: "weighted generation models",
+			config: &ProfileConfiguration{GenerationModels: []WeightedModel{
+				{Model: "gpt-4o-mini", Weight: 70}, {Model: "claude-3-5-haiku-20241022", Weight: 30}, {Model: "gpt-3.5-turbo", Weight: 0},
+			}},
+			wantErr: false,
+		},
+		{
+			name:    "weighted model without name",
+			config:  &ProfileConfiguration{GenerationModels: []WeightedModel{{Weight: 1}}},
+			wantErr: true,
+		},
+		{
+			name:    "negative weight",
+			config:  &ProfileConfiguration{GenerationModels: []WeightedModel{{Model: "a", Weight: 2}, {Model: "b", Weight: -1}}},
+			wantErr: true,
+		},
+		{
+			name:    "all weights zero",
+			config:  &ProfileConfiguration{GenerationModels: []WeightedModel{{Model: "a"}}},
+			wantErr: true,
+		},
 		{
 			name: "valid full config",
 			config: &ProfileConfiguration{
@@ -1181,6 +1315,121 @@ func TestProfileConfiguration_Validate(t *testing.T) {
 	}
 }
 
+func TestExperimentConfiguration_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  *ExperimentConfiguration
+		wantErr bool
+	}{
+		{"nil config", nil, true},
+		{"valid", &ExperimentConfiguration{CandidateModel: "gpt-4o-mini", Percent: 10, Profiles: []string{"cheap"}}, false},
+		{"paused", &ExperimentConfiguration{CandidateModel: "gpt-4o-mini"}, false},
+		{"missing candidate", &ExperimentConfiguration{Percent: 10}, true},
+		{"percent over 100", &ExperimentConfiguration{CandidateModel: "gpt-4o-mini", Percent: 101}, true},
+		{"negative percent", &ExperimentConfiguration{CandidateModel: "gpt-4o-mini", Percent: -5}, true},
+		{"invalid profile", &ExperimentConfiguration{CandidateModel: "gpt-4o-mini", Percent: 10, Profiles: []string{"Fast"}}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.config.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestExperimentConfiguration_Includes(t *testing.T) {
+	e := &ExperimentConfiguration{CandidateModel: "gpt-4o-mini", Percent: 10}
+	if !e.Includes("draft", "balanced") {
+		t.Error("expected an unfiltered experiment to include every phase")
+	}
+
+	e.Profiles = []string{"cheap"}
+	e.Phases = []string{"draft"}
+	if !e.Includes("draft", "cheap") {
+		t.Error("expected the experiment to include a matching phase")
+	}
+	if e.Includes("draft", "balanced") || e.Includes("review", "cheap") {
+		t.Error("expected the experiment to exclude phases outside its profiles or phases")
+	}
+}
+
+func TestPowerPolicyConfiguration(t *testing.T) {
+	policy := &PowerPolicyConfiguration{Enabled: true}
+	if err := policy.Validate(); err != nil {
+		t.Errorf("Validate() error = %v", err)
+	}
+	if !policy.AppliesTo("balanced") || !policy.AppliesTo("premium") || policy.AppliesTo("cheap") {
+		t.Error("expected the default policy to apply to balanced and premium phases only")
+	}
+
+	policy.Profiles = []string{"cheap"}
+	if !policy.AppliesTo("cheap") || policy.AppliesTo("balanced") {
+		t.Error("expected the policy to apply to its configured profiles")
+	}
+
+	invalid := &PowerPolicyConfiguration{Enabled: true, Profiles: []string{"Fast"}, MaxParallel: -1}
+	if err := invalid.Validate(); err == nil {
+		t.Error("expected an error for an invalid profile and negative max_parallel")
+	}
+}
+
+func TestBudgetConfiguration(t *testing.T) {
+	var parsed RoutingConfiguration
+	err := yaml.Unmarshal([]byte(`
+budgets:
+  daily: 10
+  monthly: 200
+  on_exceed: abort
+  providers:
+    anthropic:
+      daily: 5
+`), &parsed)
+	if err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	budgets := parsed.Budgets
+	if budgets == nil || budgets.Daily != 10 || budgets.Monthly != 200 || budgets.Providers["anthropic"].Daily != 5 {
+		t.Fatalf("Budgets = %+v, want the global caps inline and anthropic's daily cap", budgets)
+	}
+	if err := budgets.Validate(); err != nil {
+		t.Errorf("Validate() error = %v", err)
+	}
+	if !budgets.Aborts() || budgets.SoftShare() != DefaultBudgetSoftLimit {
+		t.Errorf("Aborts() = %v, SoftShare() = %v; want abort and the default soft limit", budgets.Aborts(), budgets.SoftShare())
+	}
+
+	invalid := &BudgetConfiguration{
+		BudgetLimit: BudgetLimit{Daily: -1},
+		Providers:   map[string]*BudgetLimit{"openai": {Monthly: -5}},
+		SoftLimit:   1.5,
+		OnExceed:    "ignore",
+	}
+	err = invalid.Validate()
+	if err == nil {
+		t.Fatal("expected errors for negative caps, soft_limit and on_exceed")
+	}
+	for _, want := range []string{"providers.openai", "soft_limit", "on_exceed"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Validate() error = %v, want it to mention %s", err, want)
+		}
+	}
+}
+
+func TestProfileConfiguration_GenerationModelIDs(t *testing.T) {
+	p := &ProfileConfiguration{GenerationModel: "gpt-4o"}
+	if got := p.GenerationModelIDs(); !slices.Equal(got, []string{"gpt-4o"}) {
+		t.Errorf("GenerationModelIDs() = %v, want the generation model", got)
+	}
+
+	p.GenerationModels = []WeightedModel{{Model: "a", Weight: 2}, {Model: "b"}, {Model: "c", Weight: 1}}
+	if got := p.GenerationModelIDs(); !slices.Equal(got, []string{"a", "c"}) {
+		t.Errorf("GenerationModelIDs() = %v, want the weighted models without drained ones", got)
+	}
+}
+
 func TestProfileConfiguration_ToSkillRoutingConfig(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -1280,6 +1529,25 @@ func TestProfileConfiguration_Merge(t *testing.T) {
 		}
 	})
 
+	t.Run("merge replaces generation models", func(t *testing.T) {
+		p := &ProfileConfiguration{GenerationModels: []WeightedModel{{Model: "a", Weight: 1}}}
+		other := &ProfileConfiguration{GenerationModels: []WeightedModel{{Model: "b", Weight: 3}, {Model: "c", Weight: 1}}}
+		p.Merge(other)
+
+		if len(p.GenerationModels) != 2 || p.GenerationModels[0].Model != "b" {
+			t.Errorf("GenerationModels = %v, want the merged list", p.GenerationModels)
+		}
+		other.GenerationModels[0].Model = "changed"
+		if p.GenerationModels[0].Model != "b" {
+			t.Error("merged GenerationModels should not alias the other profile")
+		}
+
+		p.Merge(&ProfileConfiguration{})
+		if len(p.GenerationModels) != 2 {
+			t.Error("an empty list should not override")
+		}
+	})
+
 	t.Run("empty strings don't override", func(t *testing.T) {
 		p := &ProfileConfiguration{
 			GenerationModel: "gpt-4",
@@ -1689,6 +1957,50 @@ providers:
 		}
 	})
 
+	t.Run("later files only override values they set", func(t *testing.T) {
+		tmpDir := t.TempDir()
+
+		userPath := filepath.Join(tmpDir, "routing.yaml")
+		userContent := `
+default_provider: anthropic
+fallback_chain: [anthropic, ollama]
+profiles:
+  cheap:
+    generation_model: llama3.2:3b
+`
+		if err := os.WriteFile(userPath, []byte(userContent), 0o644); err != nil {
+			t.Fatalf("Failed to write user config: %v", err)
+		}
+
+		projectPath := filepath.Join(tmpDir, ".skillrunner.yaml")
+		projectContent := `
+budget:
+  max_cost_per_run: 1
+profiles:
+  cheap:
+    review_model: llama3.2:8b
+`
+		if err := os.WriteFile(projectPath, []byte(projectContent), 0o644); err != nil {
+			t.Fatalf("Failed to write project config: %v", err)
+		}
+
+		cfg, err := LoadAndMergeRoutingConfigs(userPath, projectPath)
+		if err != nil {
+			t.Fatalf("LoadAndMergeRoutingConfigs() error = %v", err)
+		}
+
+		if cfg.DefaultProvider != "anthropic" {
+			t.Errorf("DefaultProvider = %q, want anthropic", cfg.DefaultProvider)
+		}
+		if len(cfg.FallbackChain) != 2 {
+			t.Errorf("FallbackChain = %v, want the user's chain", cfg.FallbackChain)
+		}
+		cheap := cfg.Profiles["cheap"]
+		if cheap.GenerationModel != "llama3.2:3b" || cheap.ReviewModel != "llama3.2:8b" {
+			t.Errorf("cheap profile = %+v, want user generation and project review model", cheap)
+		}
+	})
+
 	t.Run("invalid file returns error", func(t *testing.T) {
 		tmpDir := t.TempDir()
 		invalidPath := filepath.Join(tmpDir, "invalid.yaml")
@@ -1870,6 +2182,51 @@ func TestDeepCopyProviderConfig(t *testing.T) {
 			t.Error("Models should be nil")
 		}
 	})
+
+	t.Run("generic provider fields", func(t *testing.T) {
+		src := &ProviderConfiguration{
+			Type:      ProviderTypeOpenAICompatible,
+			APIKeyEnv: "VLLM_API_KEY",
+			BaseURL:   "http://localhost:8000/v1",
+		}
+
+		dst := deepCopyProviderConfig(src)
+		if dst.Type != src.Type || dst.APIKeyEnv != src.APIKeyEnv {
+			t.Errorf("Type/APIKeyEnv not copied: got %q/%q", dst.Type, dst.APIKeyEnv)
+		}
+	})
+}
+
+func TestLoadRoutingConfigFromBytes_OpenAICompatible(t *testing.T) {
+	data := []byte(`
+default_provider: ollama
+providers:
+  vllm:
+    type: openai_compatible
+    base_url: http://localhost:8000/v1
+    api_key_env: VLLM_API_KEY
+    enabled: true
+    models:
+      meta-llama/Llama-3.1-8B-Instruct:
+        tier: cheap
+        enabled: true
+`)
+
+	cfg, err := LoadRoutingConfigFromBytes(data)
+	if err != nil {
+		t.Fatalf("LoadRoutingConfigFromBytes() error = %v", err)
+	}
+
+	vllm := cfg.GetProvider("vllm")
+	if !vllm.IsOpenAICompatible() {
+		t.Fatalf("expected vllm to be openai_compatible, got type %q", vllm.Type)
+	}
+	if vllm.APIKeyEnv != "VLLM_API_KEY" {
+		t.Errorf("APIKeyEnv = %q, want VLLM_API_KEY", vllm.APIKeyEnv)
+	}
+	if vllm.GetModel("meta-llama/Llama-3.1-8B-Instruct") == nil {
+		t.Error("expected model to be configured")
+	}
 }
 
 func TestDeepCopyModelConfig(t *testing.T) {
@@ -0,0 +1,171 @@
+package config
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// DefaultRoutingReloadDebounce is how long routing files must be quiet before
+// they are reloaded, so that an editor's save is read once and complete.
+const DefaultRoutingReloadDebounce = 250 * time.Millisecond
+
+// RoutingWatcher reloads routing configuration files when they change.
+// It watches the directories containing the files rather than the files
+// themselves, so that files replaced by an editor's atomic save, or created
+// after the watcher started, are still picked up.
+type RoutingWatcher struct {
+	fsWatcher *fsnotify.Watcher
+	paths     []string
+	debounce  time.Duration
+	load      func() (*RoutingConfiguration, error)
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+	mu     sync.Mutex
+	closed bool
+}
+
+// NewRoutingWatcher creates a watcher for the given routing files, which are
+// merged in order on reload as by LoadAndMergeRoutingConfigs. Remote sources
+// are watched through their cached copies. A debounce of zero or less uses
+// DefaultRoutingReloadDebounce.
+func NewRoutingWatcher(debounce time.Duration, paths ...string) (*RoutingWatcher, error) {
+	fsWatcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+	if debounce <= 0 {
+		debounce = DefaultRoutingReloadDebounce
+	}
+
+	cleaned := make([]string, 0, len(paths))
+	for _, path := range paths {
+		if IsRemoteRoutingSource(path) {
+			// Watch the cached copy, which sr config refresh replaces
+			if path, err = RemoteRoutingCachePath(path); err != nil {
+				_ = fsWatcher.Close()
+				return nil, err
+			}
+		}
+		if path != "" {
+			cleaned = append(cleaned, filepath.Clean(path))
+		}
+	}
+
+	return &RoutingWatcher{
+		fsWatcher: fsWatcher,
+		paths:     cleaned,
+		debounce:  debounce,
+		load: func() (*RoutingConfiguration, error) {
+			return LoadAndMergeRoutingConfigs(cleaned...)
+		},
+	}, nil
+}
+
+// NewRoutingChainWatcher creates a watcher for the files of a routing
+// discovery chain, which reloads the whole chain, environment included, on
+// change.
+func NewRoutingChainWatcher(debounce time.Duration, chain RoutingChain) (*RoutingWatcher, error) {
+	w, err := NewRoutingWatcher(debounce, chain.Paths()...)
+	if err != nil {
+		return nil, err
+	}
+	w.load = chain.Load
+	return w, nil
+}
+
+// Watch starts watching in the background until ctx is cancelled or the
+// watcher is closed. After each change to the files, onChange receives the
+// reloaded and validated configuration, or the error that prevented loading
+// it; in that case the caller should keep its current configuration.
+// Directories that do not exist are skipped.
+func (w *RoutingWatcher) Watch(ctx context.Context, onChange func(*RoutingConfiguration, error)) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return nil
+	}
+
+	for _, dir := range w.dirs() {
+		if _, err := os.Stat(dir); os.IsNotExist(err) {
+			continue
+		}
+		if err := w.fsWatcher.Add(dir); err != nil {
+			return err
+		}
+	}
+
+	ctx, w.cancel = context.WithCancel(ctx)
+	w.wg.Add(1)
+	go w.run(ctx, onChange)
+	return nil
+}
+
+// Close stops the watcher and releases its resources.
+func (w *RoutingWatcher) Close() error {
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return nil
+	}
+	w.closed = true
+	if w.cancel != nil {
+		w.cancel()
+	}
+	w.mu.Unlock()
+
+	err := w.fsWatcher.Close()
+	w.wg.Wait()
+	return err
+}
+
+// run reloads the configuration once the watched files have been quiet for
+// the debounce duration.
+func (w *RoutingWatcher) run(ctx context.Context, onChange func(*RoutingConfiguration, error)) {
+	defer w.wg.Done()
+
+	timer := time.NewTimer(w.debounce)
+	timer.Stop()
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case event, ok := <-w.fsWatcher.Events:
+			if !ok {
+				return
+			}
+			if slices.Contains(w.paths, filepath.Clean(event.Name)) {
+				timer.Reset(w.debounce)
+			}
+
+		case <-timer.C:
+			onChange(w.load())
+
+		case err, ok := <-w.fsWatcher.Errors:
+			if !ok {
+				return
+			}
+			onChange(nil, err)
+		}
+	}
+}
+
+// dirs returns the directories containing the watched files.
+func (w *RoutingWatcher) dirs() []string {
+	dirs := make([]string, 0, len(w.paths))
+	for _, path := range w.paths {
+		if dir := filepath.Dir(path); !slices.Contains(dirs, dir) {
+			dirs = append(dirs, dir)
+		}
+	}
+	return dirs
+}
@@ -0,0 +1,85 @@
+package config
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+type routingReload struct {
+	cfg *RoutingConfiguration
+	err error
+}
+
+func TestRoutingWatcher(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "routing.yaml")
+	write := func(content string) {
+		t.Helper()
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatalf("failed to write routing config: %v", err)
+		}
+	}
+	write("default_provider: ollama\n")
+
+	w, err := NewRoutingWatcher(20*time.Millisecond, path)
+	if err != nil {
+		t.Fatalf("NewRoutingWatcher() error = %v", err)
+	}
+	defer w.Close()
+
+	reloads := make(chan routingReload, 10)
+	if err := w.Watch(context.Background(), func(cfg *RoutingConfiguration, err error) {
+		reloads <- routingReload{cfg, err}
+	}); err != nil {
+		t.Fatalf("Watch() error = %v", err)
+	}
+
+	next := func() routingReload {
+		t.Helper()
+		select {
+		case r := <-reloads:
+			return r
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for reload")
+			return routingReload{}
+		}
+	}
+
+	// Unrelated files in the directory are ignored
+	if err := os.WriteFile(filepath.Join(dir, "config.yaml"), []byte("x: 1\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	write("default_provider: openai\nproviders:\n  openai:\n    enabled: true\n    priority: 1\n")
+	r := next()
+	if r.err != nil {
+		t.Fatalf("reload error = %v", r.err)
+	}
+	if r.cfg.DefaultProvider != "openai" {
+		t.Errorf("DefaultProvider = %q, want openai", r.cfg.DefaultProvider)
+	}
+
+	write("invalid: [yaml")
+	if r := next(); r.err == nil {
+		t.Error("expected an error reloading invalid YAML")
+	}
+}
+
+func TestRoutingWatcher_Close(t *testing.T) {
+	w, err := NewRoutingWatcher(0, filepath.Join(t.TempDir(), "routing.yaml"))
+	if err != nil {
+		t.Fatalf("NewRoutingWatcher() error = %v", err)
+	}
+	if err := w.Watch(context.Background(), func(*RoutingConfiguration, error) {}); err != nil {
+		t.Fatalf("Watch() error = %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Errorf("Close() error = %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Errorf("second Close() error = %v", err)
+	}
+}
@@ -0,0 +1,154 @@
+package config
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// SchemaDialect is the JSON Schema draft that generated schemas declare.
+const SchemaDialect = "https://json-schema.org/draft/2020-12/schema"
+
+// Schema is a JSON Schema document, or a subschema of one. Only the keywords
+// needed to describe configuration structs are supported.
+type Schema struct {
+	Dialect     string             `json:"$schema,omitempty"`
+	Title       string             `json:"title,omitempty"`
+	Description string             `json:"description,omitempty"`
+	Type        string             `json:"type,omitempty"` // empty allows any value
+	Properties  map[string]*Schema `json:"properties,omitempty"`
+	// AdditionalProperties is the schema of map values; nil on an object
+	// with properties means no other keys are allowed.
+	AdditionalProperties *Schema   `json:"-"`
+	Items                *Schema   `json:"items,omitempty"`
+	OneOf                []*Schema `json:"oneOf,omitempty"`
+}
+
+// MarshalJSON encodes additionalProperties as false on objects that only
+// allow their properties.
+func (s *Schema) MarshalJSON() ([]byte, error) {
+	type plain Schema
+	out := struct {
+		*plain
+		AdditionalProperties any `json:"additionalProperties,omitempty"`
+	}{plain: (*plain)(s)}
+	switch {
+	case s.AdditionalProperties != nil:
+		out.AdditionalProperties = s.AdditionalProperties
+	case s.Type == "object":
+		out.AdditionalProperties = false
+	}
+	return json.Marshal(out)
+}
+
+// SchemaProvider is implemented by types that unmarshal YAML themselves, to
+// describe the values they accept.
+type SchemaProvider interface {
+	JSONSchema() *Schema
+}
+
+var (
+	schemaProviderType = reflect.TypeFor[SchemaProvider]()
+	schemaCache        sync.Map // reflect.Type -> *Schema
+)
+
+// SchemaFor returns the JSON Schema of the YAML form of v's type, derived
+// from its yaml struct tags. The result is shared and must not be modified.
+func SchemaFor(v any) *Schema {
+	t := reflect.TypeOf(v)
+	if cached, ok := schemaCache.Load(t); ok {
+		return cached.(*Schema)
+	}
+	s := typeSchema(t)
+	schemaCache.Store(t, s)
+	return s
+}
+
+// NewSchemaDocument returns a top-level schema document for v's type.
+func NewSchemaDocument(v any, title string) *Schema {
+	doc := *SchemaFor(v)
+	doc.Dialect = SchemaDialect
+	doc.Title = title
+	return &doc
+}
+
+// RoutingSchema returns the schema document of routing.yaml.
+func RoutingSchema() *Schema {
+	return NewSchemaDocument(RoutingConfiguration{}, "skillrunner routing configuration")
+}
+
+// ProjectSchema returns the schema document of .skillrunner.yaml, which
+// holds project settings as well as routing keys.
+func ProjectSchema() *Schema {
+	doc := NewSchemaDocument(ProjectConfig{}, "skillrunner project configuration")
+	doc.Properties = make(map[string]*Schema)
+	for name, s := range SchemaFor(ProjectConfig{}).Properties {
+		doc.Properties[name] = s
+	}
+	for name, s := range SchemaFor(RoutingConfiguration{}).Properties {
+		doc.Properties[name] = s
+	}
+	return doc
+}
+
+func typeSchema(t reflect.Type) *Schema {
+	if t.Implements(schemaProviderType) {
+		return reflect.Zero(t).Interface().(SchemaProvider).JSONSchema()
+	}
+	if reflect.PointerTo(t).Implements(schemaProviderType) {
+		return reflect.New(t).Interface().(SchemaProvider).JSONSchema()
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		return typeSchema(t.Elem())
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.Slice, reflect.Array:
+		return &Schema{Type: "array", Items: typeSchema(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: typeSchema(t.Elem())}
+	case reflect.Struct:
+		s := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+		addStructProperties(s, t)
+		return s
+	default:
+		// interface{} and anything else: any value
+		return &Schema{}
+	}
+}
+
+// addStructProperties adds the yaml fields of struct type t to s, including
+// those of inlined structs.
+func addStructProperties(s *Schema, t reflect.Type) {
+	for i := range t.NumField() {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		name, opts, _ := strings.Cut(f.Tag.Get("yaml"), ",")
+		if name == "-" {
+			continue
+		}
+		if strings.Contains(opts, "inline") {
+			ft := f.Type
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			addStructProperties(s, ft)
+			continue
+		}
+		if name == "" {
+			name = strings.ToLower(f.Name)
+		}
+		s.Properties[name] = typeSchema(f.Type)
+	}
+}
@@ -0,0 +1,121 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestRoutingSchema(t *testing.T) {
+	data, err := json.Marshal(RoutingSchema())
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+
+	var doc struct {
+		Schema     string `json:"$schema"`
+		Type       string `json:"type"`
+		Additional any    `json:"additionalProperties"`
+		Properties map[string]struct {
+			Type       string `json:"type"`
+			Additional any    `json:"additionalProperties"`
+		} `json:"properties"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+
+	if doc.Schema != SchemaDialect || doc.Type != "object" {
+		t.Errorf("$schema = %q, type = %q", doc.Schema, doc.Type)
+	}
+	if doc.Additional != false {
+		t.Errorf("additionalProperties = %v, want false", doc.Additional)
+	}
+	providers, ok := doc.Properties["providers"]
+	if !ok || providers.Type != "object" {
+		t.Fatalf("providers = %+v, want an object", providers)
+	}
+	if _, ok := providers.Additional.(map[string]any); !ok {
+		t.Errorf("providers.additionalProperties = %v, want the provider schema", providers.Additional)
+	}
+}
+
+func TestProjectSchema(t *testing.T) {
+	props := ProjectSchema().Properties
+	for _, name := range []string{"default_skill", "budget", "providers", "fallback_chain"} {
+		if props[name] == nil {
+			t.Errorf("project schema has no %s property", name)
+		}
+	}
+	if RoutingSchema().Properties["default_skill"] != nil {
+		t.Error("routing schema should not have project properties")
+	}
+}
+
+func TestUnmarshalYAMLWithSchema(t *testing.T) {
+	t.Setenv("SR_TEST_TIMEOUT", "45")
+
+	data := []byte(`providers:
+  ollama:
+    enabled: true
+    timeout: ${SR_TEST_TIMEOUT}
+    priorty: 1
+    models:
+      llama3:
+        tier: cheap
+        max_tokens: many
+fallback_chain: ollama
+`)
+
+	var cfg RoutingConfiguration
+	err := UnmarshalYAMLWithSchema(data, &cfg, RoutingSchema())
+
+	var schemaErr *SchemaValidationError
+	if !errors.As(err, &schemaErr) {
+		t.Fatalf("error = %v, want *SchemaValidationError", err)
+	}
+	want := []string{
+		"line 5, column 5: providers.ollama.priorty: unknown field; did you mean priority?",
+		`line 9, column 21: providers.ollama.models.llama3.max_tokens: expected an integer, got "many"`,
+		`line 10, column 17: fallback_chain: expected a list, got "ollama"`,
+	}
+	if len(schemaErr.Errors) != len(want) {
+		t.Fatalf("errors = %v, want %d", err, len(want))
+	}
+	for i, w := range want {
+		if got := schemaErr.Errors[i].Error(); got != w {
+			t.Errorf("errors[%d] = %q, want %q", i, got, w)
+		}
+	}
+}
+
+func TestUnmarshalYAMLWithSchema_Valid(t *testing.T) {
+	t.Setenv("SR_TEST_TIMEOUT", "45")
+
+	data := []byte(`providers:
+  ollama:
+    enabled: true
+    timeout: ${SR_TEST_TIMEOUT}
+    api_key_env:
+profiles:
+  cheap:
+    generation_model: llama3
+    max_context_tokens: 4096.0
+`)
+
+	var cfg RoutingConfiguration
+	if err := UnmarshalYAMLWithSchema(data, &cfg, RoutingSchema()); err != nil {
+		t.Fatalf("UnmarshalYAMLWithSchema() error = %v", err)
+	}
+	if cfg.Providers["ollama"].Timeout != 45 {
+		t.Errorf("timeout = %d, want 45", cfg.Providers["ollama"].Timeout)
+	}
+}
+
+func TestLoadRoutingConfigFromBytes_SchemaError(t *testing.T) {
+	_, err := LoadRoutingConfigFromBytes([]byte("default_provider: ollama\nfallback_chian: [ollama]\n"))
+	if err == nil || !strings.Contains(err.Error(), "line 2, column 1: fallback_chian: unknown field; did you mean fallback_chain?") {
+		t.Errorf("error = %v, want the unknown field with its position", err)
+	}
+}
@@ -0,0 +1,293 @@
+package config
+
+import (
+	"fmt"
+	"maps"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// SchemaError is a value of a YAML document that does not match its schema.
+type SchemaError struct {
+	Line    int
+	Column  int
+	Path    string // dotted path of the value, e.g. providers.ollama.timeout
+	Message string
+}
+
+func (e *SchemaError) Error() string {
+	if e.Path == "" {
+		return fmt.Sprintf("line %d, column %d: %s", e.Line, e.Column, e.Message)
+	}
+	return fmt.Sprintf("line %d, column %d: %s: %s", e.Line, e.Column, e.Path, e.Message)
+}
+
+// SchemaValidationError lists every value of a document that does not match
+// its schema, in document order.
+type SchemaValidationError struct {
+	Errors []*SchemaError
+}
+
+func (e *SchemaValidationError) Error() string {
+	if len(e.Errors) == 1 {
+		return e.Errors[0].Error()
+	}
+	lines := make([]string, len(e.Errors))
+	for i, err := range e.Errors {
+		lines[i] = "  " + err.Error()
+	}
+	return fmt.Sprintf("%d schema errors:\n%s", len(e.Errors), strings.Join(lines, "\n"))
+}
+
+// ValidateNode checks a parsed YAML document against a schema. It returns a
+// *SchemaValidationError listing the mismatches, or nil.
+func ValidateNode(node *yaml.Node, s *Schema) error {
+	v := &nodeValidator{}
+	if node.Kind == yaml.DocumentNode {
+		if len(node.Content) == 0 {
+			return nil
+		}
+		node = node.Content[0]
+	}
+	v.validate(node, s, "")
+	if len(v.errs) == 0 {
+		return nil
+	}
+	return &SchemaValidationError{Errors: v.errs}
+}
+
+// UnmarshalYAMLWithSchema parses YAML like UnmarshalYAMLWithEnv, and checks
+// the document against s before decoding it into v, so that unknown keys
+// and values of the wrong type are reported with their line and column.
+func UnmarshalYAMLWithSchema(data []byte, v any, s *Schema) error {
+	doc, err := parseYAMLWithEnv(data)
+	if err != nil || doc == nil {
+		return err
+	}
+	if err := ValidateNode(doc, s); err != nil {
+		return err
+	}
+	return doc.Decode(v)
+}
+
+// UnmarshalYAMLWithSchemaKeys parses YAML like UnmarshalYAMLWithSchema, but
+// expands environment variable references only in the values of the
+// mapping keys named in envKeys, at any depth. Other text, such as a
+// skill's prompts, is kept as written, so that it can contain ${...} and
+// cannot pull environment values into what is sent to a model.
+func UnmarshalYAMLWithSchemaKeys(data []byte, v any, s *Schema, envKeys ...string) error {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil || doc.Kind == 0 {
+		return err
+	}
+	if err := expandKeysEnv(&doc, envKeys); err != nil {
+		return err
+	}
+	if err := ValidateNode(&doc, s); err != nil {
+		return err
+	}
+	return doc.Decode(v)
+}
+
+type nodeValidator struct {
+	errs []*SchemaError
+}
+
+func (v *nodeValidator) fail(n *yaml.Node, path, format string, args ...any) {
+	v.errs = append(v.errs, &SchemaError{
+		Line:    n.Line,
+		Column:  n.Column,
+		Path:    path,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+func (v *nodeValidator) validate(n *yaml.Node, s *Schema, path string) {
+	if n.Kind == yaml.AliasNode && n.Alias != nil {
+		n = n.Alias
+	}
+	if n.Kind == yaml.ScalarNode && n.ShortTag() == "!!null" {
+		// Decodes to the zero value
+		return
+	}
+
+	if len(s.OneOf) > 0 {
+		// Check the value against the alternative of its kind, whose errors
+		// say more than a mismatch with every alternative
+		types := make([]string, len(s.OneOf))
+		for i, alt := range s.OneOf {
+			if kindMatches(n, alt.Type) {
+				v.validate(n, alt, path)
+				return
+			}
+			types[i] = schemaTypeName(alt.Type)
+		}
+		v.fail(n, path, "expected %s, got %s", strings.Join(types, " or "), describeNode(n))
+		return
+	}
+
+	switch s.Type {
+	case "":
+		// Any value
+	case "object":
+		v.validateObject(n, s, path)
+	case "array":
+		if n.Kind != yaml.SequenceNode {
+			v.fail(n, path, "expected a list, got %s", describeNode(n))
+			return
+		}
+		for i, item := range n.Content {
+			v.validate(item, s.Items, fmt.Sprintf("%s[%d]", path, i))
+		}
+	default:
+		if !scalarMatches(n, s.Type) {
+			v.fail(n, path, "expected %s, got %s", schemaTypeName(s.Type), describeNode(n))
+		}
+	}
+}
+
+func (v *nodeValidator) validateObject(n *yaml.Node, s *Schema, path string) {
+	if n.Kind != yaml.MappingNode {
+		v.fail(n, path, "expected a mapping, got %s", describeNode(n))
+		return
+	}
+
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		key, value := n.Content[i], n.Content[i+1]
+		if key.Value == "<<" {
+			// Merge keys are checked where the anchor is defined
+			continue
+		}
+		childPath := key.Value
+		if path != "" {
+			childPath = path + "." + key.Value
+		}
+
+		if prop, ok := s.Properties[key.Value]; ok {
+			v.validate(value, prop, childPath)
+			continue
+		}
+		if s.AdditionalProperties != nil {
+			v.validate(value, s.AdditionalProperties, childPath)
+			continue
+		}
+
+		if suggestion := closestKey(key.Value, slices.Collect(maps.Keys(s.Properties))); suggestion != "" {
+			v.fail(key, childPath, "unknown field; did you mean %s?", suggestion)
+		} else {
+			v.fail(key, childPath, "unknown field")
+		}
+	}
+}
+
+// kindMatches reports whether n is a value of the JSON Schema type, without
+// checking its contents.
+func kindMatches(n *yaml.Node, typ string) bool {
+	switch typ {
+	case "":
+		return true
+	case "object":
+		return n.Kind == yaml.MappingNode
+	case "array":
+		return n.Kind == yaml.SequenceNode
+	default:
+		return scalarMatches(n, typ)
+	}
+}
+
+// scalarMatches reports whether n decodes into a value of the JSON Schema type.
+func scalarMatches(n *yaml.Node, typ string) bool {
+	if n.Kind != yaml.ScalarNode {
+		return false
+	}
+	tag := n.ShortTag()
+	switch typ {
+	case "string":
+		// Any scalar decodes into a string field
+		return true
+	case "boolean":
+		return tag == "!!bool"
+	case "integer":
+		if tag == "!!int" {
+			return true
+		}
+		if tag == "!!float" {
+			f, err := strconv.ParseFloat(n.Value, 64)
+			return err == nil && f == math.Trunc(f)
+		}
+		return false
+	case "number":
+		return tag == "!!int" || tag == "!!float"
+	default:
+		return true
+	}
+}
+
+// describeNode describes a YAML value for error messages.
+func describeNode(n *yaml.Node) string {
+	switch n.Kind {
+	case yaml.MappingNode:
+		return "a mapping"
+	case yaml.SequenceNode:
+		return "a list"
+	}
+	switch n.ShortTag() {
+	case "!!bool":
+		return "boolean " + n.Value
+	case "!!int", "!!float":
+		return "number " + n.Value
+	default:
+		return strconv.Quote(n.Value)
+	}
+}
+
+// schemaTypeName names a JSON Schema type for error messages.
+func schemaTypeName(typ string) string {
+	switch typ {
+	case "integer":
+		return "an integer"
+	case "object":
+		return "a mapping"
+	case "array":
+		return "a list"
+	default:
+		return "a " + typ
+	}
+}
+
+// closestKey returns the known key within two edits of key, or "".
+func closestKey(key string, known []string) string {
+	slices.Sort(known)
+	best, bestDist := "", 3
+	for _, k := range known {
+		if d := EditDistance(key, k); d < bestDist {
+			best, bestDist = k, d
+		}
+	}
+	return best
+}
+
+// EditDistance returns the Levenshtein distance between a and b.
+func EditDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)]
+}
@@ -0,0 +1,165 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"time"
+)
+
+// Defaults of sr serve.
+const (
+	DefaultServeAddr         = "127.0.0.1:8765"
+	DefaultServeDrainTimeout = 30 * time.Second
+	DefaultServeMaxRuns      = 4
+	DefaultServeMaxQueued    = 16
+)
+
+// Readiness levels of sr serve: what /readyz checks before reporting ready.
+const (
+	// ReadinessBasic checks that providers are registered and storage is
+	// reachable, without calling the providers.
+	ReadinessBasic = "basic"
+
+	// ReadinessStandard also checks that at least one routing profile has
+	// its models served by responding providers.
+	ReadinessStandard = "standard"
+
+	// ReadinessStrict checks that every enabled provider responds and every
+	// routing profile has its models served.
+	ReadinessStrict = "strict"
+)
+
+// ServeConfig configures sr serve, which runs skills for HTTP clients.
+type ServeConfig struct {
+	// Addr is the address to listen on (default: 127.0.0.1:8765).
+	Addr string `yaml:"addr,omitempty"`
+
+	// DrainTimeout is how long in-flight runs may keep running after a
+	// shutdown signal before they are interrupted and checkpointed
+	// (default: 30s).
+	DrainTimeout time.Duration `yaml:"drain_timeout,omitempty"`
+
+	// Readiness is what /readyz checks: basic, standard or strict
+	// (default: standard).
+	Readiness string `yaml:"readiness,omitempty"`
+
+	// MaxConcurrentRuns is how many runs execute at once (default: 4).
+	// Further runs wait in a queue.
+	MaxConcurrentRuns int `yaml:"max_concurrent_runs,omitempty"`
+
+	// MaxQueuedRuns is how many runs may wait for a slot (default: 16).
+	// Runs beyond it are refused with 429 Too Many Requests.
+	MaxQueuedRuns int `yaml:"max_queued_runs,omitempty"`
+
+	// Clients are the API clients allowed to use the server. When set,
+	// every request but the health probes must carry the bearer token of a
+	// client, and clients sharing a rate-limited provider get fair shares
+	// of its limits. Without clients the API is open and every request
+	// counts as one anonymous client.
+	Clients []ServeClient `yaml:"clients,omitempty"`
+}
+
+// ServeClient is an API client of sr serve.
+type ServeClient struct {
+	// Name identifies the client in logs and usage reports.
+	Name string `yaml:"name"`
+
+	// Token is the client's bearer token.
+	Token string `yaml:"token,omitempty"`
+
+	// TokenEnv names the environment variable holding the client's bearer
+	// token. It takes precedence over Token.
+	TokenEnv string `yaml:"token_env,omitempty"`
+
+	// Weight is the client's share of a provider's rate limits relative to
+	// the other clients waiting for it (default: 1).
+	Weight float64 `yaml:"weight,omitempty"`
+
+	// Admin allows the client to use the admin API.
+	Admin bool `yaml:"admin,omitempty"`
+}
+
+// BearerToken returns the client's bearer token, from TokenEnv if set.
+func (c ServeClient) BearerToken() string {
+	if c.TokenEnv != "" {
+		return os.Getenv(c.TokenEnv)
+	}
+	return c.Token
+}
+
+// ListenAddr returns the address to listen on.
+func (s ServeConfig) ListenAddr() string {
+	if s.Addr == "" {
+		return DefaultServeAddr
+	}
+	return s.Addr
+}
+
+// Drain returns the drain timeout.
+func (s ServeConfig) Drain() time.Duration {
+	if s.DrainTimeout == 0 {
+		return DefaultServeDrainTimeout
+	}
+	return s.DrainTimeout
+}
+
+// ReadinessLevel returns the readiness level.
+func (s ServeConfig) ReadinessLevel() string {
+	if s.Readiness == "" {
+		return ReadinessStandard
+	}
+	return s.Readiness
+}
+
+// MaxRuns returns how many runs execute at once.
+func (s ServeConfig) MaxRuns() int {
+	if s.MaxConcurrentRuns == 0 {
+		return DefaultServeMaxRuns
+	}
+	return s.MaxConcurrentRuns
+}
+
+// MaxQueued returns how many runs may wait for a slot.
+func (s ServeConfig) MaxQueued() int {
+	if s.MaxQueuedRuns == 0 {
+		return DefaultServeMaxQueued
+	}
+	return s.MaxQueuedRuns
+}
+
+// Validate checks the serve settings.
+func (s *ServeConfig) Validate() error {
+	if s.Addr != "" {
+		if _, _, err := net.SplitHostPort(s.Addr); err != nil {
+			return fmt.Errorf("addr %q: %w", s.Addr, err)
+		}
+	}
+	if s.DrainTimeout < 0 {
+		return errors.New("drain_timeout must be non-negative")
+	}
+	if s.MaxConcurrentRuns < 0 || s.MaxQueuedRuns < 0 {
+		return errors.New("max_concurrent_runs and max_queued_runs must be non-negative")
+	}
+	switch s.Readiness {
+	case "", ReadinessBasic, ReadinessStandard, ReadinessStrict:
+	default:
+		return fmt.Errorf("invalid readiness %q: must be basic, standard or strict", s.Readiness)
+	}
+	names := make(map[string]bool, len(s.Clients))
+	for i, c := range s.Clients {
+		switch {
+		case c.Name == "":
+			return fmt.Errorf("clients[%d]: name is required", i)
+		case names[c.Name]:
+			return fmt.Errorf("clients[%d]: duplicate client %q", i, c.Name)
+		case c.Token == "" && c.TokenEnv == "":
+			return fmt.Errorf("client %q: token or token_env is required", c.Name)
+		case c.Weight < 0:
+			return fmt.Errorf("client %q: weight must be non-negative", c.Name)
+		}
+		names[c.Name] = true
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+package config
+
+import (
+	"testing"
+	"time"
+)
+
+func TestServeConfig(t *testing.T) {
+	var defaults ServeConfig
+	if defaults.ListenAddr() != DefaultServeAddr || defaults.Drain() != DefaultServeDrainTimeout || defaults.ReadinessLevel() != ReadinessStandard {
+		t.Errorf("ListenAddr(), Drain(), ReadinessLevel() = %q, %s, %q; want the defaults", defaults.ListenAddr(), defaults.Drain(), defaults.ReadinessLevel())
+	}
+	if err := (&ServeConfig{Addr: "localhost"}).Validate(); err == nil {
+		t.Error("an address without a port should be rejected")
+	}
+	if err := (&ServeConfig{DrainTimeout: -time.Second}).Validate(); err == nil {
+		t.Error("a negative drain_timeout should be rejected")
+	}
+	if defaults.MaxRuns() != DefaultServeMaxRuns || defaults.MaxQueued() != DefaultServeMaxQueued {
+		t.Errorf("MaxRuns(), MaxQueued() = %d, %d; want the defaults", defaults.MaxRuns(), defaults.MaxQueued())
+	}
+	if err := (&ServeConfig{MaxQueuedRuns: -1}).Validate(); err == nil {
+		t.Error("a negative max_queued_runs should be rejected")
+	}
+	if err := (&ServeConfig{Readiness: "paranoid"}).Validate(); err == nil {
+		t.Error("an unknown readiness level should be rejected")
+	}
+	if err := (&ServeConfig{Addr: ":9000", DrainTimeout: time.Minute, Readiness: ReadinessStrict}).Validate(); err != nil {
+		t.Errorf("Validate() error = %v", err)
+	}
+}
+
+func TestServeConfig_Clients(t *testing.T) {
+	for _, clients := range [][]ServeClient{
+		{{Token: "t"}},
+		{{Name: "ci"}},
+		{{Name: "ci", Token: "t"}, {Name: "ci", Token: "u"}},
+		{{Name: "ci", Token: "t", Weight: -1}},
+	} {
+		if err := (&ServeConfig{Clients: clients}).Validate(); err == nil {
+			t.Errorf("Validate() accepted clients %+v", clients)
+		}
+	}
+
+	t.Setenv("SR_TEST_CLIENT_TOKEN", "from-env")
+	cfg := ServeConfig{Clients: []ServeClient{
+		{Name: "ci", Token: "inline", Weight: 2},
+		{Name: "ops", Token: "inline", TokenEnv: "SR_TEST_CLIENT_TOKEN", Admin: true},
+	}}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+	if got := cfg.Clients[0].BearerToken(); got != "inline" {
+		t.Errorf("BearerToken() = %q, want the inline token", got)
+	}
+	if got := cfg.Clients[1].BearerToken(); got != "from-env" {
+		t.Errorf("BearerToken() = %q, want token_env to take precedence", got)
+	}
+}
@@ -0,0 +1,64 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ApplyEnv overlays the standard OpenTelemetry variables in environ, given
+// as by os.Environ, on the tracing configuration:
+//
+//	OTEL_SDK_DISABLED=true                          disables tracing
+//	OTEL_TRACES_EXPORTER=otlp|console|none          selects the exporter
+//	OTEL_EXPORTER_OTLP_ENDPOINT=http://host:4318    enables OTLP export to /v1/traces
+//	OTEL_EXPORTER_OTLP_TRACES_ENDPOINT=<url>        enables OTLP export to the URL as is
+//	OTEL_SERVICE_NAME=skillrunner-ci                sets service_name
+//	OTEL_TRACES_SAMPLER_ARG=0.25                    sets sample_rate
+//
+// An endpoint enables tracing with the otlp exporter unless
+// OTEL_TRACES_EXPORTER says otherwise. Headers and TLS settings are read by
+// the exporter itself from OTEL_EXPORTER_OTLP_HEADERS and friends.
+func (t *TracingConfig) ApplyEnv(environ []string) error {
+	env := make(map[string]string)
+	for _, entry := range environ {
+		if name, value, ok := strings.Cut(entry, "="); ok && value != "" && strings.HasPrefix(name, "OTEL_") {
+			env[name] = value
+		}
+	}
+
+	endpoint := env["OTEL_EXPORTER_OTLP_TRACES_ENDPOINT"]
+	if base := env["OTEL_EXPORTER_OTLP_ENDPOINT"]; endpoint == "" && base != "" {
+		endpoint = strings.TrimSuffix(base, "/") + "/v1/traces"
+	}
+	if endpoint != "" {
+		t.Enabled, t.ExporterType, t.OTLPEndpoint = true, "otlp", endpoint
+	}
+
+	switch exporter := env["OTEL_TRACES_EXPORTER"]; exporter {
+	case "":
+	case "otlp":
+		t.Enabled, t.ExporterType = true, "otlp"
+	case "console":
+		t.Enabled, t.ExporterType = true, "stdout"
+	case "none":
+		t.Enabled, t.ExporterType = false, "none"
+	default:
+		return fmt.Errorf("OTEL_TRACES_EXPORTER: unsupported exporter %q; use otlp, console or none", exporter)
+	}
+
+	if name := env["OTEL_SERVICE_NAME"]; name != "" {
+		t.ServiceName = name
+	}
+	if arg := env["OTEL_TRACES_SAMPLER_ARG"]; arg != "" {
+		rate, err := strconv.ParseFloat(arg, 64)
+		if err != nil || rate < 0 || rate > 1 {
+			return fmt.Errorf("OTEL_TRACES_SAMPLER_ARG: expected a sample rate between 0 and 1, got %q", arg)
+		}
+		t.SampleRate = rate
+	}
+	if strings.EqualFold(env["OTEL_SDK_DISABLED"], "true") {
+		t.Enabled = false
+	}
+	return nil
+}
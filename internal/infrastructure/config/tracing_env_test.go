@@ -0,0 +1,44 @@
+package config
+
+import "testing"
+
+func TestTracingConfig_ApplyEnv(t *testing.T) {
+	tc := TracingConfig{ExporterType: "none", SampleRate: 1, ServiceName: "skillrunner"}
+	err := tc.ApplyEnv([]string{
+		"OTEL_EXPORTER_OTLP_ENDPOINT=https://otel.example.com/",
+		"OTEL_SERVICE_NAME=skillrunner-ci",
+		"OTEL_TRACES_SAMPLER_ARG=0.25",
+		"HOME=/root",
+	})
+	if err != nil {
+		t.Fatalf("ApplyEnv() error = %v", err)
+	}
+	if !tc.Enabled || tc.ExporterType != "otlp" || tc.OTLPEndpoint != "https://otel.example.com/v1/traces" {
+		t.Errorf("ApplyEnv() = %+v, want OTLP export to the traces path", tc)
+	}
+	if tc.ServiceName != "skillrunner-ci" || tc.SampleRate != 0.25 {
+		t.Errorf("ApplyEnv() = %+v, want the service name and sample rate", tc)
+	}
+	if err := tc.Validate(); err != nil {
+		t.Errorf("Validate() error = %v", err)
+	}
+
+	tc = TracingConfig{Enabled: true, ExporterType: "stdout"}
+	if err := tc.ApplyEnv([]string{"OTEL_EXPORTER_OTLP_TRACES_ENDPOINT=http://collector:4318/otlp", "OTEL_SDK_DISABLED=true"}); err != nil {
+		t.Fatalf("ApplyEnv() error = %v", err)
+	}
+	if tc.Enabled || tc.OTLPEndpoint != "http://collector:4318/otlp" {
+		t.Errorf("ApplyEnv() = %+v, want the endpoint as is with tracing disabled", tc)
+	}
+
+	tc = TracingConfig{}
+	if err := tc.ApplyEnv([]string{"OTEL_TRACES_EXPORTER=console"}); err != nil || !tc.Enabled || tc.ExporterType != "stdout" {
+		t.Errorf("ApplyEnv(console) = %+v, %v; want the stdout exporter", tc, err)
+	}
+	if err := tc.ApplyEnv([]string{"OTEL_TRACES_EXPORTER=zipkin"}); err == nil {
+		t.Error("expected an error for an unsupported exporter")
+	}
+	if err := tc.ApplyEnv([]string{"OTEL_TRACES_SAMPLER_ARG=2"}); err == nil {
+		t.Error("expected an error for a sample rate above 1")
+	}
+}
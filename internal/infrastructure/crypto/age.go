@@ -0,0 +1,123 @@
+package crypto
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// AgeArmorHeader starts an ASCII-armored age ciphertext.
+const AgeArmorHeader = "-----BEGIN AGE ENCRYPTED FILE-----"
+
+// ErrToolUnavailable is returned when the age or sops command is not installed.
+var ErrToolUnavailable = errors.New("encryption tool not available")
+
+// ErrNoRecipients is returned when there is no age recipient to encrypt to.
+var ErrNoRecipients = errors.New("no age recipients")
+
+// toolTimeout bounds each age or sops command.
+const toolTimeout = 30 * time.Second
+
+// runTool runs an encryption command with stdin as its input and returns its
+// output; tests replace it.
+var runTool = func(ctx context.Context, stdin, name string, args ...string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, toolTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Stdin = strings.NewReader(stdin)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if errors.Is(err, exec.ErrNotFound) {
+			return "", fmt.Errorf("%w: %s is not installed", ErrToolUnavailable, name)
+		}
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("%s: %s", name, msg)
+		}
+		return "", fmt.Errorf("%s: %w", name, err)
+	}
+	return stdout.String(), nil
+}
+
+// IsAgeEncrypted reports whether s is an ASCII-armored age ciphertext.
+func IsAgeEncrypted(s string) bool {
+	return strings.HasPrefix(strings.TrimSpace(s), AgeArmorHeader)
+}
+
+// AgeIdentityFile returns the age identity file used for decryption: the
+// file named by SOPS_AGE_KEY_FILE, or sops's default keys.txt in the user
+// configuration directory, so that age values and sops files share a key.
+func AgeIdentityFile() string {
+	if path := os.Getenv("SOPS_AGE_KEY_FILE"); path != "" {
+		return path
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "sops", "age", "keys.txt")
+}
+
+// AgeRecipients returns the public keys noted in an age identity file
+// ("# public key: age1...") as written by age-keygen.
+func AgeRecipients(identityFile string) ([]string, error) {
+	data, err := os.ReadFile(identityFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read age identity file: %w", err)
+	}
+
+	var recipients []string
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		if key, ok := strings.CutPrefix(strings.TrimSpace(scanner.Text()), "# public key:"); ok {
+			recipients = append(recipients, strings.TrimSpace(key))
+		}
+	}
+	if len(recipients) == 0 {
+		return nil, fmt.Errorf("%w: %s has no public key comments", ErrNoRecipients, identityFile)
+	}
+	return recipients, nil
+}
+
+// AgeEncrypt encrypts plaintext to the recipients and returns the
+// ASCII-armored ciphertext.
+func AgeEncrypt(ctx context.Context, plaintext string, recipients []string) (string, error) {
+	if len(recipients) == 0 {
+		return "", ErrNoRecipients
+	}
+
+	args := []string{"--encrypt", "--armor"}
+	for _, r := range recipients {
+		args = append(args, "--recipient", r)
+	}
+	out, err := runTool(ctx, plaintext, "age", args...)
+	if err != nil {
+		return "", fmt.Errorf("failed to encrypt with age: %w", err)
+	}
+	return strings.TrimSpace(out) + "\n", nil
+}
+
+// AgeDecrypt decrypts an ASCII-armored age ciphertext with the identity
+// file returned by AgeIdentityFile.
+func AgeDecrypt(ctx context.Context, ciphertext string) (string, error) {
+	identity := AgeIdentityFile()
+	if identity == "" {
+		return "", errors.New("no age identity file: set SOPS_AGE_KEY_FILE")
+	}
+
+	out, err := runTool(ctx, strings.TrimSpace(ciphertext)+"\n", "age", "--decrypt", "--identity", identity)
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt with age: %w", err)
+	}
+	return strings.TrimRight(out, "\r\n"), nil
+}
@@ -0,0 +1,111 @@
+package crypto
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// fakeTool records the encryption commands run and answers them.
+type fakeTool struct {
+	calls [][]string
+	stdin []string
+	out   string
+}
+
+func stubTool(t *testing.T, out string) *fakeTool {
+	t.Helper()
+	f := &fakeTool{out: out}
+	orig := runTool
+	t.Cleanup(func() { runTool = orig })
+	runTool = func(_ context.Context, stdin, name string, args ...string) (string, error) {
+		f.calls = append(f.calls, append([]string{name}, args...))
+		f.stdin = append(f.stdin, stdin)
+		return f.out, nil
+	}
+	return f
+}
+
+const testCiphertext = AgeArmorHeader + "\nYWdlLWVuY3J5cHRpb24ub3JnL3YxCi0+IFgyNTUxOSBhYmMK\n-----END AGE ENCRYPTED FILE-----\n"
+
+func TestAge(t *testing.T) {
+	ctx := context.Background()
+
+	f := stubTool(t, testCiphertext)
+	got, err := AgeEncrypt(ctx, "sk-1", []string{"age1alice", "age1bob"})
+	if err != nil {
+		t.Fatalf("AgeEncrypt() error = %v", err)
+	}
+	if !IsAgeEncrypted(got) {
+		t.Errorf("AgeEncrypt() = %q, want an armored ciphertext", got)
+	}
+	want := []string{"age", "--encrypt", "--armor", "--recipient", "age1alice", "--recipient", "age1bob"}
+	if !slices.Equal(f.calls[0], want) || f.stdin[0] != "sk-1" {
+		t.Errorf("AgeEncrypt() ran %v with %q, want %v with the key on stdin", f.calls[0], f.stdin[0], want)
+	}
+	if _, err := AgeEncrypt(ctx, "sk-1", nil); err == nil {
+		t.Error("AgeEncrypt() without recipients should fail")
+	}
+
+	t.Setenv("SOPS_AGE_KEY_FILE", "/keys/age.txt")
+	f.out = "sk-1\n"
+	if got, err := AgeDecrypt(ctx, testCiphertext); err != nil || got != "sk-1" {
+		t.Errorf("AgeDecrypt() = %q, %v, want sk-1", got, err)
+	}
+	want = []string{"age", "--decrypt", "--identity", "/keys/age.txt"}
+	if !slices.Equal(f.calls[1], want) {
+		t.Errorf("AgeDecrypt() ran %v, want %v", f.calls[1], want)
+	}
+
+	if IsAgeEncrypted("sk-plain") {
+		t.Error("IsAgeEncrypted() should reject a plain key")
+	}
+}
+
+func TestAgeRecipients(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "keys.txt")
+	identity := "# created: 2026-01-01T00:00:00Z\n# public key: age1alice\nAGE-SECRET-KEY-1ABC\n"
+	if err := os.WriteFile(path, []byte(identity), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := AgeRecipients(path)
+	if err != nil || !slices.Equal(got, []string{"age1alice"}) {
+		t.Errorf("AgeRecipients() = %v, %v, want [age1alice]", got, err)
+	}
+
+	if err := os.WriteFile(path, []byte("AGE-SECRET-KEY-1ABC\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := AgeRecipients(path); err == nil {
+		t.Error("AgeRecipients() without public key comments should fail")
+	}
+}
+
+func TestSops(t *testing.T) {
+	doc := []byte("providers:\n  gateway:\n    api_key: ENC[AES256_GCM,data:abc,type:str]\nsops:\n  age: []\n")
+	if !IsSopsDocument(doc) {
+		t.Error("IsSopsDocument() = false for a sops document")
+	}
+	if IsSopsDocument([]byte("providers:\n  sops:\n    enabled: true\n")) {
+		t.Error("IsSopsDocument() = true for a nested sops key")
+	}
+
+	f := stubTool(t, "providers:\n  gateway:\n    api_key: sk-1\n")
+	got, err := SopsDecrypt(context.Background(), doc)
+	if err != nil {
+		t.Fatalf("SopsDecrypt() error = %v", err)
+	}
+	if !strings.Contains(string(got), "api_key: sk-1") {
+		t.Errorf("SopsDecrypt() = %q", got)
+	}
+	if f.calls[0][0] != "sops" || f.calls[0][1] != "--decrypt" {
+		t.Errorf("SopsDecrypt() ran %v", f.calls[0])
+	}
+	if _, err := os.Stat(f.calls[0][len(f.calls[0])-1]); !os.IsNotExist(err) {
+		t.Error("SopsDecrypt() should remove its temporary file")
+	}
+}
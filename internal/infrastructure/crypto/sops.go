@@ -0,0 +1,43 @@
+package crypto
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"regexp"
+)
+
+// sopsMetadata matches the top-level sops key that sops adds to the YAML
+// files it encrypts.
+var sopsMetadata = regexp.MustCompile(`(?m)^sops:\s*$`)
+
+// IsSopsDocument reports whether data is a YAML document encrypted by sops.
+func IsSopsDocument(data []byte) bool {
+	return sopsMetadata.Match(data)
+}
+
+// SopsDecrypt decrypts a sops-encrypted YAML document with the sops
+// command, which finds the keys (age, PGP or a cloud KMS) as configured for
+// sops itself.
+func SopsDecrypt(ctx context.Context, data []byte) ([]byte, error) {
+	// sops reads the document from a file
+	f, err := os.CreateTemp("", "skillrunner-sops-*.yaml")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	defer os.Remove(f.Name())
+
+	_, err = f.Write(data)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to write temporary file: %w", err)
+	}
+
+	out, err := runTool(ctx, "", "sops", "--decrypt", "--input-type", "yaml", "--output-type", "yaml", f.Name())
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt with sops: %w", err)
+	}
+	return []byte(out), nil
+}
@@ -0,0 +1,168 @@
+package ingest
+
+import (
+	"bufio"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// ignoreRule is a pattern of a .gitignore file.
+type ignoreRule struct {
+	base     string   // directory of the .gitignore file, slash-separated from the root
+	segments []string // pattern split at slashes
+	negate   bool     // !pattern re-includes what an earlier pattern ignored
+	dirOnly  bool     // pattern/ matches directories only
+	anchored bool     // a pattern with a slash matches from base; others match names at any depth
+}
+
+// gitignore decides which paths the .gitignore files of a repository
+// ignore. It reads the files from the repository's root down as the
+// directories below it are walked.
+type gitignore struct {
+	root   string
+	rules  []ignoreRule
+	loaded map[string]bool
+}
+
+// newGitignore returns the ignore rules for paths under dir: those of the
+// .gitignore files from the root of the git repository containing dir down
+// to dir, or of dir alone outside a repository.
+func newGitignore(dir string) *gitignore {
+	g := &gitignore{root: dir, loaded: make(map[string]bool)}
+	for d := dir; ; {
+		if _, err := os.Stat(filepath.Join(d, ".git")); err == nil {
+			g.root = d
+			break
+		}
+		parent := filepath.Dir(d)
+		if parent == d {
+			break
+		}
+		d = parent
+	}
+
+	rel, _ := filepath.Rel(g.root, dir)
+	g.load(g.root)
+	if rel != "." {
+		d := g.root
+		for _, name := range strings.Split(rel, string(filepath.Separator)) {
+			d = filepath.Join(d, name)
+			g.load(d)
+		}
+	}
+	return g
+}
+
+// load reads the .gitignore file of dir, once.
+func (g *gitignore) load(dir string) {
+	if g.loaded[dir] {
+		return
+	}
+	g.loaded[dir] = true
+
+	f, err := os.Open(filepath.Join(dir, ".gitignore"))
+	if err != nil {
+		return
+	}
+	defer f.Close()
+
+	base, _ := filepath.Rel(g.root, dir)
+	base = filepath.ToSlash(base)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if rule, ok := parseIgnoreRule(base, scanner.Text()); ok {
+			g.rules = append(g.rules, rule)
+		}
+	}
+}
+
+// parseIgnoreRule parses a line of the .gitignore file of base. Blank
+// lines and comments are no rules.
+func parseIgnoreRule(base, line string) (ignoreRule, bool) {
+	line = strings.TrimRight(line, " \t\r")
+	if line == "" || strings.HasPrefix(line, "#") {
+		return ignoreRule{}, false
+	}
+
+	rule := ignoreRule{base: base}
+	if strings.HasPrefix(line, "!") {
+		rule.negate = true
+		line = line[1:]
+	} else if strings.HasPrefix(line, `\`) {
+		line = line[1:] // \# and \! match a leading # or !
+	}
+	if strings.HasSuffix(line, "/") {
+		rule.dirOnly = true
+		line = strings.TrimRight(line, "/")
+	}
+	rule.anchored = strings.Contains(line, "/")
+	line = strings.TrimPrefix(line, "/")
+	if line == "" {
+		return ignoreRule{}, false
+	}
+	rule.segments = strings.Split(line, "/")
+	return rule, true
+}
+
+// ignored reports whether the file or directory at p, under the root, is
+// ignored. Later rules override earlier ones, and deeper .gitignore files
+// are read after those above them.
+func (g *gitignore) ignored(p string, isDir bool) bool {
+	rel, err := filepath.Rel(g.root, p)
+	if err != nil || rel == "." || strings.HasPrefix(rel, "..") {
+		return false
+	}
+	rel = filepath.ToSlash(rel)
+
+	ignored := false
+	for _, rule := range g.rules {
+		if rule.negate == ignored && rule.matches(rel, isDir) {
+			ignored = !rule.negate
+		}
+	}
+	return ignored
+}
+
+// matches reports whether the rule matches the path rel from the root.
+func (r ignoreRule) matches(rel string, isDir bool) bool {
+	if r.dirOnly && !isDir {
+		return false
+	}
+	if r.base != "." {
+		if !strings.HasPrefix(rel, r.base+"/") {
+			return false
+		}
+		rel = rel[len(r.base)+1:]
+	}
+	if !r.anchored {
+		ok, _ := path.Match(r.segments[0], path.Base(rel))
+		return ok
+	}
+	return matchSegments(r.segments, strings.Split(rel, "/"))
+}
+
+// matchSegments reports whether the segments of a slash-separated path
+// match those of a pattern, where ** matches any number of segments and
+// the others match one as with path.Match.
+func matchSegments(pattern, name []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for i := 0; i <= len(name); i++ {
+				if matchSegments(pattern[1:], name[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(name) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(pattern[0], name[0]); !ok {
+			return false
+		}
+		pattern, name = pattern[1:], name[1:]
+	}
+	return len(name) == 0
+}
@@ -0,0 +1,268 @@
+// Package ingest reads the files a skill takes as input: files,
+// directories and glob patterns such as ./src/**/*.go, skipping what the
+// repository's .gitignore files ignore, and formats them as template
+// variables, whole or in chunks.
+package ingest
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/jbctechsolutions/skillrunner/internal/domain/skill"
+)
+
+// DefaultChunkTokens is the size of the chunks the files are split into
+// unless another is given.
+const DefaultChunkTokens = 8000
+
+// charsPerToken estimates the tokens of text, as the executor does.
+const charsPerToken = 4
+
+// binarySniffLen is how much of a file is checked for NUL bytes, as git
+// does to tell binary files.
+const binarySniffLen = 8000
+
+// ErrNoFiles is returned when the patterns match no files.
+var ErrNoFiles = errors.New("no input files matched")
+
+// File is an input file.
+type File struct {
+	Path    string // as given or found under a directory, slash-separated
+	Content string
+}
+
+// Load reads the files of the patterns, in the order given: a file, every
+// file under a directory, or the files matching a glob pattern, in which
+// ** matches any number of directories. The files found under directories
+// and patterns leave out those the .gitignore files ignore, the .git
+// directory and binary files; files named explicitly are always read. A
+// file matched twice is read once.
+func Load(patterns []string) ([]File, error) {
+	var files []File
+	seen := make(map[string]bool)
+	add := func(p string, named bool) error {
+		p = filepath.ToSlash(filepath.Clean(p))
+		if seen[p] {
+			return nil
+		}
+		seen[p] = true
+		data, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		if isBinary(data) {
+			if named {
+				return fmt.Errorf("input file %s is not a text file", p)
+			}
+			return nil
+		}
+		files = append(files, File{Path: p, Content: string(data)})
+		return nil
+	}
+
+	for _, pattern := range patterns {
+		if !hasMeta(pattern) {
+			info, err := os.Stat(pattern)
+			if err != nil {
+				return nil, err
+			}
+			if !info.IsDir() {
+				if err := add(pattern, true); err != nil {
+					return nil, err
+				}
+				continue
+			}
+		}
+
+		n := len(files)
+		dir, glob := splitPattern(pattern)
+		err := walk(dir, func(p string) error {
+			rel, _ := filepath.Rel(dir, p)
+			if glob != nil && !matchSegments(glob, strings.Split(filepath.ToSlash(rel), "/")) {
+				return nil
+			}
+			return add(p, false)
+		})
+		if err != nil {
+			return nil, err
+		}
+		if len(files) == n {
+			return nil, fmt.Errorf("%w: %s", ErrNoFiles, pattern)
+		}
+	}
+	return files, nil
+}
+
+// walk calls fn with the path of each file under dir, in lexical order,
+// skipping the .git directory and what the .gitignore files ignore.
+func walk(dir string, fn func(p string) error) error {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return err
+	}
+	ignore := newGitignore(abs)
+
+	return filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if p == dir {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		pAbs := filepath.Join(abs, rel)
+		if d.IsDir() {
+			if d.Name() == ".git" || ignore.ignored(pAbs, true) {
+				return filepath.SkipDir
+			}
+			ignore.load(pAbs)
+			return nil
+		}
+		if !d.Type().IsRegular() || ignore.ignored(pAbs, false) {
+			return nil
+		}
+		return fn(p)
+	})
+}
+
+// hasMeta reports whether a pattern has glob metacharacters.
+func hasMeta(pattern string) bool {
+	return strings.ContainsAny(pattern, `*?[`)
+}
+
+// splitPattern splits a pattern at its first segment with metacharacters
+// into the directory to walk and the segments of the glob matching paths
+// below it. A pattern without metacharacters is a directory walked whole,
+// with a nil glob.
+func splitPattern(pattern string) (string, []string) {
+	segments := strings.Split(filepath.ToSlash(pattern), "/")
+	i := 0
+	for i < len(segments) && !hasMeta(segments[i]) {
+		i++
+	}
+	if i == len(segments) {
+		return pattern, nil
+	}
+	dir := strings.Join(segments[:i], "/")
+	switch {
+	case dir == "" && i > 0:
+		dir = "/"
+	case dir == "":
+		dir = "."
+	}
+	return filepath.FromSlash(dir), segments[i:]
+}
+
+// isBinary reports whether data looks like the content of a binary file.
+func isBinary(data []byte) bool {
+	return bytes.IndexByte(data[:min(len(data), binarySniffLen)], 0) >= 0
+}
+
+// Concat joins the files into one text, each under its path in a fenced
+// code block.
+func Concat(files []File) string {
+	parts := make([]string, len(files))
+	for i, f := range files {
+		parts[i] = block(f.Path, f.Path, f.Content)
+	}
+	return strings.Join(parts, "\n\n")
+}
+
+// block formats content of the file at path under a heading in a fenced
+// code block tagged with the file's extension, fenced with more backticks
+// than the content has in a row.
+func block(path, heading, content string) string {
+	fence := strings.Repeat("`", max(3, longestRun(content, '`')+1))
+	lang := strings.TrimPrefix(filepath.Ext(path), ".")
+	return fmt.Sprintf("%s\n%s%s\n%s\n%s", heading, fence, lang, strings.TrimRight(content, "\n"), fence)
+}
+
+// longestRun returns the length of the longest run of c in s.
+func longestRun(s string, c byte) int {
+	longest, run := 0, 0
+	for i := 0; i < len(s); i++ {
+		if s[i] == c {
+			run++
+			longest = max(longest, run)
+		} else {
+			run = 0
+		}
+	}
+	return longest
+}
+
+// Chunk splits the files into chunks of about maxTokens each, formatted as
+// Concat does. Files are kept whole where they fit and in the order given;
+// a larger file is split at line boundaries into parts headed with their
+// lines. maxTokens of 0 or less means DefaultChunkTokens.
+func Chunk(files []File, maxTokens int) []string {
+	if maxTokens <= 0 {
+		maxTokens = DefaultChunkTokens
+	}
+	limit := maxTokens * charsPerToken
+
+	var chunks []string
+	var current []string
+	size := 0
+	flush := func() {
+		if len(current) > 0 {
+			chunks = append(chunks, strings.Join(current, "\n\n"))
+			current, size = nil, 0
+		}
+	}
+	for _, f := range files {
+		for _, part := range fileParts(f, limit) {
+			if size > 0 && size+len(part) > limit {
+				flush()
+			}
+			current = append(current, part)
+			size += len(part)
+		}
+	}
+	flush()
+	return chunks
+}
+
+// fileParts formats a file as one block, or as blocks of consecutive lines
+// of about limit bytes when the file is larger.
+func fileParts(f File, limit int) []string {
+	if whole := block(f.Path, f.Path, f.Content); len(whole) <= limit {
+		return []string{whole}
+	}
+
+	lines := strings.SplitAfter(strings.TrimRight(f.Content, "\n"), "\n")
+	var parts []string
+	first, size := 0, 0
+	for i, line := range lines {
+		if size > 0 && size+len(line) > limit {
+			parts = append(parts, block(f.Path, fmt.Sprintf("%s (lines %d-%d)", f.Path, first+1, i), strings.Join(lines[first:i], "")))
+			first, size = i, 0
+		}
+		size += len(line)
+	}
+	return append(parts, block(f.Path, fmt.Sprintf("%s (lines %d-%d)", f.Path, first+1, len(lines)), strings.Join(lines[first:], "")))
+}
+
+// Variables returns the template variables of the files: their contents,
+// paths, and chunks of about chunkTokens each as a JSON array.
+func Variables(files []File, chunkTokens int) map[string]string {
+	paths := make([]string, len(files))
+	for i, f := range files {
+		paths[i] = f.Path
+	}
+	chunks, _ := json.Marshal(Chunk(files, chunkTokens))
+	return map[string]string{
+		skill.FilesVariable:      Concat(files),
+		skill.FilePathsVariable:  strings.Join(paths, "\n"),
+		skill.FileChunksVariable: string(chunks),
+	}
+}
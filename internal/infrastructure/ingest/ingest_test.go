@@ -0,0 +1,170 @@
+package ingest
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/jbctechsolutions/skillrunner/internal/domain/skill"
+)
+
+// writeFiles creates files under dir from a map of slash-separated paths
+// to contents.
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		p := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func paths(files []File) []string {
+	var got []string
+	for _, f := range files {
+		got = append(got, f.Path)
+	}
+	return got
+}
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		".git/HEAD":             "ref: refs/heads/main",
+		".gitignore":            "*.log\n/build/\nvendor/\n",
+		"main.go":               "package main",
+		"README.md":             "# demo",
+		"debug.log":             "noise",
+		"build/out.go":          "package build",
+		"src/app.go":            "package src",
+		"src/app_test.go":       "package src",
+		"src/.gitignore":        "gen/\n!keep.log\n",
+		"src/keep.log":          "kept",
+		"src/gen/types.go":      "package gen",
+		"src/deep/util/util.go": "package util",
+		"src/vendor/lib.go":     "package lib",
+		"src/logo.png":          "\x89PNG\x00\x00",
+	})
+	t.Chdir(dir)
+
+	tests := []struct {
+		name     string
+		patterns []string
+		want     []string
+	}{
+		{"directory", []string{"src"}, []string{"src/.gitignore", "src/app.go", "src/app_test.go", "src/deep/util/util.go", "src/keep.log"}},
+		{"doublestar glob", []string{"./src/**/*.go"}, []string{"src/app.go", "src/app_test.go", "src/deep/util/util.go"}},
+		{"glob", []string{"*.go", "*.md"}, []string{"main.go", "README.md"}},
+		{"explicit ignored file", []string{"debug.log", "*.go"}, []string{"debug.log", "main.go"}},
+		{"duplicates", []string{"main.go", "."}, []string{"main.go", ".gitignore", "README.md", "src/.gitignore", "src/app.go", "src/app_test.go", "src/deep/util/util.go", "src/keep.log"}},
+		{"named ignored directory", []string{"build"}, []string{"build/out.go"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			files, err := Load(tt.patterns)
+			if err != nil {
+				t.Fatalf("Load() error = %v", err)
+			}
+			if got := paths(files); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Load() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := Load([]string{"src/**/*.rs"}); !errors.Is(err, ErrNoFiles) {
+		t.Errorf("Load() of an unmatched glob error = %v, want ErrNoFiles", err)
+	}
+	if _, err := Load([]string{"missing.go"}); err == nil {
+		t.Error("Load() of a missing file succeeded")
+	}
+	if _, err := Load([]string{"src/logo.png"}); err == nil || !strings.Contains(err.Error(), "not a text file") {
+		t.Errorf("Load() of a binary file error = %v, want not a text file", err)
+	}
+}
+
+func TestParseIgnoreRule(t *testing.T) {
+	tests := []struct {
+		line  string
+		rel   string
+		isDir bool
+		want  bool
+	}{
+		{"*.log", "a/b/debug.log", false, true},
+		{"/build", "build", true, true},
+		{"/build", "src/build", true, false},
+		{"docs/*.md", "docs/a.md", false, true},
+		{"docs/*.md", "docs/sub/a.md", false, false},
+		{"docs/**/*.md", "docs/sub/a.md", false, true},
+		{"**/tmp", "a/b/tmp", true, true},
+		{"out/", "out", false, false},
+	}
+	for _, tt := range tests {
+		rule, ok := parseIgnoreRule(".", tt.line)
+		if !ok {
+			t.Fatalf("parseIgnoreRule(%q) is no rule", tt.line)
+		}
+		if got := rule.matches(tt.rel, tt.isDir); got != tt.want {
+			t.Errorf("%q matches %q = %v, want %v", tt.line, tt.rel, got, tt.want)
+		}
+	}
+
+	for _, line := range []string{"", "  ", "# comment", "/"} {
+		if _, ok := parseIgnoreRule(".", line); ok {
+			t.Errorf("parseIgnoreRule(%q) is a rule", line)
+		}
+	}
+	if rule, _ := parseIgnoreRule("src", "gen/"); !rule.matches("src/gen", true) || rule.matches("gen", true) {
+		t.Error("rule of src/.gitignore does not match relative to src")
+	}
+}
+
+func TestChunk(t *testing.T) {
+	files := []File{
+		{Path: "a.go", Content: "package a\n"},
+		{Path: "b.go", Content: "package b\n"},
+		{Path: "big.txt", Content: strings.Repeat("0123456789\n", 10)},
+	}
+	if got, want := Concat(files[:1]), "a.go\n```go\npackage a\n```"; got != want {
+		t.Errorf("Concat() = %q, want %q", got, want)
+	}
+
+	chunks := Chunk(files, 10) // 40 bytes: a.go and b.go apart, big.txt in parts
+	if len(chunks) < 4 {
+		t.Fatalf("Chunk() = %d chunks, want a.go, b.go and parts of big.txt apart", len(chunks))
+	}
+	if !strings.HasPrefix(chunks[0], "a.go\n") || !strings.HasPrefix(chunks[1], "b.go\n") {
+		t.Errorf("Chunk() starts with %q, %q; want a.go, b.go", chunks[0], chunks[1])
+	}
+	if !strings.HasPrefix(chunks[2], "big.txt (lines 1-") || !strings.Contains(chunks[len(chunks)-1], "-10)") {
+		t.Errorf("Chunk() parts of big.txt = %q", chunks[2:])
+	}
+
+	if got := Chunk(files, 0); len(got) != 1 {
+		t.Errorf("Chunk() with the default size = %d chunks, want 1", len(got))
+	}
+	if got := Concat([]File{{Path: "doc.md", Content: "```sh\nls\n```"}}); !strings.Contains(got, "````md\n") {
+		t.Errorf("Concat() of fenced content = %q, want a longer fence", got)
+	}
+}
+
+func TestVariables(t *testing.T) {
+	vars := Variables([]File{{Path: "a.go", Content: "package a"}, {Path: "b.go", Content: "package b"}}, 0)
+	if vars[skill.FilePathsVariable] != "a.go\nb.go" {
+		t.Errorf("paths = %q", vars[skill.FilePathsVariable])
+	}
+	if !strings.Contains(vars[skill.FilesVariable], "package b") {
+		t.Errorf("files = %q", vars[skill.FilesVariable])
+	}
+	var chunks []string
+	if err := json.Unmarshal([]byte(vars[skill.FileChunksVariable]), &chunks); err != nil || len(chunks) != 1 {
+		t.Errorf("chunks = %q, %v; want one chunk", vars[skill.FileChunksVariable], err)
+	}
+}
@@ -53,6 +53,8 @@ type Config struct {
 	Output     io.Writer
 	AddSource  bool
 	TimeFormat string
+	RunLogDir  string // Directory of per-run log files; empty disables them
+	EchoRuns   bool   // Also write run logs to Output
 }
 
 // DefaultConfig returns sensible default logging configuration.
@@ -70,6 +72,7 @@ func DefaultConfig() Config {
 type Logger struct {
 	slogger *slog.Logger
 	level   slog.Level
+	cfg     Config
 	mu      sync.RWMutex
 }
 
@@ -87,6 +90,13 @@ func Init(cfg Config) *Logger {
 	return global
 }
 
+// SetDefault replaces the global logger, such as with one configured from
+// the command line after Init or Default already ran.
+func SetDefault(l *Logger) {
+	globalOnce.Do(func() {})
+	global = l
+}
+
 // Default returns the global logger, initializing it with defaults if necessary.
 func Default() *Logger {
 	if global == nil {
@@ -97,11 +107,17 @@ func Default() *Logger {
 
 // New creates a new Logger with the provided configuration.
 func New(cfg Config) *Logger {
-	level := parseLevel(cfg.Level)
+	return &Logger{
+		slogger: slog.New(newHandler(cfg)),
+		level:   parseLevel(cfg.Level),
+		cfg:     cfg,
+	}
+}
 
-	var handler slog.Handler
+// newHandler creates the slog handler writing cfg.Output in cfg.Format.
+func newHandler(cfg Config) slog.Handler {
 	opts := &slog.HandlerOptions{
-		Level:     level,
+		Level:     parseLevel(cfg.Level),
 		AddSource: cfg.AddSource,
 		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
 			// Customize time format
@@ -121,14 +137,9 @@ func New(cfg Config) *Logger {
 
 	switch cfg.Format {
 	case FormatJSON:
-		handler = slog.NewJSONHandler(output, opts)
+		return slog.NewJSONHandler(output, opts)
 	default:
-		handler = slog.NewTextHandler(output, opts)
-	}
-
-	return &Logger{
-		slogger: slog.New(handler),
-		level:   level,
+		return slog.NewTextHandler(output, opts)
 	}
 }
 
@@ -160,6 +171,7 @@ func (l *Logger) With(args ...any) *Logger {
 	return &Logger{
 		slogger: l.slogger.With(args...),
 		level:   l.level,
+		cfg:     l.cfg,
 	}
 }
 
@@ -168,6 +180,7 @@ func (l *Logger) WithGroup(name string) *Logger {
 	return &Logger{
 		slogger: l.slogger.WithGroup(name),
 		level:   l.level,
+		cfg:     l.cfg,
 	}
 }
 
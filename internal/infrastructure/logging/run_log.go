@@ -0,0 +1,114 @@
+package logging
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+)
+
+// loggerKey is the context key for the logger of a run.
+const loggerKey contextKey = "logger"
+
+// DefaultRunLogDir returns the default directory of per-run log files,
+// ~/.skillrunner/logs.
+func DefaultRunLogDir() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".skillrunner", "logs"), nil
+}
+
+// RunLogPath returns the path of the log file of an execution in dir.
+func RunLogPath(dir, executionID string) string {
+	return filepath.Join(dir, filepath.Base(executionID)+".log")
+}
+
+// ForRun returns the logger of a skill run, which adds the execution ID to
+// every record. When the logger has a RunLogDir, the run logs to its own
+// file there, named after the execution ID, in the logger's format and at
+// its level, and to the logger's output only with EchoRuns. A resumed run
+// appends to its file. Close the returned closer when the run ends.
+func (l *Logger) ForRun(executionID string) (*Logger, io.Closer, error) {
+	if l.cfg.RunLogDir == "" {
+		return l.With("execution_id", executionID), nopCloser{}, nil
+	}
+	if err := os.MkdirAll(l.cfg.RunLogDir, 0o700); err != nil {
+		return nil, nil, err
+	}
+	f, err := os.OpenFile(RunLogPath(l.cfg.RunLogDir, executionID), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	fileCfg := l.cfg
+	fileCfg.Output = f
+	handler := newHandler(fileCfg)
+	if l.cfg.EchoRuns {
+		handler = teeHandler{l.slogger.Handler(), handler}
+	}
+	return &Logger{
+		slogger: slog.New(handler).With("execution_id", executionID),
+		level:   l.level,
+		cfg:     l.cfg,
+	}, f, nil
+}
+
+// WithLogger returns a context carrying logger, for FromContext.
+func WithLogger(ctx context.Context, logger *Logger) context.Context {
+	return context.WithValue(ctx, loggerKey, logger)
+}
+
+// FromContext returns the logger carried by ctx, or the global logger.
+func FromContext(ctx context.Context) *Logger {
+	if l, ok := ctx.Value(loggerKey).(*Logger); ok {
+		return l
+	}
+	return Default()
+}
+
+// teeHandler sends each record to every handler that accepts its level.
+type teeHandler []slog.Handler
+
+func (t teeHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	for _, h := range t {
+		if h.Enabled(ctx, level) {
+			return true
+		}
+	}
+	return false
+}
+
+func (t teeHandler) Handle(ctx context.Context, r slog.Record) error {
+	var errs []error
+	for _, h := range t {
+		if h.Enabled(ctx, r.Level) {
+			errs = append(errs, h.Handle(ctx, r.Clone()))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (t teeHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	handlers := make(teeHandler, len(t))
+	for i, h := range t {
+		handlers[i] = h.WithAttrs(attrs)
+	}
+	return handlers
+}
+
+func (t teeHandler) WithGroup(name string) slog.Handler {
+	handlers := make(teeHandler, len(t))
+	for i, h := range t {
+		handlers[i] = h.WithGroup(name)
+	}
+	return handlers
+}
+
+// nopCloser closes nothing.
+type nopCloser struct{}
+
+func (nopCloser) Close() error { return nil }
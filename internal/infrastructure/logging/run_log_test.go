@@ -0,0 +1,88 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestForRun(t *testing.T) {
+	dir := t.TempDir()
+	console := &bytes.Buffer{}
+	logger := New(Config{Level: LevelInfo, Format: FormatJSON, Output: console, RunLogDir: dir})
+
+	run, closer, err := logger.ForRun("exec-1")
+	if err != nil {
+		t.Fatalf("ForRun() error = %v", err)
+	}
+	run.Info("workflow execution started", "skill_id", "review")
+	run.Debug("below the level")
+	if err := closer.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(RunLogPath(dir, "exec-1"))
+	if err != nil {
+		t.Fatalf("expected a run log file: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 1 {
+		t.Fatalf("run log has %d lines, want 1:\n%s", len(lines), data)
+	}
+	var record map[string]any
+	if err := json.Unmarshal([]byte(lines[0]), &record); err != nil {
+		t.Fatalf("run log is not JSON: %v", err)
+	}
+	if record["execution_id"] != "exec-1" || record["skill_id"] != "review" {
+		t.Errorf("record = %v, want the execution and skill IDs", record)
+	}
+	if console.Len() != 0 {
+		t.Errorf("run logs should not reach the console without EchoRuns, got %q", console.String())
+	}
+}
+
+func TestForRun_Echo(t *testing.T) {
+	dir := t.TempDir()
+	console := &bytes.Buffer{}
+	logger := New(Config{Level: LevelInfo, Format: FormatText, Output: console, RunLogDir: dir, EchoRuns: true})
+
+	run, closer, err := logger.ForRun("exec-2")
+	if err != nil {
+		t.Fatalf("ForRun() error = %v", err)
+	}
+	run.Warn("stream stalled")
+	_ = closer.Close()
+
+	data, _ := os.ReadFile(RunLogPath(dir, "exec-2"))
+	for name, out := range map[string]string{"file": string(data), "console": console.String()} {
+		if !strings.Contains(out, "stream stalled") || !strings.Contains(out, "execution_id=exec-2") {
+			t.Errorf("%s output = %q, want the record with its execution ID", name, out)
+		}
+	}
+}
+
+func TestForRun_WithoutDir(t *testing.T) {
+	console := &bytes.Buffer{}
+	run, closer, err := New(Config{Level: LevelInfo, Output: console}).ForRun("exec-3")
+	if err != nil {
+		t.Fatalf("ForRun() error = %v", err)
+	}
+	run.Info("hello")
+	_ = closer.Close()
+	if !strings.Contains(console.String(), "execution_id=exec-3") {
+		t.Errorf("console = %q, want the record with its execution ID", console.String())
+	}
+}
+
+func TestFromContext(t *testing.T) {
+	if FromContext(context.Background()) != Default() {
+		t.Error("FromContext() without a logger should return the global logger")
+	}
+	logger := New(Config{Output: &bytes.Buffer{}})
+	if FromContext(WithLogger(context.Background(), logger)) != logger {
+		t.Error("FromContext() should return the context's logger")
+	}
+}
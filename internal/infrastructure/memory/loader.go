@@ -46,6 +46,12 @@ func NewLoaderWithHomeDir(maxTokens int, homeDir string) *Loader {
 // Load loads memory from both global and project locations.
 // Returns a Memory domain object combining all sources.
 func (l *Loader) Load(projectDir string) (*memory.Memory, error) {
+	return l.LoadWithFiles(projectDir, nil)
+}
+
+// LoadWithFiles loads memory like Load and adds files (absolute paths, e.g.
+// from .skillrunner.yaml) as project includes. Missing files are skipped.
+func (l *Loader) LoadWithFiles(projectDir string, files []string) (*memory.Memory, error) {
 	// Load global memory
 	globalContent, globalSource, err := l.loadGlobalMemory()
 	if err != nil && !os.IsNotExist(err) {
@@ -77,6 +83,19 @@ func (l *Loader) Load(projectDir string) (*memory.Memory, error) {
 		includes = append(includes, projectIncludes...)
 	}
 
+	// Add configured memory files
+	for _, path := range files {
+		content, err := l.readFile(path)
+		if err != nil {
+			continue
+		}
+		includes = append(includes, memory.IncludedFile{
+			Path:    path,
+			Content: content,
+			Source:  "project",
+		})
+	}
+
 	// Create memory object
 	mem := memory.NewMemory(globalContent, projectContent, includes)
 
@@ -203,6 +203,33 @@ func TestLoader_Load_GlobalAndProject(t *testing.T) {
 	}
 }
 
+func TestLoader_LoadWithFiles(t *testing.T) {
+	homeDir := t.TempDir()
+	projectDir := t.TempDir()
+
+	if err := os.WriteFile(filepath.Join(projectDir, "MEMORY.md"), []byte("# Project"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	conventions := filepath.Join(projectDir, "CONVENTIONS.md")
+	if err := os.WriteFile(conventions, []byte("Use tabs."), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	loader := NewLoaderWithHomeDir(2000, homeDir)
+	mem, err := loader.LoadWithFiles(projectDir, []string{conventions, filepath.Join(projectDir, "missing.md")})
+	if err != nil {
+		t.Fatalf("LoadWithFiles() error = %v", err)
+	}
+
+	includes := mem.Includes()
+	if len(includes) != 1 || includes[0].Path != conventions || includes[0].Source != "project" {
+		t.Errorf("includes = %+v, want the conventions file", includes)
+	}
+	if combined := mem.Combined(); !strings.Contains(combined, "# Project") || !strings.Contains(combined, "Use tabs.") {
+		t.Errorf("Combined() = %q", combined)
+	}
+}
+
 func TestLoader_Load_GlobalOnly(t *testing.T) {
 	homeDir := t.TempDir()
 	projectDir := t.TempDir()
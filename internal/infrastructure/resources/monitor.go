@@ -0,0 +1,185 @@
+// Package resources samples local resource usage during a run: CPU and
+// memory of the machine and, where nvidia-smi or Metal report it, GPU
+// utilization. Local models run in another process (such as Ollama), so
+// usage is measured for the whole machine rather than for skillrunner. It
+// also reads whether the machine runs on battery or under thermal pressure.
+package resources
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// DefaultInterval is the time between samples.
+const DefaultInterval = time.Second
+
+// Sample is one reading of resource usage.
+type Sample struct {
+	CPUPercent  float64 // Busy share of all cores
+	MemoryUsed  uint64  // Bytes in use
+	MemoryTotal uint64  // Bytes installed
+
+	GPU *GPUSample // nil when no GPU reports utilization
+}
+
+// GPUSample is one reading of GPU usage, averaged over all GPUs for
+// utilization and summed for memory.
+type GPUSample struct {
+	Source     string // nvidia-smi or metal
+	Percent    float64
+	MemoryUsed uint64 // Bytes in use; 0 if not reported
+}
+
+// Sampler reads resource usage.
+type Sampler interface {
+	Sample(ctx context.Context) (Sample, error)
+}
+
+// Stat summarizes a series of readings.
+type Stat struct {
+	Peak float64
+	Avg  float64
+}
+
+// add includes value as the n-th reading of the series.
+func (s *Stat) add(value float64, n int) {
+	s.Peak = max(s.Peak, value)
+	s.Avg += (value - s.Avg) / float64(n)
+}
+
+// Usage summarizes the samples of a run.
+type Usage struct {
+	Duration    time.Duration
+	Samples     int
+	CPU         Stat // Percent
+	Memory      Stat // Bytes
+	MemoryTotal uint64
+
+	GPU *GPUUsage // nil when no sample reported a GPU
+}
+
+// GPUUsage summarizes the GPU readings of a run.
+type GPUUsage struct {
+	Source  string
+	Percent Stat
+	Memory  Stat // Bytes
+}
+
+// Monitor samples resource usage in the background between Start and Stop.
+type Monitor struct {
+	sampler  Sampler
+	interval time.Duration
+
+	mu      sync.Mutex
+	usage   Usage
+	gpuSeen int
+	start   time.Time
+	cancel  context.CancelFunc
+	done    chan struct{}
+}
+
+// MonitorOption configures a Monitor.
+type MonitorOption func(*Monitor)
+
+// WithInterval sets the time between samples.
+func WithInterval(d time.Duration) MonitorOption {
+	return func(m *Monitor) {
+		if d > 0 {
+			m.interval = d
+		}
+	}
+}
+
+// WithSampler sets the sampler, replacing the system sampler.
+func WithSampler(s Sampler) MonitorOption {
+	return func(m *Monitor) {
+		m.sampler = s
+	}
+}
+
+// NewMonitor creates a monitor that samples the machine every
+// DefaultInterval.
+func NewMonitor(opts ...MonitorOption) *Monitor {
+	m := &Monitor{interval: DefaultInterval}
+	for _, opt := range opts {
+		opt(m)
+	}
+	if m.sampler == nil {
+		m.sampler = NewSystemSampler()
+	}
+	return m
+}
+
+// Start begins sampling until Stop is called or ctx is done.
+func (m *Monitor) Start(ctx context.Context) {
+	ctx, m.cancel = context.WithCancel(ctx)
+	m.done = make(chan struct{})
+	m.start = time.Now()
+
+	go func() {
+		defer close(m.done)
+		ticker := time.NewTicker(m.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				m.sample(ctx)
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+// Stop ends sampling, takes a final sample so that short runs are measured
+// too, and returns the usage. It returns nil if no sample could be taken,
+// for example on an unsupported platform.
+func (m *Monitor) Stop() *Usage {
+	if m.cancel == nil {
+		return nil
+	}
+	m.cancel()
+	<-m.done
+	m.sample(context.Background())
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.usage.Samples == 0 {
+		return nil
+	}
+	usage := m.usage
+	usage.Duration = time.Since(m.start)
+	if usage.GPU != nil {
+		gpu := *usage.GPU
+		usage.GPU = &gpu
+	}
+	return &usage
+}
+
+// sample takes one sample and adds it to the usage. Failed samples are
+// skipped.
+func (m *Monitor) sample(ctx context.Context) {
+	s, err := m.sampler.Sample(ctx)
+	if err != nil {
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	u := &m.usage
+	u.Samples++
+	u.CPU.add(s.CPUPercent, u.Samples)
+	u.Memory.add(float64(s.MemoryUsed), u.Samples)
+	u.MemoryTotal = max(u.MemoryTotal, s.MemoryTotal)
+
+	if s.GPU != nil {
+		if u.GPU == nil {
+			u.GPU = &GPUUsage{Source: s.GPU.Source}
+		}
+		m.gpuSeen++
+		u.GPU.Percent.add(s.GPU.Percent, m.gpuSeen)
+		u.GPU.Memory.add(float64(s.GPU.MemoryUsed), m.gpuSeen)
+	}
+}
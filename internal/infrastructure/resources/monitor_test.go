@@ -0,0 +1,82 @@
+package resources
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeSampler returns a fixed series of samples, then repeats the last.
+type fakeSampler struct {
+	mu      sync.Mutex
+	samples []Sample
+	err     error
+}
+
+func (f *fakeSampler) Sample(_ context.Context) (Sample, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.err != nil {
+		return Sample{}, f.err
+	}
+	s := f.samples[0]
+	if len(f.samples) > 1 {
+		f.samples = f.samples[1:]
+	}
+	return s, nil
+}
+
+func TestMonitor_Usage(t *testing.T) {
+	sampler := &fakeSampler{samples: []Sample{
+		{CPUPercent: 20, MemoryUsed: 4 << 30, MemoryTotal: 16 << 30},
+		{CPUPercent: 80, MemoryUsed: 8 << 30, MemoryTotal: 16 << 30, GPU: &GPUSample{Source: "metal", Percent: 90, MemoryUsed: 2 << 30}},
+		{CPUPercent: 50, MemoryUsed: 6 << 30, MemoryTotal: 16 << 30, GPU: &GPUSample{Source: "metal", Percent: 30}},
+	}}
+
+	m := NewMonitor(WithSampler(sampler), WithInterval(time.Hour))
+	m.Start(context.Background())
+	m.sample(context.Background())
+	m.sample(context.Background())
+	usage := m.Stop() // Takes the third sample
+
+	if usage == nil {
+		t.Fatal("expected usage")
+	}
+	if usage.Samples != 3 {
+		t.Errorf("Samples = %d, want 3", usage.Samples)
+	}
+	if usage.CPU.Peak != 80 || usage.CPU.Avg != 50 {
+		t.Errorf("CPU = %+v, want peak 80 avg 50", usage.CPU)
+	}
+	if usage.Memory.Peak != 8<<30 || usage.Memory.Avg != 6<<30 || usage.MemoryTotal != 16<<30 {
+		t.Errorf("Memory = %+v of %d, want peak 8 GiB avg 6 GiB of 16 GiB", usage.Memory, usage.MemoryTotal)
+	}
+	if usage.GPU == nil || usage.GPU.Source != "metal" || usage.GPU.Percent.Peak != 90 || usage.GPU.Percent.Avg != 60 {
+		t.Errorf("GPU = %+v, want metal with peak 90 avg 60 over its own samples", usage.GPU)
+	}
+}
+
+func TestMonitor_NoSamples(t *testing.T) {
+	m := NewMonitor(WithSampler(&fakeSampler{err: errors.New("unsupported")}))
+	m.Start(context.Background())
+	if usage := m.Stop(); usage != nil {
+		t.Errorf("expected nil usage without samples, got %+v", usage)
+	}
+
+	if usage := NewMonitor(WithSampler(&fakeSampler{})).Stop(); usage != nil {
+		t.Errorf("expected nil usage from a monitor that was not started, got %+v", usage)
+	}
+}
+
+func TestMonitor_SamplesInBackground(t *testing.T) {
+	sampler := &fakeSampler{samples: []Sample{{CPUPercent: 10}}}
+	m := NewMonitor(WithSampler(sampler), WithInterval(time.Millisecond))
+	m.Start(context.Background())
+	time.Sleep(20 * time.Millisecond)
+
+	if usage := m.Stop(); usage == nil || usage.Samples < 2 {
+		t.Errorf("expected background samples, got %+v", usage)
+	}
+}
@@ -0,0 +1,120 @@
+package resources
+
+import (
+	"context"
+	"io/fs"
+	"os"
+	"path"
+	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// PowerState is the power and thermal state of the machine.
+type PowerState struct {
+	OnBattery       bool // Running on battery rather than external power
+	ThermalPressure bool // The CPU is throttled to cool down
+}
+
+// Constrained reports whether sustained local inference should be avoided:
+// it drains the battery or heats a machine that is already throttling.
+func (s PowerState) Constrained() bool {
+	return s.OnBattery || s.ThermalPressure
+}
+
+// ReadPowerState reads the power state from /sys/class/power_supply and
+// /sys/class/thermal on Linux, and from pmset on macOS. Machines without a
+// battery never report OnBattery.
+func ReadPowerState(ctx context.Context) (PowerState, error) {
+	switch runtime.GOOS {
+	case "linux":
+		return PowerState{
+			OnBattery:       linuxOnBattery(os.DirFS("/sys/class/power_supply")),
+			ThermalPressure: linuxThermalPressure(os.DirFS("/sys/class/thermal")),
+		}, nil
+	case "darwin":
+		batt, err := runCommand(ctx, "pmset", "-g", "batt")
+		if err != nil {
+			return PowerState{}, err
+		}
+		state := PowerState{OnBattery: parsePMSetBatt(batt)}
+		if therm, err := runCommand(ctx, "pmset", "-g", "therm"); err == nil {
+			state.ThermalPressure = parsePMSetTherm(therm)
+		}
+		return state, nil
+	default:
+		return PowerState{}, ErrUnsupported
+	}
+}
+
+// linuxOnBattery reports whether a battery in the power_supply class is
+// discharging.
+func linuxOnBattery(fsys fs.FS) bool {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return false
+	}
+	for _, entry := range entries {
+		if readSysfs(fsys, path.Join(entry.Name(), "type")) == "Battery" &&
+			readSysfs(fsys, path.Join(entry.Name(), "status")) == "Discharging" {
+			return true
+		}
+	}
+	return false
+}
+
+// linuxThermalPressure reports whether a thermal zone has reached one of
+// its passive trip points, where the kernel starts throttling the CPU.
+func linuxThermalPressure(fsys fs.FS) bool {
+	zones, err := fs.Glob(fsys, "thermal_zone*")
+	if err != nil {
+		return false
+	}
+	for _, zone := range zones {
+		temp, err := strconv.Atoi(readSysfs(fsys, path.Join(zone, "temp")))
+		if err != nil {
+			continue
+		}
+		trips, _ := fs.Glob(fsys, path.Join(zone, "trip_point_*_type"))
+		for _, trip := range trips {
+			if readSysfs(fsys, trip) != "passive" {
+				continue
+			}
+			limit, err := strconv.Atoi(readSysfs(fsys, strings.TrimSuffix(trip, "_type")+"_temp"))
+			if err == nil && limit > 0 && temp >= limit {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// readSysfs returns the trimmed content of a sysfs attribute, or "" if it
+// cannot be read.
+func readSysfs(fsys fs.FS, name string) string {
+	data, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
+
+// parsePMSetBatt reports whether "pmset -g batt" says power is drawn from
+// the battery.
+func parsePMSetBatt(data []byte) bool {
+	return strings.Contains(string(data), "'Battery Power'")
+}
+
+var pmsetSpeedLimit = regexp.MustCompile(`CPU_Speed_Limit\s*=\s*(\d+)`)
+
+// parsePMSetTherm reports whether "pmset -g therm" shows the CPU speed
+// limited below 100%.
+func parsePMSetTherm(data []byte) bool {
+	m := pmsetSpeedLimit.FindSubmatch(data)
+	if m == nil {
+		return false
+	}
+	limit, err := strconv.Atoi(string(m[1]))
+	return err == nil && limit < 100
+}
@@ -0,0 +1,72 @@
+package resources
+
+import (
+	"testing"
+	"testing/fstest"
+)
+
+func TestLinuxOnBattery(t *testing.T) {
+	supply := func(status string) fstest.MapFS {
+		return fstest.MapFS{
+			"AC/type":      {Data: []byte("Mains\n")},
+			"AC/online":    {Data: []byte("0\n")},
+			"BAT0/type":    {Data: []byte("Battery\n")},
+			"BAT0/status":  {Data: []byte(status + "\n")},
+			"hidpp/type":   {Data: []byte("Battery\n")},
+			"hidpp/status": {Data: []byte("Full\n")},
+		}
+	}
+
+	if !linuxOnBattery(supply("Discharging")) {
+		t.Error("expected a discharging battery to be on battery")
+	}
+	if linuxOnBattery(supply("Charging")) {
+		t.Error("expected a charging battery to be on external power")
+	}
+	if linuxOnBattery(fstest.MapFS{}) {
+		t.Error("expected a machine without a battery to be on external power")
+	}
+}
+
+func TestLinuxThermalPressure(t *testing.T) {
+	zone := func(temp string) fstest.MapFS {
+		return fstest.MapFS{
+			"thermal_zone0/temp":              {Data: []byte(temp + "\n")},
+			"thermal_zone0/trip_point_0_type": {Data: []byte("critical\n")},
+			"thermal_zone0/trip_point_0_temp": {Data: []byte("105000\n")},
+			"thermal_zone0/trip_point_1_type": {Data: []byte("passive\n")},
+			"thermal_zone0/trip_point_1_temp": {Data: []byte("95000\n")},
+			"cooling_device0/type":            {Data: []byte("Processor\n")},
+		}
+	}
+
+	if !linuxThermalPressure(zone("96000")) {
+		t.Error("expected pressure above the passive trip point")
+	}
+	if linuxThermalPressure(zone("60000")) {
+		t.Error("expected no pressure below the passive trip point")
+	}
+}
+
+func TestParsePMSet(t *testing.T) {
+	onBattery := []byte("Now drawing from 'Battery Power'\n -InternalBattery-0 (id=1234)\t81%; discharging; 5:12 remaining present: true\n")
+	onAC := []byte("Now drawing from 'AC Power'\n -InternalBattery-0 (id=1234)\t100%; charged; 0:00 remaining present: true\n")
+	if !parsePMSetBatt(onBattery) || parsePMSetBatt(onAC) {
+		t.Error("parsePMSetBatt() did not tell battery from AC power")
+	}
+
+	throttled := []byte("Note: No thermal warning level has been recorded\nCPU_Scheduler_Limit \t= 100\nCPU_Available_CPUs \t= 10\nCPU_Speed_Limit \t= 70\n")
+	cool := []byte("Note: No thermal warning level has been recorded\nNote: No performance warning level has been recorded\n")
+	if !parsePMSetTherm(throttled) || parsePMSetTherm(cool) {
+		t.Error("parsePMSetTherm() did not detect the CPU speed limit")
+	}
+}
+
+func TestPowerState_Constrained(t *testing.T) {
+	if (PowerState{}).Constrained() {
+		t.Error("expected plugged-in, cool machine to be unconstrained")
+	}
+	if !(PowerState{OnBattery: true}).Constrained() || !(PowerState{ThermalPressure: true}).Constrained() {
+		t.Error("expected battery or thermal pressure to be constrained")
+	}
+}
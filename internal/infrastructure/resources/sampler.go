@@ -0,0 +1,320 @@
+package resources
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrUnsupported is returned by the system sampler on platforms whose CPU
+// and memory usage it cannot read.
+var ErrUnsupported = errors.New("resource sampling not supported on " + runtime.GOOS)
+
+// commandTimeout bounds each external command a sample runs.
+const commandTimeout = 2 * time.Second
+
+// SystemSampler reads machine-wide usage: from /proc on Linux, from ps,
+// sysctl and vm_stat on macOS, and from nvidia-smi or, on macOS, the Metal
+// accelerator statistics in ioreg for the GPU.
+type SystemSampler struct {
+	mu      sync.Mutex
+	prevCPU cpuTimes // Linux: counters of the previous sample
+
+	nvidiaSMI string // Path to nvidia-smi, if installed
+}
+
+// Ensure SystemSampler implements Sampler at compile time.
+var _ Sampler = (*SystemSampler)(nil)
+
+// NewSystemSampler creates a sampler for the current platform. On Linux it
+// reads the CPU counters once so the first sample covers the time since.
+func NewSystemSampler() *SystemSampler {
+	s := &SystemSampler{}
+	if path, err := exec.LookPath("nvidia-smi"); err == nil {
+		s.nvidiaSMI = path
+	}
+	if runtime.GOOS == "linux" {
+		s.prevCPU, _ = readProcStat()
+	}
+	return s
+}
+
+// Sample reads the current usage.
+func (s *SystemSampler) Sample(ctx context.Context) (Sample, error) {
+	var (
+		sample Sample
+		err    error
+	)
+	switch runtime.GOOS {
+	case "linux":
+		sample, err = s.sampleLinux()
+	case "darwin":
+		sample, err = sampleDarwin(ctx)
+	default:
+		return Sample{}, ErrUnsupported
+	}
+	if err != nil {
+		return Sample{}, err
+	}
+
+	sample.GPU = s.sampleGPU(ctx)
+	return sample, nil
+}
+
+// sampleLinux reads CPU usage since the previous sample from /proc/stat and
+// memory from /proc/meminfo.
+func (s *SystemSampler) sampleLinux() (Sample, error) {
+	cpu, err := readProcStat()
+	if err != nil {
+		return Sample{}, err
+	}
+	meminfo, err := os.ReadFile("/proc/meminfo")
+	if err != nil {
+		return Sample{}, err
+	}
+	used, total, err := parseMeminfo(meminfo)
+	if err != nil {
+		return Sample{}, err
+	}
+
+	s.mu.Lock()
+	percent := cpu.percentSince(s.prevCPU)
+	s.prevCPU = cpu
+	s.mu.Unlock()
+
+	return Sample{CPUPercent: percent, MemoryUsed: used, MemoryTotal: total}, nil
+}
+
+// sampleDarwin reads CPU usage from ps, which reports a decaying average
+// per process, and memory from sysctl and vm_stat.
+func sampleDarwin(ctx context.Context) (Sample, error) {
+	ps, err := runCommand(ctx, "ps", "-A", "-o", "%cpu=")
+	if err != nil {
+		return Sample{}, err
+	}
+	memsize, err := runCommand(ctx, "sysctl", "-n", "hw.memsize")
+	if err != nil {
+		return Sample{}, err
+	}
+	total, err := strconv.ParseUint(strings.TrimSpace(string(memsize)), 10, 64)
+	if err != nil {
+		return Sample{}, fmt.Errorf("parse hw.memsize: %w", err)
+	}
+	vmStat, err := runCommand(ctx, "vm_stat")
+	if err != nil {
+		return Sample{}, err
+	}
+	used, err := parseVMStat(vmStat)
+	if err != nil {
+		return Sample{}, err
+	}
+
+	return Sample{
+		CPUPercent:  min(parsePSCPU(ps)/float64(runtime.NumCPU()), 100),
+		MemoryUsed:  used,
+		MemoryTotal: total,
+	}, nil
+}
+
+// sampleGPU reads GPU usage from nvidia-smi, or from ioreg on macOS, and
+// returns nil when neither reports it.
+func (s *SystemSampler) sampleGPU(ctx context.Context) *GPUSample {
+	if s.nvidiaSMI != "" {
+		out, err := runCommand(ctx, s.nvidiaSMI, "--query-gpu=utilization.gpu,memory.used", "--format=csv,noheader,nounits")
+		if err == nil {
+			if gpu, err := parseNvidiaSMI(out); err == nil {
+				return gpu
+			}
+		}
+	}
+	if runtime.GOOS == "darwin" {
+		out, err := runCommand(ctx, "ioreg", "-r", "-d", "1", "-w", "0", "-c", "IOAccelerator")
+		if err == nil {
+			if gpu, err := parseIOReg(out); err == nil {
+				return gpu
+			}
+		}
+	}
+	return nil
+}
+
+// runCommand runs name with args and returns its standard output.
+func runCommand(ctx context.Context, name string, args ...string) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, commandTimeout)
+	defer cancel()
+
+	out, err := exec.CommandContext(ctx, name, args...).Output()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	return out, nil
+}
+
+// cpuTimes are the aggregate CPU counters of /proc/stat.
+type cpuTimes struct {
+	busy, total uint64
+}
+
+// percentSince returns the busy share of the CPU time elapsed since prev.
+func (c cpuTimes) percentSince(prev cpuTimes) float64 {
+	if c.total <= prev.total {
+		return 0
+	}
+	return float64(c.busy-prev.busy) / float64(c.total-prev.total) * 100
+}
+
+// readProcStat reads the aggregate CPU counters.
+func readProcStat() (cpuTimes, error) {
+	data, err := os.ReadFile("/proc/stat")
+	if err != nil {
+		return cpuTimes{}, err
+	}
+	return parseProcStat(data)
+}
+
+// parseProcStat parses the aggregate "cpu" line of /proc/stat. Idle and
+// iowait time count as not busy.
+func parseProcStat(data []byte) (cpuTimes, error) {
+	line, _, _ := bytes.Cut(data, []byte("\n"))
+	fields := strings.Fields(string(line))
+	if len(fields) < 5 || fields[0] != "cpu" {
+		return cpuTimes{}, errors.New("parse /proc/stat: no aggregate cpu line")
+	}
+
+	var times cpuTimes
+	for i, field := range fields[1:] {
+		// Guest time is already included in user and nice
+		if i >= 8 {
+			break
+		}
+		v, err := strconv.ParseUint(field, 10, 64)
+		if err != nil {
+			return cpuTimes{}, fmt.Errorf("parse /proc/stat: %w", err)
+		}
+		times.total += v
+		if i != 3 && i != 4 { // idle, iowait
+			times.busy += v
+		}
+	}
+	return times, nil
+}
+
+// parseMeminfo returns the memory in use (total less available) and the
+// total memory from /proc/meminfo.
+func parseMeminfo(data []byte) (used, total uint64, err error) {
+	var available uint64
+	var hasTotal, hasAvailable bool
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 {
+			continue
+		}
+		v, err := strconv.ParseUint(fields[1], 10, 64)
+		if err != nil {
+			continue
+		}
+		switch fields[0] {
+		case "MemTotal:":
+			total, hasTotal = v*1024, true
+		case "MemAvailable:":
+			available, hasAvailable = v*1024, true
+		}
+	}
+	if !hasTotal || !hasAvailable {
+		return 0, 0, errors.New("parse /proc/meminfo: missing MemTotal or MemAvailable")
+	}
+	return total - min(available, total), total, nil
+}
+
+// parsePSCPU sums the %cpu column of ps.
+func parsePSCPU(data []byte) float64 {
+	var sum float64
+	for _, field := range strings.Fields(string(data)) {
+		if v, err := strconv.ParseFloat(field, 64); err == nil {
+			sum += v
+		}
+	}
+	return sum
+}
+
+var (
+	vmStatPageSize = regexp.MustCompile(`page size of (\d+) bytes`)
+	vmStatPages    = regexp.MustCompile(`(?m)^(Pages active|Pages wired down|Pages occupied by compressor):\s+(\d+)\.`)
+)
+
+// parseVMStat returns the memory in use from vm_stat: active, wired and
+// compressed pages.
+func parseVMStat(data []byte) (uint64, error) {
+	m := vmStatPageSize.FindSubmatch(data)
+	if m == nil {
+		return 0, errors.New("parse vm_stat: no page size")
+	}
+	pageSize, _ := strconv.ParseUint(string(m[1]), 10, 64)
+
+	var pages uint64
+	for _, m := range vmStatPages.FindAllSubmatch(data, -1) {
+		v, _ := strconv.ParseUint(string(m[2]), 10, 64)
+		pages += v
+	}
+	return pages * pageSize, nil
+}
+
+// parseNvidiaSMI parses "utilization.gpu, memory.used" CSV lines, one per
+// GPU with memory in MiB, into their average utilization and total memory.
+func parseNvidiaSMI(data []byte) (*GPUSample, error) {
+	gpu := &GPUSample{Source: "nvidia-smi"}
+	var n int
+	for line := range strings.Lines(string(data)) {
+		util, mem, ok := strings.Cut(strings.TrimSpace(line), ",")
+		if !ok {
+			continue
+		}
+		percent, err := strconv.ParseFloat(strings.TrimSpace(util), 64)
+		if err != nil {
+			continue
+		}
+		n++
+		gpu.Percent += percent
+		if mib, err := strconv.ParseFloat(strings.TrimSpace(mem), 64); err == nil {
+			gpu.MemoryUsed += uint64(mib * 1024 * 1024)
+		}
+	}
+	if n == 0 {
+		return nil, errors.New("parse nvidia-smi: no GPUs")
+	}
+	gpu.Percent /= float64(n)
+	return gpu, nil
+}
+
+var (
+	ioregUtilization = regexp.MustCompile(`"Device Utilization %"=(\d+)`)
+	ioregMemory      = regexp.MustCompile(`"In use system memory"=(\d+)`)
+)
+
+// parseIOReg reads the Metal GPU utilization and memory in use from the
+// PerformanceStatistics of ioreg's IOAccelerator entries.
+func parseIOReg(data []byte) (*GPUSample, error) {
+	m := ioregUtilization.FindSubmatch(data)
+	if m == nil {
+		return nil, errors.New("parse ioreg: no device utilization")
+	}
+	percent, _ := strconv.ParseFloat(string(m[1]), 64)
+
+	gpu := &GPUSample{Source: "metal", Percent: percent}
+	if m := ioregMemory.FindSubmatch(data); m != nil {
+		gpu.MemoryUsed, _ = strconv.ParseUint(string(m[1]), 10, 64)
+	}
+	return gpu, nil
+}
@@ -0,0 +1,94 @@
+package resources
+
+import (
+	"testing"
+)
+
+func TestParseProcStat(t *testing.T) {
+	prev, err := parseProcStat([]byte("cpu  100 0 100 700 100 0 0 0 0 0\ncpu0 50 0 50 350 50 0 0 0 0 0\n"))
+	if err != nil {
+		t.Fatalf("parseProcStat() error = %v", err)
+	}
+	cur, err := parseProcStat([]byte("cpu  200 0 200 900 100 0 0 0 50 50\n"))
+	if err != nil {
+		t.Fatalf("parseProcStat() error = %v", err)
+	}
+	if got := cur.percentSince(prev); got != 50 {
+		t.Errorf("percentSince() = %v, want 50", got)
+	}
+	if got := prev.percentSince(prev); got != 0 {
+		t.Errorf("percentSince() without elapsed time = %v, want 0", got)
+	}
+
+	if _, err := parseProcStat([]byte("intr 1 2 3\n")); err == nil {
+		t.Error("expected an error without the cpu line")
+	}
+}
+
+func TestParseMeminfo(t *testing.T) {
+	used, total, err := parseMeminfo([]byte("MemTotal:       16384000 kB\nMemFree:         1000000 kB\nMemAvailable:    4096000 kB\n"))
+	if err != nil {
+		t.Fatalf("parseMeminfo() error = %v", err)
+	}
+	if total != 16384000*1024 || used != (16384000-4096000)*1024 {
+		t.Errorf("parseMeminfo() = %d/%d", used, total)
+	}
+
+	if _, _, err := parseMeminfo([]byte("MemTotal: 100 kB\n")); err == nil {
+		t.Error("expected an error without MemAvailable")
+	}
+}
+
+func TestParseVMStat(t *testing.T) {
+	out := `Mach Virtual Memory Statistics: (page size of 16384 bytes)
+Pages free:                               10000.
+Pages active:                            100000.
+Pages inactive:                           90000.
+Pages wired down:                         50000.
+Pages occupied by compressor:             20000.
+`
+	used, err := parseVMStat([]byte(out))
+	if err != nil {
+		t.Fatalf("parseVMStat() error = %v", err)
+	}
+	if want := uint64(170000 * 16384); used != want {
+		t.Errorf("parseVMStat() = %d, want %d", used, want)
+	}
+}
+
+func TestParsePSCPU(t *testing.T) {
+	if got := parsePSCPU([]byte("  0.0\n 12.5\n150.0\n")); got != 162.5 {
+		t.Errorf("parsePSCPU() = %v, want 162.5", got)
+	}
+}
+
+func TestParseNvidiaSMI(t *testing.T) {
+	gpu, err := parseNvidiaSMI([]byte("40, 1024\n80, 2048\n"))
+	if err != nil {
+		t.Fatalf("parseNvidiaSMI() error = %v", err)
+	}
+	if gpu.Source != "nvidia-smi" || gpu.Percent != 60 || gpu.MemoryUsed != 3072<<20 {
+		t.Errorf("parseNvidiaSMI() = %+v, want 60%% and 3 GiB", gpu)
+	}
+
+	if _, err := parseNvidiaSMI([]byte("No devices were found\n")); err == nil {
+		t.Error("expected an error without GPUs")
+	}
+}
+
+func TestParseIOReg(t *testing.T) {
+	out := `+-o AGXAcceleratorG13X  <class AGXAcceleratorG13X>
+    "PerformanceStatistics" = {"In use system memory"=1073741824,"Device Utilization %"=42,"Renderer Utilization %"=40}
+`
+	gpu, err := parseIOReg([]byte(out))
+	if err != nil {
+		t.Fatalf("parseIOReg() error = %v", err)
+	}
+	if gpu.Source != "metal" || gpu.Percent != 42 || gpu.MemoryUsed != 1<<30 {
+		t.Errorf("parseIOReg() = %+v, want 42%% and 1 GiB", gpu)
+	}
+
+	if _, err := parseIOReg([]byte("")); err == nil {
+		t.Error("expected an error without PerformanceStatistics")
+	}
+}
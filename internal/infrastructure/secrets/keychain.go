@@ -0,0 +1,316 @@
+// Package secrets stores provider API keys in the OS keychain.
+package secrets
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"runtime"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/jbctechsolutions/skillrunner/internal/application/ports"
+)
+
+// DefaultService is the keychain service that skillrunner's secrets are
+// stored under.
+const DefaultService = "skillrunner"
+
+// ErrUnavailable is returned when the platform has no supported keychain or
+// its command-line tool is not installed.
+var ErrUnavailable = errors.New("no OS keychain available")
+
+// commandTimeout bounds each keychain command. It is generous because the
+// OS may ask the user to unlock the keychain first.
+const commandTimeout = 30 * time.Second
+
+// notFoundStatus is the exit status of security(1) when no item matches.
+// The Windows scripts exit with it too.
+const notFoundStatus = 44
+
+// validName matches the service and key names the keychain accepts. They
+// are passed to shell-like command interpreters, so no quoting is needed.
+var validName = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// Keychain stores secrets in the macOS Keychain, in the Secret Service
+// (GNOME Keyring, KWallet) on Linux, and in the Windows Credential Manager.
+// It drives the platform's command-line tools: security, secret-tool and
+// PowerShell. Secrets are passed on standard input, never as arguments.
+type Keychain struct {
+	service string
+	goos    string
+	run     runFunc
+}
+
+// runFunc runs a command with stdin as its input and returns its output.
+type runFunc func(ctx context.Context, stdin, name string, args ...string) (string, error)
+
+// NewKeychain creates a keychain that stores secrets under service, or
+// DefaultService if it is empty.
+func NewKeychain(service string) *Keychain {
+	if service == "" {
+		service = DefaultService
+	}
+	return &Keychain{service: service, goos: runtime.GOOS, run: runCommand}
+}
+
+// Name describes the platform keychain.
+func (k *Keychain) Name() string {
+	switch k.goos {
+	case "darwin":
+		return "macOS Keychain"
+	case "linux":
+		return "Secret Service"
+	case "windows":
+		return "Windows Credential Manager"
+	default:
+		return "unsupported (" + k.goos + ")"
+	}
+}
+
+// Get returns the secret stored under key.
+func (k *Keychain) Get(ctx context.Context, key string) (string, error) {
+	if err := k.check(key); err != nil {
+		return "", err
+	}
+
+	var (
+		out string
+		err error
+	)
+	switch k.goos {
+	case "darwin":
+		out, err = k.run(ctx, "", "security", "find-generic-password", "-s", k.service, "-a", key, "-w")
+	case "linux":
+		out, err = k.run(ctx, "", "secret-tool", "lookup", "service", k.service, "account", key)
+		// secret-tool reports a missing item with status 1 and no output
+		var cmdErr *commandError
+		if (err == nil && out == "") || (errors.As(err, &cmdErr) && cmdErr.status == 1 && cmdErr.stderr == "") {
+			err = &commandError{name: "secret-tool", status: notFoundStatus}
+		}
+	case "windows":
+		out, err = k.run(ctx, "", "powershell.exe", "-NoProfile", "-NonInteractive", "-Command", k.windowsScript(key, `
+try { $c = $vault.Retrieve($resource, $user) } catch { exit 44 }
+$c.RetrievePassword()
+[Console]::Out.Write($c.Password)`))
+	default:
+		return "", k.unsupported()
+	}
+
+	if exitStatus(err) == notFoundStatus {
+		return "", fmt.Errorf("%s: %w", key, ports.ErrSecretNotFound)
+	}
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(out, "\r\n"), nil
+}
+
+// Set stores value under key, replacing any previous value.
+func (k *Keychain) Set(ctx context.Context, key, value string) error {
+	if err := k.check(key); err != nil {
+		return err
+	}
+	if value == "" {
+		return errors.New("secret is empty")
+	}
+
+	var err error
+	switch k.goos {
+	case "darwin":
+		// security -i reads the command from stdin, keeping the secret out
+		// of the process list; -X takes it hex encoded
+		cmd := fmt.Sprintf("add-generic-password -U -s %s -a %s -X %s\n", k.service, key, hex.EncodeToString([]byte(value)))
+		_, err = k.run(ctx, cmd, "security", "-i")
+	case "linux":
+		_, err = k.run(ctx, value, "secret-tool", "store", "--label", k.service+" "+key, "service", k.service, "account", key)
+	case "windows":
+		_, err = k.run(ctx, value, "powershell.exe", "-NoProfile", "-NonInteractive", "-Command", k.windowsScript(key, `
+$secret = [Console]::In.ReadToEnd()
+$vault.Add((New-Object Windows.Security.Credentials.PasswordCredential($resource, $user, $secret)))`))
+	default:
+		return k.unsupported()
+	}
+	return err
+}
+
+// Delete removes the secret stored under key.
+func (k *Keychain) Delete(ctx context.Context, key string) error {
+	if err := k.check(key); err != nil {
+		return err
+	}
+
+	var err error
+	switch k.goos {
+	case "darwin":
+		_, err = k.run(ctx, "", "security", "delete-generic-password", "-s", k.service, "-a", key)
+	case "linux":
+		_, err = k.run(ctx, "", "secret-tool", "clear", "service", k.service, "account", key)
+	case "windows":
+		_, err = k.run(ctx, "", "powershell.exe", "-NoProfile", "-NonInteractive", "-Command", k.windowsScript(key, `
+try { $vault.Remove($vault.Retrieve($resource, $user)) } catch { exit 44 }`))
+	default:
+		return k.unsupported()
+	}
+
+	if exitStatus(err) == notFoundStatus {
+		return nil
+	}
+	return err
+}
+
+// List returns the keys of the secrets stored under the service, sorted.
+func (k *Keychain) List(ctx context.Context) ([]string, error) {
+	if !validName.MatchString(k.service) {
+		return nil, fmt.Errorf("invalid keychain service %q", k.service)
+	}
+
+	var keys []string
+	switch k.goos {
+	case "darwin":
+		// dump-keychain lists the items' attributes without their secrets
+		out, err := k.run(ctx, "", "security", "dump-keychain")
+		if err != nil {
+			return nil, err
+		}
+		keys = parseDumpKeychain(out, k.service)
+	case "linux":
+		out, err := k.run(ctx, "", "secret-tool", "search", "--all", "service", k.service)
+		if err != nil && exitStatus(err) != 1 {
+			return nil, err
+		}
+		keys = parseSecretToolSearch(out)
+	case "windows":
+		out, err := k.run(ctx, "", "powershell.exe", "-NoProfile", "-NonInteractive", "-Command", k.windowsScript("", `
+try { $vault.FindAllByResource($resource) | ForEach-Object { $_.UserName } } catch { exit 0 }`))
+		if err != nil {
+			return nil, err
+		}
+		keys = strings.Fields(out)
+	default:
+		return nil, k.unsupported()
+	}
+
+	slices.Sort(keys)
+	return slices.Compact(keys), nil
+}
+
+// parseDumpKeychain returns the accounts of the generic passwords of service
+// in the output of security dump-keychain.
+func parseDumpKeychain(out, service string) []string {
+	var keys []string
+	for _, item := range strings.Split(out, "keychain: ") {
+		if !strings.Contains(item, `class: "genp"`) {
+			continue
+		}
+		if dumpAttribute(item, "svce") == service {
+			if account := dumpAttribute(item, "acct"); account != "" {
+				keys = append(keys, account)
+			}
+		}
+	}
+	return keys
+}
+
+// dumpAttribute returns the value of a string attribute of a dump-keychain
+// item, such as "acct"<blob>="anthropic".
+func dumpAttribute(item, name string) string {
+	prefix := `"` + name + `"<blob>="`
+	for _, line := range strings.Split(item, "\n") {
+		if value, ok := strings.CutPrefix(strings.TrimSpace(line), prefix); ok {
+			return strings.TrimSuffix(value, `"`)
+		}
+	}
+	return ""
+}
+
+// parseSecretToolSearch returns the accounts in the output of secret-tool
+// search, which lists each item's attributes as attribute.name = value.
+func parseSecretToolSearch(out string) []string {
+	var keys []string
+	for _, line := range strings.Split(out, "\n") {
+		if value, ok := strings.CutPrefix(strings.TrimSpace(line), "attribute.account = "); ok {
+			keys = append(keys, value)
+		}
+	}
+	return keys
+}
+
+// check validates the service and key names.
+func (k *Keychain) check(key string) error {
+	if !validName.MatchString(k.service) {
+		return fmt.Errorf("invalid keychain service %q", k.service)
+	}
+	if !validName.MatchString(key) {
+		return fmt.Errorf("invalid secret name %q: use letters, digits, '.', '_' and '-'", key)
+	}
+	return nil
+}
+
+func (k *Keychain) unsupported() error {
+	return fmt.Errorf("%w on %s", ErrUnavailable, k.goos)
+}
+
+// windowsScript prefixes body with the PowerShell that opens the Credential
+// Manager's password vault and names the credential.
+func (k *Keychain) windowsScript(key, body string) string {
+	return fmt.Sprintf(`$ErrorActionPreference = 'Stop'
+[void][Windows.Security.Credentials.PasswordVault, Windows.Security.Credentials, ContentType = WindowsRuntime]
+$vault = New-Object Windows.Security.Credentials.PasswordVault
+$resource = '%s'
+$user = '%s'%s`, k.service, key, body)
+}
+
+// commandError is a keychain command that exited with a non-zero status.
+type commandError struct {
+	name   string
+	status int
+	stderr string
+}
+
+func (e *commandError) Error() string {
+	if e.stderr != "" {
+		return fmt.Sprintf("%s exited with status %d: %s", e.name, e.status, e.stderr)
+	}
+	return fmt.Sprintf("%s exited with status %d", e.name, e.status)
+}
+
+// exitStatus returns the exit status of a failed keychain command, or -1.
+func exitStatus(err error) int {
+	var cmdErr *commandError
+	if errors.As(err, &cmdErr) {
+		return cmdErr.status
+	}
+	return -1
+}
+
+func runCommand(ctx context.Context, stdin, name string, args ...string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, commandTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Stdin = strings.NewReader(stdin)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if errors.Is(err, exec.ErrNotFound) {
+			return "", fmt.Errorf("%w: %s is not installed", ErrUnavailable, name)
+		}
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return "", &commandError{name: name, status: exitErr.ExitCode(), stderr: strings.TrimSpace(stderr.String())}
+		}
+		return "", fmt.Errorf("%s: %w", name, err)
+	}
+	return stdout.String(), nil
+}
+
+// Ensure Keychain implements SecretStorePort
+var _ ports.SecretStorePort = (*Keychain)(nil)
@@ -0,0 +1,183 @@
+package secrets
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/jbctechsolutions/skillrunner/internal/application/ports"
+)
+
+// fakeCommand records the keychain commands run and answers them.
+type fakeCommand struct {
+	calls [][]string
+	stdin []string
+	out   string
+	err   error
+}
+
+func (f *fakeCommand) run(_ context.Context, stdin, name string, args ...string) (string, error) {
+	f.calls = append(f.calls, append([]string{name}, args...))
+	f.stdin = append(f.stdin, stdin)
+	return f.out, f.err
+}
+
+func newTestKeychain(goos string, f *fakeCommand) *Keychain {
+	return &Keychain{service: DefaultService, goos: goos, run: f.run}
+}
+
+func TestKeychain_Darwin(t *testing.T) {
+	ctx := context.Background()
+	f := &fakeCommand{out: "sk-ant-123\n"}
+	k := newTestKeychain("darwin", f)
+
+	got, err := k.Get(ctx, "anthropic")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if got != "sk-ant-123" {
+		t.Errorf("Get() = %q, want sk-ant-123", got)
+	}
+	want := []string{"security", "find-generic-password", "-s", "skillrunner", "-a", "anthropic", "-w"}
+	if !slices.Equal(f.calls[0], want) {
+		t.Errorf("Get() ran %v, want %v", f.calls[0], want)
+	}
+
+	if err := k.Set(ctx, "anthropic", "sk-ant-123"); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	if !slices.Equal(f.calls[1], []string{"security", "-i"}) {
+		t.Errorf("Set() ran %v, want security -i", f.calls[1])
+	}
+	if strings.Contains(f.stdin[1], "sk-ant-123") || !strings.Contains(f.stdin[1], "-X 736b2d616e742d313233") {
+		t.Errorf("Set() stdin = %q, want the secret hex encoded", f.stdin[1])
+	}
+
+	f.err = &commandError{name: "security", status: notFoundStatus}
+	if _, err := k.Get(ctx, "openai"); !errors.Is(err, ports.ErrSecretNotFound) {
+		t.Errorf("Get() missing error = %v, want ErrSecretNotFound", err)
+	}
+	if err := k.Delete(ctx, "openai"); err != nil {
+		t.Errorf("Delete() missing error = %v, want nil", err)
+	}
+}
+
+func TestKeychain_Linux(t *testing.T) {
+	ctx := context.Background()
+	f := &fakeCommand{}
+	k := newTestKeychain("linux", f)
+
+	if err := k.Set(ctx, "groq", "gsk-1"); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	if f.stdin[0] != "gsk-1" || slices.Contains(f.calls[0], "gsk-1") {
+		t.Errorf("Set() passed the secret as %v / %q, want it on stdin only", f.calls[0], f.stdin[0])
+	}
+
+	// No output and status 1 both mean the item is missing
+	if _, err := k.Get(ctx, "groq"); !errors.Is(err, ports.ErrSecretNotFound) {
+		t.Errorf("Get() with no output error = %v, want ErrSecretNotFound", err)
+	}
+	f.err = &commandError{name: "secret-tool", status: 1}
+	if _, err := k.Get(ctx, "groq"); !errors.Is(err, ports.ErrSecretNotFound) {
+		t.Errorf("Get() with status 1 error = %v, want ErrSecretNotFound", err)
+	}
+
+	// A failure with a message is reported as is
+	f.err = &commandError{name: "secret-tool", status: 1, stderr: "Cannot autolaunch D-Bus"}
+	if _, err := k.Get(ctx, "groq"); err == nil || errors.Is(err, ports.ErrSecretNotFound) {
+		t.Errorf("Get() error = %v, want the D-Bus failure", err)
+	}
+
+	f.err = nil
+	f.out = `[/org/freedesktop/secrets/collection/login/2]
+label = skillrunner openai
+secret = sk-1
+attribute.account = openai
+attribute.service = skillrunner
+[/org/freedesktop/secrets/collection/login/1]
+label = skillrunner groq
+secret = gsk-1
+attribute.account = groq
+attribute.service = skillrunner
+`
+	keys, err := k.List(ctx)
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if !slices.Equal(keys, []string{"groq", "openai"}) {
+		t.Errorf("List() = %v, want [groq openai]", keys)
+	}
+}
+
+func TestKeychain_Windows(t *testing.T) {
+	ctx := context.Background()
+	f := &fakeCommand{out: "sk-1\r\n"}
+	k := newTestKeychain("windows", f)
+
+	got, err := k.Get(ctx, "openai")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if got != "sk-1" {
+		t.Errorf("Get() = %q, want sk-1", got)
+	}
+	script := f.calls[0][len(f.calls[0])-1]
+	if !strings.Contains(script, "$resource = 'skillrunner'") || !strings.Contains(script, "$user = 'openai'") {
+		t.Errorf("Get() script does not name the credential:\n%s", script)
+	}
+
+	if err := k.Set(ctx, "openai", "sk-1"); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	if f.stdin[1] != "sk-1" || strings.Contains(f.calls[1][len(f.calls[1])-1], "sk-1") {
+		t.Error("Set() should pass the secret on stdin only")
+	}
+}
+
+func TestParseDumpKeychain(t *testing.T) {
+	out := `keychain: "/Users/dev/Library/Keychains/login.keychain-db"
+version: 512
+class: "genp"
+attributes:
+    "acct"<blob>="anthropic"
+    "svce"<blob>="skillrunner"
+keychain: "/Users/dev/Library/Keychains/login.keychain-db"
+version: 512
+class: "genp"
+attributes:
+    "acct"<blob>="dev@example.com"
+    "svce"<blob>="other-app"
+keychain: "/Users/dev/Library/Keychains/login.keychain-db"
+version: 512
+class: "inet"
+attributes:
+    "acct"<blob>="openai"
+    "svce"<blob>="skillrunner"
+`
+	keys := parseDumpKeychain(out, "skillrunner")
+	if !slices.Equal(keys, []string{"anthropic"}) {
+		t.Errorf("parseDumpKeychain() = %v, want [anthropic]", keys)
+	}
+}
+
+func TestKeychain_Errors(t *testing.T) {
+	ctx := context.Background()
+
+	k := newTestKeychain("plan9", &fakeCommand{})
+	if _, err := k.Get(ctx, "openai"); !errors.Is(err, ErrUnavailable) {
+		t.Errorf("Get() on an unsupported platform error = %v, want ErrUnavailable", err)
+	}
+
+	k = newTestKeychain("darwin", &fakeCommand{})
+	for _, key := range []string{"", "my key", "a;rm -rf", "'quoted'"} {
+		if _, err := k.Get(ctx, key); err == nil {
+			t.Errorf("Get(%q) should reject the name", key)
+		}
+	}
+	if err := k.Set(ctx, "openai", ""); err == nil {
+		t.Error("Set() with an empty secret should fail")
+	}
+}
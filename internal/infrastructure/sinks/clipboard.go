@@ -0,0 +1,52 @@
+package sinks
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// ErrNoClipboard is returned when no clipboard program is installed.
+var ErrNoClipboard = errors.New("no clipboard program found: install wl-clipboard, xclip or xsel")
+
+// copyToClipboard copies text to the system clipboard. It is a variable so
+// that tests can capture the text.
+var copyToClipboard = func(text string) error {
+	args, err := clipboardCommand(runtime.GOOS, os.Getenv("WAYLAND_DISPLAY") != "", exec.LookPath)
+	if err != nil {
+		return err
+	}
+	// #nosec G204 -- args is one of the fixed clipboard commands
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Stdin = strings.NewReader(text)
+	return cmd.Run()
+}
+
+// clipboardCommand returns the command that copies its stdin to the
+// clipboard on the operating system: pbcopy on macOS, clip on Windows, and
+// wl-copy under Wayland, xclip or xsel elsewhere, whichever is found first.
+func clipboardCommand(goos string, wayland bool, lookPath func(string) (string, error)) ([]string, error) {
+	var candidates [][]string
+	switch goos {
+	case "darwin":
+		candidates = [][]string{{"pbcopy"}}
+	case "windows":
+		candidates = [][]string{{"clip"}}
+	default:
+		if wayland {
+			candidates = append(candidates, []string{"wl-copy"})
+		}
+		candidates = append(candidates,
+			[]string{"xclip", "-selection", "clipboard"},
+			[]string{"xsel", "--clipboard", "--input"},
+		)
+	}
+	for _, c := range candidates {
+		if _, err := lookPath(c[0]); err == nil {
+			return c, nil
+		}
+	}
+	return nil, ErrNoClipboard
+}
@@ -0,0 +1,194 @@
+// Package sinks delivers the outputs of a completed run: to files named by
+// templates, to standard output and to the system clipboard.
+package sinks
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/jbctechsolutions/skillrunner/internal/domain/skill"
+	"github.com/jbctechsolutions/skillrunner/internal/infrastructure/templates"
+)
+
+// TimestampLayout formats the run's start time as {{.timestamp}} in file
+// name templates; it sorts in time order and has no characters file
+// systems reject.
+const TimestampLayout = "20060102-150405"
+
+// Run is a completed run whose outputs are delivered.
+type Run struct {
+	Skill       string    // skill ID
+	ExecutionID string    // correlation ID of the run
+	Time        time.Time // when the run started
+	FinalOutput string
+	Phases      []Phase // completed phases, in the skill's order
+}
+
+// Phase is the output of a completed phase.
+type Phase struct {
+	ID     string
+	Output string
+	JSON   bool // the output is a JSON document
+}
+
+// phase returns the output of the phase with the given ID, and whether the
+// phase completed.
+func (r *Run) phase(id string) (string, bool) {
+	for _, p := range r.Phases {
+		if p.ID == id {
+			return p.Output, true
+		}
+	}
+	return "", false
+}
+
+// FileNameFields are the fields of file name templates.
+var FileNameFields = []string{"skill", "execution_id", "timestamp", "date", "phase"}
+
+// FileName renders a file name template with the run's {{.skill}},
+// {{.execution_id}}, {{.timestamp}} and {{.date}} (2006-01-02), and the
+// phase as {{.phase}}, empty for the final output.
+func FileName(pattern string, run *Run, phase string) (string, error) {
+	data := map[string]any{
+		"skill":        run.Skill,
+		"execution_id": run.ExecutionID,
+		"timestamp":    run.Time.Format(TimestampLayout),
+		"date":         run.Time.Format(time.DateOnly),
+		"phase":        phase,
+	}
+	name, err := templates.Render(pattern, nil, data, nil)
+	if err != nil {
+		return "", fmt.Errorf("file name %q: %w", pattern, err)
+	}
+	if strings.TrimSpace(name) == "" {
+		return "", fmt.Errorf("file name %q is empty", pattern)
+	}
+	return filepath.Clean(name), nil
+}
+
+// WriteFile writes content to the file the template names, creating its
+// directory, and returns the file's path.
+func WriteFile(pattern string, run *Run, phase, content string) (string, error) {
+	path, err := FileName(pattern, run, phase)
+	if err != nil {
+		return "", err
+	}
+	return path, writeFile(path, content)
+}
+
+// writeFile writes content to the file at path, creating its directory.
+func writeFile(path, content string) error {
+	if dir := filepath.Dir(path); dir != "." {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return err
+		}
+	}
+	return os.WriteFile(path, []byte(content), 0o644)
+}
+
+// ErrFileExists reports a skill's file sink naming a file that exists.
+var ErrFileExists = errors.New("file exists; skill output sinks do not overwrite files")
+
+// createFile writes content to the file the template of a skill's file
+// sink names, and returns the file's path. Unlike the files the user names
+// with --output-file, it must stay within the working directory and must
+// not exist yet, so a skill from a cloned repository cannot overwrite the
+// user's files.
+func createFile(pattern string, run *Run, phase, content string) (string, error) {
+	path, err := FileName(pattern, run, phase)
+	if err != nil {
+		return "", err
+	}
+	if !filepath.IsLocal(path) {
+		return "", fmt.Errorf("%w: %q", skill.ErrOutputSinkPathNotLocal, path)
+	}
+	if dir := filepath.Dir(path); dir != "." {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return "", err
+		}
+	}
+
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+	if errors.Is(err, fs.ErrExist) {
+		return "", fmt.Errorf("%w: %s", ErrFileExists, path)
+	}
+	if err != nil {
+		return "", err
+	}
+	if _, err := io.WriteString(f, content); err != nil {
+		_ = f.Close()
+		return "", err
+	}
+	return path, f.Close()
+}
+
+// WritePhases writes the output of each phase of the run to a file of its
+// own under the directory the template names: <phase>.json for JSON
+// outputs and <phase>.md for others. It returns the files' paths.
+func WritePhases(dirPattern string, run *Run) ([]string, error) {
+	dir, err := FileName(dirPattern, run, "")
+	if err != nil {
+		return nil, err
+	}
+
+	var paths []string
+	for _, p := range run.Phases {
+		name := p.ID + ".md"
+		if p.JSON {
+			name = p.ID + ".json"
+		}
+		path := filepath.Join(dir, name)
+		if err := writeFile(path, p.Output); err != nil {
+			return paths, err
+		}
+		paths = append(paths, path)
+	}
+	return paths, nil
+}
+
+// Deliver delivers the run's outputs to the skill's sinks: new files within
+// the working directory, stdout and the clipboard. It returns the paths of the files written, and the
+// errors of the sinks that failed, after trying all of them.
+func Deliver(sinks []skill.OutputSink, run *Run, stdout io.Writer) ([]string, error) {
+	var paths []string
+	var errs []error
+	for _, sink := range sinks {
+		content := run.FinalOutput
+		if sink.Phase != "" {
+			output, ok := run.phase(sink.Phase)
+			if !ok {
+				errs = append(errs, fmt.Errorf("%s sink: phase %s did not complete", sink.Type, sink.Phase))
+				continue
+			}
+			content = output
+		}
+
+		var err error
+		switch sink.Type {
+		case skill.OutputSinkFile:
+			var path string
+			if path, err = createFile(sink.Path, run, sink.Phase, content); err == nil {
+				paths = append(paths, path)
+			}
+		case skill.OutputSinkStdout:
+			if content != "" && !strings.HasSuffix(content, "\n") {
+				content += "\n"
+			}
+			_, err = io.WriteString(stdout, content)
+		case skill.OutputSinkClipboard:
+			err = copyToClipboard(content)
+		default:
+			err = skill.ErrInvalidOutputSinkType
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s sink: %w", sink.Type, err))
+		}
+	}
+	return paths, errors.Join(errs...)
+}
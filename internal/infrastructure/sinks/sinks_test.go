@@ -0,0 +1,155 @@
+package sinks
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jbctechsolutions/skillrunner/internal/domain/skill"
+)
+
+func testRun() *Run {
+	return &Run{
+		Skill:       "code-review",
+		ExecutionID: "exec-1",
+		Time:        time.Date(2026, 3, 4, 5, 6, 7, 0, time.UTC),
+		FinalOutput: "LGTM",
+		Phases: []Phase{
+			{ID: "analyze", Output: "findings"},
+			{ID: "report", Output: `{"ok":true}`, JSON: true},
+		},
+	}
+}
+
+func TestFileName(t *testing.T) {
+	run := testRun()
+	got, err := FileName("out/{{.skill}}-{{.execution_id}}-{{.timestamp}}-{{.date}}{{if .phase}}-{{.phase}}{{end}}.md", run, "analyze")
+	if err != nil {
+		t.Fatalf("FileName() error = %v", err)
+	}
+	if want := filepath.FromSlash("out/code-review-exec-1-20260304-050607-2026-03-04-analyze.md"); got != want {
+		t.Errorf("FileName() = %q, want %q", got, want)
+	}
+
+	for _, pattern := range []string{"{{.missing}}.md", "{{", "{{.phase}}"} {
+		if _, err := FileName(pattern, run, ""); err == nil {
+			t.Errorf("FileName(%q) succeeded", pattern)
+		}
+	}
+}
+
+func TestWritePhases(t *testing.T) {
+	dir := t.TempDir()
+	paths, err := WritePhases(filepath.Join(dir, "{{.execution_id}}"), testRun())
+	if err != nil {
+		t.Fatalf("WritePhases() error = %v", err)
+	}
+	want := []string{filepath.Join(dir, "exec-1", "analyze.md"), filepath.Join(dir, "exec-1", "report.json")}
+	if !reflect.DeepEqual(paths, want) {
+		t.Errorf("WritePhases() = %q, want %q", paths, want)
+	}
+	if data, _ := os.ReadFile(want[1]); string(data) != `{"ok":true}` {
+		t.Errorf("report.json = %q", data)
+	}
+}
+
+func TestDeliver(t *testing.T) {
+	var copied string
+	saved := copyToClipboard
+	copyToClipboard = func(text string) error { copied = text; return nil }
+	defer func() { copyToClipboard = saved }()
+
+	t.Chdir(t.TempDir())
+	var stdout bytes.Buffer
+	paths, err := Deliver([]skill.OutputSink{
+		{Type: skill.OutputSinkFile, Path: filepath.Join("out", "{{.skill}}.md")},
+		{Type: skill.OutputSinkStdout, Phase: "analyze"},
+		{Type: skill.OutputSinkClipboard},
+		{Type: skill.OutputSinkStdout, Phase: "skipped"},
+	}, testRun(), &stdout)
+
+	if err == nil || !strings.Contains(err.Error(), "phase skipped did not complete") {
+		t.Errorf("Deliver() error = %v, want the incomplete phase's", err)
+	}
+	if len(paths) != 1 || paths[0] != filepath.Join("out", "code-review.md") {
+		t.Errorf("Deliver() paths = %q", paths)
+	}
+	if data, _ := os.ReadFile(paths[0]); string(data) != "LGTM" {
+		t.Errorf("file = %q, want the final output", data)
+	}
+	if stdout.String() != "findings\n" {
+		t.Errorf("stdout = %q, want the analyze output", stdout.String())
+	}
+	if copied != "LGTM" {
+		t.Errorf("clipboard = %q, want the final output", copied)
+	}
+}
+
+func TestDeliver_ProtectsFiles(t *testing.T) {
+	t.Chdir(t.TempDir())
+	if err := os.WriteFile("notes.md", []byte("mine"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := Deliver([]skill.OutputSink{{Type: skill.OutputSinkFile, Path: "notes.md"}}, testRun(), io.Discard)
+	if !errors.Is(err, ErrFileExists) {
+		t.Errorf("Deliver() to an existing file error = %v, want ErrFileExists", err)
+	}
+	if data, _ := os.ReadFile("notes.md"); string(data) != "mine" {
+		t.Errorf("notes.md = %q, want it untouched", data)
+	}
+
+	run := testRun()
+	run.Skill = "../escape"
+	if _, err := Deliver([]skill.OutputSink{{Type: skill.OutputSinkFile, Path: "{{.skill}}.md"}}, run, io.Discard); !errors.Is(err, skill.ErrOutputSinkPathNotLocal) {
+		t.Errorf("Deliver() outside the working directory error = %v, want ErrOutputSinkPathNotLocal", err)
+	}
+
+	// The files the user names explicitly may be overwritten
+	if _, err := WriteFile("notes.md", testRun(), "", "LGTM"); err != nil {
+		t.Errorf("WriteFile() error = %v", err)
+	}
+}
+
+func TestClipboardCommand(t *testing.T) {
+	installed := func(names ...string) func(string) (string, error) {
+		return func(name string) (string, error) {
+			for _, n := range names {
+				if n == name {
+					return "/usr/bin/" + name, nil
+				}
+			}
+			return "", errors.New("not found")
+		}
+	}
+
+	tests := []struct {
+		name    string
+		goos    string
+		wayland bool
+		lookup  func(string) (string, error)
+		want    []string
+	}{
+		{"macOS", "darwin", false, installed("pbcopy"), []string{"pbcopy"}},
+		{"wayland", "linux", true, installed("wl-copy", "xclip"), []string{"wl-copy"}},
+		{"x11", "linux", false, installed("wl-copy", "xsel"), []string{"xsel", "--clipboard", "--input"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := clipboardCommand(tt.goos, tt.wayland, tt.lookup)
+			if err != nil || !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("clipboardCommand() = %q, %v; want %q", got, err, tt.want)
+			}
+		})
+	}
+
+	if _, err := clipboardCommand("linux", false, installed()); !errors.Is(err, ErrNoClipboard) {
+		t.Errorf("clipboardCommand() without programs error = %v, want ErrNoClipboard", err)
+	}
+}
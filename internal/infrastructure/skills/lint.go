@@ -0,0 +1,126 @@
+package skills
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/jbctechsolutions/skillrunner/internal/domain/skill"
+	"github.com/jbctechsolutions/skillrunner/internal/infrastructure/sinks"
+	"github.com/jbctechsolutions/skillrunner/internal/infrastructure/templates"
+)
+
+// lintTemplates checks the templates of a skill before any runs: that the
+// phases' templates and the partials parse and refer only to the input, the
+// input files, a foreach phase's item, or the output of a phase they depend
+// on, since rendering fails on a missing key, and that the file names of
+// output sinks refer only to the run's fields.
+func lintTemplates(s *skill.Skill) error {
+	phases := s.Phases()
+	ids := make([]string, len(phases))
+	for i := range phases {
+		ids[i] = phases[i].ID
+	}
+
+	var errs []error
+	if len(phases) > 0 {
+		if _, err := templates.Parse("", phases[0].Partials); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	for i := range phases {
+		p := &phases[i]
+		item := p.ForEach != nil
+		check := func(field, text string, item bool) {
+			if text == "" {
+				return
+			}
+			if err := lintTemplate(p, text, item, ids); err != nil {
+				errs = append(errs, fmt.Errorf("phase %s: %s: %w", p.ID, field, err))
+			}
+		}
+		check("prompt_template", p.PromptTemplate, item)
+		check("input_audio", p.InputAudio, item)
+		check("when", p.When, false)
+		check("unless", p.Unless, false)
+		if item {
+			check("foreach items", p.ForEach.Items, false)
+		}
+	}
+
+	for _, o := range s.OutputSinks() {
+		if o.Path == "" {
+			continue
+		}
+		if err := lintFileName(o.Path); err != nil {
+			errs = append(errs, fmt.Errorf("outputs: %s sink: %w", o.Type, err))
+		}
+	}
+
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+	return nil
+}
+
+// lintFileName checks that a file name template of an output sink parses
+// and refers only to the fields file names are rendered with.
+func lintFileName(text string) error {
+	fields, err := templates.Fields(text)
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for _, field := range fields {
+		if !slices.Contains(sinks.FileNameFields, field) {
+			errs = append(errs, fmt.Errorf("refers to .%s, which is not one of %s", field, strings.Join(sinks.FileNameFields, ", ")))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// lintTemplate checks that text parses and refers only to fields a phase
+// is rendered with: _input or input, the variables of the input files,
+// _item and _index for the items of a foreach phase, and the outputs of the
+// phases it depends on, directly or under phases. ids lists the skill's
+// phases, to point out a missing dependency.
+func lintTemplate(p *skill.Phase, text string, item bool, ids []string) error {
+	fields, err := templates.Fields(text)
+	if err != nil {
+		return err
+	}
+
+	known := func(name string) bool {
+		switch name {
+		case "_input", "input":
+			return true
+		case skill.FilesVariable, skill.FilePathsVariable, skill.FileChunksVariable:
+			return true
+		case "_item", "_index":
+			return item
+		}
+		return slices.Contains(p.DependsOn, name)
+	}
+
+	var errs []error
+	for _, field := range fields {
+		ident := strings.Split(field, ".")
+		name := ident[0]
+		switch {
+		case name == "phases" && len(ident) > 1:
+			name = ident[1]
+		case name == "phases" && len(p.DependsOn) > 0:
+			continue
+		}
+		if known(name) {
+			continue
+		}
+		if slices.Contains(ids, name) {
+			errs = append(errs, fmt.Errorf("refers to .%s, but the phase does not depend on %s; add it to depends_on", field, name))
+		} else {
+			errs = append(errs, fmt.Errorf("refers to .%s, which is neither the input nor the output of a phase it depends on", field))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -2,37 +2,266 @@
 package skills
 
 import (
+	"cmp"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"gopkg.in/yaml.v3"
 
 	"github.com/jbctechsolutions/skillrunner/internal/domain/skill"
+	"github.com/jbctechsolutions/skillrunner/internal/infrastructure/config"
 )
 
 // SkillDefinition represents the YAML structure of a skill definition file.
 type SkillDefinition struct {
-	ID          string            `yaml:"id"`
-	Name        string            `yaml:"name"`
-	Version     string            `yaml:"version"`
-	Description string            `yaml:"description"`
-	Phases      []PhaseDefinition `yaml:"phases"`
-	Routing     RoutingDefinition `yaml:"routing"`
-	Metadata    map[string]any    `yaml:"metadata"`
+	ID            string                   `yaml:"id"`
+	Name          string                   `yaml:"name"`
+	Version       string                   `yaml:"version"`
+	Description   string                   `yaml:"description"`
+	Phases        []PhaseDefinition        `yaml:"phases"`
+	Routing       RoutingDefinition        `yaml:"routing"`
+	LongContext   *LongContextDefinition   `yaml:"long_context"`   // default for every phase
+	ContextBudget *ContextBudgetDefinition `yaml:"context_budget"` // default for every completion phase
+	Provider      string                   `yaml:"provider"`       // default provider pin for every completion phase
+	PinSoft       bool                     `yaml:"pin_soft"`       // applies to the skill's provider pin
+	Model         string                   `yaml:"model"`          // default model pin for every completion phase
+	Requires      []RequirementDefinition  `yaml:"requires"`       // programs the skill needs on PATH
+	Moderation    string                   `yaml:"moderation"`     // block, warn or off; overrides the providers' moderation action
+	Metadata      map[string]any           `yaml:"metadata"`
+
+	// Concurrency is the default concurrency group of every phase.
+	Concurrency string `yaml:"concurrency_group"`
+
+	// Glossary is the default glossary of every completion phase with text
+	// output.
+	Glossary *GlossaryDefinition `yaml:"glossary"`
+
+	// Partials are named templates every phase's templates can render with
+	// {{template "name" .}} or {{include "name" .}}.
+	Partials map[string]string `yaml:"partials"`
+
+	// Outputs are where completed runs deliver the final output or the
+	// outputs of phases.
+	Outputs []OutputSinkDefinition `yaml:"outputs"`
+}
+
+// OutputSinkDefinition represents the YAML structure of an output sink.
+//
+//	outputs:
+//	  - type: file
+//	    path: reviews/{{.skill}}-{{.timestamp}}.md
+//	  - type: clipboard
+//	    phase: summary
+type OutputSinkDefinition struct {
+	Type  string `yaml:"type"`  // file, stdout or clipboard
+	Path  string `yaml:"path"`  // file name template of file sinks
+	Phase string `yaml:"phase"` // phase whose output is delivered; default the final output
+}
+
+// RequirementDefinition represents an entry of a skill's requires list:
+// either a program name or a mapping with the name and an install hint.
+//
+//	requires:
+//	  - git
+//	  - name: golangci-lint
+//	    install: go install github.com/golangci/golangci-lint/cmd/golangci-lint@latest
+type RequirementDefinition struct {
+	Name    string `yaml:"name"`
+	Install string `yaml:"install"`
+}
+
+// UnmarshalYAML accepts a program name as well as a mapping.
+func (r *RequirementDefinition) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		r.Name = node.Value
+		return nil
+	}
+	type plain RequirementDefinition
+	return node.Decode((*plain)(r))
+}
+
+// JSONSchema describes both forms of a requirement.
+func (RequirementDefinition) JSONSchema() *config.Schema {
+	return &config.Schema{OneOf: []*config.Schema{
+		{Type: "string"},
+		{Type: "object", Properties: map[string]*config.Schema{
+			"name":    {Type: "string"},
+			"install": {Type: "string"},
+		}},
+	}}
+}
+
+// Schema returns the JSON Schema document of skill definition files.
+func Schema() *config.Schema {
+	return config.NewSchemaDocument(SkillDefinition{}, "skillrunner skill")
 }
 
 // PhaseDefinition represents the YAML structure of a phase within a skill.
 type PhaseDefinition struct {
-	ID             string   `yaml:"id"`
-	Name           string   `yaml:"name"`
-	PromptTemplate string   `yaml:"prompt_template"`
-	RoutingProfile string   `yaml:"routing_profile"`
-	DependsOn      []string `yaml:"depends_on"`
-	MaxTokens      int      `yaml:"max_tokens"`
-	Temperature    float32  `yaml:"temperature"`
+	ID             string                   `yaml:"id"`
+	Name           string                   `yaml:"name"`
+	PromptTemplate string                   `yaml:"prompt_template"`
+	RoutingProfile string                   `yaml:"routing_profile"`
+	DependsOn      []string                 `yaml:"depends_on"`
+	Optional       bool                     `yaml:"optional"` // may be left out to fit sr run --within
+	MaxTokens      int                      `yaml:"max_tokens"`
+	Temperature    float32                  `yaml:"temperature"`
+	Provider       string                   `yaml:"provider"`
+	PinSoft        bool                     `yaml:"pin_soft"`
+	Model          string                   `yaml:"model"` // overrides the routing profile's model
+	OutputFormat   string                   `yaml:"output_format"`
+	OutputSchema   any                      `yaml:"output_schema"`  // YAML mapping or JSON string
+	InputAudio     string                   `yaml:"input_audio"`    // audio file to transcribe (template)
+	Image          *ImageDefinition         `yaml:"image"`          // options for output_format: image
+	ExtractTables  string                   `yaml:"extract_tables"` // save Markdown tables as csv, tsv or json
+	LongContext    *LongContextDefinition   `yaml:"long_context"`   // overrides the skill's long_context
+	ContextBudget  *ContextBudgetDefinition `yaml:"context_budget"` // overrides the skill's context_budget
+	Capabilities   []string                 `yaml:"capabilities"`   // model capabilities the phase needs
+
+	// SimilarityGuard checks that the output does not echo the input.
+	SimilarityGuard *SimilarityGuardDefinition `yaml:"similarity_guard"`
+
+	// Concurrency overrides the skill's concurrency_group.
+	Concurrency string `yaml:"concurrency_group"`
+
+	// Glossary overrides the skill's glossary.
+	Glossary *GlossaryDefinition `yaml:"glossary"`
+
+	// Retries, backoff and on_failure retry a failing phase, waiting
+	// backoff (a duration such as "2s", doubled on each retry) before each
+	// retry, and with on_failure: escalate_model send it once more to the
+	// skill's routing.review_model, or the premium profile's model.
+	Retries   int    `yaml:"retries"`
+	Backoff   string `yaml:"backoff"`
+	OnFailure string `yaml:"on_failure"`
+
+	// Validators check the output; one that fails sends the phase again
+	// with its error, up to max_validation_attempts times in all (default
+	// 3), before the phase fails.
+	Validators            []ValidatorDefinition `yaml:"validators"`
+	MaxValidationAttempts int                   `yaml:"max_validation_attempts"`
+
+	// Timeout bounds each attempt of the phase (a duration such as "30s");
+	// an attempt that times out is retried and escalated like any failure.
+	Timeout string `yaml:"timeout"`
+
+	// When and Unless run the phase only if a condition on its dependency
+	// outputs holds, or does not, e.g. `{{.classify}} == "bug"`.
+	When   string `yaml:"when"`
+	Unless string `yaml:"unless"`
+
+	// ForEach runs the phase once per item of a list.
+	ForEach *ForEachDefinition `yaml:"foreach"`
+
+	// Skill runs another registered skill instead of a prompt, with input
+	// (a template, default "{{._input}}") as its input. Its final output,
+	// or that of its phase named by output, is the phase's output.
+	Skill  string `yaml:"skill"`
+	Input  string `yaml:"input"`
+	Output string `yaml:"output"`
+}
+
+// ForEachDefinition represents the YAML structure of a phase's foreach:
+// either the items template or a mapping with the items and options.
+//
+//	foreach: "{{.list_files}}"
+//
+//	foreach:
+//	  items: "{{.list_files}}"
+//	  split: lines        # auto (default), json or lines
+//	  parallel: 4
+//	  aggregate: json     # concat (default) or json
+type ForEachDefinition struct {
+	Items     string `yaml:"items"`
+	Split     string `yaml:"split"`
+	Parallel  int    `yaml:"parallel"`
+	Aggregate string `yaml:"aggregate"`
+	Separator string `yaml:"separator"`
+}
+
+// UnmarshalYAML accepts the items template as well as a mapping.
+func (f *ForEachDefinition) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		f.Items = node.Value
+		return nil
+	}
+	type plain ForEachDefinition
+	return node.Decode((*plain)(f))
+}
+
+// JSONSchema describes both forms of a foreach.
+func (*ForEachDefinition) JSONSchema() *config.Schema {
+	return &config.Schema{OneOf: []*config.Schema{
+		{Type: "string"},
+		{Type: "object", Properties: map[string]*config.Schema{
+			"items":     {Type: "string"},
+			"split":     {Type: "string"},
+			"parallel":  {Type: "integer"},
+			"aggregate": {Type: "string"},
+			"separator": {Type: "string"},
+		}},
+	}}
+}
+
+// ImageDefinition represents the YAML structure of a phase's image options.
+type ImageDefinition struct {
+	Size           string `yaml:"size"`
+	Count          int    `yaml:"count"`
+	NegativePrompt string `yaml:"negative_prompt"`
+}
+
+// SimilarityGuardDefinition represents the YAML structure of a phase's
+// similarity guard.
+type SimilarityGuardDefinition struct {
+	MaxSimilarity float64 `yaml:"max_similarity"` // defaults to 0.9
+	Action        string  `yaml:"action"`         // retry (default) or flag
+}
+
+// ValidatorDefinition represents the YAML structure of an output
+// validator, which sets exactly one check.
+type ValidatorDefinition struct {
+	Regex      string `yaml:"regex"`       // pattern the output must match
+	JSONSchema any    `yaml:"json_schema"` // YAML mapping or JSON string
+	Script     string `yaml:"script"`      // shell command run in the skill file's directory
+	Dir        string `yaml:"-"`           // the skill file's directory
+}
+
+// GlossaryDefinition represents the YAML structure of a glossary: terms
+// listed inline, in a glossary file, or both.
+type GlossaryDefinition struct {
+	File   string                   `yaml:"file"` // glossary file with a terms list, relative to the skill file
+	Terms  []GlossaryTermDefinition `yaml:"terms"`
+	Action string                   `yaml:"action"` // report (default) or correct
+}
+
+// GlossaryTermDefinition represents the YAML structure of a glossary term.
+type GlossaryTermDefinition struct {
+	Term  string   `yaml:"term"`
+	Avoid []string `yaml:"avoid"`
+}
+
+// LongContextDefinition represents the YAML structure of a long-context
+// strategy. Window tokens default to the skill's routing.max_context_tokens.
+type LongContextDefinition struct {
+	Strategy        string `yaml:"strategy"`
+	WindowTokens    int    `yaml:"window_tokens"`
+	CarryoverTokens int    `yaml:"carryover_tokens"`
+	MapProfile      string `yaml:"map_profile"`    // map_reduce defaults to cheap, chunked to the phase's profile
+	ReduceProfile   string `yaml:"reduce_profile"` // map_reduce and chunked; defaults to the phase's profile
+	ReducePrompt    string `yaml:"reduce_prompt"`  // chunked only; merges the chunk outputs
+}
+
+// ContextBudgetDefinition represents the YAML structure of a context budget.
+// Max tokens default to the skill's routing.max_context_tokens.
+type ContextBudgetDefinition struct {
+	MaxTokens  int            `yaml:"max_tokens"`
+	Shares     map[string]int `yaml:"shares"`     // percentage per component
+	Priorities map[string]int `yaml:"priorities"` // priority per component, higher kept first
 }
 
 // RoutingDefinition represents the YAML structure of routing configuration.
@@ -60,9 +289,16 @@ func NewLoader() *Loader {
 	return &Loader{}
 }
 
+// envKeys are the keys of skill files whose values expand ${VAR}
+// references: routing settings only. Prompts, partials and other text are
+// kept as written, so a skill cannot put environment values, such as API
+// keys, in what it sends to a model.
+var envKeys = []string{"routing", "routing_profile", "provider", "model", "max_tokens", "temperature"}
+
 // LoadSkill loads a single skill definition from a YAML file.
-// It reads the file, parses the YAML content, validates the structure,
-// and converts it to a domain Skill type.
+// It reads the file, parses the YAML content with ${VAR} references in its
+// routing settings expanded, validates the structure, and converts it to a
+// domain Skill type.
 func (l *Loader) LoadSkill(path string) (*skill.Skill, error) {
 	// Validate the path
 	if err := validatePath(path); err != nil {
@@ -79,9 +315,10 @@ func (l *Loader) LoadSkill(path string) (*skill.Skill, error) {
 		return nil, fmt.Errorf("%w: %s", ErrEmptyFile, path)
 	}
 
-	// Parse YAML
+	// Parse YAML, expanding ${VAR} references in routing settings, and check
+	// it against the schema
 	var def SkillDefinition
-	if err := yaml.Unmarshal(data, &def); err != nil {
+	if err := config.UnmarshalYAMLWithSchemaKeys(data, &def, Schema(), envKeys...); err != nil {
 		return nil, fmt.Errorf("failed to parse YAML in %s: %w", path, err)
 	}
 
@@ -90,6 +327,18 @@ func (l *Loader) LoadSkill(path string) (*skill.Skill, error) {
 		return nil, fmt.Errorf("invalid skill definition in %s: %w", path, err)
 	}
 
+	// Read the glossary files the skill refers to
+	if err := loadGlossaryFiles(&def, filepath.Dir(path)); err != nil {
+		return nil, fmt.Errorf("invalid skill definition in %s: %w", path, err)
+	}
+
+	// Validator scripts run next to the skill file
+	for i := range def.Phases {
+		for j := range def.Phases[i].Validators {
+			def.Phases[i].Validators[j].Dir = filepath.Dir(path)
+		}
+	}
+
 	// Convert to domain type
 	return convertToDomainSkill(&def)
 }
@@ -159,6 +408,30 @@ func (l *Loader) LoadSkillsDir(dir string) (map[string]*skill.Skill, error) {
 	return skills, nil
 }
 
+// FindSkillFile returns the path of the skill file under dir that defines
+// the skill with the given ID, or "" if there is none. Files that fail to
+// load are skipped.
+func (l *Loader) FindSkillFile(dir, id string) (string, error) {
+	var found string
+	err := filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || validatePath(path) != nil {
+			return nil
+		}
+		if s, err := l.LoadSkill(path); err == nil && s.ID() == id {
+			found = path
+			return filepath.SkipAll
+		}
+		return nil
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to walk directory %s: %w", dir, err)
+	}
+	return found, nil
+}
+
 // validatePath checks if the path is valid for a skill file.
 func validatePath(path string) error {
 	if path == "" {
@@ -205,16 +478,67 @@ func validateDefinition(def *SkillDefinition) error {
 			errs = append(errs, fmt.Errorf("phase %d (%s): name is required", i, phase.ID))
 		}
 
-		if strings.TrimSpace(phase.PromptTemplate) == "" {
+		switch {
+		case strings.TrimSpace(phase.Skill) != "":
+			if strings.TrimSpace(phase.PromptTemplate) != "" {
+				errs = append(errs, fmt.Errorf("phase %d (%s): skill phases take input instead of prompt_template", i, phase.ID))
+			}
+		case strings.TrimSpace(phase.PromptTemplate) == "":
 			errs = append(errs, fmt.Errorf("phase %d (%s): prompt_template is required", i, phase.ID))
+		case phase.Input != "" || phase.Output != "":
+			errs = append(errs, fmt.Errorf("phase %d (%s): input and output require skill", i, phase.ID))
 		}
 
 		// Validate routing profile if provided
 		if phase.RoutingProfile != "" {
-			if !isValidRoutingProfile(phase.RoutingProfile) {
+			if !skill.IsValidProfileName(phase.RoutingProfile) {
 				errs = append(errs, fmt.Errorf("phase %d (%s): invalid routing_profile %q", i, phase.ID, phase.RoutingProfile))
 			}
 		}
+
+		if phase.PinSoft && strings.TrimSpace(phase.Provider) == "" {
+			errs = append(errs, fmt.Errorf("phase %d (%s): pin_soft requires provider", i, phase.ID))
+		}
+
+		switch phase.OutputFormat {
+		case "", skill.OutputFormatText, skill.OutputFormatJSON, skill.OutputFormatImage:
+		default:
+			errs = append(errs, fmt.Errorf("phase %d (%s): invalid output_format %q", i, phase.ID, phase.OutputFormat))
+		}
+		if phase.OutputSchema != nil && phase.OutputFormat != "" && phase.OutputFormat != skill.OutputFormatJSON {
+			errs = append(errs, fmt.Errorf("phase %d (%s): output_schema requires output_format json", i, phase.ID))
+		}
+		if strings.TrimSpace(phase.InputAudio) != "" && (phase.OutputFormat == skill.OutputFormatJSON || phase.OutputFormat == skill.OutputFormatImage || phase.OutputSchema != nil) {
+			errs = append(errs, fmt.Errorf("phase %d (%s): input_audio phases produce text output", i, phase.ID))
+		}
+		if phase.Image != nil && phase.OutputFormat != skill.OutputFormatImage {
+			errs = append(errs, fmt.Errorf("phase %d (%s): image requires output_format image", i, phase.ID))
+		}
+		switch phase.ExtractTables {
+		case "", skill.TableFormatCSV, skill.TableFormatTSV, skill.TableFormatJSON:
+		default:
+			errs = append(errs, fmt.Errorf("phase %d (%s): invalid extract_tables %q: must be csv, tsv or json", i, phase.ID, phase.ExtractTables))
+		}
+		if phase.ExtractTables != "" && phase.OutputFormat == skill.OutputFormatImage {
+			errs = append(errs, fmt.Errorf("phase %d (%s): extract_tables cannot be used with output_format image", i, phase.ID))
+		}
+		if err := validateLongContext(phase.LongContext); err != nil {
+			errs = append(errs, fmt.Errorf("phase %d (%s): %w", i, phase.ID, err))
+		}
+		if err := validateContextBudget(phase.ContextBudget); err != nil {
+			errs = append(errs, fmt.Errorf("phase %d (%s): %w", i, phase.ID, err))
+		}
+	}
+
+	if err := validateLongContext(def.LongContext); err != nil {
+		errs = append(errs, err)
+	}
+	if err := validateContextBudget(def.ContextBudget); err != nil {
+		errs = append(errs, err)
+	}
+
+	if def.PinSoft && strings.TrimSpace(def.Provider) == "" {
+		errs = append(errs, errors.New("pin_soft requires provider"))
 	}
 
 	// Validate phase dependencies
@@ -228,7 +552,7 @@ func validateDefinition(def *SkillDefinition) error {
 
 	// Validate routing config if provided
 	if def.Routing.DefaultProfile != "" {
-		if !isValidRoutingProfile(def.Routing.DefaultProfile) {
+		if !skill.IsValidProfileName(def.Routing.DefaultProfile) {
 			errs = append(errs, fmt.Errorf("routing: invalid default_profile %q", def.Routing.DefaultProfile))
 		}
 	}
@@ -240,18 +564,51 @@ func validateDefinition(def *SkillDefinition) error {
 	return nil
 }
 
-// isValidRoutingProfile checks if the profile is a valid routing profile.
-func isValidRoutingProfile(profile string) bool {
-	switch profile {
-	case skill.RoutingProfileCheap, skill.RoutingProfileBalanced, skill.RoutingProfilePremium:
-		return true
+// validateLongContext checks a long_context definition, if any. The
+// carryover guard is checked once window_tokens defaults are applied.
+func validateLongContext(def *LongContextDefinition) error {
+	if def == nil {
+		return nil
+	}
+	switch def.Strategy {
+	case skill.LongContextSlidingWindow:
+		if def.MapProfile != "" || def.ReduceProfile != "" {
+			return errors.New("long_context: map_profile and reduce_profile require strategy map_reduce or chunked")
+		}
+	case skill.LongContextMapReduce, skill.LongContextChunked:
+		for _, profile := range []string{def.MapProfile, def.ReduceProfile} {
+			if profile != "" && !skill.IsValidProfileName(profile) {
+				return fmt.Errorf("long_context: invalid profile %q: must be cheap, balanced, premium or a custom profile name", profile)
+			}
+		}
 	default:
-		return false
+		return fmt.Errorf("long_context: invalid strategy %q: must be sliding_window, map_reduce or chunked", def.Strategy)
 	}
+	if def.ReducePrompt != "" && def.Strategy != skill.LongContextChunked {
+		return errors.New("long_context: reduce_prompt requires strategy chunked")
+	}
+	if def.WindowTokens < 0 || def.CarryoverTokens < 0 {
+		return errors.New("long_context: window_tokens and carryover_tokens must be non-negative")
+	}
+	return nil
+}
+
+// validateContextBudget checks a context_budget definition, if any. An
+// unset max_tokens stands for the routing default, validated with the skill.
+func validateContextBudget(def *ContextBudgetDefinition) error {
+	if def == nil {
+		return nil
+	}
+	if def.MaxTokens < 0 {
+		return skill.ErrInvalidContextTokens
+	}
+	return convertToDomainContextBudget(def, 1).Validate()
 }
 
 // convertToDomainSkill converts a YAML definition to a domain Skill.
 func convertToDomainSkill(def *SkillDefinition) (*skill.Skill, error) {
+	routing := convertToDomainRouting(&def.Routing)
+
 	// Convert phases
 	phases := make([]skill.Phase, 0, len(def.Phases))
 	for _, phaseDef := range def.Phases {
@@ -259,6 +616,27 @@ func convertToDomainSkill(def *SkillDefinition) (*skill.Skill, error) {
 		if err != nil {
 			return nil, fmt.Errorf("failed to convert phase %s: %w", phaseDef.ID, err)
 		}
+		if lc := cmp.Or(phaseDef.LongContext, def.LongContext); lc != nil && phase.IsCompletion() {
+			phase.WithLongContext(convertToDomainLongContext(lc, routing.MaxContextTokens))
+		}
+		if cb := cmp.Or(phaseDef.ContextBudget, def.ContextBudget); cb != nil && phase.IsCompletion() {
+			phase.WithContextBudget(convertToDomainContextBudget(cb, routing.MaxContextTokens))
+		}
+		if phase.IsCompletion() {
+			applySkillPins(phase, def)
+		}
+		phase.WithConcurrencyGroup(cmp.Or(phaseDef.Concurrency, def.Concurrency))
+		if len(def.Partials) > 0 {
+			phase.WithPartials(def.Partials)
+		}
+		if phaseDef.Glossary != nil {
+			phase.WithGlossary(convertToDomainGlossary(phaseDef.Glossary))
+		} else if def.Glossary != nil && phase.IsCompletion() && !phase.WantsJSON() {
+			phase.WithGlossary(convertToDomainGlossary(def.Glossary))
+		}
+		if phase.Retry != nil && phase.Retry.Escalates() {
+			phase.Retry.EscalateModel = routing.ReviewModel
+		}
 		phases = append(phases, *phase)
 	}
 
@@ -274,9 +652,26 @@ func convertToDomainSkill(def *SkillDefinition) (*skill.Skill, error) {
 	}
 
 	// Set routing configuration
-	routing := convertToDomainRouting(&def.Routing)
 	s.SetRouting(routing)
 
+	if len(def.Requires) > 0 {
+		requires := make([]skill.Requirement, len(def.Requires))
+		for i, r := range def.Requires {
+			requires[i] = skill.Requirement{Name: strings.TrimSpace(r.Name), Hint: strings.TrimSpace(r.Install)}
+		}
+		s.SetRequires(requires)
+	}
+
+	s.SetModeration(strings.TrimSpace(def.Moderation))
+
+	if len(def.Outputs) > 0 {
+		outputs := make([]skill.OutputSink, len(def.Outputs))
+		for i, o := range def.Outputs {
+			outputs[i] = skill.OutputSink{Type: strings.TrimSpace(o.Type), Path: strings.TrimSpace(o.Path), Phase: strings.TrimSpace(o.Phase)}
+		}
+		s.SetOutputSinks(outputs)
+	}
+
 	// Set metadata
 	for k, v := range def.Metadata {
 		s.SetMetadata(k, v)
@@ -286,13 +681,31 @@ func convertToDomainSkill(def *SkillDefinition) (*skill.Skill, error) {
 	if err := s.Validate(); err != nil {
 		return nil, fmt.Errorf("skill validation failed: %w", err)
 	}
+	if err := lintTemplates(s); err != nil {
+		return nil, fmt.Errorf("skill templates: %w", err)
+	}
 
 	return s, nil
 }
 
+// applySkillPins pins a phase to the skill's provider and model where the
+// phase does not pin its own.
+func applySkillPins(phase *skill.Phase, def *SkillDefinition) {
+	if !phase.IsPinned() && def.Provider != "" {
+		phase.WithProvider(def.Provider).WithPinSoft(def.PinSoft)
+	}
+	if !phase.IsModelPinned() && def.Model != "" {
+		phase.WithModel(def.Model)
+	}
+}
+
 // convertToDomainPhase converts a YAML phase definition to a domain Phase.
 func convertToDomainPhase(def *PhaseDefinition) (*skill.Phase, error) {
-	phase, err := skill.NewPhase(def.ID, def.Name, def.PromptTemplate)
+	promptTemplate := def.PromptTemplate
+	if def.Skill != "" {
+		promptTemplate = cmp.Or(strings.TrimSpace(def.Input), "{{._input}}")
+	}
+	phase, err := skill.NewPhase(def.ID, def.Name, promptTemplate)
 	if err != nil {
 		return nil, err
 	}
@@ -314,9 +727,208 @@ func convertToDomainPhase(def *PhaseDefinition) (*skill.Phase, error) {
 		phase.WithTemperature(def.Temperature)
 	}
 
+	if def.Provider != "" {
+		phase.WithProvider(def.Provider).WithPinSoft(def.PinSoft)
+	}
+
+	if def.Model != "" {
+		phase.WithModel(def.Model)
+	}
+
+	phase.WithOptional(def.Optional)
+
+	if def.OutputFormat != "" {
+		phase.WithOutputFormat(def.OutputFormat)
+	}
+
+	if def.OutputSchema != nil {
+		schema, err := convertSchema("output_schema", def.OutputSchema)
+		if err != nil {
+			return nil, err
+		}
+		phase.WithOutputSchema(schema)
+	}
+
+	if def.InputAudio != "" {
+		phase.WithInputAudio(def.InputAudio)
+	}
+
+	if def.Image != nil {
+		phase.WithImageOptions(skill.ImageOptions{
+			Size:           def.Image.Size,
+			Count:          def.Image.Count,
+			NegativePrompt: def.Image.NegativePrompt,
+		})
+	}
+
+	if def.ExtractTables != "" {
+		phase.WithExtractTables(def.ExtractTables)
+	}
+
+	if len(def.Capabilities) > 0 {
+		phase.WithCapabilities(def.Capabilities...)
+	}
+
+	if def.SimilarityGuard != nil {
+		phase.WithSimilarityGuard(&skill.SimilarityGuardConfig{
+			MaxSimilarity: def.SimilarityGuard.MaxSimilarity,
+			Action:        strings.TrimSpace(def.SimilarityGuard.Action),
+		})
+	}
+
+	if def.ForEach != nil {
+		phase.WithForEach(&skill.ForEachConfig{
+			Items:     strings.TrimSpace(def.ForEach.Items),
+			Split:     strings.TrimSpace(def.ForEach.Split),
+			Parallel:  def.ForEach.Parallel,
+			Aggregate: strings.TrimSpace(def.ForEach.Aggregate),
+			Separator: def.ForEach.Separator,
+		})
+	}
+
+	if def.Skill != "" {
+		phase.WithSubSkill(&skill.SubSkillConfig{Skill: def.Skill, Output: def.Output})
+	}
+
+	if def.When != "" {
+		phase.WithWhen(def.When)
+	}
+
+	if def.Unless != "" {
+		phase.WithUnless(def.Unless)
+	}
+
+	if def.Retries != 0 || def.Backoff != "" || def.OnFailure != "" {
+		policy := &skill.RetryPolicy{Retries: def.Retries, OnFailure: strings.TrimSpace(def.OnFailure)}
+		if def.Backoff != "" {
+			backoff, err := time.ParseDuration(strings.TrimSpace(def.Backoff))
+			if err != nil {
+				return nil, fmt.Errorf("invalid backoff %q: %w", def.Backoff, err)
+			}
+			policy.Backoff = backoff
+		}
+		phase.WithRetry(policy)
+	}
+
+	if len(def.Validators) > 0 || def.MaxValidationAttempts != 0 {
+		cfg := &skill.ValidationConfig{MaxAttempts: def.MaxValidationAttempts}
+		for _, v := range def.Validators {
+			validator := skill.OutputValidator{Regex: v.Regex, Script: strings.TrimSpace(v.Script), Dir: v.Dir}
+			if v.JSONSchema != nil {
+				schema, err := convertSchema("validator json_schema", v.JSONSchema)
+				if err != nil {
+					return nil, err
+				}
+				validator.JSONSchema = schema
+			}
+			cfg.Validators = append(cfg.Validators, validator)
+		}
+		phase.WithValidation(cfg)
+	}
+
+	if def.Timeout != "" {
+		timeout, err := time.ParseDuration(strings.TrimSpace(def.Timeout))
+		if err != nil {
+			return nil, fmt.Errorf("invalid timeout %q: %w", def.Timeout, err)
+		}
+		phase.WithTimeout(timeout)
+	}
+
 	return phase, nil
 }
 
+// convertSchema converts the value of a schema field, such as
+// output_schema, to JSON. The schema may be written as a YAML mapping or as
+// a JSON string.
+func convertSchema(field string, v any) (json.RawMessage, error) {
+	if s, ok := v.(string); ok {
+		if !json.Valid([]byte(s)) {
+			return nil, fmt.Errorf("%s is not valid JSON", field)
+		}
+		return json.RawMessage(s), nil
+	}
+
+	schema, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert %s: %w", field, err)
+	}
+	return schema, nil
+}
+
+// loadGlossaryFiles adds the terms of the glossary files of a skill and its
+// phases, relative to dir, to their inline terms.
+func loadGlossaryFiles(def *SkillDefinition, dir string) error {
+	glossaries := []*GlossaryDefinition{def.Glossary}
+	for i := range def.Phases {
+		glossaries = append(glossaries, def.Phases[i].Glossary)
+	}
+	for _, g := range glossaries {
+		if g == nil || g.File == "" {
+			continue
+		}
+		path := g.File
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(dir, path)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("glossary: %w", err)
+		}
+		var file struct {
+			Terms []GlossaryTermDefinition `yaml:"terms"`
+		}
+		if err := yaml.Unmarshal(data, &file); err != nil {
+			return fmt.Errorf("glossary %s: %w", g.File, err)
+		}
+		g.Terms = append(g.Terms, file.Terms...)
+		g.File = ""
+	}
+	return nil
+}
+
+// convertToDomainGlossary converts a YAML glossary definition, with the
+// terms of its file already loaded, to a domain Glossary.
+func convertToDomainGlossary(def *GlossaryDefinition) *skill.Glossary {
+	g := &skill.Glossary{Action: strings.TrimSpace(def.Action)}
+	for _, t := range def.Terms {
+		g.Terms = append(g.Terms, skill.GlossaryTerm{Term: strings.TrimSpace(t.Term), Avoid: t.Avoid})
+	}
+	return g
+}
+
+// convertToDomainLongContext converts a YAML long_context definition to a
+// domain LongContextConfig, defaulting the window to maxContextTokens.
+func convertToDomainLongContext(def *LongContextDefinition, maxContextTokens int) *skill.LongContextConfig {
+	window := def.WindowTokens
+	if window == 0 {
+		window = maxContextTokens
+	}
+	switch def.Strategy {
+	case skill.LongContextMapReduce:
+		cfg := skill.NewMapReduce(window, def.CarryoverTokens)
+		cfg.MapProfile = cmp.Or(def.MapProfile, cfg.MapProfile)
+		cfg.ReduceProfile = def.ReduceProfile
+		return cfg
+	case skill.LongContextChunked:
+		cfg := skill.NewChunked(window, def.CarryoverTokens)
+		cfg.MapProfile = def.MapProfile
+		cfg.ReduceProfile = def.ReduceProfile
+		cfg.ReducePrompt = strings.TrimSpace(def.ReducePrompt)
+		return cfg
+	}
+	return skill.NewSlidingWindow(window, def.CarryoverTokens)
+}
+
+// convertToDomainContextBudget converts a YAML context_budget definition to
+// a domain ContextBudgetConfig, defaulting max tokens to maxContextTokens.
+func convertToDomainContextBudget(def *ContextBudgetDefinition, maxContextTokens int) *skill.ContextBudgetConfig {
+	return &skill.ContextBudgetConfig{
+		MaxTokens:  cmp.Or(def.MaxTokens, maxContextTokens),
+		Shares:     def.Shares,
+		Priorities: def.Priorities,
+	}
+}
+
 // convertToDomainRouting converts a YAML routing definition to a domain RoutingConfig.
 func convertToDomainRouting(def *RoutingDefinition) skill.RoutingConfig {
 	routing := skill.NewRoutingConfig()
@@ -1,9 +1,13 @@
 package skills
 
 import (
+	"errors"
 	"os"
 	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/jbctechsolutions/skillrunner/internal/domain/skill"
 )
@@ -36,7 +40,7 @@ phases:
   - id: generate
     name: Generation Phase
     prompt_template: |
-      Based on the analysis: {{.analyze_output}}
+      Based on the analysis: {{.analyze}}
       Generate a response.
     routing_profile: premium
     depends_on:
@@ -157,6 +161,219 @@ phases:
 	}
 }
 
+func TestLoadSkill_EnvExpansion(t *testing.T) {
+	t.Setenv("SR_TEST_PROFILE", "premium")
+	tmpDir := t.TempDir()
+
+	skillYAML := `
+id: env-skill
+name: Env Skill
+phases:
+  - id: main
+    name: Main Phase
+    prompt_template: Review for ${SR_TEST_TEAM:-platform} as ${user.name} in ${HOME}
+    routing_profile: ${SR_TEST_PROFILE}
+    max_tokens: ${SR_TEST_MAX_TOKENS:-2048}
+`
+	skillPath := filepath.Join(tmpDir, "env.yaml")
+	if err := os.WriteFile(skillPath, []byte(skillYAML), 0644); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+
+	s, err := NewLoader().LoadSkill(skillPath)
+	if err != nil {
+		t.Fatalf("LoadSkill() error = %v", err)
+	}
+
+	phase := s.Phases()[0]
+	// Prompts are not expanded
+	if want := "Review for ${SR_TEST_TEAM:-platform} as ${user.name} in ${HOME}"; phase.PromptTemplate != want {
+		t.Errorf("phase.PromptTemplate = %q, want %q", phase.PromptTemplate, want)
+	}
+	if phase.RoutingProfile != "premium" {
+		t.Errorf("phase.RoutingProfile = %q, want premium", phase.RoutingProfile)
+	}
+	if phase.MaxTokens != 2048 {
+		t.Errorf("phase.MaxTokens = %d, want 2048", phase.MaxTokens)
+	}
+}
+
+func TestLoadSkill_Requires(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	skillYAML := `
+id: go-refactor
+name: Go Refactor
+requires:
+  - git
+  - name: golangci-lint
+    install: go install github.com/golangci/golangci-lint/cmd/golangci-lint@latest
+phases:
+  - id: main
+    name: Main Phase
+    prompt_template: Refactor {{.input}}
+`
+	skillPath := filepath.Join(tmpDir, "go-refactor.yaml")
+	if err := os.WriteFile(skillPath, []byte(skillYAML), 0644); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+
+	s, err := NewLoader().LoadSkill(skillPath)
+	if err != nil {
+		t.Fatalf("LoadSkill() error = %v", err)
+	}
+
+	want := []skill.Requirement{
+		{Name: "git"},
+		{Name: "golangci-lint", Hint: "go install github.com/golangci/golangci-lint/cmd/golangci-lint@latest"},
+	}
+	if got := s.Requires(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Requires() = %+v, want %+v", got, want)
+	}
+}
+
+func TestLoadSkill_Moderation(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	skillYAML := `
+id: triage
+name: Triage
+moderation: warn
+phases:
+  - id: main
+    name: Main Phase
+    prompt_template: Triage {{._input}}
+`
+	skillPath := filepath.Join(tmpDir, "triage.yaml")
+	if err := os.WriteFile(skillPath, []byte(skillYAML), 0644); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+
+	s, err := NewLoader().LoadSkill(skillPath)
+	if err != nil {
+		t.Fatalf("LoadSkill() error = %v", err)
+	}
+	if got := s.Moderation(); got != skill.ModerationWarn {
+		t.Errorf("Moderation() = %q, want %q", got, skill.ModerationWarn)
+	}
+
+	invalid := strings.Replace(skillYAML, "moderation: warn", "moderation: ask", 1)
+	if err := os.WriteFile(skillPath, []byte(invalid), 0644); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+	if _, err := NewLoader().LoadSkill(skillPath); !errors.Is(err, skill.ErrInvalidModeration) {
+		t.Errorf("LoadSkill() error = %v, want %v", err, skill.ErrInvalidModeration)
+	}
+}
+
+func TestLoadSkill_Outputs(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	skillYAML := `
+id: review
+name: Review
+outputs:
+  - type: file
+    path: reviews/{{.skill}}-{{.timestamp}}.md
+  - type: clipboard
+    phase: main
+phases:
+  - id: main
+    name: Main Phase
+    prompt_template: Review {{._input}}
+`
+	skillPath := filepath.Join(tmpDir, "review.yaml")
+	if err := os.WriteFile(skillPath, []byte(skillYAML), 0644); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+
+	s, err := NewLoader().LoadSkill(skillPath)
+	if err != nil {
+		t.Fatalf("LoadSkill() error = %v", err)
+	}
+	want := []skill.OutputSink{
+		{Type: skill.OutputSinkFile, Path: "reviews/{{.skill}}-{{.timestamp}}.md"},
+		{Type: skill.OutputSinkClipboard, Phase: "main"},
+	}
+	if got := s.OutputSinks(); !reflect.DeepEqual(got, want) {
+		t.Errorf("OutputSinks() = %+v, want %+v", got, want)
+	}
+
+	tests := []struct {
+		name, old, new, wantErr string
+	}{
+		{"unknown phase", "phase: main", "phase: summary", "output sink phase not found"},
+		{"unknown field", "{{.timestamp}}", "{{.input}}", "refers to .input"},
+		{"unknown type", "type: clipboard", "type: email", "invalid output sink type"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			broken := strings.Replace(skillYAML, tt.old, tt.new, 1)
+			if err := os.WriteFile(skillPath, []byte(broken), 0644); err != nil {
+				t.Fatalf("failed to write test file: %v", err)
+			}
+			if _, err := NewLoader().LoadSkill(skillPath); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("LoadSkill() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoadSkill_PartialsAndTemplateLint(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	skillYAML := `
+id: review
+name: Review
+partials:
+  rules: "Follow the Go style guide."
+phases:
+  - id: plan
+    name: Plan
+    prompt_template: '{{template "rules" .}} Plan a review of {{.input}} in {{._file_paths}}'
+  - id: review
+    name: Review
+    depends_on: [plan]
+    when: '{{.plan}} != ""'
+    prompt_template: '{{include "rules" . | upper}} Review {{._input}} per {{.phases.plan}}'
+`
+	skillPath := filepath.Join(tmpDir, "review.yaml")
+	if err := os.WriteFile(skillPath, []byte(skillYAML), 0644); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+	s, err := NewLoader().LoadSkill(skillPath)
+	if err != nil {
+		t.Fatalf("LoadSkill() error = %v", err)
+	}
+	for _, p := range s.Phases() {
+		if p.Partials["rules"] != "Follow the Go style guide." {
+			t.Errorf("phase %s partials = %v, want the skill's", p.ID, p.Partials)
+		}
+	}
+
+	tests := []struct {
+		name, old, new, wantErr string
+	}{
+		{"missing dependency", "depends_on: [plan]", "depends_on: []", "does not depend on plan"},
+		{"unknown field", "{{.input}}", "{{.diff}}", "refers to .diff"},
+		{"item outside foreach", "{{.input}}", "{{._item}}", "refers to ._item"},
+		{"invalid partial", `rules: "Follow`, `rules: "{{ Follow`, "partial rules"},
+		{"invalid function", "| upper", "| nosuchfunc", "nosuchfunc"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			broken := strings.Replace(skillYAML, tt.old, tt.new, 1)
+			if err := os.WriteFile(skillPath, []byte(broken), 0644); err != nil {
+				t.Fatalf("failed to write test file: %v", err)
+			}
+			_, err := NewLoader().LoadSkill(skillPath)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("LoadSkill() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
 func TestLoadSkill_YMLExtension(t *testing.T) {
 	tmpDir := t.TempDir()
 
@@ -272,6 +489,37 @@ name: [invalid yaml
 	}
 }
 
+func TestLoadSkill_SchemaErrors(t *testing.T) {
+	tmpDir := t.TempDir()
+	skillPath := filepath.Join(tmpDir, "typo.yaml")
+	skillYAML := `
+id: typo
+name: Typo
+phases:
+  - id: main
+    name: Main Phase
+    prompt_template: Summarize {{.input}}
+    max_tokns: 100
+    temperature: warm
+`
+	if err := os.WriteFile(skillPath, []byte(skillYAML), 0644); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+
+	_, err := NewLoader().LoadSkill(skillPath)
+	if err == nil {
+		t.Fatal("LoadSkill() error = nil, want schema errors")
+	}
+	for _, want := range []string{
+		"line 8, column 5: phases[0].max_tokns: unknown field; did you mean max_tokens?",
+		`line 9, column 18: phases[0].temperature: expected a number, got "warm"`,
+	} {
+		if !containsString(err.Error(), want) {
+			t.Errorf("LoadSkill() error = %v, want %q", err, want)
+		}
+	}
+}
+
 func TestLoadSkill_ValidationErrors(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -351,7 +599,7 @@ phases:
   - id: main
     name: Main
     prompt_template: Test
-    routing_profile: invalid
+    routing_profile: Invalid Profile
 `,
 			wantErr: "invalid routing_profile",
 		},
@@ -507,6 +755,38 @@ phases:
 	}
 }
 
+func TestFindSkillFile(t *testing.T) {
+	tmpDir := t.TempDir()
+	subDir := filepath.Join(tmpDir, "subdir")
+	if err := os.Mkdir(subDir, 0755); err != nil {
+		t.Fatalf("failed to create subdir: %v", err)
+	}
+
+	nested := `
+id: nested-skill
+name: Nested Skill
+phases:
+  - id: main
+    name: Main
+    prompt_template: Test
+`
+	path := filepath.Join(subDir, "nested.yaml")
+	if err := os.WriteFile(path, []byte(nested), 0644); err != nil {
+		t.Fatalf("failed to write nested skill: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(tmpDir, "broken.yaml"), []byte("id: [unclosed"), 0644); err != nil {
+		t.Fatalf("failed to write broken skill: %v", err)
+	}
+
+	loader := NewLoader()
+	if got, err := loader.FindSkillFile(tmpDir, "nested-skill"); err != nil || got != path {
+		t.Errorf("FindSkillFile() = %q, %v; want %q", got, err, path)
+	}
+	if got, err := loader.FindSkillFile(tmpDir, "other"); err != nil || got != "" {
+		t.Errorf("FindSkillFile(other) = %q, %v; want no file", got, err)
+	}
+}
+
 func TestLoadSkillsDir_IgnoresNonYAMLFiles(t *testing.T) {
 	tmpDir := t.TempDir()
 
@@ -695,29 +975,6 @@ phases:
 	}
 }
 
-func TestIsValidRoutingProfile(t *testing.T) {
-	tests := []struct {
-		profile string
-		valid   bool
-	}{
-		{"cheap", true},
-		{"balanced", true},
-		{"premium", true},
-		{"Cheap", false},
-		{"BALANCED", false},
-		{"invalid", false},
-		{"", false},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.profile, func(t *testing.T) {
-			if got := isValidRoutingProfile(tt.profile); got != tt.valid {
-				t.Errorf("isValidRoutingProfile(%q) = %v, want %v", tt.profile, got, tt.valid)
-			}
-		})
-	}
-}
-
 func TestLoadSkill_DemoDocGenSkill(t *testing.T) {
 	// Test that the doc-gen.yaml demo skill can be loaded successfully
 	loader := NewLoader()
@@ -804,3 +1061,1057 @@ func contains(s, substr string) bool {
 	}
 	return false
 }
+
+func TestLoadSkill_ProviderPin(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	pinnedYAML := `
+id: pinned-skill
+name: Pinned Skill
+phases:
+  - id: redact
+    name: Redact PII
+    prompt_template: Redact {{._input}}
+    provider: ollama
+  - id: summarize
+    name: Summarize
+    prompt_template: Summarize {{.redact}}
+    provider: anthropic
+    pin_soft: true
+    depends_on:
+      - redact
+`
+	skillPath := filepath.Join(tmpDir, "pinned.yaml")
+	if err := os.WriteFile(skillPath, []byte(pinnedYAML), 0644); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+
+	s, err := NewLoader().LoadSkill(skillPath)
+	if err != nil {
+		t.Fatalf("LoadSkill() error: %v", err)
+	}
+
+	redact, err := s.GetPhase("redact")
+	if err != nil {
+		t.Fatalf("GetPhase(redact) error: %v", err)
+	}
+	if redact.Provider != "ollama" || redact.PinSoft {
+		t.Errorf("expected redact to be hard-pinned to ollama, got %+v", redact)
+	}
+
+	summarize, err := s.GetPhase("summarize")
+	if err != nil {
+		t.Fatalf("GetPhase(summarize) error: %v", err)
+	}
+	if summarize.Provider != "anthropic" || !summarize.PinSoft {
+		t.Errorf("expected summarize to be soft-pinned to anthropic, got %+v", summarize)
+	}
+}
+
+func TestLoadSkill_SkillPins(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	pinnedYAML := `
+id: pinned-skill
+name: Pinned Skill
+provider: anthropic
+pin_soft: true
+model: claude-3-5-sonnet-20241022
+phases:
+  - id: draft
+    name: Draft
+    prompt_template: Draft {{._input}}
+  - id: redact
+    name: Redact PII
+    prompt_template: Redact {{.draft}}
+    provider: ollama
+    model: llama3.2:3b
+    depends_on:
+      - draft
+`
+	skillPath := filepath.Join(tmpDir, "pinned.yaml")
+	if err := os.WriteFile(skillPath, []byte(pinnedYAML), 0644); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+
+	s, err := NewLoader().LoadSkill(skillPath)
+	if err != nil {
+		t.Fatalf("LoadSkill() error: %v", err)
+	}
+
+	draft, err := s.GetPhase("draft")
+	if err != nil {
+		t.Fatalf("GetPhase(draft) error: %v", err)
+	}
+	if draft.Provider != "anthropic" || !draft.PinSoft || draft.Model != "claude-3-5-sonnet-20241022" {
+		t.Errorf("expected draft to inherit the skill pins, got %+v", draft)
+	}
+
+	redact, err := s.GetPhase("redact")
+	if err != nil {
+		t.Fatalf("GetPhase(redact) error: %v", err)
+	}
+	if redact.Provider != "ollama" || redact.PinSoft || redact.Model != "llama3.2:3b" {
+		t.Errorf("expected redact to keep its own pins, got %+v", redact)
+	}
+}
+
+func TestLoadSkill_ConcurrencyGroup(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	groupYAML := `
+id: gpu-skill
+name: GPU Skill
+concurrency_group: gpu
+phases:
+  - id: caption
+    name: Caption
+    prompt_template: Caption {{._input}}
+  - id: scrape
+    name: Scrape
+    prompt_template: Scrape {{.caption}}
+    concurrency_group: scraper
+    depends_on:
+      - caption
+`
+	skillPath := filepath.Join(tmpDir, "gpu.yaml")
+	if err := os.WriteFile(skillPath, []byte(groupYAML), 0644); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+
+	s, err := NewLoader().LoadSkill(skillPath)
+	if err != nil {
+		t.Fatalf("LoadSkill() error: %v", err)
+	}
+
+	caption, _ := s.GetPhase("caption")
+	scrape, _ := s.GetPhase("scrape")
+	if caption.ConcurrencyGroup != "gpu" || scrape.ConcurrencyGroup != "scraper" {
+		t.Errorf("expected groups gpu and scraper, got %q and %q", caption.ConcurrencyGroup, scrape.ConcurrencyGroup)
+	}
+}
+
+func TestLoadSkill_SimilarityGuard(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	guardYAML := `
+id: rewrite-skill
+name: Rewrite Skill
+phases:
+  - id: rewrite
+    name: Rewrite
+    prompt_template: Rewrite formally {{._input}}
+    similarity_guard:
+      max_similarity: 0.8
+      action: flag
+  - id: translate
+    name: Translate
+    prompt_template: Translate {{.rewrite}}
+    similarity_guard: {}
+    depends_on:
+      - rewrite
+`
+	skillPath := filepath.Join(tmpDir, "rewrite.yaml")
+	if err := os.WriteFile(skillPath, []byte(guardYAML), 0644); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+
+	s, err := NewLoader().LoadSkill(skillPath)
+	if err != nil {
+		t.Fatalf("LoadSkill() error: %v", err)
+	}
+
+	rewrite, _ := s.GetPhase("rewrite")
+	if g := rewrite.SimilarityGuard; g == nil || g.MaxSimilarity != 0.8 || g.Action != skill.SimilarityActionFlag {
+		t.Errorf("rewrite guard = %+v, want max 0.8 and flag", g)
+	}
+	translate, _ := s.GetPhase("translate")
+	if g := translate.SimilarityGuard; g == nil || g.Threshold() != skill.DefaultMaxSimilarity || !g.Retries() {
+		t.Errorf("translate guard = %+v, want the defaults", g)
+	}
+
+	invalid := strings.Replace(guardYAML, "action: flag", "action: fail", 1)
+	if err := os.WriteFile(skillPath, []byte(invalid), 0644); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+	if _, err := NewLoader().LoadSkill(skillPath); err == nil {
+		t.Error("LoadSkill() with an unknown action succeeded")
+	}
+}
+
+func TestLoadSkill_RetryPolicy(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	retryYAML := `
+id: summary-skill
+name: Summary Skill
+routing:
+  review_model: claude-sonnet
+phases:
+  - id: summarize
+    name: Summarize
+    prompt_template: Summarize {{._input}}
+    routing_profile: cheap
+    retries: 2
+    backoff: 500ms
+    on_failure: escalate_model
+  - id: title
+    name: Title
+    prompt_template: Title {{.summarize}}
+    retries: 1
+    depends_on:
+      - summarize
+`
+	skillPath := filepath.Join(tmpDir, "summary.yaml")
+	if err := os.WriteFile(skillPath, []byte(retryYAML), 0644); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+
+	s, err := NewLoader().LoadSkill(skillPath)
+	if err != nil {
+		t.Fatalf("LoadSkill() error: %v", err)
+	}
+
+	summarize, _ := s.GetPhase("summarize")
+	if r := summarize.Retry; r == nil || r.Retries != 2 || r.Backoff != 500*time.Millisecond || !r.Escalates() || r.EscalateModel != "claude-sonnet" {
+		t.Errorf("summarize retry = %+v, want 2 retries after 500ms escalating to claude-sonnet", r)
+	}
+	title, _ := s.GetPhase("title")
+	if r := title.Retry; r == nil || r.Retries != 1 || r.Escalates() || r.EscalateModel != "" {
+		t.Errorf("title retry = %+v, want 1 retry without escalation", r)
+	}
+
+	for _, invalid := range []string{
+		strings.Replace(retryYAML, "backoff: 500ms", "backoff: soon", 1),
+		strings.Replace(retryYAML, "on_failure: escalate_model", "on_failure: skip", 1),
+	} {
+		if err := os.WriteFile(skillPath, []byte(invalid), 0644); err != nil {
+			t.Fatalf("failed to write test file: %v", err)
+		}
+		if _, err := NewLoader().LoadSkill(skillPath); err == nil {
+			t.Error("LoadSkill() with an invalid retry policy succeeded")
+		}
+	}
+}
+
+func TestLoadSkill_Conditions(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	conditionYAML := `
+id: triage-skill
+name: Triage Skill
+phases:
+  - id: classify
+    name: Classify
+    prompt_template: Classify {{._input}} as bug or feature
+  - id: fix
+    name: Fix
+    prompt_template: Propose a fix for {{._input}}
+    when: '{{.classify}} == "bug"'
+    depends_on: [classify]
+  - id: spec
+    name: Spec
+    prompt_template: Write a spec for {{._input}}
+    unless: '{{.classify}} == "bug"'
+    depends_on: [classify]
+`
+	skillPath := filepath.Join(tmpDir, "triage.yaml")
+	if err := os.WriteFile(skillPath, []byte(conditionYAML), 0644); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+
+	s, err := NewLoader().LoadSkill(skillPath)
+	if err != nil {
+		t.Fatalf("LoadSkill() error: %v", err)
+	}
+	fix, _ := s.GetPhase("fix")
+	spec, _ := s.GetPhase("spec")
+	if fix.When != `{{.classify}} == "bug"` || spec.Unless != `{{.classify}} == "bug"` {
+		t.Errorf("when = %q, unless = %q", fix.When, spec.Unless)
+	}
+
+	invalid := strings.Replace(conditionYAML, `== "bug"'`, `=='`, 1)
+	if err := os.WriteFile(skillPath, []byte(invalid), 0644); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+	if _, err := NewLoader().LoadSkill(skillPath); err == nil {
+		t.Error("LoadSkill() with an invalid condition succeeded")
+	}
+}
+
+func TestLoadSkill_ForEach(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	foreachYAML := `
+id: review-files
+name: Review Files
+phases:
+  - id: list
+    name: List Files
+    prompt_template: List the changed files of {{._input}}
+  - id: review
+    name: Review File
+    prompt_template: Review {{._item}}
+    foreach: "{{.list}}"
+    depends_on: [list]
+  - id: score
+    name: Score File
+    prompt_template: Score {{._item}}
+    foreach:
+      items: "{{.list}}"
+      split: lines
+      parallel: 4
+      aggregate: json
+    depends_on: [list]
+`
+	skillPath := filepath.Join(tmpDir, "review.yaml")
+	if err := os.WriteFile(skillPath, []byte(foreachYAML), 0644); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+
+	s, err := NewLoader().LoadSkill(skillPath)
+	if err != nil {
+		t.Fatalf("LoadSkill() error: %v", err)
+	}
+	review, _ := s.GetPhase("review")
+	if f := review.ForEach; f == nil || f.Items != "{{.list}}" || f.Workers() != 1 {
+		t.Errorf("review foreach = %+v, want the items with the defaults", f)
+	}
+	score, _ := s.GetPhase("score")
+	want := skill.ForEachConfig{Items: "{{.list}}", Split: skill.ForEachSplitLines, Parallel: 4, Aggregate: skill.ForEachAggregateJSON}
+	if f := score.ForEach; f == nil || *f != want {
+		t.Errorf("score foreach = %+v, want %+v", f, want)
+	}
+
+	invalid := strings.Replace(foreachYAML, "split: lines", "split: csv", 1)
+	if err := os.WriteFile(skillPath, []byte(invalid), 0644); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+	if _, err := NewLoader().LoadSkill(skillPath); err == nil {
+		t.Error("LoadSkill() with an unknown split succeeded")
+	}
+}
+
+func TestLoadSkill_Glossary(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	glossaryYAML := `
+terms:
+  - term: GitHub
+  - term: sign in
+    avoid: [log in, login]
+`
+	if err := os.WriteFile(filepath.Join(tmpDir, "style.yaml"), []byte(glossaryYAML), 0644); err != nil {
+		t.Fatalf("failed to write glossary: %v", err)
+	}
+
+	skillYAML := `
+id: docs-skill
+name: Docs Skill
+glossary:
+  file: style.yaml
+  terms:
+    - term: Skillrunner
+  action: correct
+phases:
+  - id: draft
+    name: Draft
+    prompt_template: Document {{._input}}
+  - id: outline
+    name: Outline
+    prompt_template: Outline {{._input}}
+    output_format: json
+  - id: summary
+    name: Summary
+    prompt_template: Summarize {{.draft}}
+    depends_on: [draft]
+    glossary:
+      terms:
+        - term: TL;DR
+`
+	skillPath := filepath.Join(tmpDir, "docs.yaml")
+	if err := os.WriteFile(skillPath, []byte(skillYAML), 0644); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+
+	s, err := NewLoader().LoadSkill(skillPath)
+	if err != nil {
+		t.Fatalf("LoadSkill() error: %v", err)
+	}
+
+	draft, _ := s.GetPhase("draft")
+	if g := draft.Glossary; g == nil || !g.Corrects() || len(g.Terms) != 3 || g.Terms[0].Term != "Skillrunner" || g.Terms[2].Avoid[1] != "login" {
+		t.Errorf("draft glossary = %+v, want the inline and file terms", g)
+	}
+	if outline, _ := s.GetPhase("outline"); outline.Glossary != nil {
+		t.Errorf("json phase glossary = %+v, want none", outline.Glossary)
+	}
+	if summary, _ := s.GetPhase("summary"); summary.Glossary == nil || summary.Glossary.Corrects() || len(summary.Glossary.Terms) != 1 {
+		t.Errorf("summary glossary = %+v, want its own", summary.Glossary)
+	}
+
+	missing := strings.Replace(skillYAML, "style.yaml", "missing.yaml", 1)
+	if err := os.WriteFile(skillPath, []byte(missing), 0644); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+	if _, err := NewLoader().LoadSkill(skillPath); err == nil {
+		t.Error("LoadSkill() with a missing glossary file succeeded")
+	}
+}
+
+func TestLoadSkill_OptionalPhase(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	optionalYAML := `
+id: optional-skill
+name: Optional Skill
+phases:
+  - id: draft
+    name: Draft
+    prompt_template: Draft {{._input}}
+  - id: examples
+    name: Examples
+    prompt_template: Add examples to {{.draft}}
+    optional: true
+    depends_on:
+      - draft
+`
+	skillPath := filepath.Join(tmpDir, "optional.yaml")
+	if err := os.WriteFile(skillPath, []byte(optionalYAML), 0644); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+
+	s, err := NewLoader().LoadSkill(skillPath)
+	if err != nil {
+		t.Fatalf("LoadSkill() error: %v", err)
+	}
+
+	draft, _ := s.GetPhase("draft")
+	examples, _ := s.GetPhase("examples")
+	if draft.Optional || !examples.Optional {
+		t.Errorf("expected only examples to be optional, got draft=%v examples=%v", draft.Optional, examples.Optional)
+	}
+}
+
+func TestLoadSkill_PinSoftWithoutProvider(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	invalidYAML := `
+id: bad-pin
+name: Bad Pin
+phases:
+  - id: main
+    name: Main
+    prompt_template: Test
+    pin_soft: true
+`
+	skillPath := filepath.Join(tmpDir, "bad-pin.yaml")
+	if err := os.WriteFile(skillPath, []byte(invalidYAML), 0644); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+
+	_, err := NewLoader().LoadSkill(skillPath)
+	if err == nil || !contains(err.Error(), "pin_soft requires provider") {
+		t.Errorf("expected pin_soft validation error, got %v", err)
+	}
+
+	skillLevelYAML := `
+id: bad-pin
+name: Bad Pin
+pin_soft: true
+phases:
+  - id: main
+    name: Main
+    prompt_template: Test
+`
+	if err := os.WriteFile(skillPath, []byte(skillLevelYAML), 0644); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+	if _, err := NewLoader().LoadSkill(skillPath); err == nil || !contains(err.Error(), "pin_soft requires provider") {
+		t.Errorf("expected skill-level pin_soft validation error, got %v", err)
+	}
+}
+
+func TestLoadSkill_OutputSchema(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	schemaYAML := `
+id: structured
+name: Structured
+phases:
+  - id: extract
+    name: Extract
+    prompt_template: Extract {{._input}}
+    output_schema:
+      type: object
+      required: [title, tags]
+      properties:
+        title: {type: string}
+        tags: {type: array, items: {type: string}}
+  - id: classify
+    name: Classify
+    prompt_template: Classify {{.extract}}
+    output_format: json
+    depends_on:
+      - extract
+`
+	skillPath := filepath.Join(tmpDir, "structured.yaml")
+	if err := os.WriteFile(skillPath, []byte(schemaYAML), 0644); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+
+	s, err := NewLoader().LoadSkill(skillPath)
+	if err != nil {
+		t.Fatalf("LoadSkill() error: %v", err)
+	}
+
+	extract, _ := s.GetPhase("extract")
+	if !extract.WantsJSON() || !contains(string(extract.OutputSchema), `"required":["title","tags"]`) {
+		t.Errorf("expected extract to have a json schema, got format %q schema %s", extract.OutputFormat, extract.OutputSchema)
+	}
+
+	classify, _ := s.GetPhase("classify")
+	if !classify.WantsJSON() || len(classify.OutputSchema) != 0 {
+		t.Errorf("expected classify to be json without schema, got %+v", classify)
+	}
+}
+
+func TestLoadSkill_InvalidOutputFormat(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	invalidYAML := `
+id: bad-format
+name: Bad Format
+phases:
+  - id: main
+    name: Main
+    prompt_template: Test
+    output_format: xml
+`
+	skillPath := filepath.Join(tmpDir, "bad-format.yaml")
+	if err := os.WriteFile(skillPath, []byte(invalidYAML), 0644); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+
+	_, err := NewLoader().LoadSkill(skillPath)
+	if err == nil || !contains(err.Error(), "invalid output_format") {
+		t.Errorf("expected output_format validation error, got %v", err)
+	}
+}
+
+func TestLoadSkill_InputAudio(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	audioYAML := `
+id: meeting-notes
+name: Meeting Notes
+phases:
+  - id: transcribe
+    name: Transcribe
+    input_audio: "{{._input}}"
+    prompt_template: Standup with Priya and Tomasz about Skillrunner.
+  - id: notes
+    name: Notes
+    prompt_template: Summarize {{.transcribe}}
+    depends_on:
+      - transcribe
+`
+	skillPath := filepath.Join(tmpDir, "meeting-notes.yaml")
+	if err := os.WriteFile(skillPath, []byte(audioYAML), 0644); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+
+	s, err := NewLoader().LoadSkill(skillPath)
+	if err != nil {
+		t.Fatalf("LoadSkill() error: %v", err)
+	}
+
+	transcribe, _ := s.GetPhase("transcribe")
+	if !transcribe.IsTranscription() || transcribe.InputAudio != "{{._input}}" {
+		t.Errorf("expected transcribe to read audio from the input, got %q", transcribe.InputAudio)
+	}
+	notes, _ := s.GetPhase("notes")
+	if notes.IsTranscription() {
+		t.Error("expected notes to be an LLM phase")
+	}
+}
+
+func TestLoadSkill_InputAudioJSON(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	invalidYAML := `
+id: bad-audio
+name: Bad Audio
+phases:
+  - id: main
+    name: Main
+    input_audio: call.mp3
+    prompt_template: Test
+    output_format: json
+`
+	skillPath := filepath.Join(tmpDir, "bad-audio.yaml")
+	if err := os.WriteFile(skillPath, []byte(invalidYAML), 0644); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+
+	_, err := NewLoader().LoadSkill(skillPath)
+	if err == nil || !contains(err.Error(), "input_audio phases produce text output") {
+		t.Errorf("expected input_audio validation error, got %v", err)
+	}
+}
+
+func TestLoadSkill_ImageOutput(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	imageYAML := `
+id: blog-art
+name: Blog Art
+phases:
+  - id: describe
+    name: Describe
+    prompt_template: Describe a header image for {{._input}}
+  - id: render
+    name: Render
+    prompt_template: "{{.describe}}"
+    output_format: image
+    image:
+      size: 1792x1024
+      count: 2
+      negative_prompt: text, watermark
+    depends_on:
+      - describe
+`
+	skillPath := filepath.Join(tmpDir, "blog-art.yaml")
+	if err := os.WriteFile(skillPath, []byte(imageYAML), 0644); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+
+	s, err := NewLoader().LoadSkill(skillPath)
+	if err != nil {
+		t.Fatalf("LoadSkill() error: %v", err)
+	}
+
+	render, _ := s.GetPhase("render")
+	if !render.IsImageGeneration() {
+		t.Fatalf("expected render to generate images, got format %q", render.OutputFormat)
+	}
+	if want := (skill.ImageOptions{Size: "1792x1024", Count: 2, NegativePrompt: "text, watermark"}); render.Image != want {
+		t.Errorf("Image = %+v, want %+v", render.Image, want)
+	}
+}
+
+func TestLoadSkill_ImageWithoutImageOutput(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	invalidYAML := `
+id: bad-image
+name: Bad Image
+phases:
+  - id: main
+    name: Main
+    prompt_template: Test
+    image:
+      size: 512x512
+`
+	skillPath := filepath.Join(tmpDir, "bad-image.yaml")
+	if err := os.WriteFile(skillPath, []byte(invalidYAML), 0644); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+
+	_, err := NewLoader().LoadSkill(skillPath)
+	if err == nil || !contains(err.Error(), "image requires output_format image") {
+		t.Errorf("expected image validation error, got %v", err)
+	}
+}
+
+func TestLoadSkill_ExtractTables(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	yaml := `
+id: prices
+name: Prices
+phases:
+  - id: extract
+    name: Extract
+    prompt_template: Tabulate the prices
+    extract_tables: tsv
+  - id: bad
+    name: Bad
+    prompt_template: Test
+    extract_tables: xlsx
+`
+	skillPath := filepath.Join(tmpDir, "prices.yaml")
+	if err := os.WriteFile(skillPath, []byte(yaml), 0644); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+
+	_, err := NewLoader().LoadSkill(skillPath)
+	if err == nil || !contains(err.Error(), `invalid extract_tables "xlsx"`) {
+		t.Fatalf("expected extract_tables validation error, got %v", err)
+	}
+
+	valid := strings.Replace(yaml, "extract_tables: xlsx", "", 1)
+	if err := os.WriteFile(skillPath, []byte(valid), 0644); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+	sk, err := NewLoader().LoadSkill(skillPath)
+	if err != nil {
+		t.Fatalf("LoadSkill() error = %v", err)
+	}
+	if got := sk.Phases()[0].ExtractTables; got != skill.TableFormatTSV {
+		t.Errorf("ExtractTables = %q, want tsv", got)
+	}
+}
+
+func TestLoadSkill_LongContext(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	yaml := `
+id: digest
+name: Digest
+routing:
+  max_context_tokens: 8192
+long_context:
+  strategy: sliding_window
+phases:
+  - id: read
+    name: Read
+    prompt_template: Summarize {{._input}}
+  - id: review
+    name: Review
+    prompt_template: Review the summary
+    long_context:
+      strategy: sliding_window
+      window_tokens: 2000
+      carryover_tokens: 300
+`
+	skillPath := filepath.Join(tmpDir, "digest.yaml")
+	if err := os.WriteFile(skillPath, []byte(yaml), 0644); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+
+	sk, err := NewLoader().LoadSkill(skillPath)
+	if err != nil {
+		t.Fatalf("LoadSkill() error = %v", err)
+	}
+	read := sk.Phases()[0].LongContext
+	if read == nil || read.WindowTokens != 8192 || read.CarryoverTokens != skill.DefaultCarryoverTokens {
+		t.Errorf("skill long_context = %+v, want an 8192-token window with the default carryover", read)
+	}
+	review := sk.Phases()[1].LongContext
+	if review == nil || review.WindowTokens != 2000 || review.CarryoverTokens != 300 {
+		t.Errorf("phase long_context = %+v, want the phase override", review)
+	}
+
+	invalid := strings.Replace(yaml, "carryover_tokens: 300", "carryover_tokens: 1200", 1)
+	if err := os.WriteFile(skillPath, []byte(invalid), 0644); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+	if _, err := NewLoader().LoadSkill(skillPath); !errors.Is(err, skill.ErrInvalidCarryoverTokens) {
+		t.Errorf("expected carryover guard error, got %v", err)
+	}
+
+	invalid = strings.Replace(yaml, "strategy: sliding_window\nphases", "strategy: truncate\nphases", 1)
+	if err := os.WriteFile(skillPath, []byte(invalid), 0644); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+	if _, err := NewLoader().LoadSkill(skillPath); err == nil || !contains(err.Error(), `invalid strategy "truncate"`) {
+		t.Errorf("expected strategy validation error, got %v", err)
+	}
+
+	invalid = strings.Replace(yaml, "carryover_tokens: 300", "carryover_tokens: 300\n      map_profile: cheap", 1)
+	if err := os.WriteFile(skillPath, []byte(invalid), 0644); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+	if _, err := NewLoader().LoadSkill(skillPath); err == nil || !contains(err.Error(), "require strategy map_reduce") {
+		t.Errorf("expected level profiles to require map_reduce, got %v", err)
+	}
+}
+
+func TestLoadSkill_ContextBudget(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	yaml := `
+id: digest
+name: Digest
+routing:
+  max_context_tokens: 8192
+context_budget:
+  shares:
+    input: 50
+    instructions: 20
+phases:
+  - id: read
+    name: Read
+    prompt_template: Summarize {{._input}}
+  - id: review
+    name: Review
+    prompt_template: Review the summary
+    context_budget:
+      max_tokens: 4000
+      priorities:
+        instructions: 2
+        dependencies: 1
+`
+	skillPath := filepath.Join(tmpDir, "digest.yaml")
+	if err := os.WriteFile(skillPath, []byte(yaml), 0644); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+
+	sk, err := NewLoader().LoadSkill(skillPath)
+	if err != nil {
+		t.Fatalf("LoadSkill() error = %v", err)
+	}
+	read := sk.Phases()[0].ContextBudget
+	if read == nil || read.MaxTokens != 8192 || read.Shares[skill.ContextInput] != 50 {
+		t.Errorf("skill context_budget = %+v, want input shares over 8192 tokens", read)
+	}
+	review := sk.Phases()[1].ContextBudget
+	if review == nil || review.MaxTokens != 4000 || review.Priorities[skill.ContextInstructions] != 2 {
+		t.Errorf("phase context_budget = %+v, want the phase override", review)
+	}
+
+	invalid := strings.Replace(yaml, "input: 50", "input: 90", 1)
+	if err := os.WriteFile(skillPath, []byte(invalid), 0644); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+	if _, err := NewLoader().LoadSkill(skillPath); !errors.Is(err, skill.ErrInvalidContextShare) {
+		t.Errorf("expected share validation error, got %v", err)
+	}
+
+	invalid = strings.Replace(yaml, "dependencies: 1", "workspace: 1", 1)
+	if err := os.WriteFile(skillPath, []byte(invalid), 0644); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+	if _, err := NewLoader().LoadSkill(skillPath); !errors.Is(err, skill.ErrUnknownContextPart) {
+		t.Errorf("expected unknown component error, got %v", err)
+	}
+
+	invalid = strings.Replace(yaml, "phases:", "long_context:\n  strategy: sliding_window\nphases:", 1)
+	if err := os.WriteFile(skillPath, []byte(invalid), 0644); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+	if _, err := NewLoader().LoadSkill(skillPath); !errors.Is(err, skill.ErrContextBudgetConflict) {
+		t.Errorf("expected long_context conflict error, got %v", err)
+	}
+}
+
+func TestLoadSkill_LongContextMapReduce(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	yaml := `
+id: digest
+name: Digest
+routing:
+  max_context_tokens: 8192
+long_context:
+  strategy: map_reduce
+  reduce_profile: premium
+phases:
+  - id: read
+    name: Read
+    prompt_template: Summarize {{._input}}
+`
+	skillPath := filepath.Join(tmpDir, "digest.yaml")
+	if err := os.WriteFile(skillPath, []byte(yaml), 0644); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+
+	sk, err := NewLoader().LoadSkill(skillPath)
+	if err != nil {
+		t.Fatalf("LoadSkill() error = %v", err)
+	}
+	lc := sk.Phases()[0].LongContext
+	if lc == nil || lc.Strategy != skill.LongContextMapReduce || lc.WindowTokens != 8192 {
+		t.Fatalf("long_context = %+v, want map_reduce over an 8192-token window", lc)
+	}
+	if lc.MapProfile != skill.RoutingProfileCheap || lc.ReduceProfile != skill.RoutingProfilePremium {
+		t.Errorf("profiles = %q/%q, want cheap/premium", lc.MapProfile, lc.ReduceProfile)
+	}
+
+	invalid := strings.Replace(yaml, "reduce_profile: premium", "reduce_profile: Fastest", 1)
+	if err := os.WriteFile(skillPath, []byte(invalid), 0644); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+	if _, err := NewLoader().LoadSkill(skillPath); err == nil || !contains(err.Error(), `invalid profile "Fastest"`) {
+		t.Errorf("expected profile validation error, got %v", err)
+	}
+}
+
+func TestLoadSkill_LongContextChunked(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	yaml := `
+id: action-items
+name: Action Items
+routing:
+  max_context_tokens: 8192
+phases:
+  - id: extract
+    name: Extract
+    prompt_template: List the action items in {{._input}}
+    long_context:
+      strategy: chunked
+      reduce_prompt: Merge the action item lists, removing duplicates.
+`
+	skillPath := filepath.Join(tmpDir, "action-items.yaml")
+	if err := os.WriteFile(skillPath, []byte(yaml), 0644); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+
+	sk, err := NewLoader().LoadSkill(skillPath)
+	if err != nil {
+		t.Fatalf("LoadSkill() error = %v", err)
+	}
+	lc := sk.Phases()[0].LongContext
+	if lc == nil || lc.Strategy != skill.LongContextChunked || lc.WindowTokens != 8192 || lc.MapProfile != "" {
+		t.Fatalf("long_context = %+v, want chunked over an 8192-token window on the phase's profile", lc)
+	}
+	if lc.ReducePrompt != "Merge the action item lists, removing duplicates." {
+		t.Errorf("reduce_prompt = %q", lc.ReducePrompt)
+	}
+
+	invalid := strings.Replace(yaml, "strategy: chunked", "strategy: map_reduce", 1)
+	if err := os.WriteFile(skillPath, []byte(invalid), 0644); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+	if _, err := NewLoader().LoadSkill(skillPath); err == nil || !contains(err.Error(), "reduce_prompt requires strategy chunked") {
+		t.Errorf("expected reduce_prompt validation error, got %v", err)
+	}
+}
+
+func TestLoadSkill_SubSkill(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	subSkillYAML := `
+id: release
+name: Release
+phases:
+  - id: diff
+    name: Diff
+    prompt_template: Diff {{._input}}
+  - id: review
+    name: Review
+    skill: code-review
+    input: "{{.diff}}"
+    output: summary
+    depends_on: [diff]
+  - id: notes
+    name: Notes
+    skill: release-notes
+`
+	skillPath := filepath.Join(tmpDir, "release.yaml")
+	if err := os.WriteFile(skillPath, []byte(subSkillYAML), 0644); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+
+	s, err := NewLoader().LoadSkill(skillPath)
+	if err != nil {
+		t.Fatalf("LoadSkill() error: %v", err)
+	}
+	review, _ := s.GetPhase("review")
+	if c := review.SubSkill; c == nil || *c != (skill.SubSkillConfig{Skill: "code-review", Output: "summary"}) || review.PromptTemplate != "{{.diff}}" {
+		t.Errorf("review = %+v with input %q, want code-review's summary on the diff", c, review.PromptTemplate)
+	}
+	notes, _ := s.GetPhase("notes")
+	if notes.SubSkill == nil || notes.PromptTemplate != "{{._input}}" {
+		t.Errorf("notes input = %q, want the skill's input", notes.PromptTemplate)
+	}
+
+	for _, invalid := range []string{
+		strings.Replace(subSkillYAML, "input: \"{{.diff}}\"", "prompt_template: \"{{.diff}}\"", 1),
+		strings.Replace(subSkillYAML, "skill: code-review", "prompt_template: Review", 1),
+		strings.Replace(subSkillYAML, "skill: release-notes", "skill: release", 1),
+	} {
+		if err := os.WriteFile(skillPath, []byte(invalid), 0644); err != nil {
+			t.Fatalf("failed to write test file: %v", err)
+		}
+		if _, err := NewLoader().LoadSkill(skillPath); err == nil {
+			t.Errorf("LoadSkill() of an invalid skill phase succeeded:\n%s", invalid)
+		}
+	}
+}
+
+func TestLoadSkill_Timeout(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	timeoutYAML := `
+id: summary-skill
+name: Summary Skill
+phases:
+  - id: summarize
+    name: Summarize
+    prompt_template: Summarize {{._input}}
+    timeout: 45s
+`
+	skillPath := filepath.Join(tmpDir, "summary.yaml")
+	if err := os.WriteFile(skillPath, []byte(timeoutYAML), 0644); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+
+	s, err := NewLoader().LoadSkill(skillPath)
+	if err != nil {
+		t.Fatalf("LoadSkill() error: %v", err)
+	}
+	if summarize, _ := s.GetPhase("summarize"); summarize.Timeout != 45*time.Second {
+		t.Errorf("summarize timeout = %s, want 45s", summarize.Timeout)
+	}
+
+	for _, timeout := range []string{"soon", "-5s"} {
+		invalid := strings.Replace(timeoutYAML, "45s", timeout, 1)
+		if err := os.WriteFile(skillPath, []byte(invalid), 0644); err != nil {
+			t.Fatalf("failed to write test file: %v", err)
+		}
+		if _, err := NewLoader().LoadSkill(skillPath); err == nil {
+			t.Errorf("LoadSkill() with timeout %s succeeded", timeout)
+		}
+	}
+}
+
+func TestLoadSkill_Validators(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	validatorsYAML := `
+id: review-skill
+name: Review Skill
+phases:
+  - id: review
+    name: Review
+    prompt_template: Review {{._input}}
+    max_validation_attempts: 4
+    validators:
+      - regex: "VERDICT: (PASS|FAIL)"
+      - json_schema:
+          type: object
+          required: [verdict]
+      - script: ./check.sh
+`
+	skillPath := filepath.Join(tmpDir, "review.yaml")
+	if err := os.WriteFile(skillPath, []byte(validatorsYAML), 0644); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+
+	s, err := NewLoader().LoadSkill(skillPath)
+	if err != nil {
+		t.Fatalf("LoadSkill() error: %v", err)
+	}
+	review, _ := s.GetPhase("review")
+	cfg := review.Validation
+	if cfg == nil || len(cfg.Validators) != 3 || cfg.MaxAttempts != 4 {
+		t.Fatalf("review validation = %+v, want 3 validators and 4 attempts", cfg)
+	}
+	if got := string(cfg.Validators[1].JSONSchema); got != `{"required":["verdict"],"type":"object"}` {
+		t.Errorf("json_schema = %s", got)
+	}
+	if v := cfg.Validators[2]; v.Kind() != skill.ValidatorScript || v.Dir != tmpDir {
+		t.Errorf("script validator = %+v, want one run in the skill's directory", v)
+	}
+
+	invalid := strings.Replace(validatorsYAML, "      - script: ./check.sh\n", "      - {}\n", 1)
+	if err := os.WriteFile(skillPath, []byte(invalid), 0644); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+	if _, err := NewLoader().LoadSkill(skillPath); err == nil {
+		t.Error("LoadSkill() with an empty validator succeeded")
+	}
+}
@@ -0,0 +1,205 @@
+package skills
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"gopkg.in/yaml.v3"
+)
+
+// diffContext is the number of unchanged lines shown around each change.
+const diffContext = 3
+
+// RewritePhasePrompt returns a skill definition with the prompt template of
+// a phase replaced and its version set, keeping the rest of the document,
+// comments included.
+func RewritePhasePrompt(data []byte, phaseID, prompt, version string) ([]byte, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidStructure, err)
+	}
+	if doc.Kind != yaml.DocumentNode || len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("%w: expected a mapping", ErrInvalidStructure)
+	}
+	root := doc.Content[0]
+
+	phases := mappingValue(root, "phases")
+	if phases == nil || phases.Kind != yaml.SequenceNode {
+		return nil, fmt.Errorf("%w: no phases", ErrInvalidStructure)
+	}
+	var phase *yaml.Node
+	for _, p := range phases.Content {
+		if id := mappingValue(p, "id"); id != nil && id.Value == phaseID {
+			phase = p
+			break
+		}
+	}
+	if phase == nil {
+		return nil, fmt.Errorf("phase %s not found", phaseID)
+	}
+
+	setScalar(phase, "prompt_template", prompt)
+	setScalar(root, "version", version)
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(&doc); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// mappingValue returns the value of a key of a mapping node, or nil.
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// setScalar sets a string value of a mapping node, adding the key if it is
+// missing. Multi-line values are written as literal blocks; others keep
+// the quoting of the value they replace.
+func setScalar(node *yaml.Node, key, value string) {
+	style := yaml.Style(0)
+	if strings.Contains(value, "\n") {
+		style = yaml.LiteralStyle
+	}
+
+	if v := mappingValue(node, key); v != nil {
+		if style == 0 && v.Style&(yaml.LiteralStyle|yaml.FoldedStyle) == 0 {
+			style = v.Style
+		}
+		*v = yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: value, Style: style, HeadComment: v.HeadComment, LineComment: v.LineComment}
+		return
+	}
+	node.Content = append(node.Content,
+		&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key},
+		&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: value, Style: style},
+	)
+}
+
+// NextVersion returns the version following a skill version: the last
+// number in it incremented, as in 1.2.0 to 1.2.1 or 0.3.0-dev to 0.3.1-dev.
+// A version without a number gets ".1" appended, and an empty one is "1".
+func NextVersion(version string) string {
+	end := strings.LastIndexFunc(version, unicode.IsDigit) + 1
+	if end == 0 {
+		if version == "" {
+			return "1"
+		}
+		return version + ".1"
+	}
+	start := strings.LastIndexFunc(version[:end], func(r rune) bool { return !unicode.IsDigit(r) }) + 1
+
+	n, err := strconv.Atoi(version[start:end])
+	if err != nil {
+		return version + ".1"
+	}
+	return version[:start] + strconv.Itoa(n+1) + version[end:]
+}
+
+// DiffLines returns a unified diff of two texts, line by line, with the
+// given names in its header. It returns "" if the texts are equal.
+func DiffLines(oldName, newName, a, b string) string {
+	if a == b {
+		return ""
+	}
+	x := strings.Split(strings.TrimSuffix(a, "\n"), "\n")
+	y := strings.Split(strings.TrimSuffix(b, "\n"), "\n")
+
+	// Longest common subsequence table, from the ends of the texts
+	lcs := make([][]int, len(x)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(y)+1)
+	}
+	for i := len(x) - 1; i >= 0; i-- {
+		for j := len(y) - 1; j >= 0; j-- {
+			if x[i] == y[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	type line struct {
+		op   byte // ' ', '-' or '+'
+		text string
+		i, j int // Line indexes in a and b before this line
+	}
+	var lines []line
+	i, j := 0, 0
+	for i < len(x) || j < len(y) {
+		switch {
+		case i < len(x) && j < len(y) && x[i] == y[j]:
+			lines = append(lines, line{' ', x[i], i, j})
+			i, j = i+1, j+1
+		case i < len(x) && (j == len(y) || lcs[i+1][j] >= lcs[i][j+1]):
+			lines = append(lines, line{'-', x[i], i, j})
+			i++
+		default:
+			lines = append(lines, line{'+', y[j], i, j})
+			j++
+		}
+	}
+
+	var out strings.Builder
+	fmt.Fprintf(&out, "--- %s\n+++ %s\n", oldName, newName)
+	printed := 0 // Lines already part of a hunk
+	for k := 0; k < len(lines); {
+		if lines[k].op == ' ' {
+			k++
+			continue
+		}
+
+		// Extend the hunk over changes closer than twice the context
+		start := max(k-diffContext, printed)
+		end := k
+		for end < len(lines) {
+			if lines[end].op != ' ' {
+				end++
+				continue
+			}
+			next := end
+			for next < len(lines) && lines[next].op == ' ' {
+				next++
+			}
+			if next == len(lines) || next-end > 2*diffContext {
+				end = min(end+diffContext, len(lines))
+				break
+			}
+			end = next
+		}
+
+		var oldCount, newCount int
+		for _, l := range lines[start:end] {
+			if l.op != '+' {
+				oldCount++
+			}
+			if l.op != '-' {
+				newCount++
+			}
+		}
+		fmt.Fprintf(&out, "@@ -%d,%d +%d,%d @@\n", lines[start].i+1, oldCount, lines[start].j+1, newCount)
+		for _, l := range lines[start:end] {
+			out.WriteByte(l.op)
+			out.WriteString(l.text)
+			out.WriteByte('\n')
+		}
+		k, printed = end, end
+	}
+	return out.String()
+}
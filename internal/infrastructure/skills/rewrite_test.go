@@ -0,0 +1,100 @@
+package skills
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRewritePhasePrompt(t *testing.T) {
+	original := `# Summarizes documents
+id: summarize
+name: Summarize
+version: "1.2.0"
+phases:
+  - id: draft
+    name: Draft
+    prompt_template: |
+      Summarize: {{._input}}
+  - id: polish
+    name: Polish
+    prompt_template: "Polish: {{.draft}}" # keep it short
+    depends_on: [draft]
+`
+	out, err := RewritePhasePrompt([]byte(original), "draft", "Summarize in three bullets:\n{{._input}}", "1.2.1")
+	if err != nil {
+		t.Fatalf("RewritePhasePrompt() error = %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "summarize.yaml")
+	if err := os.WriteFile(path, out, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := NewLoader().LoadSkill(path)
+	if err != nil {
+		t.Fatalf("rewritten skill does not load: %v\n%s", err, out)
+	}
+	if loaded.Version() != "1.2.1" {
+		t.Errorf("Version() = %q, want 1.2.1", loaded.Version())
+	}
+	phases := loaded.Phases()
+	if phases[0].PromptTemplate != "Summarize in three bullets:\n{{._input}}\n" && phases[0].PromptTemplate != "Summarize in three bullets:\n{{._input}}" {
+		t.Errorf("draft prompt = %q", phases[0].PromptTemplate)
+	}
+	if phases[1].PromptTemplate != "Polish: {{.draft}}" {
+		t.Errorf("polish prompt = %q, want it unchanged", phases[1].PromptTemplate)
+	}
+	for _, keep := range []string{"# Summarizes documents", "# keep it short", `version: "1.2.1"`} {
+		if !strings.Contains(string(out), keep) {
+			t.Errorf("rewritten skill lost %q:\n%s", keep, out)
+		}
+	}
+
+	if _, err := RewritePhasePrompt([]byte(original), "review", "x", "1.2.1"); err == nil {
+		t.Error("rewriting an unknown phase should fail")
+	}
+}
+
+func TestNextVersion(t *testing.T) {
+	tests := map[string]string{
+		"1.2.0":     "1.2.1",
+		"0.3.9-dev": "0.3.10-dev",
+		"2":         "3",
+		"beta":      "beta.1",
+		"":          "1",
+	}
+	for in, want := range tests {
+		if got := NextVersion(in); got != want {
+			t.Errorf("NextVersion(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestDiffLines(t *testing.T) {
+	if DiffLines("a", "b", "same\n", "same\n") != "" {
+		t.Error("equal texts should have an empty diff")
+	}
+
+	a := "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n"
+	b := "1\n2\nthree\n4\n5\n6\n7\n8\n9\n10\n11\n12\n13\n"
+	want := `--- old
++++ new
+@@ -1,6 +1,6 @@
+ 1
+ 2
+-3
++three
+ 4
+ 5
+ 6
+@@ -10,3 +10,4 @@
+ 10
+ 11
+ 12
++13
+`
+	if got := DiffLines("old", "new", a, b); got != want {
+		t.Errorf("DiffLines() =\n%s\nwant\n%s", got, want)
+	}
+}
@@ -0,0 +1,195 @@
+package skills
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"text/template"
+
+	"github.com/jbctechsolutions/skillrunner/internal/domain/skill"
+)
+
+// Scaffold validation errors.
+var (
+	ErrScaffoldID        = errors.New("skill ID must be lowercase letters, digits and hyphens, starting with a letter")
+	ErrScaffoldPhaseID   = errors.New("phase ID must be lowercase letters, digits and underscores, starting with a letter")
+	ErrScaffoldPhases    = errors.New("a skill needs at least one phase")
+	ErrScaffoldDuplicate = errors.New("duplicate phase ID")
+)
+
+var (
+	scaffoldIDPattern    = regexp.MustCompile(`^[a-z][a-z0-9-]*$`)
+	scaffoldPhasePattern = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+)
+
+// Scaffold describes a new skill to generate: a skill definition with
+// example prompt templates and a sample input to run it on.
+type Scaffold struct {
+	ID          string
+	Name        string   // defaults to the ID in title case
+	Description string   // defaults to a placeholder
+	Phases      []string // phase IDs, in order
+	Profile     string   // default routing profile, used by the last phase
+	Parallel    bool     // run all phases but the last at once, the last merging them
+}
+
+// Validate checks the skill and phase IDs and the routing profile. Phase
+// IDs must be template identifiers, so that later phases can refer to
+// their outputs as {{.phase_id}}.
+func (s Scaffold) Validate() error {
+	if !scaffoldIDPattern.MatchString(s.ID) {
+		return fmt.Errorf("%w: %q", ErrScaffoldID, s.ID)
+	}
+	if len(s.Phases) == 0 {
+		return ErrScaffoldPhases
+	}
+	for i, id := range s.Phases {
+		if !scaffoldPhasePattern.MatchString(id) {
+			return fmt.Errorf("%w: %q", ErrScaffoldPhaseID, id)
+		}
+		if slices.Contains(s.Phases[:i], id) {
+			return fmt.Errorf("%w: %s", ErrScaffoldDuplicate, id)
+		}
+	}
+	if !skill.IsValidProfileName(s.Profile) {
+		return fmt.Errorf("invalid routing profile %q", s.Profile)
+	}
+	return nil
+}
+
+// scaffoldPhase is a phase of the generated skill.
+type scaffoldPhase struct {
+	ID        string
+	Name      string
+	Profile   string
+	DependsOn []string
+}
+
+// phases returns the phases of the generated skill. Phases run one after
+// the other, each on the output of the one before, or with Parallel all
+// phases but the last run on the input and the last merges their outputs.
+// Earlier phases use the cheap profile and the last one the skill's.
+func (s Scaffold) phases() []scaffoldPhase {
+	phases := make([]scaffoldPhase, len(s.Phases))
+	last := len(s.Phases) - 1
+	for i, id := range s.Phases {
+		phase := scaffoldPhase{ID: id, Name: TitleCase(id), Profile: skill.ProfileCheap}
+		switch {
+		case i == last:
+			phase.Profile = s.Profile
+			if s.Parallel {
+				phase.DependsOn = s.Phases[:last]
+			} else if i > 0 {
+				phase.DependsOn = []string{s.Phases[i-1]}
+			}
+		case i > 0 && !s.Parallel:
+			phase.DependsOn = []string{s.Phases[i-1]}
+		}
+		phases[i] = phase
+	}
+	return phases
+}
+
+// TitleCase turns a skill or phase ID such as code-review or draft_plan into
+// a display name.
+func TitleCase(id string) string {
+	words := strings.FieldsFunc(id, func(r rune) bool { return r == '-' || r == '_' })
+	for i, w := range words {
+		words[i] = strings.ToUpper(w[:1]) + w[1:]
+	}
+	return strings.Join(words, " ")
+}
+
+// quoteYAML quotes a string as a double-quoted YAML scalar.
+func quoteYAML(s string) string {
+	data, _ := json.Marshal(s)
+	return string(data)
+}
+
+var scaffoldTemplate = template.Must(template.New("skill").Funcs(template.FuncMap{
+	"quote": quoteYAML,
+	"title": TitleCase,
+	"join":  strings.Join,
+}).Delims("[[", "]]").Parse(`# [[.Name]]
+# Generated by sr skill init. Replace the example prompts with your own,
+# then check the skill with: sr skills validate <this file>
+# See docs/skills-guide.md for every field.
+
+id: [[.ID]]
+name: [[quote .Name]]
+version: "0.1.0"
+description: [[quote .Description]]
+
+routing:
+  default_profile: [[.Profile]]
+
+phases:
+[[- range .Phases]]
+  - id: [[.ID]]
+    name: [[quote .Name]]
+    routing_profile: [[.Profile]]
+[[- if .DependsOn]]
+    depends_on: [ [[- join .DependsOn ", " -]] ]
+[[- end]]
+    max_tokens: 2048
+    prompt_template: |
+[[- if not .DependsOn]]
+      You are an expert assistant. Work on the following request and
+      describe what you find, step by step.
+
+      Request:
+      {{.input}}
+[[- else]]
+      You are an expert assistant. Using the results of the earlier
+      phases, complete the original request.
+
+      Request:
+      {{.input}}
+[[- range .DependsOn]]
+
+      [[title .]]:
+      {{.[[.]]}}
+[[- end]]
+[[- end]]
+[[end]]
+metadata:
+  author: ""
+  tags: []
+`))
+
+// YAML returns the skill definition.
+func (s Scaffold) YAML() ([]byte, error) {
+	data := struct {
+		ID, Name, Description, Profile string
+		Phases                         []scaffoldPhase
+	}{
+		ID:          s.ID,
+		Name:        s.Name,
+		Description: s.Description,
+		Profile:     s.Profile,
+		Phases:      s.phases(),
+	}
+	if data.Name == "" {
+		data.Name = TitleCase(s.ID)
+	}
+	if data.Description == "" {
+		data.Description = "Describe what " + data.Name + " does and the input it expects."
+	}
+
+	var buf bytes.Buffer
+	if err := scaffoldTemplate.Execute(&buf, data); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Fixture returns a sample input to run the skill on, e.g. to record a
+// golden run for sr skill test.
+func (s Scaffold) Fixture() []byte {
+	return []byte("<!-- Sample input for the " + s.ID + " skill. Replace it with a realistic request. -->\n\n" +
+		"Summarize the trade-offs between SQLite and PostgreSQL for a small web application.\n")
+}
@@ -0,0 +1,95 @@
+package skills
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestScaffold_YAML(t *testing.T) {
+	tests := []struct {
+		name     string
+		parallel bool
+		deps     map[string][]string
+	}{
+		{"sequential", false, map[string][]string{"analyze": nil, "draft": {"analyze"}, "review": {"draft"}}},
+		{"parallel", true, map[string][]string{"analyze": nil, "draft": nil, "review": {"analyze", "draft"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := Scaffold{ID: "my-skill", Description: `Says "hi"`, Phases: []string{"analyze", "draft", "review"}, Profile: "premium", Parallel: tt.parallel}
+			if err := s.Validate(); err != nil {
+				t.Fatalf("Validate() error = %v", err)
+			}
+			data, err := s.YAML()
+			if err != nil {
+				t.Fatalf("YAML() error = %v", err)
+			}
+			path := filepath.Join(t.TempDir(), "my-skill.yaml")
+			if err := os.WriteFile(path, data, 0o644); err != nil {
+				t.Fatal(err)
+			}
+
+			sk, err := NewLoader().LoadSkill(path)
+			if err != nil {
+				t.Fatalf("LoadSkill() of the scaffold error = %v\n%s", err, data)
+			}
+			if sk.Name() != "My Skill" || sk.Description() != `Says "hi"` || sk.Routing().DefaultProfile != "premium" {
+				t.Errorf("skill = %s, %q, %s", sk.Name(), sk.Description(), sk.Routing().DefaultProfile)
+			}
+			for _, phase := range sk.Phases() {
+				if !slices.Equal(phase.DependsOn, tt.deps[phase.ID]) {
+					t.Errorf("phase %s depends on %v, want %v", phase.ID, phase.DependsOn, tt.deps[phase.ID])
+				}
+				for _, dep := range phase.DependsOn {
+					if !strings.Contains(phase.PromptTemplate, "{{."+dep+"}}") {
+						t.Errorf("phase %s template does not use the output of %s", phase.ID, dep)
+					}
+				}
+			}
+			if last := sk.Phases()[2]; last.RoutingProfile != "premium" {
+				t.Errorf("last phase profile = %s, want the skill's", last.RoutingProfile)
+			}
+		})
+	}
+}
+
+func TestScaffold_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		s       Scaffold
+		wantErr error
+	}{
+		{"bad id", Scaffold{ID: "My Skill", Phases: []string{"a"}, Profile: "balanced"}, ErrScaffoldID},
+		{"no phases", Scaffold{ID: "s", Profile: "balanced"}, ErrScaffoldPhases},
+		{"hyphenated phase", Scaffold{ID: "s", Phases: []string{"first-pass"}, Profile: "balanced"}, ErrScaffoldPhaseID},
+		{"duplicate phase", Scaffold{ID: "s", Phases: []string{"a", "a"}, Profile: "balanced"}, ErrScaffoldDuplicate},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.s.Validate(); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Validate() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+	if err := (Scaffold{ID: "s", Phases: []string{"a"}, Profile: "Not Valid"}).Validate(); err == nil {
+		t.Error("Validate() accepted an invalid profile name")
+	}
+}
+
+func TestTitleCase(t *testing.T) {
+	for id, want := range map[string]string{
+		"code-review":  "Code Review",
+		"draft_plan":   "Draft Plan",
+		"api-v2_notes": "Api V2 Notes",
+		"summarize":    "Summarize",
+	} {
+		if got := TitleCase(id); got != want {
+			t.Errorf("TitleCase(%q) = %q, want %q", id, got, want)
+		}
+	}
+}
@@ -0,0 +1,197 @@
+package storage
+
+import (
+	"bufio"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/jbctechsolutions/skillrunner/internal/application/ports"
+	"github.com/jbctechsolutions/skillrunner/internal/domain/workflow"
+)
+
+// Compile-time checks that the audit logs implement AuditLogPort.
+var (
+	_ ports.AuditLogPort = (*AuditLogRepository)(nil)
+	_ ports.AuditLogPort = (*JSONLAuditLog)(nil)
+)
+
+// AuditLogRepository implements AuditLogPort using the audit_log table,
+// which rejects updates and deletes.
+type AuditLogRepository struct {
+	db *sql.DB
+}
+
+// NewAuditLogRepository creates a new audit log repository.
+func NewAuditLogRepository(db *sql.DB) *AuditLogRepository {
+	return &AuditLogRepository{db: db}
+}
+
+// Append inserts a record.
+func (r *AuditLogRepository) Append(ctx context.Context, record *workflow.AuditRecord) error {
+	messages, err := json.Marshal(record.Messages)
+	if err != nil {
+		return fmt.Errorf("failed to marshal audit messages: %w", err)
+	}
+
+	query := `
+		INSERT INTO audit_log (
+			execution_id, skill_id, phase_id, provider, model, system_prompt, messages,
+			max_tokens, temperature, response, error, input_tokens, output_tokens,
+			redactions, recorded_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+
+	_, err = r.db.ExecContext(ctx, query,
+		record.ExecutionID,
+		record.SkillID,
+		record.PhaseID,
+		record.Provider,
+		record.Model,
+		record.SystemPrompt,
+		string(messages),
+		record.MaxTokens,
+		record.Temperature,
+		record.Response,
+		record.Error,
+		record.InputTokens,
+		record.OutputTokens,
+		record.Redactions,
+		record.RecordedAt.UTC().Format(time.RFC3339Nano),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to append audit record: %w", err)
+	}
+
+	return nil
+}
+
+// Records returns the records of an execution, oldest first.
+func (r *AuditLogRepository) Records(ctx context.Context, executionID string) ([]*workflow.AuditRecord, error) {
+	query := `
+		SELECT execution_id, skill_id, phase_id, provider, model, system_prompt, messages,
+			max_tokens, temperature, response, error, input_tokens, output_tokens,
+			redactions, recorded_at
+		FROM audit_log
+		WHERE execution_id = ?
+		ORDER BY id
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, executionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query audit log: %w", err)
+	}
+	defer rows.Close()
+
+	records := []*workflow.AuditRecord{}
+	for rows.Next() {
+		var (
+			rec                                         workflow.AuditRecord
+			executionID, skillID, phaseID, systemPrompt sql.NullString
+			response, callErr                           sql.NullString
+			messages, recordedAt                        string
+		)
+		if err := rows.Scan(&executionID, &skillID, &phaseID, &rec.Provider, &rec.Model, &systemPrompt, &messages,
+			&rec.MaxTokens, &rec.Temperature, &response, &callErr, &rec.InputTokens, &rec.OutputTokens,
+			&rec.Redactions, &recordedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan audit record: %w", err)
+		}
+		rec.ExecutionID, rec.SkillID, rec.PhaseID = executionID.String, skillID.String, phaseID.String
+		rec.SystemPrompt, rec.Response, rec.Error = systemPrompt.String, response.String, callErr.String
+		if err := json.Unmarshal([]byte(messages), &rec.Messages); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal audit messages: %w", err)
+		}
+		if rec.RecordedAt, err = time.Parse(time.RFC3339Nano, recordedAt); err != nil {
+			return nil, fmt.Errorf("failed to parse recorded_at: %w", err)
+		}
+		records = append(records, &rec)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating audit log: %w", err)
+	}
+
+	return records, nil
+}
+
+// JSONLAuditLog implements AuditLogPort as a file of JSON records, one per
+// line, that is only ever appended to.
+type JSONLAuditLog struct {
+	path string
+	mu   sync.Mutex
+}
+
+// NewJSONLAuditLog creates an audit log appending to the file at path,
+// which is created with its directory on the first append.
+func NewJSONLAuditLog(path string) *JSONLAuditLog {
+	return &JSONLAuditLog{path: path}
+}
+
+// Path returns the path of the log file.
+func (l *JSONLAuditLog) Path() string {
+	return l.path
+}
+
+// Append writes a record as one line at the end of the file.
+func (l *JSONLAuditLog) Append(_ context.Context, record *workflow.AuditRecord) error {
+	line, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to marshal audit record: %w", err)
+	}
+	line = append(line, '\n')
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if err := os.MkdirAll(filepath.Dir(l.path), 0o700); err != nil {
+		return fmt.Errorf("failed to create audit log directory: %w", err)
+	}
+	f, err := os.OpenFile(l.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to open audit log: %w", err)
+	}
+	if _, err := f.Write(line); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("failed to append audit record: %w", err)
+	}
+	return f.Close()
+}
+
+// Records reads the file for the records of an execution, oldest first. A
+// missing file has no records.
+func (l *JSONLAuditLog) Records(_ context.Context, executionID string) ([]*workflow.AuditRecord, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	f, err := os.Open(l.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return []*workflow.AuditRecord{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit log: %w", err)
+	}
+	defer f.Close()
+
+	records := []*workflow.AuditRecord{}
+	scanner := bufio.NewScanner(f)
+	// Records hold whole prompts and responses
+	scanner.Buffer(make([]byte, 0, 64*1024), 64<<20)
+	for line := 1; scanner.Scan(); line++ {
+		var rec workflow.AuditRecord
+		if err := json.Unmarshal(scanner.Bytes(), &rec); err != nil {
+			return nil, fmt.Errorf("audit log line %d: %w", line, err)
+		}
+		if rec.ExecutionID == executionID {
+			records = append(records, &rec)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read audit log: %w", err)
+	}
+	return records, nil
+}
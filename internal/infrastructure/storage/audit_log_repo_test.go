@@ -0,0 +1,52 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jbctechsolutions/skillrunner/internal/domain/workflow"
+)
+
+func TestJSONLAuditLog_Append(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit", "audit.jsonl")
+	log := NewJSONLAuditLog(path)
+	ctx := context.Background()
+
+	if records, err := log.Records(ctx, "exec-1"); err != nil || len(records) != 0 {
+		t.Fatalf("Records() before any append = %v, %v; want none", records, err)
+	}
+	for _, phase := range []string{"analyze", "summarize"} {
+		err := log.Append(ctx, &workflow.AuditRecord{ExecutionID: "exec-1", PhaseID: phase, Provider: "ollama", Model: "llama3", RecordedAt: time.Now()})
+		if err != nil {
+			t.Fatalf("Append() error = %v", err)
+		}
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("audit log not written: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("audit log has %d lines, want 2:\n%s", len(lines), data)
+	}
+	var last workflow.AuditRecord
+	if err := json.Unmarshal([]byte(lines[1]), &last); err != nil || last.PhaseID != "summarize" {
+		t.Errorf("last record = %+v, %v; want the summarize phase", last, err)
+	}
+	if err := log.Append(ctx, &workflow.AuditRecord{ExecutionID: "exec-2", Provider: "ollama", Model: "llama3"}); err != nil {
+		t.Fatal(err)
+	}
+	records, err := log.Records(ctx, "exec-1")
+	if err != nil || len(records) != 2 || records[0].PhaseID != "analyze" {
+		t.Errorf("Records() = %v, %v; want the two records of exec-1 in order", records, err)
+	}
+	if info, err := os.Stat(path); err == nil && info.Mode().Perm() != 0o600 {
+		t.Errorf("audit log mode = %v, want 0600", info.Mode().Perm())
+	}
+}
@@ -0,0 +1,134 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/jbctechsolutions/skillrunner/internal/application/ports"
+	"github.com/jbctechsolutions/skillrunner/internal/domain/provider"
+)
+
+// Compile-time check that CostLedgerRepository implements CostLedgerPort.
+var _ ports.CostLedgerPort = (*CostLedgerRepository)(nil)
+
+// costGroupColumns maps summary dimensions to the columns they group by.
+var costGroupColumns = map[provider.CostGroupBy]string{
+	provider.CostByProvider: "provider",
+	provider.CostBySkill:    "skill_id",
+	provider.CostByModel:    "model",
+}
+
+// CostLedgerRepository implements CostLedgerPort using SQLite.
+type CostLedgerRepository struct {
+	db *sql.DB
+}
+
+// NewCostLedgerRepository creates a new cost ledger repository.
+func NewCostLedgerRepository(db *sql.DB) *CostLedgerRepository {
+	return &CostLedgerRepository{db: db}
+}
+
+// Record persists a ledger entry, unless one exists for its execution and
+// phase, along with the tags of its execution.
+func (r *CostLedgerRepository) Record(ctx context.Context, record *provider.CostRecord) error {
+	query := `
+		INSERT INTO cost_ledger (
+			execution_id, phase_id, skill_id, provider, model,
+			input_tokens, output_tokens, input_cost, output_cost, total_cost, recorded_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (execution_id, phase_id) DO NOTHING
+	`
+
+	_, err := r.db.ExecContext(ctx, query,
+		record.ExecutionID,
+		record.PhaseID,
+		record.SkillID,
+		record.Provider,
+		record.Model,
+		record.InputTokens,
+		record.OutputTokens,
+		record.InputCost,
+		record.OutputCost,
+		record.TotalCost,
+		record.RecordedAt.UTC().Format(time.RFC3339),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to record cost: %w", err)
+	}
+
+	for name, value := range record.Tags {
+		_, err := r.db.ExecContext(ctx, `
+			INSERT INTO cost_ledger_tags (execution_id, name, value) VALUES (?, ?, ?)
+			ON CONFLICT (execution_id, name) DO NOTHING
+		`, record.ExecutionID, name, value)
+		if err != nil {
+			return fmt.Errorf("failed to record cost tag: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// Summarize totals the entries recorded since the given time by provider,
+// skill, model or tag value, most expensive first.
+func (r *CostLedgerRepository) Summarize(ctx context.Context, since time.Time, by provider.CostGroupBy) ([]provider.CostGroup, error) {
+	if tag, ok := by.Tag(); ok {
+		return r.summarizeByTag(ctx, since, tag)
+	}
+	column, ok := costGroupColumns[by]
+	if !ok {
+		return nil, fmt.Errorf("invalid cost grouping %q", by)
+	}
+
+	query := fmt.Sprintf(`
+		SELECT %[1]s, COUNT(*), SUM(input_tokens), SUM(output_tokens), SUM(total_cost)
+		FROM cost_ledger
+		WHERE recorded_at >= ?
+		GROUP BY %[1]s
+		ORDER BY SUM(total_cost) DESC, %[1]s
+	`, column)
+
+	return r.queryGroups(ctx, query, since.UTC().Format(time.RFC3339))
+}
+
+// summarizeByTag totals the entries recorded since the given time by the
+// value their execution has for a tag. Untagged executions share the empty
+// key.
+func (r *CostLedgerRepository) summarizeByTag(ctx context.Context, since time.Time, tag string) ([]provider.CostGroup, error) {
+	query := `
+		SELECT COALESCE(t.value, ''), COUNT(*), SUM(l.input_tokens), SUM(l.output_tokens), SUM(l.total_cost)
+		FROM cost_ledger l
+		LEFT JOIN cost_ledger_tags t ON t.execution_id = l.execution_id AND t.name = ?
+		WHERE l.recorded_at >= ?
+		GROUP BY COALESCE(t.value, '')
+		ORDER BY SUM(l.total_cost) DESC, COALESCE(t.value, '')
+	`
+
+	return r.queryGroups(ctx, query, tag, since.UTC().Format(time.RFC3339))
+}
+
+// queryGroups runs a summary query and scans its cost groups.
+func (r *CostLedgerRepository) queryGroups(ctx context.Context, query string, args ...any) ([]provider.CostGroup, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query cost ledger: %w", err)
+	}
+	defer rows.Close()
+
+	groups := []provider.CostGroup{}
+	for rows.Next() {
+		var g provider.CostGroup
+		if err := rows.Scan(&g.Key, &g.Invocations, &g.InputTokens, &g.OutputTokens, &g.TotalCost); err != nil {
+			return nil, fmt.Errorf("failed to scan cost group: %w", err)
+		}
+		groups = append(groups, g)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating cost ledger: %w", err)
+	}
+
+	return groups, nil
+}
@@ -0,0 +1,142 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+
+	"github.com/jbctechsolutions/skillrunner/internal/domain/provider"
+)
+
+func setupCostLedgerTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatalf("failed to open in-memory database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	_, err = db.Exec(`
+		CREATE TABLE cost_ledger (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			execution_id TEXT NOT NULL,
+			phase_id TEXT NOT NULL,
+			skill_id TEXT NOT NULL,
+			provider TEXT NOT NULL,
+			model TEXT NOT NULL,
+			input_tokens INTEGER DEFAULT 0,
+			output_tokens INTEGER DEFAULT 0,
+			input_cost REAL DEFAULT 0,
+			output_cost REAL DEFAULT 0,
+			total_cost REAL DEFAULT 0,
+			recorded_at TIMESTAMP NOT NULL,
+			UNIQUE (execution_id, phase_id)
+		);
+		CREATE TABLE cost_ledger_tags (
+			execution_id TEXT NOT NULL,
+			name TEXT NOT NULL,
+			value TEXT NOT NULL,
+			PRIMARY KEY (execution_id, name)
+		);
+	`)
+	if err != nil {
+		t.Fatalf("failed to create table: %v", err)
+	}
+
+	return db
+}
+
+func costRecord(exec, phase, skill, prov, model string, cost float64, at time.Time) *provider.CostRecord {
+	return &provider.CostRecord{
+		CostBreakdown: provider.CostBreakdown{
+			TotalCost: cost, InputTokens: 100, OutputTokens: 10, Model: model, Provider: prov,
+		},
+		SkillID: skill, ExecutionID: exec, PhaseID: phase, RecordedAt: at,
+	}
+}
+
+func TestCostLedgerRepository_Summarize(t *testing.T) {
+	repo := NewCostLedgerRepository(setupCostLedgerTestDB(t))
+	ctx := context.Background()
+	now := time.Now()
+
+	for _, r := range []*provider.CostRecord{
+		costRecord("exec-1", "draft", "summarize", "anthropic", "claude-sonnet", 0.02, now),
+		costRecord("exec-1", "polish", "summarize", "openai", "gpt-4o", 0.01, now),
+		costRecord("exec-2", "draft", "review", "anthropic", "claude-haiku", 0.005, now),
+		costRecord("exec-0", "draft", "review", "anthropic", "claude-sonnet", 1, now.AddDate(0, 0, -30)),
+	} {
+		if err := repo.Record(ctx, r); err != nil {
+			t.Fatalf("Record() error = %v", err)
+		}
+	}
+
+	// A resumed run records its earlier phases again; they count once
+	if err := repo.Record(ctx, costRecord("exec-1", "draft", "summarize", "anthropic", "claude-sonnet", 0.02, now)); err != nil {
+		t.Fatalf("Record() duplicate error = %v", err)
+	}
+
+	groups, err := repo.Summarize(ctx, now.AddDate(0, 0, -7), provider.CostByProvider)
+	if err != nil {
+		t.Fatalf("Summarize() error = %v", err)
+	}
+	if len(groups) != 2 || groups[0].Key != "anthropic" || groups[0].Invocations != 2 || groups[0].InputTokens != 200 {
+		t.Fatalf("Summarize(provider) = %+v, want anthropic first with 2 invocations", groups)
+	}
+	if got := groups[0].TotalCost; got < 0.0249 || got > 0.0251 {
+		t.Errorf("anthropic cost = %v, want 0.025 (the old entry is out of range)", got)
+	}
+
+	groups, err = repo.Summarize(ctx, now.AddDate(0, 0, -7), provider.CostBySkill)
+	if err != nil {
+		t.Fatalf("Summarize() error = %v", err)
+	}
+	if len(groups) != 2 || groups[0].Key != "summarize" || groups[1].Key != "review" {
+		t.Errorf("Summarize(skill) = %+v, want summarize then review", groups)
+	}
+
+	if _, err := repo.Summarize(ctx, now, provider.CostGroupBy("phase")); err == nil {
+		t.Error("Summarize() should reject an unknown grouping")
+	}
+}
+
+func TestCostLedgerRepository_SummarizeByTag(t *testing.T) {
+	repo := NewCostLedgerRepository(setupCostLedgerTestDB(t))
+	ctx := context.Background()
+	now := time.Now()
+
+	tagged := func(r *provider.CostRecord, tags map[string]string) *provider.CostRecord {
+		r.Tags = tags
+		return r
+	}
+	for _, r := range []*provider.CostRecord{
+		tagged(costRecord("exec-1", "draft", "summarize", "anthropic", "claude-sonnet", 0.02, now), map[string]string{"team": "search", "ticket": "JIRA-1"}),
+		tagged(costRecord("exec-1", "polish", "summarize", "openai", "gpt-4o", 0.01, now), map[string]string{"team": "search", "ticket": "JIRA-1"}),
+		tagged(costRecord("exec-2", "draft", "review", "anthropic", "claude-haiku", 0.05, now), map[string]string{"team": "ads"}),
+		costRecord("exec-3", "draft", "review", "anthropic", "claude-haiku", 0.001, now),
+	} {
+		if err := repo.Record(ctx, r); err != nil {
+			t.Fatalf("Record() error = %v", err)
+		}
+	}
+
+	groups, err := repo.Summarize(ctx, now.AddDate(0, 0, -7), provider.CostByTag("team"))
+	if err != nil {
+		t.Fatalf("Summarize() error = %v", err)
+	}
+	if len(groups) != 3 || groups[0].Key != "ads" || groups[1].Key != "search" || groups[1].Invocations != 2 || groups[2].Key != "" {
+		t.Errorf("Summarize(tag:team) = %+v, want ads, search, then untagged", groups)
+	}
+
+	groups, err = repo.Summarize(ctx, now.AddDate(0, 0, -7), provider.CostByTag("ticket"))
+	if err != nil {
+		t.Fatalf("Summarize() error = %v", err)
+	}
+	if len(groups) != 2 || groups[0].Key != "" || groups[0].Invocations != 2 || groups[1].Key != "JIRA-1" {
+		t.Errorf("Summarize(tag:ticket) = %+v, want untagged then JIRA-1", groups)
+	}
+}
@@ -186,6 +186,103 @@ func (r *MetricsRepository) GetExecutions(ctx context.Context, filter metrics.Me
 	return executions, nil
 }
 
+// GetPhaseExecutions retrieves phase execution records matching the filter, oldest first.
+func (r *MetricsRepository) GetPhaseExecutions(ctx context.Context, filter metrics.MetricsFilter) ([]metrics.PhaseExecutionRecord, error) {
+	query := `
+		SELECT id, execution_id, phase_id, phase_name, status, provider, model,
+			input_tokens, output_tokens, cost, duration_ns, cache_hit,
+			started_at, completed_at, COALESCE(error_message, '')
+		FROM phase_execution_records
+		WHERE 1=1
+	`
+	args := make([]any, 0)
+
+	if filter.SkillID != "" {
+		query += " AND execution_id IN (SELECT id FROM execution_records WHERE skill_id = ?)"
+		args = append(args, filter.SkillID)
+	}
+
+	if filter.Provider != "" {
+		query += " AND provider = ?"
+		args = append(args, filter.Provider)
+	}
+
+	if filter.Model != "" {
+		query += " AND model = ?"
+		args = append(args, filter.Model)
+	}
+
+	if filter.Status != "" {
+		query += " AND status = ?"
+		args = append(args, filter.Status)
+	}
+
+	if !filter.StartDate.IsZero() {
+		query += " AND started_at >= ?"
+		args = append(args, filter.StartDate.UTC().Format(time.RFC3339))
+	}
+
+	if !filter.EndDate.IsZero() {
+		query += " AND started_at <= ?"
+		args = append(args, filter.EndDate.UTC().Format(time.RFC3339))
+	}
+
+	query += " ORDER BY started_at ASC, id ASC"
+
+	if filter.Limit > 0 {
+		query += fmt.Sprintf(" LIMIT %d", filter.Limit)
+		if filter.Offset > 0 {
+			query += fmt.Sprintf(" OFFSET %d", filter.Offset)
+		}
+	}
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query phase executions: %w", err)
+	}
+	defer rows.Close()
+
+	var phases []metrics.PhaseExecutionRecord
+	for rows.Next() {
+		var phase metrics.PhaseExecutionRecord
+		var durationNs int64
+		var startedAt, completedAt string
+
+		err := rows.Scan(
+			&phase.ID,
+			&phase.ExecutionID,
+			&phase.PhaseID,
+			&phase.PhaseName,
+			&phase.Status,
+			&phase.Provider,
+			&phase.Model,
+			&phase.InputTokens,
+			&phase.OutputTokens,
+			&phase.Cost,
+			&durationNs,
+			&phase.CacheHit,
+			&startedAt,
+			&completedAt,
+			&phase.ErrorMessage,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan phase execution record: %w", err)
+		}
+
+		phase.Duration = time.Duration(durationNs)
+		phase.StartedAt, _ = time.Parse(time.RFC3339, startedAt)
+		phase.CompletedAt, _ = time.Parse(time.RFC3339, completedAt)
+
+		phases = append(phases, phase)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating phase execution records: %w", err)
+	}
+
+	return phases, nil
+}
+
 // GetAggregatedMetrics retrieves aggregated metrics for the given filter.
 func (r *MetricsRepository) GetAggregatedMetrics(ctx context.Context, filter metrics.MetricsFilter) (*metrics.AggregatedMetrics, error) {
 	period := metrics.TimePeriod{Start: filter.StartDate, End: filter.EndDate}
@@ -292,8 +389,8 @@ func (r *MetricsRepository) GetProviderMetrics(ctx context.Context, filter metri
 			COALESCE(AVG(duration_ns), 0) as avg_latency,
 			COALESCE(MIN(duration_ns), 0) as min_latency,
 			COALESCE(MAX(duration_ns), 0) as max_latency,
-			SUM(CASE WHEN cache_hit = 1 THEN 1 ELSE 0 END) as cache_hits,
-			SUM(CASE WHEN cache_hit = 0 THEN 1 ELSE 0 END) as cache_misses
+			SUM(CASE WHEN cache_hit THEN 1 ELSE 0 END) as cache_hits,
+			SUM(CASE WHEN NOT cache_hit THEN 1 ELSE 0 END) as cache_misses
 		FROM phase_execution_records
 		WHERE started_at >= ? AND started_at <= ?
 		GROUP BY provider
@@ -266,6 +266,59 @@ func TestMetricsRepository_GetExecutions(t *testing.T) {
 	}
 }
 
+func TestMetricsRepository_GetPhaseExecutions(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	repo := NewMetricsRepository(db)
+	ctx := context.Background()
+
+	now := time.Now()
+
+	for _, exec := range []*metrics.ExecutionRecord{
+		{ID: "exec-1", SkillID: "code-review", SkillName: "Code Review", Status: "completed", StartedAt: now.Add(-2 * time.Hour), CompletedAt: now},
+		{ID: "exec-2", SkillID: "test-gen", SkillName: "Test Generator", Status: "completed", StartedAt: now.Add(-time.Hour), CompletedAt: now},
+	} {
+		if err := repo.SaveExecution(ctx, exec); err != nil {
+			t.Fatalf("failed to save execution: %v", err)
+		}
+	}
+
+	phases := []*metrics.PhaseExecutionRecord{
+		{ID: "p-2", ExecutionID: "exec-1", PhaseID: "review", PhaseName: "Review", Status: "completed", Provider: "openai", Model: "gpt-4o", StartedAt: now.Add(-90 * time.Minute), CompletedAt: now},
+		{ID: "p-1", ExecutionID: "exec-1", PhaseID: "analyze", PhaseName: "Analyze", Status: "completed", Provider: "anthropic", Model: "claude-3-5-sonnet", InputTokens: 500, OutputTokens: 200, Cost: 0.01, StartedAt: now.Add(-2 * time.Hour), CompletedAt: now},
+		{ID: "p-3", ExecutionID: "exec-2", PhaseID: "generate", PhaseName: "Generate", Status: "failed", Provider: "anthropic", Model: "claude-3-5-sonnet", ErrorMessage: "timeout", StartedAt: now.Add(-time.Hour), CompletedAt: now},
+	}
+	for _, phase := range phases {
+		if err := repo.SavePhaseExecution(ctx, phase); err != nil {
+			t.Fatalf("failed to save phase execution: %v", err)
+		}
+	}
+
+	filter := metrics.MetricsFilter{StartDate: now.Add(-3 * time.Hour), EndDate: now}
+	results, err := repo.GetPhaseExecutions(ctx, filter)
+	if err != nil {
+		t.Fatalf("failed to get phase executions: %v", err)
+	}
+	if len(results) != 3 || results[0].ID != "p-1" || results[2].ID != "p-3" {
+		t.Fatalf("expected 3 phases oldest first, got %+v", results)
+	}
+	if results[0].InputTokens != 500 || results[0].Cost != 0.01 || results[2].ErrorMessage != "timeout" {
+		t.Errorf("phase fields not round-tripped: %+v", results)
+	}
+
+	filter.SkillID = "code-review"
+	if results, _ = repo.GetPhaseExecutions(ctx, filter); len(results) != 2 {
+		t.Errorf("expected 2 code-review phases, got %d", len(results))
+	}
+
+	filter.SkillID = ""
+	filter.Provider = "anthropic"
+	if results, _ = repo.GetPhaseExecutions(ctx, filter); len(results) != 2 {
+		t.Errorf("expected 2 anthropic phases, got %d", len(results))
+	}
+}
+
 func TestMetricsRepository_GetAggregatedMetrics(t *testing.T) {
 	db := setupTestDB(t)
 	defer db.Close()
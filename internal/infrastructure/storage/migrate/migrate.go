@@ -0,0 +1,203 @@
+// Package migrate applies versioned schema migrations to SQL databases. It is
+// shared by the storage backends: each backend supplies its own migrations and
+// SQL dialect, and applied migrations are recorded in a migrations table of
+// the same shape in every backend.
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/jbctechsolutions/skillrunner/internal/application/ports"
+)
+
+// Migration is one versioned schema change.
+type Migration struct {
+	Version int    // Positive and unique; migrations apply in ascending order
+	Name    string // Short description, e.g. create_sessions_table
+	SQL     string // Statements to execute, in the backend's dialect
+}
+
+// Dialect holds the SQL that differs between databases.
+type Dialect struct {
+	Name string
+
+	// Placeholder returns the bind parameter for the n-th argument of a
+	// statement, counting from 1.
+	Placeholder func(n int) string
+}
+
+// Dialects of the databases skillrunner ships migrations for.
+var (
+	SQLite   = Dialect{Name: "sqlite", Placeholder: func(int) string { return "?" }}
+	Postgres = Dialect{Name: "postgres", Placeholder: func(n int) string { return fmt.Sprintf("$%d", n) }}
+)
+
+// Rebind rewrites the ? placeholders in query into the dialect's bind
+// parameters, so that queries written for SQLite run unchanged. Question marks
+// inside quoted strings and identifiers are left alone.
+func (d Dialect) Rebind(query string) string {
+	if d.Placeholder(1) == "?" || !strings.Contains(query, "?") {
+		return query
+	}
+
+	var b strings.Builder
+	b.Grow(len(query) + 8)
+	var quote rune
+	n := 0
+	for _, r := range query {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '\'' || r == '"':
+			quote = r
+		case r == '?':
+			n++
+			b.WriteString(d.Placeholder(n))
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// createMigrationsTable creates the table recording applied migrations.
+const createMigrationsTable = `
+	CREATE TABLE IF NOT EXISTS migrations (
+		version INTEGER PRIMARY KEY,
+		name TEXT NOT NULL,
+		applied_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	)
+`
+
+// Apply applies the migrations that have not been applied yet, in version
+// order, and returns how many it applied. Each migration runs in a
+// transaction together with its record, so a failed migration leaves no
+// trace and is retried on the next call.
+func Apply(ctx context.Context, db *sql.DB, dialect Dialect, migrations []Migration) (int, error) {
+	if err := Validate(migrations); err != nil {
+		return 0, err
+	}
+	if _, err := db.ExecContext(ctx, createMigrationsTable); err != nil {
+		return 0, fmt.Errorf("could not create migrations table: %w", err)
+	}
+
+	applied := 0
+	for _, m := range sorted(migrations) {
+		done, err := IsApplied(ctx, db, dialect, m.Version)
+		if err != nil {
+			return applied, fmt.Errorf("could not check migration %d: %w", m.Version, err)
+		}
+		if done {
+			continue
+		}
+
+		if err := apply(ctx, db, dialect, m); err != nil {
+			return applied, fmt.Errorf("could not apply migration %d (%s): %w", m.Version, m.Name, err)
+		}
+		applied++
+	}
+	return applied, nil
+}
+
+// apply executes a migration and records it in one transaction.
+func apply(ctx context.Context, db *sql.DB, dialect Dialect, m Migration) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, m.SQL); err != nil {
+		return err
+	}
+	insert := fmt.Sprintf("INSERT INTO migrations (version, name) VALUES (%s, %s)", dialect.Placeholder(1), dialect.Placeholder(2))
+	if _, err := tx.ExecContext(ctx, insert, m.Version, m.Name); err != nil {
+		return fmt.Errorf("could not record migration: %w", err)
+	}
+	return tx.Commit()
+}
+
+// IsApplied reports whether the migration with the given version has been
+// applied. The migrations table must exist.
+func IsApplied(ctx context.Context, db *sql.DB, dialect Dialect, version int) (bool, error) {
+	var count int
+	query := "SELECT COUNT(*) FROM migrations WHERE version = " + dialect.Placeholder(1)
+	if err := db.QueryRowContext(ctx, query, version).Scan(&count); err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// Status reports each migration in version order and whether it has been
+// applied, without changing the database.
+func Status(ctx context.Context, db *sql.DB, migrations []Migration) ([]ports.MigrationStatus, error) {
+	if _, err := db.ExecContext(ctx, createMigrationsTable); err != nil {
+		return nil, fmt.Errorf("could not create migrations table: %w", err)
+	}
+
+	rows, err := db.QueryContext(ctx, "SELECT version, applied_at FROM migrations")
+	if err != nil {
+		return nil, fmt.Errorf("could not list applied migrations: %w", err)
+	}
+	defer rows.Close()
+
+	appliedAt := make(map[int]sql.NullTime)
+	for rows.Next() {
+		var version int
+		var at sql.NullTime
+		if err := rows.Scan(&version, &at); err != nil {
+			return nil, fmt.Errorf("could not read applied migration: %w", err)
+		}
+		appliedAt[version] = at
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	statuses := make([]ports.MigrationStatus, 0, len(migrations))
+	for _, m := range sorted(migrations) {
+		at, applied := appliedAt[m.Version]
+		statuses = append(statuses, ports.MigrationStatus{
+			Version:   m.Version,
+			Name:      m.Name,
+			Applied:   applied,
+			AppliedAt: at.Time,
+		})
+	}
+	return statuses, nil
+}
+
+// Validate checks that migration versions are positive and unique and that
+// every migration has a name and SQL.
+func Validate(migrations []Migration) error {
+	var errs []error
+	seen := make(map[int]bool, len(migrations))
+	for _, m := range migrations {
+		switch {
+		case m.Version <= 0:
+			errs = append(errs, fmt.Errorf("migration %q: version must be positive, got %d", m.Name, m.Version))
+		case seen[m.Version]:
+			errs = append(errs, fmt.Errorf("migration %d: duplicate version", m.Version))
+		}
+		seen[m.Version] = true
+
+		if m.Name == "" || m.SQL == "" {
+			errs = append(errs, fmt.Errorf("migration %d: name and SQL are required", m.Version))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// sorted returns the migrations in ascending version order.
+func sorted(migrations []Migration) []Migration {
+	return slices.SortedFunc(slices.Values(migrations), func(a, b Migration) int {
+		return a.Version - b.Version
+	})
+}
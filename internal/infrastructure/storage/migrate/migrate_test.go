@@ -0,0 +1,127 @@
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"slices"
+	"testing"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func openTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatalf("sql.Open() error = %v", err)
+	}
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+var testMigrations = []Migration{
+	{Version: 2, Name: "create_notes_index", SQL: "CREATE INDEX idx_notes_body ON notes(body)"},
+	{Version: 1, Name: "create_notes_table", SQL: "CREATE TABLE notes (id INTEGER PRIMARY KEY, body TEXT)"},
+}
+
+func TestApply(t *testing.T) {
+	ctx := context.Background()
+	db := openTestDB(t)
+
+	applied, err := Apply(ctx, db, SQLite, testMigrations)
+	if err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+	if applied != 2 {
+		t.Errorf("Apply() applied %d migrations, want 2 in version order", applied)
+	}
+
+	applied, err = Apply(ctx, db, SQLite, testMigrations)
+	if err != nil || applied != 0 {
+		t.Errorf("second Apply() = %d, %v; want nothing to apply", applied, err)
+	}
+
+	for _, version := range []int{1, 2} {
+		if ok, err := IsApplied(ctx, db, SQLite, version); err != nil || !ok {
+			t.Errorf("IsApplied(%d) = %v, %v; want true", version, ok, err)
+		}
+	}
+	if ok, err := IsApplied(ctx, db, SQLite, 3); err != nil || ok {
+		t.Errorf("IsApplied(3) = %v, %v; want false", ok, err)
+	}
+}
+
+func TestApply_FailedMigrationIsNotRecorded(t *testing.T) {
+	ctx := context.Background()
+	db := openTestDB(t)
+
+	broken := append(slices.Clone(testMigrations), Migration{Version: 3, Name: "broken", SQL: "CREATE TABLE notes (id INTEGER)"})
+	applied, err := Apply(ctx, db, SQLite, broken)
+	if err == nil {
+		t.Fatal("Apply() error = nil, want the failing migration's error")
+	}
+	if applied != 2 {
+		t.Errorf("Apply() applied %d migrations, want the 2 before the failure", applied)
+	}
+
+	statuses, err := Status(ctx, db, broken)
+	if err != nil {
+		t.Fatalf("Status() error = %v", err)
+	}
+	if len(statuses) != 3 || !statuses[0].Applied || !statuses[1].Applied || statuses[2].Applied {
+		t.Errorf("Status() = %+v, want versions 1 and 2 applied and 3 pending", statuses)
+	}
+	if statuses[0].AppliedAt.IsZero() || !statuses[2].AppliedAt.IsZero() {
+		t.Errorf("Status() = %+v, want applied times for applied migrations only", statuses)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name       string
+		migrations []Migration
+		wantErr    bool
+	}{
+		{"valid", testMigrations, false},
+		{"zero version", []Migration{{Version: 0, Name: "a", SQL: "SELECT 1"}}, true},
+		{"duplicate version", []Migration{{Version: 1, Name: "a", SQL: "SELECT 1"}, {Version: 1, Name: "b", SQL: "SELECT 1"}}, true},
+		{"missing SQL", []Migration{{Version: 1, Name: "a"}}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := Validate(tt.migrations); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestDialectPlaceholders(t *testing.T) {
+	if got := SQLite.Placeholder(2); got != "?" {
+		t.Errorf("SQLite.Placeholder(2) = %q, want ?", got)
+	}
+	if got := Postgres.Placeholder(2); got != "$2" {
+		t.Errorf("Postgres.Placeholder(2) = %q, want $2", got)
+	}
+}
+
+func TestDialectRebind(t *testing.T) {
+	tests := []struct {
+		dialect Dialect
+		query   string
+		want    string
+	}{
+		{SQLite, "SELECT * FROM t WHERE a = ? AND b = ?", "SELECT * FROM t WHERE a = ? AND b = ?"},
+		{Postgres, "SELECT * FROM t WHERE a = ? AND b = ?", "SELECT * FROM t WHERE a = $1 AND b = $2"},
+		{Postgres, "SELECT '?' FROM \"t?\" WHERE a = ?", "SELECT '?' FROM \"t?\" WHERE a = $1"},
+		{Postgres, "SELECT 'it''s' WHERE a = ?", "SELECT 'it''s' WHERE a = $1"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.dialect.Rebind(tt.query); got != tt.want {
+			t.Errorf("%s.Rebind(%q) = %q, want %q", tt.dialect.Name, tt.query, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/jbctechsolutions/skillrunner/internal/application/ports"
+	"github.com/jbctechsolutions/skillrunner/internal/domain/workflow"
+)
+
+// Compile-time check that RunNoteRepository implements RunNotePort.
+var _ ports.RunNotePort = (*RunNoteRepository)(nil)
+
+// RunNoteRepository implements RunNotePort using SQLite.
+type RunNoteRepository struct {
+	db *sql.DB
+}
+
+// NewRunNoteRepository creates a new run note repository.
+func NewRunNoteRepository(db *sql.DB) *RunNoteRepository {
+	return &RunNoteRepository{db: db}
+}
+
+// Add persists a note.
+func (r *RunNoteRepository) Add(ctx context.Context, note *workflow.RunNote) error {
+	query := `
+		INSERT INTO run_notes (run_id, text, created_at)
+		VALUES (?, ?, ?)
+	`
+
+	_, err := r.db.ExecContext(ctx, query,
+		note.RunID,
+		note.Text,
+		note.CreatedAt.UTC().Format(time.RFC3339Nano),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to add run note: %w", err)
+	}
+
+	return nil
+}
+
+// List returns the notes on a run, oldest first.
+func (r *RunNoteRepository) List(ctx context.Context, runID string) ([]*workflow.RunNote, error) {
+	query := `
+		SELECT run_id, text, created_at
+		FROM run_notes
+		WHERE run_id = ?
+		ORDER BY id
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, runID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query run notes: %w", err)
+	}
+	defer rows.Close()
+
+	notes := []*workflow.RunNote{}
+	for rows.Next() {
+		var note workflow.RunNote
+		var createdAt string
+		if err := rows.Scan(&note.RunID, &note.Text, &createdAt); err != nil {
+			return nil, fmt.Errorf("failed to scan run note: %w", err)
+		}
+		if note.CreatedAt, err = time.Parse(time.RFC3339Nano, createdAt); err != nil {
+			return nil, fmt.Errorf("failed to parse created_at: %w", err)
+		}
+		notes = append(notes, &note)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating run notes: %w", err)
+	}
+
+	return notes, nil
+}
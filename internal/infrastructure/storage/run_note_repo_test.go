@@ -0,0 +1,70 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	_ "github.com/mattn/go-sqlite3"
+
+	"github.com/jbctechsolutions/skillrunner/internal/domain/workflow"
+)
+
+func setupRunNoteTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatalf("failed to open in-memory database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	_, err = db.Exec(`
+		CREATE TABLE run_notes (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			run_id TEXT NOT NULL,
+			text TEXT NOT NULL,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		);
+	`)
+	if err != nil {
+		t.Fatalf("failed to create table: %v", err)
+	}
+
+	return db
+}
+
+func TestRunNoteRepository_AddAndList(t *testing.T) {
+	repo := NewRunNoteRepository(setupRunNoteTestDB(t))
+	ctx := context.Background()
+
+	for _, n := range []struct{ run, text string }{
+		{"cp-1", "baseline prompt"},
+		{"cp-2", "other run"},
+		{"cp-1", "this prompt version regressed"},
+	} {
+		note, err := workflow.NewRunNote(n.run, n.text)
+		if err != nil {
+			t.Fatalf("NewRunNote() error = %v", err)
+		}
+		if err := repo.Add(ctx, note); err != nil {
+			t.Fatalf("Add() error = %v", err)
+		}
+	}
+
+	notes, err := repo.List(ctx, "cp-1")
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(notes) != 2 || notes[0].Text != "baseline prompt" || notes[1].Text != "this prompt version regressed" {
+		t.Fatalf("List() = %+v, want both cp-1 notes, oldest first", notes)
+	}
+	if notes[0].RunID != "cp-1" || notes[0].CreatedAt.IsZero() {
+		t.Errorf("note = %+v, want run ID and creation time", notes[0])
+	}
+
+	notes, err = repo.List(ctx, "cp-3")
+	if err != nil || len(notes) != 0 {
+		t.Errorf("List() of a run without notes = %v, %v; want empty", notes, err)
+	}
+}
@@ -0,0 +1,219 @@
+package storage
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/jbctechsolutions/skillrunner/internal/domain/workflow"
+)
+
+// archiveFileSuffix is the file name suffix of archived phase outputs.
+const archiveFileSuffix = ".json.gz"
+
+// archivedOutputs is the content of an archive file: the outputs taken out
+// of a checkpoint's row.
+type archivedOutputs struct {
+	PhaseOutputs  map[string]string `json:"phase_outputs"`
+	ResultOutputs map[string]string `json:"result_outputs"`
+}
+
+// archiveCandidate is a checkpoint row whose outputs are to be archived.
+type archiveCandidate struct {
+	id                                 string
+	phaseResultsJSON, phaseOutputsJSON string
+}
+
+// ArchiveOutputs moves the phase outputs of completed, failed and abandoned
+// runs created more than olderThan ago into gzip-compressed JSON files in
+// dir, one per checkpoint, and keeps the rest of the checkpoints in the
+// database. Archive files of checkpoints deleted since are removed.
+func (r *WorkflowCheckpointRepository) ArchiveOutputs(ctx context.Context, olderThan time.Duration, dir string) (int, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return 0, fmt.Errorf("failed to create archive directory: %w", err)
+	}
+
+	candidates, err := r.archiveCandidates(ctx, time.Now().Add(-olderThan))
+	if err != nil {
+		return 0, err
+	}
+
+	archived := 0
+	for _, c := range candidates {
+		var results map[string]*workflow.PhaseResultData
+		if c.phaseResultsJSON != "" && c.phaseResultsJSON != "null" {
+			if err := json.Unmarshal([]byte(c.phaseResultsJSON), &results); err != nil {
+				return archived, fmt.Errorf("failed to unmarshal phase results of %s: %w", c.id, err)
+			}
+		}
+		content := archivedOutputs{ResultOutputs: make(map[string]string, len(results))}
+		if c.phaseOutputsJSON != "" && c.phaseOutputsJSON != "null" {
+			if err := json.Unmarshal([]byte(c.phaseOutputsJSON), &content.PhaseOutputs); err != nil {
+				return archived, fmt.Errorf("failed to unmarshal phase outputs of %s: %w", c.id, err)
+			}
+		}
+		for phaseID, result := range results {
+			content.ResultOutputs[phaseID] = result.Output
+			result.Output = ""
+		}
+
+		path := filepath.Join(dir, c.id+archiveFileSuffix)
+		if err := writeArchive(path, content); err != nil {
+			return archived, err
+		}
+		hotResults, err := json.Marshal(results)
+		if err != nil {
+			return archived, fmt.Errorf("failed to marshal phase results: %w", err)
+		}
+
+		// Skip checkpoints updated since they were read
+		result, err := r.db.ExecContext(ctx, `
+			UPDATE workflow_checkpoints
+			SET phase_results = ?, phase_outputs = ?, archive_path = ?
+			WHERE id = ? AND phase_results = ? AND archive_path IS NULL
+		`, string(hotResults), "{}", path, c.id, c.phaseResultsJSON)
+		if err != nil {
+			return archived, fmt.Errorf("failed to archive workflow checkpoint: %w", err)
+		}
+		if rows, err := result.RowsAffected(); err == nil && rows > 0 {
+			archived++
+		}
+	}
+
+	if err := r.removeOrphanArchives(ctx, dir); err != nil {
+		return archived, err
+	}
+	return archived, nil
+}
+
+// archiveCandidates returns the unarchived rows of ended runs created before
+// cutoff.
+func (r *WorkflowCheckpointRepository) archiveCandidates(ctx context.Context, cutoff time.Time) ([]archiveCandidate, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, phase_results, phase_outputs
+		FROM workflow_checkpoints
+		WHERE created_at < ? AND status IN (?, ?, ?) AND archive_path IS NULL
+	`,
+		cutoff.Format(time.RFC3339),
+		string(workflow.CheckpointStatusCompleted),
+		string(workflow.CheckpointStatusFailed),
+		string(workflow.CheckpointStatusAbandoned),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query checkpoints to archive: %w", err)
+	}
+	defer rows.Close()
+
+	var candidates []archiveCandidate
+	for rows.Next() {
+		var id string
+		var results, outputs *string
+		if err := rows.Scan(&id, &results, &outputs); err != nil {
+			return nil, fmt.Errorf("failed to scan checkpoint to archive: %w", err)
+		}
+		c := archiveCandidate{id: id}
+		if results != nil {
+			c.phaseResultsJSON = *results
+		}
+		if outputs != nil {
+			c.phaseOutputsJSON = *outputs
+		}
+		candidates = append(candidates, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating checkpoints to archive: %w", err)
+	}
+	return candidates, nil
+}
+
+// removeOrphanArchives removes the archive files in dir whose checkpoints
+// no longer exist.
+func (r *WorkflowCheckpointRepository) removeOrphanArchives(ctx context.Context, dir string) error {
+	rows, err := r.db.QueryContext(ctx, `SELECT archive_path FROM workflow_checkpoints WHERE archive_path IS NOT NULL`)
+	if err != nil {
+		return fmt.Errorf("failed to query archived checkpoints: %w", err)
+	}
+	defer rows.Close()
+
+	inUse := make(map[string]bool)
+	for rows.Next() {
+		var path string
+		if err := rows.Scan(&path); err != nil {
+			return fmt.Errorf("failed to scan archived checkpoint: %w", err)
+		}
+		inUse[filepath.Clean(path)] = true
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error iterating archived checkpoints: %w", err)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return fmt.Errorf("failed to read archive directory: %w", err)
+	}
+	for _, e := range entries {
+		path := filepath.Join(dir, e.Name())
+		if !e.IsDir() && strings.HasSuffix(e.Name(), archiveFileSuffix) && !inUse[path] {
+			_ = os.Remove(path)
+		}
+	}
+	return nil
+}
+
+// writeArchive writes archived outputs to path, replacing any earlier
+// archive of the checkpoint only once the new one is complete.
+func writeArchive(path string, content archivedOutputs) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".archive-*")
+	if err != nil {
+		return fmt.Errorf("failed to create archive file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	zw := gzip.NewWriter(tmp)
+	if err := json.NewEncoder(zw).Encode(content); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("failed to write archive file: %w", err)
+	}
+	if err := zw.Close(); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("failed to write archive file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write archive file: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to write archive file: %w", err)
+	}
+	return nil
+}
+
+// rehydrateOutputs reads the archived outputs of a checkpoint back: it sets
+// the output of each phase result and returns the phase outputs.
+func rehydrateOutputs(path string, results map[string]*workflow.PhaseResultData) (map[string]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read archived phase outputs: %w", err)
+	}
+	defer f.Close()
+
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read archived phase outputs %s: %w", path, err)
+	}
+	var content archivedOutputs
+	if err := json.NewDecoder(zr).Decode(&content); err != nil {
+		return nil, fmt.Errorf("failed to read archived phase outputs %s: %w", path, err)
+	}
+
+	for phaseID, output := range content.ResultOutputs {
+		if result, ok := results[phaseID]; ok {
+			result.Output = output
+		}
+	}
+	return content.PhaseOutputs, nil
+}
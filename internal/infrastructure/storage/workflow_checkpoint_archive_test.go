@@ -0,0 +1,156 @@
+package storage
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jbctechsolutions/skillrunner/internal/domain/workflow"
+)
+
+func TestWorkflowCheckpointRepository_ArchiveOutputs(t *testing.T) {
+	db := setupWorkflowCheckpointTestDB(t)
+	defer db.Close()
+
+	repo := NewWorkflowCheckpointRepository(db)
+	ctx := context.Background()
+	dir := filepath.Join(t.TempDir(), "archive")
+
+	completed := createTestCheckpoint(t, "cp-completed")
+	completed.AddPhaseOutput("_input", "test input")
+	completed.AddPhaseOutput("review", "a long review")
+	completed.AddPhaseResult("review", &workflow.PhaseResultData{PhaseID: "review", Status: "completed", Output: "a long review", ModelUsed: "gpt-4o"})
+	completed.MarkCompleted()
+	running := createTestCheckpoint(t, "cp-running")
+	running.AddPhaseOutput("review", "in progress")
+	for _, cp := range []*workflow.WorkflowCheckpoint{completed, running} {
+		if err := repo.Create(ctx, cp); err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+	}
+
+	// Nothing is old enough yet
+	if n, err := repo.ArchiveOutputs(ctx, time.Hour, dir); err != nil || n != 0 {
+		t.Fatalf("ArchiveOutputs() = %d, %v; want nothing archived", n, err)
+	}
+	n, err := repo.ArchiveOutputs(ctx, -time.Hour, dir)
+	if err != nil || n != 1 {
+		t.Fatalf("ArchiveOutputs() = %d, %v; want the completed run archived", n, err)
+	}
+
+	var hot string
+	if err := db.QueryRow(`SELECT phase_outputs || phase_results FROM workflow_checkpoints WHERE id = ?`, "cp-completed").Scan(&hot); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(hot, "a long review") {
+		t.Errorf("row still holds the outputs: %s", hot)
+	}
+
+	// Reads bring the outputs back
+	got, err := repo.Get(ctx, "cp-completed")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if got.PhaseOutputs()["review"] != "a long review" || got.PhaseResults()["review"].Output != "a long review" || got.PhaseResults()["review"].ModelUsed != "gpt-4o" {
+		t.Errorf("Get() = %v, %+v; want the archived outputs", got.PhaseOutputs(), got.PhaseResults()["review"])
+	}
+	listed, err := repo.List(ctx, nil)
+	if err != nil || len(listed) != 2 {
+		t.Fatalf("List() = %d checkpoints, %v", len(listed), err)
+	}
+
+	// Archive files of deleted checkpoints are removed on the next pass
+	if err := repo.Delete(ctx, "cp-completed"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repo.ArchiveOutputs(ctx, -time.Hour, dir); err != nil {
+		t.Fatal(err)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+		t.Errorf("archive directory holds %d files, want none", len(entries))
+	}
+}
+
+func TestWorkflowCheckpointRepository_UpdateArchived(t *testing.T) {
+	db := setupWorkflowCheckpointTestDB(t)
+	defer db.Close()
+
+	repo := NewWorkflowCheckpointRepository(db)
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	cp := createTestCheckpoint(t, "cp-1")
+	cp.AddPhaseOutput("review", "output")
+	cp.MarkFailed()
+	if err := repo.Create(ctx, cp); err != nil {
+		t.Fatal(err)
+	}
+	if n, err := repo.ArchiveOutputs(ctx, -time.Hour, dir); err != nil || n != 1 {
+		t.Fatalf("ArchiveOutputs() = %d, %v", n, err)
+	}
+
+	// An update stores the rehydrated outputs in the row again
+	got, err := repo.Get(ctx, "cp-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.Update(ctx, got); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	if err := os.Remove(filepath.Join(dir, "cp-1"+archiveFileSuffix)); err != nil {
+		t.Fatal(err)
+	}
+	got, err = repo.Get(ctx, "cp-1")
+	if err != nil || got.PhaseOutputs()["review"] != "output" {
+		t.Errorf("Get() after Update() = %v, %v; want the outputs from the row", got, err)
+	}
+}
+
+func TestWorkflowCheckpointRepository_UnreadableArchive(t *testing.T) {
+	db := setupWorkflowCheckpointTestDB(t)
+	defer db.Close()
+
+	repo := NewWorkflowCheckpointRepository(db)
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	cp := createTestCheckpoint(t, "cp-1")
+	cp.AddPhaseOutput("review", "output")
+	cp.MarkCompleted()
+	if err := repo.Create(ctx, cp); err != nil {
+		t.Fatal(err)
+	}
+	if n, err := repo.ArchiveOutputs(ctx, -time.Hour, dir); err != nil || n != 1 {
+		t.Fatalf("ArchiveOutputs() = %d, %v", n, err)
+	}
+	archive := filepath.Join(dir, "cp-1"+archiveFileSuffix)
+	if err := os.WriteFile(archive, []byte("corrupt"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	// Listings do not read the archive
+	if listed, err := repo.List(ctx, nil); err != nil || len(listed) != 1 || listed[0].ArchiveError() != nil {
+		t.Fatalf("List() = %v, %v; want the checkpoint without reading its archive", listed, err)
+	}
+
+	// Reads degrade to a checkpoint without outputs
+	got, err := repo.Get(ctx, "cp-1")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if got.ArchiveError() == nil || len(got.PhaseOutputs()) != 0 {
+		t.Errorf("Get() = %v, %v; want no outputs and the archive error", got.PhaseOutputs(), got.ArchiveError())
+	}
+
+	// Updating it keeps the archive the outputs are in
+	if err := repo.Update(ctx, got); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	var archived bool
+	if err := db.QueryRow(`SELECT archive_path IS NOT NULL FROM workflow_checkpoints WHERE id = ?`, "cp-1").Scan(&archived); err != nil || !archived {
+		t.Errorf("archive_path set = %v, %v; want the archive kept", archived, err)
+	}
+}
@@ -14,8 +14,12 @@ import (
 	"github.com/jbctechsolutions/skillrunner/internal/domain/workflow"
 )
 
-// Compile-time check that WorkflowCheckpointRepository implements WorkflowCheckpointPort.
-var _ ports.WorkflowCheckpointPort = (*WorkflowCheckpointRepository)(nil)
+// Compile-time checks that WorkflowCheckpointRepository implements
+// WorkflowCheckpointPort and PhaseOutputArchiver.
+var (
+	_ ports.WorkflowCheckpointPort = (*WorkflowCheckpointRepository)(nil)
+	_ ports.PhaseOutputArchiver    = (*WorkflowCheckpointRepository)(nil)
+)
 
 // WorkflowCheckpointRepository implements WorkflowCheckpointPort using SQLite.
 type WorkflowCheckpointRepository struct {
@@ -43,12 +47,19 @@ func (r *WorkflowCheckpointRepository) Create(ctx context.Context, checkpoint *w
 		return fmt.Errorf("failed to marshal phase outputs: %w", err)
 	}
 
+	var tagsJSON []byte
+	if tags := checkpoint.Tags(); len(tags) > 0 {
+		if tagsJSON, err = json.Marshal(tags); err != nil {
+			return fmt.Errorf("failed to marshal tags: %w", err)
+		}
+	}
+
 	query := `
 		INSERT INTO workflow_checkpoints (
 			id, execution_id, skill_id, skill_name, input, input_hash,
 			completed_batch, total_batches, phase_results, phase_outputs,
-			status, input_tokens, output_tokens, machine_id, created_at, updated_at
-		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+			status, input_tokens, output_tokens, machine_id, pinned, tags, created_at, updated_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`
 
 	_, err = r.db.ExecContext(ctx, query,
@@ -66,6 +77,8 @@ func (r *WorkflowCheckpointRepository) Create(ctx context.Context, checkpoint *w
 		checkpoint.InputTokens(),
 		checkpoint.OutputTokens(),
 		nullableString(checkpoint.MachineID()),
+		checkpoint.Pinned(),
+		nullableString(string(tagsJSON)),
 		checkpoint.CreatedAt().Format(time.RFC3339),
 		checkpoint.UpdatedAt().Format(time.RFC3339),
 	)
@@ -80,17 +93,20 @@ func (r *WorkflowCheckpointRepository) Create(ctx context.Context, checkpoint *w
 	return nil
 }
 
-// Get retrieves a checkpoint by its unique identifier.
+// Get retrieves a checkpoint by its unique identifier, with its phase
+// outputs read back from the archive if they were archived. An archive that
+// cannot be read leaves the checkpoint without outputs, and its
+// ArchiveError set.
 func (r *WorkflowCheckpointRepository) Get(ctx context.Context, id string) (*workflow.WorkflowCheckpoint, error) {
 	query := `
 		SELECT id, execution_id, skill_id, skill_name, input, input_hash,
 			   completed_batch, total_batches, phase_results, phase_outputs,
-			   status, input_tokens, output_tokens, machine_id, created_at, updated_at
+			   status, input_tokens, output_tokens, machine_id, pinned, tags, created_at, updated_at, archive_path
 		FROM workflow_checkpoints
 		WHERE id = ?
 	`
 
-	checkpoint, err := r.scanRow(r.db.QueryRowContext(ctx, query, id))
+	checkpoint, err := r.scanRow(r.db.QueryRowContext(ctx, query, id), true)
 	if err == sql.ErrNoRows {
 		return nil, domainErrors.NewError(domainErrors.CodeNotFound, fmt.Sprintf("workflow checkpoint not found: %s", id), nil)
 	}
@@ -106,14 +122,14 @@ func (r *WorkflowCheckpointRepository) GetLatestInProgress(ctx context.Context,
 	query := `
 		SELECT id, execution_id, skill_id, skill_name, input, input_hash,
 			   completed_batch, total_batches, phase_results, phase_outputs,
-			   status, input_tokens, output_tokens, machine_id, created_at, updated_at
+			   status, input_tokens, output_tokens, machine_id, pinned, tags, created_at, updated_at, archive_path
 		FROM workflow_checkpoints
 		WHERE skill_id = ? AND input_hash = ? AND status = ?
 		ORDER BY updated_at DESC
 		LIMIT 1
 	`
 
-	checkpoint, err := r.scanRow(r.db.QueryRowContext(ctx, query, skillID, inputHash, string(workflow.CheckpointStatusInProgress)))
+	checkpoint, err := r.scanRow(r.db.QueryRowContext(ctx, query, skillID, inputHash, string(workflow.CheckpointStatusInProgress)), true)
 	if err == sql.ErrNoRows {
 		return nil, nil // No in-progress checkpoint found
 	}
@@ -124,12 +140,13 @@ func (r *WorkflowCheckpointRepository) GetLatestInProgress(ctx context.Context,
 	return checkpoint, nil
 }
 
-// GetByExecutionID retrieves all checkpoints for an execution.
+// GetByExecutionID retrieves all checkpoints for an execution. Archived
+// phase outputs are not read back; Get reads them.
 func (r *WorkflowCheckpointRepository) GetByExecutionID(ctx context.Context, executionID string) ([]*workflow.WorkflowCheckpoint, error) {
 	query := `
 		SELECT id, execution_id, skill_id, skill_name, input, input_hash,
 			   completed_batch, total_batches, phase_results, phase_outputs,
-			   status, input_tokens, output_tokens, machine_id, created_at, updated_at
+			   status, input_tokens, output_tokens, machine_id, pinned, tags, created_at, updated_at, archive_path
 		FROM workflow_checkpoints
 		WHERE execution_id = ?
 		ORDER BY updated_at DESC
@@ -138,7 +155,10 @@ func (r *WorkflowCheckpointRepository) GetByExecutionID(ctx context.Context, exe
 	return r.queryCheckpoints(ctx, query, executionID)
 }
 
-// Update persists changes to an existing checkpoint.
+// Update persists changes to an existing checkpoint. The phase outputs of an
+// archived checkpoint are read back in full, so they are stored in the row
+// again, unless they could not be read back: then the archive file still
+// holds them.
 func (r *WorkflowCheckpointRepository) Update(ctx context.Context, checkpoint *workflow.WorkflowCheckpoint) error {
 	if err := checkpoint.Validate(); err != nil {
 		return err
@@ -157,7 +177,8 @@ func (r *WorkflowCheckpointRepository) Update(ctx context.Context, checkpoint *w
 	query := `
 		UPDATE workflow_checkpoints
 		SET completed_batch = ?, phase_results = ?, phase_outputs = ?,
-			status = ?, input_tokens = ?, output_tokens = ?, updated_at = ?
+			status = ?, input_tokens = ?, output_tokens = ?, machine_id = ?, updated_at = ?,
+			archive_path = CASE WHEN ? THEN archive_path END
 		WHERE id = ?
 	`
 
@@ -168,7 +189,9 @@ func (r *WorkflowCheckpointRepository) Update(ctx context.Context, checkpoint *w
 		string(checkpoint.Status()),
 		checkpoint.InputTokens(),
 		checkpoint.OutputTokens(),
+		nullableString(checkpoint.MachineID()),
 		checkpoint.UpdatedAt().Format(time.RFC3339),
+		checkpoint.ArchiveError() != nil,
 		checkpoint.ID(),
 	)
 
@@ -189,11 +212,12 @@ func (r *WorkflowCheckpointRepository) Update(ctx context.Context, checkpoint *w
 }
 
 // List returns all checkpoints matching the optional filter criteria.
+// Archived phase outputs are not read back; Get reads them.
 func (r *WorkflowCheckpointRepository) List(ctx context.Context, filter *ports.WorkflowCheckpointFilter) ([]*workflow.WorkflowCheckpoint, error) {
 	query := `
 		SELECT id, execution_id, skill_id, skill_name, input, input_hash,
 			   completed_batch, total_batches, phase_results, phase_outputs,
-			   status, input_tokens, output_tokens, machine_id, created_at, updated_at
+			   status, input_tokens, output_tokens, machine_id, pinned, tags, created_at, updated_at, archive_path
 		FROM workflow_checkpoints
 		WHERE 1=1
 	`
@@ -267,9 +291,9 @@ func (r *WorkflowCheckpointRepository) Delete(ctx context.Context, id string) er
 	return nil
 }
 
-// DeleteByExecutionID removes all checkpoints for an execution.
+// DeleteByExecutionID removes all checkpoints for an execution, except pinned ones.
 func (r *WorkflowCheckpointRepository) DeleteByExecutionID(ctx context.Context, executionID string) (int, error) {
-	query := `DELETE FROM workflow_checkpoints WHERE execution_id = ?`
+	query := `DELETE FROM workflow_checkpoints WHERE execution_id = ? AND NOT pinned`
 
 	result, err := r.db.ExecContext(ctx, query, executionID)
 	if err != nil {
@@ -310,12 +334,31 @@ func (r *WorkflowCheckpointRepository) MarkAbandoned(ctx context.Context, machin
 	return int(rows), nil
 }
 
-// Cleanup removes checkpoints older than the specified duration.
+// SetPinned pins or unpins a checkpoint.
+func (r *WorkflowCheckpointRepository) SetPinned(ctx context.Context, id string, pinned bool) error {
+	result, err := r.db.ExecContext(ctx, `UPDATE workflow_checkpoints SET pinned = ? WHERE id = ?`, pinned, id)
+	if err != nil {
+		return fmt.Errorf("failed to pin workflow checkpoint: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check update result: %w", err)
+	}
+
+	if rows == 0 {
+		return domainErrors.NewError(domainErrors.CodeNotFound, fmt.Sprintf("workflow checkpoint not found: %s", id), nil)
+	}
+
+	return nil
+}
+
+// Cleanup removes checkpoints older than the specified duration, except pinned ones.
 func (r *WorkflowCheckpointRepository) Cleanup(ctx context.Context, olderThan time.Duration) (int, error) {
 	cutoff := time.Now().Add(-olderThan)
 	query := `
 		DELETE FROM workflow_checkpoints
-		WHERE created_at < ? AND status IN (?, ?, ?)
+		WHERE created_at < ? AND status IN (?, ?, ?) AND NOT pinned
 	`
 
 	result, err := r.db.ExecContext(ctx, query,
@@ -346,7 +389,7 @@ func (r *WorkflowCheckpointRepository) queryCheckpoints(ctx context.Context, que
 
 	var checkpoints []*workflow.WorkflowCheckpoint
 	for rows.Next() {
-		checkpoint, err := r.scanRows(rows)
+		checkpoint, err := r.scanRows(rows, false)
 		if err != nil {
 			return nil, err
 		}
@@ -360,8 +403,9 @@ func (r *WorkflowCheckpointRepository) queryCheckpoints(ctx context.Context, que
 	return checkpoints, nil
 }
 
-// scanRow scans a single row into a workflow checkpoint.
-func (r *WorkflowCheckpointRepository) scanRow(row *sql.Row) (*workflow.WorkflowCheckpoint, error) {
+// scanRow scans a single row into a workflow checkpoint, reading archived
+// phase outputs back if rehydrate is set.
+func (r *WorkflowCheckpointRepository) scanRow(row *sql.Row, rehydrate bool) (*workflow.WorkflowCheckpoint, error) {
 	var (
 		id, executionID, skillID, skillName, input, inputHash string
 		completedBatch, totalBatches                          int
@@ -369,13 +413,16 @@ func (r *WorkflowCheckpointRepository) scanRow(row *sql.Row) (*workflow.Workflow
 		status                                                string
 		inputTokens, outputTokens                             int
 		machineID                                             sql.NullString
+		pinned                                                bool
+		tagsJSON                                              sql.NullString
 		createdAt, updatedAt                                  string
+		archivePath                                           sql.NullString
 	)
 
 	err := row.Scan(
 		&id, &executionID, &skillID, &skillName, &input, &inputHash,
 		&completedBatch, &totalBatches, &phaseResultsJSON, &phaseOutputsJSON,
-		&status, &inputTokens, &outputTokens, &machineID, &createdAt, &updatedAt,
+		&status, &inputTokens, &outputTokens, &machineID, &pinned, &tagsJSON, &createdAt, &updatedAt, &archivePath,
 	)
 	if err != nil {
 		return nil, err
@@ -384,12 +431,13 @@ func (r *WorkflowCheckpointRepository) scanRow(row *sql.Row) (*workflow.Workflow
 	return buildWorkflowCheckpoint(
 		id, executionID, skillID, skillName, input, inputHash,
 		completedBatch, totalBatches, phaseResultsJSON, phaseOutputsJSON,
-		status, inputTokens, outputTokens, machineID, createdAt, updatedAt,
+		status, inputTokens, outputTokens, machineID, pinned, tagsJSON, createdAt, updatedAt, archivePath, rehydrate,
 	)
 }
 
-// scanRows scans rows into a workflow checkpoint.
-func (r *WorkflowCheckpointRepository) scanRows(rows *sql.Rows) (*workflow.WorkflowCheckpoint, error) {
+// scanRows scans rows into a workflow checkpoint, reading archived phase
+// outputs back if rehydrate is set.
+func (r *WorkflowCheckpointRepository) scanRows(rows *sql.Rows, rehydrate bool) (*workflow.WorkflowCheckpoint, error) {
 	var (
 		id, executionID, skillID, skillName, input, inputHash string
 		completedBatch, totalBatches                          int
@@ -397,13 +445,16 @@ func (r *WorkflowCheckpointRepository) scanRows(rows *sql.Rows) (*workflow.Workf
 		status                                                string
 		inputTokens, outputTokens                             int
 		machineID                                             sql.NullString
+		pinned                                                bool
+		tagsJSON                                              sql.NullString
 		createdAt, updatedAt                                  string
+		archivePath                                           sql.NullString
 	)
 
 	err := rows.Scan(
 		&id, &executionID, &skillID, &skillName, &input, &inputHash,
 		&completedBatch, &totalBatches, &phaseResultsJSON, &phaseOutputsJSON,
-		&status, &inputTokens, &outputTokens, &machineID, &createdAt, &updatedAt,
+		&status, &inputTokens, &outputTokens, &machineID, &pinned, &tagsJSON, &createdAt, &updatedAt, &archivePath,
 	)
 	if err != nil {
 		return nil, fmt.Errorf("failed to scan workflow checkpoint: %w", err)
@@ -412,11 +463,13 @@ func (r *WorkflowCheckpointRepository) scanRows(rows *sql.Rows) (*workflow.Workf
 	return buildWorkflowCheckpoint(
 		id, executionID, skillID, skillName, input, inputHash,
 		completedBatch, totalBatches, phaseResultsJSON, phaseOutputsJSON,
-		status, inputTokens, outputTokens, machineID, createdAt, updatedAt,
+		status, inputTokens, outputTokens, machineID, pinned, tagsJSON, createdAt, updatedAt, archivePath, rehydrate,
 	)
 }
 
-// buildWorkflowCheckpoint constructs a WorkflowCheckpoint domain entity from database fields.
+// buildWorkflowCheckpoint constructs a WorkflowCheckpoint domain entity from
+// database fields. Archived phase outputs are read back only if rehydrate is
+// set, so listings never touch the archive.
 func buildWorkflowCheckpoint(
 	id, executionID, skillID, skillName, input, inputHash string,
 	completedBatch, totalBatches int,
@@ -424,7 +477,11 @@ func buildWorkflowCheckpoint(
 	status string,
 	inputTokens, outputTokens int,
 	machineID sql.NullString,
+	pinned bool,
+	tagsJSON sql.NullString,
 	createdAtStr, updatedAtStr string,
+	archivePath sql.NullString,
+	rehydrate bool,
 ) (*workflow.WorkflowCheckpoint, error) {
 	// Parse timestamps
 	createdAt, err := time.Parse(time.RFC3339, createdAtStr)
@@ -452,6 +509,20 @@ func buildWorkflowCheckpoint(
 		}
 	}
 
+	// Bring back phase outputs moved to cold storage
+	var archiveErr error
+	if archivePath.Valid && rehydrate {
+		phaseOutputs, archiveErr = rehydrateOutputs(archivePath.String, phaseResults)
+	}
+
+	// Unmarshal tags
+	var tags map[string]string
+	if tagsJSON.Valid && tagsJSON.String != "" {
+		if err := json.Unmarshal([]byte(tagsJSON.String), &tags); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal tags: %w", err)
+		}
+	}
+
 	// Parse machine ID
 	var machine string
 	if machineID.Valid {
@@ -473,6 +544,9 @@ func buildWorkflowCheckpoint(
 		machine,
 		createdAt, updatedAt,
 	)
+	checkpoint.SetPinned(pinned)
+	checkpoint.SetTags(tags)
+	checkpoint.SetArchiveError(archiveErr)
 
 	return checkpoint, nil
 }
@@ -3,6 +3,7 @@ package storage
 import (
 	"context"
 	"database/sql"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -14,15 +15,22 @@ import (
 
 func setupWorkflowCheckpointTestDB(t *testing.T) *sql.DB {
 	t.Helper()
+	return openWorkflowCheckpointTestDB(t, ":memory:")
+}
+
+// openWorkflowCheckpointTestDB opens the database at dsn, creating the
+// checkpoint table unless it already exists.
+func openWorkflowCheckpointTestDB(t *testing.T, dsn string) *sql.DB {
+	t.Helper()
 
-	db, err := sql.Open("sqlite3", ":memory:")
+	db, err := sql.Open("sqlite3", dsn)
 	if err != nil {
-		t.Fatalf("failed to open in-memory database: %v", err)
+		t.Fatalf("failed to open database: %v", err)
 	}
 
 	// Create the required table
 	_, err = db.Exec(`
-		CREATE TABLE workflow_checkpoints (
+		CREATE TABLE IF NOT EXISTS workflow_checkpoints (
 			id TEXT PRIMARY KEY,
 			execution_id TEXT NOT NULL,
 			skill_id TEXT NOT NULL,
@@ -37,12 +45,15 @@ func setupWorkflowCheckpointTestDB(t *testing.T) *sql.DB {
 			input_tokens INTEGER DEFAULT 0,
 			output_tokens INTEGER DEFAULT 0,
 			machine_id TEXT,
+			pinned BOOLEAN NOT NULL DEFAULT 0,
+			tags TEXT,
 			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			archive_path TEXT
 		);
-		CREATE INDEX idx_wf_checkpoint_skill_input ON workflow_checkpoints(skill_id, input_hash);
-		CREATE INDEX idx_wf_checkpoint_status ON workflow_checkpoints(status);
-		CREATE INDEX idx_wf_checkpoint_machine ON workflow_checkpoints(machine_id);
+		CREATE INDEX IF NOT EXISTS idx_wf_checkpoint_skill_input ON workflow_checkpoints(skill_id, input_hash);
+		CREATE INDEX IF NOT EXISTS idx_wf_checkpoint_status ON workflow_checkpoints(status);
+		CREATE INDEX IF NOT EXISTS idx_wf_checkpoint_machine ON workflow_checkpoints(machine_id);
 	`)
 	if err != nil {
 		t.Fatalf("failed to create table: %v", err)
@@ -309,6 +320,7 @@ func TestWorkflowCheckpointRepository_Update(t *testing.T) {
 	cp.AddPhaseResult("phase-1", &workflow.PhaseResultData{PhaseID: "phase-1", Status: "completed"})
 	cp.AddPhaseOutput("phase-1", "output1")
 	cp.UpdateTokens(200, 100)
+	cp.SetMachineID("machine-2") // resumed on another machine
 
 	if err := repo.Update(ctx, cp); err != nil {
 		t.Fatalf("failed to update: %v", err)
@@ -322,6 +334,9 @@ func TestWorkflowCheckpointRepository_Update(t *testing.T) {
 	if got.InputTokens() != 200 {
 		t.Errorf("expected InputTokens 200, got %d", got.InputTokens())
 	}
+	if got.MachineID() != "machine-2" {
+		t.Errorf("expected MachineID machine-2, got %q", got.MachineID())
+	}
 }
 
 func TestWorkflowCheckpointRepository_Update_NotFound(t *testing.T) {
@@ -562,6 +577,82 @@ func TestWorkflowCheckpointRepository_Cleanup(t *testing.T) {
 	}
 }
 
+func TestWorkflowCheckpointRepository_PinnedSurvivesCleanup(t *testing.T) {
+	db := setupWorkflowCheckpointTestDB(t)
+	defer db.Close()
+
+	repo := NewWorkflowCheckpointRepository(db)
+	ctx := context.Background()
+
+	oldTime := time.Now().Add(-48 * time.Hour).Format(time.RFC3339)
+	for _, id := range []string{"cp-golden", "cp-stale"} {
+		_, _ = db.Exec(`
+			INSERT INTO workflow_checkpoints
+			(id, execution_id, skill_id, skill_name, input, input_hash, total_batches, status, created_at, updated_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		`, id, "exec-"+id, "skill-1", "Skill", "input", "hash", 3, "completed", oldTime, oldTime)
+	}
+
+	if err := repo.SetPinned(ctx, "cp-golden", true); err != nil {
+		t.Fatalf("SetPinned() error = %v", err)
+	}
+	if err := repo.SetPinned(ctx, "cp-missing", true); err == nil {
+		t.Error("SetPinned() of a missing checkpoint should fail")
+	}
+
+	count, err := repo.Cleanup(ctx, 24*time.Hour)
+	if err != nil {
+		t.Fatalf("failed to cleanup: %v", err)
+	}
+	if count != 1 {
+		t.Errorf("expected 1 cleaned up, got %d", count)
+	}
+	if n, _ := repo.DeleteByExecutionID(ctx, "exec-cp-golden"); n != 0 {
+		t.Errorf("DeleteByExecutionID() deleted %d pinned checkpoints", n)
+	}
+
+	golden, err := repo.Get(ctx, "cp-golden")
+	if err != nil {
+		t.Fatalf("pinned checkpoint was removed: %v", err)
+	}
+	if !golden.Pinned() {
+		t.Error("Pinned() = false after SetPinned(true)")
+	}
+}
+
+func TestWorkflowCheckpointRepository_Tags(t *testing.T) {
+	db := setupWorkflowCheckpointTestDB(t)
+	defer db.Close()
+
+	repo := NewWorkflowCheckpointRepository(db)
+	ctx := context.Background()
+
+	tagged := createTestCheckpoint(t, "cp-tagged")
+	tagged.SetTags(map[string]string{"team": "search", "ticket": "JIRA-123"})
+	untagged := createTestCheckpoint(t, "cp-untagged")
+	for _, cp := range []*workflow.WorkflowCheckpoint{tagged, untagged} {
+		if err := repo.Create(ctx, cp); err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+	}
+
+	got, err := repo.Get(ctx, "cp-tagged")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if tags := got.Tags(); len(tags) != 2 || tags["team"] != "search" || tags["ticket"] != "JIRA-123" {
+		t.Errorf("Tags() = %v, want team=search ticket=JIRA-123", tags)
+	}
+
+	got, err = repo.Get(ctx, "cp-untagged")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if got.Tags() != nil {
+		t.Errorf("Tags() = %v, want none", got.Tags())
+	}
+}
+
 func TestWorkflowCheckpointRepository_EmptyDatabase(t *testing.T) {
 	db := setupWorkflowCheckpointTestDB(t)
 	defer db.Close()
@@ -701,3 +792,51 @@ func TestWorkflowCheckpointRepository_RoundTrip(t *testing.T) {
 		t.Errorf("_input output mismatch: got %q", outputs["_input"])
 	}
 }
+
+func TestWorkflowCheckpointRepository_SurvivesReopen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "skillrunner.db")
+	ctx := context.Background()
+
+	// A run checkpoints its first batch, then the process exits
+	db := openWorkflowCheckpointTestDB(t, path)
+	cp := createTestCheckpoint(t, "cp-restart")
+	cp.AddPhaseResult("phase-1", &workflow.PhaseResultData{
+		PhaseID:      "phase-1",
+		Status:       "completed",
+		Output:       "first batch output",
+		InputTokens:  120,
+		OutputTokens: 60,
+	})
+	cp.AddPhaseOutput("phase-1", "first batch output")
+	cp.UpdateTokens(120, 60)
+	_ = cp.UpdateBatch(0)
+	if err := NewWorkflowCheckpointRepository(db).Create(ctx, cp); err != nil {
+		t.Fatalf("failed to create: %v", err)
+	}
+	if err := db.Close(); err != nil {
+		t.Fatalf("failed to close: %v", err)
+	}
+
+	// The next process finds it to resume from
+	db = openWorkflowCheckpointTestDB(t, path)
+	defer db.Close()
+	got, err := NewWorkflowCheckpointRepository(db).GetLatestInProgress(ctx, "skill-1", workflow.HashInput("test input"))
+	if err != nil {
+		t.Fatalf("failed to get latest in progress: %v", err)
+	}
+	if got == nil || got.ID() != "cp-restart" {
+		t.Fatalf("expected checkpoint cp-restart after reopening, got %v", got)
+	}
+	if got.CompletedBatch() != 0 || got.MachineID() != "machine-1" {
+		t.Errorf("completedBatch = %d, machineID = %q", got.CompletedBatch(), got.MachineID())
+	}
+	if got.InputTokens() != 120 || got.OutputTokens() != 60 {
+		t.Errorf("tokens = %d/%d, want 120/60", got.InputTokens(), got.OutputTokens())
+	}
+	if out, ok := got.PhaseOutputs()["phase-1"]; !ok || out != "first batch output" {
+		t.Errorf("phase-1 output = %q", out)
+	}
+	if r := got.PhaseResults()["phase-1"]; r == nil || r.Status != "completed" {
+		t.Errorf("phase-1 result = %+v", r)
+	}
+}
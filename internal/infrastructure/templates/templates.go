@@ -0,0 +1,150 @@
+// Package templates parses and renders prompt templates: Go templates with
+// sprig's functions, partials shared by the phases of a skill, and errors
+// for missing keys instead of "<no value>".
+package templates
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"text/template"
+	"text/template/parse"
+
+	"github.com/Masterminds/sprig/v3"
+)
+
+// Name is the name of the template parsed from a prompt; partials take
+// their own names.
+const Name = "prompt"
+
+// unsafeFuncs are sprig functions left out of prompt templates: reading
+// the environment, which could put credentials in prompts sent to cloud
+// providers, and DNS lookups.
+var unsafeFuncs = []string{"env", "expandenv", "getHostByName"}
+
+// Funcs returns the functions of prompt templates: sprig's, get, and
+// include, which renders a partial of t to a string so that it can be
+// piped, e.g. {{include "rules" . | indent 2}}. get returns "" until the
+// template is rendered.
+func Funcs(t *template.Template) template.FuncMap {
+	funcs := sprig.TxtFuncMap()
+	for _, name := range unsafeFuncs {
+		delete(funcs, name)
+	}
+	funcs["get"] = func(string) string { return "" }
+	funcs["include"] = func(name string, data any) (string, error) {
+		var buf strings.Builder
+		if err := t.ExecuteTemplate(&buf, name, data); err != nil {
+			return "", err
+		}
+		return buf.String(), nil
+	}
+	return funcs
+}
+
+// Parse parses text as a prompt template, with partials as named templates.
+// Rendering it fails on a missing map key.
+func Parse(text string, partials map[string]string) (*template.Template, error) {
+	t := template.New(Name).Option("missingkey=error")
+	t.Funcs(Funcs(t))
+	for _, name := range slices.Sorted(maps.Keys(partials)) {
+		if _, err := t.New(name).Parse(partials[name]); err != nil {
+			return nil, fmt.Errorf("partial %s: %w", name, err)
+		}
+	}
+	return t.Parse(text)
+}
+
+// Render parses text with partials and renders it with data. get looks up
+// a value by key for {{get "key"}}, which is "" rather than an error when
+// the key is missing.
+func Render(text string, partials map[string]string, data any, get func(key string) string) (string, error) {
+	t, err := Parse(text, partials)
+	if err != nil {
+		return "", err
+	}
+	if get != nil {
+		t.Funcs(template.FuncMap{"get": get})
+	}
+
+	var buf strings.Builder
+	if err := t.Execute(&buf, data); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// Fields parses text and returns the fields of the data it refers to, as
+// dotted paths such as "_input" or "phases.review", in order of first use.
+// Fields of the dot inside range and with blocks, where it is another
+// value, and inside partials, which may be given any data, are left out.
+func Fields(text string) ([]string, error) {
+	t, err := Parse(text, nil)
+	if err != nil {
+		return nil, err
+	}
+	var fields []string
+	add := func(ident []string) {
+		if f := strings.Join(ident, "."); f != "" && !slices.Contains(fields, f) {
+			fields = append(fields, f)
+		}
+	}
+	if t.Tree != nil {
+		walkFields(t.Tree.Root, true, add)
+	}
+	return fields, nil
+}
+
+// walkFields calls add with the identifiers of the fields of the template's
+// data that node refers to. Outside the root, where the dot is another
+// value, only fields of $ are the template's data.
+func walkFields(node parse.Node, root bool, add func(ident []string)) {
+	walk := func(n parse.Node) { walkFields(n, root, add) }
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		for _, child := range n.Nodes {
+			walk(child)
+		}
+	case *parse.ActionNode:
+		walk(n.Pipe)
+	case *parse.PipeNode:
+		if n == nil {
+			return
+		}
+		for _, cmd := range n.Cmds {
+			walk(cmd)
+		}
+	case *parse.CommandNode:
+		for _, arg := range n.Args {
+			walk(arg)
+		}
+	case *parse.ChainNode:
+		walk(n.Node)
+	case *parse.FieldNode:
+		if root {
+			add(n.Ident)
+		}
+	case *parse.VariableNode:
+		if len(n.Ident) > 1 && n.Ident[0] == "$" {
+			add(n.Ident[1:])
+		}
+	case *parse.IfNode:
+		walk(n.Pipe)
+		walk(n.List)
+		walk(n.ElseList)
+	case *parse.RangeNode:
+		walk(n.Pipe)
+		walkFields(n.List, false, add)
+		walk(n.ElseList)
+	case *parse.WithNode:
+		walk(n.Pipe)
+		walkFields(n.List, false, add)
+		walk(n.ElseList)
+	case *parse.TemplateNode:
+		walk(n.Pipe)
+	}
+}
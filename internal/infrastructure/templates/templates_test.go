@@ -0,0 +1,53 @@
+package templates
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestRender(t *testing.T) {
+	data := map[string]any{"_input": "  go  ", "phases": map[string]string{"plan": "steps"}}
+	partials := map[string]string{"rules": "Use {{._input | trim}}."}
+
+	got, err := Render(`{{._input | trim | upper}}: {{include "rules" . | indent 2}} {{template "rules" .}} {{.phases.plan}}`, partials, data, nil)
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	if want := "GO:   Use go. Use go. steps"; got != want {
+		t.Errorf("Render() = %q, want %q", got, want)
+	}
+
+	for _, text := range []string{"{{.missing}}", "{{.phases.review}}", `{{env "HOME"}}`, `{{template "nosuch" .}}`} {
+		if _, err := Render(text, partials, data, nil); err == nil {
+			t.Errorf("Render(%q) succeeded", text)
+		}
+	}
+
+	get := func(key string) string { return map[string]string{"plan": "steps"}[key] }
+	if got, err := Render(`[{{get "plan"}}][{{get "missing"}}]`, nil, data, get); err != nil || got != "[steps][]" {
+		t.Errorf("Render() with get = %q, %v; want [steps][]", got, err)
+	}
+	if _, err := Parse("{{.x}}", map[string]string{"bad": "{{"}); err == nil || !strings.Contains(err.Error(), "partial bad") {
+		t.Errorf("Parse() error = %v, want the partial's", err)
+	}
+}
+
+func TestFields(t *testing.T) {
+	text := `{{._input}} {{if .review}}{{.phases.review | upper}}{{end}}
+{{range $i, $f := .files}}{{.name}} {{$.phases.plan}}{{end}}
+{{with .summary}}{{.title}}{{else}}{{.draft}}{{end}} {{template "rules" .rules}} {{get "optional"}}`
+
+	got, err := Fields(text)
+	if err != nil {
+		t.Fatalf("Fields() error = %v", err)
+	}
+	want := []string{"_input", "review", "phases.review", "files", "phases.plan", "summary", "draft", "rules"}
+	if !slices.Equal(got, want) {
+		t.Errorf("Fields() = %q, want %q", got, want)
+	}
+
+	if _, err := Fields("{{.x"); err == nil {
+		t.Error("Fields() of an invalid template succeeded")
+	}
+}
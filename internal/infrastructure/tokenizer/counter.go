@@ -0,0 +1,223 @@
+package tokenizer
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkoukk/tiktoken-go"
+
+	"github.com/jbctechsolutions/skillrunner/internal/application/ports"
+	"github.com/jbctechsolutions/skillrunner/internal/domain/provider"
+)
+
+// Per-message token overhead of chat formats: role and separator tokens,
+// plus the tokens that prime the assistant reply.
+const (
+	tokensPerMessage = 4
+	tokensPerReply   = 3
+)
+
+// defaultEncoding is used for models tiktoken does not know.
+const defaultEncoding = "cl100k_base"
+
+// encodingLoadTimeout bounds how long counting waits for an encoding to
+// load. A slower load goes on in the background, and the heuristic is used
+// until it is done.
+const encodingLoadTimeout = 10 * time.Second
+
+// tiktokenProviders are the providers whose models are counted with
+// tiktoken: OpenAI's own models and the OpenAI-compatible hosts.
+var tiktokenProviders = map[string]bool{
+	"openai":    true,
+	"groq":      true,
+	"together":  true,
+	"fireworks": true,
+}
+
+// MessageCounter counts the tokens of a completion request by summing the
+// token estimates of its text plus a fixed overhead per message.
+type MessageCounter struct {
+	estimate func(ctx context.Context, model, text string) int
+}
+
+// Ensure MessageCounter implements ports.TokenCounterPort.
+var _ ports.TokenCounterPort = (*MessageCounter)(nil)
+
+// NewMessageCounter creates a counter that estimates text with estimator.
+func NewMessageCounter(estimator provider.TokenEstimator) *MessageCounter {
+	return &MessageCounter{
+		estimate: func(_ context.Context, _, text string) int { return estimator.CountTokens(text) },
+	}
+}
+
+// NewHeuristicCounter creates a counter using ~4 characters per token. It is
+// used for local models, whose tokenizers vary by model family.
+func NewHeuristicCounter() *MessageCounter {
+	return NewMessageCounter(NewSimpleEstimator())
+}
+
+// NewTiktokenCounter creates a counter using the tiktoken encoding of each
+// model. Encodings are loaded on first use; when one cannot be loaded
+// (tiktoken downloads them once), or is still loading, the heuristic is used
+// instead.
+func NewTiktokenCounter() *MessageCounter {
+	encodings := newEncodingCache(tiktoken.GetEncoding, encodingLoadTimeout)
+	heuristic := NewSimpleEstimator()
+
+	return &MessageCounter{
+		estimate: func(ctx context.Context, model, text string) int {
+			encoding := encodings.forModel(ctx, model)
+			if encoding == nil {
+				return heuristic.CountTokens(text)
+			}
+			return len(encoding.Encode(text, nil, nil))
+		},
+	}
+}
+
+// CountTokens returns the estimated input tokens of req.
+func (c *MessageCounter) CountTokens(ctx context.Context, req ports.CompletionRequest) (int, error) {
+	total := tokensPerReply
+	if req.SystemPrompt != "" {
+		total += tokensPerMessage + c.estimate(ctx, req.ModelID, req.SystemPrompt)
+	}
+	for _, msg := range req.Messages {
+		total += tokensPerMessage + c.estimate(ctx, req.ModelID, msg.Content)
+		for _, call := range msg.ToolCalls {
+			total += c.estimate(ctx, req.ModelID, call.Name) + c.estimate(ctx, req.ModelID, string(call.Arguments))
+		}
+	}
+	for _, tool := range req.Tools {
+		total += c.estimate(ctx, req.ModelID, tool.Name+" "+tool.Description+" "+string(tool.InputSchema))
+	}
+	return total, nil
+}
+
+// encodingCache loads tiktoken encodings once per encoding name. Loads run
+// outside the cache's lock, so a slow download holds up only the counts
+// waiting for that encoding, and each of those for at most the timeout.
+type encodingCache struct {
+	load    func(name string) (*tiktoken.Tiktoken, error)
+	timeout time.Duration
+
+	mu    sync.Mutex
+	loads map[string]*encodingLoad
+}
+
+// encodingLoad is the load of one encoding. Once done is closed, encoding
+// holds the encoding, or nil if it failed to load.
+type encodingLoad struct {
+	done     chan struct{}
+	encoding *tiktoken.Tiktoken
+}
+
+// newEncodingCache creates a cache that loads encodings with load and waits
+// at most timeout for one.
+func newEncodingCache(load func(name string) (*tiktoken.Tiktoken, error), timeout time.Duration) *encodingCache {
+	return &encodingCache{load: load, timeout: timeout, loads: make(map[string]*encodingLoad)}
+}
+
+// forModel returns the encoding of model, or nil if it cannot be loaded or
+// does not load before ctx is done or the timeout passes. The first call for
+// an encoding starts its load; later ones wait for the same load.
+func (c *encodingCache) forModel(ctx context.Context, model string) *tiktoken.Tiktoken {
+	name := encodingName(model)
+
+	c.mu.Lock()
+	load, ok := c.loads[name]
+	if !ok {
+		load = &encodingLoad{done: make(chan struct{})}
+		c.loads[name] = load
+		go func() {
+			defer close(load.done)
+			if encoding, err := c.load(name); err == nil {
+				load.encoding = encoding
+			}
+		}()
+	}
+	c.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+	select {
+	case <-load.done:
+		return load.encoding
+	case <-ctx.Done():
+		return nil
+	}
+}
+
+// encodingName returns the tiktoken encoding of model, matching dated model
+// versions by prefix.
+func encodingName(model string) string {
+	if name, ok := tiktoken.MODEL_TO_ENCODING[model]; ok {
+		return name
+	}
+	for prefix, name := range tiktoken.MODEL_PREFIX_TO_ENCODING {
+		if strings.HasPrefix(model, prefix) {
+			return name
+		}
+	}
+	return defaultEncoding
+}
+
+// FallbackCounter counts with a primary counter, such as a provider's
+// counting API, and falls back to a local estimate when it fails.
+type FallbackCounter struct {
+	primary  ports.TokenCounterPort
+	fallback ports.TokenCounterPort
+}
+
+// Ensure FallbackCounter implements ports.TokenCounterPort.
+var _ ports.TokenCounterPort = (*FallbackCounter)(nil)
+
+// NewFallbackCounter creates a counter that uses fallback when primary fails.
+func NewFallbackCounter(primary, fallback ports.TokenCounterPort) *FallbackCounter {
+	return &FallbackCounter{primary: primary, fallback: fallback}
+}
+
+// CountTokens returns the primary count, or the fallback count on error.
+func (c *FallbackCounter) CountTokens(ctx context.Context, req ports.CompletionRequest) (int, error) {
+	count, err := c.primary.CountTokens(ctx, req)
+	if err == nil {
+		return count, nil
+	}
+	if ctx.Err() != nil {
+		return 0, ctx.Err()
+	}
+	return c.fallback.CountTokens(ctx, req)
+}
+
+// Counters selects the token counter for each provider: the provider's own
+// counting API when it has one (Anthropic), tiktoken for OpenAI-compatible
+// providers and the heuristic for the rest (Ollama and other local models).
+type Counters struct {
+	tiktoken  *MessageCounter
+	heuristic *MessageCounter
+}
+
+// NewCounters creates a per-provider counter selector.
+func NewCounters() *Counters {
+	return &Counters{
+		tiktoken:  NewTiktokenCounter(),
+		heuristic: NewHeuristicCounter(),
+	}
+}
+
+// ForProvider returns the token counter for p.
+func (c *Counters) ForProvider(p ports.ProviderPort) ports.TokenCounterPort {
+	if p == nil {
+		return c.heuristic
+	}
+	// Found through the provider's decorators, so the counted prompt is
+	// redacted like the one completions send
+	if remote, ok := ports.As[ports.TokenCounterPort](p); ok {
+		return NewFallbackCounter(remote, c.heuristic)
+	}
+	if tiktokenProviders[p.Info().Name] {
+		return c.tiktoken
+	}
+	return c.heuristic
+}
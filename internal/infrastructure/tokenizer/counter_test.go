@@ -0,0 +1,136 @@
+package tokenizer
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/pkoukk/tiktoken-go"
+
+	"github.com/jbctechsolutions/skillrunner/internal/application/ports"
+)
+
+// fakeProvider is a minimal ports.ProviderPort.
+type fakeProvider struct {
+	ports.ProviderPort
+	name string
+}
+
+func (p *fakeProvider) Info() ports.ProviderInfo { return ports.ProviderInfo{Name: p.name} }
+
+// countingProvider has a token counting API.
+type countingProvider struct {
+	fakeProvider
+	count int
+	err   error
+}
+
+func (p *countingProvider) CountTokens(context.Context, ports.CompletionRequest) (int, error) {
+	return p.count, p.err
+}
+
+func TestMessageCounter_CountTokens(t *testing.T) {
+	counter := NewHeuristicCounter()
+	req := ports.CompletionRequest{
+		SystemPrompt: "Be terse.",                                        // 3 tokens
+		Messages:     []ports.Message{{Role: "user", Content: "Hello!"}}, // 2 tokens
+	}
+
+	got, err := counter.CountTokens(context.Background(), req)
+	if err != nil {
+		t.Fatalf("CountTokens() error = %v", err)
+	}
+	if want := tokensPerReply + 2*tokensPerMessage + 3 + 2; got != want {
+		t.Errorf("CountTokens() = %d, want %d", got, want)
+	}
+}
+
+func TestTiktokenCounter_CountTokens(t *testing.T) {
+	// Falls back to the heuristic when the encoding cannot be downloaded
+	got, err := NewTiktokenCounter().CountTokens(context.Background(), ports.CompletionRequest{
+		ModelID:  "gpt-4o-2024-08-06",
+		Messages: []ports.Message{{Role: "user", Content: "The quick brown fox jumps over the lazy dog."}},
+	})
+	if err != nil || got < 10 || got > 25 {
+		t.Errorf("CountTokens() = %d, %v", got, err)
+	}
+}
+
+func TestEncodingCache_ForModel(t *testing.T) {
+	release := make(chan struct{})
+	var loads atomic.Int32
+	cache := newEncodingCache(func(name string) (*tiktoken.Tiktoken, error) {
+		loads.Add(1)
+		if name == "o200k_base" {
+			<-release
+		}
+		return nil, errors.New("offline")
+	}, 20*time.Millisecond)
+	defer close(release)
+
+	// A slow load gives up after the timeout without holding up other
+	// encodings
+	start := time.Now()
+	if got := cache.forModel(context.Background(), "gpt-4o"); got != nil {
+		t.Errorf("forModel() of a slow encoding = %v, want nil", got)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("forModel() waited %v for a slow encoding", elapsed)
+	}
+	if got := cache.forModel(context.Background(), "gpt-4"); got != nil {
+		t.Errorf("forModel() of a failed encoding = %v, want nil", got)
+	}
+
+	// A canceled context stops the wait, and the load in progress is reused
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if got := cache.forModel(ctx, "gpt-4o-2024-08-06"); got != nil {
+		t.Errorf("forModel() with a canceled context = %v, want nil", got)
+	}
+	_ = cache.forModel(context.Background(), "gpt-4")
+	if got := loads.Load(); got != 2 {
+		t.Errorf("encodings loaded %d times, want once each", got)
+	}
+}
+
+func TestEncodingName(t *testing.T) {
+	for model, want := range map[string]string{
+		"gpt-4o":            "o200k_base",
+		"gpt-4o-2024-08-06": "o200k_base",
+		"gpt-4":             "cl100k_base",
+		"llama-3.1-70b":     defaultEncoding,
+	} {
+		if got := encodingName(model); got != want {
+			t.Errorf("encodingName(%q) = %q, want %q", model, got, want)
+		}
+	}
+}
+
+func TestCounters_ForProvider(t *testing.T) {
+	counters := NewCounters()
+	ctx := context.Background()
+	req := ports.CompletionRequest{Messages: []ports.Message{{Role: "user", Content: "Hello"}}}
+
+	if counters.ForProvider(&fakeProvider{name: "groq"}) != ports.TokenCounterPort(counters.tiktoken) {
+		t.Error("groq should use tiktoken")
+	}
+	if counters.ForProvider(&fakeProvider{name: "ollama"}) != ports.TokenCounterPort(counters.heuristic) {
+		t.Error("ollama should use the heuristic")
+	}
+	if counters.ForProvider(nil) != ports.TokenCounterPort(counters.heuristic) {
+		t.Error("an unknown provider should use the heuristic")
+	}
+
+	remote := &countingProvider{fakeProvider: fakeProvider{name: "anthropic"}, count: 123}
+	if got, _ := counters.ForProvider(remote).CountTokens(ctx, req); got != 123 {
+		t.Errorf("counting API count = %d, want 123", got)
+	}
+
+	remote.err = errors.New("offline")
+	want, _ := counters.heuristic.CountTokens(ctx, req)
+	if got, err := counters.ForProvider(remote).CountTokens(ctx, req); err != nil || got != want {
+		t.Errorf("fallback count = %d, %v, want %d", got, err, want)
+	}
+}
@@ -7,6 +7,8 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"net/url"
+	"strings"
 	"sync"
 
 	"go.opentelemetry.io/otel"
@@ -41,13 +43,14 @@ const (
 
 // Config holds tracing configuration.
 type Config struct {
-	Enabled      bool         // Whether tracing is enabled
-	ExporterType ExporterType // Type of exporter to use
-	OTLPEndpoint string       // OTLP collector endpoint (for OTLP exporter)
-	ServiceName  string       // Service name for traces
-	Environment  string       // Deployment environment (development, production)
-	SampleRate   float64      // Sampling rate (0.0 to 1.0)
-	Output       io.Writer    // Output for stdout exporter (defaults to os.Stdout)
+	Enabled      bool              // Whether tracing is enabled
+	ExporterType ExporterType      // Type of exporter to use
+	OTLPEndpoint string            // OTLP collector host:port or URL; empty uses the OTEL_EXPORTER_OTLP_* environment
+	OTLPHeaders  map[string]string // Headers sent with each OTLP export, e.g. for authentication
+	ServiceName  string            // Service name for traces
+	Environment  string            // Deployment environment (development, production)
+	SampleRate   float64           // Sampling rate (0.0 to 1.0)
+	Output       io.Writer         // Output for stdout exporter (defaults to os.Stdout)
 }
 
 // DefaultConfig returns sensible default tracing configuration.
@@ -170,11 +173,22 @@ func createExporter(ctx context.Context, cfg Config) (sdktrace.SpanExporter, err
 		return stdouttrace.New(opts...)
 
 	case ExporterOTLP:
-		opts := []otlptracehttp.Option{
-			otlptracehttp.WithInsecure(),
+		var opts []otlptracehttp.Option
+		switch {
+		case cfg.OTLPEndpoint == "":
+			// The exporter reads the endpoint and TLS settings from the
+			// OTEL_EXPORTER_OTLP_* environment
+		case strings.Contains(cfg.OTLPEndpoint, "://"):
+			endpoint, err := otlpTracesURL(cfg.OTLPEndpoint)
+			if err != nil {
+				return nil, err
+			}
+			opts = append(opts, otlptracehttp.WithEndpointURL(endpoint))
+		default:
+			opts = append(opts, otlptracehttp.WithEndpoint(cfg.OTLPEndpoint), otlptracehttp.WithInsecure())
 		}
-		if cfg.OTLPEndpoint != "" {
-			opts = append(opts, otlptracehttp.WithEndpoint(cfg.OTLPEndpoint))
+		if len(cfg.OTLPHeaders) > 0 {
+			opts = append(opts, otlptracehttp.WithHeaders(cfg.OTLPHeaders))
 		}
 		return otlptracehttp.New(ctx, opts...)
 
@@ -183,6 +197,19 @@ func createExporter(ctx context.Context, cfg Config) (sdktrace.SpanExporter, err
 	}
 }
 
+// otlpTracesURL returns the traces URL of an OTLP endpoint URL, adding the
+// default /v1/traces path to a URL without one. The scheme selects TLS.
+func otlpTracesURL(endpoint string) (string, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil || u.Host == "" {
+		return "", fmt.Errorf("invalid OTLP endpoint %q", endpoint)
+	}
+	if u.Path == "" || u.Path == "/" {
+		u.Path = "/v1/traces"
+	}
+	return u.String(), nil
+}
+
 // Shutdown gracefully shuts down the tracer provider.
 func (t *Tracer) Shutdown(ctx context.Context) error {
 	if t.provider != nil {
@@ -358,6 +385,11 @@ func (ps *ProviderSpan) SetResponse(outputTokens int, finishReason string) {
 	)
 }
 
+// SetCost sets the cost of the request.
+func (ps *ProviderSpan) SetCost(cost float64) {
+	ps.span.SetAttributes(attribute.Float64("provider.cost_usd", cost))
+}
+
 // End ends the provider span with success status.
 func (ps *ProviderSpan) End() {
 	ps.span.SetStatus(codes.Ok, "provider request completed")
@@ -371,6 +403,47 @@ func (ps *ProviderSpan) EndWithError(err error) {
 	ps.span.End()
 }
 
+// RoutingSpan represents a model selection span.
+type RoutingSpan struct {
+	span trace.Span
+	ctx  context.Context
+}
+
+// StartRoutingSpan starts a span for selecting the model of a routing
+// profile; operation names the span, e.g. router.select_model.
+func (t *Tracer) StartRoutingSpan(ctx context.Context, operation, profile string) (context.Context, *RoutingSpan) {
+	ctx, span := t.tracer.Start(ctx, operation,
+		trace.WithSpanKind(trace.SpanKindInternal),
+		trace.WithAttributes(
+			attribute.String("routing.profile", profile),
+		),
+	)
+
+	return ctx, &RoutingSpan{span: span, ctx: ctx}
+}
+
+// SetSelection sets the selected provider and model.
+func (rs *RoutingSpan) SetSelection(provider, model string, fallback bool) {
+	rs.span.SetAttributes(
+		attribute.String("routing.provider", provider),
+		attribute.String("routing.model", model),
+		attribute.Bool("routing.fallback", fallback),
+	)
+}
+
+// End ends the routing span with success status.
+func (rs *RoutingSpan) End() {
+	rs.span.SetStatus(codes.Ok, "model selected")
+	rs.span.End()
+}
+
+// EndWithError ends the routing span with error status.
+func (rs *RoutingSpan) EndWithError(err error) {
+	rs.span.RecordError(err)
+	rs.span.SetStatus(codes.Error, err.Error())
+	rs.span.End()
+}
+
 // AddEvent adds an event to the current span.
 func AddEvent(ctx context.Context, name string, attrs ...attribute.KeyValue) {
 	span := trace.SpanFromContext(ctx)
@@ -199,6 +199,7 @@ func TestProviderSpan(t *testing.T) {
 
 	ps.SetRequestTokens(500)
 	ps.SetResponse(200, "stop")
+	ps.SetCost(0.0045)
 	ps.End()
 
 	tracer.Shutdown(ctx)
@@ -208,6 +209,56 @@ func TestProviderSpan(t *testing.T) {
 	}
 }
 
+func TestRoutingSpan(t *testing.T) {
+	ctx := context.Background()
+	buf := &bytes.Buffer{}
+
+	tracer, err := New(ctx, Config{
+		Enabled:      true,
+		ExporterType: ExporterStdout,
+		ServiceName:  "test-service",
+		SampleRate:   1.0,
+		Output:       buf,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	_, rs := tracer.StartRoutingSpan(ctx, "router.select_model", "balanced")
+	rs.SetSelection("ollama", "llama3:8b", true)
+	rs.End()
+
+	_, rs = tracer.StartRoutingSpan(ctx, "router.select_model", "premium")
+	rs.EndWithError(errors.New("no model available"))
+
+	tracer.Shutdown(ctx)
+
+	for _, want := range []string{"router.select_model", "routing.model", "llama3:8b", "no model available"} {
+		if !bytes.Contains(buf.Bytes(), []byte(want)) {
+			t.Errorf("expected trace output to contain %q", want)
+		}
+	}
+}
+
+func TestOTLPTracesURL(t *testing.T) {
+	tests := []struct {
+		endpoint string
+		want     string
+		wantErr  bool
+	}{
+		{"http://localhost:4318", "http://localhost:4318/v1/traces", false},
+		{"https://otel.example.com/", "https://otel.example.com/v1/traces", false},
+		{"https://otel.example.com/otlp/v1/traces", "https://otel.example.com/otlp/v1/traces", false},
+		{"http://", "", true},
+	}
+	for _, tt := range tests {
+		got, err := otlpTracesURL(tt.endpoint)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("otlpTracesURL(%q) = %q, %v; want %q", tt.endpoint, got, err, tt.want)
+		}
+	}
+}
+
 func TestDefault(t *testing.T) {
 	// Reset global for test
 	global = nil
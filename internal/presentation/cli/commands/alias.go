@@ -0,0 +1,83 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"slices"
+
+	"github.com/spf13/cobra"
+
+	"github.com/jbctechsolutions/skillrunner/internal/infrastructure/config"
+)
+
+// projectAliasAnnotation marks commands registered from project aliases.
+const projectAliasAnnotation = "project-alias"
+
+// addProjectAliases registers the aliases of the .skillrunner.yaml project
+// containing the working directory as subcommands of root. Aliases named
+// like a built-in command are ignored. A broken project file is ignored
+// here; initializeApp reports it.
+func addProjectAliases(root *cobra.Command) {
+	wd, err := os.Getwd()
+	if err != nil {
+		return
+	}
+	path := config.FindProjectConfig(wd)
+	if path == "" {
+		return
+	}
+	project, err := config.LoadProjectConfig(path)
+	if err != nil {
+		return
+	}
+
+	addAliasCommands(root, project)
+}
+
+// addAliasCommands registers project's aliases as subcommands of root.
+func addAliasCommands(root *cobra.Command, project *config.ProjectConfig) {
+	names := make([]string, 0, len(project.Aliases))
+	for name := range project.Aliases {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	for _, name := range names {
+		if cmd, _, err := root.Find([]string{name}); err == nil && cmd != root {
+			continue
+		}
+		root.AddCommand(newAliasCmd(name, project))
+	}
+}
+
+// newAliasCmd creates the command for a project alias. It re-runs the root
+// command with the alias expanded and the given arguments appended, so
+// flags after the alias reach the expanded command.
+func newAliasCmd(name string, project *config.ProjectConfig) *cobra.Command {
+	return &cobra.Command{
+		Use:                name + " [args...]",
+		Short:              fmt.Sprintf("Alias for: sr %s (%s)", project.Aliases[name], config.ProjectConfigFileName),
+		Annotations:        map[string]string{projectAliasAnnotation: name},
+		DisableFlagParsing: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			expanded, err := project.AliasArgs(name)
+			if err != nil {
+				return err
+			}
+
+			root := cmd.Root()
+			if target, _, err := root.Find(expanded); err == nil && isProjectAlias(target) {
+				return fmt.Errorf("alias %q expands to alias %q; aliases must run a built-in command", name, target.Name())
+			}
+
+			root.SetArgs(append(expanded, args...))
+			return root.Execute()
+		},
+	}
+}
+
+// isProjectAlias reports whether cmd was registered from a project alias.
+func isProjectAlias(cmd *cobra.Command) bool {
+	_, ok := cmd.Annotations[projectAliasAnnotation]
+	return ok
+}
@@ -4,7 +4,6 @@ package commands
 import (
 	"context"
 	"fmt"
-	"slices"
 	"strings"
 
 	"github.com/spf13/cobra"
@@ -60,7 +59,7 @@ Examples:
 	cmd.Flags().StringVarP(&askOpts.Model, "model", "m", "",
 		"override model selection (e.g., claude-3-opus, gpt-4, llama3)")
 	cmd.Flags().StringVarP(&askOpts.Profile, "profile", "p", skill.ProfileBalanced,
-		fmt.Sprintf("routing profile: %s, %s, %s", skill.ProfileCheap, skill.ProfileBalanced, skill.ProfilePremium))
+		fmt.Sprintf("routing profile: %s, %s, %s or a custom profile", skill.ProfileCheap, skill.ProfileBalanced, skill.ProfilePremium))
 	cmd.Flags().StringVar(&askOpts.Phase, "phase", "",
 		"specific phase to execute (defaults to first phase)")
 	cmd.Flags().BoolVarP(&askOpts.Stream, "stream", "s", false, "enable streaming output")
@@ -161,7 +160,12 @@ func runAsk(cmd *cobra.Command, args []string) error {
 		if phase.RoutingProfile != "" {
 			profile = phase.RoutingProfile
 		}
-		modelSelection, err = router.SelectModel(ctx, profile)
+		if len(phase.Capabilities) > 0 {
+			// Only models declaring the phase's capabilities qualify
+			modelSelection, err = router.SelectModelForPhase(ctx, phase)
+		} else {
+			modelSelection, err = router.SelectModel(ctx, profile)
+		}
 		if err != nil {
 			return fmt.Errorf("could not select model: %w", err)
 		}
@@ -202,6 +206,9 @@ func runAsk(cmd *cobra.Command, args []string) error {
 		MaxTokens:   maxTokens,
 		Temperature: temperature,
 	}
+	if err := router.CheckContextWindow(ctx, modelSelection, req); err != nil {
+		return err
+	}
 
 	// Execute the request (with or without streaming)
 	var response *ports.CompletionResponse
@@ -346,12 +353,5 @@ func executeWithStreaming(ctx context.Context, provider ports.ProviderPort, req
 
 // validateAskProfile checks if the profile is valid.
 func validateAskProfile(profile string) error {
-	profile = strings.ToLower(strings.TrimSpace(profile))
-	validProfiles := []string{skill.ProfileCheap, skill.ProfileBalanced, skill.ProfilePremium}
-
-	if slices.Contains(validProfiles, profile) {
-		return nil
-	}
-
-	return fmt.Errorf("invalid profile %q: must be one of %s", profile, strings.Join(validProfiles, ", "))
+	return validateProfile(profile)
 }
@@ -0,0 +1,272 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/jbctechsolutions/skillrunner/internal/infrastructure/config"
+	"github.com/jbctechsolutions/skillrunner/internal/presentation/cli/output"
+)
+
+// AuthEntry describes where a provider's API key comes from.
+type AuthEntry struct {
+	Provider string `json:"provider"`
+	Source   string `json:"source"`
+	Enabled  bool   `json:"enabled"`
+}
+
+// NewAuthCmd creates the auth command.
+func NewAuthCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "auth",
+		Short: "Manage provider API keys in the OS keychain",
+		Long: `Manage provider API keys in the OS keychain (macOS Keychain, Secret
+Service on Linux, Windows Credential Manager), so that they are kept neither
+in environment variables nor in configuration files.
+
+Keys of built-in cloud providers (anthropic, openai, groq, together,
+fireworks, stability) are marked with keychain: true in config.yaml. Providers
+declared in routing.yaml read their key from the keychain when the variable
+named by api_key_env is not set.`,
+	}
+
+	cmd.AddCommand(NewAuthLoginCmd())
+	cmd.AddCommand(NewAuthLogoutCmd())
+	cmd.AddCommand(NewAuthListCmd())
+
+	return cmd
+}
+
+// NewAuthLoginCmd creates the auth login command.
+func NewAuthLoginCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "login <provider>",
+		Short: "Save a provider's API key in the OS keychain",
+		Long: `Save a provider's API key in the OS keychain.
+
+The key is read from standard input, so it can be piped:
+
+  sr auth login anthropic
+  op read op://dev/anthropic/key | sr auth login anthropic
+
+For a built-in cloud provider the provider is also enabled in config.yaml and
+any encrypted key there is removed.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			container := GetContainer()
+			if container == nil {
+				return fmt.Errorf("application not initialized")
+			}
+
+			formatter := GetFormatter()
+			name := args[0]
+			store := container.SecretStore()
+
+			routed := container.RoutingConfiguration().GetProvider(name)
+			if !slices.Contains(config.CloudProviderNames, name) && routed == nil {
+				return fmt.Errorf("unknown provider %q: use one of %s, or a provider declared in routing.yaml",
+					name, strings.Join(config.CloudProviderNames, ", "))
+			}
+
+			apiKey, err := newPrompter(formatter).promptSecret(name + " API key")
+			if err != nil {
+				return err
+			}
+			if apiKey == "" {
+				return errors.New("no API key entered")
+			}
+
+			if err := store.Set(cmd.Context(), name, apiKey); err != nil {
+				return fmt.Errorf("failed to save API key in %s: %w", store.Name(), err)
+			}
+
+			if err := updateCloudConfig(name, func(c *config.CloudConfig) {
+				c.Keychain = true
+				c.APIKeyEncrypted = ""
+				c.Enabled = true
+			}); err != nil {
+				return err
+			}
+
+			if formatter.Format() == output.FormatJSON {
+				return formatter.JSON(map[string]any{"provider": name, "keychain": store.Name()})
+			}
+			formatter.Success("Saved %s API key in %s", name, store.Name())
+			if routed != nil && routed.APIKeyEnv != "" {
+				formatter.Info("%s takes precedence over the keychain when it is set", routed.APIKeyEnv)
+			}
+			return nil
+		},
+	}
+}
+
+// NewAuthLogoutCmd creates the auth logout command.
+func NewAuthLogoutCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "logout <provider>",
+		Short: "Remove a provider's API key from the OS keychain",
+		Long: `Remove a provider's API key from the OS keychain.
+
+A built-in cloud provider is disabled in config.yaml unless it also has an
+encrypted key there.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			container := GetContainer()
+			if container == nil {
+				return fmt.Errorf("application not initialized")
+			}
+
+			formatter := GetFormatter()
+			name := args[0]
+			store := container.SecretStore()
+
+			if err := store.Delete(cmd.Context(), name); err != nil {
+				return fmt.Errorf("failed to remove API key from %s: %w", store.Name(), err)
+			}
+
+			if err := updateCloudConfig(name, func(c *config.CloudConfig) {
+				c.Keychain = false
+				c.Enabled = c.Enabled && c.APIKeyEncrypted != ""
+			}); err != nil {
+				return err
+			}
+
+			formatter.Success("Removed %s API key from %s", name, store.Name())
+			return nil
+		},
+	}
+}
+
+// NewAuthListCmd creates the auth list command.
+func NewAuthListCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List providers and where their API keys come from",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			container := GetContainer()
+			if container == nil {
+				return fmt.Errorf("application not initialized")
+			}
+
+			formatter := GetFormatter()
+			store := container.SecretStore()
+
+			stored, err := store.List(cmd.Context())
+			if err != nil {
+				formatter.Warning("Could not read %s: %v", store.Name(), err)
+			}
+
+			entries := authEntries(container.Config(), container.RoutingConfiguration(), stored)
+
+			if formatter.Format() == output.FormatJSON {
+				return formatter.JSON(map[string]any{
+					"keychain":  store.Name(),
+					"providers": entries,
+				})
+			}
+
+			formatter.Header("API Keys")
+			formatter.Item("Keychain", store.Name())
+			formatter.Info("")
+
+			table := output.TableData{
+				Columns: []output.TableColumn{
+					{Header: "Provider", Width: 20, Align: output.AlignLeft},
+					{Header: "Source", Width: 30, Align: output.AlignLeft},
+					{Header: "Enabled", Width: 7, Align: output.AlignLeft},
+				},
+				Rows: make([][]string, 0, len(entries)),
+			}
+			for _, e := range entries {
+				enabled := "no"
+				if e.Enabled {
+					enabled = "yes"
+				}
+				table.Rows = append(table.Rows, []string{e.Provider, e.Source, enabled})
+			}
+			return formatter.Table(table)
+		},
+	}
+}
+
+// authEntries lists the built-in cloud providers, the OpenAI-compatible
+// providers of the routing configuration and any other keys in the keychain,
+// with the source of each provider's API key.
+func authEntries(cfg *config.Config, rc *config.RoutingConfiguration, stored []string) []AuthEntry {
+	var entries []AuthEntry
+	seen := make(map[string]bool)
+
+	for _, name := range config.CloudProviderNames {
+		c := cfg.Providers.Cloud(name)
+		source := "none"
+		switch {
+		case c.Keychain && slices.Contains(stored, name):
+			source = "keychain"
+		case c.Keychain:
+			source = "keychain (missing)"
+		case c.APIKeyEncrypted != "":
+			source = "config (encrypted)"
+		}
+		entries = append(entries, AuthEntry{Provider: name, Source: source, Enabled: c.Enabled})
+		seen[name] = true
+	}
+
+	var routed []string
+	if rc != nil {
+		for name, p := range rc.Providers {
+			if p.IsOpenAICompatible() && !seen[name] {
+				routed = append(routed, name)
+			}
+		}
+	}
+	slices.Sort(routed)
+	for _, name := range routed {
+		p := rc.Providers[name]
+		source := "none"
+		switch {
+		case p.APIKeyEnv != "" && os.Getenv(p.APIKeyEnv) != "":
+			source = "env " + p.APIKeyEnv
+		case slices.Contains(stored, name):
+			source = "keychain"
+		case p.APIKeyEnv != "":
+			source = "env " + p.APIKeyEnv + " (unset)"
+		}
+		entries = append(entries, AuthEntry{Provider: name, Source: source, Enabled: p.Enabled})
+		seen[name] = true
+	}
+
+	for _, name := range stored {
+		if !seen[name] {
+			entries = append(entries, AuthEntry{Provider: name, Source: "keychain (unused)"})
+		}
+	}
+	return entries
+}
+
+// updateCloudConfig applies update to the built-in cloud provider name in
+// the configuration file. Other providers are left alone.
+func updateCloudConfig(name string, update func(*config.CloudConfig)) error {
+	if !slices.Contains(config.CloudProviderNames, name) {
+		return nil
+	}
+
+	loader, err := config.NewLoader("")
+	if err != nil {
+		return fmt.Errorf("failed to create config loader: %w", err)
+	}
+	cfg, err := loader.Load(globalFlags.ConfigFile)
+	if err != nil {
+		return err
+	}
+
+	update(cfg.Providers.Cloud(name))
+	if err := loader.Save(cfg, globalFlags.ConfigFile); err != nil {
+		return fmt.Errorf("failed to update configuration: %w", err)
+	}
+	return nil
+}
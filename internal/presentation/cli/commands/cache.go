@@ -7,6 +7,10 @@ import (
 	"time"
 
 	"github.com/spf13/cobra"
+
+	"github.com/jbctechsolutions/skillrunner/internal/application/ports"
+	"github.com/jbctechsolutions/skillrunner/internal/application/workflow"
+	"github.com/jbctechsolutions/skillrunner/internal/presentation/cli/output"
 )
 
 // NewCacheCmd creates the cache management command.
@@ -25,6 +29,7 @@ reducing API calls and improving response times.`,
 	cmd.AddCommand(NewCacheClearCmd())
 	cmd.AddCommand(NewCacheListCmd())
 	cmd.AddCommand(NewCacheConfigCmd())
+	cmd.AddCommand(NewCachePrimeCmd())
 
 	return cmd
 }
@@ -252,6 +257,123 @@ func NewCacheConfigCmd() *cobra.Command {
 	return cmd
 }
 
+// cachePrimeResult is the JSON form of cache prime.
+type cachePrimeResult struct {
+	Runs    int `json:"runs"`
+	Primed  int `json:"primed"`
+	Cached  int `json:"cached"`
+	Skipped int `json:"skipped"`
+}
+
+// NewCachePrimeCmd creates the cache prime command.
+func NewCachePrimeCmd() *cobra.Command {
+	var (
+		skillID  string
+		days     int
+		maxRuns  int
+		noMemory bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "prime",
+		Short: "Seed the cache with the outputs of recent runs",
+		Long: `Replay the prompts of recent runs into the response cache, without calling
+providers, so that cache hit analysis and replay-based testing keep working
+after their entries have been evicted.
+
+Each completed phase of a run is stored under the key the phase would be
+looked up with today: its prompt is rendered again from the run's input and
+phase outputs with the skill's current templates, and with the current
+project memory unless --no-memory is given. Phases that are already cached
+are left as they are.`,
+		Example: `  # Prime the cache with last week's summarize runs
+  sr cache prime --skill summarize --days 7
+
+  # Prime with every run of the last day
+  sr cache prime --days 1`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if days <= 0 {
+				return fmt.Errorf("--days must be positive")
+			}
+
+			container := GetContainer()
+			if container == nil {
+				return fmt.Errorf("application not initialized")
+			}
+
+			formatter := GetFormatter()
+
+			cache := container.ResponseCache()
+			if cache == nil {
+				formatter.Warning("Cache is not enabled")
+				return nil
+			}
+			registry := container.SkillRegistry()
+			if registry == nil {
+				return fmt.Errorf("skill registry not available")
+			}
+			runs, _, err := historyRepositories()
+			if err != nil {
+				return err
+			}
+
+			checkpoints, err := runs.List(cmd.Context(), &ports.WorkflowCheckpointFilter{
+				SkillID:      skillID,
+				CreatedAfter: time.Now().AddDate(0, 0, -days),
+				Limit:        maxRuns,
+			})
+			if err != nil {
+				return fmt.Errorf("failed to list runs: %w", err)
+			}
+
+			primer := workflow.NewCachePrimer(cache, workflow.CachingConfig{
+				Enabled:    true,
+				DefaultTTL: container.Config().Cache.DefaultTTL,
+			}, loadMemoryContent(noMemory))
+
+			var result cachePrimeResult
+			for _, cp := range checkpoints {
+				sk := registry.GetSkill(cp.SkillID())
+				if sk == nil {
+					continue // The skill has been removed since the run
+				}
+				primed, err := primer.Prime(cmd.Context(), sk, cp)
+				if err != nil {
+					return fmt.Errorf("failed to prime run %s: %w", cp.ID(), err)
+				}
+				result.Runs++
+				result.Primed += primed.Primed
+				result.Cached += primed.Cached
+				result.Skipped += primed.Skipped
+			}
+
+			if formatter.Format() == output.FormatJSON {
+				return formatter.JSON(result)
+			}
+			if result.Runs == 0 {
+				formatter.Info("No runs in the last %d day(s)", days)
+				return nil
+			}
+			formatter.Success("Primed %d phase(s) from %d run(s)", result.Primed, result.Runs)
+			if result.Cached > 0 {
+				formatter.Info("%d phase(s) were already cached", result.Cached)
+			}
+			if result.Skipped > 0 {
+				formatter.Info("%d phase(s) skipped: not completed, or no longer rendered by the skill", result.Skipped)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&skillID, "skill", "", "only prime runs of this skill")
+	cmd.Flags().IntVar(&days, "days", 7, "prime runs from the last N days")
+	cmd.Flags().IntVar(&maxRuns, "max", 500, "maximum number of runs to prime")
+	cmd.Flags().BoolVar(&noMemory, "no-memory", false, "render prompts without project memory, for runs made with --no-memory")
+
+	return cmd
+}
+
 // Helper functions for cache formatting
 
 func formatCacheBytes(bytes int64) string {
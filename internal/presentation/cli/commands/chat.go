@@ -15,6 +15,7 @@ import (
 	domainChat "github.com/jbctechsolutions/skillrunner/internal/domain/chat"
 	"github.com/jbctechsolutions/skillrunner/internal/domain/session"
 	"github.com/jbctechsolutions/skillrunner/internal/domain/skill"
+	"github.com/jbctechsolutions/skillrunner/internal/infrastructure/config"
 )
 
 // chatFlags holds the flags for the chat command.
@@ -66,7 +67,7 @@ Examples:
 	cmd.Flags().StringVarP(&chatOpts.Model, "model", "m", "",
 		"initial model selection (e.g., claude-3-opus, gpt-4)")
 	cmd.Flags().StringVarP(&chatOpts.Profile, "profile", "p", skill.ProfileBalanced,
-		fmt.Sprintf("routing profile: %s, %s, %s", skill.ProfileCheap, skill.ProfileBalanced, skill.ProfilePremium))
+		fmt.Sprintf("routing profile: %s, %s, %s or a custom profile", skill.ProfileCheap, skill.ProfileBalanced, skill.ProfilePremium))
 	cmd.Flags().StringVarP(&chatOpts.SessionName, "session", "s", "",
 		"session name (auto-generated if not provided)")
 	cmd.Flags().StringVar(&chatOpts.SystemPrompt, "system", "",
@@ -81,10 +82,13 @@ func runChat(cmd *cobra.Command, args []string) error {
 	ctx := context.Background()
 
 	// Initialize chat service
-	chatService, err := initChatService()
+	chatService, routingWatcher, err := initChatService(ctx)
 	if err != nil {
 		return fmt.Errorf("could not initialize chat service: %w", err)
 	}
+	if routingWatcher != nil {
+		defer routingWatcher.Close()
+	}
 
 	// Generate or use provided session name
 	sessionName := chatOpts.SessionName
@@ -242,17 +246,9 @@ func handleChatCommand(cmd string, conversation *domainChat.Conversation, curren
 		if len(parts) < 2 {
 			return false, fmt.Errorf("usage: /profile <profile-name>")
 		}
-		newProfile := parts[1]
-		validProfiles := []string{skill.ProfileCheap, skill.ProfileBalanced, skill.ProfilePremium}
-		isValid := false
-		for _, valid := range validProfiles {
-			if newProfile == valid {
-				isValid = true
-				break
-			}
-		}
-		if !isValid {
-			return false, fmt.Errorf("invalid profile: %s (must be one of: %s)", newProfile, strings.Join(validProfiles, ", "))
+		newProfile := strings.ToLower(parts[1])
+		if err := validateProfile(newProfile); err != nil {
+			return false, err
 		}
 		*currentProfile = newProfile
 		f.Success("Switched to profile: %s", *currentProfile)
@@ -264,28 +260,30 @@ func handleChatCommand(cmd string, conversation *domainChat.Conversation, curren
 }
 
 // initChatService initializes the chat service with provider registry and router.
-func initChatService() (*chat.Service, error) {
+// The router follows edits to the routing configuration until the returned
+// watcher, nil if routing hot reload is disabled, is closed.
+func initChatService(ctx context.Context) (*chat.Service, *config.RoutingWatcher, error) {
 	appCtx := GetAppContext()
 	if appCtx == nil {
-		return nil, fmt.Errorf("app context not initialized")
+		return nil, nil, fmt.Errorf("app context not initialized")
 	}
 
 	// Get the container which has the already-initialized provider registry
 	container := GetContainer()
 	if container == nil {
-		return nil, fmt.Errorf("application container not initialized")
+		return nil, nil, fmt.Errorf("application container not initialized")
 	}
 
 	// Get the provider registry from the container
 	// This registry is already populated with providers based on configuration
 	registry := container.ProviderRegistry()
 	if registry == nil {
-		return nil, fmt.Errorf("provider registry not available")
+		return nil, nil, fmt.Errorf("provider registry not available")
 	}
 
 	// Check if any providers are registered
 	if registry.Count() == 0 {
-		return nil, fmt.Errorf("no providers configured - please configure providers in ~/.skillrunner/config.yaml")
+		return nil, nil, fmt.Errorf("no providers configured - please configure providers in ~/.skillrunner/config.yaml")
 	}
 
 	// Create routing configuration from user's app config
@@ -294,14 +292,22 @@ func initChatService() (*chat.Service, error) {
 	// Create router with the populated registry
 	router, err := appProvider.NewRouter(routingCfg, registry)
 	if err != nil {
-		return nil, fmt.Errorf("could not create router: %w", err)
+		return nil, nil, fmt.Errorf("could not create router: %w", err)
 	}
 
 	// Create chat service with the properly initialized registry
 	chatService, err := chat.NewService(router, registry)
 	if err != nil {
-		return nil, fmt.Errorf("could not create chat service: %w", err)
+		return nil, nil, fmt.Errorf("could not create chat service: %w", err)
+	}
+
+	watcher, err := container.WatchRoutingConfig(ctx, func(rc *config.RoutingConfiguration) {
+		_ = router.UpdateConfig(rc)
+	})
+	if err != nil {
+		// Chat works without hot reload; the configuration is fixed at startup
+		watcher = nil
 	}
 
-	return chatService, nil
+	return chatService, watcher, nil
 }
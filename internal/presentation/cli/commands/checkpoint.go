@@ -0,0 +1,554 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/jbctechsolutions/skillrunner/internal/application/ports"
+	appProvider "github.com/jbctechsolutions/skillrunner/internal/application/provider"
+	appSkills "github.com/jbctechsolutions/skillrunner/internal/application/skills"
+	"github.com/jbctechsolutions/skillrunner/internal/application/workflow"
+	"github.com/jbctechsolutions/skillrunner/internal/domain/skill"
+	domainWorkflow "github.com/jbctechsolutions/skillrunner/internal/domain/workflow"
+	"github.com/jbctechsolutions/skillrunner/internal/presentation/cli/output"
+)
+
+// prunableStatuses are the statuses of the checkpoints sr checkpoint prune
+// removes by default: those of runs that have ended.
+var prunableStatuses = []domainWorkflow.CheckpointStatus{
+	domainWorkflow.CheckpointStatusCompleted,
+	domainWorkflow.CheckpointStatusFailed,
+	domainWorkflow.CheckpointStatusAbandoned,
+}
+
+// NewCheckpointCmd creates the checkpoint command for managing the
+// checkpoints of skill runs.
+func NewCheckpointCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "checkpoint",
+		Short: "Manage the checkpoints of skill runs",
+		Long: `Manage the checkpoints that 'sr run' records after each batch of phases.
+
+An interrupted run leaves an in_progress checkpoint behind. Resume it to
+continue from its last completed batch, or abandon it to start over.
+Checkpoints of ended runs accumulate in storage until they are pruned.
+
+For session checkpoints, see 'sr context checkpoint'.`,
+	}
+
+	cmd.AddCommand(NewCheckpointListCmd())
+	cmd.AddCommand(NewCheckpointShowCmd())
+	cmd.AddCommand(NewCheckpointResumeCmd())
+	cmd.AddCommand(NewCheckpointAbandonCmd())
+	cmd.AddCommand(NewCheckpointPruneCmd())
+
+	return cmd
+}
+
+// NewCheckpointListCmd creates the checkpoint list command.
+func NewCheckpointListCmd() *cobra.Command {
+	var skillID, status string
+	var limit int
+
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List checkpoints",
+		Long:  `List checkpoints, most recently updated first.`,
+		Example: `  # Runs that can be resumed
+  sr checkpoint list --status in_progress
+
+  # Failed and abandoned runs of one skill, as JSON
+  sr checkpoint list --skill code-review --status failed,abandoned -o json`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runCheckpointList(cmd.Context(), skillID, status, limit)
+		},
+	}
+
+	cmd.Flags().StringVar(&skillID, "skill", "", "only list checkpoints of this skill")
+	cmd.Flags().StringVar(&status, "status", "", "only list checkpoints with these comma-separated statuses (in_progress, completed, failed, abandoned)")
+	cmd.Flags().IntVar(&limit, "limit", defaultHistoryLimit, "maximum number of checkpoints to list (0 for all)")
+
+	return cmd
+}
+
+// NewCheckpointShowCmd creates the checkpoint show command.
+func NewCheckpointShowCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "show <checkpoint>",
+		Short: "Show a checkpoint and the state of its phases",
+		Example: `  sr checkpoint show 3f2a9c1e-...
+  sr checkpoint show latest -o json`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runCheckpointShow(cmd.Context(), args[0])
+		},
+	}
+}
+
+// NewCheckpointResumeCmd creates the checkpoint resume command.
+func NewCheckpointResumeCmd() *cobra.Command {
+	var profile string
+	var takeover bool
+
+	cmd := &cobra.Command{
+		Use:   "resume <checkpoint>",
+		Short: "Resume an interrupted run from its checkpoint",
+		Long: `Resume an in_progress checkpoint: run its skill on its original input,
+skipping the batches of phases that already completed.
+
+Unlike 'sr run --resume', which resumes the latest checkpoint of a skill and
+input, this resumes the given checkpoint, so it can pick up a run started by
+another command or on another machine sharing the checkpoint storage.
+
+A checkpoint another machine updated in the last 15 minutes may still be
+running there and is not resumed; use --takeover once that run has stopped.`,
+		Example: `  sr checkpoint resume 3f2a9c1e-...
+  sr checkpoint resume latest --profile cheap`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runCheckpointResume(cmd.Context(), args[0], profile, takeover)
+		},
+	}
+
+	cmd.Flags().StringVarP(&profile, "profile", "p", skill.ProfileBalanced, "routing profile for the remaining phases")
+	cmd.Flags().BoolVar(&takeover, "takeover", false, "resume the checkpoint even if another machine updated it recently")
+
+	return cmd
+}
+
+// NewCheckpointAbandonCmd creates the checkpoint abandon command.
+func NewCheckpointAbandonCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "abandon <checkpoint>",
+		Short: "Abandon an interrupted run",
+		Long: `Mark an in_progress checkpoint as abandoned, so that it is no longer
+resumed and 'sr run' no longer asks for --resume or --force on its input.`,
+		Example: `  sr checkpoint abandon 3f2a9c1e-...`,
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runCheckpointAbandon(cmd.Context(), args[0])
+		},
+	}
+}
+
+// NewCheckpointPruneCmd creates the checkpoint prune command.
+func NewCheckpointPruneCmd() *cobra.Command {
+	var olderThan, skillID, status string
+	var dryRun bool
+
+	cmd := &cobra.Command{
+		Use:   "prune",
+		Short: "Delete old checkpoints",
+		Long: `Delete the checkpoints of runs started longer ago than --older-than.
+
+Only checkpoints of ended runs (completed, failed or abandoned) are deleted;
+in_progress checkpoints must be abandoned first. Pinned runs are always kept
+(see 'sr history pin').`,
+		Example: `  # Delete checkpoints older than 30 days
+  sr checkpoint prune --older-than 30d
+
+  # Preview deleting the completed runs of one skill older than a week
+  sr checkpoint prune --older-than 7d --skill code-review --status completed --dry-run`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runCheckpointPrune(cmd.Context(), olderThan, skillID, status, dryRun)
+		},
+	}
+
+	cmd.Flags().StringVar(&olderThan, "older-than", "30d", "delete checkpoints of runs started longer ago than this (e.g., 30d, 12h)")
+	cmd.Flags().StringVar(&skillID, "skill", "", "only delete checkpoints of this skill")
+	cmd.Flags().StringVar(&status, "status", "", "only delete checkpoints with these comma-separated statuses (completed, failed, abandoned)")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "list the checkpoints that would be deleted without deleting them")
+
+	return cmd
+}
+
+// checkpointJSON is the JSON representation of a checkpoint.
+type checkpointJSON struct {
+	ID           string                                     `json:"id"`
+	ExecutionID  string                                     `json:"execution_id"`
+	Skill        string                                     `json:"skill"`
+	Status       string                                     `json:"status"`
+	Progress     string                                     `json:"progress"`
+	MachineID    string                                     `json:"machine_id,omitempty"`
+	Pinned       bool                                       `json:"pinned"`
+	Tags         map[string]string                          `json:"tags,omitempty"`
+	InputTokens  int                                        `json:"input_tokens"`
+	OutputTokens int                                        `json:"output_tokens"`
+	CreatedAt    time.Time                                  `json:"created_at"`
+	UpdatedAt    time.Time                                  `json:"updated_at"`
+	Input        string                                     `json:"input,omitempty"`
+	Phases       map[string]*domainWorkflow.PhaseResultData `json:"phases,omitempty"`
+}
+
+func toCheckpointJSON(cp *domainWorkflow.WorkflowCheckpoint) checkpointJSON {
+	return checkpointJSON{
+		ID:           cp.ID(),
+		ExecutionID:  cp.ExecutionID(),
+		Skill:        cp.SkillID(),
+		Status:       string(cp.Status()),
+		Progress:     cp.Progress(),
+		MachineID:    cp.MachineID(),
+		Pinned:       cp.Pinned(),
+		Tags:         cp.Tags(),
+		InputTokens:  cp.InputTokens(),
+		OutputTokens: cp.OutputTokens(),
+		CreatedAt:    cp.CreatedAt(),
+		UpdatedAt:    cp.UpdatedAt(),
+	}
+}
+
+// parseCheckpointStatuses parses a comma-separated list of checkpoint
+// statuses. An empty list stands for all statuses.
+func parseCheckpointStatuses(s string) ([]domainWorkflow.CheckpointStatus, error) {
+	var statuses []domainWorkflow.CheckpointStatus
+	for _, name := range strings.Split(s, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		if !domainWorkflow.IsValidStatus(name) {
+			return nil, fmt.Errorf("invalid status %q: must be one of in_progress, completed, failed, abandoned", name)
+		}
+		statuses = append(statuses, domainWorkflow.CheckpointStatus(name))
+	}
+	return statuses, nil
+}
+
+func runCheckpointList(ctx context.Context, skillID, status string, limit int) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	statuses, err := parseCheckpointStatuses(status)
+	if err != nil {
+		return err
+	}
+	formatter := GetFormatter()
+	runs, _, err := historyRepositories()
+	if err != nil {
+		return err
+	}
+
+	checkpoints, err := runs.List(ctx, &ports.WorkflowCheckpointFilter{SkillID: skillID, Status: statuses, Limit: limit})
+	if err != nil {
+		return fmt.Errorf("failed to list checkpoints: %w", err)
+	}
+
+	if formatter.Format() == output.FormatJSON {
+		rows := make([]checkpointJSON, 0, len(checkpoints))
+		for _, cp := range checkpoints {
+			rows = append(rows, toCheckpointJSON(cp))
+		}
+		return formatter.JSON(rows)
+	}
+
+	formatter.Header("Checkpoints")
+	if len(checkpoints) == 0 {
+		formatter.Info("No checkpoints found")
+		return nil
+	}
+
+	table := output.TableData{
+		Columns: []output.TableColumn{
+			{Header: "Checkpoint", Width: 36, Align: output.AlignLeft},
+			{Header: "Skill", Width: 20, Align: output.AlignLeft},
+			{Header: "Status", Width: 11, Align: output.AlignLeft},
+			{Header: "Progress", Width: 8, Align: output.AlignRight},
+			{Header: "Machine", Width: 16, Align: output.AlignLeft},
+			{Header: "Updated", Width: 10, Align: output.AlignLeft},
+		},
+		Rows: make([][]string, 0, len(checkpoints)),
+	}
+	for _, cp := range checkpoints {
+		table.Rows = append(table.Rows, []string{
+			cp.ID(),
+			cp.SkillID(),
+			string(cp.Status()),
+			cp.Progress(),
+			truncateString(cp.MachineID(), 16),
+			formatRelativeTime(cp.UpdatedAt()),
+		})
+	}
+	formatter.Table(table)
+	return nil
+}
+
+func runCheckpointShow(ctx context.Context, ref string) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	formatter := GetFormatter()
+	runs, _, err := historyRepositories()
+	if err != nil {
+		return err
+	}
+	cp, err := findRun(ctx, runs, ref)
+	if err != nil {
+		return err
+	}
+
+	if formatter.Format() == output.FormatJSON {
+		result := toCheckpointJSON(cp)
+		result.Input = cp.Input()
+		result.Phases = cp.PhaseResults()
+		return formatter.JSON(result)
+	}
+
+	formatter.Header("Checkpoint")
+	formatter.Item("ID", cp.ID())
+	formatter.Item("Execution", cp.ExecutionID())
+	formatter.Item("Skill", cp.SkillName())
+	formatter.Item("Status", string(cp.Status()))
+	formatter.Item("Progress", cp.Progress()+" batches")
+	if cp.MachineID() != "" {
+		formatter.Item("Machine", cp.MachineID())
+	}
+	if cp.Pinned() {
+		formatter.Item("Pinned", "yes")
+	}
+	formatter.Item("Tokens", fmt.Sprintf("%d in / %d out", cp.InputTokens(), cp.OutputTokens()))
+	formatter.Item("Created", cp.CreatedAt().Local().Format(time.DateTime))
+	formatter.Item("Updated", cp.UpdatedAt().Local().Format(time.DateTime))
+	formatter.Item("Input", truncateString(strings.Join(strings.Fields(cp.Input()), " "), 100))
+
+	results := cp.PhaseResults()
+	if len(results) > 0 {
+		formatter.Println("")
+		table := output.TableData{
+			Columns: []output.TableColumn{
+				{Header: "Phase", Width: 20, Align: output.AlignLeft},
+				{Header: "Batch", Width: 5, Align: output.AlignRight},
+				{Header: "Status", Width: 10, Align: output.AlignLeft},
+				{Header: "Model", Width: 24, Align: output.AlignLeft},
+				{Header: "Duration", Width: 9, Align: output.AlignRight},
+				{Header: "Error", Width: 30, Align: output.AlignLeft},
+			},
+			Rows: make([][]string, 0, len(results)),
+		}
+		ids := make([]string, 0, len(results))
+		for id := range results {
+			ids = append(ids, id)
+		}
+		slices.SortFunc(ids, func(a, b string) int {
+			if d := results[a].Batch - results[b].Batch; d != 0 {
+				return d
+			}
+			return strings.Compare(a, b)
+		})
+		for _, id := range ids {
+			r := results[id]
+			table.Rows = append(table.Rows, []string{
+				id,
+				fmt.Sprintf("%d", r.Batch),
+				r.Status,
+				truncateString(r.ModelUsed, 24),
+				formatDuration(time.Duration(r.DurationNs)),
+				truncateString(r.ErrorMessage, 30),
+			})
+		}
+		formatter.Table(table)
+	}
+
+	if cp.IsResumable() {
+		formatter.Println("")
+		formatter.Info("Resume with 'sr checkpoint resume %s'", cp.ID())
+	}
+	return nil
+}
+
+func runCheckpointResume(ctx context.Context, ref, profile string, takeover bool) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if err := validateProfile(profile); err != nil {
+		return err
+	}
+
+	formatter := GetFormatter()
+	container := GetContainer()
+	if container == nil {
+		return fmt.Errorf("application not initialized")
+	}
+	runs, _, err := historyRepositories()
+	if err != nil {
+		return err
+	}
+	cp, err := findRun(ctx, runs, ref)
+	if err != nil {
+		return err
+	}
+	if !cp.IsResumable() {
+		return fmt.Errorf("checkpoint %s is %s; only in_progress checkpoints can be resumed", cp.ID(), cp.Status())
+	}
+
+	registry := container.SkillRegistry()
+	if registry == nil {
+		return fmt.Errorf("skill registry not available")
+	}
+	sk := registry.GetSkill(cp.SkillID())
+	if sk == nil {
+		return fmt.Errorf("skill not found: %s", cp.SkillID())
+	}
+	if err := appSkills.VerifyRequirements(sk); err != nil {
+		return err
+	}
+
+	providerRegistry := container.ProviderRegistry()
+	prov := selectProvider(providerRegistry.ListProviders(), profile)
+	if prov == nil {
+		return fmt.Errorf("no suitable provider found for profile: %s", profile)
+	}
+
+	costCalc := container.CostCalculator()
+	executorConfig := workflow.DefaultExecutorConfig()
+	executorConfig.MemoryContent = loadMemoryContent(false)
+	fallbacks := newPinFallbacks()
+	executorConfig.ProviderSelector = appProvider.NewPinSelector(providerRegistry, fallbacks.warner(formatter))
+	executorConfig.ConcurrencyGroups = container.ConcurrencyGroups()
+	executorConfig.Skills = container.SkillRegistry()
+	executorConfig.Transcriber = container.Transcriber()
+	executorConfig.ImageGenerator = container.ImageGenerator()
+	executorConfig.ArtifactDir = artifactsDir(sk)
+	applyRouting(formatter, &executorConfig)
+	if err := applyBudgets(ctx, formatter, &executorConfig, container.RoutingConfiguration().Budgets, costCalc); err != nil {
+		return err
+	}
+	executor := workflow.NewCheckpointingExecutor(prov, executorConfig, workflow.CheckpointConfig{
+		Enabled:   true,
+		Port:      runs,
+		Resume:    true,
+		ResumeID:  cp.ID(),
+		MachineID: container.MachineID(),
+		Takeover:  takeover,
+	})
+
+	// The resumed run keeps reporting like the run it continues
+	runOpts.Profile = profile
+	runOpts.tags = cp.Tags()
+	if formatter.Format().Structured() {
+		return runSkillReport(ctx, executor, sk, cp.Input(), prov, costCalc, fallbacks)
+	}
+	formatter.Info("Resuming %s (%s) at batch %s", cp.ID(), sk.ID(), cp.Progress())
+	return runSkillText(ctx, executor, sk, cp.Input(), prov, formatter, costCalc)
+}
+
+func runCheckpointAbandon(ctx context.Context, ref string) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	formatter := GetFormatter()
+	runs, _, err := historyRepositories()
+	if err != nil {
+		return err
+	}
+	cp, err := findRun(ctx, runs, ref)
+	if err != nil {
+		return err
+	}
+	if !cp.IsResumable() {
+		return fmt.Errorf("checkpoint %s is %s; only in_progress checkpoints can be abandoned", cp.ID(), cp.Status())
+	}
+
+	cp.MarkAbandoned()
+	if err := runs.Update(ctx, cp); err != nil {
+		return fmt.Errorf("failed to abandon checkpoint %s: %w", cp.ID(), err)
+	}
+
+	if formatter.Format() == output.FormatJSON {
+		return formatter.JSON(toCheckpointJSON(cp))
+	}
+	formatter.Success("Abandoned checkpoint %s (%s)", cp.ID(), cp.SkillName())
+	return nil
+}
+
+// checkpointPruneJSON is the JSON output of sr checkpoint prune.
+type checkpointPruneJSON struct {
+	Deleted []string `json:"deleted"`
+	DryRun  bool     `json:"dry_run"`
+}
+
+func runCheckpointPrune(ctx context.Context, olderThan, skillID, status string, dryRun bool) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	age, err := parseDuration(olderThan)
+	if err != nil {
+		return fmt.Errorf("invalid --older-than: %w", err)
+	}
+	if age <= 0 {
+		return fmt.Errorf("--older-than must be positive")
+	}
+	statuses, err := parseCheckpointStatuses(status)
+	if err != nil {
+		return err
+	}
+	if slices.Contains(statuses, domainWorkflow.CheckpointStatusInProgress) {
+		return fmt.Errorf("in_progress checkpoints cannot be pruned; abandon them first")
+	}
+	if len(statuses) == 0 {
+		statuses = prunableStatuses
+	}
+
+	formatter := GetFormatter()
+	runs, _, err := historyRepositories()
+	if err != nil {
+		return err
+	}
+	deleted, err := pruneCheckpoints(ctx, runs, &ports.WorkflowCheckpointFilter{
+		SkillID:       skillID,
+		Status:        statuses,
+		CreatedBefore: time.Now().Add(-age),
+	}, dryRun)
+	if err != nil {
+		return err
+	}
+
+	if formatter.Format() == output.FormatJSON {
+		return formatter.JSON(checkpointPruneJSON{Deleted: deleted, DryRun: dryRun})
+	}
+	switch {
+	case len(deleted) == 0:
+		formatter.Info("No checkpoints older than %s to delete", olderThan)
+	case dryRun:
+		for _, id := range deleted {
+			formatter.Println("  %s", id)
+		}
+		formatter.Info("Would delete %d checkpoint(s)", len(deleted))
+	default:
+		formatter.Success("Deleted %d checkpoint(s) older than %s", len(deleted), olderThan)
+	}
+	return nil
+}
+
+// pruneCheckpoints deletes the unpinned checkpoints matching filter and
+// returns their IDs. With dryRun, nothing is deleted.
+func pruneCheckpoints(ctx context.Context, runs ports.WorkflowCheckpointPort, filter *ports.WorkflowCheckpointFilter, dryRun bool) ([]string, error) {
+	checkpoints, err := runs.List(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list checkpoints: %w", err)
+	}
+
+	deleted := make([]string, 0, len(checkpoints))
+	for _, cp := range checkpoints {
+		if cp.Pinned() {
+			continue
+		}
+		if !dryRun {
+			if err := runs.Delete(ctx, cp.ID()); err != nil {
+				return deleted, fmt.Errorf("failed to delete checkpoint %s: %w", cp.ID(), err)
+			}
+		}
+		deleted = append(deleted, cp.ID())
+	}
+	return deleted, nil
+}
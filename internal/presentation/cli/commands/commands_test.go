@@ -1,12 +1,33 @@
 package commands
 
 import (
+	"bufio"
 	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
 	"strings"
 	"testing"
 	"time"
 
 	"github.com/spf13/cobra"
+
+	"github.com/jbctechsolutions/skillrunner/internal/application/ports"
+	"github.com/jbctechsolutions/skillrunner/internal/application/workflow"
+	"github.com/jbctechsolutions/skillrunner/internal/domain/provider"
+	"github.com/jbctechsolutions/skillrunner/internal/domain/skill"
+	domainWorkflow "github.com/jbctechsolutions/skillrunner/internal/domain/workflow"
+	"github.com/jbctechsolutions/skillrunner/internal/infrastructure/config"
+	"github.com/jbctechsolutions/skillrunner/internal/infrastructure/resources"
+	infraSkills "github.com/jbctechsolutions/skillrunner/internal/infrastructure/skills"
+	"github.com/jbctechsolutions/skillrunner/internal/presentation/cli/output"
+	"github.com/jbctechsolutions/skillrunner/internal/presentation/server"
 )
 
 // executeCommand executes a cobra command with the given args.
@@ -30,7 +51,7 @@ func TestNewRootCmd(t *testing.T) {
 	}
 
 	// Check key subcommands exist
-	wantSubcmds := []string{"version", "list", "run", "status", "ask", "import", "init", "metrics"}
+	wantSubcmds := []string{"version", "list", "run", "status", "ask", "import", "init", "metrics", "history", "checkpoint", "skill", "config", "models", "cost", "sweep", "serve", "replay"}
 	subcmds := make(map[string]bool)
 	for _, sub := range cmd.Commands() {
 		subcmds[sub.Name()] = true
@@ -268,6 +289,56 @@ func TestNewVersionCmd_Structure(t *testing.T) {
 	}
 }
 
+func TestNewModelsCmd_Structure(t *testing.T) {
+	cmd := NewModelsCmd()
+
+	sync, _, err := cmd.Find([]string{"sync-pricing"})
+	if err != nil || sync.Name() != "sync-pricing" {
+		t.Fatalf("missing sync-pricing subcommand: %v", err)
+	}
+	if sync.Flags().Lookup("url") == nil {
+		t.Error("sync-pricing should have a --url flag")
+	}
+}
+
+func TestNewCachePrimeCmd_Structure(t *testing.T) {
+	cmd := NewCacheCmd()
+
+	prime, _, err := cmd.Find([]string{"prime"})
+	if err != nil || prime.Name() != "prime" {
+		t.Fatalf("missing prime subcommand: %v", err)
+	}
+	for _, flag := range []string{"skill", "days", "max", "no-memory"} {
+		if prime.Flags().Lookup(flag) == nil {
+			t.Errorf("prime should have a --%s flag", flag)
+		}
+	}
+}
+
+func TestNewMetricsShareCmd_Structure(t *testing.T) {
+	cmd := NewMetricsCmd()
+
+	share, _, err := cmd.Find([]string{"share"})
+	if err != nil || share.Name() != "share" {
+		t.Fatalf("missing share subcommand: %v", err)
+	}
+	for _, flag := range []string{"since", "out", "upload"} {
+		if share.Flags().Lookup(flag) == nil {
+			t.Errorf("share should have a --%s flag", flag)
+		}
+	}
+}
+
+func TestFormatModelPrice(t *testing.T) {
+	if got := formatModelPrice(nil); got != "-" {
+		t.Errorf("formatModelPrice(nil) = %q, want -", got)
+	}
+	price := &config.ModelPrice{CostPerInputToken: 3e-6, CostPerOutputToken: 15e-6}
+	if got := formatModelPrice(price); got != "$3.00 / $15.00" {
+		t.Errorf("formatModelPrice() = %q, want $3.00 / $15.00", got)
+	}
+}
+
 func TestNewRunCmd_Structure(t *testing.T) {
 	cmd := NewRunCmd()
 
@@ -281,6 +352,17 @@ func TestNewRunCmd_Structure(t *testing.T) {
 	if cmd.Flags().Lookup("stream") == nil {
 		t.Error("missing --stream flag")
 	}
+	if f := cmd.Flags().Lookup("input"); f == nil || f.Value.Type() != "stringArray" {
+		t.Error("missing repeatable --input flag")
+	}
+	if f := cmd.Flags().Lookup("chunk-tokens"); f == nil || f.DefValue != "8000" {
+		t.Error("missing --chunk-tokens flag defaulting to 8000")
+	}
+	for _, name := range []string{"output-file", "output-dir", "format"} {
+		if cmd.Flags().Lookup(name) == nil {
+			t.Errorf("missing --%s flag", name)
+		}
+	}
 }
 
 func TestNewListCmd_Structure(t *testing.T) {
@@ -667,3 +749,1101 @@ func TestFormatDateTime(t *testing.T) {
 		})
 	}
 }
+
+func TestNewHistoryCmd_Structure(t *testing.T) {
+	cmd := NewHistoryCmd()
+
+	if cmd.Use != "history" {
+		t.Errorf("expected Use='history', got %q", cmd.Use)
+	}
+
+	analyze, _, err := cmd.Find([]string{"analyze"})
+	if err != nil || analyze.Name() != "analyze" {
+		t.Fatalf("missing analyze subcommand: %v", err)
+	}
+	if err := analyze.Args(analyze, []string{}); err == nil {
+		t.Error("analyze should require a run argument")
+	}
+
+	annotate, _, err := cmd.Find([]string{"annotate"})
+	if err != nil || annotate.Name() != "annotate" {
+		t.Fatalf("missing annotate subcommand: %v", err)
+	}
+	if annotate.Flags().ShorthandLookup("m") == nil {
+		t.Error("annotate should have a -m flag")
+	}
+
+	list, _, err := cmd.Find([]string{"list"})
+	if err != nil || list.Name() != "list" {
+		t.Fatalf("missing list subcommand: %v", err)
+	}
+	for _, flag := range []string{"skill", "limit"} {
+		if list.Flags().Lookup(flag) == nil {
+			t.Errorf("list should have a --%s flag", flag)
+		}
+	}
+
+	rerun, _, err := cmd.Find([]string{"rerun"})
+	if err != nil || rerun.Name() != "rerun" {
+		t.Fatalf("missing rerun subcommand: %v", err)
+	}
+	for _, flag := range []string{"status", "since", "max", "skill", "profile", "interval"} {
+		if rerun.Flags().Lookup(flag) == nil {
+			t.Errorf("rerun should have a --%s flag", flag)
+		}
+	}
+
+	stats, _, err := cmd.Find([]string{"stats"})
+	if err != nil || stats.Name() != "stats" {
+		t.Fatalf("missing stats subcommand: %v", err)
+	}
+	for _, flag := range []string{"since", "skill", "failures", "interval"} {
+		if stats.Flags().Lookup(flag) == nil {
+			t.Errorf("stats should have a --%s flag", flag)
+		}
+	}
+}
+
+func TestSkillRunStats(t *testing.T) {
+	var checkpoints []*domainWorkflow.WorkflowCheckpoint
+	for i, run := range []struct {
+		skill  string
+		failed bool
+	}{{"review", false}, {"review", true}, {"review", false}, {"summarize", true}} {
+		cp, err := domainWorkflow.NewWorkflowCheckpoint(fmt.Sprintf("cp-%d", i), fmt.Sprintf("exec-%d", i), run.skill, run.skill, "input", 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if run.failed {
+			cp.MarkFailed()
+		} else {
+			cp.MarkCompleted()
+		}
+		checkpoints = append(checkpoints, cp)
+	}
+
+	stats := skillRunStats(checkpoints)
+	if len(stats) != 2 || stats[0].Skill != "review" || stats[0].Runs != 3 || stats[0].Failed != 1 {
+		t.Fatalf("skillRunStats() = %+v, want review first with 3 runs", stats)
+	}
+	if stats[0].Success < 0.66 || stats[0].Success > 0.67 || stats[1].Success != 0 {
+		t.Errorf("success rates = %v, %v; want 2/3 and 0", stats[0].Success, stats[1].Success)
+	}
+}
+
+func TestRerunOutcome(t *testing.T) {
+	var fixed historyRerunJSON
+	rerunOutcome(&fixed, &workflow.ExecutionResult{Status: workflow.PhaseStatusCompleted, TotalCost: 0.02}, nil, nil)
+	if fixed.Outcome != rerunOutcomeFixed || fixed.AfterStatus != "completed" || fixed.Cost != 0.02 {
+		t.Errorf("completed rerun = %+v", fixed)
+	}
+
+	var failing historyRerunJSON
+	rerunOutcome(&failing, &workflow.ExecutionResult{Status: workflow.PhaseStatusFailed, Error: errors.New("rate limited")}, nil, nil)
+	if failing.Outcome != rerunOutcomeFailed || failing.AfterError != "rate limited" {
+		t.Errorf("failed rerun = %+v", failing)
+	}
+
+	var invalid historyRerunJSON
+	rerunOutcome(&invalid, nil, errors.New("invalid skill"), nil)
+	if invalid.Outcome != rerunOutcomeFailed || invalid.AfterError != "invalid skill" {
+		t.Errorf("rerun without a result = %+v", invalid)
+	}
+}
+
+func TestCollectArtifacts(t *testing.T) {
+	start := time.Now()
+	result := &workflow.ExecutionResult{PhaseResults: map[string]*workflow.PhaseResult{
+		"summary": {PhaseID: "summary", StartTime: start.Add(time.Second), Artifacts: []string{"summary.md"}},
+		"draft":   {PhaseID: "draft", StartTime: start, Artifacts: []string{"draft.md"}},
+		"chart":   {PhaseID: "chart", StartTime: start, Artifacts: []string{"chart.png", "chart.csv"}},
+	}}
+	if got, want := collectArtifacts(result), []string{"chart.png", "chart.csv", "draft.md", "summary.md"}; !slices.Equal(got, want) {
+		t.Errorf("collectArtifacts() = %q, want %q", got, want)
+	}
+}
+
+func TestRunError(t *testing.T) {
+	cp, err := domainWorkflow.NewWorkflowCheckpoint("cp-1", "exec-1", "code-review", "Code Review", "input", 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := runError(cp); got != "" {
+		t.Errorf("runError() without failures = %q", got)
+	}
+
+	cp.AddPhaseResult("review", &domainWorkflow.PhaseResultData{PhaseID: "review", ErrorMessage: "skipped", EndTime: 20})
+	cp.AddPhaseResult("analyze", &domainWorkflow.PhaseResultData{PhaseID: "analyze", ErrorMessage: "timeout", EndTime: 10})
+	if got := runError(cp); got != "analyze: timeout" {
+		t.Errorf("runError() = %q, want the first failed phase", got)
+	}
+}
+
+// throttledProvider reports that it is throttled a number of times.
+type throttledProvider struct {
+	ports.ProviderPort
+	remaining int
+}
+
+func (p *throttledProvider) Throttled() bool {
+	p.remaining--
+	return p.remaining >= 0
+}
+
+func TestRerunPacer(t *testing.T) {
+	pacer := &rerunPacer{interval: 50 * time.Millisecond}
+	prov := &throttledProvider{}
+
+	if err := pacer.wait(context.Background(), prov); err != nil {
+		t.Fatalf("first wait() error = %v", err)
+	}
+	start := time.Now()
+	if err := pacer.wait(context.Background(), prov); err != nil {
+		t.Fatalf("second wait() error = %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < 40*time.Millisecond {
+		t.Errorf("second wait() returned after %v, want about the interval", elapsed)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := pacer.wait(ctx, &throttledProvider{remaining: 1}); err == nil {
+		t.Error("wait() on a throttled provider should stop when the context is canceled")
+	}
+}
+
+func TestLatestNote(t *testing.T) {
+	if got := latestNote(nil); got != "" {
+		t.Errorf("latestNote(nil) = %q, want empty", got)
+	}
+
+	notes := []*domainWorkflow.RunNote{
+		{RunID: "cp-1", Text: "baseline"},
+		{RunID: "cp-1", Text: "this prompt\nversion regressed"},
+	}
+	if got, want := latestNote(notes), "this prompt version regressed (+1)"; got != want {
+		t.Errorf("latestNote() = %q, want %q", got, want)
+	}
+}
+
+func TestNewSkillCmd_Structure(t *testing.T) {
+	cmd := NewSkillCmd()
+
+	if cmd.Use != "skill" {
+		t.Errorf("expected Use='skill', got %q", cmd.Use)
+	}
+
+	optimize, _, err := cmd.Find([]string{"optimize"})
+	if err != nil || optimize.Name() != "optimize" {
+		t.Fatalf("missing optimize subcommand: %v", err)
+	}
+	if optimize.Flags().Lookup("runs") == nil {
+		t.Error("optimize should have a --runs flag")
+	}
+	if err := optimize.Args(optimize, []string{}); err == nil {
+		t.Error("optimize should require a skill argument")
+	}
+
+	lint, _, err := cmd.Find([]string{"lint"})
+	if err != nil || lint.Name() != "lint" {
+		t.Fatalf("missing lint subcommand: %v", err)
+	}
+	if err := lint.Args(lint, []string{}); err == nil {
+		t.Error("lint should require a skill argument")
+	}
+
+	if !slices.Contains(cmd.Aliases, "skills") {
+		t.Error("skill should have a skills alias")
+	}
+	for _, name := range []string{"init", "list", "show", "validate"} {
+		if sub, _, err := cmd.Find([]string{name}); err != nil || sub.Name() != name {
+			t.Errorf("missing %s subcommand: %v", name, err)
+		}
+	}
+
+	tune, _, err := cmd.Find([]string{"tune"})
+	if err != nil || tune.Name() != "tune" {
+		t.Fatalf("missing tune subcommand: %v", err)
+	}
+	for _, flag := range []string{"phase", "proposer", "judge", "input", "cases", "rounds", "variants", "max-cost", "dry-run"} {
+		if tune.Flags().Lookup(flag) == nil {
+			t.Errorf("tune should have a --%s flag", flag)
+		}
+	}
+}
+
+func TestPromptScaffold(t *testing.T) {
+	s := infraSkills.Scaffold{Phases: defaultScaffoldPhases, Profile: "balanced"}
+	p := &prompter{
+		reader:    bufio.NewReader(strings.NewReader("release-notes\n\nDrafts release notes\ncollect, draft ,polish\ny\npremium\n")),
+		formatter: output.NewFormatter(output.WithWriter(&bytes.Buffer{})),
+	}
+	if err := promptScaffold(p, &s); err != nil {
+		t.Fatalf("promptScaffold() error = %v", err)
+	}
+	want := infraSkills.Scaffold{
+		ID:          "release-notes",
+		Name:        "Release Notes",
+		Description: "Drafts release notes",
+		Phases:      []string{"collect", "draft", "polish"},
+		Profile:     "premium",
+		Parallel:    true,
+	}
+	if !reflect.DeepEqual(s, want) {
+		t.Errorf("promptScaffold() = %+v, want %+v", s, want)
+	}
+}
+
+func TestWriteScaffold(t *testing.T) {
+	dir := t.TempDir()
+	s := infraSkills.Scaffold{ID: "release-notes", Phases: []string{"collect", "draft"}, Profile: "balanced"}
+
+	result, err := writeScaffold(dir, s, false)
+	if err != nil {
+		t.Fatalf("writeScaffold() error = %v", err)
+	}
+	if result.Path != filepath.Join(dir, "release-notes.yaml") || result.Fixture != filepath.Join(dir, "testdata", "release-notes.md") {
+		t.Errorf("writeScaffold() = %+v", result)
+	}
+	if _, err := os.Stat(result.Fixture); err != nil {
+		t.Errorf("sample input not written: %v", err)
+	}
+
+	if _, err := writeScaffold(dir, s, false); err == nil {
+		t.Error("writeScaffold() overwrote an existing skill without force")
+	}
+	if _, err := writeScaffold(dir, s, true); err != nil {
+		t.Errorf("writeScaffold() with force error = %v", err)
+	}
+}
+
+func TestTunedPhase(t *testing.T) {
+	draft, _ := skill.NewPhase("draft", "Draft", "Draft: {{._input}}")
+	polish, _ := skill.NewPhase("polish", "Polish", "Polish: {{.draft}}")
+	single, _ := skill.NewSkill("one", "One", "1.0.0", []skill.Phase{*draft})
+	multi, _ := skill.NewSkill("two", "Two", "1.0.0", []skill.Phase{*draft, *polish})
+
+	if id, err := tunedPhase(single, ""); err != nil || id != "draft" {
+		t.Errorf("tunedPhase(single) = %q, %v; want its only phase", id, err)
+	}
+	if _, err := tunedPhase(multi, ""); err == nil || !strings.Contains(err.Error(), "draft, polish") {
+		t.Errorf("tunedPhase(multi) error = %v, want the phases listed", err)
+	}
+	if id, err := tunedPhase(multi, "polish"); err != nil || id != "polish" {
+		t.Errorf("tunedPhase(multi, polish) = %q, %v", id, err)
+	}
+	if _, err := tunedPhase(multi, "review"); err == nil {
+		t.Error("an unknown phase should fail")
+	}
+}
+
+func TestNewConfigCmd_Structure(t *testing.T) {
+	cmd := NewConfigCmd()
+
+	if cmd.Use != "config" {
+		t.Errorf("expected Use='config', got %q", cmd.Use)
+	}
+
+	show, _, err := cmd.Find([]string{"show"})
+	if err != nil || show.Name() != "show" {
+		t.Fatalf("missing show subcommand: %v", err)
+	}
+	for _, flag := range []string{"effective", "origin", "provenance"} {
+		if show.Flags().Lookup(flag) == nil {
+			t.Errorf("show should have a --%s flag", flag)
+		}
+	}
+
+	diff, _, err := cmd.Find([]string{"diff"})
+	if err != nil || diff.Name() != "diff" {
+		t.Fatalf("missing diff subcommand: %v", err)
+	}
+	against := diff.Flags().Lookup("against")
+	if against == nil || against.DefValue != againstDefault {
+		t.Errorf("diff should have an --against flag defaulting to %q", againstDefault)
+	}
+
+	encrypt, _, err := cmd.Find([]string{"encrypt"})
+	if err != nil || encrypt.Name() != "encrypt" {
+		t.Fatalf("missing encrypt subcommand: %v", err)
+	}
+	if err := encrypt.Args(encrypt, []string{}); err == nil {
+		t.Error("encrypt should require a provider argument")
+	}
+	for _, flag := range []string{"file", "recipient"} {
+		if encrypt.Flags().Lookup(flag) == nil {
+			t.Errorf("encrypt should have a --%s flag", flag)
+		}
+	}
+
+	schema, _, err := cmd.Find([]string{"schema"})
+	if err != nil || schema.Name() != "schema" {
+		t.Fatalf("missing schema subcommand: %v", err)
+	}
+	if err := schema.Args(schema, []string{"yaml"}); err == nil {
+		t.Error("schema should reject an unknown schema name")
+	}
+}
+
+func TestRunConfigSchema(t *testing.T) {
+	for _, kind := range []string{"routing", "project", "skill"} {
+		var buf bytes.Buffer
+		if err := runConfigSchema(output.NewFormatter(output.WithWriter(&buf)), kind); err != nil {
+			t.Fatalf("runConfigSchema(%q) error = %v", kind, err)
+		}
+		var doc map[string]any
+		if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
+			t.Fatalf("%s schema is not JSON: %v", kind, err)
+		}
+		if doc["$schema"] != config.SchemaDialect {
+			t.Errorf("%s schema $schema = %v", kind, doc["$schema"])
+		}
+	}
+}
+
+func TestRunTimings_InfersDependenciesWithoutSkill(t *testing.T) {
+	cp, err := domainWorkflow.NewWorkflowCheckpoint("cp-1", "exec-1", "missing-skill", "Missing", "input", 2)
+	if err != nil {
+		t.Fatalf("NewWorkflowCheckpoint failed: %v", err)
+	}
+
+	base := time.Now()
+	cp.AddPhaseResult("a", &domainWorkflow.PhaseResultData{
+		PhaseID: "a", Status: "completed", Batch: 0,
+		StartTime: base.UnixNano(), EndTime: base.Add(time.Second).UnixNano(),
+	})
+	cp.AddPhaseResult("b", &domainWorkflow.PhaseResultData{
+		PhaseID: "b", Status: "completed", Batch: 1,
+		StartTime: base.Add(time.Second).UnixNano(), EndTime: base.Add(2 * time.Second).UnixNano(),
+	})
+
+	_, timings := runTimings(cp, nil, nil)
+	if len(timings) != 2 {
+		t.Fatalf("expected 2 timings, got %d", len(timings))
+	}
+	for _, tm := range timings {
+		if tm.PhaseID == "b" && (len(tm.DependsOn) != 1 || tm.DependsOn[0] != "a") {
+			t.Errorf("expected b to depend on previous batch [a], got %v", tm.DependsOn)
+		}
+	}
+}
+
+func TestAddAliasCommands(t *testing.T) {
+	project := &config.ProjectConfig{Aliases: map[string]string{
+		"ver":   "version --short",
+		"run":   "version",
+		"loop":  "ver",
+		"broke": "run 'x",
+	}}
+	root := NewRootCmd()
+	addAliasCommands(root, project)
+
+	cmd, _, err := root.Find([]string{"ver"})
+	if err != nil || !isProjectAlias(cmd) {
+		t.Fatalf("alias ver not registered: %v", err)
+	}
+	if cmd, _, _ := root.Find([]string{"run"}); isProjectAlias(cmd) {
+		t.Error("alias should not shadow the built-in run command")
+	}
+
+	if err := executeCommand(root, "ver"); err != nil {
+		t.Errorf("ver alias error = %v", err)
+	}
+	if err := executeCommand(root, "loop"); err == nil || !strings.Contains(err.Error(), "expands to alias") {
+		t.Errorf("expected alias chain error, got %v", err)
+	}
+	if err := executeCommand(root, "broke"); err == nil {
+		t.Error("expected error for an unparsable alias")
+	}
+}
+
+// skillMap resolves skills by ID.
+type skillMap map[string]*skill.Skill
+
+func (m skillMap) GetSkill(id string) *skill.Skill { return m[id] }
+
+func TestValidateSkillRouting(t *testing.T) {
+	draft, _ := skill.NewPhase("draft", "Draft", "Draft: {{._input}}")
+	draft.WithRoutingProfile("turbo")
+	review, _ := skill.NewPhase("review", "Review", "review")
+	review.WithSubSkill(&skill.SubSkillConfig{Skill: "review"})
+	lookup, _ := skill.NewPhase("lookup", "Lookup", "lookup")
+	lookup.WithSubSkill(&skill.SubSkillConfig{Skill: "missing"})
+	writer, _ := skill.NewSkill("writer", "Writer", "1.0.0", []skill.Phase{*draft, *review, *lookup})
+	draftOnly, _ := skill.NewPhase("draft", "Draft", "Draft: {{._input}}")
+	back, _ := skill.NewPhase("back", "Back", "back")
+	back.WithSubSkill(&skill.SubSkillConfig{Skill: "writer"})
+	reviewer, _ := skill.NewSkill("review", "Review", "1.0.0", []skill.Phase{*draftOnly, *back})
+
+	errs := validateSkillRouting(writer, nil, skillMap{"writer": writer, "review": reviewer})
+	want := []string{
+		`phase draft: unknown routing profile "turbo": must be one of cheap, balanced, premium`,
+		`runs unknown skill "missing"`,
+		"skill phases run in a cycle: writer -> review -> writer",
+	}
+	if !slices.Equal(errs, want) {
+		t.Errorf("validateSkillRouting() = %q, want %q", errs, want)
+	}
+
+	routing := &config.RoutingConfiguration{Profiles: map[string]*config.ProfileConfiguration{"turbo": {}}}
+	if errs := validateSkillRouting(reviewer, routing, nil); len(errs) != 0 {
+		t.Errorf("validateSkillRouting() of a valid skill = %q", errs)
+	}
+	if errs := validateSkillRouting(writer, routing, nil); len(errs) != 0 {
+		t.Errorf("validateSkillRouting() with a configured custom profile = %q", errs)
+	}
+}
+
+func TestSkillShowResult(t *testing.T) {
+	analyze, _ := skill.NewPhase("analyze", "Analyze", "{{._input}}")
+	analyze.Provider, analyze.PinSoft = "anthropic", true
+	report, _ := skill.NewPhase("report", "Report", "{{.analyze}}")
+	report.DependsOn = []string{"analyze"}
+	report.WithRoutingProfile(skill.ProfileCheap)
+	sk, _ := skill.NewSkill("review", "Review", "1.0.0", []skill.Phase{*analyze, *report})
+
+	plans := []domainWorkflow.PhasePlan{
+		{PhaseID: "analyze", BatchIndex: 0, ResolvedProvider: "anthropic", ResolvedModel: "claude"},
+		{PhaseID: "report", BatchIndex: 1, ResolvedProvider: "ollama", ResolvedModel: "llama3"},
+	}
+	result := skillShowResult(sk, [][]string{{"analyze"}, {"report"}}, plans)
+	if len(result.Phases) != 2 {
+		t.Fatalf("phases = %+v", result.Phases)
+	}
+	first, second := result.Phases[0], result.Phases[1]
+	if first.RoutingProfile != result.DefaultProfile || first.ResolvedModel != "claude" || phasePin(first) != "anthropic (soft)" {
+		t.Errorf("analyze = %+v, want the default profile, its model and soft pin", first)
+	}
+	if second.RoutingProfile != skill.ProfileCheap || second.Batch != 1 || !slices.Equal(second.DependsOn, []string{"analyze"}) {
+		t.Errorf("report = %+v, want its profile, batch and dependencies", second)
+	}
+}
+
+func TestSkillAndRequest(t *testing.T) {
+	tests := []struct {
+		name        string
+		args        []string
+		hasInput    bool
+		wantSkill   string
+		wantRequest string
+		wantErr     bool
+	}{
+		{"skill and request", []string{"summarize", "Be brief"}, false, "summarize", "Be brief", false},
+		{"skill with input file", []string{"summarize"}, true, "summarize", "", false},
+		{"request without default skill", []string{"Be brief"}, false, "", "", true},
+		{"nothing", nil, true, "", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			skillName, request, err := skillAndRequest(tt.args, tt.hasInput)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if skillName != tt.wantSkill || request != tt.wantRequest {
+				t.Errorf("skillAndRequest() = %q, %q", skillName, request)
+			}
+		})
+	}
+
+	if got := joinInput("Be brief", "Document"); got != "Be brief\n\nDocument" {
+		t.Errorf("joinInput() = %q", got)
+	}
+	if got := joinInput("", "Document"); got != "Document" {
+		t.Errorf("joinInput() without request = %q", got)
+	}
+}
+
+// executorFunc is a workflow.Executor calling a function.
+type executorFunc func(ctx context.Context, sk *skill.Skill, input string) (*workflow.ExecutionResult, error)
+
+func (f executorFunc) Execute(ctx context.Context, sk *skill.Skill, input string) (*workflow.ExecutionResult, error) {
+	return f(ctx, sk, input)
+}
+
+func TestRunSkillFilter(t *testing.T) {
+	request, err := readStdinRequest(strings.NewReader("diff --git a/x b/x\n"))
+	if err != nil || request != "diff --git a/x b/x\n" {
+		t.Fatalf("readStdinRequest() = %q, %v", request, err)
+	}
+	if _, err := readStdinRequest(strings.NewReader(" \n")); err == nil {
+		t.Error("readStdinRequest() of empty stdin succeeded")
+	}
+
+	var gotInput string
+	executor := executorFunc(func(_ context.Context, _ *skill.Skill, input string) (*workflow.ExecutionResult, error) {
+		gotInput = input
+		return &workflow.ExecutionResult{
+			Status:      workflow.PhaseStatusCompleted,
+			FinalOutput: "LGTM",
+			PhaseResults: map[string]*workflow.PhaseResult{
+				"review": {PhaseName: "Review", Status: workflow.PhaseStatusCompleted, Warnings: []string{"echoes the input"}},
+			},
+		}, nil
+	})
+	review, _ := skill.NewPhase("review", "Review", "Review: {{._input}}")
+	sk, _ := skill.NewSkill("review-diff", "Review Diff", "1.0.0", []skill.Phase{*review})
+	var stdout, stderr bytes.Buffer
+	formatter := output.NewFormatter(output.WithWriter(&stderr), output.WithColor(false))
+	if err := runSkillFilter(context.Background(), executor, sk, request, formatter, &stdout, nil); err != nil {
+		t.Fatalf("runSkillFilter() error = %v", err)
+	}
+	if gotInput != request {
+		t.Errorf("executed with %q, want the stdin request", gotInput)
+	}
+	if stdout.String() != "LGTM\n" {
+		t.Errorf("stdout = %q, want only the final output", stdout.String())
+	}
+
+	sk.SetOutputSinks([]skill.OutputSink{{Type: skill.OutputSinkStdout}})
+	stdout.Reset()
+	if err := runSkillFilter(context.Background(), executor, sk, request, formatter, &stdout, nil); err != nil {
+		t.Fatalf("runSkillFilter() with a stdout sink error = %v", err)
+	}
+	if stdout.String() != "LGTM\nLGTM\n" {
+		t.Errorf("stdout with a stdout sink = %q, want the final output twice", stdout.String())
+	}
+	sk.SetOutputSinks(nil)
+	if !strings.Contains(stderr.String(), "echoes the input") {
+		t.Errorf("stderr = %q, want the warnings", stderr.String())
+	}
+
+	failing := executorFunc(func(context.Context, *skill.Skill, string) (*workflow.ExecutionResult, error) {
+		return &workflow.ExecutionResult{Status: workflow.PhaseStatusFailed, Error: errors.New("HTTP 500"), FinalOutput: "partial"}, nil
+	})
+	stdout.Reset()
+	if err := runSkillFilter(context.Background(), failing, sk, request, formatter, &stdout, nil); err == nil || !strings.Contains(err.Error(), "HTTP 500") {
+		t.Errorf("runSkillFilter() of a failed run error = %v, want the run's", err)
+	}
+	if stdout.Len() != 0 {
+		t.Errorf("stdout of a failed run = %q, want nothing", stdout.String())
+	}
+}
+
+func TestSaveOutputs(t *testing.T) {
+	saved := runOpts
+	defer func() { runOpts = saved }()
+
+	draft, _ := skill.NewPhase("draft", "Draft", "Draft: {{._input}}")
+	plan, _ := skill.NewPhase("plan", "Plan", "Plan: {{.draft}}")
+	plan.OutputFormat = skill.OutputFormatJSON
+	sk, _ := skill.NewSkill("writer", "Writer", "1.0.0", []skill.Phase{*draft, *plan})
+	sk.SetOutputSinks([]skill.OutputSink{{Type: skill.OutputSinkStdout, Phase: "draft"}})
+
+	result := &workflow.ExecutionResult{
+		ExecutionID: "exec-1",
+		Status:      workflow.PhaseStatusCompleted,
+		FinalOutput: "final",
+		StartTime:   time.Date(2026, 3, 1, 9, 30, 0, 0, time.UTC),
+		PhaseResults: map[string]*workflow.PhaseResult{
+			"draft": {Status: workflow.PhaseStatusCompleted, Output: "first draft"},
+			"plan":  {Status: workflow.PhaseStatusCompleted, Output: `{"steps":[]}`},
+		},
+	}
+
+	dir := t.TempDir()
+	runOpts.OutputFile = filepath.Join(dir, "{{.skill}}-{{.timestamp}}.md")
+	runOpts.OutputDir = filepath.Join(dir, "runs", "{{.execution_id}}")
+	var stdout, stderr bytes.Buffer
+	formatter := output.NewFormatter(output.WithWriter(&stderr), output.WithColor(false))
+
+	paths := saveOutputs(formatter, sk, result, &stdout)
+	want := []string{
+		filepath.Join(dir, "writer-20260301-093000.md"),
+		filepath.Join(dir, "runs", "exec-1", "draft.md"),
+		filepath.Join(dir, "runs", "exec-1", "plan.json"),
+	}
+	if !slices.Equal(paths, want) {
+		t.Fatalf("saveOutputs() = %v, want %v", paths, want)
+	}
+	if data, err := os.ReadFile(want[0]); err != nil || string(data) != "final" {
+		t.Errorf("--output-file holds %q, %v, want the final output", data, err)
+	}
+	if data, err := os.ReadFile(want[2]); err != nil || string(data) != `{"steps":[]}` {
+		t.Errorf("plan.json holds %q, %v, want the phase output", data, err)
+	}
+	if stdout.String() != "first draft\n" {
+		t.Errorf("stdout = %q, want the draft phase's output", stdout.String())
+	}
+	if stderr.Len() != 0 {
+		t.Errorf("stderr = %q, want no warnings", stderr.String())
+	}
+
+	stdout.Reset()
+	jsonOut := output.NewFormatter(output.WithFormat(output.FormatJSON), output.WithWriter(&stderr))
+	runOpts.OutputFile, runOpts.OutputDir = "", ""
+	if paths := saveOutputs(jsonOut, sk, result, &stdout); len(paths) != 0 || stdout.Len() != 0 {
+		t.Errorf("saveOutputs() in JSON mode = %v, stdout %q, want stdout sinks skipped", paths, stdout.String())
+	}
+
+	result.Status = workflow.PhaseStatusFailed
+	runOpts.OutputFile = filepath.Join(dir, "failed.md")
+	if paths := saveOutputs(formatter, sk, result, &stdout); paths != nil {
+		t.Errorf("saveOutputs() of a failed run = %v, want nothing written", paths)
+	}
+}
+
+// namedProvider is a provider known only by its name.
+type namedProvider struct {
+	ports.ProviderPort
+	name string
+}
+
+func (p namedProvider) Info() ports.ProviderInfo {
+	return ports.ProviderInfo{Name: p.name}
+}
+
+func TestNewRunReport(t *testing.T) {
+	saved := runOpts
+	defer func() { runOpts = saved }()
+	runOpts.Profile = skill.ProfileBalanced
+
+	draft, _ := skill.NewPhase("draft", "Draft", "Draft: {{._input}}")
+	draft.Provider, draft.PinSoft = "anthropic", true
+	polish, _ := skill.NewPhase("polish", "Polish", "Polish: {{.draft}}")
+	sk, _ := skill.NewSkill("writer", "Writer", "1.0.0", []skill.Phase{*draft, *polish})
+
+	var warnings bytes.Buffer
+	fallbacks := newPinFallbacks()
+	warn := fallbacks.warner(output.NewFormatter(output.WithWriter(&warnings), output.WithColor(false)))
+	warn(draft, namedProvider{name: "ollama"}, errors.New("unhealthy"))
+	warn(draft, namedProvider{name: "ollama"}, errors.New("unhealthy"))
+	if n := strings.Count(warnings.String(), "falling back"); n != 1 {
+		t.Errorf("warned %d times of a phase's fallback, want once: %q", n, warnings.String())
+	}
+
+	result := &workflow.ExecutionResult{
+		ExecutionID: "exec-1",
+		Status:      workflow.PhaseStatusFailed,
+		TotalTokens: 30,
+		TotalCost:   0.5,
+		CacheHits:   1,
+		Error:       errors.New("polish failed"),
+		PhaseResults: map[string]*workflow.PhaseResult{
+			"polish": {PhaseID: "polish", Status: workflow.PhaseStatusFailed, Error: errors.New("HTTP 500"), ProviderUsed: "openai"},
+			"draft":  {PhaseID: "draft", Status: workflow.PhaseStatusCompleted, Output: "text", InputTokens: 20, OutputTokens: 10, ProviderUsed: "ollama", ModelUsed: "llama3", CacheHit: true},
+		},
+	}
+
+	report := newRunReport(sk, namedProvider{name: "ollama"}, result, fallbacks)
+	if report.SkillID != "writer" || report.ExecutionID != "exec-1" || report.Error != "polish failed" || report.CacheHits != 1 {
+		t.Errorf("newRunReport() = %+v, want the run's fields", report)
+	}
+	if len(report.Phases) != 2 || report.Phases[0].ID != "draft" || report.Phases[1].ID != "polish" {
+		t.Fatalf("phases = %+v, want draft and polish in the skill's order", report.Phases)
+	}
+	first, second := report.Phases[0], report.Phases[1]
+	if !first.Fallback || first.FallbackReason != "unhealthy" || !first.CacheHit || first.Model != "llama3" || first.Output != "text" {
+		t.Errorf("draft = %+v, want its fallback, cache hit, model and output", first)
+	}
+	if second.Fallback || second.Error != "HTTP 500" || second.Provider != "openai" {
+		t.Errorf("polish = %+v, want its error and no fallback", second)
+	}
+
+	var out bytes.Buffer
+	formatter := output.NewFormatter(output.WithWriter(&out), output.WithFormat(output.FormatYAML))
+	if err := formatter.FormatAuto(report, nil); err != nil {
+		t.Fatalf("FormatAuto() error = %v", err)
+	}
+	for _, want := range []string{"skill_id: writer\n", "execution_id: exec-1\n", "  - id: draft\n", "    fallback: true\n", "    fallback_reason: unhealthy\n"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("YAML report lacks %q:\n%s", want, out.String())
+		}
+	}
+}
+
+func TestApplyRunFormat(t *testing.T) {
+	saved := runOpts
+	defer func() { runOpts = saved }()
+
+	formatter := output.NewFormatter(output.WithFormat(output.FormatJSON))
+	runOpts.Format = ""
+	if err := applyRunFormat(formatter); err != nil || formatter.Format() != output.FormatJSON {
+		t.Errorf("applyRunFormat() without --format = %v, %v, want the global format kept", formatter.Format(), err)
+	}
+	runOpts.Format = "yaml"
+	if err := applyRunFormat(formatter); err != nil || formatter.Format() != output.FormatYAML {
+		t.Errorf("applyRunFormat(yaml) = %v, %v", formatter.Format(), err)
+	}
+	for _, format := range []string{"table", "xml"} {
+		runOpts.Format = format
+		if err := applyRunFormat(formatter); err == nil {
+			t.Errorf("applyRunFormat(%s) succeeded", format)
+		}
+	}
+}
+
+func TestValidateReportStyle(t *testing.T) {
+	saved := runOpts
+	defer func() { runOpts = saved }()
+
+	text := output.NewFormatter(output.WithFormat(output.FormatText))
+	jsonOut := output.NewFormatter(output.WithFormat(output.FormatJSON))
+
+	tests := []struct {
+		name      string
+		style     string
+		stream    bool
+		formatter *output.Formatter
+		wantErr   bool
+	}{
+		{"default", "", false, text, false},
+		{"text", "text", true, jsonOut, false},
+		{"notebook", "notebook", false, text, false},
+		{"notebook with stream", "notebook", true, text, true},
+		{"notebook with json", "notebook", false, jsonOut, true},
+		{"unknown", "html", false, text, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runOpts.ReportStyle = tt.style
+			runOpts.Stream = tt.stream
+			if err := validateReportStyle(tt.formatter); (err != nil) != tt.wantErr {
+				t.Errorf("validateReportStyle() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestResourceRows(t *testing.T) {
+	if rows := resourceRows(nil); rows != nil {
+		t.Errorf("expected no rows without usage, got %v", rows)
+	}
+
+	usage := &resources.Usage{
+		Samples:     3,
+		CPU:         resources.Stat{Peak: 85, Avg: 42.4},
+		Memory:      resources.Stat{Peak: 12 << 30, Avg: 10 << 30},
+		MemoryTotal: 32 << 30,
+		GPU: &resources.GPUUsage{
+			Source:  "nvidia-smi",
+			Percent: resources.Stat{Peak: 97, Avg: 60},
+			Memory:  resources.Stat{Peak: 6 << 30, Avg: 5 << 30},
+		},
+	}
+	want := []output.NotebookRow{
+		{Label: "CPU", Value: "peak 85%, avg 42%"},
+		{Label: "RAM", Value: "peak 12.00 GB, avg 10.00 GB of 32.00 GB"},
+		{Label: "GPU (nvidia-smi)", Value: "peak 97%, avg 60%, memory peak 6.00 GB"},
+	}
+	if got := resourceRows(usage); !slices.Equal(got, want) {
+		t.Errorf("resourceRows() = %v, want %v", got, want)
+	}
+
+	got := resourceUsageJSON(usage)
+	if got["samples"] != 3 || got["memory_total_bytes"] != uint64(32<<30) {
+		t.Errorf("resourceUsageJSON() = %v", got)
+	}
+	if gpu, ok := got["gpu"].(map[string]any); !ok || gpu["source"] != "nvidia-smi" {
+		t.Errorf("resourceUsageJSON() gpu = %v", got["gpu"])
+	}
+}
+
+func TestAuthEntries(t *testing.T) {
+	t.Setenv("SR_TEST_GATEWAY_KEY", "")
+
+	cfg := config.NewDefaultConfig()
+	cfg.Providers.Anthropic = config.CloudConfig{Keychain: true, Enabled: true}
+	cfg.Providers.OpenAI = config.CloudConfig{APIKeyEncrypted: "abc", Enabled: true}
+	cfg.Providers.Groq = config.CloudConfig{Keychain: true}
+
+	rc := &config.RoutingConfiguration{Providers: map[string]*config.ProviderConfiguration{
+		"gateway": {Type: config.ProviderTypeOpenAICompatible, APIKeyEnv: "SR_TEST_GATEWAY_KEY", Enabled: true},
+		"vllm":    {Type: config.ProviderTypeOpenAICompatible, APIKeyEnv: "SR_TEST_VLLM_KEY"},
+		"ollama":  {Enabled: true},
+	}}
+
+	entries := authEntries(cfg, rc, []string{"anthropic", "gateway", "old"})
+
+	sources := make(map[string]string)
+	for _, e := range entries {
+		sources[e.Provider] = e.Source
+	}
+	want := map[string]string{
+		"anthropic": "keychain",
+		"openai":    "config (encrypted)",
+		"groq":      "keychain (missing)",
+		"together":  "none",
+		"gateway":   "keychain",
+		"vllm":      "env SR_TEST_VLLM_KEY (unset)",
+		"old":       "keychain (unused)",
+	}
+	for provider, source := range want {
+		if sources[provider] != source {
+			t.Errorf("%s source = %q, want %q", provider, sources[provider], source)
+		}
+	}
+	if _, ok := sources["ollama"]; ok {
+		t.Error("built-in routing providers without API keys should not be listed")
+	}
+
+	t.Setenv("SR_TEST_GATEWAY_KEY", "from-env")
+	for _, e := range authEntries(cfg, rc, []string{"gateway"}) {
+		if e.Provider == "gateway" && e.Source != "env SR_TEST_GATEWAY_KEY" {
+			t.Errorf("gateway source = %q, want the environment variable", e.Source)
+		}
+	}
+}
+
+func TestNewCostReportCmd_Structure(t *testing.T) {
+	cmd := NewCostCmd()
+
+	report, _, err := cmd.Find([]string{"report"})
+	if err != nil || report.Name() != "report" {
+		t.Fatalf("missing report subcommand: %v", err)
+	}
+	if f := report.Flags().Lookup("since"); f == nil || f.DefValue != "7d" {
+		t.Error("report should have a --since flag defaulting to 7d")
+	}
+	if f := report.Flags().Lookup("by"); f == nil || f.DefValue != "provider" {
+		t.Error("report should have a --by flag defaulting to provider")
+	}
+}
+
+func TestCostGroupHeader(t *testing.T) {
+	if got := costGroupHeader(provider.CostByTag("team")); got != "Tag team" {
+		t.Errorf("costGroupHeader(tag:team) = %q, want \"Tag team\"", got)
+	}
+	if got := costGroupHeader(provider.CostByModel); got != "Model" {
+		t.Errorf("costGroupHeader(model) = %q, want \"Model\"", got)
+	}
+}
+
+func TestNewSweepCmd_Structure(t *testing.T) {
+	cmd := NewSweepCmd()
+
+	if err := cmd.Args(cmd, []string{"summarize"}); err == nil {
+		t.Error("sweep should require a skill and an input")
+	}
+	for _, flag := range []string{"param", "profile", "parallel", "max-cost", "judge", "no-memory"} {
+		if cmd.Flags().Lookup(flag) == nil {
+			t.Errorf("sweep should have a --%s flag", flag)
+		}
+	}
+}
+
+func TestNewServeCmd_Structure(t *testing.T) {
+	cmd := NewServeCmd()
+
+	if err := cmd.Args(cmd, []string{"extra"}); err == nil {
+		t.Error("serve should take no arguments")
+	}
+	for _, flag := range []string{"addr", "drain-timeout", "readiness", "max-runs", "max-queued"} {
+		if cmd.Flags().Lookup(flag) == nil {
+			t.Errorf("serve should have a --%s flag", flag)
+		}
+	}
+	if sub, _, err := cmd.Find([]string{"queue"}); err != nil || sub.Name() != "queue" {
+		t.Error("serve should have a queue subcommand")
+	}
+}
+
+func TestServeURL(t *testing.T) {
+	for addr, want := range map[string]string{
+		"127.0.0.1:8765":            "http://127.0.0.1:8765",
+		":9000":                     "http://127.0.0.1:9000",
+		"https://sr.example.com/":   "https://sr.example.com",
+		"skillrunner.internal:8765": "http://skillrunner.internal:8765",
+	} {
+		if got := serveURL(addr); got != want {
+			t.Errorf("serveURL(%q) = %q, want %q", addr, got, want)
+		}
+	}
+}
+
+func TestFetchServeQueue(t *testing.T) {
+	srv := server.New(server.Config{
+		MaxConcurrentRuns: 2,
+		MaxQueuedRuns:     8,
+		Clients:           []server.Client{{Name: "ci", Token: "secret"}},
+	}, nil)
+	ts := httptest.NewServer(srv.Handler())
+	defer ts.Close()
+
+	if _, err := fetchServeQueue(context.Background(), ts.URL, ""); err == nil || !strings.Contains(err.Error(), "--token") {
+		t.Errorf("fetchServeQueue() without a token error = %v, want a hint to pass one", err)
+	}
+	queue, err := fetchServeQueue(context.Background(), ts.URL, "secret")
+	if err != nil {
+		t.Fatalf("fetchServeQueue() error = %v", err)
+	}
+	if queue.MaxConcurrentRuns != 2 || queue.MaxQueuedRuns != 8 || queue.Running != 0 || queue.Saturated {
+		t.Errorf("fetchServeQueue() = %+v, want an idle queue with the server's limits", queue)
+	}
+}
+
+func TestServeClients(t *testing.T) {
+	t.Setenv("SR_TEST_SERVE_TOKEN", "env-token")
+	clients, err := serveClients([]config.ServeClient{
+		{Name: "ci", Token: "inline", Weight: 2},
+		{Name: "ops", TokenEnv: "SR_TEST_SERVE_TOKEN", Admin: true},
+	})
+	if err != nil {
+		t.Fatalf("serveClients() error = %v", err)
+	}
+	if len(clients) != 2 || clients[0].Token != "inline" || clients[0].Weight != 2 || clients[1].Token != "env-token" || !clients[1].Admin {
+		t.Errorf("serveClients() = %+v, want the clients with their tokens", clients)
+	}
+	if _, err := serveClients([]config.ServeClient{{Name: "ci", TokenEnv: "SR_TEST_SERVE_TOKEN_UNSET"}}); err == nil || !strings.Contains(err.Error(), "SR_TEST_SERVE_TOKEN_UNSET") {
+		t.Errorf("serveClients() error = %v, want one naming the unset variable", err)
+	}
+}
+
+func TestSelectReplayRecord(t *testing.T) {
+	records := []*domainWorkflow.AuditRecord{
+		{PhaseID: "analyze", Response: "first"},
+		{PhaseID: "review", Response: "only"},
+		{PhaseID: "analyze", Response: "retry"},
+	}
+
+	rec, call, calls, err := selectReplayRecord(records, "analyze", 0)
+	if err != nil || rec.Response != "retry" || call != 2 || calls != 2 {
+		t.Errorf("selectReplayRecord(analyze) = %v, %d, %d, %v; want the last of 2 calls", rec, call, calls, err)
+	}
+	if rec, _, _, _ := selectReplayRecord(records, "analyze", 1); rec == nil || rec.Response != "first" {
+		t.Errorf("selectReplayRecord(analyze, 1) = %v, want the first call", rec)
+	}
+	for _, tt := range []struct {
+		phase string
+		call  int
+	}{{"", 0}, {"summarize", 0}, {"review", 2}} {
+		if _, _, _, err := selectReplayRecord(records, tt.phase, tt.call); err == nil {
+			t.Errorf("selectReplayRecord(%q, %d) should fail", tt.phase, tt.call)
+		}
+	}
+	if rec, _, _, err := selectReplayRecord(records[1:2], "", 0); err != nil || rec.Response != "only" {
+		t.Errorf("selectReplayRecord() of a single-phase run = %v, %v; want its phase", rec, err)
+	}
+}
+
+func TestReplayRequest(t *testing.T) {
+	rec := &domainWorkflow.AuditRecord{
+		Model:        "llama3.2:8b",
+		SystemPrompt: "You review code.",
+		Messages:     []domainWorkflow.AuditMessage{{Role: "user", Content: "diff"}},
+		MaxTokens:    2048,
+		Temperature:  0.3,
+	}
+	req := replayRequest(rec, "")
+	if req.ModelID != "llama3.2:8b" || req.SystemPrompt != rec.SystemPrompt || req.MaxTokens != 2048 || req.Temperature != 0.3 || req.Messages[0].Content != "diff" {
+		t.Errorf("replayRequest() = %+v, want the recorded request", req)
+	}
+	if req := replayRequest(rec, "gpt-4o"); req.ModelID != "gpt-4o" {
+		t.Errorf("replayRequest() model = %q, want gpt-4o", req.ModelID)
+	}
+}
+
+func TestNewCheckpointCmd_Structure(t *testing.T) {
+	cmd := NewCheckpointCmd()
+
+	for _, name := range []string{"list", "show", "resume", "abandon", "prune"} {
+		sub, _, err := cmd.Find([]string{name})
+		if err != nil || sub.Name() != name {
+			t.Fatalf("missing %s subcommand: %v", name, err)
+		}
+	}
+
+	list, _, _ := cmd.Find([]string{"list"})
+	for _, flag := range []string{"skill", "status", "limit"} {
+		if list.Flags().Lookup(flag) == nil {
+			t.Errorf("list should have a --%s flag", flag)
+		}
+	}
+	prune, _, _ := cmd.Find([]string{"prune"})
+	for _, flag := range []string{"older-than", "skill", "status", "dry-run"} {
+		if prune.Flags().Lookup(flag) == nil {
+			t.Errorf("prune should have a --%s flag", flag)
+		}
+	}
+	resume, _, _ := cmd.Find([]string{"resume"})
+	if err := resume.Args(resume, []string{}); err == nil {
+		t.Error("resume should require a checkpoint argument")
+	}
+}
+
+func TestParseCheckpointStatuses(t *testing.T) {
+	got, err := parseCheckpointStatuses("failed, abandoned")
+	if err != nil || !slices.Equal(got, []domainWorkflow.CheckpointStatus{domainWorkflow.CheckpointStatusFailed, domainWorkflow.CheckpointStatusAbandoned}) {
+		t.Errorf("parseCheckpointStatuses() = %v, %v", got, err)
+	}
+	if got, err := parseCheckpointStatuses(""); err != nil || got != nil {
+		t.Errorf("parseCheckpointStatuses(\"\") = %v, %v; want all statuses", got, err)
+	}
+	if _, err := parseCheckpointStatuses("done"); err == nil {
+		t.Error("parseCheckpointStatuses() should reject an unknown status")
+	}
+}
+
+// pruneCheckpointPort lists a fixed set of checkpoints and records deletes.
+type pruneCheckpointPort struct {
+	ports.WorkflowCheckpointPort
+	checkpoints []*domainWorkflow.WorkflowCheckpoint
+	deleted     []string
+}
+
+func (p *pruneCheckpointPort) List(ctx context.Context, filter *ports.WorkflowCheckpointFilter) ([]*domainWorkflow.WorkflowCheckpoint, error) {
+	return p.checkpoints, nil
+}
+
+func (p *pruneCheckpointPort) Delete(ctx context.Context, id string) error {
+	p.deleted = append(p.deleted, id)
+	return nil
+}
+
+func TestPruneCheckpoints(t *testing.T) {
+	port := &pruneCheckpointPort{}
+	for _, id := range []string{"cp-1", "cp-2", "cp-3"} {
+		cp, err := domainWorkflow.NewWorkflowCheckpoint(id, "exec-"+id, "review", "Review", "input", 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		cp.MarkCompleted()
+		port.checkpoints = append(port.checkpoints, cp)
+	}
+	port.checkpoints[1].SetPinned(true)
+
+	deleted, err := pruneCheckpoints(context.Background(), port, &ports.WorkflowCheckpointFilter{}, true)
+	if err != nil || !slices.Equal(deleted, []string{"cp-1", "cp-3"}) || len(port.deleted) != 0 {
+		t.Errorf("dry run = %v, %v after %v deletes; want cp-1 and cp-3 listed only", deleted, err, port.deleted)
+	}
+
+	deleted, err = pruneCheckpoints(context.Background(), port, &ports.WorkflowCheckpointFilter{}, false)
+	if err != nil || !slices.Equal(deleted, []string{"cp-1", "cp-3"}) || !slices.Equal(port.deleted, deleted) {
+		t.Errorf("pruneCheckpoints() = %v, %v after deleting %v; want the pinned run kept", deleted, err, port.deleted)
+	}
+}
+
+func TestConfirmResume(t *testing.T) {
+	cp, err := domainWorkflow.NewWorkflowCheckpoint("cp-1", "exec-1", "review", "Review", "input", 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	text := output.NewFormatter(output.WithWriter(&buf), output.WithFormat(output.FormatText))
+
+	tests := []struct {
+		name        string
+		answer      string
+		interactive bool
+		formatter   *output.Formatter
+		want        bool
+		wantErr     bool
+	}{
+		{"default is resume", "\n", true, text, true, false},
+		{"declined", "n\n", true, text, false, false},
+		{"not a terminal", "", false, text, false, true},
+		{"json output", "y\n", true, output.NewFormatter(output.WithWriter(&buf), output.WithFormat(output.FormatJSON)), false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := confirmResume(tt.formatter, cp, strings.NewReader(tt.answer), tt.interactive)
+			if (err != nil) != tt.wantErr || got != tt.want {
+				t.Errorf("confirmResume() = %v, %v; want %v, error %v", got, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+	if !strings.Contains(buf.String(), "cp-1") {
+		t.Errorf("prompt output = %q, want the checkpoint ID", buf.String())
+	}
+}
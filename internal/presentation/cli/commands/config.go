@@ -0,0 +1,614 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	appProvider "github.com/jbctechsolutions/skillrunner/internal/application/provider"
+	"github.com/jbctechsolutions/skillrunner/internal/infrastructure/config"
+	"github.com/jbctechsolutions/skillrunner/internal/infrastructure/crypto"
+	infraSkills "github.com/jbctechsolutions/skillrunner/internal/infrastructure/skills"
+	"github.com/jbctechsolutions/skillrunner/internal/presentation/cli/output"
+)
+
+// againstDefault compares the effective configuration with the built-in defaults.
+const againstDefault = "default"
+
+// NewConfigCmd creates the config command for reviewing configuration.
+func NewConfigCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "config",
+		Short: "Review skillrunner configuration",
+		Long:  `Inspect the effective configuration produced by config.yaml, routing.yaml and .skillrunner.yaml, diagnose it against the configured providers, refresh shared routing sources, show the organization policy, encrypt the API keys it holds, and print the JSON Schemas of its files.`,
+	}
+
+	cmd.AddCommand(NewConfigShowCmd())
+	cmd.AddCommand(NewConfigDiffCmd())
+	cmd.AddCommand(NewConfigEncryptCmd())
+	cmd.AddCommand(NewConfigSchemaCmd())
+	cmd.AddCommand(NewConfigDoctorCmd())
+	cmd.AddCommand(NewConfigRefreshCmd())
+	cmd.AddCommand(NewConfigPolicyCmd())
+
+	return cmd
+}
+
+// NewConfigShowCmd creates the config show command.
+func NewConfigShowCmd() *cobra.Command {
+	var effective, origin bool
+
+	cmd := &cobra.Command{
+		Use:   "show",
+		Short: "Show configuration values and where they come from",
+		Long: `Show the application and routing configuration as dotted settings.
+
+By default only values set by a configuration source are listed. With
+--effective every value is listed, including built-in defaults. With
+--origin each value is annotated with the layer that supplied it, from
+lowest to highest precedence:
+  defaults   built-in defaults
+  remote     shared routing sources listed under routing.sources
+  system     /etc/skillrunner/routing.yaml
+  global     ~/.skillrunner/config.yaml and ~/.skillrunner/routing.yaml
+  project    the nearest .skillrunner/routing.yaml and .skillrunner.yaml
+             in this or a parent directory
+  env        SR_ environment variables, such as SR_DEFAULT_PROVIDER
+  flags      the files passed with --config and --routing
+
+API keys, tokens, passwords and other secrets are redacted.`,
+		Example: `  # Values set in your config files
+  sr config show
+
+  # The fully merged configuration, annotated with layers
+  sr config show --effective --origin
+
+  # As JSON
+  sr config show --effective --origin -o json`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runConfigShow(effective, origin)
+		},
+	}
+
+	cmd.Flags().BoolVar(&effective, "effective", false, "include values supplied by built-in defaults")
+	cmd.Flags().BoolVar(&origin, "origin", false, "show the layer that supplied each value")
+	cmd.Flags().BoolVar(&origin, "provenance", false, "alias of --origin")
+
+	return cmd
+}
+
+// configShowResult is the JSON form of config show.
+type configShowResult struct {
+	Application []config.Setting `json:"application"`
+	Routing     []config.Setting `json:"routing"`
+}
+
+func runConfigShow(effective, origin bool) error {
+	formatter := GetFormatter()
+	ctx := GetAppContext()
+	if ctx == nil || ctx.Config == nil {
+		return fmt.Errorf("application not initialized")
+	}
+
+	loader, err := config.NewLoader("")
+	if err != nil {
+		return err
+	}
+
+	// config.yaml: the --config file, or the default file if present
+	configLayer := config.Layer{Source: config.SourceGlobal, Value: ctx.Config}
+	if globalFlags.ConfigFile != "" {
+		configLayer.Source = config.SourceFlags
+		configLayer.File = globalFlags.ConfigFile
+	} else if fileExists(loader.DefaultConfigPath()) {
+		configLayer.File = loader.DefaultConfigPath()
+	}
+
+	appLayers := []config.Layer{
+		{Source: config.SourceDefaults, Value: config.NewDefaultConfig()},
+		configLayer,
+	}
+	container := GetContainer()
+	var project *config.ProjectConfig
+	if container != nil {
+		project, _ = container.ProjectConfig()
+	}
+	if project != nil && project.DefaultProfile != "" {
+		projectCfg := *ctx.Config
+		projectCfg.Routing.DefaultProfile = project.DefaultProfile
+		appLayers = append(appLayers, config.Layer{Source: config.SourceProject, File: project.Path, Value: &projectCfg})
+	}
+
+	appSettings, err := config.TraceSettings(appLayers...)
+	if err != nil {
+		return err
+	}
+
+	routingLayers := []config.Layer{
+		{Source: config.SourceDefaults, Value: config.NewRoutingConfiguration()},
+		{Source: configLayer.Source, File: configLayer.File, Value: &config.RoutingConfiguration{Profiles: ctx.Config.Routing.Profiles}},
+	}
+	if container != nil {
+		chainLayers, err := container.RoutingChain().Layers()
+		if err != nil {
+			return err
+		}
+		routingLayers = append(routingLayers, chainLayers...)
+	}
+
+	_, routingSettings, err := config.TraceRoutingSettings(routingLayers...)
+	if err != nil {
+		return err
+	}
+
+	if !effective {
+		appSettings = withoutDefaults(appSettings)
+		routingSettings = withoutDefaults(routingSettings)
+	}
+
+	if formatter.Format() == output.FormatJSON {
+		return formatter.JSON(configShowResult{Application: appSettings, Routing: routingSettings})
+	}
+
+	formatter.Header("Configuration")
+	printSettings(formatter, "Application", appSettings, origin)
+	printSettings(formatter, "Routing", routingSettings, origin)
+	return nil
+}
+
+func withoutDefaults(settings []config.Setting) []config.Setting {
+	return slices.DeleteFunc(settings, func(s config.Setting) bool {
+		return s.Source == config.SourceDefaults
+	})
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+func printSettings(formatter *output.Formatter, title string, settings []config.Setting, origin bool) {
+	formatter.SubHeader(title)
+	if len(settings) == 0 {
+		formatter.BulletItem("No values set; use --effective to include defaults")
+		formatter.Println("")
+		return
+	}
+
+	table := output.TableData{
+		Columns: []output.TableColumn{
+			{Header: "Setting", Width: 30, Align: output.AlignLeft},
+			{Header: "Value", Width: 20, Align: output.AlignLeft},
+		},
+		Rows: make([][]string, 0, len(settings)),
+	}
+	if origin {
+		table.Columns = append(table.Columns, output.TableColumn{Header: "Origin", Width: 10, Align: output.AlignLeft})
+	}
+
+	for _, s := range settings {
+		row := []string{s.Path, s.Value}
+		if origin {
+			layer := s.Source
+			if s.File != "" {
+				layer += " (" + s.File + ")"
+			}
+			row = append(row, layer)
+		}
+		table.Rows = append(table.Rows, row)
+	}
+	formatter.Table(table)
+	formatter.Println("")
+}
+
+// NewConfigDiffCmd creates the config diff command.
+func NewConfigDiffCmd() *cobra.Command {
+	var against string
+
+	cmd := &cobra.Command{
+		Use:   "diff",
+		Short: "Show how the routing configuration differs from defaults or another file",
+		Long: `Show a semantic diff of the effective routing configuration.
+
+The effective configuration is config.yaml with ~/.skillrunner/routing.yaml
+and the project's .skillrunner.yaml merged over it. It is compared with the
+built-in defaults, or with another routing file applied over the defaults,
+and reported as:
+  • Providers added, removed, enabled or disabled
+  • Models added, removed, or moved to a different tier
+  • Changed costs, limits and timeouts
+  • Profile and fallback chain changes
+
+Use it to review config edits before committing them.`,
+		Example: `  # What does my configuration change from the defaults?
+  sr config diff
+
+  # Compare with the committed routing file
+  sr config diff --against routing.yaml.orig
+
+  # As JSON
+  sr config diff -o json`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runConfigDiff(against)
+		},
+	}
+
+	cmd.Flags().StringVar(&against, "against", againstDefault, `"default" or a routing file to compare with`)
+
+	return cmd
+}
+
+func runConfigDiff(against string) error {
+	formatter := GetFormatter()
+	container := GetContainer()
+	if container == nil {
+		return fmt.Errorf("application not initialized")
+	}
+
+	base := config.NewRoutingConfiguration()
+	if against != againstDefault {
+		other, err := config.LoadRoutingConfig(against)
+		if err != nil {
+			return fmt.Errorf("failed to load %s: %w", against, err)
+		}
+		base = config.MergeRoutingConfigs(base, other)
+	}
+
+	changes := config.DiffRoutingConfigs(base, container.EffectiveRoutingConfiguration())
+
+	if formatter.Format() == output.FormatJSON {
+		return formatter.JSON(map[string]any{
+			"against": against,
+			"changes": changes,
+		})
+	}
+
+	printRoutingChanges(formatter, against, changes)
+	return nil
+}
+
+func printRoutingChanges(formatter *output.Formatter, against string, changes []config.RoutingChange) {
+	formatter.Header("Routing Configuration Diff")
+	formatter.Item("Against", against)
+	formatter.Item("Changes", fmt.Sprintf("%d", len(changes)))
+
+	if len(changes) == 0 {
+		formatter.Println("")
+		formatter.Info("No differences")
+		return
+	}
+
+	section := ""
+	for _, c := range changes {
+		if c.Section != section {
+			section = c.Section
+			formatter.Println("")
+			formatter.SubHeader(section)
+		}
+
+		switch c.Kind {
+		case config.ChangeAdded:
+			formatter.Println("  %s %s", formatter.Colorize("+", output.ColorGreen), c)
+		case config.ChangeRemoved:
+			formatter.Println("  %s %s", formatter.Colorize("-", output.ColorRed), c)
+		default:
+			formatter.Println("  %s %s", formatter.Colorize("~", output.ColorYellow), c)
+		}
+	}
+}
+
+// NewConfigEncryptCmd creates the config encrypt command.
+func NewConfigEncryptCmd() *cobra.Command {
+	var file string
+	var recipients []string
+
+	cmd := &cobra.Command{
+		Use:   "encrypt <provider>",
+		Short: "Store a provider's API key in routing.yaml, encrypted with age",
+		Long: `Encrypt a provider's API key with age and store it as the provider's
+api_key in routing.yaml, so the file can be committed or shared without
+exposing the key. The key is decrypted when the configuration is loaded.
+
+The key is read from standard input. It is encrypted to the --recipient
+public keys, or to the public keys noted in the age identity file
+(SOPS_AGE_KEY_FILE, or sops/age/keys.txt in the user configuration
+directory), which is also used to decrypt it. The age command must be
+installed.
+
+Files encrypted with sops are decrypted as a whole when loaded; use sops
+itself to edit them.`,
+		Example: `  # Encrypt to the key in your age identity file
+  sr config encrypt gateway
+
+  # Encrypt to a teammate as well
+  echo "$GATEWAY_KEY" | sr config encrypt gateway \
+    --recipient age1ql3z7hjy54pw3hyww5ayyfg7zqgvc7w3j2elw8zmrj2kg5sfn9aqmcac8p \
+    --recipient age1lggyhqrw2nlhcxprm67z43rta597azn8gknawjehu9d9dl0jq3yqqvfafg`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runConfigEncrypt(cmd.Context(), args[0], file, recipients)
+		},
+	}
+
+	cmd.Flags().StringVar(&file, "file", "", "routing file to update (default ~/.skillrunner/routing.yaml)")
+	cmd.Flags().StringArrayVar(&recipients, "recipient", nil, "age public key to encrypt to (repeatable)")
+
+	return cmd
+}
+
+func runConfigEncrypt(ctx context.Context, provider, file string, recipients []string) error {
+	formatter := GetFormatter()
+
+	if file == "" {
+		loader, err := config.NewLoader("")
+		if err != nil {
+			return fmt.Errorf("failed to create config loader: %w", err)
+		}
+		file = loader.DefaultRoutingConfigPath()
+	}
+
+	if len(recipients) == 0 {
+		var err error
+		if recipients, err = crypto.AgeRecipients(crypto.AgeIdentityFile()); err != nil {
+			return fmt.Errorf("%w; pass --recipient or create a key with age-keygen", err)
+		}
+	}
+
+	apiKey, err := newPrompter(formatter).promptSecret(provider + " API key")
+	if err != nil {
+		return err
+	}
+	if apiKey == "" {
+		return errors.New("no API key entered")
+	}
+
+	encrypted, err := crypto.AgeEncrypt(ctx, apiKey, recipients)
+	if err != nil {
+		return err
+	}
+	if err := config.SetProviderAPIKey(file, provider, encrypted); err != nil {
+		return err
+	}
+
+	if formatter.Format() == output.FormatJSON {
+		return formatter.JSON(map[string]any{
+			"provider":   provider,
+			"file":       file,
+			"recipients": recipients,
+		})
+	}
+	formatter.Success("Encrypted %s API key in %s for %d recipient(s)", provider, file, len(recipients))
+	return nil
+}
+
+// configSchemas are the JSON Schema documents printed by sr config schema.
+var configSchemas = map[string]func() *config.Schema{
+	"routing": config.RoutingSchema,
+	"project": config.ProjectSchema,
+	"skill":   infraSkills.Schema,
+}
+
+// NewConfigSchemaCmd creates the config schema command.
+func NewConfigSchemaCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "schema [routing|project|skill]",
+		Short: "Print the JSON Schema of routing, project or skill files",
+		Long: `Print the JSON Schema of routing.yaml (the default), .skillrunner.yaml
+or skill definition files.
+
+Files are checked against the same schemas when they are loaded, so unknown
+keys and values of the wrong type are reported with their line and column.
+Save a schema and point your editor's YAML language server at it to get
+completion and the same checks while editing.`,
+		Example: `  # Schema of routing.yaml
+  sr config schema > routing.schema.json
+
+  # Schema of skill files
+  sr config schema skill > skill.schema.json`,
+		Args:      cobra.MatchAll(cobra.MaximumNArgs(1), cobra.OnlyValidArgs),
+		ValidArgs: slices.Sorted(maps.Keys(configSchemas)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			kind := "routing"
+			if len(args) > 0 {
+				kind = args[0]
+			}
+			return runConfigSchema(GetFormatter(), kind)
+		},
+	}
+
+	return cmd
+}
+
+// runConfigSchema prints a schema as JSON whatever the output format.
+func runConfigSchema(formatter *output.Formatter, kind string) error {
+	schema, ok := configSchemas[kind]
+	if !ok {
+		return fmt.Errorf("unknown schema %q", kind)
+	}
+	return formatter.JSON(schema())
+}
+
+// doctorTimeout bounds the provider health checks of sr config doctor.
+const doctorTimeout = 30 * time.Second
+
+// NewConfigDoctorCmd creates the config doctor command.
+func NewConfigDoctorCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "doctor",
+		Short: "Check the configuration against the configured providers",
+		Long: `Load the merged configuration, ping every enabled provider and check that
+the generation, review and fallback models of every routing profile are
+served by a responding provider.
+
+Each problem is reported with the change that would fix it, such as setting
+a missing API key, pulling a model into Ollama, or switching a profile to
+the closest model that is served. The command fails if any check fails, so
+it can run in CI; a missing fallback model is only a warning.`,
+		Example: `  # Diagnose the configuration
+  sr config doctor
+
+  # As JSON
+  sr config doctor -o json`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runConfigDoctor(cmd.Context())
+		},
+	}
+}
+
+func runConfigDoctor(ctx context.Context) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	formatter := GetFormatter()
+	container := GetContainer()
+	if container == nil {
+		return fmt.Errorf("application not initialized")
+	}
+	initializer := container.ProviderInitializer()
+	if initializer == nil {
+		return fmt.Errorf("provider initializer not available")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, doctorTimeout)
+	defer cancel()
+	report := appProvider.Diagnose(container.EffectiveRoutingConfiguration(), initializer.CheckHealth(ctx))
+
+	if formatter.Format() == output.FormatJSON {
+		if err := formatter.JSON(report); err != nil {
+			return err
+		}
+	} else {
+		printDoctorReport(formatter, report)
+	}
+
+	if n := report.Count(appProvider.DoctorError); n > 0 {
+		return fmt.Errorf("%d configuration problem(s) found", n)
+	}
+	return nil
+}
+
+func printDoctorReport(formatter *output.Formatter, report *appProvider.DoctorReport) {
+	formatter.Header("Configuration Doctor")
+
+	subject := ""
+	for _, c := range report.Checks {
+		if c.Subject != subject {
+			subject = c.Subject
+			formatter.Println("")
+			formatter.SubHeader(subject)
+		}
+
+		switch c.Severity {
+		case appProvider.DoctorOK:
+			formatter.Success("%s", c.Message)
+		case appProvider.DoctorWarning:
+			formatter.Warning("%s", c.Message)
+		default:
+			formatter.Error("%s", c.Message)
+		}
+		if c.Fix != "" {
+			formatter.Println("    fix: %s", c.Fix)
+		}
+	}
+
+	formatter.Println("")
+	errs, warnings := report.Count(appProvider.DoctorError), report.Count(appProvider.DoctorWarning)
+	if errs == 0 && warnings == 0 {
+		formatter.Success("No problems found")
+		return
+	}
+	formatter.Info("%d error(s), %d warning(s)", errs, warnings)
+}
+
+// NewConfigRefreshCmd creates the config refresh command.
+func NewConfigRefreshCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "refresh",
+		Short: "Fetch shared routing sources again",
+		Long: `Fetch the shared routing sources listed under routing.sources in
+config.yaml and replace their cached copies.
+
+Sources are http(s) URLs or git repositories, written as
+git::<repository>//<path>?ref=<branch or tag>. They are merged under
+~/.skillrunner/routing.yaml, so a team can share a central model policy while
+each member keeps local overrides. A source is fetched the first time it is
+used and otherwise read from its cache until it is refreshed; long-running
+commands pick up refreshed sources when routing.hot_reload is on.
+
+A fetched file that does not parse keeps the previous cached copy.`,
+		Example: `  # In config.yaml:
+  #   routing:
+  #     sources:
+  #       - https://config.example.com/skillrunner/routing.yaml
+  #       - git::git@github.com:acme/ai-policy.git//routing.yaml?ref=main
+  sr config refresh`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runConfigRefresh(cmd.Context())
+		},
+	}
+}
+
+// configRefreshResult is the JSON form of one refreshed source.
+type configRefreshResult struct {
+	Source string `json:"source"`
+	Cache  string `json:"cache,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+func runConfigRefresh(ctx context.Context) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	formatter := GetFormatter()
+	appCtx := GetAppContext()
+	if appCtx == nil || appCtx.Config == nil {
+		return fmt.Errorf("application not initialized")
+	}
+
+	sources := appCtx.Config.Routing.Sources
+	results := make([]configRefreshResult, 0, len(sources))
+	failed := 0
+	for _, source := range sources {
+		result := configRefreshResult{Source: source}
+		if path, err := config.RefreshRemoteRouting(ctx, source); err != nil {
+			result.Error = err.Error()
+			failed++
+		} else {
+			result.Cache = path
+		}
+		results = append(results, result)
+	}
+
+	if formatter.Format() == output.FormatJSON {
+		if err := formatter.JSON(results); err != nil {
+			return err
+		}
+	} else if len(sources) == 0 {
+		formatter.Info("No shared routing sources configured; list them under routing.sources in config.yaml")
+	} else {
+		for _, r := range results {
+			if r.Error != "" {
+				formatter.Error("%s", r.Error)
+			} else {
+				formatter.Success("Refreshed %s", r.Source)
+			}
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d routing source(s) could not be refreshed", failed)
+	}
+	return nil
+}
@@ -0,0 +1,232 @@
+package commands
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/jbctechsolutions/skillrunner/internal/infrastructure/config"
+	"github.com/jbctechsolutions/skillrunner/internal/presentation/cli/output"
+)
+
+// NewConfigPolicyCmd creates the config policy command.
+func NewConfigPolicyCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "policy",
+		Short: "Show the organization policy in effect",
+		Long: `Show the organization policy in effect and where it came from.
+
+An organization policy is a signed bundle fetched at startup from the URL
+in /etc/skillrunner/policy.yaml, or in the policy section of config.yaml:
+
+  policy:
+    url: https://config.example.com/skillrunner/policy.json
+    public_key: <base64 Ed25519 public key>
+
+It restricts the providers skillrunner may use and their endpoints, caps
+the per-run budget, redacts patterns from prompts sent to cloud providers,
+and loads required skill directories. Local configuration cannot weaken it. The last verified
+bundle is cached and used when the URL cannot be reached.
+
+Administrators create a signing key with 'sr config policy keygen' and
+sign policy files with 'sr config policy sign'.`,
+		Example: `  # The policy in effect
+  sr config policy
+
+  # Publish a policy
+  sr config policy keygen --out policy.key
+  sr config policy sign policy.yaml --key policy.key --out policy.json`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runConfigPolicy()
+		},
+	}
+
+	cmd.AddCommand(newConfigPolicyKeygenCmd())
+	cmd.AddCommand(newConfigPolicySignCmd())
+
+	return cmd
+}
+
+// configPolicyResult is the JSON form of config policy.
+type configPolicyResult struct {
+	Status *config.PolicyStatus `json:"status,omitempty"`
+	Policy *config.Policy       `json:"policy,omitempty"`
+}
+
+func runConfigPolicy() error {
+	formatter := GetFormatter()
+	container := GetContainer()
+	if container == nil {
+		return fmt.Errorf("application not initialized")
+	}
+
+	policy, status := container.Policy()
+	if formatter.Format() == output.FormatJSON {
+		return formatter.JSON(configPolicyResult{Status: status, Policy: policy})
+	}
+
+	formatter.Header("Organization Policy")
+	if policy == nil {
+		formatter.Info("No organization policy is configured")
+		return nil
+	}
+
+	if policy.Name != "" {
+		formatter.Item("Name", policy.Name)
+	}
+	formatter.Item("URL", status.URL)
+	formatter.Item("Source", status.Source)
+	formatter.Item("Verified", status.VerifiedAt.Local().Format(time.RFC1123))
+	formatter.Item("Issued", policy.IssuedAt.Local().Format(time.RFC1123))
+	formatter.Item("Expires", policy.ExpiresAt.Local().Format(time.RFC1123))
+	if status.FetchError != "" {
+		formatter.Warning("Using the cached bundle: %s", status.FetchError)
+	}
+	formatter.Println("")
+
+	formatter.SubHeader("Rules")
+	allowed := "any"
+	if len(policy.AllowedProviders) > 0 {
+		allowed = strings.Join(policy.AllowedProviders, ", ")
+	}
+	formatter.Item("Allowed providers", allowed)
+	for _, name := range slices.Sorted(maps.Keys(policy.ProviderEndpoints)) {
+		formatter.Item("Endpoint "+name, policy.ProviderEndpoints[name])
+	}
+	if len(status.DeniedProviders) > 0 {
+		formatter.Item("Disabled providers", strings.Join(status.DeniedProviders, ", "))
+	}
+	if policy.Budget.MaxCostPerRun > 0 {
+		formatter.Item("Max cost per run", formatCost(policy.Budget.MaxCostPerRun))
+	}
+	for _, rule := range policy.Redaction {
+		formatter.Item("Redaction "+rule.Name, rule.Pattern)
+	}
+	for _, dir := range policy.RequiredSkillSources {
+		formatter.Item("Required skills", dir)
+	}
+	return nil
+}
+
+func newConfigPolicyKeygenCmd() *cobra.Command {
+	var out string
+
+	cmd := &cobra.Command{
+		Use:   "keygen",
+		Short: "Create a key pair for signing policy bundles",
+		Long: `Create an Ed25519 key pair for signing organization policy bundles.
+The private key is written to --out, readable only by you; keep it out of
+version control. The public key is printed for the public_key setting.`,
+		Example: `  sr config policy keygen --out policy.key`,
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runConfigPolicyKeygen(out)
+		},
+	}
+
+	cmd.Flags().StringVar(&out, "out", "", "file to write the private key to (required)")
+	_ = cmd.MarkFlagRequired("out")
+
+	return cmd
+}
+
+func runConfigPolicyKeygen(out string) error {
+	formatter := GetFormatter()
+
+	if _, err := os.Stat(out); err == nil {
+		return fmt.Errorf("%s already exists", out)
+	}
+	publicKey, privateKey, err := config.GeneratePolicyKey()
+	if err != nil {
+		return fmt.Errorf("failed to generate key: %w", err)
+	}
+	if err := os.WriteFile(out, []byte(privateKey+"\n"), 0o600); err != nil {
+		return fmt.Errorf("failed to write private key: %w", err)
+	}
+
+	if formatter.Format() == output.FormatJSON {
+		return formatter.JSON(map[string]string{"private_key_file": out, "public_key": publicKey})
+	}
+	formatter.Success("Wrote the private key to %s", out)
+	formatter.Item("public_key", publicKey)
+	return nil
+}
+
+func newConfigPolicySignCmd() *cobra.Command {
+	var keyFile, out string
+	var validFor time.Duration
+
+	cmd := &cobra.Command{
+		Use:   "sign <policy.yaml>",
+		Short: "Sign a policy file into a bundle",
+		Long: `Validate a policy YAML file and sign it into a bundle for publishing at
+the policy URL. The bundle is written to --out, or to standard output.
+
+The bundle records when it was signed and expires after --valid-for.
+Installations refuse an expired bundle, and a bundle signed before the one
+they have cached, so publish a freshly signed bundle before it expires.
+
+A policy file looks like:
+
+  name: acme-engineering
+  allowed_providers: [ollama, anthropic, gateway]
+  provider_endpoints:
+    gateway: https://llm.acme.example/v1
+  budget:
+    max_cost_per_run: 2.00
+  redaction:
+    - name: customer-id
+      pattern: 'CUST-\d{6}'
+  required_skill_sources:
+    - /opt/acme/skillrunner/skills`,
+		Example: `  sr config policy sign policy.yaml --key policy.key --out policy.json
+
+  # A bundle valid for 30 days
+  sr config policy sign policy.yaml --key policy.key --valid-for 720h --out policy.json`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runConfigPolicySign(args[0], keyFile, out, validFor)
+		},
+	}
+
+	cmd.Flags().StringVar(&keyFile, "key", "", "private key file from sr config policy keygen (required)")
+	cmd.Flags().StringVar(&out, "out", "", "file to write the bundle to (default: standard output)")
+	cmd.Flags().DurationVar(&validFor, "valid-for", config.DefaultPolicyValidity, "how long the bundle stays valid")
+	_ = cmd.MarkFlagRequired("key")
+
+	return cmd
+}
+
+func runConfigPolicySign(policyFile, keyFile, out string, validFor time.Duration) error {
+	formatter := GetFormatter()
+
+	policyYAML, err := os.ReadFile(policyFile)
+	if err != nil {
+		return fmt.Errorf("failed to read policy: %w", err)
+	}
+	privateKey, err := os.ReadFile(keyFile)
+	if err != nil {
+		return fmt.Errorf("failed to read signing key: %w", err)
+	}
+
+	bundle, err := config.SignPolicy(policyYAML, string(privateKey), time.Now(), validFor)
+	if err != nil {
+		return err
+	}
+
+	if out == "" {
+		_, err := os.Stdout.Write(append(bundle, '\n'))
+		return err
+	}
+	if err := os.WriteFile(out, append(bundle, '\n'), 0o644); err != nil {
+		return fmt.Errorf("failed to write bundle: %w", err)
+	}
+	formatter.Success("Signed %s into %s", policyFile, out)
+	return nil
+}
@@ -0,0 +1,170 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/jbctechsolutions/skillrunner/internal/domain/provider"
+	"github.com/jbctechsolutions/skillrunner/internal/presentation/cli/output"
+)
+
+// costReportJSON is the JSON output of sr cost report.
+type costReportJSON struct {
+	Since        time.Time            `json:"since"`
+	GroupBy      string               `json:"group_by"`
+	TotalCost    float64              `json:"total_cost"`
+	InputTokens  int                  `json:"input_tokens"`
+	OutputTokens int                  `json:"output_tokens"`
+	Groups       []provider.CostGroup `json:"groups"`
+}
+
+// NewCostCmd creates the cost command.
+func NewCostCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "cost",
+		Short: "Report on model spending",
+		Long: `Report on what model invocations have cost.
+
+Every run records the cost of each phase that called a model in a cost ledger
+kept in the storage backend, with the model, provider, token counts, skill,
+execution ID and the run's --tag cost attribution tags. Phases served from
+cache cost nothing and are not recorded.`,
+	}
+
+	cmd.AddCommand(NewCostReportCmd())
+
+	return cmd
+}
+
+// NewCostReportCmd creates the cost report command.
+func NewCostReportCmd() *cobra.Command {
+	var since, by string
+
+	cmd := &cobra.Command{
+		Use:   "report",
+		Short: "Summarize recorded costs by provider, skill, model or tag",
+		Long: `Summarize the cost ledger over a time range, grouped by provider, skill,
+model or the value of a cost attribution tag (--by tag:<name>), most expensive
+first. Runs without the tag are reported as untagged.`,
+		Example: `  # Spending per provider over the last week
+  sr cost report
+
+  # Spending per skill over the last month
+  sr cost report --since 30d --by skill
+
+  # Spending per team, from runs tagged with --tag team=<name>
+  sr cost report --since 30d --by tag:team
+
+  # As JSON
+  sr cost report --by model -o json`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runCostReport(cmd.Context(), since, by)
+		},
+	}
+
+	cmd.Flags().StringVar(&since, "since", "7d", "time range to report on (e.g., 24h, 7d, 30d)")
+	cmd.Flags().StringVar(&by, "by", string(provider.CostByProvider), "group costs by: provider, skill, model, tag:<name>")
+
+	return cmd
+}
+
+func runCostReport(ctx context.Context, since, by string) error {
+	duration, err := parseDuration(since)
+	if err != nil {
+		return fmt.Errorf("invalid time range: %w", err)
+	}
+	groupBy, err := provider.ParseCostGroupBy(by)
+	if err != nil {
+		return err
+	}
+
+	formatter := GetFormatter()
+	container := GetContainer()
+	if container == nil {
+		return errors.New("application not initialized")
+	}
+	ledger := container.CostLedgerRepository()
+	if ledger == nil {
+		return errors.New("cost ledger not available")
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	start := time.Now().Add(-duration)
+	groups, err := ledger.Summarize(ctx, start, groupBy)
+	if err != nil {
+		return fmt.Errorf("failed to read cost ledger: %w", err)
+	}
+
+	report := costReportJSON{Since: start.UTC(), GroupBy: string(groupBy), Groups: groups}
+	for _, g := range groups {
+		report.TotalCost += g.TotalCost
+		report.InputTokens += g.InputTokens
+		report.OutputTokens += g.OutputTokens
+	}
+
+	if formatter.Format() == output.FormatJSON {
+		return formatter.JSON(report)
+	}
+
+	formatter.Header(fmt.Sprintf("Costs by %s (last %s)", groupBy, since))
+	if len(groups) == 0 {
+		formatter.Info("No costs recorded in this period")
+		return nil
+	}
+
+	table := output.TableData{
+		Columns: []output.TableColumn{
+			{Header: costGroupHeader(groupBy), Width: 36, Align: output.AlignLeft},
+			{Header: "Calls", Width: 6, Align: output.AlignRight},
+			{Header: "Input", Width: 10, Align: output.AlignRight},
+			{Header: "Output", Width: 10, Align: output.AlignRight},
+			{Header: "Cost", Width: 10, Align: output.AlignRight},
+		},
+	}
+	_, byTag := groupBy.Tag()
+	for _, g := range groups {
+		key := g.Key
+		switch {
+		case key == "" && byTag:
+			key = "(untagged)"
+		case key == "":
+			key = "(unknown)"
+		}
+		table.Rows = append(table.Rows, []string{
+			truncateString(key, 36),
+			fmt.Sprintf("%d", g.Invocations),
+			fmt.Sprintf("%d", g.InputTokens),
+			fmt.Sprintf("%d", g.OutputTokens),
+			formatCost(g.TotalCost),
+		})
+	}
+	if err := formatter.Table(table); err != nil {
+		return err
+	}
+
+	formatter.Println("")
+	formatter.Item("Total", formatCost(report.TotalCost))
+	return nil
+}
+
+// costGroupHeader returns the table header of a cost grouping.
+func costGroupHeader(by provider.CostGroupBy) string {
+	if tag, ok := by.Tag(); ok {
+		return "Tag " + tag
+	}
+	switch by {
+	case provider.CostBySkill:
+		return "Skill"
+	case provider.CostByModel:
+		return "Model"
+	default:
+		return "Provider"
+	}
+}
@@ -0,0 +1,338 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/jbctechsolutions/skillrunner/internal/application/ports"
+	"github.com/jbctechsolutions/skillrunner/internal/domain/provider"
+	"github.com/jbctechsolutions/skillrunner/internal/domain/skill"
+	domainWorkflow "github.com/jbctechsolutions/skillrunner/internal/domain/workflow"
+	"github.com/jbctechsolutions/skillrunner/internal/presentation/cli/output"
+)
+
+// latestRunRef selects the most recent run in history commands.
+const latestRunRef = "latest"
+
+// NewHistoryCmd creates the history command for inspecting past runs.
+func NewHistoryCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "history",
+		Short: "Inspect past skill runs",
+		Long: `Inspect past skill runs recorded by 'sr run'.
+
+Runs are identified by their checkpoint ID or execution ID, or by "latest"
+for the most recent run.`,
+	}
+
+	cmd.AddCommand(NewHistoryListCmd())
+	cmd.AddCommand(NewHistoryAnalyzeCmd())
+	cmd.AddCommand(NewHistoryAnnotateCmd())
+	cmd.AddCommand(NewHistoryPinCmd())
+	cmd.AddCommand(NewHistoryUnpinCmd())
+	cmd.AddCommand(NewHistoryRerunCmd())
+	cmd.AddCommand(NewHistoryStatsCmd())
+
+	return cmd
+}
+
+// NewHistoryAnalyzeCmd creates the history analyze command.
+func NewHistoryAnalyzeCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "analyze <run>",
+		Short: "Find the critical path and bottlenecks of a run",
+		Long: `Analyze a past run's DAG execution.
+
+Reports:
+  • The critical path: the dependency chain that bounds wall time
+  • Per-phase wait vs execute time
+  • Suggestions for which phases to parallelize, cache, or move to a
+    cheaper routing profile for the biggest time and cost wins`,
+		Example: `  # Analyze the most recent run
+  sr history analyze latest
+
+  # Analyze a specific run as JSON
+  sr history analyze 3f2a9c1e-... -o json`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runHistoryAnalyze(cmd.Context(), args[0])
+		},
+	}
+
+	return cmd
+}
+
+// historyAnalysisJSON is the JSON representation of a run analysis.
+type historyAnalysisJSON struct {
+	RunID          string                    `json:"run_id"`
+	ExecutionID    string                    `json:"execution_id"`
+	Skill          string                    `json:"skill"`
+	Status         string                    `json:"status"`
+	WallTimeMs     int64                     `json:"wall_time_ms"`
+	CriticalPath   []string                  `json:"critical_path"`
+	CriticalPathMs int64                     `json:"critical_path_ms"`
+	TotalWaitMs    int64                     `json:"total_wait_ms"`
+	TotalCost      float64                   `json:"total_cost"`
+	Phases         []historyPhaseJSON        `json:"phases"`
+	Suggestions    []historySuggestionRow    `json:"suggestions"`
+	Notes          []*domainWorkflow.RunNote `json:"notes"`
+}
+
+type historyPhaseJSON struct {
+	ID        string  `json:"id"`
+	Batch     int     `json:"batch"`
+	WaitMs    int64   `json:"wait_ms"`
+	SlotMs    int64   `json:"slot_wait_ms"`
+	ExecuteMs int64   `json:"execute_ms"`
+	Critical  bool    `json:"critical"`
+	Cost      float64 `json:"cost"`
+}
+
+type historySuggestionRow struct {
+	Kind    string `json:"kind"`
+	Phase   string `json:"phase"`
+	Message string `json:"message"`
+}
+
+func runHistoryAnalyze(ctx context.Context, ref string) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	formatter := GetFormatter()
+	repo, notesRepo, err := historyRepositories()
+	if err != nil {
+		return err
+	}
+	container := GetContainer()
+
+	cp, err := findRun(ctx, repo, ref)
+	if err != nil {
+		return err
+	}
+	notes, err := notesRepo.List(ctx, cp.ID())
+	if err != nil {
+		return err
+	}
+
+	var sk *skill.Skill
+	if registry := container.SkillRegistry(); registry != nil {
+		sk = registry.GetSkill(cp.SkillID())
+	}
+
+	start, timings := runTimings(cp, sk, container.CostCalculator())
+	analysis, err := domainWorkflow.AnalyzeRun(start, timings)
+	if err != nil {
+		return fmt.Errorf("failed to analyze run %s: %w", cp.ID(), err)
+	}
+
+	if formatter.Format() == output.FormatJSON {
+		result := toHistoryAnalysisJSON(cp, analysis)
+		result.Notes = notes
+		return formatter.JSON(result)
+	}
+
+	printHistoryAnalysis(formatter, cp, analysis, sk == nil)
+	printRunNotes(formatter, notes)
+	return nil
+}
+
+// findRun resolves a run reference to its checkpoint, with its phase
+// outputs, and warns when they were archived and cannot be read back.
+func findRun(ctx context.Context, repo ports.WorkflowCheckpointPort, ref string) (*domainWorkflow.WorkflowCheckpoint, error) {
+	id := ref
+	if ref == latestRunRef {
+		runs, err := repo.List(ctx, &ports.WorkflowCheckpointFilter{Limit: 1})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list runs: %w", err)
+		}
+		if len(runs) == 0 {
+			return nil, fmt.Errorf("no runs recorded yet")
+		}
+		id = runs[0].ID()
+	}
+
+	cp, err := repo.Get(ctx, id)
+	if err != nil {
+		runs, err := repo.GetByExecutionID(ctx, ref)
+		if err != nil {
+			return nil, fmt.Errorf("failed to look up run %s: %w", ref, err)
+		}
+		if len(runs) == 0 {
+			return nil, fmt.Errorf("run not found: %s", ref)
+		}
+		if cp, err = repo.Get(ctx, runs[0].ID()); err != nil {
+			return nil, fmt.Errorf("failed to read run %s: %w", ref, err)
+		}
+	}
+
+	if err := cp.ArchiveError(); err != nil {
+		warnRun(GetFormatter(), fmt.Sprintf("run %s: phase outputs are unavailable: %v", cp.ID(), err))
+	}
+	return cp, nil
+}
+
+// runTimings converts a checkpoint's phase results into analysis input.
+// Dependencies and routing profiles come from the skill definition; when the
+// skill is no longer installed, every phase is assumed to depend on all
+// phases of the previous batch.
+func runTimings(cp *domainWorkflow.WorkflowCheckpoint, sk *skill.Skill, costCalc *provider.CostCalculator) (time.Time, []domainWorkflow.PhaseTiming) {
+	start := cp.CreatedAt()
+	results := cp.PhaseResults()
+
+	byBatch := make(map[int][]string)
+	for id, data := range results {
+		byBatch[data.Batch] = append(byBatch[data.Batch], id)
+	}
+
+	timings := make([]domainWorkflow.PhaseTiming, 0, len(results))
+	for id, data := range results {
+		if data.StartTime == 0 || data.EndTime == 0 {
+			continue
+		}
+
+		t := domainWorkflow.PhaseTiming{
+			PhaseID:      id,
+			PhaseName:    data.PhaseName,
+			Model:        data.ModelUsed,
+			Batch:        data.Batch,
+			StartTime:    time.Unix(0, data.StartTime),
+			EndTime:      time.Unix(0, data.EndTime),
+			InputTokens:  data.InputTokens,
+			OutputTokens: data.OutputTokens,
+			CacheHit:     data.CacheHit,
+		}
+		if data.QueuedAt != 0 {
+			t.QueuedAt = time.Unix(0, data.QueuedAt)
+		}
+
+		if phase := findPhase(sk, id); phase != nil {
+			t.DependsOn = phase.DependsOn
+			t.RoutingProfile = phase.RoutingProfile
+		} else {
+			t.DependsOn = byBatch[data.Batch-1]
+		}
+
+		if costCalc != nil {
+			t.Cost = costCalc.CalculateOrZero(data.ModelUsed, data.InputTokens, data.OutputTokens).TotalCost
+		}
+
+		earliest := t.StartTime
+		if !t.QueuedAt.IsZero() && t.QueuedAt.Before(earliest) {
+			earliest = t.QueuedAt
+		}
+		if earliest.Before(start) {
+			start = earliest
+		}
+
+		timings = append(timings, t)
+	}
+
+	return start, timings
+}
+
+// findPhase returns the phase with the given ID, or nil.
+func findPhase(sk *skill.Skill, id string) *skill.Phase {
+	if sk == nil {
+		return nil
+	}
+	phase, err := sk.GetPhase(id)
+	if err != nil {
+		return nil
+	}
+	return phase
+}
+
+func toHistoryAnalysisJSON(cp *domainWorkflow.WorkflowCheckpoint, a *domainWorkflow.RunAnalysis) historyAnalysisJSON {
+	result := historyAnalysisJSON{
+		RunID:          cp.ID(),
+		ExecutionID:    cp.ExecutionID(),
+		Skill:          cp.SkillName(),
+		Status:         string(cp.Status()),
+		WallTimeMs:     a.WallTime.Milliseconds(),
+		CriticalPath:   a.CriticalPath,
+		CriticalPathMs: a.CriticalPathDuration.Milliseconds(),
+		TotalWaitMs:    a.TotalWait.Milliseconds(),
+		TotalCost:      a.TotalCost,
+		Phases:         make([]historyPhaseJSON, 0, len(a.Phases)),
+		Suggestions:    make([]historySuggestionRow, 0, len(a.Suggestions)),
+	}
+
+	for _, p := range a.Phases {
+		result.Phases = append(result.Phases, historyPhaseJSON{
+			ID:        p.PhaseID,
+			Batch:     p.Batch,
+			WaitMs:    p.Wait.Milliseconds(),
+			SlotMs:    p.SlotWait.Milliseconds(),
+			ExecuteMs: p.Execute.Milliseconds(),
+			Critical:  p.Critical,
+			Cost:      p.Cost,
+		})
+	}
+
+	for _, s := range a.Suggestions {
+		result.Suggestions = append(result.Suggestions, historySuggestionRow{
+			Kind:    string(s.Kind),
+			Phase:   s.PhaseID,
+			Message: s.Message,
+		})
+	}
+
+	return result
+}
+
+func printHistoryAnalysis(formatter *output.Formatter, cp *domainWorkflow.WorkflowCheckpoint, a *domainWorkflow.RunAnalysis, inferredDeps bool) {
+	formatter.Header("Run Analysis")
+	formatter.Item("Run", cp.ID())
+	formatter.Item("Skill", cp.SkillName())
+	formatter.Item("Status", string(cp.Status()))
+	formatter.Item("Wall Time", formatDuration(a.WallTime))
+	formatter.Item("Critical Path", fmt.Sprintf("%s (%s)", strings.Join(a.CriticalPath, " → "), formatDuration(a.CriticalPathDuration)))
+	formatter.Item("Total Cost", formatCost(a.TotalCost))
+	if inferredDeps {
+		formatter.Warning("Skill %s is not installed; dependencies were inferred from batches", cp.SkillID())
+	}
+	formatter.Println("")
+
+	formatter.SubHeader("Phases")
+	table := output.TableData{
+		Columns: []output.TableColumn{
+			{Header: "Phase", Width: 20, Align: output.AlignLeft},
+			{Header: "Batch", Width: 5, Align: output.AlignRight},
+			{Header: "Wait", Width: 8, Align: output.AlignRight},
+			{Header: "Execute", Width: 8, Align: output.AlignRight},
+			{Header: "Cost", Width: 10, Align: output.AlignRight},
+			{Header: "Critical", Width: 8, Align: output.AlignCenter},
+		},
+		Rows: make([][]string, 0, len(a.Phases)),
+	}
+	for _, p := range a.Phases {
+		critical := ""
+		if p.Critical {
+			critical = "●"
+		}
+		table.Rows = append(table.Rows, []string{
+			p.PhaseID,
+			fmt.Sprintf("%d", p.Batch),
+			formatDuration(p.Wait),
+			formatDuration(p.Execute),
+			formatCost(p.Cost),
+			critical,
+		})
+	}
+	formatter.Table(table)
+	formatter.Println("")
+
+	formatter.SubHeader("Suggestions")
+	if len(a.Suggestions) == 0 {
+		formatter.BulletItem("No significant bottlenecks found")
+		return
+	}
+	for _, s := range a.Suggestions {
+		formatter.BulletItem(fmt.Sprintf("[%s] %s", s.Kind, s.Message))
+	}
+}
@@ -0,0 +1,235 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/jbctechsolutions/skillrunner/internal/application/ports"
+	domainWorkflow "github.com/jbctechsolutions/skillrunner/internal/domain/workflow"
+	"github.com/jbctechsolutions/skillrunner/internal/presentation/cli/output"
+)
+
+// defaultHistoryLimit is how many runs sr history list shows by default.
+const defaultHistoryLimit = 20
+
+// NewHistoryAnnotateCmd creates the history annotate command.
+func NewHistoryAnnotateCmd() *cobra.Command {
+	var message string
+
+	cmd := &cobra.Command{
+		Use:   "annotate <run>",
+		Short: "Attach a note to a past run",
+		Long: `Attach a free-form note to a past run, such as what changed in the
+prompt or how the output compared with the previous version.
+
+A run can have any number of notes. They are shown by 'sr history list'
+and 'sr history analyze'.`,
+		Example: `  # Note a regression on the most recent run
+  sr history annotate latest -m "this prompt version regressed"
+
+  # Annotate a specific run
+  sr history annotate 3f2a9c1e-... -m "baseline before splitting the review phase"`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runHistoryAnnotate(cmd.Context(), args[0], message)
+		},
+	}
+
+	cmd.Flags().StringVarP(&message, "message", "m", "", "note text (required)")
+	_ = cmd.MarkFlagRequired("message")
+
+	return cmd
+}
+
+func runHistoryAnnotate(ctx context.Context, ref, message string) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	formatter := GetFormatter()
+	runs, notes, err := historyRepositories()
+	if err != nil {
+		return err
+	}
+
+	cp, err := findRun(ctx, runs, ref)
+	if err != nil {
+		return err
+	}
+
+	note, err := domainWorkflow.NewRunNote(cp.ID(), message)
+	if err != nil {
+		return err
+	}
+	if err := notes.Add(ctx, note); err != nil {
+		return err
+	}
+
+	if formatter.Format() == output.FormatJSON {
+		return formatter.JSON(note)
+	}
+	formatter.Success("Annotated run %s (%s)", cp.ID(), cp.SkillName())
+	return nil
+}
+
+// NewHistoryListCmd creates the history list command.
+func NewHistoryListCmd() *cobra.Command {
+	var skillID string
+	var limit int
+
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List recent runs with their notes",
+		Long: `List recent skill runs, most recent first, with the notes attached
+to them by 'sr history annotate'.`,
+		Example: `  # Recent runs
+  sr history list
+
+  # The last 5 runs of one skill, as JSON
+  sr history list --skill code-review --limit 5 -o json`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runHistoryList(cmd.Context(), skillID, limit)
+		},
+	}
+
+	cmd.Flags().StringVar(&skillID, "skill", "", "only list runs of this skill")
+	cmd.Flags().IntVar(&limit, "limit", defaultHistoryLimit, "maximum number of runs to list (0 for all)")
+
+	return cmd
+}
+
+// historyRunJSON is the JSON representation of a run in sr history list.
+type historyRunJSON struct {
+	RunID       string                    `json:"run_id"`
+	ExecutionID string                    `json:"execution_id"`
+	Skill       string                    `json:"skill"`
+	Status      string                    `json:"status"`
+	Pinned      bool                      `json:"pinned"`
+	Tags        map[string]string         `json:"tags,omitempty"`
+	CreatedAt   time.Time                 `json:"created_at"`
+	UpdatedAt   time.Time                 `json:"updated_at"`
+	Notes       []*domainWorkflow.RunNote `json:"notes"`
+}
+
+func runHistoryList(ctx context.Context, skillID string, limit int) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	formatter := GetFormatter()
+	runs, notes, err := historyRepositories()
+	if err != nil {
+		return err
+	}
+
+	checkpoints, err := runs.List(ctx, &ports.WorkflowCheckpointFilter{SkillID: skillID, Limit: limit})
+	if err != nil {
+		return fmt.Errorf("failed to list runs: %w", err)
+	}
+
+	rows := make([]historyRunJSON, 0, len(checkpoints))
+	for _, cp := range checkpoints {
+		runNotes, err := notes.List(ctx, cp.ID())
+		if err != nil {
+			return err
+		}
+		rows = append(rows, historyRunJSON{
+			RunID:       cp.ID(),
+			ExecutionID: cp.ExecutionID(),
+			Skill:       cp.SkillID(),
+			Status:      string(cp.Status()),
+			Pinned:      cp.Pinned(),
+			Tags:        cp.Tags(),
+			CreatedAt:   cp.CreatedAt(),
+			UpdatedAt:   cp.UpdatedAt(),
+			Notes:       runNotes,
+		})
+	}
+
+	if formatter.Format() == output.FormatJSON {
+		return formatter.JSON(rows)
+	}
+
+	printHistoryList(formatter, rows)
+	return nil
+}
+
+func printHistoryList(formatter *output.Formatter, rows []historyRunJSON) {
+	formatter.Header("Run History")
+	if len(rows) == 0 {
+		formatter.Info("No runs recorded yet")
+		return
+	}
+
+	table := output.TableData{
+		Columns: []output.TableColumn{
+			{Header: "Run", Width: 36, Align: output.AlignLeft},
+			{Header: "Skill", Width: 20, Align: output.AlignLeft},
+			{Header: "Status", Width: 11, Align: output.AlignLeft},
+			{Header: "Started", Width: 10, Align: output.AlignLeft},
+			{Header: "Pin", Width: 3, Align: output.AlignLeft},
+			{Header: "Note", Width: 40, Align: output.AlignLeft},
+		},
+		Rows: make([][]string, 0, len(rows)),
+	}
+	for _, r := range rows {
+		pin := ""
+		if r.Pinned {
+			pin = "*"
+		}
+		table.Rows = append(table.Rows, []string{
+			r.RunID,
+			r.Skill,
+			r.Status,
+			formatRelativeTime(r.CreatedAt),
+			pin,
+			truncateString(latestNote(r.Notes), 40),
+		})
+	}
+	formatter.Table(table)
+}
+
+// latestNote summarizes a run's notes for a table cell: the most recent one,
+// and how many more there are.
+func latestNote(notes []*domainWorkflow.RunNote) string {
+	if len(notes) == 0 {
+		return ""
+	}
+	text := strings.Join(strings.Fields(notes[len(notes)-1].Text), " ")
+	if len(notes) > 1 {
+		text = fmt.Sprintf("%s (+%d)", text, len(notes)-1)
+	}
+	return text
+}
+
+// printRunNotes prints a run's notes, oldest first.
+func printRunNotes(formatter *output.Formatter, notes []*domainWorkflow.RunNote) {
+	if len(notes) == 0 {
+		return
+	}
+	formatter.Println("")
+	formatter.SubHeader("Notes")
+	for _, n := range notes {
+		formatter.BulletItem(fmt.Sprintf("%s  %s", n.CreatedAt.Local().Format("2006-01-02 15:04"), n.Text))
+	}
+}
+
+// historyRepositories returns the stores of run history and run notes.
+func historyRepositories() (ports.WorkflowCheckpointPort, ports.RunNotePort, error) {
+	container := GetContainer()
+	if container == nil {
+		return nil, nil, errors.New("application not initialized")
+	}
+
+	runs, notes := container.WorkflowCheckpointRepository(), container.RunNoteRepository()
+	if runs == nil || notes == nil {
+		return nil, nil, errors.New("run history not available")
+	}
+	return runs, notes, nil
+}
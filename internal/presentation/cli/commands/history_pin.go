@@ -0,0 +1,82 @@
+package commands
+
+import (
+	"context"
+
+	"github.com/spf13/cobra"
+
+	"github.com/jbctechsolutions/skillrunner/internal/presentation/cli/output"
+)
+
+// NewHistoryPinCmd creates the history pin command.
+func NewHistoryPinCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "pin <run>",
+		Short: "Pin a run as a golden run",
+		Long: `Pin a past run as a golden run.
+
+Pinned runs are never deleted by checkpoint retention or cleanup, and their
+phase outputs can be used as the regression baseline of
+'sr skill test --against-golden'.`,
+		Example: `  # Keep the most recent run as the baseline for its skill
+  sr history pin latest
+
+  # Compare a later version of the skill against it
+  sr skill test code-review --against-golden 3f2a9c1e-...`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runHistoryPin(cmd.Context(), args[0], true)
+		},
+	}
+}
+
+// NewHistoryUnpinCmd creates the history unpin command.
+func NewHistoryUnpinCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:     "unpin <run>",
+		Short:   "Unpin a golden run",
+		Long:    `Unpin a golden run so that checkpoint retention may delete it again.`,
+		Example: `  sr history unpin 3f2a9c1e-...`,
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runHistoryPin(cmd.Context(), args[0], false)
+		},
+	}
+}
+
+// historyPinJSON is the JSON output of sr history pin and unpin.
+type historyPinJSON struct {
+	RunID  string `json:"run_id"`
+	Skill  string `json:"skill"`
+	Pinned bool   `json:"pinned"`
+}
+
+func runHistoryPin(ctx context.Context, ref string, pinned bool) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	formatter := GetFormatter()
+	runs, _, err := historyRepositories()
+	if err != nil {
+		return err
+	}
+
+	cp, err := findRun(ctx, runs, ref)
+	if err != nil {
+		return err
+	}
+	if err := runs.SetPinned(ctx, cp.ID(), pinned); err != nil {
+		return err
+	}
+
+	if formatter.Format() == output.FormatJSON {
+		return formatter.JSON(historyPinJSON{RunID: cp.ID(), Skill: cp.SkillID(), Pinned: pinned})
+	}
+	if pinned {
+		formatter.Success("Pinned run %s (%s) as a golden run", cp.ID(), cp.SkillName())
+	} else {
+		formatter.Success("Unpinned run %s (%s)", cp.ID(), cp.SkillName())
+	}
+	return nil
+}
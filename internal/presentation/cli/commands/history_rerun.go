@@ -0,0 +1,376 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/jbctechsolutions/skillrunner/internal/application/ports"
+	appProvider "github.com/jbctechsolutions/skillrunner/internal/application/provider"
+	appSkills "github.com/jbctechsolutions/skillrunner/internal/application/skills"
+	"github.com/jbctechsolutions/skillrunner/internal/application/workflow"
+	"github.com/jbctechsolutions/skillrunner/internal/domain/provider"
+	"github.com/jbctechsolutions/skillrunner/internal/domain/skill"
+	domainWorkflow "github.com/jbctechsolutions/skillrunner/internal/domain/workflow"
+	"github.com/jbctechsolutions/skillrunner/internal/presentation/cli/output"
+)
+
+// Defaults of sr history rerun.
+const (
+	defaultRerunMax      = 50
+	defaultRerunInterval = 2 * time.Second
+
+	// rerunThrottlePoll is how often a throttled provider is checked
+	// again before the next rerun starts.
+	rerunThrottlePoll = time.Second
+)
+
+// historyRerunOptions holds the flags of sr history rerun.
+type historyRerunOptions struct {
+	Status   string
+	Since    string
+	Max      int
+	Skill    string
+	Profile  string
+	Interval time.Duration
+}
+
+// NewHistoryRerunCmd creates the history rerun command.
+func NewHistoryRerunCmd() *cobra.Command {
+	opts := historyRerunOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "rerun",
+		Short: "Rerun past failed runs under the current configuration",
+		Long: `Rerun past runs with their original input under the current skills,
+routing and providers, then compare each run's outcome before and after.
+
+Use it after fixing a provider outage, a misconfigured model or a broken
+prompt to find out which failures are resolved. Runs are rerun one at a
+time, oldest first, at most one every --interval. A run does not start while
+its provider is throttled by its rate limits (see rate_limits in
+routing.yaml), so a large rerun does not exhaust a provider's quota.
+
+Reruns are recorded in run history like any other run. Runs of skills that
+no longer exist are skipped.`,
+		Example: `  # Rerun up to 50 runs that failed in the last day
+  sr history rerun --status failed --since 24h --max 50
+
+  # Rerun the failures of one skill from the last week on the cheap profile
+  sr history rerun --skill code-review --since 7d --profile cheap
+
+  # Slow down for a provider with a low quota, and report as JSON
+  sr history rerun --interval 30s -o json`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runHistoryRerun(cmd.Context(), opts)
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.Status, "status", string(domainWorkflow.CheckpointStatusFailed), "status of the runs to rerun (failed, abandoned, in_progress, completed)")
+	cmd.Flags().StringVar(&opts.Since, "since", "24h", "only rerun runs started within this time (e.g., 24h, 7d)")
+	cmd.Flags().IntVar(&opts.Max, "max", defaultRerunMax, "maximum number of runs to rerun")
+	cmd.Flags().StringVar(&opts.Skill, "skill", "", "only rerun runs of this skill")
+	cmd.Flags().StringVarP(&opts.Profile, "profile", "p", skill.ProfileBalanced, "routing profile for the reruns")
+	cmd.Flags().DurationVar(&opts.Interval, "interval", defaultRerunInterval, "minimum time between the starts of two reruns")
+
+	return cmd
+}
+
+// Outcomes of a rerun.
+const (
+	rerunOutcomeFixed    = "fixed"
+	rerunOutcomeFailed   = "still_failing"
+	rerunOutcomeSkipped  = "skipped"
+	rerunOutcomeCanceled = "canceled"
+)
+
+// historyRerunJSON is the before/after record of one rerun.
+type historyRerunJSON struct {
+	RunID        string        `json:"run_id"`
+	Skill        string        `json:"skill"`
+	BeforeStatus string        `json:"before_status"`
+	BeforeError  string        `json:"before_error,omitempty"`
+	AfterStatus  string        `json:"after_status,omitempty"`
+	AfterError   string        `json:"after_error,omitempty"`
+	Outcome      string        `json:"outcome"`
+	Duration     time.Duration `json:"duration_ns,omitempty"`
+	Cost         float64       `json:"cost,omitempty"`
+}
+
+// historyRerunSummaryJSON is the JSON output of sr history rerun.
+type historyRerunSummaryJSON struct {
+	Runs      []historyRerunJSON `json:"runs"`
+	Fixed     int                `json:"fixed"`
+	Failing   int                `json:"still_failing"`
+	Skipped   int                `json:"skipped"`
+	TotalCost float64            `json:"total_cost"`
+}
+
+func runHistoryRerun(ctx context.Context, opts historyRerunOptions) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	status := domainWorkflow.CheckpointStatus(opts.Status)
+	if !slices.Contains(domainWorkflow.ValidCheckpointStatuses, status) {
+		return fmt.Errorf("invalid --status %q", opts.Status)
+	}
+	since, err := parseDuration(opts.Since)
+	if err != nil {
+		return fmt.Errorf("invalid --since: %w", err)
+	}
+	if opts.Max <= 0 {
+		return fmt.Errorf("--max must be positive")
+	}
+	if opts.Interval < 0 {
+		return fmt.Errorf("--interval must not be negative")
+	}
+	if err := validateProfile(opts.Profile); err != nil {
+		return err
+	}
+
+	formatter := GetFormatter()
+	container := GetContainer()
+	if container == nil {
+		return fmt.Errorf("application not initialized")
+	}
+	registry := container.SkillRegistry()
+	if registry == nil {
+		return fmt.Errorf("skill registry not available")
+	}
+	runs, _, err := historyRepositories()
+	if err != nil {
+		return err
+	}
+
+	checkpoints, err := runs.List(ctx, &ports.WorkflowCheckpointFilter{
+		SkillID:      opts.Skill,
+		Status:       []domainWorkflow.CheckpointStatus{status},
+		CreatedAfter: time.Now().Add(-since),
+		Limit:        opts.Max,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to list runs: %w", err)
+	}
+	if len(checkpoints) == 0 {
+		if formatter.Format() == output.FormatJSON {
+			return formatter.JSON(historyRerunSummaryJSON{Runs: []historyRerunJSON{}})
+		}
+		formatter.Info("No %s runs in the last %s", opts.Status, opts.Since)
+		return nil
+	}
+
+	providerRegistry := container.ProviderRegistry()
+	prov := selectProvider(providerRegistry.ListProviders(), opts.Profile)
+	if prov == nil {
+		return fmt.Errorf("no suitable provider found for profile: %s", opts.Profile)
+	}
+
+	costCalc := container.CostCalculator()
+	executorConfig := workflow.DefaultExecutorConfig()
+	executorConfig.ProviderSelector = appProvider.NewPinSelector(providerRegistry, pinFallbackWarner(formatter))
+	executorConfig.ConcurrencyGroups = container.ConcurrencyGroups()
+	executorConfig.Skills = container.SkillRegistry()
+	applyRouting(formatter, &executorConfig)
+	if err := applyBudgets(ctx, formatter, &executorConfig, container.RoutingConfiguration().Budgets, costCalc); err != nil {
+		return err
+	}
+	cpConfig := workflow.CheckpointConfig{
+		Enabled:   true,
+		Port:      runs,
+		MachineID: container.MachineID(),
+	}
+
+	// List returns the most recent runs first; rerun them in the order they ran
+	pacer := &rerunPacer{interval: opts.Interval}
+	summary := historyRerunSummaryJSON{Runs: make([]historyRerunJSON, 0, len(checkpoints))}
+	for i := len(checkpoints) - 1; i >= 0; i-- {
+		cp := checkpoints[i]
+		record := historyRerunJSON{
+			RunID:        cp.ID(),
+			Skill:        cp.SkillID(),
+			BeforeStatus: string(cp.Status()),
+			BeforeError:  runError(cp),
+		}
+
+		sk := registry.GetSkill(cp.SkillID())
+		switch {
+		case sk == nil:
+			record.Outcome = rerunOutcomeSkipped
+			record.AfterError = "skill not found"
+		case ctx.Err() != nil:
+			record.Outcome = rerunOutcomeCanceled
+		default:
+			if err := appSkills.VerifyRequirements(sk); err != nil {
+				record.Outcome = rerunOutcomeSkipped
+				record.AfterError = err.Error()
+				break
+			}
+			if err := pacer.wait(ctx, prov); err != nil {
+				record.Outcome = rerunOutcomeCanceled
+				break
+			}
+			if formatter.Format() != output.FormatJSON {
+				formatter.Info("Rerunning %s (%s)...", cp.ID(), sk.ID())
+			}
+			// The rerun is attributed to the same tags as the original run
+			cpConfig.Tags = cp.Tags()
+			executor := workflow.NewCheckpointingExecutor(prov, executorConfig, cpConfig)
+			result, err := executor.Execute(ctx, sk, cp.Input())
+			rerunOutcome(&record, result, err, costCalc)
+			recordCosts(ctx, formatter, result, costCalc, cp.Tags())
+		}
+
+		switch record.Outcome {
+		case rerunOutcomeFixed:
+			summary.Fixed++
+		case rerunOutcomeFailed:
+			summary.Failing++
+		default:
+			summary.Skipped++
+		}
+		summary.TotalCost += record.Cost
+		summary.Runs = append(summary.Runs, record)
+	}
+
+	if formatter.Format() == output.FormatJSON {
+		return formatter.JSON(summary)
+	}
+	printHistoryRerun(formatter, summary)
+	return nil
+}
+
+// rerunOutcome fills in the after side of a rerun record from its result.
+func rerunOutcome(record *historyRerunJSON, result *workflow.ExecutionResult, err error, costCalc *provider.CostCalculator) {
+	if result == nil {
+		record.AfterStatus = string(domainWorkflow.CheckpointStatusFailed)
+		record.Outcome = rerunOutcomeFailed
+		if err != nil {
+			record.AfterError = err.Error()
+		}
+		return
+	}
+
+	calculateCostsForResult(result, costCalc)
+	record.Duration = result.Duration
+	record.Cost = result.TotalCost
+	if err == nil && result.Status == workflow.PhaseStatusCompleted {
+		record.AfterStatus = string(domainWorkflow.CheckpointStatusCompleted)
+		record.Outcome = rerunOutcomeFixed
+		return
+	}
+
+	record.AfterStatus = string(domainWorkflow.CheckpointStatusFailed)
+	record.Outcome = rerunOutcomeFailed
+	switch {
+	case result.Error != nil:
+		record.AfterError = result.Error.Error()
+	case err != nil:
+		record.AfterError = err.Error()
+	}
+}
+
+// runError returns the error of the first failed phase of a run, if any.
+func runError(cp *domainWorkflow.WorkflowCheckpoint) string {
+	first := cp.FailedPhase()
+	if first == nil {
+		return ""
+	}
+	return fmt.Sprintf("%s: %s", first.PhaseID, first.ErrorMessage)
+}
+
+// rerunPacer spaces out reruns so that a bulk rerun stays within provider
+// limits: starts are at least interval apart, and none happens while the
+// provider reports that it is throttled.
+type rerunPacer struct {
+	interval  time.Duration
+	lastStart time.Time
+}
+
+// wait blocks until the next rerun may start on prov.
+func (p *rerunPacer) wait(ctx context.Context, prov ports.ProviderPort) error {
+	if !p.lastStart.IsZero() {
+		if err := sleepContext(ctx, time.Until(p.lastStart.Add(p.interval))); err != nil {
+			return err
+		}
+	}
+	if throttle, ok := prov.(ports.ThrottleReporter); ok {
+		for throttle.Throttled() {
+			if err := sleepContext(ctx, rerunThrottlePoll); err != nil {
+				return err
+			}
+		}
+	}
+	p.lastStart = time.Now()
+	return nil
+}
+
+// sleepContext sleeps for d, or until ctx is done.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+func printHistoryRerun(formatter *output.Formatter, summary historyRerunSummaryJSON) {
+	formatter.Header("Rerun Summary")
+
+	table := output.TableData{
+		Columns: []output.TableColumn{
+			{Header: "Run", Width: 36, Align: output.AlignLeft},
+			{Header: "Skill", Width: 20, Align: output.AlignLeft},
+			{Header: "Before", Width: 30, Align: output.AlignLeft},
+			{Header: "After", Width: 30, Align: output.AlignLeft},
+			{Header: "Cost", Width: 8, Align: output.AlignRight},
+		},
+		Rows: make([][]string, 0, len(summary.Runs)),
+	}
+	for _, r := range summary.Runs {
+		table.Rows = append(table.Rows, []string{
+			r.RunID,
+			r.Skill,
+			truncateString(describeRerunSide(r.BeforeStatus, r.BeforeError), 30),
+			truncateString(describeRerunSide(r.AfterStatus, r.AfterError), 30),
+			formatCost(r.Cost),
+		})
+	}
+	formatter.Table(table)
+	formatter.Println("")
+
+	formatter.Item("Fixed", fmt.Sprintf("%d", summary.Fixed))
+	formatter.Item("Still failing", fmt.Sprintf("%d", summary.Failing))
+	if summary.Skipped > 0 {
+		formatter.Item("Skipped", fmt.Sprintf("%d", summary.Skipped))
+	}
+	formatter.Item("Total cost", formatCost(summary.TotalCost))
+
+	if summary.Failing > 0 {
+		formatter.Warning("%d run(s) still fail; see 'sr history analyze <run>'", summary.Failing)
+	} else if summary.Fixed > 0 {
+		formatter.Success("All rerun failures are fixed")
+	}
+}
+
+// describeRerunSide summarizes one side of a rerun for a table cell.
+func describeRerunSide(status, errMsg string) string {
+	switch {
+	case status == "":
+		return errMsg
+	case errMsg == "":
+		return status
+	default:
+		return status + ": " + errMsg
+	}
+}
@@ -0,0 +1,262 @@
+package commands
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/jbctechsolutions/skillrunner/internal/application/ports"
+	domainWorkflow "github.com/jbctechsolutions/skillrunner/internal/domain/workflow"
+	"github.com/jbctechsolutions/skillrunner/internal/presentation/cli/output"
+)
+
+// historyStatsOptions holds the flags of the history stats command.
+type historyStatsOptions struct {
+	Since    string
+	Skill    string
+	Failures bool
+	Interval string
+}
+
+// historySkillStatsJSON counts the runs of a skill in sr history stats.
+type historySkillStatsJSON struct {
+	Skill     string  `json:"skill"`
+	Runs      int     `json:"runs"`
+	Completed int     `json:"completed"`
+	Failed    int     `json:"failed"`
+	Success   float64 `json:"success_rate"` // Completed share of finished runs
+}
+
+// historyStatsJSON is the JSON output of sr history stats.
+type historyStatsJSON struct {
+	Since  time.Time               `json:"since"`
+	Runs   int                     `json:"runs"`
+	Failed int                     `json:"failed"`
+	Skills []historySkillStatsJSON `json:"skills"`
+}
+
+// NewHistoryStatsCmd creates the history stats command.
+func NewHistoryStatsCmd() *cobra.Command {
+	var opts historyStatsOptions
+
+	cmd := &cobra.Command{
+		Use:   "stats",
+		Short: "Summarize run outcomes and why runs failed",
+		Long: `Summarize the runs recorded over a time range: how many of each skill
+completed and failed.
+
+With --failures, failed runs are grouped by the class of the first phase
+that failed, as recorded when the run was persisted:
+  provider_outage     provider unreachable, overloaded, rate limited or erroring
+  context_overflow    prompt did not fit the model's context window
+  validation_failed   request or output rejected as invalid, e.g. by a schema
+  json_parse_error    output that should be JSON did not parse
+  tool_error          a tool or program the skill relies on failed
+  other               anything else, including canceled runs
+
+Each class is shown with the skills it affects and its latest error, followed
+by the failures of each --interval period, to show what breaks workflows over
+time. Runs recorded before classification are classified by their error.`,
+		Example: `  # Success rates per skill over the last 30 days
+  sr history stats
+
+  # What broke runs, week by week
+  sr history stats --failures
+
+  # Daily failures of one skill over the last two weeks, as JSON
+  sr history stats --failures --skill code-review --since 14d --interval 1d -o json`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runHistoryStats(cmd.Context(), opts)
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.Since, "since", "30d", "time range to summarize (e.g., 7d, 30d)")
+	cmd.Flags().StringVar(&opts.Skill, "skill", "", "only summarize runs of this skill")
+	cmd.Flags().BoolVar(&opts.Failures, "failures", false, "group failed runs by failure class")
+	cmd.Flags().StringVar(&opts.Interval, "interval", "7d", "length of the periods failures are counted over (with --failures)")
+
+	return cmd
+}
+
+func runHistoryStats(ctx context.Context, opts historyStatsOptions) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	since, err := parseDuration(opts.Since)
+	if err != nil {
+		return fmt.Errorf("invalid time range: %w", err)
+	}
+	interval, err := parseDuration(opts.Interval)
+	if err != nil || interval <= 0 {
+		return fmt.Errorf("invalid interval: %s", opts.Interval)
+	}
+
+	formatter := GetFormatter()
+	runs, _, err := historyRepositories()
+	if err != nil {
+		return err
+	}
+
+	start := time.Now().Add(-since)
+	checkpoints, err := runs.List(ctx, &ports.WorkflowCheckpointFilter{SkillID: opts.Skill, CreatedAfter: start})
+	if err != nil {
+		return fmt.Errorf("failed to list runs: %w", err)
+	}
+
+	if opts.Failures {
+		stats := domainWorkflow.SummarizeFailures(checkpoints, interval)
+		if formatter.Format() == output.FormatJSON {
+			return formatter.JSON(stats)
+		}
+		return printFailureStats(formatter, stats, opts.Since)
+	}
+
+	stats := historyStatsJSON{Since: start.UTC(), Skills: skillRunStats(checkpoints)}
+	for _, s := range stats.Skills {
+		stats.Runs += s.Runs
+		stats.Failed += s.Failed
+	}
+	if formatter.Format() == output.FormatJSON {
+		return formatter.JSON(stats)
+	}
+	return printHistoryStats(formatter, stats, opts.Since)
+}
+
+// skillRunStats counts the runs of each skill, most runs first.
+func skillRunStats(checkpoints []*domainWorkflow.WorkflowCheckpoint) []historySkillStatsJSON {
+	bySkill := make(map[string]*historySkillStatsJSON)
+	for _, cp := range checkpoints {
+		s := bySkill[cp.SkillID()]
+		if s == nil {
+			s = &historySkillStatsJSON{Skill: cp.SkillID()}
+			bySkill[cp.SkillID()] = s
+		}
+		s.Runs++
+		switch cp.Status() {
+		case domainWorkflow.CheckpointStatusCompleted:
+			s.Completed++
+		case domainWorkflow.CheckpointStatusFailed:
+			s.Failed++
+		}
+	}
+
+	skills := make([]historySkillStatsJSON, 0, len(bySkill))
+	for _, s := range bySkill {
+		if finished := s.Completed + s.Failed; finished > 0 {
+			s.Success = float64(s.Completed) / float64(finished)
+		}
+		skills = append(skills, *s)
+	}
+	slices.SortFunc(skills, func(a, b historySkillStatsJSON) int {
+		return cmp.Or(cmp.Compare(b.Runs, a.Runs), cmp.Compare(a.Skill, b.Skill))
+	})
+	return skills
+}
+
+// printHistoryStats prints the run counts of each skill.
+func printHistoryStats(formatter *output.Formatter, stats historyStatsJSON, since string) error {
+	formatter.Header(fmt.Sprintf("Run Outcomes (last %s)", since))
+	if stats.Runs == 0 {
+		formatter.Info("No runs recorded in this period")
+		return nil
+	}
+
+	table := output.TableData{
+		Columns: []output.TableColumn{
+			{Header: "Skill", Width: 30, Align: output.AlignLeft},
+			{Header: "Runs", Width: 6, Align: output.AlignRight},
+			{Header: "Completed", Width: 9, Align: output.AlignRight},
+			{Header: "Failed", Width: 6, Align: output.AlignRight},
+			{Header: "Success", Width: 7, Align: output.AlignRight},
+		},
+	}
+	for _, s := range stats.Skills {
+		table.Rows = append(table.Rows, []string{
+			truncateString(s.Skill, 30),
+			fmt.Sprintf("%d", s.Runs),
+			fmt.Sprintf("%d", s.Completed),
+			fmt.Sprintf("%d", s.Failed),
+			fmt.Sprintf("%.0f%%", s.Success*100),
+		})
+	}
+	if err := formatter.Table(table); err != nil {
+		return err
+	}
+
+	formatter.Println("")
+	formatter.Item("Runs", fmt.Sprintf("%d (%d failed)", stats.Runs, stats.Failed))
+	if stats.Failed > 0 {
+		formatter.Info("Use --failures to see why runs failed")
+	}
+	return nil
+}
+
+// printFailureStats prints failed runs by failure class and by period.
+func printFailureStats(formatter *output.Formatter, stats *domainWorkflow.FailureStats, since string) error {
+	formatter.Header(fmt.Sprintf("Failures (last %s)", since))
+	if stats.Failed == 0 {
+		formatter.Info("No failed runs in this period (%d runs)", stats.Runs)
+		return nil
+	}
+
+	classes := output.TableData{
+		Columns: []output.TableColumn{
+			{Header: "Class", Width: 17, Align: output.AlignLeft},
+			{Header: "Runs", Width: 5, Align: output.AlignRight},
+			{Header: "Share", Width: 5, Align: output.AlignRight},
+			{Header: "Skills", Width: 24, Align: output.AlignLeft},
+			{Header: "Last Seen", Width: 10, Align: output.AlignLeft},
+			{Header: "Latest Error", Width: 40, Align: output.AlignLeft},
+		},
+	}
+	for _, c := range stats.Classes {
+		classes.Rows = append(classes.Rows, []string{
+			string(c.Class),
+			fmt.Sprintf("%d", c.Runs),
+			fmt.Sprintf("%.0f%%", float64(c.Runs)/float64(stats.Failed)*100),
+			truncateString(strings.Join(c.Skills, ", "), 24),
+			formatRelativeTime(c.LastSeen),
+			truncateString(strings.Join(strings.Fields(c.Example), " "), 40),
+		})
+	}
+	if err := formatter.Table(classes); err != nil {
+		return err
+	}
+
+	formatter.Println("")
+	formatter.Header("Over Time")
+	periods := output.TableData{
+		Columns: []output.TableColumn{
+			{Header: "Period", Width: 10, Align: output.AlignLeft},
+			{Header: "Runs", Width: 5, Align: output.AlignRight},
+			{Header: "Failed", Width: 6, Align: output.AlignRight},
+		},
+	}
+	for _, c := range stats.Classes {
+		periods.Columns = append(periods.Columns, output.TableColumn{Header: string(c.Class), Width: len(c.Class), Align: output.AlignRight})
+	}
+	for _, p := range stats.Periods {
+		row := []string{
+			p.Start.Local().Format("2006-01-02"),
+			fmt.Sprintf("%d", p.Runs),
+			fmt.Sprintf("%d", p.Failed),
+		}
+		for _, c := range stats.Classes {
+			row = append(row, fmt.Sprintf("%d", p.ByClass[c.Class]))
+		}
+		periods.Rows = append(periods.Rows, row)
+	}
+	if err := formatter.Table(periods); err != nil {
+		return err
+	}
+
+	formatter.Println("")
+	formatter.Item("Failed", fmt.Sprintf("%d of %d runs", stats.Failed, stats.Runs))
+	return nil
+}
@@ -80,6 +80,9 @@ Use --since to filter by time range (e.g., "24h", "7d", "30d").`,
 
 	cmd.Flags().StringVar(&since, "since", "24h", "time range for metrics (e.g., 24h, 7d, 30d)")
 
+	cmd.AddCommand(NewMetricsExportCmd())
+	cmd.AddCommand(NewMetricsShareCmd())
+
 	return cmd
 }
 
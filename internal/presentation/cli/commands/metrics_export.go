@@ -0,0 +1,105 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/jbctechsolutions/skillrunner/internal/application/observability"
+	"github.com/jbctechsolutions/skillrunner/internal/domain/metrics"
+	"github.com/jbctechsolutions/skillrunner/internal/presentation/cli/output"
+)
+
+// NewMetricsExportCmd creates the metrics export command.
+func NewMetricsExportCmd() *cobra.Command {
+	var since, file, account string
+
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "Export cost and usage in the FinOps FOCUS format",
+		Long: `Export per-phase provider usage and cost from the metrics store as FOCUS
+(FinOps Open Cost and Usage Specification) records, to fold AI spend into
+FinOps tools and OpenCost alongside cloud bills.
+
+Each record is one phase execution: the provider is the FOCUS provider, the
+model the SKU, and the skill the sub-account. Tokens are the consumed and
+pricing quantity; costs are skillrunner's estimates from its pricing catalog.
+Phases served from cache are left out.
+
+Records are written as CSV, or as a JSON array with -o json.`,
+		Example: `  # Last month's usage as a FOCUS CSV file
+  sr metrics export --since 30d --file usage.csv
+
+  # Under your organization's billing account, as JSON
+  sr metrics export --since 7d --account acme-ai -o json`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runMetricsExport(cmd.Context(), cmd.OutOrStdout(), since, file, account)
+		},
+	}
+
+	cmd.Flags().StringVar(&since, "since", "30d", "time range to export (e.g., 24h, 7d, 30d)")
+	cmd.Flags().StringVar(&file, "file", "", "write the records to this file instead of standard output")
+	cmd.Flags().StringVar(&account, "account", observability.DefaultFOCUSAccount, "billing account ID of the records")
+
+	return cmd
+}
+
+func runMetricsExport(ctx context.Context, stdout io.Writer, since, file, account string) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	duration, err := parseDuration(since)
+	if err != nil {
+		return fmt.Errorf("invalid time range: %w", err)
+	}
+
+	formatter := GetFormatter()
+	container := GetContainer()
+	if container == nil {
+		return errors.New("application not initialized")
+	}
+	store := container.MetricsRepository()
+	if store == nil {
+		return errors.New("metrics not enabled in configuration")
+	}
+
+	now := time.Now()
+	records, err := observability.FOCUSRecords(ctx, store, metrics.MetricsFilter{StartDate: now.Add(-duration), EndDate: now}, account)
+	if err != nil {
+		return fmt.Errorf("failed to export usage: %w", err)
+	}
+
+	w := stdout
+	if file != "" {
+		f, err := os.Create(file)
+		if err != nil {
+			return fmt.Errorf("failed to create %s: %w", file, err)
+		}
+		defer f.Close()
+		w = f
+	}
+
+	if formatter.Format() == output.FormatJSON {
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		err = encoder.Encode(records)
+	} else {
+		err = observability.WriteFOCUSCSV(w, records)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write usage records: %w", err)
+	}
+
+	if file != "" {
+		formatter.Success("Exported %d usage record(s) to %s", len(records), file)
+	}
+	return nil
+}
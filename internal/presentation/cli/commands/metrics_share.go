@@ -0,0 +1,163 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/jbctechsolutions/skillrunner/internal/application/observability"
+	"github.com/jbctechsolutions/skillrunner/internal/domain/metrics"
+	"github.com/jbctechsolutions/skillrunner/internal/presentation/cli/output"
+)
+
+// defaultBenchmarkFile is where sr metrics share writes benchmark aggregates.
+const defaultBenchmarkFile = "skillrunner-benchmark.json"
+
+// NewMetricsShareCmd creates the metrics share command.
+func NewMetricsShareCmd() *cobra.Command {
+	var since, out, upload string
+
+	cmd := &cobra.Command{
+		Use:   "share",
+		Short: "Share anonymized benchmark aggregates with the community",
+		Long: `Write coarse benchmark aggregates of your phase executions to a local file
+for review, then upload the reviewed file.
+
+The file holds, per model and latency bucket (<1s, 1-5s, 5-15s, 15-60s,
+>60s), the number of phase executions and their success rate. Laplace noise
+is added to every count, groups with fewer than min_group_size executions
+are dropped, and the period is rounded to whole days. It names no skill,
+phase, prompt, output or execution. Phases served from cache are left out.
+
+Uploading is opt-in: it requires observability.metrics.share.enabled and
+share.url in config.yaml, and posts the file exactly as written, so what
+you review is what is sent:
+
+  observability:
+    metrics:
+      share:
+        enabled: true
+        url: https://benchmarks.example.com/upload
+        epsilon: 1.0        # smaller is noisier
+        min_group_size: 10`,
+		Example: `  # Write last month's aggregates for review
+  sr metrics share --since 30d --out benchmark.json
+
+  # Upload the reviewed file
+  sr metrics share --upload benchmark.json`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if upload != "" {
+				return runMetricsShareUpload(cmd.Context(), upload)
+			}
+			return runMetricsShare(cmd.Context(), since, out)
+		},
+	}
+
+	cmd.Flags().StringVar(&since, "since", "30d", "time range to aggregate (e.g., 7d, 30d)")
+	cmd.Flags().StringVar(&out, "out", defaultBenchmarkFile, "file to write the aggregates to for review")
+	cmd.Flags().StringVar(&upload, "upload", "", "upload a reviewed benchmark file instead of writing one")
+
+	return cmd
+}
+
+func runMetricsShare(ctx context.Context, since, out string) error {
+	duration, err := parseDuration(since)
+	if err != nil {
+		return fmt.Errorf("invalid time range: %w", err)
+	}
+
+	formatter := GetFormatter()
+	container := GetContainer()
+	if container == nil {
+		return errors.New("application not initialized")
+	}
+	store := container.MetricsRepository()
+	if store == nil {
+		return errors.New("metrics not enabled in configuration")
+	}
+	shareCfg := container.Config().Observability.Metrics.Share
+
+	// Whole days, so the period does not reveal when runs happened
+	end := time.Now().UTC().Truncate(24 * time.Hour)
+	filter := metrics.MetricsFilter{StartDate: end.Add(-duration).Truncate(24 * time.Hour), EndDate: end}
+	report, err := observability.BenchmarkAggregates(ctx, store, filter, observability.BenchmarkOptions{
+		Epsilon:      shareCfg.Epsilon,
+		MinGroupSize: shareCfg.MinGroupSize,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to aggregate metrics: %w", err)
+	}
+
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(out, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", out, err)
+	}
+
+	if formatter.Format() == output.FormatJSON {
+		return formatter.JSON(report)
+	}
+
+	formatter.Header("Benchmark Aggregates")
+	formatter.Item("Period", report.PeriodStart+" to "+report.PeriodEnd)
+	formatter.Item("Epsilon", fmt.Sprintf("%g", report.Epsilon))
+	formatter.Info("")
+	if len(report.Stats) == 0 {
+		formatter.Warning("No model has enough phase executions to share")
+	} else {
+		table := output.TableData{
+			Columns: []output.TableColumn{
+				{Header: "Model", Width: 36, Align: output.AlignLeft},
+				{Header: "Latency", Width: 8, Align: output.AlignLeft},
+				{Header: "Runs", Width: 6, Align: output.AlignRight},
+				{Header: "Success", Width: 8, Align: output.AlignRight},
+			},
+		}
+		for _, s := range report.Stats {
+			table.Rows = append(table.Rows, []string{
+				truncateString(s.Model, 36), s.LatencyBucket, fmt.Sprintf("%d", s.Runs), fmt.Sprintf("%.0f%%", s.SuccessRate*100),
+			})
+		}
+		if err := formatter.Table(table); err != nil {
+			return err
+		}
+	}
+
+	formatter.Info("")
+	formatter.Success("Wrote %s; review it, then upload it with 'sr metrics share --upload %s'", out, out)
+	return nil
+}
+
+func runMetricsShareUpload(ctx context.Context, file string) error {
+	formatter := GetFormatter()
+	container := GetContainer()
+	if container == nil {
+		return errors.New("application not initialized")
+	}
+	shareCfg := container.Config().Observability.Metrics.Share
+	if !shareCfg.Enabled {
+		return errors.New("sharing is disabled; set observability.metrics.share.enabled and share.url in config.yaml to opt in")
+	}
+
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", file, err)
+	}
+	if err := observability.UploadBenchmarkReport(ctx, shareCfg.URL, data); err != nil {
+		return fmt.Errorf("failed to upload %s: %w", file, err)
+	}
+
+	if formatter.Format() == output.FormatJSON {
+		return formatter.JSON(map[string]string{"file": file, "url": shareCfg.URL})
+	}
+	formatter.Success("Uploaded %s to %s", file, shareCfg.URL)
+	return nil
+}
@@ -0,0 +1,154 @@
+package commands
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/jbctechsolutions/skillrunner/internal/infrastructure/config"
+	"github.com/jbctechsolutions/skillrunner/internal/presentation/cli/output"
+)
+
+// maxPriceChangesShown caps the price changes listed by sync-pricing in text
+// output; the JSON output lists them all.
+const maxPriceChangesShown = 25
+
+// NewModelsCmd creates the models command.
+func NewModelsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "models",
+		Short: "Manage model metadata",
+		Long: `Manage metadata about the models skillrunner routes to, such as their
+per-token prices.`,
+	}
+
+	cmd.AddCommand(NewModelsSyncPricingCmd())
+
+	return cmd
+}
+
+// NewModelsSyncPricingCmd creates the models sync-pricing command.
+func NewModelsSyncPricingCmd() *cobra.Command {
+	var url string
+
+	cmd := &cobra.Command{
+		Use:   "sync-pricing",
+		Short: "Sync per-token model prices from a pricing catalog",
+		Long: `Fetch current per-token prices for the models of the cloud providers and
+cache them in ~/.skillrunner/pricing/catalog.json.
+
+Synced prices replace the built-in defaults in cost tracking, and fill in the
+costs of models in routing.yaml that do not set cost_per_input_token and
+cost_per_output_token; costs set in routing.yaml take precedence.
+
+The catalog defaults to the model price list maintained by the LiteLLM
+project, and can be changed with pricing.url in config.yaml. Set
+pricing.auto_sync to refresh it in the background once it is older than
+pricing.max_age (default: 7 days).`,
+		Example: `  # Sync prices from the default catalog
+  sr models sync-pricing
+
+  # Sync from an internal mirror
+  sr models sync-pricing --url https://config.example.com/pricing.json`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runModelsSyncPricing(cmd, url)
+		},
+	}
+
+	cmd.Flags().StringVar(&url, "url", "", "pricing catalog URL (default: pricing.url or the LiteLLM catalog)")
+
+	return cmd
+}
+
+// modelsSyncPricingResult is the JSON form of models sync-pricing.
+type modelsSyncPricingResult struct {
+	Source    string               `json:"source"`
+	UpdatedAt string               `json:"updated_at"`
+	Models    int                  `json:"models"`
+	Changes   []config.PriceChange `json:"changes"`
+}
+
+func runModelsSyncPricing(cmd *cobra.Command, url string) error {
+	formatter := GetFormatter()
+	container := GetContainer()
+	if container == nil {
+		return fmt.Errorf("application not initialized")
+	}
+
+	if url == "" {
+		url = container.Config().Pricing.SourceURL()
+	}
+
+	// The previous catalog is only used to report what changed
+	previous, _ := config.LoadPricingCatalog()
+
+	catalog, err := config.FetchPricingCatalog(cmd.Context(), url)
+	if err != nil {
+		return err
+	}
+	if err := config.SavePricingCatalog(catalog); err != nil {
+		return fmt.Errorf("failed to save pricing catalog: %w", err)
+	}
+
+	changes := catalog.Changes(previous)
+	if formatter.Format() == output.FormatJSON {
+		return formatter.JSON(modelsSyncPricingResult{
+			Source:    catalog.Source,
+			UpdatedAt: catalog.UpdatedAt.Format(time.RFC3339),
+			Models:    len(catalog.Models),
+			Changes:   changes,
+		})
+	}
+
+	formatter.Header("Model Pricing")
+	formatter.Item("Source", catalog.Source)
+	formatter.Item("Models", fmt.Sprintf("%d", len(catalog.Models)))
+
+	if previous == nil {
+		formatter.Success("Synced prices for %d models", len(catalog.Models))
+		return nil
+	}
+	if len(changes) == 0 {
+		formatter.Success("Prices are unchanged")
+		return nil
+	}
+
+	formatter.Info("")
+	table := output.TableData{
+		Columns: []output.TableColumn{
+			{Header: "Provider", Width: 10, Align: output.AlignLeft},
+			{Header: "Model", Width: 40, Align: output.AlignLeft},
+			{Header: "Before (in/out per 1M)", Width: 22, Align: output.AlignRight},
+			{Header: "After (in/out per 1M)", Width: 22, Align: output.AlignRight},
+		},
+	}
+	for i, c := range changes {
+		if i == maxPriceChangesShown {
+			break
+		}
+		table.Rows = append(table.Rows, []string{
+			c.Provider, truncateString(c.Model, 40), formatModelPrice(c.Old), formatModelPrice(c.New),
+		})
+	}
+	if err := formatter.Table(table); err != nil {
+		return err
+	}
+	if len(changes) > maxPriceChangesShown {
+		formatter.Info("... and %d more; use --output json to list them all", len(changes)-maxPriceChangesShown)
+	}
+
+	formatter.Info("")
+	formatter.Success("%d price change(s) synced", len(changes))
+	return nil
+}
+
+// formatModelPrice formats a model's input and output prices per million
+// tokens, or "-" if it is not priced.
+func formatModelPrice(p *config.ModelPrice) string {
+	if p == nil {
+		return "-"
+	}
+	return fmt.Sprintf("$%.2f / $%.2f", p.CostPerInputToken*1e6, p.CostPerOutputToken*1e6)
+}
@@ -11,11 +11,13 @@ import (
 
 	"github.com/spf13/cobra"
 
+	adapterProvider "github.com/jbctechsolutions/skillrunner/internal/adapters/provider"
+	appProvider "github.com/jbctechsolutions/skillrunner/internal/application/provider"
 	"github.com/jbctechsolutions/skillrunner/internal/application/workflow"
 	"github.com/jbctechsolutions/skillrunner/internal/domain/provider"
 	"github.com/jbctechsolutions/skillrunner/internal/domain/skill"
 	domainWorkflow "github.com/jbctechsolutions/skillrunner/internal/domain/workflow"
-	infraMemory "github.com/jbctechsolutions/skillrunner/internal/infrastructure/memory"
+	"github.com/jbctechsolutions/skillrunner/internal/infrastructure/config"
 	"github.com/jbctechsolutions/skillrunner/internal/infrastructure/tokenizer"
 	"github.com/jbctechsolutions/skillrunner/internal/presentation/cli/output"
 )
@@ -67,7 +69,7 @@ Examples:
 
 	// Define flags
 	cmd.Flags().StringVarP(&planOpts.Profile, "profile", "p", skill.ProfileBalanced,
-		fmt.Sprintf("routing profile: %s, %s, %s", skill.ProfileCheap, skill.ProfileBalanced, skill.ProfilePremium))
+		fmt.Sprintf("routing profile: %s, %s, %s or a custom profile", skill.ProfileCheap, skill.ProfileBalanced, skill.ProfilePremium))
 	cmd.Flags().BoolVar(&planOpts.Approve, "approve", false, "auto-approve and execute without confirmation")
 	cmd.Flags().BoolVar(&planOpts.SaveOnly, "save-only", false, "show plan only, do not execute")
 	cmd.Flags().StringVarP(&planOpts.Output, "output", "O", "", "save plan to file (JSON format)")
@@ -82,6 +84,7 @@ func runPlan(cmd *cobra.Command, args []string) error {
 	request := args[1]
 
 	// Validate profile
+	planOpts.Profile = projectProfile(cmd, planOpts.Profile)
 	if err := validateProfile(planOpts.Profile); err != nil {
 		return err
 	}
@@ -111,20 +114,7 @@ func runPlan(cmd *cobra.Command, args []string) error {
 	ctx := context.Background()
 
 	// Load memory content (unless disabled)
-	var memoryContent string
-	appCtx := GetAppContext()
-	memoryEnabled := appCtx != nil && appCtx.Config != nil && appCtx.Config.Memory.Enabled
-	if memoryEnabled && !planOpts.NoMemory {
-		cwd, err := os.Getwd()
-		if err == nil {
-			maxTokens := appCtx.Config.Memory.MaxTokens
-			loader := infraMemory.NewLoader(maxTokens)
-			mem, err := loader.Load(cwd)
-			if err == nil && !mem.IsEmpty() {
-				memoryContent = mem.Combined()
-			}
-		}
-	}
+	memoryContent := loadMemoryContent(planOpts.NoMemory)
 
 	// Create planner
 	planner := createPlanner(container)
@@ -176,24 +166,30 @@ func runPlan(cmd *cobra.Command, args []string) error {
 	return executePlanSkill(ctx, sk, request, memoryContent, formatter)
 }
 
-// createPlanner creates a planner with available dependencies.
+// createPlanner creates a planner with available dependencies. Models are
+// resolved through profile routing when a routing configuration is loaded.
 func createPlanner(container interface {
 	CostCalculator() *provider.CostCalculator
+	RoutingConfiguration() *config.RoutingConfiguration
+	ProviderRegistry() *adapterProvider.Registry
 }) *workflow.Planner {
 	// Get cost calculator from container
 	costCalculator := container.CostCalculator()
 
-	// Create token estimator
-	tokenEstimator, err := tokenizer.NewEstimator()
+	// Create token estimator, falling back to a character-based estimate
+	// when the tokenizer's encoding cannot be loaded
+	var tokenEstimator provider.TokenEstimator = tokenizer.NewSimpleEstimator()
+	if estimator, err := tokenizer.NewEstimator(); err == nil {
+		tokenEstimator = estimator
+	}
+
+	// Without a router the planner uses placeholder model names
+	router, err := appProvider.NewRouter(container.RoutingConfiguration(), container.ProviderRegistry())
 	if err != nil {
-		// Fall back to nil tokenEstimator (will use defaults)
-		tokenEstimator = nil
+		router = nil
 	}
 
-	// Create planner without router (it will use placeholder model names)
-	// This is acceptable since we're just generating the plan for visualization
-	config := workflow.DefaultPlannerConfig()
-	return workflow.NewPlanner(nil, costCalculator, tokenEstimator, config)
+	return workflow.NewPlanner(router, costCalculator, tokenEstimator, workflow.DefaultPlannerConfig())
 }
 
 // outputPlanJSON outputs the plan as JSON.
@@ -255,6 +251,7 @@ func executePlanSkill(ctx context.Context, sk *skill.Skill, request string, memo
 	// Create executor with memory content
 	executorConfig := workflow.DefaultExecutorConfig()
 	executorConfig.MemoryContent = memoryContent
+	applyRouting(formatter, &executorConfig)
 	executor := workflow.NewExecutor(selectedProvider, executorConfig)
 
 	// Get cost calculator for pricing
@@ -0,0 +1,411 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/jbctechsolutions/skillrunner/internal/application/ports"
+	"github.com/jbctechsolutions/skillrunner/internal/application/workflow"
+	"github.com/jbctechsolutions/skillrunner/internal/domain/skill"
+	domainWorkflow "github.com/jbctechsolutions/skillrunner/internal/domain/workflow"
+	"github.com/jbctechsolutions/skillrunner/internal/presentation/cli/output"
+)
+
+// replayOptions holds the flags of sr replay.
+type replayOptions struct {
+	Phase    string
+	Model    string
+	Provider string
+	Call     int
+}
+
+// NewReplayCmd creates the replay command.
+func NewReplayCmd() *cobra.Command {
+	var opts replayOptions
+
+	cmd := &cobra.Command{
+		Use:   "replay <execution-id>",
+		Short: "Re-send a recorded phase prompt to a model",
+		Long: `Re-send the prompt of a phase of a past run and show the recorded
+response next to the new one. Use it to debug a regression or compare how
+models answer the same prompt.
+
+When the run was recorded with audit.enabled (see the Audit Configuration),
+the exact system prompt, messages, max tokens and temperature are replayed
+as recorded; the run's skill and memory are not read again. Text redacted
+from the record is replayed redacted. When a phase made several calls, such
+as retries, the last is replayed unless --call picks another.
+
+Without audit records, the prompt is rebuilt from the run's history: the
+phase's current prompt template is rendered with the run's input and the
+outputs of the phases it depends on, and the current memory, and its last
+call is replayed. A warning says when the phase or memory changed since the
+run, so the rebuilt prompt may differ from the one sent.
+
+The prompt goes to the recorded model unless --model names another, on the
+recorded provider unless --provider names another or the model is served
+elsewhere.`,
+		Example: `  # Replay the review phase of a run on its original model
+  sr replay 3f2a9c1e-... --phase review
+
+  # Compare with another model
+  sr replay 3f2a9c1e-... --phase review --model gpt-4o
+
+  # Replay the first call of the phase of the latest run, as JSON
+  sr replay latest --phase review --call 1 -o json`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runReplay(cmd.Context(), args[0], opts)
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.Phase, "phase", "", "phase to replay (required when the run has several)")
+	cmd.Flags().StringVar(&opts.Model, "model", "", "model to replay the prompt on (default: the recorded model)")
+	cmd.Flags().StringVar(&opts.Provider, "provider", "", "provider to replay the prompt on (default: the recorded provider, or one serving --model)")
+	cmd.Flags().IntVar(&opts.Call, "call", 0, "which call of the phase to replay, from 1 (default: the last)")
+
+	return cmd
+}
+
+// replayResponseJSON is a response of a replayed prompt.
+type replayResponseJSON struct {
+	Provider     string        `json:"provider"`
+	Model        string        `json:"model"`
+	Content      string        `json:"content"`
+	Error        string        `json:"error,omitempty"`
+	InputTokens  int           `json:"input_tokens,omitempty"`
+	OutputTokens int           `json:"output_tokens,omitempty"`
+	Duration     time.Duration `json:"duration_ns,omitempty"`
+}
+
+// replayJSON is the JSON output of sr replay.
+type replayJSON struct {
+	ExecutionID string             `json:"execution_id"`
+	Source      string             `json:"source"` // audit or history
+	PhaseID     string             `json:"phase_id"`
+	Call        int                `json:"call"`
+	Calls       int                `json:"calls"`
+	Redactions  int                `json:"redactions,omitempty"`
+	Original    replayResponseJSON `json:"original"`
+	Replay      replayResponseJSON `json:"replay"`
+}
+
+func runReplay(ctx context.Context, ref string, opts replayOptions) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if opts.Call < 0 {
+		return fmt.Errorf("--call must be positive")
+	}
+
+	formatter := GetFormatter()
+	container := GetContainer()
+	if container == nil {
+		return fmt.Errorf("application not initialized")
+	}
+
+	var (
+		executionID string
+		records     []*domainWorkflow.AuditRecord
+		err         error
+	)
+	if auditLog := container.AuditLog(); auditLog != nil {
+		if executionID, records, err = replayRecords(ctx, auditLog, ref); err != nil {
+			return err
+		}
+	}
+
+	source := replaySourceAudit
+	var rec *domainWorkflow.AuditRecord
+	call, calls := 1, 1
+	if len(records) > 0 {
+		if rec, call, calls, err = selectReplayRecord(records, opts.Phase, opts.Call); err != nil {
+			return err
+		}
+	} else {
+		if opts.Call > 1 {
+			return fmt.Errorf("--call %d: run %s has no audit records, and its history keeps only the last call of each phase", opts.Call, ref)
+		}
+		source = replaySourceHistory
+		if executionID, rec, err = replayHistoryRecord(ctx, formatter, ref, opts.Phase); err != nil {
+			return err
+		}
+	}
+
+	req := replayRequest(rec, opts.Model)
+	prov, err := replayProvider(ctx, rec, req.ModelID, opts.Provider)
+	if err != nil {
+		return err
+	}
+
+	start := time.Now()
+	resp, callErr := prov.Complete(ctx, req)
+	replay := replayResponseJSON{Provider: prov.Info().Name, Model: req.ModelID, Duration: time.Since(start)}
+	if callErr != nil {
+		replay.Error = callErr.Error()
+	}
+	if resp != nil {
+		replay.Content = resp.Content
+		replay.InputTokens, replay.OutputTokens = resp.InputTokens, resp.OutputTokens
+		if resp.ModelUsed != "" {
+			replay.Model = resp.ModelUsed
+		}
+	}
+
+	result := replayJSON{
+		ExecutionID: executionID,
+		Source:      source,
+		PhaseID:     rec.PhaseID,
+		Call:        call,
+		Calls:       calls,
+		Redactions:  rec.Redactions,
+		Original: replayResponseJSON{
+			Provider:     rec.Provider,
+			Model:        rec.Model,
+			Content:      rec.Response,
+			Error:        rec.Error,
+			InputTokens:  rec.InputTokens,
+			OutputTokens: rec.OutputTokens,
+		},
+		Replay: replay,
+	}
+	if formatter.Format() == output.FormatJSON {
+		if err := formatter.JSON(result); err != nil {
+			return err
+		}
+	} else {
+		printReplay(formatter, result)
+	}
+
+	if callErr != nil {
+		return fmt.Errorf("replay failed: %w", callErr)
+	}
+	return nil
+}
+
+// Sources of replayed prompts.
+const (
+	replaySourceAudit   = "audit"
+	replaySourceHistory = "history"
+)
+
+// replayRecords returns the audit records of a run, given its execution ID
+// or, as for sr history, its run ID or "latest". A run without audit
+// records has none.
+func replayRecords(ctx context.Context, auditLog ports.AuditLogPort, ref string) (string, []*domainWorkflow.AuditRecord, error) {
+	executionID := ref
+	if ref == latestRunRef {
+		runs, _, err := historyRepositories()
+		if err != nil {
+			return "", nil, err
+		}
+		cp, err := findRun(ctx, runs, ref)
+		if err != nil {
+			return "", nil, err
+		}
+		executionID = cp.ExecutionID()
+	}
+
+	records, err := auditLog.Records(ctx, executionID)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to read audit log: %w", err)
+	}
+	if len(records) == 0 && ref != latestRunRef {
+		// The reference may be a run ID rather than an execution ID
+		if runs, _, err := historyRepositories(); err == nil {
+			if cp, err := runs.Get(ctx, ref); err == nil && cp.ExecutionID() != ref {
+				executionID = cp.ExecutionID()
+				if records, err = auditLog.Records(ctx, executionID); err != nil {
+					return "", nil, fmt.Errorf("failed to read audit log: %w", err)
+				}
+			}
+		}
+	}
+	return executionID, records, nil
+}
+
+// replayHistoryRecord rebuilds the last call of phase in a run from its
+// history, for runs without audit records: the phase of the skill is
+// rendered again with the run's input and phase outputs and the current
+// memory. An empty phase is allowed when the run has a single phase.
+func replayHistoryRecord(ctx context.Context, formatter *output.Formatter, ref, phase string) (string, *domainWorkflow.AuditRecord, error) {
+	runs, _, err := historyRepositories()
+	if err != nil {
+		return "", nil, err
+	}
+	cp, err := findRun(ctx, runs, ref)
+	if err != nil {
+		return "", nil, fmt.Errorf("no audit records or history for run %s: %w", ref, err)
+	}
+
+	results := cp.PhaseResults()
+	phases := slices.Sorted(maps.Keys(results))
+	if phase == "" {
+		if len(phases) != 1 {
+			return "", nil, fmt.Errorf("--phase is required; the run has phases %s", strings.Join(phases, ", "))
+		}
+		phase = phases[0]
+	}
+	data := results[phase]
+	switch {
+	case data == nil:
+		return "", nil, fmt.Errorf("no recorded result of phase %q; the run has phases %s", phase, strings.Join(phases, ", "))
+	case data.ModelUsed == "":
+		return "", nil, fmt.Errorf("phase %q of run %s called no model", phase, cp.ID())
+	}
+
+	var sk *skill.Skill
+	if registry := GetContainer().SkillRegistry(); registry != nil {
+		sk = registry.GetSkill(cp.SkillID())
+	}
+	if sk == nil {
+		return "", nil, fmt.Errorf("run %s has no audit records, and its skill %s is not installed to rebuild the prompt from", cp.ID(), cp.SkillID())
+	}
+	p, err := sk.GetPhase(phase)
+	if err != nil {
+		return "", nil, fmt.Errorf("phase %q is no longer in skill %s: %w", phase, sk.ID(), err)
+	}
+
+	memory := loadMemoryContent(false)
+	if data.PromptHash != "" && data.PromptHash != workflow.PhasePromptHash(p, memory) {
+		warnRun(formatter, fmt.Sprintf("phase %q or the memory changed since run %s; the rebuilt prompt may differ from the one sent", phase, cp.ID()))
+	}
+	req, err := workflow.PhaseRequest(p, memory, cp.PhaseOutputs())
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to rebuild the prompt of phase %q: %w", phase, err)
+	}
+
+	rec := &domainWorkflow.AuditRecord{
+		ExecutionID:  cp.ExecutionID(),
+		SkillID:      cp.SkillID(),
+		PhaseID:      phase,
+		Provider:     data.ProviderUsed,
+		Model:        data.ModelUsed,
+		MaxTokens:    req.MaxTokens,
+		Temperature:  req.Temperature,
+		Response:     data.Output,
+		Error:        data.ErrorMessage,
+		InputTokens:  data.InputTokens,
+		OutputTokens: data.OutputTokens,
+		Messages:     make([]domainWorkflow.AuditMessage, len(req.Messages)),
+	}
+	for i, msg := range req.Messages {
+		rec.Messages[i] = domainWorkflow.AuditMessage{Role: msg.Role, Content: msg.Content}
+	}
+	return cp.ExecutionID(), rec, nil
+}
+
+// selectReplayRecord picks the record of phase to replay: its call-th call,
+// counting from 1, or its last if call is 0. An empty phase is allowed when
+// every record belongs to the same phase. Returns the record, its call number
+// and the number of calls of the phase.
+func selectReplayRecord(records []*domainWorkflow.AuditRecord, phase string, call int) (*domainWorkflow.AuditRecord, int, int, error) {
+	var phases []string
+	for _, rec := range records {
+		if !slices.Contains(phases, rec.PhaseID) {
+			phases = append(phases, rec.PhaseID)
+		}
+	}
+	if phase == "" {
+		if len(phases) != 1 {
+			return nil, 0, 0, fmt.Errorf("--phase is required; the run has phases %s", strings.Join(phases, ", "))
+		}
+		phase = phases[0]
+	}
+
+	var calls []*domainWorkflow.AuditRecord
+	for _, rec := range records {
+		if rec.PhaseID == phase {
+			calls = append(calls, rec)
+		}
+	}
+	switch {
+	case len(calls) == 0:
+		return nil, 0, 0, fmt.Errorf("no recorded calls of phase %q; the run has phases %s", phase, strings.Join(phases, ", "))
+	case call > len(calls):
+		return nil, 0, 0, fmt.Errorf("--call %d: phase %q made %d call(s)", call, phase, len(calls))
+	case call == 0:
+		call = len(calls)
+	}
+	return calls[call-1], call, len(calls), nil
+}
+
+// replayRequest rebuilds the request of a record, on model if it is set.
+func replayRequest(rec *domainWorkflow.AuditRecord, model string) ports.CompletionRequest {
+	req := ports.CompletionRequest{
+		ModelID:      rec.Model,
+		SystemPrompt: rec.SystemPrompt,
+		MaxTokens:    rec.MaxTokens,
+		Temperature:  rec.Temperature,
+		Messages:     make([]ports.Message, len(rec.Messages)),
+	}
+	if model != "" {
+		req.ModelID = model
+	}
+	for i, msg := range rec.Messages {
+		req.Messages[i] = ports.Message{Role: msg.Role, Content: msg.Content}
+	}
+	return req
+}
+
+// replayProvider returns the provider to replay a record on: the named one,
+// else the recorded one if it serves model, else any serving model.
+func replayProvider(ctx context.Context, rec *domainWorkflow.AuditRecord, model, name string) (ports.ProviderPort, error) {
+	registry := GetContainer().ProviderRegistry()
+	if registry == nil {
+		return nil, fmt.Errorf("provider registry not available")
+	}
+	if name != "" {
+		return registry.GetRequired(name)
+	}
+	if prov := registry.Get(rec.Provider); prov != nil {
+		if model == rec.Model {
+			return prov, nil
+		}
+		if ok, err := prov.SupportsModel(ctx, model); err == nil && ok {
+			return prov, nil
+		}
+	}
+	prov, err := registry.FindByModel(ctx, model)
+	if err != nil {
+		return nil, fmt.Errorf("%w; use --provider to pick one", err)
+	}
+	return prov, nil
+}
+
+func printReplay(formatter *output.Formatter, r replayJSON) {
+	formatter.Header(fmt.Sprintf("Replay of phase %s, call %d of %d", r.PhaseID, r.Call, r.Calls))
+	formatter.Item("Execution", r.ExecutionID)
+	if r.Source == replaySourceHistory {
+		formatter.Item("Prompt", "rebuilt from the run's history")
+	}
+	if r.Redactions > 0 {
+		formatter.Warning("%d redacted match(es) in the recorded call are replayed as redacted text", r.Redactions)
+	}
+
+	for _, side := range []struct {
+		title string
+		resp  replayResponseJSON
+	}{{"Original", r.Original}, {"Replay", r.Replay}} {
+		formatter.Println("")
+		formatter.SubHeader(fmt.Sprintf("%s: %s/%s", side.title, side.resp.Provider, side.resp.Model))
+		if side.resp.InputTokens > 0 || side.resp.OutputTokens > 0 {
+			formatter.Item("Tokens", fmt.Sprintf("%d in, %d out", side.resp.InputTokens, side.resp.OutputTokens))
+		}
+		if side.resp.Duration > 0 {
+			formatter.Item("Duration", side.resp.Duration.Round(time.Millisecond).String())
+		}
+		if side.resp.Error != "" {
+			formatter.Error("%s", side.resp.Error)
+		}
+		if side.resp.Content != "" {
+			formatter.Println("%s", side.resp.Content)
+		}
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"os"
 	"os/signal"
 	"sync"
+	"sync/atomic"
 	"syscall"
 
 	"github.com/spf13/cobra"
@@ -25,9 +26,12 @@ var (
 
 // GlobalFlags holds the global CLI flags.
 type GlobalFlags struct {
-	ConfigFile string
-	Output     string
-	Verbose    bool
+	ConfigFile   string
+	RoutingFiles []string
+	Output       string
+	Verbose      bool
+	LogLevel     string
+	LogFormat    string
 }
 
 // AppContext holds the application runtime context.
@@ -63,8 +67,11 @@ Key features:
 		SilenceUsage:  true,
 		SilenceErrors: true,
 		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
-			// Skip initialization for help, version, init, and completion commands
-			if cmd.Name() == "help" || cmd.Name() == "version" || cmd.Name() == "completion" || cmd.Name() == "init" {
+			// Skip initialization for help, version, init, and completion commands,
+			// and for project aliases, which re-run the root command. sr skill
+			// init still initializes, to check custom routing profiles.
+			isInit := cmd.Name() == "init" && cmd.Parent() == cmd.Root()
+			if cmd.Name() == "help" || cmd.Name() == "version" || cmd.Name() == "completion" || isInit || isProjectAlias(cmd) {
 				return nil
 			}
 			return initializeApp()
@@ -73,20 +80,29 @@ Key features:
 
 	// Global flags
 	rootCmd.PersistentFlags().StringVarP(&globalFlags.ConfigFile, "config", "c", "", "config file path (default: ~/.skillrunner/config.yaml)")
+	rootCmd.PersistentFlags().StringArrayVar(&globalFlags.RoutingFiles, "routing", nil, "routing file merged over every other routing layer (repeatable)")
 	rootCmd.PersistentFlags().StringVarP(&globalFlags.Output, "output", "o", "text", "output format: text, json")
 	rootCmd.PersistentFlags().BoolVarP(&globalFlags.Verbose, "verbose", "v", false, "enable verbose output")
+	rootCmd.PersistentFlags().StringVar(&globalFlags.LogLevel, "log-level", "", "log level: debug, info, warn, error (default from config)")
+	rootCmd.PersistentFlags().StringVar(&globalFlags.LogFormat, "log-format", "", "log format: text, json (default from config)")
 
 	// Add subcommands
 	rootCmd.AddCommand(NewVersionCmd())
 	rootCmd.AddCommand(NewListCmd())
 	rootCmd.AddCommand(NewRunCmd())
 	rootCmd.AddCommand(NewPlanCmd())
+	rootCmd.AddCommand(NewSweepCmd())
 	rootCmd.AddCommand(NewStatusCmd())
 	rootCmd.AddCommand(NewAskCmd())
 	rootCmd.AddCommand(NewChatCmd())
 	rootCmd.AddCommand(NewImportCmd())
 	rootCmd.AddCommand(NewInitCmd())
 	rootCmd.AddCommand(NewMetricsCmd())
+	rootCmd.AddCommand(NewHistoryCmd())
+	rootCmd.AddCommand(NewCheckpointCmd())
+	rootCmd.AddCommand(NewReplayCmd())
+	rootCmd.AddCommand(NewSkillCmd())
+	rootCmd.AddCommand(NewConfigCmd())
 	rootCmd.AddCommand(NewContextCmd())
 	rootCmd.AddCommand(NewMemoryCmd())
 
@@ -97,6 +113,21 @@ Key features:
 	// Wave 10: Cache management
 	rootCmd.AddCommand(NewCacheCmd())
 
+	// Storage backend management
+	rootCmd.AddCommand(NewStorageCmd())
+
+	// Provider API keys in the OS keychain
+	rootCmd.AddCommand(NewAuthCmd())
+
+	// Model pricing
+	rootCmd.AddCommand(NewModelsCmd())
+
+	// Cost ledger reports
+	rootCmd.AddCommand(NewCostCmd())
+
+	// HTTP API for running skills as a service
+	rootCmd.AddCommand(NewServeCmd())
+
 	return rootCmd
 }
 
@@ -122,6 +153,21 @@ func initializeApp() error {
 		}
 		cfg = config.NewDefaultConfig()
 	}
+	for _, path := range globalFlags.RoutingFiles {
+		if _, err := os.Stat(path); err != nil {
+			return fmt.Errorf("invalid --routing file: %w", err)
+		}
+	}
+	cfg.Routing.Files = globalFlags.RoutingFiles
+	if globalFlags.LogLevel != "" {
+		cfg.Logging.Level = globalFlags.LogLevel
+	}
+	if globalFlags.LogFormat != "" {
+		cfg.Logging.Format = globalFlags.LogFormat
+	}
+	if err := cfg.Observability.Tracing.ApplyEnv(os.Environ()); err != nil {
+		return fmt.Errorf("invalid tracing environment: %w", err)
+	}
 
 	// Validate config
 	if err := cfg.Validate(); err != nil {
@@ -134,6 +180,14 @@ func initializeApp() error {
 		return fmt.Errorf("failed to initialize application: %w", err)
 	}
 
+	// A broken .skillrunner.yaml is ignored rather than blocking every command
+	if _, err := container.ProjectConfig(); err != nil {
+		warnRun(formatter, err.Error())
+	}
+	if err := container.RoutingError(); err != nil {
+		warnRun(formatter, fmt.Sprintf("routing files ignored: %v", err))
+	}
+
 	// Create cancellable context for graceful shutdown
 	ctx, cancel := context.WithCancel(context.Background())
 
@@ -216,6 +270,11 @@ func Shutdown() {
 	}
 }
 
+// handlesSignals is set by commands, such as sr serve, that shut down
+// gracefully on SIGINT and SIGTERM themselves. Execute then waits for them to
+// return after the first signal, and exits only on a second one.
+var handlesSignals atomic.Bool
+
 // Execute runs the root command with graceful shutdown support.
 func Execute() {
 	// Set up signal handling for graceful shutdown
@@ -226,19 +285,24 @@ func Execute() {
 	errChan := make(chan error, 1)
 	go func() {
 		rootCmd := NewRootCmd()
+		addProjectAliases(rootCmd)
 		errChan <- rootCmd.Execute()
 	}()
 
 	// Wait for either command completion or signal
 	select {
 	case err := <-errChan:
-		if err != nil {
-			formatter := GetFormatter()
-			formatter.Error("%s", err.Error())
-			Shutdown()
-			os.Exit(1)
-		}
+		exitOnError(err)
 	case sig := <-sigChan:
+		if handlesSignals.Load() {
+			select {
+			case err := <-errChan:
+				exitOnError(err)
+				Shutdown()
+				return
+			case sig = <-sigChan:
+			}
+		}
 		formatter := GetFormatter()
 		formatter.Warning("Received signal %v, shutting down...", sig)
 		Shutdown()
@@ -247,3 +311,12 @@ func Execute() {
 
 	Shutdown()
 }
+
+// exitOnError reports err on stderr and exits, if the command failed.
+func exitOnError(err error) {
+	if err != nil {
+		stderrFormatter().Error("%s", err.Error())
+		Shutdown()
+		os.Exit(1)
+	}
+}
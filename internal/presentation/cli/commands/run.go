@@ -2,20 +2,31 @@
 package commands
 
 import (
+	"bufio"
+	"cmp"
 	"context"
 	"fmt"
+	"io"
+	"maps"
 	"os"
+	"path/filepath"
 	"slices"
 	"sort"
 	"strings"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/spf13/cobra"
 
 	"github.com/jbctechsolutions/skillrunner/internal/application/ports"
+	appProvider "github.com/jbctechsolutions/skillrunner/internal/application/provider"
+	appSkills "github.com/jbctechsolutions/skillrunner/internal/application/skills"
 	"github.com/jbctechsolutions/skillrunner/internal/application/workflow"
 	"github.com/jbctechsolutions/skillrunner/internal/domain/provider"
 	"github.com/jbctechsolutions/skillrunner/internal/domain/skill"
+	domainWorkflow "github.com/jbctechsolutions/skillrunner/internal/domain/workflow"
+	"github.com/jbctechsolutions/skillrunner/internal/infrastructure/config"
+	"github.com/jbctechsolutions/skillrunner/internal/infrastructure/ingest"
 	infraMemory "github.com/jbctechsolutions/skillrunner/internal/infrastructure/memory"
 	"github.com/jbctechsolutions/skillrunner/internal/presentation/cli/output"
 )
@@ -26,10 +37,38 @@ type runFlags struct {
 	Stream       bool
 	NoMemory     bool
 	Resume       bool
+	Takeover     bool
 	NoCheckpoint bool
-	Force        bool
+	Fresh        bool
+	TraceFile    string
+	Batch        bool
+	ArtifactsDir string
+	InputFile    string
+	InputURL     string
+	Inputs       []string
+	ChunkTokens  int
+	OutputFile   string
+	OutputDir    string
+	ReportStyle  string
+	Within       time.Duration
+	NoWarmup     bool
+	Estimate     bool
+	Tags         []string
+	Reuse        bool
+	Format       string
+	Sample       string
+	SampleSeed   uint64
+
+	tags       map[string]string // Tags parsed into cost attribution tags
+	sampleRate float64           // Sample parsed into a fraction of foreach items
 }
 
+// Report styles of the run command's results.
+const (
+	reportStyleText     = "text"
+	reportStyleNotebook = "notebook"
+)
+
 var runOpts runFlags
 
 // NewRunCmd creates the run command for executing skills.
@@ -52,14 +91,59 @@ Examples:
   # Run with streaming output
   sr run summarize "Summarize this document" --stream
 
-  # Resume from last checkpoint
+  # Resume from last checkpoint without being asked
   sr run long-analysis "Complex analysis" --resume
 
   # Run without checkpoint persistence
   sr run quick-task "Simple task" --no-checkpoint
 
-  # Force new execution even if checkpoint exists
-  sr run analysis "Data analysis" --force
+  # Start over even if a checkpoint exists
+  sr run analysis "Data analysis" --fresh
+
+  # After editing the last phase's prompt, rerun only that phase
+  sr run code-review "Review this PR" --reuse
+
+  # Write the full run result as YAML for a CI job
+  sr run code-review "Review this PR" --format yaml > review.yaml
+
+  # Export a timeline viewable in chrome://tracing or ui.perfetto.dev
+  sr run code-review "Review this PR" --trace-file trace.json
+
+  # Run through the OpenAI Batch API at lower cost (results within 24h)
+  sr run bulk-classify "$(cat records.txt)" --batch
+
+  # Save generated images to a chosen directory
+  sr run blog-art "Release 2.0 announcement" --artifacts-dir ./images
+
+  # Summarize a document; PDF, DOCX and HTML are converted to text
+  sr run summarize --input-file paper.pdf
+
+  # Summarize the main content of a web page
+  sr run summarize --input-url https://example.com/blog/post
+
+  # Read the request from stdin and write only the final output to stdout
+  git diff --cached | sr run review-diff - > review.md
+
+  # Review the Go files under src (quote globs so the shell keeps **)
+  sr run code-review "Look for error handling bugs" --input './src/**/*.go'
+
+  # Save the final output, and each phase's output under a run directory
+  sr run code-review "Review this PR" --output-file 'reviews/{{.timestamp}}.md' --output-dir 'runs/{{.execution_id}}'
+
+  # Save a notebook-style Markdown report of each phase's prompt and output
+  sr run code-review "Review the staged changes" --report-style notebook > review.md
+
+  # Run the project's default_skill from .skillrunner.yaml
+  sr run "Review the staged changes"
+
+  # Fit the run into a minute, using faster models if history says it must
+  sr run code-review "Review the staged changes" --within 60s
+
+  # Estimate the tokens and cost of each phase without running anything
+  sr run code-review "Review the staged changes" --estimate
+
+  # Attribute the run's costs to a team and a ticket
+  sr run code-review "Review this PR" --tag team=search --tag ticket=JIRA-123
 
 Routing Profiles:
   cheap     - Prioritize cost, use local/cheaper models
@@ -68,37 +152,160 @@ Routing Profiles:
 
 Crash Recovery:
   By default, execution state is checkpointed after each phase batch.
-  Use --resume to continue from the last checkpoint if available.
+  When an interrupted run of the skill on the same input exists, sr run asks
+  whether to continue it from its last completed batch. Use --resume to
+  continue it, or --fresh to start a new execution, without being asked;
+  one of them is required when the input is not a terminal.
+  A checkpoint another machine updated in the last 15 minutes may still be
+  running there; --takeover resumes it anyway.
   Use --no-checkpoint to disable checkpointing (for testing or short tasks).
-  Use --force to start a new execution even if a checkpoint exists.
+
+Batch Mode:
+  --batch submits the phases of each DAG batch as one job to the provider's
+  batch API (currently OpenAI), polls until it finishes and records the
+  results in the checkpoint. Batch jobs cost about half as much but may take
+  up to 24 hours. If the run is interrupted, --resume collects the submitted
+  job instead of resubmitting it. Batch items are redacted, audited and
+  counted against budgets like other requests. Items that fail, or whose
+  output echoes their input under a retrying similarity guard, run again
+  directly under the phase's retry policy; glossaries apply as usual.
+
+Time Budget:
+  --within estimates the run's wall time from the phase durations recorded
+  in run history. When the estimate exceeds the budget, phases of a DAG
+  batch all run at once, phases move to cheaper, faster routing profiles
+  and phases marked optional: true are left out until it fits. The run is
+  stopped when the budget runs out.
+
+Cost Estimate:
+  --estimate resolves each phase's model through profile routing, counts
+  the tokens of its rendered prompt (plus the estimated outputs of the
+  phases it depends on), assumes half of max_tokens of output, and prints
+  the tokens and cost per phase and in total. Nothing is executed.
+
+Cost Attribution:
+  Each --tag name=value is recorded on the run's checkpoint and on its cost
+  ledger entries, so that 'sr cost report --by tag:<name>' can total costs
+  by team, ticket or any other business dimension.
+
+Model Warm-up:
+  While a DAG batch runs, the models of the next batch are warmed up in the
+  background: Ollama loads them into memory and cloud providers open their
+  connections, so later phases do not wait on a cold start. Use --no-warmup
+  to skip this, e.g. when memory is too tight to hold several local models.
+
+Input Files:
+  Each --input names a file, a directory read recursively, or a glob
+  pattern in which ** matches any number of directories. Files that the
+  repository's .gitignore files ignore, the .git directory and binary files
+  are skipped, except files named explicitly. The files are appended to the
+  request, each under its path in a fenced code block, and are also
+  available to prompt templates as {{._files}}, their paths as
+  {{._file_paths}} and chunks of about --chunk-tokens tokens as the JSON
+  array {{._file_chunks}}, e.g. for the items of a foreach phase.
+
+Unix Filter:
+  A request of - is read from stdin. The run then writes only the final
+  output to stdout, and progress, warnings and errors to stderr; a run that
+  fails exits with a non-zero status. With --format json or yaml, or
+  --report-style notebook, stdin is read the same way and the report goes
+  to stdout. Runs reading stdin do not offer to resume an interrupted run;
+  use --resume.
+
+Machine-Readable Output:
+  --format json or yaml writes the run result to stdout as one document:
+  the execution ID, status, tokens, cost and cache statistics of the run,
+  and each phase's status, output, tokens, cost, provider and model, and
+  whether it fell back from its pinned provider or model. Warnings go to
+  stderr. --format overrides the global -o flag.
+
+Output Files:
+  When the run completes, --output-file writes the final output to a file
+  and --output-dir writes each phase's output to <phase>.md, or
+  <phase>.json for JSON phases, in a directory. Both names are templates
+  with {{.skill}}, {{.execution_id}}, {{.timestamp}} (20060102-150405) and
+  {{.date}}; missing directories are created. The skill's outputs list
+  delivers outputs to files, stdout or the clipboard as well. (-o/--output
+  sets the output format.)
+
+Sampling:
+  --sample runs foreach phases on a reproducible sample of their items,
+  given as a percentage (10%) or a fraction (0.1), to try a skill on a large
+  input cheaply. The same --sample-seed selects the same items; each sampled
+  item keeps its index among all the items in {{._index}}. The items run are
+  recorded in the checkpoint and in the phase's sample in --format json.
+
+Generated Files:
+  Phases with output_format: image save their images under
+  .skillrunner/artifacts/<skill>-<timestamp> (or --artifacts-dir) and pass
+  the file paths to downstream phases.
 
 Note: Streaming mode (--stream) does not support checkpointing. Use standard
 mode for long-running tasks that may need crash recovery.`,
-		Args: cobra.ExactArgs(2),
+		Args: cobra.RangeArgs(0, 2),
 		RunE: runSkill,
 	}
 
 	// Define flags
 	cmd.Flags().StringVarP(&runOpts.Profile, "profile", "p", skill.ProfileBalanced,
-		fmt.Sprintf("routing profile: %s, %s, %s", skill.ProfileCheap, skill.ProfileBalanced, skill.ProfilePremium))
+		fmt.Sprintf("routing profile: %s, %s, %s or a custom profile", skill.ProfileCheap, skill.ProfileBalanced, skill.ProfilePremium))
 	cmd.Flags().BoolVarP(&runOpts.Stream, "stream", "s", false, "enable streaming output")
 	cmd.Flags().BoolVar(&runOpts.NoMemory, "no-memory", false, "disable memory injection (MEMORY.md/CLAUDE.md)")
 	cmd.Flags().BoolVar(&runOpts.Resume, "resume", false, "resume from last checkpoint if available")
+	cmd.Flags().BoolVar(&runOpts.Takeover, "takeover", false, "resume a checkpoint another machine updated recently and may still be running")
 	cmd.Flags().BoolVar(&runOpts.NoCheckpoint, "no-checkpoint", false, "disable checkpoint persistence")
-	cmd.Flags().BoolVarP(&runOpts.Force, "force", "f", false, "start new execution even if checkpoint exists")
+	cmd.Flags().BoolVar(&runOpts.Fresh, "fresh", false, "start a new execution even if an interrupted one can be resumed")
+	cmd.Flags().BoolVarP(&runOpts.Fresh, "force", "f", false, "start a new execution even if an interrupted one can be resumed")
+	_ = cmd.Flags().MarkDeprecated("force", "use --fresh instead")
+	cmd.Flags().StringVar(&runOpts.TraceFile, "trace-file", "", "write the execution timeline to a chrome://tracing JSON file")
+	cmd.Flags().BoolVar(&runOpts.Batch, "batch", false, "run phases through the provider's asynchronous batch API (non-interactive, lower cost)")
+	cmd.Flags().StringVar(&runOpts.ArtifactsDir, "artifacts-dir", "", "directory for files generated by the skill, such as images")
+	cmd.Flags().StringVar(&runOpts.InputFile, "input-file", "", "read the request input from a file (PDF, DOCX and HTML are converted to text)")
+	cmd.Flags().StringVar(&runOpts.ReportStyle, "report-style", reportStyleText, "how results are reported: text or notebook (Markdown with each phase's prompt and output)")
+	cmd.Flags().StringVar(&runOpts.InputURL, "input-url", "", "fetch a web page and use its main content as the request input")
+	cmd.Flags().StringArrayVar(&runOpts.Inputs, "input", nil, "file, directory or glob pattern (e.g. './src/**/*.go') of files to read as input, skipping what .gitignore ignores (repeatable)")
+	cmd.Flags().IntVar(&runOpts.ChunkTokens, "chunk-tokens", ingest.DefaultChunkTokens, "size in tokens of the chunks of the input files in {{._file_chunks}}")
+	cmd.Flags().StringVar(&runOpts.OutputFile, "output-file", "", "write the final output to a file; the name may use {{.skill}}, {{.execution_id}}, {{.timestamp}} and {{.date}}")
+	cmd.Flags().StringVar(&runOpts.OutputDir, "output-dir", "", "write each phase's output to a file of its own in a directory, named like --output-file")
+	cmd.Flags().DurationVar(&runOpts.Within, "within", 0, "time budget for the run (e.g. 60s); faster profiles and fewer optional phases are used to fit it")
+	cmd.Flags().BoolVar(&runOpts.NoWarmup, "no-warmup", false, "do not warm up the models of upcoming phases while earlier phases run")
+	cmd.Flags().BoolVar(&runOpts.Estimate, "estimate", false, "estimate the tokens and cost of each phase without running the skill")
+	cmd.Flags().StringArrayVar(&runOpts.Tags, "tag", nil, "cost attribution tag as name=value, recorded with the run's costs (repeatable)")
+	cmd.Flags().BoolVar(&runOpts.Reuse, "reuse", false, "reuse the results of phases unchanged since the last run on the same input instead of running them again")
+	cmd.Flags().StringVar(&runOpts.Sample, "sample", "", "run foreach phases on a sample of their items, e.g. 10% or 0.1")
+	cmd.Flags().Uint64Var(&runOpts.SampleSeed, "sample-seed", 1, "seed that selects the items of --sample")
+	cmd.Flags().StringVar(&runOpts.Format, "format", "", "output format: text, json or yaml; json and yaml write the full run result for scripts and CI (default: uses global --output flag)")
 
 	return cmd
 }
 
 // runSkill executes the skill workflow.
 func runSkill(cmd *cobra.Command, args []string) error {
-	skillName := args[0]
-	request := args[1]
+	skillName, request, err := skillAndRequest(args, runOpts.InputFile != "" || runOpts.InputURL != "" || len(runOpts.Inputs) > 0)
+	if err != nil {
+		return err
+	}
+	stdin := request == stdinRequest
+	if stdin {
+		if request, err = readStdinRequest(os.Stdin); err != nil {
+			return err
+		}
+	}
 
 	// Validate profile
+	runOpts.Profile = projectProfile(cmd, runOpts.Profile)
 	if err := validateProfile(runOpts.Profile); err != nil {
 		return err
 	}
+	if runOpts.tags, err = provider.ParseCostTags(runOpts.Tags); err != nil {
+		return err
+	}
+	runOpts.sampleRate = 0
+	if runOpts.Sample != "" {
+		if runOpts.sampleRate, err = domainWorkflow.ParseSampleRate(runOpts.Sample); err != nil {
+			return err
+		}
+	}
 
 	formatter := GetFormatter()
 	container := GetContainer()
@@ -107,6 +314,48 @@ func runSkill(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("application not initialized")
 	}
 
+	if err := applyRunFormat(formatter); err != nil {
+		return err
+	}
+	if err := validateReportStyle(formatter); err != nil {
+		return err
+	}
+
+	// Reading the request from stdin makes the text report a Unix filter
+	filter := stdin && !formatter.Format().Structured() && runOpts.ReportStyle != reportStyleNotebook
+	if filter {
+		if runOpts.Stream {
+			return fmt.Errorf("--stream cannot be combined with reading the request from stdin")
+		}
+		formatter = stderrFormatter()
+	}
+
+	if runOpts.InputFile != "" {
+		input, err := container.DocumentConverters().ConvertFile(context.Background(), runOpts.InputFile)
+		if err != nil {
+			return err
+		}
+		request = joinInput(request, input)
+	}
+
+	if runOpts.InputURL != "" {
+		page, err := container.WebFetcher().Fetch(context.Background(), runOpts.InputURL)
+		if err != nil {
+			return err
+		}
+		request = joinInput(request, page.Markdown)
+	}
+
+	var inputFiles map[string]string
+	if len(runOpts.Inputs) > 0 {
+		files, err := ingest.Load(runOpts.Inputs)
+		if err != nil {
+			return err
+		}
+		request = joinInput(request, ingest.Concat(files))
+		inputFiles = ingest.Variables(files, runOpts.ChunkTokens)
+	}
+
 	// Get skill registry and load skill
 	registry := container.SkillRegistry()
 	if registry == nil {
@@ -122,6 +371,15 @@ func runSkill(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("skill not found: %s", skillName)
 	}
 
+	if runOpts.Estimate {
+		return runEstimate(context.Background(), formatter, sk, request)
+	}
+
+	// Report missing tools before any phase runs
+	if err := appSkills.VerifyRequirements(sk); err != nil {
+		return err
+	}
+
 	// Get a provider for execution
 	providerRegistry := container.ProviderRegistry()
 	providers := providerRegistry.ListProviders()
@@ -131,27 +389,35 @@ func runSkill(cmd *cobra.Command, args []string) error {
 
 	// Select provider based on profile
 	provider := selectProvider(providers, runOpts.Profile)
+	if runOpts.Batch {
+		if runOpts.Stream {
+			return fmt.Errorf("--batch cannot be combined with --stream")
+		}
+		provider = selectBatchProvider(providers, provider)
+		if provider == nil {
+			return fmt.Errorf("--batch requires a provider with a batch API (openai); none is configured")
+		}
+	}
 	if provider == nil {
 		return fmt.Errorf("no suitable provider found for profile: %s", runOpts.Profile)
 	}
+	if runOpts.Within < 0 {
+		return fmt.Errorf("--within must be positive")
+	}
+	if runOpts.Within > 0 && runOpts.Batch {
+		return fmt.Errorf("--within cannot be combined with --batch")
+	}
+	if runOpts.Resume && runOpts.Fresh {
+		return fmt.Errorf("--resume cannot be combined with --fresh")
+	}
+	if runOpts.Reuse && (runOpts.NoCheckpoint || runOpts.Stream) {
+		return fmt.Errorf("--reuse needs checkpoints; it cannot be combined with --no-checkpoint or --stream")
+	}
 
 	ctx := context.Background()
 
 	// Load memory content (unless disabled)
-	var memoryContent string
-	appCtx := GetAppContext()
-	memoryEnabled := appCtx != nil && appCtx.Config != nil && appCtx.Config.Memory.Enabled
-	if memoryEnabled && !runOpts.NoMemory {
-		cwd, err := os.Getwd()
-		if err == nil {
-			maxTokens := appCtx.Config.Memory.MaxTokens
-			loader := infraMemory.NewLoader(maxTokens)
-			mem, err := loader.Load(cwd)
-			if err == nil && !mem.IsEmpty() {
-				memoryContent = mem.Combined()
-			}
-		}
-	}
+	memoryContent := loadMemoryContent(runOpts.NoMemory)
 
 	// Build checkpoint config
 	cpConfig := workflow.CheckpointConfig{
@@ -159,46 +425,404 @@ func runSkill(cmd *cobra.Command, args []string) error {
 		Port:      container.WorkflowCheckpointRepository(),
 		Resume:    runOpts.Resume,
 		MachineID: container.MachineID(),
+		Takeover:  runOpts.Takeover,
+		Tags:      runOpts.tags,
+		Reuse:     runOpts.Reuse,
 	}
 
-	// Check for existing checkpoint if not resuming and not forcing
-	if cpConfig.Enabled && !runOpts.Resume && !runOpts.Force && cpConfig.Port != nil {
+	// Offer to resume an interrupted run of the skill on the same input;
+	// runs reading stdin cannot be asked and start a new run
+	if cpConfig.Enabled && !runOpts.Resume && !runOpts.Fresh && !stdin && cpConfig.Port != nil {
 		existingCP, _ := workflow.GetExistingCheckpoint(ctx, cpConfig.Port, sk.ID(), request)
 		if existingCP != nil {
-			formatter.Warning("An incomplete execution exists for this skill/input (progress: %s).", existingCP.Progress())
-			formatter.Warning("Use --resume to continue, or --force to start fresh.")
-			return fmt.Errorf("checkpoint exists; use --resume or --force")
+			resume, err := confirmResume(formatter, existingCP, os.Stdin, isTerminal(os.Stdin))
+			if err != nil {
+				return err
+			}
+			if resume {
+				cpConfig.Resume = true
+				cpConfig.ResumeID = existingCP.ID()
+			}
 		}
 	}
 
 	// Get cost calculator for pricing
 	costCalc := container.CostCalculator()
 
-	// JSON output for scripting (non-streaming)
-	if formatter.Format() == output.FormatJSON {
-		executorConfig := workflow.DefaultExecutorConfig()
-		executorConfig.MemoryContent = memoryContent
+	// Honor per-phase provider pins; soft pins fall back with a warning
+	fallbacks := newPinFallbacks()
+	pinSelector := appProvider.NewPinSelector(providerRegistry, fallbacks.warner(formatter))
+
+	executorConfig := workflow.DefaultExecutorConfig()
+	executorConfig.MemoryContent = memoryContent
+	executorConfig.ProviderSelector = pinSelector
+	if experiments := appProvider.NewExperimentRouter(container.RoutingConfiguration(), providerRegistry); experiments != nil {
+		executorConfig.Experiments = experiments
+	}
+	if runOpts.Within > 0 {
+		if sk, err = planWithin(ctx, formatter, sk, &executorConfig); err != nil {
+			return err
+		}
+	}
+	applyPowerPolicy(ctx, formatter, &executorConfig, pinSelector, container.RoutingConfiguration().PowerPolicy)
+	if err := applyBudgets(ctx, formatter, &executorConfig, container.RoutingConfiguration().Budgets, costCalc); err != nil {
+		return err
+	}
+	executorConfig.Transcriber = container.Transcriber()
+	executorConfig.ImageGenerator = container.ImageGenerator()
+	executorConfig.ArtifactDir = artifactsDir(sk)
+	executorConfig.ConcurrencyGroups = container.ConcurrencyGroups()
+	executorConfig.Skills = container.SkillRegistry()
+	executorConfig.InputFiles = inputFiles
+	executorConfig.SampleRate = runOpts.sampleRate
+	executorConfig.SampleSeed = runOpts.SampleSeed
+	applyRouting(formatter, &executorConfig)
+	if runOpts.Batch {
+		executorConfig.BatchAPI = true
+		executorConfig.Timeout = workflow.BatchJobTimeout
+	}
+	executorConfig.Warmup = !runOpts.NoWarmup
+
+	// JSON or YAML report for scripting (non-streaming)
+	if formatter.Format().Structured() {
 		executor := workflow.NewCheckpointingExecutor(provider, executorConfig, cpConfig)
-		return runSkillJSON(ctx, executor, sk, request, provider, costCalc)
+		return runSkillReport(ctx, executor, sk, request, provider, costCalc, fallbacks)
 	}
 
 	// Streaming output mode
 	// Note: Checkpointing is not supported in streaming mode. For long-running
 	// tasks that need crash recovery, use standard (non-streaming) mode.
 	if runOpts.Stream {
-		streamingConfig := workflow.DefaultExecutorConfig()
-		streamingConfig.MemoryContent = memoryContent
-		streamingExecutor := workflow.NewStreamingExecutor(provider, streamingConfig)
+		streamingExecutor := workflow.NewStreamingExecutor(provider, executorConfig)
 		return runSkillStreaming(ctx, streamingExecutor, sk, request, provider, formatter)
 	}
 
-	// Standard text output with progress display
-	executorConfig := workflow.DefaultExecutorConfig()
-	executorConfig.MemoryContent = memoryContent
 	executor := workflow.NewCheckpointingExecutor(provider, executorConfig, cpConfig)
+
+	// Only the final output on stdout
+	if filter {
+		return runSkillFilter(ctx, executor, sk, request, formatter, os.Stdout, costCalc)
+	}
+
+	// Notebook-style Markdown report
+	if runOpts.ReportStyle == reportStyleNotebook {
+		return runSkillNotebook(ctx, executor, sk, request, provider, formatter, costCalc)
+	}
+
+	// Standard text output with progress display
 	return runSkillText(ctx, executor, sk, request, provider, formatter, costCalc)
 }
 
+// confirmResume asks whether to continue an interrupted run of the skill on
+// the same input from its last completed batch. When the input is not
+// interactive, the run stops and --resume or --fresh has to be given instead.
+func confirmResume(formatter *output.Formatter, cp *domainWorkflow.WorkflowCheckpoint, in io.Reader, interactive bool) (bool, error) {
+	formatter.Warning("An interrupted run of this skill on the same input exists (checkpoint %s, %s batches done, updated %s).",
+		cp.ID(), cp.Progress(), formatRelativeTime(cp.UpdatedAt()))
+	if !interactive || formatter.Format().Structured() {
+		return false, fmt.Errorf("checkpoint exists; use --resume to continue it or --fresh to start over")
+	}
+
+	p := &prompter{reader: bufio.NewReader(in), formatter: formatter}
+	return p.promptYesNo("Resume from the last completed batch?", true)
+}
+
+// isTerminal reports whether f is an interactive terminal.
+func isTerminal(f *os.File) bool {
+	stat, err := f.Stat()
+	return err == nil && stat.Mode()&os.ModeCharDevice != 0
+}
+
+// currentProject returns the configuration of the .skillrunner.yaml project
+// containing the working directory, or nil if there is none.
+func currentProject() *config.ProjectConfig {
+	container := GetContainer()
+	if container == nil {
+		return nil
+	}
+	project, _ := container.ProjectConfig()
+	return project
+}
+
+// skillAndRequest returns the skill and request of the run arguments. A
+// lone request runs the project's default skill; with an input file, URL
+// or files the request is optional.
+func skillAndRequest(args []string, hasInput bool) (string, string, error) {
+	switch {
+	case len(args) == 2:
+		return args[0], args[1], nil
+	case len(args) == 1 && hasInput:
+		return args[0], "", nil
+	}
+
+	if project := currentProject(); project != nil && project.DefaultSkill != "" {
+		if len(args) == 1 {
+			return project.DefaultSkill, args[0], nil
+		}
+		if hasInput {
+			return project.DefaultSkill, "", nil
+		}
+	}
+	return "", "", fmt.Errorf("accepts <skill> <request>; only a request may be given inside a project with default_skill set in %s", config.ProjectConfigFileName)
+}
+
+// joinInput appends the text of an input file, page or files to the request.
+func joinInput(request, input string) string {
+	if request == "" {
+		return input
+	}
+	return request + "\n\n" + input
+}
+
+// projectProfile returns the project's default profile when the --profile
+// flag was not given, and profile otherwise.
+func projectProfile(cmd *cobra.Command, profile string) string {
+	if cmd.Flags().Changed("profile") {
+		return profile
+	}
+	if project := currentProject(); project != nil && project.DefaultProfile != "" {
+		return project.DefaultProfile
+	}
+	return profile
+}
+
+// loadMemoryContent returns the memory to inject into prompts, or "" if memory
+// is disabled. Inside a .skillrunner.yaml project, project memory is read from
+// the project root and includes the project's memory files.
+func loadMemoryContent(disabled bool) string {
+	appCtx := GetAppContext()
+	if disabled || appCtx == nil || appCtx.Config == nil || !appCtx.Config.Memory.Enabled {
+		return ""
+	}
+
+	projectDir, err := os.Getwd()
+	if err != nil {
+		return ""
+	}
+	var files []string
+	if project := currentProject(); project != nil {
+		projectDir = project.Root()
+		files = project.MemoryFiles()
+	}
+
+	loader := infraMemory.NewLoader(appCtx.Config.Memory.MaxTokens)
+	mem, err := loader.LoadWithFiles(projectDir, files)
+	if err != nil || mem.IsEmpty() {
+		return ""
+	}
+	return mem.Combined()
+}
+
+// runBudget returns the cost a single run may spend: the project's
+// budget.max_cost_per_run, or the organization policy's if that is
+// stricter, and the project when its budget applies. 0 is no limit.
+func runBudget() (float64, *config.ProjectConfig) {
+	var local float64
+	project := currentProject()
+	if project != nil {
+		local = project.Budget.MaxCostPerRun
+	}
+	var policy *config.Policy
+	if container := GetContainer(); container != nil {
+		policy, _ = container.Policy()
+	}
+
+	limit := policy.MaxCostPerRun(local)
+	if limit != local {
+		project = nil
+	}
+	return limit, project
+}
+
+// checkProjectBudget warns when a run cost more than its per-run budget.
+// The budget guard of applyBudgets stops cloud spending once the budget is
+// reached, but the phase that reaches it may still pass it.
+func checkProjectBudget(formatter *output.Formatter, result *workflow.ExecutionResult) {
+	if result == nil {
+		return
+	}
+
+	limit, project := runBudget()
+	if limit <= 0 || result.TotalCost <= limit {
+		return
+	}
+	if project == nil {
+		warnRun(formatter, fmt.Sprintf("run cost %s exceeds the organization policy budget of %s per run",
+			formatCost(result.TotalCost), formatCost(limit)))
+		return
+	}
+	warnRun(formatter, fmt.Sprintf("run cost %s exceeds the project budget of %s per run (%s)",
+		formatCost(result.TotalCost), formatCost(limit), project.Path))
+}
+
+// applyBudgets enforces the routing budgets and the per-run budget on the
+// run's cloud providers: it warns at their soft limits, and once a cap is
+// reached moves phases to a local provider or fails them.
+func applyBudgets(ctx context.Context, formatter *output.Formatter, cfg *workflow.ExecutorConfig, budgets *config.BudgetConfiguration, costCalc *provider.CostCalculator) error {
+	perRun, _ := runBudget()
+	if budgets == nil && perRun <= 0 {
+		return nil
+	}
+	if budgets == nil {
+		budgets = &config.BudgetConfiguration{}
+	}
+
+	container := GetContainer()
+	guard, err := appProvider.NewBudgetGuard(ctx, budgets, container.CostLedgerRepository(), costCalc, func(msg string) {
+		warnRun(formatter, msg)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to apply budgets: %w", err)
+	}
+	guard.CapRun(perRun)
+	cfg.ProviderSelector = appProvider.NewBudgetSelector(cfg.ProviderSelector, container.ProviderRegistry(), guard)
+	return nil
+}
+
+// applyRouting routes phases through the routing configuration: phases
+// with a custom profile run on the provider and model it selects, requests
+// are checked against context windows and stalled phases move to their
+// profile's fallback. An invalid routing configuration is warned about
+// and the run goes on without it.
+func applyRouting(formatter *output.Formatter, cfg *workflow.ExecutorConfig) {
+	if router := newRunRouter(formatter); router != nil {
+		routeWith(cfg, router)
+	}
+}
+
+// newRunRouter returns the router of the routing configuration, or nil
+// with a warning naming what a run loses without it if the configuration
+// is invalid.
+func newRunRouter(formatter *output.Formatter) *appProvider.Router {
+	container := GetContainer()
+	router, err := appProvider.NewRouter(container.RoutingConfiguration(), container.ProviderRegistry())
+	if err != nil {
+		warnRun(formatter, fmt.Sprintf("routing configuration not applied (%v): context windows are not checked, and custom profiles and stall fallbacks are unavailable", err))
+		return nil
+	}
+	return router
+}
+
+// routeWith routes phases through router, as applyRouting does, and
+// records the latency of their requests for its fastest selection.
+func routeWith(cfg *workflow.ExecutorConfig, router *appProvider.Router) {
+	cfg.ContextWindows = router
+	cfg.Fallbacks = router
+	cfg.Profiles = router
+	cfg.Latency = router
+}
+
+// pinFallbackWarner returns a pin fallback handler that warns once per
+// phase, since a phase's provider is also resolved to warm up its model.
+func pinFallbackWarner(formatter *output.Formatter) appProvider.PinFallbackHandler {
+	return newPinFallbacks().warner(formatter)
+}
+
+// warnPinFallback reports that a soft-pinned or model-pinned phase fell back
+// to another provider.
+func warnPinFallback(formatter *output.Formatter, phase *skill.Phase, fallback ports.ProviderPort, reason error) {
+	if !phase.IsPinned() {
+		warnRun(formatter, fmt.Sprintf("phase %q: pinned model %q unavailable, falling back to profile routing on %q",
+			phase.ID, phase.Model, fallback.Info().Name))
+		return
+	}
+	warnRun(formatter, fmt.Sprintf("phase %q: pinned provider %q unavailable, falling back to %q (%v)",
+		phase.ID, phase.Provider, fallback.Info().Name, reason))
+}
+
+// applyRunFormat switches the formatter to the format --format names, if
+// set.
+func applyRunFormat(formatter *output.Formatter) error {
+	if runOpts.Format == "" {
+		return nil
+	}
+	format, err := output.ParseFormat(runOpts.Format)
+	if err != nil || format == output.FormatTable {
+		return fmt.Errorf("invalid format: %s (valid options: text, json, yaml)", runOpts.Format)
+	}
+	formatter.SetFormat(format)
+	formatter.SetColor(!format.Structured())
+	return nil
+}
+
+// warnRun prints a warning during skill execution.
+// In JSON and YAML modes the warning goes to stderr so stdout remains a
+// valid document.
+func warnRun(formatter *output.Formatter, msg string) {
+	if formatter.Format().Structured() || runOpts.ReportStyle == reportStyleNotebook {
+		fmt.Fprintln(os.Stderr, "warning: "+msg)
+		return
+	}
+	_ = formatter.Warning("%s", msg)
+}
+
+// exportTrace writes the execution timeline to --trace-file, if set.
+// Failing to write the trace does not fail the run.
+func exportTrace(formatter *output.Formatter, result *workflow.ExecutionResult) {
+	if runOpts.TraceFile == "" || result == nil {
+		return
+	}
+
+	if err := writeTraceFile(runOpts.TraceFile, result); err != nil {
+		warnRun(formatter, fmt.Sprintf("failed to write trace file: %v", err))
+	}
+}
+
+// recordCosts adds the cost of each phase that called a model to the cost
+// ledger, with the run's cost attribution tags, for sr cost report. Failing
+// to record does not fail the run.
+func recordCosts(ctx context.Context, formatter *output.Formatter, result *workflow.ExecutionResult, costCalc *provider.CostCalculator, tags map[string]string) {
+	container := GetContainer()
+	if container == nil || container.CostLedgerRepository() == nil || costCalc == nil || result == nil {
+		return
+	}
+
+	// Runs without a checkpoint have no execution ID; the ledger still needs one
+	executionID := result.ExecutionID
+	if executionID == "" {
+		executionID = uuid.New().String()
+	}
+
+	for _, pr := range result.PhaseResults {
+		if pr.CacheHit || pr.ModelUsed == "" || pr.InputTokens+pr.OutputTokens == 0 {
+			continue
+		}
+		record := &provider.CostRecord{
+			CostBreakdown: *costCalc.CalculateOrZero(pr.ModelUsed, pr.InputTokens, pr.OutputTokens),
+			SkillID:       result.SkillID,
+			ExecutionID:   executionID,
+			PhaseID:       pr.PhaseID,
+			RecordedAt:    pr.EndTime,
+			Tags:          tags,
+		}
+		if pr.ProviderUsed != "" {
+			record.Provider = pr.ProviderUsed
+		}
+		if record.RecordedAt.IsZero() {
+			record.RecordedAt = time.Now()
+		}
+		if err := container.CostLedgerRepository().Record(ctx, record); err != nil {
+			warnRun(formatter, fmt.Sprintf("failed to record costs: %v", err))
+			return
+		}
+	}
+}
+
+// writeTraceFile writes result to path in Chrome trace format.
+func writeTraceFile(path string, result *workflow.ExecutionResult) error {
+	f, err := os.Create(filepath.Clean(path))
+	if err != nil {
+		return err
+	}
+
+	if err := workflow.WriteChromeTrace(f, result); err != nil {
+		_ = f.Close()
+		return err
+	}
+
+	return f.Close()
+}
+
 // selectProvider chooses a provider based on the routing profile.
 func selectProvider(providers []ports.ProviderPort, profile string) ports.ProviderPort {
 	if len(providers) == 0 {
@@ -233,11 +857,26 @@ func selectProvider(providers []ports.ProviderPort, profile string) ports.Provid
 	}
 }
 
-// runSkillJSON executes the skill and outputs results as JSON.
-func runSkillJSON(ctx context.Context, executor workflow.Executor, sk *skill.Skill, request string, prov ports.ProviderPort, costCalc *provider.CostCalculator) error {
+// selectBatchProvider returns preferred if it has a batch API, otherwise the
+// first provider that does, or nil if none does.
+func selectBatchProvider(providers []ports.ProviderPort, preferred ports.ProviderPort) ports.ProviderPort {
+	if _, ok := ports.As[ports.BatchProviderPort](preferred); ok {
+		return preferred
+	}
+	for _, p := range providers {
+		if _, ok := ports.As[ports.BatchProviderPort](p); ok {
+			return p
+		}
+	}
+	return nil
+}
+
+// runSkillReport executes the skill and writes its report as JSON or YAML,
+// in the formatter's format.
+func runSkillReport(ctx context.Context, executor workflow.Executor, sk *skill.Skill, request string, prov ports.ProviderPort, costCalc *provider.CostCalculator, fallbacks *pinFallbacks) error {
 	formatter := GetFormatter()
 
-	result, err := executor.Execute(ctx, sk, request)
+	result, usage, err := executeWithResources(ctx, executor, sk, request)
 	if err != nil {
 		errorResult := map[string]any{
 			"skill":   sk.Name(),
@@ -245,57 +884,42 @@ func runSkillJSON(ctx context.Context, executor workflow.Executor, sk *skill.Ski
 			"error":   err.Error(),
 			"profile": runOpts.Profile,
 		}
-		return formatter.JSON(errorResult)
+		return formatter.FormatAuto(errorResult, nil)
 	}
 
 	// Calculate costs for each phase using model pricing
 	calculateCostsForResult(result, costCalc)
+	recordCosts(ctx, formatter, result, costCalc, runOpts.tags)
+	exportTrace(formatter, result)
+	checkProjectBudget(formatter, result)
 
-	// Build phase results for JSON output
-	phaseResults := make([]map[string]any, 0, len(result.PhaseResults))
-	for _, pr := range result.PhaseResults {
-		phaseResults = append(phaseResults, map[string]any{
-			"id":            pr.PhaseID,
-			"name":          pr.PhaseName,
-			"status":        string(pr.Status),
-			"duration_ms":   pr.Duration.Milliseconds(),
-			"input_tokens":  pr.InputTokens,
-			"output_tokens": pr.OutputTokens,
-			"model":         pr.ModelUsed,
-			"cost":          pr.Cost,
-		})
-	}
-
-	jsonResult := map[string]any{
-		"skill":        sk.Name(),
-		"status":       string(result.Status),
-		"profile":      runOpts.Profile,
-		"provider":     prov.Info().Name,
-		"duration_ms":  result.Duration.Milliseconds(),
-		"total_tokens": result.TotalTokens,
-		"total_cost":   result.TotalCost,
-		"phases":       phaseResults,
-		"final_output": result.FinalOutput,
-		"streaming":    runOpts.Stream,
-	}
-
-	if result.Error != nil {
-		jsonResult["error"] = result.Error.Error()
+	report := newRunReport(sk, prov, result, fallbacks)
+	if usage != nil {
+		report.Resources = resourceUsageJSON(usage)
 	}
+	report.Redactions = providerRedactions()
+	report.ModerationWarnings = providerModerations()
+	report.OutputFiles = saveOutputs(formatter, sk, result, os.Stdout)
 
-	return formatter.JSON(jsonResult)
+	return formatter.FormatAuto(report, nil)
 }
 
 // runSkillStreaming executes the skill with streaming output.
 func runSkillStreaming(ctx context.Context, executor workflow.StreamingExecutor, sk *skill.Skill, request string, _ ports.ProviderPort, formatter *output.Formatter) error {
 	// Create streaming output handler
 	streamOut := output.NewStreamingOutput(
-		output.WithStreamingColor(formatter.Format() != output.FormatJSON),
+		output.WithStreamingColor(!formatter.Format().Structured()),
 		output.WithShowTokenCounts(true),
 		output.WithShowPhaseInfo(true),
+		output.WithStatusSpinner(isTerminal(os.Stdout) && !formatter.Format().Structured()),
 	)
 
 	phases := sk.Phases()
+	plan := make([]output.PlannedPhase, len(phases))
+	for i, p := range phases {
+		plan[i] = output.PlannedPhase{ID: p.ID, Name: p.Name, DependsOn: p.DependsOn}
+	}
+	streamOut.SetPlan(plan)
 	streamOut.StartWorkflow(sk.Name(), sk.Version(), len(phases))
 
 	// Create streaming callback
@@ -305,12 +929,12 @@ func runSkillStreaming(ctx context.Context, executor workflow.StreamingExecutor,
 			streamOut.StartPhase(event.PhaseID, event.PhaseName, event.PhaseIndex)
 		case workflow.EventPhaseProgress:
 			if event.Content != "" {
-				streamOut.WriteChunk(event.Content)
+				streamOut.WritePhaseChunk(event.PhaseID, event.Content)
 			}
 		case workflow.EventPhaseCompleted:
-			streamOut.CompletePhase(event.InputTokens, event.OutputTokens, "")
+			streamOut.CompletePhaseOf(event.PhaseID, event.InputTokens, event.OutputTokens, event.ModelUsed)
 		case workflow.EventPhaseFailed:
-			streamOut.FailPhase(event.Error)
+			streamOut.FailPhaseOf(event.PhaseID, event.Error)
 		case workflow.EventTokenUpdate:
 			streamOut.UpdateTokens(event.InputTokens, event.OutputTokens)
 		case workflow.EventWorkflowCompleted:
@@ -320,14 +944,25 @@ func runSkillStreaming(ctx context.Context, executor workflow.StreamingExecutor,
 	}
 
 	// Execute with streaming
-	result, err := executor.ExecuteWithStreaming(ctx, sk, request, callback)
+	result, usage, err := sampleResources(ctx, func() (*workflow.ExecutionResult, error) {
+		return executor.ExecuteWithStreaming(ctx, sk, request, callback)
+	})
 	if err != nil {
 		streamOut.CompleteWorkflow(false)
 		return err
 	}
+	exportTrace(formatter, result)
 
 	// Complete workflow
 	streamOut.CompleteWorkflow(result.Status == workflow.PhaseStatusCompleted)
+	displayQualityWarnings(formatter, result)
+	displaySamples(formatter, result)
+	displayRedactions(formatter)
+	displayModerations(formatter)
+	displayResourceUsage(formatter, usage)
+	for _, path := range saveOutputs(formatter, sk, result, os.Stdout) {
+		formatter.Info("Saved output to %s", path)
+	}
 
 	return nil
 }
@@ -371,7 +1006,7 @@ func runSkillText(ctx context.Context, executor workflow.Executor, sk *skill.Ski
 
 	// Execute the workflow
 	startTime := time.Now()
-	result, err := executor.Execute(ctx, sk, request)
+	result, usage, err := executeWithResources(ctx, executor, sk, request)
 	executionTime := time.Since(startTime)
 
 	spinner.Stop()
@@ -383,6 +1018,9 @@ func runSkillText(ctx context.Context, executor workflow.Executor, sk *skill.Ski
 
 	// Calculate costs for each phase using model pricing
 	calculateCostsForResult(result, costCalc)
+	recordCosts(ctx, formatter, result, costCalc, runOpts.tags)
+	exportTrace(formatter, result)
+	checkProjectBudget(formatter, result)
 
 	// Display results
 	formatter.Println("")
@@ -399,8 +1037,38 @@ func runSkillText(ctx context.Context, executor workflow.Executor, sk *skill.Ski
 	formatter.Item("Total Duration", formatDuration(executionTime))
 	formatter.Item("Total Tokens", fmt.Sprintf("%d", result.TotalTokens))
 	formatter.Item("Total Cost", formatCost(result.TotalCost))
+	if from, reused := reusedPhases(result); reused > 0 {
+		formatter.Item("Reused", fmt.Sprintf("%d phase(s) from run %s", reused, from))
+	}
 	formatter.Println("")
 
+	// Outputs flagged by similarity guards
+	displayQualityWarnings(formatter, result)
+
+	// A/B experiment variants
+	displayExperiments(formatter, result)
+
+	// Foreach phases run on a sample of their items
+	displaySamples(formatter, result)
+
+	// Values masked by guardrails and policy redaction
+	displayRedactions(formatter)
+
+	// Requests sent despite the moderation check
+	displayModerations(formatter)
+
+	// Local resource usage
+	displayResourceUsage(formatter, usage)
+
+	// Generated files
+	if artifacts := collectArtifacts(result); len(artifacts) > 0 {
+		formatter.SubHeader("Artifacts")
+		for _, path := range artifacts {
+			formatter.BulletItem(path)
+		}
+		formatter.Println("")
+	}
+
 	// Final output
 	if result.FinalOutput != "" {
 		formatter.SubHeader("Output")
@@ -421,9 +1089,261 @@ func runSkillText(ctx context.Context, executor workflow.Executor, sk *skill.Ski
 		formatter.Error("Skill execution failed: %v", result.Error)
 	}
 
+	// Outputs saved to files, stdout and the clipboard
+	for _, path := range saveOutputs(formatter, sk, result, os.Stdout) {
+		formatter.Info("Saved output to %s", path)
+	}
+
+	return nil
+}
+
+// validateReportStyle checks --report-style and the output modes it can be
+// combined with.
+func validateReportStyle(formatter *output.Formatter) error {
+	switch runOpts.ReportStyle {
+	case "", reportStyleText:
+		return nil
+	case reportStyleNotebook:
+		if runOpts.Stream {
+			return fmt.Errorf("--report-style notebook cannot be combined with --stream")
+		}
+		if formatter.Format().Structured() {
+			return fmt.Errorf("--report-style notebook cannot be combined with JSON or YAML output")
+		}
+		return nil
+	default:
+		return fmt.Errorf("invalid report style %q: must be %s or %s", runOpts.ReportStyle, reportStyleText, reportStyleNotebook)
+	}
+}
+
+// runSkillNotebook executes the skill and writes a notebook-style Markdown
+// report to stdout. Progress and warnings go to stderr, so the report can be
+// redirected to a file.
+func runSkillNotebook(ctx context.Context, executor workflow.Executor, sk *skill.Skill, request string, prov ports.ProviderPort, formatter *output.Formatter, costCalc *provider.CostCalculator) error {
+	spinner := output.NewSpinner("Executing workflow...", output.WithSpinnerWriter(os.Stderr))
+	spinner.Start()
+	result, usage, err := executeWithResources(ctx, executor, sk, request)
+	spinner.Stop()
+	if err != nil {
+		return err
+	}
+
+	calculateCostsForResult(result, costCalc)
+	recordCosts(ctx, formatter, result, costCalc, runOpts.tags)
+	exportTrace(formatter, result)
+	checkProjectBudget(formatter, result)
+
+	report := notebookReport(sk, request, prov, result)
+	report.Resources = resourceRows(usage)
+	if err := output.RenderNotebook(formatter, report); err != nil {
+		return err
+	}
+	saveOutputs(formatter, sk, result, os.Stdout)
+	return nil
+}
+
+// notebookReport converts an execution result to a notebook report with the
+// phases in the order they started.
+func notebookReport(sk *skill.Skill, request string, prov ports.ProviderPort, result *workflow.ExecutionResult) *output.NotebookReport {
+	report := &output.NotebookReport{
+		Skill:       sk.Name(),
+		Version:     sk.Version(),
+		Profile:     runOpts.Profile,
+		Provider:    prov.Info().Name,
+		Request:     request,
+		Status:      string(result.Status),
+		StartTime:   result.StartTime,
+		Duration:    result.Duration,
+		TotalTokens: result.TotalTokens,
+		TotalCost:   result.TotalCost,
+		FinalOutput: result.FinalOutput,
+	}
+	if result.Error != nil {
+		report.Error = result.Error.Error()
+	}
+
+	phases := slices.SortedFunc(maps.Values(result.PhaseResults), func(a, b *workflow.PhaseResult) int {
+		return a.StartTime.Compare(b.StartTime)
+	})
+	for _, pr := range phases {
+		cell := output.NotebookCell{
+			ID:           pr.PhaseID,
+			Name:         pr.PhaseName,
+			Status:       string(pr.Status),
+			Provider:     pr.ProviderUsed,
+			Model:        pr.ModelUsed,
+			Prompt:       pr.Prompt,
+			Output:       pr.Output,
+			Duration:     pr.Duration,
+			InputTokens:  pr.InputTokens,
+			OutputTokens: pr.OutputTokens,
+			Cost:         pr.Cost,
+			CacheHit:     pr.CacheHit,
+			Artifacts:    pr.Artifacts,
+			Experiment:   pr.Experiment,
+			Variant:      pr.Variant,
+			Warnings:     pr.Warnings,
+		}
+		if pr.Error != nil {
+			cell.Error = pr.Error.Error()
+		}
+		report.Cells = append(report.Cells, cell)
+	}
+	return report
+}
+
+// artifactsDir returns the directory generated files of this run are saved
+// in: --artifacts-dir, or a new directory under .skillrunner/artifacts.
+func artifactsDir(sk *skill.Skill) string {
+	if runOpts.ArtifactsDir != "" {
+		return runOpts.ArtifactsDir
+	}
+	return filepath.Join(".skillrunner", "artifacts", sk.ID()+"-"+time.Now().Format("20060102-150405"))
+}
+
+// collectArtifacts returns the files saved by all phases, in the order the
+// phases started; phases that started together are ordered by ID.
+func collectArtifacts(result *workflow.ExecutionResult) []string {
+	phases := slices.SortedFunc(maps.Values(result.PhaseResults), func(a, b *workflow.PhaseResult) int {
+		return cmp.Or(a.StartTime.Compare(b.StartTime), cmp.Compare(a.PhaseID, b.PhaseID))
+	})
+
+	var artifacts []string
+	for _, pr := range phases {
+		artifacts = append(artifacts, pr.Artifacts...)
+	}
+	return artifacts
+}
+
+// displayQualityWarnings lists the quality problems found in phase outputs,
+// such as an output that echoes its input.
+func displayQualityWarnings(formatter *output.Formatter, result *workflow.ExecutionResult) {
+	phases := slices.SortedFunc(maps.Values(result.PhaseResults), func(a, b *workflow.PhaseResult) int {
+		return a.StartTime.Compare(b.StartTime)
+	})
+	phases = slices.DeleteFunc(phases, func(pr *workflow.PhaseResult) bool {
+		return len(pr.Warnings) == 0
+	})
+	if len(phases) == 0 {
+		return
+	}
+
+	formatter.SubHeader("Quality")
+	for _, pr := range phases {
+		for _, warning := range pr.Warnings {
+			_ = formatter.Warning("%s: %s", pr.PhaseName, warning)
+		}
+	}
+	formatter.Println("")
+}
+
+// displayExperiments lists the phases that took part in A/B experiments and
+// the variant each ran as.
+func displayExperiments(formatter *output.Formatter, result *workflow.ExecutionResult) {
+	phases := slices.SortedFunc(maps.Values(result.PhaseResults), func(a, b *workflow.PhaseResult) int {
+		return a.StartTime.Compare(b.StartTime)
+	})
+	phases = slices.DeleteFunc(phases, func(pr *workflow.PhaseResult) bool {
+		return pr.Experiment == ""
+	})
+	if len(phases) == 0 {
+		return
+	}
+
+	formatter.SubHeader("Experiments")
+	for _, pr := range phases {
+		formatter.Item(pr.PhaseName, fmt.Sprintf("%s (%s, %s)", pr.Experiment, pr.Variant, pr.ModelUsed))
+	}
+	formatter.Println("")
+}
+
+// displaySamples lists the foreach phases that ran on a sample of their
+// items and how many of them they ran.
+func displaySamples(formatter *output.Formatter, result *workflow.ExecutionResult) {
+	phases := slices.SortedFunc(maps.Values(result.PhaseResults), func(a, b *workflow.PhaseResult) int {
+		return a.StartTime.Compare(b.StartTime)
+	})
+	phases = slices.DeleteFunc(phases, func(pr *workflow.PhaseResult) bool {
+		return pr.Sample == nil
+	})
+	if len(phases) == 0 {
+		return
+	}
+
+	formatter.SubHeader("Sampled")
+	for _, pr := range phases {
+		formatter.Item(pr.PhaseName, fmt.Sprintf("%d of %d items (seed %d)", len(pr.Sample.Selected), pr.Sample.Total, pr.Sample.Seed))
+	}
+	formatter.Println("")
+}
+
+// providerRedactions returns how many values each redaction rule masked in
+// the prompts sent to each provider, by provider name.
+func providerRedactions() map[string]map[string]int {
+	container := GetContainer()
+	if container == nil {
+		return nil
+	}
+	if initializer := container.ProviderInitializer(); initializer != nil {
+		return initializer.Redactions()
+	}
+	return nil
+}
+
+// displayRedactions lists what guardrails and the organization policy
+// masked in the prompts sent to each provider, e.g. "2 email, 1 api_key".
+func displayRedactions(formatter *output.Formatter) {
+	redactions := providerRedactions()
+	if len(redactions) == 0 {
+		return
+	}
+
+	formatter.SubHeader("Redactions")
+	for _, name := range slices.Sorted(maps.Keys(redactions)) {
+		counts := redactions[name]
+		parts := make([]string, 0, len(counts))
+		for _, rule := range slices.Sorted(maps.Keys(counts)) {
+			parts = append(parts, fmt.Sprintf("%d %s", counts[rule], rule))
+		}
+		formatter.Item(name, strings.Join(parts, ", "))
+	}
+	formatter.Println("")
+}
+
+// providerModerations returns how many requests to each provider were sent
+// despite the moderation check flagging them, by category, by provider name.
+func providerModerations() map[string]map[string]int {
+	container := GetContainer()
+	if container == nil {
+		return nil
+	}
+	if initializer := container.ProviderInitializer(); initializer != nil {
+		return initializer.Moderations()
+	}
 	return nil
 }
 
+// displayModerations lists the requests sent to each provider although the
+// moderation check flagged them, or could not check them, e.g.
+// "1 violence, 1 unchecked".
+func displayModerations(formatter *output.Formatter) {
+	flags := providerModerations()
+	if len(flags) == 0 {
+		return
+	}
+
+	formatter.SubHeader("Moderation Warnings")
+	for _, name := range slices.Sorted(maps.Keys(flags)) {
+		counts := flags[name]
+		parts := make([]string, 0, len(counts))
+		for _, category := range slices.Sorted(maps.Keys(counts)) {
+			parts = append(parts, fmt.Sprintf("%d %s", counts[category], category))
+		}
+		formatter.Item(name, strings.Join(parts, ", "))
+	}
+	formatter.Println("")
+}
+
 // displayPhaseResults displays the results of each phase in a table with cost breakdown.
 func displayPhaseResults(formatter *output.Formatter, result *workflow.ExecutionResult) {
 	// Sort phase results by completion order
@@ -594,16 +1514,38 @@ func getPremiumModelPricing() provider.ModelCostRate {
 	return defaultRate
 }
 
-// validateProfile checks if the profile is valid.
+// validateProfile checks that the profile is a built-in routing profile or a
+// custom profile defined in the routing configuration.
 func validateProfile(profile string) error {
 	profile = strings.ToLower(strings.TrimSpace(profile))
-	validProfiles := []string{skill.ProfileCheap, skill.ProfileBalanced, skill.ProfilePremium}
 
-	if slices.Contains(validProfiles, profile) {
+	var routing *config.RoutingConfiguration
+	if container := GetContainer(); container != nil {
+		routing = container.RoutingConfiguration()
+	}
+	if routing.HasProfile(profile) {
 		return nil
 	}
 
-	return fmt.Errorf("invalid profile %q: must be one of %s", profile, strings.Join(validProfiles, ", "))
+	return fmt.Errorf("invalid profile %q: must be one of %s", profile, strings.Join(profileNames(routing), ", "))
+}
+
+// profileNames returns the built-in routing profiles followed by the custom
+// profiles defined in routing, sorted.
+func profileNames(routing *config.RoutingConfiguration) []string {
+	names := []string{skill.ProfileCheap, skill.ProfileBalanced, skill.ProfilePremium}
+	if routing == nil {
+		return names
+	}
+
+	var custom []string
+	for name := range routing.Profiles {
+		if !skill.IsBuiltinProfile(name) {
+			custom = append(custom, name)
+		}
+	}
+	slices.Sort(custom)
+	return append(names, custom...)
 }
 
 // calculateCostsForResult populates cost data for each phase in the execution result.
@@ -625,6 +1567,20 @@ func calculateCostsForResult(result *workflow.ExecutionResult, costCalc *provide
 	result.TotalCost = totalCost
 }
 
+// reusedPhases returns the run that phases of result were reused from and
+// how many were.
+func reusedPhases(result *workflow.ExecutionResult) (string, int) {
+	var from string
+	var reused int
+	for _, pr := range result.PhaseResults {
+		if pr.ReusedFrom != "" {
+			from = pr.ReusedFrom
+			reused++
+		}
+	}
+	return from, reused
+}
+
 // init registers the run command with the root command.
 func init() {
 	// This will be called when the package is imported
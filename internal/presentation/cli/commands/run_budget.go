@@ -0,0 +1,100 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jbctechsolutions/skillrunner/internal/application/ports"
+	"github.com/jbctechsolutions/skillrunner/internal/application/workflow"
+	"github.com/jbctechsolutions/skillrunner/internal/domain/skill"
+	"github.com/jbctechsolutions/skillrunner/internal/presentation/cli/output"
+)
+
+// withinHistoryRuns is how many recent runs inform the phase latency
+// estimates of --within.
+const withinHistoryRuns = 200
+
+// planWithin fits the run into the --within time budget using the phase
+// latencies recorded in run history. It may run more phases at once, move
+// phases to faster routing profiles and leave out optional phases, and
+// stops the run when the budget runs out. It returns the skill to run.
+func planWithin(ctx context.Context, formatter *output.Formatter, sk *skill.Skill, cfg *workflow.ExecutorConfig) (*skill.Skill, error) {
+	history := loadLatencyHistory(ctx)
+	plan, err := workflow.PlanTimeBudget(sk, runOpts.Within, cfg.MaxParallel, func(phase *skill.Phase, profile string) (time.Duration, bool) {
+		return history.Estimate(sk.ID(), phase, profile)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to plan run within %s: %w", runOpts.Within, err)
+	}
+
+	for _, msg := range timeBudgetMessages(plan, cfg.MaxParallel, runOpts.Within) {
+		warnRun(formatter, msg)
+	}
+
+	cfg.MaxParallel = plan.MaxParallel
+	cfg.Timeout = runOpts.Within
+	return plan.Skill, nil
+}
+
+// timeBudgetMessages describes how a plan changes the run.
+func timeBudgetMessages(plan *workflow.TimeBudgetPlan, maxParallel int, budget time.Duration) []string {
+	var msgs []string
+	if len(plan.Unestimated) > 0 {
+		msgs = append(msgs, fmt.Sprintf("time budget: no latency history for %s; run the skill without --within to record some",
+			strings.Join(plan.Unestimated, ", ")))
+	}
+	if plan.MaxParallel != maxParallel {
+		msgs = append(msgs, fmt.Sprintf("time budget: running up to %d phases at once", plan.MaxParallel))
+	}
+	for _, phase := range plan.Skill.Phases() {
+		if profile, ok := plan.Downgraded[phase.ID]; ok {
+			msgs = append(msgs, fmt.Sprintf("time budget: phase %q runs with the %s profile", phase.ID, profile))
+		}
+	}
+	for _, id := range plan.Dropped {
+		msgs = append(msgs, fmt.Sprintf("time budget: optional phase %q left out", id))
+	}
+	if !plan.Fits(budget) {
+		msgs = append(msgs, fmt.Sprintf("time budget: estimated %s exceeds %s; running best effort",
+			plan.Estimate.Round(time.Second), budget))
+	}
+	return msgs
+}
+
+// loadLatencyHistory records the completed, uncached phases of recent runs.
+// Runs recorded before checkpoints stored the routing profile take it from
+// the installed skill.
+func loadLatencyHistory(ctx context.Context) *workflow.LatencyHistory {
+	history := workflow.NewLatencyHistory()
+
+	container := GetContainer()
+	repo := container.WorkflowCheckpointRepository()
+	if repo == nil {
+		return history
+	}
+	runs, err := repo.List(ctx, &ports.WorkflowCheckpointFilter{Limit: withinHistoryRuns})
+	if err != nil {
+		return history
+	}
+
+	registry := container.SkillRegistry()
+	for _, cp := range runs {
+		var sk *skill.Skill
+		if registry != nil {
+			sk = registry.GetSkill(cp.SkillID())
+		}
+		for id, result := range cp.PhaseResults() {
+			if result.Status != string(workflow.PhaseStatusCompleted) || result.CacheHit {
+				continue
+			}
+			profile := result.RoutingProfile
+			if phase := findPhase(sk, id); profile == "" && phase != nil {
+				profile = phase.RoutingProfile
+			}
+			history.Record(cp.SkillID(), id, profile, time.Duration(result.DurationNs), result.OutputTokens)
+		}
+	}
+	return history
+}
@@ -0,0 +1,70 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jbctechsolutions/skillrunner/internal/domain/skill"
+	domainWorkflow "github.com/jbctechsolutions/skillrunner/internal/domain/workflow"
+	"github.com/jbctechsolutions/skillrunner/internal/presentation/cli/output"
+)
+
+// runEstimate prints the estimated tokens and cost of each phase of a run
+// of sk on request, without executing it.
+func runEstimate(ctx context.Context, formatter *output.Formatter, sk *skill.Skill, request string) error {
+	container := GetContainer()
+	if container == nil {
+		return fmt.Errorf("application not initialized")
+	}
+
+	plan, err := createPlanner(container).GeneratePlan(ctx, sk, request, loadMemoryContent(runOpts.NoMemory))
+	if err != nil {
+		return fmt.Errorf("failed to estimate run: %w", err)
+	}
+
+	if formatter.Format().Structured() {
+		return formatter.FormatAuto(plan, nil)
+	}
+	printRunEstimate(formatter, plan)
+	return nil
+}
+
+func printRunEstimate(formatter *output.Formatter, plan *domainWorkflow.ExecutionPlan) {
+	formatter.Header("Run Estimate")
+	formatter.Item("Skill", plan.SkillID)
+	formatter.Println("")
+
+	table := output.TableData{
+		Columns: []output.TableColumn{
+			{Header: "Phase", Width: 20, Align: output.AlignLeft},
+			{Header: "Profile", Width: 10, Align: output.AlignLeft},
+			{Header: "Model", Width: 28, Align: output.AlignLeft},
+			{Header: "In", Width: 8, Align: output.AlignRight},
+			{Header: "Out", Width: 8, Align: output.AlignRight},
+			{Header: "Cost", Width: 9, Align: output.AlignRight},
+		},
+		Rows: make([][]string, 0, len(plan.Phases)+1),
+	}
+	for _, p := range plan.Phases {
+		table.Rows = append(table.Rows, []string{
+			truncateString(p.PhaseID, 20),
+			p.RoutingProfile,
+			truncateString(p.ResolvedModel, 28),
+			fmt.Sprintf("%d", p.EstimatedInputTokens),
+			fmt.Sprintf("%d", p.EstimatedOutputTokens),
+			formatCost(p.EstimatedCost),
+		})
+	}
+	table.Rows = append(table.Rows, []string{
+		"Total",
+		"",
+		"",
+		fmt.Sprintf("%d", plan.TotalEstimatedInputTokens),
+		fmt.Sprintf("%d", plan.TotalEstimatedOutputTokens),
+		formatCost(plan.TotalEstimatedCost),
+	})
+	formatter.Table(table)
+
+	formatter.Println("")
+	formatter.Info("Output tokens assume half of each phase's max_tokens; unpriced models count as free")
+}
@@ -0,0 +1,83 @@
+package commands
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/jbctechsolutions/skillrunner/internal/application/workflow"
+	"github.com/jbctechsolutions/skillrunner/internal/domain/provider"
+	"github.com/jbctechsolutions/skillrunner/internal/domain/skill"
+	"github.com/jbctechsolutions/skillrunner/internal/presentation/cli/output"
+)
+
+// stdinRequest is the request argument that reads the request from stdin,
+// as in cat diff.patch | sr run review-diff -.
+const stdinRequest = "-"
+
+// readStdinRequest reads the request of a run from in, up to its end.
+func readStdinRequest(in io.Reader) (string, error) {
+	data, err := io.ReadAll(in)
+	if err != nil {
+		return "", fmt.Errorf("failed to read the request from stdin: %w", err)
+	}
+	if strings.TrimSpace(string(data)) == "" {
+		return "", fmt.Errorf("no request on stdin")
+	}
+	return string(data), nil
+}
+
+// stderrFormatter returns the formatter of a filter run's progress and
+// warnings: text on stderr, colored when stderr is a terminal.
+func stderrFormatter() *output.Formatter {
+	return output.NewFormatter(
+		output.WithWriter(os.Stderr),
+		output.WithColor(isTerminal(os.Stderr)),
+	)
+}
+
+// runSkillFilter executes the skill as a Unix filter: only the final output,
+// and the outputs of the skill's stdout sinks, are written to out, so that
+// the run composes with pipelines and git hooks, and warnings go to
+// formatter, which writes to stderr. A run that does not complete returns
+// an error, for a non-zero exit status.
+func runSkillFilter(ctx context.Context, executor workflow.Executor, sk *skill.Skill, request string, formatter *output.Formatter, out io.Writer, costCalc *provider.CostCalculator) error {
+	var spinner *output.Spinner
+	if isTerminal(os.Stderr) {
+		spinner = output.NewSpinner("Executing workflow...", output.WithSpinnerWriter(os.Stderr))
+		spinner.Start()
+	}
+	result, _, err := executeWithResources(ctx, executor, sk, request)
+	if spinner != nil {
+		spinner.Stop()
+	}
+	if err != nil {
+		return err
+	}
+
+	calculateCostsForResult(result, costCalc)
+	recordCosts(ctx, formatter, result, costCalc, runOpts.tags)
+	exportTrace(formatter, result)
+	checkProjectBudget(formatter, result)
+	displayQualityWarnings(formatter, result)
+	displayRedactions(formatter)
+	displayModerations(formatter)
+
+	if result.Status != workflow.PhaseStatusCompleted {
+		return fmt.Errorf("skill execution failed: %w", cmp.Or(result.Error, fmt.Errorf("run ended %s", result.Status)))
+	}
+	text := result.FinalOutput
+	if text != "" && !strings.HasSuffix(text, "\n") {
+		text += "\n"
+	}
+	if _, err := io.WriteString(out, text); err != nil {
+		return err
+	}
+	for _, path := range saveOutputs(formatter, sk, result, out) {
+		formatter.Info("Saved output to %s", path)
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+package commands
+
+import (
+	"fmt"
+	"io"
+	"slices"
+
+	"github.com/jbctechsolutions/skillrunner/internal/application/workflow"
+	"github.com/jbctechsolutions/skillrunner/internal/domain/skill"
+	"github.com/jbctechsolutions/skillrunner/internal/infrastructure/sinks"
+	"github.com/jbctechsolutions/skillrunner/internal/presentation/cli/output"
+)
+
+// saveOutputs delivers the outputs of a completed run: the final output to
+// --output-file, each phase's output to a file under --output-dir, and the
+// outputs the skill's sinks name, with stdout sinks writing to stdout. JSON
+// output leaves out stdout sinks, since it holds the final output. Failing
+// to deliver an output does not fail the run. It returns the files written.
+func saveOutputs(formatter *output.Formatter, sk *skill.Skill, result *workflow.ExecutionResult, stdout io.Writer) []string {
+	if result == nil || result.Status != workflow.PhaseStatusCompleted {
+		return nil
+	}
+	run := sinkRun(sk, result)
+
+	var paths []string
+	if runOpts.OutputFile != "" {
+		path, err := sinks.WriteFile(runOpts.OutputFile, run, "", result.FinalOutput)
+		if err != nil {
+			warnRun(formatter, fmt.Sprintf("failed to write --output-file: %v", err))
+		} else {
+			paths = append(paths, path)
+		}
+	}
+	if runOpts.OutputDir != "" {
+		written, err := sinks.WritePhases(runOpts.OutputDir, run)
+		paths = append(paths, written...)
+		if err != nil {
+			warnRun(formatter, fmt.Sprintf("failed to write --output-dir: %v", err))
+		}
+	}
+
+	outputs := sk.OutputSinks()
+	if formatter.Format().Structured() {
+		outputs = slices.DeleteFunc(outputs, func(o skill.OutputSink) bool {
+			return o.Type == skill.OutputSinkStdout
+		})
+	}
+	written, err := sinks.Deliver(outputs, run, stdout)
+	paths = append(paths, written...)
+	if err != nil {
+		warnRun(formatter, fmt.Sprintf("failed to deliver outputs: %v", err))
+	}
+	return paths
+}
+
+// sinkRun returns the outputs of a completed run for delivery, with the
+// completed phases in the skill's order.
+func sinkRun(sk *skill.Skill, result *workflow.ExecutionResult) *sinks.Run {
+	run := &sinks.Run{
+		Skill:       sk.ID(),
+		ExecutionID: result.ExecutionID,
+		Time:        result.StartTime,
+		FinalOutput: result.FinalOutput,
+	}
+	for _, phase := range sk.Phases() {
+		if pr := result.PhaseResults[phase.ID]; pr != nil && pr.Status == workflow.PhaseStatusCompleted {
+			run.Phases = append(run.Phases, sinks.Phase{ID: phase.ID, Output: pr.Output, JSON: phase.WantsJSON()})
+		}
+	}
+	return run
+}
@@ -0,0 +1,183 @@
+package commands
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/jbctechsolutions/skillrunner/internal/application/ports"
+	appProvider "github.com/jbctechsolutions/skillrunner/internal/application/provider"
+	"github.com/jbctechsolutions/skillrunner/internal/application/workflow"
+	"github.com/jbctechsolutions/skillrunner/internal/domain/skill"
+	domainWorkflow "github.com/jbctechsolutions/skillrunner/internal/domain/workflow"
+	"github.com/jbctechsolutions/skillrunner/internal/presentation/cli/output"
+)
+
+// runReport is the machine-readable result of a run, written with
+// --format json or yaml for scripts and CI.
+type runReport struct {
+	Skill              string                    `json:"skill"`
+	SkillID            string                    `json:"skill_id"`
+	ExecutionID        string                    `json:"execution_id,omitempty"`
+	Status             string                    `json:"status"`
+	Profile            string                    `json:"profile"`
+	Provider           string                    `json:"provider"`
+	StartTime          time.Time                 `json:"start_time,omitzero"`
+	EndTime            time.Time                 `json:"end_time,omitzero"`
+	DurationMs         int64                     `json:"duration_ms"`
+	TotalTokens        int                       `json:"total_tokens"`
+	TotalCost          float64                   `json:"total_cost"`
+	CacheHits          int                       `json:"cache_hits"`
+	CacheMisses        int                       `json:"cache_misses"`
+	Phases             []phaseReport             `json:"phases"`
+	FinalOutput        string                    `json:"final_output"`
+	Streaming          bool                      `json:"streaming"`
+	Resources          map[string]any            `json:"resources,omitempty"`
+	Redactions         map[string]map[string]int `json:"redactions,omitempty"`
+	ModerationWarnings map[string]map[string]int `json:"moderation_warnings,omitempty"`
+	OutputFiles        []string                  `json:"output_files,omitempty"`
+	Error              string                    `json:"error,omitempty"`
+}
+
+// phaseReport is the result of one phase in a runReport.
+type phaseReport struct {
+	ID             string                 `json:"id"`
+	Name           string                 `json:"name"`
+	Status         string                 `json:"status"`
+	Output         string                 `json:"output"`
+	Error          string                 `json:"error,omitempty"`
+	StartTime      time.Time              `json:"start_time,omitzero"`
+	EndTime        time.Time              `json:"end_time,omitzero"`
+	DurationMs     int64                  `json:"duration_ms"`
+	InputTokens    int                    `json:"input_tokens"`
+	OutputTokens   int                    `json:"output_tokens"`
+	Provider       string                 `json:"provider,omitempty"`
+	Model          string                 `json:"model"`
+	Fallback       bool                   `json:"fallback"`
+	FallbackReason string                 `json:"fallback_reason,omitempty"`
+	CacheHit       bool                   `json:"cache_hit"`
+	Batch          int                    `json:"batch"`
+	BatchJobID     string                 `json:"batch_job_id,omitempty"`
+	Cost           float64                `json:"cost"`
+	Artifacts      []string               `json:"artifacts,omitempty"`
+	Experiment     string                 `json:"experiment,omitempty"`
+	Variant        string                 `json:"variant,omitempty"`
+	ReusedFrom     string                 `json:"reused_from,omitempty"`
+	Warnings       []string               `json:"warnings,omitempty"`
+	SkipReason     string                 `json:"skip_reason,omitempty"`
+	Sample         *domainWorkflow.Sample `json:"sample,omitempty"`
+}
+
+// newRunReport builds the report of a run, with its phases in the skill's
+// order and the phases that fell back from their pins flagged.
+func newRunReport(sk *skill.Skill, prov ports.ProviderPort, result *workflow.ExecutionResult, fallbacks *pinFallbacks) *runReport {
+	report := &runReport{
+		Skill:       sk.Name(),
+		SkillID:     sk.ID(),
+		ExecutionID: result.ExecutionID,
+		Status:      string(result.Status),
+		Profile:     runOpts.Profile,
+		Provider:    prov.Info().Name,
+		StartTime:   result.StartTime,
+		EndTime:     result.EndTime,
+		DurationMs:  result.Duration.Milliseconds(),
+		TotalTokens: result.TotalTokens,
+		TotalCost:   result.TotalCost,
+		CacheHits:   result.CacheHits,
+		CacheMisses: result.CacheMisses,
+		Phases:      make([]phaseReport, 0, len(result.PhaseResults)),
+		FinalOutput: result.FinalOutput,
+		Streaming:   runOpts.Stream,
+	}
+	if result.Error != nil {
+		report.Error = result.Error.Error()
+	}
+
+	ids := make([]string, 0, len(result.PhaseResults))
+	for _, phase := range sk.Phases() {
+		if _, ok := result.PhaseResults[phase.ID]; ok {
+			ids = append(ids, phase.ID)
+		}
+	}
+	var others []string
+	for id := range result.PhaseResults {
+		if !slices.Contains(ids, id) {
+			others = append(others, id)
+		}
+	}
+	slices.Sort(others)
+
+	for _, id := range append(ids, others...) {
+		pr := result.PhaseResults[id]
+		phase := phaseReport{
+			ID:           cmp.Or(pr.PhaseID, id),
+			Name:         pr.PhaseName,
+			Status:       string(pr.Status),
+			Output:       pr.Output,
+			StartTime:    pr.StartTime,
+			EndTime:      pr.EndTime,
+			DurationMs:   pr.Duration.Milliseconds(),
+			InputTokens:  pr.InputTokens,
+			OutputTokens: pr.OutputTokens,
+			Provider:     pr.ProviderUsed,
+			Model:        pr.ModelUsed,
+			CacheHit:     pr.CacheHit,
+			Batch:        pr.Batch,
+			BatchJobID:   pr.BatchJobID,
+			Cost:         pr.Cost,
+			Artifacts:    pr.Artifacts,
+			Experiment:   pr.Experiment,
+			Variant:      pr.Variant,
+			ReusedFrom:   pr.ReusedFrom,
+			Warnings:     pr.Warnings,
+			SkipReason:   pr.SkipReason,
+			Sample:       pr.Sample,
+		}
+		if pr.Error != nil {
+			phase.Error = pr.Error.Error()
+		}
+		phase.FallbackReason, phase.Fallback = fallbacks.reason(id)
+		report.Phases = append(report.Phases, phase)
+	}
+	return report
+}
+
+// pinFallbacks records why the phases of a run fell back from their pinned
+// provider or model, for the run's report.
+type pinFallbacks struct {
+	mu      sync.Mutex
+	reasons map[string]string // by phase ID
+}
+
+func newPinFallbacks() *pinFallbacks {
+	return &pinFallbacks{reasons: make(map[string]string)}
+}
+
+// warner returns a pin fallback handler that records each phase's fallback
+// and warns of it once, since a phase's provider is also resolved to warm
+// up its model.
+func (f *pinFallbacks) warner(formatter *output.Formatter) appProvider.PinFallbackHandler {
+	return func(phase *skill.Phase, fallback ports.ProviderPort, reason error) {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		if _, warned := f.reasons[phase.ID]; warned {
+			return
+		}
+		f.reasons[phase.ID] = fmt.Sprint(reason)
+		warnPinFallback(formatter, phase, fallback, reason)
+	}
+}
+
+// reason returns why the phase fell back from its pin, and whether it did.
+// A nil pinFallbacks records no fallbacks.
+func (f *pinFallbacks) reason(phaseID string) (string, bool) {
+	if f == nil {
+		return "", false
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	reason, ok := f.reasons[phaseID]
+	return reason, ok
+}
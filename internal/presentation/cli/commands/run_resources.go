@@ -0,0 +1,143 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+
+	appProvider "github.com/jbctechsolutions/skillrunner/internal/application/provider"
+	"github.com/jbctechsolutions/skillrunner/internal/application/workflow"
+	"github.com/jbctechsolutions/skillrunner/internal/domain/skill"
+	"github.com/jbctechsolutions/skillrunner/internal/infrastructure/config"
+	"github.com/jbctechsolutions/skillrunner/internal/infrastructure/resources"
+	"github.com/jbctechsolutions/skillrunner/internal/presentation/cli/output"
+)
+
+// executeWithResources executes the skill and, when a local provider is
+// registered, samples the machine's resource usage while it runs. The usage
+// is nil when no local provider is registered or sampling is not supported.
+func executeWithResources(ctx context.Context, executor workflow.Executor, sk *skill.Skill, request string) (*workflow.ExecutionResult, *resources.Usage, error) {
+	return sampleResources(ctx, func() (*workflow.ExecutionResult, error) {
+		return executor.Execute(ctx, sk, request)
+	})
+}
+
+// sampleResources runs execute, sampling resource usage while it runs as
+// executeWithResources does.
+func sampleResources(ctx context.Context, execute func() (*workflow.ExecutionResult, error)) (*workflow.ExecutionResult, *resources.Usage, error) {
+	if !hasLocalProvider() {
+		result, err := execute()
+		return result, nil, err
+	}
+
+	monitor := resources.NewMonitor()
+	monitor.Start(ctx)
+	result, err := execute()
+	return result, monitor.Stop(), err
+}
+
+// hasLocalProvider reports whether a local provider, such as Ollama, is
+// registered. Resource usage is only sampled for runs that may use one.
+func hasLocalProvider() bool {
+	container := GetContainer()
+	if container == nil || container.ProviderRegistry() == nil {
+		return false
+	}
+	for _, p := range container.ProviderRegistry().ListProviders() {
+		if p.Info().IsLocal {
+			return true
+		}
+	}
+	return false
+}
+
+// applyPowerPolicy applies an enabled routing power policy to the run: local
+// phases of its profiles move to a cloud provider while the machine runs on
+// battery or under thermal pressure, and a run started in that state uses at
+// most the policy's max_parallel phases at once.
+func applyPowerPolicy(ctx context.Context, formatter *output.Formatter, cfg *workflow.ExecutorConfig, pins *appProvider.PinSelector, policy *config.PowerPolicyConfiguration) {
+	if policy == nil || !policy.Enabled {
+		return
+	}
+
+	selector := appProvider.NewPowerAwareSelector(pins, GetContainer().ProviderRegistry(), policy, resources.ReadPowerState, func(state resources.PowerState) {
+		warnRun(formatter, powerChangeMessage(state))
+	})
+	cfg.ProviderSelector = selector
+
+	if selector.Constrained(ctx) && policy.MaxParallel > 0 {
+		cfg.MaxParallel = min(cfg.MaxParallel, policy.MaxParallel)
+	}
+}
+
+// powerChangeMessage describes a change of the power state for the power
+// policy's warnings.
+func powerChangeMessage(state resources.PowerState) string {
+	switch {
+	case state.OnBattery:
+		return "running on battery: local phases move to a cloud provider until plugged in"
+	case state.ThermalPressure:
+		return "CPU is thermally throttled: local phases move to a cloud provider until it cools down"
+	default:
+		return "power restored: phases run on local providers again"
+	}
+}
+
+// resourceRows formats usage as labeled rows for the run report.
+func resourceRows(usage *resources.Usage) []output.NotebookRow {
+	if usage == nil {
+		return nil
+	}
+
+	rows := []output.NotebookRow{
+		{Label: "CPU", Value: fmt.Sprintf("peak %.0f%%, avg %.0f%%", usage.CPU.Peak, usage.CPU.Avg)},
+		{Label: "RAM", Value: fmt.Sprintf("peak %s, avg %s of %s",
+			formatCacheBytes(int64(usage.Memory.Peak)), formatCacheBytes(int64(usage.Memory.Avg)), formatCacheBytes(int64(usage.MemoryTotal)))},
+	}
+	if gpu := usage.GPU; gpu != nil {
+		value := fmt.Sprintf("peak %.0f%%, avg %.0f%%", gpu.Percent.Peak, gpu.Percent.Avg)
+		if gpu.Memory.Peak > 0 {
+			value += fmt.Sprintf(", memory peak %s", formatCacheBytes(int64(gpu.Memory.Peak)))
+		}
+		rows = append(rows, output.NotebookRow{Label: fmt.Sprintf("GPU (%s)", gpu.Source), Value: value})
+	}
+	return rows
+}
+
+// displayResourceUsage displays the resource usage section of a text run
+// report, if usage was sampled.
+func displayResourceUsage(formatter *output.Formatter, usage *resources.Usage) {
+	rows := resourceRows(usage)
+	if len(rows) == 0 {
+		return
+	}
+
+	formatter.SubHeader("Resource Usage")
+	for _, row := range rows {
+		formatter.Item(row.Label, row.Value)
+	}
+	formatter.Println("")
+}
+
+// resourceUsageJSON converts usage to the "resources" object of JSON run
+// output.
+func resourceUsageJSON(usage *resources.Usage) map[string]any {
+	stat := func(s resources.Stat) map[string]any {
+		return map[string]any{"peak": s.Peak, "avg": s.Avg}
+	}
+
+	result := map[string]any{
+		"samples":            usage.Samples,
+		"duration_ms":        usage.Duration.Milliseconds(),
+		"cpu_percent":        stat(usage.CPU),
+		"memory_bytes":       stat(usage.Memory),
+		"memory_total_bytes": usage.MemoryTotal,
+	}
+	if gpu := usage.GPU; gpu != nil {
+		result["gpu"] = map[string]any{
+			"source":       gpu.Source,
+			"percent":      stat(gpu.Percent),
+			"memory_bytes": stat(gpu.Memory),
+		}
+	}
+	return result
+}
@@ -0,0 +1,397 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/jbctechsolutions/skillrunner/internal/application/ports"
+	appProvider "github.com/jbctechsolutions/skillrunner/internal/application/provider"
+	appSkills "github.com/jbctechsolutions/skillrunner/internal/application/skills"
+	"github.com/jbctechsolutions/skillrunner/internal/application/workflow"
+	"github.com/jbctechsolutions/skillrunner/internal/domain/skill"
+	domainWorkflow "github.com/jbctechsolutions/skillrunner/internal/domain/workflow"
+	"github.com/jbctechsolutions/skillrunner/internal/infrastructure/config"
+	"github.com/jbctechsolutions/skillrunner/internal/presentation/cli/output"
+	"github.com/jbctechsolutions/skillrunner/internal/presentation/server"
+)
+
+// serveCheckpointGrace is how long runs interrupted at the end of the drain
+// timeout may take to save their checkpoints before sr serve exits.
+const serveCheckpointGrace = 5 * time.Second
+
+// serveHealthTTL is how long the provider health checks behind /readyz are
+// reused, so that frequent probes do not call the providers each time.
+const serveHealthTTL = 30 * time.Second
+
+// serveOptions holds the flags of sr serve.
+type serveOptions struct {
+	Addr         string
+	DrainTimeout time.Duration
+	Readiness    string
+	MaxRuns      int
+	MaxQueued    int
+}
+
+// NewServeCmd creates the serve command.
+func NewServeCmd() *cobra.Command {
+	var opts serveOptions
+
+	cmd := &cobra.Command{
+		Use:   "serve",
+		Short: "Run skills for HTTP clients",
+		Long: `Serve an HTTP API that runs skills in the background, for running
+skillrunner as a service under systemd or in a container.
+
+  POST /v1/runs              start a run: {"skill", "input", "profile"}
+  GET  /v1/runs/{id}         the status of a run
+  GET  /v1/runs/{id}/events  the events of a run, as server-sent events
+  GET  /v1/queue             the number of running and queued runs, and the
+                             estimated wait of a new run
+  GET  /v1/admin/usage       each client's use of the providers' rate limits
+  GET  /healthz              200 while the process is alive
+  GET  /readyz               200 once the server is ready for runs, else 503
+
+The readiness level sets what /readyz checks:
+
+  basic     providers are registered and storage is reachable
+  standard  also, at least one routing profile has its models served by
+            responding providers (the default)
+  strict    every enabled provider responds and every profile is served
+
+A draining server is not ready.
+
+At most --max-runs runs execute at once; further runs are queued, and once
+--max-queued runs are waiting, new ones are refused with 429 Too Many
+Requests and a Retry-After header. Responses to POST /v1/runs carry the
+queue depth and the estimated wait in seconds as X-Queue-Depth and
+X-Estimated-Wait. sr serve queue shows the queue of a running server.
+
+With serve.clients configured, every request but the health probes must
+carry a client's token as "Authorization: Bearer <token>". Clients see only
+their own runs, and when their runs wait for the same rate-limited provider
+they start in fair-share order, in proportion to the clients' weights, so
+one busy client cannot starve the others. Admin clients see every run and
+the usage report. Without clients the API is open to anyone who can reach
+it.
+
+Runs are checkpointed like those of sr run. On SIGTERM or SIGINT the server
+stops accepting runs and lets in-flight runs finish for up to the drain
+timeout. Runs still going then are interrupted: their checkpoints are kept,
+and their event streams end with a shutdown event carrying a resume token.
+Posting {"resume_token": "..."} to /v1/runs resumes the run from its last
+completed batch. A second signal exits immediately.
+
+Set the drain timeout a few seconds below the stop timeout of the service
+manager (TimeoutStopSec for systemd, the termination grace period for
+Kubernetes) so that interrupted runs have time to save their checkpoints.`,
+		Example: `  # Serve on the default address, 127.0.0.1:8765
+  sr serve
+
+  # Listen on all interfaces and drain for up to 2 minutes on shutdown
+  sr serve --addr :8765 --drain-timeout 2m
+
+  # Start a run and follow it
+  curl -d '{"skill":"code-review","input":"..."}' localhost:8765/v1/runs
+  curl -N localhost:8765/v1/runs/<execution_id>/events`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runServe(cmd, opts)
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.Addr, "addr", "", "address to listen on (default from serve.addr, or 127.0.0.1:8765)")
+	cmd.Flags().DurationVar(&opts.DrainTimeout, "drain-timeout", 0, "how long in-flight runs may finish on shutdown (default from serve.drain_timeout, or 30s)")
+	cmd.Flags().StringVar(&opts.Readiness, "readiness", "", "what /readyz checks: basic, standard or strict (default from serve.readiness, or standard)")
+	cmd.Flags().IntVar(&opts.MaxRuns, "max-runs", 0, "runs executed at once (default from serve.max_concurrent_runs, or 4)")
+	cmd.Flags().IntVar(&opts.MaxQueued, "max-queued", 0, "runs waiting for a slot before new ones get 429 (default from serve.max_queued_runs, or 16)")
+
+	cmd.AddCommand(newServeQueueCmd())
+
+	return cmd
+}
+
+func runServe(cmd *cobra.Command, opts serveOptions) error {
+	formatter := GetFormatter()
+	container := GetContainer()
+	if container == nil {
+		return fmt.Errorf("application not initialized")
+	}
+
+	serveCfg := container.Config().Serve
+	addr := serveCfg.ListenAddr()
+	if opts.Addr != "" {
+		addr = opts.Addr
+	}
+	drain := serveCfg.Drain()
+	if cmd.Flags().Changed("drain-timeout") {
+		if opts.DrainTimeout < 0 {
+			return fmt.Errorf("--drain-timeout must not be negative")
+		}
+		drain = opts.DrainTimeout
+	}
+	if opts.Readiness != "" {
+		serveCfg.Readiness = opts.Readiness
+		if err := serveCfg.Validate(); err != nil {
+			return fmt.Errorf("--readiness: %w", err)
+		}
+	}
+
+	if opts.MaxRuns != 0 {
+		serveCfg.MaxConcurrentRuns = opts.MaxRuns
+	}
+	if opts.MaxQueued != 0 {
+		serveCfg.MaxQueuedRuns = opts.MaxQueued
+	}
+	if opts.MaxRuns < 0 || opts.MaxQueued < 0 {
+		return fmt.Errorf("--max-runs and --max-queued must be positive")
+	}
+
+	clients, err := serveClients(serveCfg.Clients)
+	if err != nil {
+		return err
+	}
+
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", addr, err)
+	}
+	srv := server.New(server.Config{
+		DrainTimeout:      drain,
+		ReadinessChecks:   readinessChecks(serveCfg.ReadinessLevel()),
+		MaxConcurrentRuns: serveCfg.MaxRuns(),
+		MaxQueuedRuns:     serveCfg.MaxQueued(),
+		Clients:           clients,
+		Usage: func() map[string][]ports.ClientUsage {
+			if initializer := GetContainer().ProviderInitializer(); initializer != nil {
+				return initializer.ClientUsage()
+			}
+			return nil
+		},
+	}, serveRun(formatter, newRunRouter(formatter)))
+
+	// Shut down gracefully on the first signal; Execute exits on the second
+	handlesSignals.Store(true)
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	served := make(chan error, 1)
+	go func() { served <- srv.Serve(listener) }()
+	formatter.Info("Serving skill runs on http://%s", listener.Addr())
+
+	select {
+	case err := <-served:
+		return err
+	case <-ctx.Done():
+	}
+	stop()
+
+	formatter.Info("Shutting down: waiting up to %s for in-flight runs...", drain)
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), drain+serveCheckpointGrace)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		return fmt.Errorf("failed to shut down: %w", err)
+	}
+	return <-served
+}
+
+// serveRun returns the function that runs the skills of sr serve through
+// router, with checkpoints named after the server's execution IDs. The
+// runs share router, so that the latency recorded by each informs the
+// fastest selection of the next; when it is nil, the runs are not routed.
+func serveRun(formatter *output.Formatter, router *appProvider.Router) server.RunFunc {
+	return func(ctx context.Context, req server.RunRequest, executionID string) (*workflow.ExecutionResult, error) {
+		container := GetContainer()
+		cpConfig := workflow.CheckpointConfig{
+			Enabled:     true,
+			Port:        container.WorkflowCheckpointRepository(),
+			ExecutionID: executionID,
+			MachineID:   container.MachineID(),
+		}
+
+		skillName, input := req.Skill, req.Input
+		if req.ResumeToken != "" {
+			cp, err := interruptedCheckpoint(ctx, cpConfig, req.ResumeToken)
+			if err != nil {
+				return nil, err
+			}
+			skillName, input = cp.SkillID(), cp.Input()
+			cpConfig.Resume = true
+			cpConfig.Tags = cp.Tags()
+		}
+
+		registry := container.SkillRegistry()
+		if registry == nil {
+			return nil, fmt.Errorf("skill registry not available")
+		}
+		sk := registry.GetSkill(skillName)
+		if sk == nil {
+			sk = registry.GetSkillByName(skillName)
+		}
+		if sk == nil {
+			return nil, fmt.Errorf("skill not found: %s", skillName)
+		}
+		if err := appSkills.VerifyRequirements(sk); err != nil {
+			return nil, err
+		}
+
+		profile := req.Profile
+		if profile == "" {
+			profile = skill.ProfileBalanced
+		}
+		if err := validateProfile(profile); err != nil {
+			return nil, err
+		}
+		providerRegistry := container.ProviderRegistry()
+		prov := selectProvider(providerRegistry.ListProviders(), profile)
+		if prov == nil {
+			return nil, fmt.Errorf("no suitable provider found for profile: %s", profile)
+		}
+
+		costCalc := container.CostCalculator()
+		executorConfig := workflow.DefaultExecutorConfig()
+		executorConfig.MemoryContent = loadMemoryContent(false)
+		executorConfig.ProviderSelector = appProvider.NewPinSelector(providerRegistry, pinFallbackWarner(formatter))
+		if err := applyBudgets(ctx, formatter, &executorConfig, container.RoutingConfiguration().Budgets, costCalc); err != nil {
+			return nil, err
+		}
+		executorConfig.Transcriber = container.Transcriber()
+		executorConfig.ImageGenerator = container.ImageGenerator()
+		executorConfig.ArtifactDir = artifactsDir(sk)
+		executorConfig.ConcurrencyGroups = container.ConcurrencyGroups()
+		executorConfig.Skills = container.SkillRegistry()
+		if router != nil {
+			routeWith(&executorConfig, router)
+		}
+
+		executor := workflow.NewCheckpointingExecutor(prov, executorConfig, cpConfig)
+		result, err := executor.Execute(ctx, sk, input)
+		calculateCostsForResult(result, costCalc)
+		recordCosts(context.WithoutCancel(ctx), formatter, result, costCalc, cpConfig.Tags)
+		return result, err
+	}
+}
+
+// serveClients returns the API clients of the server, with their tokens.
+func serveClients(configured []config.ServeClient) ([]server.Client, error) {
+	clients := make([]server.Client, 0, len(configured))
+	for _, c := range configured {
+		token := c.BearerToken()
+		switch {
+		case token == "" && c.TokenEnv != "":
+			return nil, fmt.Errorf("serve client %q has no token: %s is not set", c.Name, c.TokenEnv)
+		case token == "":
+			return nil, fmt.Errorf("serve client %q has no token", c.Name)
+		}
+		clients = append(clients, server.Client{Name: c.Name, Token: token, Weight: c.Weight, Admin: c.Admin})
+	}
+	return clients, nil
+}
+
+// interruptedCheckpoint returns the in-progress checkpoint of the run with
+// the given resume token, its execution ID.
+func interruptedCheckpoint(ctx context.Context, cpConfig workflow.CheckpointConfig, token string) (*domainWorkflow.WorkflowCheckpoint, error) {
+	if cpConfig.Port == nil {
+		return nil, errors.New("checkpoints are not available")
+	}
+	checkpoints, err := cpConfig.Port.GetByExecutionID(ctx, token)
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up resume token: %w", err)
+	}
+	for _, cp := range checkpoints {
+		if cp.Status() == domainWorkflow.CheckpointStatusInProgress {
+			return cp, nil
+		}
+	}
+	return nil, fmt.Errorf("no interrupted run to resume for token %s", token)
+}
+
+// readinessChecks returns the checks of /readyz at the given readiness level.
+func readinessChecks(level string) []server.Check {
+	checks := []server.Check{
+		{Name: "providers", Run: func(context.Context) error {
+			if GetContainer().ProviderRegistry().Count() == 0 {
+				return errors.New("no providers are registered")
+			}
+			return nil
+		}},
+		{Name: "storage", Run: func(ctx context.Context) error {
+			backend := GetContainer().Storage()
+			if backend == nil {
+				return errors.New("storage is not available")
+			}
+			return backend.Ping(ctx)
+		}},
+	}
+	if level == config.ReadinessBasic {
+		return checks
+	}
+
+	diagnose := cachedDiagnosis(serveHealthTTL)
+	strict := level == config.ReadinessStrict
+	if strict {
+		checks = append(checks, server.Check{Name: "provider_health", Run: func(ctx context.Context) error {
+			report, err := diagnose(ctx)
+			if err != nil {
+				return err
+			}
+			if _, failing := report.Subjects("provider "); len(failing) > 0 {
+				return fmt.Errorf("not responding: %s", strings.Join(failing, ", "))
+			}
+			return nil
+		}})
+	}
+	return append(checks, server.Check{Name: "profiles", Run: func(ctx context.Context) error {
+		report, err := diagnose(ctx)
+		if err != nil {
+			return err
+		}
+		passing, failing := report.Subjects("profile ")
+		switch {
+		case strict && len(failing) > 0:
+			return fmt.Errorf("models not served: %s", strings.Join(failing, ", "))
+		case len(passing) == 0:
+			return errors.New("no routing profile has its models served by a responding provider")
+		}
+		return nil
+	}})
+}
+
+// cachedDiagnosis returns a function diagnosing the routing configuration
+// against the providers' health, which it checks at most once per ttl.
+func cachedDiagnosis(ttl time.Duration) func(context.Context) (*appProvider.DoctorReport, error) {
+	var (
+		mu      sync.Mutex
+		report  *appProvider.DoctorReport
+		checked time.Time
+	)
+	return func(ctx context.Context) (*appProvider.DoctorReport, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		if report != nil && time.Since(checked) < ttl {
+			return report, nil
+		}
+		container := GetContainer()
+		initializer := container.ProviderInitializer()
+		if initializer == nil {
+			return nil, errors.New("provider initializer not available")
+		}
+		health := initializer.CheckHealth(ctx)
+		if err := ctx.Err(); err != nil {
+			// Health checks cut short say nothing about the providers
+			return nil, err
+		}
+		report = appProvider.Diagnose(container.EffectiveRoutingConfiguration(), health)
+		checked = time.Now()
+		return report, nil
+	}
+}
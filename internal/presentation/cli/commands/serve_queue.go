@@ -0,0 +1,118 @@
+package commands
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/jbctechsolutions/skillrunner/internal/presentation/cli/output"
+	"github.com/jbctechsolutions/skillrunner/internal/presentation/server"
+)
+
+// serveTokenEnv is the environment variable holding the bearer token sr
+// serve queue sends, for servers with clients.
+const serveTokenEnv = "SR_SERVE_TOKEN"
+
+// newServeQueueCmd creates the serve queue command.
+func newServeQueueCmd() *cobra.Command {
+	var addr, token string
+
+	cmd := &cobra.Command{
+		Use:   "queue",
+		Short: "Show the run queue of a running server",
+		Long: `Show how many runs a running sr serve is executing and has queued, and
+how long a run posted now would wait before it starts.
+
+The server is reached at --addr, by default serve.addr. For a server with
+serve.clients, pass a client's token with --token or ` + serveTokenEnv + `.`,
+		Example: `  sr serve queue
+  sr serve queue --addr skillrunner.internal:8765 -o json`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runServeQueue(cmd.Context(), addr, cmp.Or(token, os.Getenv(serveTokenEnv)))
+		},
+	}
+
+	cmd.Flags().StringVar(&addr, "addr", "", "address of the server (default from serve.addr, or 127.0.0.1:8765)")
+	cmd.Flags().StringVar(&token, "token", "", "bearer token of a serve client (default from "+serveTokenEnv+")")
+
+	return cmd
+}
+
+func runServeQueue(ctx context.Context, addr, token string) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	formatter := GetFormatter()
+	if addr == "" {
+		if container := GetContainer(); container != nil {
+			addr = container.Config().Serve.ListenAddr()
+		}
+	}
+
+	queue, err := fetchServeQueue(ctx, serveURL(addr), token)
+	if err != nil {
+		return err
+	}
+	if formatter.Format() == output.FormatJSON {
+		return formatter.JSON(queue)
+	}
+
+	formatter.Header("Run Queue")
+	formatter.Item("Running", fmt.Sprintf("%d of %d", queue.Running, queue.MaxConcurrentRuns))
+	formatter.Item("Queued", fmt.Sprintf("%d of %d", queue.Queued, queue.MaxQueuedRuns))
+	formatter.Item("Estimated wait", queue.EstimatedWait.Round(time.Second).String())
+	if queue.Saturated {
+		formatter.Warning("The queue is full: new runs are refused until a slot frees")
+	}
+	return nil
+}
+
+// serveURL returns the base URL of the server listening on addr.
+func serveURL(addr string) string {
+	if strings.Contains(addr, "://") {
+		return strings.TrimSuffix(addr, "/")
+	}
+	if strings.HasPrefix(addr, ":") {
+		addr = "127.0.0.1" + addr
+	}
+	return "http://" + addr
+}
+
+// fetchServeQueue gets the queue status of the server at baseURL.
+func fetchServeQueue(ctx context.Context, baseURL, token string) (*server.QueueStatus, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, baseURL+"/v1/queue", nil)
+	if err != nil {
+		return nil, err
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reach the server: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusUnauthorized:
+		return nil, fmt.Errorf("the server requires a client token; pass --token or set %s", serveTokenEnv)
+	default:
+		return nil, fmt.Errorf("the server answered %s", resp.Status)
+	}
+	var queue server.QueueStatus
+	if err := json.NewDecoder(resp.Body).Decode(&queue); err != nil {
+		return nil, fmt.Errorf("invalid queue status: %w", err)
+	}
+	return &queue, nil
+}
@@ -0,0 +1,430 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	appProvider "github.com/jbctechsolutions/skillrunner/internal/application/provider"
+	"github.com/jbctechsolutions/skillrunner/internal/application/skills"
+	"github.com/jbctechsolutions/skillrunner/internal/application/workflow"
+	"github.com/jbctechsolutions/skillrunner/internal/domain/skill"
+	"github.com/jbctechsolutions/skillrunner/internal/presentation/cli/output"
+)
+
+// NewSkillCmd creates the skill command for working with a single skill.
+func NewSkillCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "skill",
+		Aliases: []string{"skills"},
+		Short:   "Create, list, inspect, check and tune skills",
+		Long: `Create a new skill from a template, list the discovered skills, and
+inspect, validate, check and tune an individual skill definition.`,
+	}
+
+	cmd.AddCommand(NewSkillInitCmd())
+	cmd.AddCommand(NewListCmd())
+	cmd.AddCommand(NewSkillShowCmd())
+	cmd.AddCommand(NewSkillValidateCmd())
+	cmd.AddCommand(NewSkillOptimizeCmd())
+	cmd.AddCommand(NewSkillLintCmd())
+	cmd.AddCommand(NewSkillTestCmd())
+	cmd.AddCommand(NewSkillTuneCmd())
+
+	return cmd
+}
+
+// NewSkillOptimizeCmd creates the skill optimize command.
+func NewSkillOptimizeCmd() *cobra.Command {
+	var runs int
+
+	cmd := &cobra.Command{
+		Use:   "optimize <skill>",
+		Short: "Suggest skill configuration changes from run history",
+		Long: `Analyze a skill's recent runs and suggest configuration changes.
+
+For each phase, token usage, failures, truncated outputs and pinned-provider
+fallbacks are aggregated across runs. Suggestions include:
+  • Downgrading a routing_profile whose outputs stay small
+  • Upgrading a routing_profile whose requests exceed its context window
+  • Raising max_tokens when outputs are repeatedly cut off
+  • Reviewing provider pins that frequently fall back
+
+Suggestions are advisory; the skill file is not modified.`,
+		Example: `  # Suggest changes for code-review based on its last 50 runs
+  sr skill optimize code-review
+
+  # Only consider the last 10 runs, as JSON
+  sr skill optimize code-review --runs 10 -o json`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runSkillOptimize(cmd.Context(), args[0], runs)
+		},
+	}
+
+	cmd.Flags().IntVar(&runs, "runs", skills.DefaultOptimizerMaxRuns, "number of recent runs to analyze")
+
+	return cmd
+}
+
+// SkillLintResult is the JSON output of sr skill lint.
+type SkillLintResult struct {
+	Skill    string                     `json:"skill"`
+	Valid    bool                       `json:"valid"`
+	Error    string                     `json:"error,omitempty"`
+	Requires []skills.RequirementStatus `json:"requires"`
+}
+
+// NewSkillLintCmd creates the skill lint command.
+func NewSkillLintCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "lint <skill|file>",
+		Short: "Check a skill definition and the programs it requires",
+		Long: `Check that a skill definition is valid and that the programs it declares
+under requires are on PATH, with a hint on how to install each missing one.
+
+The argument is a skill ID or name, or the path of a skill YAML file. The
+command fails if the skill is invalid or a required program is missing, so it
+can run in CI.`,
+		Example: `  # Check an installed skill
+  sr skill lint code-review
+
+  # Check a skill file before installing it
+  sr skill lint ./skills/go-refactor.yaml`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runSkillLint(args[0])
+		},
+	}
+}
+
+func runSkillLint(target string) error {
+	formatter := GetFormatter()
+	container := GetContainer()
+	if container == nil {
+		return fmt.Errorf("application not initialized")
+	}
+
+	result := SkillLintResult{Skill: target, Requires: []skills.RequirementStatus{}}
+
+	var sk *skill.Skill
+	if info, err := os.Stat(target); err == nil && !info.IsDir() {
+		if sk, err = container.SkillLoader().LoadSkill(target); err != nil {
+			result.Error = err.Error()
+		}
+	} else {
+		if registry := container.SkillRegistry(); registry != nil {
+			if sk = registry.GetSkill(target); sk == nil {
+				sk = registry.GetSkillByName(target)
+			}
+		}
+		if sk == nil {
+			return fmt.Errorf("skill not found: %s", target)
+		}
+	}
+
+	missing := 0
+	if sk != nil {
+		result.Skill = sk.ID()
+		result.Valid = true
+		result.Requires = skills.CheckRequirements(sk)
+		for _, r := range result.Requires {
+			if !r.Found() {
+				missing++
+			}
+		}
+	}
+
+	if formatter.Format() == output.FormatJSON {
+		if err := formatter.JSON(result); err != nil {
+			return err
+		}
+	} else {
+		printSkillLint(formatter, result)
+	}
+
+	switch {
+	case !result.Valid:
+		return fmt.Errorf("skill %s is invalid", target)
+	case missing > 0:
+		return fmt.Errorf("%d required program(s) missing", missing)
+	}
+	return nil
+}
+
+func printSkillLint(formatter *output.Formatter, result SkillLintResult) {
+	formatter.Header("Skill Lint")
+	formatter.Item("Skill", result.Skill)
+	formatter.Println("")
+
+	if !result.Valid {
+		formatter.Error("Invalid definition: %s", result.Error)
+		return
+	}
+	formatter.Success("Definition is valid")
+
+	if len(result.Requires) == 0 {
+		formatter.Info("No required programs declared")
+		return
+	}
+
+	formatter.Println("")
+	formatter.SubHeader("Required Programs")
+	for _, r := range result.Requires {
+		if r.Found() {
+			formatter.Success("%s (%s)", r.Name, r.Path)
+		} else {
+			formatter.Error("%s not found; %s", r.Name, r.Hint)
+		}
+	}
+}
+
+// NewSkillTestCmd creates the skill test command.
+func NewSkillTestCmd() *cobra.Command {
+	var golden, profile string
+	var minSimilarity float64
+
+	cmd := &cobra.Command{
+		Use:   "test <skill>",
+		Short: "Check a skill for regressions against a golden run",
+		Long: `Run a skill on the input of a golden run and compare its phase outputs
+with the golden run's.
+
+The golden run must be a run of the same skill pinned with 'sr history pin'.
+A phase regresses when its output is less similar to the golden output than
+--min-similarity (0 to 1, by shared words), or when it ran on only one side.
+The command fails if any phase regresses, so it can run in CI.
+
+Test runs are not recorded in run history.`,
+		Example: `  # Pin a known-good run, then test a changed skill against it
+  sr history pin latest
+  sr skill test code-review --against-golden 3f2a9c1e-...
+
+  # Require near-identical outputs
+  sr skill test code-review --against-golden 3f2a9c1e-... --min-similarity 0.95`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runSkillTest(cmd.Context(), args[0], golden, profile, minSimilarity)
+		},
+	}
+
+	cmd.Flags().StringVar(&golden, "against-golden", "", "pinned run to use as the baseline (required)")
+	cmd.Flags().StringVarP(&profile, "profile", "p", skill.ProfileBalanced, "routing profile for the test run")
+	cmd.Flags().Float64Var(&minSimilarity, "min-similarity", skills.DefaultMinSimilarity, "minimum similarity of each phase output to the golden run's")
+	_ = cmd.MarkFlagRequired("against-golden")
+
+	return cmd
+}
+
+func runSkillTest(ctx context.Context, skillName, goldenRef, profile string, minSimilarity float64) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if minSimilarity < 0 || minSimilarity > 1 {
+		return fmt.Errorf("--min-similarity must be between 0 and 1")
+	}
+	if err := validateProfile(profile); err != nil {
+		return err
+	}
+
+	formatter := GetFormatter()
+	container := GetContainer()
+	if container == nil {
+		return fmt.Errorf("application not initialized")
+	}
+
+	registry := container.SkillRegistry()
+	if registry == nil {
+		return fmt.Errorf("skill registry not available")
+	}
+	sk := registry.GetSkill(skillName)
+	if sk == nil {
+		sk = registry.GetSkillByName(skillName)
+	}
+	if sk == nil {
+		return fmt.Errorf("skill not found: %s", skillName)
+	}
+
+	runs, _, err := historyRepositories()
+	if err != nil {
+		return err
+	}
+	golden, err := findRun(ctx, runs, goldenRef)
+	if err != nil {
+		return err
+	}
+	if err := skills.CheckGoldenRun(golden, sk); err != nil {
+		return err
+	}
+
+	if err := skills.VerifyRequirements(sk); err != nil {
+		return err
+	}
+	provider := selectProvider(container.ProviderRegistry().ListProviders(), profile)
+	if provider == nil {
+		return fmt.Errorf("no suitable provider found for profile: %s", profile)
+	}
+
+	executorConfig := workflow.DefaultExecutorConfig()
+	executorConfig.ProviderSelector = appProvider.NewPinSelector(container.ProviderRegistry(), pinFallbackWarner(formatter))
+	executorConfig.ConcurrencyGroups = container.ConcurrencyGroups()
+	executorConfig.Skills = container.SkillRegistry()
+	applyRouting(formatter, &executorConfig)
+
+	if formatter.Format() != output.FormatJSON {
+		formatter.Info("Running %s on the input of golden run %s...", sk.ID(), golden.ID())
+	}
+	result, err := workflow.NewExecutor(provider, executorConfig).Execute(ctx, sk, golden.Input())
+	if err != nil {
+		return fmt.Errorf("test run failed: %w", err)
+	}
+
+	outputs := make(map[string]string, len(result.PhaseResults))
+	for id, pr := range result.PhaseResults {
+		if pr.Status == workflow.PhaseStatusCompleted {
+			outputs[id] = pr.Output
+		}
+	}
+	comparison := skills.CompareWithGolden(golden, outputs, minSimilarity)
+
+	if formatter.Format() == output.FormatJSON {
+		if err := formatter.JSON(comparison); err != nil {
+			return err
+		}
+	} else {
+		printGoldenComparison(formatter, sk.ID(), comparison)
+	}
+
+	if n := len(comparison.Regressions()); n > 0 {
+		return fmt.Errorf("%d phase(s) regressed against golden run %s", n, golden.ID())
+	}
+	return nil
+}
+
+func printGoldenComparison(formatter *output.Formatter, skillID string, comparison *skills.GoldenComparison) {
+	formatter.Header("Skill Test")
+	formatter.Item("Skill", skillID)
+	formatter.Item("Golden Run", comparison.GoldenRunID)
+	formatter.Item("Min Similarity", fmt.Sprintf("%.2f", comparison.MinSimilarity))
+	formatter.Println("")
+
+	for _, p := range comparison.Phases {
+		switch {
+		case p.Missing == "golden":
+			formatter.Error("%s: not in the golden run", p.PhaseID)
+		case p.Missing == "current":
+			formatter.Error("%s: no output in this run", p.PhaseID)
+		case p.Passed:
+			formatter.Success("%s: %.2f similar", p.PhaseID, p.Similarity)
+		default:
+			formatter.Error("%s: %.2f similar", p.PhaseID, p.Similarity)
+		}
+	}
+}
+
+func runSkillOptimize(ctx context.Context, skillName string, runs int) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	formatter := GetFormatter()
+	container := GetContainer()
+	if container == nil {
+		return fmt.Errorf("application not initialized")
+	}
+
+	registry := container.SkillRegistry()
+	if registry == nil {
+		return fmt.Errorf("skill registry not available")
+	}
+
+	// Try to find skill by ID first, then by name
+	sk := registry.GetSkill(skillName)
+	if sk == nil {
+		sk = registry.GetSkillByName(skillName)
+	}
+	if sk == nil {
+		return fmt.Errorf("skill not found: %s", skillName)
+	}
+
+	repo := container.WorkflowCheckpointRepository()
+	if repo == nil {
+		return fmt.Errorf("run history not available")
+	}
+
+	limits := make(map[string]int)
+	if rc := container.RoutingConfiguration(); rc != nil {
+		for name, profile := range rc.Profiles {
+			if profile != nil {
+				limits[name] = profile.MaxContextTokens
+			}
+		}
+	}
+
+	optimizer := skills.NewOptimizer(repo, skills.OptimizerConfig{
+		MaxRuns:       runs,
+		ContextLimits: limits,
+	})
+
+	report, err := optimizer.Analyze(ctx, sk)
+	if err != nil {
+		return fmt.Errorf("failed to analyze skill %s: %w", sk.ID(), err)
+	}
+
+	if formatter.Format() == output.FormatJSON {
+		return formatter.JSON(report)
+	}
+
+	printOptimizationReport(formatter, report)
+	return nil
+}
+
+func printOptimizationReport(formatter *output.Formatter, report *skills.OptimizationReport) {
+	formatter.Header("Skill Optimization")
+	formatter.Item("Skill", report.SkillID)
+	formatter.Item("Runs Analyzed", fmt.Sprintf("%d", report.RunsAnalyzed))
+	formatter.Println("")
+
+	if report.RunsAnalyzed == 0 {
+		formatter.Warning("No recorded runs for %s; run it with 'sr run' first", report.SkillID)
+		return
+	}
+
+	formatter.SubHeader("Phases")
+	table := output.TableData{
+		Columns: []output.TableColumn{
+			{Header: "Phase", Width: 20, Align: output.AlignLeft},
+			{Header: "Profile", Width: 10, Align: output.AlignLeft},
+			{Header: "Runs", Width: 5, Align: output.AlignRight},
+			{Header: "Failed", Width: 6, Align: output.AlignRight},
+			{Header: "Avg In", Width: 8, Align: output.AlignRight},
+			{Header: "Avg Out", Width: 8, Align: output.AlignRight},
+			{Header: "Max Total", Width: 9, Align: output.AlignRight},
+		},
+		Rows: make([][]string, 0, len(report.Phases)),
+	}
+	for _, p := range report.Phases {
+		table.Rows = append(table.Rows, []string{
+			p.PhaseID,
+			p.RoutingProfile,
+			fmt.Sprintf("%d", p.Runs),
+			fmt.Sprintf("%d", p.Failures),
+			fmt.Sprintf("%d", p.AvgInputTokens),
+			fmt.Sprintf("%d", p.AvgOutputTokens),
+			fmt.Sprintf("%d", p.MaxTotalTokens),
+		})
+	}
+	formatter.Table(table)
+	formatter.Println("")
+
+	formatter.SubHeader("Suggestions")
+	if len(report.Suggestions) == 0 {
+		formatter.BulletItem("No changes suggested")
+		return
+	}
+	for _, s := range report.Suggestions {
+		formatter.BulletItem(fmt.Sprintf("[%s] %s", s.Kind, s.Message))
+	}
+}
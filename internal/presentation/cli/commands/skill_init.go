@@ -0,0 +1,219 @@
+package commands
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/jbctechsolutions/skillrunner/internal/domain/skill"
+	infraSkills "github.com/jbctechsolutions/skillrunner/internal/infrastructure/skills"
+	"github.com/jbctechsolutions/skillrunner/internal/presentation/cli/output"
+)
+
+// defaultScaffoldPhases are the phases of a new skill when none are given.
+var defaultScaffoldPhases = []string{"analyze", "generate"}
+
+// skillInitFlags holds the flags for the skill init command.
+type skillInitFlags struct {
+	Name        string
+	Description string
+	Phases      []string
+	Profile     string
+	Parallel    bool
+	Dir         string
+	Force       bool
+	Yes         bool
+}
+
+// SkillInitResult is the JSON output of sr skill init.
+type SkillInitResult struct {
+	Skill   string `json:"skill"`
+	Path    string `json:"path"`
+	Fixture string `json:"fixture"`
+}
+
+// NewSkillInitCmd creates the skill init command.
+func NewSkillInitCmd() *cobra.Command {
+	var opts skillInitFlags
+
+	cmd := &cobra.Command{
+		Use:   "init [skill-id]",
+		Short: "Create a new skill from a template",
+		Long: `Create a new skill definition with phases, dependencies, routing profiles
+and example prompt templates, and a sample input to run it on, so a new
+skill does not start from a blank file.
+
+In a terminal, the command asks for each setting, offering the flags'
+values as defaults. With --yes, or when stdin is not a terminal, the flags
+are used as they are. Phases run one after the other, each on the output of
+the one before; with --parallel, all phases but the last run at once on the
+input and the last merges their outputs. Earlier phases use the cheap
+profile and the last one --profile.
+
+The skill is written to <dir>/<skill-id>.yaml and the sample input to
+<dir>/testdata/<skill-id>.md. The skill is loaded once written, to check
+that it is valid.`,
+		Example: `  # Answer the prompts
+  sr skill init
+
+  # Create a three-phase skill without prompts
+  sr skill init release-notes --phases collect,draft,polish --profile premium --yes
+
+  # Fan out two analyses and merge them, in the project's skills directory
+  sr skill init api-review --phases security,style,summary --parallel --dir .skillrunner/skills -y`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var id string
+			if len(args) > 0 {
+				id = args[0]
+			}
+			return runSkillInit(id, opts)
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.Name, "name", "", "display name (default: the skill ID in title case)")
+	cmd.Flags().StringVar(&opts.Description, "description", "", "what the skill does")
+	cmd.Flags().StringSliceVar(&opts.Phases, "phases", defaultScaffoldPhases, "phase IDs in order, comma-separated")
+	cmd.Flags().StringVarP(&opts.Profile, "profile", "p", skill.ProfileBalanced,
+		fmt.Sprintf("default routing profile: %s, %s, %s or a custom profile", skill.ProfileCheap, skill.ProfileBalanced, skill.ProfilePremium))
+	cmd.Flags().BoolVar(&opts.Parallel, "parallel", false, "run all phases but the last at once, the last merging their outputs")
+	cmd.Flags().StringVar(&opts.Dir, "dir", "", "directory to write the skill to (default: ~/.skillrunner/skills)")
+	cmd.Flags().BoolVar(&opts.Force, "force", false, "overwrite an existing skill file")
+	cmd.Flags().BoolVarP(&opts.Yes, "yes", "y", false, "use the flags without asking")
+
+	return cmd
+}
+
+func runSkillInit(id string, opts skillInitFlags) error {
+	formatter := GetFormatter()
+
+	scaffold := infraSkills.Scaffold{
+		ID:          id,
+		Name:        opts.Name,
+		Description: opts.Description,
+		Phases:      opts.Phases,
+		Profile:     opts.Profile,
+		Parallel:    opts.Parallel,
+	}
+	if !opts.Yes && formatter.Format() != output.FormatJSON && isTerminal(os.Stdin) {
+		p := &prompter{reader: bufio.NewReader(os.Stdin), formatter: formatter}
+		if err := promptScaffold(p, &scaffold); err != nil {
+			return err
+		}
+	}
+	if scaffold.ID == "" {
+		return fmt.Errorf("a skill ID is required: sr skill init <skill-id>")
+	}
+	if err := scaffold.Validate(); err != nil {
+		return err
+	}
+	if err := validateProfile(scaffold.Profile); err != nil {
+		return err
+	}
+
+	dir := opts.Dir
+	if dir == "" {
+		var err error
+		if dir, err = getSkillsDir(); err != nil {
+			return err
+		}
+	}
+	result, err := writeScaffold(dir, scaffold, opts.Force)
+	if err != nil {
+		return err
+	}
+
+	if formatter.Format() == output.FormatJSON {
+		return formatter.JSON(result)
+	}
+	formatter.Success("Created skill %s", result.Skill)
+	formatter.Item("Skill", result.Path)
+	formatter.Item("Sample input", result.Fixture)
+	formatter.Println("")
+	formatter.SubHeader("Next steps")
+	formatter.BulletItem(fmt.Sprintf("Edit the prompts in %s", result.Path))
+	formatter.BulletItem(fmt.Sprintf("Check it: sr skills validate %s", result.Path))
+	formatter.BulletItem(fmt.Sprintf("Try it: sr run %s --input-file %s", result.Skill, result.Fixture))
+	formatter.BulletItem(fmt.Sprintf("Pin a good run and test changes against it: sr history pin latest, then sr skill test %s --against-golden <run>", result.Skill))
+	return nil
+}
+
+// promptScaffold asks for each setting of the new skill, offering the
+// current ones as defaults.
+func promptScaffold(p *prompter, s *infraSkills.Scaffold) error {
+	var err error
+	if s.ID, err = p.prompt("Skill ID (lowercase, hyphens)", s.ID); err != nil {
+		return err
+	}
+	if s.Name == "" && s.ID != "" {
+		s.Name = infraSkills.TitleCase(s.ID)
+	}
+	if s.Name, err = p.prompt("Name", s.Name); err != nil {
+		return err
+	}
+	if s.Description, err = p.prompt("Description", s.Description); err != nil {
+		return err
+	}
+	phases, err := p.prompt("Phases (comma-separated, in order)", strings.Join(s.Phases, ","))
+	if err != nil {
+		return err
+	}
+	s.Phases = splitPhases(phases)
+	if len(s.Phases) > 2 {
+		if s.Parallel, err = p.promptYesNo("Run all phases but the last in parallel", s.Parallel); err != nil {
+			return err
+		}
+	}
+	s.Profile, err = p.prompt("Routing profile of the final phase", s.Profile)
+	return err
+}
+
+// splitPhases splits a comma-separated list of phase IDs.
+func splitPhases(list string) []string {
+	var phases []string
+	for _, id := range strings.Split(list, ",") {
+		if id = strings.TrimSpace(id); id != "" {
+			phases = append(phases, id)
+		}
+	}
+	return phases
+}
+
+// writeScaffold writes the new skill and its sample input under dir and
+// loads the skill back to check it. An existing skill file is only
+// overwritten with force.
+func writeScaffold(dir string, s infraSkills.Scaffold, force bool) (SkillInitResult, error) {
+	result := SkillInitResult{
+		Skill:   s.ID,
+		Path:    filepath.Join(dir, s.ID+".yaml"),
+		Fixture: filepath.Join(dir, "testdata", s.ID+".md"),
+	}
+	if _, err := os.Stat(result.Path); err == nil && !force {
+		return result, fmt.Errorf("skill already exists: %s (use --force to overwrite)", result.Path)
+	}
+
+	data, err := s.YAML()
+	if err != nil {
+		return result, err
+	}
+	if err := os.MkdirAll(filepath.Dir(result.Fixture), 0o755); err != nil {
+		return result, fmt.Errorf("could not create skills directory: %w", err)
+	}
+	if err := os.WriteFile(result.Path, data, 0o644); err != nil {
+		return result, err
+	}
+	if _, err := os.Stat(result.Fixture); err != nil {
+		if err := os.WriteFile(result.Fixture, s.Fixture(), 0o644); err != nil {
+			return result, err
+		}
+	}
+
+	if _, err := infraSkills.NewLoader().LoadSkill(result.Path); err != nil {
+		return result, fmt.Errorf("generated skill is invalid: %w", err)
+	}
+	return result, nil
+}
@@ -0,0 +1,332 @@
+package commands
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/jbctechsolutions/skillrunner/internal/application"
+	"github.com/jbctechsolutions/skillrunner/internal/application/workflow"
+	"github.com/jbctechsolutions/skillrunner/internal/domain/skill"
+	domainWorkflow "github.com/jbctechsolutions/skillrunner/internal/domain/workflow"
+	"github.com/jbctechsolutions/skillrunner/internal/infrastructure/config"
+	"github.com/jbctechsolutions/skillrunner/internal/presentation/cli/output"
+)
+
+// SkillShowResult is the JSON output of sr skill show.
+type SkillShowResult struct {
+	ID             string           `json:"id"`
+	Name           string           `json:"name"`
+	Version        string           `json:"version"`
+	Description    string           `json:"description,omitempty"`
+	DefaultProfile string           `json:"default_profile"`
+	Batches        [][]string       `json:"batches"`
+	Phases         []SkillShowPhase `json:"phases"`
+	Metadata       map[string]any   `json:"metadata,omitempty"`
+}
+
+// SkillShowPhase is a phase of a skill and the routing it resolves to.
+type SkillShowPhase struct {
+	ID               string   `json:"id"`
+	Name             string   `json:"name"`
+	Batch            int      `json:"batch"`
+	DependsOn        []string `json:"depends_on,omitempty"`
+	RoutingProfile   string   `json:"routing_profile"`
+	ProviderPin      string   `json:"provider_pin,omitempty"`
+	PinSoft          bool     `json:"pin_soft,omitempty"`
+	ModelPin         string   `json:"model_pin,omitempty"`
+	ResolvedProvider string   `json:"resolved_provider"`
+	ResolvedModel    string   `json:"resolved_model"`
+	Skill            string   `json:"skill,omitempty"`
+	Optional         bool     `json:"optional,omitempty"`
+}
+
+// SkillValidateResult is the JSON output of sr skill validate for one
+// skill.
+type SkillValidateResult struct {
+	Skill  string   `json:"skill"`
+	Valid  bool     `json:"valid"`
+	Errors []string `json:"errors"`
+}
+
+// NewSkillShowCmd creates the skill show command.
+func NewSkillShowCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "show <skill|file>",
+		Short: "Show a skill's phase DAG and the routing of each phase",
+		Long: `Show a skill's details, the batches its phases run in, and how each phase
+is routed: its routing profile, its provider and model pins, and the
+provider and model profile routing resolves it to with the current
+configuration. Nothing is executed.
+
+The argument is a skill ID or name, or the path of a skill YAML file.`,
+		Example: `  # Show an installed skill
+  sr skills show code-review
+
+  # Show a skill file as JSON
+  sr skills show ./skills/go-refactor.yaml -o json`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runSkillShow(cmd.Context(), args[0])
+		},
+	}
+}
+
+// NewSkillValidateCmd creates the skill validate command.
+func NewSkillValidateCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "validate <skill|file>...",
+		Short: "Validate skill definitions without running them",
+		Long: `Validate skill definitions without running them: their schema and fields,
+the syntax and variables of their templates, cycles in their phase
+dependencies, routing profiles that are not built in or configured, and
+skill phases that run unknown skills or run themselves.
+
+Each argument is a skill ID or name, or the path of a skill YAML file. The
+command fails if any skill is invalid, so it can run in CI.`,
+		Example: `  # Validate skill files before installing them
+  sr skills validate ./skills/*.yaml
+
+  # Validate an installed skill as JSON
+  sr skills validate code-review -o json`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runSkillValidate(args)
+		},
+	}
+}
+
+// resolveSkillTarget returns the skill a command argument names: a skill
+// file if the path exists, otherwise an installed skill's ID or name.
+func resolveSkillTarget(container *application.Container, target string) (*skill.Skill, error) {
+	if info, err := os.Stat(target); err == nil && !info.IsDir() {
+		return container.SkillLoader().LoadSkill(target)
+	}
+	if registry := container.SkillRegistry(); registry != nil {
+		if sk := registry.GetSkill(target); sk != nil {
+			return sk, nil
+		}
+		if sk := registry.GetSkillByName(target); sk != nil {
+			return sk, nil
+		}
+	}
+	return nil, fmt.Errorf("skill not found: %s", target)
+}
+
+func runSkillShow(ctx context.Context, target string) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	formatter := GetFormatter()
+	container := GetContainer()
+	if container == nil {
+		return fmt.Errorf("application not initialized")
+	}
+
+	sk, err := resolveSkillTarget(container, target)
+	if err != nil {
+		return err
+	}
+	plan, err := createPlanner(container).GeneratePlan(ctx, sk, "", "")
+	if err != nil {
+		return fmt.Errorf("failed to resolve the phases of %s: %w", sk.ID(), err)
+	}
+
+	result := skillShowResult(sk, plan.Batches, plan.Phases)
+	if formatter.Format() == output.FormatJSON {
+		return formatter.JSON(result)
+	}
+	printSkillShow(formatter, result)
+	return nil
+}
+
+// skillShowResult describes the skill with its phases in batch order, each
+// with the routing the planner resolved for it.
+func skillShowResult(sk *skill.Skill, batches [][]string, plans []domainWorkflow.PhasePlan) SkillShowResult {
+	routing := sk.Routing()
+	result := SkillShowResult{
+		ID:             sk.ID(),
+		Name:           sk.Name(),
+		Version:        sk.Version(),
+		Description:    sk.Description(),
+		DefaultProfile: routing.DefaultProfile,
+		Batches:        batches,
+		Phases:         make([]SkillShowPhase, 0, len(plans)),
+		Metadata:       sk.Metadata(),
+	}
+	for _, plan := range plans {
+		phase, err := sk.GetPhase(plan.PhaseID)
+		if err != nil {
+			continue
+		}
+		var subSkill string
+		if phase.SubSkill != nil {
+			subSkill = phase.SubSkill.Skill
+		}
+		result.Phases = append(result.Phases, SkillShowPhase{
+			ID:               phase.ID,
+			Name:             phase.Name,
+			Batch:            plan.BatchIndex,
+			DependsOn:        phase.DependsOn,
+			RoutingProfile:   cmp.Or(phase.RoutingProfile, routing.DefaultProfile),
+			ProviderPin:      phase.Provider,
+			PinSoft:          phase.PinSoft,
+			ModelPin:         phase.Model,
+			ResolvedProvider: plan.ResolvedProvider,
+			ResolvedModel:    plan.ResolvedModel,
+			Skill:            subSkill,
+			Optional:         phase.Optional,
+		})
+	}
+	return result
+}
+
+func printSkillShow(formatter *output.Formatter, result SkillShowResult) {
+	formatter.Header(result.Name)
+	formatter.Item("ID", result.ID)
+	formatter.Item("Version", result.Version)
+	formatter.Item("Default Profile", result.DefaultProfile)
+	if result.Description != "" {
+		formatter.Item("Description", strings.TrimSpace(result.Description))
+	}
+	formatter.Println("")
+
+	formatter.SubHeader("DAG")
+	for i, batch := range result.Batches {
+		formatter.Item(fmt.Sprintf("Batch %d", i+1), strings.Join(batch, ", "))
+	}
+	formatter.Println("")
+
+	formatter.SubHeader("Phases")
+	table := output.TableData{
+		Columns: []output.TableColumn{
+			{Header: "Batch", Width: 5, Align: output.AlignRight},
+			{Header: "Phase", Width: 20, Align: output.AlignLeft},
+			{Header: "Depends On", Width: 20, Align: output.AlignLeft},
+			{Header: "Profile", Width: 10, Align: output.AlignLeft},
+			{Header: "Pin", Width: 20, Align: output.AlignLeft},
+			{Header: "Provider", Width: 12, Align: output.AlignLeft},
+			{Header: "Model", Width: 24, Align: output.AlignLeft},
+		},
+	}
+	for _, phase := range result.Phases {
+		table.Rows = append(table.Rows, []string{
+			fmt.Sprintf("%d", phase.Batch+1),
+			phase.ID,
+			strings.Join(phase.DependsOn, ", "),
+			phase.RoutingProfile,
+			phasePin(phase),
+			phase.ResolvedProvider,
+			phase.ResolvedModel,
+		})
+	}
+	formatter.Table(table)
+}
+
+// phasePin describes a phase's pins, or the skill a skill phase runs.
+func phasePin(phase SkillShowPhase) string {
+	var pins []string
+	if phase.ProviderPin != "" {
+		pin := phase.ProviderPin
+		if phase.PinSoft {
+			pin += " (soft)"
+		}
+		pins = append(pins, pin)
+	}
+	if phase.ModelPin != "" {
+		pins = append(pins, phase.ModelPin)
+	}
+	if phase.Skill != "" {
+		pins = append(pins, "skill "+phase.Skill)
+	}
+	return strings.Join(pins, ", ")
+}
+
+func runSkillValidate(targets []string) error {
+	formatter := GetFormatter()
+	container := GetContainer()
+	if container == nil {
+		return fmt.Errorf("application not initialized")
+	}
+
+	results := make([]SkillValidateResult, 0, len(targets))
+	invalid := 0
+	for _, target := range targets {
+		result := SkillValidateResult{Skill: target, Errors: []string{}}
+		sk, err := resolveSkillTarget(container, target)
+		if err != nil {
+			result.Errors = append(result.Errors, err.Error())
+		} else {
+			result.Skill = sk.ID()
+			var resolver workflow.SkillResolver
+			if registry := container.SkillRegistry(); registry != nil {
+				resolver = registry
+			}
+			result.Errors = append(result.Errors, validateSkillRouting(sk, container.RoutingConfiguration(), resolver)...)
+		}
+		result.Valid = len(result.Errors) == 0
+		if !result.Valid {
+			invalid++
+		}
+		results = append(results, result)
+	}
+
+	if formatter.Format() == output.FormatJSON {
+		if err := formatter.JSON(results); err != nil {
+			return err
+		}
+	} else {
+		printSkillValidate(formatter, results)
+	}
+
+	if invalid > 0 {
+		return fmt.Errorf("%d of %d skill(s) invalid", invalid, len(results))
+	}
+	return nil
+}
+
+// validateSkillRouting checks what loading a skill cannot: that its routing
+// profiles are built in or configured in routing, and that its skill phases
+// run installed skills and do not run themselves. resolve may be nil.
+func validateSkillRouting(sk *skill.Skill, routing *config.RoutingConfiguration, resolve workflow.SkillResolver) []string {
+	var errs []string
+	if profile := sk.Routing().DefaultProfile; profile != "" && !routing.HasProfile(profile) {
+		errs = append(errs, fmt.Sprintf("unknown default routing profile %q: must be one of %s", profile, strings.Join(profileNames(routing), ", ")))
+	}
+	for _, phase := range sk.Phases() {
+		if phase.RoutingProfile != "" && !routing.HasProfile(phase.RoutingProfile) {
+			errs = append(errs, fmt.Sprintf("phase %s: unknown routing profile %q: must be one of %s", phase.ID, phase.RoutingProfile, strings.Join(profileNames(routing), ", ")))
+		}
+	}
+
+	if resolve == nil {
+		return errs
+	}
+	for _, id := range sk.SubSkills() {
+		if id != sk.ID() && resolve.GetSkill(id) == nil {
+			errs = append(errs, fmt.Sprintf("runs unknown skill %q", id))
+		}
+	}
+	if cycle := workflow.SubSkillCycle(sk, resolve); cycle != nil {
+		errs = append(errs, fmt.Sprintf("skill phases run in a cycle: %s", strings.Join(cycle, " -> ")))
+	}
+	return errs
+}
+
+func printSkillValidate(formatter *output.Formatter, results []SkillValidateResult) {
+	formatter.Header("Skill Validation")
+	for _, result := range results {
+		if result.Valid {
+			formatter.Success("%s is valid", result.Skill)
+			continue
+		}
+		formatter.Error("%s is invalid", result.Skill)
+		for _, msg := range result.Errors {
+			formatter.BulletItem(msg)
+		}
+	}
+}
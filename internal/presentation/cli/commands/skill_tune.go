@@ -0,0 +1,385 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/jbctechsolutions/skillrunner/internal/application/ports"
+	appProvider "github.com/jbctechsolutions/skillrunner/internal/application/provider"
+	"github.com/jbctechsolutions/skillrunner/internal/application/skills"
+	"github.com/jbctechsolutions/skillrunner/internal/application/workflow"
+	"github.com/jbctechsolutions/skillrunner/internal/domain/skill"
+	infraSkills "github.com/jbctechsolutions/skillrunner/internal/infrastructure/skills"
+	"github.com/jbctechsolutions/skillrunner/internal/presentation/cli/output"
+)
+
+// Defaults of the skill tune command.
+const (
+	defaultTuneMaxCost = 1.0
+	defaultTuneCases   = 5
+)
+
+// skillTuneOptions holds the flags of the skill tune command.
+type skillTuneOptions struct {
+	Phase    string
+	Proposer string
+	Judge    string
+	Inputs   []string
+	Cases    int
+	Rounds   int
+	Variants int
+	MaxCost  float64
+	Profile  string
+	DryRun   bool
+}
+
+// SkillTuneResult is the JSON output of sr skill tune.
+type SkillTuneResult struct {
+	Report  *workflow.TuneReport `json:"report"`
+	Version string               `json:"version,omitempty"` // Version of the tuned skill
+	Diff    string               `json:"diff,omitempty"`
+	Written string               `json:"written,omitempty"` // Skill file the tuned skill was written to
+}
+
+// NewSkillTuneCmd creates the skill tune command.
+func NewSkillTuneCmd() *cobra.Command {
+	var opts skillTuneOptions
+
+	cmd := &cobra.Command{
+		Use:   "tune <skill|file>",
+		Short: "Tune a phase prompt with a propose-and-score loop (experimental)",
+		Long: `Tune the prompt template of a skill phase with a meta-prompting loop.
+
+The current prompt is scored on the test cases, then for each round the
+--proposer model proposes --variants rewrites of the best prompt so far,
+each is scored, and the best is kept. Test cases are the skill's pinned
+golden runs (see 'sr history pin'), scored by how similar each phase output
+stays to the golden run's, and any --input, which need a --judge model to
+rate the final output from 1 to 10. With both, scores are averaged.
+
+The loop stops once runs, proposals and scoring cost --max-cost. When a
+variant beats the current prompt, it is written back to the skill file as a
+new version and the diff is shown for review; --dry-run only shows the diff.
+Tuning runs are not recorded in run history.`,
+		Example: `  # Tune the only phase of summarize against its golden runs
+  sr skill tune summarize --proposer gpt-4o
+
+  # Tune the draft phase on two inputs rated by a judge, spending at most $2
+  sr skill tune ./skills/report.yaml --phase draft --proposer gpt-4o \
+    --judge gpt-4o-mini --input "$(cat q1.md)" --input "$(cat q2.md)" --max-cost 2
+
+  # Show the winning prompt without changing the skill
+  sr skill tune summarize --proposer gpt-4o --dry-run`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runSkillTune(cmd.Context(), args[0], opts)
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.Phase, "phase", "", "phase whose prompt to tune (required for skills with more than one phase)")
+	cmd.Flags().StringVar(&opts.Proposer, "proposer", "", "model that proposes prompt variants (required)")
+	cmd.Flags().StringVar(&opts.Judge, "judge", "", "model that rates final outputs from 1 to 10")
+	cmd.Flags().StringArrayVar(&opts.Inputs, "input", nil, "test input rated by the judge (repeatable)")
+	cmd.Flags().IntVar(&opts.Cases, "cases", defaultTuneCases, "maximum number of golden runs to test against")
+	cmd.Flags().IntVar(&opts.Rounds, "rounds", workflow.DefaultTuneRounds, "rounds of proposals")
+	cmd.Flags().IntVar(&opts.Variants, "variants", workflow.DefaultTuneVariants, "variants proposed per round")
+	cmd.Flags().Float64Var(&opts.MaxCost, "max-cost", defaultTuneMaxCost, "stop once tuning has cost this much in USD")
+	cmd.Flags().StringVarP(&opts.Profile, "profile", "p", skill.ProfileBalanced, "routing profile for the test runs")
+	cmd.Flags().BoolVar(&opts.DryRun, "dry-run", false, "show the diff without writing the skill file")
+	_ = cmd.MarkFlagRequired("proposer")
+
+	return cmd
+}
+
+func runSkillTune(ctx context.Context, target string, opts skillTuneOptions) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if opts.MaxCost <= 0 {
+		return fmt.Errorf("--max-cost must be positive")
+	}
+	if len(opts.Inputs) > 0 && opts.Judge == "" {
+		return fmt.Errorf("--input test cases need a --judge model")
+	}
+	if err := validateProfile(opts.Profile); err != nil {
+		return err
+	}
+
+	formatter := GetFormatter()
+	container := GetContainer()
+	if container == nil {
+		return fmt.Errorf("application not initialized")
+	}
+
+	sk, path, err := resolveSkillFile(container.SkillRegistry(), container.SkillLoader(), target)
+	if err != nil {
+		return err
+	}
+	if path == "" && !opts.DryRun {
+		return fmt.Errorf("no project or user skill file defines %s; pass the file path or use --dry-run", sk.ID())
+	}
+	phaseID, err := tunedPhase(sk, opts.Phase)
+	if err != nil {
+		return err
+	}
+	if err := skills.VerifyRequirements(sk); err != nil {
+		return err
+	}
+
+	runs, _, err := historyRepositories()
+	if err != nil {
+		return err
+	}
+	cases, err := tuneCases(ctx, runs, sk, opts)
+	if err != nil {
+		return err
+	}
+
+	providerRegistry := container.ProviderRegistry()
+	costCalc := container.CostCalculator()
+	provider := selectProvider(providerRegistry.ListProviders(), opts.Profile)
+	if provider == nil {
+		return fmt.Errorf("no suitable provider found for profile: %s", opts.Profile)
+	}
+	proposer, err := providerRegistry.FindByModel(ctx, opts.Proposer)
+	if err != nil {
+		return fmt.Errorf("proposer model: %w", err)
+	}
+	var judge ports.ProviderPort
+	if opts.Judge != "" {
+		if judge, err = providerRegistry.FindByModel(ctx, opts.Judge); err != nil {
+			return fmt.Errorf("judge model: %w", err)
+		}
+	}
+
+	executorConfig := workflow.DefaultExecutorConfig()
+	executorConfig.ProviderSelector = appProvider.NewPinSelector(providerRegistry, pinFallbackWarner(formatter))
+	executorConfig.ConcurrencyGroups = container.ConcurrencyGroups()
+	executorConfig.Skills = container.SkillRegistry()
+	applyRouting(formatter, &executorConfig)
+	if err := applyBudgets(ctx, formatter, &executorConfig, container.RoutingConfiguration().Budgets, costCalc); err != nil {
+		return err
+	}
+
+	tuneConfig := workflow.TuneConfig{
+		PhaseID:  phaseID,
+		Rounds:   opts.Rounds,
+		Variants: opts.Variants,
+		MaxCost:  opts.MaxCost,
+		Run: func(ctx context.Context, variant *skill.Skill, input string) (*workflow.ExecutionResult, error) {
+			result, err := workflow.NewExecutor(provider, executorConfig).Execute(ctx, variant, input)
+			calculateCostsForResult(result, costCalc)
+			recordCosts(ctx, formatter, result, costCalc, nil)
+			return result, err
+		},
+		Propose: workflow.NewLLMProposer(proposer, opts.Proposer, costCalc),
+		Score:   workflow.NewTuneScorer(judge, opts.Judge, costCalc),
+	}
+
+	if formatter.Format() != output.FormatJSON {
+		formatter.Info("Tuning %s phase %s on %d test case(s), up to %s...", sk.ID(), phaseID, len(cases), formatCost(opts.MaxCost))
+	}
+	report, err := workflow.RunTune(ctx, sk, cases, tuneConfig)
+	if err != nil {
+		return err
+	}
+
+	result := SkillTuneResult{Report: report}
+	if report.Best != nil && path != "" {
+		original, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read skill file: %w", err)
+		}
+		result.Version = infraSkills.NextVersion(sk.Version())
+		tuned, err := infraSkills.RewritePhasePrompt(original, phaseID, report.Best.Prompt, result.Version)
+		if err != nil {
+			return fmt.Errorf("failed to rewrite skill file: %w", err)
+		}
+		result.Diff = infraSkills.DiffLines(path, path, string(original), string(tuned))
+
+		if !opts.DryRun {
+			info, err := os.Stat(path)
+			if err != nil {
+				return fmt.Errorf("failed to stat skill file: %w", err)
+			}
+			if err := os.WriteFile(path, tuned, info.Mode().Perm()); err != nil {
+				return fmt.Errorf("failed to write skill file: %w", err)
+			}
+			result.Written = path
+		}
+	}
+
+	if formatter.Format() == output.FormatJSON {
+		return formatter.JSON(result)
+	}
+	printSkillTune(formatter, result)
+	return nil
+}
+
+// resolveSkillFile finds a skill by file path, ID or name, and the project or
+// user skill file it can be written back to. The path is "" for skills
+// without one, such as built-in and policy skills.
+func resolveSkillFile(registry *skills.Registry, loader *infraSkills.Loader, target string) (*skill.Skill, string, error) {
+	if info, err := os.Stat(target); err == nil && !info.IsDir() {
+		sk, err := loader.LoadSkill(target)
+		if err != nil {
+			return nil, "", err
+		}
+		return sk, target, nil
+	}
+
+	if registry == nil {
+		return nil, "", fmt.Errorf("skill registry not available")
+	}
+	sk := registry.GetSkill(target)
+	if sk == nil {
+		sk = registry.GetSkillByName(target)
+	}
+	if sk == nil {
+		return nil, "", fmt.Errorf("skill not found: %s", target)
+	}
+	if source := registry.GetSource(sk.ID()); source != nil && source.Source() == skill.SourcePolicy {
+		return sk, "", nil
+	}
+
+	// Project skills override user skills, so look there first
+	for _, dir := range []string{registry.ProjectDir(), registry.UserDir()} {
+		if dir == "" {
+			continue
+		}
+		if _, err := os.Stat(dir); err != nil {
+			continue
+		}
+		path, err := loader.FindSkillFile(dir, sk.ID())
+		if err != nil {
+			return nil, "", err
+		}
+		if path != "" {
+			return sk, path, nil
+		}
+	}
+	return sk, "", nil
+}
+
+// tunedPhase returns the ID of the phase to tune: the given one, or the
+// skill's only phase.
+func tunedPhase(sk *skill.Skill, phaseID string) (string, error) {
+	if phaseID != "" {
+		if _, err := sk.GetPhase(phaseID); err != nil {
+			return "", fmt.Errorf("phase %s not found in skill %s", phaseID, sk.ID())
+		}
+		return phaseID, nil
+	}
+
+	phases := sk.Phases()
+	if len(phases) != 1 {
+		ids := make([]string, len(phases))
+		for i, p := range phases {
+			ids[i] = p.ID
+		}
+		return "", fmt.Errorf("--phase is required for skills with more than one phase (%s)", strings.Join(ids, ", "))
+	}
+	return phases[0].ID, nil
+}
+
+// tuneCases returns the test cases of a tuning loop: the skill's most recent
+// pinned golden runs, up to opts.Cases, and the --input values.
+func tuneCases(ctx context.Context, runs ports.WorkflowCheckpointPort, sk *skill.Skill, opts skillTuneOptions) ([]workflow.TuneCase, error) {
+	var cases []workflow.TuneCase
+	if opts.Cases > 0 {
+		history, err := runs.List(ctx, &ports.WorkflowCheckpointFilter{SkillID: sk.ID()})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list runs: %w", err)
+		}
+		for _, run := range history {
+			if !run.Pinned() {
+				continue
+			}
+			// Listings leave archived outputs out
+			if run, err = runs.Get(ctx, run.ID()); err != nil {
+				return nil, fmt.Errorf("failed to read run: %w", err)
+			}
+			if err := run.ArchiveError(); err != nil {
+				return nil, fmt.Errorf("golden run %s: %w", run.ID(), err)
+			}
+			cases = append(cases, workflow.TuneCase{Name: run.ID(), Input: run.Input(), Expected: run.PhaseOutputs()})
+			if len(cases) == opts.Cases {
+				break
+			}
+		}
+	}
+	for i, input := range opts.Inputs {
+		cases = append(cases, workflow.TuneCase{Name: fmt.Sprintf("input %d", i+1), Input: input})
+	}
+
+	if len(cases) == 0 {
+		return nil, fmt.Errorf("no test cases for %s: pin golden runs with 'sr history pin' or pass --input with --judge", sk.ID())
+	}
+	return cases, nil
+}
+
+func printSkillTune(formatter *output.Formatter, result SkillTuneResult) {
+	report := result.Report
+
+	formatter.Println("")
+	formatter.Header("Skill Tune")
+	formatter.Item("Skill", report.SkillID)
+	formatter.Item("Phase", report.PhaseID)
+	formatter.Item("Total Cost", formatCost(report.TotalCost))
+	formatter.Println("")
+
+	table := output.TableData{
+		Columns: []output.TableColumn{
+			{Header: "Round", Width: 5, Align: output.AlignRight},
+			{Header: "Status", Width: 11, Align: output.AlignLeft},
+			{Header: "Score", Width: 5, Align: output.AlignRight},
+			{Header: "Cost", Width: 9, Align: output.AlignRight},
+			{Header: "Prompt", Width: 50, Align: output.AlignLeft},
+		},
+	}
+	for _, c := range append([]workflow.TuneCandidate{report.Baseline}, report.Candidates...) {
+		score := "-"
+		if c.Status == workflow.TuneStatusEvaluated {
+			score = fmt.Sprintf("%.2f", c.Score)
+		}
+		prompt := c.Prompt
+		if c.Error != "" {
+			prompt = c.Error
+		}
+		table.Rows = append(table.Rows, []string{
+			fmt.Sprintf("%d", c.Round),
+			c.Status,
+			score,
+			formatCost(c.Cost),
+			truncateString(strings.Join(strings.Fields(prompt), " "), 50),
+		})
+	}
+	formatter.Table(table)
+	formatter.Println("")
+
+	if report.Exhausted {
+		formatter.Warning("Stopped at the cost cap")
+	}
+	if report.Best == nil {
+		formatter.Info("No variant beat the current prompt (score %.2f); the skill is unchanged", report.Baseline.Score)
+		return
+	}
+
+	formatter.Success("Best variant scored %.2f, up from %.2f", report.Best.Score, report.Baseline.Score)
+	if result.Diff == "" {
+		formatter.Println("")
+		formatter.Println("%s", report.Best.Prompt)
+		return
+	}
+	formatter.Println("")
+	formatter.Println("%s", strings.TrimSuffix(result.Diff, "\n"))
+	if result.Written != "" {
+		formatter.Success("Wrote version %s to %s", result.Version, result.Written)
+	} else {
+		formatter.Info("Dry run: %s is unchanged", report.SkillID)
+	}
+}
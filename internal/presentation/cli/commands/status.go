@@ -45,7 +45,7 @@ func NewStatusCmd() *cobra.Command {
 		Long: `Display the health status of the skillrunner system.
 
 This includes:
-  • Provider connectivity and health (Ollama, Anthropic, OpenAI, Groq)
+  • Provider connectivity and health (Ollama, Anthropic, OpenAI, Groq, Together, Fireworks)
   • Available models per provider
   • Configuration status
   • Skill availability
@@ -128,12 +128,14 @@ func getProviderStatuses(container interface {
 	ProviderInitializer() *appProvider.Initializer
 }, checkHealth bool) []ProviderStatus {
 	// Define known providers in order
-	knownProviders := []string{"ollama", "anthropic", "openai", "groq"}
+	knownProviders := []string{"ollama", "anthropic", "openai", "groq", "together", "fireworks"}
 	providerTypes := map[string]string{
 		"ollama":    "local",
 		"anthropic": "cloud",
 		"openai":    "cloud",
 		"groq":      "cloud",
+		"together":  "cloud",
+		"fireworks": "cloud",
 	}
 
 	// If container is nil, return all providers as unavailable
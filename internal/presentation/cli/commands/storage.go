@@ -0,0 +1,202 @@
+package commands
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/jbctechsolutions/skillrunner/internal/presentation/cli/output"
+)
+
+// NewStorageCmd creates the storage management command.
+func NewStorageCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "storage",
+		Short: "Manage the storage backend",
+		Long: `Manage the backend that stores sessions, checkpoints, metrics and the
+disk cache.
+
+The backend is selected with storage.backend in ~/.skillrunner/config.yaml
+(default: sqlite). Pending migrations are applied whenever it is opened.`,
+	}
+
+	cmd.AddCommand(NewStorageStatusCmd())
+	cmd.AddCommand(NewStorageMigrateCmd())
+	cmd.AddCommand(NewStorageArchiveCmd())
+
+	return cmd
+}
+
+// NewStorageStatusCmd creates the storage status command.
+func NewStorageStatusCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "status",
+		Short: "Show the storage backend and its migrations",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			container := GetContainer()
+			if container == nil {
+				return fmt.Errorf("application not initialized")
+			}
+
+			formatter := GetFormatter()
+			backend := container.Storage()
+
+			statuses, err := backend.MigrationStatus(cmd.Context())
+			if err != nil {
+				return fmt.Errorf("failed to get migration status: %w", err)
+			}
+
+			if formatter.Format() == output.FormatJSON {
+				return formatter.JSON(map[string]any{
+					"backend":    backend.Name(),
+					"migrations": statuses,
+				})
+			}
+
+			formatter.Header("Storage")
+			formatter.Item("Backend", backend.Name())
+			formatter.Info("")
+
+			table := output.TableData{
+				Columns: []output.TableColumn{
+					{Header: "Version", Width: 7, Align: output.AlignRight},
+					{Header: "Name", Width: 40, Align: output.AlignLeft},
+					{Header: "Applied", Width: 19, Align: output.AlignLeft},
+				},
+				Rows: make([][]string, 0, len(statuses)),
+			}
+			pending := 0
+			for _, s := range statuses {
+				applied := "pending"
+				if s.Applied {
+					applied = s.AppliedAt.Local().Format("2006-01-02 15:04:05")
+				} else {
+					pending++
+				}
+				table.Rows = append(table.Rows, []string{fmt.Sprintf("%d", s.Version), s.Name, applied})
+			}
+			if err := formatter.Table(table); err != nil {
+				return err
+			}
+
+			formatter.Info("")
+			if pending > 0 {
+				formatter.Warning("%d pending migration(s); run 'sr storage migrate' to apply them", pending)
+			} else {
+				formatter.Success("Schema is up to date")
+			}
+			return nil
+		},
+	}
+}
+
+// NewStorageMigrateCmd creates the storage migrate command.
+func NewStorageMigrateCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "migrate",
+		Short: "Apply pending storage migrations",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			container := GetContainer()
+			if container == nil {
+				return fmt.Errorf("application not initialized")
+			}
+
+			formatter := GetFormatter()
+			backend := container.Storage()
+
+			applied, err := backend.Migrate(cmd.Context())
+			if err != nil {
+				return fmt.Errorf("failed to migrate %s storage: %w", backend.Name(), err)
+			}
+
+			if applied == 0 {
+				formatter.Success("%s storage is up to date", backend.Name())
+				return nil
+			}
+			formatter.Success("Applied %d migration(s) to %s storage", applied, backend.Name())
+			return nil
+		},
+	}
+}
+
+// defaultArchiveAfterDays is the age in days of the runs sr storage archive
+// archives when storage.archive.after_days is not set.
+const defaultArchiveAfterDays = 30
+
+// NewStorageArchiveCmd creates the storage archive command.
+func NewStorageArchiveCmd() *cobra.Command {
+	var olderThan string
+
+	cmd := &cobra.Command{
+		Use:   "archive",
+		Short: "Move the phase outputs of old runs to compressed files",
+		Long: `Move the phase outputs of completed, failed and abandoned runs out of the
+storage backend into gzip-compressed files, one per run, to bound the size
+of the database.
+
+Everything else about the runs stays in the database, so history listings,
+statistics and cost reports are unaffected. Commands that need the outputs
+of an archived run, such as 'sr checkpoint show -o json', read them back
+from the archive transparently.
+
+Runs started more than storage.archive.after_days days ago (default 30) are
+archived into storage.archive.dir (default ~/.skillrunner/archive). Run it
+periodically, for example from cron.`,
+		Example: `  # Archive runs older than storage.archive.after_days
+  sr storage archive
+
+  # Archive runs older than a week
+  sr storage archive --older-than 7d`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			container := GetContainer()
+			if container == nil {
+				return fmt.Errorf("application not initialized")
+			}
+
+			age := time.Duration(container.Config().Storage.Archive.AfterDays) * 24 * time.Hour
+			if age == 0 {
+				age = defaultArchiveAfterDays * 24 * time.Hour
+			}
+			if olderThan != "" {
+				var err error
+				if age, err = parseDuration(olderThan); err != nil {
+					return fmt.Errorf("invalid --older-than: %w", err)
+				}
+				if age <= 0 {
+					return fmt.Errorf("--older-than must be positive")
+				}
+			}
+
+			archiver := container.PhaseOutputArchiver()
+			if archiver == nil {
+				return fmt.Errorf("the checkpoint store does not support archiving")
+			}
+			dir, err := container.ArchiveDir()
+			if err != nil {
+				return err
+			}
+
+			formatter := GetFormatter()
+			archived, err := archiver.ArchiveOutputs(cmd.Context(), age, dir)
+			if err != nil {
+				return fmt.Errorf("failed to archive phase outputs: %w", err)
+			}
+
+			if formatter.Format() == output.FormatJSON {
+				return formatter.JSON(map[string]any{"archived": archived, "dir": dir})
+			}
+			if archived == 0 {
+				formatter.Info("No runs to archive")
+				return nil
+			}
+			formatter.Success("Archived the phase outputs of %d run(s) to %s", archived, dir)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&olderThan, "older-than", "", "archive runs started longer ago than this (e.g., 30d); default storage.archive.after_days")
+
+	return cmd
+}
@@ -0,0 +1,231 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	appProvider "github.com/jbctechsolutions/skillrunner/internal/application/provider"
+	"github.com/jbctechsolutions/skillrunner/internal/application/skills"
+	"github.com/jbctechsolutions/skillrunner/internal/application/workflow"
+	"github.com/jbctechsolutions/skillrunner/internal/domain/skill"
+	"github.com/jbctechsolutions/skillrunner/internal/presentation/cli/output"
+)
+
+// sweepOptions holds the flags of the sweep command.
+type sweepOptions struct {
+	Params   []string
+	Profile  string
+	Parallel int
+	MaxCost  float64
+	Judge    string
+	NoMemory bool
+}
+
+// NewSweepCmd creates the sweep command.
+func NewSweepCmd() *cobra.Command {
+	var opts sweepOptions
+
+	cmd := &cobra.Command{
+		Use:   "sweep <skill> <input>",
+		Short: "Run a skill across a grid of parameters and compare the outputs",
+		Long: `Run a skill on the same input once for every combination of the given
+parameter values, and compare the outputs, costs and, with --judge, the
+scores a judge model gives them.
+
+Each --param sets a parameter for every phase of the skill:
+  temperature   sampling temperature (0 to 2)
+  model         model to pin, run on the provider that serves it
+  max_tokens    maximum output tokens
+  profile       routing profile
+
+Combinations run --parallel at a time. No new combination starts once the
+completed ones cost --max-cost, and routing budgets apply as for sr run.
+Sweep runs are not recorded in run history.`,
+		Example: `  # Compare three temperatures on two models
+  sr sweep summarize "$(cat report.md)" \
+    --param temperature=0,0.3,0.7 --param model=llama3.2:8b,gpt-4o-mini
+
+  # Score each output with a judge model, spending at most $1
+  sr sweep summarize "$(cat report.md)" --param temperature=0,0.7 \
+    --judge gpt-4o --max-cost 1.00 -o json`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runSweep(cmd.Context(), args[0], args[1], opts)
+		},
+	}
+
+	cmd.Flags().StringArrayVar(&opts.Params, "param", nil, "parameter values to sweep, as name=value1,value2 (repeatable)")
+	cmd.Flags().StringVarP(&opts.Profile, "profile", "p", skill.ProfileBalanced, "routing profile for combinations without a model or profile")
+	cmd.Flags().IntVar(&opts.Parallel, "parallel", workflow.DefaultSweepParallel, "combinations to run at once")
+	cmd.Flags().Float64Var(&opts.MaxCost, "max-cost", 0, "stop starting combinations once the sweep has cost this much in USD (0 for no cap)")
+	cmd.Flags().StringVar(&opts.Judge, "judge", "", "model that scores each output from 1 to 10")
+	cmd.Flags().BoolVar(&opts.NoMemory, "no-memory", false, "disable memory injection from MEMORY.md/CLAUDE.md")
+	_ = cmd.MarkFlagRequired("param")
+
+	return cmd
+}
+
+func runSweep(ctx context.Context, skillName, input string, opts sweepOptions) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if err := validateProfile(opts.Profile); err != nil {
+		return err
+	}
+	if opts.MaxCost < 0 {
+		return fmt.Errorf("--max-cost must not be negative")
+	}
+
+	var params []workflow.SweepParam
+	seen := make(map[string]bool)
+	for _, raw := range opts.Params {
+		param, err := workflow.ParseSweepParam(raw)
+		if err != nil {
+			return err
+		}
+		if seen[param.Name] {
+			return fmt.Errorf("parameter %s given more than once", param.Name)
+		}
+		seen[param.Name] = true
+		params = append(params, param)
+	}
+
+	formatter := GetFormatter()
+	container := GetContainer()
+	if container == nil {
+		return fmt.Errorf("application not initialized")
+	}
+
+	registry := container.SkillRegistry()
+	if registry == nil {
+		return fmt.Errorf("skill registry not available")
+	}
+	sk := registry.GetSkill(skillName)
+	if sk == nil {
+		sk = registry.GetSkillByName(skillName)
+	}
+	if sk == nil {
+		return fmt.Errorf("skill not found: %s", skillName)
+	}
+	if err := skills.VerifyRequirements(sk); err != nil {
+		return err
+	}
+
+	providerRegistry := container.ProviderRegistry()
+	costCalc := container.CostCalculator()
+
+	executorConfig := workflow.DefaultExecutorConfig()
+	executorConfig.MemoryContent = loadMemoryContent(opts.NoMemory)
+	executorConfig.ProviderSelector = appProvider.NewPinSelector(providerRegistry, pinFallbackWarner(formatter))
+	executorConfig.ConcurrencyGroups = container.ConcurrencyGroups()
+	executorConfig.Skills = container.SkillRegistry()
+	applyRouting(formatter, &executorConfig)
+	if err := applyBudgets(ctx, formatter, &executorConfig, container.RoutingConfiguration().Budgets, costCalc); err != nil {
+		return err
+	}
+
+	sweepConfig := workflow.SweepConfig{
+		Parallel: opts.Parallel,
+		MaxCost:  opts.MaxCost,
+		Run: func(ctx context.Context, variant *skill.Skill, combo workflow.SweepCombination) (*workflow.ExecutionResult, error) {
+			profile := opts.Profile
+			if p := combo.Get(workflow.SweepProfile); p != "" {
+				profile = p
+			}
+			prov := selectProvider(providerRegistry.ListProviders(), profile)
+			if model := combo.Get(workflow.SweepModel); model != "" {
+				p, err := providerRegistry.FindByModel(ctx, model)
+				if err != nil {
+					return nil, err
+				}
+				prov = p
+			}
+			if prov == nil {
+				return nil, fmt.Errorf("no suitable provider found for profile: %s", profile)
+			}
+
+			result, err := workflow.NewExecutor(prov, executorConfig).Execute(ctx, variant, input)
+			calculateCostsForResult(result, costCalc)
+			recordCosts(ctx, formatter, result, costCalc, nil)
+			return result, err
+		},
+	}
+	if opts.Judge != "" {
+		judge, err := providerRegistry.FindByModel(ctx, opts.Judge)
+		if err != nil {
+			return fmt.Errorf("judge model: %w", err)
+		}
+		sweepConfig.Judge = workflow.NewLLMJudge(judge, opts.Judge)
+	}
+
+	if formatter.Format() != output.FormatJSON {
+		formatter.Info("Running %s with %d combinations...", sk.ID(), len(workflow.SweepCombinations(params)))
+	}
+	report, err := workflow.RunSweep(ctx, sk, input, params, sweepConfig)
+	if err != nil {
+		return err
+	}
+
+	if formatter.Format() == output.FormatJSON {
+		return formatter.JSON(report)
+	}
+	return printSweepReport(formatter, report)
+}
+
+// printSweepReport prints the comparison matrix of a sweep.
+func printSweepReport(formatter *output.Formatter, report *workflow.SweepReport) error {
+	formatter.Println("")
+	formatter.Header("Sweep Results")
+	formatter.Item("Skill", report.SkillID)
+	formatter.Item("Total Cost", formatCost(report.TotalCost))
+	formatter.Println("")
+
+	var table output.TableData
+	for _, name := range report.Params {
+		table.Columns = append(table.Columns, output.TableColumn{Header: name, Width: 14, Align: output.AlignLeft})
+	}
+	table.Columns = append(table.Columns,
+		output.TableColumn{Header: "Status", Width: 11, Align: output.AlignLeft},
+		output.TableColumn{Header: "Score", Width: 5, Align: output.AlignRight},
+		output.TableColumn{Header: "Tokens", Width: 7, Align: output.AlignRight},
+		output.TableColumn{Header: "Cost", Width: 9, Align: output.AlignRight},
+		output.TableColumn{Header: "Time", Width: 8, Align: output.AlignRight},
+		output.TableColumn{Header: "Output", Width: 40, Align: output.AlignLeft},
+	)
+
+	for _, res := range report.Results {
+		var row []string
+		for _, s := range res.Combination {
+			row = append(row, truncateString(s.Value, 14))
+		}
+		score := "-"
+		if res.Score != nil {
+			score = fmt.Sprintf("%.1f", *res.Score)
+		}
+		preview := res.Output
+		if res.Error != "" {
+			preview = res.Error
+		}
+		row = append(row,
+			res.Status,
+			score,
+			fmt.Sprintf("%d", res.Tokens),
+			formatCost(res.Cost),
+			formatDuration(res.Duration),
+			truncateString(strings.Join(strings.Fields(preview), " "), 40),
+		)
+		table.Rows = append(table.Rows, row)
+	}
+	if err := formatter.Table(table); err != nil {
+		return err
+	}
+
+	if best := report.Best(); best != nil {
+		formatter.Println("")
+		formatter.Success("Best score %.1f with %s", *best.Score, best.Combination)
+	}
+	return nil
+}
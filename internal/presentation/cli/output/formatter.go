@@ -10,6 +10,8 @@ import (
 	"strings"
 	"sync"
 	"time"
+
+	"gopkg.in/yaml.v3"
 )
 
 // Format represents the output format type.
@@ -19,8 +21,15 @@ const (
 	FormatTable Format = "table"
 	FormatJSON  Format = "json"
 	FormatText  Format = "text"
+	FormatYAML  Format = "yaml"
 )
 
+// Structured reports whether the format is a machine-readable document,
+// JSON or YAML, so that output in it must hold nothing else.
+func (f Format) Structured() bool {
+	return f == FormatJSON || f == FormatYAML
+}
+
 // Color represents ANSI color codes for terminal output.
 type Color string
 
@@ -352,11 +361,47 @@ func (f *Formatter) JSONCompact(data any) error {
 	return json.NewEncoder(f.writer).Encode(data)
 }
 
+// YAML writes data as YAML with the field names and order of its JSON
+// encoding, so that both formats of a document have the same keys.
+func (f *Formatter) YAML(data any) error {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	// JSON is YAML; parsing it to a node keeps the order of its keys
+	var node yaml.Node
+	if err := yaml.Unmarshal(raw, &node); err != nil {
+		return err
+	}
+	blockStyle(&node)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	encoder := yaml.NewEncoder(f.writer)
+	encoder.SetIndent(len(f.indent))
+	if err := encoder.Encode(&node); err != nil {
+		return err
+	}
+	return encoder.Close()
+}
+
+// blockStyle clears the JSON flow and quoting styles of the node and its
+// children, so that they are written in YAML's block style.
+func blockStyle(node *yaml.Node) {
+	node.Style = 0
+	for _, child := range node.Content {
+		blockStyle(child)
+	}
+}
+
 // FormatAuto formats data according to the current format setting.
 func (f *Formatter) FormatAuto(data any, tableData *TableData) error {
 	switch f.Format() {
 	case FormatJSON:
 		return f.JSON(data)
+	case FormatYAML:
+		return f.YAML(data)
 	case FormatTable:
 		if tableData != nil {
 			return f.Table(*tableData)
@@ -781,6 +826,8 @@ func ParseFormat(s string) (Format, error) {
 		return FormatTable, nil
 	case "json":
 		return FormatJSON, nil
+	case "yaml", "yml":
+		return FormatYAML, nil
 	case "text", "":
 		return FormatText, nil
 	default:
@@ -225,6 +225,42 @@ func TestFormatter_JSON(t *testing.T) {
 	}
 }
 
+func TestFormatter_YAML(t *testing.T) {
+	var buf bytes.Buffer
+	f := NewFormatter(WithWriter(&buf), WithFormat(FormatYAML))
+
+	data := struct {
+		Status string   `json:"status"`
+		Count  int      `json:"count"`
+		Code   string   `json:"code"`
+		Output string   `json:"output"`
+		Tags   []string `json:"tags"`
+		Empty  []string `json:"empty"`
+	}{"ok", 2, "123", "line one\nline two", []string{"a", "b"}, []string{}}
+
+	if err := f.FormatAuto(data, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := `status: ok
+count: 2
+code: "123"
+output: |-
+  line one
+  line two
+tags:
+  - a
+  - b
+empty: []
+`
+	if buf.String() != want {
+		t.Errorf("YAML() =\n%s\nwant the JSON keys in order in block style:\n%s", buf.String(), want)
+	}
+	if !FormatYAML.Structured() || !FormatJSON.Structured() || FormatText.Structured() {
+		t.Error("Structured() should hold for JSON and YAML only")
+	}
+}
+
 func TestFormatter_JSONCompact(t *testing.T) {
 	var buf bytes.Buffer
 	f := NewFormatter(WithWriter(&buf))
@@ -668,6 +704,8 @@ func TestParseFormat(t *testing.T) {
 		{"  table  ", FormatTable, false},
 		{"json", FormatJSON, false},
 		{"JSON", FormatJSON, false},
+		{"yaml", FormatYAML, false},
+		{"yml", FormatYAML, false},
 		{"text", FormatText, false},
 		{"", FormatText, false},
 		{"unknown", FormatText, true},
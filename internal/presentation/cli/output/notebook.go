@@ -0,0 +1,199 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// NotebookReport is a completed skill run rendered as a notebook: one cell
+// per phase with its prompt and output.
+type NotebookReport struct {
+	Skill       string
+	Version     string
+	Profile     string
+	Provider    string
+	Request     string
+	Status      string
+	Error       string
+	StartTime   time.Time
+	Duration    time.Duration
+	TotalTokens int
+	TotalCost   float64
+	Resources   []NotebookRow  // Local resource usage, if sampled
+	Cells       []NotebookCell // In execution order
+	FinalOutput string
+}
+
+// NotebookRow is a labeled value in the header table of a notebook report.
+type NotebookRow struct {
+	Label string
+	Value string
+}
+
+// NotebookCell is the record of one phase of a notebook report.
+type NotebookCell struct {
+	ID           string
+	Name         string
+	Status       string
+	Provider     string
+	Model        string
+	Prompt       string
+	Output       string
+	Error        string
+	Duration     time.Duration
+	InputTokens  int
+	OutputTokens int
+	Cost         float64
+	CacheHit     bool
+	Artifacts    []string
+	Experiment   string // A/B experiment the phase took part in, if any
+	Variant      string
+	Warnings     []string // Quality problems found in the output
+}
+
+// RenderNotebook writes report as notebook-style Markdown: a header with the
+// run's details, then a section per phase with its prompt in a collapsed
+// <details> block and its output in an expanded one. The result renders on
+// GitHub and similar viewers and is meant to be committed as an analysis
+// artifact.
+func RenderNotebook(w io.Writer, report *NotebookReport) error {
+	var sb strings.Builder
+
+	fmt.Fprintf(&sb, "# %s", report.Skill)
+	if report.Version != "" {
+		fmt.Fprintf(&sb, " v%s", report.Version)
+	}
+	sb.WriteString("\n\n")
+
+	sb.WriteString("| | |\n|---|---|\n")
+	if !report.StartTime.IsZero() {
+		notebookRow(&sb, "Run", report.StartTime.Format(time.RFC3339))
+	}
+	notebookRow(&sb, "Status", report.Status)
+	notebookRow(&sb, "Profile", report.Profile)
+	notebookRow(&sb, "Provider", report.Provider)
+	notebookRow(&sb, "Duration", report.Duration.Round(time.Millisecond).String())
+	notebookRow(&sb, "Tokens", fmt.Sprintf("%d", report.TotalTokens))
+	notebookRow(&sb, "Cost", fmt.Sprintf("$%.4f", report.TotalCost))
+	for _, row := range report.Resources {
+		notebookRow(&sb, row.Label, row.Value)
+	}
+	sb.WriteString("\n")
+
+	if report.Request != "" {
+		sb.WriteString("## Request\n\n")
+		sb.WriteString(fenced(report.Request, "text"))
+		sb.WriteString("\n")
+	}
+
+	for i, cell := range report.Cells {
+		writeNotebookCell(&sb, i+1, cell)
+	}
+
+	if report.Error != "" {
+		sb.WriteString("## Error\n\n")
+		sb.WriteString(fenced(report.Error, "text"))
+		sb.WriteString("\n")
+	}
+
+	if report.FinalOutput != "" {
+		sb.WriteString("## Final Output\n\n")
+		sb.WriteString(strings.TrimSpace(report.FinalOutput))
+		sb.WriteString("\n")
+	}
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// writeNotebookCell writes the section of one phase.
+func writeNotebookCell(sb *strings.Builder, n int, cell NotebookCell) {
+	name := cell.Name
+	if name == "" {
+		name = cell.ID
+	}
+	fmt.Fprintf(sb, "## [%d] %s\n\n", n, name)
+
+	var meta []string
+	if cell.Status != "" {
+		meta = append(meta, cell.Status)
+	}
+	if cell.Provider != "" {
+		meta = append(meta, cell.Provider)
+	}
+	if cell.Model != "" {
+		meta = append(meta, "`"+cell.Model+"`")
+	}
+	if cell.Duration > 0 {
+		meta = append(meta, cell.Duration.Round(time.Millisecond).String())
+	}
+	if cell.InputTokens+cell.OutputTokens > 0 {
+		meta = append(meta, fmt.Sprintf("%d → %d tokens", cell.InputTokens, cell.OutputTokens))
+	}
+	if cell.Cost > 0 {
+		meta = append(meta, fmt.Sprintf("$%.4f", cell.Cost))
+	}
+	if cell.CacheHit {
+		meta = append(meta, "cached")
+	}
+	if cell.Experiment != "" {
+		meta = append(meta, fmt.Sprintf("experiment %s: %s", cell.Experiment, cell.Variant))
+	}
+	if len(meta) > 0 {
+		fmt.Fprintf(sb, "_%s_\n\n", strings.Join(meta, " · "))
+	}
+
+	if cell.Prompt != "" {
+		sb.WriteString("<details>\n<summary>Prompt</summary>\n\n")
+		sb.WriteString(fenced(cell.Prompt, "text"))
+		sb.WriteString("\n</details>\n\n")
+	}
+
+	switch {
+	case cell.Error != "":
+		sb.WriteString("**Error:**\n\n")
+		sb.WriteString(fenced(cell.Error, "text"))
+		sb.WriteString("\n")
+	case cell.Output != "":
+		sb.WriteString("<details open>\n<summary>Output</summary>\n\n")
+		sb.WriteString(strings.TrimSpace(cell.Output))
+		sb.WriteString("\n\n</details>\n\n")
+	}
+
+	for _, warning := range cell.Warnings {
+		fmt.Fprintf(sb, "> **Warning:** %s\n\n", warning)
+	}
+
+	if len(cell.Artifacts) > 0 {
+		sb.WriteString("Artifacts:\n\n")
+		for _, path := range cell.Artifacts {
+			fmt.Fprintf(sb, "- `%s`\n", path)
+		}
+		sb.WriteString("\n")
+	}
+}
+
+// notebookRow writes a row of the header table, skipping empty values.
+func notebookRow(sb *strings.Builder, key, value string) {
+	if value == "" {
+		return
+	}
+	fmt.Fprintf(sb, "| %s | %s |\n", key, strings.ReplaceAll(value, "|", `\|`))
+}
+
+// fenced returns text in a code fence longer than any backtick run inside it.
+func fenced(text, lang string) string {
+	longest, run := 0, 0
+	for _, r := range text {
+		if r == '`' {
+			run++
+			longest = max(longest, run)
+		} else {
+			run = 0
+		}
+	}
+	fence := strings.Repeat("`", max(3, longest+1))
+	return fence + lang + "\n" + strings.TrimRight(text, "\n") + "\n" + fence + "\n"
+}
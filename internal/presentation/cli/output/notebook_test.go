@@ -0,0 +1,95 @@
+package output
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRenderNotebook(t *testing.T) {
+	report := &NotebookReport{
+		Skill:       "code-review",
+		Version:     "1.0.0",
+		Profile:     "balanced",
+		Provider:    "anthropic",
+		Request:     "Review main.go",
+		Status:      "completed",
+		StartTime:   time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC),
+		Duration:    2500 * time.Millisecond,
+		TotalTokens: 300,
+		TotalCost:   0.0123,
+		Resources:   []NotebookRow{{Label: "CPU", Value: "peak 80%, avg 40%"}},
+		Cells: []NotebookCell{
+			{
+				ID:           "analyze",
+				Name:         "Analyze",
+				Status:       "completed",
+				Model:        "claude-sonnet-4",
+				Prompt:       "Analyze this:\n```go\nfunc main() {}\n```",
+				Output:       "Looks fine.",
+				Duration:     time.Second,
+				InputTokens:  100,
+				OutputTokens: 50,
+				Cost:         0.005,
+				Warnings:     []string{"the output is 95% similar to the input"},
+			},
+			{
+				ID:     "report",
+				Status: "failed",
+				Error:  "provider unavailable",
+			},
+		},
+		FinalOutput: "## Summary\n\nNo issues.",
+	}
+
+	var buf bytes.Buffer
+	if err := RenderNotebook(&buf, report); err != nil {
+		t.Fatalf("RenderNotebook() error = %v", err)
+	}
+	got := buf.String()
+
+	for _, want := range []string{
+		"# code-review v1.0.0\n",
+		"| Run | 2025-03-01T12:00:00Z |",
+		"| Cost | $0.0123 |",
+		"| CPU | peak 80%, avg 40% |",
+		"## Request\n\n```text\nReview main.go\n```",
+		"## [1] Analyze\n\n_completed · `claude-sonnet-4` · 1s · 100 → 50 tokens · $0.0050_",
+		"<details>\n<summary>Prompt</summary>\n\n````text\nAnalyze this:\n```go\nfunc main() {}\n```\n````\n\n</details>",
+		"<details open>\n<summary>Output</summary>\n\nLooks fine.\n\n</details>\n\n> **Warning:** the output is 95% similar to the input\n",
+		"## [2] report\n\n_failed_\n\n**Error:**\n\n```text\nprovider unavailable\n```",
+		"## Final Output\n\n## Summary\n\nNo issues.\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("notebook missing %q:\n%s", want, got)
+		}
+	}
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) { return 0, errors.New("disk full") }
+
+func TestRenderNotebook_WriteError(t *testing.T) {
+	if err := RenderNotebook(failingWriter{}, &NotebookReport{Skill: "s"}); err == nil {
+		t.Error("expected write error")
+	}
+}
+
+func TestFenced(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"plain", "```\nplain\n```\n"},
+		{"a ``` b", "````\na ``` b\n````\n"},
+		{"trailing\n\n", "```\ntrailing\n```\n"},
+	}
+	for _, tt := range tests {
+		if got := fenced(tt.text, ""); got != tt.want {
+			t.Errorf("fenced(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
@@ -11,23 +11,72 @@ import (
 )
 
 // StreamingOutput provides real-time output display for streaming LLM responses.
+//
+// Phases that run at once stream one at a time: the output of the phase
+// shown first streams live, while the output of the others is held and
+// shown, with their headers, as soon as the phases before them end. With a
+// spinner, a status line names the phases running in the background and
+// those awaiting dependencies whenever the output pauses.
 type StreamingOutput struct {
 	mu              sync.Mutex
 	writer          io.Writer
 	colored         bool
 	currentPhase    string
-	phaseName       string
-	phaseIndex      int
 	totalPhases     int
 	inputTokens     int
 	outputTokens    int
 	startTime       time.Time
-	phaseStartTime  time.Time
-	contentBuffer   strings.Builder
 	showTokenCounts bool
 	showPhaseInfo   bool
+
+	phases  map[string]*streamPhase
+	queue   []string // started phases waiting to be shown, in start order
+	active  string   // phase whose output is shown live
+	plan    []PlannedPhase
+	started map[string]bool
+
+	spinner     bool
+	frame       int
+	lastWrite   time.Time
+	atLineStart bool
+	statusShown bool
+	stop        chan struct{}
+	stopped     chan struct{}
+}
+
+// PlannedPhase describes a phase of the workflow for the status line.
+type PlannedPhase struct {
+	ID        string
+	Name      string
+	DependsOn []string
+}
+
+// streamPhase is the display state of a started phase.
+type streamPhase struct {
+	name    string
+	index   int
+	start   time.Time
+	held    strings.Builder // output not shown yet
+	written bool            // whether the phase produced output
+	newline bool            // whether its output ends with a newline
+	ended   bool
+	footer  string // completion or failure lines, once it ended
 }
 
+// spinnerFrames animate the status line.
+var spinnerFrames = []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"}
+
+const (
+	// statusIdle is how long the output pauses before the status line shows.
+	statusIdle = 300 * time.Millisecond
+
+	// statusInterval is how often the status line is redrawn.
+	statusInterval = 100 * time.Millisecond
+
+	// maxStatusWidth truncates the status line.
+	maxStatusWidth = 100
+)
+
 // StreamingOutputOption is a functional option for configuring StreamingOutput.
 type StreamingOutputOption func(*StreamingOutput)
 
@@ -39,6 +88,9 @@ func NewStreamingOutput(opts ...StreamingOutputOption) *StreamingOutput {
 		showTokenCounts: true,
 		showPhaseInfo:   true,
 		startTime:       time.Now(),
+		phases:          make(map[string]*streamPhase),
+		started:         make(map[string]bool),
+		atLineStart:     true,
 	}
 
 	for _, opt := range opts {
@@ -76,6 +128,22 @@ func WithShowPhaseInfo(enabled bool) StreamingOutputOption {
 	}
 }
 
+// WithStatusSpinner enables or disables the status line. Enable it only
+// when writing to a terminal.
+func WithStatusSpinner(enabled bool) StreamingOutputOption {
+	return func(so *StreamingOutput) {
+		so.spinner = enabled
+	}
+}
+
+// SetPlan sets the phases of the workflow, so the status line can name the
+// phases awaiting dependencies.
+func (so *StreamingOutput) SetPlan(phases []PlannedPhase) {
+	so.mu.Lock()
+	defer so.mu.Unlock()
+	so.plan = phases
+}
+
 // StartWorkflow initializes the streaming output for a new workflow.
 func (so *StreamingOutput) StartWorkflow(skillName, skillVersion string, totalPhases int) {
 	so.mu.Lock()
@@ -85,133 +153,336 @@ func (so *StreamingOutput) StartWorkflow(skillName, skillVersion string, totalPh
 	so.startTime = time.Now()
 	so.inputTokens = 0
 	so.outputTokens = 0
-	so.contentBuffer.Reset()
 
 	// Print header
 	if so.colored {
-		fmt.Fprintf(so.writer, "%s%s v%s%s\n", ColorBold, skillName, skillVersion, ColorReset)
+		so.write(fmt.Sprintf("%s%s v%s%s\n", ColorBold, skillName, skillVersion, ColorReset))
 	} else {
-		fmt.Fprintf(so.writer, "%s v%s\n", skillName, skillVersion)
+		so.write(fmt.Sprintf("%s v%s\n", skillName, skillVersion))
+	}
+	so.write(strings.Repeat("─", len(skillName)+len(skillVersion)+3) + "\n\n")
+
+	if so.spinner && so.stop == nil {
+		so.stop = make(chan struct{})
+		so.stopped = make(chan struct{})
+		go so.animate(so.stop, so.stopped)
 	}
-	fmt.Fprintf(so.writer, "%s\n\n", strings.Repeat("─", len(skillName)+len(skillVersion)+3))
 }
 
-// StartPhase indicates a new phase is beginning.
+// StartPhase indicates a new phase is beginning. Its header is shown at
+// once, or when the phases started before it that are still shown end.
 func (so *StreamingOutput) StartPhase(phaseID, phaseName string, phaseIndex int) {
 	so.mu.Lock()
 	defer so.mu.Unlock()
 
 	so.currentPhase = phaseID
-	so.phaseName = phaseName
-	so.phaseIndex = phaseIndex
-	so.phaseStartTime = time.Now()
-	so.contentBuffer.Reset()
-
-	if so.showPhaseInfo {
-		if so.colored {
-			fmt.Fprintf(so.writer, "%s[%d/%d] %s%s\n",
-				ColorCyan, phaseIndex, so.totalPhases, phaseName, ColorReset)
-		} else {
-			fmt.Fprintf(so.writer, "[%d/%d] %s\n", phaseIndex, so.totalPhases, phaseName)
-		}
-	}
+	so.started[phaseID] = true
+	so.phases[phaseID] = &streamPhase{name: phaseName, index: phaseIndex, start: time.Now()}
+	so.queue = append(so.queue, phaseID)
+	so.showNext()
 }
 
-// WriteChunk writes a streaming chunk to the output.
+// WriteChunk writes a streaming chunk of the phase started last.
 func (so *StreamingOutput) WriteChunk(chunk string) {
+	so.WritePhaseChunk(so.current(), chunk)
+}
+
+// WritePhaseChunk writes a streaming chunk of a phase, or holds it until
+// the phase is shown.
+func (so *StreamingOutput) WritePhaseChunk(phaseID, chunk string) {
 	so.mu.Lock()
 	defer so.mu.Unlock()
 
-	so.contentBuffer.WriteString(chunk)
-	_, _ = fmt.Fprint(so.writer, chunk)
+	p := so.phases[phaseID]
+	if p == nil || chunk == "" {
+		so.write(chunk)
+		return
+	}
+	p.written = true
+	p.newline = strings.HasSuffix(chunk, "\n")
+	if phaseID == so.active {
+		so.write(chunk)
+	} else {
+		p.held.WriteString(chunk)
+	}
 }
 
-// CompletePhase marks the current phase as complete.
+// CompletePhase marks the phase started last as complete.
 func (so *StreamingOutput) CompletePhase(inputTokens, outputTokens int, modelUsed string) {
+	so.CompletePhaseOf(so.current(), inputTokens, outputTokens, modelUsed)
+}
+
+// CompletePhaseOf marks a phase as complete.
+func (so *StreamingOutput) CompletePhaseOf(phaseID string, inputTokens, outputTokens int, modelUsed string) {
 	so.mu.Lock()
 	defer so.mu.Unlock()
 
-	// Ensure we end with a newline
-	if so.contentBuffer.Len() > 0 {
-		content := so.contentBuffer.String()
-		if !strings.HasSuffix(content, "\n") {
-			fmt.Fprintln(so.writer)
-		}
-	}
-
 	so.inputTokens += inputTokens
 	so.outputTokens += outputTokens
 
-	phaseDuration := time.Since(so.phaseStartTime)
+	p := so.phases[phaseID]
+	if p == nil || !(so.showTokenCounts || so.showPhaseInfo) {
+		so.end(phaseID, "")
+		return
+	}
 
-	if so.showTokenCounts || so.showPhaseInfo {
-		fmt.Fprintln(so.writer)
+	var b strings.Builder
+	b.WriteString("\n")
+	if so.colored {
+		fmt.Fprintf(&b, "%s✓ %s completed%s", ColorGreen, p.name, ColorReset)
+	} else {
+		fmt.Fprintf(&b, "✓ %s completed", p.name)
+	}
+	if so.showTokenCounts {
+		details := fmt.Sprintf("(tokens: %d in, %d out | %s | %s)", inputTokens, outputTokens, modelUsed, formatStreamDuration(time.Since(p.start)))
 		if so.colored {
-			fmt.Fprintf(so.writer, "%s✓ %s completed%s", ColorGreen, so.phaseName, ColorReset)
-		} else {
-			fmt.Fprintf(so.writer, "✓ %s completed", so.phaseName)
-		}
-
-		if so.showTokenCounts {
-			if so.colored {
-				fmt.Fprintf(so.writer, " %s(tokens: %d in, %d out | %s | %s)%s\n",
-					ColorDim, inputTokens, outputTokens, modelUsed, formatStreamDuration(phaseDuration), ColorReset)
-			} else {
-				fmt.Fprintf(so.writer, " (tokens: %d in, %d out | %s | %s)\n",
-					inputTokens, outputTokens, modelUsed, formatStreamDuration(phaseDuration))
-			}
+			fmt.Fprintf(&b, " %s%s%s", ColorDim, details, ColorReset)
 		} else {
-			fmt.Fprintln(so.writer)
+			b.WriteString(" " + details)
 		}
-		fmt.Fprintln(so.writer)
 	}
+	b.WriteString("\n\n")
+	so.end(phaseID, b.String())
 }
 
-// FailPhase marks the current phase as failed.
+// FailPhase marks the phase started last as failed.
 func (so *StreamingOutput) FailPhase(err error) {
+	so.FailPhaseOf(so.current(), err)
+}
+
+// FailPhaseOf marks a phase as failed.
+func (so *StreamingOutput) FailPhaseOf(phaseID string, err error) {
 	so.mu.Lock()
 	defer so.mu.Unlock()
 
-	fmt.Fprintln(so.writer)
+	name := phaseID
+	if p := so.phases[phaseID]; p != nil {
+		name = p.name
+	}
+	footer := fmt.Sprintf("\n✗ %s failed: %v\n", name, err)
 	if so.colored {
-		fmt.Fprintf(so.writer, "%s✗ %s failed: %v%s\n", ColorRed, so.phaseName, err, ColorReset)
-	} else {
-		fmt.Fprintf(so.writer, "✗ %s failed: %v\n", so.phaseName, err)
+		footer = fmt.Sprintf("\n%s✗ %s failed: %v%s\n", ColorRed, name, err, ColorReset)
 	}
+	so.end(phaseID, footer)
 }
 
 // CompleteWorkflow marks the workflow as complete and shows summary.
 func (so *StreamingOutput) CompleteWorkflow(success bool) {
+	so.stopSpinner()
+
 	so.mu.Lock()
 	defer so.mu.Unlock()
 
+	// Show the phases still held, such as those a failure interrupted
+	for so.active != "" || len(so.queue) > 0 {
+		so.active = ""
+		so.showNext()
+	}
+
 	totalDuration := time.Since(so.startTime)
 	totalTokens := so.inputTokens + so.outputTokens
 
-	fmt.Fprintln(so.writer, strings.Repeat("─", 40))
-	if success {
+	so.write(strings.Repeat("─", 40) + "\n")
+	switch {
+	case success && so.colored:
+		so.write(fmt.Sprintf("%s✓ Workflow completed successfully%s\n", ColorGreen, ColorReset))
+	case success:
+		so.write("✓ Workflow completed successfully\n")
+	case so.colored:
+		so.write(fmt.Sprintf("%s✗ Workflow failed%s\n", ColorRed, ColorReset))
+	default:
+		so.write("✗ Workflow failed\n")
+	}
+
+	if so.showTokenCounts {
+		summary := fmt.Sprintf("Total: %d tokens (%d in, %d out) | Duration: %s",
+			totalTokens, so.inputTokens, so.outputTokens, formatStreamDuration(totalDuration))
 		if so.colored {
-			fmt.Fprintf(so.writer, "%s✓ Workflow completed successfully%s\n", ColorGreen, ColorReset)
-		} else {
-			fmt.Fprintf(so.writer, "✓ Workflow completed successfully\n")
+			summary = string(ColorDim) + summary + string(ColorReset)
 		}
-	} else {
-		if so.colored {
-			fmt.Fprintf(so.writer, "%s✗ Workflow failed%s\n", ColorRed, ColorReset)
+		so.write(summary + "\n")
+	}
+}
+
+// current returns the phase started last.
+func (so *StreamingOutput) current() string {
+	so.mu.Lock()
+	defer so.mu.Unlock()
+	return so.currentPhase
+}
+
+// end records a phase's footer, and shows it and the phases held behind
+// the phase if it is shown.
+func (so *StreamingOutput) end(phaseID, footer string) {
+	p := so.phases[phaseID]
+	if p == nil {
+		so.write(footer)
+		return
+	}
+	p.ended = true
+	p.footer = footer
+	if phaseID == so.active {
+		so.writeFooter(p)
+		so.active = ""
+		so.showNext()
+	}
+}
+
+// showNext shows the phases held in start order while none is shown live:
+// each one's header and held output, and the footer of those that ended.
+// Must be called with so.mu held.
+func (so *StreamingOutput) showNext() {
+	for so.active == "" && len(so.queue) > 0 {
+		id := so.queue[0]
+		so.queue = so.queue[1:]
+		p := so.phases[id]
+
+		if so.showPhaseInfo {
+			header := fmt.Sprintf("[%d/%d] %s", p.index, so.totalPhases, p.name)
+			if so.colored {
+				header = string(ColorCyan) + header + string(ColorReset)
+			}
+			so.write(header + "\n")
+		}
+		so.write(p.held.String())
+		p.held.Reset()
+
+		if p.ended {
+			so.writeFooter(p)
 		} else {
-			fmt.Fprintf(so.writer, "✗ Workflow failed\n")
+			so.active = id
 		}
 	}
+}
 
-	if so.showTokenCounts {
-		if so.colored {
-			fmt.Fprintf(so.writer, "%sTotal: %d tokens (%d in, %d out) | Duration: %s%s\n",
-				ColorDim, totalTokens, so.inputTokens, so.outputTokens, formatStreamDuration(totalDuration), ColorReset)
+// writeFooter writes a phase's footer on a line of its own.
+// Must be called with so.mu held.
+func (so *StreamingOutput) writeFooter(p *streamPhase) {
+	if p.footer == "" {
+		return
+	}
+	if p.written && !p.newline {
+		so.write("\n")
+	}
+	so.write(p.footer)
+}
+
+// write writes s, clearing the status line first.
+// Must be called with so.mu held.
+func (so *StreamingOutput) write(s string) {
+	if s == "" {
+		return
+	}
+	if so.statusShown {
+		_, _ = fmt.Fprint(so.writer, "\r\033[K")
+		so.statusShown = false
+	}
+	_, _ = fmt.Fprint(so.writer, s)
+	so.atLineStart = strings.HasSuffix(s, "\n")
+	so.lastWrite = time.Now()
+}
+
+// animate redraws the status line until the spinner stops.
+func (so *StreamingOutput) animate(stop <-chan struct{}, stopped chan<- struct{}) {
+	defer close(stopped)
+	ticker := time.NewTicker(statusInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			so.drawStatus()
+		}
+	}
+}
+
+// drawStatus draws the status line when the output has paused at the start
+// of a line.
+func (so *StreamingOutput) drawStatus() {
+	so.mu.Lock()
+	defer so.mu.Unlock()
+
+	if !so.atLineStart || time.Since(so.lastWrite) < statusIdle {
+		return
+	}
+	status := so.status()
+	if status == "" {
+		return
+	}
+	so.frame = (so.frame + 1) % len(spinnerFrames)
+	line := spinnerFrames[so.frame] + " " + status
+	if so.colored {
+		line = string(ColorDim) + line + string(ColorReset)
+	}
+	_, _ = fmt.Fprint(so.writer, "\r\033[K"+line)
+	so.statusShown = true
+}
+
+// status describes the phases running and those awaiting dependencies,
+// e.g. "running: Lint, Test · waiting: Summary on Lint".
+// Must be called with so.mu held.
+func (so *StreamingOutput) status() string {
+	var running, waiting []string
+	for _, id := range so.queue {
+		running = append(running, so.phases[id].name)
+	}
+	if p := so.phases[so.active]; p != nil {
+		running = append([]string{p.name}, running...)
+	}
+	names := make(map[string]string, len(so.plan))
+	for _, p := range so.plan {
+		names[p.ID] = p.Name
+	}
+	for _, p := range so.plan {
+		if so.started[p.ID] {
+			continue
+		}
+		var on []string
+		for _, dep := range p.DependsOn {
+			if d := so.phases[dep]; d == nil || !d.ended {
+				on = append(on, names[dep])
+			}
+		}
+		if len(on) > 0 {
+			waiting = append(waiting, p.Name+" on "+strings.Join(on, ", "))
 		} else {
-			fmt.Fprintf(so.writer, "Total: %d tokens (%d in, %d out) | Duration: %s\n",
-				totalTokens, so.inputTokens, so.outputTokens, formatStreamDuration(totalDuration))
+			waiting = append(waiting, p.Name)
 		}
 	}
+
+	var parts []string
+	if len(running) > 0 {
+		parts = append(parts, "running: "+strings.Join(running, ", "))
+	}
+	if len(waiting) > 0 {
+		parts = append(parts, "waiting: "+strings.Join(waiting, "; "))
+	}
+	status := strings.Join(parts, " · ")
+	if r := []rune(status); len(r) > maxStatusWidth {
+		status = string(r[:maxStatusWidth-1]) + "…"
+	}
+	return status
+}
+
+// stopSpinner stops the status line, clearing it.
+func (so *StreamingOutput) stopSpinner() {
+	so.mu.Lock()
+	stop, stopped := so.stop, so.stopped
+	so.stop = nil
+	so.mu.Unlock()
+	if stop == nil {
+		return
+	}
+	close(stop)
+	<-stopped
+
+	so.mu.Lock()
+	defer so.mu.Unlock()
+	if so.statusShown {
+		_, _ = fmt.Fprint(so.writer, "\r\033[K")
+		so.statusShown = false
+	}
 }
 
 // UpdateTokens updates the running token count display.
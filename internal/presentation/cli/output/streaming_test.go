@@ -2,8 +2,11 @@ package output
 
 import (
 	"bytes"
+	"errors"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 )
 
 func TestStreamingOutput_Basic(t *testing.T) {
@@ -152,6 +155,118 @@ func TestStreamingOutput_NoPhaseInfo(t *testing.T) {
 	}
 }
 
+func TestStreamingOutput_ParallelPhases(t *testing.T) {
+	var buf bytes.Buffer
+	so := NewStreamingOutput(
+		WithStreamingWriter(&buf),
+		WithStreamingColor(false),
+		WithShowTokenCounts(true),
+		WithShowPhaseInfo(true),
+	)
+
+	so.StartWorkflow("Test", "1.0", 3)
+	buf.Reset()
+
+	so.StartPhase("lint", "Lint", 1)
+	so.StartPhase("test", "Test", 2)
+	so.WritePhaseChunk("test", "tests pass")
+	so.WritePhaseChunk("lint", "no issues")
+	if got := buf.String(); got != "[1/3] Lint\nno issues" {
+		t.Errorf("expected only the first phase streamed, got: %q", got)
+	}
+
+	so.CompletePhaseOf("test", 10, 5, "model-b")
+	if strings.Contains(buf.String(), "Test") {
+		t.Errorf("expected the second phase held, got: %q", buf.String())
+	}
+
+	so.StartPhase("summary", "Summary", 3)
+	so.CompletePhaseOf("lint", 20, 8, "model-a")
+	so.WritePhaseChunk("summary", "all good")
+	so.FailPhaseOf("summary", errors.New("timeout"))
+
+	output := buf.String()
+	order := []string{
+		"no issues\n\n✓ Lint completed (tokens: 20 in, 8 out | model-a",
+		"[2/3] Test\ntests pass\n\n✓ Test completed (tokens: 10 in, 5 out | model-b",
+		"[3/3] Summary\nall good\n\n✗ Summary failed: timeout",
+	}
+	last := 0
+	for _, want := range order {
+		i := strings.Index(output, want)
+		if i < last {
+			t.Fatalf("expected %q after the previous phase, got: %q", want, output)
+		}
+		last = i
+	}
+	if in, out := so.GetTotalTokens(); in != 30 || out != 13 {
+		t.Errorf("expected totals 30/13, got %d/%d", in, out)
+	}
+}
+
+func TestStreamingOutput_Status(t *testing.T) {
+	so := NewStreamingOutput(WithStreamingWriter(&bytes.Buffer{}), WithStreamingColor(false))
+	so.SetPlan([]PlannedPhase{
+		{ID: "lint", Name: "Lint"},
+		{ID: "test", Name: "Test"},
+		{ID: "report", Name: "Report", DependsOn: []string{"lint", "test"}},
+	})
+	so.StartWorkflow("Test", "1.0", 3)
+	so.StartPhase("lint", "Lint", 1)
+	so.StartPhase("test", "Test", 2)
+
+	if got, want := so.status(), "running: Lint, Test · waiting: Report on Lint, Test"; got != want {
+		t.Errorf("status() = %q, want %q", got, want)
+	}
+
+	so.CompletePhaseOf("lint", 1, 1, "m")
+	if got, want := so.status(), "running: Test · waiting: Report on Test"; got != want {
+		t.Errorf("status() = %q, want %q", got, want)
+	}
+}
+
+func TestStreamingOutput_Spinner(t *testing.T) {
+	var buf syncBuffer
+	so := NewStreamingOutput(
+		WithStreamingWriter(&buf),
+		WithStreamingColor(false),
+		WithStatusSpinner(true),
+	)
+
+	so.StartWorkflow("Test", "1.0", 1)
+	so.StartPhase("lint", "Lint", 1)
+	time.Sleep(statusIdle + 3*statusInterval)
+	so.WritePhaseChunk("lint", "done\n")
+	so.CompletePhaseOf("lint", 1, 1, "m")
+	so.CompleteWorkflow(true)
+
+	output := buf.String()
+	if !strings.Contains(output, "running: Lint") {
+		t.Errorf("expected the status line while the phase was silent, got: %q", output)
+	}
+	if !strings.Contains(output, "\r\033[Kdone\n") {
+		t.Errorf("expected the status line cleared before the output, got: %q", output)
+	}
+}
+
+// syncBuffer is a bytes.Buffer safe for the spinner's concurrent writes.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
 func TestLiveTokenCounter_Basic(t *testing.T) {
 	var buf bytes.Buffer
 	ltc := NewLiveTokenCounter(&buf, false)
@@ -0,0 +1,101 @@
+package server
+
+import (
+	"context"
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"github.com/jbctechsolutions/skillrunner/internal/application/ports"
+)
+
+// AnonymousClient is the client of every request to a server without
+// configured clients.
+const AnonymousClient = "anonymous"
+
+// Client is an API client of the server, identified by its bearer token.
+type Client struct {
+	Name  string
+	Token string
+
+	// Weight is the client's share of a provider's rate limits relative to
+	// the other clients waiting for it; zero counts as 1.
+	Weight float64
+
+	// Admin allows the client to use the admin API and to see the runs of
+	// other clients.
+	Admin bool
+}
+
+// clientKey is the context key of the client making a request.
+type clientKey struct{}
+
+func withClient(ctx context.Context, c Client) context.Context {
+	return context.WithValue(ctx, clientKey{}, c)
+}
+
+// clientFrom returns the client of a request passed through authorized.
+func clientFrom(ctx context.Context) Client {
+	c, _ := ctx.Value(clientKey{}).(Client)
+	return c
+}
+
+// canSee reports whether c may see the runs of owner.
+func (c Client) canSee(owner string) bool {
+	return c.Admin || c.Name == owner
+}
+
+// UsageResponse is the body of GET /v1/admin/usage.
+type UsageResponse struct {
+	// Providers holds, by provider name, what each client has used of the
+	// rate limits of the provider.
+	Providers map[string][]ports.ClientUsage `json:"providers"`
+}
+
+// authenticate returns the client making r: the one whose token it bears,
+// or the anonymous admin client when the server has no clients.
+func (s *Server) authenticate(r *http.Request) (Client, bool) {
+	if len(s.cfg.Clients) == 0 {
+		return Client{Name: AnonymousClient, Admin: true}, true
+	}
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok || token == "" {
+		return Client{}, false
+	}
+	for _, c := range s.cfg.Clients {
+		if c.Token != "" && subtle.ConstantTimeCompare([]byte(token), []byte(c.Token)) == 1 {
+			return c, true
+		}
+	}
+	return Client{}, false
+}
+
+// authorized wraps h so that it serves only authenticated clients, and
+// only admin ones when admin is set. h finds the client with clientFrom.
+func (s *Server) authorized(admin bool, h http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		c, ok := s.authenticate(r)
+		if !ok {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			writeError(w, http.StatusUnauthorized, "a valid bearer token is required")
+			return
+		}
+		if admin && !c.Admin {
+			writeError(w, http.StatusForbidden, "admin access is required")
+			return
+		}
+		h(w, r.WithContext(withClient(r.Context(), c)))
+	}
+}
+
+// handleUsage reports what each client has used of the providers' rate
+// limits.
+func (s *Server) handleUsage(w http.ResponseWriter, _ *http.Request) {
+	resp := UsageResponse{Providers: map[string][]ports.ClientUsage{}}
+	if s.cfg.Usage != nil {
+		if usage := s.cfg.Usage(); usage != nil {
+			resp.Providers = usage
+		}
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
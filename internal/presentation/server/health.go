@@ -0,0 +1,64 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// readinessTimeout bounds the readiness checks of one /readyz request.
+const readinessTimeout = 10 * time.Second
+
+// Check is a readiness check: Run returns nil when the dependency it checks
+// is ready.
+type Check struct {
+	Name string
+	Run  func(ctx context.Context) error
+}
+
+// CheckResult is the outcome of a readiness check.
+type CheckResult struct {
+	Name  string `json:"name"`
+	OK    bool   `json:"ok"`
+	Error string `json:"error,omitempty"`
+}
+
+// Readiness is the body of a /readyz response.
+type Readiness struct {
+	Ready    bool          `json:"ready"`
+	Draining bool          `json:"draining,omitempty"`
+	Checks   []CheckResult `json:"checks,omitempty"`
+}
+
+// handleHealth reports that the process is alive and serving requests.
+func (s *Server) handleHealth(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+// handleReady runs the readiness checks, answering 503 unless all pass. A
+// draining server is not ready, so that load balancers stop sending it runs.
+func (s *Server) handleReady(w http.ResponseWriter, r *http.Request) {
+	if s.isDraining() {
+		writeJSON(w, http.StatusServiceUnavailable, Readiness{Draining: true})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), readinessTimeout)
+	defer cancel()
+	ready := Readiness{Ready: true}
+	for _, check := range s.cfg.ReadinessChecks {
+		result := CheckResult{Name: check.Name, OK: true}
+		if err := check.Run(ctx); err != nil {
+			result.OK = false
+			result.Error = err.Error()
+			ready.Ready = false
+		}
+		ready.Checks = append(ready.Checks, result)
+	}
+
+	code := http.StatusOK
+	if !ready.Ready {
+		code = http.StatusServiceUnavailable
+	}
+	writeJSON(w, code, ready)
+}
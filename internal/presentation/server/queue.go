@@ -0,0 +1,96 @@
+package server
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// assumedRunDuration is how long runs are assumed to take for wait
+// estimates until one has completed.
+const assumedRunDuration = time.Minute
+
+// QueueStatus is the body of GET /v1/queue: how busy the server's run slots
+// are and how long a run posted now would wait for one.
+type QueueStatus struct {
+	Running           int `json:"running"`
+	Queued            int `json:"queued"`
+	MaxConcurrentRuns int `json:"max_concurrent_runs,omitempty"`
+	MaxQueuedRuns     int `json:"max_queued_runs,omitempty"`
+
+	// EstimatedWait is how long a run posted now would wait before it
+	// starts, from the average duration of recent runs.
+	EstimatedWait time.Duration `json:"estimated_wait_ns"`
+
+	// Saturated reports that the queue is full and new runs are refused.
+	Saturated bool `json:"saturated,omitempty"`
+}
+
+// observeRun records the duration of a completed run for wait estimates.
+func (s *Server) observeRun(d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.avgRun == 0 {
+		s.avgRun = d
+		return
+	}
+	s.avgRun = (4*s.avgRun + d) / 5
+}
+
+// runEstimateLocked returns how long runs are expected to take. The caller
+// holds s.mu.
+func (s *Server) runEstimateLocked() time.Duration {
+	if s.avgRun == 0 {
+		return assumedRunDuration
+	}
+	return s.avgRun
+}
+
+// queueStatus returns the current state of the run queue.
+func (s *Server) queueStatus() QueueStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	limit := s.cfg.MaxConcurrentRuns
+	qs := QueueStatus{
+		Running:           s.admitted - s.queued,
+		Queued:            s.queued,
+		MaxConcurrentRuns: limit,
+		MaxQueuedRuns:     s.cfg.MaxQueuedRuns,
+	}
+	if limit > 0 && s.admitted >= limit {
+		// A new run starts once the runs ahead of it have finished, limit
+		// at a time
+		ahead := s.admitted - limit + 1
+		qs.EstimatedWait = s.runEstimateLocked() * time.Duration((ahead+limit-1)/limit)
+		qs.Saturated = s.admitted >= limit+max(s.cfg.MaxQueuedRuns, 0)
+	}
+	return qs
+}
+
+// retryAfter returns how long a client refused for a full queue should
+// wait before posting again: about until one of the executing runs ends.
+func (s *Server) retryAfter() time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return max(time.Second, s.runEstimateLocked()/time.Duration(max(s.cfg.MaxConcurrentRuns, 1)))
+}
+
+// setQueueHeaders reports the queue depth and estimated wait of a run posted
+// now in the headers of a response.
+func setQueueHeaders(w http.ResponseWriter, qs QueueStatus) {
+	w.Header().Set("X-Queue-Depth", strconv.Itoa(qs.Queued))
+	w.Header().Set("X-Estimated-Wait", strconv.Itoa(ceilSeconds(qs.EstimatedWait)))
+}
+
+// ceilSeconds returns d in whole seconds, rounded up.
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
+
+func (s *Server) handleQueue(w http.ResponseWriter, _ *http.Request) {
+	qs := s.queueStatus()
+	setQueueHeaders(w, qs)
+	writeJSON(w, http.StatusOK, qs)
+}
@@ -0,0 +1,100 @@
+package server
+
+import (
+	"sync"
+	"time"
+)
+
+// run is a run of the server and its subscribers.
+type run struct {
+	id     string
+	client string // name of the client that started the run
+
+	mu          sync.Mutex
+	current     RunStatus
+	events      []Event
+	subscribers map[chan Event]struct{}
+	endedAt     time.Time // zero while the run is in flight
+}
+
+func newRun(id, skill, client string) *run {
+	return &run{
+		id:          id,
+		client:      client,
+		current:     RunStatus{ExecutionID: id, Skill: skill, Status: StatusRunning},
+		subscribers: make(map[chan Event]struct{}),
+	}
+}
+
+// status returns the current status of the run.
+func (r *run) status() RunStatus {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.current
+}
+
+// finished reports whether the run has ended.
+func (r *run) finished() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return !r.endedAt.IsZero()
+}
+
+// finishedBefore reports whether the run ended before t.
+func (r *run) finishedBefore(t time.Time) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return !r.endedAt.IsZero() && r.endedAt.Before(t)
+}
+
+// publish records ev and sends it to the subscribers.
+func (r *run) publish(ev Event) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.publishLocked(ev)
+}
+
+func (r *run) publishLocked(ev Event) {
+	r.current = ev.RunStatus
+	r.events = append(r.events, ev)
+	for ch := range r.subscribers {
+		select {
+		case ch <- ev:
+		default:
+			// A subscriber that stopped reading misses the event
+		}
+	}
+}
+
+// finish publishes the last event of the run and ends its subscriptions.
+func (r *run) finish(ev Event) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.publishLocked(ev)
+	r.endedAt = time.Now()
+	for ch := range r.subscribers {
+		close(ch)
+	}
+	clear(r.subscribers)
+}
+
+// subscribe returns a channel that receives the events of the run so far
+// and then those to come, and is closed when the run ends.
+func (r *run) subscribe() (<-chan Event, func()) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	ch := make(chan Event, len(r.events)+8)
+	for _, ev := range r.events {
+		ch <- ev
+	}
+	if !r.endedAt.IsZero() {
+		close(ch)
+		return ch, func() {}
+	}
+	r.subscribers[ch] = struct{}{}
+	return ch, func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		delete(r.subscribers, ch)
+	}
+}
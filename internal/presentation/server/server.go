@@ -0,0 +1,452 @@
+// Package server runs skills for HTTP clients, for sr serve. Clients start
+// runs with POST /v1/runs and follow them as server-sent events. GET /healthz
+// and GET /readyz report whether the process is alive and ready for runs.
+//
+// Runs beyond the server's run slots wait in a queue, and runs beyond the
+// queue are refused with 429 and a Retry-After header. GET /v1/queue and
+// the X-Queue-Depth and X-Estimated-Wait headers of POST /v1/runs report
+// how busy the server is.
+//
+// With configured clients, every other request must carry the bearer token
+// of a client. Clients see only their own runs, and the rate limits of the
+// providers their runs share are split fairly among them; admin clients
+// see every run and what each client has used with GET /v1/admin/usage.
+//
+// On shutdown the server stops accepting runs and lets in-flight runs finish
+// within its drain timeout. Runs still going after it are interrupted with
+// workflow.ErrInterrupted, which keeps their checkpoints in progress, and
+// their subscribers are sent a shutdown event with a resume token. Posting
+// the token back as resume_token resumes the run from its checkpoint.
+package server
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/jbctechsolutions/skillrunner/internal/application/ports"
+	"github.com/jbctechsolutions/skillrunner/internal/application/workflow"
+	"github.com/jbctechsolutions/skillrunner/internal/infrastructure/logging"
+)
+
+// Run statuses.
+const (
+	StatusQueued      = "queued"
+	StatusRunning     = "running"
+	StatusCompleted   = "completed"
+	StatusFailed      = "failed"
+	StatusInterrupted = "interrupted"
+)
+
+// Types of the events sent to the subscribers of a run.
+const (
+	EventQueued    = "queued" // the run waits for one of the server's run slots
+	EventStarted   = "started"
+	EventCompleted = "completed"
+	EventFailed    = "failed"
+	EventShutdown  = "shutdown" // the run was interrupted; resume it with its resume token
+)
+
+const (
+	// maxRequestSize is the largest run request accepted.
+	maxRequestSize = 16 << 20
+
+	// finishedRunTTL is how long a finished run can still be looked up.
+	finishedRunTTL = time.Hour
+)
+
+var (
+	errDraining  = errors.New("server is shutting down")
+	errRunning   = errors.New("run is already in progress")
+	errSaturated = errors.New("server is at capacity; retry later")
+)
+
+// RunRequest is the body of POST /v1/runs.
+type RunRequest struct {
+	Skill   string `json:"skill"`
+	Input   string `json:"input"`
+	Profile string `json:"profile,omitempty"`
+
+	// ResumeToken resumes an interrupted run from its checkpoint, with its
+	// skill and input, instead of starting a new one.
+	ResumeToken string `json:"resume_token,omitempty"`
+}
+
+// RunFunc runs the skill of req as executionID until ctx ends.
+type RunFunc func(ctx context.Context, req RunRequest, executionID string) (*workflow.ExecutionResult, error)
+
+// Config configures a Server.
+type Config struct {
+	// DrainTimeout is how long in-flight runs may keep running after
+	// Shutdown is called before they are interrupted.
+	DrainTimeout time.Duration
+
+	// ReadinessChecks must all pass for /readyz to report ready.
+	ReadinessChecks []Check
+
+	// Clients are the clients allowed to use the server. Without clients
+	// the API is open and every request comes from AnonymousClient.
+	Clients []Client
+
+	// MaxConcurrentRuns is how many runs may execute at once; 0 means no
+	// limit. Further runs wait in a queue.
+	MaxConcurrentRuns int
+
+	// MaxQueuedRuns is how many runs may wait for a slot when
+	// MaxConcurrentRuns are executing. Runs beyond it are refused with 429
+	// and a Retry-After header.
+	MaxQueuedRuns int
+
+	// Usage reports, by provider name, what each client has used of the
+	// providers' rate limits, for GET /v1/admin/usage.
+	Usage func() map[string][]ports.ClientUsage
+}
+
+// RunStatus describes a run.
+type RunStatus struct {
+	ExecutionID string `json:"execution_id"`
+	Skill       string `json:"skill,omitempty"`
+	Status      string `json:"status"`
+	Output      string `json:"output,omitempty"`
+	Error       string `json:"error,omitempty"`
+	ResumeToken string `json:"resume_token,omitempty"`
+}
+
+// Event is a server-sent event about a run.
+type Event struct {
+	Type string `json:"type"`
+	RunStatus
+}
+
+// Server runs skills for HTTP clients.
+type Server struct {
+	cfg    Config
+	runFn  RunFunc
+	http   *http.Server
+	logger *logging.Logger
+
+	// ctx is the context of all runs, canceled with ErrInterrupted once
+	// the drain timeout elapses.
+	ctx       context.Context
+	interrupt context.CancelCauseFunc
+
+	mu       sync.Mutex
+	runs     map[string]*run
+	draining bool
+	inFlight sync.WaitGroup
+
+	// Run slots and the queue for them
+	slots    chan struct{} // nil when runs are not limited
+	admitted int           // runs executing or queued
+	queued   int
+	avgRun   time.Duration // moving average of run durations, for wait estimates
+}
+
+// New creates a server that runs skills with runFn.
+func New(cfg Config, runFn RunFunc) *Server {
+	ctx, interrupt := context.WithCancelCause(context.Background())
+	s := &Server{
+		cfg:       cfg,
+		runFn:     runFn,
+		logger:    logging.Default(),
+		ctx:       ctx,
+		interrupt: interrupt,
+		runs:      make(map[string]*run),
+	}
+	if cfg.MaxConcurrentRuns > 0 {
+		s.slots = make(chan struct{}, cfg.MaxConcurrentRuns)
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /v1/runs", s.authorized(false, s.handleStart))
+	mux.HandleFunc("GET /v1/runs/{id}", s.authorized(false, s.handleStatus))
+	mux.HandleFunc("GET /v1/runs/{id}/events", s.authorized(false, s.handleEvents))
+	mux.HandleFunc("GET /v1/queue", s.authorized(false, s.handleQueue))
+	mux.HandleFunc("GET /v1/admin/usage", s.authorized(true, s.handleUsage))
+	mux.HandleFunc("GET /healthz", s.handleHealth)
+	mux.HandleFunc("GET /readyz", s.handleReady)
+	s.http = &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	return s
+}
+
+// Handler returns the server's HTTP handler.
+func (s *Server) Handler() http.Handler {
+	return s.http.Handler
+}
+
+// Serve accepts connections on l until Shutdown is called.
+func (s *Server) Serve(l net.Listener) error {
+	if err := s.http.Serve(l); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// Shutdown stops accepting runs and waits for in-flight runs to finish.
+// Runs still going after the drain timeout, or when ctx ends, are
+// interrupted and their subscribers sent a shutdown event. Shutdown then
+// closes the server once its interrupted runs have returned, or ctx ends.
+func (s *Server) Shutdown(ctx context.Context) error {
+	s.mu.Lock()
+	s.draining = true
+	inFlight := 0
+	for _, r := range s.runs {
+		if !r.finished() {
+			inFlight++
+		}
+	}
+	s.mu.Unlock()
+	s.logger.Info("draining runs", "in_flight", inFlight, "drain_timeout", s.cfg.DrainTimeout)
+
+	drained := make(chan struct{})
+	go func() {
+		s.inFlight.Wait()
+		close(drained)
+	}()
+	timer := time.NewTimer(s.cfg.DrainTimeout)
+	defer timer.Stop()
+	select {
+	case <-drained:
+	case <-timer.C:
+		s.interruptRuns()
+	case <-ctx.Done():
+		s.interruptRuns()
+	}
+	select {
+	case <-drained:
+	case <-ctx.Done():
+	}
+
+	if err := s.http.Shutdown(ctx); err != nil {
+		return errors.Join(err, s.http.Close())
+	}
+	return nil
+}
+
+// interruptRuns interrupts the runs still in flight.
+func (s *Server) interruptRuns() {
+	s.logger.Warn("drain timeout elapsed, interrupting runs")
+	s.interrupt(workflow.ErrInterrupted)
+}
+
+// isDraining reports whether Shutdown has been called.
+func (s *Server) isDraining() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.draining
+}
+
+// start starts a run of req for client c.
+func (s *Server) start(req RunRequest, c Client) (*run, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.draining {
+		return nil, errDraining
+	}
+	s.pruneLocked()
+
+	id := cmp.Or(req.ResumeToken, uuid.New().String())
+	if existing, ok := s.runs[id]; ok && (!existing.finished() || !c.canSee(existing.client)) {
+		return nil, errRunning
+	}
+	limit := s.cfg.MaxConcurrentRuns
+	if limit > 0 && s.admitted >= limit+max(s.cfg.MaxQueuedRuns, 0) {
+		return nil, errSaturated
+	}
+	r := newRun(id, req.Skill, c.Name)
+	if limit > 0 && s.admitted >= limit {
+		r.current.Status = StatusQueued
+		s.queued++
+	}
+	s.admitted++
+	s.runs[id] = r
+	s.inFlight.Add(1)
+	go s.execute(r, req, ports.Client{Name: c.Name, Weight: c.Weight})
+	return r, nil
+}
+
+// execute runs r for client, once it has a run slot, and publishes how it
+// ended.
+func (s *Server) execute(r *run, req RunRequest, client ports.Client) {
+	defer s.inFlight.Done()
+	defer func() {
+		s.mu.Lock()
+		s.admitted--
+		s.mu.Unlock()
+	}()
+
+	if s.slots != nil {
+		queued := r.status().Status == StatusQueued
+		if queued {
+			r.publish(Event{Type: EventQueued, RunStatus: r.status()})
+		}
+		var slot bool
+		select {
+		case s.slots <- struct{}{}:
+			slot = true
+			defer func() { <-s.slots }()
+		case <-s.ctx.Done():
+		}
+		if queued {
+			s.mu.Lock()
+			s.queued--
+			s.mu.Unlock()
+		}
+		if !slot {
+			st := r.status()
+			st.Status = StatusFailed
+			st.Error = "server shut down before the run started"
+			r.finish(Event{Type: EventFailed, RunStatus: st})
+			return
+		}
+	}
+	started := time.Now()
+
+	st := r.status()
+	st.Status = StatusRunning
+	r.publish(Event{Type: EventStarted, RunStatus: st})
+
+	result, err := s.runFn(ports.WithClient(s.ctx, client), req, r.id)
+	st = r.status()
+	if result != nil {
+		st.Skill = cmp.Or(result.SkillID, st.Skill)
+		st.Output = result.FinalOutput
+		if err == nil {
+			err = result.Error
+		}
+	}
+	switch {
+	case err == nil && result != nil && result.Status == workflow.PhaseStatusCompleted:
+		s.observeRun(time.Since(started))
+		st.Status = StatusCompleted
+		r.finish(Event{Type: EventCompleted, RunStatus: st})
+	case errors.Is(context.Cause(s.ctx), workflow.ErrInterrupted):
+		st.Status = StatusInterrupted
+		st.Error = workflow.ErrInterrupted.Error()
+		st.ResumeToken = r.id
+		r.finish(Event{Type: EventShutdown, RunStatus: st})
+	default:
+		st.Status = StatusFailed
+		if err != nil {
+			st.Error = err.Error()
+		} else {
+			st.Error = "workflow failed"
+		}
+		r.finish(Event{Type: EventFailed, RunStatus: st})
+	}
+}
+
+// pruneLocked forgets runs that finished more than finishedRunTTL ago.
+func (s *Server) pruneLocked() {
+	for id, r := range s.runs {
+		if r.finishedBefore(time.Now().Add(-finishedRunTTL)) {
+			delete(s.runs, id)
+		}
+	}
+}
+
+// lookup returns the run with the given ID, or nil if there is none that
+// client c may see.
+func (s *Server) lookup(id string, c Client) *run {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if r, ok := s.runs[id]; ok && c.canSee(r.client) {
+		return r
+	}
+	return nil
+}
+
+func (s *Server) handleStart(w http.ResponseWriter, r *http.Request) {
+	var req RunRequest
+	if err := json.NewDecoder(io.LimitReader(r.Body, maxRequestSize)).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid run request: "+err.Error())
+		return
+	}
+	if req.Skill == "" && req.ResumeToken == "" {
+		writeError(w, http.StatusBadRequest, "skill is required")
+		return
+	}
+
+	setQueueHeaders(w, s.queueStatus())
+	run, err := s.start(req, clientFrom(r.Context()))
+	switch {
+	case errors.Is(err, errSaturated):
+		w.Header().Set("Retry-After", strconv.Itoa(ceilSeconds(s.retryAfter())))
+		writeError(w, http.StatusTooManyRequests, err.Error())
+	case errors.Is(err, errDraining):
+		writeError(w, http.StatusServiceUnavailable, err.Error())
+	case errors.Is(err, errRunning):
+		writeError(w, http.StatusConflict, err.Error())
+	default:
+		writeJSON(w, http.StatusAccepted, run.status())
+	}
+}
+
+func (s *Server) handleStatus(w http.ResponseWriter, r *http.Request) {
+	run := s.lookup(r.PathValue("id"), clientFrom(r.Context()))
+	if run == nil {
+		writeError(w, http.StatusNotFound, "run not found")
+		return
+	}
+	writeJSON(w, http.StatusOK, run.status())
+}
+
+// handleEvents streams the events of a run, from its first, until it ends.
+func (s *Server) handleEvents(w http.ResponseWriter, r *http.Request) {
+	run := s.lookup(r.PathValue("id"), clientFrom(r.Context()))
+	if run == nil {
+		writeError(w, http.StatusNotFound, "run not found")
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, http.StatusInternalServerError, "streaming is not supported")
+		return
+	}
+
+	events, unsubscribe := run.subscribe()
+	defer unsubscribe()
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	for {
+		select {
+		case ev, ok := <-events:
+			if !ok {
+				return
+			}
+			data, err := json.Marshal(ev)
+			if err != nil {
+				return
+			}
+			if _, err := io.WriteString(w, "event: "+ev.Type+"\ndata: "+string(data)+"\n\n"); err != nil {
+				return
+			}
+			flusher.Flush()
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
+
+// writeJSON writes v as a JSON response.
+func writeJSON(w http.ResponseWriter, code int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// writeError writes an error response.
+func writeError(w http.ResponseWriter, code int, msg string) {
+	writeJSON(w, code, map[string]string{"error": msg})
+}
@@ -0,0 +1,381 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jbctechsolutions/skillrunner/internal/application/ports"
+	"github.com/jbctechsolutions/skillrunner/internal/application/workflow"
+)
+
+// startRun posts req to the server and returns the started run.
+func startRun(t *testing.T, url string, req RunRequest) RunStatus {
+	t.Helper()
+	body, _ := json.Marshal(req)
+	resp, err := http.Post(url+"/v1/runs", "application/json", strings.NewReader(string(body)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusAccepted {
+		t.Fatalf("POST /v1/runs status = %d, want 202", resp.StatusCode)
+	}
+	var st RunStatus
+	if err := json.NewDecoder(resp.Body).Decode(&st); err != nil {
+		t.Fatal(err)
+	}
+	return st
+}
+
+// readEvents reads the events of a run until its stream ends.
+func readEvents(t *testing.T, url, id string) []Event {
+	t.Helper()
+	resp, err := http.Get(url + "/v1/runs/" + id + "/events")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("Content-Type = %q, want text/event-stream", ct)
+	}
+	var events []Event
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		data, ok := strings.CutPrefix(scanner.Text(), "data: ")
+		if !ok {
+			continue
+		}
+		var ev Event
+		if err := json.Unmarshal([]byte(data), &ev); err != nil {
+			t.Fatalf("invalid event %q: %v", data, err)
+		}
+		events = append(events, ev)
+	}
+	return events
+}
+
+func eventTypes(events []Event) []string {
+	types := make([]string, len(events))
+	for i, ev := range events {
+		types[i] = ev.Type
+	}
+	return types
+}
+
+func TestServer_Run(t *testing.T) {
+	s := New(Config{DrainTimeout: time.Second}, func(_ context.Context, req RunRequest, _ string) (*workflow.ExecutionResult, error) {
+		return &workflow.ExecutionResult{SkillID: req.Skill, Status: workflow.PhaseStatusCompleted, FinalOutput: "done: " + req.Input}, nil
+	})
+	ts := httptest.NewServer(s.Handler())
+	defer ts.Close()
+
+	st := startRun(t, ts.URL, RunRequest{Skill: "review", Input: "diff"})
+	events := readEvents(t, ts.URL, st.ExecutionID)
+	if got := eventTypes(events); len(got) != 2 || got[0] != EventStarted || got[1] != EventCompleted {
+		t.Fatalf("events = %v, want started, completed", got)
+	}
+	if last := events[1]; last.Output != "done: diff" || last.Status != StatusCompleted {
+		t.Errorf("completed event = %+v", last)
+	}
+}
+
+func TestServer_ShutdownDrainsRuns(t *testing.T) {
+	release := make(chan struct{})
+	s := New(Config{DrainTimeout: 5 * time.Second}, func(context.Context, RunRequest, string) (*workflow.ExecutionResult, error) {
+		<-release
+		return &workflow.ExecutionResult{Status: workflow.PhaseStatusCompleted}, nil
+	})
+	ts := httptest.NewServer(s.Handler())
+	defer ts.Close()
+
+	st := startRun(t, ts.URL, RunRequest{Skill: "review"})
+	shutdown := make(chan error, 1)
+	go func() { shutdown <- s.Shutdown(context.Background()) }()
+
+	// New runs are refused while draining
+	for !s.isDraining() {
+		time.Sleep(time.Millisecond)
+	}
+	resp, err := http.Post(ts.URL+"/v1/runs", "application/json", strings.NewReader(`{"skill":"review"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("POST while draining status = %d, want 503", resp.StatusCode)
+	}
+
+	close(release)
+	if err := <-shutdown; err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+	if got := s.lookup(st.ExecutionID, Client{Admin: true}).status().Status; got != StatusCompleted {
+		t.Errorf("run status = %s, want completed within the drain timeout", got)
+	}
+}
+
+func TestServer_ShutdownInterruptsRuns(t *testing.T) {
+	causes := make(chan error, 1)
+	s := New(Config{DrainTimeout: 20 * time.Millisecond}, func(ctx context.Context, _ RunRequest, _ string) (*workflow.ExecutionResult, error) {
+		<-ctx.Done()
+		causes <- context.Cause(ctx)
+		return &workflow.ExecutionResult{Status: workflow.PhaseStatusFailed, Error: ctx.Err()}, ctx.Err()
+	})
+	ts := httptest.NewServer(s.Handler())
+	defer ts.Close()
+
+	st := startRun(t, ts.URL, RunRequest{Skill: "review"})
+	events := make(chan []Event, 1)
+	go func() { events <- readEvents(t, ts.URL, st.ExecutionID) }()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := s.Shutdown(ctx); err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+	if cause := <-causes; !errors.Is(cause, workflow.ErrInterrupted) {
+		t.Errorf("run canceled with %v, want ErrInterrupted", cause)
+	}
+
+	got := <-events
+	last := got[len(got)-1]
+	if last.Type != EventShutdown || last.ResumeToken != st.ExecutionID {
+		t.Errorf("last event = %+v, want a shutdown event with the run's resume token", last)
+	}
+}
+
+func TestServer_ResumeTokenReusesExecutionID(t *testing.T) {
+	resumed := make(chan RunRequest, 1)
+	s := New(Config{DrainTimeout: time.Second}, func(_ context.Context, req RunRequest, id string) (*workflow.ExecutionResult, error) {
+		if id != req.ResumeToken {
+			t.Errorf("execution ID = %q, want the resume token %q", id, req.ResumeToken)
+		}
+		resumed <- req
+		return &workflow.ExecutionResult{Status: workflow.PhaseStatusCompleted}, nil
+	})
+	ts := httptest.NewServer(s.Handler())
+	defer ts.Close()
+
+	st := startRun(t, ts.URL, RunRequest{ResumeToken: "exec-1"})
+	if st.ExecutionID != "exec-1" {
+		t.Errorf("execution ID = %q, want exec-1", st.ExecutionID)
+	}
+	<-resumed
+}
+
+func TestServer_BadRequests(t *testing.T) {
+	s := New(Config{}, nil)
+	ts := httptest.NewServer(s.Handler())
+	defer ts.Close()
+
+	resp, err := http.Post(ts.URL+"/v1/runs", "application/json", strings.NewReader(`{"input":"x"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("POST without a skill status = %d, want 400", resp.StatusCode)
+	}
+
+	resp, err = http.Get(ts.URL + "/v1/runs/missing")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("GET of an unknown run status = %d, want 404", resp.StatusCode)
+	}
+}
+
+// getReadiness returns the status code and body of GET /readyz.
+func getReadiness(t *testing.T, url string) (int, Readiness) {
+	t.Helper()
+	resp, err := http.Get(url + "/readyz")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	var ready Readiness
+	if err := json.NewDecoder(resp.Body).Decode(&ready); err != nil {
+		t.Fatal(err)
+	}
+	return resp.StatusCode, ready
+}
+
+func TestServer_HealthAndReadiness(t *testing.T) {
+	var storageErr error
+	s := New(Config{DrainTimeout: time.Second, ReadinessChecks: []Check{
+		{Name: "providers", Run: func(context.Context) error { return nil }},
+		{Name: "storage", Run: func(context.Context) error { return storageErr }},
+	}}, nil)
+	ts := httptest.NewServer(s.Handler())
+	defer ts.Close()
+
+	resp, err := http.Get(ts.URL + "/healthz")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("GET /healthz status = %d, want 200", resp.StatusCode)
+	}
+
+	if code, ready := getReadiness(t, ts.URL); code != http.StatusOK || !ready.Ready || len(ready.Checks) != 2 {
+		t.Errorf("GET /readyz = %d, %+v; want ready with both checks", code, ready)
+	}
+
+	storageErr = errors.New("database is locked")
+	code, ready := getReadiness(t, ts.URL)
+	if code != http.StatusServiceUnavailable || ready.Ready || ready.Checks[1].OK || ready.Checks[1].Error != "database is locked" {
+		t.Errorf("GET /readyz with storage down = %d, %+v; want 503 naming the failed check", code, ready)
+	}
+
+	storageErr = nil
+	if err := s.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if code, ready := getReadiness(t, ts.URL); code != http.StatusServiceUnavailable || !ready.Draining {
+		t.Errorf("GET /readyz while draining = %d, %+v; want 503", code, ready)
+	}
+}
+
+// request sends a request with the given bearer token and returns its
+// status code, decoding a JSON body into out if it is set.
+func request(t *testing.T, method, url, token, body string, out any) int {
+	t.Helper()
+	req, err := http.NewRequest(method, url, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if out != nil {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return resp.StatusCode
+}
+
+func TestServer_Clients(t *testing.T) {
+	clients := make(chan ports.Client, 1)
+	s := New(Config{
+		DrainTimeout: time.Second,
+		Clients: []Client{
+			{Name: "ci", Token: "ci-token", Weight: 2},
+			{Name: "web", Token: "web-token"},
+			{Name: "ops", Token: "ops-token", Admin: true},
+		},
+		Usage: func() map[string][]ports.ClientUsage {
+			return map[string][]ports.ClientUsage{"openai": {{Client: "ci", Requests: 3}}}
+		},
+	}, func(ctx context.Context, req RunRequest, _ string) (*workflow.ExecutionResult, error) {
+		clients <- ports.ClientFrom(ctx)
+		return &workflow.ExecutionResult{SkillID: req.Skill, Status: workflow.PhaseStatusCompleted}, nil
+	})
+	ts := httptest.NewServer(s.Handler())
+	defer ts.Close()
+
+	body := `{"skill":"review"}`
+	for _, token := range []string{"", "wrong"} {
+		if code := request(t, http.MethodPost, ts.URL+"/v1/runs", token, body, nil); code != http.StatusUnauthorized {
+			t.Errorf("POST /v1/runs with token %q status = %d, want 401", token, code)
+		}
+	}
+	if code := request(t, http.MethodGet, ts.URL+"/healthz", "", "", nil); code != http.StatusOK {
+		t.Errorf("GET /healthz without a token status = %d, want 200", code)
+	}
+
+	var st RunStatus
+	if code := request(t, http.MethodPost, ts.URL+"/v1/runs", "ci-token", body, &st); code != http.StatusAccepted {
+		t.Fatalf("POST /v1/runs status = %d, want 202", code)
+	}
+	if c := <-clients; c.Name != "ci" || c.Weight != 2 {
+		t.Errorf("run client = %+v, want ci with its weight", c)
+	}
+	for token, want := range map[string]int{"ci-token": http.StatusOK, "web-token": http.StatusNotFound, "ops-token": http.StatusOK} {
+		if code := request(t, http.MethodGet, ts.URL+"/v1/runs/"+st.ExecutionID, token, "", nil); code != want {
+			t.Errorf("GET run with %s status = %d, want %d", token, code, want)
+		}
+	}
+
+	if code := request(t, http.MethodGet, ts.URL+"/v1/admin/usage", "ci-token", "", nil); code != http.StatusForbidden {
+		t.Errorf("GET /v1/admin/usage as a client status = %d, want 403", code)
+	}
+	var usage UsageResponse
+	if code := request(t, http.MethodGet, ts.URL+"/v1/admin/usage", "ops-token", "", &usage); code != http.StatusOK {
+		t.Fatalf("GET /v1/admin/usage as an admin status = %d, want 200", code)
+	}
+	if u := usage.Providers["openai"]; len(u) != 1 || u[0].Client != "ci" || u[0].Requests != 3 {
+		t.Errorf("usage = %+v, want the usage reported by the limiters", usage)
+	}
+}
+
+func TestServer_Backpressure(t *testing.T) {
+	release := make(chan struct{})
+	s := New(Config{DrainTimeout: time.Second, MaxConcurrentRuns: 1, MaxQueuedRuns: 1}, func(_ context.Context, req RunRequest, _ string) (*workflow.ExecutionResult, error) {
+		<-release
+		return &workflow.ExecutionResult{SkillID: req.Skill, Status: workflow.PhaseStatusCompleted}, nil
+	})
+	ts := httptest.NewServer(s.Handler())
+	defer ts.Close()
+
+	post := func() (*http.Response, RunStatus) {
+		t.Helper()
+		resp, err := http.Post(ts.URL+"/v1/runs", "application/json", strings.NewReader(`{"skill":"review"}`))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer func() { _ = resp.Body.Close() }()
+		var st RunStatus
+		_ = json.NewDecoder(resp.Body).Decode(&st)
+		return resp, st
+	}
+
+	resp, first := post()
+	if resp.StatusCode != http.StatusAccepted || first.Status != StatusRunning || resp.Header.Get("X-Estimated-Wait") != "0" {
+		t.Errorf("first run = %d, %s, wait %q; want it running at once", resp.StatusCode, first.Status, resp.Header.Get("X-Estimated-Wait"))
+	}
+	resp, second := post()
+	if resp.StatusCode != http.StatusAccepted || second.Status != StatusQueued || resp.Header.Get("X-Estimated-Wait") != "60" {
+		t.Errorf("second run = %d, %s, wait %q; want it queued behind an assumed one-minute run", resp.StatusCode, second.Status, resp.Header.Get("X-Estimated-Wait"))
+	}
+	resp, _ = post()
+	if resp.StatusCode != http.StatusTooManyRequests || resp.Header.Get("Retry-After") != "60" || resp.Header.Get("X-Queue-Depth") != "1" {
+		t.Errorf("third run = %d, Retry-After %q, depth %q; want 429 with a full queue", resp.StatusCode, resp.Header.Get("Retry-After"), resp.Header.Get("X-Queue-Depth"))
+	}
+
+	var queue QueueStatus
+	if code := request(t, http.MethodGet, ts.URL+"/v1/queue", "", "", &queue); code != http.StatusOK {
+		t.Fatalf("GET /v1/queue status = %d", code)
+	}
+	if queue.Running != 1 || queue.Queued != 1 || !queue.Saturated {
+		t.Errorf("queue = %+v, want one running, one queued, saturated", queue)
+	}
+
+	close(release)
+	if got := eventTypes(readEvents(t, ts.URL, second.ExecutionID)); !slices.Equal(got, []string{EventQueued, EventStarted, EventCompleted}) {
+		t.Errorf("queued run events = %v, want queued, started, completed", got)
+	}
+	deadline := time.Now().Add(time.Second)
+	for got := s.queueStatus(); got.Running != 0 || got.Queued != 0 || got.EstimatedWait != 0; got = s.queueStatus() {
+		if time.Now().After(deadline) {
+			t.Fatalf("queue after the runs = %+v, want it empty", got)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}